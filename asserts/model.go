@@ -592,10 +592,11 @@ type Model struct {
 
 	validationSets []*ModelValidationSet
 
-	serialAuthority  []string
-	sysUserAuthority []string
-	preseedAuthority []string
-	timestamp        time.Time
+	serialAuthority   []string
+	sysUserAuthority  []string
+	preseedAuthority  []string
+	sideloadAuthority []string
+	timestamp         time.Time
 }
 
 // BrandID returns the brand identifier. Same as the authority id.
@@ -770,6 +771,15 @@ func (mod *Model) PreseedAuthority() []string {
 	return mod.preseedAuthority
 }
 
+// SideloadAuthority returns the authority ids that are accepted as
+// signers of the snap-revision assertions of snaps sideloaded on devices
+// of this model, or nil if sideloading is not restricted. If set, it
+// always includes the brand of the model and unasserted snaps cannot
+// be sideloaded.
+func (mod *Model) SideloadAuthority() []string {
+	return mod.sideloadAuthority
+}
+
 // Timestamp returns the time when the model assertion was issued.
 func (mod *Model) Timestamp() time.Time {
 	return mod.timestamp
@@ -853,6 +863,15 @@ func checkOptionalPreseedAuthority(headers map[string]interface{}, brandID strin
 	return checkOptionalAuthority(headers, "preseed-authority", brandID, acceptsWildcard)
 }
 
+func checkOptionalSideloadAuthority(headers map[string]interface{}, brandID string) ([]string, error) {
+	// unlike the other authorities there is no restriction by default
+	if _, ok := headers["sideload-authority"]; !ok {
+		return nil, nil
+	}
+	const acceptsWildcard = false
+	return checkOptionalAuthority(headers, "sideload-authority", brandID, acceptsWildcard)
+}
+
 func checkModelValidationSetAccountID(headers map[string]interface{}, what, brandID string) (string, error) {
 	accountID, err := checkOptionalStringWhat(headers, "account-id", what)
 	if err != nil {
@@ -1181,6 +1200,11 @@ func assembleModel(assert assertionBase) (Assertion, error) {
 		return nil, err
 	}
 
+	sideloadAuthority, err := checkOptionalSideloadAuthority(assert.headers, brandID)
+	if err != nil {
+		return nil, err
+	}
+
 	timestamp, err := checkRFC3339Date(assert.headers, "timestamp")
 	if err != nil {
 		return nil, err
@@ -1216,6 +1240,7 @@ func assembleModel(assert assertionBase) (Assertion, error) {
 		serialAuthority:            serialAuthority,
 		sysUserAuthority:           sysUserAuthority,
 		preseedAuthority:           preseedAuthority,
+		sideloadAuthority:          sideloadAuthority,
 		timestamp:                  timestamp,
 	}, nil
 }
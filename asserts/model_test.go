@@ -550,6 +550,28 @@ func (mods *modelSuite) TestDecodePreseedAuthorityIsOptional(c *C) {
 	c.Check(model.PreseedAuthority(), DeepEquals, []string{"brand-id1", "foo", "bar"})
 }
 
+func (mods *modelSuite) TestDecodeSideloadAuthority(c *C) {
+	withTimestamp := strings.Replace(modelExample, "TSLINE", mods.tsLine, 1)
+	a, err := asserts.Decode([]byte(withTimestamp))
+	c.Assert(err, IsNil)
+	model := a.(*asserts.Model)
+	// the default is not to restrict sideloading
+	c.Check(model.SideloadAuthority(), IsNil)
+
+	encoded := strings.Replace(withTimestamp, preseedAuths, preseedAuths+"sideload-authority:\n  - foo\n", 1)
+	a, err = asserts.Decode([]byte(encoded))
+	c.Assert(err, IsNil)
+	model = a.(*asserts.Model)
+	// the brand is always added implicitly
+	c.Check(model.SideloadAuthority(), DeepEquals, []string{"brand-id1", "foo"})
+
+	encoded = strings.Replace(withTimestamp, preseedAuths, preseedAuths+"sideload-authority:\n  - brand-id1\n", 1)
+	a, err = asserts.Decode([]byte(encoded))
+	c.Assert(err, IsNil)
+	model = a.(*asserts.Model)
+	c.Check(model.SideloadAuthority(), DeepEquals, []string{"brand-id1"})
+}
+
 func (mods *modelSuite) TestDecodeKernelTrack(c *C) {
 	withTimestamp := strings.Replace(modelExample, "TSLINE", mods.tsLine, 1)
 	encoded := strings.Replace(withTimestamp, "kernel: baz-linux\n", "kernel: baz-linux=18\n", 1)
@@ -632,6 +654,8 @@ func (mods *modelSuite) TestDecodeInvalid(c *C) {
 		{preseedAuths, "preseed-authority:\n  a: 1\n", `"preseed-authority" header must be a list of account ids`},
 		{preseedAuths, "preseed-authority:\n  - 5_6\n", `"preseed-authority" header must be a list of account ids`},
 		{preseedAuths, "preseed-authority: *\n", `"preseed-authority" header must be a list of account ids`},
+		{preseedAuths, preseedAuths + "sideload-authority:\n  - 5_6\n", `"sideload-authority" header must be a list of account ids`},
+		{preseedAuths, preseedAuths + "sideload-authority: *\n", `"sideload-authority" header must be a list of account ids`},
 		{reqSnaps, "grade: dangerous\n", `cannot specify a grade for model without the extended snaps header`},
 	}
 
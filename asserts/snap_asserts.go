@@ -575,6 +575,13 @@ func (snaprev *SnapRevision) Provenance() string {
 	return snaprev.HeaderString("provenance")
 }
 
+// BuildProvenanceSHA3_384 returns the SHA3-384 digest of the optional
+// build provenance document embedded in the snap, or the empty string if
+// the snap does not carry one.
+func (snaprev *SnapRevision) BuildProvenanceSHA3_384() string {
+	return snaprev.HeaderString("build-provenance-sha3-384")
+}
+
 // SnapID returns the snap id of the snap.
 func (snaprev *SnapRevision) SnapID() string {
 	return snaprev.HeaderString("snap-id")
@@ -694,6 +701,13 @@ func assembleSnapRevision(assert assertionBase) (Assertion, error) {
 		return nil, err
 	}
 
+	if _, ok := assert.headers["build-provenance-sha3-384"]; ok {
+		_, err = checkDigest(assert.headers, "build-provenance-sha3-384", crypto.SHA3_384)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	_, err = checkNotEmptyString(assert.headers, "snap-id")
 	if err != nil {
 		return nil, err
@@ -1097,6 +1097,21 @@ func (srs *snapRevSuite) TestDecodeOKWithProvenance(c *C) {
 	c.Check(snapRev.Provenance(), Equals, "foo")
 }
 
+func (srs *snapRevSuite) TestDecodeOKWithBuildProvenance(c *C) {
+	encoded := srs.makeValidEncoded()
+	c.Check(strings.Contains(encoded, "build-provenance-sha3-384"), Equals, false)
+	a, err := asserts.Decode([]byte(encoded))
+	c.Assert(err, IsNil)
+	c.Check(a.(*asserts.SnapRevision).BuildProvenanceSHA3_384(), Equals, "")
+
+	encoded = strings.Replace(encoded, "snap-id: snap-id-1", "build-provenance-sha3-384: "+blobSHA3_384+"\nsnap-id: snap-id-1", 1)
+	a, err = asserts.Decode([]byte(encoded))
+	c.Assert(err, IsNil)
+	snapRev := a.(*asserts.SnapRevision)
+	c.Check(snapRev.SnapSHA3_384(), Equals, blobSHA3_384)
+	c.Check(snapRev.BuildProvenanceSHA3_384(), Equals, blobSHA3_384)
+}
+
 func (srs *snapRevSuite) TestDecodeOKWithIntegrity(c *C) {
 	encoded := srs.makeValidEncodedWithIntegrity()
 	a, err := asserts.Decode([]byte(encoded))
@@ -1133,6 +1148,8 @@ func (srs *snapRevSuite) TestDecodeInvalid(c *C) {
 		{digestHdr, "snap-sha3-384: eHl6\n", `"snap-sha3-384" header does not have the expected bit length: 24`},
 		{"snap-id: snap-id-1\n", "provenance: \nsnap-id: snap-id-1\n", `"provenance" header should not be empty`},
 		{"snap-id: snap-id-1\n", "provenance: *\nsnap-id: snap-id-1\n", `"provenance" header contains invalid characters: "\*"`},
+		{"snap-id: snap-id-1\n", "build-provenance-sha3-384: \nsnap-id: snap-id-1\n", `"build-provenance-sha3-384" header should not be empty`},
+		{"snap-id: snap-id-1\n", "build-provenance-sha3-384: eHl6\nsnap-id: snap-id-1\n", `"build-provenance-sha3-384" header does not have the expected bit length: 24`},
 		{"snap-size: 123\n", "", `"snap-size" header is mandatory`},
 		{"snap-size: 123\n", "snap-size: \n", `"snap-size" header should not be empty`},
 		{"snap-size: 123\n", "snap-size: -1\n", `"snap-size" header is not an unsigned integer: -1`},
@@ -21,15 +21,20 @@
 package snapasserts
 
 import (
+	"crypto"
 	"errors"
 	"fmt"
+	"os"
 	"strconv"
 
+	"golang.org/x/crypto/sha3"
+
 	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/release"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/naming"
 	"github.com/snapcore/snapd/snap/snapfile"
+	"github.com/snapcore/snapd/strutil"
 )
 
 type Finder interface {
@@ -291,6 +296,38 @@ func CheckProvenanceWithVerifiedRevision(snapPath string, verifiedRev *asserts.S
 	return nil
 }
 
+// CheckBuildProvenanceWithVerifiedRevision checks that the build provenance
+// document embedded in the given snap matches the digest carried by the
+// provided snap-revision, if the latter carries one. It is intended to be
+// called safely on snaps for which a matching and authorized snap-revision
+// has been already found and cross-checked.
+func CheckBuildProvenanceWithVerifiedRevision(snapPath string, verifiedRev *asserts.SnapRevision) error {
+	expected := verifiedRev.BuildProvenanceSHA3_384()
+	if expected == "" {
+		return nil
+	}
+	snapf, err := snapfile.Open(snapPath)
+	if err != nil {
+		return err
+	}
+	doc, err := snapf.ReadFile(snap.BuildProvenancePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("snap %q does not contain the build provenance document referenced by its signatures", snapPath)
+	}
+	if err != nil {
+		return err
+	}
+	digest := sha3.Sum384(doc)
+	sha3_384, err := asserts.EncodeDigest(crypto.SHA3_384, digest[:])
+	if err != nil {
+		return err
+	}
+	if sha3_384 != expected {
+		return fmt.Errorf("snap %q build provenance document does not have expected digest according to signatures (snap is broken or tampered): %s != %s", snapPath, sha3_384, expected)
+	}
+	return nil
+}
+
 // CheckComponentProvenanceWithVerifiedRevision checks that the given component
 // has the same provenance as of the provided resource-revision. It is intended
 // to be called safely on components for which a matching and authorized
@@ -352,22 +389,55 @@ func DeriveComponentSideInfo(name, path string, info *snap.Info, model *asserts.
 // model is used to cross check that the found snap-revision is applicable
 // on the device.
 func DeriveSideInfoFromDigestAndSize(snapPath string, snapSHA3_384 string, snapSize uint64, model *asserts.Model, db Finder) (*snap.SideInfo, error) {
+	si, _, err := deriveSideInfoFromDigestAndSize(snapPath, snapSHA3_384, snapSize, model, db)
+	return si, err
+}
+
+// DeriveSideInfoForSideload is like DeriveSideInfo but for snaps being
+// sideloaded on a device of the given model. If the model restricts
+// sideloading, it additionally checks that the snap-revision is signed by
+// one of the sideload authorities of the model. The build provenance
+// document of the snap, if referenced by the snap-revision, is checked as
+// well.
+func DeriveSideInfoForSideload(snapPath string, model *asserts.Model, db Finder) (*snap.SideInfo, error) {
+	snapSHA3_384, snapSize, err := asserts.SnapFileSHA3_384(snapPath)
+	if err != nil {
+		return nil, err
+	}
+
+	si, snapRev, err := deriveSideInfoFromDigestAndSize(snapPath, snapSHA3_384, snapSize, model, db)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckBuildProvenanceWithVerifiedRevision(snapPath, snapRev); err != nil {
+		return nil, err
+	}
+	if model == nil || model.SideloadAuthority() == nil {
+		return si, nil
+	}
+	if !strutil.ListContains(model.SideloadAuthority(), snapRev.AuthorityID()) {
+		return nil, fmt.Errorf("cannot sideload snap %q: snap-revision assertion is signed by %q, not by an authority allowed by the model", snapPath, snapRev.AuthorityID())
+	}
+	return si, nil
+}
+
+func deriveSideInfoFromDigestAndSize(snapPath string, snapSHA3_384 string, snapSize uint64, model *asserts.Model, db Finder) (*snap.SideInfo, *asserts.SnapRevision, error) {
 	// get relevant assertions and reconstruct metadata
 	headers := map[string]string{
 		"snap-sha3-384": snapSHA3_384,
 	}
 	a, err := db.Find(asserts.SnapRevisionType, headers)
 	if err != nil && !errors.Is(err, &asserts.NotFoundError{}) {
-		return nil, err
+		return nil, nil, err
 	}
 	if a == nil {
 		// non-default provenance?
 		cands, err := db.FindMany(asserts.SnapRevisionType, headers)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		if len(cands) != 1 {
-			return nil, fmt.Errorf("safely handling snaps with different provenance but same hash not yet supported")
+			return nil, nil, fmt.Errorf("safely handling snaps with different provenance but same hash not yet supported")
 		}
 		a = cands[0]
 	}
@@ -375,25 +445,25 @@ func DeriveSideInfoFromDigestAndSize(snapPath string, snapSHA3_384 string, snapS
 	snapRev := a.(*asserts.SnapRevision)
 
 	if snapRev.SnapSize() != snapSize {
-		return nil, fmt.Errorf("snap %q does not have expected size according to signatures (broken or tampered): %d != %d", snapPath, snapSize, snapRev.SnapSize())
+		return nil, nil, fmt.Errorf("snap %q does not have expected size according to signatures (broken or tampered): %d != %d", snapPath, snapSize, snapRev.SnapSize())
 	}
 
 	snapID := snapRev.SnapID()
 
 	snapDecl, err := findSnapDeclaration(snapID, snapPath, db)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if _, err = CrossCheckProvenance(snapDecl.SnapName(), snapRev, snapDecl, model, db); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if err := CheckProvenanceWithVerifiedRevision(snapPath, snapRev); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return SideInfoFromSnapAssertions(snapDecl, snapRev), nil
+	return SideInfoFromSnapAssertions(snapDecl, snapRev), snapRev, nil
 }
 
 // SideInfoFromSnapAssertions returns a *snap.SideInfo reflecting the given snap assertions.
@@ -410,6 +480,35 @@ func SideInfoFromSnapAssertions(snapDecl *asserts.SnapDeclaration, snapRev *asse
 // snap to find the relevant assertions with the information in the given
 // database. It will fail with an asserts.NotFoundError if it cannot find them.
 func DeriveComponentSideInfoFromDigestAndSize(resName, snapName, snapID string, compPath, snapSHA3_384 string, resSize uint64, model *asserts.Model, db Finder) (*snap.ComponentSideInfo, error) {
+	csi, _, err := deriveComponentSideInfoFromDigestAndSize(resName, snapName, snapID, compPath, snapSHA3_384, resSize, model, db)
+	return csi, err
+}
+
+// DeriveComponentSideInfoForSideload is like DeriveComponentSideInfo but for
+// components being sideloaded on a device of the given model. If the model
+// restricts sideloading, it additionally checks that the
+// snap-resource-revision is signed by one of the sideload authorities of the
+// model.
+func DeriveComponentSideInfoForSideload(name, path string, info *snap.Info, model *asserts.Model, db Finder) (*snap.ComponentSideInfo, error) {
+	digest, size, err := asserts.SnapFileSHA3_384(path)
+	if err != nil {
+		return nil, err
+	}
+
+	csi, resRev, err := deriveComponentSideInfoFromDigestAndSize(name, info.SnapName(), info.ID(), path, digest, size, model, db)
+	if err != nil {
+		return nil, err
+	}
+	if model == nil || model.SideloadAuthority() == nil {
+		return csi, nil
+	}
+	if !strutil.ListContains(model.SideloadAuthority(), resRev.AuthorityID()) {
+		return nil, fmt.Errorf("cannot sideload component %q: snap-resource-revision assertion is signed by %q, not by an authority allowed by the model", path, resRev.AuthorityID())
+	}
+	return csi, nil
+}
+
+func deriveComponentSideInfoFromDigestAndSize(resName, snapName, snapID string, compPath, snapSHA3_384 string, resSize uint64, model *asserts.Model, db Finder) (*snap.ComponentSideInfo, *asserts.SnapResourceRevision, error) {
 	// get relevant assertions and reconstruct metadata
 	headers := map[string]string{
 		"snap-id":           snapID,
@@ -418,16 +517,16 @@ func DeriveComponentSideInfoFromDigestAndSize(resName, snapName, snapID string,
 	}
 	a, err := db.Find(asserts.SnapResourceRevisionType, headers)
 	if err != nil && !errors.Is(err, &asserts.NotFoundError{}) {
-		return nil, err
+		return nil, nil, err
 	}
 	if a == nil {
 		// non-default provenance?
 		cands, err := db.FindMany(asserts.SnapResourceRevisionType, headers)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		if len(cands) != 1 {
-			return nil, fmt.Errorf("safely handling resources with different provenance but same hash not yet supported")
+			return nil, nil, fmt.Errorf("safely handling resources with different provenance but same hash not yet supported")
 		}
 		a = cands[0]
 	}
@@ -435,26 +534,26 @@ func DeriveComponentSideInfoFromDigestAndSize(resName, snapName, snapID string,
 	resRev := a.(*asserts.SnapResourceRevision)
 
 	if resRev.ResourceSize() != resSize {
-		return nil, fmt.Errorf("resource %q does not have the expected size according to signatures (broken or tampered): %d != %d", resName, resSize, resRev.ResourceSize())
+		return nil, nil, fmt.Errorf("resource %q does not have the expected size according to signatures (broken or tampered): %d != %d", resName, resSize, resRev.ResourceSize())
 	}
 
 	snapDecl, err := findSnapDeclaration(snapID, snapName, db)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	err = crossCheckResourceProvenance(resRev, snapDecl, model, db)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if err := CheckComponentProvenanceWithVerifiedRevision(compPath, resRev); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	return &snap.ComponentSideInfo{
 		Component: naming.NewComponentRef(snapName, resName),
 		Revision:  snap.R(resRev.ResourceRevision()),
-	}, nil
+	}, resRev, nil
 }
 
 // FetchSnapAssertions fetches the assertions matching the snap file digest and optional provenance using the given fetcher.
@@ -644,6 +644,47 @@ version: 1
 
 }
 
+func (s *snapassertsSuite) TestCheckBuildProvenanceWithVerifiedRevision(c *C) {
+	doc := []byte(`{"predicateType": "https://slsa.dev/provenance/v1"}`)
+	docDigest := sha3.Sum384(doc)
+	docSHA3_384, err := asserts.EncodeDigest(crypto.SHA3_384, docDigest[:])
+	c.Assert(err, IsNil)
+
+	snapRev := func(buildProvenance string) *asserts.SnapRevision {
+		headers := map[string]interface{}{
+			"type":          "snap-revision",
+			"authority-id":  "can0nical",
+			"snap-id":       "snap-id-1",
+			"snap-sha3-384": makeDigest(12),
+			"snap-size":     "1000",
+			"snap-revision": "12",
+			"developer-id":  s.dev1Acct.AccountID(),
+			"timestamp":     time.Now().Format(time.RFC3339),
+		}
+		if buildProvenance != "" {
+			headers["build-provenance-sha3-384"] = buildProvenance
+		}
+		return assertstest.FakeAssertion(headers).(*asserts.SnapRevision)
+	}
+	withDoc := snaptest.MakeTestSnapWithFiles(c, "name: foo\nversion: 1\n", [][]string{
+		{"meta/build-provenance.json", string(doc)},
+	})
+	withoutDoc := snaptest.MakeTestSnapWithFiles(c, "name: foo\nversion: 1\n", nil)
+
+	// nothing to check
+	c.Check(snapasserts.CheckBuildProvenanceWithVerifiedRevision(withDoc, snapRev("")), IsNil)
+	c.Check(snapasserts.CheckBuildProvenanceWithVerifiedRevision(withoutDoc, snapRev("")), IsNil)
+
+	// matching
+	c.Check(snapasserts.CheckBuildProvenanceWithVerifiedRevision(withDoc, snapRev(docSHA3_384)), IsNil)
+
+	// mismatches
+	err = snapasserts.CheckBuildProvenanceWithVerifiedRevision(withDoc, snapRev(makeDigest(13)))
+	c.Check(err, ErrorMatches, fmt.Sprintf(`snap %q build provenance document does not have expected digest according to signatures \(snap is broken or tampered\): %s != %s`, withDoc, docSHA3_384, makeDigest(13)))
+	err = snapasserts.CheckBuildProvenanceWithVerifiedRevision(withoutDoc, snapRev(docSHA3_384))
+	c.Check(err, ErrorMatches, fmt.Sprintf(`snap %q does not contain the build provenance document referenced by its signatures`, withoutDoc))
+}
+
 func (s *snapassertsSuite) TestCheckComponentProvenanceWithVerifiedRevision(c *C) {
 	digest := makeDigest(12)
 	size := uint64(len(fakeSnap(12)))
@@ -855,6 +896,88 @@ func assertedSnapID(snapName string) string {
 	return snaptest.AssertedSnapID(snapName)
 }
 
+func (s *snapassertsSuite) TestDeriveSideInfoForSideload(c *C) {
+	fooSnap := snaptest.MakeTestSnapWithFiles(c, `name: foo
+version: 1`, nil)
+	digest, size, err := asserts.SnapFileSHA3_384(fooSnap)
+	c.Assert(err, IsNil)
+
+	headers := map[string]interface{}{
+		"snap-id":       "snap-id-1",
+		"snap-sha3-384": digest,
+		"snap-size":     fmt.Sprintf("%d", size),
+		"snap-revision": "42",
+		"developer-id":  s.dev1Acct.AccountID(),
+		"timestamp":     time.Now().Format(time.RFC3339),
+	}
+	snapRev, err := s.storeSigning.Sign(asserts.SnapRevisionType, headers, nil, "")
+	c.Assert(err, IsNil)
+	err = s.localDB.Add(snapRev)
+	c.Assert(err, IsNil)
+
+	expectedSI := &snap.SideInfo{
+		RealName: "foo",
+		SnapID:   "snap-id-1",
+		Revision: snap.R(42),
+	}
+
+	// sideloading is not restricted
+	si, err := snapasserts.DeriveSideInfoForSideload(fooSnap, s.makeUC20Model(c, nil), s.localDB)
+	c.Assert(err, IsNil)
+	c.Check(si, DeepEquals, expectedSI)
+
+	// the store is allowed explicitly
+	model := s.makeUC20Model(c, map[string]interface{}{
+		"sideload-authority": []interface{}{"can0nical"},
+	})
+	si, err = snapasserts.DeriveSideInfoForSideload(fooSnap, model, s.localDB)
+	c.Assert(err, IsNil)
+	c.Check(si, DeepEquals, expectedSI)
+
+	// only the brand is allowed
+	model = s.makeUC20Model(c, map[string]interface{}{
+		"sideload-authority": []interface{}{s.dev1Acct.AccountID()},
+	})
+	_, err = snapasserts.DeriveSideInfoForSideload(fooSnap, model, s.localDB)
+	c.Check(err, ErrorMatches, fmt.Sprintf(`cannot sideload snap %q: snap-revision assertion is signed by "can0nical", not by an authority allowed by the model`, fooSnap))
+}
+
+func (s *snapassertsSuite) TestDeriveSideInfoForSideloadBuildProvenance(c *C) {
+	doc := []byte(`{"predicateType": "https://slsa.dev/provenance/v1"}`)
+	docDigest := sha3.Sum384(doc)
+	docSHA3_384, err := asserts.EncodeDigest(crypto.SHA3_384, docDigest[:])
+	c.Assert(err, IsNil)
+
+	addSnapRev := func(snapPath, revision string) {
+		digest, size, err := asserts.SnapFileSHA3_384(snapPath)
+		c.Assert(err, IsNil)
+		snapRev, err := s.storeSigning.Sign(asserts.SnapRevisionType, map[string]interface{}{
+			"snap-id":                   "snap-id-1",
+			"snap-sha3-384":             digest,
+			"snap-size":                 fmt.Sprintf("%d", size),
+			"snap-revision":             revision,
+			"developer-id":              s.dev1Acct.AccountID(),
+			"build-provenance-sha3-384": docSHA3_384,
+			"timestamp":                 time.Now().Format(time.RFC3339),
+		}, nil, "")
+		c.Assert(err, IsNil)
+		c.Assert(s.localDB.Add(snapRev), IsNil)
+	}
+
+	withDoc := snaptest.MakeTestSnapWithFiles(c, "name: foo\nversion: 1\n", [][]string{
+		{"meta/build-provenance.json", string(doc)},
+	})
+	addSnapRev(withDoc, "42")
+	si, err := snapasserts.DeriveSideInfoForSideload(withDoc, s.makeUC20Model(c, nil), s.localDB)
+	c.Assert(err, IsNil)
+	c.Check(si.Revision, Equals, snap.R(42))
+
+	withoutDoc := snaptest.MakeTestSnapWithFiles(c, "name: foo\nversion: 2\n", nil)
+	addSnapRev(withoutDoc, "43")
+	_, err = snapasserts.DeriveSideInfoForSideload(withoutDoc, s.makeUC20Model(c, nil), s.localDB)
+	c.Check(err, ErrorMatches, fmt.Sprintf(`snap %q does not contain the build provenance document referenced by its signatures`, withoutDoc))
+}
+
 func (s *snapassertsSuite) makeUC20Model(c *C, extraHeaders map[string]interface{}) *asserts.Model {
 	comps := map[string]interface{}{
 		"comp1": "required",
@@ -962,6 +1085,65 @@ version: 1.0.3
 	c.Assert(err, ErrorMatches, "snap-resource-revision assertion not found")
 }
 
+func (s *snapassertsSuite) TestDeriveComponentSideInfoForSideload(c *C) {
+	compPath := snaptest.MakeTestComponentWithFiles(c, "comp1", `component: snap+comp1
+type: standard
+version: 1.0.2
+`, nil)
+
+	info := snap.Info{
+		SideInfo: snap.SideInfo{
+			RealName: "snap",
+			SnapID:   "snap-id-1",
+			Revision: snap.R(1),
+		},
+	}
+
+	digest, size, err := asserts.SnapFileSHA3_384(compPath)
+	c.Assert(err, IsNil)
+
+	resRev, err := s.storeSigning.Sign(asserts.SnapResourceRevisionType, map[string]interface{}{
+		"type":              "snap-resource-revision",
+		"authority-id":      "can0nical",
+		"snap-id":           "snap-id-1",
+		"resource-name":     "comp1",
+		"resource-sha3-384": digest,
+		"developer-id":      s.dev1Acct.AccountID(),
+		"provenance":        "global-upload",
+		"resource-revision": "22",
+		"resource-size":     fmt.Sprintf("%d", size),
+		"timestamp":         time.Now().Format(time.RFC3339),
+	}, nil, "")
+	c.Assert(err, IsNil)
+	err = s.localDB.Add(resRev)
+	c.Assert(err, IsNil)
+
+	expectedCSI := &snap.ComponentSideInfo{
+		Component: naming.NewComponentRef("snap", "comp1"),
+		Revision:  snap.R(22),
+	}
+
+	// sideloading is not restricted
+	csi, err := snapasserts.DeriveComponentSideInfoForSideload("comp1", compPath, &info, s.makeUC20Model(c, nil), s.localDB)
+	c.Assert(err, IsNil)
+	c.Check(csi, DeepEquals, expectedCSI)
+
+	// the store is allowed explicitly
+	model := s.makeUC20Model(c, map[string]interface{}{
+		"sideload-authority": []interface{}{"can0nical"},
+	})
+	csi, err = snapasserts.DeriveComponentSideInfoForSideload("comp1", compPath, &info, model, s.localDB)
+	c.Assert(err, IsNil)
+	c.Check(csi, DeepEquals, expectedCSI)
+
+	// only the brand is allowed
+	model = s.makeUC20Model(c, map[string]interface{}{
+		"sideload-authority": []interface{}{s.dev1Acct.AccountID()},
+	})
+	_, err = snapasserts.DeriveComponentSideInfoForSideload("comp1", compPath, &info, model, s.localDB)
+	c.Check(err, ErrorMatches, fmt.Sprintf(`cannot sideload component %q: snap-resource-revision assertion is signed by "can0nical", not by an authority allowed by the model`, compPath))
+}
+
 func (s *snapassertsSuite) TestDeriveComponentSideInfoFromDigestAndSize(c *C) {
 	model := s.makeUC20Model(c, nil)
 
@@ -24,6 +24,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"strings"
 	"time"
 )
 
@@ -134,6 +135,15 @@ const (
 type ChangesOptions struct {
 	SnapName string // if empty, no filtering by name is done
 	Selector ChangeSelector
+
+	// Kinds and Statuses restrict the changes to the ones of the given
+	// kinds and statuses, if not empty.
+	Kinds    []string
+	Statuses []string
+	// Since and Until restrict the changes to the ones spawned in the
+	// given time range, if not zero.
+	Since time.Time
+	Until time.Time
 }
 
 func (client *Client) Changes(opts *ChangesOptions) ([]*Change, error) {
@@ -145,6 +155,18 @@ func (client *Client) Changes(opts *ChangesOptions) ([]*Change, error) {
 		if opts.SnapName != "" {
 			query.Set("for", opts.SnapName)
 		}
+		if len(opts.Kinds) != 0 {
+			query.Set("kind", strings.Join(opts.Kinds, ","))
+		}
+		if len(opts.Statuses) != 0 {
+			query.Set("status", strings.Join(opts.Statuses, ","))
+		}
+		if !opts.Since.IsZero() {
+			query.Set("since", opts.Since.Format(time.RFC3339))
+		}
+		if !opts.Until.IsZero() {
+			query.Set("until", opts.Until.Format(time.RFC3339))
+		}
 	}
 
 	var chgds []changeAndData
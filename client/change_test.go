@@ -21,6 +21,7 @@ package client_test
 
 import (
 	"io"
+	"net/url"
 	"time"
 
 	"gopkg.in/check.v1"
@@ -179,6 +180,26 @@ func (cs *clientSuite) TestClientChanges(c *check.C) {
 
 }
 
+func (cs *clientSuite) TestClientChangesFilters(c *check.C) {
+	cs.rsp = `{"type": "sync", "result": []}`
+
+	_, err := cs.cli.Changes(&client.ChangesOptions{
+		Selector: client.ChangesAll,
+		Kinds:    []string{"install", "refresh"},
+		Statuses: []string{"Error"},
+		Since:    time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+		Until:    time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC),
+	})
+	c.Assert(err, check.IsNil)
+	c.Check(cs.req.URL.Query(), check.DeepEquals, url.Values{
+		"select": {"all"},
+		"kind":   {"install,refresh"},
+		"status": {"Error"},
+		"since":  {"2024-03-01T10:00:00Z"},
+		"until":  {"2024-03-02T10:00:00Z"},
+	})
+}
+
 func (cs *clientSuite) TestClientChangesData(c *check.C) {
 	cs.rsp = `{"type": "sync", "result": [{
   "id":   "uno",
@@ -77,6 +77,11 @@ type Config struct {
 
 	// User-Agent to sent to the snapd daemon
 	UserAgent string
+
+	// ConflictRetryTimeout is how long operations that conflict with an
+	// ongoing change are retried, waiting in between as suggested by snapd.
+	// Operations are not retried if it is zero.
+	ConflictRetryTimeout time.Duration
 }
 
 // A Client knows how to talk to the snappy daemon.
@@ -94,6 +99,8 @@ type Client struct {
 
 	userAgent string
 
+	conflictRetryTimeout time.Duration
+
 	// SetMayLogBody controls whether a request or response's body may be logged
 	// if the appropriate environment variable is set
 	SetMayLogBody func(bool)
@@ -137,6 +144,9 @@ func New(config *Config) *Client {
 		disableAuth: config.DisableAuth,
 		interactive: config.Interactive,
 		userAgent:   config.UserAgent,
+
+		conflictRetryTimeout: config.ConflictRetryTimeout,
+
 		SetMayLogBody: func(logBody bool) {
 			transport.MayLogBody = logBody
 		},
@@ -532,7 +542,40 @@ func (client *Client) doAsync(method, path string, query url.Values, headers map
 	return
 }
 
+// timeSleep can be mocked in tests
+var timeSleep = time.Sleep
+
 func (client *Client) doAsyncFull(method, path string, query url.Values, headers map[string]string, body io.Reader, opts *doOptions) (result json.RawMessage, changeID string, err error) {
+	deadline := time.Now().Add(client.conflictRetryTimeout)
+	if buf, ok := body.(*bytes.Buffer); ok && client.conflictRetryTimeout > 0 {
+		// a buffer is drained when sent, keep its content around so
+		// that it can be sent again
+		body = bytes.NewReader(buf.Bytes())
+	}
+	for {
+		result, changeID, err = client.doAsyncOnce(method, path, query, headers, body, opts)
+		if client.conflictRetryTimeout <= 0 || !IsRetryable(err) {
+			return result, changeID, err
+		}
+		delay := RetryAfter(err)
+		if delay <= 0 || time.Now().Add(delay).After(deadline) {
+			return result, changeID, err
+		}
+		// the body needs to be sent again
+		if body != nil {
+			seeker, ok := body.(io.Seeker)
+			if !ok {
+				return result, changeID, err
+			}
+			if _, serr := seeker.Seek(0, io.SeekStart); serr != nil {
+				return result, changeID, err
+			}
+		}
+		timeSleep(delay)
+	}
+}
+
+func (client *Client) doAsyncOnce(method, path string, query url.Values, headers map[string]string, body io.Reader, opts *doOptions) (result json.RawMessage, changeID string, err error) {
 	var rsp response
 	statusCode, err := client.do(method, path, query, headers, body, &rsp, opts)
 	if err != nil {
@@ -623,6 +666,24 @@ func IsRetryable(err error) bool {
 	return false
 }
 
+// RetryAfter returns how long snapd suggested to wait before retrying an
+// operation that failed with the given error, or zero if it did not.
+func RetryAfter(err error) time.Duration {
+	e, ok := err.(*Error)
+	if !ok || e.Kind != ErrorKindSnapChangeConflict {
+		return 0
+	}
+	value, ok := e.Value.(map[string]interface{})
+	if !ok {
+		return 0
+	}
+	secs, ok := value["retry-after"].(float64)
+	if !ok {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
+
 // IsTwoFactorError returns whether the given error is due to problems
 // in two-factor authentication.
 func IsTwoFactorError(err error) bool {
@@ -802,6 +863,27 @@ func (c *Client) MigrateSnapHome(snaps []string) (changeID string, err error) {
 	return c.doAsync("POST", "/v2/debug", nil, nil, bytes.NewReader(body))
 }
 
+// ConfineDebug enables verbose logging of snap-confine and snap-update-ns for
+// the next given number of invocations of the snaps. Zero invocations disables
+// it again.
+func (c *Client) ConfineDebug(snaps []string, invocations int) error {
+	body, err := json.Marshal(struct {
+		Action string         `json:"action"`
+		Snaps  []string       `json:"snaps"`
+		Params map[string]int `json:"params"`
+	}{
+		Action: "confine-debug",
+		Snaps:  snaps,
+		Params: map[string]int{"invocations": invocations},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = c.doSync("POST", "/v2/debug", nil, nil, bytes.NewReader(body), nil)
+	return err
+}
+
 // DebugRaw allows to make raw queries to the API with the intention of using it
 // from the debug code.
 func (client *Client) DebugRaw(ctx context.Context, method, urlpath string, query url.Values, headers map[string]string, body io.Reader) (*http.Response, error) {
@@ -596,6 +596,99 @@ func (cs *clientSuite) TestIsRetryable(c *C) {
 	c.Check(client.IsRetryable(&client.Error{Kind: client.ErrorKindSnapChangeConflict}), Equals, true)
 }
 
+func (cs *clientSuite) TestRetryAfter(c *C) {
+	c.Check(client.RetryAfter(nil), Equals, time.Duration(0))
+	c.Check(client.RetryAfter(errors.New("some-error")), Equals, time.Duration(0))
+	c.Check(client.RetryAfter(&client.Error{Kind: client.ErrorKindSnapChangeConflict}), Equals, time.Duration(0))
+	c.Check(client.RetryAfter(&client.Error{
+		Kind:  client.ErrorKindSnapChangeConflict,
+		Value: map[string]interface{}{"retry-after": 12.},
+	}), Equals, 12*time.Second)
+}
+
+const conflictErrorResponse = `{
+	"type": "error",
+	"status-code": 409,
+	"result": {
+		"kind": "snap-change-conflict",
+		"message": "snap \"foo\" has \"install\" change in progress",
+		"value": {"snap-name": "foo", "change-kind": "install", "change-id": "1", "retry-after": 5}
+	}
+}`
+
+func (cs *clientSuite) TestConflictRetry(c *C) {
+	var slept []time.Duration
+	restore := client.MockTimeSleep(func(d time.Duration) {
+		slept = append(slept, d)
+	})
+	defer restore()
+
+	cli := client.New(&client.Config{ConflictRetryTimeout: time.Minute})
+	cli.SetDoer(cs)
+
+	cs.status = 202
+	cs.rsps = []string{
+		conflictErrorResponse,
+		conflictErrorResponse,
+		`{"type": "async", "status-code": 202, "result": {}, "change": "42"}`,
+	}
+	id, err := cli.SetConf("foo", map[string]interface{}{"key": "value"})
+	c.Assert(err, IsNil)
+	c.Check(id, Equals, "42")
+	c.Check(slept, DeepEquals, []time.Duration{5 * time.Second, 5 * time.Second})
+	c.Assert(cs.reqs, HasLen, 3)
+	// the body is sent again
+	body, err := io.ReadAll(cs.reqs[2].Body)
+	c.Assert(err, IsNil)
+	c.Check(string(body), Equals, `{"key":"value"}`)
+}
+
+func (cs *clientSuite) TestConflictRetrySnapAction(c *C) {
+	restore := client.MockTimeSleep(func(d time.Duration) {})
+	defer restore()
+
+	cli := client.New(&client.Config{ConflictRetryTimeout: time.Minute})
+	cli.SetDoer(cs)
+
+	cs.status = 202
+	cs.rsps = []string{
+		conflictErrorResponse,
+		`{"type": "async", "status-code": 202, "result": {}, "change": "42"}`,
+	}
+	id, err := cli.Remove("foo", nil, nil)
+	c.Assert(err, IsNil)
+	c.Check(id, Equals, "42")
+	c.Assert(cs.reqs, HasLen, 2)
+	// the buffered body is sent again
+	body, err := io.ReadAll(cs.reqs[1].Body)
+	c.Assert(err, IsNil)
+	c.Check(string(body), Equals, `{"action":"remove"}`)
+}
+
+func (cs *clientSuite) TestConflictRetryTimeout(c *C) {
+	var slept []time.Duration
+	restore := client.MockTimeSleep(func(d time.Duration) {
+		slept = append(slept, d)
+	})
+	defer restore()
+
+	cs.status = 202
+	cs.rsp = conflictErrorResponse
+
+	// retrying is disabled by default
+	_, err := cs.cli.SetConf("foo", map[string]interface{}{"key": "value"})
+	c.Check(client.IsRetryable(err), Equals, true)
+	c.Check(cs.doCalls, Equals, 1)
+
+	// the suggested delay goes past the timeout
+	cli := client.New(&client.Config{ConflictRetryTimeout: time.Second})
+	cli.SetDoer(cs)
+	_, err = cli.SetConf("foo", map[string]interface{}{"key": "value"})
+	c.Check(err, ErrorMatches, `snap "foo" has "install" change in progress`)
+	c.Check(cs.doCalls, Equals, 2)
+	c.Check(slept, HasLen, 0)
+}
+
 func (cs *clientSuite) TestUserAgent(c *C) {
 	cli := client.New(&client.Config{UserAgent: "some-agent/9.87"})
 	cli.SetDoer(cs)
@@ -663,6 +756,20 @@ func (cs *clientSuite) TestDebugMigrateHome(c *C) {
 	c.Check(string(data), Equals, `{"action":"migrate-home","snaps":["foo","bar"]}`)
 }
 
+func (cs *clientSuite) TestDebugConfineDebug(c *C) {
+	cs.rsp = `{"type": "sync", "result": true}`
+
+	err := cs.cli.ConfineDebug([]string{"foo", "bar"}, 3)
+	c.Check(err, IsNil)
+
+	c.Check(cs.reqs, HasLen, 1)
+	c.Check(cs.reqs[0].Method, Equals, "POST")
+	c.Check(cs.reqs[0].URL.Path, Equals, "/v2/debug")
+	data, err := io.ReadAll(cs.reqs[0].Body)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, `{"action":"confine-debug","snaps":["foo","bar"],"params":{"invocations":3}}`)
+}
+
 type integrationSuite struct{}
 
 var _ = Suite(&integrationSuite{})
@@ -65,6 +65,7 @@ func ClientSnapFromSnapInfo(snapInfo *snap.Info, decorator StatusDecorator) (*cl
 		InstallDate: snapInfo.InstallDate(),
 		Name:        snapInfo.InstanceName(),
 		Revision:    snapInfo.Revision,
+		Epoch:       snapInfo.Epoch,
 		Summary:     snapInfo.Summary(),
 		Type:        string(snapInfo.Type()),
 		Base:        snapInfo.Base,
@@ -80,6 +81,7 @@ func ClientSnapFromSnapInfo(snapInfo *snap.Info, decorator StatusDecorator) (*cl
 		Prices:      snapInfo.Prices,
 		Channels:    snapInfo.Channels,
 		Tracks:      snapInfo.Tracks,
+		TrackInfo:   snapInfo.TrackInfo,
 		CommonIDs:   snapInfo.CommonIDs,
 		Links:       snapInfo.Links(),
 		Contact:     snapInfo.Contact(),
@@ -62,7 +62,8 @@ func (*cmdSuite) TestClientSnapFromSnapInfo(c *C) {
 			DisplayName: "Thingy Inc.",
 			Validation:  "unproven",
 		},
-		Base: "core18",
+		Base:  "core18",
+		Epoch: snap.E("1*"),
 		SideInfo: snap.SideInfo{
 			RealName:          "the-snap",
 			SnapID:            "snapidid",
@@ -80,7 +81,10 @@ func (*cmdSuite) TestClientSnapFromSnapInfo(c *C) {
 		},
 		Channels: map[string]*snap.ChannelSnapInfo{},
 		Tracks:   []string{},
-		Prices:   map[string]float64{},
+		TrackInfo: map[string]*snap.TrackInfo{
+			"latest": {Name: "latest", Status: snap.TrackStatusDefault},
+		},
+		Prices: map[string]float64{},
 		Media: []snap.MediaInfo{
 			{Type: "icon", URL: "https://dashboard.snapcraft.io/site_media/appmedia/2017/12/Thingy.png"},
 			{Type: "screenshot", URL: "https://dashboard.snapcraft.io/site_media/appmedia/2018/01/Thingy_01.png"},
@@ -123,6 +127,9 @@ func (*cmdSuite) TestClientSnapFromSnapInfo(c *C) {
 		"RefreshInhibit",
 		"RefreshFailures",
 		"Components",
+		"TmpUsage",
+		"TmpSize",
+		"RefreshAvailable",
 	}
 	var checker func(string, reflect.Value)
 	checker = func(pfx string, x reflect.Value) {
@@ -152,6 +159,7 @@ func (*cmdSuite) TestClientSnapFromSnapInfo(c *C) {
 	c.Check(ci.Type, Equals, "app")
 	c.Check(ci.ID, Equals, si.ID())
 	c.Check(ci.Revision, Equals, snap.R(99))
+	c.Check(ci.Epoch, DeepEquals, snap.E("1*"))
 	c.Check(ci.Version, Equals, "v1")
 	c.Check(ci.Title, Equals, "the-title")
 	c.Check(ci.Summary, Equals, "the-summary")
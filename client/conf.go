@@ -24,6 +24,9 @@ import (
 	"encoding/json"
 	"net/url"
 	"strings"
+	"time"
+
+	"github.com/snapcore/snapd/snap"
 )
 
 // SetConf requests a snap to apply the provided patch to the configuration.
@@ -35,6 +38,14 @@ func (client *Client) SetConf(snapName string, patch map[string]interface{}) (ch
 	return client.doAsync("PUT", "/v2/snaps/"+snapName+"/conf", nil, nil, bytes.NewReader(b))
 }
 
+// RestoreConf requests a snap to set the options modified by the given change
+// back to the values they had before it.
+func (client *Client) RestoreConf(snapName, changeID string) (string, error) {
+	query := url.Values{}
+	query.Set("restore", changeID)
+	return client.doAsync("PUT", "/v2/snaps/"+snapName+"/conf", query, nil, nil)
+}
+
 // Conf asks for a snap's current configuration.
 //
 // Note that the configuration may include json.Numbers.
@@ -50,3 +61,107 @@ func (client *Client) Conf(snapName string, keys []string) (configuration map[st
 
 	return configuration, nil
 }
+
+// ConfChange describes an option whose value differs between the
+// configuration of a snap before its last refresh and its current one.
+type ConfChange struct {
+	Key string      `json:"key"`
+	Old interface{} `json:"old,omitempty"`
+	New interface{} `json:"new,omitempty"`
+}
+
+// ConfDiff holds the changes to the configuration of a snap since the
+// previous revision of the snap was replaced.
+type ConfDiff struct {
+	Revision snap.Revision `json:"revision"`
+	Changes  []ConfChange  `json:"changes"`
+}
+
+// ConfDiff asks for the changes to a snap's configuration since its last
+// refresh.
+//
+// Note that the values may include json.Numbers.
+func (client *Client) ConfDiff(snapName string) (*ConfDiff, error) {
+	query := url.Values{}
+	query.Set("diff", "true")
+
+	var diff ConfDiff
+	if _, err := client.doSync("GET", "/v2/snaps/"+snapName+"/conf", query, nil, nil, &diff); err != nil {
+		return nil, err
+	}
+	return &diff, nil
+}
+
+// ConfHistoryEntry holds the changes made to the configuration of a snap by
+// a change, or by snapctl outside of a change.
+type ConfHistoryEntry struct {
+	Change  string       `json:"change,omitempty"`
+	Actor   string       `json:"actor"`
+	Time    time.Time    `json:"time"`
+	Changes []ConfChange `json:"changes"`
+}
+
+// ConfHistory asks for the recorded changes to a snap's configuration,
+// oldest first.
+//
+// Note that the values may include json.Numbers.
+func (client *Client) ConfHistory(snapName string) ([]ConfHistoryEntry, error) {
+	query := url.Values{}
+	query.Set("history", "true")
+
+	var history []ConfHistoryEntry
+	if _, err := client.doSync("GET", "/v2/snaps/"+snapName+"/conf", query, nil, nil, &history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// ConfValue holds the value of a configuration option of a snap, its default
+// and the origin of the value: "explicit", "default" or "unset".
+type ConfValue struct {
+	Key     string      `json:"key"`
+	Value   interface{} `json:"value,omitempty"`
+	Default interface{} `json:"default,omitempty"`
+	Origin  string      `json:"origin"`
+}
+
+// ConfWithDefaults asks for all the configuration options of a snap,
+// including the ones only set by the gadget defaults.
+//
+// Note that the values may include json.Numbers.
+func (client *Client) ConfWithDefaults(snapName string) ([]ConfValue, error) {
+	query := url.Values{}
+	query.Set("defaults", "true")
+
+	var values []ConfValue
+	if _, err := client.doSync("GET", "/v2/snaps/"+snapName+"/conf", query, nil, nil, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// SetUserConf applies the provided patch to the configuration of a snap for
+// the calling user. The configuration is changed right away, without
+// running the configure hook of the snap.
+func (client *Client) SetUserConf(snapName string, patch map[string]interface{}) error {
+	b, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	_, err = client.doSync("PUT", "/v2/snaps/"+snapName+"/user-conf", nil, nil, bytes.NewReader(b), nil)
+	return err
+}
+
+// UserConf asks for the configuration of a snap for the calling user.
+//
+// Note that the configuration may include json.Numbers.
+func (client *Client) UserConf(snapName string, keys []string) (configuration map[string]interface{}, err error) {
+	query := url.Values{}
+	query.Set("keys", strings.Join(keys, ","))
+
+	_, err = client.doSync("GET", "/v2/snaps/"+snapName+"/user-conf", query, nil, nil, &configuration)
+	if err != nil {
+		return nil, err
+	}
+	return configuration, nil
+}
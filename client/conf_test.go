@@ -21,8 +21,12 @@ package client_test
 
 import (
 	"encoding/json"
+	"time"
 
 	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/snap"
 )
 
 func (cs *clientSuite) TestClientSetConfCallsEndpoint(c *check.C) {
@@ -65,6 +69,22 @@ func (cs *clientSuite) TestClientSetConf(c *check.C) {
 	})
 }
 
+func (cs *clientSuite) TestClientRestoreConf(c *check.C) {
+	cs.status = 202
+	cs.rsp = `{
+		"type": "async",
+		"status-code": 202,
+		"result": { },
+		"change": "foo"
+	}`
+	id, err := cs.cli.RestoreConf("snap-name", "42")
+	c.Assert(err, check.IsNil)
+	c.Check(id, check.Equals, "foo")
+	c.Check(cs.req.Method, check.Equals, "PUT")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/snaps/snap-name/conf")
+	c.Check(cs.req.URL.Query().Get("restore"), check.Equals, "42")
+}
+
 func (cs *clientSuite) TestClientGetConf(c *check.C) {
 	cs.rsp = `{
 		"type": "sync",
@@ -103,3 +123,120 @@ func (cs *clientSuite) TestClientGetConfMultipleKeys(c *check.C) {
 		"test-key2": "test-value2",
 	})
 }
+
+func (cs *clientSuite) TestClientConfDiff(c *check.C) {
+	cs.rsp = `{
+		"type": "sync",
+		"status-code": 200,
+		"result": {
+			"revision": "1",
+			"changes": [
+				{"key": "test-key1", "old": "test-value1", "new": 2},
+				{"key": "test-key2", "new": "test-value2"}
+			]
+		}
+	}`
+	diff, err := cs.cli.ConfDiff("snap-name")
+	c.Assert(err, check.IsNil)
+	c.Check(cs.req.Method, check.Equals, "GET")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/snaps/snap-name/conf")
+	c.Check(cs.req.URL.Query().Get("diff"), check.Equals, "true")
+	c.Check(diff, check.DeepEquals, &client.ConfDiff{
+		Revision: snap.R(1),
+		Changes: []client.ConfChange{
+			{Key: "test-key1", Old: "test-value1", New: json.Number("2")},
+			{Key: "test-key2", New: "test-value2"},
+		},
+	})
+}
+
+func (cs *clientSuite) TestClientConfWithDefaults(c *check.C) {
+	cs.rsp = `{
+		"type": "sync",
+		"status-code": 200,
+		"result": [
+			{"key": "service.rsyslog.disable", "value": true, "default": true, "origin": "default"},
+			{"key": "watchdog.runtime-timeout", "value": "10m", "default": "5m", "origin": "explicit"},
+			{"key": "system.power-key-action", "default": "ignore", "origin": "unset"},
+			{"key": "refresh.timer", "value": "fri", "origin": "explicit"}
+		]
+	}`
+	values, err := cs.cli.ConfWithDefaults("system")
+	c.Assert(err, check.IsNil)
+	c.Check(cs.req.Method, check.Equals, "GET")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/snaps/system/conf")
+	c.Check(cs.req.URL.Query().Get("defaults"), check.Equals, "true")
+	c.Check(values, check.DeepEquals, []client.ConfValue{
+		{Key: "service.rsyslog.disable", Value: true, Default: true, Origin: "default"},
+		{Key: "watchdog.runtime-timeout", Value: "10m", Default: "5m", Origin: "explicit"},
+		{Key: "system.power-key-action", Default: "ignore", Origin: "unset"},
+		{Key: "refresh.timer", Value: "fri", Origin: "explicit"},
+	})
+}
+
+func (cs *clientSuite) TestClientConfHistory(c *check.C) {
+	cs.rsp = `{
+		"type": "sync",
+		"status-code": 200,
+		"result": [
+			{"change": "42", "actor": "configure-snap", "time": "2024-01-02T03:04:05Z", "changes": [
+				{"key": "test-key1", "old": "test-value1", "new": 2},
+				{"key": "test-key2", "new": "test-value2"}
+			]},
+			{"actor": "snapctl", "time": "2024-01-03T03:04:05Z", "changes": [
+				{"key": "test-key2", "old": "test-value2"}
+			]}
+		]
+	}`
+	history, err := cs.cli.ConfHistory("snap-name")
+	c.Assert(err, check.IsNil)
+	c.Check(cs.req.Method, check.Equals, "GET")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/snaps/snap-name/conf")
+	c.Check(cs.req.URL.Query().Get("history"), check.Equals, "true")
+	c.Check(history, check.DeepEquals, []client.ConfHistoryEntry{{
+		Change: "42",
+		Actor:  "configure-snap",
+		Time:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Changes: []client.ConfChange{
+			{Key: "test-key1", Old: "test-value1", New: json.Number("2")},
+			{Key: "test-key2", New: "test-value2"},
+		},
+	}, {
+		Actor: "snapctl",
+		Time:  time.Date(2024, 1, 3, 3, 4, 5, 0, time.UTC),
+		Changes: []client.ConfChange{
+			{Key: "test-key2", Old: "test-value2"},
+		},
+	}})
+}
+
+func (cs *clientSuite) TestClientSetUserConf(c *check.C) {
+	cs.rsp = `{
+		"type": "sync",
+		"status-code": 200,
+		"result": null
+	}`
+	err := cs.cli.SetUserConf("snap-name", map[string]interface{}{"key": "value"})
+	c.Assert(err, check.IsNil)
+	c.Check(cs.req.Method, check.Equals, "PUT")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/snaps/snap-name/user-conf")
+	var body map[string]interface{}
+	c.Assert(json.NewDecoder(cs.req.Body).Decode(&body), check.IsNil)
+	c.Check(body, check.DeepEquals, map[string]interface{}{
+		"key": "value",
+	})
+}
+
+func (cs *clientSuite) TestClientUserConf(c *check.C) {
+	cs.rsp = `{
+		"type": "sync",
+		"status-code": 200,
+		"result": {"test-key": "test-value", "number": 42}
+	}`
+	value, err := cs.cli.UserConf("snap-name", []string{"test-key", "number"})
+	c.Assert(err, check.IsNil)
+	c.Check(cs.req.Method, check.Equals, "GET")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/snaps/snap-name/user-conf")
+	c.Check(cs.req.URL.Query().Get("keys"), check.Equals, "test-key,number")
+	c.Check(value, check.DeepEquals, map[string]interface{}{"test-key": "test-value", "number": json.Number("42")})
+}
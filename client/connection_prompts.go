@@ -0,0 +1,83 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"time"
+)
+
+// ConnectionPrompt describes a plug which was not auto-connected and for
+// which the user is asked whether it should be connected.
+type ConnectionPrompt struct {
+	ID        string    `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	UserID    uint32    `json:"user-id"`
+	Interface string    `json:"interface"`
+	Plug      PlugRef   `json:"plug"`
+	Slot      SlotRef   `json:"slot"`
+}
+
+// ConnectionPromptReply holds the reply to a connection prompt. Action is
+// either "allow" or "deny", while Lifespan is either "single" (the default)
+// or "forever", in which case the reply is remembered for the plug.
+type ConnectionPromptReply struct {
+	Action   string `json:"action"`
+	Lifespan string `json:"lifespan,omitempty"`
+}
+
+// ConnectionPrompts returns the pending connection prompts.
+func (client *Client) ConnectionPrompts() ([]*ConnectionPrompt, error) {
+	var prompts []*ConnectionPrompt
+	_, err := client.doSync("GET", "/v2/prompts", nil, nil, nil, &prompts)
+	return prompts, err
+}
+
+// ConnectionPrompt returns the pending connection prompt with the given ID.
+func (client *Client) ConnectionPrompt(id string) (*ConnectionPrompt, error) {
+	var prompt ConnectionPrompt
+	if _, err := client.doSync("GET", "/v2/prompts/"+url.PathEscape(id), nil, nil, nil, &prompt); err != nil {
+		return nil, err
+	}
+	return &prompt, nil
+}
+
+// ReplyToConnectionPrompt replies to the connection prompt with the given
+// ID. If the reply results in the plug being connected, the ID of the
+// change performing the connection is returned.
+func (client *Client) ReplyToConnectionPrompt(id string, reply *ConnectionPromptReply) (changeID string, err error) {
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(reply); err != nil {
+		return "", err
+	}
+	headers := map[string]string{"Content-Type": "application/json"}
+
+	var rsp response
+	statusCode, err := client.do("POST", "/v2/prompts/"+url.PathEscape(id), nil, headers, &body, &rsp, nil)
+	if err != nil {
+		return "", err
+	}
+	if err := rsp.err(client, statusCode); err != nil {
+		return "", err
+	}
+	return rsp.Change, nil
+}
@@ -0,0 +1,118 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package client_test
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/client"
+)
+
+func (cs *clientSuite) TestClientConnectionPrompts(c *C) {
+	cs.rsp = `{
+		"type": "sync",
+		"result": [
+			{
+				"id": "1",
+				"timestamp": "2024-08-01T10:00:00Z",
+				"interface": "camera",
+				"plug": {"snap": "consumer", "plug": "camera"},
+				"slot": {"snap": "core", "slot": "camera"}
+			}
+		]
+	}`
+	prompts, err := cs.cli.ConnectionPrompts()
+	c.Assert(err, IsNil)
+	c.Check(cs.req.Method, Equals, "GET")
+	c.Check(cs.req.URL.Path, Equals, "/v2/prompts")
+	c.Check(prompts, DeepEquals, []*client.ConnectionPrompt{{
+		ID:        "1",
+		Timestamp: time.Date(2024, 8, 1, 10, 0, 0, 0, time.UTC),
+		Interface: "camera",
+		Plug:      client.PlugRef{Snap: "consumer", Name: "camera"},
+		Slot:      client.SlotRef{Snap: "core", Name: "camera"},
+	}})
+}
+
+func (cs *clientSuite) TestClientConnectionPrompt(c *C) {
+	cs.rsp = `{
+		"type": "sync",
+		"result": {
+			"id": "2",
+			"timestamp": "2024-08-01T10:00:00Z",
+			"interface": "camera",
+			"plug": {"snap": "consumer", "plug": "camera"},
+			"slot": {"snap": "core", "slot": "camera"}
+		}
+	}`
+	prompt, err := cs.cli.ConnectionPrompt("2")
+	c.Assert(err, IsNil)
+	c.Check(cs.req.Method, Equals, "GET")
+	c.Check(cs.req.URL.Path, Equals, "/v2/prompts/2")
+	c.Check(prompt.ID, Equals, "2")
+	c.Check(prompt.Plug, Equals, client.PlugRef{Snap: "consumer", Name: "camera"})
+}
+
+func (cs *clientSuite) TestClientReplyToConnectionPromptAllow(c *C) {
+	cs.status = 202
+	cs.rsp = `{
+		"type": "async",
+		"status-code": 202,
+		"change": "42"
+	}`
+	chgID, err := cs.cli.ReplyToConnectionPrompt("1", &client.ConnectionPromptReply{Action: "allow", Lifespan: "forever"})
+	c.Assert(err, IsNil)
+	c.Check(chgID, Equals, "42")
+	c.Check(cs.req.Method, Equals, "POST")
+	c.Check(cs.req.URL.Path, Equals, "/v2/prompts/1")
+
+	body, err := io.ReadAll(cs.req.Body)
+	c.Assert(err, IsNil)
+	var jsonBody map[string]interface{}
+	c.Assert(json.Unmarshal(body, &jsonBody), IsNil)
+	c.Check(jsonBody, DeepEquals, map[string]interface{}{
+		"action":   "allow",
+		"lifespan": "forever",
+	})
+}
+
+func (cs *clientSuite) TestClientReplyToConnectionPromptDeny(c *C) {
+	cs.rsp = `{
+		"type": "sync",
+		"result": null
+	}`
+	chgID, err := cs.cli.ReplyToConnectionPrompt("1", &client.ConnectionPromptReply{Action: "deny"})
+	c.Assert(err, IsNil)
+	c.Check(chgID, Equals, "")
+}
+
+func (cs *clientSuite) TestClientReplyToConnectionPromptError(c *C) {
+	cs.status = 404
+	cs.rsp = `{
+		"type": "error",
+		"result": {"message": "cannot find connection prompt \"1\""}
+	}`
+	_, err := cs.cli.ReplyToConnectionPrompt("1", &client.ConnectionPromptReply{Action: "deny"})
+	c.Check(err, ErrorMatches, `cannot find connection prompt "1"`)
+}
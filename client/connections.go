@@ -20,6 +20,7 @@
 package client
 
 import (
+	"fmt"
 	"net/url"
 )
 
@@ -60,6 +61,10 @@ type ConnectionOptions struct {
 	// All when true, selects established and undesired connections as well
 	// as all disconnected plugs and slots.
 	All bool
+	// Disconnected when true, selects only undesired connections and the
+	// plugs and slots that are not connected. It cannot be used together
+	// with All.
+	Disconnected bool
 }
 
 // Connections returns matching plugs, slots and their connections. Unless
@@ -73,9 +78,15 @@ func (client *Client) Connections(opts *ConnectionOptions) (Connections, error)
 	if opts != nil && opts.Interface != "" {
 		query.Set("interface", opts.Interface)
 	}
+	if opts != nil && opts.All && opts.Disconnected {
+		return conns, fmt.Errorf("cannot select all and only disconnected connections at the same time")
+	}
 	if opts != nil && opts.All {
 		query.Set("select", "all")
 	}
+	if opts != nil && opts.Disconnected {
+		query.Set("select", "disconnected")
+	}
 	_, err := client.doSync("GET", "/v2/connections", query, nil, nil, &conns)
 	return conns, err
 }
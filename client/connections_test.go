@@ -267,4 +267,14 @@ func (cs *clientSuite) TestClientConnectionsFilter(c *check.C) {
 		"interface": []string{"test"},
 		"snap":      []string{"foo"},
 	})
+
+	_, err = cs.cli.Connections(&client.ConnectionOptions{Disconnected: true, Interface: "test"})
+	c.Assert(err, check.IsNil)
+	c.Check(cs.req.URL.Query(), check.DeepEquals, url.Values{
+		"select":    []string{"disconnected"},
+		"interface": []string{"test"},
+	})
+
+	_, err = cs.cli.Connections(&client.ConnectionOptions{All: true, Disconnected: true})
+	c.Assert(err, check.ErrorMatches, "cannot select all and only disconnected connections at the same time")
 }
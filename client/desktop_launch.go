@@ -0,0 +1,40 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// VerifyDesktopLaunch asks snapd to verify the launch token of a launch of
+// the given snap application from a desktop file generated by snapd.
+func (client *Client) VerifyDesktopLaunch(desktopFile, snapApp, token string) error {
+	body, err := json.Marshal(map[string]string{
+		"desktop-file": desktopFile,
+		"snap-app":     snapApp,
+		"token":        token,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = client.doSync("POST", "/v2/desktop-launch", nil, nil, bytes.NewReader(body), nil)
+	return err
+}
@@ -0,0 +1,54 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package client_test
+
+import (
+	"encoding/json"
+	"io"
+
+	"gopkg.in/check.v1"
+)
+
+func (cs *clientSuite) TestVerifyDesktopLaunch(c *check.C) {
+	cs.rsp = `{"type": "sync", "status-code": 200, "result": null}`
+
+	err := cs.cli.VerifyDesktopLaunch("/foo_app.desktop", "foo.app", "token")
+	c.Assert(err, check.IsNil)
+	c.Check(cs.req.Method, check.Equals, "POST")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/desktop-launch")
+
+	data, err := io.ReadAll(cs.req.Body)
+	c.Assert(err, check.IsNil)
+	var body map[string]string
+	c.Assert(json.Unmarshal(data, &body), check.IsNil)
+	c.Check(body, check.DeepEquals, map[string]string{
+		"desktop-file": "/foo_app.desktop",
+		"snap-app":     "foo.app",
+		"token":        "token",
+	})
+}
+
+func (cs *clientSuite) TestVerifyDesktopLaunchInvalid(c *check.C) {
+	cs.status = 400
+	cs.rsp = `{"type": "error", "status-code": 400, "result": {"message": "invalid launch token for desktop file \"/foo_app.desktop\""}}`
+
+	err := cs.cli.VerifyDesktopLaunch("/foo_app.desktop", "foo.app", "token")
+	c.Check(err, check.ErrorMatches, `invalid launch token for desktop file "/foo_app.desktop"`)
+}
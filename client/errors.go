@@ -101,7 +101,9 @@ const (
 	// ErrorKindSnapChangeConflict: the requested operation would
 	// conflict with currently ongoing change. This is a temporary
 	// error. The error `value` is an object with optional fields
-	// `snap-name`, `change-kind` of the ongoing change.
+	// `snap-name`, `change-kind`, `change-id` of the ongoing change
+	// and `retry-after`, the number of seconds after which the
+	// operation is expected to succeed.
 	ErrorKindSnapChangeConflict ErrorKind = "snap-change-conflict"
 
 	// ErrorKindQuotaChangeConflict: the requested operation would
@@ -119,6 +121,11 @@ const (
 	// operation would have no effect.
 	ErrorKindInterfacesUnchanged ErrorKind = "interfaces-unchanged"
 
+	// ErrorKindInterfacesDenied: the interface cannot be connected
+	// as it is denied with the interfaces.deny system option. The
+	// error `value` is the name of the interface.
+	ErrorKindInterfacesDenied ErrorKind = "interfaces-denied"
+
 	// ErrorKindBadQuery: a bad query was provided.
 	ErrorKindBadQuery ErrorKind = "bad-query"
 	// ErrorKindConfigNoSuchOption: the given configuration option
@@ -161,6 +168,11 @@ const (
 	// ErrorKindInterfacesRequestsRuleConflict: a rule with conflicting path pattern and permissions already exists.
 	ErrorKindInterfacesRequestsRuleConflict ErrorKind = "interfaces-requests-rule-conflict"
 
+	// ErrorKindPrerequisitesConfirmationRequired: the requested
+	// operation would install prerequisites, which the system is
+	// configured to require a confirmation for.
+	ErrorKindPrerequisitesConfirmationRequired ErrorKind = "prerequisites-confirmation-required"
+
 	// ErrorKindInterfacesRequestsRuleConflict: cannot find a snap-resource-pair when attempting to sideload a component
 	ErrorKindMissingSnapResourcePair ErrorKind = "missing-snap-resource-pair"
 )
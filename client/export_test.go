@@ -23,6 +23,7 @@ import (
 	"encoding/json"
 	"io"
 	"net/url"
+	"time"
 )
 
 // SetDoer sets the client's doer to the given one
@@ -61,3 +62,11 @@ func MockStdinReadLimit(new int64) (restore func()) {
 		stdinReadLimit = oldStdinReadLimit
 	}
 }
+
+func MockTimeSleep(f func(time.Duration)) (restore func()) {
+	old := timeSleep
+	timeSleep = f
+	return func() {
+		timeSleep = old
+	}
+}
@@ -51,6 +51,7 @@ type Slot struct {
 	Attrs       map[string]interface{} `json:"attrs,omitempty"`
 	Apps        []string               `json:"apps,omitempty"`
 	Label       string                 `json:"label,omitempty"`
+	Hotplug     bool                   `json:"hotplug,omitempty"`
 	Connections []PlugRef              `json:"connections,omitempty"`
 }
 
@@ -54,6 +54,7 @@ type Snap struct {
 	TrackingChannel  string        `json:"tracking-channel,omitempty"`
 	IgnoreValidation bool          `json:"ignore-validation"`
 	Revision         snap.Revision `json:"revision"`
+	Epoch            snap.Epoch    `json:"epoch"`
 	Confinement      string        `json:"confinement"`
 	Private          bool          `json:"private"`
 	DevMode          bool          `json:"devmode"`
@@ -83,6 +84,9 @@ type Snap struct {
 	// The ordered list of tracks that contains channels
 	Tracks []string `json:"tracks,omitempty"`
 
+	// The lifecycle of the tracks, by track name
+	TrackInfo map[string]*snap.TrackInfo `json:"track-info,omitempty"`
+
 	Health *SnapHealth `json:"health,omitempty"`
 
 	// Hold is the time until which the snap's refreshes are held by the user.
@@ -91,8 +95,19 @@ type Snap struct {
 	GatingHold *time.Time `json:"gating-hold,omitempty"`
 	// if RefreshInhibit is nil, then there is no pending refresh.
 	RefreshInhibit *SnapRefreshInhibit `json:"refresh-inhibit,omitempty"`
+	// RefreshAvailable is set if the last refresh check found an update
+	// for the snap.
+	RefreshAvailable *SnapRefreshAvailable `json:"refresh-available,omitempty"`
 	// RefreshFailures tracks information about snap failed refreshes.
 	RefreshFailures *snap.RefreshFailuresInfo `json:"refresh-failures,omitempty"`
+	// TmpUsage and TmpSize are the bytes used and available in the
+	// private /tmp of the snap, when it is backed by a tmpfs.
+	TmpUsage int64 `json:"tmp-usage,omitempty"`
+	TmpSize  int64 `json:"tmp-size,omitempty"`
+
+	// BuildProvenance is the build provenance document shipped in the snap,
+	// only set when asking about a single installed snap.
+	BuildProvenance string `json:"build-provenance,omitempty"`
 
 	// Components is a list of the snap components
 	Components []Component `json:"components,omitempty"`
@@ -115,6 +130,15 @@ type SnapRefreshInhibit struct {
 	ProceedTime time.Time `json:"proceed-time"`
 }
 
+// SnapRefreshAvailable holds the details of a pending refresh as found by
+// the last refresh check.
+type SnapRefreshAvailable struct {
+	Revision     snap.Revision `json:"revision"`
+	Version      string        `json:"version,omitempty"`
+	Channel      string        `json:"channel,omitempty"`
+	DownloadSize int64         `json:"download-size,omitempty"`
+}
+
 // Statuses and types a snap may have.
 const (
 	StatusAvailable = "available"
@@ -151,6 +175,11 @@ type FindOptions struct {
 	Category string
 	// Section is deprecated, use Category instead.
 	Section string
+	// Publisher restricts the results to snaps by the publisher with
+	// the given username.
+	Publisher string
+	// License restricts the results to snaps with the given license.
+	License string
 	Private bool
 	Scope   string
 
@@ -250,6 +279,12 @@ func (client *Client) Find(opts *FindOptions) ([]*Snap, *ResultInfo, error) {
 	if opts.Section != "" {
 		q.Set("section", opts.Section)
 	}
+	if opts.Publisher != "" {
+		q.Set("publisher", opts.Publisher)
+	}
+	if opts.License != "" {
+		q.Set("license", opts.License)
+	}
 	if opts.Scope != "" {
 		q.Set("scope", opts.Scope)
 	}
@@ -98,6 +98,21 @@ func (cs *clientSuite) TestClientFindWithCategorySetsQuery(c *check.C) {
 	})
 }
 
+func (cs *clientSuite) TestClientFindWithPublisherAndLicenseSetsQuery(c *check.C) {
+	_, _, _ = cs.cli.Find(&client.FindOptions{
+		Query:     "foo",
+		Publisher: "mypublisher",
+		License:   "MIT",
+	})
+	c.Check(cs.req.Method, check.Equals, "GET")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/find")
+	c.Check(cs.req.URL.Query(), check.DeepEquals, url.Values{
+		"q":         []string{"foo"},
+		"publisher": []string{"mypublisher"},
+		"license":   []string{"MIT"},
+	})
+}
+
 func (cs *clientSuite) TestClientFindPrivateSetsQuery(c *check.C) {
 	_, _, _ = cs.cli.Find(&client.FindOptions{
 		Private: true,
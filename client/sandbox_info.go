@@ -0,0 +1,82 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package client
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// SandboxInfo holds information about the sandbox of a snap, as seen by
+// the processes of that snap.
+type SandboxInfo struct {
+	// Snap is the instance name of the snap.
+	Snap string `json:"snap"`
+	// Connections lists the connections of the plugs and slots of the
+	// snap, including the ones which were disconnected.
+	Connections []SandboxConnection `json:"connections,omitempty"`
+	// DataDirs holds the data directories available to the snap.
+	DataDirs SandboxDataDirs `json:"data-dirs"`
+	// Quota is the quota group the snap belongs to, if any.
+	Quota *QuotaGroupResult `json:"quota,omitempty"`
+	// RefreshInhibit is set if a refresh of the snap is pending but
+	// inhibited by its running applications.
+	RefreshInhibit *SnapRefreshInhibit `json:"refresh-inhibit,omitempty"`
+}
+
+// SandboxConnection describes the state of a connection of a snap.
+type SandboxConnection struct {
+	Interface string  `json:"interface"`
+	Plug      PlugRef `json:"plug"`
+	Slot      SlotRef `json:"slot"`
+	// Connected is false if the connection was explicitly disconnected
+	// or if its hotplug slot is gone.
+	Connected bool `json:"connected"`
+	Manual    bool `json:"manual,omitempty"`
+	Gadget    bool `json:"gadget,omitempty"`
+}
+
+// SandboxDataDirs holds the data directories of a snap. The per-user
+// directories are those of the user making the request.
+type SandboxDataDirs struct {
+	Data       string `json:"data"`
+	Common     string `json:"common"`
+	UserData   string `json:"user-data,omitempty"`
+	UserCommon string `json:"user-common,omitempty"`
+}
+
+// SandboxInfo returns information about the sandbox of the given snap.
+//
+// When talking to snapd-snap.socket, only information about the snap of
+// the calling process can be retrieved.
+func (client *Client) SandboxInfo(snapName string) (*SandboxInfo, error) {
+	if snapName == "" {
+		return nil, fmt.Errorf("cannot get sandbox information without a snap name")
+	}
+
+	query := url.Values{}
+	query.Set("snap", snapName)
+
+	var info *SandboxInfo
+	if _, err := client.doSync("GET", "/v2/sandbox-info", query, nil, nil, &info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
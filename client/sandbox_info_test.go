@@ -0,0 +1,115 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package client_test
+
+import (
+	"time"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/gadget/quantity"
+)
+
+func (cs *clientSuite) TestSandboxInfoNoName(c *check.C) {
+	_, err := cs.cli.SandboxInfo("")
+	c.Check(err, check.ErrorMatches, `cannot get sandbox information without a snap name`)
+}
+
+func (cs *clientSuite) TestSandboxInfo(c *check.C) {
+	cs.rsp = `{
+		"type": "sync",
+		"status-code": 200,
+		"result": {
+			"snap": "foo",
+			"connections": [
+				{
+					"interface": "network",
+					"plug": {"snap": "foo", "plug": "network"},
+					"slot": {"snap": "snapd", "slot": "network"},
+					"connected": true
+				},
+				{
+					"interface": "home",
+					"plug": {"snap": "foo", "plug": "home"},
+					"slot": {"snap": "snapd", "slot": "home"},
+					"connected": false,
+					"manual": true
+				}
+			],
+			"data-dirs": {
+				"data": "/var/snap/foo/1",
+				"common": "/var/snap/foo/common",
+				"user-data": "/home/user/snap/foo/1",
+				"user-common": "/home/user/snap/foo/common"
+			},
+			"quota": {
+				"group-name": "grp",
+				"snaps": ["foo"],
+				"constraints": {"memory": 1000}
+			},
+			"refresh-inhibit": {"proceed-time": "2024-05-01T10:00:00Z"}
+		}
+	}`
+
+	info, err := cs.cli.SandboxInfo("foo")
+	c.Assert(err, check.IsNil)
+	c.Check(cs.req.Method, check.Equals, "GET")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/sandbox-info")
+	c.Check(cs.req.URL.Query().Get("snap"), check.Equals, "foo")
+	c.Check(info, check.DeepEquals, &client.SandboxInfo{
+		Snap: "foo",
+		Connections: []client.SandboxConnection{
+			{
+				Interface: "network",
+				Plug:      client.PlugRef{Snap: "foo", Name: "network"},
+				Slot:      client.SlotRef{Snap: "snapd", Name: "network"},
+				Connected: true,
+			},
+			{
+				Interface: "home",
+				Plug:      client.PlugRef{Snap: "foo", Name: "home"},
+				Slot:      client.SlotRef{Snap: "snapd", Name: "home"},
+				Manual:    true,
+			},
+		},
+		DataDirs: client.SandboxDataDirs{
+			Data:       "/var/snap/foo/1",
+			Common:     "/var/snap/foo/common",
+			UserData:   "/home/user/snap/foo/1",
+			UserCommon: "/home/user/snap/foo/common",
+		},
+		Quota: &client.QuotaGroupResult{
+			GroupName:   "grp",
+			Snaps:       []string{"foo"},
+			Constraints: &client.QuotaValues{Memory: quantity.Size(1000)},
+		},
+		RefreshInhibit: &client.SnapRefreshInhibit{
+			ProceedTime: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		},
+	})
+}
+
+func (cs *clientSuite) TestSandboxInfoError(c *check.C) {
+	cs.status = 403
+	cs.rsp = `{"type": "error", "result": {"message": "access denied"}}`
+	_, err := cs.cli.SandboxInfo("foo")
+	c.Check(err, check.ErrorMatches, `access denied`)
+}
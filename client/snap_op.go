@@ -64,6 +64,15 @@ type SnapOptions struct {
 	Time             string          `json:"time,omitempty"`
 	HoldLevel        string          `json:"hold-level,omitempty"`
 	Users            []string        `json:"users,omitempty"`
+	// AcceptPrerequisites confirms the installation of prerequisites
+	// when the system is configured to ask for it.
+	AcceptPrerequisites bool `json:"accept-prerequisites,omitempty"`
+	// Queue runs the operation once a conflicting change completes
+	// instead of failing with a change conflict error.
+	Queue bool `json:"queue,omitempty"`
+	// Assertions holds assertion streams to acknowledge before
+	// installing local snaps, so they can be installed as asserted.
+	Assertions [][]byte `json:"-"`
 }
 
 func writeFieldBool(mw *multipart.Writer, key string, val bool) error {
@@ -103,6 +112,7 @@ func (opts *SnapOptions) writeOptionFields(mw *multipart.Writer) error {
 		{"ignore-running", opts.IgnoreRunning},
 		{"unaliased", opts.Unaliased},
 		{"prefer", opts.Prefer},
+		{"accept-prerequisites", opts.AcceptPrerequisites},
 	}
 	if opts.Transaction != "" {
 		if err := mw.WriteField("transaction", string(opts.Transaction)); err != nil {
@@ -122,6 +132,7 @@ type actionData struct {
 	Name       string   `json:"name,omitempty"`
 	SnapPath   string   `json:"snap-path,omitempty"`
 	Components []string `json:"components,omitempty"`
+	DryRun     bool     `json:"dry-run,omitempty"`
 	*SnapOptions
 }
 
@@ -136,6 +147,9 @@ type multiActionData struct {
 	Time           string              `json:"time,omitempty"`
 	HoldLevel      string              `json:"hold-level,omitempty"`
 	Components     map[string][]string `json:"components,omitempty"`
+	DryRun         bool                `json:"dry-run,omitempty"`
+
+	AcceptPrerequisites bool `json:"accept-prerequisites,omitempty"`
 }
 
 // Install adds the snap with the given name from the given channel (or
@@ -222,6 +236,76 @@ func (client *Client) SnapshotMany(names []string, users []string) (setID uint64
 	return x.SetID, changeID, nil
 }
 
+// PlannedTask is a task that a snap operation would perform.
+type PlannedTask struct {
+	Kind    string `json:"kind"`
+	Summary string `json:"summary"`
+	Snap    string `json:"snap,omitempty"`
+}
+
+// SnapOpPlan describes what a snap operation would do.
+type SnapOpPlan struct {
+	Summary   string        `json:"summary"`
+	SnapNames []string      `json:"snap-names,omitempty"`
+	Tasks     []PlannedTask `json:"tasks"`
+	// Prerequisites are the snaps, like bases and default content
+	// providers, that would be installed as well.
+	Prerequisites []string `json:"prerequisites,omitempty"`
+	// RebootRequired is set when the operation would require a reboot
+	// of the system.
+	RebootRequired bool `json:"reboot-required,omitempty"`
+	// DownloadSize is the estimated number of bytes to download, and
+	// so of the disk space needed.
+	DownloadSize int64 `json:"download-size,omitempty"`
+}
+
+// PlanSnapAction returns what the given install, refresh or remove action
+// on the given snaps would do, without performing it. An empty list of
+// snaps refreshes all of them.
+func (client *Client) PlanSnapAction(actionName string, names []string, options *SnapOptions) (*SnapOpPlan, error) {
+	if options != nil && options.Dangerous {
+		return nil, ErrDangerousNotApplicable
+	}
+
+	var path string
+	var data []byte
+	var err error
+	if len(names) == 1 {
+		path = fmt.Sprintf("/v2/snaps/%s", names[0])
+		data, err = json.Marshal(&actionData{
+			Action:      actionName,
+			DryRun:      true,
+			SnapOptions: options,
+		})
+	} else {
+		path = "/v2/snaps"
+		action := multiActionData{
+			Action: actionName,
+			Snaps:  names,
+			DryRun: true,
+		}
+		if options != nil {
+			action.Transaction = options.Transaction
+			action.IgnoreRunning = options.IgnoreRunning
+			action.Purge = options.Purge
+		}
+		data, err = json.Marshal(&action)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot marshal snap action: %s", err)
+	}
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	var plan SnapOpPlan
+	if _, err := client.doSync("POST", path, nil, headers, bytes.NewBuffer(data), &plan); err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
 var ErrDangerousNotApplicable = fmt.Errorf("dangerous option only meaningful when installing from a local file")
 
 func (client *Client) doSnapAction(actionName string, snapName string, components []string, options *SnapOptions) (changeID string, err error) {
@@ -269,6 +353,7 @@ func (client *Client) doMultiSnapActionFull(actionName string, snaps []string, c
 		action.ValidationSets = options.ValidationSets
 		action.Time = options.Time
 		action.HoldLevel = options.HoldLevel
+		action.AcceptPrerequisites = options.AcceptPrerequisites
 	}
 
 	data, err := json.Marshal(&action)
@@ -405,6 +490,13 @@ func sendSnapFiles(paths []string, files []*os.File, pw *io.PipeWriter, mw *mult
 		return
 	}
 
+	for _, assertions := range action.Assertions {
+		if err := mw.WriteField("assertion", string(assertions)); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+	}
+
 	for i, file := range files {
 		path := paths[i]
 		fw, err := mw.CreateFormFile("snap", filepath.Base(path))
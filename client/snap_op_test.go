@@ -283,6 +283,85 @@ func (cs *clientSuite) TestClientMultiOpSnapIgnoreRunning(c *check.C) {
 	}
 }
 
+func (cs *clientSuite) TestClientOpAcceptPrerequisites(c *check.C) {
+	cs.status = 202
+	cs.rsp = `{
+		"change": "d728",
+		"status-code": 202,
+		"type": "async"
+	}`
+	opts := &client.SnapOptions{AcceptPrerequisites: true}
+
+	_, err := cs.cli.Install(pkgName, nil, opts)
+	c.Assert(err, check.IsNil)
+	var jsonBody map[string]interface{}
+	c.Assert(json.NewDecoder(cs.req.Body).Decode(&jsonBody), check.IsNil)
+	c.Check(jsonBody, check.DeepEquals, map[string]interface{}{
+		"action":               "install",
+		"accept-prerequisites": true,
+	})
+
+	_, err = cs.cli.RefreshMany([]string{pkgName}, nil, opts)
+	c.Assert(err, check.IsNil)
+	jsonBody = nil
+	c.Assert(json.NewDecoder(cs.req.Body).Decode(&jsonBody), check.IsNil)
+	c.Check(jsonBody, check.DeepEquals, map[string]interface{}{
+		"action":               "refresh",
+		"snaps":                []interface{}{pkgName},
+		"accept-prerequisites": true,
+	})
+}
+
+func (cs *clientSuite) TestClientPlanSnapAction(c *check.C) {
+	cs.rsp = `{
+		"type": "sync",
+		"result": {
+			"summary": "Install \"foo\" snap",
+			"snap-names": ["foo"],
+			"tasks": [
+				{"kind": "prerequisites", "summary": "Ensure prerequisites for \"foo\" are available", "snap": "foo"},
+				{"kind": "link-snap", "summary": "Make snap \"foo\" (7) available to the system", "snap": "foo"}
+			],
+			"prerequisites": ["core22"],
+			"reboot-required": true,
+			"download-size": 2048
+		}
+	}`
+	plan, err := cs.cli.PlanSnapAction("install", []string{"foo"}, &client.SnapOptions{Channel: "edge"})
+	c.Assert(err, check.IsNil)
+	c.Check(plan, check.DeepEquals, &client.SnapOpPlan{
+		Summary:   `Install "foo" snap`,
+		SnapNames: []string{"foo"},
+		Tasks: []client.PlannedTask{
+			{Kind: "prerequisites", Summary: `Ensure prerequisites for "foo" are available`, Snap: "foo"},
+			{Kind: "link-snap", Summary: `Make snap "foo" (7) available to the system`, Snap: "foo"},
+		},
+		Prerequisites:  []string{"core22"},
+		RebootRequired: true,
+		DownloadSize:   2048,
+	})
+	c.Check(cs.req.Method, check.Equals, "POST")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/snaps/foo")
+	var jsonBody map[string]interface{}
+	c.Assert(json.NewDecoder(cs.req.Body).Decode(&jsonBody), check.IsNil)
+	c.Check(jsonBody, check.DeepEquals, map[string]interface{}{
+		"action":  "install",
+		"channel": "edge",
+		"dry-run": true,
+	})
+
+	_, err = cs.cli.PlanSnapAction("refresh", nil, &client.SnapOptions{IgnoreRunning: true})
+	c.Assert(err, check.IsNil)
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/snaps")
+	jsonBody = nil
+	c.Assert(json.NewDecoder(cs.req.Body).Decode(&jsonBody), check.IsNil)
+	c.Check(jsonBody, check.DeepEquals, map[string]interface{}{
+		"action":         "refresh",
+		"ignore-running": true,
+		"dry-run":        true,
+	})
+}
+
 func (cs *clientSuite) TestClientMultiSnapshot(c *check.C) {
 	// Note body is essentially the same as TestClientMultiOpSnap; keep in sync
 	cs.status = 202
@@ -370,6 +449,34 @@ func (cs *clientSuite) TestClientOpInstallPathIgnoreRunning(c *check.C) {
 	c.Check(id, check.Equals, "66b3")
 }
 
+func (cs *clientSuite) TestClientOpInstallPathWithAssertions(c *check.C) {
+	cs.status = 202
+	cs.rsp = `{
+		"change": "66b3",
+		"status-code": 202,
+		"type": "async"
+	}`
+	bodyData := []byte("snap-data")
+
+	snap := filepath.Join(c.MkDir(), "foo.snap")
+	err := os.WriteFile(snap, bodyData, 0644)
+	c.Assert(err, check.IsNil)
+
+	opts := &client.SnapOptions{
+		Assertions: [][]byte{[]byte("assertion-one"), []byte("assertion-two")},
+	}
+	id, err := cs.cli.InstallPath(snap, "", opts)
+	c.Assert(err, check.IsNil)
+
+	body, err := io.ReadAll(cs.req.Body)
+	c.Assert(err, check.IsNil)
+
+	c.Assert(string(body), check.Matches, "(?s).*\r\nsnap-data\r\n.*")
+	c.Assert(string(body), check.Matches, "(?s).*Content-Disposition: form-data; name=\"assertion\"\r\n\r\nassertion-one\r\n.*")
+	c.Assert(string(body), check.Matches, "(?s).*Content-Disposition: form-data; name=\"assertion\"\r\n\r\nassertion-two\r\n.*")
+	c.Check(id, check.Equals, "66b3")
+}
+
 func (cs *clientSuite) TestClientOpInstallPathInstance(c *check.C) {
 	cs.status = 202
 	cs.rsp = `{
@@ -681,6 +788,30 @@ func (cs *clientSuite) TestClientOpInstallPrefer(c *check.C) {
 	c.Assert(string(body), check.Matches, "(?s).*Content-Disposition: form-data; name=\"prefer\"\r\n\r\ntrue\r\n.*")
 }
 
+func (cs *clientSuite) TestClientOpInstallPathAcceptPrerequisites(c *check.C) {
+	cs.status = 202
+	cs.rsp = `{
+		"change": "66b3",
+		"status-code": 202,
+		"type": "async"
+	}`
+	snap := filepath.Join(c.MkDir(), "foo.snap")
+	err := os.WriteFile(snap, []byte("snap-data"), 0644)
+	c.Assert(err, check.IsNil)
+
+	opts := client.SnapOptions{
+		AcceptPrerequisites: true,
+	}
+
+	_, err = cs.cli.InstallPath(snap, "", &opts)
+	c.Assert(err, check.IsNil)
+
+	body, err := io.ReadAll(cs.req.Body)
+	c.Assert(err, check.IsNil)
+
+	c.Assert(string(body), check.Matches, "(?s).*Content-Disposition: form-data; name=\"accept-prerequisites\"\r\n\r\ntrue\r\n.*")
+}
+
 func formToMap(c *check.C, mr *multipart.Reader) map[string]string {
 	formData := map[string]string{}
 	for {
@@ -47,6 +47,31 @@ type ValidationSetResult struct {
 	// TODO: flags/states for notes column
 }
 
+// ValidationSetConstraint holds the constraint a validation set puts on a
+// snap.
+type ValidationSetConstraint struct {
+	AccountID string `json:"account-id"`
+	Name      string `json:"name"`
+	Sequence  int    `json:"sequence,omitempty"`
+	Mode      string `json:"mode"`
+	Presence  string `json:"presence"`
+	Revision  string `json:"revision,omitempty"`
+}
+
+// SnapValidationConstraints holds the constraints the validation sets put on
+// a snap.
+type SnapValidationConstraints struct {
+	Snap        string                     `json:"snap"`
+	Constraints []*ValidationSetConstraint `json:"constraints,omitempty"`
+	// Presence and Revision are the combined constraints of the enforced
+	// validation sets.
+	Presence string `json:"presence,omitempty"`
+	Revision string `json:"revision,omitempty"`
+	// Blocked lists the actions on the snap that the enforced validation
+	// sets do not allow.
+	Blocked []string `json:"blocked,omitempty"`
+}
+
 type postValidationSetData struct {
 	Action   string `json:"action"`
 	Mode     string `json:"mode,omitempty"`
@@ -133,3 +158,21 @@ func (client *Client) ValidationSet(accountID, name string, sequence int) (*Vali
 	}
 	return res, nil
 }
+
+// SnapValidationConstraints queries the constraints the tracked validation
+// sets put on the given snap.
+func (client *Client) SnapValidationConstraints(snapName string) (*SnapValidationConstraints, error) {
+	if snapName == "" {
+		return nil, xerrors.Errorf("cannot query validation set constraints without a snap name")
+	}
+
+	q := url.Values{}
+	q.Set("snap", snapName)
+
+	var res *SnapValidationConstraints
+	if _, err := client.doSync("GET", "/v2/validation-sets", q, nil, nil, &res); err != nil {
+		fmt := "cannot query validation set constraints: %w"
+		return nil, xerrors.Errorf(fmt, err)
+	}
+	return res, nil
+}
@@ -238,3 +238,47 @@ func (cs *clientSuite) TestValidationSetWithSequence(c *check.C) {
 		AccountID: "abc", Name: "def", Mode: "monitor", Sequence: 9, Valid: false,
 	})
 }
+
+func (cs *clientSuite) TestSnapValidationConstraints(c *check.C) {
+	cs.rsp = `{
+		"type": "sync",
+		"status-code": 200,
+		"result": {
+			"snap": "foo",
+			"constraints": [
+				{"account-id": "abc", "name": "def", "sequence": 3, "mode": "enforce", "presence": "required", "revision": "7"},
+				{"account-id": "ghi", "name": "jkl", "sequence": 1, "mode": "monitor", "presence": "optional"}
+			],
+			"presence": "required",
+			"revision": "7",
+			"blocked": ["remove", "refresh", "revert"]
+		}
+	}`
+
+	res, err := cs.cli.SnapValidationConstraints("foo")
+	c.Assert(err, check.IsNil)
+	c.Check(cs.req.Method, check.Equals, "GET")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/validation-sets")
+	c.Check(cs.req.URL.Query(), check.DeepEquals, url.Values{"snap": []string{"foo"}})
+	c.Check(res, check.DeepEquals, &client.SnapValidationConstraints{
+		Snap: "foo",
+		Constraints: []*client.ValidationSetConstraint{
+			{AccountID: "abc", Name: "def", Sequence: 3, Mode: "enforce", Presence: "required", Revision: "7"},
+			{AccountID: "ghi", Name: "jkl", Sequence: 1, Mode: "monitor", Presence: "optional"},
+		},
+		Presence: "required",
+		Revision: "7",
+		Blocked:  []string{"remove", "refresh", "revert"},
+	})
+}
+
+func (cs *clientSuite) TestSnapValidationConstraintsError(c *check.C) {
+	cs.status = 500
+	cs.rsp = errorResponseJSON
+
+	_, err := cs.cli.SnapValidationConstraints("foo")
+	c.Assert(err, check.ErrorMatches, "cannot query validation set constraints: failed")
+
+	_, err = cs.cli.SnapValidationConstraints("")
+	c.Assert(err, check.ErrorMatches, "cannot query validation set constraints without a snap name")
+}
@@ -28,6 +28,7 @@
 #include <stdlib.h>
 #include <string.h>
 #include <sys/capability.h>
+#include <sys/file.h>
 #include <sys/stat.h>
 #include <sys/types.h>
 #include <sys/time.h>
@@ -290,6 +291,60 @@ static void sc_restore_process_state(const sc_preserved_process_state
 	debug("the process has been placed in the special void directory");
 }
 
+/**
+ * Enable debug logging if requested by snapd for the given snap.
+ *
+ * Snapd writes the number of invocations of the snap that should be debugged
+ * to /run/snapd/confine-debug/<snap>, keep in sync with dirs.SnapConfineDebugDir.
+ * Every invocation consumes one of them, the file is removed once all of them
+ * are used. Debugging is enabled through the environment, so that it is also
+ * enabled in snap-update-ns invoked by snap-confine.
+ **/
+static void sc_maybe_enable_debug_for_snap(const char *snap_instance)
+{
+	char path[PATH_MAX] = { 0 };
+	sc_must_snprintf(path, sizeof path, "/run/snapd/confine-debug/%s",
+			 snap_instance);
+	int fd SC_CLEANUP(sc_cleanup_close) = -1;
+	fd = open(path, O_RDWR | O_NOFOLLOW | O_CLOEXEC);
+	if (fd < 0) {
+		return;
+	}
+	struct stat file_info;
+	if (fstat(fd, &file_info) < 0 || !S_ISREG(file_info.st_mode)
+	    || file_info.st_uid != 0) {
+		return;
+	}
+	if (flock(fd, LOCK_EX) < 0) {
+		return;
+	}
+	char buf[16] = { 0 };
+	ssize_t n = pread(fd, buf, sizeof buf - 1, 0);
+	if (n <= 0) {
+		return;
+	}
+	char *end = NULL;
+	errno = 0;
+	long invocations = strtol(buf, &end, 10);
+	if (errno != 0 || end == buf || invocations <= 0) {
+		return;
+	}
+	if (invocations == 1) {
+		unlink(path);
+	} else {
+		char remaining[16] = { 0 };
+		sc_must_snprintf(remaining, sizeof remaining, "%ld\n",
+				 invocations - 1);
+		if (ftruncate(fd, 0) < 0
+		    || pwrite(fd, remaining, strlen(remaining), 0) < 0) {
+			return;
+		}
+	}
+	setenv("SNAP_CONFINE_DEBUG", "1", 1);
+	debug("debug logging enabled by snapd, %ld invocations remaining",
+	      invocations - 1);
+}
+
 static void log_startup_stage(const char *stage)
 {
 	if (!sc_is_debug_enabled()) {
@@ -365,6 +420,7 @@ int main(int argc, char **argv)
 
 	sc_init_invocation(&invocation, args, snap_instance_name_env,
 			   snap_component_name_env);
+	sc_maybe_enable_debug_for_snap(invocation.snap_instance);
 
 	// Who are we?
 	uid_t real_uid, effective_uid, saved_uid;
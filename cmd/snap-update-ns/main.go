@@ -22,11 +22,14 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"syscall"
 
 	"github.com/jessevdk/go-flags"
 
+	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/osutil"
 )
 
 var opts struct {
@@ -80,6 +83,13 @@ func run() error {
 		return err
 	}
 
+	// When invoked by snap-confine debugging is already enabled through
+	// the environment, as snap-confine consumes the debug control file of
+	// the snap. When invoked by snapd only look for the control file.
+	if !opts.FromSnapConfine && osutil.FileExists(filepath.Join(dirs.SnapConfineDebugDir, opts.Positionals.SnapName)) {
+		os.Setenv("SNAPD_DEBUG", "1")
+	}
+
 	// Explicitly set the umask to 0 to prevent permission bits
 	// being masked out when creating files and directories.
 	//
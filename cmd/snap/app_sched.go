@@ -0,0 +1,74 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"runtime"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/snap"
+)
+
+var (
+	schedSetaffinity = unix.SchedSetaffinity
+	schedSetAttr     = unix.SchedSetAttr
+)
+
+var schedulingPolicies = map[snap.SchedulingPolicy]uint32{
+	"idle":  unix.SCHED_IDLE,
+	"batch": unix.SCHED_BATCH,
+}
+
+// applyAppScheduling restricts the process to the CPUs and scheduling policy
+// declared for the app, these are inherited by snap-confine and the app it
+// executes. Services get them from their systemd unit instead.
+//
+// Both settings apply to the calling thread only, which is locked for the
+// rest of the life of the process so that the exec happens from it.
+func applyAppScheduling(app *snap.AppInfo) {
+	if app.IsService() || (len(app.CPUAffinity) == 0 && app.SchedulingPolicy == "") {
+		return
+	}
+	runtime.LockOSThread()
+
+	if len(app.CPUAffinity) > 0 {
+		var set unix.CPUSet
+		for _, cpu := range app.CPUAffinity {
+			set.Set(cpu)
+		}
+		if err := schedSetaffinity(0, &set); err != nil {
+			logger.Noticef("WARNING: cannot set CPU affinity of %s: %v", app.SecurityTag(), err)
+		}
+	}
+
+	if app.SchedulingPolicy != "" {
+		policy, ok := schedulingPolicies[app.SchedulingPolicy]
+		if !ok {
+			logger.Noticef("WARNING: unknown scheduling policy %q of %s", app.SchedulingPolicy, app.SecurityTag())
+			return
+		}
+		attr := unix.SchedAttr{Policy: policy}
+		if err := schedSetAttr(0, &attr, 0); err != nil {
+			logger.Noticef("WARNING: cannot set scheduling policy of %s to %s: %v", app.SecurityTag(), app.SchedulingPolicy, err)
+		}
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"regexp"
 	"sort"
+	"time"
 
 	"github.com/jessevdk/go-flags"
 
@@ -34,6 +35,10 @@ var shortChangesHelp = i18n.G("List system changes")
 var shortTasksHelp = i18n.G("List a change's tasks")
 var longChangesHelp = i18n.G(`
 The changes command displays a summary of system changes performed recently.
+
+The changes can be narrowed down by kind, status and the time they were
+spawned. Times are either in RFC 3339 format or a duration before now, as in
+--since=24h.
 `)
 var longTasksHelp = i18n.G(`
 The tasks command displays a summary of tasks associated with an individual
@@ -43,6 +48,11 @@ change.
 type cmdChanges struct {
 	clientMixin
 	timeMixin
+	Kinds      []string `long:"kind"`
+	Statuses   []string `long:"status"`
+	Since      string   `long:"since"`
+	Until      string   `long:"until"`
+	ErrorsOnly bool     `long:"errors-only"`
 	Positional struct {
 		Snap string `positional-arg-name:"<snap>"`
 	} `positional-args:"yes"`
@@ -55,7 +65,18 @@ type cmdTasks struct {
 
 func init() {
 	addCommand("changes", shortChangesHelp, longChangesHelp,
-		func() flags.Commander { return &cmdChanges{} }, timeDescs, nil)
+		func() flags.Commander { return &cmdChanges{} }, timeDescs.also(map[string]string{
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"kind": i18n.G("Only show changes of the given kind (install, refresh, remove, auto-refresh, etc.)"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"status": i18n.G("Only show changes with the given status (Doing, Done, Error, etc.)"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"since": i18n.G("Only show changes spawned at or after the given time"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"until": i18n.G("Only show changes spawned before the given time"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"errors-only": i18n.G("Only show changes that failed"),
+		}), nil)
 	addCommand("tasks", shortTasksHelp, longTasksHelp,
 		func() flags.Commander { return &cmdTasks{} },
 		changeIDMixinOptDesc.also(timeDescs),
@@ -81,6 +102,22 @@ func queryChanges(cli *client.Client, opts *client.ChangesOptions) ([]*client.Ch
 	return chgs, nil
 }
 
+// parseChangesTime parses a time given either in RFC 3339 format or as a
+// duration before now.
+func parseChangesTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	dur, err := time.ParseDuration(s)
+	if err != nil || dur < 0 {
+		return time.Time{}, fmt.Errorf(i18n.G("expected a time in RFC 3339 format or a duration, got %q"), s)
+	}
+	return timeNow().Add(-dur), nil
+}
+
 func (c *cmdChanges) Execute(args []string) error {
 	if len(args) > 0 {
 		return ErrExtraArgs
@@ -99,6 +136,21 @@ func (c *cmdChanges) Execute(args []string) error {
 	opts := client.ChangesOptions{
 		SnapName: c.Positional.Snap,
 		Selector: client.ChangesAll,
+		Kinds:    c.Kinds,
+		Statuses: c.Statuses,
+	}
+	if c.ErrorsOnly {
+		if len(c.Statuses) != 0 {
+			return fmt.Errorf(i18n.G("cannot use --errors-only and --status together"))
+		}
+		opts.Statuses = []string{"Error"}
+	}
+	var err error
+	if opts.Since, err = parseChangesTime(c.Since); err != nil {
+		return fmt.Errorf(i18n.G("invalid --since value: %v"), err)
+	}
+	if opts.Until, err = parseChangesTime(c.Until); err != nil {
+		return fmt.Errorf(i18n.G("invalid --until value: %v"), err)
 	}
 
 	changes, err := queryChanges(c.client, &opts)
@@ -22,7 +22,9 @@ package main_test
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
 
 	"gopkg.in/check.v1"
 
@@ -250,3 +252,51 @@ func (s *SnapSuite) TestNoChanges(c *check.C) {
 	c.Assert(err, check.IsNil)
 	c.Check(s.Stderr(), check.Equals, "no changes found\n")
 }
+
+func (s *SnapSuite) TestChangesFilters(c *check.C) {
+	restore := snap.MockTimeNow(func() time.Time {
+		return time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC)
+	})
+	defer restore()
+
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.Method, check.Equals, "GET")
+			c.Check(r.URL.Path, check.Equals, "/v2/changes")
+			c.Check(r.URL.Query(), check.DeepEquals, url.Values{
+				"select": []string{"all"},
+				"for":    []string{"foo"},
+				"kind":   []string{"install-snap,refresh-snap"},
+				"status": []string{"Error"},
+				"since":  []string{"2024-03-01T12:00:00Z"},
+				"until":  []string{"2024-03-02T00:00:00Z"},
+			})
+			fmt.Fprintln(w, `{"type": "sync", "result": []}`)
+		default:
+			c.Fatalf("expected to get 1 requests, now on %d", n+1)
+		}
+
+		n++
+	})
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"changes", "--kind=install-snap", "--kind=refresh-snap",
+		"--errors-only", "--since=24h", "--until=2024-03-02T00:00:00Z", "foo"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stderr(), check.Equals, "no changes found\n")
+}
+
+func (s *SnapSuite) TestChangesFiltersErrors(c *check.C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Fatalf("unexpected request")
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"changes", "--since=yesterday"})
+	c.Check(err, check.ErrorMatches, `invalid --since value: expected a time in RFC 3339 format or a duration, got "yesterday"`)
+
+	_, err = snap.Parser(snap.Client()).ParseArgs([]string{"changes", "--until=-1h"})
+	c.Check(err, check.ErrorMatches, `invalid --until value: expected a time in RFC 3339 format or a duration, got "-1h"`)
+
+	_, err = snap.Parser(snap.Client()).ParseArgs([]string{"changes", "--errors-only", "--status=Done"})
+	c.Check(err, check.ErrorMatches, `cannot use --errors-only and --status together`)
+}
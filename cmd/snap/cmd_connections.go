@@ -33,8 +33,12 @@ import (
 
 type cmdConnections struct {
 	clientMixin
-	All         bool `long:"all"`
-	Positionals struct {
+	formatMixin
+	All              bool   `long:"all"`
+	Interface        string `long:"interface"`
+	DisconnectedOnly bool   `long:"disconnected-only"`
+	Attrs            bool   `long:"attrs"`
+	Positionals      struct {
 		Snap installedSnapName
 	} `positional-args:"true"`
 }
@@ -52,14 +56,25 @@ $ snap connections <snap>
 
 Lists connected and unconnected plugs and slots for the specified
 snap.
+
+The listing can be narrowed down to plugs and slots of a given interface
+with --interface, and to plugs and slots which are not connected with
+--disconnected-only. Pass --attrs to show the attributes of the plugs and
+slots of each connection.
 `)
 
 func init() {
 	addCommand("connections", shortConnectionsHelp, longConnectionsHelp, func() flags.Commander {
 		return &cmdConnections{}
-	}, map[string]string{
+	}, formatDescs.also(map[string]string{
 		"all": i18n.G("Show connected and unconnected plugs and slots"),
-	}, []argDesc{{
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"interface": i18n.G("Constrain listing to plugs and slots of the given interface"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"disconnected-only": i18n.G("Show only plugs and slots that are not connected"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"attrs": i18n.G("Show plug and slot attributes"),
+	}), []argDesc{{
 		// TRANSLATORS: This needs to be wrapped in <>s.
 		name: "<snap>",
 		// TRANSLATORS: This should not start with a lowercase letter.
@@ -85,6 +100,8 @@ type connection struct {
 	interfaceDeterminant string
 	manual               bool
 	gadget               bool
+	plugAttrs            map[string]interface{}
+	slotAttrs            map[string]interface{}
 }
 
 func (cn connection) String() string {
@@ -101,6 +118,39 @@ func (cn connection) String() string {
 	return strings.Join(opts, ",")
 }
 
+// listedConnection is the machine-readable form of a connection in the
+// output of the connections command. Plug or slot are empty for
+// unconnected slots and plugs respectively.
+type listedConnection struct {
+	Interface string `json:"interface" yaml:"interface"`
+	Plug      string `json:"plug,omitempty" yaml:"plug,omitempty"`
+	Slot      string `json:"slot,omitempty" yaml:"slot,omitempty"`
+	Manual    bool   `json:"manual,omitempty" yaml:"manual,omitempty"`
+	Gadget    bool   `json:"gadget,omitempty" yaml:"gadget,omitempty"`
+
+	PlugAttrs map[string]interface{} `json:"plug-attrs,omitempty" yaml:"plug-attrs,omitempty"`
+	SlotAttrs map[string]interface{} `json:"slot-attrs,omitempty" yaml:"slot-attrs,omitempty"`
+}
+
+func (cn connection) listed(withAttrs bool) listedConnection {
+	lc := listedConnection{
+		Interface: cn.interfaceName,
+		Manual:    cn.manual,
+		Gadget:    cn.gadget,
+	}
+	if withAttrs {
+		lc.PlugAttrs = cn.plugAttrs
+		lc.SlotAttrs = cn.slotAttrs
+	}
+	if cn.plug != "-" {
+		lc.Plug = cn.plug
+	}
+	if cn.slot != "-" {
+		lc.Slot = cn.slot
+	}
+	return lc
+}
+
 type byConnectionData []connection
 
 func (b byConnectionData) Len() int      { return len(b) }
@@ -137,8 +187,14 @@ func (x *cmdConnections) Execute(args []string) error {
 		return ErrExtraArgs
 	}
 
+	if x.All && x.DisconnectedOnly {
+		return errors.New(i18n.G("cannot use --all with --disconnected-only"))
+	}
+
 	opts := client.ConnectionOptions{
-		All: x.All,
+		All:          x.All,
+		Disconnected: x.DisconnectedOnly,
+		Interface:    x.Interface,
 	}
 	wanted := string(x.Positionals.Snap)
 	if wanted != "" {
@@ -147,19 +203,29 @@ func (x *cmdConnections) Execute(args []string) error {
 			// when it was passed explicitly
 			return errors.New(i18n.G("cannot use --all with snap name"))
 		}
-		// when asking for a single snap, include its disconnected plugs
-		// and slots
 		opts.Snap = wanted
-		opts.All = true
+		if !x.DisconnectedOnly {
+			// when asking for a single snap, include its disconnected
+			// plugs and slots
+			opts.All = true
+		}
 		// print all slots
 		x.All = true
 	}
+	if x.DisconnectedOnly {
+		// the daemon only returns plugs and slots which are not
+		// connected, print all of them
+		x.All = true
+	}
 
 	connections, err := x.client.Connections(&opts)
 	if err != nil {
 		return err
 	}
 	if len(connections.Plugs) == 0 && len(connections.Slots) == 0 {
+		if x.structured() {
+			return x.printStructured([]listedConnection{})
+		}
 		return nil
 	}
 
@@ -172,18 +238,18 @@ func (x *cmdConnections) Execute(args []string) error {
 			gadget:               conn.Gadget,
 			interfaceName:        conn.Interface,
 			interfaceDeterminant: interfaceDeterminant(&conn),
+			plugAttrs:            conn.PlugAttrs,
+			slotAttrs:            conn.SlotAttrs,
 		})
 	}
 
-	w := tabWriter()
-	fmt.Fprintln(w, i18n.G("Interface\tPlug\tSlot\tNotes"))
-
 	for _, plug := range connections.Plugs {
 		if len(plug.Connections) == 0 && x.All {
 			annotatedConns = append(annotatedConns, connection{
 				plug:          endpoint(plug.Snap, plug.Name),
 				slot:          "-",
 				interfaceName: plug.Interface,
+				plugAttrs:     plug.Attrs,
 			})
 		}
 	}
@@ -198,12 +264,28 @@ func (x *cmdConnections) Execute(args []string) error {
 				plug:          "-",
 				slot:          endpoint(slot.Snap, slot.Name),
 				interfaceName: slot.Interface,
+				slotAttrs:     slot.Attrs,
 			})
 		}
 	}
 
 	sort.Sort(byConnectionData(annotatedConns))
 
+	if x.structured() {
+		listed := make([]listedConnection, 0, len(annotatedConns))
+		for _, conn := range annotatedConns {
+			listed = append(listed, conn.listed(x.Attrs))
+		}
+		return x.printStructured(listed)
+	}
+
+	if x.Attrs {
+		x.showDetails(annotatedConns)
+		return nil
+	}
+
+	w := tabWriter()
+	fmt.Fprintln(w, i18n.G("Interface\tPlug\tSlot\tNotes"))
 	for _, note := range annotatedConns {
 		fmt.Fprintf(w, "%s%s\t%s\t%s\t%s\n", note.interfaceName, note.interfaceDeterminant, note.plug, note.slot, note)
 	}
@@ -213,3 +295,27 @@ func (x *cmdConnections) Execute(args []string) error {
 	}
 	return nil
 }
+
+// showDetails prints each connection on its own, together with the
+// attributes of its plug and slot.
+func (x *cmdConnections) showDetails(conns []connection) {
+	w := tabWriter()
+	defer w.Flush()
+	for i, conn := range conns {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "interface:\t%s%s\n", conn.interfaceName, conn.interfaceDeterminant)
+		fmt.Fprintf(w, "plug:\t%s\n", conn.plug)
+		fmt.Fprintf(w, "slot:\t%s\n", conn.slot)
+		fmt.Fprintf(w, "notes:\t%s\n", conn)
+		if len(conn.plugAttrs) > 0 {
+			fmt.Fprintf(w, "plug-attrs:\n")
+			showAttrs(w, conn.plugAttrs, "")
+		}
+		if len(conn.slotAttrs) > 0 {
+			fmt.Fprintf(w, "slot-attrs:\n")
+			showAttrs(w, conn.slotAttrs, "")
+		}
+	}
+}
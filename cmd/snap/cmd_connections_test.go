@@ -382,6 +382,81 @@ func (s *SnapSuite) TestConnectionsSomeDisconnected(c *C) {
 	c.Assert(s.Stderr(), Equals, "")
 }
 
+func (s *SnapSuite) TestConnectionsFormat(c *C) {
+	result := client.Connections{
+		Established: []client.Connection{
+			{
+				Plug:      client.PlugRef{Snap: "keyboard-lights", Name: "capslock"},
+				Slot:      client.SlotRef{Snap: "leds-provider", Name: "capslock-led"},
+				Interface: "leds",
+				Manual:    true,
+			},
+		},
+		Plugs: []client.Plug{
+			{
+				Snap:      "keyboard-lights",
+				Name:      "capslock",
+				Interface: "leds",
+				Connections: []client.SlotRef{{
+					Snap: "leds-provider",
+					Name: "capslock-led",
+				}},
+			}, {
+				Snap:      "keyboard-lights",
+				Name:      "numlock",
+				Interface: "leds",
+			},
+		},
+		Slots: []client.Slot{
+			{
+				Snap:      "leds-provider",
+				Name:      "capslock-led",
+				Interface: "leds",
+				Connections: []client.PlugRef{{
+					Snap: "keyboard-lights",
+					Name: "capslock",
+				}},
+			},
+		},
+	}
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/connections")
+		EncodeResponseBody(c, w, map[string]interface{}{
+			"type":   "sync",
+			"result": result,
+		})
+	})
+
+	_, err := Parser(Client()).ParseArgs([]string{"connections", "--all", "--format=json"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, `[
+  {
+    "interface": "leds",
+    "plug": "keyboard-lights:capslock",
+    "slot": "leds-provider:capslock-led",
+    "manual": true
+  },
+  {
+    "interface": "leds",
+    "plug": "keyboard-lights:numlock"
+  }
+]
+`)
+	c.Check(s.Stderr(), Equals, "")
+
+	s.ResetStdStreams()
+	_, err = Parser(Client()).ParseArgs([]string{"connections", "--all", "--format=yaml"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, `- interface: leds
+  plug: keyboard-lights:capslock
+  slot: leds-provider:capslock-led
+  manual: true
+- interface: leds
+  plug: keyboard-lights:numlock
+`)
+}
+
 func (s *SnapSuite) TestConnectionsOnlyDisconnected(c *C) {
 	result := client.Connections{
 		Undesired: []client.Connection{
@@ -851,3 +926,137 @@ func (s *SnapSuite) TestConnectionsDefiningAttribute(c *C) {
 	c.Assert(s.Stdout(), Equals, expectedStdout)
 	c.Assert(s.Stderr(), Equals, "")
 }
+
+func (s *SnapSuite) TestConnectionsInterfaceDisconnectedOnly(c *C) {
+	result := client.Connections{
+		Plugs: []client.Plug{
+			{
+				Snap:      "keyboard-lights",
+				Name:      "numlock",
+				Interface: "leds",
+			},
+		},
+		Slots: []client.Slot{
+			{
+				Snap:      "leds-provider",
+				Name:      "numlock-led",
+				Interface: "leds",
+			},
+		},
+	}
+	query := url.Values{
+		"interface": []string{"leds"},
+		"select":    []string{"disconnected"},
+	}
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/connections")
+		c.Check(r.URL.Query(), DeepEquals, query)
+		EncodeResponseBody(c, w, map[string]interface{}{
+			"type":   "sync",
+			"result": result,
+		})
+	})
+
+	rest, err := Parser(Client()).ParseArgs([]string{"connections", "--interface=leds", "--disconnected-only"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	expectedStdout := "" +
+		"Interface  Plug                     Slot                       Notes\n" +
+		"leds       -                        leds-provider:numlock-led  -\n" +
+		"leds       keyboard-lights:numlock  -                          -\n"
+	c.Check(s.Stdout(), Equals, expectedStdout)
+	c.Check(s.Stderr(), Equals, "")
+
+	s.ResetStdStreams()
+	query = url.Values{
+		"snap":   []string{"leds-provider"},
+		"select": []string{"disconnected"},
+	}
+	_, err = Parser(Client()).ParseArgs([]string{"connections", "--disconnected-only", "leds-provider"})
+	c.Assert(err, IsNil)
+
+	_, err = Parser(Client()).ParseArgs([]string{"connections", "--disconnected-only", "--all"})
+	c.Assert(err, ErrorMatches, "cannot use --all with --disconnected-only")
+}
+
+func (s *SnapSuite) TestConnectionsAttrs(c *C) {
+	result := client.Connections{
+		Established: []client.Connection{
+			{
+				Plug:      client.PlugRef{Snap: "foo", Name: "data"},
+				Slot:      client.SlotRef{Snap: "bar", Name: "data"},
+				Interface: "content",
+				PlugAttrs: map[string]interface{}{"content": "data", "target": "$SNAP/data"},
+				SlotAttrs: map[string]interface{}{"content": "data", "read": []interface{}{"$SNAP/data"}},
+				Manual:    true,
+			},
+		},
+		Plugs: []client.Plug{
+			{
+				Snap:        "foo",
+				Name:        "data",
+				Interface:   "content",
+				Connections: []client.SlotRef{{Snap: "bar", Name: "data"}},
+			}, {
+				Snap:      "foo",
+				Name:      "audio-playback",
+				Interface: "audio-playback",
+			},
+		},
+		Slots: []client.Slot{
+			{
+				Snap:        "bar",
+				Name:        "data",
+				Interface:   "content",
+				Connections: []client.PlugRef{{Snap: "foo", Name: "data"}},
+			},
+		},
+	}
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/connections")
+		EncodeResponseBody(c, w, map[string]interface{}{
+			"type":   "sync",
+			"result": result,
+		})
+	})
+
+	rest, err := Parser(Client()).ParseArgs([]string{"connections", "--attrs", "foo"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(s.Stdout(), Equals, ""+
+		"interface:  audio-playback\n"+
+		"plug:       foo:audio-playback\n"+
+		"slot:       -\n"+
+		"notes:      -\n"+
+		"\n"+
+		"interface:  content[data]\n"+
+		"plug:       foo:data\n"+
+		"slot:       bar:data\n"+
+		"notes:      manual\n"+
+		"plug-attrs:\n"+
+		"  content:  data\n"+
+		"  target:   $SNAP/data\n"+
+		"slot-attrs:\n"+
+		"  content:  data\n")
+	c.Check(s.Stderr(), Equals, "")
+
+	s.ResetStdStreams()
+	_, err = Parser(Client()).ParseArgs([]string{"connections", "--attrs", "--format=yaml", "foo"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, `- interface: audio-playback
+  plug: foo:audio-playback
+- interface: content
+  plug: foo:data
+  slot: bar:data
+  manual: true
+  plug-attrs:
+    content: data
+    target: $SNAP/data
+  slot-attrs:
+    content: data
+    read:
+    - $SNAP/data
+`)
+}
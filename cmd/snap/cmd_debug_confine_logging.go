@@ -0,0 +1,122 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/strutil"
+)
+
+type cmdDebugConfineLogging struct {
+	clientMixin
+
+	Invocations int  `long:"invocations" default:"1"`
+	Disable     bool `long:"disable"`
+
+	Positional struct {
+		Snaps []installedSnapName `positional-arg-name:"<snap>"`
+	} `positional-args:"yes"`
+}
+
+func init() {
+	addDebugCommand("confine-logging",
+		i18n.G("Enable verbose logging of snap-confine for snaps"),
+		i18n.G(`
+The confine-logging command enables verbose logging of snap-confine and
+snap-update-ns for the next invocations of the given snaps, without the need to
+set SNAP_CONFINE_DEBUG in the environment they are started from. The log is
+written to the standard error of the snap, which for services and applications
+started by the desktop session usually ends up in the journal.
+
+Without snaps, the snaps with verbose logging enabled are listed.
+`),
+		func() flags.Commander {
+			return &cmdDebugConfineLogging{}
+		}, map[string]string{
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"invocations": i18n.G("Number of invocations to log verbosely (defaults to 1)"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"disable": i18n.G("Disable verbose logging again"),
+		}, nil)
+}
+
+func (x *cmdDebugConfineLogging) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	if len(x.Positional.Snaps) == 0 {
+		if x.Disable {
+			return errors.New(i18n.G("cannot use --disable without snaps"))
+		}
+		return x.showStatus()
+	}
+
+	snaps := installedSnapNames(x.Positional.Snaps)
+	invocations := x.Invocations
+	if x.Disable {
+		invocations = 0
+	} else if invocations <= 0 {
+		return fmt.Errorf(i18n.G("invalid number of invocations %d"), invocations)
+	}
+	if err := x.client.ConfineDebug(snaps, invocations); err != nil {
+		return err
+	}
+	switch invocations {
+	case 0:
+		fmt.Fprintf(Stdout, i18n.G("Verbose logging of snap-confine disabled for %s.\n"), strutil.Quoted(snaps))
+	case 1:
+		fmt.Fprintf(Stdout, i18n.G("Verbose logging of snap-confine enabled for the next invocation of %s.\n"), strutil.Quoted(snaps))
+	default:
+		fmt.Fprintf(Stdout, i18n.G("Verbose logging of snap-confine enabled for the next %d invocations of %s.\n"), invocations, strutil.Quoted(snaps))
+	}
+	return nil
+}
+
+func (x *cmdDebugConfineLogging) showStatus() error {
+	var status map[string]int
+	if err := x.client.DebugGet("confine-debug", &status, nil); err != nil {
+		return err
+	}
+	if len(status) == 0 {
+		fmt.Fprintln(Stderr, i18n.G("Verbose logging of snap-confine is not enabled for any snap."))
+		return nil
+	}
+
+	names := make([]string, 0, len(status))
+	for name := range status {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := tabWriter()
+	defer w.Flush()
+	fmt.Fprintln(w, i18n.G("Snap\tInvocations"))
+	for _, name := range names {
+		fmt.Fprintf(w, "%s\t%d\n", name, status[name])
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snap"
+)
+
+func (s *SnapSuite) TestDebugConfineLoggingStatus(c *check.C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		c.Check(r.Method, check.Equals, "GET")
+		c.Check(r.URL.Path, check.Equals, "/v2/debug")
+		c.Check(r.URL.RawQuery, check.Equals, "aspect=confine-debug")
+		fmt.Fprintln(w, `{"type": "sync", "result": {"foo": 2, "bar_1": 1}}`)
+	})
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "confine-logging"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(n, check.Equals, 1)
+	c.Check(s.Stdout(), check.Equals, `Snap   Invocations
+bar_1  1
+foo    2
+`)
+	c.Check(s.Stderr(), check.Equals, "")
+}
+
+func (s *SnapSuite) TestDebugConfineLoggingStatusNone(c *check.C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"type": "sync", "result": {}}`)
+	})
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "confine-logging"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stdout(), check.Equals, "")
+	c.Check(s.Stderr(), check.Equals, "Verbose logging of snap-confine is not enabled for any snap.\n")
+}
+
+func (s *SnapSuite) TestDebugConfineLoggingEnableDisable(c *check.C) {
+	for _, tc := range []struct {
+		args []string
+		body string
+		out  string
+	}{{
+		args: []string{"foo"},
+		body: `{"action":"confine-debug","snaps":["foo"],"params":{"invocations":1}}`,
+		out:  "Verbose logging of snap-confine enabled for the next invocation of \"foo\".\n",
+	}, {
+		args: []string{"--invocations=3", "foo", "bar"},
+		body: `{"action":"confine-debug","snaps":["foo","bar"],"params":{"invocations":3}}`,
+		out:  "Verbose logging of snap-confine enabled for the next 3 invocations of \"foo\", \"bar\".\n",
+	}, {
+		args: []string{"--disable", "foo"},
+		body: `{"action":"confine-debug","snaps":["foo"],"params":{"invocations":0}}`,
+		out:  "Verbose logging of snap-confine disabled for \"foo\".\n",
+	}} {
+		s.ResetStdStreams()
+		s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+			c.Check(r.Method, check.Equals, "POST")
+			c.Check(r.URL.Path, check.Equals, "/v2/debug")
+			data, err := io.ReadAll(r.Body)
+			c.Check(err, check.IsNil)
+			c.Check(string(data), check.Equals, tc.body)
+			fmt.Fprintln(w, `{"type": "sync", "result": true}`)
+		})
+		_, err := snap.Parser(snap.Client()).ParseArgs(append([]string{"debug", "confine-logging"}, tc.args...))
+		c.Assert(err, check.IsNil)
+		c.Check(s.Stdout(), check.Equals, tc.out)
+	}
+}
+
+func (s *SnapSuite) TestDebugConfineLoggingErrors(c *check.C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Fatalf("unexpected request")
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "confine-logging", "--disable"})
+	c.Check(err, check.ErrorMatches, "cannot use --disable without snaps")
+
+	_, err = snap.Parser(snap.Client()).ParseArgs([]string{"debug", "confine-logging", "--invocations=0", "foo"})
+	c.Check(err, check.ErrorMatches, "invalid number of invocations 0")
+}
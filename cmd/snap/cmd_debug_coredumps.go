@@ -0,0 +1,165 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/sandbox/cgroup"
+	"github.com/snapcore/snapd/strutil"
+	"github.com/snapcore/snapd/systemd"
+)
+
+type cmdDebugCoredumps struct {
+	timeMixin
+
+	Pid    int    `long:"pid"`
+	Output string `long:"output"`
+
+	Positional struct {
+		Snap installedSnapName `positional-arg-name:"<snap>" required:"1"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+var longDebugCoredumpsHelp = i18n.G(`
+The coredumps command lists the core dumps of the processes of the given
+snap that were collected by systemd-coredump. Collection is enabled on
+Ubuntu Core with the system.coredump.enable option, while
+system.coredump.maxuse limits the disk space used by the core dumps.
+
+With --pid and --output the core dump of the given process is written to the
+given file.
+
+With the system.coredump.snap-maxuse option, snapd also copies the core dumps
+of snap services to /var/lib/snapd/coredump/<snap>, keeping at most the given
+disk space for each snap.
+
+The core dumps are obtained from the journal as the calling user, so only the
+core dumps of processes run by that user are accessible unless run as root.
+`)
+
+func init() {
+	addDebugCommand("coredumps",
+		i18n.G("List and retrieve core dumps of snap processes"),
+		longDebugCoredumpsHelp,
+		func() flags.Commander {
+			return &cmdDebugCoredumps{}
+		}, timeDescs.also(map[string]string{
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"pid": i18n.G("Retrieve the core dump of the process with the given pid"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"output": i18n.G("Write the retrieved core dump to the given file"),
+		}), nil)
+}
+
+// snapCoredumps returns the core dumps of the processes of the given snap,
+// identified by the cgroup of the processes. Unlike the executable path, the
+// cgroup tells parallel instances apart.
+func snapCoredumps(snapName string) ([]*systemd.Coredump, error) {
+	all, err := systemd.Coredumps("")
+	if err != nil {
+		return nil, err
+	}
+	var coredumps []*systemd.Coredump
+	for _, cd := range all {
+		if cgroup.SnapNameFromCgroupPath(cd.Cgroup) == snapName {
+			coredumps = append(coredumps, cd)
+		}
+	}
+	return coredumps, nil
+}
+
+// coredumpFileInfo returns the state of the file of the given core dump, as
+// shown by coredumpctl, and its size if present.
+func coredumpFileInfo(cd *systemd.Coredump) (state, size string) {
+	if cd.Filename == "" {
+		return "none", "-"
+	}
+	fi, err := os.Stat(cd.Filename)
+	switch {
+	case err == nil:
+		return "present", strutil.SizeToStr(fi.Size())
+	case errors.Is(err, os.ErrNotExist):
+		return "missing", "-"
+	default:
+		return "inaccessible", "-"
+	}
+}
+
+func (x *cmdDebugCoredumps) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+	if (x.Pid == 0) != (x.Output == "") {
+		return errors.New(i18n.G("--pid and --output must be used together"))
+	}
+
+	snapName := string(x.Positional.Snap)
+	coredumps, err := snapCoredumps(snapName)
+	if err != nil {
+		return err
+	}
+
+	if x.Pid != 0 {
+		return x.retrieve(snapName, coredumps)
+	}
+
+	if len(coredumps) == 0 {
+		fmt.Fprintf(Stderr, i18n.G("No core dumps found for snap %q.\n"), snapName)
+		return nil
+	}
+
+	w := tabWriter()
+	defer w.Flush()
+
+	fmt.Fprintln(w, i18n.G("Time\tPID\tUID\tSignal\tSize\tCore\tExe"))
+	for _, cd := range coredumps {
+		state, size := coredumpFileInfo(cd)
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\t%s\t%s\n", x.fmtTime(cd.Time()), cd.Pid, cd.UID, cd.Signal, size, state, cd.Exe)
+	}
+	return nil
+}
+
+func (x *cmdDebugCoredumps) retrieve(snapName string, coredumps []*systemd.Coredump) error {
+	// the pid may have been reused by another process of the snap, use the
+	// latest core dump
+	var coredump *systemd.Coredump
+	for _, cd := range coredumps {
+		if cd.Pid == x.Pid {
+			coredump = cd
+		}
+	}
+	// do not let the command be used to retrieve core dumps of processes
+	// outside of the snap
+	if coredump == nil {
+		return fmt.Errorf(i18n.G("no core dump found for process %d of snap %q"), x.Pid, snapName)
+	}
+
+	if err := systemd.DumpCoredump(coredump, x.Output); err != nil {
+		return err
+	}
+	fmt.Fprintf(Stdout, i18n.G("Core dump of process %d written to %s.\n"), x.Pid, x.Output)
+	return nil
+}
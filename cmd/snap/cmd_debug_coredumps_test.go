@@ -0,0 +1,142 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snap"
+	"github.com/snapcore/snapd/testutil"
+)
+
+// the dumps of process 2345 of another user, of process 3456 of the foo_x
+// parallel instance, which runs from /snap/foo too, and of an older process
+// 1234 of a foo app
+const mockCoredumpsList = `
+{"__CURSOR":"s=1","COREDUMP_TIMESTAMP":"1699999000000000","COREDUMP_PID":"1234","COREDUMP_UID":"1000","COREDUMP_SIGNAL":"6","COREDUMP_EXE":"/snap/foo/12/bin/app","COREDUMP_CGROUP":"/user.slice/user-1000.slice/user@1000.service/app.slice/snap.foo.app-54b38acc-3ba2-4c6d-b284-7ac07e1159e5.scope"}
+{"__CURSOR":"s=2","COREDUMP_TIMESTAMP":"1700000000000000","COREDUMP_PID":"1234","COREDUMP_UID":"0","COREDUMP_SIGNAL":"11","COREDUMP_EXE":"/snap/foo/12/bin/foo","COREDUMP_CGROUP":"/system.slice/snap.foo.svc.service","COREDUMP_FILENAME":"@COREFILE@"}
+{"__CURSOR":"s=3","COREDUMP_TIMESTAMP":"1700000001000000","COREDUMP_PID":"2345","COREDUMP_UID":"1000","COREDUMP_SIGNAL":"6","COREDUMP_EXE":"/usr/bin/other","COREDUMP_CGROUP":"/user.slice/user-1000.slice/session-1.scope"}
+{"__CURSOR":"s=4","COREDUMP_TIMESTAMP":"1700000002000000","COREDUMP_PID":"3456","COREDUMP_UID":"0","COREDUMP_SIGNAL":"6","COREDUMP_EXE":"/snap/foo/1/bin/foo","COREDUMP_CGROUP":"/system.slice/snap.foo_x.svc.service","COREDUMP_FILENAME":"/does/not/exist"}
+`
+
+func (s *SnapSuite) mockJournalctlCoredumps(c *check.C) *testutil.MockCmd {
+	corefile := filepath.Join(c.MkDir(), "core.foo.1234.zst")
+	c.Assert(os.WriteFile(corefile, make([]byte, 4000), 0644), check.IsNil)
+	list := strings.Replace(mockCoredumpsList, "@COREFILE@", corefile, 1)
+	cmd := testutil.MockCommand(c, "journalctl", "cat <<'EOF'"+list+"EOF\n")
+	s.AddCleanup(cmd.Restore)
+	return cmd
+}
+
+func (s *SnapSuite) TestDebugCoredumpsList(c *check.C) {
+	cmd := s.mockJournalctlCoredumps(c)
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "coredumps", "--abs-time", "foo"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(s.Stdout(), check.Matches, `Time +PID +UID +Signal +Size +Core +Exe
+2023-11-1\dT\d\d:56:40Z +1234 +1000 +6 +- +none +/snap/foo/12/bin/app
+2023-11-1\dT\d\d:13:20Z +1234 +0 +11 +4kB +present +/snap/foo/12/bin/foo
+`)
+	c.Check(s.Stderr(), check.Equals, "")
+	c.Check(cmd.Calls(), check.DeepEquals, [][]string{
+		{"journalctl", "--output=json", "--no-pager", "MESSAGE_ID=fc2e22bc6ee647b6b90729ab34a250b1"},
+	})
+}
+
+func (s *SnapSuite) TestDebugCoredumpsListParallelInstance(c *check.C) {
+	s.mockJournalctlCoredumps(c)
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "coredumps", "--abs-time", "foo_x"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stdout(), check.Matches, `Time +PID +UID +Signal +Size +Core +Exe
+2023-11-1\dT\d\d:13:22Z +3456 +0 +6 +- +missing +/snap/foo/1/bin/foo
+`)
+}
+
+func (s *SnapSuite) TestDebugCoredumpsListNone(c *check.C) {
+	s.mockJournalctlCoredumps(c)
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "coredumps", "bar"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stdout(), check.Equals, "")
+	c.Check(s.Stderr(), check.Equals, "No core dumps found for snap \"bar\".\n")
+}
+
+func (s *SnapSuite) TestDebugCoredumpsNoCoredumpsAtAll(c *check.C) {
+	cmd := testutil.MockCommand(c, "journalctl", `echo "-- No entries --"`)
+	defer cmd.Restore()
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "coredumps", "foo"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stderr(), check.Equals, "No core dumps found for snap \"foo\".\n")
+}
+
+func (s *SnapSuite) TestDebugCoredumpsListError(c *check.C) {
+	cmd := testutil.MockCommand(c, "journalctl", `
+echo "Failed to open journal" >&2
+exit 1
+`)
+	defer cmd.Restore()
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "coredumps", "foo"})
+	c.Assert(err, check.ErrorMatches, `(?s)cannot list core dumps: .*Failed to open journal.*`)
+}
+
+func (s *SnapSuite) TestDebugCoredumpsRetrieve(c *check.C) {
+	s.mockJournalctlCoredumps(c)
+	cmd := testutil.MockCommand(c, "coredumpctl", "")
+	defer cmd.Restore()
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "coredumps", "foo", "--pid=1234", "--output=/tmp/core"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stdout(), check.Equals, "Core dump of process 1234 written to /tmp/core.\n")
+	// the latest dump of the process is selected by its timestamp
+	c.Check(cmd.Calls(), check.DeepEquals, [][]string{
+		{"coredumpctl", "--no-pager", "--output=/tmp/core", "dump", "COREDUMP_PID=1234", "COREDUMP_TIMESTAMP=1700000000000000"},
+	})
+}
+
+func (s *SnapSuite) TestDebugCoredumpsRetrieveOtherProcess(c *check.C) {
+	s.mockJournalctlCoredumps(c)
+	cmd := testutil.MockCommand(c, "coredumpctl", "")
+	defer cmd.Restore()
+
+	for _, pid := range []string{"2345", "3456", "9999"} {
+		_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "coredumps", "foo", "--pid=" + pid, "--output=/tmp/core"})
+		c.Assert(err, check.ErrorMatches, `no core dump found for process `+pid+` of snap "foo"`)
+	}
+	// nothing was dumped
+	c.Check(cmd.Calls(), check.HasLen, 0)
+}
+
+func (s *SnapSuite) TestDebugCoredumpsPidWithoutOutput(c *check.C) {
+	cmd := s.mockJournalctlCoredumps(c)
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "coredumps", "foo", "--pid=1234"})
+	c.Assert(err, check.ErrorMatches, "--pid and --output must be used together")
+	_, err = snap.Parser(snap.Client()).ParseArgs([]string{"debug", "coredumps", "foo", "--output=/tmp/core"})
+	c.Assert(err, check.ErrorMatches, "--pid and --output must be used together")
+	c.Check(cmd.Calls(), check.HasLen, 0)
+}
@@ -0,0 +1,98 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/snap"
+)
+
+var shortDebugEpochsHelp = i18n.G("Show the epochs of a snap and its refresh paths")
+var longDebugEpochsHelp = i18n.G(`
+The epochs command shows the epoch of the installed revision of a snap and
+the epochs of the revisions in each channel of the store, along with whether
+the installed revision can be refreshed to them. When a revision cannot be
+refreshed to directly, the revision to refresh to first is shown if there
+is one.
+`)
+
+type cmdDebugEpochs struct {
+	clientMixin
+
+	Positional struct {
+		Snap installedSnapName `positional-arg-name:"<snap>" required:"yes"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+func init() {
+	addDebugCommand("epochs", shortDebugEpochsHelp, longDebugEpochsHelp, func() flags.Commander {
+		return &cmdDebugEpochs{}
+	}, nil, nil)
+}
+
+func (x *cmdDebugEpochs) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	name := string(x.Positional.Snap)
+	local, _, err := x.client.Snap(name)
+	if err != nil {
+		return err
+	}
+	remote, _, err := x.client.FindOne(snap.InstanceSnap(name))
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(Stdout, i18n.G("Installed revision %s has epoch %s\n"), local.Revision, local.Epoch)
+	if len(remote.Channels) == 0 {
+		return nil
+	}
+
+	w := tabWriter()
+	defer w.Flush()
+
+	chNames := make([]string, 0, len(remote.Channels))
+	for chName := range remote.Channels {
+		chNames = append(chNames, chName)
+	}
+	sort.Strings(chNames)
+
+	fmt.Fprintln(w, i18n.G("Channel\tRevision\tEpoch\tRefresh"))
+	for _, chName := range chNames {
+		ch := remote.Channels[chName]
+		refresh := i18n.G("yes")
+		if !ch.Epoch.CanRead(local.Epoch) {
+			refresh = i18n.G("no")
+			if hop := snap.EpochHop(remote.Channels, local.Epoch, ch.Epoch); hop != nil {
+				// TRANSLATORS: %s are a revision and the channel it is in
+				refresh = fmt.Sprintf(i18n.G("via revision %s (%s)"), hop.Revision, hop.Channel)
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", chName, ch.Revision, ch.Epoch, refresh)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snap"
+)
+
+func (s *SnapSuite) TestDebugEpochs(c *check.C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, check.Equals, "GET")
+		switch n {
+		case 0:
+			c.Check(r.URL.Path, check.Equals, "/v2/snaps/foo")
+			fmt.Fprintln(w, `{"type": "sync", "result": {"name": "foo", "revision": "10", "epoch": "0"}}`)
+		case 1:
+			c.Check(r.URL.Path, check.Equals, "/v2/find")
+			c.Check(r.URL.Query().Get("name"), check.Equals, "foo")
+			fmt.Fprintln(w, `{"type": "sync", "result": [{"name": "foo", "revision": "30", "channels": {
+"latest/stable": {"revision": "10", "channel": "latest/stable", "epoch": "0"},
+"latest/candidate": {"revision": "20", "channel": "latest/candidate", "epoch": "1*"},
+"latest/beta": {"revision": "30", "channel": "latest/beta", "epoch": "2*"},
+"latest/edge": {"revision": "40", "channel": "latest/edge", "epoch": "5"}
+}}]}`)
+		default:
+			c.Fatalf("expected to get 2 requests, now on %d", n+1)
+		}
+		n++
+	})
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "epochs", "foo"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(s.Stdout(), check.Equals, `Installed revision 10 has epoch 0
+Channel           Revision  Epoch  Refresh
+latest/beta       30        2*     via revision 20 (latest/candidate)
+latest/candidate  20        1*     yes
+latest/edge       40        5      no
+latest/stable     10        0      yes
+`)
+	c.Check(s.Stderr(), check.Equals, "")
+	c.Check(n, check.Equals, 2)
+}
+
+func (s *SnapSuite) TestDebugEpochsNotInstalled(c *check.C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, check.Equals, "/v2/snaps/foo")
+		w.WriteHeader(404)
+		fmt.Fprintln(w, `{"type": "error", "status-code": 404, "result": {"message": "snap not installed", "kind": "snap-not-found"}}`)
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "epochs", "foo"})
+	c.Assert(err, check.ErrorMatches, `cannot retrieve snap "foo": snap not installed`)
+}
@@ -0,0 +1,103 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/snapdtool"
+)
+
+var snapdtoolReexecStatus = snapdtool.ReexecStatus
+
+type cmdDebugReexec struct {
+	clientMixin
+
+	DisableUntilReboot bool `long:"disable-until-reboot"`
+	Enable             bool `long:"enable"`
+}
+
+func init() {
+	addDebugCommand("reexec",
+		i18n.G("Show whether snap and snapd re-execute into the snapd snap"),
+		i18n.G(`
+The reexec command shows whether snap and snapd run from the snapd snap, or
+why they run from the distribution package instead.
+
+Re-execution into the snapd snap can be disabled until the next boot to debug
+the distribution package, snapd needs to be restarted for it to take effect.
+It can be disabled permanently with 'snap set system snapd.reexec=false'.
+`),
+		func() flags.Commander {
+			return &cmdDebugReexec{}
+		}, map[string]string{
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"disable-until-reboot": i18n.G("Disable re-execution into the snapd snap until the next boot"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"enable": i18n.G("Enable again re-execution into the snapd snap"),
+		}, nil)
+}
+
+func printReexecStatus(tool string, status *snapdtool.ReexecInfo) {
+	fmt.Fprintf(Stdout, "%s:\n", tool)
+	fmt.Fprintf(Stdout, "  exe:     %s\n", status.Exe)
+	fmt.Fprintf(Stdout, "  reexecd: %v\n", status.Reexecd)
+	if status.Reason != "" {
+		fmt.Fprintf(Stdout, "  reason:  %s\n", status.Reason)
+	}
+}
+
+func (x *cmdDebugReexec) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+	if x.DisableUntilReboot && x.Enable {
+		return errors.New(i18n.G("cannot use --disable-until-reboot and --enable together"))
+	}
+	if x.DisableUntilReboot || x.Enable {
+		params := map[string]bool{"disable-reexec-until-reboot": x.DisableUntilReboot}
+		if err := x.client.Debug("reexec", params, nil); err != nil {
+			return err
+		}
+		if x.DisableUntilReboot {
+			fmt.Fprintln(Stdout, i18n.G("Re-execution into the snapd snap is disabled until reboot, restart snapd to apply."))
+		} else {
+			fmt.Fprintln(Stdout, i18n.G("Re-execution into the snapd snap is enabled, restart snapd to apply."))
+		}
+		return nil
+	}
+
+	status, err := snapdtoolReexecStatus()
+	if err != nil {
+		return fmt.Errorf("cannot get re-exec status of snap: %v", err)
+	}
+	printReexecStatus("snap", status)
+
+	var snapdStatus snapdtool.ReexecInfo
+	if err := x.client.DebugGet("reexec", &snapdStatus, nil); err != nil {
+		return fmt.Errorf("cannot get re-exec status of snapd: %v", err)
+	}
+	printReexecStatus("snapd", &snapdStatus)
+	return nil
+}
@@ -0,0 +1,95 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snap"
+	"github.com/snapcore/snapd/snapdtool"
+)
+
+func (s *SnapSuite) TestDebugReexec(c *check.C) {
+	restore := snap.MockSnapdtoolReexecStatus(func() (*snapdtool.ReexecInfo, error) {
+		return &snapdtool.ReexecInfo{
+			Exe:     "/snap/snapd/42/usr/bin/snap",
+			Reexecd: true,
+		}, nil
+	})
+	defer restore()
+
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		c.Check(r.Method, check.Equals, "GET")
+		c.Check(r.URL.Path, check.Equals, "/v2/debug")
+		c.Check(r.URL.RawQuery, check.Equals, "aspect=reexec")
+		fmt.Fprintln(w, `{"type": "sync", "result": {"exe": "/usr/lib/snapd/snapd", "reexecd": false, "reason": "disabled until reboot"}}`)
+	})
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "reexec"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(n, check.Equals, 1)
+	c.Check(s.Stdout(), check.Equals, `snap:
+  exe:     /snap/snapd/42/usr/bin/snap
+  reexecd: true
+snapd:
+  exe:     /usr/lib/snapd/snapd
+  reexecd: false
+  reason:  disabled until reboot
+`)
+	c.Check(s.Stderr(), check.Equals, "")
+}
+
+func (s *SnapSuite) TestDebugReexecDisableUntilReboot(c *check.C) {
+	for _, tc := range []struct {
+		flag string
+		body string
+		out  string
+	}{{
+		flag: "--disable-until-reboot",
+		body: `{"action":"reexec","params":{"disable-reexec-until-reboot":true}}`,
+		out:  "Re-execution into the snapd snap is disabled until reboot, restart snapd to apply.\n",
+	}, {
+		flag: "--enable",
+		body: `{"action":"reexec","params":{"disable-reexec-until-reboot":false}}`,
+		out:  "Re-execution into the snapd snap is enabled, restart snapd to apply.\n",
+	}} {
+		s.ResetStdStreams()
+		s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+			c.Check(r.Method, check.Equals, "POST")
+			c.Check(r.URL.Path, check.Equals, "/v2/debug")
+			data, err := io.ReadAll(r.Body)
+			c.Check(err, check.IsNil)
+			c.Check(string(data), check.Equals, tc.body)
+			fmt.Fprintln(w, `{"type": "sync", "result": true}`)
+		})
+		_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "reexec", tc.flag})
+		c.Assert(err, check.IsNil)
+		c.Check(s.Stdout(), check.Equals, tc.out)
+	}
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "reexec", "--enable", "--disable-until-reboot"})
+	c.Assert(err, check.ErrorMatches, "cannot use --disable-until-reboot and --enable together")
+}
@@ -119,6 +119,12 @@ var timingsTests = []timingsCmdArgs{{
 		"60    Doing         910ms            -  bar    task bar summary\n" +
 		" ^                    1ms            -  foo      foo summary\n" +
 		"  ^                   1ms            -  bar        bar summary\n\n",
+}, {
+	args: "debug timings --ensure=factory-reset",
+	stdout: "ID             Status        Doing      Undoing  Summary\n" +
+		"factory-reset                  5ms            -  \n" +
+		" ^                             5ms            -    create partitions\n" +
+		"70             Done          120ms            -  task reset summary\n\n",
 }, {
 	args: "debug timings --startup=ifacemgr",
 	stdout: "ID        Status        Doing      Undoing  Summary\n" +
@@ -233,6 +239,14 @@ func (s *SnapSuite) mockCmdTimingsAPI(c *C) {
 										{"label":"foo", "summary": "foo summary", "duration": 1000001},
 										{"level":1, "label":"bar", "summary": "bar summary", "duration": 1000002}
 								]}}}]}`)
+			case ensure == "factory-reset" && all == "false":
+				fmt.Fprintln(w, `{"type":"sync","status-code":200,"status":"OK","result":[
+					{"change-id":"3",
+						"total-duration": 5000002,
+						"ensure-timings": [{"label":"create-partitions", "summary": "create partitions", "duration": 5000001}],
+						"change-timings":{
+							"70":{"doing-time":120000000, "status": "Done", "kind": "factory-reset-run-system", "summary": "task reset summary"}
+					}}]}`)
 			case startup == "ifacemgr" && all == "false":
 				fmt.Fprintln(w, `{"type":"sync","status-code":200,"status":"OK","result":[
 					{"total-duration": 8000002, "startup-timings": [
@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/jessevdk/go-flags"
@@ -33,6 +34,7 @@ import (
 	"github.com/snapcore/snapd/i18n"
 	"github.com/snapcore/snapd/image"
 	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snapfile"
 	"github.com/snapcore/snapd/store/tooling"
 )
 
@@ -41,6 +43,7 @@ type cmdDownload struct {
 	Revision  string `long:"revision"`
 	Basename  string `long:"basename"`
 	TargetDir string `long:"target-directory"`
+	Bundle    string `long:"bundle"`
 
 	CohortKey  string `long:"cohort"`
 	Positional struct {
@@ -52,6 +55,15 @@ var shortDownloadHelp = i18n.G("Download the given snap")
 var longDownloadHelp = i18n.G(`
 The download command downloads the given snap and its supporting assertions
 to the current directory with .snap and .assert file extensions, respectively.
+
+An interrupted download is resumed when the command is run again for the same
+revision of the snap, and the snap is verified against the sha3-384 digest
+published by the store as it is downloaded.
+
+With --bundle, the snap and the snaps it needs (its base and the default
+providers of its content plugs) are packaged together with their assertions
+into a single bundle file that can be installed on a machine without access
+to the store using 'snap import-bundle'.
 `)
 
 func init() {
@@ -66,6 +78,8 @@ func init() {
 		"basename": i18n.G("Use this basename for the snap and assertion files (defaults to <snap>_<revision>)"),
 		// TRANSLATORS: This should not start with a lowercase letter.
 		"target-directory": i18n.G("Download to this directory (defaults to the current directory)"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"bundle": i18n.G("Package the snap, its prerequisites and their assertions into this bundle file"),
 	}), []argDesc{{
 		name: "<snap>",
 		// TRANSLATORS: This should not start with a lowercase letter.
@@ -142,6 +156,131 @@ func downloadDirectImpl(snapName string, revision snap.Revision, dlOpts tooling.
 	return nil
 }
 
+// bundlePrerequisites returns the names of the snaps that need to be
+// installed before the given one.
+func bundlePrerequisites(info *snap.Info) []string {
+	var prereqs []string
+	base := info.Base
+	if base == "" && info.Type() == snap.TypeApp {
+		base = "core"
+	}
+	if base != "" && base != "none" {
+		prereqs = append(prereqs, base)
+	}
+	providers := snap.NeededDefaultProviders(info)
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append(prereqs, names...)
+}
+
+// for testing
+var downloadBundle = downloadBundleImpl
+
+func downloadBundleImpl(snapName string, dlOpts tooling.DownloadSnapOptions, bundlePath string) (err error) {
+	tsto, err := tooling.NewToolingStore()
+	if err != nil {
+		return err
+	}
+	tsto.Stdout = Stdout
+
+	db, err := asserts.OpenDatabase(&asserts.DatabaseConfig{
+		Backstore: asserts.NewMemoryBackstore(),
+		Trusted:   sysdb.Trusted(),
+	})
+	if err != nil {
+		return err
+	}
+
+	tmpDir, err := os.MkdirTemp(dlOpts.TargetDir, ".snap-bundle-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	f, err := os.Create(bundlePath)
+	if err != nil {
+		return fmt.Errorf(i18n.G("cannot create bundle: %v"), err)
+	}
+	defer func() {
+		f.Close()
+		if err != nil {
+			os.Remove(bundlePath)
+		}
+	}()
+
+	bw := tooling.NewBundleWriter(f)
+	fetcher := tsto.AssertionFetcher(db, bw.AddAssertion)
+
+	type downloaded struct {
+		path string
+		info *snap.Info
+	}
+	var all []downloaded
+	seen := map[string]bool{snapName: true}
+	toDownload := []string{snapName}
+	for len(toDownload) > 0 {
+		name := toDownload[0]
+		toDownload = toDownload[1:]
+
+		opts := tooling.DownloadSnapOptions{TargetDir: tmpDir}
+		if name == snapName {
+			opts = dlOpts
+			opts.TargetDir = tmpDir
+		}
+		fmt.Fprintf(Stdout, i18n.G("Fetching snap %q\n"), name)
+		dlSnap, err := tsto.DownloadSnap(name, nil, opts)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(Stdout, i18n.G("Fetching assertions for %q\n"), name)
+		// TODO:COMPS: support bundling components
+		if _, err := image.FetchAndCheckSnapAssertions(dlSnap.Path, dlSnap.Info, nil, nil, fetcher, db); err != nil {
+			return err
+		}
+
+		snapf, err := snapfile.Open(dlSnap.Path)
+		if err != nil {
+			return err
+		}
+		info, err := snap.ReadInfoFromSnapFile(snapf, &dlSnap.Info.SideInfo)
+		if err != nil {
+			return err
+		}
+		all = append(all, downloaded{path: dlSnap.Path, info: info})
+
+		for _, prereq := range bundlePrerequisites(info) {
+			if !seen[prereq] {
+				seen[prereq] = true
+				toDownload = append(toDownload, prereq)
+			}
+		}
+	}
+
+	// prerequisites are found after the snaps needing them but need to
+	// be installed first
+	for i := len(all) - 1; i >= 0; i-- {
+		if err := bw.AddSnap(all[i].path, all[i].info); err != nil {
+			return fmt.Errorf(i18n.G("cannot write bundle: %v"), err)
+		}
+	}
+	if err := bw.Close(); err != nil {
+		return fmt.Errorf(i18n.G("cannot write bundle: %v"), err)
+	}
+
+	// simplify path
+	wd, _ := os.Getwd()
+	if p, err := filepath.Rel(wd, bundlePath); err == nil {
+		bundlePath = p
+	}
+	fmt.Fprintf(Stdout, i18n.G(`Install the snaps on another machine with:
+   snap import-bundle %s
+`), bundlePath)
+	return nil
+}
+
 func (x *cmdDownload) downloadFromStore(snapName string, revision snap.Revision) error {
 	dlOpts := tooling.DownloadSnapOptions{
 		TargetDir: x.TargetDir,
@@ -152,6 +291,9 @@ func (x *cmdDownload) downloadFromStore(snapName string, revision snap.Revision)
 		// if something goes wrong, don't force it to start over again
 		LeavePartialOnError: true,
 	}
+	if x.Bundle != "" {
+		return downloadBundle(snapName, dlOpts, x.Bundle)
+	}
 	return downloadDirect(snapName, revision, dlOpts)
 }
 
@@ -159,6 +301,9 @@ func (x *cmdDownload) Execute(args []string) error {
 	if strings.ContainsRune(x.Basename, filepath.Separator) {
 		return errors.New(i18n.G("cannot specify a path in basename (use --target-dir for that)"))
 	}
+	if x.Bundle != "" && x.Basename != "" {
+		return errors.New(i18n.G("cannot specify both basename and bundle"))
+	}
 	if err := x.setChannelFromCommandline(); err != nil {
 		return err
 	}
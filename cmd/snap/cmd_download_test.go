@@ -28,6 +28,7 @@ import (
 
 	snapCmd "github.com/snapcore/snapd/cmd/snap"
 	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
 	"github.com/snapcore/snapd/store/tooling"
 )
 
@@ -128,3 +129,63 @@ func (s *SnapSuite) TestDownloadDirectErrors(c *check.C) {
 	c.Assert(err, check.ErrorMatches, "some-error")
 	c.Check(n, check.Equals, 1)
 }
+
+func (s *SnapSuite) TestDownloadBundle(c *check.C) {
+	var n int
+	restore := snapCmd.MockDownloadDirect(func(snapName string, revision snap.Revision, dlOpts tooling.DownloadSnapOptions) error {
+		c.Fatalf("unexpected direct download")
+		return nil
+	})
+	defer restore()
+	restore = snapCmd.MockDownloadBundle(func(snapName string, dlOpts tooling.DownloadSnapOptions, bundlePath string) error {
+		c.Check(snapName, check.Equals, "a-snap")
+		c.Check(dlOpts.Channel, check.Equals, "some-channel")
+		c.Check(dlOpts.TargetDir, check.Equals, "some-target-dir")
+		c.Check(bundlePath, check.Equals, "a-snap.bundle")
+		n++
+		return nil
+	})
+	defer restore()
+
+	_, err := snapCmd.Parser(snapCmd.Client()).ParseArgs([]string{
+		"download",
+		"--target-directory=some-target-dir",
+		"--channel=some-channel",
+		"--bundle=a-snap.bundle",
+		"a-snap"},
+	)
+	c.Assert(err, check.IsNil)
+	c.Check(n, check.Equals, 1)
+}
+
+func (s *SnapSuite) TestDownloadBundleAndBasename(c *check.C) {
+	_, err := snapCmd.Parser(snapCmd.Client()).ParseArgs([]string{
+		"download", "--bundle=a-snap.bundle", "--basename=foo", "a-snap",
+	})
+	c.Check(err, check.ErrorMatches, "cannot specify both basename and bundle")
+}
+
+func (s *SnapSuite) TestBundlePrerequisites(c *check.C) {
+	info := snaptest.MockInfo(c, `name: foo
+version: 1
+plugs:
+  gtk-3-themes:
+    interface: content
+    target: $SNAP/data-dir/themes
+    default-provider: gtk-common-themes
+  other:
+    interface: content
+    target: $SNAP/other
+    default-provider: another-provider:foo
+`, nil)
+	c.Check(snapCmd.BundlePrerequisites(info), check.DeepEquals, []string{"core", "another-provider", "gtk-common-themes"})
+
+	info = snaptest.MockInfo(c, "name: foo\nversion: 1\nbase: core22\n", nil)
+	c.Check(snapCmd.BundlePrerequisites(info), check.DeepEquals, []string{"core22"})
+
+	info = snaptest.MockInfo(c, "name: foo\nversion: 1\nbase: none\n", nil)
+	c.Check(snapCmd.BundlePrerequisites(info), check.HasLen, 0)
+
+	info = snaptest.MockInfo(c, "name: core22\nversion: 1\ntype: base\n", nil)
+	c.Check(snapCmd.BundlePrerequisites(info), check.HasLen, 0)
+}
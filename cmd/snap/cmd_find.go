@@ -45,6 +45,12 @@ With the --private flag, which requires the user to be logged-in to the store
 has developer access to, either directly or through the store's collaboration
 feature.
 
+The --category, --publisher and --license options restrict the results to
+snaps in the given store category, by the given publisher (as identified by
+their store username), or with the given license, respectively. Without a
+value, --category lists the categories of the store. The --section option is
+deprecated in favour of --category.
+
 A green check mark (given color and unicode support) after a publisher name
 indicates that the publisher has been verified.
 `)
@@ -149,11 +155,58 @@ func showSections(cli *client.Client) error {
 	return nil
 }
 
+type CategoryName string
+
+func (s CategoryName) Complete(match string) []flags.Completion {
+	cli := mkClient()
+	categories, err := cli.Categories()
+	if err != nil {
+		return nil
+	}
+	ret := make([]flags.Completion, 0, len(categories))
+	for _, cat := range categories {
+		if strings.HasPrefix(cat.Name, match) {
+			ret = append(ret, flags.Completion{Item: cat.Name})
+		}
+	}
+	return ret
+}
+
+func getCategories(cli *client.Client) ([]string, error) {
+	categories, err := cli.Categories()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(categories))
+	for _, cat := range categories {
+		names = append(names, cat.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func showCategories(cli *client.Client) error {
+	categories, err := getCategories(cli)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(Stdout, i18n.G("No category specified. Available categories:\n"))
+	for _, cat := range categories {
+		fmt.Fprintf(Stdout, " * %s\n", cat)
+	}
+	fmt.Fprint(Stdout, i18n.G("Please try 'snap find --category=<selected category>'\n"))
+	return nil
+}
+
 type cmdFind struct {
 	clientMixin
-	Private    bool        `long:"private"`
-	Narrow     bool        `long:"narrow"`
-	Section    SectionName `long:"section" optional:"true" optional-value:"show-all-sections-please" default:"no-section-specified" default-mask:"-"`
+	Private    bool         `long:"private"`
+	Narrow     bool         `long:"narrow"`
+	Section    SectionName  `long:"section" optional:"true" optional-value:"show-all-sections-please" default:"no-section-specified" default-mask:"-"`
+	Category   CategoryName `long:"category" optional:"true" optional-value:"show-all-categories-please"`
+	Publisher  string       `long:"publisher"`
+	License    string       `long:"license"`
 	Positional struct {
 		Query []string
 	} `positional-args:"yes"`
@@ -169,7 +222,13 @@ func init() {
 		// TRANSLATORS: This should not start with a lowercase letter.
 		"narrow": i18n.G("Only search for snaps in “stable”."),
 		// TRANSLATORS: This should not start with a lowercase letter.
-		"section": i18n.G("Restrict the search to a given section."),
+		"section": i18n.G("Restrict the search to a given section (deprecated, use --category)."),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"category": i18n.G("Restrict the search to a given category."),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"publisher": i18n.G("Restrict the search to snaps by the given publisher."),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"license": i18n.G("Restrict the search to snaps with the given license."),
 	}), []argDesc{{
 		// TRANSLATORS: This needs to begin with < and end with >
 		name: i18n.G("<query>"),
@@ -199,9 +258,19 @@ func (x *cmdFind) Execute(args []string) error {
 	case "no-section-specified":
 		x.Section = ""
 	}
+	if x.Category == "show-all-categories-please" {
+		if x.Section != "" {
+			return errors.New(i18n.G("cannot use --section and --category together"))
+		}
+		return showCategories(x.client)
+	}
+	if x.Section != "" && x.Category != "" {
+		return errors.New(i18n.G("cannot use --section and --category together"))
+	}
 
 	// magic! `snap find` returns the featured snaps
-	showFeatured := (query == "" && x.Section == "")
+	filtered := x.Category != "" || x.Publisher != "" || x.License != ""
+	showFeatured := (query == "" && x.Section == "" && !filtered)
 	if showFeatured {
 		x.Section = "featured"
 	}
@@ -225,9 +294,12 @@ func (x *cmdFind) Execute(args []string) error {
 	}
 
 	opts := &client.FindOptions{
-		Query:   query,
-		Section: string(x.Section),
-		Private: x.Private,
+		Query:     query,
+		Section:   string(x.Section),
+		Category:  string(x.Category),
+		Publisher: x.Publisher,
+		License:   x.License,
+		Private:   x.Private,
 	}
 
 	if !x.Narrow {
@@ -243,6 +315,21 @@ func (x *cmdFind) Execute(args []string) error {
 		return err
 	}
 	if len(snaps) == 0 {
+		if x.Category != "" {
+			categories, err := getCategories(x.client)
+			if err != nil {
+				return err
+			}
+			if !strutil.SortedListContains(categories, string(x.Category)) {
+				// TRANSLATORS: the %q is the (quoted) name of the category the user entered
+				return fmt.Errorf(i18n.G("No matching category %q, use --category to list existing categories"), x.Category)
+			}
+			// TRANSLATORS: the first %q is the (quoted) query, the
+			// second %q is the (quoted) name of the category the
+			// user entered
+			fmt.Fprintf(Stderr, i18n.G("No matching snaps for %q in category %q\n"), opts.Query, x.Category)
+			return nil
+		}
 		if x.Section == "" {
 			// TRANSLATORS: the %q is the (quoted) query the user entered
 			fmt.Fprintf(Stderr, i18n.G("No matching snaps for %q\n"), opts.Query)
@@ -516,6 +516,44 @@ func (s *SnapSuite) TestFindNothingMeansFeaturedSection(c *check.C) {
 	c.Check(n, check.Equals, 1)
 }
 
+func (s *SnapSuite) TestFindFilters(c *check.C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.Method, check.Equals, "GET")
+			c.Check(r.URL.Path, check.Equals, "/v2/find")
+			c.Check(r.URL.Query(), check.DeepEquals, url.Values{
+				"category":  []string{"productivity"},
+				"publisher": []string{"canonical"},
+				"license":   []string{"MIT"},
+				"scope":     []string{"wide"},
+			})
+			fmt.Fprint(w, findJSON)
+		default:
+			c.Fatalf("expected to get 1 request, now on %d", n+1)
+		}
+		n++
+	})
+
+	// no query with filters does not mean featured snaps
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"find", "--category=productivity", "--publisher=canonical", "--license=MIT"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(s.Stdout(), check.Matches, `Name +Version +Publisher +Notes +Summary
+hello +2.10 +canonical\*\* +- +GNU Hello, the "hello world" snap
+hello-world +6.1 +canonical\*\* +- +Hello world example
+hello-huge +1.0 +noise +- +a really big snap
+`)
+	c.Check(s.Stderr(), check.Equals, "")
+	c.Check(n, check.Equals, 1)
+}
+
+func (s *SnapSuite) TestFindSectionAndCategoryError(c *check.C) {
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"find", "--section=foo", "--category=bar", "hello"})
+	c.Assert(err, check.ErrorMatches, `cannot use --section and --category together`)
+}
+
 func (s *SnapSuite) TestSectionCompletion(c *check.C) {
 	n := 0
 	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
@@ -698,3 +736,118 @@ Please try 'snap find --section=<selected section>'
 	s.ResetStdStreams()
 	c.Check(numHits, check.Equals, 1)
 }
+
+func (s *SnapSuite) TestCategoryCompletion(c *check.C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0, 1:
+			c.Check(r.Method, check.Equals, "GET")
+			c.Check(r.URL.Path, check.Equals, "/v2/categories")
+			fmt.Fprintln(w, `{"type":"sync","result":[{"name":"foo"},{"name":"bar"},{"name":"baz"}]}`)
+		default:
+			c.Fatalf("expected to get 2 requests, now on #%d", n+1)
+		}
+		n++
+	})
+
+	c.Check(snap.CategoryName("").Complete(""), check.DeepEquals, []flags.Completion{
+		{Item: "foo"},
+		{Item: "bar"},
+		{Item: "baz"},
+	})
+
+	c.Check(snap.CategoryName("").Complete("b"), check.DeepEquals, []flags.Completion{
+		{Item: "bar"},
+		{Item: "baz"},
+	})
+}
+
+func (s *SnapSuite) TestFindSnapCategoryOverview(c *check.C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.Method, check.Equals, "GET")
+			c.Check(r.URL.Path, check.Equals, "/v2/categories")
+			fmt.Fprintln(w, `{"type":"sync","result":[{"name":"cat2"},{"name":"cat1"}]}`)
+		default:
+			c.Fatalf("expected to get 1 request, now on #%d", n+1)
+		}
+		n++
+	})
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"find", "--category"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+
+	c.Check(s.Stdout(), check.Equals, `No category specified. Available categories:
+ * cat1
+ * cat2
+Please try 'snap find --category=<selected category>'
+`)
+	c.Check(s.Stderr(), check.Equals, "")
+}
+
+func (s *SnapSuite) TestFindSnapCategoryOverviewWithSection(c *check.C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Fatalf("unexpected request to %s", r.URL.Path)
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"find", "--section=foo", "--category"})
+	c.Assert(err, check.ErrorMatches, `cannot use --section and --category together`)
+}
+
+func (s *SnapSuite) TestFindSnapInvalidCategory(c *check.C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.Method, check.Equals, "GET")
+			c.Check(r.URL.Path, check.Equals, "/v2/find")
+			c.Check(r.URL.Query().Get("category"), check.Equals, "foobar")
+			EncodeResponseBody(c, w, map[string]interface{}{
+				"type":   "sync",
+				"result": []string{},
+			})
+		case 1:
+			c.Check(r.Method, check.Equals, "GET")
+			c.Check(r.URL.Path, check.Equals, "/v2/categories")
+			fmt.Fprintln(w, `{"type":"sync","result":[{"name":"cat1"}]}`)
+		default:
+			c.Fatalf("expected to get 2 requests, now on #%d", n+1)
+		}
+		n++
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"find", "--category=foobar", "hello"})
+	c.Assert(err, check.ErrorMatches, `No matching category "foobar", use --category to list existing categories`)
+}
+
+func (s *SnapSuite) TestFindSnapNotFoundInCategory(c *check.C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.Method, check.Equals, "GET")
+			c.Check(r.URL.Path, check.Equals, "/v2/find")
+			c.Check(r.URL.Query().Get("category"), check.Equals, "foobar")
+			EncodeResponseBody(c, w, map[string]interface{}{
+				"type":   "sync",
+				"result": []string{},
+			})
+		case 1:
+			c.Check(r.Method, check.Equals, "GET")
+			c.Check(r.URL.Path, check.Equals, "/v2/categories")
+			fmt.Fprintln(w, `{"type":"sync","result":[{"name":"foobar"}]}`)
+		default:
+			c.Fatalf("expected to get 2 requests, now on #%d", n+1)
+		}
+		n++
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"find", "--category=foobar", "hello"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stderr(), check.Equals, "No matching snaps for \"hello\" in category \"foobar\"\n")
+	c.Check(s.Stdout(), check.Equals, "")
+}
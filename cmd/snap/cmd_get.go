@@ -49,6 +49,20 @@ Nested values may be retrieved via a dotted path:
 
     $ snap get snap-name author.name
     frank
+
+The --diff option shows which options changed since the snap was last
+refreshed, along with their values before and after the refresh.
+
+The --all option shows all the options of the snap, including the ones with
+a default from the gadget, and whether each value is explicitly set, is the
+default, or was unset. Use -d to get the same information as JSON.
+
+The --history option shows the recent configuration changes of the snap and
+the changes that made them. The options modified by one of those changes can
+be restored to their previous values with "snap unset --restore".
+
+The --user option prints the options from the configuration of the snap for
+the calling user, as set with "snap set --user".
 `)
 
 var longConfdbGetHelp = i18n.G(`
@@ -60,6 +74,7 @@ view paths.
 
 type cmdGet struct {
 	clientMixin
+	timeMixin
 	Positional struct {
 		Snap installedSnapName `required:"yes"`
 		Keys []string
@@ -68,6 +83,10 @@ type cmdGet struct {
 	Typed    bool `short:"t"`
 	Document bool `short:"d"`
 	List     bool `short:"l"`
+	Diff     bool `long:"diff"`
+	All      bool `long:"all"`
+	History  bool `long:"history"`
+	User     bool `long:"user"`
 }
 
 func init() {
@@ -76,14 +95,22 @@ func init() {
 	}
 
 	addCommand("get", shortGetHelp, longGetHelp, func() flags.Commander { return &cmdGet{} },
-		map[string]string{
+		timeDescs.also(map[string]string{
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"d": i18n.G("Always return document, even with single key"),
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"l": i18n.G("Always return list, even with single key"),
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"t": i18n.G("Strict typing with nulls and quoted strings"),
-		}, []argDesc{
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"diff": i18n.G("Show configuration changes since the last refresh"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"all": i18n.G("Show all options, including gadget defaults, and the origin of their values"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"history": i18n.G("Show the recorded configuration changes"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"user": i18n.G("Show the configuration of the snap for the calling user"),
+		}), []argDesc{
 			{
 				name: "<snap>",
 				// TRANSLATORS: This should not start with a lowercase letter.
@@ -254,9 +281,31 @@ func (x *cmdGet) Execute(args []string) error {
 	snapName := string(x.Positional.Snap)
 	confKeys := x.Positional.Keys
 
+	if x.Diff && x.All {
+		return fmt.Errorf("cannot use --diff and --all together")
+	}
+	if x.History && (x.Diff || x.All) {
+		return fmt.Errorf("cannot use --history with --diff or --all")
+	}
+	if x.User && (x.History || x.Diff || x.All) {
+		return fmt.Errorf("cannot use --user with --history, --diff or --all")
+	}
+	if x.History {
+		return x.outputHistory(snapName, confKeys)
+	}
+	if x.Diff {
+		return x.outputDiff(snapName, confKeys)
+	}
+	if x.All {
+		return x.outputAll(snapName, confKeys)
+	}
+
 	var conf map[string]interface{}
 	var err error
 	if isConfdbViewID(snapName) {
+		if x.User {
+			return fmt.Errorf("cannot use --user with a confdb view")
+		}
 		if err := validateConfdbFeatureFlag(); err != nil {
 			return err
 		}
@@ -268,6 +317,8 @@ func (x *cmdGet) Execute(args []string) error {
 		}
 
 		conf, err = x.client.ConfdbGetViaView(confdbViewID, confKeys)
+	} else if x.User {
+		conf, err = x.client.UserConf(snapName, confKeys)
 	} else {
 		conf, err = x.client.Conf(snapName, confKeys)
 	}
@@ -286,6 +337,158 @@ func (x *cmdGet) Execute(args []string) error {
 	}
 }
 
+// fmtConfDiffValue formats a configuration value for the tables printed by
+// "snap get --diff" and "snap get --all", using "-" for options that are not
+// set.
+func fmtConfDiffValue(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "-", nil
+	case string:
+		return v, nil
+	}
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
+
+// outputDiff prints the configuration options of the snap that changed since
+// its last refresh.
+func (x *cmdGet) outputDiff(snapName string, confKeys []string) error {
+	if len(confKeys) != 0 {
+		return fmt.Errorf("cannot use --diff with configuration keys")
+	}
+	if x.Typed || x.List {
+		return fmt.Errorf("cannot use --diff with -t or -l")
+	}
+	if isConfdbViewID(snapName) {
+		return fmt.Errorf("cannot use --diff with a confdb view")
+	}
+
+	diff, err := x.client.ConfDiff(snapName)
+	if err != nil {
+		return err
+	}
+	if x.Document {
+		return x.outputJson(diff)
+	}
+	if len(diff.Changes) == 0 {
+		fmt.Fprintf(Stderr, i18n.G("No configuration changes since revision %s.\n"), diff.Revision)
+		return nil
+	}
+
+	w := tabWriter()
+	defer w.Flush()
+
+	// TRANSLATORS: the %s is the revision of the snap before its last refresh
+	fmt.Fprintf(w, i18n.G("Key\tRevision %s\tCurrent\n"), diff.Revision)
+	for _, ch := range diff.Changes {
+		old, err := fmtConfDiffValue(ch.Old)
+		if err != nil {
+			return err
+		}
+		new, err := fmtConfDiffValue(ch.New)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", ch.Key, old, new)
+	}
+	return nil
+}
+
+// outputAll prints all the configuration options of the snap, including the
+// ones only set by the gadget defaults, along with the origin of their values.
+func (x *cmdGet) outputAll(snapName string, confKeys []string) error {
+	if len(confKeys) != 0 {
+		return fmt.Errorf("cannot use --all with configuration keys")
+	}
+	if x.Typed || x.List {
+		return fmt.Errorf("cannot use --all with -t or -l")
+	}
+	if isConfdbViewID(snapName) {
+		return fmt.Errorf("cannot use --all with a confdb view")
+	}
+
+	values, err := x.client.ConfWithDefaults(snapName)
+	if err != nil {
+		return err
+	}
+	if x.Document {
+		return x.outputJson(values)
+	}
+	if len(values) == 0 {
+		fmt.Fprintf(Stderr, i18n.G("Snap %q has no configuration.\n"), snapName)
+		return nil
+	}
+
+	w := tabWriter()
+	defer w.Flush()
+
+	fmt.Fprintln(w, i18n.G("Key\tValue\tDefault\tOrigin"))
+	for _, v := range values {
+		value, err := fmtConfDiffValue(v.Value)
+		if err != nil {
+			return err
+		}
+		def, err := fmtConfDiffValue(v.Default)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", v.Key, value, def, v.Origin)
+	}
+	return nil
+}
+
+// outputHistory prints the recorded configuration changes of the snap.
+func (x *cmdGet) outputHistory(snapName string, confKeys []string) error {
+	if len(confKeys) != 0 {
+		return fmt.Errorf("cannot use --history with configuration keys")
+	}
+	if x.Typed || x.List {
+		return fmt.Errorf("cannot use --history with -t or -l")
+	}
+	if isConfdbViewID(snapName) {
+		return fmt.Errorf("cannot use --history with a confdb view")
+	}
+
+	history, err := x.client.ConfHistory(snapName)
+	if err != nil {
+		return err
+	}
+	if x.Document {
+		return x.outputJson(history)
+	}
+	if len(history) == 0 {
+		fmt.Fprintf(Stderr, i18n.G("No configuration changes recorded for snap %q.\n"), snapName)
+		return nil
+	}
+
+	w := tabWriter()
+	defer w.Flush()
+
+	fmt.Fprintln(w, i18n.G("Change\tTime\tActor\tKey\tOld\tNew"))
+	for _, entry := range history {
+		change := entry.Change
+		if change == "" {
+			change = "-"
+		}
+		for _, ch := range entry.Changes {
+			old, err := fmtConfDiffValue(ch.Old)
+			if err != nil {
+				return err
+			}
+			new, err := fmtConfDiffValue(ch.New)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", change, x.fmtTime(entry.Time), entry.Actor, ch.Key, old, new)
+		}
+	}
+	return nil
+}
+
 func validateConfdbFeatureFlag() error {
 	if !features.Confdbs.IsEnabled() {
 		_, confName := features.Confdbs.ConfigOption()
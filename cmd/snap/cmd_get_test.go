@@ -146,6 +146,160 @@ func (s *SnapSuite) TestSnapGetNoConfiguration(c *C) {
 	s.runTests(getNoConfigTests, c)
 }
 
+func (s *SnapSuite) mockGetConfigDiffServer(c *C, result string) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/snaps/snapname/conf")
+		c.Check(r.URL.Query().Get("diff"), Equals, "true")
+		fmt.Fprintf(w, `{"type":"sync", "status-code": 200, "result": %s}`, result)
+	})
+}
+
+func (s *SnapSuite) TestSnapGetDiff(c *C) {
+	s.mockGetConfigDiffServer(c, `{"revision": "1", "changes": [
+		{"key": "bar", "old": 100, "new": 101},
+		{"key": "foo.key1", "old": "value1"},
+		{"key": "foo.key3", "new": {"a": true}}
+	]}`)
+	s.runTests([]getCmdArgs{{
+		args: "get --diff snapname",
+		stdout: "Key       Revision 1  Current\n" +
+			"bar       100         101\n" +
+			"foo.key1  value1      -\n" +
+			"foo.key3  -           {\"a\":true}\n",
+	}, {
+		args:   "get --diff -d snapname",
+		stdout: "{\n\t\"revision\": \"1\",\n\t\"changes\": [\n\t\t{\n\t\t\t\"key\": \"bar\",\n\t\t\t\"old\": 100,\n\t\t\t\"new\": 101\n\t\t},\n\t\t{\n\t\t\t\"key\": \"foo.key1\",\n\t\t\t\"old\": \"value1\"\n\t\t},\n\t\t{\n\t\t\t\"key\": \"foo.key3\",\n\t\t\t\"new\": {\n\t\t\t\t\"a\": true\n\t\t\t}\n\t\t}\n\t]\n}\n",
+	}, {
+		args:  "get --diff snapname foo",
+		error: "cannot use --diff with configuration keys",
+	}, {
+		args:  "get --diff -l snapname",
+		error: "cannot use --diff with -t or -l",
+	}}, c)
+}
+
+func (s *SnapSuite) TestSnapGetDiffNoChanges(c *C) {
+	s.mockGetConfigDiffServer(c, `{"revision": "1", "changes": []}`)
+	s.runTests([]getCmdArgs{{
+		args:   "get --diff snapname",
+		stderr: "No configuration changes since revision 1.\n",
+	}}, c)
+}
+
+func (s *SnapSuite) mockGetConfigAllServer(c *C, result string) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/snaps/snapname/conf")
+		c.Check(r.URL.Query().Get("defaults"), Equals, "true")
+		fmt.Fprintf(w, `{"type":"sync", "status-code": 200, "result": %s}`, result)
+	})
+}
+
+func (s *SnapSuite) TestSnapGetAll(c *C) {
+	s.mockGetConfigAllServer(c, `[
+		{"key": "bar", "value": 100, "default": 100, "origin": "default"},
+		{"key": "foo.key1", "value": "value1", "origin": "explicit"},
+		{"key": "foo.key2", "default": true, "origin": "unset"}
+	]`)
+	s.runTests([]getCmdArgs{{
+		args: "get --all snapname",
+		stdout: "Key       Value   Default  Origin\n" +
+			"bar       100     100      default\n" +
+			"foo.key1  value1  -        explicit\n" +
+			"foo.key2  -       true     unset\n",
+	}, {
+		args:   "get --all -d snapname",
+		stdout: "[\n\t{\n\t\t\"key\": \"bar\",\n\t\t\"value\": 100,\n\t\t\"default\": 100,\n\t\t\"origin\": \"default\"\n\t},\n\t{\n\t\t\"key\": \"foo.key1\",\n\t\t\"value\": \"value1\",\n\t\t\"origin\": \"explicit\"\n\t},\n\t{\n\t\t\"key\": \"foo.key2\",\n\t\t\"default\": true,\n\t\t\"origin\": \"unset\"\n\t}\n]\n",
+	}, {
+		args:  "get --all snapname foo",
+		error: "cannot use --all with configuration keys",
+	}, {
+		args:  "get --all -t snapname",
+		error: "cannot use --all with -t or -l",
+	}, {
+		args:  "get --all --diff snapname",
+		error: "cannot use --diff and --all together",
+	}}, c)
+}
+
+func (s *SnapSuite) TestSnapGetAllNoConfig(c *C) {
+	s.mockGetConfigAllServer(c, `[]`)
+	s.runTests([]getCmdArgs{{
+		args:   "get --all snapname",
+		stderr: "Snap \"snapname\" has no configuration.\n",
+	}}, c)
+}
+
+func (s *SnapSuite) TestSnapGetHistory(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/snaps/snapname/conf")
+		c.Check(r.URL.Query().Get("history"), Equals, "true")
+		fmt.Fprint(w, `{"type":"sync", "status-code": 200, "result": [
+			{"change": "42", "actor": "configure-snap", "time": "2024-01-02T03:04:05Z", "changes": [
+				{"key": "bar", "old": 100, "new": 101},
+				{"key": "foo", "new": "x"}
+			]},
+			{"actor": "snapctl", "time": "2024-01-03T03:04:05Z", "changes": [
+				{"key": "foo", "old": "x"}
+			]}
+		]}`)
+	})
+	s.runTests([]getCmdArgs{{
+		args: "get --history --abs-time snapname",
+		stdout: "Change  Time                  Actor           Key  Old  New\n" +
+			"42      2024-01-02T03:04:05Z  configure-snap  bar  100  101\n" +
+			"42      2024-01-02T03:04:05Z  configure-snap  foo  -    x\n" +
+			"-       2024-01-03T03:04:05Z  snapctl         foo  x    -\n",
+	}, {
+		args:  "get --history snapname foo",
+		error: "cannot use --history with configuration keys",
+	}, {
+		args:  "get --history --diff snapname",
+		error: "cannot use --history with --diff or --all",
+	}}, c)
+}
+
+func (s *SnapSuite) TestSnapGetHistoryEmpty(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"type":"sync", "status-code": 200, "result": []}`)
+	})
+	s.runTests([]getCmdArgs{{
+		args:   "get --history snapname",
+		stderr: "No configuration changes recorded for snap \"snapname\".\n",
+	}}, c)
+}
+
+func (s *SnapSuite) TestSnapGetUser(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/snaps/snapname/user-conf")
+		c.Check(r.URL.Query().Get("keys"), Equals, "theme")
+		fmt.Fprint(w, `{"type":"sync", "status-code": 200, "result": {"theme": "dark"}}`)
+	})
+	s.runTests([]getCmdArgs{{
+		args:   "get --user snapname theme",
+		stdout: "dark\n",
+	}}, c)
+}
+
+func (s *SnapSuite) TestSnapGetUserErrors(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Fatalf("unexpected request to %s", r.URL.Path)
+	})
+	s.runTests([]getCmdArgs{{
+		args:  "get --user --diff snapname",
+		error: "cannot use --user with --history, --diff or --all",
+	}, {
+		args:  "get --user --history snapname",
+		error: "cannot use --user with --history, --diff or --all",
+	}, {
+		args:  "get --user acc/confdb/view key",
+		error: "cannot use --user with a confdb view",
+	}}, c)
+}
+
 func (s *SnapSuite) TestSortByPath(c *C) {
 	values := []snapset.ConfigValue{
 		{Path: "test-key3.b"},
@@ -0,0 +1,122 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/store/tooling"
+)
+
+type cmdImportBundle struct {
+	colorMixin
+	waitMixin
+
+	Positional struct {
+		Bundle flags.Filename
+	} `positional-args:"true" required:"true"`
+}
+
+var shortImportBundleHelp = i18n.G("Install the snaps of a bundle")
+var longImportBundleHelp = i18n.G(`
+The import-bundle command adds the assertions carried by a bundle created with
+'snap download --bundle' to the system and installs its snaps, without
+contacting the store.
+
+The snaps are installed together in a single change, so that they are set
+up after the bases and other snaps of the bundle they need. Snaps of the
+bundle that are already installed at the same revision are left alone.
+`)
+
+func init() {
+	addCommand("import-bundle", shortImportBundleHelp, longImportBundleHelp, func() flags.Commander {
+		return &cmdImportBundle{}
+	}, colorDescs.also(waitDescs), []argDesc{{
+		// TRANSLATORS: This needs to begin with < and end with >
+		name: i18n.G("<bundle file>"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		desc: i18n.G("Bundle file"),
+	}})
+}
+
+func (x *cmdImportBundle) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+	f, err := os.Open(string(x.Positional.Bundle))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	tmpDir, err := os.MkdirTemp("", "snap-import-bundle-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	bundle, err := tooling.ExtractBundle(f, tmpDir)
+	if err != nil {
+		return err
+	}
+
+	if err := ackFile(x.client, bundle.AssertsPath); err != nil {
+		return fmt.Errorf(i18n.G("cannot add the assertions of the bundle: %v"), err)
+	}
+
+	var paths []string
+	for _, bs := range bundle.Snaps {
+		if snap, _, err := x.client.Snap(bs.Name); err == nil && snap.Revision == bs.Revision {
+			fmt.Fprintf(Stdout, i18n.G("snap %q is already installed at revision %s\n"), bs.Name, bs.Revision)
+			continue
+		}
+		paths = append(paths, bs.Path)
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+
+	// install all the snaps in one change so that snapd orders them after
+	// their bases and other prerequisites, which are not available from
+	// the store on an offline machine.
+	// don't log the request's body because the encoded snaps are large.
+	x.client.SetMayLogBody(false)
+	changeID, err := x.client.InstallPathMany(paths, &client.SnapOptions{})
+	if err != nil {
+		return fmt.Errorf(i18n.G("cannot install the snaps of the bundle: %v"), err)
+	}
+	chg, err := x.wait(changeID)
+	if err != nil {
+		if err == noWait {
+			return nil
+		}
+		return err
+	}
+	changedSnaps, err := changedSnapsFromChange(chg)
+	if err != nil {
+		return fmt.Errorf("cannot extract the snap-name from change: %w", err)
+	}
+	return showDone(x.client, chg, changedSnaps, "install", nil, x.getEscapes())
+}
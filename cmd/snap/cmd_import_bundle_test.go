@@ -0,0 +1,178 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts/assertstest"
+	snapCmd "github.com/snapcore/snapd/cmd/snap"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/store/tooling"
+)
+
+func (s *SnapOpSuite) makeBundle(c *check.C) string {
+	dir := c.MkDir()
+	bundlePath := filepath.Join(dir, "foo.bundle")
+	f, err := os.Create(bundlePath)
+	c.Assert(err, check.IsNil)
+	defer f.Close()
+
+	bw := tooling.NewBundleWriter(f)
+	for _, sn := range []struct {
+		name string
+		rev  int
+	}{{"core22", 10}, {"foo", 2}} {
+		info := &snap.Info{SideInfo: snap.SideInfo{RealName: sn.name, Revision: snap.R(sn.rev)}}
+		p := filepath.Join(dir, info.Filename())
+		c.Assert(os.WriteFile(p, []byte(sn.name+"-data"), 0644), check.IsNil)
+		c.Assert(bw.AddSnap(p, info), check.IsNil)
+	}
+	storeSigning := assertstest.NewStoreStack("canonical", nil)
+	c.Assert(bw.AddAssertion(storeSigning.TrustedAccount), check.IsNil)
+	c.Assert(bw.Close(), check.IsNil)
+	return bundlePath
+}
+
+type importBundleServer struct {
+	installed map[string]string
+	acked     int
+	posts     int
+	filenames []string
+	contents  []string
+}
+
+func (s *SnapOpSuite) redirectToImportBundleServer(c *check.C, srv *importBundleServer) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/v2/assertions":
+			body, err := io.ReadAll(r.Body)
+			c.Assert(err, check.IsNil)
+			c.Check(string(body), check.Matches, "(?s)type: account\n.*")
+			srv.acked++
+			fmt.Fprintln(w, `{"type": "sync", "result": {}}`)
+		case r.Method == "GET" && strings.HasPrefix(r.URL.Path, "/v2/snaps/"):
+			name := strings.TrimPrefix(r.URL.Path, "/v2/snaps/")
+			if rev, ok := srv.installed[name]; ok {
+				fmt.Fprintf(w, `{"type": "sync", "result": {"name": %q, "revision": %q}}`+"\n", name, rev)
+				return
+			}
+			w.WriteHeader(404)
+			fmt.Fprintln(w, `{"type": "error", "result": {"message": "snap not installed", "kind": "snap-not-found"}, "status-code": 404}`)
+		case r.Method == "POST" && r.URL.Path == "/v2/snaps":
+			srv.posts++
+			form := testForm(r, c)
+			defer form.RemoveAll()
+			c.Check(form.Value["action"], check.DeepEquals, []string{"install"})
+			_, filenames, contents := formFiles(form, c)
+			srv.filenames = append(srv.filenames, filenames...)
+			for _, content := range contents {
+				srv.contents = append(srv.contents, string(content))
+			}
+			w.WriteHeader(202)
+			fmt.Fprintln(w, `{"type":"async", "change": "42", "status-code": 202}`)
+		case r.Method == "GET" && r.URL.Path == "/v2/changes/42":
+			fmt.Fprintln(w, `{"type": "sync", "result": {"ready": true, "status": "Done", "data": {"snap-names": ["foo"]}}}`)
+		case r.Method == "GET" && r.URL.Path == "/v2/snaps":
+			fmt.Fprintln(w, `{"type": "sync", "result": [{"name": "foo", "status": "active", "version": "1.0", "developer": "bar", "publisher": {"id": "bar-id", "username": "bar", "display-name": "Bar", "validation": "unproven"}, "revision":2, "channel": "stable", "tracking-channel": "stable"}]}`)
+		default:
+			c.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	})
+}
+
+func (s *SnapOpSuite) TestImportBundle(c *check.C) {
+	bundlePath := s.makeBundle(c)
+
+	// core22 is already installed at the bundled revision
+	srv := &importBundleServer{installed: map[string]string{"core22": "10"}}
+	s.redirectToImportBundleServer(c, srv)
+
+	rest, err := snapCmd.Parser(snapCmd.Client()).ParseArgs([]string{"import-bundle", bundlePath})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(srv.acked, check.Equals, 1)
+	c.Check(srv.posts, check.Equals, 1)
+	c.Check(srv.filenames, check.DeepEquals, []string{"foo_2.snap"})
+	c.Check(srv.contents, check.DeepEquals, []string{"foo-data"})
+	c.Check(s.Stdout(), check.Matches, `(?s)snap "core22" is already installed at revision 10\n.*foo 1.0 from Bar installed\n$`)
+}
+
+func (s *SnapOpSuite) TestImportBundleInstallsInOneChange(c *check.C) {
+	bundlePath := s.makeBundle(c)
+
+	// core22 is installed at another revision
+	srv := &importBundleServer{installed: map[string]string{"core22": "9"}}
+	s.redirectToImportBundleServer(c, srv)
+
+	_, err := snapCmd.Parser(snapCmd.Client()).ParseArgs([]string{"import-bundle", bundlePath})
+	c.Assert(err, check.IsNil)
+	// the base and the snap using it go into a single request, snapd
+	// orders their installation
+	c.Check(srv.posts, check.Equals, 1)
+	c.Check(srv.filenames, check.DeepEquals, []string{"core22_10.snap", "foo_2.snap"})
+	c.Check(srv.contents, check.DeepEquals, []string{"core22-data", "foo-data"})
+}
+
+func (s *SnapOpSuite) TestImportBundleAllInstalled(c *check.C) {
+	bundlePath := s.makeBundle(c)
+
+	srv := &importBundleServer{installed: map[string]string{"core22": "10", "foo": "2"}}
+	s.redirectToImportBundleServer(c, srv)
+
+	_, err := snapCmd.Parser(snapCmd.Client()).ParseArgs([]string{"import-bundle", bundlePath})
+	c.Assert(err, check.IsNil)
+	c.Check(srv.acked, check.Equals, 1)
+	c.Check(srv.posts, check.Equals, 0)
+	c.Check(s.Stdout(), check.Equals, `snap "core22" is already installed at revision 10
+snap "foo" is already installed at revision 2
+`)
+}
+
+func (s *SnapOpSuite) TestImportBundleNoWait(c *check.C) {
+	bundlePath := s.makeBundle(c)
+
+	srv := &importBundleServer{}
+	s.redirectToImportBundleServer(c, srv)
+
+	_, err := snapCmd.Parser(snapCmd.Client()).ParseArgs([]string{"import-bundle", "--no-wait", bundlePath})
+	c.Assert(err, check.IsNil)
+	c.Check(srv.posts, check.Equals, 1)
+	c.Check(s.Stdout(), check.Equals, "42\n")
+}
+
+func (s *SnapOpSuite) TestImportBundleBadBundle(c *check.C) {
+	bundlePath := filepath.Join(c.MkDir(), "foo.bundle")
+	c.Assert(os.WriteFile(bundlePath, nil, 0644), check.IsNil)
+
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+	})
+
+	_, err := snapCmd.Parser(snapCmd.Client()).ParseArgs([]string{"import-bundle", bundlePath})
+	c.Check(err, check.ErrorMatches, "cannot read bundle: no manifest")
+}
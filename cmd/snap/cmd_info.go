@@ -23,6 +23,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -40,6 +41,7 @@ import (
 	"github.com/snapcore/snapd/i18n"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/channel"
 	"github.com/snapcore/snapd/snap/snapfile"
 	"github.com/snapcore/snapd/snap/squashfs"
 	"github.com/snapcore/snapd/strutil"
@@ -51,6 +53,7 @@ type infoCmd struct {
 	timeMixin
 
 	Verbose    bool `long:"verbose"`
+	Provenance bool `long:"provenance"`
 	Positional struct {
 		Snaps []anySnapName `positional-arg-name:"<snap>" required:"1"`
 	} `positional-args:"yes" required:"yes"`
@@ -75,6 +78,8 @@ func init() {
 		}, colorDescs.also(timeDescs).also(map[string]string{
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"verbose": i18n.G("Include more details on the snap (expanded notes, base, etc.)"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"provenance": i18n.G("Include the build provenance document of the snap, if any"),
 		}), nil)
 }
 
@@ -159,11 +164,12 @@ type infoWriter struct {
 	path       string
 	// fields that don't change and so can be set once
 	writeflusher
-	esc       *escapes
-	termWidth int
-	fmtTime   func(time.Time) string
-	absTime   bool
-	verbose   bool
+	esc        *escapes
+	termWidth  int
+	fmtTime    func(time.Time) string
+	absTime    bool
+	verbose    bool
+	provenance bool
 }
 
 func (iw *infoWriter) setupDiskSnap(path string, diskSnap *client.Snap) {
@@ -258,9 +264,70 @@ func (iw *infoWriter) maybePrintTrackingChannel() {
 	if iw.localSnap.TrackingChannel == "" {
 		return
 	}
+	if iw.trackClosed(iw.localSnap.TrackingChannel) {
+		fmt.Fprintf(iw, "tracking:\t%s (track closed)\n", iw.localSnap.TrackingChannel)
+		return
+	}
 	fmt.Fprintf(iw, "tracking:\t%s\n", iw.localSnap.TrackingChannel)
 }
 
+// trackClosed returns whether the store reports the track of the given
+// channel as closed.
+func (iw *infoWriter) trackClosed(ch string) bool {
+	if iw.remoteSnap == nil {
+		return false
+	}
+	parsed, err := channel.ParseVerbatim(ch, "")
+	if err != nil {
+		return false
+	}
+	track := parsed.Track
+	if track == "" {
+		track = "latest"
+	}
+	ti := iw.remoteSnap.TrackInfo[track]
+	return ti != nil && ti.Closed()
+}
+
+// maybePrintTrackLifecycle prints the tracks that were closed in the store
+// or for which the publisher announced an end of life.
+func (iw *infoWriter) maybePrintTrackLifecycle() {
+	if iw.remoteSnap == nil {
+		return
+	}
+	dateFmt := "2006-01-02"
+	if iw.absTime {
+		dateFmt = time.RFC3339
+	}
+	names := make([]string, 0, len(iw.remoteSnap.TrackInfo))
+	for name, ti := range iw.remoteSnap.TrackInfo {
+		if ti.Closed() || !ti.EndOfLife.IsZero() {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+	sort.Strings(names)
+
+	iw.Flush()
+	fmt.Fprintln(iw, "tracks:")
+	for _, name := range names {
+		ti := iw.remoteSnap.TrackInfo[name]
+		var status string
+		switch {
+		case ti.Closed():
+			status = i18n.G("closed")
+		case ti.EndOfLife.Before(timeNow()):
+			status = fmt.Sprintf(i18n.G("reached end of life on %s"), ti.EndOfLife.Format(dateFmt))
+		default:
+			status = fmt.Sprintf(i18n.G("end of life on %s"), ti.EndOfLife.Format(dateFmt))
+		}
+		fmt.Fprintf(iw, "  %s:\t%s\n", name, status)
+	}
+	iw.Flush()
+}
+
 func (iw *infoWriter) maybePrintRefreshInfo() {
 	if iw.localSnap == nil {
 		return
@@ -518,6 +585,16 @@ func (iw *infoWriter) maybePrintCohortKey() {
 	fmt.Fprintf(iw, "cohort:\t%s\n", coh)
 }
 
+func (iw *infoWriter) maybePrintTmpUsage() {
+	if !iw.verbose {
+		return
+	}
+	if iw.localSnap == nil || iw.localSnap.TmpSize == 0 {
+		return
+	}
+	fmt.Fprintf(iw, "tmp-usage:\t%s of %s\n", strutil.SizeToStr(iw.localSnap.TmpUsage), strutil.SizeToStr(iw.localSnap.TmpSize))
+}
+
 func (iw *infoWriter) maybePrintSum() {
 	if !iw.verbose {
 		return
@@ -537,6 +614,33 @@ func (iw *infoWriter) maybePrintSum() {
 	fmt.Fprintf(iw, "sha3-384:\t%s\n", sha3_384)
 }
 
+func (iw *infoWriter) maybePrintBuildProvenance() {
+	if !iw.provenance {
+		return
+	}
+	var doc []byte
+	switch {
+	case iw.diskSnap != nil:
+		snapf, err := snapfile.Open(iw.path)
+		if err == nil {
+			doc, err = snapf.ReadFile(snap.BuildProvenancePath)
+		}
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(iw, i18n.G("warning:\tcannot read build provenance: %v\n"), err)
+			return
+		}
+	case iw.localSnap != nil:
+		doc = []byte(iw.localSnap.BuildProvenance)
+	}
+	if len(doc) == 0 {
+		return
+	}
+	fmt.Fprintln(iw, "build-provenance: |")
+	for _, line := range strings.Split(strings.TrimRightFunc(string(doc), unicode.IsSpace), "\n") {
+		fmt.Fprintf(iw, "  %s\n", line)
+	}
+}
+
 var channelRisks = []string{"stable", "candidate", "beta", "edge"}
 
 type channelInfo struct {
@@ -633,6 +737,7 @@ func (x *infoCmd) Execute([]string) error {
 		esc:          esc,
 		termWidth:    termWidth,
 		verbose:      x.Verbose,
+		provenance:   x.Provenance,
 		fmtTime:      x.fmtTime,
 		absTime:      x.AbsTime,
 	}
@@ -688,10 +793,13 @@ func (x *infoCmd) Execute([]string) error {
 		iw.maybePrintType()
 		iw.maybePrintBase()
 		iw.maybePrintSum()
+		iw.maybePrintBuildProvenance()
 		iw.maybePrintID()
 		iw.maybePrintCohortKey()
+		iw.maybePrintTmpUsage()
 		iw.maybePrintTrackingChannel()
 		iw.maybePrintRefreshInfo()
+		iw.maybePrintTrackLifecycle()
 		iw.maybePrintChinfo()
 	}
 	w.Flush()
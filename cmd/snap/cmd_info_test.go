@@ -25,6 +25,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"gopkg.in/check.v1"
@@ -893,6 +894,67 @@ installed:    2.10 (100) 1kB disabled
 	c.Check(s.Stderr(), check.Equals, "")
 }
 
+func (s *infoSuite) TestInfoWithLocalBuildProvenance(c *check.C) {
+	localJSON := strings.Replace(mockInfoJSONNoLicense, `"tracking-channel": "beta"`,
+		`"tracking-channel": "beta",
+      "build-provenance": "{\n  \"predicateType\": \"https://slsa.dev/provenance/v1\"\n}\n"`, 1)
+
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0, 2:
+			c.Check(r.Method, check.Equals, "GET")
+			c.Check(r.URL.Path, check.Equals, "/v2/find")
+			fmt.Fprint(w, mockInfoJSON)
+		case 1, 3:
+			c.Check(r.Method, check.Equals, "GET")
+			c.Check(r.URL.Path, check.Equals, "/v2/snaps/hello")
+			fmt.Fprint(w, localJSON)
+		default:
+			c.Fatalf("expected to get 4 requests, now on %d (%v)", n+1, r)
+		}
+
+		n++
+	})
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"info", "--abs-time", "--provenance", "hello"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(s.Stdout(), check.Equals, `name:      hello
+summary:   The GNU Hello snap
+publisher: Canonical**
+license:   unset
+description: |
+  GNU hello prints a friendly greeting. This is part of the snapcraft tour at
+  https://snapcraft.io/
+build-provenance: |
+  {
+    "predicateType": "https://slsa.dev/provenance/v1"
+  }
+snap-id:      mVyGrEwiqSi5PugCwyH7WgpoQLemtTd6
+tracking:     beta
+refresh-date: 2006-01-02T22:04:07Z
+installed:    2.10 (100) 1kB disabled
+`)
+	c.Check(s.Stderr(), check.Equals, "")
+
+	// the document is only shown on request
+	s.ResetStdStreams()
+	_, err = snap.Parser(snap.Client()).ParseArgs([]string{"info", "--abs-time", "hello"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stdout(), check.Not(check.Matches), `(?s).*build-provenance.*`)
+}
+
+func (s *infoSuite) TestInfoPathBuildProvenance(c *check.C) {
+	dir := c.MkDir()
+	snaptest.PopulateDir(dir, [][]string{
+		{"meta/snap.yaml", "name: some-snap\nversion: 9\nsummary: some summary\n"},
+		{"meta/build-provenance.json", `{"builder": "launchpad"}`},
+	})
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"info", "--provenance", dir})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stdout(), check.Matches, `(?s).*build-provenance: \|\n  {"builder": "launchpad"}\n.*`)
+}
+
 func (s *infoSuite) TestInfoWithChannelsAndLocal(c *check.C) {
 	n := 0
 	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
@@ -989,6 +1051,64 @@ installed:     2.10            (100)  1kB disabled
 	c.Check(n, check.Equals, 6)
 }
 
+func (s *infoSuite) TestInfoWithTrackLifecycle(c *check.C) {
+	restore := snap.MockTimeNow(func() time.Time {
+		return time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	})
+	defer restore()
+
+	findJSON := strings.Replace(mockInfoJSONWithChannels, `"tracks": ["1"]`, `"tracks": ["1"],
+      "track-info": {
+        "latest": {"name": "latest", "status": "closed"},
+        "1": {"name": "1", "status": "default", "end-of-life": "2025-04-30T00:00:00Z"},
+        "0": {"name": "0", "status": "active", "end-of-life": "2024-04-30T00:00:00Z"},
+        "2": {"name": "2", "status": "active"}
+      }`, 1)
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.Method, check.Equals, "GET")
+			c.Check(r.URL.Path, check.Equals, "/v2/find")
+			fmt.Fprint(w, findJSON)
+		case 1:
+			c.Check(r.Method, check.Equals, "GET")
+			c.Check(r.URL.Path, check.Equals, "/v2/snaps/hello")
+			fmt.Fprint(w, mockInfoJSONNoLicense)
+		default:
+			c.Fatalf("expected to get 2 requests, now on %d (%v)", n+1, r)
+		}
+
+		n++
+	})
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"info", "--abs-time", "hello"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(s.Stdout(), check.Equals, `name:      hello
+summary:   The GNU Hello snap
+publisher: Canonical**
+store-url: https://snapcraft.io/hello
+license:   unset
+description: |
+  GNU hello prints a friendly greeting. This is part of the snapcraft tour at
+  https://snapcraft.io/
+snap-id:      mVyGrEwiqSi5PugCwyH7WgpoQLemtTd6
+tracking:     beta (track closed)
+refresh-date: 2006-01-02T22:04:07Z
+tracks:
+  0:      reached end of life on 2024-04-30T00:00:00Z
+  1:      end of life on 2025-04-30T00:00:00Z
+  latest: closed
+channels:
+  1/stable:    2.10 2018-12-18T15:16:56Z   (1) 65kB -
+  1/candidate: ^                                    
+  1/beta:      ^                                    
+  1/edge:      ^                                    
+installed:     2.10                      (100)  1kB disabled
+`)
+	c.Check(s.Stderr(), check.Equals, "")
+}
+
 func (s *infoSuite) TestInfoHumanTimes(c *check.C) {
 	// checks that tiemutil.Human is called when no --abs-time is given
 	restore := snap.MockTimeutilHuman(func(time.Time) string { return "TOTALLY NOT A ROBOT" })
@@ -1100,6 +1220,32 @@ func (infoSuite) TestMaybePrintCohortKey(c *check.C) {
 	}
 }
 
+func (infoSuite) TestMaybePrintTmpUsage(c *check.C) {
+	type T struct {
+		snap     *client.Snap
+		verbose  bool
+		expected string
+	}
+
+	tests := []T{
+		{snap: nil, verbose: true, expected: ""},
+		{snap: &client.Snap{}, verbose: true, expected: ""},
+		{snap: &client.Snap{TmpUsage: 1000, TmpSize: 64000000}, verbose: false, expected: ""},
+		{snap: &client.Snap{TmpUsage: 1000, TmpSize: 64000000}, verbose: true, expected: "tmp-usage:\t1kB of 64MB\n"},
+		{snap: &client.Snap{TmpSize: 64000000}, verbose: true, expected: "tmp-usage:\t0B of 64MB\n"},
+	}
+
+	var buf flushBuffer
+	iw := snap.NewInfoWriter(&buf)
+	for i, t := range tests {
+		buf.Reset()
+		snap.SetupSnap(iw, t.snap, nil, nil)
+		snap.SetVerbose(iw, t.verbose)
+		snap.MaybePrintTmpUsage(iw)
+		c.Check(buf.String(), check.Equals, t.expected, check.Commentf("%d", i))
+	}
+}
+
 func (infoSuite) TestMaybePrintHealth(c *check.C) {
 	type T struct {
 		snap     *client.Snap
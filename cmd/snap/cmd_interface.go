@@ -133,7 +133,7 @@ func (x *cmdInterface) showOneInterface(iface *client.Interface) {
 			// yaml object so that we can write the attributes.
 			if len(plug.Attrs) > 0 && x.ShowAttrs {
 				fmt.Fprintf(w, ":\n")
-				x.showAttrs(w, plug.Attrs, "    ")
+				showAttrs(w, plug.Attrs, "    ")
 			} else {
 				fmt.Fprintf(w, "\n")
 			}
@@ -146,6 +146,9 @@ func (x *cmdInterface) showOneInterface(iface *client.Interface) {
 			if slot.Label != "" {
 				labelPart = fmt.Sprintf(" (%s)", slot.Label)
 			}
+			if slot.Hotplug {
+				labelPart += " [hotplug]"
+			}
 			if slot.Name == iface.Name {
 				fmt.Fprintf(w, "  - %s%s", slot.Snap, labelPart)
 			} else {
@@ -155,7 +158,7 @@ func (x *cmdInterface) showOneInterface(iface *client.Interface) {
 			// yaml object so that we can write the attributes.
 			if len(slot.Attrs) > 0 && x.ShowAttrs {
 				fmt.Fprintf(w, ":\n")
-				x.showAttrs(w, slot.Attrs, "    ")
+				showAttrs(w, slot.Attrs, "    ")
 			} else {
 				fmt.Fprintf(w, "\n")
 			}
@@ -172,7 +175,7 @@ func (x *cmdInterface) showManyInterfaces(infos []*client.Interface) {
 	}
 }
 
-func (x *cmdInterface) showAttrs(w io.Writer, attrs map[string]interface{}, indent string) {
+func showAttrs(w io.Writer, attrs map[string]interface{}, indent string) {
 	if len(attrs) == 0 {
 		return
 	}
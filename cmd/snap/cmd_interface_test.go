@@ -240,6 +240,42 @@ func (s *SnapSuite) TestInterfaceDetailsAndAttrs(c *C) {
 	c.Assert(s.Stderr(), Equals, "")
 }
 
+func (s *SnapSuite) TestInterfaceDetailsHotplug(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/interfaces")
+		c.Check(r.URL.RawQuery, Equals, "doc=true&names=serial-port&plugs=true&select=all&slots=true")
+		EncodeResponseBody(c, w, map[string]interface{}{
+			"type": "sync",
+			"result": []*client.Interface{{
+				Name:    "serial-port",
+				Summary: "allows providing or using a specific serial port",
+				Slots: []client.Slot{{
+					Snap:  "gizmo-gadget",
+					Name:  "debug-serial-port",
+					Label: "serial port for debugging",
+				}, {
+					Snap:    "system",
+					Name:    "ft232rusbuart",
+					Label:   "FT232R USB UART",
+					Hotplug: true,
+				}},
+			}},
+		})
+	})
+	rest, err := Parser(Client()).ParseArgs([]string{"interface", "serial-port"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	expectedStdout := "" +
+		"name:    serial-port\n" +
+		"summary: allows providing or using a specific serial port\n" +
+		"slots:\n" +
+		"  - gizmo-gadget:debug-serial-port (serial port for debugging)\n" +
+		"  - system:ft232rusbuart (FT232R USB UART) [hotplug]\n"
+	c.Assert(s.Stdout(), Equals, expectedStdout)
+	c.Assert(s.Stderr(), Equals, "")
+}
+
 func (s *SnapSuite) TestInterfaceCompletion(c *C) {
 	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
 		c.Assert(r.Method, Equals, "GET")
@@ -48,11 +48,12 @@ type cmdList struct {
 
 	All bool `long:"all"`
 	colorMixin
+	formatMixin
 }
 
 func init() {
 	addCommand("list", shortListHelp, longListHelp, func() flags.Commander { return &cmdList{} },
-		colorDescs.also(map[string]string{
+		colorDescs.also(formatDescs).also(map[string]string{
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"all": i18n.G("Show all revisions"),
 		}), nil)
@@ -92,6 +93,39 @@ func fmtVersion(v string) string {
 	return v
 }
 
+// listedSnap is the machine-readable form of a snap in the output of the
+// list command.
+type listedSnap struct {
+	Name        string `json:"name" yaml:"name"`
+	Version     string `json:"version" yaml:"version"`
+	Revision    string `json:"revision" yaml:"revision"`
+	Tracking    string `json:"tracking,omitempty" yaml:"tracking,omitempty"`
+	Publisher   string `json:"publisher,omitempty" yaml:"publisher,omitempty"`
+	Type        string `json:"type" yaml:"type"`
+	Confinement string `json:"confinement" yaml:"confinement"`
+	Status      string `json:"status" yaml:"status"`
+}
+
+func (x *cmdList) printStructured(snaps []*client.Snap) error {
+	listed := make([]listedSnap, 0, len(snaps))
+	for _, snap := range snaps {
+		ls := listedSnap{
+			Name:        snap.Name,
+			Version:     snap.Version,
+			Revision:    snap.Revision.String(),
+			Tracking:    snap.TrackingChannel,
+			Type:        snap.Type,
+			Confinement: string(snap.Confinement),
+			Status:      snap.Status,
+		}
+		if snap.Publisher != nil {
+			ls.Publisher = snap.Publisher.Username
+		}
+		listed = append(listed, ls)
+	}
+	return x.formatMixin.printStructured(listed)
+}
+
 func (x *cmdList) Execute(args []string) error {
 	if len(args) > 0 {
 		return ErrExtraArgs
@@ -102,6 +136,9 @@ func (x *cmdList) Execute(args []string) error {
 	if err != nil {
 		if err == client.ErrNoSnapsInstalled {
 			if len(names) == 0 {
+				if x.structured() {
+					return x.printStructured(nil)
+				}
 				fmt.Fprintln(Stderr, i18n.G("No snaps are installed yet. Try 'snap install hello-world'."))
 				return nil
 			} else {
@@ -114,6 +151,10 @@ func (x *cmdList) Execute(args []string) error {
 	}
 	sort.Sort(snapsByName(snaps))
 
+	if x.structured() {
+		return x.printStructured(snaps)
+	}
+
 	esc := x.getEscapes()
 	w := tabWriter()
 
@@ -43,6 +43,8 @@ indicates that the publisher has been verified.
                                       some things. (default: auto)
       --unicode=[auto|never|always]   Use a little bit of Unicode to improve
                                       legibility. (default: auto)
+      --format=[text|json|yaml]       Use the given output format (default:
+                                      text)
 `
 	s.testSubCommandHelp(c, "list", msg)
 }
@@ -82,6 +84,72 @@ foo   4.2      17   potatoes  bar        blocked
 	c.Check(s.Stderr(), check.Equals, "")
 }
 
+func (s *SnapSuite) TestListFormatJSONAndYAML(c *check.C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, check.Equals, "GET")
+		c.Check(r.URL.Path, check.Equals, "/v2/snaps")
+		fmt.Fprintln(w, `{"type": "sync", "result": [
+{"name": "foo", "status": "active", "version": "4.2", "type": "app", "confinement": "strict", "publisher": {"id": "bar-id", "username": "bar", "display-name": "Bar", "validation": "unproven"}, "revision": 17, "tracking-channel": "latest/stable"},
+{"name": "baz", "status": "installed", "version": "1.0", "type": "app", "confinement": "classic", "revision": "x1"}
+]}`)
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"list", "--format=json"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stdout(), check.Equals, `[
+  {
+    "name": "baz",
+    "version": "1.0",
+    "revision": "x1",
+    "type": "app",
+    "confinement": "classic",
+    "status": "installed"
+  },
+  {
+    "name": "foo",
+    "version": "4.2",
+    "revision": "17",
+    "tracking": "latest/stable",
+    "publisher": "bar",
+    "type": "app",
+    "confinement": "strict",
+    "status": "active"
+  }
+]
+`)
+	c.Check(s.Stderr(), check.Equals, "")
+
+	s.ResetStdStreams()
+	_, err = snap.Parser(snap.Client()).ParseArgs([]string{"list", "--format=yaml", "foo"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stdout(), check.Equals, `- name: baz
+  version: "1.0"
+  revision: x1
+  type: app
+  confinement: classic
+  status: installed
+- name: foo
+  version: "4.2"
+  revision: "17"
+  tracking: latest/stable
+  publisher: bar
+  type: app
+  confinement: strict
+  status: active
+`)
+}
+
+func (s *SnapSuite) TestListFormatJSONNoSnaps(c *check.C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"type": "sync", "result": []}`)
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"list", "--format=json"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stdout(), check.Equals, "[]\n")
+	c.Check(s.Stderr(), check.Equals, "")
+}
+
 func (s *SnapSuite) TestListAll(c *check.C) {
 	n := 0
 	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
@@ -59,6 +59,8 @@ type cmdPrepareImage struct {
 	ExtraSnaps         []string `long:"extra-snaps" hidden:"yes"` // DEPRECATED
 	RevisionsFile      string   `long:"revisions"`
 	WriteRevisionsFile string   `long:"write-revisions" optional:"true" optional-value:"./seed.manifest"`
+
+	SizeOnly bool `long:"size-only"`
 }
 
 func init() {
@@ -100,6 +102,8 @@ For preparing classic images it supports a --classic mode`),
 			"channel": i18n.G("The channel to use"),
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"customize": i18n.G("Image customizations specified as JSON file."),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"size-only": i18n.G("Only print the size of the seed and of the images of the gadget volumes, in bytes, without writing the seed"),
 		}, []argDesc{
 			{
 				// TRANSLATORS: This needs to begin with < and end with >
@@ -182,7 +186,12 @@ func (x *cmdPrepareImage) Execute(args []string) error {
 		return fmt.Errorf("--sysfs-overlay cannot be used without --preseed")
 	}
 
+	if x.SizeOnly && x.Preseed {
+		return fmt.Errorf("--size-only cannot be used with --preseed")
+	}
+
 	opts.Preseed = x.Preseed
+	opts.SizeOnly = x.SizeOnly
 	opts.PreseedSignKey = x.PreseedSignKey
 	opts.AppArmorKernelFeaturesDir = x.AppArmorKernelFeaturesDir
 	opts.SysfsOverlay = x.SysfsOverlay
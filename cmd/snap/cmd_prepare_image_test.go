@@ -233,6 +233,31 @@ func (s *SnapPrepareImageSuite) TestPrepareImagePreseed(c *C) {
 	})
 }
 
+func (s *SnapPrepareImageSuite) TestPrepareImageSizeOnly(c *C) {
+	var opts *image.Options
+	prep := func(o *image.Options) error {
+		opts = o
+		return nil
+	}
+	r := cmdsnap.MockImagePrepare(prep)
+	defer r()
+
+	rest, err := cmdsnap.Parser(cmdsnap.Client()).ParseArgs([]string{"prepare-image", "--size-only", "model", "prepare-dir"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+
+	c.Check(opts, DeepEquals, &image.Options{
+		ModelFile:  "model",
+		PrepareDir: "prepare-dir",
+		SizeOnly:   true,
+	})
+}
+
+func (s *SnapPrepareImageSuite) TestPrepareImageSizeOnlyPreseedArgError(c *C) {
+	_, err := cmdsnap.Parser(cmdsnap.Client()).ParseArgs([]string{"prepare-image", "--size-only", "--preseed", "model", "prepare-dir"})
+	c.Assert(err, ErrorMatches, `--size-only cannot be used with --preseed`)
+}
+
 func (s *SnapPrepareImageSuite) TestPrepareImageWriteRevisions(c *C) {
 	var opts *image.Options
 	prep := func(o *image.Options) error {
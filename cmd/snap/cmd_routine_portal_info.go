@@ -20,13 +20,16 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"path/filepath"
 	"text/template"
 
 	"github.com/jessevdk/go-flags"
 
 	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/i18n"
 	"github.com/snapcore/snapd/sandbox/apparmor"
 	"github.com/snapcore/snapd/sandbox/cgroup"
@@ -34,6 +37,7 @@ import (
 
 type cmdRoutinePortalInfo struct {
 	clientMixin
+	JSON              bool `long:"json"`
 	PortalInfoOptions struct {
 		Pid int
 	} `positional-args:"true" required:"true"`
@@ -45,12 +49,21 @@ The portal-info command returns information about a process in keyfile format.
 
 This command is used by the xdg-desktop-portal service to retrieve
 information about snap confined processes.
+
+With --json, information about the sandbox of the snap the process belongs
+to is returned in JSON format instead: the state of its connections, its data
+directories, its quota group and whether a refresh of it is inhibited. Snap
+confined processes can use this to retrieve information about their own
+sandbox.
 `)
 
 func init() {
 	addRoutineCommand("portal-info", shortRoutinePortalInfoHelp, longRoutinePortalInfoHelp, func() flags.Commander {
 		return &cmdRoutinePortalInfo{}
-	}, nil, []argDesc{{
+	}, map[string]string{
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"json": i18n.G("Return information about the sandbox of the snap in JSON format"),
+	}, []argDesc{{
 		// TRANSLATORS: This needs to begin with < and end with >
 		name: i18n.G("<process ID>"),
 		// TRANSLATORS: This should not start with a lowercase letter.
@@ -72,6 +85,10 @@ func (x *cmdRoutinePortalInfo) Execute(args []string) error {
 	if err != nil {
 		return err
 	}
+	if x.JSON {
+		return x.showSandboxInfo(snapName)
+	}
+
 	snap, _, err := x.client.Snap(snapName)
 	if err != nil {
 		return fmt.Errorf("cannot retrieve info for snap %q: %v", snapName, err)
@@ -160,3 +177,21 @@ HasNetworkStatus={{.HasNetworkStatus}}
 	}
 	return nil
 }
+
+func (x *cmdRoutinePortalInfo) showSandboxInfo(snapName string) error {
+	// processes running inside a snap can only reach snapd through
+	// snapd-snap.socket, where they may only query their own snap
+	if os.Getenv("SNAP_INSTANCE_NAME") != "" {
+		ClientConfig.Socket = dirs.SnapSocket
+		x.setClient(mkClient())
+	}
+
+	info, err := x.client.SandboxInfo(snapName)
+	if err != nil {
+		return fmt.Errorf("cannot retrieve sandbox information for snap %q: %v", snapName, err)
+	}
+
+	enc := json.NewEncoder(Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(info)
+}
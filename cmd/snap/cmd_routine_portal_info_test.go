@@ -24,11 +24,13 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"os"
 
 	. "gopkg.in/check.v1"
 
 	"github.com/snapcore/snapd/client"
 	snap "github.com/snapcore/snapd/cmd/snap"
+	"github.com/snapcore/snapd/dirs"
 )
 
 // only used for /v2/snaps/hello
@@ -239,3 +241,92 @@ HasNetworkStatus=false
 `)
 	c.Check(s.Stderr(), Equals, "")
 }
+
+const mockSandboxInfoJSON = `{
+  "type": "sync",
+  "status-code": 200,
+  "status": "OK",
+  "result": {
+    "snap": "hello",
+    "connections": [
+      {
+        "interface": "network",
+        "plug": {"snap": "hello", "plug": "network"},
+        "slot": {"snap": "snapd", "slot": "network"},
+        "connected": true
+      }
+    ],
+    "data-dirs": {
+      "data": "/var/snap/hello/38",
+      "common": "/var/snap/hello/common"
+    }
+  }
+}`
+
+func (s *SnapSuite) TestPortalInfoJSON(c *C) {
+	restore := snap.MockCgroupSnapNameFromPid(func(pid int) (string, error) {
+		c.Check(pid, Equals, 42)
+		return "hello", nil
+	})
+	defer restore()
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.Method, Equals, "GET")
+			c.Check(r.URL.Path, Equals, "/v2/sandbox-info")
+			c.Check(r.URL.Query(), DeepEquals, url.Values{"snap": []string{"hello"}})
+			fmt.Fprint(w, mockSandboxInfoJSON)
+		default:
+			c.Fatalf("expected to get 1 request, now on %d (%v)", n+1, r)
+		}
+		n++
+	})
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"routine", "portal-info", "--json", "42"})
+	c.Assert(err, IsNil)
+	c.Check(n, Equals, 1)
+	c.Check(s.Stdout(), Equals, `{
+  "snap": "hello",
+  "connections": [
+    {
+      "interface": "network",
+      "plug": {
+        "snap": "hello",
+        "plug": "network"
+      },
+      "slot": {
+        "snap": "snapd",
+        "slot": "network"
+      },
+      "connected": true
+    }
+  ],
+  "data-dirs": {
+    "data": "/var/snap/hello/38",
+    "common": "/var/snap/hello/common"
+  }
+}
+`)
+	c.Check(s.Stderr(), Equals, "")
+	c.Check(snap.ClientConfig.Socket, Not(Equals), dirs.SnapSocket)
+}
+
+func (s *SnapSuite) TestPortalInfoJSONFromSnapUsesSnapSocket(c *C) {
+	restore := snap.MockCgroupSnapNameFromPid(func(pid int) (string, error) {
+		return "hello", nil
+	})
+	defer restore()
+	os.Setenv("SNAP_INSTANCE_NAME", "hello")
+	defer os.Unsetenv("SNAP_INSTANCE_NAME")
+	oldSocket := snap.ClientConfig.Socket
+	defer func() { snap.ClientConfig.Socket = oldSocket }()
+
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, Equals, "/v2/sandbox-info")
+		w.WriteHeader(403)
+		fmt.Fprint(w, `{"type": "error", "result": {"message": "access denied"}, "status-code": 403}`)
+	})
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"routine", "portal-info", "--json", "42"})
+	c.Assert(err, ErrorMatches, `cannot retrieve sandbox information for snap "hello": access denied`)
+	c.Check(snap.ClientConfig.Socket, Equals, dirs.SnapSocket)
+}
@@ -40,6 +40,7 @@ import (
 
 	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/cmd/snaplock/runinhibit"
+	"github.com/snapcore/snapd/desktop/desktopentry"
 	"github.com/snapcore/snapd/desktop/portal"
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/features"
@@ -527,6 +528,35 @@ func checkSnapRunInhibitionConflict(app *snap.AppInfo) error {
 	return nil
 }
 
+// verifyDesktopLaunch checks that a launch from a desktop file generated by
+// snapd carries a valid launch token for the application being run, so that
+// a tampered desktop file cannot re-route the launch to another snap while
+// still claiming the identity of the original desktop file. The launch key
+// is only readable by root, the token is thus verified by snapd.
+func (x *cmdRun) verifyDesktopLaunch(snapName, appName string) error {
+	token, hasToken := os.LookupEnv(desktopentry.LaunchTokenEnv)
+	// the token is of no use to the application
+	os.Unsetenv(desktopentry.LaunchTokenEnv)
+
+	snapApp := snap.JoinSnapApp(snapName, appName)
+	desktopFile := os.Getenv("BAMF_DESKTOP_FILE_HINT")
+	managed := desktopFile != "" && filepath.Dir(desktopFile) == dirs.SnapDesktopFilesDir
+	if !hasToken && !managed {
+		// not launched from a desktop file generated by snapd
+		return nil
+	}
+	if !managed {
+		return fmt.Errorf(i18n.G("cannot run %q: desktop file %q is not managed by snapd"), snapApp, desktopFile)
+	}
+	if !hasToken {
+		return fmt.Errorf(i18n.G("cannot run %q: missing launch token for desktop file %q"), snapApp, desktopFile)
+	}
+	if err := x.client.VerifyDesktopLaunch(desktopFile, snapApp, token); err != nil {
+		return fmt.Errorf(i18n.G("cannot run %q: %v"), snapApp, err)
+	}
+	return nil
+}
+
 func (x *cmdRun) snapRunApp(snapApp string, args []string) error {
 	if x.DebugLog {
 		os.Setenv("SNAPD_DEBUG", "1")
@@ -534,6 +564,10 @@ func (x *cmdRun) snapRunApp(snapApp string, args []string) error {
 	}
 	snapName, appName := snap.SplitSnapApp(snapApp)
 
+	if err := x.verifyDesktopLaunch(snapName, appName); err != nil {
+		return err
+	}
+
 	var retryCnt int
 	for {
 		if retryCnt > 1 {
@@ -583,9 +617,27 @@ func (x *cmdRun) snapRunApp(snapApp string, args []string) error {
 			return checkSnapRunInhibitionConflict(app)
 		}
 
+		instanceLock, err := maybeLockSingleInstance(app, args)
+		if err != nil {
+			if hintFlock != nil {
+				hintFlock.Close()
+			}
+			if errors.Is(err, errForwardedToRunningInstance) {
+				return nil
+			}
+			return err
+		}
+
+		applyAppScheduling(app)
+
 		runner := newAppRunnable(info, app)
 
 		err = x.runSnapConfine(info, runner, closeFlockOrCheckConflict, args)
+		if instanceLock != nil {
+			// only reached if the app was not executed or ran in a
+			// child process
+			instanceLock.Close()
+		}
 		if errors.Is(err, errSnapRefreshConflict) {
 			// Possible race condition detected, let's retry.
 			//
@@ -24,21 +24,25 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"golang.org/x/sys/unix"
 	"gopkg.in/check.v1"
 
 	snaprun "github.com/snapcore/snapd/cmd/snap"
 	"github.com/snapcore/snapd/cmd/snaplock/runinhibit"
+	"github.com/snapcore/snapd/desktop/desktopentry"
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/features"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/osutil/strace"
+	"github.com/snapcore/snapd/osutil/sys"
 	"github.com/snapcore/snapd/osutil/user"
 	"github.com/snapcore/snapd/sandbox/cgroup"
 	"github.com/snapcore/snapd/sandbox/selinux"
@@ -248,6 +252,353 @@ func (s *RunSuite) TestSnapRunAppIntegration(c *check.C) {
 	c.Check(execEnv, testutil.Contains, fmt.Sprintf("TMPDIR=%s", tmpdir))
 }
 
+func (s *RunSuite) mockDesktopLaunch(c *check.C, desktopFile, token string) (verified *int) {
+	key := []byte("some-key")
+	verified = new(int)
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/desktop-launch":
+			c.Check(r.Method, check.Equals, "POST")
+			var req map[string]string
+			c.Assert(json.NewDecoder(r.Body).Decode(&req), check.IsNil)
+			*verified++
+			if desktopentry.VerifyLaunchToken(key, req["desktop-file"], req["snap-app"], req["token"]) {
+				EncodeResponseBody(c, w, map[string]any{"type": "sync", "result": nil})
+				return
+			}
+			w.WriteHeader(400)
+			EncodeResponseBody(c, w, map[string]any{
+				"type":   "error",
+				"result": map[string]any{"message": fmt.Sprintf("invalid launch token for desktop file %q", req["desktop-file"])},
+			})
+		case "/v2/notices", "/v2/connections":
+			EncodeResponseBody(c, w, map[string]any{"type": "sync", "result": nil})
+		default:
+			c.Error("this should never be reached")
+		}
+	})
+
+	os.Setenv("BAMF_DESKTOP_FILE_HINT", desktopFile)
+	if token != "" {
+		os.Setenv("SNAP_DESKTOP_LAUNCH_TOKEN", token)
+	}
+	s.AddCleanup(func() {
+		os.Unsetenv("BAMF_DESKTOP_FILE_HINT")
+		os.Unsetenv("SNAP_DESKTOP_LAUNCH_TOKEN")
+	})
+	return verified
+}
+
+func (s *RunSuite) TestSnapRunAppDesktopLaunchToken(c *check.C) {
+	defer mockSnapConfine(dirs.DistroLibExecDir)()
+
+	snaptest.MockSnapCurrent(c, string(mockYaml), &snap.SideInfo{
+		Revision: snap.R("x2"),
+	})
+	desktopFile := filepath.Join(dirs.SnapDesktopFilesDir, "snapname_app.desktop")
+	verified := s.mockDesktopLaunch(c, desktopFile, desktopentry.LaunchToken([]byte("some-key"), desktopFile, "snapname.app"))
+
+	execEnv := []string{}
+	restorer := snaprun.MockSyscallExec(func(arg0 string, args []string, envv []string) error {
+		execEnv = envv
+		return nil
+	})
+	defer restorer()
+
+	_, err := snaprun.Parser(snaprun.Client()).ParseArgs([]string{"run", "--", "snapname.app"})
+	c.Assert(err, check.IsNil)
+	c.Check(*verified, check.Equals, 1)
+	c.Check(execEnv, testutil.Contains, "BAMF_DESKTOP_FILE_HINT="+desktopFile)
+	// the token is not passed on to the application
+	c.Check(strings.Join(execEnv, "\n"), check.Not(testutil.Contains), "SNAP_DESKTOP_LAUNCH_TOKEN=")
+}
+
+func (s *RunSuite) TestSnapRunAppDesktopLaunchTokenOtherApp(c *check.C) {
+	defer mockSnapConfine(dirs.DistroLibExecDir)()
+
+	snaptest.MockSnapCurrent(c, string(mockYaml), &snap.SideInfo{
+		Revision: snap.R("x2"),
+	})
+	// the token of the desktop file of another snap is re-routed to this one
+	desktopFile := filepath.Join(dirs.SnapDesktopFilesDir, "other_app.desktop")
+	s.mockDesktopLaunch(c, desktopFile, desktopentry.LaunchToken([]byte("some-key"), desktopFile, "other.app"))
+
+	restorer := snaprun.MockSyscallExec(func(arg0 string, args []string, envv []string) error {
+		c.Fatalf("unexpected exec")
+		return nil
+	})
+	defer restorer()
+
+	_, err := snaprun.Parser(snaprun.Client()).ParseArgs([]string{"run", "--", "snapname.app"})
+	c.Assert(err, check.ErrorMatches, fmt.Sprintf(`cannot run "snapname.app": invalid launch token for desktop file %q`, desktopFile))
+}
+
+func (s *RunSuite) TestSnapRunAppDesktopLaunchMissingToken(c *check.C) {
+	defer mockSnapConfine(dirs.DistroLibExecDir)()
+
+	snaptest.MockSnapCurrent(c, string(mockYaml), &snap.SideInfo{
+		Revision: snap.R("x2"),
+	})
+	desktopFile := filepath.Join(dirs.SnapDesktopFilesDir, "snapname_app.desktop")
+	verified := s.mockDesktopLaunch(c, desktopFile, "")
+
+	restorer := snaprun.MockSyscallExec(func(arg0 string, args []string, envv []string) error {
+		c.Fatalf("unexpected exec")
+		return nil
+	})
+	defer restorer()
+
+	_, err := snaprun.Parser(snaprun.Client()).ParseArgs([]string{"run", "--", "snapname.app"})
+	c.Assert(err, check.ErrorMatches, fmt.Sprintf(`cannot run "snapname.app": missing launch token for desktop file %q`, desktopFile))
+	c.Check(*verified, check.Equals, 0)
+}
+
+func (s *RunSuite) TestSnapRunAppDesktopLaunchTokenUnmanagedDesktopFile(c *check.C) {
+	defer mockSnapConfine(dirs.DistroLibExecDir)()
+
+	snaptest.MockSnapCurrent(c, string(mockYaml), &snap.SideInfo{
+		Revision: snap.R("x2"),
+	})
+	desktopFile := filepath.Join(s.fakeHome, ".local/share/applications/snapname_app.desktop")
+	s.mockDesktopLaunch(c, desktopFile, desktopentry.LaunchToken([]byte("some-key"), desktopFile, "snapname.app"))
+
+	restorer := snaprun.MockSyscallExec(func(arg0 string, args []string, envv []string) error {
+		c.Fatalf("unexpected exec")
+		return nil
+	})
+	defer restorer()
+
+	_, err := snaprun.Parser(snaprun.Client()).ParseArgs([]string{"run", "--", "snapname.app"})
+	c.Assert(err, check.ErrorMatches, fmt.Sprintf(`cannot run "snapname.app": desktop file %q is not managed by snapd`, desktopFile))
+}
+
+var mockSingleInstanceYaml = []byte(`name: snapname
+version: 1.0
+apps:
+ app:
+  command: run-app
+  single-instance: true
+`)
+
+func (s *RunSuite) TestSnapRunSingleInstanceFirst(c *check.C) {
+	defer mockSnapConfine(dirs.DistroLibExecDir)()
+
+	info := snaptest.MockSnapCurrent(c, string(mockSingleInstanceYaml), &snap.SideInfo{
+		Revision: snap.R("x2"),
+	})
+	app := info.Apps["app"]
+	euid := sys.UserID(os.Geteuid())
+	defer os.Unsetenv("SNAP_SINGLE_INSTANCE_SOCKET")
+
+	restore := snaprun.MockCgroupPidsOfSnap(func(snapInstanceName string) (map[string][]int, error) {
+		c.Fatalf("unexpected call")
+		return nil, nil
+	})
+	defer restore()
+
+	execCalled := false
+	restore = snaprun.MockSyscallExec(func(arg0 string, args []string, envv []string) error {
+		execCalled = true
+		c.Check(envv, testutil.Contains, "SNAP_SINGLE_INSTANCE_SOCKET="+app.SingleInstanceSocket(euid))
+		// the lock is held while the app runs
+		flock, err := osutil.NewFileLock(app.SingleInstanceLockFile(euid))
+		c.Assert(err, check.IsNil)
+		defer flock.Close()
+		c.Check(flock.TryLock(), check.Equals, osutil.ErrAlreadyLocked)
+		return nil
+	})
+	defer restore()
+
+	_, err := snaprun.Parser(snaprun.Client()).ParseArgs([]string{"run", "--", "snapname.app", "--arg1"})
+	c.Assert(err, check.IsNil)
+	c.Check(execCalled, check.Equals, true)
+
+	// released as the app was not executed
+	flock, err := osutil.NewFileLock(app.SingleInstanceLockFile(euid))
+	c.Assert(err, check.IsNil)
+	defer flock.Close()
+	c.Check(flock.TryLock(), check.IsNil)
+}
+
+func (s *RunSuite) lockSingleInstance(c *check.C, app *snap.AppInfo) {
+	lockPath := app.SingleInstanceLockFile(sys.UserID(os.Geteuid()))
+	c.Assert(os.MkdirAll(filepath.Dir(lockPath), 0700), check.IsNil)
+	flock, err := osutil.NewFileLock(lockPath)
+	c.Assert(err, check.IsNil)
+	c.Assert(flock.Lock(), check.IsNil)
+	s.AddCleanup(func() { flock.Close() })
+}
+
+func (s *RunSuite) TestSnapRunSingleInstanceForward(c *check.C) {
+	defer mockSnapConfine(dirs.DistroLibExecDir)()
+
+	info := snaptest.MockSnapCurrent(c, string(mockSingleInstanceYaml), &snap.SideInfo{
+		Revision: snap.R("x2"),
+	})
+	app := info.Apps["app"]
+	s.lockSingleInstance(c, app)
+
+	restore := snaprun.MockCgroupPidsOfSnap(func(snapInstanceName string) (map[string][]int, error) {
+		c.Check(snapInstanceName, check.Equals, "snapname")
+		return map[string][]int{"snap.snapname.app": {1234}}, nil
+	})
+	defer restore()
+	restore = snaprun.MockSyscallExec(func(arg0 string, args []string, envv []string) error {
+		c.Fatalf("unexpected exec")
+		return nil
+	})
+	defer restore()
+
+	l, err := net.Listen("unix", app.SingleInstanceSocket(sys.UserID(os.Geteuid())))
+	c.Assert(err, check.IsNil)
+	defer l.Close()
+	received := make(chan map[string]interface{}, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			close(received)
+			return
+		}
+		defer conn.Close()
+		var req map[string]interface{}
+		json.NewDecoder(conn).Decode(&req)
+		received <- req
+	}()
+
+	_, err = snaprun.Parser(snaprun.Client()).ParseArgs([]string{"run", "--", "snapname.app", "--arg1", "arg2"})
+	c.Assert(err, check.IsNil)
+
+	cwd, err := os.Getwd()
+	c.Assert(err, check.IsNil)
+	c.Check(<-received, check.DeepEquals, map[string]interface{}{
+		"args": []interface{}{"--arg1", "arg2"},
+		"cwd":  cwd,
+	})
+}
+
+func (s *RunSuite) TestSnapRunSingleInstanceForwardError(c *check.C) {
+	defer mockSnapConfine(dirs.DistroLibExecDir)()
+
+	info := snaptest.MockSnapCurrent(c, string(mockSingleInstanceYaml), &snap.SideInfo{
+		Revision: snap.R("x2"),
+	})
+	s.lockSingleInstance(c, info.Apps["app"])
+
+	// cgroup tracking is not available, the lock is trusted
+	restore := snaprun.MockCgroupPidsOfSnap(func(snapInstanceName string) (map[string][]int, error) {
+		return nil, fmt.Errorf("boom")
+	})
+	defer restore()
+	restore = snaprun.MockSyscallExec(func(arg0 string, args []string, envv []string) error {
+		c.Fatalf("unexpected exec")
+		return nil
+	})
+	defer restore()
+
+	_, err := snaprun.Parser(snaprun.Client()).ParseArgs([]string{"run", "--", "snapname.app"})
+	c.Assert(err, check.ErrorMatches, `cannot pass arguments to the running instance of "snapname.app": .*no such file or directory`)
+}
+
+func (s *RunSuite) TestSnapRunSingleInstanceNotRunning(c *check.C) {
+	defer mockSnapConfine(dirs.DistroLibExecDir)()
+
+	info := snaptest.MockSnapCurrent(c, string(mockSingleInstanceYaml), &snap.SideInfo{
+		Revision: snap.R("x2"),
+	})
+	s.lockSingleInstance(c, info.Apps["app"])
+
+	restore := snaprun.MockCgroupPidsOfSnap(func(snapInstanceName string) (map[string][]int, error) {
+		return map[string][]int{"snap.snapname.other": {1234}}, nil
+	})
+	defer restore()
+	execCalled := false
+	restore = snaprun.MockSyscallExec(func(arg0 string, args []string, envv []string) error {
+		execCalled = true
+		return nil
+	})
+	defer restore()
+
+	_, err := snaprun.Parser(snaprun.Client()).ParseArgs([]string{"run", "--", "snapname.app"})
+	c.Assert(err, check.IsNil)
+	c.Check(execCalled, check.Equals, true)
+}
+
+var mockSchedulingYaml = []byte(`name: snapname
+version: 1.0
+apps:
+ app:
+  command: run-app
+  cpu-affinity: [0, 2]
+  scheduling-policy: idle
+`)
+
+func (s *RunSuite) TestSnapRunAppScheduling(c *check.C) {
+	defer mockSnapConfine(dirs.DistroLibExecDir)()
+
+	snaptest.MockSnapCurrent(c, string(mockSchedulingYaml), &snap.SideInfo{
+		Revision: snap.R("x2"),
+	})
+
+	var calls []string
+	restore := snaprun.MockSchedSetaffinity(func(pid int, set *unix.CPUSet) error {
+		calls = append(calls, "affinity")
+		c.Check(pid, check.Equals, 0)
+		c.Check(set.Count(), check.Equals, 2)
+		c.Check(set.IsSet(0), check.Equals, true)
+		c.Check(set.IsSet(2), check.Equals, true)
+		return nil
+	})
+	defer restore()
+	restore = snaprun.MockSchedSetAttr(func(pid int, attr *unix.SchedAttr, flags uint) error {
+		calls = append(calls, "attr")
+		c.Check(pid, check.Equals, 0)
+		c.Check(attr.Policy, check.Equals, uint32(unix.SCHED_IDLE))
+		c.Check(attr.Priority, check.Equals, uint32(0))
+		return nil
+	})
+	defer restore()
+	restore = snaprun.MockSyscallExec(func(arg0 string, args []string, envv []string) error {
+		calls = append(calls, "exec")
+		return nil
+	})
+	defer restore()
+
+	_, err := snaprun.Parser(snaprun.Client()).ParseArgs([]string{"run", "--", "snapname.app"})
+	c.Assert(err, check.IsNil)
+	c.Check(calls, check.DeepEquals, []string{"affinity", "attr", "exec"})
+}
+
+func (s *RunSuite) TestSnapRunAppSchedulingErrorsAreNotFatal(c *check.C) {
+	defer mockSnapConfine(dirs.DistroLibExecDir)()
+
+	snaptest.MockSnapCurrent(c, string(mockSchedulingYaml), &snap.SideInfo{
+		Revision: snap.R("x2"),
+	})
+	logbuf, restore := logger.MockLogger()
+	defer restore()
+
+	restore = snaprun.MockSchedSetaffinity(func(pid int, set *unix.CPUSet) error {
+		return unix.EINVAL
+	})
+	defer restore()
+	restore = snaprun.MockSchedSetAttr(func(pid int, attr *unix.SchedAttr, flags uint) error {
+		return unix.EPERM
+	})
+	defer restore()
+	execCalled := false
+	restore = snaprun.MockSyscallExec(func(arg0 string, args []string, envv []string) error {
+		execCalled = true
+		return nil
+	})
+	defer restore()
+
+	_, err := snaprun.Parser(snaprun.Client()).ParseArgs([]string{"run", "--", "snapname.app"})
+	c.Assert(err, check.IsNil)
+	c.Check(execCalled, check.Equals, true)
+	c.Check(logbuf.String(), testutil.Contains, "WARNING: cannot set CPU affinity of snap.snapname.app: invalid argument")
+	c.Check(logbuf.String(), testutil.Contains, "WARNING: cannot set scheduling policy of snap.snapname.app to idle: operation not permitted")
+}
+
 func checkHintFileNotLocked(c *check.C, snapName string) {
 	flock, err := openHintFileLock(snapName)
 	c.Assert(err, check.IsNil)
@@ -30,6 +30,7 @@ import (
 	"github.com/snapcore/snapd/client/clientutil"
 	"github.com/snapcore/snapd/i18n"
 	"github.com/snapcore/snapd/osutil/user"
+	"github.com/snapcore/snapd/snap"
 )
 
 type svcStatus struct {
@@ -39,6 +40,7 @@ type svcStatus struct {
 	} `positional-args:"yes"`
 	Global bool `long:"global" short:"g"`
 	User   bool `long:"user" short:"u"`
+	formatMixin
 }
 
 type svcLogs struct {
@@ -94,12 +96,12 @@ func init() {
 		// TRANSLATORS: This should not start with a lowercase letter.
 		desc: i18n.G("A service specification, which can be just a snap name (for all services in the snap), or <snap>.<app> for a single service."),
 	}}
-	addCommand("services", shortServicesHelp, longServicesHelp, func() flags.Commander { return &svcStatus{} }, map[string]string{
+	addCommand("services", shortServicesHelp, longServicesHelp, func() flags.Commander { return &svcStatus{} }, formatDescs.also(map[string]string{
 		// TRANSLATORS: This should not start with a lowercase letter.
 		"global": i18n.G("Show the global enable status for user services instead of the status for the current user."),
 		// TRANSLATORS: This should not start with a lowercase letter.
 		"user": i18n.G("Show the current status of the user services instead of the global enable status."),
-	}, argdescs)
+	}), argdescs)
 	addCommand("logs", shortLogsHelp, longLogsHelp, func() flags.Commander { return &svcLogs{} },
 		timeDescs.also(map[string]string{
 			// TRANSLATORS: This should not start with a lowercase letter.
@@ -150,6 +152,40 @@ func (s *svcStatus) validateArguments() error {
 	return nil
 }
 
+// serviceStatus is the machine-readable form of a service in the output of
+// the services command.
+type serviceStatus struct {
+	Service string `json:"service" yaml:"service"`
+	Snap    string `json:"snap" yaml:"snap"`
+	App     string `json:"app" yaml:"app"`
+	Daemon  string `json:"daemon" yaml:"daemon"`
+	Scope   string `json:"daemon-scope,omitempty" yaml:"daemon-scope,omitempty"`
+	Enabled bool   `json:"enabled" yaml:"enabled"`
+	// Active is not known for user services when showing the global
+	// enable status
+	Active *bool `json:"active,omitempty" yaml:"active,omitempty"`
+}
+
+func (s *svcStatus) printStructured(services []*client.AppInfo, isGlobal bool) error {
+	statuses := make([]serviceStatus, 0, len(services))
+	for _, svc := range services {
+		status := serviceStatus{
+			Service: svc.Snap + "." + svc.Name,
+			Snap:    svc.Snap,
+			App:     svc.Name,
+			Daemon:  svc.Daemon,
+			Scope:   string(svc.DaemonScope),
+			Enabled: svc.Enabled,
+		}
+		if !(svc.DaemonScope == snap.UserDaemon && isGlobal) {
+			active := svc.Active
+			status.Active = &active
+		}
+		statuses = append(statuses, status)
+	}
+	return s.formatMixin.printStructured(statuses)
+}
+
 func (s *svcStatus) Execute(args []string) error {
 	if len(args) > 0 {
 		return ErrExtraArgs
@@ -173,6 +209,10 @@ func (s *svcStatus) Execute(args []string) error {
 		return err
 	}
 
+	if s.structured() {
+		return s.printStructured(services, isGlobal)
+	}
+
 	if len(services) == 0 {
 		fmt.Fprintln(Stderr, i18n.G("There are no services provided by installed snaps."))
 		return nil
@@ -439,6 +439,81 @@ foo.zed  enabled  active    -
 	c.Check(n, check.Equals, 1)
 }
 
+func (s *appOpSuite) TestAppStatusFormat(c *check.C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, check.Equals, "/v2/apps")
+		c.Check(r.URL.Query().Get("select"), check.Equals, "service")
+		c.Check(r.URL.Query().Get("global"), check.Equals, "true")
+		w.WriteHeader(200)
+		enc := json.NewEncoder(w)
+		enc.Encode(map[string]interface{}{
+			"type": "sync",
+			"result": []map[string]interface{}{
+				{
+					"snap":         "foo",
+					"name":         "bar",
+					"daemon":       "oneshot",
+					"daemon-scope": "system",
+					"active":       false,
+					"enabled":      true,
+				}, {
+					"snap":         "foo",
+					"name":         "qux",
+					"daemon":       "simple",
+					"daemon-scope": "user",
+					"active":       false,
+					"enabled":      false,
+				},
+			},
+			"status":      "OK",
+			"status-code": 200,
+		})
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"services", "--global", "--format=json"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stderr(), check.Equals, "")
+	// the active state of user services is not known globally
+	c.Check(s.Stdout(), check.Equals, `[
+  {
+    "service": "foo.bar",
+    "snap": "foo",
+    "app": "bar",
+    "daemon": "oneshot",
+    "daemon-scope": "system",
+    "enabled": true,
+    "active": false
+  },
+  {
+    "service": "foo.qux",
+    "snap": "foo",
+    "app": "qux",
+    "daemon": "simple",
+    "daemon-scope": "user",
+    "enabled": false
+  }
+]
+`)
+
+	s.stdout.Reset()
+	_, err = snap.Parser(snap.Client()).ParseArgs([]string{"services", "--global", "--format=yaml"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stdout(), check.Equals, `- service: foo.bar
+  snap: foo
+  app: bar
+  daemon: oneshot
+  daemon-scope: system
+  enabled: true
+  active: false
+- service: foo.qux
+  snap: foo
+  app: qux
+  daemon: simple
+  daemon-scope: user
+  enabled: false
+`)
+}
+
 func (s *appOpSuite) TestServiceCompletion(c *check.C) {
 	n := 0
 	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
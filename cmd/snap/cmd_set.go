@@ -44,6 +44,11 @@ Nested values may be modified via a dotted path:
 
 Configuration option may be unset with exclamation mark:
     $ snap set snap-name author!
+
+With --user the options are set in the configuration of the snap for the
+calling user, which applications of the snap can read with
+"snapctl get --user". It is changed right away, without running the
+configuration hook of the snap.
 `)
 
 var longConfdbSetHelp = i18n.G(`
@@ -61,6 +66,7 @@ type cmdSet struct {
 
 	Typed  bool `short:"t"`
 	String bool `short:"s"`
+	User   bool `long:"user"`
 }
 
 func init() {
@@ -74,6 +80,8 @@ func init() {
 			"t": i18n.G("Parse the value strictly as JSON document"),
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"s": i18n.G("Parse the value as a string"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"user": i18n.G("Change the configuration of the snap for the calling user"),
 		}), []argDesc{
 			{
 				name: "<snap>",
@@ -100,6 +108,13 @@ func (x *cmdSet) Execute([]string) error {
 	}
 
 	snapName := string(x.Positional.Snap)
+	if x.User {
+		if isConfdbViewID(snapName) {
+			return errors.New(i18n.G("cannot use --user with a confdb view"))
+		}
+		return x.client.SetUserConf(snapName, patchValues)
+	}
+
 	var chgID string
 	if isConfdbViewID(snapName) {
 		if err := validateConfdbFeatureFlag(); err != nil {
@@ -162,6 +162,29 @@ func (s *snapSetSuite) mockSetConfigServer(c *check.C, expectedValue interface{}
 	})
 }
 
+func (s *snapSetSuite) TestSnapSetUser(c *check.C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, check.Equals, "PUT")
+		c.Check(r.URL.Path, check.Equals, "/v2/snaps/snapname/user-conf")
+		c.Check(DecodedRequestBody(c, r), check.DeepEquals, map[string]interface{}{
+			"key":   "value",
+			"other": nil,
+		})
+		fmt.Fprintln(w, `{"type":"sync", "status-code": 200, "result": null}`)
+		s.setConfApiCalls += 1
+	})
+
+	_, err := snapset.Parser(snapset.Client()).ParseArgs([]string{"set", "--user", "snapname", "key=value", "other!"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.setConfApiCalls, check.Equals, 1)
+}
+
+func (s *snapSetSuite) TestSnapSetUserConfdbView(c *check.C) {
+	_, err := snapset.Parser(snapset.Client()).ParseArgs([]string{"set", "--user", "acc/confdb/view", "key=value"})
+	c.Assert(err, check.ErrorMatches, "cannot use --user with a confdb view")
+	c.Check(s.setConfApiCalls, check.Equals, 0)
+}
+
 const asyncResp = `{
 	"type": "async",
 	"change": "123",
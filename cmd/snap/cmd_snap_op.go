@@ -20,6 +20,8 @@
 package main
 
 import (
+	"bufio"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -31,6 +33,7 @@ import (
 
 	"github.com/jessevdk/go-flags"
 
+	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/i18n"
@@ -71,6 +74,15 @@ override, taking the snap back to the current revision of the channel it's
 tracking.
 
 Use --name to set the instance name when installing from snap file.
+
+Use --assertions to provide the assertions for snap files, which are then
+acknowledged and the snaps installed as if they came from the store, without
+needing --dangerous.
+
+When installing multiple snaps, each snap is installed separately by default,
+so that the failure of one snap does not affect the others. With
+--transaction=all-snaps the snaps are installed as a whole: if any of them
+fails to install, the installation of all the others is undone.
 `)
 
 var longRemoveHelp = i18n.G(`
@@ -106,6 +118,16 @@ requests from 'snap refresh target-snap'.
 When snaps are specified --hold is effective on both their auto-refreshes
 and general refresh requests from 'snap refresh'. However, specific snap
 requests from 'snap refresh target-snap' remain unblocked and will proceed.
+
+When refreshing multiple snaps, each snap is refreshed separately by default,
+so that the failure of one snap does not affect the others. With
+--transaction=all-snaps the snaps are refreshed as a whole: if any of them
+fails to refresh, all the others are reverted to their previous revisions.
+
+The --check option is meant for patch management tools: it does not refresh
+anything but exits with 0 if all snaps are up to date, with 100 if updates are
+available and with 101 if at least one of the updates requires a reboot. With
+--json the details of the available updates are printed in JSON format.
 `)
 
 var longTryHelp = i18n.G(`
@@ -135,6 +157,7 @@ type cmdRemove struct {
 	Revision   string `long:"revision"`
 	Purge      bool   `long:"purge"`
 	Terminate  bool   `long:"terminate"`
+	DryRun     bool   `long:"dry-run"`
 	Positional struct {
 		Snaps []installedSnapName `positional-arg-name:"<snap>" required:"1"`
 	} `positional-args:"yes" required:"yes"`
@@ -311,6 +334,9 @@ func (x *cmdRemove) removeMany(opts *client.SnapOptions) error {
 
 func (x *cmdRemove) Execute([]string) error {
 	opts := &client.SnapOptions{Revision: x.Revision, Purge: x.Purge, Terminate: x.Terminate}
+	if x.DryRun {
+		return showPlan(x.client, "remove", installedSnapNames(x.Positional.Snaps), opts)
+	}
 	if len(x.Positional.Snaps) == 1 {
 		return x.removeOne(opts)
 	}
@@ -706,6 +732,9 @@ type cmdInstall struct {
 	IgnoreRunning    bool                   `long:"ignore-running" hidden:"yes"`
 	Transaction      client.TransactionType `long:"transaction" default:"per-snap" choice:"all-snaps" choice:"per-snap"`
 	QuotaGroupName   string                 `long:"quota-group"`
+	DryRun           bool                   `long:"dry-run"`
+	AcceptPrereqs    bool                   `long:"accept-prerequisites"`
+	Assertions       []flags.Filename       `long:"assertions"`
 	Positional       struct {
 		Snaps []remoteSnapName `positional-arg-name:"<snap>" required:"1"`
 	} `positional-args:"yes" required:"yes"`
@@ -734,6 +763,9 @@ func (x *cmdInstall) installOne(nameOrPath, desiredName string, opts *client.Sna
 		}
 
 		changeID, err = x.client.Install(name, comps, opts)
+		if confirmPrerequisites(err, opts) {
+			changeID, err = x.client.Install(name, comps, opts)
+		}
 	}
 	if err != nil {
 		msg, err := errorToCmdMessage(nameOrPath, "install", err, opts)
@@ -797,6 +829,9 @@ func (x *cmdInstall) installMany(names []string, opts *client.SnapOptions) error
 			return e
 		}
 		changeID, err = x.client.InstallMany(names, compsBySnap, opts)
+		if confirmPrerequisites(err, opts) {
+			changeID, err = x.client.InstallMany(names, compsBySnap, opts)
+		}
 	}
 
 	if err != nil {
@@ -865,16 +900,17 @@ func (x *cmdInstall) Execute([]string) error {
 
 	dangerous := x.Dangerous || x.ForceDangerous
 	opts := &client.SnapOptions{
-		Channel:          x.Channel,
-		Revision:         x.Revision,
-		Dangerous:        dangerous,
-		Unaliased:        x.Unaliased,
-		CohortKey:        x.Cohort,
-		IgnoreValidation: x.IgnoreValidation,
-		IgnoreRunning:    x.IgnoreRunning,
-		Transaction:      x.Transaction,
-		QuotaGroupName:   x.QuotaGroupName,
-		Prefer:           x.Prefer,
+		Channel:             x.Channel,
+		Revision:            x.Revision,
+		Dangerous:           dangerous,
+		Unaliased:           x.Unaliased,
+		CohortKey:           x.Cohort,
+		IgnoreValidation:    x.IgnoreValidation,
+		IgnoreRunning:       x.IgnoreRunning,
+		Transaction:         x.Transaction,
+		QuotaGroupName:      x.QuotaGroupName,
+		Prefer:              x.Prefer,
+		AcceptPrerequisites: x.AcceptPrereqs,
 	}
 	x.setModes(opts)
 
@@ -885,6 +921,33 @@ func (x *cmdInstall) Execute([]string) error {
 		}
 	}
 
+	if len(x.Assertions) > 0 {
+		for _, name := range names {
+			if !isLocalContainer(name) {
+				return errors.New(i18n.G("cannot use --assertions when installing snaps from the store"))
+			}
+		}
+		for _, fn := range x.Assertions {
+			data, err := os.ReadFile(string(fn))
+			if err != nil {
+				return fmt.Errorf(i18n.G("cannot read assertions: %v"), err)
+			}
+			opts.Assertions = append(opts.Assertions, data)
+		}
+	}
+
+	if x.DryRun {
+		for _, name := range names {
+			if isLocalContainer(name) {
+				return errors.New(i18n.G("cannot use --dry-run when installing local snap files"))
+			}
+		}
+		if x.Name != "" {
+			return errors.New(i18n.G("cannot use explicit name when installing from store"))
+		}
+		return showPlan(x.client, "install", names, opts)
+	}
+
 	if len(names) == 1 {
 		return x.installOne(names[0], x.Name, opts)
 	}
@@ -917,12 +980,16 @@ type cmdRefresh struct {
 	Cohort           string                 `long:"cohort"`
 	LeaveCohort      bool                   `long:"leave-cohort"`
 	List             bool                   `long:"list"`
+	Check            bool                   `long:"check"`
+	JSON             bool                   `long:"json"`
 	Time             bool                   `long:"time"`
 	IgnoreValidation bool                   `long:"ignore-validation"`
 	IgnoreRunning    bool                   `long:"ignore-running" hidden:"yes"`
 	Transaction      client.TransactionType `long:"transaction" default:"per-snap" choice:"all-snaps" choice:"per-snap"`
 	Hold             string                 `long:"hold" optional:"yes" optional-value:"forever"`
 	Unhold           bool                   `long:"unhold"`
+	DryRun           bool                   `long:"dry-run"`
+	AcceptPrereqs    bool                   `long:"accept-prerequisites"`
 	Positional       struct {
 		Snaps []installedSnapName `positional-arg-name:"<snap>"`
 	} `positional-args:"yes"`
@@ -936,6 +1003,9 @@ func (x *cmdRefresh) refreshMany(snaps []string, opts *client.SnapOptions) error
 	}
 
 	changeID, err := x.client.RefreshMany(names, compsBySnap, opts)
+	if confirmPrerequisites(err, opts) {
+		changeID, err = x.client.RefreshMany(names, compsBySnap, opts)
+	}
 	if err != nil {
 		return err
 	}
@@ -970,6 +1040,9 @@ func (x *cmdRefresh) refreshOne(name string, opts *client.SnapOptions) error {
 	}
 
 	changeID, err := x.client.Refresh(snapName, comps, opts)
+	if confirmPrerequisites(err, opts) {
+		changeID, err = x.client.Refresh(snapName, comps, opts)
+	}
 	if err != nil {
 		msg, err := errorToCmdMessage(snapName, "refresh", err, opts)
 		if err != nil {
@@ -1023,13 +1096,7 @@ func (x *cmdRefresh) showRefreshTimes() error {
 		fmt.Fprintf(Stdout, "last: n/a\n")
 	}
 	if !hold.IsZero() {
-		// show holds over 100 years as "forever", like in the input of 'snap refresh
-		// --hold', instead of as a distant time (how they're internally represented)
-		if hold.After(timeNow().Add(100 * 365 * 24 * time.Hour)) {
-			fmt.Fprintf(Stdout, "hold: forever\n")
-		} else {
-			fmt.Fprintf(Stdout, "hold: %s\n", x.fmtTime(hold))
-		}
+		fmt.Fprintf(Stdout, "hold: %s\n", x.fmtHoldTime(hold))
 	}
 	// only show "next" if its after "hold" to not confuse users
 	if !next.IsZero() {
@@ -1043,6 +1110,43 @@ func (x *cmdRefresh) showRefreshTimes() error {
 	} else {
 		fmt.Fprintf(Stdout, "next: n/a\n")
 	}
+	return x.showHeldSnaps()
+}
+
+// fmtHoldTime formats the time until which refreshes are held.
+func (x *cmdRefresh) fmtHoldTime(hold time.Time) string {
+	// show holds over 100 years as "forever", like in the input of 'snap refresh
+	// --hold', instead of as a distant time (how they're internally represented)
+	if hold.After(timeNow().Add(100 * 365 * 24 * time.Hour)) {
+		return "forever"
+	}
+	return x.fmtTime(hold)
+}
+
+// showHeldSnaps lists the snaps whose refreshes are held by the user.
+func (x *cmdRefresh) showHeldSnaps() error {
+	snaps, err := x.client.List(nil, nil)
+	if err != nil {
+		if err == client.ErrNoSnapsInstalled {
+			return nil
+		}
+		return err
+	}
+
+	var held []*client.Snap
+	for _, snp := range snaps {
+		if snp.Hold != nil && snp.Hold.After(timeNow()) {
+			held = append(held, snp)
+		}
+	}
+	if len(held) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(Stdout, "held snaps:\n")
+	for _, snp := range held {
+		fmt.Fprintf(Stdout, "  %s: %s\n", snp.Name, x.fmtHoldTime(*snp.Hold))
+	}
 	return nil
 }
 
@@ -1073,6 +1177,116 @@ func (x *cmdRefresh) listRefresh() error {
 	return nil
 }
 
+const (
+	refreshCheckUpdatesAvailable = 100
+	refreshCheckRebootRequired   = 101
+)
+
+type refreshCheckUpdate struct {
+	Name           string `json:"name"`
+	Version        string `json:"version"`
+	Revision       string `json:"revision"`
+	Size           int64  `json:"size"`
+	Publisher      string `json:"publisher,omitempty"`
+	RebootRequired bool   `json:"reboot-required"`
+}
+
+type refreshCheckResult struct {
+	Updates        []refreshCheckUpdate `json:"updates"`
+	RebootRequired bool                 `json:"reboot-required"`
+}
+
+// refreshRequiresReboot returns whether refreshing the given snap requires a
+// reboot of the system, which is the case for the snaps that participate in
+// booting the given model.
+func refreshRequiresReboot(sn *client.Snap, model *asserts.Model) bool {
+	if model == nil {
+		return false
+	}
+	// classic systems without a kernel snap boot without snaps
+	if model.Classic() && model.Kernel() == "" {
+		return false
+	}
+	switch sn.Type {
+	case string(snap.TypeKernel):
+		return sn.Name == model.Kernel()
+	case string(snap.TypeGadget):
+		return sn.Name == model.Gadget()
+	case string(snap.TypeOS):
+		// bases do not participate in booting classic systems
+		return !model.Classic() && model.Base() == ""
+	case string(snap.TypeBase):
+		return !model.Classic() && sn.Name == model.Base()
+	}
+	return false
+}
+
+func (x *cmdRefresh) checkRefresh() error {
+	snaps, _, err := x.client.Find(&client.FindOptions{
+		Refresh: true,
+	})
+	if err != nil {
+		return err
+	}
+	sort.Sort(snapsByName(snaps))
+
+	var model *asserts.Model
+	for _, sn := range snaps {
+		switch sn.Type {
+		case string(snap.TypeKernel), string(snap.TypeGadget), string(snap.TypeOS), string(snap.TypeBase):
+		default:
+			continue
+		}
+		// only refreshes of boot snaps may require a reboot
+		model, err = x.client.CurrentModelAssertion()
+		if err != nil {
+			return err
+		}
+		break
+	}
+
+	res := refreshCheckResult{Updates: []refreshCheckUpdate{}}
+	for _, sn := range snaps {
+		update := refreshCheckUpdate{
+			Name:           sn.Name,
+			Version:        sn.Version,
+			Revision:       sn.Revision.String(),
+			Size:           sn.DownloadSize,
+			RebootRequired: refreshRequiresReboot(sn, model),
+		}
+		if sn.Publisher != nil {
+			update.Publisher = sn.Publisher.Username
+		}
+		res.Updates = append(res.Updates, update)
+		res.RebootRequired = res.RebootRequired || update.RebootRequired
+	}
+
+	if x.JSON {
+		enc := json.NewEncoder(Stdout)
+		if err := enc.Encode(&res); err != nil {
+			return err
+		}
+	} else {
+		switch {
+		case len(res.Updates) == 0:
+			fmt.Fprintln(Stdout, i18n.G("All snaps up to date."))
+		case res.RebootRequired:
+			fmt.Fprintf(Stdout, i18n.NG("%d update available, reboot required.\n", "%d updates available, reboot required.\n", len(res.Updates)), len(res.Updates))
+		default:
+			fmt.Fprintf(Stdout, i18n.NG("%d update available.\n", "%d updates available.\n", len(res.Updates)), len(res.Updates))
+		}
+	}
+
+	// make the availability of updates usable from scripts
+	switch {
+	case res.RebootRequired:
+		panic(&exitStatus{refreshCheckRebootRequired})
+	case len(res.Updates) > 0:
+		panic(&exitStatus{refreshCheckUpdatesAvailable})
+	}
+	return nil
+}
+
 func (x *cmdRefresh) Execute([]string) error {
 	if err := x.setChannelFromCommandline(); err != nil {
 		return err
@@ -1088,6 +1302,10 @@ func (x *cmdRefresh) Execute([]string) error {
 		return x.showRefreshTimes()
 	}
 
+	if x.JSON && !x.Check {
+		return errors.New(i18n.G("--json can only be used with --check"))
+	}
+
 	if x.List {
 		if len(x.Positional.Snaps) > 0 || x.asksForMode() || x.asksForChannel() {
 			return errors.New(i18n.G("--list does not accept additional arguments"))
@@ -1096,14 +1314,23 @@ func (x *cmdRefresh) Execute([]string) error {
 		return x.listRefresh()
 	}
 
+	if x.Check {
+		if len(x.Positional.Snaps) > 0 || x.asksForMode() || x.asksForChannel() ||
+			x.Hold != "" || x.Unhold {
+			return errors.New(i18n.G("--check does not accept additional arguments"))
+		}
+
+		return x.checkRefresh()
+	}
+
 	if len(x.Positional.Snaps) == 0 && os.Getenv("SNAP_REFRESH_FROM_TIMER") == "1" {
 		fmt.Fprintf(Stdout, "Ignoring `snap refresh` from the systemd timer")
 		return nil
 	}
 
 	otherFlags := x.Amend || x.Revision != "" || x.Cohort != "" ||
-		x.LeaveCohort || x.List || x.Time || x.IgnoreValidation || x.IgnoreRunning ||
-		x.Transaction != client.TransactionPerSnap
+		x.LeaveCohort || x.List || x.Check || x.Time || x.IgnoreValidation || x.IgnoreRunning ||
+		x.Transaction != client.TransactionPerSnap || x.DryRun || x.AcceptPrereqs
 
 	if x.Hold != "" && (x.Unhold || otherFlags) {
 		return errors.New(i18n.G("cannot use --hold with other flags"))
@@ -1118,23 +1345,28 @@ func (x *cmdRefresh) Execute([]string) error {
 	names := installedSnapNames(x.Positional.Snaps)
 	if len(names) == 1 {
 		opts := &client.SnapOptions{
-			Amend:            x.Amend,
-			Channel:          x.Channel,
-			IgnoreValidation: x.IgnoreValidation,
-			IgnoreRunning:    x.IgnoreRunning,
-			Revision:         x.Revision,
-			CohortKey:        x.Cohort,
-			LeaveCohort:      x.LeaveCohort,
-			Transaction:      x.Transaction,
+			Amend:               x.Amend,
+			Channel:             x.Channel,
+			IgnoreValidation:    x.IgnoreValidation,
+			IgnoreRunning:       x.IgnoreRunning,
+			Revision:            x.Revision,
+			CohortKey:           x.Cohort,
+			LeaveCohort:         x.LeaveCohort,
+			Transaction:         x.Transaction,
+			AcceptPrerequisites: x.AcceptPrereqs,
 		}
 		x.setModes(opts)
+		if x.DryRun {
+			return showPlan(x.client, "refresh", names, opts)
+		}
 		return x.refreshOne(names[0], opts)
 	}
-	// transaction flag and ignore-running flags are the only ones with meaning when
-	// refreshing many snaps
+	// transaction, ignore-running and accept-prerequisites flags are the only
+	// ones with meaning when refreshing many snaps
 	opts := &client.SnapOptions{
-		IgnoreRunning: x.IgnoreRunning,
-		Transaction:   x.Transaction,
+		IgnoreRunning:       x.IgnoreRunning,
+		Transaction:         x.Transaction,
+		AcceptPrerequisites: x.AcceptPrereqs,
 	}
 
 	if x.asksForMode() || x.asksForChannel() {
@@ -1145,6 +1377,9 @@ func (x *cmdRefresh) Execute([]string) error {
 		return errors.New(i18n.G("a single snap name must be specified when ignoring validation"))
 	}
 
+	if x.DryRun {
+		return showPlan(x.client, "refresh", names, opts)
+	}
 	return x.refreshMany(names, opts)
 }
 
@@ -1498,6 +1733,76 @@ func (x cmdSwitch) Execute(args []string) error {
 		"switch", opts, nil)
 }
 
+// showPlan prints what the given action on the given snaps would do,
+// without performing it.
+func showPlan(cli *client.Client, action string, names []string, opts *client.SnapOptions) error {
+	for _, name := range names {
+		if _, comps := snap.SplitSnapInstanceAndComponents(name); len(comps) != 0 {
+			return errors.New(i18n.G("cannot use --dry-run with components"))
+		}
+	}
+
+	plan, err := cli.PlanSnapAction(action, names, opts)
+	if err != nil {
+		return err
+	}
+	if len(plan.Tasks) == 0 {
+		fmt.Fprintln(Stderr, i18n.G("Nothing to do."))
+		return nil
+	}
+
+	w := tabWriter()
+	defer w.Flush()
+	fmt.Fprintf(w, "%s\n", plan.Summary)
+	if len(plan.Prerequisites) > 0 {
+		fmt.Fprintf(w, i18n.G("Prerequisites:\t%s\n"), strings.Join(plan.Prerequisites, ", "))
+	}
+	if plan.DownloadSize > 0 {
+		fmt.Fprintf(w, i18n.G("Download size:\t%s\n"), strutil.SizeToStr(plan.DownloadSize))
+	}
+	reboot := i18n.G("no")
+	if plan.RebootRequired {
+		reboot = i18n.G("yes")
+	}
+	fmt.Fprintf(w, i18n.G("Reboot required:\t%s\n"), reboot)
+	fmt.Fprintln(w, i18n.G("Tasks:"))
+	for _, t := range plan.Tasks {
+		fmt.Fprintf(w, "  %s\n", t.Summary)
+	}
+	return nil
+}
+
+// confirmPrerequisites asks the user whether to install the prerequisites
+// that the given error says need to be confirmed first. It returns whether
+// they were accepted, in which case opts is updated so that the request can
+// be retried.
+func confirmPrerequisites(err error, opts *client.SnapOptions) bool {
+	var cerr *client.Error
+	if !errors.As(err, &cerr) || cerr.Kind != client.ErrorKindPrerequisitesConfirmationRequired {
+		return false
+	}
+	if opts.AcceptPrerequisites || !isStdinTTY {
+		return false
+	}
+
+	var prereqs []string
+	if value, ok := cerr.Value.(map[string]interface{}); ok {
+		names, _ := value["prerequisites"].([]interface{})
+		for _, name := range names {
+			prereqs = append(prereqs, fmt.Sprint(name))
+		}
+	}
+	// TRANSLATORS: the %s is a list of snap names
+	fmt.Fprintf(Stdout, i18n.G("The following prerequisites will also be installed: %s\nContinue? [y/N] "), strings.Join(prereqs, ", "))
+	answer, _ := bufio.NewReader(Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		opts.AcceptPrerequisites = true
+		return true
+	}
+	return false
+}
+
 func init() {
 	addCommand("remove", shortRemoveHelp, longRemoveHelp, func() flags.Commander { return &cmdRemove{} },
 		waitDescs.also(map[string]string{
@@ -1507,6 +1812,8 @@ func init() {
 			"purge": i18n.G("Remove the snap without saving a snapshot of its data"),
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"terminate": i18n.G("Terminate running processes associated with a snap before removal"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"dry-run": i18n.G("Show what removing the snaps would do without removing them"),
 		}), nil)
 	addCommand("install", shortInstallHelp, longInstallHelp, func() flags.Commander { return &cmdInstall{} },
 		colorDescs.also(waitDescs).also(channelDescs).also(modeDescs).also(map[string]string{
@@ -1532,6 +1839,12 @@ func init() {
 			"quota-group": i18n.G("Add the snap to a quota group on install"),
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"prefer": i18n.G("Enable all aliases of the given snap in preference to conflicting aliases of other snaps"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"dry-run": i18n.G("Show what installing the snaps would do without installing them"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"accept-prerequisites": i18n.G("Install the prerequisites of the snaps without asking for confirmation"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"assertions": i18n.G("Acknowledge the assertions in the given file before installing the snap files"),
 		}), nil)
 	addCommand("refresh", shortRefreshHelp, longRefreshHelp, func() flags.Commander { return &cmdRefresh{} },
 		colorDescs.also(waitDescs).also(channelDescs).also(modeDescs).also(timeDescs).also(map[string]string{
@@ -1542,6 +1855,10 @@ func init() {
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"list": i18n.G("Show the new versions of snaps that would be updated with the next refresh"),
 			// TRANSLATORS: This should not start with a lowercase letter.
+			"check": i18n.G("Exit with a status telling whether updates, possibly requiring a reboot, are available"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"json": i18n.G("Show the details of the available updates in JSON format (with --check)"),
+			// TRANSLATORS: This should not start with a lowercase letter.
 			"time": i18n.G("Show auto refresh information but do not perform a refresh"),
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"ignore-validation": i18n.G("Ignore validation by other snaps blocking the refresh"),
@@ -1557,6 +1874,10 @@ func init() {
 			"hold": i18n.G("Hold refreshes for a specified duration (or forever, if no value is specified)"),
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"unhold": i18n.G("Remove refresh hold"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"dry-run": i18n.G("Show what refreshing the snaps would do without refreshing them"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"accept-prerequisites": i18n.G("Install the prerequisites of the snaps without asking for confirmation"),
 		}), nil)
 	addCommand("try", shortTryHelp, longTryHelp, func() flags.Commander { return &cmdTry{} }, waitDescs.also(modeDescs), nil)
 	addCommand("enable", shortEnableHelp, longEnableHelp, func() flags.Commander { return &cmdEnable{} }, waitDescs, nil)
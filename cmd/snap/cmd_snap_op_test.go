@@ -1476,6 +1476,53 @@ func (s *SnapOpSuite) TestInstallPathDangerous(c *check.C) {
 	c.Check(s.srv.n, check.Equals, s.srv.total)
 }
 
+func (s *SnapOpSuite) TestInstallPathWithAssertions(c *check.C) {
+	s.srv.checker = func(r *http.Request) {
+		c.Check(r.URL.Path, check.Equals, "/v2/snaps")
+		form := testForm(r, c)
+		defer form.RemoveAll()
+
+		c.Check(form.Value["action"], check.DeepEquals, []string{"install"})
+		c.Check(form.Value["dangerous"], check.IsNil)
+		c.Check(form.Value["assertion"], check.DeepEquals, []string{"assertion-data"})
+		c.Check(form.Value["snap-path"], check.NotNil)
+		c.Check(form.Value["transaction"], check.NotNil)
+		c.Check(form.Value, check.HasLen, 4)
+
+		name, _, body := formFile(form, c)
+		c.Check(name, check.Equals, "snap")
+		c.Check(string(body), check.Equals, "snap-data")
+	}
+
+	s.RedirectClientToTestServer(s.srv.handle)
+	dir := c.MkDir()
+	snapPath := filepath.Join(dir, "foo.snap")
+	c.Assert(os.WriteFile(snapPath, []byte("snap-data"), 0644), check.IsNil)
+	assertPath := filepath.Join(dir, "foo.assert")
+	c.Assert(os.WriteFile(assertPath, []byte("assertion-data"), 0644), check.IsNil)
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"install", "--assertions", assertPath, snapPath})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(s.Stdout(), check.Matches, `(?sm).*foo 1.0 from Bar installed`)
+	c.Check(s.Stderr(), check.Equals, "")
+	// ensure that the fake server api was actually hit
+	c.Check(s.srv.n, check.Equals, s.srv.total)
+}
+
+func (s *SnapOpSuite) TestInstallAssertionsErrors(c *check.C) {
+	s.RedirectClientToTestServer(nil)
+	dir := c.MkDir()
+	snapPath := filepath.Join(dir, "foo.snap")
+	c.Assert(os.WriteFile(snapPath, []byte("snap-data"), 0644), check.IsNil)
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"install", "--assertions", snapPath, "foo"})
+	c.Assert(err, check.ErrorMatches, "cannot use --assertions when installing snaps from the store")
+
+	_, err = snap.Parser(snap.Client()).ParseArgs([]string{"install", "--assertions", filepath.Join(dir, "missing.assert"), snapPath})
+	c.Assert(err, check.ErrorMatches, "cannot read assertions: .*no such file or directory")
+}
+
 func (s *SnapOpSuite) TestInstallPathQuotaGroup(c *check.C) {
 	s.srv.checker = func(r *http.Request) {
 		c.Check(r.URL.Path, check.Equals, "/v2/snaps")
@@ -1847,6 +1894,160 @@ foo +4.2update1 +17 +436MB +bar +-.*
 	c.Check(n, check.Equals, 1)
 }
 
+func (s *SnapSuite) TestRefreshCheckLessOptions(c *check.C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Fatal("expected to get 0 requests")
+	})
+
+	for _, flag := range []string{"--beta", "--channel=potato", "--classic"} {
+		_, err := snap.Parser(snap.Client()).ParseArgs([]string{"refresh", "--check", flag})
+		c.Assert(err, check.ErrorMatches, "--check does not accept additional arguments")
+	}
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"refresh", "--check", "some-snap"})
+	c.Assert(err, check.ErrorMatches, "--check does not accept additional arguments")
+
+	for _, flag := range []string{"--hold", "--hold=1h", "--unhold"} {
+		_, err := snap.Parser(snap.Client()).ParseArgs([]string{"refresh", "--check", flag})
+		c.Assert(err, check.ErrorMatches, "--check does not accept additional arguments")
+	}
+
+	for _, args := range [][]string{{"refresh", "--json"}, {"refresh", "--list", "--json"}} {
+		_, err = snap.Parser(snap.Client()).ParseArgs(args)
+		c.Assert(err, check.ErrorMatches, "--json can only be used with --check")
+	}
+}
+
+func (s *SnapSuite) TestRefreshCheckUpToDate(c *check.C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.Method, check.Equals, "GET")
+			c.Check(r.URL.Path, check.Equals, "/v2/find")
+			c.Check(r.URL.Query().Get("select"), check.Equals, "refresh")
+			fmt.Fprintln(w, `{"type": "sync", "result": []}`)
+		default:
+			c.Fatalf("expected to get 1 requests, now on %d", n+1)
+		}
+
+		n++
+	})
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"refresh", "--check"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(s.Stdout(), check.Equals, "All snaps up to date.\n")
+	c.Check(s.Stderr(), check.Equals, "")
+	c.Check(n, check.Equals, 1)
+}
+
+func (s *SnapSuite) TestRefreshCheckUpToDateJSON(c *check.C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, check.Equals, "/v2/find")
+		fmt.Fprintln(w, `{"type": "sync", "result": []}`)
+	})
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"refresh", "--check", "--json"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stdout(), check.Equals, `{"updates":[],"reboot-required":false}`+"\n")
+	c.Check(s.Stderr(), check.Equals, "")
+}
+
+func (s *SnapSuite) TestRefreshCheckUpdatesAvailable(c *check.C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.URL.Path, check.Equals, "/v2/find")
+			c.Check(r.URL.Query().Get("select"), check.Equals, "refresh")
+			fmt.Fprintln(w, `{"type": "sync", "result": [{"name": "foo", "type": "app", "version": "4.2update1", "download-size": 436375552, "publisher": {"id": "bar-id", "username": "bar"}, "revision": 17}, {"name": "core22", "type": "base", "version": "22", "download-size": 1000, "revision": 3}]}`)
+		case 1:
+			c.Check(r.URL.Path, check.Equals, "/v2/model")
+			w.Header().Set("X-Ubuntu-Assertions-Count", "1")
+			fmt.Fprint(w, happyModelAssertionResponse)
+		default:
+			c.Fatalf("expected to get 2 requests, now on %d", n+1)
+		}
+
+		n++
+	})
+	c.Check(func() {
+		snap.Parser(snap.Client()).ParseArgs([]string{"refresh", "--check", "--json"})
+	}, check.PanicMatches, `internal error: exitStatus\{100\} .*`)
+	c.Check(s.Stdout(), check.Equals, `{"updates":[{"name":"core22","version":"22","revision":"3","size":1000,"reboot-required":false},{"name":"foo","version":"4.2update1","revision":"17","size":436375552,"publisher":"bar","reboot-required":false}],"reboot-required":false}`+"\n")
+	c.Check(s.Stderr(), check.Equals, "")
+	c.Check(n, check.Equals, 2)
+}
+
+const classicModelAssertionResponse = `type: model
+authority-id: mememe
+series: 16
+brand-id: mememe
+model: test-classic-model
+architecture: amd64
+classic: true
+gadget: pc
+store: mememestore
+timestamp: 2017-07-27T00:00:00.0Z
+sign-key-sha3-384: 8B3Wmemeu3H6i4dEV4Q85Q4gIUCHIBCNMHq49e085QeLGHi7v27l3Cqmemer4__t
+
+AcLBcwQAAQoAHRYhBMbX+t6MbKGH5C3nnLZW7+q0g6ELBQJdTdwTAAoJELZW7+q0g6ELEvgQAI3j
+jXTqR6kKOqvw94pArwdMDUaZ++tebASAZgso8ejrW2DQGWSc0Q7SQICIR8bvHxqS1GtupQswOzwS
+U8hjDTv7WEchH1jylyTj/1W1GernmitTKycecRlEkSOE+EpuqBFgTtj6PdA1Fj3CiCRi1rLMhgF2
+luCOitBLaP+E8P3fuATsLqqDLYzt1VY4Y14MU75hMn+CxAQdnOZTI+NzGMasPsldmOYCPNaN/b3N
+6/fDLU47RtNlMJ3K0Tz8kj0bqRbegKlD0RdNbAgo9iZwNmrr5E9WCu9f/0rUor/NIxO77H2ExIll
+zhmsZ7E6qlxvAgBmzKgAXrn68gGrBkIb0eXKiCaKy/i2ApvjVZ9HkOzA6Ldd+SwNJv/iA8rdiMsq
+p2BfKV5f3ju5b6+WktHxAakJ8iqQmj9Yh7piHjsOAUf1PEJd2s2nqQ+pEEn1F0B23gVCY/Fa9YRQ
+iKtWVeL3rBw4dSAaK9rpTMqlNcr+yrdXfTK5YzkCC6RU4yzc5MW0hKeseeSiEDSaRYxvftjFfVNa
+ZaVXKg8Lu+cHtCJDeYXEkPIDQzXswdBO1M8Mb9D0mYxQwHxwvsWv1DByB+Otq08EYgPh4kyHo7ag
+85yK2e/NQ/fxSwQJMhBF74jM1z9arq6RMiE/KOleFAOraKn2hcROKnEeinABW+sOn6vNuMVv
+`
+
+func (s *SnapSuite) TestRefreshCheckRebootRequired(c *check.C) {
+	for _, tc := range []struct {
+		snaps  string
+		model  string
+		reboot bool
+	}{
+		{`{"name": "pc-kernel", "type": "kernel", "version": "6.8", "revision": 10}`, happyModelAssertionResponse, true},
+		{`{"name": "pc", "type": "gadget", "version": "22", "revision": 2}`, happyModelAssertionResponse, true},
+		// the model base is core18
+		{`{"name": "core18", "type": "base", "version": "18", "revision": 5}`, happyModelAssertionResponse, true},
+		{`{"name": "core", "type": "os", "version": "16", "revision": 20}`, happyModelAssertionResponse, false},
+		{`{"name": "core20", "type": "base", "version": "20", "revision": 5}`, happyModelAssertionResponse, false},
+		{`{"name": "other-kernel", "type": "kernel", "version": "6.8", "revision": 10}`, happyModelAssertionResponse, false},
+		{`{"name": "pc-kernel", "type": "kernel", "version": "6.8", "revision": 10}`, happyUC20ModelAssertionResponse, true},
+		{`{"name": "core20", "type": "base", "version": "20", "revision": 5}`, happyUC20ModelAssertionResponse, true},
+		// nothing on a classic system boots from snaps
+		{`{"name": "pc", "type": "gadget", "version": "22", "revision": 2}`, classicModelAssertionResponse, false},
+		{`{"name": "core", "type": "os", "version": "16", "revision": 20}`, classicModelAssertionResponse, false},
+	} {
+		s.ResetStdStreams()
+		s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/v2/find":
+				fmt.Fprintf(w, `{"type": "sync", "result": [{"name": "foo", "type": "app", "version": "1", "revision": 1}, %s]}`+"\n", tc.snaps)
+			case "/v2/model":
+				w.Header().Set("X-Ubuntu-Assertions-Count", "1")
+				fmt.Fprint(w, tc.model)
+			default:
+				c.Fatalf("unexpected request to %s", r.URL.Path)
+			}
+		})
+		comment := check.Commentf(tc.snaps)
+		if tc.reboot {
+			c.Check(func() {
+				snap.Parser(snap.Client()).ParseArgs([]string{"refresh", "--check"})
+			}, check.PanicMatches, `internal error: exitStatus\{101\} .*`, comment)
+			c.Check(s.Stdout(), check.Equals, "2 updates available, reboot required.\n", comment)
+		} else {
+			c.Check(func() {
+				snap.Parser(snap.Client()).ParseArgs([]string{"refresh", "--check"})
+			}, check.PanicMatches, `internal error: exitStatus\{100\} .*`, comment)
+			c.Check(s.Stdout(), check.Equals, "2 updates available.\n", comment)
+		}
+		c.Check(s.Stderr(), check.Equals, "")
+	}
+}
+
 func (s *SnapSuite) TestRefreshLegacyTime(c *check.C) {
 	n := 0
 	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
@@ -1855,8 +2056,12 @@ func (s *SnapSuite) TestRefreshLegacyTime(c *check.C) {
 			c.Check(r.Method, check.Equals, "GET")
 			c.Check(r.URL.Path, check.Equals, "/v2/system-info")
 			fmt.Fprintln(w, `{"type": "sync", "status-code": 200, "result": {"refresh": {"schedule": "00:00-04:59/5:00-10:59/11:00-16:59/17:00-23:59", "last": "2017-04-25T17:35:00+02:00", "next": "2017-04-26T00:58:00+02:00"}}}`)
+		case 1:
+			c.Check(r.Method, check.Equals, "GET")
+			c.Check(r.URL.Path, check.Equals, "/v2/snaps")
+			fmt.Fprintln(w, `{"type": "sync", "status-code": 200, "result": []}`)
 		default:
-			c.Fatalf("expected to get 1 requests, now on %d", n+1)
+			c.Fatalf("expected to get 2 requests, now on %d", n+1)
 		}
 
 		n++
@@ -1870,7 +2075,7 @@ next: 2017-04-26T00:58:00+02:00
 `)
 	c.Check(s.Stderr(), check.Equals, "")
 	// ensure that the fake server api was actually hit
-	c.Check(n, check.Equals, 1)
+	c.Check(n, check.Equals, 2)
 }
 
 func (s *SnapSuite) TestRefreshTimer(c *check.C) {
@@ -1881,8 +2086,12 @@ func (s *SnapSuite) TestRefreshTimer(c *check.C) {
 			c.Check(r.Method, check.Equals, "GET")
 			c.Check(r.URL.Path, check.Equals, "/v2/system-info")
 			fmt.Fprintln(w, `{"type": "sync", "status-code": 200, "result": {"refresh": {"timer": "0:00-24:00/4", "last": "2017-04-25T17:35:00+02:00", "next": "2017-04-26T00:58:00+02:00"}}}`)
+		case 1:
+			c.Check(r.Method, check.Equals, "GET")
+			c.Check(r.URL.Path, check.Equals, "/v2/snaps")
+			fmt.Fprintln(w, `{"type": "sync", "status-code": 200, "result": []}`)
 		default:
-			c.Fatalf("expected to get 1 requests, now on %d", n+1)
+			c.Fatalf("expected to get 2 requests, now on %d", n+1)
 		}
 
 		n++
@@ -1896,7 +2105,7 @@ next: 2017-04-26T00:58:00+02:00
 `)
 	c.Check(s.Stderr(), check.Equals, "")
 	// ensure that the fake server api was actually hit
-	c.Check(n, check.Equals, 1)
+	c.Check(n, check.Equals, 2)
 }
 
 func (s *SnapSuite) TestRefreshTimeShowsHolds(c *check.C) {
@@ -1923,8 +2132,12 @@ func (s *SnapSuite) TestRefreshTimeShowsHolds(c *check.C) {
 				c.Check(r.Method, check.Equals, "GET")
 				c.Check(r.URL.Path, check.Equals, "/v2/system-info")
 				fmt.Fprintf(w, `{"type": "sync", "status-code": 200, "result": {"refresh": {"timer": "0:00-24:00/4", "last": "2017-04-25T17:35:00+02:00", "next": "2017-04-26T00:58:00+02:00", "hold": %q}}}`, tc.in)
+			case 1:
+				c.Check(r.Method, check.Equals, "GET")
+				c.Check(r.URL.Path, check.Equals, "/v2/snaps")
+				fmt.Fprintln(w, `{"type": "sync", "status-code": 200, "result": []}`)
 			default:
-				errMsg := fmt.Sprintf("expected to get 1 requests, now on %d", n+1)
+				errMsg := fmt.Sprintf("expected to get 2 requests, now on %d", n+1)
 				c.Error(errMsg)
 				w.WriteHeader(500)
 				w.Write([]byte(errMsg))
@@ -1944,11 +2157,56 @@ next: 2017-04-26T00:58:00+02:00 (but held)
 		c.Check(s.Stdout(), check.Equals, expectedOutput)
 		c.Check(s.Stderr(), check.Equals, "")
 		// ensure that the fake server api was actually hit
-		c.Check(n, check.Equals, 1)
+		c.Check(n, check.Equals, 2)
 		s.ResetStdStreams()
 	}
 }
 
+func (s *SnapSuite) TestRefreshTimeShowsHeldSnaps(c *check.C) {
+	curTime, err := time.Parse(time.RFC3339, "2017-04-27T23:00:00+02:00")
+	c.Assert(err, check.IsNil)
+	restore := snap.MockTimeNow(func() time.Time {
+		return curTime
+	})
+	defer restore()
+
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.Method, check.Equals, "GET")
+			c.Check(r.URL.Path, check.Equals, "/v2/system-info")
+			fmt.Fprintln(w, `{"type": "sync", "status-code": 200, "result": {"refresh": {"timer": "0:00-24:00/4", "last": "2017-04-25T17:35:00+02:00", "next": "2017-04-28T00:58:00+02:00"}}}`)
+		case 1:
+			c.Check(r.Method, check.Equals, "GET")
+			c.Check(r.URL.Path, check.Equals, "/v2/snaps")
+			fmt.Fprintln(w, `{"type": "sync", "status-code": 200, "result": [
+{"name": "bar", "hold": "2117-04-28T00:00:00+02:00"},
+{"name": "baz", "hold": "2017-04-20T00:00:00+02:00"},
+{"name": "foo", "hold": "2017-04-28T00:00:00+02:00"},
+{"name": "quux"}
+]}`)
+		default:
+			c.Fatalf("expected to get 2 requests, now on %d", n+1)
+		}
+
+		n++
+	})
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"refresh", "--time", "--abs-time"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	// expired holds are not shown
+	c.Check(s.Stdout(), check.Equals, `timer: 0:00-24:00/4
+last: 2017-04-25T17:35:00+02:00
+next: 2017-04-28T00:58:00+02:00
+held snaps:
+  bar: forever
+  foo: 2017-04-28T00:00:00+02:00
+`)
+	c.Check(s.Stderr(), check.Equals, "")
+	c.Check(n, check.Equals, 2)
+}
+
 func (s *SnapSuite) TestRefreshHoldAllForever(c *check.C) {
 	var n int
 	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
@@ -2339,6 +2597,28 @@ func (s *SnapOpSuite) TestRefreshOne(c *check.C) {
 	c.Check(s.Stdout(), check.Matches, `(?sm).*foo 1.0 from Bar refreshed`)
 }
 
+func (s *SnapOpSuite) TestRefreshOneNoUpdate(c *check.C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"type": "error", "result": {"message": "snap has no updates available", "kind": "snap-no-update-available"}, "status-code": 400}`)
+	})
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"refresh", "foo"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stdout(), check.Equals, "")
+	c.Check(s.Stderr(), check.Equals, "snap \"foo\" has no updates available\n")
+}
+
+func (s *SnapOpSuite) TestRefreshOneNoUpdateHeldByValidationSets(c *check.C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"type": "error", "result": {"message": "snap \"foo\" has no updates available: revision 3 is required by validation sets: acc/bar=2", "kind": "snap-no-update-available", "value": {"snap": "foo", "presence": "required", "revision": "3", "blocked": ["remove", "refresh", "revert"]}}, "status-code": 400}`)
+	})
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"refresh", "foo"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stdout(), check.Equals, "")
+	c.Check(s.Stderr(), check.Equals, `snap "foo" has no updates available: revision 3 is required by
+       validation sets: acc/bar=2 (see 'snap validate --list-constraints foo')
+`)
+}
+
 func (s *SnapOpSuite) TestRefreshOneAdditionalComponents(c *check.C) {
 	s.RedirectClientToTestServer(s.srv.handle)
 	s.srv.checker = func(r *http.Request) {
@@ -3098,6 +3378,173 @@ func (s *SnapOpSuite) TestRemoveRevision(c *check.C) {
 	c.Check(s.srv.n, check.Equals, s.srv.total)
 }
 
+func (s *SnapOpSuite) TestDryRun(c *check.C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, check.Equals, "POST")
+		switch n {
+		case 0:
+			c.Check(r.URL.Path, check.Equals, "/v2/snaps/foo")
+			c.Check(DecodedRequestBody(c, r), check.DeepEquals, map[string]interface{}{
+				"action":      "install",
+				"channel":     "edge",
+				"transaction": "per-snap",
+				"dry-run":     true,
+			})
+			fmt.Fprintln(w, `{"type": "sync", "result": {
+"summary": "Install \"foo\" snap",
+"snap-names": ["foo"],
+"tasks": [
+  {"kind": "prerequisites", "summary": "Ensure prerequisites for \"foo\" are available", "snap": "foo"},
+  {"kind": "download-snap", "summary": "Download snap \"foo\" (7) from channel \"edge\"", "snap": "foo"}
+],
+"prerequisites": ["core22", "gtk-common-themes"],
+"download-size": 2048000
+}}`)
+		case 1:
+			c.Check(r.URL.Path, check.Equals, "/v2/snaps")
+			c.Check(DecodedRequestBody(c, r), check.DeepEquals, map[string]interface{}{
+				"action":      "refresh",
+				"transaction": "per-snap",
+				"dry-run":     true,
+			})
+			fmt.Fprintln(w, `{"type": "sync", "result": {
+"summary": "Refresh snaps \"pc-kernel\"",
+"tasks": [{"kind": "link-snap", "summary": "Make snap \"pc-kernel\" (12) available to the system", "snap": "pc-kernel"}],
+"reboot-required": true
+}}`)
+		case 2:
+			c.Check(r.URL.Path, check.Equals, "/v2/snaps")
+			c.Check(DecodedRequestBody(c, r), check.DeepEquals, map[string]interface{}{
+				"action":  "remove",
+				"snaps":   []interface{}{"one", "two"},
+				"dry-run": true,
+			})
+			fmt.Fprintln(w, `{"type": "sync", "result": {"summary": "Remove snaps \"one\", \"two\"", "tasks": []}}`)
+		default:
+			c.Fatalf("expected to get 3 requests, now on %d", n+1)
+		}
+		n++
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"install", "--dry-run", "--edge", "foo"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stdout(), check.Equals, `Install "foo" snap
+Prerequisites:    core22, gtk-common-themes
+Download size:    2MB
+Reboot required:  no
+Tasks:
+  Ensure prerequisites for "foo" are available
+  Download snap "foo" (7) from channel "edge"
+`)
+	c.Check(s.Stderr(), check.Equals, "")
+
+	s.ResetStdStreams()
+	_, err = snap.Parser(snap.Client()).ParseArgs([]string{"refresh", "--dry-run"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stdout(), check.Equals, `Refresh snaps "pc-kernel"
+Reboot required:  yes
+Tasks:
+  Make snap "pc-kernel" (12) available to the system
+`)
+
+	s.ResetStdStreams()
+	_, err = snap.Parser(snap.Client()).ParseArgs([]string{"remove", "--dry-run", "one", "two"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stdout(), check.Equals, "")
+	c.Check(s.Stderr(), check.Equals, "Nothing to do.\n")
+	c.Check(n, check.Equals, 3)
+}
+
+func (s *SnapOpSuite) TestDryRunErrors(c *check.C) {
+	s.RedirectClientToTestServer(nil)
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"install", "--dry-run", "./foo.snap"})
+	c.Check(err, check.ErrorMatches, "cannot use --dry-run when installing local snap files")
+
+	_, err = snap.Parser(snap.Client()).ParseArgs([]string{"install", "--dry-run", "foo+comp"})
+	c.Check(err, check.ErrorMatches, "cannot use --dry-run with components")
+
+	_, err = snap.Parser(snap.Client()).ParseArgs([]string{"refresh", "--dry-run", "--hold", "foo"})
+	c.Check(err, check.ErrorMatches, "cannot use --hold with other flags")
+}
+
+const prereqsConfirmationRequiredResponse = `{"type": "error", "status-code": 409, "result": {
+"message": "installing prerequisites \"core22\" needs to be confirmed",
+"kind": "prerequisites-confirmation-required",
+"value": {"snap-names": ["foo"], "prerequisites": ["core22"]}
+}}`
+
+func (s *SnapOpSuite) TestInstallConfirmPrerequisites(c *check.C) {
+	defer snap.MockIsStdinTTY(true)()
+	s.stdin.WriteString("y\n")
+
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, check.Equals, "/v2/snaps/foo")
+		switch n {
+		case 0:
+			c.Check(DecodedRequestBody(c, r), check.DeepEquals, map[string]interface{}{
+				"action":      "install",
+				"transaction": "per-snap",
+			})
+			w.WriteHeader(409)
+			fmt.Fprintln(w, prereqsConfirmationRequiredResponse)
+		case 1:
+			c.Check(DecodedRequestBody(c, r), check.DeepEquals, map[string]interface{}{
+				"action":               "install",
+				"transaction":          "per-snap",
+				"accept-prerequisites": true,
+			})
+			w.WriteHeader(202)
+			fmt.Fprintln(w, `{"type": "async", "change": "42", "status-code": 202}`)
+		default:
+			c.Fatalf("expected to get 2 requests, now on %d", n+1)
+		}
+		n++
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"install", "--no-wait", "foo"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stdout(), check.Equals, "The following prerequisites will also be installed: core22\nContinue? [y/N] 42\n")
+	c.Check(n, check.Equals, 2)
+}
+
+func (s *SnapOpSuite) TestInstallConfirmPrerequisitesDeclined(c *check.C) {
+	for _, tty := range []bool{true, false} {
+		restore := snap.MockIsStdinTTY(tty)
+		s.stdin.Reset()
+		s.stdin.WriteString("n\n")
+
+		n := 0
+		s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(409)
+			fmt.Fprintln(w, prereqsConfirmationRequiredResponse)
+			n++
+		})
+
+		_, err := snap.Parser(snap.Client()).ParseArgs([]string{"install", "foo"})
+		c.Check(err, check.ErrorMatches, `installing prerequisites "core22" needs to be confirmed, use\s+--accept-prerequisites to install them`)
+		c.Check(n, check.Equals, 1)
+		restore()
+	}
+}
+
+func (s *SnapOpSuite) TestRefreshAcceptPrerequisites(c *check.C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(DecodedRequestBody(c, r), check.DeepEquals, map[string]interface{}{
+			"action":               "refresh",
+			"transaction":          "per-snap",
+			"accept-prerequisites": true,
+		})
+		w.WriteHeader(202)
+		fmt.Fprintln(w, `{"type": "async", "change": "42", "status-code": 202}`)
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"refresh", "--no-wait", "--accept-prerequisites", "foo"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stdout(), check.Equals, "42\n")
+}
+
 func (s *SnapOpSuite) TestRemoveManyOptions(c *check.C) {
 	s.RedirectClientToTestServer(nil)
 	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"remove", "--revision=17", "one", "two"})
@@ -3327,6 +3774,52 @@ func (s *SnapOpSuite) TestRefreshManyNoChanges(c *check.C) {
 	c.Check(n, check.Equals, total)
 }
 
+func (s *SnapOpSuite) testManyTransactional(c *check.C, action string) {
+	total := 3
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.URL.Path, check.Equals, "/v2/snaps")
+			c.Check(DecodedRequestBody(c, r), check.DeepEquals, map[string]interface{}{
+				"action":      action,
+				"snaps":       []interface{}{"one", "two"},
+				"transaction": string(client.TransactionAllSnaps),
+			})
+
+			c.Check(r.Method, check.Equals, "POST")
+			w.WriteHeader(202)
+			fmt.Fprintln(w, `{"type":"async", "change": "42", "status-code": 202}`)
+		case 1:
+			c.Check(r.Method, check.Equals, "GET")
+			c.Check(r.URL.Path, check.Equals, "/v2/changes/42")
+			fmt.Fprintln(w, `{"type": "sync", "result": {"status": "Doing"}}`)
+		case 2:
+			c.Check(r.Method, check.Equals, "GET")
+			c.Check(r.URL.Path, check.Equals, "/v2/changes/42")
+			fmt.Fprintln(w, `{"type": "sync", "result": {"ready": true, "status": "Done", "data": {}}}`)
+		default:
+			c.Fatalf("expected to get %d requests, now on %d", total, n+1)
+		}
+
+		n++
+	})
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{action, "--transaction=all-snaps", "one", "two"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	// ensure that the fake server api was actually hit
+	c.Check(n, check.Equals, total)
+}
+
+func (s *SnapOpSuite) TestRefreshManyTransactional(c *check.C) {
+	s.testManyTransactional(c, "refresh")
+}
+
+func (s *SnapOpSuite) TestInstallManyTransactional(c *check.C) {
+	s.testManyTransactional(c, "install")
+}
+
 func (s *SnapOpSuite) TestInstallZeroEmpty(c *check.C) {
 	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"install"})
 	c.Assert(err, check.Not(check.IsNil))
@@ -104,11 +104,16 @@ restriction may be lifted in the future.
 
 var longExportSnapshotHelp = i18n.G(`
 Export a snapshot to the given filename.
+
+If the filename is -, the snapshot is written to standard output, so that
+it can be piped to import-snapshot on another machine.
 `)
 
 var longImportSnapshotHelp = i18n.G(`
 Import an exported snapshot set to the system. The snapshot is imported
 with a new snapshot ID and can be restored using the restore command.
+
+If the filename is -, the snapshot is read from standard input.
 `)
 
 type savedCmd struct {
@@ -450,8 +455,20 @@ func (x *exportSnapshotCmd) Execute([]string) (err error) {
 	if err != nil {
 		return err
 	}
+	defer r.Close()
 
 	filename := x.Positional.Filename
+	if filename == "-" {
+		n, err := io.Copy(Stdout, r)
+		if err != nil {
+			return err
+		}
+		if n != expectedSize {
+			return fmt.Errorf(i18n.G("unexpected size, got: %v but wanted %v"), n, expectedSize)
+		}
+		return nil
+	}
+
 	f, err := os.Create(filename + ".part")
 	if err != nil {
 		return err
@@ -493,11 +510,39 @@ type importSnapshotCmd struct {
 	} `positional-args:"yes" required:"yes"`
 }
 
+// spoolStdin copies the snapshot read from standard input into a temporary
+// file, as importing requires knowing the size of the snapshot upfront.
+func spoolStdin() (*os.File, error) {
+	f, err := os.CreateTemp("", "snapshot-import-")
+	if err != nil {
+		return nil, err
+	}
+	os.Remove(f.Name())
+	if _, err := io.Copy(f, Stdin); err != nil {
+		f.Close()
+		return nil, fmt.Errorf(i18n.G("cannot read snapshot from standard input: %v"), err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
 func (x *importSnapshotCmd) Execute([]string) error {
 	filename := x.Positional.Filename
-	f, err := os.Open(filename)
-	if err != nil {
-		return fmt.Errorf("error accessing file: %v", err)
+	var f *os.File
+	var err error
+	if filename == "-" {
+		f, err = spoolStdin()
+		if err != nil {
+			return err
+		}
+	} else {
+		f, err = os.Open(filename)
+		if err != nil {
+			return fmt.Errorf("error accessing file: %v", err)
+		}
 	}
 	defer f.Close()
 	st, err := f.Stat()
@@ -21,6 +21,7 @@ package main_test
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -113,6 +114,15 @@ func (s *SnapSuite) TestSnapshotExportHappy(c *C) {
 	c.Check(exportedSnapshotPath+".part", testutil.FileAbsent)
 }
 
+func (s *SnapSuite) TestSnapshotExportToStdout(c *C) {
+	s.mockSnapshotsServer(c)
+
+	_, err := main.Parser(main.Client()).ParseArgs([]string{"export-snapshot", "1", "-"})
+	c.Check(err, IsNil)
+	c.Check(s.Stderr(), Equals, "")
+	c.Check(s.Stdout(), Equals, "Hello World!")
+}
+
 func (s *SnapSuite) mockSnapshotsServer(c *C) {
 	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
@@ -167,3 +177,25 @@ Set  Snap  Age    Version  Rev   Size    Notes
 1    htop  %-6s 2        1168      1B  -
 `, ageStr))
 }
+
+func (s *SnapSuite) TestSnapshotImportFromStdin(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, Equals, "/v2/snapshots")
+		if r.Method == "GET" {
+			fmt.Fprintln(w, `{"type":"sync","status-code":200,"status":"OK","result":[]}`)
+			return
+		}
+		c.Check(r.Header.Get("Content-Type"), Equals, client.SnapshotExportMediaType)
+		c.Check(r.ContentLength, Equals, int64(len("snapshot zip file data")))
+		body, err := io.ReadAll(r.Body)
+		c.Check(err, IsNil)
+		c.Check(string(body), Equals, "snapshot zip file data")
+		fmt.Fprintln(w, `{"type": "sync", "result": {"set-id": 42, "snaps": []}}`)
+	})
+	s.stdin.WriteString("snapshot zip file data")
+
+	_, err := main.Parser(main.Client()).ParseArgs([]string{"import-snapshot", "-"})
+	c.Check(err, IsNil)
+	c.Check(s.Stderr(), Equals, "")
+	c.Check(s.Stdout(), Equals, "Imported snapshot as #42\nNo snapshots found.\n")
+}
@@ -20,6 +20,8 @@
 package main
 
 import (
+	"fmt"
+
 	"github.com/jessevdk/go-flags"
 
 	"github.com/snapcore/snapd/i18n"
@@ -37,6 +39,11 @@ snap's configuration hook returns successfully.
 Nested values may be removed via a dotted path:
 
 	$ snap unset snap-name user.name
+
+With --restore, the options modified by the given change, as listed by
+"snap get --history", are set back to their previous values instead:
+
+	$ snap unset --restore 42 snap-name
 `)
 
 var longConfdbUnsetHelp = i18n.G(`
@@ -48,9 +55,10 @@ paths.
 
 type cmdUnset struct {
 	waitMixin
+	Restore    string `long:"restore" value-name:"<change-id>"`
 	Positional struct {
 		Snap     installedSnapName
-		ConfKeys []string `required:"1"`
+		ConfKeys []string
 	} `positional-args:"yes" required:"yes"`
 }
 
@@ -59,7 +67,10 @@ func init() {
 		longUnsetHelp += longConfdbUnsetHelp
 	}
 
-	addCommand("unset", shortUnsetHelp, longUnsetHelp, func() flags.Commander { return &cmdUnset{} }, waitDescs, []argDesc{
+	addCommand("unset", shortUnsetHelp, longUnsetHelp, func() flags.Commander { return &cmdUnset{} }, waitDescs.also(map[string]string{
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"restore": i18n.G("Restore the options modified by the given change to their previous values"),
+	}), []argDesc{
 		{
 			name: "<snap>",
 			// TRANSLATORS: This should not start with a lowercase letter.
@@ -74,12 +85,22 @@ func init() {
 }
 
 func (x *cmdUnset) Execute(args []string) error {
+	snapName := string(x.Positional.Snap)
+	if x.Restore != "" {
+		if len(x.Positional.ConfKeys) != 0 {
+			return fmt.Errorf(i18n.G("cannot use --restore with configuration keys"))
+		}
+		return x.restore(snapName)
+	}
+	if len(x.Positional.ConfKeys) == 0 {
+		return fmt.Errorf(i18n.G("the required argument `<conf key> (at least 1 argument)` was not provided"))
+	}
+
 	patchValues := make(map[string]interface{})
 	for _, confKey := range x.Positional.ConfKeys {
 		patchValues[confKey] = nil
 	}
 
-	snapName := string(x.Positional.Snap)
 	var id string
 	var err error
 
@@ -112,3 +133,23 @@ func (x *cmdUnset) Execute(args []string) error {
 
 	return nil
 }
+
+// restore sets the options modified by the change given with --restore back
+// to the values they had before it.
+func (x *cmdUnset) restore(snapName string) error {
+	if isConfdbViewID(snapName) {
+		return fmt.Errorf(i18n.G("cannot use --restore with a confdb view"))
+	}
+
+	id, err := x.client.RestoreConf(snapName, x.Restore)
+	if err != nil {
+		return err
+	}
+	if _, err := x.wait(id); err != nil {
+		if err == noWait {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
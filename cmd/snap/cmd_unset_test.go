@@ -31,7 +31,7 @@ import (
 func (s *snapSetSuite) TestInvalidUnsetParameters(c *check.C) {
 	invalidParameters := []string{"unset"}
 	_, err := snapunset.Parser(snapunset.Client()).ParseArgs(invalidParameters)
-	c.Check(err, check.ErrorMatches, "the required arguments `<snap>` and `<conf key> \\(at least 1 argument\\)` were not provided")
+	c.Check(err, check.ErrorMatches, "the required argument `<snap>` was not provided")
 	c.Check(s.setConfApiCalls, check.Equals, 0)
 
 	invalidParameters = []string{"unset", "snap-name"}
@@ -40,6 +40,35 @@ func (s *snapSetSuite) TestInvalidUnsetParameters(c *check.C) {
 	c.Check(s.setConfApiCalls, check.Equals, 0)
 }
 
+func (s *snapSetSuite) TestSnapUnsetRestore(c *check.C) {
+	var setCalls int
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, check.Equals, "/v2/snaps/snapname/conf")
+		c.Check(r.Method, check.Equals, "PUT")
+		setCalls++
+		switch r.URL.Query().Get("restore") {
+		case "42":
+			w.WriteHeader(202)
+			fmt.Fprint(w, `{"type": "async", "status-code": 202, "change": "43"}`)
+		default:
+			w.WriteHeader(400)
+			fmt.Fprint(w, `{"type": "error", "status-code": 400, "result": {"message": "no configuration change of snap \"snapname\" recorded for change \"7\""}}`)
+		}
+	})
+
+	_, err := snapunset.Parser(snapunset.Client()).ParseArgs([]string{"unset", "--no-wait", "--restore", "42", "snapname"})
+	c.Assert(err, check.IsNil)
+	c.Check(setCalls, check.Equals, 1)
+	c.Check(s.Stdout(), check.Equals, "43\n")
+
+	_, err = snapunset.Parser(snapunset.Client()).ParseArgs([]string{"unset", "--restore", "7", "snapname"})
+	c.Assert(err, check.ErrorMatches, `no configuration change of snap "snapname" recorded for change "7"`)
+	c.Check(setCalls, check.Equals, 2)
+
+	_, err = snapunset.Parser(snapunset.Client()).ParseArgs([]string{"unset", "--restore", "42", "snapname", "key"})
+	c.Assert(err, check.ErrorMatches, "cannot use --restore with configuration keys")
+}
+
 func (s *snapSetSuite) TestSnapUnset(c *check.C) {
 	// expected value is "nil" as the key is unset
 	s.mockSetConfigServer(c, nil)
@@ -40,6 +40,9 @@ type cmdValidate struct {
 	Positional struct {
 		ValidationSet string `positional-arg-name:"<validation-set>"`
 	} `positional-args:"yes"`
+
+	ListConstraints string `long:"list-constraints" value-name:"<snap>"`
+
 	colorMixin
 	waitMixin
 }
@@ -53,6 +56,13 @@ fixed revisions.
 A validation set can either be in monitoring mode, in which case its constraints
 aren't enforced, or in enforcing mode, in which case snapd will not allow
 operations which would result in snaps breaking the validation set's constraints.
+
+When querying a single validation set, the command exits with status 1 if the
+set is invalid.
+
+With --list-constraints, the command lists the validation sets that constrain
+the presence or the revision of the given snap, and the actions on the snap
+that the enforced ones currently block.
 `)
 
 func init() {
@@ -65,6 +75,8 @@ func init() {
 		"forget": i18n.G("Forget the given validation set"),
 		// TRANSLATORS: This should not start with a lowercase letter.
 		"refresh": i18n.G("Refresh or install snaps to satisfy enforced validation sets"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"list-constraints": i18n.G("List the validation sets constraining the given snap"),
 	})), []argDesc{{
 		// TRANSLATORS: This needs to begin with < and end with >
 		name: i18n.G("<validation-set>"),
@@ -87,7 +99,51 @@ func fmtValidationSet(res *client.ValidationSetResult) string {
 	return fmt.Sprintf("%s/%s=%d", res.AccountID, res.Name, res.PinnedAt)
 }
 
+func fmtConstraintValidationSet(cstr *client.ValidationSetConstraint) string {
+	return fmt.Sprintf("%s/%s=%d", cstr.AccountID, cstr.Name, cstr.Sequence)
+}
+
+func (cmd *cmdValidate) listConstraints() error {
+	res, err := cmd.client.SnapValidationConstraints(cmd.ListConstraints)
+	if err != nil {
+		return err
+	}
+	if len(res.Constraints) == 0 {
+		fmt.Fprintf(Stderr, i18n.G("No validation sets constrain snap %q\n"), cmd.ListConstraints)
+		return nil
+	}
+
+	w := tabWriter()
+	fmt.Fprintln(w, i18n.G("Validation\tMode\tPresence\tRevision"))
+	for _, cstr := range res.Constraints {
+		rev := cstr.Revision
+		if rev == "" {
+			rev = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", fmtConstraintValidationSet(cstr), cstr.Mode, cstr.Presence, rev)
+	}
+	w.Flush()
+
+	if len(res.Blocked) != 0 {
+		fmt.Fprintln(Stdout)
+		if res.Revision != "" {
+			fmt.Fprintf(Stdout, i18n.G("Enforced validation sets require snap %q to be %s at revision %s.\n"), res.Snap, res.Presence, res.Revision)
+		} else {
+			fmt.Fprintf(Stdout, i18n.G("Enforced validation sets require snap %q to be %s.\n"), res.Snap, res.Presence)
+		}
+		fmt.Fprintf(Stdout, i18n.G("Blocked actions: %s\n"), strings.Join(res.Blocked, ", "))
+	}
+	return nil
+}
+
 func (cmd *cmdValidate) Execute(args []string) error {
+	if cmd.ListConstraints != "" {
+		if cmd.Monitor || cmd.Enforce || cmd.Forget || cmd.Refresh || cmd.Positional.ValidationSet != "" {
+			return fmt.Errorf("cannot use --list-constraints with other actions or a validation set")
+		}
+		return cmd.listConstraints()
+	}
+
 	// check that only one action is used at a time
 	var action string
 	for _, a := range []struct {
@@ -211,7 +267,10 @@ func (cmd *cmdValidate) Execute(args []string) error {
 			return err
 		}
 		fmt.Fprint(Stdout, fmtValid(vset))
-		// XXX: exit status 1 if invalid?
+		// make the state of the validation set usable from scripts
+		if !vset.Valid {
+			panic(&exitStatus{1})
+		}
 	}
 
 	return nil
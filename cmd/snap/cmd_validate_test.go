@@ -107,6 +107,8 @@ func (s *validateSuite) TestValidateInvalidArgs(c *check.C) {
 		{[]string{"--monitor"}, `missing validation set argument`},
 		{[]string{"--forget"}, `missing validation set argument`},
 		{[]string{"--forget", "foo/-"}, `cannot parse validation set "foo/-": invalid validation set name "-"`},
+		{[]string{"--list-constraints", "some-snap", "foo/bar"}, `cannot use --list-constraints with other actions or a validation set`},
+		{[]string{"--list-constraints", "some-snap", "--monitor"}, `cannot use --list-constraints with other actions or a validation set`},
 	} {
 		s.stdout.Reset()
 		s.stderr.Reset()
@@ -195,9 +197,9 @@ func (s *validateSuite) TestValidateQueryOneInvalid(c *check.C) {
 
 	s.RedirectClientToTestServer(makeFakeValidationSetQueryHandler(c, `{"type": "sync", "status-code": 200, "result": {"account-id":"foo","name":"bar","mode":"monitor","sequence":3,"valid":false}}`))
 
-	rest, err := main.Parser(main.Client()).ParseArgs([]string{"validate", "foo/bar"})
-	c.Assert(err, check.IsNil)
-	c.Check(rest, check.HasLen, 0)
+	c.Assert(func() {
+		main.Parser(main.Client()).ParseArgs([]string{"validate", "foo/bar"})
+	}, check.PanicMatches, `internal error: exitStatus\{1\} .*`)
 	c.Check(s.Stderr(), check.Equals, "")
 	c.Check(s.Stdout(), check.Equals, "invalid")
 }
@@ -234,6 +236,75 @@ func (s *validateSuite) TestValidationSetsListEmpty(c *check.C) {
 	c.Check(s.Stdout(), check.Equals, "")
 }
 
+func makeFakeSnapValidationConstraintsHandler(c *check.C, body string) func(w http.ResponseWriter, r *http.Request) {
+	var called bool
+	return func(w http.ResponseWriter, r *http.Request) {
+		if called {
+			c.Fatalf("expected a single request")
+		}
+		called = true
+		c.Check(r.URL.Path, check.Equals, "/v2/validation-sets")
+		c.Check(r.URL.Query().Get("snap"), check.Equals, "some-snap")
+		c.Check(r.Method, check.Equals, "GET")
+		w.WriteHeader(200)
+		fmt.Fprintln(w, body)
+	}
+}
+
+func (s *validateSuite) TestValidateListConstraints(c *check.C) {
+	s.RedirectClientToTestServer(makeFakeSnapValidationConstraintsHandler(c, `{"type": "sync", "status-code": 200, "result": {
+		"snap": "some-snap",
+		"constraints": [
+			{"account-id":"foo","name":"bar","sequence":3,"mode":"enforce","presence":"required","revision":"7"},
+			{"account-id":"foo","name":"baz","sequence":1,"mode":"monitor","presence":"optional"}
+		],
+		"presence": "required",
+		"revision": "7",
+		"blocked": ["remove", "refresh", "revert"]
+	}}`))
+
+	rest, err := main.Parser(main.Client()).ParseArgs([]string{"validate", "--list-constraints", "some-snap"})
+	c.Assert(err, check.IsNil)
+	c.Check(rest, check.HasLen, 0)
+	c.Check(s.Stderr(), check.Equals, "")
+	c.Check(s.Stdout(), check.Equals, "Validation  Mode     Presence  Revision\n"+
+		"foo/bar=3   enforce  required  7\n"+
+		"foo/baz=1   monitor  optional  -\n"+
+		"\n"+
+		"Enforced validation sets require snap \"some-snap\" to be required at revision 7.\n"+
+		"Blocked actions: remove, refresh, revert\n",
+	)
+}
+
+func (s *validateSuite) TestValidateListConstraintsInvalid(c *check.C) {
+	s.RedirectClientToTestServer(makeFakeSnapValidationConstraintsHandler(c, `{"type": "sync", "status-code": 200, "result": {
+		"snap": "some-snap",
+		"constraints": [
+			{"account-id":"foo","name":"bar","sequence":3,"mode":"enforce","presence":"invalid"}
+		],
+		"presence": "invalid",
+		"blocked": ["install"]
+	}}`))
+
+	_, err := main.Parser(main.Client()).ParseArgs([]string{"validate", "--list-constraints", "some-snap"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stdout(), check.Equals, "Validation  Mode     Presence  Revision\n"+
+		"foo/bar=3   enforce  invalid   -\n"+
+		"\n"+
+		"Enforced validation sets require snap \"some-snap\" to be invalid.\n"+
+		"Blocked actions: install\n",
+	)
+}
+
+func (s *validateSuite) TestValidateListConstraintsNone(c *check.C) {
+	s.RedirectClientToTestServer(makeFakeSnapValidationConstraintsHandler(c, `{"type": "sync", "status-code": 200, "result": {"snap": "some-snap"}}`))
+
+	_, err := main.Parser(main.Client()).ParseArgs([]string{"validate", "--list-constraints", "some-snap"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stdout(), check.Equals, "")
+	c.Check(s.Stderr(), check.Equals, "No validation sets constrain snap \"some-snap\"\n")
+}
+
 func (s *validateSuite) TestValidateRefreshOnlyUsedWithEnforce(c *check.C) {
 	rest, err := main.Parser(main.Client()).ParseArgs([]string{"validate", "--refresh", "--monitor", "foo/bar"})
 	c.Assert(err, check.ErrorMatches, "--refresh can only be used together with --enforce")
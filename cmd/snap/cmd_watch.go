@@ -20,32 +20,60 @@
 package main
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/jessevdk/go-flags"
 
+	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/snap"
 )
 
-type cmdWatch struct{ changeIDMixin }
+type cmdWatch struct {
+	changeIDMixin
+	ForServices string `long:"for-services" value-name:"<snap>"`
+}
 
 var shortWatchHelp = i18n.G("Watch a change in progress")
 var longWatchHelp = i18n.G(`
 The watch command waits for the given change-id to finish and shows progress
 (if available).
+
+With --for-services, the command additionally waits for the enabled services
+of the given snap to become active. Services that notify systemd of their
+readiness are only active once they have done so. Oneshot services, services
+activated by timers or sockets and user services are not waited for. When no
+change is given, only the services are waited for.
 `)
 
+// maxServicesWaitTime is how long "snap watch --for-services" waits for the
+// services to become active.
+var maxServicesWaitTime = 5 * time.Minute
+
 func init() {
 	addCommand("watch", shortWatchHelp, longWatchHelp, func() flags.Commander {
 		return &cmdWatch{}
-	}, changeIDMixinOptDesc, changeIDMixinArgDesc)
+	}, changeIDMixinOptDesc.also(map[string]string{
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"for-services": i18n.G("Wait for the enabled services of the given snap to become active"),
+	}), changeIDMixinArgDesc)
 }
 
 func (x *cmdWatch) Execute(args []string) error {
 	if len(args) > 0 {
 		return ErrExtraArgs
 	}
+	if x.ForServices != "" && x.Positional.ID == "" && x.LastChangeType == "" {
+		return x.waitForServices(x.ForServices)
+	}
 	id, err := x.GetChangeID()
 	if err != nil {
 		if err == noChangeFoundOK {
+			if x.ForServices != "" {
+				return x.waitForServices(x.ForServices)
+			}
 			return nil
 		}
 		return err
@@ -55,7 +83,55 @@ func (x *cmdWatch) Execute(args []string) error {
 	// without --no-wait), so we fake it here.
 	wmx := &waitMixin{skipAbort: true, waitForTasksInWaitStatus: true}
 	wmx.client = x.client
-	_, err = wmx.wait(id)
+	if _, err := wmx.wait(id); err != nil {
+		return err
+	}
+
+	if x.ForServices != "" {
+		return x.waitForServices(x.ForServices)
+	}
+	return nil
+}
 
-	return err
+// waitForServices waits until all the enabled services of the snap that are
+// expected to keep running are active, as reported by systemd.
+func (x *cmdWatch) waitForServices(snapName string) error {
+	tMax := time.Now().Add(maxServicesWaitTime)
+	for {
+		services, err := x.client.Apps([]string{snapName}, client.AppOptions{Service: true})
+		if err != nil {
+			return err
+		}
+
+		var inactive []string
+		for _, svc := range services {
+			if svc.Enabled && !svc.Active && expectActiveService(svc) {
+				inactive = append(inactive, svc.Snap+"."+svc.Name)
+			}
+		}
+		if len(inactive) == 0 {
+			return nil
+		}
+		if time.Now().After(tMax) {
+			return fmt.Errorf(i18n.G("timeout waiting for services to become active: %s"), strings.Join(inactive, ", "))
+		}
+		time.Sleep(pollTime)
+	}
+}
+
+// expectActiveService returns whether the enabled service is expected to
+// become active on its own and stay so.
+func expectActiveService(svc *client.AppInfo) bool {
+	switch {
+	case svc.DaemonScope == snap.UserDaemon:
+		// started in the user sessions, not by the system instance
+		return false
+	case svc.Daemon == "oneshot":
+		// only active while running, unless it remains after exit
+		return false
+	case len(svc.Activators) > 0:
+		// only started once a timer elapses or a socket is used
+		return false
+	}
+	return true
 }
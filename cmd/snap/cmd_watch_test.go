@@ -188,3 +188,119 @@ func (s *SnapOpSuite) TestWatchWaitsForWaitTasks(c *C) {
 	c.Check(meter.Notices, testutil.Contains, "INFO: Task set to wait until a manual system restart allows to continue")
 	c.Check(n, Equals, 2)
 }
+
+func (s *SnapSuite) TestWatchForServices(c *C) {
+	defer snap.MockPollTime(time.Millisecond)()
+
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/apps")
+		c.Check(r.URL.Query().Get("names"), Equals, "foo")
+		c.Check(r.URL.Query().Get("select"), Equals, "service")
+		switch n {
+		case 1:
+			fmt.Fprintln(w, `{"type": "sync", "result": [
+				{"snap": "foo", "name": "svc1", "daemon": "simple", "enabled": true, "active": true},
+				{"snap": "foo", "name": "svc2", "daemon": "notify", "enabled": true},
+				{"snap": "foo", "name": "svc3", "daemon": "simple"}
+			]}`)
+		case 2:
+			fmt.Fprintln(w, `{"type": "sync", "result": [
+				{"snap": "foo", "name": "svc1", "daemon": "simple", "enabled": true, "active": true},
+				{"snap": "foo", "name": "svc2", "daemon": "notify", "enabled": true, "active": true},
+				{"snap": "foo", "name": "svc3", "daemon": "simple"}
+			]}`)
+		default:
+			c.Errorf("expected 2 queries, currently on %d", n)
+		}
+	})
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"watch", "--for-services", "foo"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, HasLen, 0)
+	c.Check(n, Equals, 2)
+	c.Check(s.Stdout(), Equals, "")
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestWatchChangeForServices(c *C) {
+	defer snap.MockPollTime(time.Millisecond)()
+
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		switch n {
+		case 1:
+			c.Check(r.URL.Path, Equals, "/v2/changes/two")
+			fmt.Fprintln(w, `{"type": "sync", "result": {"id": "two", "ready": true, "status": "Done"}}`)
+		case 2:
+			c.Check(r.URL.Path, Equals, "/v2/apps")
+			fmt.Fprintln(w, `{"type": "sync", "result": [
+				{"snap": "foo", "name": "svc1", "daemon": "simple", "enabled": true, "active": true}
+			]}`)
+		default:
+			c.Errorf("expected 2 queries, currently on %d", n)
+		}
+	})
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"watch", "--for-services=foo", "two"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, HasLen, 0)
+	c.Check(n, Equals, 2)
+}
+
+func (s *SnapSuite) TestWatchForServicesTimeout(c *C) {
+	defer snap.MockPollTime(time.Millisecond)()
+	defer snap.MockMaxServicesWaitTime(0)()
+
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, Equals, "/v2/apps")
+		fmt.Fprintln(w, `{"type": "sync", "result": [
+			{"snap": "foo", "name": "svc1", "daemon": "simple", "enabled": true},
+			{"snap": "foo", "name": "svc2", "daemon": "notify", "enabled": true}
+		]}`)
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"watch", "--for-services", "foo"})
+	c.Assert(err, ErrorMatches, `timeout waiting for services to become active: foo.svc1, foo.svc2`)
+}
+
+func (s *SnapSuite) testWatchForServicesNotWaitingFor(c *C, svcJSON string) {
+	defer snap.MockPollTime(time.Millisecond)()
+	defer snap.MockMaxServicesWaitTime(0)()
+
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		c.Check(r.URL.Path, Equals, "/v2/apps")
+		fmt.Fprintf(w, `{"type": "sync", "result": [
+			{"snap": "foo", "name": "svc1", "daemon": "simple", "enabled": true, "active": true},
+			%s
+		]}`, svcJSON)
+	})
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"watch", "--for-services", "foo"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, HasLen, 0)
+	c.Check(n, Equals, 1)
+}
+
+func (s *SnapSuite) TestWatchForServicesFinishedOneshot(c *C) {
+	s.testWatchForServicesNotWaitingFor(c, `{"snap": "foo", "name": "svc2", "daemon": "oneshot", "enabled": true}`)
+}
+
+func (s *SnapSuite) TestWatchForServicesTimerActivated(c *C) {
+	s.testWatchForServicesNotWaitingFor(c, `{"snap": "foo", "name": "svc2", "daemon": "simple", "enabled": true,
+		"activators": [{"Name": "svc2", "Type": "timer", "Active": true, "Enabled": true}]}`)
+}
+
+func (s *SnapSuite) TestWatchForServicesSocketActivated(c *C) {
+	s.testWatchForServicesNotWaitingFor(c, `{"snap": "foo", "name": "svc2", "daemon": "simple", "enabled": true,
+		"activators": [{"Name": "sock", "Type": "socket", "Active": true, "Enabled": true}]}`)
+}
+
+func (s *SnapSuite) TestWatchForServicesUserDaemon(c *C) {
+	s.testWatchForServicesNotWaitingFor(c, `{"snap": "foo", "name": "svc2", "daemon": "simple", "daemon-scope": "user", "enabled": true}`)
+}
@@ -213,6 +213,12 @@ If you understand and want to proceed repeat the command including --classic.
 	case client.ErrorKindSnapNoUpdateAvailable:
 		isError = false
 		msg = i18n.G("snap %q has no updates available")
+		if values, ok := err.Value.(map[string]interface{}); ok && values["revision"] != nil && snapName != "" {
+			// held at its revision by enforced validation sets
+			usesSnapName = false
+			// TRANSLATORS: the first %s is an error message, the second one a snap name
+			msg = fmt.Sprintf(i18n.G("%s (see 'snap validate --list-constraints %s')"), err.Message, snapName)
+		}
 	case client.ErrorKindSnapNotInstalled:
 		isError = true
 		// if the snap isn't installed, then remove can ignore this error
@@ -242,6 +248,10 @@ If you understand and want to proceed repeat the command including --classic.
 				}
 			}
 		}
+	case client.ErrorKindPrerequisitesConfirmationRequired:
+		usesSnapName = false
+		// TRANSLATORS: the %s is the error message from the daemon
+		msg = fmt.Sprintf(i18n.G("%s, use --accept-prerequisites to install them"), err.Message)
 	case client.ErrorKindInsufficientDiskSpace:
 		// this error carries multiple snap names
 		usesSnapName = false
@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/jessevdk/go-flags"
+	"golang.org/x/sys/unix"
 
 	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/cmd/snaplock/runinhibit"
@@ -35,6 +36,7 @@ import (
 	"github.com/snapcore/snapd/sandbox/selinux"
 	"github.com/snapcore/snapd/seed/seedwriter"
 	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snapdtool"
 	"github.com/snapcore/snapd/store"
 	"github.com/snapcore/snapd/store/tooling"
 	"github.com/snapcore/snapd/testutil"
@@ -93,7 +95,8 @@ var (
 
 	SortTimingsTasks = sortTimingsTasks
 
-	PrintInstallHint = printInstallHint
+	PrintInstallHint    = printInstallHint
+	BundlePrerequisites = bundlePrerequisites
 
 	IsStopping = isStopping
 
@@ -149,6 +152,7 @@ var (
 	MaybePrintPath              = (*infoWriter).maybePrintPath
 	MaybePrintSum               = (*infoWriter).maybePrintSum
 	MaybePrintCohortKey         = (*infoWriter).maybePrintCohortKey
+	MaybePrintTmpUsage          = (*infoWriter).maybePrintTmpUsage
 	MaybePrintHealth            = (*infoWriter).maybePrintHealth
 	MaybePrintRefreshInfo       = (*infoWriter).maybePrintRefreshInfo
 	WaitWhileInhibited          = waitWhileInhibited
@@ -164,6 +168,14 @@ func MockPollTime(d time.Duration) (restore func()) {
 	}
 }
 
+func MockMaxServicesWaitTime(d time.Duration) (restore func()) {
+	d0 := maxServicesWaitTime
+	maxServicesWaitTime = d
+	return func() {
+		maxServicesWaitTime = d0
+	}
+}
+
 func MockMaxGoneTime(d time.Duration) (restore func()) {
 	d0 := maxGoneTime
 	maxGoneTime = d
@@ -180,6 +192,26 @@ func MockSyscallExec(f func(string, []string, []string) error) (restore func())
 	}
 }
 
+func MockSnapdtoolReexecStatus(f func() (*snapdtool.ReexecInfo, error)) (restore func()) {
+	old := snapdtoolReexecStatus
+	snapdtoolReexecStatus = f
+	return func() {
+		snapdtoolReexecStatus = old
+	}
+}
+
+func MockCgroupPidsOfSnap(f func(snapInstanceName string) (map[string][]int, error)) (restore func()) {
+	return testutil.Mock(&cgroupPidsOfSnap, f)
+}
+
+func MockSchedSetaffinity(f func(pid int, set *unix.CPUSet) error) (restore func()) {
+	return testutil.Mock(&schedSetaffinity, f)
+}
+
+func MockSchedSetAttr(f func(pid int, attr *unix.SchedAttr, flags uint) error) (restore func()) {
+	return testutil.Mock(&schedSetAttr, f)
+}
+
 func MockUserCurrent(f func() (*user.User, error)) (restore func()) {
 	userCurrentOrig := userCurrent
 	userCurrent = f
@@ -405,6 +437,14 @@ func MockDownloadDirect(f func(snapName string, revision snap.Revision, dlOpts t
 	}
 }
 
+func MockDownloadBundle(f func(snapName string, dlOpts tooling.DownloadSnapOptions, bundlePath string) error) (restore func()) {
+	old := downloadBundle
+	downloadBundle = f
+	return func() {
+		downloadBundle = old
+	}
+}
+
 func MockSnapdAPIInterval(t time.Duration) (restore func()) {
 	old := snapdAPIInterval
 	snapdAPIInterval = t
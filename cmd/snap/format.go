@@ -0,0 +1,63 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"encoding/json"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/snapcore/snapd/i18n"
+)
+
+// formatMixin provides the --format option to commands which can print
+// their output in a machine-readable format, for use by tools that would
+// otherwise need to parse the columns meant for humans.
+type formatMixin struct {
+	Format string `long:"format" default:"text" choice:"text" choice:"json" choice:"yaml"`
+}
+
+var formatDescs = mixinDescs{
+	// TRANSLATORS: This should not start with a lowercase letter.
+	"format": i18n.G("Use the given output format"),
+}
+
+// structured returns whether a machine-readable output format was requested.
+func (fm formatMixin) structured() bool {
+	return fm.Format == "json" || fm.Format == "yaml"
+}
+
+// printStructured prints v in the requested machine-readable format.
+func (fm formatMixin) printStructured(v interface{}) error {
+	switch fm.Format {
+	case "json":
+		enc := json.NewEncoder(Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case "yaml":
+		out, err := yaml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = Stdout.Write(out)
+		return err
+	}
+	return nil
+}
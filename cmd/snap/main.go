@@ -28,6 +28,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 	"unicode"
 	"unicode/utf8"
 
@@ -382,6 +383,7 @@ func mkClient() *client.Client {
 	// Set client user-agent when talking to the snapd daemon to the
 	// same value as when talking to the store.
 	cfg.UserAgent = snapdenv.UserAgent()
+	cfg.ConflictRetryTimeout = conflictRetryTimeout()
 
 	cli := client.New(cfg)
 	goos := runtime.GOOS
@@ -400,6 +402,22 @@ This command has been left available for documentation purposes only.
 	return cli
 }
 
+// conflictRetryTimeout returns for how long operations conflicting with a
+// change in progress are retried, as set with SNAP_CONFLICT_RETRY_TIMEOUT
+// (e.g. "5m"). Conflicts are not retried by default.
+func conflictRetryTimeout() time.Duration {
+	value := os.Getenv("SNAP_CONFLICT_RETRY_TIMEOUT")
+	if value == "" {
+		return 0
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		fmt.Fprintf(Stderr, i18n.G("WARNING: ignoring invalid SNAP_CONFLICT_RETRY_TIMEOUT value %q\n"), value)
+		return 0
+	}
+	return timeout
+}
+
 func init() {
 	err := logger.SimpleSetup(nil)
 	if err != nil {
@@ -464,6 +464,74 @@ func (s *SnapSuite) TestSetsUserAgent(c *C) {
 	c.Assert(testServerHit, Equals, true)
 }
 
+const conflictErrorJSON = `{"type": "error", "status-code": 409, "result": {
+	"message": "snap \"foo\" has \"install\" change in progress",
+	"kind": "snap-change-conflict",
+	"value": {"snap-name": "foo", "change-kind": "install", "retry-after": 1}
+}}`
+
+func (s *SnapSuite) TestConflictRetryTimeoutFromEnv(c *C) {
+	os.Setenv("SNAP_CONFLICT_RETRY_TIMEOUT", "1m")
+	defer os.Unsetenv("SNAP_CONFLICT_RETRY_TIMEOUT")
+
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		c.Check(r.Method, Equals, "POST")
+		c.Check(r.URL.Path, Equals, "/v2/snaps/foo")
+		c.Check(DecodedRequestBody(c, r), DeepEquals, map[string]interface{}{
+			"action": "remove",
+		})
+		switch n {
+		case 1:
+			w.WriteHeader(409)
+			fmt.Fprintln(w, conflictErrorJSON)
+		case 2:
+			w.WriteHeader(202)
+			fmt.Fprintln(w, `{"type": "async", "status-code": 202, "change": "42"}`)
+		default:
+			c.Errorf("expected 2 queries, currently on %d", n)
+		}
+	})
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"remove", "--no-wait", "foo"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, HasLen, 0)
+	c.Check(n, Equals, 2)
+	c.Check(s.Stdout(), Equals, "42\n")
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestConflictNotRetriedByDefault(c *C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		w.WriteHeader(409)
+		fmt.Fprintln(w, conflictErrorJSON)
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"remove", "--no-wait", "foo"})
+	c.Assert(err, ErrorMatches, `snap "foo" has "install" change in progress`)
+	c.Check(n, Equals, 1)
+}
+
+func (s *SnapSuite) TestConflictRetryTimeoutInvalid(c *C) {
+	os.Setenv("SNAP_CONFLICT_RETRY_TIMEOUT", "forever")
+	defer os.Unsetenv("SNAP_CONFLICT_RETRY_TIMEOUT")
+
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		w.WriteHeader(409)
+		fmt.Fprintln(w, conflictErrorJSON)
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"remove", "--no-wait", "foo"})
+	c.Assert(err, ErrorMatches, `snap "foo" has "install" change in progress`)
+	c.Check(n, Equals, 1)
+	c.Check(s.Stderr(), Equals, "WARNING: ignoring invalid SNAP_CONFLICT_RETRY_TIMEOUT value \"forever\"\n")
+}
+
 func (s *SnapSuite) TestCompletionHandlerSkipsHidden(c *C) {
 	snap.MarkForNoCompletion(snap.HiddenCmd("bar yadda yack", false))
 	snap.MarkForNoCompletion(snap.HiddenCmd("bar yack yack yack", true))
@@ -0,0 +1,148 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/osutil/sys"
+	"github.com/snapcore/snapd/sandbox/cgroup"
+	"github.com/snapcore/snapd/snap"
+)
+
+// singleInstanceSocketEnv tells the running instance of a single-instance app
+// where to listen for the arguments of further launches.
+const singleInstanceSocketEnv = "SNAP_SINGLE_INSTANCE_SOCKET"
+
+var (
+	cgroupPidsOfSnap = cgroup.PidsOfSnap
+
+	singleInstanceForwardTimeout = 5 * time.Second
+)
+
+// singleInstanceRequest is sent to the running instance of a single-instance
+// app in place of starting a new one.
+type singleInstanceRequest struct {
+	Args []string `json:"args"`
+	Cwd  string   `json:"cwd,omitempty"`
+}
+
+// errForwardedToRunningInstance is returned once the arguments were passed on
+// to the running instance of a single-instance app.
+var errForwardedToRunningInstance = errors.New("arguments forwarded to the running instance")
+
+// appIsRunning returns whether a process of the given app is tracked in its
+// cgroup.
+func appIsRunning(app *snap.AppInfo) (bool, error) {
+	pids, err := cgroupPidsOfSnap(app.Snap.InstanceName())
+	if err != nil {
+		return false, err
+	}
+	return len(pids[app.SecurityTag()]) > 0, nil
+}
+
+// forwardToRunningInstance passes the arguments of the launch to the running
+// instance of the app through its socket.
+func forwardToRunningInstance(app *snap.AppInfo, euid sys.UserID, args []string) error {
+	conn, err := net.DialTimeout("unix", app.SingleInstanceSocket(euid), singleInstanceForwardTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(singleInstanceForwardTimeout))
+
+	req := singleInstanceRequest{Args: args}
+	if cwd, err := os.Getwd(); err == nil {
+		req.Cwd = cwd
+	}
+	return json.NewEncoder(conn).Encode(&req)
+}
+
+// maybeLockSingleInstance ensures that only one instance of a single-instance
+// app runs for the current user. The first instance takes a per-user lock
+// that is inherited by the app, so that it is held for as long as the app
+// runs, and is told where to listen for further launches. If the app is
+// already running, the arguments are forwarded to it instead and
+// errForwardedToRunningInstance is returned.
+//
+// The returned lock, if any, must be closed only if the app is not executed.
+func maybeLockSingleInstance(app *snap.AppInfo, args []string) (*osutil.FileLock, error) {
+	if !app.SingleInstance {
+		return nil, nil
+	}
+
+	euid := sys.UserID(os.Geteuid())
+	lockPath := app.SingleInstanceLockFile(euid)
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0700); err != nil {
+		return nil, err
+	}
+	flock, err := osutil.NewFileLockWithMode(lockPath, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	err = flock.TryLock()
+	if err == nil {
+		// keep the lock held by the app across exec
+		if _, _, errno := syscall.Syscall(syscall.SYS_FCNTL, flock.File().Fd(), syscall.F_SETFD, 0); errno != 0 {
+			flock.Close()
+			return nil, fmt.Errorf("cannot pass single instance lock to %q: %v", app.Name, errno)
+		}
+		// a leftover socket of a previous instance cannot be used
+		if err := os.Remove(app.SingleInstanceSocket(euid)); err != nil && !os.IsNotExist(err) {
+			flock.Close()
+			return nil, err
+		}
+		os.Setenv(singleInstanceSocketEnv, app.SingleInstanceSocket(euid))
+		return flock, nil
+	}
+	flock.Close()
+	if err != osutil.ErrAlreadyLocked {
+		return nil, err
+	}
+
+	running, err := appIsRunning(app)
+	if err != nil {
+		// cgroup tracking may not be available, trust the lock
+		logger.Debugf("cannot check whether %q is running: %v", app.Name, err)
+		running = true
+	}
+	if !running {
+		// the lock is held by a process that left the tracking cgroup of
+		// the app, do not prevent running the app
+		logger.Noticef("WARNING: %q is not running but its single instance lock is held, starting a new instance", app.Name)
+		return nil, nil
+	}
+
+	if err := forwardToRunningInstance(app, euid, args); err != nil {
+		return nil, fmt.Errorf(i18n.G("cannot pass arguments to the running instance of %q: %v"), snap.JoinSnapApp(app.Snap.InstanceName(), app.Name), err)
+	}
+	return nil, errForwardedToRunningInstance
+}
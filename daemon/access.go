@@ -20,7 +20,11 @@
 package daemon
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -131,6 +135,87 @@ func (ac authenticatedAccess) CheckAccess(d *Daemon, r *http.Request, ucred *ucr
 	return Unauthorized("access denied")
 }
 
+// snapOpAccess allows requests from authenticated users to the snap
+// operation endpoints, in the same way as authenticatedAccess. The Polkit
+// action is chosen according to the requested operation so that users can
+// be granted the ability to install, remove or refresh snaps without being
+// granted full management of snaps.
+type snapOpAccess struct{}
+
+func (ac snapOpAccess) CheckAccess(d *Daemon, r *http.Request, ucred *ucrednet, user *auth.UserState) *apiError {
+	if rspe := requireSnapdSocket(ucred); rspe != nil {
+		return rspe
+	}
+
+	if user != nil {
+		return nil
+	}
+
+	if ucred.Uid == 0 {
+		return nil
+	}
+
+	action := polkitActionForSnapOp(r)
+	if action != polkitActionManage {
+		// polkit rules granting the management of snaps keep granting
+		// all the operations, check them first without prompting so
+		// that the user is not asked for the more specific action
+		noInteraction := r.Clone(r.Context())
+		noInteraction.Header.Del(client.AllowInteractionHeader)
+		if checkPolkitAction(noInteraction, ucred, polkitActionManage) == nil {
+			return nil
+		}
+	}
+	return checkPolkitAction(r, ucred, action)
+}
+
+// maxSnapOpPeekSize is the size of the request body read to find the
+// requested operation before the request is authorized.
+const maxSnapOpPeekSize = 1024 * 1024
+
+// polkitActionForSnapOp returns the Polkit action for the operation
+// requested on the snap operation endpoints. Multipart requests are
+// sideloads and thus installs, while for JSON requests the action is peeked
+// from the body, which is restored for the handler to consume. Operations
+// without a dedicated action, or whose body is too large to be peeked, fall
+// back to polkitActionManage.
+func polkitActionForSnapOp(r *http.Request) string {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err == nil && mediaType == "multipart/form-data" {
+		return polkitActionInstall
+	}
+
+	if r.Body == nil {
+		return polkitActionManage
+	}
+	// the request is not authorized yet, do not read more than needed
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxSnapOpPeekSize+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+	if err != nil || len(body) > maxSnapOpPeekSize {
+		return polkitActionManage
+	}
+
+	var inst struct {
+		Action string `json:"action"`
+	}
+	if err := json.Unmarshal(body, &inst); err != nil {
+		// let the handler report the malformed request
+		return polkitActionManage
+	}
+	switch inst.Action {
+	case "install":
+		return polkitActionInstall
+	case "remove":
+		return polkitActionRemove
+	case "refresh":
+		return polkitActionRefresh
+	}
+	return polkitActionManage
+}
+
 // rootAccess allows requests from the root uid, provided they
 // were not received on snapd-snap.socket
 type rootAccess struct{}
@@ -218,6 +303,35 @@ func requireInterfaceApiAccessImpl(d *Daemon, r *http.Request, ucred *ucrednet,
 	return Forbidden("access denied")
 }
 
+// snapSelfAccess behaves like openAccess, but also allows requests from
+// snapd-snap.socket made by the processes of the snap named by the "snap"
+// query parameter, so that snaps can introspect their own sandbox.
+type snapSelfAccess struct{}
+
+func (ac snapSelfAccess) CheckAccess(d *Daemon, r *http.Request, ucred *ucrednet, user *auth.UserState) *apiError {
+	if ucred == nil {
+		return Forbidden("access denied")
+	}
+
+	switch ucred.Socket {
+	case dirs.SnapdSocket:
+		return nil
+	case dirs.SnapSocket:
+		// Handled below
+	default:
+		return Forbidden("access denied")
+	}
+
+	snapName, err := cgroupSnapNameFromPid(int(ucred.Pid))
+	if err != nil {
+		return Forbidden("could not determine snap name for pid: %s", err)
+	}
+	if r.URL.Query().Get("snap") != snapName {
+		return Forbidden("access denied")
+	}
+	return nil
+}
+
 // interfaceOpenAccess behaves like openAccess, but allows requests from
 // snapd-snap.socket for snaps that plug one of the provided interfaces.
 type interfaceOpenAccess struct {
@@ -21,8 +21,10 @@ package daemon_test
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 
 	. "gopkg.in/check.v1"
 
@@ -131,6 +133,132 @@ func (s *accessSuite) TestAuthenticatedAccessPolkit(c *C) {
 	c.Check(ac.CheckAccess(nil, req, ucred, nil), IsNil)
 }
 
+func (s *accessSuite) TestSnapOpAccess(c *C) {
+	var ac daemon.AccessChecker = daemon.SnapOpAccess{}
+
+	user := &auth.UserState{}
+	ucred := &daemon.Ucrednet{Uid: 0, Pid: 100, Socket: dirs.SnapdSocket}
+
+	// polkit is not checked if any of:
+	//   * ucred is missing
+	//   * the request comes from snapd-snap.socket
+	//   * macaroon auth is provided
+	//   * user is root
+	restore := daemon.MockCheckPolkitAction(func(r *http.Request, ucred *daemon.Ucrednet, action string) *daemon.APIError {
+		c.Fail()
+		return daemon.Forbidden("access denied")
+	})
+	defer restore()
+	req := httptest.NewRequest("POST", "/", nil)
+	c.Check(ac.CheckAccess(nil, req, nil, nil), DeepEquals, errForbidden)
+	c.Check(ac.CheckAccess(nil, req, &daemon.Ucrednet{Uid: 0, Pid: 100, Socket: dirs.SnapSocket}, nil), DeepEquals, errForbidden)
+	c.Check(ac.CheckAccess(nil, req, ucred, nil), IsNil)
+	ucred = &daemon.Ucrednet{Uid: 42, Pid: 100, Socket: dirs.SnapdSocket}
+	c.Check(ac.CheckAccess(nil, req, ucred, user), IsNil)
+
+	// for regular users without macaroon auth polkit is checked with
+	// an action matching the requested operation
+	for _, tc := range []struct {
+		contentType string
+		body        string
+		action      string
+	}{
+		{"application/json", `{"action": "install"}`, "io.snapcraft.snapd.install"},
+		{"application/json", `{"action": "remove", "purge": true}`, "io.snapcraft.snapd.remove"},
+		{"application/json", `{"action": "refresh"}`, "io.snapcraft.snapd.refresh"},
+		{"application/json", `{"action": "revert"}`, "io.snapcraft.snapd.manage"},
+		{"application/json", `{"action": "install"`, "io.snapcraft.snapd.manage"},
+		{"application/json", ``, "io.snapcraft.snapd.manage"},
+		{"multipart/form-data; boundary=foo", `--foo`, "io.snapcraft.snapd.install"},
+	} {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", tc.contentType)
+
+		var checkedActions []string
+		restore := daemon.MockCheckPolkitAction(func(r *http.Request, u *daemon.Ucrednet, action string) *daemon.APIError {
+			c.Check(u, Equals, ucred)
+			checkedActions = append(checkedActions, action)
+			if action == "io.snapcraft.snapd.manage" && action != tc.action {
+				return daemon.Unauthorized("access denied")
+			}
+			return nil
+		})
+		c.Check(ac.CheckAccess(nil, req, ucred, nil), IsNil)
+		restore()
+		if tc.action == "io.snapcraft.snapd.manage" {
+			c.Check(checkedActions, DeepEquals, []string{tc.action}, Commentf("%s", tc.body))
+		} else {
+			// the management of snaps is checked first
+			c.Check(checkedActions, DeepEquals, []string{"io.snapcraft.snapd.manage", tc.action}, Commentf("%s", tc.body))
+		}
+
+		// the body is still available to the handler
+		body, err := io.ReadAll(req.Body)
+		c.Assert(err, IsNil)
+		c.Check(string(body), Equals, tc.body)
+	}
+}
+
+func (s *accessSuite) TestSnapOpAccessManageFallback(c *C) {
+	var ac daemon.AccessChecker = daemon.SnapOpAccess{}
+	ucred := &daemon.Ucrednet{Uid: 42, Pid: 100, Socket: dirs.SnapdSocket}
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"action": "install"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(client.AllowInteractionHeader, "true")
+
+	// users granted the management of snaps can still install them,
+	// without being prompted
+	var checked []string
+	restore := daemon.MockCheckPolkitAction(func(r *http.Request, u *daemon.Ucrednet, action string) *daemon.APIError {
+		checked = append(checked, action)
+		c.Check(r.Header.Get(client.AllowInteractionHeader), Equals, "")
+		return nil
+	})
+	defer restore()
+	c.Check(ac.CheckAccess(nil, req, ucred, nil), IsNil)
+	c.Check(checked, DeepEquals, []string{"io.snapcraft.snapd.manage"})
+
+	// otherwise the specific action is checked, with interaction
+	checked = nil
+	restore = daemon.MockCheckPolkitAction(func(r *http.Request, u *daemon.Ucrednet, action string) *daemon.APIError {
+		checked = append(checked, action)
+		if action == "io.snapcraft.snapd.manage" {
+			c.Check(r.Header.Get(client.AllowInteractionHeader), Equals, "")
+			return daemon.Unauthorized("access denied")
+		}
+		c.Check(r.Header.Get(client.AllowInteractionHeader), Equals, "true")
+		return daemon.Unauthorized("access denied")
+	})
+	defer restore()
+	c.Check(ac.CheckAccess(nil, req, ucred, nil), DeepEquals, daemon.Unauthorized("access denied"))
+	c.Check(checked, DeepEquals, []string{"io.snapcraft.snapd.manage", "io.snapcraft.snapd.install"})
+}
+
+func (s *accessSuite) TestSnapOpAccessLargeBody(c *C) {
+	var ac daemon.AccessChecker = daemon.SnapOpAccess{}
+	ucred := &daemon.Ucrednet{Uid: 42, Pid: 100, Socket: dirs.SnapdSocket}
+
+	largeBody := `{"action": "install", "snaps": ["` + strings.Repeat("a", daemon.MaxSnapOpPeekSize) + `"]}`
+	req := httptest.NewRequest("POST", "/", strings.NewReader(largeBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	var checked []string
+	restore := daemon.MockCheckPolkitAction(func(r *http.Request, u *daemon.Ucrednet, action string) *daemon.APIError {
+		checked = append(checked, action)
+		return nil
+	})
+	defer restore()
+	c.Check(ac.CheckAccess(nil, req, ucred, nil), IsNil)
+	// the body is not fully read before authorization
+	c.Check(checked, DeepEquals, []string{"io.snapcraft.snapd.manage"})
+
+	// but is still available in full to the handler
+	body, err := io.ReadAll(req.Body)
+	c.Assert(err, IsNil)
+	c.Check(string(body), Equals, largeBody)
+}
+
 func (s *accessSuite) TestCheckPolkitActionImpl(c *C) {
 	logbuf, restore := logger.MockLogger()
 	defer restore()
@@ -227,6 +355,48 @@ func (s *accessSuite) TestSnapAccess(c *C) {
 	c.Check(ac.CheckAccess(nil, nil, nil, nil), DeepEquals, errForbidden)
 }
 
+func (s *accessSuite) TestSnapSelfAccess(c *C) {
+	restore := daemon.MockCgroupSnapNameFromPid(func(pid int) (string, error) {
+		if pid == 42 {
+			return "some-snap", nil
+		}
+		return "", fmt.Errorf("not a snap")
+	})
+	defer restore()
+
+	var ac daemon.AccessChecker = daemon.SnapSelfAccess{}
+	req, err := http.NewRequest("GET", "/v2/sandbox-info?snap=some-snap", nil)
+	c.Assert(err, IsNil)
+
+	// Access with no ucred data is forbidden
+	c.Check(ac.CheckAccess(nil, req, nil, nil), DeepEquals, errForbidden)
+
+	// Access from snapd.socket is allowed
+	ucred := &daemon.Ucrednet{Uid: 1000, Pid: 1001, Socket: dirs.SnapdSocket}
+	c.Check(ac.CheckAccess(nil, req, ucred, nil), IsNil)
+
+	// Access from unknown sockets is forbidden
+	ucred = &daemon.Ucrednet{Uid: 1000, Pid: 42, Socket: "unknown.socket"}
+	c.Check(ac.CheckAccess(nil, req, ucred, nil), DeepEquals, errForbidden)
+
+	// Access from pids that cannot be mapped to a snap on
+	// snapd-snap.socket is rejected
+	ucred = &daemon.Ucrednet{Uid: 1000, Pid: 1001, Socket: dirs.SnapSocket}
+	c.Check(ac.CheckAccess(nil, req, ucred, nil), DeepEquals, daemon.Forbidden("could not determine snap name for pid: not a snap"))
+
+	// Snaps can query information about themselves
+	ucred = &daemon.Ucrednet{Uid: 1000, Pid: 42, Socket: dirs.SnapSocket}
+	c.Check(ac.CheckAccess(nil, req, ucred, nil), IsNil)
+
+	// But not about other snaps
+	req, err = http.NewRequest("GET", "/v2/sandbox-info?snap=other-snap", nil)
+	c.Assert(err, IsNil)
+	c.Check(ac.CheckAccess(nil, req, ucred, nil), DeepEquals, errForbidden)
+	req, err = http.NewRequest("GET", "/v2/sandbox-info", nil)
+	c.Assert(err, IsNil)
+	c.Check(ac.CheckAccess(nil, req, ucred, nil), DeepEquals, errForbidden)
+}
+
 func (s *accessSuite) TestRequireInterfaceApiAccessImpl(c *C) {
 	d := s.daemon(c)
 	s.mockSnap(c, `
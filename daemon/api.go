@@ -48,6 +48,7 @@ var api = []*Command{
 	snapFileCmd,
 	snapDownloadCmd,
 	snapConfCmd,
+	snapUserConfCmd,
 	interfacesCmd,
 	assertsCmd,
 	assertsFindManyCmd,
@@ -82,6 +83,8 @@ var api = []*Command{
 	systemRecoveryKeysCmd,
 	quotaGroupsCmd,
 	quotaGroupInfoCmd,
+	sandboxInfoCmd,
+	desktopLaunchCmd,
 	confdbCmd,
 	noticesCmd,
 	noticeCmd,
@@ -89,6 +92,8 @@ var api = []*Command{
 	requestsPromptCmd,
 	requestsRulesCmd,
 	requestsRuleCmd,
+	connectionPromptsCmd,
+	connectionPromptCmd,
 }
 
 const (
@@ -96,6 +101,9 @@ const (
 	polkitActionManage              = "io.snapcraft.snapd.manage"
 	polkitActionManageInterfaces    = "io.snapcraft.snapd.manage-interfaces"
 	polkitActionManageConfiguration = "io.snapcraft.snapd.manage-configuration"
+	polkitActionInstall             = "io.snapcraft.snapd.install"
+	polkitActionRemove              = "io.snapcraft.snapd.remove"
+	polkitActionRefresh             = "io.snapcraft.snapd.refresh"
 )
 
 // userFromRequest extracts user information from request and return the respective user in state, if valid
@@ -194,6 +202,17 @@ func newChange(st *state.State, kind, summary string, tsets []*state.TaskSet, sn
 	return chg
 }
 
+// setRequesterUID records on the change the uid of the user who requested it
+// through the given remote address, so that prompts resulting from the change
+// are directed at that user.
+func setRequesterUID(chg *state.Change, remoteAddr string) {
+	ucred, err := ucrednetGet(remoteAddr)
+	if err != nil {
+		return
+	}
+	chg.Set("requester-uid", ucred.Uid)
+}
+
 func isTrue(form *Form, key string) bool {
 	values := form.Values[key]
 	if len(values) == 0 {
@@ -146,6 +146,8 @@ func (s *aliasesSuite) TestAliasChangeConflict(c *check.C) {
 			"kind":    "snap-change-conflict",
 			"value": map[string]interface{}{
 				"change-kind": "manip",
+				"change-id":   "1",
+				"retry-after": 5.,
 				"snap-name":   "alias-snap",
 			},
 		},
@@ -0,0 +1,137 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/overlord/ifacestate"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+var (
+	connectionPromptsCmd = &Command{
+		Path:       "/v2/prompts",
+		GET:        getConnectionPrompts,
+		ReadAccess: interfaceOpenAccess{Interfaces: []string{"snap-interfaces-requests-control"}},
+	}
+
+	connectionPromptCmd = &Command{
+		Path:        "/v2/prompts/{id}",
+		GET:         getConnectionPrompt,
+		POST:        postConnectionPrompt,
+		ReadAccess:  interfaceOpenAccess{Interfaces: []string{"snap-interfaces-requests-control"}},
+		WriteAccess: interfaceAuthenticatedAccess{Interfaces: []string{"snap-interfaces-requests-control"}, Polkit: polkitActionManageInterfaces},
+	}
+)
+
+var ifacestateReplyToConnectionPrompt = ifacestate.ReplyToConnectionPrompt
+
+// connectionPromptsUserID returns the uid of the user making the request,
+// whose connection prompts are the only ones the request can access.
+func connectionPromptsUserID(r *http.Request) (uint32, Response) {
+	ucred, err := ucrednetGet(r.RemoteAddr)
+	if err != nil {
+		return 0, Forbidden("cannot get remote user: %v", err)
+	}
+	return ucred.Uid, nil
+}
+
+func getConnectionPrompts(c *Command, r *http.Request, user *auth.UserState) Response {
+	userID, rsp := connectionPromptsUserID(r)
+	if rsp != nil {
+		return rsp
+	}
+
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	prompts, err := ifacestate.ConnectionPrompts(st, userID)
+	if err != nil {
+		return InternalError("%v", err)
+	}
+	return SyncResponse(prompts)
+}
+
+func getConnectionPrompt(c *Command, r *http.Request, user *auth.UserState) Response {
+	id := muxVars(r)["id"]
+	userID, rsp := connectionPromptsUserID(r)
+	if rsp != nil {
+		return rsp
+	}
+
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	prompt, err := ifacestate.ConnectionPromptByID(st, userID, id)
+	if errors.Is(err, ifacestate.ErrConnectionPromptNotFound) {
+		return NotFound("cannot find connection prompt %q", id)
+	}
+	if err != nil {
+		return InternalError("%v", err)
+	}
+	return SyncResponse(prompt)
+}
+
+func postConnectionPrompt(c *Command, r *http.Request, user *auth.UserState) Response {
+	id := muxVars(r)["id"]
+	userID, rsp := connectionPromptsUserID(r)
+	if rsp != nil {
+		return rsp
+	}
+
+	var reply ifacestate.ConnectionPromptReply
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&reply); err != nil {
+		return BadRequest("cannot decode request body into connection prompt reply: %v", err)
+	}
+
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	prompt, err := ifacestate.ConnectionPromptByID(st, userID, id)
+	if errors.Is(err, ifacestate.ErrConnectionPromptNotFound) {
+		return NotFound("cannot find connection prompt %q", id)
+	}
+	if err != nil {
+		return InternalError("%v", err)
+	}
+
+	ts, err := ifacestateReplyToConnectionPrompt(st, userID, id, &reply)
+	if err != nil {
+		return errToResponse(err, []string{prompt.Plug.Snap}, BadRequest, "cannot reply to connection prompt: %v")
+	}
+	if ts == nil {
+		return SyncResponse(nil)
+	}
+
+	summary := fmt.Sprintf("Connect %s:%s to %s:%s", prompt.Plug.Snap, prompt.Plug.Name, prompt.Slot.Snap, prompt.Slot.Name)
+	chg := newChange(st, "connect-snap", summary, []*state.TaskSet{ts}, []string{prompt.Plug.Snap, prompt.Slot.Snap})
+	ensureStateSoon(st)
+
+	return AsyncResponse(nil, chg.ID())
+}
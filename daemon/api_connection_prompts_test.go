@@ -0,0 +1,250 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/daemon"
+	"github.com/snapcore/snapd/overlord/ifacestate"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+var _ = Suite(&connectionPromptsSuite{})
+
+type connectionPromptsSuite struct {
+	apiBaseSuite
+}
+
+func (s *connectionPromptsSuite) SetUpTest(c *C) {
+	s.apiBaseSuite.SetUpTest(c)
+
+	s.expectReadAccess(daemon.InterfaceOpenAccess{Interfaces: []string{"snap-interfaces-requests-control"}})
+	s.expectWriteAccess(daemon.InterfaceAuthenticatedAccess{Interfaces: []string{"snap-interfaces-requests-control"}, Polkit: "io.snapcraft.snapd.manage-interfaces"})
+}
+
+func (s *connectionPromptsSuite) mockPrompts(st *state.State) {
+	st.Set("connection-prompts", map[string]interface{}{
+		"1": map[string]interface{}{
+			"id":        "1",
+			"timestamp": "2024-08-01T10:00:00Z",
+			"user-id":   1000,
+			"interface": "camera",
+			"plug":      map[string]interface{}{"snap": "consumer", "plug": "camera"},
+			"slot":      map[string]interface{}{"snap": "core", "slot": "camera"},
+		},
+		"2": map[string]interface{}{
+			"id":        "2",
+			"timestamp": "2024-08-01T10:00:01Z",
+			"user-id":   1000,
+			"interface": "audio-record",
+			"plug":      map[string]interface{}{"snap": "consumer", "plug": "audio-record"},
+			"slot":      map[string]interface{}{"snap": "core", "slot": "audio-record"},
+		},
+		"3": map[string]interface{}{
+			"id":        "3",
+			"timestamp": "2024-08-01T10:00:02Z",
+			"user-id":   1001,
+			"interface": "camera",
+			"plug":      map[string]interface{}{"snap": "other", "plug": "camera"},
+			"slot":      map[string]interface{}{"snap": "core", "slot": "camera"},
+		},
+	})
+}
+
+func newConnectionPromptsRequest(c *C, method, url string, body *bytes.Buffer, uid uint32) *http.Request {
+	var req *http.Request
+	var err error
+	if body != nil {
+		req, err = http.NewRequest(method, url, body)
+	} else {
+		req, err = http.NewRequest(method, url, nil)
+	}
+	c.Assert(err, IsNil)
+	req.RemoteAddr = fmt.Sprintf("pid=100;uid=%d;socket=;", uid)
+	return req
+}
+
+func (s *connectionPromptsSuite) TestGetConnectionPrompts(c *C) {
+	d := s.daemon(c)
+	st := d.Overlord().State()
+	st.Lock()
+	s.mockPrompts(st)
+	st.Unlock()
+
+	req := newConnectionPromptsRequest(c, "GET", "/v2/prompts", nil, 1000)
+	rsp := s.syncReq(c, req, nil)
+	prompts, ok := rsp.Result.([]*ifacestate.ConnectionPrompt)
+	c.Assert(ok, Equals, true)
+	c.Assert(prompts, HasLen, 2)
+	c.Check(prompts[0].ID, Equals, "1")
+	c.Check(prompts[0].Interface, Equals, "camera")
+	c.Check(prompts[1].ID, Equals, "2")
+	c.Check(prompts[1].Plug.Name, Equals, "audio-record")
+
+	// other users only get their own prompts
+	req = newConnectionPromptsRequest(c, "GET", "/v2/prompts", nil, 1001)
+	rsp = s.syncReq(c, req, nil)
+	prompts, ok = rsp.Result.([]*ifacestate.ConnectionPrompt)
+	c.Assert(ok, Equals, true)
+	c.Assert(prompts, HasLen, 1)
+	c.Check(prompts[0].ID, Equals, "3")
+
+	req = newConnectionPromptsRequest(c, "GET", "/v2/prompts", nil, 0)
+	rsp = s.syncReq(c, req, nil)
+	c.Check(rsp.Result, HasLen, 0)
+}
+
+func (s *connectionPromptsSuite) TestConnectionPromptsNoRemoteUser(c *C) {
+	s.daemon(c)
+
+	for _, method := range []string{"GET", "POST"} {
+		req, err := http.NewRequest(method, "/v2/prompts/1", bytes.NewBufferString(`{"action": "allow"}`))
+		c.Assert(err, IsNil)
+		rspe := s.errorReq(c, req, nil)
+		c.Check(rspe.Status, Equals, 403)
+		c.Check(rspe.Message, Matches, "cannot get remote user: .*")
+	}
+}
+
+func (s *connectionPromptsSuite) TestGetConnectionPromptsNone(c *C) {
+	s.daemon(c)
+
+	req := newConnectionPromptsRequest(c, "GET", "/v2/prompts", nil, 1000)
+	rsp := s.syncReq(c, req, nil)
+	c.Check(rsp.Result, HasLen, 0)
+}
+
+func (s *connectionPromptsSuite) TestGetConnectionPrompt(c *C) {
+	d := s.daemon(c)
+	st := d.Overlord().State()
+	st.Lock()
+	s.mockPrompts(st)
+	st.Unlock()
+
+	req := newConnectionPromptsRequest(c, "GET", "/v2/prompts/2", nil, 1000)
+	rsp := s.syncReq(c, req, nil)
+	prompt, ok := rsp.Result.(*ifacestate.ConnectionPrompt)
+	c.Assert(ok, Equals, true)
+	c.Check(prompt.ID, Equals, "2")
+	c.Check(prompt.Slot.Name, Equals, "audio-record")
+
+	// the prompt of another user
+	req = newConnectionPromptsRequest(c, "GET", "/v2/prompts/3", nil, 1000)
+	rspe := s.errorReq(c, req, nil)
+	c.Check(rspe.Status, Equals, 404)
+	c.Check(rspe.Message, Equals, `cannot find connection prompt "3"`)
+
+	req = newConnectionPromptsRequest(c, "GET", "/v2/prompts/4", nil, 1000)
+	rspe = s.errorReq(c, req, nil)
+	c.Check(rspe.Status, Equals, 404)
+	c.Check(rspe.Message, Equals, `cannot find connection prompt "4"`)
+}
+
+func (s *connectionPromptsSuite) TestPostConnectionPromptAllow(c *C) {
+	d := s.daemonWithOverlordMock()
+	st := d.Overlord().State()
+	st.Lock()
+	s.mockPrompts(st)
+	st.Unlock()
+
+	var gotReply *ifacestate.ConnectionPromptReply
+	restore := daemon.MockIfacestateReplyToConnectionPrompt(func(st *state.State, userID uint32, id string, reply *ifacestate.ConnectionPromptReply) (*state.TaskSet, error) {
+		c.Check(userID, Equals, uint32(1000))
+		c.Check(id, Equals, "1")
+		gotReply = reply
+		t := st.NewTask("fake-connect", "...")
+		return state.NewTaskSet(t), nil
+	})
+	defer restore()
+
+	buf := bytes.NewBufferString(`{"action": "allow", "lifespan": "forever"}`)
+	req := newConnectionPromptsRequest(c, "POST", "/v2/prompts/1", buf, 1000)
+	rsp := s.asyncReq(c, req, nil)
+	c.Check(gotReply, DeepEquals, &ifacestate.ConnectionPromptReply{Action: "allow", Lifespan: "forever"})
+
+	st.Lock()
+	defer st.Unlock()
+	chg := st.Change(rsp.Change)
+	c.Assert(chg, NotNil)
+	c.Check(chg.Kind(), Equals, "connect-snap")
+	c.Check(chg.Summary(), Equals, "Connect consumer:camera to core:camera")
+	var snapNames []string
+	c.Assert(chg.Get("snap-names", &snapNames), IsNil)
+	c.Check(snapNames, DeepEquals, []string{"consumer", "core"})
+}
+
+func (s *connectionPromptsSuite) TestPostConnectionPromptDeny(c *C) {
+	d := s.daemon(c)
+	st := d.Overlord().State()
+	st.Lock()
+	s.mockPrompts(st)
+	st.Unlock()
+
+	buf := bytes.NewBufferString(`{"action": "deny"}`)
+	req := newConnectionPromptsRequest(c, "POST", "/v2/prompts/1", buf, 1000)
+	rsp := s.syncReq(c, req, nil)
+	c.Check(rsp.Result, IsNil)
+
+	st.Lock()
+	defer st.Unlock()
+	prompts, err := ifacestate.ConnectionPrompts(st, 1000)
+	c.Assert(err, IsNil)
+	c.Assert(prompts, HasLen, 1)
+	c.Check(prompts[0].ID, Equals, "2")
+}
+
+func (s *connectionPromptsSuite) TestPostConnectionPromptErrors(c *C) {
+	d := s.daemon(c)
+	st := d.Overlord().State()
+	st.Lock()
+	s.mockPrompts(st)
+	st.Unlock()
+
+	for _, tc := range []struct {
+		id, body string
+		status   int
+		message  string
+	}{
+		{"1", `}`, 400, `cannot decode request body into connection prompt reply: .*`},
+		{"3", `{"action": "allow"}`, 404, `cannot find connection prompt "3"`},
+		{"4", `{"action": "allow"}`, 404, `cannot find connection prompt "4"`},
+		{"1", `{"action": "maybe"}`, 400, `cannot reply to connection prompt: invalid connection prompt action "maybe"`},
+	} {
+		req := newConnectionPromptsRequest(c, "POST", "/v2/prompts/"+tc.id, bytes.NewBufferString(tc.body), 1000)
+		rspe := s.errorReq(c, req, nil)
+		c.Check(rspe.Status, Equals, tc.status, Commentf("%s", tc.body))
+		c.Check(rspe.Message, Matches, tc.message)
+	}
+
+	restore := daemon.MockIfacestateReplyToConnectionPrompt(func(st *state.State, userID uint32, id string, reply *ifacestate.ConnectionPromptReply) (*state.TaskSet, error) {
+		return nil, errors.New("boom")
+	})
+	defer restore()
+	req := newConnectionPromptsRequest(c, "POST", "/v2/prompts/1", bytes.NewBufferString(`{"action": "allow"}`), 1000)
+	rspe := s.errorReq(c, req, nil)
+	c.Check(rspe.Status, Equals, 400)
+	c.Check(rspe.Message, Equals, "cannot reply to connection prompt: boom")
+}
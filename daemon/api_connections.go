@@ -38,9 +38,10 @@ var connectionsCmd = &Command{
 }
 
 type collectFilter struct {
-	snapName  string
-	ifaceName string
-	connected bool
+	snapName     string
+	ifaceName    string
+	connected    bool
+	disconnected bool
 }
 
 func (c *collectFilter) plugOrConnectedSlotMatches(plug *interfaces.PlugRef, connectedSlots []interfaces.SlotRef) bool {
@@ -175,6 +176,9 @@ func collectConnections(ifaceMgr *ifacestate.InterfaceManager, filter collectFil
 			plugConns[plugID] = append(plugConns[plugID], slotRef)
 			slotConns[slotID] = append(slotConns[slotID], plugRef)
 
+			if filter.disconnected {
+				continue
+			}
 			connsjson.Established = append(connsjson.Established, cj)
 		}
 	}
@@ -182,7 +186,7 @@ func collectConnections(ifaceMgr *ifacestate.InterfaceManager, filter collectFil
 	for _, plug := range ifaces.Plugs {
 		plugRef := interfaces.PlugRef{Snap: plug.Snap.InstanceName(), Name: plug.Name}
 		connectedSlots, connected := plugConns[plugRef.String()]
-		if !connected && filter.connected {
+		if (!connected && filter.connected) || (connected && filter.disconnected) {
 			continue
 		}
 		if !filter.ifaceMatches(plug.Interface) || !filter.plugOrConnectedSlotMatches(&plugRef, connectedSlots) {
@@ -207,7 +211,7 @@ func collectConnections(ifaceMgr *ifacestate.InterfaceManager, filter collectFil
 	for _, slot := range ifaces.Slots {
 		slotRef := interfaces.SlotRef{Snap: slot.Snap.InstanceName(), Name: slot.Name}
 		connectedPlugs, connected := slotConns[slotRef.String()]
-		if !connected && filter.connected {
+		if (!connected && filter.connected) || (connected && filter.disconnected) {
 			continue
 		}
 		if !filter.ifaceMatches(slot.Interface) || !filter.slotOrConnectedPlugMatches(&slotRef, connectedPlugs) {
@@ -225,6 +229,7 @@ func collectConnections(ifaceMgr *ifacestate.InterfaceManager, filter collectFil
 			Attrs:       slot.Attrs,
 			Apps:        apps,
 			Label:       slot.Label,
+			Hotplug:     slot.HotplugKey != "",
 			Connections: connectedPlugs,
 		}
 		connsjson.Slots = append(connsjson.Slots, sj)
@@ -258,10 +263,11 @@ func getConnections(c *Command, r *http.Request, user *auth.UserState) Response
 	snapName := query.Get("snap")
 	ifaceName := query.Get("interface")
 	qselect := query.Get("select")
-	if qselect != "all" && qselect != "" {
+	if qselect != "all" && qselect != "disconnected" && qselect != "" {
 		return BadRequest("unsupported select qualifier")
 	}
 	onlyConnected := qselect == ""
+	onlyDisconnected := qselect == "disconnected"
 
 	snapName = ifacestate.RemapSnapFromRequest(snapName)
 	if snapName != "" {
@@ -274,9 +280,10 @@ func getConnections(c *Command, r *http.Request, user *auth.UserState) Response
 	}
 
 	connsjson, err := collectConnections(c.d.overlord.InterfaceManager(), collectFilter{
-		snapName:  snapName,
-		ifaceName: ifaceName,
-		connected: onlyConnected,
+		snapName:     snapName,
+		ifaceName:    ifaceName,
+		connected:    onlyConnected,
+		disconnected: onlyDisconnected,
 	})
 	if err != nil {
 		return InternalError("collecting connection information failed: %v", err)
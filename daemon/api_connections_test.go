@@ -863,6 +863,63 @@ func (s *interfacesSuite) TestConnectionsOnlyUndesired(c *check.C) {
 	})
 }
 
+func (s *interfacesSuite) TestConnectionsOnlyDisconnected(c *check.C) {
+	restore := builtin.MockInterface(&ifacetest.TestInterface{InterfaceName: "test"})
+	defer restore()
+
+	d := s.daemon(c)
+
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+	s.mockSnap(c, `
+name: another-producer
+version: 1
+apps:
+ app:
+slots:
+ slot:
+  interface: test
+  key: value
+  label: label
+`)
+
+	s.testConnectionsConnected(c, d, "/v2/connections?select=disconnected", map[string]interface{}{
+		"consumer:plug producer:slot": map[string]interface{}{
+			"interface": "test",
+		},
+		"consumer:plug another-producer:slot": map[string]interface{}{
+			"interface": "test",
+			"undesired": true,
+		},
+	}, nil, map[string]interface{}{
+		"result": map[string]interface{}{
+			"established": []interface{}{},
+			"plugs":       []interface{}{},
+			"slots": []interface{}{
+				map[string]interface{}{
+					"snap":      "another-producer",
+					"slot":      "slot",
+					"interface": "test",
+					"attrs":     map[string]interface{}{"key": "value"},
+					"apps":      []interface{}{"app"},
+					"label":     "label",
+				},
+			},
+			"undesired": []interface{}{
+				map[string]interface{}{
+					"plug":      map[string]interface{}{"snap": "consumer", "plug": "plug"},
+					"slot":      map[string]interface{}{"snap": "another-producer", "slot": "slot"},
+					"manual":    true,
+					"interface": "test",
+				},
+			},
+		},
+		"status":      "OK",
+		"status-code": 200.0,
+		"type":        "sync",
+	})
+}
+
 func (s *interfacesSuite) TestConnectionsHotplugGone(c *check.C) {
 	restore := builtin.MockInterface(&ifacetest.TestInterface{InterfaceName: "test"})
 	defer restore()
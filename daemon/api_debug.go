@@ -21,6 +21,7 @@ package daemon
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"sort"
@@ -34,6 +35,7 @@ import (
 	"github.com/snapcore/snapd/overlord/devicestate"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snapdtool"
 	"github.com/snapcore/snapd/timings"
 )
 
@@ -52,10 +54,19 @@ type debugAction struct {
 		ChgID string `json:"chg-id"`
 
 		RecoverySystemLabel string `json:"recovery-system-label"`
+
+		DisableReexecUntilReboot bool `json:"disable-reexec-until-reboot"`
+
+		Invocations int `json:"invocations"`
 	} `json:"params"`
 	Snaps []string `json:"snaps"`
 }
 
+var (
+	snapdtoolReexecStatus             = snapdtool.ReexecStatus
+	snapdtoolDisableReexecUntilReboot = snapdtool.DisableReexecUntilReboot
+)
+
 type connectivityStatus struct {
 	Connectivity bool     `json:"connectivity"`
 	Unreachable  []string `json:"unreachable,omitempty"`
@@ -338,6 +349,50 @@ func createRecovery(st *state.State, label string) Response {
 	return AsyncResponse(nil, chg.ID())
 }
 
+func getReexecStatus() Response {
+	status, err := snapdtoolReexecStatus()
+	if err != nil {
+		return InternalError("cannot get re-exec status: %v", err)
+	}
+	return SyncResponse(status)
+}
+
+func setReexec(disableUntilReboot bool) Response {
+	if err := snapdtoolDisableReexecUntilReboot(disableUntilReboot); err != nil {
+		return InternalError("cannot change re-exec: %v", err)
+	}
+	return SyncResponse(true)
+}
+
+func getConfineDebug() Response {
+	status, err := snapdtool.ConfineDebugStatus()
+	if err != nil {
+		return InternalError("cannot get snap-confine debug status: %v", err)
+	}
+	return SyncResponse(status)
+}
+
+func setConfineDebug(st *state.State, snaps []string, invocations int) Response {
+	if len(snaps) == 0 {
+		return BadRequest("no snaps were provided")
+	}
+	for _, name := range snaps {
+		var snapst snapstate.SnapState
+		if err := snapstate.Get(st, name, &snapst); err != nil {
+			if errors.Is(err, state.ErrNoState) {
+				return SnapNotFound(name, err)
+			}
+			return InternalError("cannot get state of snap %q: %v", name, err)
+		}
+	}
+	for _, name := range snaps {
+		if err := snapdtool.EnableConfineDebug(name, invocations); err != nil {
+			return BadRequest("cannot change snap-confine debug logging of snap %q: %v", name, err)
+		}
+	}
+	return SyncResponse(true)
+}
+
 func getDebug(c *Command, r *http.Request, user *auth.UserState) Response {
 	query := r.URL.Query()
 	aspect := query.Get("aspect")
@@ -370,6 +425,10 @@ func getDebug(c *Command, r *http.Request, user *auth.UserState) Response {
 		return getGadgetDiskMapping(st)
 	case "disks":
 		return getDisks(st)
+	case "reexec":
+		return getReexecStatus()
+	case "confine-debug":
+		return getConfineDebug()
 	default:
 		return BadRequest("unknown debug aspect %q", aspect)
 	}
@@ -411,6 +470,10 @@ func postDebug(c *Command, r *http.Request, user *auth.UserState) Response {
 		return createRecovery(st, a.Params.RecoverySystemLabel)
 	case "migrate-home":
 		return migrateHome(st, a.Snaps)
+	case "reexec":
+		return setReexec(a.Params.DisableReexecUntilReboot)
+	case "confine-debug":
+		return setConfineDebug(st, a.Snaps, a.Params.Invocations)
 	default:
 		return BadRequest("unknown debug action: %v", a.Action)
 	}
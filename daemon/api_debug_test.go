@@ -24,14 +24,19 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"path/filepath"
 	"strings"
 
 	"gopkg.in/check.v1"
 
 	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/daemon"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/snapstate/snapstatetest"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snapdtool"
 	"github.com/snapcore/snapd/testutil"
 	"github.com/snapcore/snapd/timings"
 )
@@ -63,6 +68,119 @@ func (s *postDebugSuite) TestPostDebugEnsureStateSoon(c *check.C) {
 	c.Check(soon, check.Equals, 1)
 }
 
+func (s *postDebugSuite) TestGetDebugReexec(c *check.C) {
+	s.daemonWithOverlordMock()
+
+	restore := daemon.MockSnapdtoolReexecStatus(func() (*snapdtool.ReexecInfo, error) {
+		return &snapdtool.ReexecInfo{
+			Exe:    "/usr/lib/snapd/snapd",
+			Reason: "disabled until reboot",
+		}, nil
+	})
+	defer restore()
+
+	req, err := http.NewRequest("GET", "/v2/debug?aspect=reexec", nil)
+	c.Assert(err, check.IsNil)
+
+	rsp := s.syncReq(c, req, nil)
+	c.Check(rsp.Result, check.DeepEquals, &snapdtool.ReexecInfo{
+		Exe:    "/usr/lib/snapd/snapd",
+		Reason: "disabled until reboot",
+	})
+}
+
+func (s *postDebugSuite) TestPostDebugReexec(c *check.C) {
+	s.daemonWithOverlordMock()
+	s.expectRootAccess()
+
+	var calls []bool
+	restore := daemon.MockSnapdtoolDisableReexecUntilReboot(func(disable bool) error {
+		calls = append(calls, disable)
+		return nil
+	})
+	defer restore()
+
+	for _, body := range []string{
+		`{"action": "reexec", "params": {"disable-reexec-until-reboot": true}}`,
+		`{"action": "reexec", "params": {"disable-reexec-until-reboot": false}}`,
+	} {
+		req, err := http.NewRequest("POST", "/v2/debug", strings.NewReader(body))
+		c.Assert(err, check.IsNil)
+		rsp := s.syncReq(c, req, nil)
+		c.Check(rsp.Result, check.Equals, true)
+	}
+	c.Check(calls, check.DeepEquals, []bool{true, false})
+}
+
+func (s *postDebugSuite) TestConfineDebug(c *check.C) {
+	d := s.daemonWithOverlordMock()
+	s.expectRootAccess()
+
+	st := d.Overlord().State()
+	st.Lock()
+	snapstate.Set(st, "foo", &snapstate.SnapState{
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "foo", Revision: snap.R(1)},
+		}),
+		Current: snap.R(1),
+		Active:  true,
+	})
+	st.Unlock()
+
+	body := strings.NewReader(`{"action": "confine-debug", "snaps": ["foo"], "params": {"invocations": 3}}`)
+	req, err := http.NewRequest("POST", "/v2/debug", body)
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil)
+	c.Check(rsp.Result, check.Equals, true)
+	c.Check(filepath.Join(dirs.SnapConfineDebugDir, "foo"), testutil.FileEquals, "3\n")
+
+	s.expectReadAccess(daemon.OpenAccess{})
+	req, err = http.NewRequest("GET", "/v2/debug?aspect=confine-debug", nil)
+	c.Assert(err, check.IsNil)
+	rsp = s.syncReq(c, req, nil)
+	c.Check(rsp.Result, check.DeepEquals, map[string]int{"foo": 3})
+
+	body = strings.NewReader(`{"action": "confine-debug", "snaps": ["foo"], "params": {"invocations": 0}}`)
+	req, err = http.NewRequest("POST", "/v2/debug", body)
+	c.Assert(err, check.IsNil)
+	rsp = s.syncReq(c, req, nil)
+	c.Check(rsp.Result, check.Equals, true)
+	c.Check(filepath.Join(dirs.SnapConfineDebugDir, "foo"), testutil.FileAbsent)
+}
+
+func (s *postDebugSuite) TestConfineDebugErrors(c *check.C) {
+	d := s.daemonWithOverlordMock()
+	s.expectRootAccess()
+
+	st := d.Overlord().State()
+	st.Lock()
+	snapstate.Set(st, "foo", &snapstate.SnapState{
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "foo", Revision: snap.R(1)},
+		}),
+		Current: snap.R(1),
+		Active:  true,
+	})
+	st.Unlock()
+
+	for _, t := range []struct {
+		body   string
+		status int
+		msg    string
+	}{
+		{`{"action": "confine-debug", "params": {"invocations": 1}}`, 400, `no snaps were provided`},
+		{`{"action": "confine-debug", "snaps": ["foo", "bar"], "params": {"invocations": 1}}`, 404, `no state entry for key`},
+		{`{"action": "confine-debug", "snaps": ["foo"], "params": {"invocations": -1}}`, 400, `cannot change snap-confine debug logging of snap "foo": invalid number of invocations -1, must be between 0 and 1000`},
+	} {
+		req, err := http.NewRequest("POST", "/v2/debug", strings.NewReader(t.body))
+		c.Assert(err, check.IsNil)
+		rspe := s.errorReq(c, req, nil)
+		c.Check(rspe.Status, check.Equals, t.status, check.Commentf(t.body))
+		c.Check(rspe.Message, check.Matches, t.msg, check.Commentf(t.body))
+	}
+	c.Check(dirs.SnapConfineDebugDir, testutil.FileAbsent)
+}
+
 func (s *postDebugSuite) TestDebugConnectivityHappy(c *check.C) {
 	_ = s.daemon(c)
 
@@ -0,0 +1,65 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+
+	"github.com/snapcore/snapd/desktop/desktopentry"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/overlord/auth"
+)
+
+var desktopLaunchCmd = &Command{
+	Path:        "/v2/desktop-launch",
+	POST:        postDesktopLaunch,
+	WriteAccess: openAccess{},
+}
+
+type desktopLaunchRequest struct {
+	DesktopFile string `json:"desktop-file"`
+	SnapApp     string `json:"snap-app"`
+	Token       string `json:"token"`
+}
+
+// postDesktopLaunch verifies the launch token of a launch from a desktop
+// file generated by snapd. The launch key is only readable by root, so the
+// verification is done by snapd on behalf of "snap run".
+func postDesktopLaunch(c *Command, r *http.Request, _ *auth.UserState) Response {
+	var req desktopLaunchRequest
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&req); err != nil {
+		return BadRequest("cannot decode request body: %v", err)
+	}
+	if req.DesktopFile == "" || req.SnapApp == "" || req.Token == "" {
+		return BadRequest("desktop file, snap app and token must be provided")
+	}
+
+	key, err := os.ReadFile(dirs.SnapDesktopLaunchKey)
+	if err != nil {
+		return InternalError("cannot read desktop launch key: %v", err)
+	}
+	if !desktopentry.VerifyLaunchToken(key, req.DesktopFile, req.SnapApp, req.Token) {
+		return BadRequest("invalid launch token for desktop file %q", req.DesktopFile)
+	}
+	return SyncResponse(nil)
+}
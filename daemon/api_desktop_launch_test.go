@@ -0,0 +1,76 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon_test
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/daemon"
+	"github.com/snapcore/snapd/desktop/desktopentry"
+	"github.com/snapcore/snapd/dirs"
+)
+
+var _ = check.Suite(&desktopLaunchSuite{})
+
+type desktopLaunchSuite struct {
+	apiBaseSuite
+}
+
+func (s *desktopLaunchSuite) SetUpTest(c *check.C) {
+	s.apiBaseSuite.SetUpTest(c)
+	s.expectWriteAccess(daemon.OpenAccess{})
+	s.daemon(c)
+
+	c.Assert(os.MkdirAll(filepath.Dir(dirs.SnapDesktopLaunchKey), 0755), check.IsNil)
+	c.Assert(os.WriteFile(dirs.SnapDesktopLaunchKey, []byte("some-key"), 0600), check.IsNil)
+}
+
+func (s *desktopLaunchSuite) TestVerify(c *check.C) {
+	token := desktopentry.LaunchToken([]byte("some-key"), "/var/lib/snapd/desktop/applications/foo_app.desktop", "foo.app")
+	body := `{"desktop-file": "/var/lib/snapd/desktop/applications/foo_app.desktop", "snap-app": "foo.app", "token": "` + token + `"}`
+	req, err := http.NewRequest("POST", "/v2/desktop-launch", bytes.NewBufferString(body))
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil)
+	c.Check(rsp.Status, check.Equals, 200)
+}
+
+func (s *desktopLaunchSuite) TestVerifyInvalidToken(c *check.C) {
+	token := desktopentry.LaunchToken([]byte("some-key"), "/var/lib/snapd/desktop/applications/foo_app.desktop", "foo.app")
+	// the token of foo.app is re-routed to another app
+	body := `{"desktop-file": "/var/lib/snapd/desktop/applications/foo_app.desktop", "snap-app": "bar.app", "token": "` + token + `"}`
+	req, err := http.NewRequest("POST", "/v2/desktop-launch", bytes.NewBufferString(body))
+	c.Assert(err, check.IsNil)
+	rspe := s.errorReq(c, req, nil)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Message, check.Equals, `invalid launch token for desktop file "/var/lib/snapd/desktop/applications/foo_app.desktop"`)
+}
+
+func (s *desktopLaunchSuite) TestVerifyMissingFields(c *check.C) {
+	req, err := http.NewRequest("POST", "/v2/desktop-launch", bytes.NewBufferString(`{"desktop-file": "foo_app.desktop", "snap-app": "foo.app"}`))
+	c.Assert(err, check.IsNil)
+	rspe := s.errorReq(c, req, nil)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Message, check.Equals, "desktop file, snap app and token must be provided")
+}
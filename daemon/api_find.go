@@ -54,6 +54,8 @@ func searchStore(c *Command, r *http.Request, user *auth.UserState) Response {
 	commonID := query.Get("common-id")
 	section := query.Get("section")
 	category := query.Get("category")
+	publisher := query.Get("publisher")
+	license := query.Get("license")
 	name := query.Get("name")
 	scope := query.Get("scope")
 	private := false
@@ -107,12 +109,14 @@ func searchStore(c *Command, r *http.Request, user *auth.UserState) Response {
 	theStore := storeFrom(c.d)
 	ctx := store.WithClientUserAgent(r.Context(), r)
 	found, err := theStore.Find(ctx, &store.Search{
-		Query:    q,
-		Prefix:   prefix,
-		CommonID: commonID,
-		Category: category,
-		Private:  private,
-		Scope:    scope,
+		Query:     q,
+		Prefix:    prefix,
+		CommonID:  commonID,
+		Category:  category,
+		Publisher: publisher,
+		License:   license,
+		Private:   private,
+		Scope:     scope,
 	}, user)
 	switch err {
 	case nil:
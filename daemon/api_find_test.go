@@ -246,6 +246,23 @@ func (s *findSuite) TestFindCategory(c *check.C) {
 	})
 }
 
+func (s *findSuite) TestFindPublisherAndLicense(c *check.C) {
+	s.daemon(c)
+
+	s.rsnaps = []*snap.Info{}
+
+	req, err := http.NewRequest("GET", "/v2/find?q=foo&publisher=bar&license=MIT", nil)
+	c.Assert(err, check.IsNil)
+
+	_ = s.syncReq(c, req, nil)
+
+	c.Check(s.storeSearch, check.DeepEquals, store.Search{
+		Query:     "foo",
+		Publisher: "bar",
+		License:   "MIT",
+	})
+}
+
 func (s *findSuite) TestFindScope(c *check.C) {
 	s.daemon(c)
 
@@ -42,6 +42,7 @@ import (
 	"github.com/snapcore/snapd/release"
 	"github.com/snapcore/snapd/sandbox"
 	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/strutil"
 )
 
 var (
@@ -235,13 +236,49 @@ func getChanges(c *Command, r *http.Request, user *auth.UserState) Response {
 		return BadRequest("select should be one of: all,in-progress,ready")
 	}
 
-	if wantedName := query.Get("for"); wantedName != "" {
+	// narrow adds a further condition to filter
+	narrow := func(cond func(*state.Change) bool) {
 		outerFilter := filter
 		filter = func(chg *state.Change) bool {
-			if !outerFilter(chg) {
-				return false
+			return outerFilter(chg) && cond(chg)
+		}
+	}
+
+	if kinds := strutil.CommaSeparatedList(query.Get("kind")); len(kinds) != 0 {
+		narrow(func(chg *state.Change) bool {
+			return strutil.ListContains(kinds, chg.Kind())
+		})
+	}
+
+	if statuses := strutil.CommaSeparatedList(query.Get("status")); len(statuses) != 0 {
+		for _, status := range statuses {
+			if !isChangeStatusName(status) {
+				return BadRequest("invalid change status %q", status)
 			}
+		}
+		narrow(func(chg *state.Change) bool {
+			return strutil.ListContains(statuses, chg.Status().String())
+		})
+	}
 
+	if v := query.Get("since"); v != "" {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return BadRequest("invalid since parameter: %v", err)
+		}
+		narrow(func(chg *state.Change) bool { return !chg.SpawnTime().Before(since) })
+	}
+
+	if v := query.Get("until"); v != "" {
+		until, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return BadRequest("invalid until parameter: %v", err)
+		}
+		narrow(func(chg *state.Change) bool { return chg.SpawnTime().Before(until) })
+	}
+
+	if wantedName := query.Get("for"); wantedName != "" {
+		narrow(func(chg *state.Change) bool {
 			var snapNames []string
 			if err := chg.Get("snap-names", &snapNames); err != nil {
 				logger.Noticef("Cannot get snap-name for change %v", chg.ID())
@@ -259,7 +296,7 @@ func getChanges(c *Command, r *http.Request, user *auth.UserState) Response {
 				}
 			}
 			return false
-		}
+		})
 	}
 
 	state := c.d.overlord.State()
@@ -276,6 +313,16 @@ func getChanges(c *Command, r *http.Request, user *auth.UserState) Response {
 	return SyncResponse(chgInfos)
 }
 
+// isChangeStatusName returns whether name is the name of a change status.
+func isChangeStatusName(name string) bool {
+	for st := state.HoldStatus; st <= state.WaitStatus; st++ {
+		if st.String() == name {
+			return true
+		}
+	}
+	return false
+}
+
 func abortChange(c *Command, r *http.Request, user *auth.UserState) Response {
 	chID := muxVars(r)["id"]
 	state := c.d.overlord.State()
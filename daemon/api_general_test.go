@@ -27,6 +27,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sort"
 	"time"
 
 	"gopkg.in/check.v1"
@@ -610,6 +611,66 @@ func (s *generalSuite) TestStateChangesForSnapNameWithApp(c *check.C) {
 	c.Assert(rec.Code, check.Equals, 200)
 }
 
+func (s *generalSuite) TestStateChangesFilters(c *check.C) {
+	restore := state.MockTime(time.Date(2016, 04, 21, 1, 2, 3, 0, time.UTC))
+	defer restore()
+
+	s.expectChangesReadAccess()
+	d := s.daemon(c)
+	st := d.Overlord().State()
+	st.Lock()
+	setupChanges(st)
+	st.Unlock()
+
+	for _, t := range []struct {
+		query string
+		kinds []string
+	}{
+		{"kind=remove", []string{"remove"}},
+		{"kind=install,remove", []string{"install", "remove"}},
+		{"status=Error", []string{"remove"}},
+		{"status=Do,Doing", []string{"install"}},
+		{"kind=install&status=Error", nil},
+		{"since=2016-04-21T01:02:03Z", []string{"install", "remove"}},
+		{"since=2016-04-21T01:02:04Z", nil},
+		{"until=2016-04-21T01:02:03Z", nil},
+		{"until=2016-04-21T03:02:04%2B02:00", []string{"install", "remove"}},
+		{"for=funky-snap-name&status=Do", []string{"install"}},
+	} {
+		req, err := http.NewRequest("GET", "/v2/changes?select=all&"+t.query, nil)
+		c.Assert(err, check.IsNil)
+		rsp := s.syncReq(c, req, nil)
+		c.Assert(rsp.Result, check.FitsTypeOf, []*daemon.ChangeInfo(nil), check.Commentf(t.query))
+
+		var kinds []string
+		for _, chg := range rsp.Result.([]*daemon.ChangeInfo) {
+			kinds = append(kinds, chg.Kind)
+		}
+		sort.Strings(kinds)
+		c.Check(kinds, check.DeepEquals, t.kinds, check.Commentf(t.query))
+	}
+}
+
+func (s *generalSuite) TestStateChangesFiltersErrors(c *check.C) {
+	s.expectChangesReadAccess()
+	s.daemon(c)
+
+	for _, t := range []struct {
+		query string
+		err   string
+	}{
+		{"status=Bogus", `invalid change status "Bogus"`},
+		{"since=yesterday", `invalid since parameter: .*`},
+		{"until=2016-04-21", `invalid until parameter: .*`},
+	} {
+		req, err := http.NewRequest("GET", "/v2/changes?select=all&"+t.query, nil)
+		c.Assert(err, check.IsNil)
+		rspe := s.errorReq(c, req, nil)
+		c.Check(rspe.Status, check.Equals, 400)
+		c.Check(rspe.Message, check.Matches, t.err)
+	}
+}
+
 func (s *generalSuite) TestStateChange(c *check.C) {
 	restore := state.MockTime(time.Date(2016, 04, 21, 1, 2, 3, 0, time.UTC))
 	defer restore()
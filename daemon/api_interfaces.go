@@ -96,6 +96,9 @@ func getInterfaces(c *Command, r *http.Request, user *auth.UserState) Response {
 				Name:  slot.Name,
 				Attrs: slot.Attrs,
 				Label: slot.Label,
+				// hotplug slots carry the key of the device they were
+				// created for
+				Hotplug: slot.HotplugKey != "",
 			})
 		}
 		infoJSONs = append(infoJSONs, &interfaceJSON{
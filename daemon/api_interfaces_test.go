@@ -36,6 +36,7 @@ import (
 	"github.com/snapcore/snapd/interfaces/ifacetest"
 	"github.com/snapcore/snapd/overlord/ifacestate"
 	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
 )
 
 var _ = check.Suite(&interfacesSuite{})
@@ -444,6 +445,8 @@ func (s *interfacesSuite) TestConnectPlugChangeConflict(c *check.C) {
 			"kind":    "snap-change-conflict",
 			"value": map[string]interface{}{
 				"change-kind": "manip",
+				"change-id":   "1",
+				"retry-after": 5.,
 				"snap-name":   "consumer",
 			},
 		},
@@ -868,6 +871,8 @@ func (s *interfacesSuite) TestDisconnectConflict(c *check.C) {
 			"kind":    "snap-change-conflict",
 			"value": map[string]interface{}{
 				"change-kind": "manip",
+				"change-id":   "1",
+				"retry-after": 5.,
 				"snap-name":   "consumer",
 			},
 		},
@@ -1187,6 +1192,53 @@ func (s *interfacesSuite) TestInterfacesModern(c *check.C) {
 	})
 }
 
+func (s *interfacesSuite) TestInterfacesHotplugSlot(c *check.C) {
+	restore := builtin.MockInterface(&ifacetest.TestInterface{InterfaceName: "test"})
+	defer restore()
+
+	d := s.daemon(c)
+
+	coreInfo := s.mockSnap(c, coreProducerYaml)
+
+	repo := d.Overlord().InterfaceManager().Repository()
+	err := repo.AddSlot(&snap.SlotInfo{
+		Snap:       coreInfo,
+		Name:       "hotplugslot",
+		Interface:  "test",
+		HotplugKey: "1234",
+	})
+	c.Assert(err, check.IsNil)
+
+	req, err := http.NewRequest("GET", "/v2/interfaces?select=all&names=test&slots=true", nil)
+	c.Assert(err, check.IsNil)
+	rec := httptest.NewRecorder()
+	s.req(c, req, nil).ServeHTTP(rec, req)
+	c.Check(rec.Code, check.Equals, 200)
+	var body map[string]interface{}
+	err = json.Unmarshal(rec.Body.Bytes(), &body)
+	c.Check(err, check.IsNil)
+	c.Check(body["result"], check.DeepEquals, []interface{}{
+		map[string]interface{}{
+			"name": "test",
+			"slots": []interface{}{
+				map[string]interface{}{
+					"snap":    "core",
+					"slot":    "hotplugslot",
+					"hotplug": true,
+				},
+				map[string]interface{}{
+					"snap":  "core",
+					"slot":  "slot",
+					"label": "label",
+					"attrs": map[string]interface{}{
+						"key": "value",
+					},
+				},
+			},
+		},
+	})
+}
+
 func (s *interfacesSuite) TestInterfacesAllDefaultDocURL(c *check.C) {
 	_ = s.daemon(c)
 
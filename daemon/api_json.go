@@ -43,6 +43,8 @@ type slotJSON struct {
 	Attrs     map[string]interface{} `json:"attrs,omitempty"`
 	Apps      []string               `json:"apps,omitempty"`
 	Label     string                 `json:"label,omitempty"`
+	// Hotplug is set for slots created dynamically for hotplugged devices.
+	Hotplug bool `json:"hotplug,omitempty"`
 	// Connections are synthesized, they are not on the original type.
 	Connections []interfaces.PlugRef `json:"connections,omitempty"`
 }
@@ -122,7 +122,7 @@ func remodelJSON(c *Command, r *http.Request) Response {
 		Offline: data.Offline,
 	})
 	if err != nil {
-		return BadRequest("cannot remodel device: %v", err)
+		return errToResponse(err, nil, BadRequest, "cannot remodel device: %v")
 	}
 	ensureStateSoon(st)
 
@@ -208,7 +208,7 @@ func startOfflineRemodelChange(st *state.State, newModel *asserts.Model,
 		Offline: true,
 	})
 	if err != nil {
-		return nil, BadRequest("cannot remodel device: %v", err)
+		return nil, errToResponse(err, nil, BadRequest, "cannot remodel device: %v")
 	}
 	ensureStateSoon(st)
 
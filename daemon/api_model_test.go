@@ -44,6 +44,7 @@ import (
 	"github.com/snapcore/snapd/overlord/devicestate"
 	"github.com/snapcore/snapd/overlord/devicestate/devicestatetest"
 	"github.com/snapcore/snapd/overlord/hookstate"
+	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/snap"
 )
@@ -171,6 +172,34 @@ func (s *modelSuite) testPostRemodel(c *check.C, offline bool) {
 	c.Assert(soon, check.Equals, 1)
 }
 
+func (s *modelSuite) TestPostRemodelConflict(c *check.C) {
+	s.expectRootAccess()
+
+	newModel := s.Brands.Model("my-brand", "my-old-model", modelDefaults, map[string]interface{}{
+		"revision": "2",
+	})
+
+	s.daemonWithOverlordMockAndStore()
+
+	defer daemon.MockDevicestateRemodel(func(st *state.State, nm *asserts.Model, localSnaps []*snap.SideInfo, paths []string, opts devicestate.RemodelOptions) (*state.Change, error) {
+		return nil, &snapstate.ChangeConflictError{
+			Message:    "cannot start remodel, clashing with concurrent one",
+			ChangeKind: "remodel",
+		}
+	})()
+
+	data, err := json.Marshal(daemon.PostModelData{NewModel: string(asserts.Encode(newModel))})
+	c.Check(err, check.IsNil)
+
+	req, err := http.NewRequest("POST", "/v2/model", bytes.NewBuffer(data))
+	c.Assert(err, check.IsNil)
+	rspe := s.errorReq(c, req, nil)
+	c.Check(rspe.Status, check.Equals, 409)
+	c.Check(rspe.Kind, check.Equals, client.ErrorKindSnapChangeConflict)
+	c.Check(rspe.Message, check.Equals, "cannot start remodel, clashing with concurrent one")
+	c.Check(rspe.Value, check.DeepEquals, map[string]interface{}{"change-kind": "remodel"})
+}
+
 func (s *modelSuite) TestPostRemodelWrongBody(c *check.C) {
 	s.expectRootAccess()
 
@@ -0,0 +1,172 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/overlord/ifacestate"
+	"github.com/snapcore/snapd/overlord/servicestate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/strutil"
+)
+
+var sandboxInfoCmd = &Command{
+	Path:       "/v2/sandbox-info",
+	GET:        getSandboxInfo,
+	ReadAccess: snapSelfAccess{},
+}
+
+// getSandboxInfo returns information about the sandbox of a snap: the
+// state of its connections, its data directories, its quota group and
+// whether a refresh of it is inhibited.
+func getSandboxInfo(c *Command, r *http.Request, _ *auth.UserState) Response {
+	snapName := r.URL.Query().Get("snap")
+	if snapName == "" {
+		return BadRequest("snap name is required")
+	}
+
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	var snapst snapstate.SnapState
+	if err := snapstate.Get(st, snapName, &snapst); err != nil && !errors.Is(err, state.ErrNoState) {
+		return InternalError("cannot consult state: %v", err)
+	}
+	info, err := snapst.CurrentInfo()
+	if err == snapstate.ErrNoCurrent {
+		return SnapNotFound(snapName, err)
+	}
+	if err != nil {
+		return InternalError("cannot read snap details: %v", err)
+	}
+
+	connections, err := sandboxConnections(st, snapName)
+	if err != nil {
+		return InternalError("cannot get connections: %v", err)
+	}
+
+	dataDirs, err := sandboxDataDirs(st, r, info)
+	if err != nil {
+		return InternalError("cannot get data directories: %v", err)
+	}
+
+	quota, err := sandboxQuota(st, snapName)
+	if err != nil {
+		return InternalError("cannot get quota group: %v", err)
+	}
+
+	return SyncResponse(&client.SandboxInfo{
+		Snap:           snapName,
+		Connections:    connections,
+		DataDirs:       *dataDirs,
+		Quota:          quota,
+		RefreshInhibit: clientSnapRefreshInhibit(st, &snapst, snapName),
+	})
+}
+
+func sandboxConnections(st *state.State, snapName string) ([]client.SandboxConnection, error) {
+	conns, err := ifacestate.ConnectionStates(st)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make([]string, 0, len(conns))
+	for refStr := range conns {
+		refs = append(refs, refStr)
+	}
+	sort.Strings(refs)
+
+	var connections []client.SandboxConnection
+	for _, refStr := range refs {
+		connRef, err := interfaces.ParseConnRef(refStr)
+		if err != nil {
+			return nil, err
+		}
+		if connRef.PlugRef.Snap != snapName && connRef.SlotRef.Snap != snapName {
+			continue
+		}
+		connState := conns[refStr]
+		connections = append(connections, client.SandboxConnection{
+			Interface: connState.Interface,
+			Plug:      client.PlugRef{Snap: connRef.PlugRef.Snap, Name: connRef.PlugRef.Name},
+			Slot:      client.SlotRef{Snap: connRef.SlotRef.Snap, Name: connRef.SlotRef.Name},
+			Connected: connState.Active(),
+			Manual:    !connState.Auto,
+			Gadget:    connState.ByGadget,
+		})
+	}
+	return connections, nil
+}
+
+func sandboxDataDirs(st *state.State, r *http.Request, info *snap.Info) (*client.SandboxDataDirs, error) {
+	dataDirs := &client.SandboxDataDirs{
+		Data:   info.DataDir(),
+		Common: info.CommonDataDir(),
+	}
+
+	// per-user directories are only reported when the requesting user
+	// can be identified
+	u, err := systemUserFromRequest(r)
+	if err != nil || u.HomeDir == "" {
+		return dataDirs, nil
+	}
+	opts, err := snapstate.GetSnapDirOpts(st, info.InstanceName())
+	if err != nil {
+		return nil, err
+	}
+	dataDirs.UserData = info.UserDataDir(u.HomeDir, opts)
+	dataDirs.UserCommon = info.UserCommonDataDir(u.HomeDir, opts)
+	return dataDirs, nil
+}
+
+func sandboxQuota(st *state.State, snapName string) (*client.QuotaGroupResult, error) {
+	quotas, err := servicestate.AllQuotas(st)
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range quotas {
+		if !strutil.ListContains(group.Snaps, snapName) {
+			continue
+		}
+		currentUsage, err := getQuotaUsage(group)
+		if err != nil {
+			return nil, err
+		}
+		return &client.QuotaGroupResult{
+			GroupName:   group.Name,
+			Parent:      group.ParentGroup,
+			Subgroups:   group.SubGroups,
+			Snaps:       group.Snaps,
+			Services:    group.Services,
+			Constraints: createQuotaValues(group),
+			Current:     currentUsage,
+		}, nil
+	}
+	return nil, nil
+}
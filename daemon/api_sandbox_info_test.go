@@ -0,0 +1,180 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon_test
+
+import (
+	"context"
+	"net/http"
+	"os/user"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/daemon"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/gadget/quantity"
+	"github.com/snapcore/snapd/overlord/servicestate/servicestatetest"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/snap/quota"
+)
+
+var _ = check.Suite(&sandboxInfoSuite{})
+
+type sandboxInfoSuite struct {
+	apiBaseSuite
+}
+
+func (s *sandboxInfoSuite) SetUpTest(c *check.C) {
+	s.apiBaseSuite.SetUpTest(c)
+	s.expectReadAccess(daemon.SnapSelfAccess{})
+	s.daemon(c)
+
+	s.AddCleanup(daemon.MockSystemUserFromRequest(func(r *http.Request) (*user.User, error) {
+		return &user.User{
+			Uid:      "1000",
+			Gid:      "1000",
+			Username: "user",
+			HomeDir:  "/home/user",
+		}, nil
+	}))
+}
+
+const sandboxInfoSnapYaml = `name: foo
+version: 1
+apps:
+  app:
+plugs:
+  network:
+  home:
+`
+
+func (s *sandboxInfoSuite) TestSandboxInfo(c *check.C) {
+	s.mockSnap(c, sandboxInfoSnapYaml)
+
+	st := s.d.Overlord().State()
+	st.Lock()
+	st.Set("conns", map[string]interface{}{
+		"foo:network core:network": map[string]interface{}{
+			"interface": "network",
+			"auto":      true,
+		},
+		"foo:home core:home": map[string]interface{}{
+			"interface": "home",
+			"undesired": true,
+		},
+		"other:network core:network": map[string]interface{}{
+			"interface": "network",
+			"auto":      true,
+		},
+	})
+	err := servicestatetest.MockQuotaInState(st, "grp", "", []string{"foo"}, nil, quota.NewResourcesBuilder().WithMemoryLimit(quantity.SizeMiB).Build())
+	c.Assert(err, check.IsNil)
+
+	var snapst snapstate.SnapState
+	c.Assert(snapstate.Get(st, "foo", &snapst), check.IsNil)
+	refreshInhibitTime := time.Now().Add(time.Hour)
+	snapst.RefreshInhibitedTime = &refreshInhibitTime
+	snapstate.Set(st, "foo", &snapst)
+	expectedProceedTime := snapst.RefreshInhibitProceedTime(st)
+	st.Cache("monitored-snaps", map[string]context.CancelFunc{"foo": func() {}})
+	st.Unlock()
+
+	s.AddCleanup(daemon.MockGetQuotaUsage(func(grp *quota.Group) (*client.QuotaValues, error) {
+		c.Check(grp.Name, check.Equals, "grp")
+		return &client.QuotaValues{Memory: quantity.Size(500)}, nil
+	}))
+
+	req, err := http.NewRequest("GET", "/v2/sandbox-info?snap=foo", nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil)
+	c.Assert(rsp.Status, check.Equals, 200)
+	c.Assert(rsp.Result, check.FitsTypeOf, &client.SandboxInfo{})
+	info := rsp.Result.(*client.SandboxInfo)
+
+	c.Assert(info.RefreshInhibit, check.NotNil)
+	c.Check(info.RefreshInhibit.ProceedTime.Equal(expectedProceedTime), check.Equals, true)
+	info.RefreshInhibit = nil
+
+	c.Check(info, check.DeepEquals, &client.SandboxInfo{
+		Snap: "foo",
+		Connections: []client.SandboxConnection{
+			{
+				Interface: "home",
+				Plug:      client.PlugRef{Snap: "foo", Name: "home"},
+				Slot:      client.SlotRef{Snap: "core", Name: "home"},
+				Manual:    true,
+			},
+			{
+				Interface: "network",
+				Plug:      client.PlugRef{Snap: "foo", Name: "network"},
+				Slot:      client.SlotRef{Snap: "core", Name: "network"},
+				Connected: true,
+			},
+		},
+		DataDirs: client.SandboxDataDirs{
+			Data:       filepath.Join(dirs.SnapDataDir, "foo/1"),
+			Common:     filepath.Join(dirs.SnapDataDir, "foo/common"),
+			UserData:   "/home/user/snap/foo/1",
+			UserCommon: "/home/user/snap/foo/common",
+		},
+		Quota: &client.QuotaGroupResult{
+			GroupName:   "grp",
+			Snaps:       []string{"foo"},
+			Constraints: &client.QuotaValues{Memory: quantity.SizeMiB},
+			Current:     &client.QuotaValues{Memory: quantity.Size(500)},
+		},
+	})
+}
+
+func (s *sandboxInfoSuite) TestSandboxInfoMinimal(c *check.C) {
+	s.mockSnap(c, sandboxInfoSnapYaml)
+
+	req, err := http.NewRequest("GET", "/v2/sandbox-info?snap=foo", nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil)
+	c.Assert(rsp.Status, check.Equals, 200)
+	c.Check(rsp.Result, check.DeepEquals, &client.SandboxInfo{
+		Snap: "foo",
+		DataDirs: client.SandboxDataDirs{
+			Data:       filepath.Join(dirs.SnapDataDir, "foo/1"),
+			Common:     filepath.Join(dirs.SnapDataDir, "foo/common"),
+			UserData:   "/home/user/snap/foo/1",
+			UserCommon: "/home/user/snap/foo/common",
+		},
+	})
+}
+
+func (s *sandboxInfoSuite) TestSandboxInfoNoSnapName(c *check.C) {
+	req, err := http.NewRequest("GET", "/v2/sandbox-info", nil)
+	c.Assert(err, check.IsNil)
+	rspe := s.errorReq(c, req, nil)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Message, check.Equals, "snap name is required")
+}
+
+func (s *sandboxInfoSuite) TestSandboxInfoSnapNotInstalled(c *check.C) {
+	req, err := http.NewRequest("GET", "/v2/sandbox-info?snap=foo", nil)
+	c.Assert(err, check.IsNil)
+	rspe := s.errorReq(c, req, nil)
+	c.Check(rspe.Status, check.Equals, 404)
+	c.Check(rspe.Kind, check.Equals, client.ErrorKindSnapNotFound)
+}
@@ -140,7 +140,7 @@ type sideloadFlags struct {
 	dangerousOK bool
 }
 
-func sideloadOrTrySnap(ctx context.Context, c *Command, body io.ReadCloser, boundary string, user *auth.UserState) Response {
+func sideloadOrTrySnap(ctx context.Context, c *Command, body io.ReadCloser, boundary, remoteAddr string, user *auth.UserState) Response {
 	route := c.d.router.Get(stateChangeCmd.Path)
 	if route == nil {
 		return InternalError("cannot find route for change")
@@ -207,6 +207,10 @@ func sideloadOrTrySnap(ctx context.Context, c *Command, body io.ReadCloser, boun
 	st.Lock()
 	defer st.Unlock()
 
+	if errRsp := ackFormAssertions(st, form.Values["assertion"]); errRsp != nil {
+		return errRsp
+	}
+
 	var chg *state.Change
 	if len(snapFiles) > 1 {
 		chg, errRsp = sideloadManySnaps(ctx, st, snapFiles, sideloadFlags, user)
@@ -218,6 +222,10 @@ func sideloadOrTrySnap(ctx context.Context, c *Command, body io.ReadCloser, boun
 	}
 
 	chg.Set("system-restart-immediate", isTrue(form, "system-restart-immediate"))
+	setRequesterUID(chg, remoteAddr)
+	if err := markPrerequisitesConfirmation(st, chg, isTrue(form, "accept-prerequisites")); err != nil {
+		return InternalError("cannot check prerequisites: %v", err)
+	}
 
 	ensureStateSoon(st)
 
@@ -231,6 +239,29 @@ func sideloadOrTrySnap(ctx context.Context, c *Command, body io.ReadCloser, boun
 	return AsyncResponse(nil, chg.ID())
 }
 
+// ackFormAssertions adds the assertion streams uploaded along with the
+// sideloaded snaps to the assertion database, so that the snaps can be
+// installed as asserted snaps.
+func ackFormAssertions(st *state.State, streams []string) *apiError {
+	if len(streams) == 0 {
+		return nil
+	}
+
+	batch := asserts.NewBatch(nil)
+	for _, stream := range streams {
+		if _, err := batch.AddStream(strings.NewReader(stream)); err != nil {
+			return BadRequest("cannot decode assertions: %v", err)
+		}
+	}
+
+	if err := assertstate.AddBatch(st, batch, &asserts.CommitOptions{
+		Precheck: true,
+	}); err != nil {
+		return BadRequest("cannot add assertions: %v", err)
+	}
+	return nil
+}
+
 // sideloadedInfo contains information from a bunch of sideloaded snaps
 type sideloadedInfo struct {
 	// snaps contains the set of snaps that should be sideloaded. Any components
@@ -605,6 +636,9 @@ func sideloadSnap(_ context.Context, st *state.State, upload *uploadedContainer,
 
 func readInfoAndDeriveSideInfo(st *state.State, tempPath string, origPath string, flags sideloadFlags, model *asserts.Model) (*snap.Info, *apiError) {
 	if flags.dangerousOK {
+		if model.SideloadAuthority() != nil {
+			return nil, BadRequest("cannot sideload unasserted snaps: the device model only allows sideloading snaps signed by: %s", strings.Join(model.SideloadAuthority(), ", "))
+		}
 		info, err := unsafeReadSnapInfo(tempPath)
 		if err != nil {
 			return nil, BadRequest("cannot read snap file: %v", err)
@@ -613,7 +647,7 @@ func readInfoAndDeriveSideInfo(st *state.State, tempPath string, origPath string
 		return info, nil
 	}
 
-	si, err := snapasserts.DeriveSideInfo(tempPath, model, assertstate.DB(st))
+	si, err := snapasserts.DeriveSideInfoForSideload(tempPath, model, assertstate.DB(st))
 	if err != nil {
 		if !errors.Is(err, &asserts.NotFoundError{}) {
 			return nil, BadRequest(err.Error())
@@ -621,7 +655,7 @@ func readInfoAndDeriveSideInfo(st *state.State, tempPath string, origPath string
 
 		// with devmode we try to find assertions but it's ok
 		// if they are not there (implies --dangerous)
-		if !flags.DevMode {
+		if !flags.DevMode || model.SideloadAuthority() != nil {
 			msg := "cannot find signatures with metadata for snap/component"
 			if origPath != "" {
 				msg = fmt.Sprintf("%s %q", msg, origPath)
@@ -635,7 +669,7 @@ func readInfoAndDeriveSideInfo(st *state.State, tempPath string, origPath string
 		return nil, BadRequest("cannot read snap file: %v", err)
 	}
 
-	// might be nil if snapasserts.DeriveSideInfo returned an error and we're
+	// might be nil if snapasserts.DeriveSideInfoForSideload returned an error and we're
 	// doing a devmode install
 	if si != nil {
 		info.SideInfo = *si
@@ -677,6 +711,9 @@ func readComponentInfoAndDeriveSideInfo(
 	matchingSnap func(instanceName string, cref naming.ComponentRef) (*snap.Info, *apiError),
 ) (*snap.ComponentInfo, *snap.Info, *apiError) {
 	if flags.dangerousOK {
+		if model.SideloadAuthority() != nil {
+			return nil, nil, BadRequest("cannot sideload unasserted components: the device model only allows sideloading components signed by: %s", strings.Join(model.SideloadAuthority(), ", "))
+		}
 		return readComponentInfoDangerous(upload, matchingSnap)
 	}
 
@@ -712,7 +749,7 @@ func readComponentInfoAndDeriveSideInfo(
 
 	db := assertstate.DB(st)
 
-	csi, err := snapasserts.DeriveComponentSideInfo(cref.ComponentName, upload.tmpPath, info, model, db)
+	csi, err := snapasserts.DeriveComponentSideInfoForSideload(cref.ComponentName, upload.tmpPath, info, model, db)
 	if err != nil {
 		if !errors.Is(err, &asserts.NotFoundError{}) {
 			return nil, nil, BadRequest(err.Error())
@@ -723,7 +760,7 @@ func readComponentInfoAndDeriveSideInfo(
 			msg = fmt.Sprintf("%s %q", msg, upload.filename)
 		}
 
-		if !flags.DevMode {
+		if !flags.DevMode || model.SideloadAuthority() != nil {
 			if upload.filename != "" {
 				msg = fmt.Sprintf("%s %q", msg, upload.filename)
 			}
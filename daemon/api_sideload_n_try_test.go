@@ -44,7 +44,9 @@ import (
 	"github.com/snapcore/snapd/daemon"
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/assertstate"
 	"github.com/snapcore/snapd/overlord/assertstate/assertstatetest"
+	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/snapstate/sequence"
 	"github.com/snapcore/snapd/overlord/snapstate/snapstatetest"
@@ -70,7 +72,7 @@ type sideloadSuite struct {
 func (s *sideloadSuite) SetUpTest(c *check.C) {
 	s.apiBaseSuite.SetUpTest(c)
 
-	s.expectWriteAccess(daemon.AuthenticatedAccess{Polkit: "io.snapcraft.snapd.manage"})
+	s.expectWriteAccess(daemon.SnapOpAccess{})
 }
 
 func (s *sideloadSuite) markSeeded(d *daemon.Daemon) {
@@ -631,6 +633,60 @@ func (s *sideloadSuite) TestSideloadComponentDevModeNoAssertions(c *check.C) {
 	c.Check(systemRestartImmediate, check.Equals, false)
 }
 
+func (s *sideloadSuite) TestSideloadComponentSideloadAuthority(c *check.C) {
+	d := s.daemonWithFakeSnapManager(c)
+	s.markSeeded(d)
+	model := s.Brands.Model("can0nical", "pc", map[string]interface{}{
+		"architecture":       "amd64",
+		"gadget":             "gadget",
+		"kernel":             "kernel",
+		"sideload-authority": []interface{}{"can0nical"},
+	})
+	s.AddCleanup(snapstatetest.MockDeviceModel(model))
+
+	ssi := &snap.SideInfo{
+		RealName: "local",
+		Revision: snap.R(1),
+		SnapID:   snaptest.AssertedSnapID("local"),
+	}
+	st := d.Overlord().State()
+	st.Lock()
+	snapstate.Set(st, "local", &snapstate.SnapState{
+		Active: true,
+		Sequence: snapstatetest.NewSequenceFromRevisionSideInfos(
+			[]*sequence.RevisionSideState{sequence.NewRevisionSideState(ssi, nil)},
+		),
+		Current: snap.R(1),
+	})
+	st.Unlock()
+
+	s.AddCleanup(daemon.MockSnapstateInstallComponentPath(func(st *state.State, csi *snap.ComponentSideInfo, info *snap.Info,
+		path string, opts snapstate.Options) (*state.TaskSet, error) {
+		c.Fatalf("unexpected component install")
+		return nil, nil
+	}))
+
+	devModeBody := sideLoadComponentBody +
+		"Content-Disposition: form-data; name=\"devmode\"\r\n" +
+		"\r\n" +
+		"true\r\n" +
+		"----hello--\r\n"
+
+	for _, t := range []struct {
+		body, msg string
+	}{
+		{sideLoadComponentBodyDangerous, `cannot sideload unasserted snaps: the device model only allows sideloading snaps signed by: can0nical`},
+		// devmode does not imply --dangerous then
+		{devModeBody, `cannot find signatures with metadata for snap/component "a/b/local\+comp_1.0.comp"`},
+	} {
+		apiErr := s.sideloadComponentFailure(c, t.body, map[string]string{
+			"Content-Type": "multipart/thing; boundary=--hello--",
+		})
+		c.Check(apiErr.Status, check.Equals, 400)
+		c.Check(apiErr.Message, check.Matches, t.msg)
+	}
+}
+
 func (s *sideloadSuite) TestSideloadComponentInstanceName(c *check.C) {
 	// try a multipart/form-data upload
 	body := sideLoadComponentBodyDangerous +
@@ -1090,6 +1146,112 @@ version: 1`, nil)
 	})
 }
 
+func (s *sideloadSuite) TestLocalInstallSnapWithAssertions(c *check.C) {
+	d := s.daemonWithOverlordMockAndStore()
+	s.markSeeded(d)
+	st := d.Overlord().State()
+
+	fooSnap := snaptest.MakeTestSnapWithFiles(c, `name: foo
+version: 1`, nil)
+	digest, size, err := asserts.SnapFileSHA3_384(fooSnap)
+	c.Assert(err, check.IsNil)
+	fooSnapBytes, err := os.ReadFile(fooSnap)
+	c.Assert(err, check.IsNil)
+
+	dev1Acct := assertstest.NewAccount(s.StoreSigning, "devel1", nil, "")
+
+	snapDecl, err := s.StoreSigning.Sign(asserts.SnapDeclarationType, map[string]interface{}{
+		"series":       "16",
+		"snap-id":      "foo-id",
+		"snap-name":    "foo",
+		"publisher-id": dev1Acct.AccountID(),
+		"timestamp":    time.Now().Format(time.RFC3339),
+	}, nil, "")
+	c.Assert(err, check.IsNil)
+
+	snapRev, err := s.StoreSigning.Sign(asserts.SnapRevisionType, map[string]interface{}{
+		"snap-sha3-384": digest,
+		"snap-size":     fmt.Sprintf("%d", size),
+		"snap-id":       "foo-id",
+		"snap-revision": "41",
+		"developer-id":  dev1Acct.AccountID(),
+		"timestamp":     time.Now().Format(time.RFC3339),
+	}, nil, "")
+	c.Assert(err, check.IsNil)
+
+	// the assertions are uploaded along with the snap, in two streams
+	var stream1, stream2 bytes.Buffer
+	enc := asserts.NewEncoder(&stream1)
+	c.Assert(enc.Encode(s.StoreSigning.StoreAccountKey("")), check.IsNil)
+	c.Assert(enc.Encode(dev1Acct), check.IsNil)
+	enc = asserts.NewEncoder(&stream2)
+	c.Assert(enc.Encode(snapDecl), check.IsNil)
+	c.Assert(enc.Encode(snapRev), check.IsNil)
+
+	bodyBuf := new(bytes.Buffer)
+	for _, stream := range []*bytes.Buffer{&stream1, &stream2} {
+		bodyBuf.WriteString("----hello--\r\n" +
+			"Content-Disposition: form-data; name=\"assertion\"\r\n\r\n")
+		bodyBuf.Write(stream.Bytes())
+		bodyBuf.WriteString("\r\n")
+	}
+	bodyBuf.WriteString("----hello--\r\n" +
+		"Content-Disposition: form-data; name=\"snap\"; filename=\"foo.snap\"\r\n\r\n")
+	bodyBuf.Write(fooSnapBytes)
+	bodyBuf.WriteString("\r\n----hello--\r\n")
+	req, err := http.NewRequest("POST", "/v2/snaps", bodyBuf)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", "multipart/thing; boundary=--hello--")
+
+	defer daemon.MockSnapstateInstallPath(func(s *state.State, si *snap.SideInfo, path, name, channel string, flags snapstate.Flags, prqt snapstate.PrereqTracker) (*state.TaskSet, *snap.Info, error) {
+		c.Check(flags, check.Equals, snapstate.Flags{RemoveSnapPath: true, Transaction: client.TransactionPerSnap})
+		c.Check(si, check.DeepEquals, &snap.SideInfo{
+			RealName: "foo",
+			SnapID:   "foo-id",
+			Revision: snap.R(41),
+		})
+
+		return state.NewTaskSet(), &snap.Info{SuggestedName: "foo"}, nil
+	})()
+
+	rsp := s.asyncReq(c, req, nil)
+
+	st.Lock()
+	defer st.Unlock()
+	chg := st.Change(rsp.Change)
+	c.Assert(chg, check.NotNil)
+	c.Check(chg.Summary(), check.Equals, `Install "foo" snap from file "foo.snap"`)
+
+	// the assertions were acknowledged
+	_, err = assertstate.DB(st).Find(asserts.SnapRevisionType, map[string]string{
+		"snap-sha3-384": digest,
+	})
+	c.Check(err, check.IsNil)
+}
+
+func (s *sideloadSuite) TestSideloadSnapInvalidAssertions(c *check.C) {
+	body := "" +
+		"----hello--\r\n" +
+		"Content-Disposition: form-data; name=\"assertion\"\r\n" +
+		"\r\n" +
+		"not-an-assertion\r\n" +
+		"----hello--\r\n" +
+		"Content-Disposition: form-data; name=\"snap\"; filename=\"x\"\r\n" +
+		"\r\n" +
+		"xyzzy\r\n" +
+		"----hello--\r\n"
+	d := s.daemonWithOverlordMockAndStore()
+	s.markSeeded(d)
+
+	req, err := http.NewRequest("POST", "/v2/snaps", bytes.NewBufferString(body))
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", "multipart/thing; boundary=--hello--")
+
+	rspe := s.errorReq(c, req, nil)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Message, check.Matches, `cannot decode assertions: .*`)
+}
+
 func (s *sideloadSuite) TestSideloadSnapNoSignaturesDangerOff(c *check.C) {
 	body := "" +
 		"----hello--\r\n" +
@@ -1113,6 +1275,48 @@ func (s *sideloadSuite) TestSideloadSnapNoSignaturesDangerOff(c *check.C) {
 	c.Check(len(glbBefore), check.Equals, len(glbAfter))
 }
 
+func (s *sideloadSuite) TestSideloadSnapDangerousSideloadAuthority(c *check.C) {
+	d := s.daemonWithOverlordMockAndStore()
+	st := d.Overlord().State()
+	st.Lock()
+	st.Set("seeded", true)
+	model := s.Brands.Model("can0nical", "pc", map[string]interface{}{
+		"architecture":       "amd64",
+		"gadget":             "gadget",
+		"kernel":             "kernel",
+		"sideload-authority": []interface{}{"can0nical"},
+	})
+	st.Unlock()
+	s.AddCleanup(snapstatetest.MockDeviceModel(model))
+
+	devModeBody := "" +
+		"----hello--\r\n" +
+		"Content-Disposition: form-data; name=\"snap\"; filename=\"x\"\r\n" +
+		"\r\n" +
+		"xyzzy\r\n" +
+		"----hello--\r\n" +
+		"Content-Disposition: form-data; name=\"devmode\"\r\n" +
+		"\r\n" +
+		"true\r\n" +
+		"----hello--\r\n"
+
+	for _, t := range []struct {
+		body, msg string
+	}{
+		{sideLoadBodyWithoutDevMode, `cannot sideload unasserted snaps: the device model only allows sideloading snaps signed by: can0nical`},
+		// devmode does not imply --dangerous then
+		{devModeBody, `cannot find signatures with metadata for snap/component "x"`},
+	} {
+		req, err := http.NewRequest("POST", "/v2/snaps", bytes.NewBufferString(t.body))
+		c.Assert(err, check.IsNil)
+		req.Header.Set("Content-Type", "multipart/thing; boundary=--hello--")
+
+		rspe := s.errorReq(c, req, nil)
+		c.Check(rspe.Status, check.Equals, 400)
+		c.Check(rspe.Message, check.Equals, t.msg)
+	}
+}
+
 func (s *sideloadSuite) TestSideloadSnapNotValidFormFile(c *check.C) {
 	s.daemon(c)
 
@@ -1257,6 +1461,63 @@ func (s *sideloadSuite) TestInstallPathSystemRestartImmediate(c *check.C) {
 	c.Check(systemRestartImmediate, check.Equals, true)
 }
 
+func (s *sideloadSuite) TestInstallPathPrerequisitesConfirm(c *check.C) {
+	d := s.daemonWithOverlordMockAndStore()
+	s.markSeeded(d)
+
+	st := d.Overlord().State()
+	st.Lock()
+	tr := config.NewTransaction(st)
+	tr.Set("core", "prerequisites.confirm", true)
+	tr.Commit()
+	st.Unlock()
+
+	defer daemon.MockUnsafeReadSnapInfo(func(path string) (*snap.Info, error) {
+		return &snap.Info{SuggestedName: "local"}, nil
+	})()
+	defer daemon.MockSnapstateInstallPath(func(s *state.State, si *snap.SideInfo, path, name, channel string, flags snapstate.Flags, prqt snapstate.PrereqTracker) (*state.TaskSet, *snap.Info, error) {
+		t := s.NewTask("fake-install-snap", "Doing a fake install")
+		return state.NewTaskSet(t), &snap.Info{SuggestedName: "local"}, nil
+	})()
+
+	for _, accept := range []bool{false, true} {
+		body := "" +
+			"----hello--\r\n" +
+			"Content-Disposition: form-data; name=\"snap\"; filename=\"x\"\r\n" +
+			"\r\n" +
+			"xyzzy\r\n" +
+			"----hello--\r\n" +
+			"Content-Disposition: form-data; name=\"dangerous\"\r\n" +
+			"\r\n" +
+			"true\r\n" +
+			"----hello--\r\n" +
+			"Content-Disposition: form-data; name=\"accept-prerequisites\"\r\n" +
+			"\r\n" +
+			fmt.Sprintf("%t\r\n", accept) +
+			"----hello--\r\n"
+		req, err := http.NewRequest("POST", "/v2/snaps", bytes.NewBufferString(body))
+		c.Assert(err, check.IsNil)
+		req.Header.Set("Content-Type", "multipart/thing; boundary=--hello--")
+
+		rsp := s.asyncReq(c, req, nil)
+
+		st.Lock()
+		chg := st.Change(rsp.Change)
+		c.Assert(chg, check.NotNil)
+		// the prerequisites found by the change when it runs must be
+		// confirmed unless the request accepted them
+		var confirm bool
+		err = chg.Get("confirm-prerequisites", &confirm)
+		if accept {
+			c.Check(err, testutil.ErrorIs, state.ErrNoState)
+		} else {
+			c.Check(err, check.IsNil)
+			c.Check(confirm, check.Equals, true)
+		}
+		st.Unlock()
+	}
+}
+
 func (s *sideloadSuite) TestFormdataIsWrittenToCorrectTmpLocation(c *check.C) {
 	oldTempDir := os.Getenv("TMPDIR")
 	defer func() {
@@ -2277,7 +2538,7 @@ type trySuite struct {
 func (s *trySuite) SetUpTest(c *check.C) {
 	s.apiBaseSuite.SetUpTest(c)
 
-	s.expectWriteAccess(daemon.AuthenticatedAccess{Polkit: "io.snapcraft.snapd.manage"})
+	s.expectWriteAccess(daemon.SnapOpAccess{})
 }
 
 func (s *trySuite) TestTrySnap(c *check.C) {
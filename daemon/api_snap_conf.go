@@ -20,15 +20,20 @@
 package daemon
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/jsonutil"
+	"github.com/snapcore/snapd/osutil/sys"
 	"github.com/snapcore/snapd/overlord/auth"
 	"github.com/snapcore/snapd/overlord/configstate"
 	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/configstate/configcore"
+	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/strutil"
@@ -42,6 +47,15 @@ var (
 		ReadAccess:  authenticatedAccess{Polkit: polkitActionManageConfiguration},
 		WriteAccess: authenticatedAccess{Polkit: polkitActionManageConfiguration},
 	}
+
+	// the configuration of the snap for the user making the request
+	snapUserConfCmd = &Command{
+		Path:        "/v2/snaps/{name}/user-conf",
+		GET:         getSnapUserConf,
+		PUT:         setSnapUserConf,
+		ReadAccess:  openAccess{},
+		WriteAccess: openAccess{},
+	}
 )
 
 func getSnapConf(c *Command, r *http.Request, user *auth.UserState) Response {
@@ -50,6 +64,25 @@ func getSnapConf(c *Command, r *http.Request, user *auth.UserState) Response {
 
 	keys := strutil.CommaSeparatedList(r.URL.Query().Get("keys"))
 
+	if r.URL.Query().Get("diff") == "true" {
+		if len(keys) != 0 {
+			return BadRequest("cannot use keys together with diff")
+		}
+		return getSnapConfDiff(c, snapName)
+	}
+	if r.URL.Query().Get("history") == "true" {
+		if len(keys) != 0 {
+			return BadRequest("cannot use keys together with history")
+		}
+		return getSnapConfHistory(c, snapName)
+	}
+	if r.URL.Query().Get("defaults") == "true" {
+		if len(keys) != 0 {
+			return BadRequest("cannot use keys together with defaults")
+		}
+		return getSnapConfWithDefaults(c, snapName)
+	}
+
 	s := c.d.overlord.State()
 	s.Lock()
 	tr := config.NewTransaction(s)
@@ -98,6 +131,70 @@ func getSnapConf(c *Command, r *http.Request, user *auth.UserState) Response {
 	return SyncResponse(currentConfValues)
 }
 
+// getSnapConfDiff returns the changes between the configuration of the snap
+// before its last refresh and its current configuration.
+func getSnapConfDiff(c *Command, snapName string) Response {
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	var snapst snapstate.SnapState
+	if err := snapstate.Get(st, snapName, &snapst); err != nil {
+		if errors.Is(err, state.ErrNoState) {
+			return SnapNotFound(snapName, err)
+		}
+		return InternalError("%v", err)
+	}
+	idx := snapst.LastIndex(snapst.Current)
+	if idx <= 0 {
+		return BadRequest("snap %q has no previous revision to compare configuration with", snapName)
+	}
+	prev := snapst.Sequence.Revisions[idx-1].Snap.Revision
+
+	changes, err := config.DiffRevisionConfig(st, snapName, prev)
+	if err != nil {
+		return InternalError("%v", err)
+	}
+	if changes == nil {
+		changes = []config.Change{}
+	}
+	return SyncResponse(map[string]interface{}{
+		"revision": prev,
+		"changes":  changes,
+	})
+}
+
+// getSnapConfHistory returns the recorded configuration changes of the snap,
+// oldest first.
+func getSnapConfHistory(c *Command, snapName string) Response {
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	history, err := config.History(st, snapName)
+	if err != nil {
+		return InternalError("%v", err)
+	}
+	if history == nil {
+		history = []config.HistoryEntry{}
+	}
+	return SyncResponse(history)
+}
+
+// getSnapConfWithDefaults returns the current configuration of the snap
+// along with its gadget defaults and the origin of each value.
+func getSnapConfWithDefaults(c *Command, snapName string) Response {
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	values, err := configstate.EffectiveConfig(st, snapName)
+	if err != nil {
+		return InternalError("%v", err)
+	}
+	return SyncResponse(values)
+}
+
 // pruneExperimentalFlags returns a copy of val with unsupported experimental
 // features removed from the experimental configuration. This applies to
 // generic queries, where the key is either an empty string ("") or "experimental".
@@ -146,6 +243,10 @@ func setSnapConf(c *Command, r *http.Request, user *auth.UserState) Response {
 	vars := muxVars(r)
 	snapName := configstate.RemapSnapFromRequest(vars["name"])
 
+	if changeID := r.URL.Query().Get("restore"); changeID != "" {
+		return restoreSnapConf(c, r, snapName, changeID)
+	}
+
 	var patchValues map[string]interface{}
 	if err := jsonutil.DecodeWithNumber(r.Body, &patchValues); err != nil {
 		return BadRequest("cannot decode request body into patch values: %v", err)
@@ -155,19 +256,173 @@ func setSnapConf(c *Command, r *http.Request, user *auth.UserState) Response {
 	st.Lock()
 	defer st.Unlock()
 
+	summary := fmt.Sprintf("Change configuration of %q snap", snapName)
+	return configureSnap(st, r, snapName, patchValues, summary)
+}
+
+// restoreSnapConf sets the options of the snap modified by the given change
+// back to the values they had before it, as recorded in the configuration
+// history of the snap.
+func restoreSnapConf(c *Command, r *http.Request, snapName, changeID string) Response {
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	entry, err := config.HistoryEntryForChange(st, snapName, changeID)
+	if err != nil {
+		return BadRequest("%v", err)
+	}
+
+	summary := fmt.Sprintf("Restore configuration of %q snap changed by change %s", snapName, changeID)
+	return configureSnap(st, r, snapName, entry.RestorePatch(), summary)
+}
+
+// configureSnap creates a change applying the configuration patch to the
+// snap, on behalf of the user making the request.
+func configureSnap(st *state.State, r *http.Request, snapName string, patchValues map[string]interface{}, summary string) Response {
 	taskset, err := configstate.ConfigureInstalled(st, snapName, patchValues, 0)
 	if err != nil {
 		// TODO: just return snap-not-installed instead ?
 		if _, ok := err.(*snap.NotInstalledError); ok {
 			return SnapNotFound(snapName, err)
 		}
+		var verr *config.ValidationError
+		if errors.As(err, &verr) {
+			return BadRequest("%v", err)
+		}
 		return errToResponse(err, []string{snapName}, InternalError, "%v")
 	}
 
-	summary := fmt.Sprintf("Change configuration of %q snap", snapName)
 	change := newChange(st, "configure-snap", summary, []*state.TaskSet{taskset}, []string{snapName})
+	if actor := requestActor(r); actor != "" {
+		configstate.SetChangeActor(change, actor)
+	}
 
 	st.EnsureBefore(0)
 
 	return AsyncResponse(nil, change.ID())
 }
+
+// requestActor returns the name of the system user making the request, or
+// their uid if it cannot be resolved.
+func requestActor(r *http.Request) string {
+	if u, err := systemUserFromRequest(r); err == nil {
+		return u.Username
+	}
+	if uid, err := uidFromRequest(r); err == nil {
+		return fmt.Sprintf("uid %d", uid)
+	}
+	return ""
+}
+
+// maxUserConfRequestSize bounds the size of the requests changing the
+// configuration of a snap for a user, the size of the configuration itself
+// is limited by the config package.
+const maxUserConfRequestSize = 1024 * 1024
+
+// userConfSnapInstalled returns an error response if the given snap is not
+// installed.
+func userConfSnapInstalled(st *state.State, snapName string) Response {
+	var snapst snapstate.SnapState
+	if err := snapstate.Get(st, snapName, &snapst); err != nil {
+		if errors.Is(err, state.ErrNoState) {
+			return SnapNotFound(snapName, err)
+		}
+		return InternalError("%v", err)
+	}
+	return nil
+}
+
+func getSnapUserConf(c *Command, r *http.Request, user *auth.UserState) Response {
+	vars := muxVars(r)
+	snapName := configstate.RemapSnapFromRequest(vars["name"])
+	keys := strutil.CommaSeparatedList(r.URL.Query().Get("keys"))
+
+	uid, err := uidFromRequest(r)
+	if err != nil {
+		return Forbidden("cannot get user configuration: %v", err)
+	}
+
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	if rsp := userConfSnapInstalled(st, snapName); rsp != nil {
+		return rsp
+	}
+
+	// Special case - return root document
+	if len(keys) == 0 {
+		var value interface{}
+		if err := config.GetUserConfig(st, snapName, sys.UserID(uid), "", &value); err != nil {
+			if config.IsNoOption(err) {
+				// no configuration - return empty document
+				return SyncResponse(map[string]interface{}{})
+			}
+			return InternalError("%v", err)
+		}
+		return SyncResponse(value)
+	}
+
+	values := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		if key == "" {
+			return BadRequest("keys contains zero-length string")
+		}
+		var value interface{}
+		if err := config.GetUserConfig(st, snapName, sys.UserID(uid), key, &value); err != nil {
+			if config.IsNoOption(err) {
+				return &apiError{
+					Status:  400,
+					Message: err.Error(),
+					Kind:    client.ErrorKindConfigNoSuchOption,
+					Value:   err,
+				}
+			}
+			return InternalError("%v", err)
+		}
+		values[key] = value
+	}
+	return SyncResponse(values)
+}
+
+func setSnapUserConf(c *Command, r *http.Request, user *auth.UserState) Response {
+	vars := muxVars(r)
+	snapName := configstate.RemapSnapFromRequest(vars["name"])
+
+	if snapName == "core" {
+		return BadRequest("cannot set user configuration of the system")
+	}
+
+	uid, err := uidFromRequest(r)
+	if err != nil {
+		return Forbidden("cannot set user configuration: %v", err)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxUserConfRequestSize+1))
+	if err != nil {
+		return BadRequest("cannot read request body: %v", err)
+	}
+	if len(body) > maxUserConfRequestSize {
+		return BadRequest("cannot set user configuration: request body too large")
+	}
+	var patchValues map[string]interface{}
+	if err := jsonutil.DecodeWithNumber(bytes.NewReader(body), &patchValues); err != nil {
+		return BadRequest("cannot decode request body into patch values: %v", err)
+	}
+
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	if rsp := userConfSnapInstalled(st, snapName); rsp != nil {
+		return rsp
+	}
+
+	// the user configuration is not seen by the configure hook, it is
+	// applied right away
+	if err := config.PatchUserConfig(st, snapName, sys.UserID(uid), patchValues); err != nil {
+		return BadRequest("%v", err)
+	}
+	return SyncResponse(nil)
+}
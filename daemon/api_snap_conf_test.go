@@ -22,6 +22,7 @@ package daemon_test
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -34,6 +35,9 @@ import (
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/configstate/configcore"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/snapstate/snapstatetest"
+	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/testutil"
 )
 
@@ -194,6 +198,130 @@ func (s *snapConfSuite) TestGetConfBadKey(c *check.C) {
 	c.Check(result, check.DeepEquals, map[string]interface{}{"message": `invalid option name: ""`})
 }
 
+func (s *snapConfSuite) TestGetConfDiff(c *check.C) {
+	d := s.daemon(c)
+
+	st := d.Overlord().State()
+	st.Lock()
+	snapstate.Set(st, "test-snap", &snapstate.SnapState{
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "test-snap", Revision: snap.R(1)},
+			{RealName: "test-snap", Revision: snap.R(2)},
+		}),
+		Current: snap.R(2),
+		Active:  true,
+	})
+	tr := config.NewTransaction(st)
+	tr.Set("test-snap", "test-key1", "test-value1")
+	tr.Commit()
+	c.Assert(config.SaveRevisionConfig(st, "test-snap", snap.R(1)), check.IsNil)
+	tr = config.NewTransaction(st)
+	tr.Set("test-snap", "test-key1", "test-value2")
+	tr.Set("test-snap", "test-key2", "test-value3")
+	tr.Commit()
+	st.Unlock()
+
+	req, err := http.NewRequest("GET", "/v2/snaps/test-snap/conf?diff=true", nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil)
+	c.Check(rsp.Result, check.DeepEquals, map[string]interface{}{
+		"revision": snap.R(1),
+		"changes": []config.Change{
+			{Key: "test-key1", Old: "test-value1", New: "test-value2"},
+			{Key: "test-key2", New: "test-value3"},
+		},
+	})
+}
+
+func (s *snapConfSuite) TestGetConfDiffErrors(c *check.C) {
+	d := s.daemon(c)
+
+	req, err := http.NewRequest("GET", "/v2/snaps/test-snap/conf?diff=true", nil)
+	c.Assert(err, check.IsNil)
+	rspe := s.errorReq(c, req, nil)
+	c.Check(rspe.Status, check.Equals, 404)
+
+	st := d.Overlord().State()
+	st.Lock()
+	snapstate.Set(st, "test-snap", &snapstate.SnapState{
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "test-snap", Revision: snap.R(1)},
+		}),
+		Current: snap.R(1),
+		Active:  true,
+	})
+	st.Unlock()
+
+	rspe = s.errorReq(c, req, nil)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Message, check.Equals, `snap "test-snap" has no previous revision to compare configuration with`)
+
+	req, err = http.NewRequest("GET", "/v2/snaps/test-snap/conf?diff=true&keys=foo", nil)
+	c.Assert(err, check.IsNil)
+	rspe = s.errorReq(c, req, nil)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Message, check.Equals, "cannot use keys together with diff")
+}
+
+func (s *snapConfSuite) TestGetConfHistory(c *check.C) {
+	d := s.daemon(c)
+
+	req, err := http.NewRequest("GET", "/v2/snaps/test-snap/conf?history=true", nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil)
+	c.Check(rsp.Result, check.DeepEquals, []config.HistoryEntry{})
+
+	st := d.Overlord().State()
+	st.Lock()
+	tr := config.NewTransaction(st)
+	tr.Set("test-snap", "test-key1", "test-value1")
+	c.Assert(config.RecordingCommit(tr, "test-snap", "42", "configure-snap"), check.IsNil)
+	st.Unlock()
+
+	rsp = s.syncReq(c, req, nil)
+	history, ok := rsp.Result.([]config.HistoryEntry)
+	c.Assert(ok, check.Equals, true)
+	c.Assert(history, check.HasLen, 1)
+	c.Check(history[0].Change, check.Equals, "42")
+	c.Check(history[0].Actor, check.Equals, "configure-snap")
+	c.Check(history[0].Changes, check.DeepEquals, []config.Change{
+		{Key: "test-key1", New: "test-value1"},
+	})
+
+	req, err = http.NewRequest("GET", "/v2/snaps/test-snap/conf?history=true&keys=foo", nil)
+	c.Assert(err, check.IsNil)
+	rspe := s.errorReq(c, req, nil)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Message, check.Equals, "cannot use keys together with history")
+}
+
+func (s *snapConfSuite) TestGetConfWithDefaults(c *check.C) {
+	d := s.daemon(c)
+
+	st := d.Overlord().State()
+	st.Lock()
+	tr := config.NewTransaction(st)
+	tr.Set("test-snap", "test-key1", "test-value1")
+	tr.Set("test-snap", "test-key2", map[string]interface{}{"nested": true})
+	tr.Commit()
+	st.Unlock()
+
+	// without a model there are no gadget defaults
+	req, err := http.NewRequest("GET", "/v2/snaps/test-snap/conf?defaults=true", nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil)
+	c.Check(rsp.Result, check.DeepEquals, []config.EffectiveValue{
+		{Key: "test-key1", Value: "test-value1", Origin: config.OriginExplicit},
+		{Key: "test-key2.nested", Value: true, Origin: config.OriginExplicit},
+	})
+
+	req, err = http.NewRequest("GET", "/v2/snaps/test-snap/conf?defaults=true&keys=foo", nil)
+	c.Assert(err, check.IsNil)
+	rspe := s.errorReq(c, req, nil)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Message, check.Equals, "cannot use keys together with defaults")
+}
+
 const configYaml = `
 name: config-snap
 version: 1
@@ -247,6 +375,68 @@ func (s *snapConfSuite) TestSetConf(c *check.C) {
 	}})
 }
 
+func (s *snapConfSuite) TestSetConfRestore(c *check.C) {
+	d := s.daemon(c)
+	s.mockSnap(c, configYaml)
+
+	hookRunner := testutil.MockCommand(c, "snap", "")
+	defer hookRunner.Restore()
+
+	st := d.Overlord().State()
+	st.Lock()
+	tr := config.NewTransaction(st)
+	tr.Set("config-snap", "a", 1)
+	c.Assert(config.RecordingCommit(tr, "config-snap", "41", "alice"), check.IsNil)
+	tr = config.NewTransaction(st)
+	tr.Set("config-snap", "a", 2)
+	tr.Set("config-snap", "b", "x")
+	c.Assert(config.RecordingCommit(tr, "config-snap", "42", "alice"), check.IsNil)
+	st.Unlock()
+
+	d.Overlord().Loop()
+	defer d.Overlord().Stop()
+
+	req, err := http.NewRequest("PUT", "/v2/snaps/config-snap/conf?restore=42", nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.asyncReq(c, req, nil)
+
+	st.Lock()
+	chg := st.Change(rsp.Change)
+	c.Assert(chg, check.NotNil)
+	c.Check(chg.Summary(), check.Equals, `Restore configuration of "config-snap" snap changed by change 42`)
+	st.Unlock()
+
+	<-chg.Ready()
+
+	st.Lock()
+	defer st.Unlock()
+	c.Assert(chg.Err(), check.IsNil)
+
+	var a int
+	tr = config.NewTransaction(st)
+	c.Assert(tr.Get("config-snap", "a", &a), check.IsNil)
+	c.Check(a, check.Equals, 1)
+	var b string
+	c.Check(config.IsNoOption(tr.Get("config-snap", "b", &b)), check.Equals, true)
+
+	history, err := config.History(st, "config-snap")
+	c.Assert(err, check.IsNil)
+	c.Assert(history, check.HasLen, 3)
+	c.Check(history[2].Change, check.Equals, chg.ID())
+	c.Check(history[2].Actor, check.Equals, "root")
+}
+
+func (s *snapConfSuite) TestSetConfRestoreUnknownChange(c *check.C) {
+	s.daemon(c)
+	s.mockSnap(c, configYaml)
+
+	req, err := http.NewRequest("PUT", "/v2/snaps/config-snap/conf?restore=7", nil)
+	c.Assert(err, check.IsNil)
+	rspe := s.errorReq(c, req, nil)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Message, check.Equals, `no configuration change of snap "config-snap" recorded for change "7"`)
+}
+
 func (s *snapConfSuite) TestSetConfCoreSystemAlias(c *check.C) {
 	d := s.daemon(c)
 	s.mockSnap(c, `
@@ -340,6 +530,25 @@ func (s *snapConfSuite) TestSetConfNumber(c *check.C) {
 	c.Assert(result, check.DeepEquals, json.Number("1234567890"))
 }
 
+func (s *snapConfSuite) TestSetConfSchemaValidation(c *check.C) {
+	s.daemon(c)
+	info := s.mockSnap(c, configYaml)
+
+	schemaPath := filepath.Join(info.MountDir(), "meta", "config-schema.json")
+	c.Assert(os.MkdirAll(filepath.Dir(schemaPath), 0755), check.IsNil)
+	c.Assert(os.WriteFile(schemaPath, []byte(`{"properties": {"key": {"type": "integer"}}}`), 0644), check.IsNil)
+
+	text, err := json.Marshal(map[string]interface{}{"key": "value"})
+	c.Assert(err, check.IsNil)
+
+	req, err := http.NewRequest("PUT", "/v2/snaps/config-snap/conf", bytes.NewBuffer(text))
+	c.Assert(err, check.IsNil)
+
+	rspe := s.errorReq(c, req, nil)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Message, check.Equals, `invalid "config-snap" option "key": must be an integer`)
+}
+
 func (s *snapConfSuite) TestSetConfBadSnap(c *check.C) {
 	s.daemonWithOverlordMockAndStore()
 
@@ -396,8 +605,143 @@ func (s *snapConfSuite) TestSetConfChangeConflict(c *check.C) {
 			"kind":    "snap-change-conflict",
 			"value": map[string]interface{}{
 				"change-kind": "manip",
+				"change-id":   "1",
+				"retry-after": 5.,
 				"snap-name":   "config-snap",
 			},
 		},
 		"type": "error"})
 }
+
+var _ = check.Suite(&snapUserConfSuite{})
+
+type snapUserConfSuite struct {
+	apiBaseSuite
+}
+
+func (s *snapUserConfSuite) SetUpTest(c *check.C) {
+	s.apiBaseSuite.SetUpTest(c)
+
+	s.expectReadAccess(daemon.OpenAccess{})
+	s.expectWriteAccess(daemon.OpenAccess{})
+}
+
+func (s *snapUserConfSuite) runUserConf(c *check.C, method, url string, uid int, body map[string]interface{}) (int, map[string]interface{}) {
+	var buffer bytes.Buffer
+	if body != nil {
+		c.Assert(json.NewEncoder(&buffer).Encode(body), check.IsNil)
+	}
+	req, err := http.NewRequest(method, url, &buffer)
+	c.Assert(err, check.IsNil)
+	req.RemoteAddr = fmt.Sprintf("pid=100;uid=%d;socket=%s;", uid, dirs.SnapdSocket)
+	rec := httptest.NewRecorder()
+	s.req(c, req, nil).ServeHTTP(rec, req)
+
+	var rsp map[string]interface{}
+	c.Assert(json.Unmarshal(rec.Body.Bytes(), &rsp), check.IsNil)
+	return rec.Code, rsp
+}
+
+func (s *snapUserConfSuite) TestSetGetUserConf(c *check.C) {
+	s.daemon(c)
+	s.mockSnap(c, configYaml)
+
+	code, _ := s.runUserConf(c, "PUT", "/v2/snaps/config-snap/user-conf", 1000, map[string]interface{}{
+		"theme":        "dark",
+		"window.width": 800,
+	})
+	c.Check(code, check.Equals, 200)
+	code, _ = s.runUserConf(c, "PUT", "/v2/snaps/config-snap/user-conf", 1001, map[string]interface{}{
+		"theme": "light",
+	})
+	c.Check(code, check.Equals, 200)
+
+	code, rsp := s.runUserConf(c, "GET", "/v2/snaps/config-snap/user-conf?keys=theme,window.width", 1000, nil)
+	c.Check(code, check.Equals, 200)
+	c.Check(rsp["result"], check.DeepEquals, map[string]interface{}{"theme": "dark", "window.width": 800.0})
+
+	code, rsp = s.runUserConf(c, "GET", "/v2/snaps/config-snap/user-conf", 1001, nil)
+	c.Check(code, check.Equals, 200)
+	c.Check(rsp["result"], check.DeepEquals, map[string]interface{}{"theme": "light"})
+
+	// no configure hook is run
+	st := s.d.Overlord().State()
+	st.Lock()
+	defer st.Unlock()
+	c.Check(st.Changes(), check.HasLen, 0)
+	// and the system configuration is untouched
+	var value interface{}
+	err := config.NewTransaction(st).Get("config-snap", "theme", &value)
+	c.Check(config.IsNoOption(err), check.Equals, true)
+}
+
+func (s *snapUserConfSuite) TestGetUserConfNone(c *check.C) {
+	s.daemon(c)
+	s.mockSnap(c, configYaml)
+
+	code, rsp := s.runUserConf(c, "GET", "/v2/snaps/config-snap/user-conf", 1000, nil)
+	c.Check(code, check.Equals, 200)
+	c.Check(rsp["result"], check.DeepEquals, map[string]interface{}{})
+
+	code, rsp = s.runUserConf(c, "GET", "/v2/snaps/config-snap/user-conf?keys=theme", 1000, nil)
+	c.Check(code, check.Equals, 400)
+	c.Check(rsp["result"], check.DeepEquals, map[string]interface{}{
+		"value": map[string]interface{}{
+			"SnapName": "config-snap",
+			"Key":      "theme",
+		},
+		"message": `snap "config-snap" has no "theme" configuration option`,
+		"kind":    "option-not-found",
+	})
+}
+
+func (s *snapUserConfSuite) TestGetUserConfSnapNotFound(c *check.C) {
+	s.daemon(c)
+
+	code, rsp := s.runUserConf(c, "GET", "/v2/snaps/config-snap/user-conf", 1000, nil)
+	c.Check(code, check.Equals, 404)
+	c.Check(rsp["result"].(map[string]interface{})["kind"], check.Equals, "snap-not-found")
+}
+
+func (s *snapUserConfSuite) TestSetUserConfTooLarge(c *check.C) {
+	s.daemon(c)
+	s.mockSnap(c, configYaml)
+
+	// the configuration of each user is limited
+	code, rsp := s.runUserConf(c, "PUT", "/v2/snaps/config-snap/user-conf", 1000, map[string]interface{}{
+		"theme": strings.Repeat("x", 128*1024),
+	})
+	c.Check(code, check.Equals, 400)
+	c.Check(rsp["result"].(map[string]interface{})["message"], check.Equals, `cannot set snap "config-snap" user configuration: configuration would exceed the limit of 65536 bytes`)
+
+	// and so are the requests
+	code, rsp = s.runUserConf(c, "PUT", "/v2/snaps/config-snap/user-conf", 1000, map[string]interface{}{
+		"theme": strings.Repeat("x", 2*1024*1024),
+	})
+	c.Check(code, check.Equals, 400)
+	c.Check(rsp["result"].(map[string]interface{})["message"], check.Equals, "cannot set user configuration: request body too large")
+
+	st := s.d.Overlord().State()
+	st.Lock()
+	defer st.Unlock()
+	var value interface{}
+	err := config.GetUserConfig(st, "config-snap", 1000, "theme", &value)
+	c.Check(config.IsNoOption(err), check.Equals, true)
+}
+
+func (s *snapUserConfSuite) TestSetUserConfErrors(c *check.C) {
+	s.daemon(c)
+
+	code, rsp := s.runUserConf(c, "PUT", "/v2/snaps/config-snap/user-conf", 1000, map[string]interface{}{"theme": "dark"})
+	c.Check(code, check.Equals, 404)
+	c.Check(rsp["result"].(map[string]interface{})["kind"], check.Equals, "snap-not-found")
+
+	code, rsp = s.runUserConf(c, "PUT", "/v2/snaps/system/user-conf", 1000, map[string]interface{}{"theme": "dark"})
+	c.Check(code, check.Equals, 400)
+	c.Check(rsp["result"].(map[string]interface{})["message"], check.Equals, "cannot set user configuration of the system")
+
+	s.mockSnap(c, configYaml)
+	code, rsp = s.runUserConf(c, "PUT", "/v2/snaps/config-snap/user-conf", 1000, map[string]interface{}{"Bad": "dark"})
+	c.Check(code, check.Equals, 400)
+	c.Check(rsp["result"].(map[string]interface{})["message"], check.Equals, `invalid option name: "Bad"`)
+}
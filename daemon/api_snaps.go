@@ -26,15 +26,18 @@ import (
 	"fmt"
 	"mime"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/snapcore/snapd/asserts/snapasserts"
+	"github.com/snapcore/snapd/boot"
 	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/i18n"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/overlord/assertstate"
 	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/servicestate"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
@@ -42,6 +45,7 @@ import (
 	"github.com/snapcore/snapd/sandbox"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/channel"
+	"github.com/snapcore/snapd/store"
 	"github.com/snapcore/snapd/strutil"
 )
 
@@ -52,7 +56,7 @@ var (
 		GET:         getSnapInfo,
 		POST:        postSnap,
 		ReadAccess:  interfaceOpenAccess{Interfaces: []string{"snap-interfaces-requests-control", "snap-refresh-observe"}},
-		WriteAccess: authenticatedAccess{Polkit: polkitActionManage},
+		WriteAccess: snapOpAccess{},
 	}
 
 	snapsCmd = &Command{
@@ -60,7 +64,7 @@ var (
 		GET:         getSnapsInfo,
 		POST:        postSnaps,
 		ReadAccess:  interfaceOpenAccess{Interfaces: []string{"snap-refresh-observe"}},
-		WriteAccess: authenticatedAccess{Polkit: polkitActionManage},
+		WriteAccess: snapOpAccess{},
 	}
 )
 
@@ -90,9 +94,11 @@ func getSnapInfo(c *Command, r *http.Request, user *auth.UserState) Response {
 
 	sd := servicestate.NewStatusDecorator(progress.Null)
 
-	result := webify(mapLocal(about, sd), url.String())
+	result := mapLocal(about, sd)
+	fillPrivateTmpUsage(result, about.info.InstanceName())
+	fillBuildProvenance(result, about.info)
 
-	return SyncResponse(result)
+	return SyncResponse(webify(result, url.String()))
 }
 
 func webify(result *client.Snap, resource string) *client.Snap {
@@ -153,13 +159,40 @@ func postSnap(c *Command, r *http.Request, user *auth.UserState) Response {
 
 	res, err := impl(r.Context(), &inst, st)
 	if err != nil {
+		var conflErr *snapstate.ChangeConflictError
+		if inst.Queue && errors.As(err, &conflErr) && conflErr.ChangeID != "" {
+			chg := queueSnapOp(st, &inst, conflErr.ChangeID)
+			setRequesterUID(chg, r.RemoteAddr)
+			ensureStateSoon(st)
+			return AsyncResponse(nil, chg.ID())
+		}
 		return inst.errToResponse(err)
 	}
 
+	if inst.DryRun {
+		return planResponse(st, res)
+	}
+
+	prereqs, err := prerequisitesToConfirm(st, &inst, res)
+	if err != nil {
+		discardTasksets(st, res.Tasksets)
+		return InternalError("cannot check prerequisites: %v", err)
+	}
+	if len(prereqs) > 0 && !inst.AcceptPrerequisites {
+		discardTasksets(st, res.Tasksets)
+		return PrerequisitesConfirmationRequired(res.Affected, prereqs)
+	}
+
 	chg := newChange(st, inst.Action+"-snap", res.Summary, res.Tasksets, res.Affected)
 	if len(res.Tasksets) == 0 {
 		chg.SetStatus(state.DoneStatus)
 	}
+	setRequesterUID(chg, r.RemoteAddr)
+	if inst.Action == "install" || inst.Action == "refresh" {
+		if err := markPrerequisitesConfirmation(st, chg, inst.AcceptPrerequisites); err != nil {
+			return InternalError("cannot check prerequisites: %v", err)
+		}
+	}
 
 	if inst.SystemRestartImmediate {
 		chg.Set("system-restart-immediate", true)
@@ -172,6 +205,11 @@ func postSnap(c *Command, r *http.Request, user *auth.UserState) Response {
 	if len(res.AffectedComponents) > 0 {
 		apiData["components"] = res.AffectedComponents
 	}
+	if len(prereqs) > 0 {
+		// record that the installation of the prerequisites
+		// was confirmed
+		apiData["accepted-prerequisites"] = prereqs
+	}
 
 	chg.Set("api-data", apiData)
 
@@ -180,6 +218,79 @@ func postSnap(c *Command, r *http.Request, user *auth.UserState) Response {
 	return AsyncResponse(nil, chg.ID())
 }
 
+func init() {
+	snapstate.QueuedSnapOpTasks = queuedSnapOpTasks
+}
+
+// queueSnapOp creates a change that runs the given single-snap instruction
+// once the change with the given ID, which it conflicts with, and any
+// operations queued before it for the same snap are done.
+func queueSnapOp(st *state.State, inst *snapInstruction, conflictChangeID string) *state.Change {
+	snapName := inst.Snaps[0]
+	summary := fmt.Sprintf(i18n.G("Queued %q of %q snap after change %s"), inst.Action, snapName, conflictChangeID)
+	t := snapstate.NewQueuedSnapOpTask(st, snapName)
+	t.Set("snap-instruction", inst)
+	t.Set("user-id", inst.userID)
+	if len(inst.CompsForSnaps) > 0 {
+		t.Set("components", inst.CompsForSnaps)
+	}
+
+	chg := newChange(st, inst.Action+"-snap", summary, []*state.TaskSet{state.NewTaskSet(t)}, []string{snapName})
+	chg.Set("api-data", map[string]interface{}{"snap-names": []string{snapName}})
+	return chg
+}
+
+// queuedSnapOpTasks returns the tasks of the snap instruction recorded on the
+// given queued-snap-op task, see snapstate.QueuedSnapOpTasks.
+func queuedSnapOpTasks(ctx context.Context, t *state.Task) ([]*state.TaskSet, error) {
+	st := t.State()
+
+	var inst snapInstruction
+	if err := t.Get("snap-instruction", &inst); err != nil {
+		return nil, err
+	}
+	if err := t.Get("user-id", &inst.userID); err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, err
+	}
+	if err := t.Get("components", &inst.CompsForSnaps); err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, err
+	}
+
+	impl := inst.dispatch()
+	if impl == nil {
+		return nil, fmt.Errorf("unknown action %s", inst.Action)
+	}
+	res, err := impl(ctx, &inst, st)
+	if err != nil {
+		return nil, err
+	}
+
+	prereqs, err := prerequisitesToConfirm(st, &inst, res)
+	if err != nil {
+		discardTasksets(st, res.Tasksets)
+		return nil, fmt.Errorf("cannot check prerequisites: %v", err)
+	}
+	if len(prereqs) > 0 && !inst.AcceptPrerequisites {
+		discardTasksets(st, res.Tasksets)
+		return nil, fmt.Errorf("cannot %s %q: installation of prerequisites %s requires confirmation", inst.Action, inst.Snaps[0], strutil.Quoted(prereqs))
+	}
+
+	chg := t.Change()
+	if len(res.Affected) > 0 {
+		chg.Set("snap-names", res.Affected)
+	}
+	if inst.Action == "install" || inst.Action == "refresh" {
+		if err := markPrerequisitesConfirmation(st, chg, inst.AcceptPrerequisites); err != nil {
+			discardTasksets(st, res.Tasksets)
+			return nil, fmt.Errorf("cannot check prerequisites: %v", err)
+		}
+	}
+	if inst.SystemRestartImmediate {
+		chg.Set("system-restart-immediate", true)
+	}
+	return res.Tasksets, nil
+}
+
 type snapRevisionOptions struct {
 	Channel  string        `json:"channel"`
 	Revision snap.Revision `json:"revision"`
@@ -233,6 +344,11 @@ type snapInstruction struct {
 	QuotaGroupName         string                           `json:"quota-group"`
 	Time                   string                           `json:"time"`
 	HoldLevel              string                           `json:"hold-level"`
+	DryRun                 bool                             `json:"dry-run"`
+	AcceptPrerequisites    bool                             `json:"accept-prerequisites"`
+	// Queue requests the operation to run once a conflicting change
+	// completes, instead of failing with a change conflict error.
+	Queue bool `json:"queue"`
 
 	// The fields below should not be unmarshalled into. Do not export them.
 	userID int
@@ -395,10 +511,26 @@ func (inst *snapInstruction) validate() error {
 	if inst.Unaliased && inst.Prefer {
 		return errUnaliasedPreferConflict
 	}
+
+	if inst.Queue && inst.DryRun {
+		return errors.New("cannot queue a dry-run")
+	}
 	if inst.Prefer && inst.Action != "install" {
 		return fmt.Errorf("the prefer flag can only be specified on install")
 	}
 
+	if inst.DryRun {
+		switch inst.Action {
+		case "install", "refresh", "remove":
+		default:
+			return fmt.Errorf("dry-run can only be specified for install, refresh or remove")
+		}
+	}
+
+	if inst.AcceptPrerequisites && inst.Action != "install" && inst.Action != "refresh" {
+		return fmt.Errorf("accept-prerequisites can only be specified for install or refresh")
+	}
+
 	if inst.Terminate && inst.Action != "remove" {
 		return fmt.Errorf(`terminate can only be specified for the "remove" action`)
 	}
@@ -433,6 +565,165 @@ type snapInstructionResult struct {
 	Result             map[string]interface{}
 }
 
+// snapOpPlan describes what a snap operation would do, it is returned
+// instead of a change for dry-run requests.
+type snapOpPlan struct {
+	Summary        string        `json:"summary"`
+	SnapNames      []string      `json:"snap-names,omitempty"`
+	Tasks          []plannedTask `json:"tasks"`
+	Prerequisites  []string      `json:"prerequisites,omitempty"`
+	RebootRequired bool          `json:"reboot-required,omitempty"`
+	DownloadSize   int64         `json:"download-size,omitempty"`
+}
+
+type plannedTask struct {
+	Kind    string `json:"kind"`
+	Summary string `json:"summary"`
+	Snap    string `json:"snap,omitempty"`
+}
+
+// planResponse describes the task sets of the result of a snap operation
+// without creating a change for them. The tasks are discarded afterwards.
+func planResponse(st *state.State, res *snapInstructionResult) Response {
+	defer discardTasksets(st, res.Tasksets)
+	plan, err := newSnapOpPlan(st, res)
+	if err != nil {
+		return InternalError("%v", err)
+	}
+	return SyncResponse(*plan)
+}
+
+// discardTasksets removes the tasks of task sets that are not carried out
+// from the state, e.g. when the operation needs to be confirmed first.
+func discardTasksets(st *state.State, tss []*state.TaskSet) {
+	for _, ts := range tss {
+		st.DiscardUnlinkedTasks(ts.Tasks())
+	}
+}
+
+// prerequisitesToConfirm returns the snaps that would be installed as
+// prerequisites by the result of an install or refresh, if the system is
+// configured to ask for confirmation before installing them.
+func prerequisitesToConfirm(st *state.State, inst *snapInstruction, res *snapInstructionResult) ([]string, error) {
+	if inst.Action != "install" && inst.Action != "refresh" {
+		return nil, nil
+	}
+
+	confirm, err := prerequisitesConfirmationEnabled(st)
+	if err != nil || !confirm {
+		return nil, err
+	}
+
+	plan, err := newSnapOpPlan(st, res)
+	if err != nil {
+		return nil, err
+	}
+	return plan.Prerequisites, nil
+}
+
+func prerequisitesConfirmationEnabled(st *state.State) (bool, error) {
+	var confirm bool
+	tr := config.NewTransaction(st)
+	if err := tr.Get("core", "prerequisites.confirm", &confirm); err != nil && !config.IsNoOption(err) {
+		return false, err
+	}
+	return confirm, nil
+}
+
+// markPrerequisitesConfirmation records in the change whether the
+// prerequisites it installs still need to be confirmed, so that the
+// prerequisites task handler refuses to install the ones that are only
+// found when the change runs.
+func markPrerequisitesConfirmation(st *state.State, chg *state.Change, accepted bool) error {
+	if accepted {
+		return nil
+	}
+	confirm, err := prerequisitesConfirmationEnabled(st)
+	if err != nil {
+		return err
+	}
+	if confirm {
+		chg.Set("confirm-prerequisites", true)
+	}
+	return nil
+}
+
+func newSnapOpPlan(st *state.State, res *snapInstructionResult) (*snapOpPlan, error) {
+	plan := &snapOpPlan{
+		Summary:   res.Summary,
+		SnapNames: res.Affected,
+		Tasks:     []plannedTask{},
+	}
+
+	// a missing device context means there is no model yet, in
+	// which case no snap participates in the boot
+	deviceCtx, _ := snapstate.DeviceCtxFromState(st, nil)
+
+	// the tasks are not part of a change, so the task holding the
+	// snap-setup of another one cannot be looked up in the state
+	tasksByID := make(map[string]*state.Task)
+	for _, ts := range res.Tasksets {
+		for _, t := range ts.Tasks() {
+			tasksByID[t.ID()] = t
+		}
+	}
+
+	prereqs := make(map[string]bool)
+	for _, ts := range res.Tasksets {
+		for _, t := range ts.Tasks() {
+			pt := plannedTask{Kind: t.Kind(), Summary: t.Summary()}
+			setupTask := t
+			var id string
+			if t.Get("snap-setup-task", &id) == nil && tasksByID[id] != nil {
+				setupTask = tasksByID[id]
+			}
+			var snapsup snapstate.SnapSetup
+			if err := setupTask.Get("snap-setup", &snapsup); err != nil {
+				plan.Tasks = append(plan.Tasks, pt)
+				continue
+			}
+			pt.Snap = snapsup.InstanceName()
+			plan.Tasks = append(plan.Tasks, pt)
+
+			switch pt.Kind {
+			case "prerequisites":
+				if snapsup.Base != "" && snapsup.Base != "none" {
+					prereqs[snapsup.Base] = true
+				}
+				for _, name := range snapsup.Prereq {
+					prereqs[name] = true
+				}
+			case "download-snap":
+				if snapsup.DownloadInfo != nil {
+					plan.DownloadSize += snapsup.DownloadInfo.Size
+				}
+			case "link-snap":
+				if deviceCtx != nil && boot.SnapTypeParticipatesInBoot(snapsup.Type, deviceCtx) {
+					if snapsup.Type != snap.TypeBase || deviceCtx.Base() == pt.Snap {
+						plan.RebootRequired = true
+					}
+				}
+			}
+		}
+	}
+
+	for name := range prereqs {
+		if strutil.ListContains(res.Affected, name) {
+			continue
+		}
+		var snapst snapstate.SnapState
+		if err := snapstate.Get(st, name, &snapst); err == nil && snapst.IsInstalled() {
+			continue
+		} else if err != nil && !errors.Is(err, state.ErrNoState) {
+			return nil, fmt.Errorf("cannot get state of snap %q: %v", name, err)
+		}
+		plan.Prerequisites = append(plan.Prerequisites, name)
+	}
+	sort.Strings(plan.Prerequisites)
+
+	return plan, nil
+}
+
 var errDevJailModeConflict = errors.New("cannot use devmode and jailmode flags together")
 var errClassicDevmodeConflict = errors.New("cannot use classic and devmode flags together")
 var errUnaliasedPreferConflict = errors.New("cannot use unaliased and prefer flags together")
@@ -577,6 +868,11 @@ func snapUpdate(ctx context.Context, inst *snapInstruction, st *state.State) (*s
 		Flags:  flags,
 		UserID: inst.userID,
 	})
+	if err == store.ErrNoUpdateAvailable && !flags.IgnoreValidation {
+		if heldErr := heldByValidationSets(st, inst.Snaps[0]); heldErr != nil {
+			return nil, heldErr
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -774,7 +1070,7 @@ func postSnaps(c *Command, r *http.Request, user *auth.UserState) Response {
 		return BadRequest("unknown content type: %s", contentType)
 	}
 
-	return sideloadOrTrySnap(r.Context(), c, r.Body, params["boundary"], user)
+	return sideloadOrTrySnap(r.Context(), c, r.Body, params["boundary"], r.RemoteAddr, user)
 }
 
 func snapOpMany(c *Command, r *http.Request, user *auth.UserState) Response {
@@ -790,7 +1086,7 @@ func snapOpMany(c *Command, r *http.Request, user *auth.UserState) Response {
 	}
 
 	// TODO: inst.Amend, etc?
-	if inst.Channel != "" || !inst.Revision.Unset() || inst.DevMode || inst.JailMode || inst.CohortKey != "" || inst.LeaveCohort || inst.Prefer {
+	if inst.Channel != "" || !inst.Revision.Unset() || inst.DevMode || inst.JailMode || inst.CohortKey != "" || inst.LeaveCohort || inst.Prefer || inst.Queue {
 		return BadRequest("unsupported option provided for multi-snap operation")
 	}
 	if len(inst.CompsRaw) > 0 {
@@ -822,10 +1118,30 @@ func snapOpMany(c *Command, r *http.Request, user *auth.UserState) Response {
 		return inst.errToResponse(err)
 	}
 
+	if inst.DryRun {
+		return planResponse(st, res)
+	}
+
+	prereqs, err := prerequisitesToConfirm(st, &inst, res)
+	if err != nil {
+		discardTasksets(st, res.Tasksets)
+		return InternalError("cannot check prerequisites: %v", err)
+	}
+	if len(prereqs) > 0 && !inst.AcceptPrerequisites {
+		discardTasksets(st, res.Tasksets)
+		return PrerequisitesConfirmationRequired(res.Affected, prereqs)
+	}
+
 	chg := newChange(st, inst.Action+"-snap", res.Summary, res.Tasksets, res.Affected)
 	if len(res.Tasksets) == 0 {
 		chg.SetStatus(state.DoneStatus)
 	}
+	setRequesterUID(chg, r.RemoteAddr)
+	if inst.Action == "install" || inst.Action == "refresh" {
+		if err := markPrerequisitesConfirmation(st, chg, inst.AcceptPrerequisites); err != nil {
+			return InternalError("cannot check prerequisites: %v", err)
+		}
+	}
 
 	if inst.SystemRestartImmediate {
 		chg.Set("system-restart-immediate", true)
@@ -838,6 +1154,11 @@ func snapOpMany(c *Command, r *http.Request, user *auth.UserState) Response {
 	if len(res.AffectedComponents) > 0 {
 		apiData["components"] = res.AffectedComponents
 	}
+	if len(prereqs) > 0 {
+		// record that the installation of the prerequisites
+		// was confirmed
+		apiData["accepted-prerequisites"] = prereqs
+	}
 
 	chg.Set("api-data", apiData)
 
@@ -1211,6 +1532,8 @@ func getSnapsInfo(c *Command, r *http.Request, user *auth.UserState) Response {
 		sel = snapSelectEnabled
 	case "refresh-inhibited":
 		sel = snapSelectRefreshInhibited
+	case "refresh-available":
+		sel = snapSelectRefreshAvailable
 	default:
 		return BadRequest("invalid select parameter: %q", sel)
 	}
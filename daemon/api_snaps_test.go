@@ -42,8 +42,10 @@ import (
 	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/daemon"
 	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/gadget/quantity"
 	"github.com/snapcore/snapd/overlord/assertstate"
 	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/healthstate"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/snapstate/sequence"
@@ -68,7 +70,7 @@ var _ = check.Suite(&snapsSuite{})
 func (s *snapsSuite) SetUpTest(c *check.C) {
 	s.apiBaseSuite.SetUpTest(c)
 
-	s.expectWriteAccess(daemon.AuthenticatedAccess{Polkit: "io.snapcraft.snapd.manage"})
+	s.expectWriteAccess(daemon.SnapOpAccess{})
 }
 
 func (s *snapsSuite) expectSnapsReadAccess() {
@@ -575,6 +577,106 @@ func (s *snapsSuite) TestPostSnapsRemoveWithTerminate(c *check.C) {
 	c.Assert(snapstateRemoveCalled, check.Equals, 1)
 }
 
+func (s *snapsSuite) TestPostSnapQueueOnConflict(c *check.C) {
+	d := s.daemonWithOverlordMockAndStore()
+
+	st := d.Overlord().State()
+	st.Lock()
+	conflicting := st.NewChange("refresh-snap", "...")
+	conflictingTask := st.NewTask("fake-refresh", "...")
+	conflicting.AddTask(conflictingTask)
+	st.Unlock()
+
+	var snapstateRemoveCalled int
+	defer daemon.MockSnapstateRemove(func(st *state.State, name string, revision snap.Revision, flags *snapstate.RemoveFlags) (*state.TaskSet, error) {
+		snapstateRemoveCalled++
+		c.Check(name, check.Equals, "foo")
+		c.Check(flags.Purge, check.Equals, true)
+		if !conflicting.IsReady() {
+			return nil, &snapstate.ChangeConflictError{Snap: "foo", ChangeKind: "refresh", ChangeID: conflicting.ID()}
+		}
+		t := st.NewTask("fake-remove", "Remove one")
+		return state.NewTaskSet(t), nil
+	})()
+
+	buf := strings.NewReader(`{"action": "remove", "purge": true, "queue": true}`)
+	req, err := http.NewRequest("POST", "/v2/snaps/foo", buf)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", "application/json")
+
+	rsp := s.jsonReq(c, req, nil)
+	c.Check(rsp.Status, check.Equals, 202)
+	c.Check(snapstateRemoveCalled, check.Equals, 1)
+
+	st.Lock()
+	chg := st.Change(rsp.Change)
+	c.Assert(chg, check.NotNil)
+	c.Check(chg.Kind(), check.Equals, "remove-snap")
+	c.Check(chg.Summary(), check.Equals, fmt.Sprintf(`Queued "remove" of "foo" snap after change %s`, conflicting.ID()))
+	var names []string
+	c.Assert(chg.Get("snap-names", &names), check.IsNil)
+	c.Check(names, check.DeepEquals, []string{"foo"})
+	c.Assert(chg.Tasks(), check.HasLen, 1)
+	queued := chg.Tasks()[0]
+	c.Check(queued.Kind(), check.Equals, "queued-snap-op")
+	st.Unlock()
+
+	// the operation still conflicts while the other change is in progress
+	st.Lock()
+	_, err = daemon.QueuedSnapOpTasks(context.Background(), queued)
+	st.Unlock()
+	c.Check(err, check.FitsTypeOf, &snapstate.ChangeConflictError{})
+	c.Check(snapstateRemoveCalled, check.Equals, 2)
+
+	st.Lock()
+	defer st.Unlock()
+	conflictingTask.SetStatus(state.DoneStatus)
+
+	tss, err := daemon.QueuedSnapOpTasks(context.Background(), queued)
+	c.Assert(err, check.IsNil)
+	c.Check(snapstateRemoveCalled, check.Equals, 3)
+	c.Assert(tss, check.HasLen, 1)
+	c.Assert(tss[0].Tasks(), check.HasLen, 1)
+	c.Check(tss[0].Tasks()[0].Kind(), check.Equals, "fake-remove")
+}
+
+func (s *snapsSuite) TestPostSnapQueueConflictWithoutChange(c *check.C) {
+	s.daemonWithOverlordMockAndStore()
+
+	defer daemon.MockSnapstateRemove(func(st *state.State, name string, revision snap.Revision, flags *snapstate.RemoveFlags) (*state.TaskSet, error) {
+		// there is no change to wait for
+		return nil, &snapstate.ChangeConflictError{Snap: "foo", ChangeKind: "some-global-op"}
+	})()
+
+	buf := strings.NewReader(`{"action": "remove", "queue": true}`)
+	req, err := http.NewRequest("POST", "/v2/snaps/foo", buf)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", "application/json")
+
+	rspe := s.errorReq(c, req, nil)
+	c.Check(rspe.Status, check.Equals, 409)
+	c.Check(rspe.Kind, check.Equals, client.ErrorKindSnapChangeConflict)
+}
+
+func (s *snapsSuite) TestPostSnapQueueErrors(c *check.C) {
+	s.daemonWithOverlordMockAndStore()
+
+	for _, tc := range []struct {
+		path, body, err string
+	}{
+		{"/v2/snaps/foo", `{"action": "remove", "queue": true, "dry-run": true}`, "cannot queue a dry-run"},
+		{"/v2/snaps", `{"action": "remove", "snaps": ["foo"], "queue": true}`, "unsupported option provided for multi-snap operation"},
+	} {
+		req, err := http.NewRequest("POST", tc.path, strings.NewReader(tc.body))
+		c.Assert(err, check.IsNil)
+		req.Header.Set("Content-Type", "application/json")
+
+		rspe := s.errorReq(c, req, nil)
+		c.Check(rspe.Status, check.Equals, 400)
+		c.Check(rspe.Message, check.Equals, tc.err)
+	}
+}
+
 func (s *snapsSuite) TestPostSnapsRemoveManyWithTerminate(c *check.C) {
 	d := s.daemonWithOverlordMockAndStore()
 
@@ -1235,6 +1337,7 @@ UnitFileState=enabled
 			Icon:        "/v2/icons/foo/icon",
 			Type:        string(snap.TypeApp),
 			Base:        "base18",
+			Epoch:       snap.E("0"),
 			Private:     false,
 			DevMode:     false,
 			JailMode:    false,
@@ -1585,6 +1688,137 @@ func (s *snapsSuite) TestSnapManyInfosSelectRefreshInhibited(c *check.C) {
 	}
 }
 
+func (s *snapsSuite) TestSnapManyInfosSelectRefreshAvailable(c *check.C) {
+	s.expectSnapsReadAccess()
+	d := s.daemon(c)
+	s.mkInstalledInState(c, d, "snap-a", "bar", "v0", snap.R(5), true, "")
+	s.mkInstalledInState(c, d, "snap-b", "bar", "v0", snap.R(5), true, "")
+
+	st := d.Overlord().State()
+	st.Lock()
+	// refresh candidates as recorded by the last refresh hints check
+	st.Set("refresh-candidates", map[string]*snapstate.SnapSetup{
+		"snap-a": {
+			SideInfo:     &snap.SideInfo{RealName: "snap-a", Revision: snap.R(7)},
+			Version:      "v1",
+			Channel:      "stable",
+			DownloadInfo: &snap.DownloadInfo{Size: 4096},
+		},
+	})
+	st.Unlock()
+
+	req, err := http.NewRequest("GET", "/v2/snaps?select=refresh-available", nil)
+	c.Assert(err, check.IsNil)
+
+	rsp := s.jsonReq(c, req, nil)
+	snaps := snapList(rsp.Result)
+	c.Assert(snaps, check.HasLen, 1)
+	c.Check(snaps[0]["name"], check.Equals, "snap-a")
+	c.Check(snaps[0]["refresh-available"], check.DeepEquals, map[string]interface{}{
+		"revision":      "7",
+		"version":       "v1",
+		"channel":       "stable",
+		"download-size": 4096.,
+	})
+
+	// no store roundtrip was needed
+	c.Check(s.storeSearch, check.DeepEquals, store.Search{})
+}
+
+func (s *snapsSuite) TestSnapInfoReturnsRefreshAvailable(c *check.C) {
+	s.expectSnapsNameReadAccess()
+	d := s.daemon(c)
+	s.mkInstalledInState(c, d, "foo", "bar", "v0", snap.R(5), true, "")
+
+	st := d.Overlord().State()
+	st.Lock()
+	st.Set("refresh-candidates", map[string]*snapstate.SnapSetup{
+		"foo": {
+			SideInfo: &snap.SideInfo{RealName: "foo", Revision: snap.R(6)},
+			Version:  "v1",
+		},
+	})
+	st.Unlock()
+
+	req, err := http.NewRequest("GET", "/v2/snaps/foo", nil)
+	c.Assert(err, check.IsNil)
+
+	rsp := s.syncReq(c, req, nil)
+
+	c.Assert(rsp.Result, check.FitsTypeOf, &client.Snap{})
+	snapInfo := rsp.Result.(*client.Snap)
+	c.Check(snapInfo.RefreshAvailable, check.DeepEquals, &client.SnapRefreshAvailable{
+		Revision: snap.R(6),
+		Version:  "v1",
+	})
+}
+
+func (s *snapsSuite) TestSnapInfoReturnsPrivateTmpUsage(c *check.C) {
+	s.expectSnapsNameReadAccess()
+	d := s.daemon(c)
+	s.mkInstalledInState(c, d, "foo", "bar", "v0", snap.R(5), true, "")
+
+	var queried []string
+	restore := daemon.MockPrivateTmpUsage(func(instanceName string) (used, size quantity.Size, err error) {
+		queried = append(queried, instanceName)
+		return 1024, 4096, nil
+	})
+	defer restore()
+
+	req, err := http.NewRequest("GET", "/v2/snaps/foo", nil)
+	c.Assert(err, check.IsNil)
+
+	rsp := s.syncReq(c, req, nil)
+
+	c.Assert(rsp.Result, check.FitsTypeOf, &client.Snap{})
+	snapInfo := rsp.Result.(*client.Snap)
+	c.Check(snapInfo.TmpUsage, check.Equals, int64(1024))
+	c.Check(snapInfo.TmpSize, check.Equals, int64(4096))
+	c.Check(queried, check.DeepEquals, []string{"foo"})
+}
+
+func (s *snapsSuite) TestSnapInfoReturnsBuildProvenance(c *check.C) {
+	s.expectSnapsNameReadAccess()
+	d := s.daemon(c)
+	info := s.mkInstalledInState(c, d, "foo", "bar", "v0", snap.R(5), true, "")
+
+	req, err := http.NewRequest("GET", "/v2/snaps/foo", nil)
+	c.Assert(err, check.IsNil)
+
+	// no document shipped in the snap
+	rsp := s.syncReq(c, req, nil)
+	c.Assert(rsp.Result, check.FitsTypeOf, &client.Snap{})
+	c.Check(rsp.Result.(*client.Snap).BuildProvenance, check.Equals, "")
+
+	doc := `{"predicateType": "https://slsa.dev/provenance/v1"}`
+	c.Assert(os.WriteFile(filepath.Join(info.MountDir(), snap.BuildProvenancePath), []byte(doc), 0644), check.IsNil)
+
+	rsp = s.syncReq(c, req, nil)
+	c.Assert(rsp.Result, check.FitsTypeOf, &client.Snap{})
+	c.Check(rsp.Result.(*client.Snap).BuildProvenance, check.Equals, doc)
+}
+
+func (s *snapsSuite) TestSnapsInfoNoPrivateTmpUsage(c *check.C) {
+	s.expectSnapsReadAccess()
+	d := s.daemon(c)
+	s.mkInstalledInState(c, d, "foo", "bar", "v0", snap.R(5), true, "")
+
+	restore := daemon.MockPrivateTmpUsage(func(instanceName string) (used, size quantity.Size, err error) {
+		c.Errorf("unexpected private /tmp usage query for %q", instanceName)
+		return 0, 0, nil
+	})
+	defer restore()
+
+	req, err := http.NewRequest("GET", "/v2/snaps", nil)
+	c.Assert(err, check.IsNil)
+
+	rsp := s.syncReq(c, req, nil)
+
+	snaps := snapList(rsp.Result)
+	c.Assert(snaps, check.HasLen, 1)
+	c.Check(snaps[0]["tmp-usage"], check.IsNil)
+}
+
 func (s *snapsSuite) TestSnapInfoReturnsRefreshFailures(c *check.C) {
 	s.expectSnapsNameReadAccess()
 	d := s.daemon(c)
@@ -2045,6 +2279,7 @@ func (s *snapsSuite) testPostSnap(c *check.C, extraJSON string, checkOpts func(o
 	buf = bytes.NewBufferString(fmt.Sprintf(`{"action": "install"%s}`, extraJSON))
 	req, err := http.NewRequest("POST", "/v2/snaps/foo", buf)
 	c.Assert(err, check.IsNil)
+	req.RemoteAddr = "pid=100;uid=1000;socket=;"
 
 	rsp := s.asyncReq(c, req, nil)
 
@@ -2059,6 +2294,11 @@ func (s *snapsSuite) testPostSnap(c *check.C, extraJSON string, checkOpts func(o
 	c.Assert(err, check.IsNil)
 	c.Check(names, check.DeepEquals, []string{"foo"})
 
+	// prompts resulting from the change are for the requesting user
+	var requesterUID uint32
+	c.Assert(chg.Get("requester-uid", &requesterUID), check.IsNil)
+	c.Check(requesterUID, check.Equals, uint32(1000))
+
 	c.Check(checked, check.Equals, true)
 	c.Check(soon, check.Equals, 1)
 	c.Check(chg.Tasks()[0].Summary(), check.Equals, "Doing a fake install")
@@ -2087,6 +2327,214 @@ func (s *snapsSuite) TestPostSnapVerifySnapInstruction(c *check.C) {
 	c.Check(rspe.Message, testutil.Contains, `cannot install "ubuntu-core", please use "core" instead`)
 }
 
+func (s *snapsSuite) TestPostSnapDryRun(c *check.C) {
+	d := s.daemon(c)
+
+	defer daemon.MockSnapstateInstallWithGoal(func(ctx context.Context, st *state.State, g snapstate.InstallGoal, opts snapstate.Options) ([]*snap.Info, []*state.TaskSet, error) {
+		prereq := st.NewTask("prerequisites", "Ensure prerequisites for \"foo\" are available")
+		prereq.Set("snap-setup", &snapstate.SnapSetup{
+			SideInfo:     &snap.SideInfo{RealName: "foo", Revision: snap.R(7)},
+			Base:         "core22",
+			Prereq:       []string{"gtk-common-themes", "foo-content"},
+			Type:         snap.TypeApp,
+			DownloadInfo: &snap.DownloadInfo{Size: 2048},
+		})
+		download := st.NewTask("download-snap", "Download snap \"foo\" (7)")
+		download.Set("snap-setup-task", prereq.ID())
+		link := st.NewTask("link-snap", "Make snap \"foo\" (7) available to the system")
+		link.Set("snap-setup-task", prereq.ID())
+		return []*snap.Info{{}}, []*state.TaskSet{state.NewTaskSet(prereq, download, link)}, nil
+	})()
+
+	st := d.Overlord().State()
+	st.Lock()
+	snapstate.Set(st, "gtk-common-themes", &snapstate.SnapState{
+		Active:   true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{{RealName: "gtk-common-themes", Revision: snap.R(1)}}),
+		Current:  snap.R(1),
+	})
+	st.Unlock()
+
+	buf := bytes.NewBufferString(`{"action": "install", "dry-run": true}`)
+	req, err := http.NewRequest("POST", "/v2/snaps/foo", buf)
+	c.Assert(err, check.IsNil)
+
+	rsp := s.syncReq(c, req, nil)
+	c.Check(rsp.Result, check.DeepEquals, daemon.SnapOpPlan{
+		Summary:   `Install "foo" snap`,
+		SnapNames: []string{"foo"},
+		Tasks: []daemon.PlannedTask{
+			{Kind: "prerequisites", Summary: `Ensure prerequisites for "foo" are available`, Snap: "foo"},
+			{Kind: "download-snap", Summary: `Download snap "foo" (7)`, Snap: "foo"},
+			{Kind: "link-snap", Summary: `Make snap "foo" (7) available to the system`, Snap: "foo"},
+		},
+		Prerequisites: []string{"core22", "foo-content"},
+		DownloadSize:  2048,
+	})
+
+	// no change was created and the tasks were discarded
+	st.Lock()
+	defer st.Unlock()
+	c.Check(st.Changes(), check.HasLen, 0)
+	c.Check(st.TaskCount(), check.Equals, 0)
+}
+
+func (s *snapsSuite) TestPostSnapDryRunUnsupportedAction(c *check.C) {
+	s.daemonWithOverlordMock()
+
+	buf := bytes.NewBufferString(`{"action": "enable", "dry-run": true}`)
+	req, err := http.NewRequest("POST", "/v2/snaps/foo", buf)
+	c.Assert(err, check.IsNil)
+
+	rspe := s.errorReq(c, req, nil)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Message, check.Equals, "dry-run can only be specified for install, refresh or remove")
+}
+
+func (s *snapsSuite) TestPostSnapPrerequisitesConfirm(c *check.C) {
+	d := s.daemon(c)
+	_, restore := daemon.MockEnsureStateSoon(func(st *state.State) {})
+	defer restore()
+
+	defer daemon.MockSnapstateInstallWithGoal(func(ctx context.Context, st *state.State, g snapstate.InstallGoal, opts snapstate.Options) ([]*snap.Info, []*state.TaskSet, error) {
+		prereq := st.NewTask("prerequisites", "Ensure prerequisites for \"foo\" are available")
+		prereq.Set("snap-setup", &snapstate.SnapSetup{
+			SideInfo: &snap.SideInfo{RealName: "foo", Revision: snap.R(7)},
+			Base:     "core22",
+			Prereq:   []string{"foo-content"},
+			Type:     snap.TypeApp,
+		})
+		return []*snap.Info{{}}, []*state.TaskSet{state.NewTaskSet(prereq)}, nil
+	})()
+
+	st := d.Overlord().State()
+	st.Lock()
+	tr := config.NewTransaction(st)
+	tr.Set("core", "prerequisites.confirm", true)
+	tr.Commit()
+	st.Unlock()
+
+	buf := bytes.NewBufferString(`{"action": "install"}`)
+	req, err := http.NewRequest("POST", "/v2/snaps/foo", buf)
+	c.Assert(err, check.IsNil)
+
+	rspe := s.errorReq(c, req, nil)
+	c.Check(rspe.Status, check.Equals, 409)
+	c.Check(rspe.Kind, check.Equals, client.ErrorKindPrerequisitesConfirmationRequired)
+	c.Check(rspe.Message, check.Equals, `installing prerequisites "core22", "foo-content" needs to be confirmed`)
+	c.Check(rspe.Value, check.DeepEquals, map[string]interface{}{
+		"snap-names":    []string{"foo"},
+		"prerequisites": []string{"core22", "foo-content"},
+	})
+
+	// no change was created and the tasks were discarded
+	st.Lock()
+	c.Check(st.Changes(), check.HasLen, 0)
+	c.Check(st.TaskCount(), check.Equals, 0)
+	st.Unlock()
+
+	buf = bytes.NewBufferString(`{"action": "install", "accept-prerequisites": true}`)
+	req, err = http.NewRequest("POST", "/v2/snaps/foo", buf)
+	c.Assert(err, check.IsNil)
+
+	rsp := s.asyncReq(c, req, nil)
+
+	st.Lock()
+	defer st.Unlock()
+	chg := st.Change(rsp.Change)
+	c.Assert(chg, check.NotNil)
+	var apiData map[string]interface{}
+	c.Assert(chg.Get("api-data", &apiData), check.IsNil)
+	c.Check(apiData["accepted-prerequisites"], check.DeepEquals, []interface{}{"core22", "foo-content"})
+	var confirm bool
+	c.Check(chg.Get("confirm-prerequisites", &confirm), testutil.ErrorIs, state.ErrNoState)
+}
+
+func (s *snapsSuite) TestPostSnapPrerequisitesConfirmAtRuntime(c *check.C) {
+	d := s.daemon(c)
+	_, restore := daemon.MockEnsureStateSoon(func(st *state.State) {})
+	defer restore()
+
+	defer daemon.MockSnapstateInstallWithGoal(func(ctx context.Context, st *state.State, g snapstate.InstallGoal, opts snapstate.Options) ([]*snap.Info, []*state.TaskSet, error) {
+		prereq := st.NewTask("prerequisites", "Ensure prerequisites for \"foo\" are available")
+		prereq.Set("snap-setup", &snapstate.SnapSetup{
+			SideInfo: &snap.SideInfo{RealName: "foo", Revision: snap.R(7)},
+			Base:     "none",
+			Type:     snap.TypeApp,
+		})
+		return []*snap.Info{{}}, []*state.TaskSet{state.NewTaskSet(prereq)}, nil
+	})()
+
+	st := d.Overlord().State()
+	st.Lock()
+	tr := config.NewTransaction(st)
+	tr.Set("core", "prerequisites.confirm", true)
+	tr.Commit()
+	st.Unlock()
+
+	// no prerequisites are known upfront, but the ones found when the
+	// change runs still need to be confirmed
+	buf := bytes.NewBufferString(`{"action": "install"}`)
+	req, err := http.NewRequest("POST", "/v2/snaps/foo", buf)
+	c.Assert(err, check.IsNil)
+
+	rsp := s.asyncReq(c, req, nil)
+
+	st.Lock()
+	defer st.Unlock()
+	chg := st.Change(rsp.Change)
+	c.Assert(chg, check.NotNil)
+	var confirm bool
+	c.Assert(chg.Get("confirm-prerequisites", &confirm), check.IsNil)
+	c.Check(confirm, check.Equals, true)
+}
+
+func (s *snapsSuite) TestPostSnapPrerequisitesNotConfirmed(c *check.C) {
+	d := s.daemonWithOverlordMock()
+
+	defer daemon.MockSnapstateInstallWithGoal(func(ctx context.Context, st *state.State, g snapstate.InstallGoal, opts snapstate.Options) ([]*snap.Info, []*state.TaskSet, error) {
+		prereq := st.NewTask("prerequisites", "Ensure prerequisites for \"foo\" are available")
+		prereq.Set("snap-setup", &snapstate.SnapSetup{
+			SideInfo: &snap.SideInfo{RealName: "foo", Revision: snap.R(7)},
+			Base:     "core22",
+			Type:     snap.TypeApp,
+		})
+		return []*snap.Info{{}}, []*state.TaskSet{state.NewTaskSet(prereq)}, nil
+	})()
+
+	// without the option prerequisites are installed silently
+	buf := bytes.NewBufferString(`{"action": "install"}`)
+	req, err := http.NewRequest("POST", "/v2/snaps/foo", buf)
+	c.Assert(err, check.IsNil)
+
+	rsp := s.asyncReq(c, req, nil)
+
+	st := d.Overlord().State()
+	st.Lock()
+	defer st.Unlock()
+	chg := st.Change(rsp.Change)
+	c.Assert(chg, check.NotNil)
+	var apiData map[string]interface{}
+	c.Assert(chg.Get("api-data", &apiData), check.IsNil)
+	c.Check(apiData["snap-names"], check.DeepEquals, []interface{}{"foo"})
+	_, ok := apiData["accepted-prerequisites"]
+	c.Check(ok, check.Equals, false)
+	var confirm bool
+	c.Check(chg.Get("confirm-prerequisites", &confirm), testutil.ErrorIs, state.ErrNoState)
+}
+
+func (s *snapsSuite) TestPostSnapAcceptPrerequisitesWrongAction(c *check.C) {
+	s.daemonWithOverlordMock()
+
+	buf := bytes.NewBufferString(`{"action": "remove", "accept-prerequisites": true}`)
+	req, err := http.NewRequest("POST", "/v2/snaps/foo", buf)
+	c.Assert(err, check.IsNil)
+
+	rspe := s.errorReq(c, req, nil)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Message, check.Equals, "accept-prerequisites can only be specified for install or refresh")
+}
+
 func (s *snapsSuite) TestPostSnapCohortUnsupportedAction(c *check.C) {
 	s.daemonWithOverlordMock()
 	const expectedErr = "cohort-key can only be specified for install, refresh, or switch"
@@ -3139,6 +3587,21 @@ func (s *snapsSuite) TestErrToResponseForChangeConflict(c *check.C) {
 			"change-kind": "some-global-op",
 		},
 	})
+
+	// with the conflicting change and a retry hint
+	err = &snapstate.ChangeConflictError{Snap: "foo", ChangeKind: "install", ChangeID: "42", RetryAfter: 1500 * time.Millisecond}
+	rspe = si.ErrToResponse(err)
+	c.Check(rspe, check.DeepEquals, &daemon.APIError{
+		Status:  409,
+		Message: `snap "foo" has "install" change in progress`,
+		Kind:    client.ErrorKindSnapChangeConflict,
+		Value: map[string]interface{}{
+			"snap-name":   "foo",
+			"change-kind": "install",
+			"change-id":   "42",
+			"retry-after": 2,
+		},
+	})
 }
 
 func (s *snapsSuite) TestPostSnapInvalidTransaction(c *check.C) {
@@ -26,15 +26,19 @@ import (
 	"net/http"
 	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/asserts/snapasserts"
 	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/overlord/assertstate"
 	"github.com/snapcore/snapd/overlord/auth"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/release"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/naming"
 )
 
 var (
@@ -94,6 +98,17 @@ func listValidationSets(c *Command, r *http.Request, _ *auth.UserState) Response
 	st.Lock()
 	defer st.Unlock()
 
+	if snapName := r.URL.Query().Get("snap"); snapName != "" {
+		if err := naming.ValidateSnap(snapName); err != nil {
+			return BadRequest("invalid snap name %q", snapName)
+		}
+		res, err := snapValidationConstraints(st, snapName)
+		if err != nil {
+			return InternalError("cannot get validation set constraints of snap %q: %v", snapName, err)
+		}
+		return SyncResponse(res)
+	}
+
 	validationSets, err := assertstate.ValidationSets(st)
 	if err != nil {
 		return InternalError("accessing validation sets failed: %v", err)
@@ -136,6 +151,134 @@ func listValidationSets(c *Command, r *http.Request, _ *auth.UserState) Response
 	return SyncResponse(results)
 }
 
+type validationSetConstraint struct {
+	AccountID string `json:"account-id"`
+	Name      string `json:"name"`
+	Sequence  int    `json:"sequence,omitempty"`
+	Mode      string `json:"mode"`
+	Presence  string `json:"presence"`
+	Revision  string `json:"revision,omitempty"`
+}
+
+type snapValidationConstraintsResult struct {
+	Snap        string                    `json:"snap"`
+	Constraints []validationSetConstraint `json:"constraints,omitempty"`
+	// Presence and Revision are the combined constraints of the enforced
+	// validation sets.
+	Presence string `json:"presence,omitempty"`
+	Revision string `json:"revision,omitempty"`
+	// Blocked lists the actions on the snap that the enforced validation
+	// sets do not allow.
+	Blocked []string `json:"blocked,omitempty"`
+}
+
+// snapValidationConstraints returns the constraints that the tracked
+// validation sets put on the given snap.
+func snapValidationConstraints(st *state.State, snapName string) (*snapValidationConstraintsResult, error) {
+	validationSets, err := assertstate.ValidationSets(st)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(validationSets))
+	for k := range validationSets {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	res := &snapValidationConstraintsResult{Snap: snapName}
+	enforced := snapasserts.NewValidationSets()
+	for _, k := range keys {
+		tr := validationSets[k]
+		vs, err := validationSetAssertFromDb(st, tr.AccountID, tr.Name, tr.Sequence())
+		if err != nil {
+			return nil, err
+		}
+		for _, sn := range vs.Snaps() {
+			if sn.SnapName() != snapName {
+				continue
+			}
+			modeStr, err := modeString(tr.Mode)
+			if err != nil {
+				return nil, err
+			}
+			cstr := validationSetConstraint{
+				AccountID: tr.AccountID,
+				Name:      tr.Name,
+				Sequence:  tr.Sequence(),
+				Mode:      modeStr,
+				Presence:  string(sn.Presence),
+			}
+			if sn.Revision != 0 {
+				cstr.Revision = snap.R(sn.Revision).String()
+			}
+			res.Constraints = append(res.Constraints, cstr)
+			if tr.Mode == assertstate.Enforce {
+				if err := enforced.Add(vs); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if enforced.Empty() {
+		return res, nil
+	}
+	if err := enforced.Conflict(); err != nil {
+		// the constraints cannot be combined, report them as they are
+		return res, nil
+	}
+	pres, err := enforced.Presence(naming.Snap(snapName))
+	if err != nil {
+		return nil, err
+	}
+	if !pres.Constrained() {
+		return res, nil
+	}
+	res.Presence = string(pres.Presence)
+	switch pres.Presence {
+	case asserts.PresenceRequired:
+		res.Blocked = append(res.Blocked, "remove")
+	case asserts.PresenceInvalid:
+		res.Blocked = append(res.Blocked, "install")
+	}
+	if !pres.Revision.Unset() {
+		res.Revision = pres.Revision.String()
+		res.Blocked = append(res.Blocked, "refresh", "revert")
+	}
+	return res, nil
+}
+
+// heldByValidationSets returns an error explaining that the given snap has no
+// updates because the enforced validation sets pin its revision, or nil if
+// they don't.
+func heldByValidationSets(st *state.State, instanceName string) *apiError {
+	snapName, instanceKey := snap.SplitInstanceName(instanceName)
+	if instanceKey != "" {
+		// validation sets are not applied to parallel instances
+		return nil
+	}
+	res, err := snapValidationConstraints(st, snapName)
+	if err != nil {
+		logger.Noticef("cannot get validation set constraints of snap %q: %v", snapName, err)
+		return nil
+	}
+	if res.Revision == "" {
+		return nil
+	}
+	var sets []string
+	for _, cstr := range res.Constraints {
+		if cstr.Mode == "enforce" && cstr.Revision != "" {
+			sets = append(sets, fmt.Sprintf("%s/%s=%d", cstr.AccountID, cstr.Name, cstr.Sequence))
+		}
+	}
+	return &apiError{
+		Status:  400,
+		Message: fmt.Sprintf("snap %q has no updates available: revision %s is required by validation sets: %s", instanceName, res.Revision, strings.Join(sets, ",")),
+		Kind:    client.ErrorKindSnapNoUpdateAvailable,
+		Value:   res,
+	}
+}
+
 var checkInstalledSnaps = func(vsets *snapasserts.ValidationSets, snaps []*snapasserts.InstalledSnap, ignoreValidation map[string]bool) error {
 	return vsets.CheckInstalledSnaps(snaps, ignoreValidation)
 }
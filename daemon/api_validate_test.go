@@ -20,6 +20,7 @@
 package daemon_test
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -31,6 +32,7 @@ import (
 	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/asserts/assertstest"
 	"github.com/snapcore/snapd/asserts/snapasserts"
+	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/daemon"
 	"github.com/snapcore/snapd/overlord/assertstate"
 	"github.com/snapcore/snapd/overlord/assertstate/assertstatetest"
@@ -40,6 +42,7 @@ import (
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/naming"
+	"github.com/snapcore/snapd/store"
 	"github.com/snapcore/snapd/testutil"
 )
 
@@ -255,6 +258,134 @@ func (s *apiValidationSetsSuite) TestListValidationSets(c *check.C) {
 	})
 }
 
+func (s *apiValidationSetsSuite) TestListSnapValidationConstraints(c *check.C) {
+	st := s.d.Overlord().State()
+	st.Lock()
+	s.mockValidationSetsTracking(st)
+	assertstatetest.AddMany(st, s.dev1acct, s.acct1Key)
+	err := assertstate.Add(st, s.mockAssert(c, "foo", "9"))
+	c.Check(err, check.IsNil)
+	err = assertstate.Add(st, s.mockAssert(c, "baz", "2"))
+	st.Unlock()
+	c.Assert(err, check.IsNil)
+
+	req, err := http.NewRequest("GET", "/v2/validation-sets?snap=snap-b", nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil)
+	c.Assert(rsp.Status, check.Equals, 200)
+	res := rsp.Result.(*daemon.SnapValidationConstraintsResult)
+	c.Check(res, check.DeepEquals, &daemon.SnapValidationConstraintsResult{
+		Snap: "snap-b",
+		Constraints: []daemon.ValidationSetConstraint{
+			{
+				AccountID: s.dev1acct.AccountID(),
+				Name:      "baz",
+				Sequence:  2,
+				Mode:      "monitor",
+				Presence:  "required",
+				Revision:  "1",
+			},
+			{
+				AccountID: s.dev1acct.AccountID(),
+				Name:      "foo",
+				Sequence:  9,
+				Mode:      "enforce",
+				Presence:  "required",
+				Revision:  "1",
+			},
+		},
+		Presence: "required",
+		Revision: "1",
+		Blocked:  []string{"remove", "refresh", "revert"},
+	})
+
+	// a snap not mentioned by any validation set
+	req, err = http.NewRequest("GET", "/v2/validation-sets?snap=other-snap", nil)
+	c.Assert(err, check.IsNil)
+	rsp = s.syncReq(c, req, nil)
+	c.Assert(rsp.Status, check.Equals, 200)
+	c.Check(rsp.Result, check.DeepEquals, &daemon.SnapValidationConstraintsResult{
+		Snap: "other-snap",
+	})
+}
+
+func (s *apiValidationSetsSuite) TestListSnapValidationConstraintsMonitorOnly(c *check.C) {
+	st := s.d.Overlord().State()
+	st.Lock()
+	s.mockValidationSetsTracking(st)
+	// forget the enforced one
+	assertstate.ForgetValidationSet(st, s.dev1acct.AccountID(), "foo", assertstate.ForgetValidationSetOpts{})
+	assertstatetest.AddMany(st, s.dev1acct, s.acct1Key)
+	err := assertstate.Add(st, s.mockAssert(c, "baz", "2"))
+	st.Unlock()
+	c.Assert(err, check.IsNil)
+
+	req, err := http.NewRequest("GET", "/v2/validation-sets?snap=snap-b", nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil)
+	c.Assert(rsp.Status, check.Equals, 200)
+	res := rsp.Result.(*daemon.SnapValidationConstraintsResult)
+	c.Check(res.Constraints, check.HasLen, 1)
+	c.Check(res.Presence, check.Equals, "")
+	c.Check(res.Revision, check.Equals, "")
+	c.Check(res.Blocked, check.HasLen, 0)
+}
+
+func (s *apiValidationSetsSuite) TestListSnapValidationConstraintsInvalidName(c *check.C) {
+	req, err := http.NewRequest("GET", "/v2/validation-sets?snap=-foo", nil)
+	c.Assert(err, check.IsNil)
+	rspe := s.errorReq(c, req, nil)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Message, check.Equals, `invalid snap name "-foo"`)
+}
+
+func (s *apiValidationSetsSuite) TestRefreshHeldByValidationSets(c *check.C) {
+	defer daemon.MockSnapstateUpdateOne(func(ctx context.Context, st *state.State, g snapstate.UpdateGoal, filter func(*snap.Info, *snapstate.SnapState) bool, opts snapstate.Options) (*state.TaskSet, error) {
+		return nil, store.ErrNoUpdateAvailable
+	})()
+	defer daemon.MockAssertstateRefreshSnapAssertions(func(s *state.State, userID int, opts *assertstate.RefreshAssertionsOptions) error {
+		return nil
+	})()
+
+	st := s.d.Overlord().State()
+	st.Lock()
+	defer st.Unlock()
+	s.mockValidationSetsTracking(st)
+	assertstatetest.AddMany(st, s.dev1acct, s.acct1Key)
+	c.Assert(assertstate.Add(st, s.mockAssert(c, "foo", "9")), check.IsNil)
+	c.Assert(assertstate.Add(st, s.mockAssert(c, "baz", "2")), check.IsNil)
+
+	inst := &daemon.SnapInstruction{
+		Action: "refresh",
+		Snaps:  []string{"snap-b"},
+	}
+	_, err := inst.Dispatch()(context.Background(), inst, st)
+	c.Assert(err, check.NotNil)
+	rspe := inst.ErrToResponse(err)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Kind, check.Equals, client.ErrorKindSnapNoUpdateAvailable)
+	c.Check(rspe.Message, check.Equals, fmt.Sprintf(`snap "snap-b" has no updates available: revision 1 is required by validation sets: %s/foo=9`, s.dev1acct.AccountID()))
+	res := rspe.Value.(*daemon.SnapValidationConstraintsResult)
+	c.Check(res.Revision, check.Equals, "1")
+
+	// not held by validation sets
+	inst = &daemon.SnapInstruction{
+		Action: "refresh",
+		Snaps:  []string{"other-snap"},
+	}
+	_, err = inst.Dispatch()(context.Background(), inst, st)
+	c.Check(err, check.Equals, store.ErrNoUpdateAvailable)
+
+	// ignoring validation
+	inst = &daemon.SnapInstruction{
+		Action:           "refresh",
+		Snaps:            []string{"snap-b"},
+		IgnoreValidation: true,
+	}
+	_, err = inst.Dispatch()(context.Background(), inst, st)
+	c.Check(err, check.Equals, store.ErrNoUpdateAvailable)
+}
+
 func (s *apiValidationSetsSuite) TestGetValidationSetOne(c *check.C) {
 	s.mockSeqFormingAssertionFn = func(assertType *asserts.AssertionType, sequenceKey []string, sequence int, user *auth.UserState) (asserts.Assertion, error) {
 		return nil, &asserts.NotFoundError{
@@ -24,13 +24,16 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"time"
 
 	"github.com/snapcore/snapd/arch"
 	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/overlord/ifacestate"
 	"github.com/snapcore/snapd/overlord/servicestate"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/store"
+	"github.com/snapcore/snapd/strutil"
 )
 
 // apiError represents an error meant for returning to the client.
@@ -220,6 +223,13 @@ func SnapChangeConflict(cce *snapstate.ChangeConflictError) *apiError {
 	if cce.ChangeKind != "" {
 		value["change-kind"] = cce.ChangeKind
 	}
+	if cce.ChangeID != "" {
+		value["change-id"] = cce.ChangeID
+	}
+	if cce.RetryAfter > 0 {
+		// in whole seconds, as for the Retry-After HTTP header
+		value["retry-after"] = int((cce.RetryAfter + time.Second - 1) / time.Second)
+	}
 
 	return &apiError{
 		Status:  409,
@@ -266,6 +276,20 @@ func InsufficientSpace(dserr *snapstate.InsufficientSpaceError) *apiError {
 	}
 }
 
+// PrerequisitesConfirmationRequired is an error responder used when an
+// operation would install prerequisites that need to be confirmed first.
+func PrerequisitesConfirmationRequired(snapNames, prereqs []string) *apiError {
+	return &apiError{
+		Status:  409,
+		Message: fmt.Sprintf("installing prerequisites %s needs to be confirmed", strutil.Quoted(prereqs)),
+		Kind:    client.ErrorKindPrerequisitesConfirmationRequired,
+		Value: map[string]interface{}{
+			"snap-names":    snapNames,
+			"prerequisites": prereqs,
+		},
+	}
+}
+
 // AppNotFound is an error responder used when an operation is
 // requested on a app that doesn't exist.
 func AppNotFound(format string, v ...interface{}) *apiError {
@@ -319,6 +343,9 @@ func errToResponse(err error, snaps []string, fallback errorResponder, format st
 	default:
 		handled := true
 		switch err := err.(type) {
+		case *apiError:
+			// already a response
+			return err
 		case *store.RevisionNotAvailableError:
 			// store.ErrRevisionNotAvailable should only be returned for
 			// individual snap queries; in all other cases something's wrong
@@ -338,6 +365,13 @@ func errToResponse(err error, snaps []string, fallback errorResponder, format st
 			snapName = err.Snap
 		case *servicestate.QuotaChangeConflictError:
 			return QuotaChangeConflict(err)
+		case *ifacestate.ErrInterfaceDenied:
+			return &apiError{
+				Status:  400,
+				Message: err.Error(),
+				Kind:    client.ErrorKindInterfacesDenied,
+				Value:   err.Interface,
+			}
 		case *snapstate.SnapNeedsDevModeError:
 			kind = client.ErrorKindSnapNeedsDevMode
 			snapName = err.Snap
@@ -29,6 +29,7 @@ import (
 
 	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/daemon"
+	"github.com/snapcore/snapd/overlord/ifacestate"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/store"
@@ -127,6 +128,7 @@ func (s *errorsSuite) TestErrToResponse(c *C) {
 	nc := &snapstate.SnapNotClassicError{Snap: "foo"}
 	nce := &snapstate.SnapNeedsClassicError{Snap: "foo"}
 	ncse := &snapstate.SnapNeedsClassicSystemError{Snap: "foo"}
+	ide := &ifacestate.ErrInterfaceDenied{Interface: "camera"}
 	netoe := fakeNetError{message: "other"}
 	nettoute := fakeNetError{message: "timeout", timeout: true}
 	nettmpe := fakeNetError{message: "temp", temporary: true}
@@ -166,6 +168,7 @@ func (s *errorsSuite) TestErrToResponse(c *C) {
 		{nce, makeErrorRsp(client.ErrorKindSnapNeedsClassic, nce, "foo"), false},
 		{ncse, makeErrorRsp(client.ErrorKindSnapNeedsClassicSystem, ncse, "foo"), false},
 		{cce, daemon.SnapChangeConflict(cce), false},
+		{ide, makeErrorRsp(client.ErrorKindInterfacesDenied, ide, "camera"), false},
 		{nettoute, makeErrorRsp(client.ErrorKindNetworkTimeout, nettoute, ""), false},
 		{netoe, daemon.BadRequest("ERR: %v", netoe), false},
 		{nettmpe, daemon.BadRequest("ERR: %v", nettmpe), false},
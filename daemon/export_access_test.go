@@ -32,12 +32,16 @@ type (
 	AuthenticatedAccess          = authenticatedAccess
 	RootAccess                   = rootAccess
 	SnapAccess                   = snapAccess
+	SnapOpAccess                 = snapOpAccess
+	SnapSelfAccess               = snapSelfAccess
 	InterfaceOpenAccess          = interfaceOpenAccess
 	InterfaceAuthenticatedAccess = interfaceAuthenticatedAccess
 )
 
 var CheckPolkitActionImpl = checkPolkitActionImpl
 
+const MaxSnapOpPeekSize = maxSnapOpPeekSize
+
 func MockCheckPolkitAction(new func(r *http.Request, ucred *Ucrednet, action string) *APIError) (restore func()) {
 	old := checkPolkitAction
 	checkPolkitAction = new
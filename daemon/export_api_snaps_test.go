@@ -33,6 +33,11 @@ var (
 	MapLocal = mapLocal
 )
 
+type (
+	SnapOpPlan  = snapOpPlan
+	PlannedTask = plannedTask
+)
+
 func MockAssertstateRestoreValidationSetsTracking(f func(*state.State) error) (restore func()) {
 	old := assertstateRestoreValidationSetsTracking
 	assertstateRestoreValidationSetsTracking = f
@@ -30,6 +30,7 @@ import (
 	"github.com/snapcore/snapd/boot"
 	"github.com/snapcore/snapd/client/clientutil"
 	"github.com/snapcore/snapd/confdb"
+	"github.com/snapcore/snapd/gadget/quantity"
 	"github.com/snapcore/snapd/osutil/user"
 	"github.com/snapcore/snapd/overlord"
 	"github.com/snapcore/snapd/overlord/assertstate"
@@ -39,6 +40,7 @@ import (
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snapdtool"
 	"github.com/snapcore/snapd/testutil"
 )
 
@@ -112,6 +114,14 @@ func MockShutdownTimeout(tm time.Duration) (restore func()) {
 	}
 }
 
+func MockSnapdtoolReexecStatus(f func() (*snapdtool.ReexecInfo, error)) (restore func()) {
+	return testutil.Mock(&snapdtoolReexecStatus, f)
+}
+
+func MockSnapdtoolDisableReexecUntilReboot(f func(disable bool) error) (restore func()) {
+	return testutil.Mock(&snapdtoolDisableReexecUntilReboot, f)
+}
+
 func MockUnsafeReadSnapInfo(mock func(string) (*snap.Info, error)) (restore func()) {
 	oldUnsafeReadSnapInfo := unsafeReadSnapInfo
 	unsafeReadSnapInfo = mock
@@ -326,6 +336,14 @@ func MockLongestGatingHold(f func(st *state.State, name string) (time.Time, erro
 	}
 }
 
+func MockPrivateTmpUsage(f func(instanceName string) (used, size quantity.Size, err error)) (restore func()) {
+	old := backendPrivateTmpUsage
+	backendPrivateTmpUsage = f
+	return func() {
+		backendPrivateTmpUsage = old
+	}
+}
+
 func MockReboot(f func(boot.RebootAction, time.Duration, *boot.RebootInfo) error) func() {
 	reboot = f
 	return func() { reboot = boot.Reboot }
@@ -385,6 +403,8 @@ var (
 	ErrToResponse      = errToResponse
 
 	MaxReadBuflen = maxReadBuflen
+
+	QueuedSnapOpTasks = queuedSnapOpTasks
 )
 
 func MockConfdbstateGet(f func(_ *state.State, _, _, _ string, _ []string) (interface{}, error)) (restore func()) {
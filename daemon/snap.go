@@ -33,17 +33,21 @@ import (
 	"github.com/snapcore/snapd/overlord/assertstate"
 	"github.com/snapcore/snapd/overlord/healthstate"
 	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/snapstate/backend"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/snap"
 )
 
 var errNoSnap = errors.New("snap not installed")
 
+var backendPrivateTmpUsage = backend.PrivateTmpUsage
+
 type aboutSnap struct {
-	info           *snap.Info
-	snapst         *snapstate.SnapState
-	health         *client.SnapHealth
-	refreshInhibit *client.SnapRefreshInhibit
+	info             *snap.Info
+	snapst           *snapstate.SnapState
+	health           *client.SnapHealth
+	refreshInhibit   *client.SnapRefreshInhibit
+	refreshAvailable *client.SnapRefreshAvailable
 
 	hold       time.Time
 	gatingHold time.Time
@@ -86,13 +90,19 @@ func localSnapInfo(st *state.State, name string) (aboutSnap, error) {
 
 	refreshInhibit := clientSnapRefreshInhibit(st, &snapst, name)
 
+	refreshes, err := snapstate.AvailableRefreshes(st)
+	if err != nil {
+		return aboutSnap{}, InternalError("%v", err)
+	}
+
 	return aboutSnap{
-		info:           info,
-		snapst:         &snapst,
-		health:         clientHealthFromHealthstate(health),
-		refreshInhibit: refreshInhibit,
-		hold:           userHold,
-		gatingHold:     gatingHold,
+		info:             info,
+		snapst:           &snapst,
+		health:           clientHealthFromHealthstate(health),
+		refreshInhibit:   refreshInhibit,
+		refreshAvailable: clientSnapRefreshAvailable(refreshes[name]),
+		hold:             userHold,
+		gatingHold:       gatingHold,
 	}, nil
 }
 
@@ -117,6 +127,7 @@ const (
 	snapSelectAll
 	snapSelectEnabled
 	snapSelectRefreshInhibited
+	snapSelectRefreshAvailable
 )
 
 // allLocalSnapInfos returns the information about the all current snaps and their SnapStates.
@@ -135,6 +146,11 @@ func allLocalSnapInfos(st *state.State, sel snapSelect, wanted map[string]bool)
 		return nil, err
 	}
 
+	refreshes, err := snapstate.AvailableRefreshes(st)
+	if err != nil {
+		return nil, err
+	}
+
 	for name, snapst := range snapStates {
 		if len(wanted) > 0 && !wanted[name] {
 			continue
@@ -152,6 +168,12 @@ func allLocalSnapInfos(st *state.State, sel snapSelect, wanted map[string]bool)
 			continue
 		}
 
+		refreshAvailable := clientSnapRefreshAvailable(refreshes[name])
+		if sel == snapSelectRefreshAvailable && refreshAvailable == nil {
+			// skip snaps without a pending refresh
+			continue
+		}
+
 		var aboutThis []aboutSnap
 		var info *snap.Info
 		if sel == snapSelectAll {
@@ -173,12 +195,13 @@ func allLocalSnapInfos(st *state.State, sel snapSelect, wanted map[string]bool)
 					return nil, err
 				}
 				abSnap := aboutSnap{
-					info:           info,
-					snapst:         snapst,
-					health:         health,
-					refreshInhibit: refreshInhibit,
-					hold:           userHold,
-					gatingHold:     gatingHold,
+					info:             info,
+					snapst:           snapst,
+					health:           health,
+					refreshInhibit:   refreshInhibit,
+					refreshAvailable: refreshAvailable,
+					hold:             userHold,
+					gatingHold:       gatingHold,
 				}
 				aboutThis = append(aboutThis, abSnap)
 			}
@@ -194,12 +217,13 @@ func allLocalSnapInfos(st *state.State, sel snapSelect, wanted map[string]bool)
 			}
 
 			abSnap := aboutSnap{
-				info:           info,
-				snapst:         snapst,
-				health:         health,
-				refreshInhibit: refreshInhibit,
-				hold:           userHold,
-				gatingHold:     gatingHold,
+				info:             info,
+				snapst:           snapst,
+				health:           health,
+				refreshInhibit:   refreshInhibit,
+				refreshAvailable: refreshAvailable,
+				hold:             userHold,
+				gatingHold:       gatingHold,
 			}
 			aboutThis = append(aboutThis, abSnap)
 		}
@@ -233,6 +257,44 @@ func clientSnapRefreshInhibit(st *state.State, snapst *snapstate.SnapState, inst
 	return nil
 }
 
+func clientSnapRefreshAvailable(refresh *snapstate.AvailableRefresh) *client.SnapRefreshAvailable {
+	if refresh == nil {
+		return nil
+	}
+	return &client.SnapRefreshAvailable{
+		Revision:     refresh.Revision,
+		Version:      refresh.Version,
+		Channel:      refresh.Channel,
+		DownloadSize: refresh.DownloadSize,
+	}
+}
+
+// fillPrivateTmpUsage sets the usage of the private /tmp of the snap. This
+// needs a statfs per snap so it is only done when asking about a single snap.
+func fillPrivateTmpUsage(result *client.Snap, instanceName string) {
+	used, size, err := backendPrivateTmpUsage(instanceName)
+	if err != nil {
+		logger.Noticef("cannot get private /tmp usage of snap %q: %v", instanceName, err)
+		return
+	}
+	result.TmpUsage = int64(used)
+	result.TmpSize = int64(size)
+}
+
+// fillBuildProvenance sets the build provenance document shipped in the
+// snap, if any. Its integrity was checked against the snap-revision when the
+// snap was installed.
+func fillBuildProvenance(result *client.Snap, info snap.PlaceInfo) {
+	doc, err := os.ReadFile(filepath.Join(info.MountDir(), snap.BuildProvenancePath))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Noticef("cannot read build provenance of snap %q: %v", info.InstanceName(), err)
+		}
+		return
+	}
+	result.BuildProvenance = string(doc)
+}
+
 func mapLocal(about aboutSnap, sd clientutil.StatusDecorator) *client.Snap {
 	localSnap, snapst := about.info, about.snapst
 	result, err := clientutil.ClientSnapFromSnapInfo(localSnap, sd)
@@ -267,6 +329,7 @@ func mapLocal(about aboutSnap, sd clientutil.StatusDecorator) *client.Snap {
 	}
 	result.Health = about.health
 	result.RefreshInhibit = about.refreshInhibit
+	result.RefreshAvailable = about.refreshAvailable
 
 	if !about.hold.IsZero() {
 		result.Hold = &about.hold
@@ -0,0 +1,348 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package dbustest
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/godbus/dbus"
+)
+
+// ArgMatcher matches a single argument of a method call.
+//
+// Arguments given to Call.WithArgs that are not matchers are compared with
+// reflect.DeepEqual.
+type ArgMatcher interface {
+	MatchArg(arg interface{}) bool
+	String() string
+}
+
+type funcMatcher struct {
+	desc  string
+	match func(arg interface{}) bool
+}
+
+func (m *funcMatcher) MatchArg(arg interface{}) bool { return m.match(arg) }
+func (m *funcMatcher) String() string                { return m.desc }
+
+// ArgFunc returns a matcher using the given function, the description is
+// used when reporting unexpected calls.
+func ArgFunc(desc string, match func(arg interface{}) bool) ArgMatcher {
+	return &funcMatcher{desc: desc, match: match}
+}
+
+// AnyArg returns a matcher accepting any argument.
+func AnyArg() ArgMatcher {
+	return ArgFunc("<any>", func(interface{}) bool { return true })
+}
+
+// ArgHasPrefix returns a matcher accepting string arguments with the given
+// prefix.
+func ArgHasPrefix(prefix string) ArgMatcher {
+	return ArgFunc(fmt.Sprintf("<prefix %q>", prefix), func(arg interface{}) bool {
+		s, ok := arg.(string)
+		return ok && strings.HasPrefix(s, prefix)
+	})
+}
+
+type equalMatcher struct {
+	value interface{}
+}
+
+func (m *equalMatcher) MatchArg(arg interface{}) bool { return reflect.DeepEqual(m.value, arg) }
+func (m *equalMatcher) String() string                { return fmt.Sprintf("%#v", m.value) }
+
+// Call is an expected method call together with the reaction of the bus to
+// it. Calls are created with Script.ExpectCall and configured with the
+// chainable methods.
+type Call struct {
+	iface  string
+	member string
+	path   dbus.ObjectPath
+	dest   string
+	args   []ArgMatcher
+
+	replyBody []interface{}
+	errName   string
+	signals   []*dbus.Message
+
+	// times is the number of expected calls, -1 for any
+	times int
+	calls int
+}
+
+// OnPath restricts the call to the given object path.
+func (c *Call) OnPath(path dbus.ObjectPath) *Call {
+	c.path = path
+	return c
+}
+
+// To restricts the call to the given destination.
+func (c *Call) To(dest string) *Call {
+	c.dest = dest
+	return c
+}
+
+// WithArgs restricts the call to the given arguments, which are either
+// ArgMatchers or values compared with reflect.DeepEqual.
+func (c *Call) WithArgs(args ...interface{}) *Call {
+	c.args = make([]ArgMatcher, len(args))
+	for i, arg := range args {
+		if m, ok := arg.(ArgMatcher); ok {
+			c.args[i] = m
+		} else {
+			c.args[i] = &equalMatcher{value: arg}
+		}
+	}
+	return c
+}
+
+// Return makes the call reply with the given body.
+func (c *Call) Return(body ...interface{}) *Call {
+	c.replyBody = body
+	c.errName = ""
+	return c
+}
+
+// Fail makes the call reply with an error of the given name and body.
+func (c *Call) Fail(errName string, body ...interface{}) *Call {
+	c.replyBody = body
+	c.errName = errName
+	return c
+}
+
+// ThenEmit makes the bus emit the given signal after replying to the call.
+func (c *Call) ThenEmit(path dbus.ObjectPath, iface, member string, body ...interface{}) *Call {
+	c.signals = append(c.signals, signalMessage(path, iface, member, body...))
+	return c
+}
+
+// Times sets the number of times the call is expected, by default a call is
+// expected once.
+func (c *Call) Times(n int) *Call {
+	c.times = n
+	return c
+}
+
+// AnyTimes makes the call expected any number of times, including never.
+func (c *Call) AnyTimes() *Call {
+	c.times = -1
+	return c
+}
+
+func (c *Call) String() string {
+	var args []string
+	for _, m := range c.args {
+		args = append(args, m.String())
+	}
+	s := fmt.Sprintf("%s.%s(%s)", c.iface, c.member, strings.Join(args, ", "))
+	if c.path != "" {
+		s += fmt.Sprintf(" on %s", c.path)
+	}
+	if c.dest != "" {
+		s += fmt.Sprintf(" to %s", c.dest)
+	}
+	return s
+}
+
+func headerString(msg *dbus.Message, field dbus.HeaderField) string {
+	v, ok := msg.Headers[field]
+	if !ok {
+		return ""
+	}
+	switch value := v.Value().(type) {
+	case string:
+		return value
+	case dbus.ObjectPath:
+		return string(value)
+	}
+	return ""
+}
+
+func (c *Call) matches(msg *dbus.Message) bool {
+	if msg.Type != dbus.TypeMethodCall {
+		return false
+	}
+	if headerString(msg, dbus.FieldInterface) != c.iface || headerString(msg, dbus.FieldMember) != c.member {
+		return false
+	}
+	if c.path != "" && headerString(msg, dbus.FieldPath) != string(c.path) {
+		return false
+	}
+	if c.dest != "" && headerString(msg, dbus.FieldDestination) != c.dest {
+		return false
+	}
+	if c.args == nil {
+		return true
+	}
+	if len(c.args) != len(msg.Body) {
+		return false
+	}
+	for i, m := range c.args {
+		if !m.MatchArg(msg.Body[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Call) exhausted() bool {
+	return c.times >= 0 && c.calls >= c.times
+}
+
+func (c *Call) respond(msg *dbus.Message) []*dbus.Message {
+	reply := &dbus.Message{
+		Type: dbus.TypeMethodReply,
+		Headers: map[dbus.HeaderField]dbus.Variant{
+			dbus.FieldReplySerial: dbus.MakeVariant(msg.Serial()),
+			dbus.FieldSender:      dbus.MakeVariant(":1"),
+		},
+		Body: c.replyBody,
+	}
+	if c.errName != "" {
+		reply.Type = dbus.TypeError
+		reply.Headers[dbus.FieldErrorName] = dbus.MakeVariant(c.errName)
+	}
+	if len(c.replyBody) > 0 {
+		reply.Headers[dbus.FieldSignature] = dbus.MakeVariant(dbus.SignatureOf(c.replyBody...))
+	}
+	return append([]*dbus.Message{reply}, c.signals...)
+}
+
+func signalMessage(path dbus.ObjectPath, iface, member string, body ...interface{}) *dbus.Message {
+	msg := &dbus.Message{
+		Type: dbus.TypeSignal,
+		Headers: map[dbus.HeaderField]dbus.Variant{
+			dbus.FieldSender:    dbus.MakeVariant(":1"),
+			dbus.FieldPath:      dbus.MakeVariant(path),
+			dbus.FieldInterface: dbus.MakeVariant(iface),
+			dbus.FieldMember:    dbus.MakeVariant(member),
+		},
+		Body: body,
+	}
+	if len(body) > 0 {
+		msg.Headers[dbus.FieldSignature] = dbus.MakeVariant(dbus.SignatureOf(body...))
+	}
+	return msg
+}
+
+// Script is a declarative description of the method calls a test expects on
+// the bus and of the replies to them, as an alternative to writing a
+// DBusHandlerFunc by hand.
+//
+// Incoming method calls are matched against the expected calls in the order
+// in which they were added, the first one that matches and was not called
+// the expected number of times yet is used. Method calls that do not match
+// any expected call are answered with an
+// org.freedesktop.DBus.Error.UnknownMethod error and reported by Verify.
+type Script struct {
+	mu         sync.Mutex
+	calls      []*Call
+	unexpected []string
+	inject     InjectMessageFunc
+}
+
+// NewScript returns an empty script.
+func NewScript() *Script {
+	return &Script{}
+}
+
+// ExpectCall adds an expected call of the given method, which by default
+// replies with an empty body.
+func (s *Script) ExpectCall(iface, member string) *Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	call := &Call{iface: iface, member: member, times: 1}
+	s.calls = append(s.calls, call)
+	return call
+}
+
+// Handler returns a DBusHandlerFunc executing the script.
+func (s *Script) Handler() DBusHandlerFunc {
+	return func(msg *dbus.Message, n int) ([]*dbus.Message, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for _, call := range s.calls {
+			if call.exhausted() || !call.matches(msg) {
+				continue
+			}
+			call.calls++
+			return call.respond(msg), nil
+		}
+		if msg.Type != dbus.TypeMethodCall {
+			return nil, nil
+		}
+		s.unexpected = append(s.unexpected, fmt.Sprintf("unexpected message #%d: %s", n, msg))
+		return []*dbus.Message{{
+			Type: dbus.TypeError,
+			Headers: map[dbus.HeaderField]dbus.Variant{
+				dbus.FieldReplySerial: dbus.MakeVariant(msg.Serial()),
+				dbus.FieldSender:      dbus.MakeVariant(":1"),
+				dbus.FieldErrorName:   dbus.MakeVariant("org.freedesktop.DBus.Error.UnknownMethod"),
+			},
+		}}, nil
+	}
+}
+
+// Connection returns a DBus connection executing the script.
+func (s *Script) Connection() (*dbus.Conn, error) {
+	conn, inject, err := InjectableConnection(s.Handler())
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.inject = inject
+	s.mu.Unlock()
+	return conn, nil
+}
+
+// Emit makes the bus emit the given signal on the connection returned by
+// Connection.
+func (s *Script) Emit(path dbus.ObjectPath, iface, member string, body ...interface{}) error {
+	s.mu.Lock()
+	inject := s.inject
+	s.mu.Unlock()
+	if inject == nil {
+		return errors.New("cannot emit signal without a connection")
+	}
+	inject(signalMessage(path, iface, member, body...))
+	return nil
+}
+
+// Verify returns an error if any method call was not expected or if any
+// expected call was not done the expected number of times.
+func (s *Script) Verify() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	problems := append([]string(nil), s.unexpected...)
+	for _, call := range s.calls {
+		if call.times >= 0 && call.calls != call.times {
+			problems = append(problems, fmt.Sprintf("expected %d call(s) of %s, got %d", call.times, call, call.calls))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(problems, "\n"))
+}
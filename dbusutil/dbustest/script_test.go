@@ -0,0 +1,115 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package dbustest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus"
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dbusutil/dbustest"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type scriptSuite struct{}
+
+var _ = Suite(&scriptSuite{})
+
+func (s *scriptSuite) TestReplies(c *C) {
+	script := dbustest.NewScript()
+	script.ExpectCall("org.example.Foo", "Get").
+		To("org.example").
+		OnPath("/org/example/Foo").
+		WithArgs("bar", dbustest.AnyArg()).
+		Return("baz", uint32(42))
+	script.ExpectCall("org.example.Foo", "Get").
+		WithArgs(dbustest.ArgHasPrefix("broken-"), dbustest.AnyArg()).
+		Fail("org.example.Error.Broken", "it broke")
+
+	conn, err := script.Connection()
+	c.Assert(err, IsNil)
+	defer conn.Close()
+	obj := conn.Object("org.example", "/org/example/Foo")
+
+	var str string
+	var num uint32
+	err = obj.Call("org.example.Foo.Get", 0, "bar", int32(1)).Store(&str, &num)
+	c.Assert(err, IsNil)
+	c.Check(str, Equals, "baz")
+	c.Check(num, Equals, uint32(42))
+
+	err = obj.Call("org.example.Foo.Get", 0, "broken-bar", int32(1)).Store()
+	c.Check(err, DeepEquals, dbus.Error{Name: "org.example.Error.Broken", Body: []interface{}{"it broke"}})
+
+	c.Check(script.Verify(), IsNil)
+}
+
+func (s *scriptSuite) TestUnexpectedAndMissingCalls(c *C) {
+	script := dbustest.NewScript()
+	script.ExpectCall("org.example.Foo", "Get").WithArgs("bar")
+	script.ExpectCall("org.example.Foo", "Set").AnyTimes()
+	script.ExpectCall("org.example.Foo", "Reset")
+
+	conn, err := script.Connection()
+	c.Assert(err, IsNil)
+	defer conn.Close()
+	obj := conn.Object("org.example", "/org/example/Foo")
+
+	c.Assert(obj.Call("org.example.Foo.Get", 0, "bar").Store(), IsNil)
+	// the expected call was already done once
+	err = obj.Call("org.example.Foo.Get", 0, "bar").Store()
+	c.Check(err, ErrorMatches, "org.freedesktop.DBus.Error.UnknownMethod")
+
+	c.Check(script.Verify(), ErrorMatches, `(?s)unexpected message #1: .*Get.*
+expected 1 call\(s\) of org.example.Foo.Reset\(\), got 0`)
+}
+
+func (s *scriptSuite) TestSignals(c *C) {
+	script := dbustest.NewScript()
+	script.ExpectCall("org.example.Foo", "Start").
+		ThenEmit("/org/example/Foo", "org.example.Foo", "Started", "job")
+
+	err := script.Emit("/org/example/Foo", "org.example.Foo", "Stopped")
+	c.Check(err, ErrorMatches, "cannot emit signal without a connection")
+
+	conn, err := script.Connection()
+	c.Assert(err, IsNil)
+	defer conn.Close()
+	signals := make(chan *dbus.Signal, 10)
+	conn.Signal(signals)
+
+	c.Assert(conn.Object("org.example", "/org/example/Foo").Call("org.example.Foo.Start", 0).Store(), IsNil)
+	c.Assert(script.Emit("/org/example/Foo", "org.example.Foo", "Stopped", "job"), IsNil)
+
+	for _, name := range []string{"org.example.Foo.Started", "org.example.Foo.Stopped"} {
+		select {
+		case sig := <-signals:
+			c.Check(sig.Name, Equals, name)
+			c.Check(sig.Path, Equals, dbus.ObjectPath("/org/example/Foo"))
+			c.Check(sig.Body, DeepEquals, []interface{}{"job"})
+		case <-time.After(5 * time.Second):
+			c.Fatalf("signal %s not received", name)
+		}
+	}
+	c.Check(script.Verify(), IsNil)
+}
@@ -0,0 +1,50 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package desktopentry
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// LaunchTokenEnv is the environment variable through which the desktop files
+// generated by snapd pass their launch token to "snap run".
+const LaunchTokenEnv = "SNAP_DESKTOP_LAUNCH_TOKEN"
+
+// LaunchToken returns the token authenticating that a launch of the given
+// snap application originates from the given desktop file generated by
+// snapd. The token is keyed with the desktop launch key of the system so
+// that a desktop file copied or modified to start another application does
+// not carry a valid token for it.
+func LaunchToken(key []byte, desktopFile, snapApp string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(desktopFile))
+	mac.Write([]byte{0})
+	mac.Write([]byte(snapApp))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyLaunchToken returns whether the token authenticates the launch of
+// the given snap application from the given desktop file.
+func VerifyLaunchToken(key []byte, desktopFile, snapApp, token string) bool {
+	expected := LaunchToken(key, desktopFile, snapApp)
+	return hmac.Equal([]byte(expected), []byte(token))
+}
@@ -0,0 +1,43 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package desktopentry_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/desktop/desktopentry"
+)
+
+type launchTokenSuite struct{}
+
+var _ = Suite(&launchTokenSuite{})
+
+func (s *launchTokenSuite) TestLaunchToken(c *C) {
+	key := []byte("some-key")
+	token := desktopentry.LaunchToken(key, "/var/lib/snapd/desktop/applications/foo_foo.desktop", "foo")
+	c.Check(token, HasLen, 64)
+	c.Check(desktopentry.VerifyLaunchToken(key, "/var/lib/snapd/desktop/applications/foo_foo.desktop", "foo", token), Equals, true)
+
+	// the token is bound to the desktop file, the application and the key
+	c.Check(desktopentry.VerifyLaunchToken(key, "/var/lib/snapd/desktop/applications/bar_bar.desktop", "foo", token), Equals, false)
+	c.Check(desktopentry.VerifyLaunchToken(key, "/var/lib/snapd/desktop/applications/foo_foo.desktop", "bar", token), Equals, false)
+	c.Check(desktopentry.VerifyLaunchToken([]byte("other-key"), "/var/lib/snapd/desktop/applications/foo_foo.desktop", "foo", token), Equals, false)
+	c.Check(desktopentry.VerifyLaunchToken(key, "/var/lib/snapd/desktop/applications/foo_foo.desktop", "foo", ""), Equals, false)
+}
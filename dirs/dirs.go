@@ -61,11 +61,16 @@ var (
 	SnapRunDir           string
 	SnapRunNsDir         string
 	SnapRunLockDir       string
+	SnapPrivateTmpDir    string
 	SnapBootstrapRunDir  string
 	SnapVoidDir          string
 
 	SnapdMaintenanceFile string
 
+	SnapdReexecDisabledFile    string
+	SnapdReexecDisabledRunFile string
+	SnapConfineDebugDir        string
+
 	SnapdStoreSSLCertsDir string
 
 	SnapSeedDir   string
@@ -73,9 +78,11 @@ var (
 
 	SnapAssertsDBDir      string
 	SnapCookieDir         string
+	SnapFirewallDir       string
 	SnapTrustedAccountKey string
 	SnapAssertsSpoolDir   string
 	SnapSeqDir            string
+	SnapCoredumpDir       string
 
 	SnapStateFile     string
 	SnapStateLockFile string
@@ -96,6 +103,9 @@ var (
 	SnapCommandsDB      string
 	SnapAuxStoreInfoDir string
 
+	SnapStoreMetadataCacheDir string
+	SnapToolingCacheDir       string
+
 	SnapBinariesDir        string
 	SnapServicesDir        string
 	SnapRuntimeServicesDir string
@@ -103,6 +113,7 @@ var (
 	SnapSystemdConfDir     string
 	SnapDesktopFilesDir    string
 	SnapDesktopIconsDir    string
+	SnapDesktopLaunchKey   string
 	SnapPolkitPolicyDir    string
 	SnapSystemdDir         string
 	SnapSystemdRunDir      string
@@ -478,13 +489,29 @@ func SetRootDir(rootdir string) {
 	// freedesktop.org specifications
 	SnapDesktopFilesDir = filepath.Join(rootdir, snappyDir, "desktop", "applications")
 	SnapDesktopIconsDir = filepath.Join(rootdir, snappyDir, "desktop", "icons")
+	// SnapDesktopLaunchKey authenticates the launches from the desktop
+	// files generated by snapd
+	SnapDesktopLaunchKey = filepath.Join(rootdir, snappyDir, "desktop", "launch-key")
 	RunDir = filepath.Join(rootdir, "/run")
 	SnapRunDir = filepath.Join(rootdir, "/run/snapd")
 	SnapRunNsDir = filepath.Join(SnapRunDir, "/ns")
 	SnapRunLockDir = filepath.Join(SnapRunDir, "/lock")
+	// the private /tmp directories of snaps are set up by snap-confine
+	// under this directory
+	SnapPrivateTmpDir = filepath.Join(rootdir, "/tmp/snap-private-tmp")
 
 	SnapBootstrapRunDir = filepath.Join(SnapRunDir, "snap-bootstrap")
 
+	// re-exec into the snapd snap can be disabled through the
+	// configuration, or until the next boot
+	SnapdReexecDisabledFile = filepath.Join(rootdir, snappyDir, "reexec-disabled")
+	SnapdReexecDisabledRunFile = filepath.Join(SnapRunDir, "reexec-disabled")
+
+	// verbose logging of snap-confine and snap-update-ns can be enabled
+	// per snap through control files in this directory, keep in sync with
+	// cmd/snap-confine/snap-confine.c
+	SnapConfineDebugDir = filepath.Join(SnapRunDir, "confine-debug")
+
 	SnapdStoreSSLCertsDir = filepath.Join(rootdir, snappyDir, "ssl/store-certs")
 
 	// keep in sync with the debian/snapd.socket file:
@@ -493,8 +520,10 @@ func SetRootDir(rootdir string) {
 
 	SnapAssertsDBDir = filepath.Join(rootdir, snappyDir, "assertions")
 	SnapCookieDir = filepath.Join(rootdir, snappyDir, "cookie")
+	SnapFirewallDir = filepath.Join(rootdir, snappyDir, "firewall")
 	SnapAssertsSpoolDir = filepath.Join(rootdir, "run/snapd/auto-import")
 	SnapSeqDir = filepath.Join(rootdir, snappyDir, "sequence")
+	SnapCoredumpDir = filepath.Join(rootdir, snappyDir, "coredump")
 
 	SnapStateFile = SnapStateFileUnder(rootdir)
 	SnapStateLockFile = SnapStateLockFileUnder(rootdir)
@@ -505,6 +534,8 @@ func SetRootDir(rootdir string) {
 	SnapSectionsFile = filepath.Join(SnapCacheDir, "sections")
 	SnapCommandsDB = filepath.Join(SnapCacheDir, "commands.db")
 	SnapAuxStoreInfoDir = filepath.Join(SnapCacheDir, "aux")
+	SnapStoreMetadataCacheDir = filepath.Join(SnapCacheDir, "store-metadata")
+	SnapToolingCacheDir = filepath.Join(SnapCacheDir, "tooling")
 
 	SnapSeedDir = SnapSeedDirUnder(rootdir)
 	SnapDeviceDir = SnapDeviceDirUnder(rootdir)
@@ -77,6 +77,10 @@ const (
 	ConfdbControl
 	// AppArmorPrompting enables AppArmor to prompt the user for permission when apps perform certain operations.
 	AppArmorPrompting
+	// ConnectionPrompting enables prompting the user to connect plugs which were not auto-connected.
+	ConnectionPrompting
+	// AppArmorHashCache enables parallel compilation of AppArmor profiles into a cache keyed by their hash.
+	AppArmorHashCache
 
 	// lastFeature is the final known feature, it is only used for testing.
 	lastFeature
@@ -127,6 +131,10 @@ var featureNames = map[SnapdFeature]string{
 	ConfdbControl: "confdb-control",
 
 	AppArmorPrompting: "apparmor-prompting",
+
+	ConnectionPrompting: "connection-prompting",
+
+	AppArmorHashCache: "apparmor-hash-cache",
 }
 
 // featuresEnabledWhenUnset contains a set of features that are enabled when not explicitly configured.
@@ -65,6 +65,8 @@ func (*featureSuite) TestName(c *C) {
 	check(features.Confdbs, "confdbs")
 	check(features.ConfdbControl, "confdb-control")
 	check(features.AppArmorPrompting, "apparmor-prompting")
+	check(features.ConnectionPrompting, "connection-prompting")
+	check(features.AppArmorHashCache, "apparmor-hash-cache")
 
 	c.Check(tested, Equals, features.NumberOfFeatures())
 	c.Check(func() { _ = features.SnapdFeature(1000).String() }, PanicMatches, "unknown feature flag code 1000")
@@ -106,6 +108,8 @@ func (*featureSuite) TestIsExported(c *C) {
 	check(features.Confdbs, true)
 	check(features.ConfdbControl, false)
 	check(features.AppArmorPrompting, true)
+	check(features.ConnectionPrompting, false)
+	check(features.AppArmorHashCache, false)
 
 	c.Check(tested, Equals, features.NumberOfFeatures())
 }
@@ -232,6 +236,8 @@ func (*featureSuite) TestIsEnabledWhenUnset(c *C) {
 	check(features.Confdbs, false)
 	check(features.AppArmorPrompting, false)
 	check(features.ConfdbControl, false)
+	check(features.ConnectionPrompting, false)
+	check(features.AppArmorHashCache, false)
 
 	c.Check(tested, Equals, features.NumberOfFeatures())
 }
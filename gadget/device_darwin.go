@@ -28,3 +28,7 @@ var errNotImplemented = errors.New("not implemented")
 func FindDeviceForStructure(vs *VolumeStructure) (string, error) {
 	return "", errNotImplemented
 }
+
+func StructureIsForBoard(vs *VolumeStructure) (bool, error) {
+	return false, errNotImplemented
+}
@@ -20,22 +20,96 @@
 package gadget
 
 import (
+	"bytes"
 	"fmt"
+	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/osutil/disks"
+	"github.com/snapcore/snapd/strutil"
 )
 
 var evalSymlinks = filepath.EvalSymlinks
 
+// deviceTreeCompatible returns the compatible strings of the device tree
+// root node of the running board, or nil if the board has no device tree.
+func deviceTreeCompatible() ([]string, error) {
+	b, err := os.ReadFile(filepath.Join(dirs.GlobalRootDir, "/proc/device-tree/compatible"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var compatible []string
+	for _, entry := range bytes.Split(b, []byte{0}) {
+		if len(entry) != 0 {
+			compatible = append(compatible, string(entry))
+		}
+	}
+	return compatible, nil
+}
+
+// findMTDDevice returns the character device node of the MTD partition with
+// the given name.
+func findMTDDevice(name string) (string, error) {
+	matches, err := filepath.Glob(filepath.Join(dirs.GlobalRootDir, "/sys/class/mtd/mtd*/name"))
+	if err != nil {
+		return "", err
+	}
+	for _, nameFile := range matches {
+		mtd := filepath.Base(filepath.Dir(nameFile))
+		if strings.HasSuffix(mtd, "ro") {
+			// read-only variant of the same partition
+			continue
+		}
+		b, err := os.ReadFile(nameFile)
+		if err != nil {
+			return "", fmt.Errorf("cannot read MTD partition name: %v", err)
+		}
+		if strings.TrimSpace(string(b)) == name {
+			return filepath.Join(dirs.GlobalRootDir, "/dev", mtd), nil
+		}
+	}
+	return "", ErrDeviceNotFound
+}
+
+// StructureIsForBoard returns whether the given volume structure is meant
+// for the running board, that is whether it is not restricted to specific
+// device tree compatible boards or one of them matches the running board.
+func StructureIsForBoard(vs *VolumeStructure) (bool, error) {
+	if len(vs.DeviceTreeCompatible) == 0 {
+		return true, nil
+	}
+	compatible, err := deviceTreeCompatible()
+	if err != nil {
+		return false, fmt.Errorf("cannot read device tree compatible: %v", err)
+	}
+	return len(strutil.Intersection(compatible, vs.DeviceTreeCompatible)) != 0, nil
+}
+
 // FindDeviceForStructure attempts to find an existing block device matching
 // given volume structure, by inspecting its name and, optionally, the
-// filesystem label. Assumes that the host's udev has set up device symlinks
-// correctly.
+// filesystem label. Structures restricted to specific device tree compatible
+// boards are not matched on other boards, and structures backed by an MTD
+// partition are looked up by the MTD partition name. Assumes that the host's
+// udev has set up device symlinks correctly.
 func FindDeviceForStructure(vs *VolumeStructure) (string, error) {
+	forBoard, err := StructureIsForBoard(vs)
+	if err != nil {
+		return "", err
+	}
+	if !forBoard {
+		return "", ErrDeviceNotFound
+	}
+	if vs.MTDName != "" {
+		return findMTDDevice(vs.MTDName)
+	}
+
 	var candidates []string
 
 	if vs.Name != "" {
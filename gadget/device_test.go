@@ -23,6 +23,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 
 	. "gopkg.in/check.v1"
 
@@ -244,3 +245,113 @@ func (d *deviceSuite) TestDeviceFindBadEvalSymlinks(c *C) {
 	c.Check(err, ErrorMatches, `cannot read device link: failed`)
 	c.Check(found, Equals, "")
 }
+
+func (d *deviceSuite) mockDeviceTreeCompatible(c *C, compatible ...string) {
+	dtDir := filepath.Join(d.dir, "/proc/device-tree")
+	c.Assert(os.MkdirAll(dtDir, 0755), IsNil)
+	content := strings.Join(compatible, "\x00") + "\x00"
+	c.Assert(os.WriteFile(filepath.Join(dtDir, "compatible"), []byte(content), 0644), IsNil)
+}
+
+func (d *deviceSuite) mockMTDPartition(c *C, mtd, name string) {
+	mtdDir := filepath.Join(d.dir, "/sys/class/mtd", mtd)
+	c.Assert(os.MkdirAll(mtdDir, 0755), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(mtdDir, "name"), []byte(name+"\n"), 0644), IsNil)
+}
+
+func (d *deviceSuite) TestDeviceFindByMTDName(c *C) {
+	d.mockMTDPartition(c, "mtd0", "spl")
+	d.mockMTDPartition(c, "mtd0ro", "spl")
+	d.mockMTDPartition(c, "mtd1", "u-boot")
+	d.mockMTDPartition(c, "mtd1ro", "u-boot")
+
+	found, err := gadget.FindDeviceForStructure(&gadget.VolumeStructure{
+		Name:    "bootloader",
+		Type:    "bare",
+		MTDName: "u-boot",
+	})
+	c.Check(err, IsNil)
+	c.Check(found, Equals, filepath.Join(d.dir, "/dev/mtd1"))
+
+	found, err = gadget.FindDeviceForStructure(&gadget.VolumeStructure{
+		Name:    "env",
+		Type:    "bare",
+		MTDName: "u-boot-env",
+	})
+	c.Check(err, Equals, gadget.ErrDeviceNotFound)
+	c.Check(found, Equals, "")
+}
+
+func (d *deviceSuite) TestDeviceFindByDeviceTreeCompatible(c *C) {
+	d.mockDeviceTreeCompatible(c, "vendor,board-a", "vendor,soc")
+	d.mockMTDPartition(c, "mtd0", "u-boot")
+
+	for _, tc := range []struct {
+		compatible []string
+		found      string
+		err        error
+	}{
+		{[]string{"vendor,board-a"}, filepath.Join(d.dir, "/dev/mtd0"), nil},
+		{[]string{"vendor,board-b", "vendor,soc"}, filepath.Join(d.dir, "/dev/mtd0"), nil},
+		{[]string{"vendor,board-b"}, "", gadget.ErrDeviceNotFound},
+	} {
+		found, err := gadget.FindDeviceForStructure(&gadget.VolumeStructure{
+			Type:                 "bare",
+			MTDName:              "u-boot",
+			DeviceTreeCompatible: tc.compatible,
+		})
+		c.Check(err, Equals, tc.err, Commentf("%v", tc.compatible))
+		c.Check(found, Equals, tc.found)
+	}
+}
+
+func (d *deviceSuite) TestStructureIsForBoard(c *C) {
+	for _, tc := range []struct {
+		compatible []string
+		forBoard   bool
+	}{
+		{nil, true},
+		{[]string{"vendor,board-a"}, false},
+	} {
+		forBoard, err := gadget.StructureIsForBoard(&gadget.VolumeStructure{DeviceTreeCompatible: tc.compatible})
+		c.Check(err, IsNil)
+		c.Check(forBoard, Equals, tc.forBoard, Commentf("%v", tc.compatible))
+	}
+
+	d.mockDeviceTreeCompatible(c, "vendor,board-a", "vendor,soc")
+	for _, tc := range []struct {
+		compatible []string
+		forBoard   bool
+	}{
+		{nil, true},
+		{[]string{"vendor,board-a"}, true},
+		{[]string{"vendor,board-b", "vendor,soc"}, true},
+		{[]string{"vendor,board-b"}, false},
+	} {
+		forBoard, err := gadget.StructureIsForBoard(&gadget.VolumeStructure{DeviceTreeCompatible: tc.compatible})
+		c.Check(err, IsNil)
+		c.Check(forBoard, Equals, tc.forBoard, Commentf("%v", tc.compatible))
+	}
+}
+
+func (d *deviceSuite) TestDeviceFindDeviceTreeCompatibleNoDeviceTree(c *C) {
+	err := os.Symlink(filepath.Join(d.dir, "/dev/fakedevice"), filepath.Join(d.dir, "/dev/disk/by-partlabel/boot"))
+	c.Assert(err, IsNil)
+
+	found, err := gadget.FindDeviceForStructure(&gadget.VolumeStructure{
+		Name:                 "boot",
+		DeviceTreeCompatible: []string{"vendor,board-a"},
+		EnclosingVolume:      &gadget.Volume{},
+	})
+	c.Check(err, Equals, gadget.ErrDeviceNotFound)
+	c.Check(found, Equals, "")
+
+	d.mockDeviceTreeCompatible(c, "vendor,board-a")
+	found, err = gadget.FindDeviceForStructure(&gadget.VolumeStructure{
+		Name:                 "boot",
+		DeviceTreeCompatible: []string{"vendor,board-a"},
+		EnclosingVolume:      &gadget.Volume{},
+	})
+	c.Check(err, IsNil)
+	c.Check(found, Equals, filepath.Join(d.dir, "/dev/fakedevice"))
+}
@@ -205,6 +205,20 @@ func (v *Volume) HasPartial(pp PartialProperty) bool {
 	return false
 }
 
+// IsMTD returns whether all the structures of the volume are backed by MTD
+// partitions, in which case the volume lives on raw flash and not on a disk.
+func (v *Volume) IsMTD() bool {
+	if len(v.Structure) == 0 {
+		return false
+	}
+	for _, vs := range v.Structure {
+		if vs.MTDName == "" {
+			return false
+		}
+	}
+	return true
+}
+
 // size returns the size of the volume using the structureSizer function to calculate
 // structures size. It assumes sorted structures.
 func (v *Volume) size(structureSizer func(VolumeStructure) quantity.Size) quantity.Size {
@@ -276,6 +290,18 @@ func (vs *VolumeStructure) Copy() *VolumeStructure {
 			}
 		}
 	}
+	if vs.UBIVolumes != nil {
+		newVs.UBIVolumes = make([]UBIVolume, len(vs.UBIVolumes))
+		copy(newVs.UBIVolumes, vs.UBIVolumes)
+	}
+	if vs.DeviceTreeCompatible != nil {
+		newVs.DeviceTreeCompatible = make([]string, len(vs.DeviceTreeCompatible))
+		copy(newVs.DeviceTreeCompatible, vs.DeviceTreeCompatible)
+	}
+	if vs.EMMCBoot != nil {
+		emmcBoot := *vs.EMMCBoot
+		newVs.EMMCBoot = &emmcBoot
+	}
 	return &newVs
 }
 
@@ -347,6 +373,22 @@ type VolumeStructure struct {
 	// Content of the structure
 	Content []VolumeContent `yaml:"content" json:"content"`
 	Update  VolumeUpdate    `yaml:"update" json:"update"`
+	// MTDName is the name of the MTD partition backing a bare
+	// structure, for raw flash devices that do not carry a partition
+	// table, like SPI NOR flash on ARM boards.
+	MTDName string `yaml:"mtd-name,omitempty" json:"mtd-name,omitempty"`
+	// UBIVolumes lists the UBI volumes created in the MTD partition
+	// backing the structure, which is formatted as a UBI device. It
+	// cannot be combined with content.
+	UBIVolumes []UBIVolume `yaml:"ubi-volumes,omitempty" json:"ubi-volumes,omitempty"`
+	// DeviceTreeCompatible restricts device matching of the structure
+	// to boards whose device tree root node is compatible with one of
+	// the listed strings, so that a single gadget can describe the
+	// structures of several boards.
+	DeviceTreeCompatible []string `yaml:"device-tree-compatible,omitempty" json:"device-tree-compatible,omitempty"`
+	// EMMCBoot configures booting from the eMMC hardware boot partition
+	// described by the structure, only valid for "emmc" schema volumes.
+	EMMCBoot *EMMCBootConfig `yaml:"emmc-boot,omitempty" json:"emmc-boot,omitempty"`
 
 	// Note that the Device field will never be part of the yaml
 	// and just used as part of the POST /systems/<label> API that
@@ -560,6 +602,28 @@ func (vc VolumeContent) String() string {
 	return fmt.Sprintf("source:%s", vc.UnresolvedSource)
 }
 
+// UBIVolume is a volume of the UBI device created in the MTD partition of a
+// structure.
+type UBIVolume struct {
+	// Name is the name of the UBI volume.
+	Name string `yaml:"name" json:"name"`
+	// Size is the size of the volume, if not set the volume takes all
+	// the space left, which is only possible for the last volume.
+	Size quantity.Size `yaml:"size,omitempty" json:"size,omitempty"`
+	// Image is the image written to the volume, if any.
+	Image string `yaml:"image,omitempty" json:"image,omitempty"`
+}
+
+// EMMCBootConfig is the boot configuration of an eMMC hardware boot
+// partition, which is stored in the extended CSD register of the device.
+type EMMCBootConfig struct {
+	// Enable selects the partition as the one the device boots from.
+	Enable bool `yaml:"enable" json:"enable"`
+	// Ack requests the device to send the boot acknowledge pattern
+	// while booting, which some SoC boot ROMs wait for.
+	Ack bool `yaml:"ack" json:"ack"`
+}
+
 type VolumeUpdate struct {
 	Edition  edition.Number `yaml:"edition" json:"edition"`
 	Preserve []string       `yaml:"preserve" json:"preserve"`
@@ -914,6 +978,10 @@ func AllDiskVolumeDeviceTraits(allVols map[string]*Volume, optsPerVolume map[str
 	// system
 	allTraits := map[string]DiskVolumeDeviceTraits{}
 	for name, vol := range allVols {
+		// volumes on raw flash have no disk
+		if vol.IsMTD() {
+			continue
+		}
 		// try to find a device for a structure inside the volume, we have a
 		// loop to attempt to use all structures in the volume in case there are
 		// partitions we can't map to a device directly at first using the
@@ -1673,6 +1741,20 @@ func validateVolumeStructure(vs *VolumeStructure, vol *Volume) error {
 	if vs.Filesystem != "" && !strutil.ListContains([]string{"ext4", "vfat", "vfat-16", "vfat-32", "none"}, vs.Filesystem) {
 		return fmt.Errorf("invalid filesystem %q", vs.Filesystem)
 	}
+	if vs.MTDName != "" && vs.IsPartition() {
+		return errors.New("mtd-name can only be used with bare structures")
+	}
+	if err := validateUBIVolumes(vs); err != nil {
+		return err
+	}
+	for _, compatible := range vs.DeviceTreeCompatible {
+		if compatible == "" {
+			return errors.New("invalid empty device-tree-compatible entry")
+		}
+	}
+	if vs.EMMCBoot != nil && vol.Schema != schemaEMMC {
+		return fmt.Errorf(`emmc-boot can only be used with %q schema`, schemaEMMC)
+	}
 
 	contentChecker := contentCheckerCreate(vs, vol)
 	for i, c := range vs.Content {
@@ -1814,6 +1896,32 @@ func validateRole(vs *VolumeStructure) error {
 	return nil
 }
 
+func validateUBIVolumes(vs *VolumeStructure) error {
+	if len(vs.UBIVolumes) == 0 {
+		return nil
+	}
+	if vs.MTDName == "" {
+		return errors.New("ubi-volumes can only be used with mtd-name")
+	}
+	if len(vs.Content) != 0 {
+		return errors.New("cannot use both ubi-volumes and content")
+	}
+	seen := make(map[string]bool, len(vs.UBIVolumes))
+	for i, uv := range vs.UBIVolumes {
+		if uv.Name == "" {
+			return fmt.Errorf("invalid UBI volume #%v: missing name", i)
+		}
+		if seen[uv.Name] {
+			return fmt.Errorf("invalid UBI volume #%v: duplicate name %q", i, uv.Name)
+		}
+		seen[uv.Name] = true
+		if uv.Size == 0 && i != len(vs.UBIVolumes)-1 {
+			return fmt.Errorf("invalid UBI volume #%v: only the last volume can omit its size", i)
+		}
+	}
+	return nil
+}
+
 func validateBareContent(vc *VolumeContent) error {
 	if vc.UnresolvedSource != "" || vc.Target != "" {
 		return fmt.Errorf("cannot use non-image content for bare file system")
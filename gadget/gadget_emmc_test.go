@@ -142,6 +142,84 @@ volumes:
 	c.Assert(err, ErrorMatches, `invalid volume "my-emmc": cannot set "partial" content for eMMC schemas`)
 }
 
+func (s *gadgetYamlEMMCSuite) TestReadGadgetYamlEMMCBoot(c *C) {
+	err := os.WriteFile(s.gadgetYamlPath, []byte(`
+volumes:
+  volumename:
+    schema: mbr
+    bootloader: u-boot
+  my-emmc:
+    schema: emmc
+    structure:
+      - name: boot0
+        size: 4M
+        emmc-boot:
+          enable: true
+          ack: true
+        content:
+          - image: boot0filename
+      - name: boot1
+        size: 4M
+        content:
+          - image: boot1filename
+`), 0644)
+	c.Assert(err, IsNil)
+
+	info, err := gadget.ReadInfo(s.dir, coreMod)
+	c.Assert(err, IsNil)
+	c.Assert(gadget.Validate(info, nil, nil), IsNil)
+
+	vol := info.Volumes["my-emmc"]
+	c.Check(vol.Structure[0].EMMCBoot, DeepEquals, &gadget.EMMCBootConfig{Enable: true, Ack: true})
+	c.Check(vol.Structure[1].EMMCBoot, IsNil)
+}
+
+func (s *gadgetYamlEMMCSuite) TestReadGadgetYamlEMMCBootEnabledTwice(c *C) {
+	err := os.WriteFile(s.gadgetYamlPath, []byte(`
+volumes:
+  volumename:
+    schema: mbr
+    bootloader: u-boot
+  my-emmc:
+    schema: emmc
+    structure:
+      - name: boot0
+        size: 4M
+        emmc-boot:
+          enable: true
+      - name: boot1
+        size: 4M
+        emmc-boot:
+          enable: true
+`), 0644)
+	c.Assert(err, IsNil)
+
+	info, err := gadget.ReadInfo(s.dir, coreMod)
+	c.Assert(err, IsNil)
+
+	err = gadget.Validate(info, nil, nil)
+	c.Assert(err, ErrorMatches, `invalid volume "my-emmc": cannot enable booting from more than one eMMC boot partition`)
+}
+
+func (s *gadgetYamlEMMCSuite) TestReadGadgetYamlEMMCBootOnlyForEMMC(c *C) {
+	err := os.WriteFile(s.gadgetYamlPath, []byte(`
+volumes:
+  volumename:
+    schema: mbr
+    bootloader: u-boot
+    structure:
+      - name: foo
+        type: bare
+        size: 4M
+        emmc-boot:
+          enable: true
+`), 0644)
+	c.Assert(err, IsNil)
+
+	_, err = gadget.ReadInfo(s.dir, coreMod)
+	c.Assert(err, ErrorMatches, `.*emmc-boot can only be used with "emmc" schema`)
+}
+
 func (s *gadgetYamlEMMCSuite) TestReadGadgetYamlOffsetNotSupportedForBoot(c *C) {
 	for _, t := range []string{"boot0", "boot1"} {
 		err := os.WriteFile(s.gadgetYamlPath, []byte(fmt.Sprintf(`
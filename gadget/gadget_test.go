@@ -1385,6 +1385,87 @@ func (s *gadgetYamlTestSuite) TestValidateFilesystem(c *C) {
 	}
 }
 
+func (s *gadgetYamlTestSuite) TestValidateMTDNameAndDeviceTreeCompatible(c *C) {
+	vol := &gadget.Volume{Schema: "gpt"}
+	for i, tc := range []struct {
+		s   *gadget.VolumeStructure
+		err string
+	}{
+		{&gadget.VolumeStructure{Type: "bare", Size: 123, MTDName: "u-boot"}, ""},
+		{&gadget.VolumeStructure{Type: "bare", Size: 123, DeviceTreeCompatible: []string{"vendor,board-a", "vendor,board-b"}}, ""},
+		{&gadget.VolumeStructure{Type: "21686148-6449-6E6F-744E-656564454649", Size: 123, MTDName: "u-boot"}, `mtd-name can only be used with bare structures`},
+		{&gadget.VolumeStructure{Type: "bare", Size: 123, DeviceTreeCompatible: []string{"vendor,board-a", ""}}, `invalid empty device-tree-compatible entry`},
+	} {
+		c.Logf("tc: %v %+v", i, tc.s)
+
+		tc.s.EnclosingVolume = vol
+		err := gadget.ValidateVolumeStructure(tc.s, vol)
+		if tc.err != "" {
+			c.Check(err, ErrorMatches, tc.err)
+		} else {
+			c.Check(err, IsNil)
+		}
+	}
+}
+
+func (s *gadgetYamlTestSuite) TestValidateUBIVolumes(c *C) {
+	vol := &gadget.Volume{Schema: "gpt"}
+	for i, tc := range []struct {
+		s   *gadget.VolumeStructure
+		err string
+	}{
+		{&gadget.VolumeStructure{Type: "bare", Size: 123, MTDName: "ubi", UBIVolumes: []gadget.UBIVolume{
+			{Name: "kernel", Size: 8 * quantity.SizeMiB, Image: "kernel.img"},
+			{Name: "data"},
+		}}, ""},
+		{&gadget.VolumeStructure{Type: "bare", Size: 123, UBIVolumes: []gadget.UBIVolume{{Name: "data"}}},
+			`ubi-volumes can only be used with mtd-name`},
+		{&gadget.VolumeStructure{Type: "bare", Size: 123, MTDName: "ubi", UBIVolumes: []gadget.UBIVolume{{Name: "data"}},
+			Content: []gadget.VolumeContent{{Image: "rootfs.ubi"}}}, `cannot use both ubi-volumes and content`},
+		{&gadget.VolumeStructure{Type: "bare", Size: 123, MTDName: "ubi", UBIVolumes: []gadget.UBIVolume{{Size: 123}}},
+			`invalid UBI volume #0: missing name`},
+		{&gadget.VolumeStructure{Type: "bare", Size: 123, MTDName: "ubi", UBIVolumes: []gadget.UBIVolume{{Name: "data", Size: 123}, {Name: "data"}}},
+			`invalid UBI volume #1: duplicate name "data"`},
+		{&gadget.VolumeStructure{Type: "bare", Size: 123, MTDName: "ubi", UBIVolumes: []gadget.UBIVolume{{Name: "data"}, {Name: "other", Size: 123}}},
+			`invalid UBI volume #0: only the last volume can omit its size`},
+	} {
+		c.Logf("tc: %v %+v", i, tc.s)
+
+		tc.s.EnclosingVolume = vol
+		err := gadget.ValidateVolumeStructure(tc.s, vol)
+		if tc.err != "" {
+			c.Check(err, ErrorMatches, tc.err)
+		} else {
+			c.Check(err, IsNil)
+		}
+	}
+}
+
+func (s *gadgetYamlTestSuite) TestReadGadgetYamlUBIVolumes(c *C) {
+	const yaml = `
+volumes:
+  nand:
+    schema: mbr
+    bootloader: u-boot
+    structure:
+      - name: rootfs
+        type: bare
+        size: 64M
+        mtd-name: ubi
+        ubi-volumes:
+          - name: kernel
+            size: 16M
+            image: kernel.img
+          - name: data
+`
+	ginfo, err := gadget.InfoFromGadgetYaml([]byte(yaml), nil)
+	c.Assert(err, IsNil)
+	c.Check(ginfo.Volumes["nand"].Structure[0].UBIVolumes, DeepEquals, []gadget.UBIVolume{
+		{Name: "kernel", Size: 16 * quantity.SizeMiB, Image: "kernel.img"},
+		{Name: "data"},
+	})
+}
+
 func (s *gadgetYamlTestSuite) TestValidateVolumeSchema(c *C) {
 	for i, tc := range []struct {
 		s   string
@@ -5224,6 +5305,18 @@ volumes:
             offset: 1M
 `)
 
+func (s *gadgetYamlTestSuite) TestVolumeIsMTD(c *C) {
+	c.Check((&gadget.Volume{}).IsMTD(), Equals, false)
+	c.Check((&gadget.Volume{Structure: []gadget.VolumeStructure{
+		{Name: "spl", Type: "bare", MTDName: "spl"},
+		{Name: "u-boot", Type: "bare", MTDName: "u-boot"},
+	}}).IsMTD(), Equals, true)
+	c.Check((&gadget.Volume{Structure: []gadget.VolumeStructure{
+		{Name: "spl", Type: "bare", MTDName: "spl"},
+		{Name: "ubuntu-seed", Role: gadget.SystemSeed, Filesystem: "vfat"},
+	}}).IsMTD(), Equals, false)
+}
+
 func (s *gadgetYamlTestSuite) TestVolumeCopy(c *C) {
 	for _, yaml := range [][]byte{
 		mockMultiVolumeUC20GadgetYaml,
@@ -5239,6 +5332,30 @@ func (s *gadgetYamlTestSuite) TestVolumeCopy(c *C) {
 			c.Assert(newV, DeepEquals, v)
 		}
 	}
+
+	vs := &gadget.VolumeStructure{
+		Name:                 "boot",
+		Offset:               asOffsetPtr(1024),
+		Content:              []gadget.VolumeContent{{Image: "boot.img", Offset: asOffsetPtr(512)}},
+		DeviceTreeCompatible: []string{"vendor,board-a", "vendor,board-b"},
+		EMMCBoot:             &gadget.EMMCBootConfig{Enable: true},
+		UBIVolumes:           []gadget.UBIVolume{{Name: "data"}},
+	}
+	newVs := vs.Copy()
+	c.Assert(newVs, DeepEquals, vs)
+
+	// modifying the copy does not affect the original
+	*newVs.Offset = 2048
+	*newVs.Content[0].Offset = 0
+	newVs.DeviceTreeCompatible[0] = "vendor,board-c"
+	newVs.EMMCBoot.Enable = false
+	newVs.EMMCBoot.Ack = true
+	newVs.UBIVolumes[0].Name = "other"
+	c.Check(*vs.Offset, Equals, quantity.Offset(1024))
+	c.Check(*vs.Content[0].Offset, Equals, quantity.Offset(512))
+	c.Check(vs.DeviceTreeCompatible, DeepEquals, []string{"vendor,board-a", "vendor,board-b"})
+	c.Check(vs.EMMCBoot, DeepEquals, &gadget.EMMCBootConfig{Enable: true})
+	c.Check(vs.UBIVolumes, DeepEquals, []gadget.UBIVolume{{Name: "data"}})
 }
 
 func (s *gadgetYamlTestSuite) TestLayoutCompatibilityVfatPartitions(c *C) {
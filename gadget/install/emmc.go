@@ -0,0 +1,167 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package install
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/gadget"
+	"github.com/snapcore/snapd/gadget/quantity"
+	"github.com/snapcore/snapd/logger"
+)
+
+// emmcBootPartitionNumber maps the eMMC hardware boot partitions to the
+// numbers used for them by "mmc bootpart enable".
+var emmcBootPartitionNumber = map[string]string{
+	"boot0": "1",
+	"boot1": "2",
+}
+
+// emmcDevice returns the device node of the eMMC described by the given
+// volume. Without a volume assignment, the only eMMC that has hardware boot
+// partitions is used.
+func emmcDevice(vol *gadget.Volume) (string, error) {
+	if vol.AssignedDevice != "" {
+		return filepath.EvalSymlinks(filepath.Join(dirs.GlobalRootDir, vol.AssignedDevice))
+	}
+
+	matches, err := filepath.Glob(filepath.Join(dirs.GlobalRootDir, "/dev/mmcblk*boot0"))
+	if err != nil {
+		return "", err
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no eMMC device with hardware boot partitions found")
+	case 1:
+		return strings.TrimSuffix(matches[0], "boot0"), nil
+	default:
+		return "", fmt.Errorf("found %d eMMC devices with hardware boot partitions, a volume assignment is required", len(matches))
+	}
+}
+
+// setEMMCForceReadOnly toggles the write protection the kernel applies by
+// default to eMMC hardware boot partitions.
+func setEMMCForceReadOnly(part string, ro bool) error {
+	forceRO := filepath.Join(dirs.GlobalRootDir, "/sys/class/block", filepath.Base(part), "force_ro")
+	val := "0"
+	if ro {
+		val = "1"
+	}
+	return os.WriteFile(forceRO, []byte(val), 0644)
+}
+
+func writeEMMCImages(vs *gadget.VolumeStructure, gadgetRoot, part string) error {
+	var size quantity.Size
+	for _, c := range vs.Content {
+		st, err := os.Stat(filepath.Join(gadgetRoot, c.Image))
+		if err != nil {
+			return err
+		}
+		size += quantity.Size(st.Size())
+	}
+	if vs.Size != 0 && size > vs.Size {
+		return fmt.Errorf("content size %v exceeds the structure size %v", size, vs.Size)
+	}
+
+	out, err := os.OpenFile(part, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	for _, c := range vs.Content {
+		in, err := os.Open(filepath.Join(gadgetRoot, c.Image))
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(out, in)
+		in.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return out.Sync()
+}
+
+// writeEMMCBootPartition writes the content of a structure to the eMMC
+// hardware boot partition of the same name and updates the boot
+// configuration of the device if requested by the structure.
+func writeEMMCBootPartition(vs *gadget.VolumeStructure, gadgetRoot, device string) (err error) {
+	part := device + vs.Name
+	if len(vs.Content) != 0 {
+		if err := setEMMCForceReadOnly(part, false); err != nil {
+			return fmt.Errorf("cannot disable write protection: %v", err)
+		}
+		defer func() {
+			if rerr := setEMMCForceReadOnly(part, true); rerr != nil && err == nil {
+				err = fmt.Errorf("cannot restore write protection: %v", rerr)
+			}
+		}()
+		if err := writeEMMCImages(vs, gadgetRoot, part); err != nil {
+			return err
+		}
+	}
+
+	if vs.EMMCBoot != nil && vs.EMMCBoot.Enable {
+		ack := "0"
+		if vs.EMMCBoot.Ack {
+			ack = "1"
+		}
+		if err := runFlashTool("mmc", "bootpart", "enable", emmcBootPartitionNumber[vs.Name], ack, device); err != nil {
+			return fmt.Errorf("cannot enable booting: %v", err)
+		}
+	}
+	return nil
+}
+
+// writeEMMCVolumes writes the content of the hardware boot partitions
+// described by the "emmc" schema volumes.
+func writeEMMCVolumes(volumes map[string]*gadget.Volume, gadgetRoot string) error {
+	volNames := make([]string, 0, len(volumes))
+	for name := range volumes {
+		volNames = append(volNames, name)
+	}
+	sort.Strings(volNames)
+
+	for _, volName := range volNames {
+		vol := volumes[volName]
+		if vol.Schema != "emmc" {
+			continue
+		}
+		device, err := emmcDevice(vol)
+		if err != nil {
+			return fmt.Errorf("cannot find eMMC device for volume %q: %v", volName, err)
+		}
+		for i := range vol.Structure {
+			vs := &vol.Structure[i]
+			logger.Noticef("writing structure %q to eMMC %s", vs.Name, device)
+			if err := writeEMMCBootPartition(vs, gadgetRoot, device); err != nil {
+				return fmt.Errorf("cannot write structure %q to eMMC %s: %v", vs.Name, device, err)
+			}
+		}
+	}
+	return nil
+}
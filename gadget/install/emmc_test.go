@@ -0,0 +1,146 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package install_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/gadget"
+	"github.com/snapcore/snapd/gadget/install"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type emmcTestSuite struct {
+	testutil.BaseTest
+
+	dir        string
+	gadgetRoot string
+
+	mockMmc *testutil.MockCmd
+}
+
+var _ = Suite(&emmcTestSuite{})
+
+func (s *emmcTestSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+
+	s.dir = c.MkDir()
+	dirs.SetRootDir(s.dir)
+	s.AddCleanup(func() { dirs.SetRootDir("/") })
+
+	s.gadgetRoot = c.MkDir()
+	c.Assert(os.WriteFile(filepath.Join(s.gadgetRoot, "spl.img"), []byte("spl"), 0644), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(s.gadgetRoot, "u-boot.img"), []byte("u-boot"), 0644), IsNil)
+
+	c.Assert(os.MkdirAll(filepath.Join(s.dir, "/dev"), 0755), IsNil)
+	for _, part := range []string{"mmcblk0", "mmcblk0boot0", "mmcblk0boot1"} {
+		c.Assert(os.WriteFile(filepath.Join(s.dir, "/dev", part), nil, 0644), IsNil)
+		sysDir := filepath.Join(s.dir, "/sys/class/block", part)
+		c.Assert(os.MkdirAll(sysDir, 0755), IsNil)
+		c.Assert(os.WriteFile(filepath.Join(sysDir, "force_ro"), []byte("1"), 0644), IsNil)
+	}
+
+	s.mockMmc = testutil.MockCommand(c, "mmc", "")
+	s.AddCleanup(s.mockMmc.Restore)
+}
+
+func (s *emmcTestSuite) emmcVolumes() map[string]*gadget.Volume {
+	return map[string]*gadget.Volume{
+		"pc": {
+			Schema: "gpt",
+			Structure: []gadget.VolumeStructure{
+				{Name: "ubuntu-seed", Role: gadget.SystemSeed, Filesystem: "vfat"},
+			},
+		},
+		"my-emmc": {
+			Schema: "emmc",
+			Structure: []gadget.VolumeStructure{
+				{
+					Name:     "boot0",
+					Size:     4096,
+					EMMCBoot: &gadget.EMMCBootConfig{Enable: true, Ack: true},
+					Content: []gadget.VolumeContent{
+						{Image: "spl.img"},
+						{Image: "u-boot.img"},
+					},
+				}, {
+					Name:    "boot1",
+					Size:    4096,
+					Content: []gadget.VolumeContent{{Image: "u-boot.img"}},
+				},
+			},
+		},
+	}
+}
+
+func (s *emmcTestSuite) TestWriteEMMCVolumes(c *C) {
+	err := install.WriteEMMCVolumes(s.emmcVolumes(), s.gadgetRoot)
+	c.Assert(err, IsNil)
+
+	c.Check(filepath.Join(s.dir, "/dev/mmcblk0boot0"), testutil.FileEquals, "splu-boot")
+	c.Check(filepath.Join(s.dir, "/dev/mmcblk0boot1"), testutil.FileEquals, "u-boot")
+	// write protection is restored
+	c.Check(filepath.Join(s.dir, "/sys/class/block/mmcblk0boot0/force_ro"), testutil.FileEquals, "1")
+	c.Check(filepath.Join(s.dir, "/sys/class/block/mmcblk0boot1/force_ro"), testutil.FileEquals, "1")
+	c.Check(s.mockMmc.Calls(), DeepEquals, [][]string{
+		{"mmc", "bootpart", "enable", "1", "1", filepath.Join(s.dir, "/dev/mmcblk0")},
+	})
+}
+
+func (s *emmcTestSuite) TestWriteEMMCVolumesAssignedDevice(c *C) {
+	byPath := filepath.Join(s.dir, "/dev/disk/by-path")
+	c.Assert(os.MkdirAll(byPath, 0755), IsNil)
+	c.Assert(os.Symlink("../../mmcblk0", filepath.Join(byPath, "platform-fe340000.mmc")), IsNil)
+	// another eMMC which would make the device ambiguous otherwise
+	c.Assert(os.WriteFile(filepath.Join(s.dir, "/dev/mmcblk1boot0"), nil, 0644), IsNil)
+
+	volumes := s.emmcVolumes()
+	volumes["my-emmc"].AssignedDevice = "/dev/disk/by-path/platform-fe340000.mmc"
+	volumes["my-emmc"].Structure[0].EMMCBoot.Ack = false
+
+	err := install.WriteEMMCVolumes(volumes, s.gadgetRoot)
+	c.Assert(err, IsNil)
+	c.Check(filepath.Join(s.dir, "/dev/mmcblk0boot0"), testutil.FileEquals, "splu-boot")
+	c.Check(s.mockMmc.Calls(), DeepEquals, [][]string{
+		{"mmc", "bootpart", "enable", "1", "0", filepath.Join(s.dir, "/dev/mmcblk0")},
+	})
+}
+
+func (s *emmcTestSuite) TestWriteEMMCVolumesErrors(c *C) {
+	c.Assert(os.WriteFile(filepath.Join(s.dir, "/dev/mmcblk1boot0"), nil, 0644), IsNil)
+	err := install.WriteEMMCVolumes(s.emmcVolumes(), s.gadgetRoot)
+	c.Check(err, ErrorMatches, `cannot find eMMC device for volume "my-emmc": found 2 eMMC devices with hardware boot partitions, a volume assignment is required`)
+	c.Assert(os.Remove(filepath.Join(s.dir, "/dev/mmcblk1boot0")), IsNil)
+
+	volumes := s.emmcVolumes()
+	volumes["my-emmc"].Structure[1].Size = 4
+	err = install.WriteEMMCVolumes(volumes, s.gadgetRoot)
+	c.Check(err, ErrorMatches, `cannot write structure "boot1" to eMMC .*/dev/mmcblk0: content size 6 exceeds the structure size 4`)
+	c.Check(filepath.Join(s.dir, "/sys/class/block/mmcblk0boot1/force_ro"), testutil.FileEquals, "1")
+
+	mockMmc := testutil.MockCommand(c, "mmc", "echo 'permission denied'; exit 1")
+	defer mockMmc.Restore()
+	err = install.WriteEMMCVolumes(s.emmcVolumes(), s.gadgetRoot)
+	c.Check(err, ErrorMatches, `cannot write structure "boot0" to eMMC .*/dev/mmcblk0: cannot enable booting: permission denied`)
+}
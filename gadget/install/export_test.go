@@ -42,6 +42,9 @@ var (
 
 	CreatedDuringInstall        = createdDuringInstall
 	TestCreateMissingPartitions = createMissingPartitions
+
+	WriteMTDStructures = writeMTDStructures
+	WriteEMMCVolumes   = writeEMMCVolumes
 )
 
 func MockSysMount(f func(source, target, fstype string, flags uintptr, data string) error) (restore func()) {
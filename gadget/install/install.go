@@ -379,7 +379,22 @@ func Run(model gadget.Model, gadgetRoot string, kernelSnapInfo *KernelSnapInfo,
 		return nil, err
 	}
 
-	// Step 2: layout content in the created partitions
+	// Step 2: write the structures living on raw flash and on eMMC
+	// hardware boot partitions
+	timings.Run(perfTimings, "write-mtd-structures", "Write structures to MTD partitions", func(timings.Measurer) {
+		err = writeMTDStructures(volumes, gadgetRoot)
+	})
+	if err != nil {
+		return nil, err
+	}
+	timings.Run(perfTimings, "write-emmc-volumes", "Write eMMC hardware boot partitions", func(timings.Measurer) {
+		err = writeEMMCVolumes(volumes, gadgetRoot)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 3: layout content in the created partitions
 	var keyForRole map[string]keys.EncryptionKey
 	devicesForRoles := map[string]string{}
 	partsEncrypted := map[string]gadget.StructureEncryptionParameters{}
@@ -526,12 +541,24 @@ func deviceForMaybeEncryptedVolume(volStruct *gadget.VolumeStructure, encSetupDa
 
 // WriteContent writes gadget content to the devices specified in
 // onVolumes. It returns the resolved on disk volumes.
-func WriteContent(onVolumes map[string]*gadget.Volume, allLaidOutVols map[string]*gadget.LaidOutVolume, encSetupData *EncryptionSetupData, kSnapInfo *KernelSnapInfo, observer gadget.ContentObserver, perfTimings timings.Measurer) ([]*gadget.OnDiskVolume, error) {
+func WriteContent(onVolumes map[string]*gadget.Volume, allLaidOutVols map[string]*gadget.LaidOutVolume, gadgetRoot string, encSetupData *EncryptionSetupData, kSnapInfo *KernelSnapInfo, observer gadget.ContentObserver, perfTimings timings.Measurer) ([]*gadget.OnDiskVolume, error) {
 	// TODO this taking onVolumes and allLaidOutVols is odd,
 	// we should try to avoid this when we have partial
 
+	var err error
+	timings.Run(perfTimings, "write-mtd-structures", "Write structures to MTD partitions", func(timings.Measurer) {
+		err = writeMTDStructures(onVolumes, gadgetRoot)
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	var onDiskVols []*gadget.OnDiskVolume
 	for volName, vol := range onVolumes {
+		// volumes living only on raw flash have no disk
+		if vol.IsMTD() {
+			continue
+		}
 		onDiskVol, err := gadget.OnDiskVolumeFromGadgetVol(vol)
 		if err != nil {
 			return nil, err
@@ -849,6 +876,14 @@ func FactoryReset(model gadget.Model, gadgetRoot string, kernelSnapInfo *KernelS
 		}
 	}
 
+	// the structures on raw flash are reset to the gadget content as well
+	timings.Run(perfTimings, "write-mtd-structures", "Write structures to MTD partitions", func(timings.Measurer) {
+		err = writeMTDStructures(volumes, gadgetRoot)
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	// after we have created all partitions, build up the mapping of volumes
 	// to disk device traits and save it to disk for later usage
 	optsPerVol := map[string]*gadget.DiskVolumeValidationOptions{
@@ -877,8 +877,22 @@ type factoryResetOpts struct {
 	traits            map[string]gadget.DiskVolumeDeviceTraits
 	fromSeed          bool
 	volumeAssignments bool
+	mtd               bool
 }
 
+const mtdVolumeYaml = `
+  nand:
+    schema: mbr
+    structure:
+    - name: u-boot
+      type: bare
+      size: 1M
+      mtd-name: bootloader
+      content:
+      - image: spl.img
+      - image: u-boot.img
+`
+
 func (s *installSuite) testFactoryReset(c *C, opts factoryResetOpts) {
 	uc20Mod := &gadgettest.ModelCharacteristics{
 		HasModes: true,
@@ -1056,8 +1070,25 @@ fi
 	})
 	defer restore()
 
-	gadgetRoot, err := gadgettest.WriteGadgetYaml(c.MkDir(), opts.gadgetYaml)
+	gadgetYaml := opts.gadgetYaml
+	var mockNandwrite *testutil.MockCmd
+	if opts.mtd {
+		gadgetYaml += mtdVolumeYaml
+		mtdDir := filepath.Join(s.dir, "/sys/class/mtd/mtd0")
+		c.Assert(os.MkdirAll(mtdDir, 0755), IsNil)
+		c.Assert(os.WriteFile(filepath.Join(mtdDir, "name"), []byte("bootloader\n"), 0644), IsNil)
+		c.Assert(os.WriteFile(filepath.Join(mtdDir, "type"), []byte("nand\n"), 0644), IsNil)
+		mockFlashErase := testutil.MockCommand(c, "flash_erase", "")
+		defer mockFlashErase.Restore()
+		mockNandwrite = testutil.MockCommand(c, "nandwrite", "")
+		defer mockNandwrite.Restore()
+	}
+	gadgetRoot, err := gadgettest.WriteGadgetYaml(c.MkDir(), gadgetYaml)
 	c.Assert(err, IsNil)
+	if opts.mtd {
+		c.Assert(os.WriteFile(filepath.Join(gadgetRoot, "spl.img"), []byte("spl"), 0644), IsNil)
+		c.Assert(os.WriteFile(filepath.Join(gadgetRoot, "u-boot.img"), []byte("u-boot"), 0644), IsNil)
+	}
 
 	var dataPrimaryKey keys.EncryptionKey
 	secbootFormatEncryptedDeviceCall := 0
@@ -1122,6 +1153,14 @@ fi
 	c.Assert(mockSfdisk.Calls(), HasLen, 0)
 	c.Assert(mockPartx.Calls(), HasLen, 0)
 
+	if opts.mtd {
+		mtd0 := filepath.Join(s.dir, "/dev/mtd0")
+		c.Check(mockNandwrite.Calls(), DeepEquals, [][]string{
+			{"nandwrite", "--quiet", "--pad", mtd0, filepath.Join(gadgetRoot, "spl.img")},
+			{"nandwrite", "--quiet", "--pad", "--start=3", mtd0, filepath.Join(gadgetRoot, "u-boot.img")},
+		})
+	}
+
 	udevmadmCalls := [][]string{}
 
 	if opts.fromSeed {
@@ -1191,6 +1230,22 @@ func (s *installSuite) TestFactoryResetHappyWithExisting(c *C) {
 	})
 }
 
+func (s *installSuite) TestFactoryResetHappyWithMTD(c *C) {
+	s.testFactoryReset(c, factoryResetOpts{
+		diskMappings: map[string]*disks.MockDiskMapping{
+			"mmcblk0": gadgettest.ExpectedRaspiMockDiskMapping,
+		},
+		disks:      defaultDiskSetup,
+		gadgetYaml: gadgettest.RaspiSimplifiedYaml,
+		traitsJSON: gadgettest.ExpectedRaspiDiskVolumeDeviceTraitsJSON,
+		// the volume on raw flash has no disk traits
+		traits: map[string]gadget.DiskVolumeDeviceTraits{
+			"pi": gadgettest.ExpectedRaspiDiskVolumeDeviceTraits,
+		},
+		mtd: true,
+	})
+}
+
 func (s *installSuite) TestFactoryResetHappyWithoutDataAndBoot(c *C) {
 	s.testFactoryReset(c, factoryResetOpts{
 		diskMappings: map[string]*disks.MockDiskMapping{
@@ -1424,7 +1479,7 @@ func (s *installSuite) testWriteContent(c *C, opts writeContentOpts) {
 		}
 		esd = install.MockEncryptionSetupData(labelToEncData)
 	}
-	onDiskVols, err := install.WriteContent(ginfo.Volumes, allLaidOutVols, esd, nil, nil, timings.New(nil))
+	onDiskVols, err := install.WriteContent(ginfo.Volumes, allLaidOutVols, gadgetRoot, esd, nil, nil, timings.New(nil))
 	c.Assert(err, IsNil)
 	c.Assert(len(onDiskVols), Equals, 1)
 
@@ -1463,7 +1518,7 @@ func (s *installSuite) TestInstallWriteContentDeviceNotFound(c *C) {
 			},
 		},
 	}
-	onDiskVols, err := install.WriteContent(vols, nil, nil, nil, nil, timings.New(nil))
+	onDiskVols, err := install.WriteContent(vols, nil, "", nil, nil, nil, timings.New(nil))
 	c.Check(err.Error(), testutil.Contains, "readlink /sys/class/block/randomdev: no such file or directory")
 	c.Check(onDiskVols, IsNil)
 }
@@ -0,0 +1,238 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package install
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/gadget"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/osutil"
+)
+
+// ubiImageMagic is the magic of the UBI erase counter header, which
+// starts images created by ubinize.
+var ubiImageMagic = []byte("UBI#")
+
+func isUBIImage(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	magic := make([]byte, len(ubiImageMagic))
+	if _, err := io.ReadFull(f, magic); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return false, nil
+		}
+		return false, err
+	}
+	return bytes.Equal(magic, ubiImageMagic), nil
+}
+
+func runFlashTool(name string, args ...string) error {
+	if output, err := exec.Command(name, args...).CombinedOutput(); err != nil {
+		return osutil.OutputErr(output, err)
+	}
+	return nil
+}
+
+// mtdType returns the flash type of the given MTD device as reported by the
+// kernel, e.g. "nand", "mlc-nand" or "nor".
+func mtdType(device string) (string, error) {
+	typeFile := filepath.Join(dirs.GlobalRootDir, "/sys/class/mtd", filepath.Base(device), "type")
+	b, err := os.ReadFile(typeFile)
+	if err != nil {
+		return "", fmt.Errorf("cannot read MTD device type: %v", err)
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// ubiDeviceNumberRe matches the number of the UBI device reported by
+// ubiattach.
+var ubiDeviceNumberRe = regexp.MustCompile(`UBI device number ([0-9]+)`)
+
+// writeUBIVolumes formats the given MTD device as a UBI device, which skips
+// the bad blocks of the flash, and creates in it the UBI volumes of the
+// structure, writing their images if any.
+func writeUBIVolumes(vs *gadget.VolumeStructure, gadgetRoot, device string) (err error) {
+	if err := runFlashTool("ubiformat", device, "--yes"); err != nil {
+		return err
+	}
+	output, err := exec.Command("ubiattach", "--dev-path="+device).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("cannot attach UBI device: %v", osutil.OutputErr(output, err))
+	}
+	m := ubiDeviceNumberRe.FindSubmatch(output)
+	if m == nil {
+		return fmt.Errorf("cannot find UBI device number in %q", output)
+	}
+	defer func() {
+		if detachErr := runFlashTool("ubidetach", "--dev-path="+device); detachErr != nil && err == nil {
+			err = fmt.Errorf("cannot detach UBI device: %v", detachErr)
+		}
+	}()
+
+	ubiDevice := filepath.Join(dirs.GlobalRootDir, "/dev", "ubi"+string(m[1]))
+	for i, uv := range vs.UBIVolumes {
+		args := []string{ubiDevice, fmt.Sprintf("--vol_id=%d", i), "--name=" + uv.Name}
+		if uv.Size != 0 {
+			args = append(args, fmt.Sprintf("--size=%d", uv.Size))
+		} else {
+			args = append(args, "--maxavsize")
+		}
+		if err := runFlashTool("ubimkvol", args...); err != nil {
+			return fmt.Errorf("cannot create UBI volume %q: %v", uv.Name, err)
+		}
+		if uv.Image == "" {
+			continue
+		}
+		volDevice := fmt.Sprintf("%s_%d", ubiDevice, i)
+		if err := runFlashTool("ubiupdatevol", volDevice, filepath.Join(gadgetRoot, uv.Image)); err != nil {
+			return fmt.Errorf("cannot write UBI volume %q: %v", uv.Name, err)
+		}
+	}
+	return nil
+}
+
+// layoutMTDStructure lays out the content of a structure backed by an MTD
+// partition, which starts at the beginning of its MTD device.
+func layoutMTDStructure(vs *gadget.VolumeStructure, gadgetRoot string) (*gadget.LaidOutStructure, error) {
+	return gadget.LayoutVolumeStructure(&gadget.OnDiskAndGadgetStructurePair{
+		DiskStructure:   &gadget.OnDiskStructure{Name: vs.Name, Size: vs.Size},
+		GadgetStructure: vs,
+	}, nil, &gadget.LayoutOptions{GadgetRootDir: gadgetRoot, SkipResolveContent: true})
+}
+
+// writeMTDStructure writes the content of a structure to the given MTD
+// device. UBI volumes of the structure are created with the ubi tools. A
+// single UBI image is written with ubiformat, which also preserves the erase
+// counters of the flash. NAND flash may contain bad blocks, which nandwrite
+// knows to skip, so other images are written with it after erasing the
+// device. NOR flash has no bad blocks nor out of band data, a single image is
+// written with flashcp, which also verifies it, and several images are
+// written with dd after erasing the device. Images are written at the offsets
+// of the laid out content.
+func writeMTDStructure(vs *gadget.VolumeStructure, gadgetRoot, device string) error {
+	if len(vs.UBIVolumes) != 0 {
+		return writeUBIVolumes(vs, gadgetRoot, device)
+	}
+
+	flashType, err := mtdType(device)
+	if err != nil {
+		return err
+	}
+	nor := flashType == "nor"
+
+	if len(vs.Content) == 1 && (vs.Content[0].Offset == nil || *vs.Content[0].Offset == 0) {
+		image := filepath.Join(gadgetRoot, vs.Content[0].Image)
+		ubi, err := isUBIImage(image)
+		if err != nil {
+			return err
+		}
+		if ubi {
+			return runFlashTool("ubiformat", device, "--yes", "--flash-image="+image)
+		}
+		if nor {
+			return runFlashTool("flashcp", image, device)
+		}
+	}
+
+	laidOut, err := layoutMTDStructure(vs, gadgetRoot)
+	if err != nil {
+		return err
+	}
+	if err := runFlashTool("flash_erase", "--quiet", device, "0", "0"); err != nil {
+		return err
+	}
+	for _, c := range laidOut.LaidOutContent {
+		image := filepath.Join(gadgetRoot, c.Image)
+		if nor {
+			args := []string{"if=" + image, "of=" + device, "conv=fsync", "status=none"}
+			if c.StartOffset != 0 {
+				args = append(args, "oflag=seek_bytes", fmt.Sprintf("seek=%d", c.StartOffset))
+			}
+			if err := runFlashTool("dd", args...); err != nil {
+				return err
+			}
+			continue
+		}
+		args := []string{"--quiet", "--pad"}
+		if c.StartOffset != 0 {
+			args = append(args, fmt.Sprintf("--start=%d", c.StartOffset))
+		}
+		args = append(args, device, image)
+		if err := runFlashTool("nandwrite", args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeMTDStructures writes the content of the structures of the given
+// volumes which are backed by MTD partitions. Structures restricted to boards
+// with different device tree compatible strings are skipped, while a missing
+// MTD partition for any other structure is an error.
+func writeMTDStructures(volumes map[string]*gadget.Volume, gadgetRoot string) error {
+	volNames := make([]string, 0, len(volumes))
+	for name := range volumes {
+		volNames = append(volNames, name)
+	}
+	sort.Strings(volNames)
+
+	for _, volName := range volNames {
+		vol := volumes[volName]
+		for i := range vol.Structure {
+			vs := &vol.Structure[i]
+			if vs.MTDName == "" {
+				continue
+			}
+			forBoard, err := gadget.StructureIsForBoard(vs)
+			if err != nil {
+				return err
+			}
+			if !forBoard {
+				logger.Noticef("skipping structure %q not meant for this board", vs.Name)
+				continue
+			}
+			// the structure is meant for this board, a missing MTD
+			// partition is an error
+			device, err := gadget.FindDeviceForStructure(vs)
+			if err != nil {
+				return fmt.Errorf("cannot find MTD partition %q for structure %q: %v", vs.MTDName, vs.Name, err)
+			}
+			logger.Noticef("writing structure %q to MTD partition %q (%s)", vs.Name, vs.MTDName, device)
+			if err := writeMTDStructure(vs, gadgetRoot, device); err != nil {
+				return fmt.Errorf("cannot write structure %q to MTD partition %q: %v", vs.Name, vs.MTDName, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,429 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package install_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/gadget"
+	"github.com/snapcore/snapd/gadget/install"
+	"github.com/snapcore/snapd/gadget/quantity"
+	"github.com/snapcore/snapd/testutil"
+	"github.com/snapcore/snapd/timings"
+)
+
+type mtdTestSuite struct {
+	testutil.BaseTest
+
+	dir        string
+	gadgetRoot string
+
+	mockFlashErase *testutil.MockCmd
+	mockNandwrite  *testutil.MockCmd
+	mockUbiformat  *testutil.MockCmd
+	mockFlashcp    *testutil.MockCmd
+	mockDd         *testutil.MockCmd
+	mockUbiattach  *testutil.MockCmd
+	mockUbidetach  *testutil.MockCmd
+	mockUbimkvol   *testutil.MockCmd
+	mockUbiupdate  *testutil.MockCmd
+}
+
+var _ = Suite(&mtdTestSuite{})
+
+func (s *mtdTestSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+
+	s.dir = c.MkDir()
+	dirs.SetRootDir(s.dir)
+	s.AddCleanup(func() { dirs.SetRootDir("/") })
+
+	s.gadgetRoot = c.MkDir()
+	c.Assert(os.WriteFile(filepath.Join(s.gadgetRoot, "spl.img"), []byte("spl"), 0644), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(s.gadgetRoot, "u-boot.img"), []byte("u-boot"), 0644), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(s.gadgetRoot, "rootfs.ubi"), []byte("UBI#\x01\x00\x00\x00"), 0644), IsNil)
+
+	s.mockMTDPartition(c, "mtd0", "bootloader", "nand")
+	s.mockMTDPartition(c, "mtd1", "ubi", "nand")
+
+	s.mockFlashErase = testutil.MockCommand(c, "flash_erase", "")
+	s.AddCleanup(s.mockFlashErase.Restore)
+	s.mockNandwrite = testutil.MockCommand(c, "nandwrite", "")
+	s.AddCleanup(s.mockNandwrite.Restore)
+	s.mockUbiformat = testutil.MockCommand(c, "ubiformat", "")
+	s.AddCleanup(s.mockUbiformat.Restore)
+	s.mockFlashcp = testutil.MockCommand(c, "flashcp", "")
+	s.AddCleanup(s.mockFlashcp.Restore)
+	s.mockDd = testutil.MockCommand(c, "dd", "")
+	s.AddCleanup(s.mockDd.Restore)
+	s.mockUbiattach = testutil.MockCommand(c, "ubiattach", `echo 'UBI device number 3, total 1024 LEBs (130023424 bytes, 124.0 MiB)'`)
+	s.AddCleanup(s.mockUbiattach.Restore)
+	s.mockUbidetach = testutil.MockCommand(c, "ubidetach", "")
+	s.AddCleanup(s.mockUbidetach.Restore)
+	s.mockUbimkvol = testutil.MockCommand(c, "ubimkvol", "")
+	s.AddCleanup(s.mockUbimkvol.Restore)
+	s.mockUbiupdate = testutil.MockCommand(c, "ubiupdatevol", "")
+	s.AddCleanup(s.mockUbiupdate.Restore)
+}
+
+func (s *mtdTestSuite) mockMTDPartition(c *C, mtd, name, flashType string) {
+	mtdDir := filepath.Join(s.dir, "/sys/class/mtd", mtd)
+	c.Assert(os.MkdirAll(mtdDir, 0755), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(mtdDir, "name"), []byte(name+"\n"), 0644), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(mtdDir, "type"), []byte(flashType+"\n"), 0644), IsNil)
+}
+
+func (s *mtdTestSuite) TestWriteMTDStructures(c *C) {
+	offset := quantity.Offset(0x20000)
+	volumes := map[string]*gadget.Volume{
+		"pc": {
+			Structure: []gadget.VolumeStructure{
+				{Name: "ubuntu-seed", Role: gadget.SystemSeed, Filesystem: "vfat"},
+			},
+		},
+		"nand": {
+			Structure: []gadget.VolumeStructure{
+				{
+					Name:    "bootloader",
+					Type:    "bare",
+					Size:    quantity.SizeMiB,
+					MTDName: "bootloader",
+					Content: []gadget.VolumeContent{
+						{Image: "spl.img"},
+						{Image: "u-boot.img", Offset: &offset},
+					},
+				}, {
+					Name:    "rootfs",
+					Type:    "bare",
+					Size:    quantity.SizeMiB,
+					MTDName: "ubi",
+					Content: []gadget.VolumeContent{
+						{Image: "rootfs.ubi"},
+					},
+				},
+			},
+		},
+	}
+
+	err := install.WriteMTDStructures(volumes, s.gadgetRoot)
+	c.Assert(err, IsNil)
+
+	mtd0 := filepath.Join(s.dir, "/dev/mtd0")
+	mtd1 := filepath.Join(s.dir, "/dev/mtd1")
+	c.Check(s.mockFlashErase.Calls(), DeepEquals, [][]string{
+		{"flash_erase", "--quiet", mtd0, "0", "0"},
+	})
+	c.Check(s.mockNandwrite.Calls(), DeepEquals, [][]string{
+		{"nandwrite", "--quiet", "--pad", mtd0, filepath.Join(s.gadgetRoot, "spl.img")},
+		{"nandwrite", "--quiet", "--pad", "--start=131072", mtd0, filepath.Join(s.gadgetRoot, "u-boot.img")},
+	})
+	// UBI images are written preserving the erase counters
+	c.Check(s.mockUbiformat.Calls(), DeepEquals, [][]string{
+		{"ubiformat", mtd1, "--yes", "--flash-image=" + filepath.Join(s.gadgetRoot, "rootfs.ubi")},
+	})
+}
+
+func (s *mtdTestSuite) TestWriteMTDStructuresNOR(c *C) {
+	s.mockMTDPartition(c, "mtd2", "spi-spl", "nor")
+	s.mockMTDPartition(c, "mtd3", "spi-u-boot", "nor")
+
+	offset := quantity.Offset(0x20000)
+	volumes := map[string]*gadget.Volume{
+		"nor": {
+			Structure: []gadget.VolumeStructure{
+				{
+					Name:    "spl",
+					Type:    "bare",
+					Size:    quantity.SizeMiB,
+					MTDName: "spi-spl",
+					Content: []gadget.VolumeContent{{Image: "spl.img"}},
+				}, {
+					Name:    "u-boot",
+					Type:    "bare",
+					Size:    quantity.SizeMiB,
+					MTDName: "spi-u-boot",
+					Content: []gadget.VolumeContent{
+						{Image: "spl.img"},
+						{Image: "u-boot.img", Offset: &offset},
+					},
+				},
+			},
+		},
+	}
+
+	err := install.WriteMTDStructures(volumes, s.gadgetRoot)
+	c.Assert(err, IsNil)
+
+	mtd2 := filepath.Join(s.dir, "/dev/mtd2")
+	mtd3 := filepath.Join(s.dir, "/dev/mtd3")
+	// a single image is written and verified by flashcp
+	c.Check(s.mockFlashcp.Calls(), DeepEquals, [][]string{
+		{"flashcp", filepath.Join(s.gadgetRoot, "spl.img"), mtd2},
+	})
+	// images at offsets are written with dd to the erased device
+	c.Check(s.mockFlashErase.Calls(), DeepEquals, [][]string{
+		{"flash_erase", "--quiet", mtd3, "0", "0"},
+	})
+	c.Check(s.mockDd.Calls(), DeepEquals, [][]string{
+		{"dd", "if=" + filepath.Join(s.gadgetRoot, "spl.img"), "of=" + mtd3, "conv=fsync", "status=none"},
+		{"dd", "if=" + filepath.Join(s.gadgetRoot, "u-boot.img"), "of=" + mtd3, "conv=fsync", "status=none", "oflag=seek_bytes", "seek=131072"},
+	})
+	c.Check(s.mockNandwrite.Calls(), HasLen, 0)
+}
+
+func (s *mtdTestSuite) TestWriteMTDStructuresContentWithoutOffsets(c *C) {
+	s.mockMTDPartition(c, "mtd2", "spi-u-boot", "nor")
+
+	content := []gadget.VolumeContent{
+		{Image: "spl.img"},
+		{Image: "u-boot.img"},
+		{Image: "spl.img"},
+	}
+	volumes := map[string]*gadget.Volume{
+		"flash": {
+			Structure: []gadget.VolumeStructure{
+				{
+					Name:    "nand-u-boot",
+					Type:    "bare",
+					Size:    quantity.SizeMiB,
+					MTDName: "bootloader",
+					Content: content,
+				}, {
+					Name:    "nor-u-boot",
+					Type:    "bare",
+					Size:    quantity.SizeMiB,
+					MTDName: "spi-u-boot",
+					Content: content,
+				},
+			},
+		},
+	}
+
+	err := install.WriteMTDStructures(volumes, s.gadgetRoot)
+	c.Assert(err, IsNil)
+
+	mtd0 := filepath.Join(s.dir, "/dev/mtd0")
+	mtd2 := filepath.Join(s.dir, "/dev/mtd2")
+	spl := filepath.Join(s.gadgetRoot, "spl.img")
+	uBoot := filepath.Join(s.gadgetRoot, "u-boot.img")
+	c.Check(s.mockFlashErase.Calls(), DeepEquals, [][]string{
+		{"flash_erase", "--quiet", mtd0, "0", "0"},
+		{"flash_erase", "--quiet", mtd2, "0", "0"},
+	})
+	// each image follows the previous one
+	c.Check(s.mockNandwrite.Calls(), DeepEquals, [][]string{
+		{"nandwrite", "--quiet", "--pad", mtd0, spl},
+		{"nandwrite", "--quiet", "--pad", "--start=3", mtd0, uBoot},
+		{"nandwrite", "--quiet", "--pad", "--start=9", mtd0, spl},
+	})
+	c.Check(s.mockDd.Calls(), DeepEquals, [][]string{
+		{"dd", "if=" + spl, "of=" + mtd2, "conv=fsync", "status=none"},
+		{"dd", "if=" + uBoot, "of=" + mtd2, "conv=fsync", "status=none", "oflag=seek_bytes", "seek=3"},
+		{"dd", "if=" + spl, "of=" + mtd2, "conv=fsync", "status=none", "oflag=seek_bytes", "seek=9"},
+	})
+}
+
+func (s *mtdTestSuite) TestWriteMTDStructuresContentTooLarge(c *C) {
+	volumes := map[string]*gadget.Volume{
+		"nand": {
+			Structure: []gadget.VolumeStructure{
+				{
+					Name:    "bootloader",
+					Type:    "bare",
+					Size:    8,
+					MTDName: "bootloader",
+					Content: []gadget.VolumeContent{
+						{Image: "spl.img"},
+						{Image: "u-boot.img"},
+					},
+				},
+			},
+		},
+	}
+
+	err := install.WriteMTDStructures(volumes, s.gadgetRoot)
+	c.Check(err, ErrorMatches, `cannot write structure "bootloader" to MTD partition "bootloader": cannot lay out structure .*: content "u-boot.img" does not fit in the structure`)
+	c.Check(s.mockFlashErase.Calls(), HasLen, 0)
+	c.Check(s.mockNandwrite.Calls(), HasLen, 0)
+}
+
+func (s *mtdTestSuite) TestWriteMTDStructuresUBIVolumes(c *C) {
+	c.Assert(os.WriteFile(filepath.Join(s.gadgetRoot, "kernel.img"), []byte("kernel"), 0644), IsNil)
+
+	volumes := map[string]*gadget.Volume{
+		"nand": {
+			Structure: []gadget.VolumeStructure{
+				{
+					Name:    "rootfs",
+					Type:    "bare",
+					Size:    quantity.SizeMiB,
+					MTDName: "ubi",
+					UBIVolumes: []gadget.UBIVolume{
+						{Name: "kernel", Size: 16 * quantity.SizeKiB, Image: "kernel.img"},
+						{Name: "data"},
+					},
+				},
+			},
+		},
+	}
+
+	err := install.WriteMTDStructures(volumes, s.gadgetRoot)
+	c.Assert(err, IsNil)
+
+	mtd1 := filepath.Join(s.dir, "/dev/mtd1")
+	ubi3 := filepath.Join(s.dir, "/dev/ubi3")
+	c.Check(s.mockUbiformat.Calls(), DeepEquals, [][]string{
+		{"ubiformat", mtd1, "--yes"},
+	})
+	c.Check(s.mockUbiattach.Calls(), DeepEquals, [][]string{
+		{"ubiattach", "--dev-path=" + mtd1},
+	})
+	c.Check(s.mockUbimkvol.Calls(), DeepEquals, [][]string{
+		{"ubimkvol", ubi3, "--vol_id=0", "--name=kernel", "--size=16384"},
+		{"ubimkvol", ubi3, "--vol_id=1", "--name=data", "--maxavsize"},
+	})
+	c.Check(s.mockUbiupdate.Calls(), DeepEquals, [][]string{
+		{"ubiupdatevol", ubi3 + "_0", filepath.Join(s.gadgetRoot, "kernel.img")},
+	})
+	c.Check(s.mockUbidetach.Calls(), DeepEquals, [][]string{
+		{"ubidetach", "--dev-path=" + mtd1},
+	})
+	c.Check(s.mockFlashErase.Calls(), HasLen, 0)
+	c.Check(s.mockNandwrite.Calls(), HasLen, 0)
+}
+
+func (s *mtdTestSuite) TestWriteMTDStructuresUBIVolumesErrors(c *C) {
+	volumes := map[string]*gadget.Volume{
+		"nand": {
+			Structure: []gadget.VolumeStructure{
+				{
+					Name:       "rootfs",
+					Type:       "bare",
+					Size:       quantity.SizeMiB,
+					MTDName:    "ubi",
+					UBIVolumes: []gadget.UBIVolume{{Name: "data"}},
+				},
+			},
+		},
+	}
+
+	mockUbimkvol := testutil.MockCommand(c, "ubimkvol", "echo 'no space'; exit 1")
+	defer mockUbimkvol.Restore()
+	err := install.WriteMTDStructures(volumes, s.gadgetRoot)
+	c.Check(err, ErrorMatches, `cannot write structure "rootfs" to MTD partition "ubi": cannot create UBI volume "data": no space`)
+	// the UBI device is detached on errors too
+	c.Check(s.mockUbidetach.Calls(), HasLen, 1)
+
+	mockUbiattach := testutil.MockCommand(c, "ubiattach", "echo 'attached'")
+	defer mockUbiattach.Restore()
+	err = install.WriteMTDStructures(volumes, s.gadgetRoot)
+	c.Check(err, ErrorMatches, `cannot write structure "rootfs" to MTD partition "ubi": cannot find UBI device number in "attached\\n"`)
+}
+
+func (s *mtdTestSuite) TestWriteContentMTDVolume(c *C) {
+	volumes := map[string]*gadget.Volume{
+		"nand": {
+			Structure: []gadget.VolumeStructure{
+				{
+					Name:    "bootloader",
+					Type:    "bare",
+					Size:    quantity.SizeMiB,
+					MTDName: "bootloader",
+					Content: []gadget.VolumeContent{
+						{Image: "spl.img"},
+						{Image: "u-boot.img"},
+					},
+				},
+			},
+		},
+	}
+
+	onDiskVols, err := install.WriteContent(volumes, nil, s.gadgetRoot, nil, nil, nil, timings.New(nil))
+	c.Assert(err, IsNil)
+	// a volume on raw flash has no disk
+	c.Check(onDiskVols, HasLen, 0)
+
+	mtd0 := filepath.Join(s.dir, "/dev/mtd0")
+	c.Check(s.mockNandwrite.Calls(), DeepEquals, [][]string{
+		{"nandwrite", "--quiet", "--pad", mtd0, filepath.Join(s.gadgetRoot, "spl.img")},
+		{"nandwrite", "--quiet", "--pad", "--start=3", mtd0, filepath.Join(s.gadgetRoot, "u-boot.img")},
+	})
+}
+
+func (s *mtdTestSuite) TestWriteMTDStructuresOtherBoard(c *C) {
+	dtDir := filepath.Join(s.dir, "/proc/device-tree")
+	c.Assert(os.MkdirAll(dtDir, 0755), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(dtDir, "compatible"), []byte("vendor,board-a\x00"), 0644), IsNil)
+
+	volumes := map[string]*gadget.Volume{
+		"nand": {
+			Structure: []gadget.VolumeStructure{
+				{
+					Name:                 "bootloader",
+					Type:                 "bare",
+					MTDName:              "bootloader",
+					DeviceTreeCompatible: []string{"vendor,board-b"},
+					Content:              []gadget.VolumeContent{{Image: "u-boot.img"}},
+				},
+			},
+		},
+	}
+
+	err := install.WriteMTDStructures(volumes, s.gadgetRoot)
+	c.Assert(err, IsNil)
+	c.Check(s.mockFlashErase.Calls(), HasLen, 0)
+	c.Check(s.mockNandwrite.Calls(), HasLen, 0)
+}
+
+func (s *mtdTestSuite) TestWriteMTDStructuresErrors(c *C) {
+	volumes := map[string]*gadget.Volume{
+		"nand": {
+			Structure: []gadget.VolumeStructure{
+				{
+					Name:    "env",
+					Type:    "bare",
+					Size:    quantity.SizeMiB,
+					MTDName: "u-boot-env",
+					Content: []gadget.VolumeContent{{Image: "u-boot.img"}},
+				},
+			},
+		},
+	}
+	err := install.WriteMTDStructures(volumes, s.gadgetRoot)
+	c.Check(err, ErrorMatches, `cannot find MTD partition "u-boot-env" for structure "env": device not found`)
+
+	// a structure meant for this board must have its MTD partition
+	dtDir := filepath.Join(s.dir, "/proc/device-tree")
+	c.Assert(os.MkdirAll(dtDir, 0755), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(dtDir, "compatible"), []byte("vendor,board-a\x00"), 0644), IsNil)
+	volumes["nand"].Structure[0].DeviceTreeCompatible = []string{"vendor,board-a"}
+	err = install.WriteMTDStructures(volumes, s.gadgetRoot)
+	c.Check(err, ErrorMatches, `cannot find MTD partition "u-boot-env" for structure "env": device not found`)
+
+	volumes["nand"].Structure[0].MTDName = "bootloader"
+	mockNandwrite := testutil.MockCommand(c, "nandwrite", "echo 'bad block'; exit 1")
+	defer mockNandwrite.Restore()
+	err = install.WriteMTDStructures(volumes, s.gadgetRoot)
+	c.Check(err, ErrorMatches, `cannot write structure "env" to MTD partition "bootloader": bad block`)
+}
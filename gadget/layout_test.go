@@ -1498,3 +1498,59 @@ func (p *layoutTestSuite) TestLayoutVolumeStructure(c *C) {
 		}
 	}
 }
+
+func (p *layoutTestSuite) TestVolumeSizes(c *C) {
+	gi, err := gadget.InfoFromGadgetYaml([]byte(`
+volumes:
+  pc:
+    bootloader: grub
+    structure:
+      - name: mbr
+        type: mbr
+        size: 440
+      - name: BIOS Boot
+        type: DA,21686148-6449-6E6F-744E-656564454649
+        size: 1M
+        offset: 1M
+      - name: ubuntu-seed
+        role: system-seed
+        filesystem: vfat
+        type: EF,C12A7328-F81F-11D2-BA4B-00A0C93EC93B
+        size: 1200M
+  other:
+    schema: mbr
+    structure:
+      - name: data
+        type: 83
+        filesystem: ext4
+        offset: 4M
+        size: 8M
+  my-emmc:
+    schema: emmc
+    structure:
+      - name: boot0
+        size: 4M
+`), nil)
+	c.Assert(err, IsNil)
+
+	sizes, err := gadget.VolumeSizes(gi)
+	c.Assert(err, IsNil)
+	c.Check(sizes, DeepEquals, []gadget.VolumeSize{
+		{
+			Name: "other",
+			Size: 12 * quantity.SizeMiB,
+			Structures: []gadget.StructureSize{
+				{Name: "data", Offset: 4 * quantity.OffsetMiB, Size: 8 * quantity.SizeMiB},
+			},
+		}, {
+			Name: "pc",
+			// backup GPT is accounted for at the end
+			Size: 1202*quantity.SizeMiB + 34*512,
+			Structures: []gadget.StructureSize{
+				{Name: "mbr", Offset: 0, Size: 440},
+				{Name: "BIOS Boot", Offset: quantity.OffsetMiB, Size: quantity.SizeMiB},
+				{Name: "ubuntu-seed", Offset: 2 * quantity.OffsetMiB, Size: 1200 * quantity.SizeMiB},
+			},
+		},
+	})
+}
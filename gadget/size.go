@@ -0,0 +1,88 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package gadget
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/snapcore/snapd/gadget/quantity"
+)
+
+// gptBackupSize is the size of the backup GPT header and partition table
+// at the end of a disk, which is 34 sectors with 512 bytes sectors.
+const gptBackupSize = 34 * 512
+
+// StructureSize is the placement of a structure in the image of a volume.
+type StructureSize struct {
+	Name   string
+	Offset quantity.Offset
+	Size   quantity.Size
+}
+
+// VolumeSize is the size of the image of a volume and of its structures.
+type VolumeSize struct {
+	Name       string
+	Size       quantity.Size
+	Structures []StructureSize
+}
+
+// VolumeSizes lays out the volumes of the gadget as it is done when building
+// an image and returns the size of the resulting volume images, sorted by
+// volume name. Nothing is written, which allows checking the gadget against
+// the storage budget of a device. Volumes of the "emmc" schema describe
+// hardware partitions rather than images and are not included.
+func VolumeSizes(info *Info) ([]VolumeSize, error) {
+	volNames := make([]string, 0, len(info.Volumes))
+	for name := range info.Volumes {
+		volNames = append(volNames, name)
+	}
+	sort.Strings(volNames)
+
+	sizes := make([]VolumeSize, 0, len(volNames))
+	for _, name := range volNames {
+		vol := info.Volumes[name]
+		if vol.Schema == schemaEMMC {
+			continue
+		}
+		lv, err := LayoutVolume(vol, OnDiskStructsFromGadget(vol), &LayoutOptions{IgnoreContent: true})
+		if err != nil {
+			return nil, fmt.Errorf("cannot lay out volume %q: %v", name, err)
+		}
+		vs := VolumeSize{Name: name}
+		var end quantity.Offset
+		for _, ls := range lv.LaidOutStructure {
+			vs.Structures = append(vs.Structures, StructureSize{
+				Name:   ls.Name(),
+				Offset: ls.StartOffset,
+				Size:   ls.Size,
+			})
+			if structEnd := ls.StartOffset + quantity.Offset(ls.Size); structEnd > end {
+				end = structEnd
+			}
+		}
+		vs.Size = quantity.Size(end)
+		if vol.Schema == schemaGPT {
+			vs.Size += gptBackupSize
+		}
+		sizes = append(sizes, vs)
+	}
+	return sizes, nil
+}
@@ -22,6 +22,8 @@ package gadget
 import (
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 
@@ -1249,6 +1251,20 @@ func validateVolumesMatch(old, new map[string]*Volume) error {
 // d. After step (c) is completed the kernel refresh will now also work (no more
 // violation of rule 1)
 func Update(model Model, old, new GadgetData, rollbackDirPath string, updatePolicy UpdatePolicyFunc, observer ContentUpdateObserver) error {
+	return UpdateWithProgress(model, old, new, rollbackDirPath, updatePolicy, observer, nil)
+}
+
+// UpdateProgressFunc is called when the updates to the structures of a
+// volume were applied, with the number of structures of the volume whose
+// content was changed out of the structures considered for an update.
+type UpdateProgressFunc func(volumeName string, updated, total int)
+
+// UpdateWithProgress is like Update, but additionally reports the progress of
+// the update of each volume through the provided progress function. When the
+// update spans multiple volumes, the volumes are updated one after another in
+// order of their names, each using its own subdirectory of the rollback
+// directory.
+func UpdateWithProgress(model Model, old, new GadgetData, rollbackDirPath string, updatePolicy UpdatePolicyFunc, observer ContentUpdateObserver, progress UpdateProgressFunc) error {
 	// The gadget can only match if they have identical volumes assigned for the
 	// (currently) matching device
 	oldVolumes, _, err := VolumesForCurrentDevice(old.Info)
@@ -1280,10 +1296,6 @@ func Update(model Model, old, new GadgetData, rollbackDirPath string, updatePoli
 	// we treat the whole gadget as invalid and return an error blocking the
 	// refresh
 
-	// TODO: should we handle the updates on multiple volumes in a
-	// deterministic order? iterating over maps is not deterministic, but we
-	// perform all updates at the end together in one call
-
 	// ensure all required kernel assets are found in the gadget
 	kernelInfo, err := kernel.ReadInfo(new.KernelRootDir)
 	if err != nil {
@@ -1418,8 +1430,12 @@ func Update(model Model, old, new GadgetData, rollbackDirPath string, updatePoli
 		}
 	}
 
-	// apply all updates at once
-	if err := applyUpdates(structureLocations, new, allUpdates, rollbackDirPath, observer); err != nil {
+	// apply all updates at once, one volume after another in a deterministic
+	// order
+	sort.SliceStable(allUpdates, func(i, j int) bool {
+		return allUpdates[i].volume.Name < allUpdates[j].volume.Name
+	})
+	if err := applyUpdates(structureLocations, new, allUpdates, rollbackDirPath, observer, progress); err != nil {
 		return err
 	}
 
@@ -1688,7 +1704,19 @@ func updateLocationForStructure(structureLocations map[string]map[int]StructureL
 	}
 }
 
-func applyUpdates(structureLocations map[string]map[int]StructureLocation, new GadgetData, updates []updatePair, rollbackDir string, observer ContentUpdateObserver) error {
+// volumeRollbackDir returns the directory holding the rollback data of the
+// updates to the given volume. Updates spanning multiple volumes keep the
+// rollback data of each volume in its own subdirectory.
+func volumeRollbackDir(rollbackDir string, updates []updatePair, volName string) string {
+	for _, one := range updates {
+		if one.volume.Name != volName {
+			return filepath.Join(rollbackDir, volName)
+		}
+	}
+	return rollbackDir
+}
+
+func applyUpdates(structureLocations map[string]map[int]StructureLocation, new GadgetData, updates []updatePair, rollbackDir string, observer ContentUpdateObserver, progress UpdateProgressFunc) error {
 	updaters := make([]Updater, len(updates))
 
 	for i, one := range updates {
@@ -1696,7 +1724,13 @@ func applyUpdates(structureLocations map[string]map[int]StructureLocation, new G
 		if err != nil {
 			return fmt.Errorf("cannot prepare update for volume structure %v on volume %s: %v", one.to, one.volume.Name, err)
 		}
-		up, err := updaterForStructure(loc, one.from, one.to, new.RootDir, rollbackDir, observer)
+		volRollbackDir := volumeRollbackDir(rollbackDir, updates, one.volume.Name)
+		if volRollbackDir != rollbackDir {
+			if err := os.MkdirAll(volRollbackDir, 0755); err != nil {
+				return fmt.Errorf("cannot prepare rollback directory for volume %s: %v", one.volume.Name, err)
+			}
+		}
+		up, err := updaterForStructure(loc, one.from, one.to, new.RootDir, volRollbackDir, observer)
 		if err != nil {
 			return fmt.Errorf("cannot prepare update for volume structure %v on volume %s: %v", one.to, one.volume.Name, err)
 		}
@@ -1730,15 +1764,26 @@ func applyUpdates(structureLocations map[string]map[int]StructureLocation, new G
 	var updateErr error
 	var updateLastAttempted int
 	var skipped int
+	// the structures considered and updated on the current volume
+	var volTotal, volUpdated int
 	for i, one := range updaters {
 		updateLastAttempted = i
+		volTotal++
 		if err := one.Update(); err != nil {
-			if err == ErrNoUpdate {
-				skipped++
-				continue
+			if err != ErrNoUpdate {
+				updateErr = fmt.Errorf("cannot update volume structure %v on volume %s: %v", updates[i].to, updates[i].volume.Name, err)
+				break
 			}
-			updateErr = fmt.Errorf("cannot update volume structure %v on volume %s: %v", updates[i].to, updates[i].volume.Name, err)
-			break
+			skipped++
+		} else {
+			volUpdated++
+		}
+		if i == len(updates)-1 || updates[i+1].volume.Name != updates[i].volume.Name {
+			// done with this volume
+			if progress != nil {
+				progress(updates[i].volume.Name, volUpdated, volTotal)
+			}
+			volTotal, volUpdated = 0, 0
 		}
 	}
 	if skipped == len(updaters) {
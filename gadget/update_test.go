@@ -1948,7 +1948,8 @@ func (u *updateTestSuite) TestUpdateApplyUC20WithInitialMapAllVolumesUpdatedFull
 	fooBackupCalls := make(map[string]bool)
 	restore = gadget.MockUpdaterForStructure(func(loc gadget.StructureLocation, fromPs, ps *gadget.LaidOutStructure, psRootDir, psRollbackDir string, observer gadget.ContentUpdateObserver) (gadget.Updater, error) {
 		c.Assert(psRootDir, Equals, newData.RootDir)
-		c.Assert(psRollbackDir, Equals, rollbackDir)
+		// updates spanning multiple volumes use a rollback directory per volume
+		c.Assert(psRollbackDir, Equals, filepath.Join(rollbackDir, ps.VolumeStructure.VolumeName))
 		c.Assert(observer, Equals, muo)
 		// TODO:UC20 verify observer
 
@@ -2512,7 +2513,8 @@ volumes:
 	fooBackupCalls := make(map[string]bool)
 	restore = gadget.MockUpdaterForStructure(func(loc gadget.StructureLocation, fromPs, ps *gadget.LaidOutStructure, psRootDir, psRollbackDir string, observer gadget.ContentUpdateObserver) (gadget.Updater, error) {
 		c.Assert(psRootDir, Equals, newData.RootDir)
-		c.Assert(psRollbackDir, Equals, rollbackDir)
+		// updates spanning multiple volumes use a rollback directory per volume
+		c.Assert(psRollbackDir, Equals, filepath.Join(rollbackDir, ps.VolumeStructure.VolumeName))
 		c.Assert(observer, Equals, muo)
 		// TODO:UC20 verify observer
 
@@ -2601,7 +2603,10 @@ volumes:
 	defer restore()
 
 	// go go go
-	err = gadget.Update(uc20Model, oldData, newData, rollbackDir, nil, muo)
+	var progress []string
+	err = gadget.UpdateWithProgress(uc20Model, oldData, newData, rollbackDir, nil, muo, func(volName string, updated, total int) {
+		progress = append(progress, fmt.Sprintf("%s %d/%d", volName, updated, total))
+	})
 	c.Assert(err, IsNil)
 	c.Assert(pcUpdaterForStructureCalls, Equals, 1)
 	c.Assert(fooUpdaterForStructureCalls, Equals, 1)
@@ -2621,6 +2626,11 @@ volumes:
 
 	c.Assert(muo.beforeWriteCalled, Equals, 1)
 	c.Assert(muo.canceledCalled, Equals, 0)
+
+	// volumes are updated in order, each with its own rollback directory
+	c.Check(progress, DeepEquals, []string{"foo 1/1", "pc 1/1"})
+	c.Check(filepath.Join(rollbackDir, "foo"), testutil.FilePresent)
+	c.Check(filepath.Join(rollbackDir, "pc"), testutil.FilePresent)
 }
 
 func (u *updateTestSuite) TestUpdateApplyUC20KernelAssetsOnSingleVolumeWithInitialMapAllVolumesUpdatedFullLogic(c *C) {
@@ -2819,7 +2829,8 @@ volumes:
 	fooBackupCalls := make(map[string]bool)
 	restore = gadget.MockUpdaterForStructure(func(loc gadget.StructureLocation, fromPs, ps *gadget.LaidOutStructure, psRootDir, psRollbackDir string, observer gadget.ContentUpdateObserver) (gadget.Updater, error) {
 		c.Assert(psRootDir, Equals, newData.RootDir)
-		c.Assert(psRollbackDir, Equals, rollbackDir)
+		// updates spanning multiple volumes use a rollback directory per volume
+		c.Assert(psRollbackDir, Equals, filepath.Join(rollbackDir, ps.VolumeStructure.VolumeName))
 		c.Assert(observer, Equals, muo)
 		// TODO:UC20 verify observer
 
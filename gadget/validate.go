@@ -167,6 +167,16 @@ func ruleValidateEMMCVolume(vol *Volume) error {
 	if len(vol.Partial) != 0 {
 		return fmt.Errorf(`cannot set "partial" content for eMMC schemas`)
 	}
+	// the device boots from at most one of its hardware boot partitions
+	enabled := 0
+	for _, vs := range vol.Structure {
+		if vs.EMMCBoot != nil && vs.EMMCBoot.Enable {
+			enabled++
+		}
+	}
+	if enabled > 1 {
+		return fmt.Errorf(`cannot enable booting from more than one eMMC boot partition`)
+	}
 	return nil
 }
 
@@ -252,8 +252,40 @@ func isDnsUnavailable(err error) bool {
 	return strings.Contains(dnsErr.Err, "Temporary failure in name resolution")
 }
 
+// RetryGate limits the requests and retries made by RetryRequestWithGate,
+// e.g. to implement a retry budget or a circuit breaker.
+type RetryGate interface {
+	// Allow returns an error if no request to endpoint should be made.
+	Allow(endpoint string) error
+	// AllowRetry returns whether a failed request to endpoint can be
+	// retried.
+	AllowRetry(endpoint string) bool
+	// Record records whether a request to endpoint failed with a
+	// transient error or a server error.
+	Record(endpoint string, failed bool)
+}
+
+type unlimitedRetryGate struct{}
+
+func (unlimitedRetryGate) Allow(string) error     { return nil }
+func (unlimitedRetryGate) AllowRetry(string) bool { return true }
+func (unlimitedRetryGate) Record(string, bool)    {}
+
 // RetryRequest calls doRequest and read the response body in a retry loop using the given retryStrategy.
 func RetryRequest(endpoint string, doRequest func() (*http.Response, error), readResponseBody func(resp *http.Response) error, retryStrategy retry.Strategy) (resp *http.Response, err error) {
+	return RetryRequestWithGate(endpoint, doRequest, readResponseBody, retryStrategy, nil)
+}
+
+// RetryRequestWithGate is like RetryRequest but consults the given gate
+// before making the request and before each retry.
+func RetryRequestWithGate(endpoint string, doRequest func() (*http.Response, error), readResponseBody func(resp *http.Response) error, retryStrategy retry.Strategy, gate RetryGate) (resp *http.Response, err error) {
+	if gate == nil {
+		gate = unlimitedRetryGate{}
+	}
+	if err := gate.Allow(endpoint); err != nil {
+		return nil, err
+	}
+
 	var attempt *retry.Attempt
 	startTime := time.Now()
 	for attempt = retry.Start(retryStrategy, nil); attempt.Next(); {
@@ -261,7 +293,9 @@ func RetryRequest(endpoint string, doRequest func() (*http.Response, error), rea
 
 		resp, err = doRequest()
 		if err != nil {
-			if ShouldRetryAttempt(attempt, err) {
+			transient := ShouldRetryError(err)
+			gate.Record(endpoint, transient)
+			if transient && attempt.More() && gate.AllowRetry(endpoint) {
 				continue
 			}
 
@@ -271,14 +305,15 @@ func RetryRequest(endpoint string, doRequest func() (*http.Response, error), rea
 			break
 		}
 
-		if ShouldRetryHttpResponse(attempt, resp) {
+		gate.Record(endpoint, resp.StatusCode >= 500)
+		if ShouldRetryHttpResponse(attempt, resp) && gate.AllowRetry(endpoint) {
 			resp.Body.Close()
 			continue
 		} else {
 			err := readResponseBody(resp)
 			resp.Body.Close()
 			if err != nil {
-				if ShouldRetryAttempt(attempt, err) {
+				if ShouldRetryAttempt(attempt, err) && gate.AllowRetry(endpoint) {
 					continue
 				} else {
 					maybeLogRetrySummary(startTime, endpoint, attempt, resp, err)
@@ -506,3 +506,57 @@ func (s *retrySuite) TestRetryOnHttp2ProtocolErrors(c *C) {
 	c.Assert(err, NotNil)
 	c.Assert(n > 1, Equals, true, Commentf("%v not > 1", n))
 }
+
+type mockRetryGate struct {
+	allowErr error
+	retries  int
+	records  []bool
+}
+
+func (g *mockRetryGate) Allow(endpoint string) error {
+	return g.allowErr
+}
+
+func (g *mockRetryGate) AllowRetry(endpoint string) bool {
+	if g.retries == 0 {
+		return false
+	}
+	g.retries--
+	return true
+}
+
+func (g *mockRetryGate) Record(endpoint string, failed bool) {
+	g.records = append(g.records, failed)
+}
+
+func (s *retrySuite) TestRetryRequestWithGate(c *C) {
+	n := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		w.WriteHeader(500)
+	}))
+	defer mockServer.Close()
+
+	cli := httputil.NewHTTPClient(nil)
+	doRequest := func() (*http.Response, error) {
+		return cli.Get(mockServer.URL)
+	}
+	readResponseBody := func(resp *http.Response) error {
+		return nil
+	}
+
+	// the gate limits the retries
+	gate := &mockRetryGate{retries: 2}
+	resp, err := httputil.RetryRequestWithGate("endp", doRequest, readResponseBody, testRetryStrategy, gate)
+	c.Assert(err, IsNil)
+	c.Check(resp.StatusCode, Equals, 500)
+	c.Check(n, Equals, 3)
+	c.Check(gate.records, DeepEquals, []bool{true, true, true})
+
+	// or prevents the request altogether
+	gate = &mockRetryGate{allowErr: fmt.Errorf("endpoint suspended")}
+	_, err = httputil.RetryRequestWithGate("endp", doRequest, readResponseBody, testRetryStrategy, gate)
+	c.Assert(err, ErrorMatches, "endpoint suspended")
+	c.Check(n, Equals, 3)
+	c.Check(gate.records, HasLen, 0)
+}
@@ -50,6 +50,35 @@ func FetchAndCheckSnapAssertions(snapPath string, info *snap.Info, comps []CompI
 		return nil, err
 	}
 
+	verifiedRev, err := fetchAndCrossCheckSnapRevision(sha3_384, size, info, model, f, db)
+	if err != nil {
+		return nil, err
+	}
+	if err := snapasserts.CheckProvenanceWithVerifiedRevision(snapPath, verifiedRev); err != nil {
+		return nil, err
+	}
+
+	// fetch component assertions
+	for _, comp := range comps {
+		if err := FetchAndCheckComponentAssertions(comp, info, model, f, db); err != nil {
+			return nil, err
+		}
+	}
+
+	return findSnapDeclaration(info, db)
+}
+
+// fetchAndCheckStoreSnapAssertions is like FetchAndCheckSnapAssertions but
+// for a snap that was not downloaded, the digest and size reported by the
+// store are cross checked instead of the ones of the snap file.
+func fetchAndCheckStoreSnapAssertions(info *snap.Info, model *asserts.Model, f asserts.Fetcher, db asserts.RODatabase) (*asserts.SnapDeclaration, error) {
+	if _, err := fetchAndCrossCheckSnapRevision(info.Sha3_384, uint64(info.Size), info, model, f, db); err != nil {
+		return nil, err
+	}
+	return findSnapDeclaration(info, db)
+}
+
+func fetchAndCrossCheckSnapRevision(sha3_384 string, size uint64, info *snap.Info, model *asserts.Model, f asserts.Fetcher, db asserts.RODatabase) (*asserts.SnapRevision, error) {
 	expectedProv := info.Provenance()
 	// this assumes series "16"
 	if err := snapasserts.FetchSnapAssertions(f, sha3_384, expectedProv); err != nil {
@@ -57,14 +86,10 @@ func FetchAndCheckSnapAssertions(snapPath string, info *snap.Info, comps []CompI
 	}
 
 	// cross checks
-	verifiedRev, err := snapasserts.CrossCheck(info.InstanceName(), sha3_384, expectedProv, size, &info.SideInfo, model, db)
-	if err != nil {
-		return nil, err
-	}
-	if err := snapasserts.CheckProvenanceWithVerifiedRevision(snapPath, verifiedRev); err != nil {
-		return nil, err
-	}
+	return snapasserts.CrossCheck(info.InstanceName(), sha3_384, expectedProv, size, &info.SideInfo, model, db)
+}
 
+func findSnapDeclaration(info *snap.Info, db asserts.RODatabase) (*asserts.SnapDeclaration, error) {
 	a, err := db.Find(asserts.SnapDeclarationType, map[string]string{
 		"series":  release.Series,
 		"snap-id": info.SnapID,
@@ -72,14 +97,6 @@ func FetchAndCheckSnapAssertions(snapPath string, info *snap.Info, comps []CompI
 	if err != nil {
 		return nil, fmt.Errorf("internal error: lost snap declaration for %q: %v", info.InstanceName(), err)
 	}
-
-	// fetch component assertions
-	for _, comp := range comps {
-		if err := FetchAndCheckComponentAssertions(comp, info, model, f, db); err != nil {
-			return nil, err
-		}
-	}
-
 	return a.(*asserts.SnapDeclaration), nil
 }
 
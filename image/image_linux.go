@@ -28,6 +28,7 @@ import (
 	"sort"
 	"strings"
 	"syscall"
+	"text/tabwriter"
 	"time"
 
 	"github.com/snapcore/snapd/arch"
@@ -153,6 +154,10 @@ func Prepare(opts *Options) error {
 		return err
 	}
 
+	if opts.SizeOnly && opts.Preseed {
+		return fmt.Errorf("cannot preseed the image when only computing its size")
+	}
+
 	if err := setupSeed(tsto, model, opts); err != nil {
 		return err
 	}
@@ -285,11 +290,21 @@ type imageSeeder struct {
 	tsto  *tooling.ToolingStore
 
 	classic        bool
+	sizeOnly       bool
 	prepareDir     string
 	wideCohortKey  string
 	customizations *Customizations
 	architecture   string
 
+	// sizeOnlyDir is where the snaps that need to be read are
+	// downloaded to when only computing sizes, so that the seed is not
+	// written
+	sizeOnlyDir string
+	localSnaps  localSnapRefs
+	// notDownloaded holds the store information about the snaps that
+	// were not downloaded when only computing sizes
+	notDownloaded map[string]*tooling.DownloadedSnap
+
 	hasModes    bool
 	rootDir     string
 	bootRootDir string
@@ -309,6 +324,7 @@ func newImageSeeder(tsto *tooling.ToolingStore, model *asserts.Model, opts *Opti
 	// we are generating.
 	s := &imageSeeder{
 		classic:       opts.Classic,
+		sizeOnly:      opts.SizeOnly,
 		prepareDir:    opts.PrepareDir,
 		wideCohortKey: opts.WideCohortKey,
 		// keep a pointer to the customization object in opts as the Validation
@@ -603,6 +619,12 @@ func (s *imageSeeder) downloadSnaps(snapsToDownload []*seedwriter.SeedSnap, curS
 		if err := s.validateSnapArchs([]*seedwriter.SeedSnap{sn}); err != nil {
 			return "", nil, err
 		}
+		if s.sizeOnly {
+			sn.Path = filepath.Join(s.sizeOnlyDir, filepath.Base(sn.Path))
+			for i, comp := range sn.Components {
+				sn.Components[i].Path = filepath.Join(s.sizeOnlyDir, filepath.Base(comp.Path))
+			}
+		}
 
 		compPaths := make(map[string]string, len(cinfos))
 		for _, comp := range sn.Components {
@@ -654,8 +676,13 @@ func (s *imageSeeder) downloadSnaps(snapsToDownload []*seedwriter.SeedSnap, curS
 	sort.Slice(curSnaps, func(i, j int) bool {
 		return curSnaps[i].SnapName < curSnaps[j].SnapName
 	})
+	var skipDownload func(*snap.Info) bool
+	if s.sizeOnly {
+		skipDownload = sizeOnlySkipDownload
+	}
 	downloadedSnaps, err = s.tsto.DownloadMany(snapToDownloadOptions, curSnaps, tooling.DownloadManyOptions{
 		BeforeDownloadFunc: beforeDownload,
+		SkipDownloadFunc:   skipDownload,
 		EnforceValidation:  s.customizations.Validation == "enforce",
 	})
 	if err != nil {
@@ -665,6 +692,18 @@ func (s *imageSeeder) downloadSnaps(snapsToDownload []*seedwriter.SeedSnap, curS
 	return downloadedSnaps, nil
 }
 
+// sizeOnlySkipDownload returns whether the snap does not need to be
+// downloaded when only computing sizes. The gadget is read to compute the
+// sizes of the volumes, the kernel and the snap carrying snapd to select the
+// assertion formats.
+func sizeOnlySkipDownload(info *snap.Info) bool {
+	switch info.Type() {
+	case snap.TypeGadget, snap.TypeKernel, snap.TypeSnapd, snap.TypeOS:
+		return false
+	}
+	return true
+}
+
 func localSnapsWithID(snaps localSnapRefs) []*tooling.CurrentSnap {
 	var localSnaps []*tooling.CurrentSnap
 	for sn := range snaps {
@@ -699,6 +738,9 @@ func (s *imageSeeder) downloadAllSnaps(localSnaps localSnapRefs, fetchAsserts se
 			if err := s.w.SetRedirectChannel(sn, dlsn.RedirectChannel); err != nil {
 				return err
 			}
+			if dlsn.Path == "" {
+				s.notDownloaded[sn.SnapName()] = dlsn
+			}
 
 			curSnaps = append(curSnaps, &tooling.CurrentSnap{
 				SnapName: sn.Info.SnapName(),
@@ -860,6 +902,10 @@ func (s *imageSeeder) finish() error {
 		}
 	}
 
+	if s.sizeOnly {
+		return s.printSizes()
+	}
+
 	copySnap := func(name, src, dst string) error {
 		fmt.Fprintf(Stdout, "Copying %q (%s)\n", src, name)
 		return osutil.CopyFile(src, dst, 0)
@@ -881,6 +927,94 @@ func (s *imageSeeder) finish() error {
 	return s.finishSeedCore()
 }
 
+func treeSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// printSizes prints the size of the snaps of the seed and, for core models,
+// the sizes of the images of the gadget volumes, without writing the seed.
+func (s *imageSeeder) printSizes() error {
+	// snaps from the store that need to be read were downloaded aside,
+	// the store reports the size of the others, local ones would be
+	// copied into the seed
+	seedSize, err := treeSize(s.sizeOnlyDir)
+	if err != nil {
+		return err
+	}
+	for _, dlsn := range s.notDownloaded {
+		seedSize += dlsn.Info.Size
+		for _, comp := range dlsn.Components {
+			seedSize += comp.Size
+		}
+	}
+	addSize := func(path string) error {
+		st, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		seedSize += st.Size()
+		return nil
+	}
+	for sn := range s.localSnaps {
+		if err := addSize(sn.Path); err != nil {
+			return err
+		}
+		for _, comp := range sn.Components {
+			if err := addSize(comp.Path); err != nil {
+				return err
+			}
+		}
+	}
+
+	w := tabwriter.NewWriter(Stdout, 5, 3, 2, ' ', 0)
+	fmt.Fprintf(w, "Name\tOffset\tSize\n")
+	fmt.Fprintf(w, "seed\t-\t%d\n", seedSize)
+	defer w.Flush()
+
+	if s.classic {
+		return nil
+	}
+
+	bootSnaps, err := s.w.BootSnaps()
+	if err != nil {
+		return err
+	}
+	for _, sn := range bootSnaps {
+		if sn.Info.Type() != snap.TypeGadget {
+			continue
+		}
+		snapf, err := snapfile.Open(sn.Path)
+		if err != nil {
+			return err
+		}
+		gadgetInfo, err := gadget.ReadInfoFromSnapFile(snapf, s.model)
+		if err != nil {
+			return err
+		}
+		volSizes, err := gadget.VolumeSizes(gadgetInfo)
+		if err != nil {
+			return err
+		}
+		for _, vol := range volSizes {
+			fmt.Fprintf(w, "%s\t-\t%d\n", vol.Name, vol.Size)
+			for _, st := range vol.Structures {
+				fmt.Fprintf(w, "%s/%s\t%d\t%d\n", vol.Name, st.Name, st.Offset, st.Size)
+			}
+		}
+	}
+	return nil
+}
+
 func readComponentInfoFromCont(path string) (*snap.ComponentInfo, error) {
 	compf, err := snapfile.Open(path)
 	if err != nil {
@@ -1060,6 +1194,13 @@ var setupSeed = func(tsto *tooling.ToolingStore, model *asserts.Model, opts *Opt
 			if err := copyOrRefetchIfFormatTooNewIntoDb(aRefs); err != nil {
 				return nil, err
 			}
+		} else if s.notDownloaded[sn.SnapName()] != nil {
+			// only the snap assertions can be checked against the
+			// information from the store, the components ones
+			// are not needed to compute sizes
+			if _, err := fetchAndCheckStoreSnapAssertions(sn.Info, model, s.f, s.db); err != nil {
+				return nil, err
+			}
 		} else {
 			// fetch snap and components assertions
 			compPaths := make([]CompInfoPath, len(sn.Components))
@@ -1076,6 +1217,21 @@ var setupSeed = func(tsto *tooling.ToolingStore, model *asserts.Model, opts *Opt
 		return s.f.Refs()[prev:], nil
 	}
 
+	if s.sizeOnly {
+		// the snaps are only needed to compute sizes and to check them,
+		// download them aside instead of into the seed
+		if err := os.MkdirAll(s.prepareDir, 0755); err != nil {
+			return err
+		}
+		s.sizeOnlyDir, err = os.MkdirTemp(s.prepareDir, "size-only-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(s.sizeOnlyDir)
+		s.localSnaps = localSnaps
+		s.notDownloaded = make(map[string]*tooling.DownloadedSnap)
+	}
+
 	if err := s.downloadAllSnaps(localSnaps, fetchAsserts); err != nil {
 		return err
 	}
@@ -26,6 +26,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"testing"
@@ -76,6 +77,7 @@ type imageSuite struct {
 	curSnaps               [][]*store.CurrentSnap
 	assertReqs             []assertReq
 	seqReqs                []seqReq
+	downloads              []string
 
 	assertMaxFormats map[string]int
 
@@ -162,6 +164,7 @@ func (s *imageSuite) TearDownTest(c *C) {
 	image.Stderr = os.Stderr
 	s.storeActions = nil
 	s.storeActionsBunchSizes = nil
+	s.downloads = nil
 	s.curSnaps = nil
 	s.assertReqs = nil
 	s.assertMaxFormats = nil
@@ -192,6 +195,13 @@ func (s *imageSuite) SnapAction(_ context.Context, curSnaps []*store.CurrentSnap
 			return nil, nil, fmt.Errorf("no %q in the fake store", a.InstanceName)
 		}
 		info1 := *info
+		// the store reports the digest and size of the snap
+		sha3_384, size, err := asserts.SnapFileSHA3_384(s.AssertedSnap(a.InstanceName))
+		if err != nil {
+			return nil, nil, err
+		}
+		info1.Sha3_384 = sha3_384
+		info1.Size = int64(size)
 		channel := a.Channel
 		redirectChannel := ""
 		if strings.HasPrefix(a.InstanceName, "default-track-") {
@@ -211,6 +221,7 @@ func (s *imageSuite) SnapAction(_ context.Context, curSnaps []*store.CurrentSnap
 }
 
 func (s *imageSuite) Download(ctx context.Context, name, targetFn string, downloadInfo *snap.DownloadInfo, pbar progress.Meter, user *auth.UserState, dlOpts *store.DownloadOptions) error {
+	s.downloads = append(s.downloads, name)
 	return osutil.CopyFile(s.AssertedSnap(name), targetFn, 0)
 }
 
@@ -3458,6 +3469,77 @@ func (s *imageSuite) testSetupSeedCore20Grub(c *C, kernelContent [][]string, exp
 	c.Check(declCount, Equals, 5)
 }
 
+func (s *imageSuite) TestSetupSeedCore20SizeOnly(c *C) {
+	bootloader.Force(nil)
+	restore := image.MockTrusted(s.StoreSigning.Trusted)
+	defer restore()
+
+	model := s.makeUC20Model(nil)
+	prepareDir := c.MkDir()
+
+	s.makeSnap(c, "snapd", [][]string{snapdInfoFile}, snap.R(1), "")
+	s.makeSnap(c, "core20", nil, snap.R(20), "")
+	s.makeSnap(c, "pc-kernel=20", nil, snap.R(1), "")
+	gadgetContent := [][]string{
+		{"grub-recovery.conf", "# recovery grub.cfg"},
+		{"grub.conf", "# boot grub.cfg"},
+		{"meta/gadget.yaml", pcUC20GadgetYaml},
+	}
+	s.makeSnap(c, "pc=20", gadgetContent, snap.R(22), "")
+	s.makeSnap(c, "required20", nil, snap.R(21), "other")
+
+	opts := &image.Options{
+		PrepareDir: prepareDir,
+		Customizations: image.Customizations{
+			Validation: "ignore",
+		},
+		SizeOnly: true,
+	}
+
+	var seedSize int64
+	for _, name := range []string{"snapd", "core20", "pc-kernel", "pc", "required20"} {
+		st, err := os.Stat(s.AssertedSnap(name))
+		c.Assert(err, IsNil)
+		seedSize += st.Size()
+	}
+
+	err := image.SetupSeed(s.tsto, model, opts)
+	c.Assert(err, IsNil)
+
+	// the seed was not written
+	c.Check(filepath.Join(prepareDir, "system-seed"), testutil.FileAbsent)
+	// and the downloaded snaps were removed
+	entries, err := os.ReadDir(prepareDir)
+	c.Assert(err, IsNil)
+	c.Check(entries, HasLen, 0)
+	// only the snaps that are read were downloaded, the store reports
+	// the size of the others
+	sort.Strings(s.downloads)
+	c.Check(s.downloads, DeepEquals, []string{"pc", "pc-kernel", "snapd"})
+
+	c.Check(s.stdout.String(), Matches, fmt.Sprintf(`(?ms).*
+Name            Offset     Size
+seed            -          %d
+pc              -          315638784
+pc/ubuntu-seed  1048576    104857600
+pc/ubuntu-data  105906176  209715200
+`, seedSize))
+	c.Check(s.stdout.String(), Not(testutil.Contains), "Copying")
+}
+
+func (s *imageSuite) TestPrepareSizeOnlyNoPreseed(c *C) {
+	fn := filepath.Join(c.MkDir(), "model.assertion")
+	c.Assert(os.WriteFile(fn, asserts.Encode(s.makeUC20Model(nil)), 0644), IsNil)
+
+	err := image.Prepare(&image.Options{
+		ModelFile:  fn,
+		PrepareDir: c.MkDir(),
+		Preseed:    true,
+		SizeOnly:   true,
+	})
+	c.Assert(err, ErrorMatches, `cannot preseed the image when only computing its size`)
+}
+
 func (s *imageSuite) TestSetupSeedCore20Grub(c *C) {
 	expectedAssertMaxFormats := map[string]int{
 		"snap-declaration": 5,
@@ -70,6 +70,11 @@ type Options struct {
 	Architecture string
 
 	Customizations Customizations
+
+	// SizeOnly requests only the size of the seed and of the images of
+	// the gadget volumes to be computed and printed, instead of writing
+	// the seed.
+	SizeOnly bool
 }
 
 // Customizatons defines possible image customizations. Not all of
@@ -74,7 +74,8 @@ var (
 
 // Backend is responsible for maintaining apparmor profiles for snaps and parts of snapd.
 type Backend struct {
-	preseed bool
+	preseed   bool
+	hashCache bool
 
 	coreSnap  *snap.Info
 	snapdSnap *snap.Info
@@ -85,6 +86,18 @@ func (b *Backend) Name() interfaces.SecuritySystem {
 	return interfaces.SecurityAppArmor
 }
 
+// parserFlags returns the given apparmor_parser flags extended according to
+// how the backend was initialized.
+func (b *Backend) parserFlags(flags apparmor_sandbox.AaParserFlags) apparmor_sandbox.AaParserFlags {
+	if b.preseed {
+		flags |= apparmor_sandbox.SkipKernelLoad
+	}
+	if b.hashCache {
+		flags |= apparmor_sandbox.HashCache
+	}
+	return flags
+}
+
 // Initialize prepares customized apparmor policy for snap-confine.
 func (b *Backend) Initialize(opts *interfaces.SecurityBackendOptions) error {
 	if opts != nil && opts.Preseed {
@@ -94,6 +107,7 @@ func (b *Backend) Initialize(opts *interfaces.SecurityBackendOptions) error {
 	if opts != nil {
 		b.coreSnap = opts.CoreSnapInfo
 		b.snapdSnap = opts.SnapdSnapInfo
+		b.hashCache = opts.AppArmorHashCache
 	}
 	// NOTE: It would be nice if we could also generate the profile for
 	// snap-confine executing from the core snap, right here, and not have to
@@ -136,10 +150,7 @@ func (b *Backend) Initialize(opts *interfaces.SecurityBackendOptions) error {
 		return nil
 	}
 
-	aaFlags := apparmor_sandbox.SkipReadCache
-	if b.preseed {
-		aaFlags |= apparmor_sandbox.SkipKernelLoad
-	}
+	aaFlags := b.parserFlags(apparmor_sandbox.SkipReadCache)
 
 	if err := loadProfiles([]string{profilePath}, apparmor_sandbox.SystemCacheDir, aaFlags); err != nil {
 		// When we cannot reload the profile then let's remove the generated
@@ -280,10 +291,7 @@ func (b *Backend) setupSnapConfineReexec(info *snap.Info) error {
 		pathnames[i] = filepath.Join(dir, profile)
 	}
 
-	var aaFlags apparmor_sandbox.AaParserFlags
-	if b.preseed {
-		aaFlags = apparmor_sandbox.SkipKernelLoad
-	}
+	aaFlags := b.parserFlags(0)
 	errReload := loadProfiles(pathnames, cache, aaFlags)
 	errRemoveCached := removeCachedProfiles(removed, cache)
 	if errEnsure != nil {
@@ -455,10 +463,7 @@ func (b *Backend) Setup(appSet *interfaces.SnapAppSet, opts interfaces.Confineme
 	// work despite time being wrong (e.g. in the past). For more details see
 	// https://forum.snapcraft.io/t/apparmor-profile-caching/1268/18
 	var errReloadChanged error
-	aaFlags := apparmor_sandbox.SkipReadCache
-	if b.preseed {
-		aaFlags |= apparmor_sandbox.SkipKernelLoad
-	}
+	aaFlags := b.parserFlags(apparmor_sandbox.SkipReadCache)
 	timings.Run(tm, "load-profiles[changed]", fmt.Sprintf("load changed security profiles of snap %q", snapInfo.InstanceName()), func(nesttm timings.Measurer) {
 		errReloadChanged = loadProfiles(prof.changed, apparmor_sandbox.CacheDir, aaFlags)
 	})
@@ -467,10 +472,7 @@ func (b *Backend) Setup(appSet *interfaces.SnapAppSet, opts interfaces.Confineme
 	// the kernel even if the files on disk were not changed. We rely on
 	// apparmor cache to make this performant.
 	var errReloadOther error
-	aaFlags = 0
-	if b.preseed {
-		aaFlags |= apparmor_sandbox.SkipKernelLoad
-	}
+	aaFlags = b.parserFlags(0)
 	timings.Run(tm, "load-profiles[unchanged]", fmt.Sprintf("load unchanged security profiles of snap %q", snapInfo.InstanceName()), func(nesttm timings.Measurer) {
 		errReloadOther = loadProfiles(prof.unchanged, apparmor_sandbox.CacheDir, aaFlags)
 	})
@@ -507,19 +509,13 @@ func (b *Backend) SetupMany(appSets []*interfaces.SnapAppSet, confinement func(s
 	}
 
 	if !fallback {
-		aaFlags := apparmor_sandbox.SkipReadCache | apparmor_sandbox.ConserveCPU
-		if b.preseed {
-			aaFlags |= apparmor_sandbox.SkipKernelLoad
-		}
+		aaFlags := b.parserFlags(apparmor_sandbox.SkipReadCache | apparmor_sandbox.ConserveCPU)
 		var errReloadChanged error
 		timings.Run(tm, "load-profiles[changed-many]", fmt.Sprintf("load changed security profiles of %d snaps", len(appSets)), func(nesttm timings.Measurer) {
 			errReloadChanged = loadProfiles(allChangedPaths, apparmor_sandbox.CacheDir, aaFlags)
 		})
 
-		aaFlags = apparmor_sandbox.ConserveCPU
-		if b.preseed {
-			aaFlags |= apparmor_sandbox.SkipKernelLoad
-		}
+		aaFlags = b.parserFlags(apparmor_sandbox.ConserveCPU)
 		var errReloadOther error
 		timings.Run(tm, "load-profiles[unchanged-many]", fmt.Sprintf("load unchanged security profiles %d snaps", len(appSets)), func(nesttm timings.Measurer) {
 			errReloadOther = loadProfiles(allUnchangedPaths, apparmor_sandbox.CacheDir, aaFlags)
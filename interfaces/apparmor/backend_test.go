@@ -2903,6 +2903,30 @@ func (s *backendSuite) TestSetupManyInPreseedMode(c *C) {
 	}
 }
 
+func (s *backendSuite) TestSetupWithHashCache(c *C) {
+	aa, ok := s.Backend.(*apparmor.Backend)
+	c.Assert(ok, Equals, true)
+
+	opts := interfaces.SecurityBackendOptions{
+		AppArmorHashCache: true,
+		CoreSnapInfo:      ifacetest.DefaultInitializeOpts.CoreSnapInfo,
+	}
+	c.Assert(aa.Initialize(&opts), IsNil)
+	s.loadProfilesCalls = nil
+
+	s.InstallSnap(c, interfaces.ConfinementOptions{}, "", ifacetest.SambaYamlV1, 1)
+
+	updateNSProfile := filepath.Join(dirs.SnapAppArmorDir, "snap-update-ns.samba")
+	profile := filepath.Join(dirs.SnapAppArmorDir, "snap.samba.smbd")
+	c.Check(s.loadProfilesCalls, DeepEquals, []loadProfilesParams{
+		{
+			[]string{updateNSProfile, profile},
+			fmt.Sprintf("%s/var/cache/apparmor", s.RootDir),
+			apparmor_sandbox.SkipReadCache | apparmor_sandbox.HashCache,
+		},
+	})
+}
+
 func (s *backendSuite) TestCoreSnippetOnCoreSystem(c *C) {
 	dirs.SetRootDir(s.RootDir)
 
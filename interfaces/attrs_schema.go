@@ -0,0 +1,177 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package interfaces
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/strutil"
+)
+
+// AttrType is the type of the value of a plug or slot attribute.
+type AttrType int
+
+const (
+	AttrString AttrType = iota
+	AttrBool
+	AttrInt
+	AttrStringList
+)
+
+func (t AttrType) String() string {
+	switch t {
+	case AttrString:
+		return "a string"
+	case AttrBool:
+		return "a boolean"
+	case AttrInt:
+		return "an int"
+	case AttrStringList:
+		return "a list of strings"
+	}
+	return fmt.Sprintf("AttrType(%d)", int(t))
+}
+
+// AttrSpec describes the acceptable values of a plug or slot attribute.
+type AttrSpec struct {
+	Type AttrType
+	// Required makes the attribute mandatory.
+	Required bool
+	// Values, if set, lists the acceptable values of a string attribute,
+	// or of the elements of a list of strings.
+	Values []string
+	// Pattern, if set, must be matched by the value of a string attribute,
+	// or by the elements of a list of strings.
+	Pattern *regexp.Regexp
+}
+
+// AttrSchema describes the attributes of the plugs or the slots of an
+// interface, by name. Attributes not described by the schema are not
+// checked and are left to the interface.
+type AttrSchema map[string]AttrSpec
+
+// PlugAttrsDeclarer is implemented by interfaces declaring the schema of
+// the attributes of their plugs. The attributes are validated against the
+// schema before the plug is sanitized by the interface, and before
+// connections of the plug.
+type PlugAttrsDeclarer interface {
+	PlugAttrSchema() AttrSchema
+}
+
+// SlotAttrsDeclarer is implemented by interfaces declaring the schema of
+// the attributes of their slots. The attributes are validated against the
+// schema before the slot is sanitized by the interface.
+type SlotAttrsDeclarer interface {
+	SlotAttrSchema() AttrSchema
+}
+
+// Validate checks the given attributes against the schema. The side is
+// "plug" or "slot" and is only used in error messages, together with the
+// interface name.
+//
+// When partial is true, missing required attributes are not reported, which
+// is useful when validating attributes which only complement others.
+func (schema AttrSchema) Validate(ifaceName, side string, attrs map[string]interface{}, partial bool) error {
+	names := make([]string, 0, len(schema))
+	for name := range schema {
+		names = append(names, name)
+	}
+	// report errors in a stable order
+	sort.Strings(names)
+	for _, name := range names {
+		spec := schema[name]
+		value, ok := attrs[name]
+		if !ok {
+			if spec.Required && !partial {
+				return fmt.Errorf("%s %s must have a %s attribute", ifaceName, side, name)
+			}
+			continue
+		}
+		if err := spec.validate(value); err != nil {
+			return fmt.Errorf("%s %s %s attribute %v", ifaceName, side, name, err)
+		}
+	}
+	return nil
+}
+
+func (spec AttrSpec) validate(value interface{}) error {
+	switch spec.Type {
+	case AttrString:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("must be %s", spec.Type)
+		}
+		return spec.validateString(s)
+	case AttrBool:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("must be %s", spec.Type)
+		}
+	case AttrInt:
+		switch value.(type) {
+		case int, int64:
+		default:
+			return fmt.Errorf("must be %s", spec.Type)
+		}
+	case AttrStringList:
+		list, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("must be %s", spec.Type)
+		}
+		for _, elem := range list {
+			s, ok := elem.(string)
+			if !ok {
+				return fmt.Errorf("must be %s", spec.Type)
+			}
+			if err := spec.validateString(s); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("has unsupported type %v", spec.Type)
+	}
+	return nil
+}
+
+func (spec AttrSpec) validateString(s string) error {
+	if len(spec.Values) > 0 && !strutil.ListContains(spec.Values, s) {
+		return fmt.Errorf("must be one of %s, not %q", strutil.Quoted(spec.Values), s)
+	}
+	if spec.Pattern != nil && !spec.Pattern.MatchString(s) {
+		return fmt.Errorf("has invalid value %q", s)
+	}
+	return nil
+}
+
+func validatePlugAttrs(iface Interface, plugInfo *snap.PlugInfo) error {
+	if iface, ok := iface.(PlugAttrsDeclarer); ok {
+		return iface.PlugAttrSchema().Validate(plugInfo.Interface, "plug", plugInfo.Attrs, false)
+	}
+	return nil
+}
+
+func validateSlotAttrs(iface Interface, slotInfo *snap.SlotInfo) error {
+	if iface, ok := iface.(SlotAttrsDeclarer); ok {
+		return iface.SlotAttrSchema().Validate(slotInfo.Interface, "slot", slotInfo.Attrs, false)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package interfaces_test
+
+import (
+	"regexp"
+
+	. "gopkg.in/check.v1"
+
+	. "github.com/snapcore/snapd/interfaces"
+)
+
+type AttrSchemaSuite struct{}
+
+var _ = Suite(&AttrSchemaSuite{})
+
+func (s *AttrSchemaSuite) TestTypes(c *C) {
+	schema := AttrSchema{
+		"str":  {Type: AttrString},
+		"bool": {Type: AttrBool},
+		"int":  {Type: AttrInt},
+		"list": {Type: AttrStringList},
+	}
+	attrs := map[string]interface{}{
+		"str":  "foo",
+		"bool": true,
+		"int":  int64(42),
+		"list": []interface{}{"a", "b"},
+	}
+	c.Check(schema.Validate("iface", "plug", attrs, false), IsNil)
+
+	for _, t := range []struct {
+		name  string
+		value interface{}
+		err   string
+	}{
+		{"str", 1, `iface plug str attribute must be a string`},
+		{"bool", "true", `iface plug bool attribute must be a boolean`},
+		{"int", "42", `iface plug int attribute must be an int`},
+		{"list", "a", `iface plug list attribute must be a list of strings`},
+		{"list", []interface{}{"a", 1}, `iface plug list attribute must be a list of strings`},
+	} {
+		bad := map[string]interface{}{t.name: t.value}
+		c.Check(schema.Validate("iface", "plug", bad, false), ErrorMatches, t.err, Commentf("%s: %v", t.name, t.value))
+	}
+}
+
+func (s *AttrSchemaSuite) TestRequired(c *C) {
+	schema := AttrSchema{
+		"number": {Type: AttrInt, Required: true},
+	}
+	c.Check(schema.Validate("gpio", "slot", nil, false), ErrorMatches, `gpio slot must have a number attribute`)
+	// missing required attributes are fine when validating partially
+	c.Check(schema.Validate("gpio", "slot", nil, true), IsNil)
+	c.Check(schema.Validate("gpio", "slot", map[string]interface{}{"number": "1"}, true), ErrorMatches,
+		`gpio slot number attribute must be an int`)
+}
+
+func (s *AttrSchemaSuite) TestValues(c *C) {
+	schema := AttrSchema{
+		"mode":  {Type: AttrString, Values: []string{"ro", "rw"}},
+		"modes": {Type: AttrStringList, Values: []string{"ro", "rw"}},
+	}
+	c.Check(schema.Validate("iface", "slot", map[string]interface{}{
+		"mode":  "ro",
+		"modes": []interface{}{"ro", "rw"},
+	}, false), IsNil)
+	c.Check(schema.Validate("iface", "slot", map[string]interface{}{"mode": "wo"}, false), ErrorMatches,
+		`iface slot mode attribute must be one of "ro", "rw", not "wo"`)
+	c.Check(schema.Validate("iface", "slot", map[string]interface{}{"modes": []interface{}{"ro", "wo"}}, false), ErrorMatches,
+		`iface slot modes attribute must be one of "ro", "rw", not "wo"`)
+}
+
+func (s *AttrSchemaSuite) TestPattern(c *C) {
+	schema := AttrSchema{
+		"path": {Type: AttrString, Pattern: regexp.MustCompile(`^/dev/[a-z]+$`)},
+	}
+	c.Check(schema.Validate("iface", "plug", map[string]interface{}{"path": "/dev/foo"}, false), IsNil)
+	c.Check(schema.Validate("iface", "plug", map[string]interface{}{"path": "/etc/foo"}, false), ErrorMatches,
+		`iface plug path attribute has invalid value "/etc/foo"`)
+}
+
+func (s *AttrSchemaSuite) TestUnknownAttributesIgnored(c *C) {
+	schema := AttrSchema{
+		"known": {Type: AttrBool},
+	}
+	c.Check(schema.Validate("iface", "plug", map[string]interface{}{"other": 1}, false), IsNil)
+}
+
+func (s *AttrSchemaSuite) TestStableErrorOrder(c *C) {
+	schema := AttrSchema{
+		"b": {Type: AttrBool, Required: true},
+		"a": {Type: AttrBool, Required: true},
+		"c": {Type: AttrBool, Required: true},
+	}
+	for i := 0; i < 10; i++ {
+		c.Check(schema.Validate("iface", "plug", nil, false), ErrorMatches, `iface plug must have a a attribute`)
+	}
+}
@@ -85,6 +85,9 @@ type SecurityBackendOptions struct {
 	// SnapdSnapInfo is the current revision of the snapd snap (if it is
 	// installed)
 	SnapdSnapInfo *snap.Info
+	// AppArmorHashCache flag is set when AppArmor profiles should be
+	// compiled in parallel into a cache keyed by their hash.
+	AppArmorHashCache bool
 }
 
 // SecurityBackend abstracts interactions between the interface system and the
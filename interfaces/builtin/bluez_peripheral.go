@@ -0,0 +1,197 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+import (
+	"strings"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/dbus"
+	"github.com/snapcore/snapd/interfaces/seccomp"
+	"github.com/snapcore/snapd/snap"
+)
+
+// The bluez-peripheral interface allows a snap to act as a Bluetooth Low
+// Energy peripheral: registering advertisements and GATT applications with
+// bluez and serving the requests bluez forwards to the objects the snap
+// exports. It is meant for beacon-style devices which would otherwise need
+// the much broader bluez or bluetooth-control interfaces.
+
+const bluezPeripheralSummary = `allows advertising and serving GATT services as a Bluetooth LE peripheral`
+
+const bluezPeripheralBaseDeclarationSlots = `
+  bluez-peripheral:
+    allow-installation:
+      slot-snap-type:
+        - app
+        - core
+    deny-installation:
+      slot-snap-type:
+        - app
+    deny-auto-connection: true
+`
+
+const bluezPeripheralConnectedPlugAppArmor = `
+# Description: Allow registering Bluetooth LE advertisements and GATT
+# applications with bluez and serving requests for them.
+
+#include <abstractions/dbus-strict>
+
+# Allow enumerating the adapters and reading their properties
+dbus (send)
+    bus=system
+    path=/
+    interface=org.freedesktop.DBus.ObjectManager
+    member=GetManagedObjects
+    peer=(name=org.bluez, label=###SLOT_SECURITY_TAGS###),
+dbus (receive)
+    bus=system
+    path=/
+    interface=org.freedesktop.DBus.ObjectManager
+    member="Interfaces{Added,Removed}"
+    peer=(label=###SLOT_SECURITY_TAGS###),
+dbus (send)
+    bus=system
+    path=/org/bluez/hci[0-9]*
+    interface=org.freedesktop.DBus.Properties
+    member="Get{,All}"
+    peer=(name=org.bluez, label=###SLOT_SECURITY_TAGS###),
+dbus (receive)
+    bus=system
+    path=/org/bluez/hci[0-9]*
+    interface=org.freedesktop.DBus.Properties
+    member=PropertiesChanged
+    peer=(label=###SLOT_SECURITY_TAGS###),
+
+# Allow (un)registering advertisements and GATT applications
+dbus (send)
+    bus=system
+    path=/org/bluez/hci[0-9]*
+    interface=org.bluez.LEAdvertisingManager1
+    member="{Register,Unregister}Advertisement"
+    peer=(name=org.bluez, label=###SLOT_SECURITY_TAGS###),
+dbus (send)
+    bus=system
+    path=/org/bluez/hci[0-9]*
+    interface=org.bluez.GattManager1
+    member="{Register,Unregister}Application"
+    peer=(name=org.bluez, label=###SLOT_SECURITY_TAGS###),
+
+# Allow bluez to query and call the objects exported by the snap. Their
+# object paths are chosen by the snap, so none are specified here.
+dbus (receive)
+    bus=system
+    interface=org.freedesktop.DBus.ObjectManager
+    member=GetManagedObjects
+    peer=(label=###SLOT_SECURITY_TAGS###),
+dbus (receive)
+    bus=system
+    interface=org.freedesktop.DBus.Properties
+    member="Get{,All}"
+    peer=(label=###SLOT_SECURITY_TAGS###),
+dbus (receive)
+    bus=system
+    interface=org.bluez.LEAdvertisement1
+    member=Release
+    peer=(label=###SLOT_SECURITY_TAGS###),
+dbus (receive)
+    bus=system
+    interface=org.bluez.Gatt{Characteristic,Descriptor}1
+    member="{ReadValue,WriteValue,StartNotify,StopNotify,AcquireWrite,AcquireNotify,Confirm}"
+    peer=(label=###SLOT_SECURITY_TAGS###),
+dbus (send)
+    bus=system
+    interface=org.freedesktop.DBus.Properties
+    member=PropertiesChanged
+    peer=(label=###SLOT_SECURITY_TAGS###),
+
+# Allow using the file descriptors shared by AcquireWrite and AcquireNotify
+unix (send, receive) type="seqpacket" addr=none peer=(addr=none label=###SLOT_SECURITY_TAGS###),
+`
+
+const bluezPeripheralConnectedSlotAppArmor = `
+# Allow the peripheral clients to register their advertisements and GATT
+# applications and to serve requests for them
+dbus (receive, send)
+    bus=system
+    peer=(label=###PLUG_SECURITY_TAGS###),
+
+# Allow sharing file descriptors (via DBus)
+unix (send, receive) type="seqpacket" addr=none peer=(addr=none label=###PLUG_SECURITY_TAGS###),
+`
+
+type bluezPeripheralInterface struct {
+	commonInterface
+}
+
+// The slot can be provided by a bluez snap, which then needs the same
+// privileges as for the bluez interface.
+
+func (iface *bluezPeripheralInterface) AppArmorPermanentSlot(spec *apparmor.Specification, slot *snap.SlotInfo) error {
+	if !implicitSystemPermanentSlot(slot) {
+		spec.AddSnippet(bluezPermanentSlotAppArmor)
+	}
+	return nil
+}
+
+func (iface *bluezPeripheralInterface) SecCompPermanentSlot(spec *seccomp.Specification, slot *snap.SlotInfo) error {
+	if !implicitSystemPermanentSlot(slot) {
+		spec.AddSnippet(bluezPermanentSlotSecComp)
+	}
+	return nil
+}
+
+func (iface *bluezPeripheralInterface) DBusPermanentSlot(spec *dbus.Specification, slot *snap.SlotInfo) error {
+	if !implicitSystemPermanentSlot(slot) {
+		spec.AddSnippet(bluezPermanentSlotDBus)
+	}
+	return nil
+}
+
+func (iface *bluezPeripheralInterface) AppArmorConnectedPlug(spec *apparmor.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	old := "###SLOT_SECURITY_TAGS###"
+	var new string
+	if implicitSystemConnectedSlot(slot) {
+		new = "unconfined"
+	} else {
+		new = slot.LabelExpression()
+	}
+	spec.AddSnippet(strings.Replace(bluezPeripheralConnectedPlugAppArmor, old, new, -1))
+	return nil
+}
+
+func (iface *bluezPeripheralInterface) AppArmorConnectedSlot(spec *apparmor.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	if !implicitSystemConnectedSlot(slot) {
+		old := "###PLUG_SECURITY_TAGS###"
+		new := plug.LabelExpression()
+		spec.AddSnippet(strings.Replace(bluezPeripheralConnectedSlotAppArmor, old, new, -1))
+	}
+	return nil
+}
+
+func init() {
+	registerIface(&bluezPeripheralInterface{commonInterface{
+		name:                 "bluez-peripheral",
+		summary:              bluezPeripheralSummary,
+		implicitOnClassic:    true,
+		baseDeclarationSlots: bluezPeripheralBaseDeclarationSlots,
+	}})
+}
@@ -0,0 +1,170 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/dbus"
+	"github.com/snapcore/snapd/interfaces/seccomp"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type BluezPeripheralInterfaceSuite struct {
+	iface        interfaces.Interface
+	appSlot      *interfaces.ConnectedSlot
+	appSlotInfo  *snap.SlotInfo
+	coreSlot     *interfaces.ConnectedSlot
+	coreSlotInfo *snap.SlotInfo
+	plug         *interfaces.ConnectedPlug
+	plugInfo     *snap.PlugInfo
+}
+
+var _ = Suite(&BluezPeripheralInterfaceSuite{
+	iface: builtin.MustInterface("bluez-peripheral"),
+})
+
+const bluezPeripheralConsumerYaml = `name: consumer
+version: 0
+apps:
+ app:
+  plugs: [bluez-peripheral]
+`
+
+const bluezPeripheralProducerYaml = `name: producer
+version: 0
+apps:
+ app:
+  slots: [bluez-peripheral]
+`
+
+const bluezPeripheralCoreYaml = `name: core
+version: 0
+type: os
+slots:
+  bluez-peripheral:
+`
+
+func (s *BluezPeripheralInterfaceSuite) SetUpTest(c *C) {
+	s.plug, s.plugInfo = MockConnectedPlug(c, bluezPeripheralConsumerYaml, nil, "bluez-peripheral")
+	s.appSlot, s.appSlotInfo = MockConnectedSlot(c, bluezPeripheralProducerYaml, nil, "bluez-peripheral")
+	s.coreSlot, s.coreSlotInfo = MockConnectedSlot(c, bluezPeripheralCoreYaml, nil, "bluez-peripheral")
+}
+
+func (s *BluezPeripheralInterfaceSuite) TestName(c *C) {
+	c.Assert(s.iface.Name(), Equals, "bluez-peripheral")
+}
+
+func (s *BluezPeripheralInterfaceSuite) TestSanitizeSlot(c *C) {
+	c.Assert(interfaces.BeforePrepareSlot(s.iface, s.appSlotInfo), IsNil)
+	c.Assert(interfaces.BeforePrepareSlot(s.iface, s.coreSlotInfo), IsNil)
+}
+
+func (s *BluezPeripheralInterfaceSuite) TestSanitizePlug(c *C) {
+	c.Assert(interfaces.BeforePreparePlug(s.iface, s.plugInfo), IsNil)
+}
+
+func (s *BluezPeripheralInterfaceSuite) TestAppArmorSpecAppSlot(c *C) {
+	spec := apparmor.NewSpecification(s.plug.AppSet())
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.appSlot), IsNil)
+	c.Assert(spec.SecurityTags(), DeepEquals, []string{"snap.consumer.app"})
+	snippet := spec.SnippetForTag("snap.consumer.app")
+	c.Check(snippet, testutil.Contains, "interface=org.bluez.LEAdvertisingManager1\n    member=\"{Register,Unregister}Advertisement\"\n    peer=(name=org.bluez, label=\"snap.producer.app\"),")
+	c.Check(snippet, testutil.Contains, "interface=org.bluez.GattManager1\n")
+	// raw HCI access is left to bluetooth-control
+	c.Check(snippet, Not(testutil.Contains), "capability net_raw,")
+	c.Check(snippet, Not(testutil.Contains), "network bluetooth,")
+	c.Check(snippet, Not(testutil.Contains), "label=unconfined")
+
+	spec = apparmor.NewSpecification(s.appSlot.AppSet())
+	c.Assert(spec.AddConnectedSlot(s.iface, s.plug, s.appSlot), IsNil)
+	c.Assert(spec.SecurityTags(), DeepEquals, []string{"snap.producer.app"})
+	c.Check(spec.SnippetForTag("snap.producer.app"), testutil.Contains, "peer=(label=\"snap.consumer.app\"),")
+}
+
+func (s *BluezPeripheralInterfaceSuite) TestAppArmorSpecCoreSlot(c *C) {
+	spec := apparmor.NewSpecification(s.plug.AppSet())
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.coreSlot), IsNil)
+	snippet := spec.SnippetForTag("snap.consumer.app")
+	c.Check(snippet, testutil.Contains, "interface=org.bluez.GattManager1\n    member=\"{Register,Unregister}Application\"\n    peer=(name=org.bluez, label=unconfined),")
+	c.Check(snippet, Not(testutil.Contains), "###SLOT_SECURITY_TAGS###")
+
+	// the implicit slot is provided by the unconfined system bluez
+	spec = apparmor.NewSpecification(s.coreSlot.AppSet())
+	c.Assert(spec.AddConnectedSlot(s.iface, s.plug, s.coreSlot), IsNil)
+	c.Check(spec.SecurityTags(), HasLen, 0)
+}
+
+func (s *BluezPeripheralInterfaceSuite) TestSecCompSpec(c *C) {
+	spec := seccomp.NewSpecification(s.plug.AppSet())
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.coreSlot), IsNil)
+	c.Check(spec.SecurityTags(), HasLen, 0)
+}
+
+func (s *BluezPeripheralInterfaceSuite) TestPermanentSlotAppSlot(c *C) {
+	appSet := s.appSlot.AppSet()
+	apparmorSpec := apparmor.NewSpecification(appSet)
+	c.Assert(apparmorSpec.AddPermanentSlot(s.iface, s.appSlotInfo), IsNil)
+	c.Assert(apparmorSpec.SecurityTags(), DeepEquals, []string{"snap.producer.app"})
+	c.Check(apparmorSpec.SnippetForTag("snap.producer.app"), testutil.Contains, "network bluetooth,\n")
+
+	seccompSpec := seccomp.NewSpecification(appSet)
+	c.Assert(seccompSpec.AddPermanentSlot(s.iface, s.appSlotInfo), IsNil)
+	c.Check(seccompSpec.SnippetForTag("snap.producer.app"), testutil.Contains, "bind\n")
+
+	dbusSpec := dbus.NewSpecification(appSet)
+	c.Assert(dbusSpec.AddPermanentSlot(s.iface, s.appSlotInfo), IsNil)
+	c.Check(dbusSpec.SnippetForTag("snap.producer.app"), testutil.Contains, `<allow own="org.bluez"/>`)
+}
+
+func (s *BluezPeripheralInterfaceSuite) TestPermanentSlotCoreSlot(c *C) {
+	appSet := s.coreSlot.AppSet()
+	apparmorSpec := apparmor.NewSpecification(appSet)
+	c.Assert(apparmorSpec.AddPermanentSlot(s.iface, s.coreSlotInfo), IsNil)
+	c.Check(apparmorSpec.SecurityTags(), HasLen, 0)
+
+	seccompSpec := seccomp.NewSpecification(appSet)
+	c.Assert(seccompSpec.AddPermanentSlot(s.iface, s.coreSlotInfo), IsNil)
+	c.Check(seccompSpec.SecurityTags(), HasLen, 0)
+
+	dbusSpec := dbus.NewSpecification(appSet)
+	c.Assert(dbusSpec.AddPermanentSlot(s.iface, s.coreSlotInfo), IsNil)
+	c.Check(dbusSpec.SecurityTags(), HasLen, 0)
+}
+
+func (s *BluezPeripheralInterfaceSuite) TestStaticInfo(c *C) {
+	si := interfaces.StaticInfoOf(s.iface)
+	c.Assert(si.ImplicitOnCore, Equals, false)
+	c.Assert(si.ImplicitOnClassic, Equals, true)
+	c.Assert(si.Summary, Equals, `allows advertising and serving GATT services as a Bluetooth LE peripheral`)
+	c.Assert(si.BaseDeclarationSlots, testutil.Contains, "bluez-peripheral")
+}
+
+func (s *BluezPeripheralInterfaceSuite) TestAutoConnect(c *C) {
+	c.Assert(s.iface.AutoConnect(s.plugInfo, s.coreSlotInfo), Equals, true)
+}
+
+func (s *BluezPeripheralInterfaceSuite) TestInterfaces(c *C) {
+	c.Assert(builtin.Interfaces(), testutil.DeepContains, s.iface)
+}
@@ -63,21 +63,12 @@ func (iface *gpioInterface) StaticInfo() interfaces.StaticInfo {
 	}
 }
 
-// BeforePrepareSlot checks the slot definition is valid
-func (iface *gpioInterface) BeforePrepareSlot(slot *snap.SlotInfo) error {
-	// Must have a GPIO number
-	number, ok := slot.Attrs["number"]
-	if !ok {
-		return fmt.Errorf("gpio slot must have a number attribute")
+// SlotAttrSchema declares the attributes of gpio slots, which must have
+// a GPIO number.
+func (iface *gpioInterface) SlotAttrSchema() interfaces.AttrSchema {
+	return interfaces.AttrSchema{
+		"number": {Type: interfaces.AttrInt, Required: true},
 	}
-
-	// Valid values of number
-	if _, ok := number.(int64); !ok {
-		return fmt.Errorf("gpio slot number attribute must be an int")
-	}
-
-	// Slot is good
-	return nil
 }
 
 func (iface *gpioInterface) AppArmorConnectedPlug(spec *apparmor.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
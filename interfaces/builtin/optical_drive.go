@@ -20,11 +20,8 @@
 package builtin
 
 import (
-	"fmt"
-
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
-	"github.com/snapcore/snapd/snap"
 )
 
 const opticalDriveSummary = `allows access to optical drives`
@@ -67,19 +64,12 @@ type opticalDriveInterface struct {
 	commonInterface
 }
 
-// BeforePrepareSlot checks and possibly modifies a slot.
-// Valid "optical-drive" slots may contain the attribute "write".
-// If defined, the attribute "write" must be either "true" or "false".
-func (iface *opticalDriveInterface) BeforePreparePlug(plug *snap.PlugInfo) error {
-	// It's fine if 'write' isn't specified, but if it is, it needs to be bool
-	if w, ok := plug.Attrs["write"]; ok {
-		_, ok = w.(bool)
-		if !ok {
-			return fmt.Errorf(`optical-drive "write" attribute must be a boolean`)
-		}
+// PlugAttrSchema declares the attributes of optical-drive plugs. The
+// optional "write" attribute grants write access to the drives.
+func (iface *opticalDriveInterface) PlugAttrSchema() interfaces.AttrSchema {
+	return interfaces.AttrSchema{
+		"write": {Type: interfaces.AttrBool},
 	}
-
-	return nil
 }
 
 func (iface *opticalDriveInterface) AppArmorConnectedPlug(spec *apparmor.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
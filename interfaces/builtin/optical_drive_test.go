@@ -104,6 +104,18 @@ func (s *OpticalDriveInterfaceSuite) TestSanitizePlug(c *C) {
 	c.Assert(interfaces.BeforePreparePlug(s.iface, s.testPlugWritableInfo), IsNil)
 }
 
+func (s *OpticalDriveInterfaceSuite) TestSanitizePlugBadWrite(c *C) {
+	const mockSnapYaml = `name: optical-drive-client
+version: 1.0
+plugs:
+  optical-drive:
+    write: "yes"
+`
+	plug := MockPlug(c, mockSnapYaml, nil, "optical-drive")
+	c.Assert(interfaces.BeforePreparePlug(s.iface, plug), ErrorMatches,
+		"optical-drive plug write attribute must be a boolean")
+}
+
 func (s *OpticalDriveInterfaceSuite) TestAppArmorSpec(c *C) {
 	type options struct {
 		appName         string
@@ -49,32 +49,13 @@ type pwmInterface struct {
 	commonInterface
 }
 
-// BeforePrepareSlot checks the slot definition is valid
-func (iface *pwmInterface) BeforePrepareSlot(slot *snap.SlotInfo) error {
-	// must have a PWM channel
-	channel, ok := slot.Attrs["channel"]
-	if !ok {
-		return fmt.Errorf("pwm slot must have a channel attribute")
+// SlotAttrSchema declares the attributes of pwm slots, which must have a
+// PWM channel and chip number.
+func (iface *pwmInterface) SlotAttrSchema() interfaces.AttrSchema {
+	return interfaces.AttrSchema{
+		"channel":     {Type: interfaces.AttrInt, Required: true},
+		"chip-number": {Type: interfaces.AttrInt, Required: true},
 	}
-
-	// valid values of channel
-	if _, ok := channel.(int64); !ok {
-		return fmt.Errorf("pwm slot channel attribute must be an int")
-	}
-
-	// must have a PWM chip number
-	chipNum, ok := slot.Attrs["chip-number"]
-	if !ok {
-		return fmt.Errorf("pwm slot must have a chip-number attribute")
-	}
-
-	// valid values of chip number
-	if _, ok := chipNum.(int64); !ok {
-		return fmt.Errorf("pwm slot chip-number attribute must be an int")
-	}
-
-	// slot is good
-	return nil
 }
 
 func (iface *pwmInterface) AppArmorConnectedPlug(spec *apparmor.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
@@ -46,6 +46,11 @@ const rawInputConnectedPlugAppArmor = `
 
 /dev/input/* rw,
 
+# Allow discovering the input devices, as done by game controller and
+# kiosk libraries which enumerate them rather than rely on udev alone
+/dev/input/ r,
+/dev/input/by-{id,path}/ r,
+
 # Allow reading for supported event reports for all input devices. See
 # https://www.kernel.org/doc/Documentation/input/event-codes.txt
 /sys/devices/**/input[0-9]*/capabilities/* r,
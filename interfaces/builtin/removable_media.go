@@ -19,6 +19,20 @@
 
 package builtin
 
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/utils"
+	apparmor_sandbox "github.com/snapcore/snapd/sandbox/apparmor"
+	"github.com/snapcore/snapd/snap"
+)
+
 const removableMediaSummary = `allows access to mounted removable storage`
 
 const removableMediaBaseDeclarationSlots = `
@@ -49,13 +63,128 @@ const removableMediaConnectedPlugAppArmor = `
 /mnt/** mrwklix,
 `
 
+const removableMediaConnectedPlugAppArmorRestricted = `
+# Description: Can access removable storage filesystems with the given
+# filesystem labels or mounted at the given paths only
+
+# Allow read-access to /run/ for navigating to removable media.
+/run/ r,
+
+# Allow read on /run/media/ and /media/ for navigating to the mount points.
+/{,run/}media/ r,
+/{,run/}media/*/ r,
+
+# Allow read-only access to /mnt to enumerate items.
+/mnt/ r,
+`
+
+// Mount paths must be below the locations allowed by the unrestricted
+// policy.
+var removableMediaMountPathRegexp = regexp.MustCompile(`^/((run/)?media|mnt)/[^"@]+$`)
+
+// hasControlChars returns whether the string contains control characters,
+// like newlines, which could be used to inject AppArmor rules.
+func hasControlChars(s string) bool {
+	return strings.IndexFunc(s, unicode.IsControl) >= 0
+}
+
+type removableMediaInterface struct {
+	commonInterface
+}
+
+func validateRemovableMediaLabel(label string) error {
+	// Filesystem labels are used as the name of the mount point directory
+	// by udisks, so they cannot contain "/". Characters with a special
+	// meaning for AppArmor are forbidden too.
+	if label == "" || label == "." || label == ".." || hasControlChars(label) || strings.ContainsAny(label, `/,@\`) {
+		return fmt.Errorf(`removable-media "labels" attribute contains invalid label: %q`, label)
+	}
+	if err := apparmor_sandbox.ValidateNoAppArmorRegexp(label); err != nil {
+		return fmt.Errorf(`removable-media "labels" attribute contains invalid label: %q`, label)
+	}
+	return nil
+}
+
+func validateRemovableMediaMountPath(path string) error {
+	if hasControlChars(path) || !removableMediaMountPathRegexp.MatchString(path) {
+		return fmt.Errorf(`removable-media "mount-paths" attribute must contain paths below /media, /run/media or /mnt without special characters: %q`, path)
+	}
+	if !cleanSubPath(path) {
+		return fmt.Errorf(`removable-media "mount-paths" attribute contains path which is not clean: %q`, path)
+	}
+	// "**" is an AppArmor specific globbing pattern which we don't want to
+	// expose in our API contract.
+	if strings.Contains(path, "**") {
+		return fmt.Errorf(`removable-media "mount-paths" attribute contains invalid glob pattern "**": %q`, path)
+	}
+	const allowCommas = false
+	if _, err := utils.NewPathPattern(path, allowCommas); err != nil {
+		return fmt.Errorf(`removable-media "mount-paths" attribute cannot be used: %v`, err)
+	}
+	return nil
+}
+
+func (iface *removableMediaInterface) BeforePreparePlug(plug *snap.PlugInfo) error {
+	for _, attr := range []struct {
+		name     string
+		validate func(string) error
+	}{
+		{"labels", validateRemovableMediaLabel},
+		{"mount-paths", validateRemovableMediaMountPath},
+	} {
+		if _, ok := plug.Attrs[attr.name]; !ok {
+			continue
+		}
+		var values []string
+		if err := plug.Attr(attr.name, &values); err != nil || len(values) == 0 {
+			return fmt.Errorf(`removable-media %q attribute must be a non-empty list of strings`, attr.name)
+		}
+		for _, value := range values {
+			if err := attr.validate(value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (iface *removableMediaInterface) AppArmorConnectedPlug(spec *apparmor.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	// attributes were validated before, on error no rule is written
+	var labels, mountPaths []string
+	_ = plug.Attr("labels", &labels)
+	_ = plug.Attr("mount-paths", &mountPaths)
+
+	// without any restriction all removable media are accessible
+	if len(labels) == 0 && len(mountPaths) == 0 {
+		spec.AddSnippet(removableMediaConnectedPlugAppArmor)
+		return nil
+	}
+
+	// The mount namespace of the snap shares /media, /run/media and /mnt
+	// with the host, see snap-confine, so that media mounted later are
+	// visible. The restriction to the given labels and mount paths is thus
+	// enforced by the AppArmor policy alone.
+	var snippet bytes.Buffer
+	snippet.WriteString(removableMediaConnectedPlugAppArmorRestricted)
+	for _, label := range labels {
+		// mount points are /run/media/<user>/<label> or /media/<user>/<label>
+		fmt.Fprintf(&snippet, "\"/{,run/}media/*/%s/\" r,\n", label)
+		fmt.Fprintf(&snippet, "\"/{,run/}media/*/%s/**\" mrwklix,\n", label)
+	}
+	for _, path := range mountPaths {
+		fmt.Fprintf(&snippet, "\"%s/\" r,\n", path)
+		fmt.Fprintf(&snippet, "\"%s/**\" mrwklix,\n", path)
+	}
+	spec.AddSnippet(snippet.String())
+	return nil
+}
+
 func init() {
-	registerIface(&commonInterface{
-		name:                  "removable-media",
-		summary:               removableMediaSummary,
-		implicitOnCore:        true,
-		implicitOnClassic:     true,
-		baseDeclarationSlots:  removableMediaBaseDeclarationSlots,
-		connectedPlugAppArmor: removableMediaConnectedPlugAppArmor,
-	})
+	registerIface(&removableMediaInterface{commonInterface{
+		name:                 "removable-media",
+		summary:              removableMediaSummary,
+		implicitOnCore:       true,
+		implicitOnClassic:    true,
+		baseDeclarationSlots: removableMediaBaseDeclarationSlots,
+	}})
 }
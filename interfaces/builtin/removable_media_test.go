@@ -20,6 +20,8 @@
 package builtin_test
 
 import (
+	"fmt"
+
 	. "gopkg.in/check.v1"
 
 	"github.com/snapcore/snapd/interfaces"
@@ -82,6 +84,85 @@ func (s *RemovableMediaInterfaceSuite) TestUsedSecuritySystems(c *C) {
 	c.Check(apparmorSpec.SnippetForTag("snap.client-snap.other"), testutil.Contains, "/mnt/** mrwklix,")
 }
 
+func (s *RemovableMediaInterfaceSuite) TestSanitizePlugRestricted(c *C) {
+	const mockSnapYaml = `name: client-snap
+version: 0
+plugs:
+ removable-media:
+  labels: [KIOSK, "My Stick"]
+  mount-paths: [/media/*/data, /mnt/usb]
+`
+	plug := MockPlug(c, mockSnapYaml, nil, "removable-media")
+	c.Assert(interfaces.BeforePreparePlug(s.iface, plug), IsNil)
+}
+
+func (s *RemovableMediaInterfaceSuite) TestSanitizePlugRestrictedErrors(c *C) {
+	const mockSnapYaml = `name: client-snap
+version: 0
+plugs:
+ removable-media:
+  %s
+`
+	for _, tc := range []struct {
+		attr   string
+		errStr string
+	}{
+		{`labels: KIOSK`, `removable-media "labels" attribute must be a non-empty list of strings`},
+		{`labels: []`, `removable-media "labels" attribute must be a non-empty list of strings`},
+		{`labels: [1]`, `removable-media "labels" attribute must be a non-empty list of strings`},
+		{`labels: [a/b]`, `removable-media "labels" attribute contains invalid label: "a/b"`},
+		{`labels: [".."]`, `removable-media "labels" attribute contains invalid label: "\.\."`},
+		{`labels: ["KIO*"]`, `removable-media "labels" attribute contains invalid label: "KIO\*"`},
+		{`labels: ["{a,b}"]`, `removable-media "labels" attribute contains invalid label: "{a,b}"`},
+		{`labels: ["a\n/** rwx,"]`, `removable-media "labels" attribute contains invalid label: "a\\n/\*\* rwx,"`},
+		{`labels: ["a,b"]`, `removable-media "labels" attribute contains invalid label: "a,b"`},
+		{`labels: ["a@b"]`, `removable-media "labels" attribute contains invalid label: "a@b"`},
+		{`labels: ["a\tb"]`, `removable-media "labels" attribute contains invalid label: "a\\tb"`},
+		{`labels: [""]`, `removable-media "labels" attribute contains invalid label: ""`},
+		{`mount-paths: ["/mnt/usb\n/** rwx"]`, `removable-media "mount-paths" attribute must contain .*`},
+		{`mount-paths: /mnt/usb`, `removable-media "mount-paths" attribute must be a non-empty list of strings`},
+		{`mount-paths: [/home/usb]`, `removable-media "mount-paths" attribute must contain paths below /media, /run/media or /mnt without special characters: "/home/usb"`},
+		{`mount-paths: [/mnt/]`, `removable-media "mount-paths" attribute must contain .*`},
+		{`mount-paths: ["/mnt/@{HOME}"]`, `removable-media "mount-paths" attribute must contain .*`},
+		{`mount-paths: [/mnt/usb/]`, `removable-media "mount-paths" attribute contains path which is not clean: "/mnt/usb/"`},
+		{`mount-paths: [/mnt/../etc]`, `removable-media "mount-paths" attribute contains path which is not clean: "/mnt/../etc"`},
+		{`mount-paths: [/mnt/**]`, `removable-media "mount-paths" attribute contains invalid glob pattern "\*\*": "/mnt/\*\*"`},
+		{`mount-paths: ["/mnt/{a,b"]`, `removable-media "mount-paths" attribute cannot be used: .*`},
+	} {
+		plug := MockPlug(c, fmt.Sprintf(mockSnapYaml, tc.attr), nil, "removable-media")
+		c.Check(interfaces.BeforePreparePlug(s.iface, plug), ErrorMatches, tc.errStr, Commentf("%s", tc.attr))
+	}
+}
+
+func (s *RemovableMediaInterfaceSuite) TestAppArmorSpecRestricted(c *C) {
+	const mockPlugSnapInfoYaml = `name: client-snap
+version: 0
+apps:
+ other:
+  command: foo
+  plugs: [removable-media]
+plugs:
+ removable-media:
+  labels: [KIOSK, "My Stick"]
+  mount-paths: [/mnt/usb]
+`
+	plug, _ := MockConnectedPlug(c, mockPlugSnapInfoYaml, nil, "removable-media")
+
+	apparmorSpec := apparmor.NewSpecification(plug.AppSet())
+	err := apparmorSpec.AddConnectedPlug(s.iface, plug, s.slot)
+	c.Assert(err, IsNil)
+	c.Assert(apparmorSpec.SecurityTags(), DeepEquals, []string{"snap.client-snap.other"})
+	snippet := apparmorSpec.SnippetForTag("snap.client-snap.other")
+	c.Check(snippet, testutil.Contains, "/{,run/}media/*/ r,\n")
+	c.Check(snippet, testutil.Contains, "/mnt/ r,\n")
+	c.Check(snippet, testutil.Contains, "\"/{,run/}media/*/KIOSK/\" r,\n\"/{,run/}media/*/KIOSK/**\" mrwklix,\n")
+	c.Check(snippet, testutil.Contains, "\"/{,run/}media/*/My Stick/\" r,\n\"/{,run/}media/*/My Stick/**\" mrwklix,\n")
+	c.Check(snippet, testutil.Contains, "\"/mnt/usb/\" r,\n\"/mnt/usb/**\" mrwklix,\n")
+	// no unrestricted access
+	c.Check(snippet, Not(testutil.Contains), "/{,run/}media/*/** mrwklix,")
+	c.Check(snippet, Not(testutil.Contains), "/mnt/** mrwklix,")
+}
+
 func (s *RemovableMediaInterfaceSuite) TestInterfaces(c *C) {
 	c.Check(builtin.Interfaces(), testutil.DeepContains, s.iface)
 }
@@ -0,0 +1,327 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/dbus"
+	"github.com/snapcore/snapd/interfaces/seccomp"
+	"github.com/snapcore/snapd/interfaces/udev"
+	"github.com/snapcore/snapd/snap"
+)
+
+// The udisks2-client interface grants the subset of the UDisks2 API that
+// file managers need to work with removable media: mounting, unmounting,
+// unlocking and ejecting them. Unlike the udisks2 interface it does not
+// allow formatting, partitioning or otherwise modifying block devices.
+//
+// Usage: plugs may set the following attribute:
+//
+//	loop-devices: true
+//
+// which additionally allows setting up and deleting loop devices, for
+// example to mount disk images.
+//
+// Whether a device may be mounted without authentication is still decided by
+// the polkit policy of UDisks2, which distinguishes removable from system
+// devices.
+//
+// Like with the udisks2 interface, the slot is either implicit on classic or
+// provided by a snap running the UDisks2 service, in which case the slot side
+// gets the same permanent policy as with udisks2 and only serves the subset
+// of the API above to the connected clients.
+
+const udisks2ClientSummary = `allows mounting and unmounting removable media via the UDisks2 service`
+
+const udisks2ClientBaseDeclarationSlots = `
+  udisks2-client:
+    allow-installation:
+      slot-snap-type:
+        - app
+        - core
+    deny-connection:
+      on-classic: false
+    deny-auto-connection: true
+`
+
+const udisks2ClientConnectedPlugAppArmor = `
+# Description: Allow mounting and unmounting removable media via the UDisks2
+# service. Formatting and partitioning block devices is not allowed.
+
+#include <abstractions/dbus-strict>
+
+dbus (send)
+    bus=system
+    path=/org/freedesktop/UDisks2
+    interface=org.freedesktop.DBus.Peer
+    member=Ping
+    peer=(label=###SLOT_SECURITY_TAGS###),
+
+# Allow reading the properties of all objects and getting notified about
+# changes. Properties can not be set.
+# do not use peer=(label=unconfined) here since this is DBus activated
+dbus (send)
+    bus=system
+    path=/org/freedesktop/UDisks2/**
+    interface=org.freedesktop.DBus.Properties
+    member="Get{,All}",
+dbus (receive)
+    bus=system
+    path=/org/freedesktop/UDisks2/**
+    interface=org.freedesktop.DBus.Properties
+    member=PropertiesChanged
+    peer=(label=###SLOT_SECURITY_TAGS###),
+
+# Allow enumerating the drives and block devices
+dbus (send)
+    bus=system
+    path=/org/freedesktop/UDisks2
+    interface=org.freedesktop.DBus.ObjectManager
+    member=GetManagedObjects
+    peer=(label=###SLOT_SECURITY_TAGS###),
+dbus (receive)
+    bus=system
+    path=/org/freedesktop/UDisks2
+    interface=org.freedesktop.DBus.ObjectManager
+    member="Interfaces{Added,Removed}"
+    peer=(label=###SLOT_SECURITY_TAGS###),
+
+dbus (send)
+    bus=system
+    path=/org/freedesktop/UDisks2/block_devices/*
+    interface=org.freedesktop.UDisks2.Filesystem
+    member="{Mount,Unmount}"
+    peer=(label=###SLOT_SECURITY_TAGS###),
+
+dbus (send)
+    bus=system
+    path=/org/freedesktop/UDisks2/block_devices/*
+    interface=org.freedesktop.UDisks2.Encrypted
+    member="{Unlock,Lock}"
+    peer=(label=###SLOT_SECURITY_TAGS###),
+
+dbus (send)
+    bus=system
+    path=/org/freedesktop/UDisks2/drives/*
+    interface=org.freedesktop.UDisks2.Drive
+    member="{Eject,PowerOff}"
+    peer=(label=###SLOT_SECURITY_TAGS###),
+
+# Allow clients to introspect the service
+# do not use peer=(label=unconfined) here since this is DBus activated
+dbus (send)
+    bus=system
+    path=/org/freedesktop/UDisks2{,/**}
+    interface=org.freedesktop.DBus.Introspectable
+    member=Introspect,
+`
+
+const udisks2ClientConnectedPlugAppArmorLoopDevices = `
+# Allow setting up loop devices from file descriptors and deleting them
+dbus (send)
+    bus=system
+    path=/org/freedesktop/UDisks2/Manager
+    interface=org.freedesktop.UDisks2.Manager
+    member=LoopSetup
+    peer=(label=###SLOT_SECURITY_TAGS###),
+
+dbus (send)
+    bus=system
+    path=/org/freedesktop/UDisks2/block_devices/loop*
+    interface=org.freedesktop.UDisks2.Loop
+    member="{Delete,SetAutoclear}"
+    peer=(label=###SLOT_SECURITY_TAGS###),
+`
+
+const udisks2ClientConnectedSlotAppArmor = `
+# Allow connected clients to mount and unmount removable media via the
+# service.
+
+dbus (receive)
+    bus=system
+    path=/org/freedesktop/UDisks2
+    interface=org.freedesktop.DBus.Peer
+    member=Ping
+    peer=(label=###PLUG_SECURITY_TAGS###),
+
+dbus (receive)
+    bus=system
+    path=/org/freedesktop/UDisks2/**
+    interface=org.freedesktop.DBus.Properties
+    member="Get{,All}"
+    peer=(label=###PLUG_SECURITY_TAGS###),
+dbus (send)
+    bus=system
+    path=/org/freedesktop/UDisks2/**
+    interface=org.freedesktop.DBus.Properties
+    member=PropertiesChanged
+    peer=(label=###PLUG_SECURITY_TAGS###),
+
+dbus (receive)
+    bus=system
+    path=/org/freedesktop/UDisks2
+    interface=org.freedesktop.DBus.ObjectManager
+    member=GetManagedObjects
+    peer=(label=###PLUG_SECURITY_TAGS###),
+dbus (send)
+    bus=system
+    path=/org/freedesktop/UDisks2
+    interface=org.freedesktop.DBus.ObjectManager
+    member="Interfaces{Added,Removed}"
+    peer=(label=###PLUG_SECURITY_TAGS###),
+
+dbus (receive)
+    bus=system
+    path=/org/freedesktop/UDisks2/block_devices/*
+    interface=org.freedesktop.UDisks2.Filesystem
+    member="{Mount,Unmount}"
+    peer=(label=###PLUG_SECURITY_TAGS###),
+
+dbus (receive)
+    bus=system
+    path=/org/freedesktop/UDisks2/block_devices/*
+    interface=org.freedesktop.UDisks2.Encrypted
+    member="{Unlock,Lock}"
+    peer=(label=###PLUG_SECURITY_TAGS###),
+
+dbus (receive)
+    bus=system
+    path=/org/freedesktop/UDisks2/drives/*
+    interface=org.freedesktop.UDisks2.Drive
+    member="{Eject,PowerOff}"
+    peer=(label=###PLUG_SECURITY_TAGS###),
+
+# Allow clients to introspect the service
+dbus (receive)
+    bus=system
+    path=/org/freedesktop/UDisks2{,/**}
+    interface=org.freedesktop.DBus.Introspectable
+    member=Introspect
+    peer=(label=###PLUG_SECURITY_TAGS###),
+`
+
+const udisks2ClientConnectedSlotAppArmorLoopDevices = `
+# Allow connected clients to set up and delete loop devices
+dbus (receive)
+    bus=system
+    path=/org/freedesktop/UDisks2/Manager
+    interface=org.freedesktop.UDisks2.Manager
+    member=LoopSetup
+    peer=(label=###PLUG_SECURITY_TAGS###),
+
+dbus (receive)
+    bus=system
+    path=/org/freedesktop/UDisks2/block_devices/loop*
+    interface=org.freedesktop.UDisks2.Loop
+    member="{Delete,SetAutoclear}"
+    peer=(label=###PLUG_SECURITY_TAGS###),
+`
+
+type udisks2ClientInterface struct {
+	commonInterface
+}
+
+func (iface *udisks2ClientInterface) BeforePreparePlug(plug *snap.PlugInfo) error {
+	if v, ok := plug.Attrs["loop-devices"]; ok {
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf(`udisks2-client "loop-devices" attribute must be a boolean`)
+		}
+	}
+	return nil
+}
+
+func (iface *udisks2ClientInterface) AppArmorConnectedPlug(spec *apparmor.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	snippet := udisks2ClientConnectedPlugAppArmor
+	var loopDevices bool
+	_ = plug.Attr("loop-devices", &loopDevices)
+	if loopDevices {
+		snippet += udisks2ClientConnectedPlugAppArmorLoopDevices
+	}
+
+	old := "###SLOT_SECURITY_TAGS###"
+	var new string
+	if implicitSystemConnectedSlot(slot) {
+		new = "unconfined"
+	} else {
+		new = slot.LabelExpression()
+	}
+	spec.AddSnippet(strings.Replace(snippet, old, new, -1))
+	return nil
+}
+
+func (iface *udisks2ClientInterface) AppArmorConnectedSlot(spec *apparmor.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	if implicitSystemConnectedSlot(slot) {
+		return nil
+	}
+	snippet := udisks2ClientConnectedSlotAppArmor
+	var loopDevices bool
+	_ = plug.Attr("loop-devices", &loopDevices)
+	if loopDevices {
+		snippet += udisks2ClientConnectedSlotAppArmorLoopDevices
+	}
+	spec.AddSnippet(strings.Replace(snippet, "###PLUG_SECURITY_TAGS###", plug.LabelExpression(), -1))
+	return nil
+}
+
+// The permanent slot policy is the one of the udisks2 interface, the service
+// is the same.
+
+func (iface *udisks2ClientInterface) AppArmorPermanentSlot(spec *apparmor.Specification, slot *snap.SlotInfo) error {
+	if !implicitSystemPermanentSlot(slot) {
+		spec.AddSnippet(udisks2PermanentSlotAppArmor)
+	}
+	return nil
+}
+
+func (iface *udisks2ClientInterface) DBusPermanentSlot(spec *dbus.Specification, slot *snap.SlotInfo) error {
+	if !implicitSystemPermanentSlot(slot) {
+		spec.AddSnippet(udisks2PermanentSlotDBus)
+	}
+	return nil
+}
+
+func (iface *udisks2ClientInterface) SecCompPermanentSlot(spec *seccomp.Specification, slot *snap.SlotInfo) error {
+	if !implicitSystemPermanentSlot(slot) {
+		spec.AddSnippet(udisks2PermanentSlotSecComp)
+	}
+	return nil
+}
+
+func (iface *udisks2ClientInterface) UDevPermanentSlot(spec *udev.Specification, slot *snap.SlotInfo) error {
+	if !implicitSystemPermanentSlot(slot) {
+		spec.AddSnippet(udisks2PermanentSlotUDev)
+		spec.TagDevice(`SUBSYSTEM=="block"`)
+		spec.TagDevice(`SUBSYSTEM=="usb"`)
+	}
+	return nil
+}
+
+func init() {
+	registerIface(&udisks2ClientInterface{commonInterface{
+		name:                 "udisks2-client",
+		summary:              udisks2ClientSummary,
+		implicitOnClassic:    true,
+		baseDeclarationSlots: udisks2ClientBaseDeclarationSlots,
+	}})
+}
@@ -0,0 +1,211 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/dbus"
+	"github.com/snapcore/snapd/interfaces/seccomp"
+	"github.com/snapcore/snapd/interfaces/udev"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type UDisks2ClientInterfaceSuite struct {
+	iface        interfaces.Interface
+	coreSlotInfo *snap.SlotInfo
+	coreSlot     *interfaces.ConnectedSlot
+	appSlotInfo  *snap.SlotInfo
+	appSlot      *interfaces.ConnectedSlot
+	plugInfo     *snap.PlugInfo
+	plug         *interfaces.ConnectedPlug
+}
+
+var _ = Suite(&UDisks2ClientInterfaceSuite{
+	iface: builtin.MustInterface("udisks2-client"),
+})
+
+const udisks2ClientConsumerYaml = `name: consumer
+version: 0
+apps:
+ app:
+  plugs: [udisks2-client]
+`
+
+const udisks2ClientLoopConsumerYaml = `name: consumer
+version: 0
+plugs:
+ udisks2-client:
+  loop-devices: true
+apps:
+ app:
+  plugs: [udisks2-client]
+`
+
+const udisks2ClientCoreYaml = `name: core
+version: 0
+type: os
+slots:
+ udisks2-client:
+`
+
+const udisks2ClientProducerYaml = `name: producer
+version: 0
+apps:
+ app:
+  slots: [udisks2-client]
+`
+
+func (s *UDisks2ClientInterfaceSuite) SetUpTest(c *C) {
+	s.plug, s.plugInfo = MockConnectedPlug(c, udisks2ClientConsumerYaml, nil, "udisks2-client")
+	s.coreSlot, s.coreSlotInfo = MockConnectedSlot(c, udisks2ClientCoreYaml, nil, "udisks2-client")
+	s.appSlot, s.appSlotInfo = MockConnectedSlot(c, udisks2ClientProducerYaml, nil, "udisks2-client")
+}
+
+func (s *UDisks2ClientInterfaceSuite) TestName(c *C) {
+	c.Assert(s.iface.Name(), Equals, "udisks2-client")
+}
+
+func (s *UDisks2ClientInterfaceSuite) TestSanitizeSlot(c *C) {
+	c.Assert(interfaces.BeforePrepareSlot(s.iface, s.coreSlotInfo), IsNil)
+	c.Assert(interfaces.BeforePrepareSlot(s.iface, s.appSlotInfo), IsNil)
+}
+
+func (s *UDisks2ClientInterfaceSuite) TestSanitizePlug(c *C) {
+	c.Assert(interfaces.BeforePreparePlug(s.iface, s.plugInfo), IsNil)
+
+	plug := MockPlug(c, udisks2ClientLoopConsumerYaml, nil, "udisks2-client")
+	c.Assert(interfaces.BeforePreparePlug(s.iface, plug), IsNil)
+
+	const badYaml = `name: consumer
+version: 0
+plugs:
+ udisks2-client:
+  loop-devices: yes-please
+apps:
+ app:
+  plugs: [udisks2-client]
+`
+	plug = MockPlug(c, badYaml, nil, "udisks2-client")
+	c.Assert(interfaces.BeforePreparePlug(s.iface, plug), ErrorMatches,
+		`udisks2-client "loop-devices" attribute must be a boolean`)
+}
+
+func (s *UDisks2ClientInterfaceSuite) TestAppArmorSpecImplicitSlot(c *C) {
+	spec := apparmor.NewSpecification(s.plug.AppSet())
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.coreSlot), IsNil)
+	c.Assert(spec.SecurityTags(), DeepEquals, []string{"snap.consumer.app"})
+	snippet := spec.SnippetForTag("snap.consumer.app")
+	c.Check(snippet, testutil.Contains, "interface=org.freedesktop.UDisks2.Filesystem\n    member=\"{Mount,Unmount}\"\n    peer=(label=unconfined),")
+	c.Check(snippet, testutil.Contains, "interface=org.freedesktop.UDisks2.Drive\n    member=\"{Eject,PowerOff}\"")
+	c.Check(snippet, Not(testutil.Contains), "member=Format")
+	c.Check(snippet, Not(testutil.Contains), "LoopSetup")
+	c.Check(snippet, Not(testutil.Contains), "###SLOT_SECURITY_TAGS###")
+}
+
+func (s *UDisks2ClientInterfaceSuite) TestAppArmorSpecAppSlot(c *C) {
+	spec := apparmor.NewSpecification(s.plug.AppSet())
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.appSlot), IsNil)
+	snippet := spec.SnippetForTag("snap.consumer.app")
+	c.Check(snippet, testutil.Contains, `peer=(label="snap.producer.app"),`)
+	c.Check(snippet, Not(testutil.Contains), "peer=(label=unconfined),")
+}
+
+func (s *UDisks2ClientInterfaceSuite) TestAppArmorSpecLoopDevices(c *C) {
+	plug, _ := MockConnectedPlug(c, udisks2ClientLoopConsumerYaml, nil, "udisks2-client")
+	spec := apparmor.NewSpecification(plug.AppSet())
+	c.Assert(spec.AddConnectedPlug(s.iface, plug, s.coreSlot), IsNil)
+	snippet := spec.SnippetForTag("snap.consumer.app")
+	c.Check(snippet, testutil.Contains, "interface=org.freedesktop.UDisks2.Manager\n    member=LoopSetup\n    peer=(label=unconfined),")
+	c.Check(snippet, testutil.Contains, "interface=org.freedesktop.UDisks2.Loop\n    member=\"{Delete,SetAutoclear}\"")
+}
+
+func (s *UDisks2ClientInterfaceSuite) TestAppArmorSpecConnectedSlot(c *C) {
+	spec := apparmor.NewSpecification(s.appSlot.AppSet())
+	c.Assert(spec.AddConnectedSlot(s.iface, s.plug, s.appSlot), IsNil)
+	c.Assert(spec.SecurityTags(), DeepEquals, []string{"snap.producer.app"})
+	snippet := spec.SnippetForTag("snap.producer.app")
+	c.Check(snippet, testutil.Contains, "interface=org.freedesktop.UDisks2.Filesystem\n    member=\"{Mount,Unmount}\"\n    peer=(label=\"snap.consumer.app\"),")
+	c.Check(snippet, Not(testutil.Contains), "interface=org.freedesktop.UDisks2.*")
+	c.Check(snippet, Not(testutil.Contains), "LoopSetup")
+	c.Check(snippet, Not(testutil.Contains), "###PLUG_SECURITY_TAGS###")
+
+	plug, _ := MockConnectedPlug(c, udisks2ClientLoopConsumerYaml, nil, "udisks2-client")
+	spec = apparmor.NewSpecification(s.appSlot.AppSet())
+	c.Assert(spec.AddConnectedSlot(s.iface, plug, s.appSlot), IsNil)
+	c.Check(spec.SnippetForTag("snap.producer.app"), testutil.Contains, "interface=org.freedesktop.UDisks2.Manager\n    member=LoopSetup\n    peer=(label=\"snap.consumer.app\"),")
+
+	// no policy for the implicit slot
+	spec = apparmor.NewSpecification(s.coreSlot.AppSet())
+	c.Assert(spec.AddConnectedSlot(s.iface, s.plug, s.coreSlot), IsNil)
+	c.Check(spec.SecurityTags(), HasLen, 0)
+}
+
+func (s *UDisks2ClientInterfaceSuite) TestPermanentSlotSpecs(c *C) {
+	apparmorSpec := apparmor.NewSpecification(s.appSlot.AppSet())
+	c.Assert(apparmorSpec.AddPermanentSlot(s.iface, s.appSlotInfo), IsNil)
+	c.Check(apparmorSpec.SnippetForTag("snap.producer.app"), testutil.Contains, "Allow operating as the udisks2")
+
+	dbusSpec := dbus.NewSpecification(s.appSlot.AppSet())
+	c.Assert(dbusSpec.AddPermanentSlot(s.iface, s.appSlotInfo), IsNil)
+	c.Check(dbusSpec.SnippetForTag("snap.producer.app"), testutil.Contains, `<allow own="org.freedesktop.UDisks2"/>`)
+
+	seccompSpec := seccomp.NewSpecification(s.appSlot.AppSet())
+	c.Assert(seccompSpec.AddPermanentSlot(s.iface, s.appSlotInfo), IsNil)
+	c.Check(seccompSpec.SnippetForTag("snap.producer.app"), testutil.Contains, "umount2\n")
+
+	udevSpec := udev.NewSpecification(s.appSlot.AppSet())
+	c.Assert(udevSpec.AddPermanentSlot(s.iface, s.appSlotInfo), IsNil)
+	c.Check(udevSpec.Snippets(), HasLen, 4)
+
+	// none for the implicit slot
+	apparmorSpec = apparmor.NewSpecification(s.coreSlot.AppSet())
+	c.Assert(apparmorSpec.AddPermanentSlot(s.iface, s.coreSlotInfo), IsNil)
+	c.Check(apparmorSpec.SecurityTags(), HasLen, 0)
+	dbusSpec = dbus.NewSpecification(s.coreSlot.AppSet())
+	c.Assert(dbusSpec.AddPermanentSlot(s.iface, s.coreSlotInfo), IsNil)
+	c.Check(dbusSpec.SecurityTags(), HasLen, 0)
+	seccompSpec = seccomp.NewSpecification(s.coreSlot.AppSet())
+	c.Assert(seccompSpec.AddPermanentSlot(s.iface, s.coreSlotInfo), IsNil)
+	c.Check(seccompSpec.SecurityTags(), HasLen, 0)
+	udevSpec = udev.NewSpecification(s.coreSlot.AppSet())
+	c.Assert(udevSpec.AddPermanentSlot(s.iface, s.coreSlotInfo), IsNil)
+	c.Check(udevSpec.Snippets(), HasLen, 0)
+}
+
+func (s *UDisks2ClientInterfaceSuite) TestStaticInfo(c *C) {
+	si := interfaces.StaticInfoOf(s.iface)
+	c.Assert(si.ImplicitOnCore, Equals, false)
+	c.Assert(si.ImplicitOnClassic, Equals, true)
+	c.Assert(si.Summary, Equals, `allows mounting and unmounting removable media via the UDisks2 service`)
+	c.Assert(si.BaseDeclarationSlots, testutil.Contains, "udisks2-client")
+}
+
+func (s *UDisks2ClientInterfaceSuite) TestAutoConnect(c *C) {
+	c.Assert(s.iface.AutoConnect(s.plugInfo, s.coreSlotInfo), Equals, true)
+}
+
+func (s *UDisks2ClientInterfaceSuite) TestInterfaces(c *C) {
+	c.Assert(builtin.Interfaces(), testutil.DeepContains, s.iface)
+}
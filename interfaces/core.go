@@ -33,6 +33,9 @@ func BeforePreparePlug(iface Interface, plugInfo *snap.PlugInfo) error {
 		return fmt.Errorf("cannot sanitize plug %q (interface %q) using interface %q",
 			PlugRef{Snap: plugInfo.Snap.InstanceName(), Name: plugInfo.Name}, plugInfo.Interface, iface.Name())
 	}
+	if err := validatePlugAttrs(iface, plugInfo); err != nil {
+		return err
+	}
 	var err error
 	if iface, ok := iface.(PlugSanitizer); ok {
 		err = iface.BeforePreparePlug(plugInfo)
@@ -84,6 +87,9 @@ func BeforePrepareSlot(iface Interface, slotInfo *snap.SlotInfo) error {
 		return fmt.Errorf("cannot sanitize slot %q (interface %q) using interface %q",
 			SlotRef{Snap: slotInfo.Snap.InstanceName(), Name: slotInfo.Name}, slotInfo.Interface, iface.Name())
 	}
+	if err := validateSlotAttrs(iface, slotInfo); err != nil {
+		return err
+	}
 	var err error
 	if iface, ok := iface.(SlotSanitizer); ok {
 		err = iface.BeforePrepareSlot(slotInfo)
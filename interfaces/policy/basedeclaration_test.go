@@ -860,6 +860,7 @@ var (
 		"thumbnailer-service":       {"app"},
 		"ubuntu-download-manager":   {"app"},
 		"udisks2":                   {"app", "core"},
+		"udisks2-client":            {"app", "core"},
 		"uhid":                      {"core"},
 		"uio":                       {"core", "gadget"},
 		"unity8":                    {"app"},
@@ -1237,6 +1238,7 @@ func (s *baseDeclSuite) TestConnectionImplicitOnClassicOrAppSnap(c *C) {
 		"network-manager": true,
 		"ofono":           true,
 		"pulseaudio":      true,
+		"udisks2-client":  true,
 	}
 
 	for _, iface := range all {
@@ -564,6 +564,18 @@ func (r *Repository) Connect(ref *ConnRef, plugStaticAttrs, plugDynamicAttrs, sl
 
 	// policyCheck is null when reloading connections
 	if policyCheck != nil {
+		// dynamic attributes complement the static ones, which were
+		// already validated when the plug and slot were prepared
+		if i, ok := iface.(PlugAttrsDeclarer); ok {
+			if err := i.PlugAttrSchema().Validate(plug.Interface, "plug", plugDynamicAttrs, true); err != nil {
+				return nil, fmt.Errorf("cannot connect plug %q of snap %q: %s", plug.Name, plug.Snap.InstanceName(), err)
+			}
+		}
+		if i, ok := iface.(SlotAttrsDeclarer); ok {
+			if err := i.SlotAttrSchema().Validate(slot.Interface, "slot", slotDynamicAttrs, true); err != nil {
+				return nil, fmt.Errorf("cannot connect slot %q of snap %q: %s", slot.Name, slot.Snap.InstanceName(), err)
+			}
+		}
 		if i, ok := iface.(plugValidator); ok {
 			if err := i.BeforeConnectPlug(cplug); err != nil {
 				return nil, fmt.Errorf("cannot connect plug %q of snap %q: %s", plug.Name, plug.Snap.InstanceName(), err)
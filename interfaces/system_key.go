@@ -81,10 +81,20 @@ type systemKey struct {
 	SecCompActions         []string `json:"seccomp-features"`
 	SeccompCompilerVersion string   `json:"seccomp-compiler-version"`
 	CgroupVersion          string   `json:"cgroup-version"`
+
+	// SnapConfine records the version and the features of the
+	// snap-confine that is used to run snaps, if known. It is compared
+	// against the features snapd relies on when snapd starts.
+	SnapConfine *snapConfineKey `json:"snap-confine,omitempty"`
+}
+
+type snapConfineKey struct {
+	Version  string   `json:"version"`
+	Features []string `json:"features"`
 }
 
 // IMPORTANT: when adding/removing/changing inputs bump this
-const systemKeyVersion = 11
+const systemKeyVersion = 12
 
 var (
 	isHomeUsingRemoteFS   = osutil.IsHomeUsingRemoteFS
@@ -94,6 +104,16 @@ var (
 	readBuildID = osutil.ReadBuildID
 )
 
+// snapConfineInfo returns the information recorded by the info file
+// installed along the snap-confine that is used to run snaps.
+var snapConfineInfo = func() (*snapdtool.SnapConfineInfo, error) {
+	snapConfine, err := snapdtool.InternalToolPath("snap-confine")
+	if err != nil {
+		return nil, err
+	}
+	return snapdtool.SnapConfineInfoFromInfoFile(filepath.Dir(snapConfine))
+}
+
 func seccompCompilerVersionInfo(path string) (seccomp.VersionInfo, error) {
 	return seccomp.CompilerVersionInfo(func(name string) (string, error) { return filepath.Join(path, name), nil })
 }
@@ -159,9 +179,31 @@ func generateSystemKey() (*systemKey, error) {
 	}
 	sk.CgroupVersion = strconv.FormatInt(int64(cgv), 10)
 
+	// Add the snap-confine version and features, the info file is
+	// missing when running from a development tree
+	if sci, err := snapConfineInfo(); err == nil {
+		sk.SnapConfine = &snapConfineKey{
+			Version:  sci.Version,
+			Features: sci.Features,
+		}
+	} else {
+		logger.Debugf("cannot determine snap-confine features: %v", err)
+	}
+
 	return sk, nil
 }
 
+// SnapConfineMissingFeatures returns the version of the snap-confine that is
+// used to run snaps, and the features snapd relies on which it lacks. Snaps
+// can still be run but the listed features are degraded.
+func SnapConfineMissingFeatures() (version string, missing []string, err error) {
+	sci, err := snapConfineInfo()
+	if err != nil {
+		return "", nil, err
+	}
+	return sci.Version, sci.MissingFeatures(), nil
+}
+
 // UnmarshalJSONSystemKey unmarshalls the data from the reader as JSON into a
 // system key usable with SystemKeysMatch.
 func UnmarshalJSONSystemKey(r io.Reader) (interface{}, error) {
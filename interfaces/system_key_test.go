@@ -399,9 +399,62 @@ func (s *systemKeySuite) TestStaticVersion(c *C) {
 		"SecCompActions:[]",
 		"SeccompCompilerVersion:",
 		"CgroupVersion:",
+		"SnapConfine:<nil>",
 	}, " ")+"}")
 }
 
+func (s *systemKeySuite) TestInterfaceWriteSystemKeySnapConfine(c *C) {
+	restore := interfaces.MockIsHomeUsingRemoteFS(func() (bool, error) { return false, nil })
+	defer restore()
+	restore = interfaces.MockReadBuildID(func(p string) (string, error) { return s.buildID, nil })
+	defer restore()
+	restore = interfaces.MockIsRootWritableOverlay(func() (string, error) { return "", nil })
+	defer restore()
+	restore = cgroup.MockVersion(1, nil)
+	defer restore()
+
+	err := os.WriteFile(filepath.Join(dirs.DistroLibExecDir, "info"), []byte("VERSION=2.48\n"), 0644)
+	c.Assert(err, IsNil)
+
+	c.Assert(interfaces.WriteSystemKey(interfaces.SystemKeyExtraData{}), IsNil)
+
+	systemKey, err := os.ReadFile(dirs.SnapSystemKeyFile)
+	c.Assert(err, IsNil)
+	c.Check(string(systemKey), Matches, `.*,"snap-confine":\{"version":"2.48","features":\["parallel-instances","per-user-mount-ns"\]\}\}`)
+
+	key1, err := interfaces.RecordedSystemKey()
+	c.Assert(err, IsNil)
+
+	// a snap-confine update changes the system key
+	err = os.WriteFile(filepath.Join(dirs.DistroLibExecDir, "info"), []byte("VERSION=2.49\n"), 0644)
+	c.Assert(err, IsNil)
+	c.Assert(interfaces.WriteSystemKey(interfaces.SystemKeyExtraData{}), IsNil)
+	key2, err := interfaces.RecordedSystemKey()
+	c.Assert(err, IsNil)
+	match, err := interfaces.SystemKeysMatch(key1, key2)
+	c.Assert(err, IsNil)
+	c.Check(match, Equals, false)
+}
+
+func (s *systemKeySuite) TestSnapConfineMissingFeatures(c *C) {
+	_, _, err := interfaces.SnapConfineMissingFeatures()
+	c.Check(err, ErrorMatches, `cannot open snapd info file .*`)
+
+	err = os.WriteFile(filepath.Join(dirs.DistroLibExecDir, "info"), []byte("VERSION=2.48\n"), 0644)
+	c.Assert(err, IsNil)
+	version, missing, err := interfaces.SnapConfineMissingFeatures()
+	c.Assert(err, IsNil)
+	c.Check(version, Equals, "2.48")
+	c.Check(missing, DeepEquals, []string{"cgroup-v2-device-filter"})
+
+	err = os.WriteFile(filepath.Join(dirs.DistroLibExecDir, "info"), []byte("VERSION=2.63\nSNAPD_SNAP_CONFINE_FEATURES='cgroup-v2-device-filter parallel-instances per-user-mount-ns'\n"), 0644)
+	c.Assert(err, IsNil)
+	version, missing, err = interfaces.SnapConfineMissingFeatures()
+	c.Assert(err, IsNil)
+	c.Check(version, Equals, "2.63")
+	c.Check(missing, HasLen, 0)
+}
+
 func (s *systemKeySuite) TestRecordedSystemKey(c *C) {
 	_, err := interfaces.RecordedSystemKey()
 	c.Check(err, Equals, interfaces.ErrSystemKeyMissing)
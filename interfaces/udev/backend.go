@@ -224,6 +224,11 @@ func (b *Backend) SandboxFeatures() []string {
 		"tagging",          /* Tagging dynamically associates new devices with specific snaps */
 		"device-filtering", /* Snapd can limit device access for each snap */
 	}
+	if devicesCanBeTriggered() {
+		commonFeatures = append(commonFeatures,
+			"device-triggers", /* Snapd re-tags existing devices when profiles change */
+		)
+	}
 
 	if cgroup.IsUnified() {
 		return append(commonFeatures,
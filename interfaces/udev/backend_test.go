@@ -30,6 +30,7 @@ import (
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/ifacetest"
 	"github.com/snapcore/snapd/interfaces/udev"
+	"github.com/snapcore/snapd/release"
 	"github.com/snapcore/snapd/sandbox/cgroup"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/testutil"
@@ -65,6 +66,7 @@ func (s *backendSuite) SetUpTest(c *C) {
 
 	s.BackendSuite.SetUpTest(c)
 	c.Assert(s.Repo.AddBackend(s.Backend), IsNil)
+	s.AddCleanup(release.MockOnContainer("", false))
 
 	// Mock away any real udev interaction
 	s.udevadmCmd = testutil.MockCommand(c, "udevadm", "")
@@ -623,6 +625,7 @@ func (s *backendSuite) TestSandboxFeatures(c *C) {
 	c.Assert(s.Backend.SandboxFeatures(), DeepEquals, []string{
 		"tagging",
 		"device-filtering",
+		"device-triggers",
 		"device-cgroup-v1",
 	})
 
@@ -631,6 +634,29 @@ func (s *backendSuite) TestSandboxFeatures(c *C) {
 	c.Assert(s.Backend.SandboxFeatures(), DeepEquals, []string{
 		"tagging",
 		"device-filtering",
+		"device-triggers",
+		"device-cgroup-v2",
+	})
+}
+
+func (s *backendSuite) TestSandboxFeaturesInContainer(c *C) {
+	defer cgroup.MockVersion(cgroup.V2, nil)()
+
+	restore := release.MockOnContainer("lxc", true)
+	defer restore()
+	c.Assert(s.Backend.SandboxFeatures(), DeepEquals, []string{
+		"tagging",
+		"device-filtering",
+		"device-cgroup-v2",
+	})
+
+	// privileged containers can trigger events
+	restore = release.MockOnContainer("docker", false)
+	defer restore()
+	c.Assert(s.Backend.SandboxFeatures(), DeepEquals, []string{
+		"tagging",
+		"device-filtering",
+		"device-triggers",
 		"device-cgroup-v2",
 	})
 }
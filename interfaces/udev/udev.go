@@ -22,8 +22,20 @@ package udev
 import (
 	"fmt"
 	"os/exec"
+
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/release"
 )
 
+// devicesCanBeTriggered returns whether replaying udev events is possible and
+// useful. WSL does not expose real hardware devices to the distribution and
+// unprivileged containers are not allowed to trigger events, so doing so
+// there only blocks for a long time or fails. Privileged containers can
+// trigger events for the devices passed to them.
+func devicesCanBeTriggered() bool {
+	return !release.OnWSL && !release.OnUnprivilegedContainer
+}
+
 // udevadmTrigger runs "udevadm trigger" but ignores an non-zero exit codes.
 // udevadm only started reporting errors in systemd 248 and in order to
 // work correctly in LXD these errors need to be ignored. See
@@ -70,6 +82,11 @@ func (b *Backend) reloadRules(subsystemTriggers []string) error {
 		return fmt.Errorf("cannot reload udev rules: %s\nudev output:\n%s", err, string(output))
 	}
 
+	if !devicesCanBeTriggered() {
+		logger.Debugf("not triggering udev events inside WSL or an unprivileged container")
+		return nil
+	}
+
 	// By default, trigger for all events except the input subsystem since
 	// it can cause noticeable blocked input on, for example, classic
 	// desktop.
@@ -26,6 +26,7 @@ import (
 
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/udev"
+	"github.com/snapcore/snapd/release"
 	"github.com/snapcore/snapd/testutil"
 )
 
@@ -34,6 +35,7 @@ func Test(t *testing.T) {
 }
 
 type uDevSuite struct {
+	testutil.BaseTest
 	backend *udev.Backend
 }
 
@@ -42,10 +44,17 @@ var _ = Suite(&uDevSuite{})
 // Tests for ReloadRules()
 
 func (s *uDevSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+	s.AddCleanup(release.MockOnContainer("", false))
+
 	s.backend = &udev.Backend{}
 	c.Assert(s.backend.Initialize(nil), IsNil)
 }
 
+func (s *uDevSuite) TearDownTest(c *C) {
+	s.BaseTest.TearDownTest(c)
+}
+
 func (s *uDevSuite) TestReloadUDevRulesRunsUDevAdm(c *C) {
 	cmd := testutil.MockCommand(c, "udevadm", "")
 	defer cmd.Restore()
@@ -205,3 +214,43 @@ func (s *uDevSuite) TestNoReloadWhenPreseeding(c *C) {
 	c.Assert(b.ReloadRules(nil), IsNil)
 	c.Assert(cmd.Calls(), HasLen, 0)
 }
+
+func (s *uDevSuite) TestReloadUDevRulesNoTriggersOnWSL(c *C) {
+	defer testutil.Backup(&release.OnWSL)()
+	release.OnWSL = true
+
+	cmd := testutil.MockCommand(c, "udevadm", "")
+	defer cmd.Restore()
+	err := s.backend.ReloadRules([]string{"input"})
+	c.Assert(err, IsNil)
+	c.Assert(cmd.Calls(), DeepEquals, [][]string{
+		{"udevadm", "control", "--reload-rules"},
+	})
+}
+
+func (s *uDevSuite) TestReloadUDevRulesNoTriggersInUnprivilegedContainer(c *C) {
+	defer release.MockOnContainer("lxc", true)()
+
+	cmd := testutil.MockCommand(c, "udevadm", "")
+	defer cmd.Restore()
+	err := s.backend.ReloadRules(nil)
+	c.Assert(err, IsNil)
+	c.Assert(cmd.Calls(), DeepEquals, [][]string{
+		{"udevadm", "control", "--reload-rules"},
+	})
+}
+
+func (s *uDevSuite) TestReloadUDevRulesTriggersInPrivilegedContainer(c *C) {
+	defer release.MockOnContainer("docker", false)()
+
+	cmd := testutil.MockCommand(c, "udevadm", "")
+	defer cmd.Restore()
+	err := s.backend.ReloadRules(nil)
+	c.Assert(err, IsNil)
+	c.Assert(cmd.Calls(), DeepEquals, [][]string{
+		{"udevadm", "control", "--reload-rules"},
+		{"udevadm", "trigger", "--subsystem-nomatch=input"},
+		{"udevadm", "trigger", "--property-match=ID_INPUT_JOYSTICK=1"},
+		{"udevadm", "settle", "--timeout=10"},
+	})
+}
@@ -36,8 +36,11 @@ const (
 	NetworkManagerMeteredGuessNo  = 4
 )
 
+const dbusServiceUnknown = "org.freedesktop.DBus.Error.ServiceUnknown"
+
 // IsOnMeteredConnection checks whether the current default network connection
-// is metered. If the state can not be determined, returns false and an error.
+// is metered, as reported by NetworkManager or, when it is not running, by
+// ConnMan. If the state can not be determined, returns false and an error.
 func IsOnMeteredConnection() (bool, error) {
 	// obtain a shared connection to system bus, no need to close it
 	conn, err := dbus.SystemBus()
@@ -45,7 +48,24 @@ func IsOnMeteredConnection() (bool, error) {
 		return false, fmt.Errorf("cannot connect to system bus: %v", err)
 	}
 
-	return isNMOnMetered(conn)
+	return isOnMetered(conn)
+}
+
+func isOnMetered(conn *dbus.Conn) (bool, error) {
+	metered, err := isNMOnMetered(conn)
+	if !isServiceUnknown(err) {
+		return metered, err
+	}
+	metered, err = isConnManOnMetered(conn)
+	if isServiceUnknown(err) {
+		return false, fmt.Errorf("cannot determine metered state: neither NetworkManager nor ConnMan are running")
+	}
+	return metered, err
+}
+
+func isServiceUnknown(err error) bool {
+	dbusErr, ok := err.(dbus.Error)
+	return ok && dbusErr.Name == dbusServiceUnknown
 }
 
 func isNMOnMetered(conn *dbus.Conn) (bool, error) {
@@ -63,3 +83,30 @@ func isNMOnMetered(conn *dbus.Conn) (bool, error) {
 
 	return v == NetworkManagerMeteredGuessYes || v == NetworkManagerMeteredYes, nil
 }
+
+type connManService struct {
+	Path       dbus.ObjectPath
+	Properties map[string]dbus.Variant
+}
+
+// isConnManOnMetered checks the default service of ConnMan, which does not
+// track metering explicitly, cellular services are considered metered.
+func isConnManOnMetered(conn *dbus.Conn) (bool, error) {
+	managerObj := conn.Object("net.connman", "/")
+	// https://git.kernel.org/pub/scm/network/connman/connman.git/tree/doc/manager-api.txt
+	var services []connManService
+	if err := managerObj.Call("net.connman.Manager.GetServices", 0).Store(&services); err != nil {
+		return false, err
+	}
+	// services are sorted by ConnMan, the connected ones coming first
+	for _, service := range services {
+		state, _ := service.Properties["State"].Value().(string)
+		if state != "ready" && state != "online" {
+			continue
+		}
+		typ, _ := service.Properties["Type"].Value().(string)
+		logger.Debugf("default service type reported by ConnMan: %s", typ)
+		return typ == "cellular", nil
+	}
+	return false, nil
+}
@@ -0,0 +1,196 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package netutil_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/godbus/dbus"
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dbusutil/dbustest"
+	"github.com/snapcore/snapd/netutil"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type meteredSuite struct{}
+
+var _ = Suite(&meteredSuite{})
+
+func replyTo(msg *dbus.Message, body ...interface{}) *dbus.Message {
+	return &dbus.Message{
+		Type: dbus.TypeMethodReply,
+		Headers: map[dbus.HeaderField]dbus.Variant{
+			dbus.FieldReplySerial: dbus.MakeVariant(msg.Serial()),
+			dbus.FieldSender:      dbus.MakeVariant(":1"),
+			dbus.FieldSignature:   dbus.MakeVariant(dbus.SignatureOf(body...)),
+		},
+		Body: body,
+	}
+}
+
+func serviceUnknown(msg *dbus.Message) *dbus.Message {
+	return &dbus.Message{
+		Type: dbus.TypeError,
+		Headers: map[dbus.HeaderField]dbus.Variant{
+			dbus.FieldReplySerial: dbus.MakeVariant(msg.Serial()),
+			dbus.FieldSender:      dbus.MakeVariant(":1"),
+			dbus.FieldErrorName:   dbus.MakeVariant("org.freedesktop.DBus.Error.ServiceUnknown"),
+		},
+	}
+}
+
+func checkNMMeteredCall(c *C, msg *dbus.Message) {
+	c.Assert(msg.Type, Equals, dbus.TypeMethodCall)
+	c.Check(msg.Headers[dbus.FieldDestination], DeepEquals, dbus.MakeVariant("org.freedesktop.NetworkManager"))
+	c.Check(msg.Headers[dbus.FieldMember], DeepEquals, dbus.MakeVariant("Get"))
+	c.Check(msg.Body, DeepEquals, []interface{}{"org.freedesktop.NetworkManager", "Metered"})
+}
+
+func checkConnManServicesCall(c *C, msg *dbus.Message) {
+	c.Assert(msg.Type, Equals, dbus.TypeMethodCall)
+	c.Check(msg.Headers[dbus.FieldDestination], DeepEquals, dbus.MakeVariant("net.connman"))
+	c.Check(msg.Headers[dbus.FieldPath], DeepEquals, dbus.MakeVariant(dbus.ObjectPath("/")))
+	c.Check(msg.Headers[dbus.FieldMember], DeepEquals, dbus.MakeVariant("GetServices"))
+}
+
+func (s *meteredSuite) TestNetworkManager(c *C) {
+	for _, t := range []struct {
+		value   uint32
+		metered bool
+	}{
+		{netutil.NetworkManagerMeteredUnknown, false},
+		{netutil.NetworkManagerMeteredYes, true},
+		{netutil.NetworkManagerMeteredNo, false},
+		{netutil.NetworkManagerMeteredGuessYes, true},
+		{netutil.NetworkManagerMeteredGuessNo, false},
+	} {
+		conn, err := dbustest.Connection(func(msg *dbus.Message, n int) ([]*dbus.Message, error) {
+			switch n {
+			case 0:
+				checkNMMeteredCall(c, msg)
+				return []*dbus.Message{replyTo(msg, dbus.MakeVariant(t.value))}, nil
+			}
+			return nil, fmt.Errorf("unexpected message #%d: %s", n, msg)
+		})
+		c.Assert(err, IsNil)
+
+		metered, err := netutil.IsOnMetered(conn)
+		c.Assert(err, IsNil)
+		c.Check(metered, Equals, t.metered, Commentf("%v", t.value))
+		conn.Close()
+	}
+}
+
+func (s *meteredSuite) TestNetworkManagerInvalidValue(c *C) {
+	conn, err := dbustest.Connection(func(msg *dbus.Message, n int) ([]*dbus.Message, error) {
+		switch n {
+		case 0:
+			checkNMMeteredCall(c, msg)
+			return []*dbus.Message{replyTo(msg, dbus.MakeVariant("yes"))}, nil
+		}
+		return nil, fmt.Errorf("unexpected message #%d: %s", n, msg)
+	})
+	c.Assert(err, IsNil)
+	defer conn.Close()
+
+	_, err = netutil.IsOnMetered(conn)
+	c.Assert(err, ErrorMatches, `network manager returned invalid value for metering verification: "yes"`)
+}
+
+type service struct {
+	Path       dbus.ObjectPath
+	Properties map[string]dbus.Variant
+}
+
+func connManService(path, typ, state string) service {
+	return service{
+		Path: dbus.ObjectPath(path),
+		Properties: map[string]dbus.Variant{
+			"Type":  dbus.MakeVariant(typ),
+			"State": dbus.MakeVariant(state),
+		},
+	}
+}
+
+func (s *meteredSuite) TestConnMan(c *C) {
+	for _, t := range []struct {
+		services []service
+		metered  bool
+	}{
+		{nil, false},
+		{[]service{
+			connManService("/net/connman/service/cellular_1", "cellular", "online"),
+			connManService("/net/connman/service/wifi_1", "wifi", "idle"),
+		}, true},
+		{[]service{
+			connManService("/net/connman/service/wifi_1", "wifi", "ready"),
+			connManService("/net/connman/service/cellular_1", "cellular", "ready"),
+		}, false},
+		{[]service{
+			connManService("/net/connman/service/cellular_1", "cellular", "idle"),
+			connManService("/net/connman/service/ethernet_1", "ethernet", "online"),
+		}, false},
+	} {
+		conn, err := dbustest.Connection(func(msg *dbus.Message, n int) ([]*dbus.Message, error) {
+			switch n {
+			case 0:
+				checkNMMeteredCall(c, msg)
+				return []*dbus.Message{serviceUnknown(msg)}, nil
+			case 1:
+				checkConnManServicesCall(c, msg)
+				services := t.services
+				if services == nil {
+					services = []service{}
+				}
+				return []*dbus.Message{replyTo(msg, services)}, nil
+			}
+			return nil, fmt.Errorf("unexpected message #%d: %s", n, msg)
+		})
+		c.Assert(err, IsNil)
+
+		metered, err := netutil.IsOnMetered(conn)
+		c.Assert(err, IsNil)
+		c.Check(metered, Equals, t.metered, Commentf("%v", t.services))
+		conn.Close()
+	}
+}
+
+func (s *meteredSuite) TestNoNetworkDaemon(c *C) {
+	conn, err := dbustest.Connection(func(msg *dbus.Message, n int) ([]*dbus.Message, error) {
+		switch n {
+		case 0:
+			checkNMMeteredCall(c, msg)
+			return []*dbus.Message{serviceUnknown(msg)}, nil
+		case 1:
+			checkConnManServicesCall(c, msg)
+			return []*dbus.Message{serviceUnknown(msg)}, nil
+		}
+		return nil, fmt.Errorf("unexpected message #%d: %s", n, msg)
+	})
+	c.Assert(err, IsNil)
+	defer conn.Close()
+
+	metered, err := netutil.IsOnMetered(conn)
+	c.Assert(err, ErrorMatches, "cannot determine metered state: neither NetworkManager nor ConnMan are running")
+	c.Check(metered, Equals, false)
+}
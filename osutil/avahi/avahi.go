@@ -0,0 +1,191 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package avahi advertises services on the local network through the
+// Avahi mDNS/DNS-SD daemon.
+package avahi
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/godbus/dbus"
+
+	"github.com/snapcore/snapd/dbusutil"
+)
+
+const (
+	busName             = "org.freedesktop.Avahi"
+	serverInterface     = "org.freedesktop.Avahi.Server"
+	entryGroupInterface = "org.freedesktop.Avahi.EntryGroup"
+
+	// AVAHI_IF_UNSPEC and AVAHI_PROTO_UNSPEC, advertise on all network
+	// interfaces over both IPv4 and IPv6
+	ifaceUnspec = int32(-1)
+	protoUnspec = int32(-1)
+)
+
+// Service is a service advertised on the local network.
+type Service struct {
+	// Name is the name of the service instance.
+	Name string
+	// Type is the DNS-SD service type, e.g. _http._tcp.
+	Type string
+	// Port is the port the service listens on.
+	Port uint16
+	// TXT holds the key=value entries of the TXT record of the service.
+	TXT []string
+}
+
+var (
+	mu sync.Mutex
+	// groups maps the tags of the registered services to the
+	// corresponding Avahi entry groups
+	groups = make(map[string]dbus.ObjectPath)
+)
+
+// Register advertises the given service under the given tag, replacing any
+// service previously registered with the same tag. Avahi keeps the service
+// registered for as long as the connection to the system bus stays open.
+func Register(tag string, svc Service) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	conn, err := dbusutil.SystemBus()
+	if err != nil {
+		return fmt.Errorf("cannot connect to system bus: %v", err)
+	}
+	if err := unregisterLocked(conn, tag); err != nil {
+		return err
+	}
+
+	var path dbus.ObjectPath
+	server := conn.Object(busName, "/")
+	if err := server.Call(serverInterface+".EntryGroupNew", 0).Store(&path); err != nil {
+		return fmt.Errorf("cannot create avahi entry group: %v", err)
+	}
+	group := conn.Object(busName, path)
+
+	txt := make([][]byte, 0, len(svc.TXT))
+	for _, entry := range svc.TXT {
+		txt = append(txt, []byte(entry))
+	}
+	err = group.Call(entryGroupInterface+".AddService", 0, ifaceUnspec, protoUnspec, uint32(0),
+		svc.Name, svc.Type, "", "", svc.Port, txt).Err
+	if err == nil {
+		err = group.Call(entryGroupInterface+".Commit", 0).Err
+	}
+	if err != nil {
+		group.Call(entryGroupInterface+".Free", 0)
+		return fmt.Errorf("cannot advertise service %q of type %s: %v", svc.Name, svc.Type, err)
+	}
+
+	groups[tag] = path
+	return nil
+}
+
+// Unregister withdraws the service registered under the given tag, if any.
+func Unregister(tag string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, ok := groups[tag]; !ok {
+		return nil
+	}
+	conn, err := dbusutil.SystemBus()
+	if err != nil {
+		return fmt.Errorf("cannot connect to system bus: %v", err)
+	}
+	return unregisterLocked(conn, tag)
+}
+
+// WatchStarted calls the given function each time the Avahi daemon appears
+// on the system bus, e.g. when it starts after snapd or is restarted. The
+// services registered with a previous instance of the daemon are gone by
+// then and need to be registered again. The returned function stops the
+// watch.
+func WatchStarted(started func()) (stop func(), err error) {
+	conn, err := dbusutil.SystemBus()
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to system bus: %v", err)
+	}
+
+	matchRules := []dbus.MatchOption{
+		dbus.WithMatchSender("org.freedesktop.DBus"),
+		dbus.WithMatchInterface("org.freedesktop.DBus"),
+		dbus.WithMatchMember("NameOwnerChanged"),
+		dbus.WithMatchOption("arg0", busName),
+	}
+	// XXX: do not close as this may lead to panic on already closed channel
+	// due to https://github.com/godbus/dbus/issues/271
+	signals := make(chan *dbus.Signal, 10)
+	conn.Signal(signals)
+	if err := conn.AddMatchSignal(matchRules...); err != nil {
+		conn.RemoveSignal(signals)
+		return nil, fmt.Errorf("cannot watch avahi on system bus: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-signals:
+				if sig.Name != "org.freedesktop.DBus.NameOwnerChanged" || len(sig.Body) != 3 {
+					continue
+				}
+				name, _ := sig.Body[0].(string)
+				newOwner, _ := sig.Body[2].(string)
+				if name != busName {
+					continue
+				}
+				// the entry groups went away with the previous owner
+				mu.Lock()
+				groups = make(map[string]dbus.ObjectPath)
+				mu.Unlock()
+				if newOwner != "" {
+					started()
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			conn.RemoveSignal(signals)
+			conn.RemoveMatchSignal(matchRules...)
+			close(done)
+		})
+	}, nil
+}
+
+func unregisterLocked(conn *dbus.Conn, tag string) error {
+	path, ok := groups[tag]
+	if !ok {
+		return nil
+	}
+	group := conn.Object(busName, path)
+	if err := group.Call(entryGroupInterface+".Free", 0).Err; err != nil {
+		return fmt.Errorf("cannot withdraw advertised service: %v", err)
+	}
+	delete(groups, tag)
+	return nil
+}
@@ -0,0 +1,284 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package avahi_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus"
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dbusutil"
+	"github.com/snapcore/snapd/dbusutil/dbustest"
+	"github.com/snapcore/snapd/osutil/avahi"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type avahiSuite struct {
+	conn *dbus.Conn
+}
+
+var _ = Suite(&avahiSuite{})
+
+func (s *avahiSuite) SetUpTest(c *C) {
+	avahi.Reset()
+}
+
+func (s *avahiSuite) TearDownTest(c *C) {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+}
+
+func (s *avahiSuite) mockBus(c *C, handler dbustest.DBusHandlerFunc) (restore func()) {
+	conn, err := dbustest.Connection(handler)
+	c.Assert(err, IsNil)
+	s.conn = conn
+	return dbusutil.MockOnlySystemBusAvailable(conn)
+}
+
+func replyTo(msg *dbus.Message, body ...interface{}) *dbus.Message {
+	return &dbus.Message{
+		Type: dbus.TypeMethodReply,
+		Headers: map[dbus.HeaderField]dbus.Variant{
+			dbus.FieldReplySerial: dbus.MakeVariant(msg.Serial()),
+			dbus.FieldSender:      dbus.MakeVariant(":1"),
+			dbus.FieldSignature:   dbus.MakeVariant(dbus.SignatureOf(body...)),
+		},
+		Body: body,
+	}
+}
+
+func replyError(msg *dbus.Message, name string) *dbus.Message {
+	return &dbus.Message{
+		Type: dbus.TypeError,
+		Headers: map[dbus.HeaderField]dbus.Variant{
+			dbus.FieldReplySerial: dbus.MakeVariant(msg.Serial()),
+			dbus.FieldSender:      dbus.MakeVariant(":1"),
+			dbus.FieldErrorName:   dbus.MakeVariant(name),
+		},
+	}
+}
+
+func checkCall(c *C, msg *dbus.Message, path dbus.ObjectPath, member string) {
+	c.Assert(msg.Type, Equals, dbus.TypeMethodCall)
+	c.Check(msg.Headers[dbus.FieldDestination], DeepEquals, dbus.MakeVariant("org.freedesktop.Avahi"))
+	c.Check(msg.Headers[dbus.FieldPath], DeepEquals, dbus.MakeVariant(path))
+	c.Check(msg.Headers[dbus.FieldMember], DeepEquals, dbus.MakeVariant(member))
+}
+
+func (s *avahiSuite) TestRegisterUnregister(c *C) {
+	restore := s.mockBus(c, func(msg *dbus.Message, n int) ([]*dbus.Message, error) {
+		switch n {
+		case 0:
+			checkCall(c, msg, "/", "EntryGroupNew")
+			return []*dbus.Message{replyTo(msg, dbus.ObjectPath("/Client1/EntryGroup1"))}, nil
+		case 1:
+			checkCall(c, msg, "/Client1/EntryGroup1", "AddService")
+			c.Check(msg.Body, DeepEquals, []interface{}{
+				int32(-1), int32(-1), uint32(0), "foo", "_http._tcp", "", "", uint16(8080),
+				[][]byte{[]byte("path=/"), []byte("version=1")},
+			})
+			return []*dbus.Message{replyTo(msg)}, nil
+		case 2:
+			checkCall(c, msg, "/Client1/EntryGroup1", "Commit")
+			return []*dbus.Message{replyTo(msg)}, nil
+		case 3:
+			checkCall(c, msg, "/Client1/EntryGroup1", "Free")
+			return []*dbus.Message{replyTo(msg)}, nil
+		}
+		return nil, fmt.Errorf("unexpected message #%d: %s", n, msg)
+	})
+	defer restore()
+
+	err := avahi.Register("snap.foo.svc", avahi.Service{
+		Name: "foo",
+		Type: "_http._tcp",
+		Port: 8080,
+		TXT:  []string{"path=/", "version=1"},
+	})
+	c.Assert(err, IsNil)
+	c.Check(avahi.Registered(), DeepEquals, map[string]string{
+		"snap.foo.svc": "/Client1/EntryGroup1",
+	})
+
+	err = avahi.Unregister("snap.foo.svc")
+	c.Assert(err, IsNil)
+	c.Check(avahi.Registered(), HasLen, 0)
+
+	// unregistering again is a no-op
+	err = avahi.Unregister("snap.foo.svc")
+	c.Assert(err, IsNil)
+}
+
+func (s *avahiSuite) TestRegisterReplaces(c *C) {
+	restore := s.mockBus(c, func(msg *dbus.Message, n int) ([]*dbus.Message, error) {
+		switch n {
+		case 0, 4:
+			checkCall(c, msg, "/", "EntryGroupNew")
+			return []*dbus.Message{replyTo(msg, dbus.ObjectPath(fmt.Sprintf("/Client1/EntryGroup%d", n)))}, nil
+		case 1, 5:
+			c.Check(msg.Headers[dbus.FieldMember], DeepEquals, dbus.MakeVariant("AddService"))
+			return []*dbus.Message{replyTo(msg)}, nil
+		case 2, 6:
+			c.Check(msg.Headers[dbus.FieldMember], DeepEquals, dbus.MakeVariant("Commit"))
+			return []*dbus.Message{replyTo(msg)}, nil
+		case 3:
+			// the previous group is freed first
+			checkCall(c, msg, "/Client1/EntryGroup0", "Free")
+			return []*dbus.Message{replyTo(msg)}, nil
+		}
+		return nil, fmt.Errorf("unexpected message #%d: %s", n, msg)
+	})
+	defer restore()
+
+	svc := avahi.Service{Name: "foo", Type: "_http._tcp", Port: 8080}
+	c.Assert(avahi.Register("snap.foo.svc", svc), IsNil)
+	c.Assert(avahi.Register("snap.foo.svc", svc), IsNil)
+	c.Check(avahi.Registered(), DeepEquals, map[string]string{
+		"snap.foo.svc": "/Client1/EntryGroup4",
+	})
+}
+
+func (s *avahiSuite) TestRegisterNoAvahi(c *C) {
+	restore := s.mockBus(c, func(msg *dbus.Message, n int) ([]*dbus.Message, error) {
+		switch n {
+		case 0:
+			return []*dbus.Message{replyError(msg, "org.freedesktop.DBus.Error.ServiceUnknown")}, nil
+		}
+		return nil, fmt.Errorf("unexpected message #%d: %s", n, msg)
+	})
+	defer restore()
+
+	err := avahi.Register("snap.foo.svc", avahi.Service{Name: "foo", Type: "_http._tcp", Port: 8080})
+	c.Assert(err, ErrorMatches, "cannot create avahi entry group: .*")
+	c.Check(avahi.Registered(), HasLen, 0)
+}
+
+func (s *avahiSuite) TestRegisterAddServiceError(c *C) {
+	restore := s.mockBus(c, func(msg *dbus.Message, n int) ([]*dbus.Message, error) {
+		switch n {
+		case 0:
+			return []*dbus.Message{replyTo(msg, dbus.ObjectPath("/Client1/EntryGroup1"))}, nil
+		case 1:
+			return []*dbus.Message{replyError(msg, "org.freedesktop.Avahi.CollisionError")}, nil
+		case 2:
+			checkCall(c, msg, "/Client1/EntryGroup1", "Free")
+			return []*dbus.Message{replyTo(msg)}, nil
+		}
+		return nil, fmt.Errorf("unexpected message #%d: %s", n, msg)
+	})
+	defer restore()
+
+	err := avahi.Register("snap.foo.svc", avahi.Service{Name: "foo", Type: "_http._tcp", Port: 8080})
+	c.Assert(err, ErrorMatches, `cannot advertise service "foo" of type _http._tcp: .*`)
+	c.Check(avahi.Registered(), HasLen, 0)
+}
+
+func nameOwnerChanged(name, oldOwner, newOwner string) *dbus.Message {
+	return &dbus.Message{
+		Type: dbus.TypeSignal,
+		Headers: map[dbus.HeaderField]dbus.Variant{
+			dbus.FieldPath:      dbus.MakeVariant(dbus.ObjectPath("/org/freedesktop/DBus")),
+			dbus.FieldInterface: dbus.MakeVariant("org.freedesktop.DBus"),
+			dbus.FieldMember:    dbus.MakeVariant("NameOwnerChanged"),
+			dbus.FieldSender:    dbus.MakeVariant("org.freedesktop.DBus"),
+			dbus.FieldSignature: dbus.MakeVariant(dbus.SignatureOf(name, oldOwner, newOwner)),
+		},
+		Body: []interface{}{name, oldOwner, newOwner},
+	}
+}
+
+func (s *avahiSuite) TestWatchStarted(c *C) {
+	restore := s.mockBus(c, func(msg *dbus.Message, n int) ([]*dbus.Message, error) {
+		switch n {
+		case 0:
+			c.Check(msg.Headers[dbus.FieldMember], DeepEquals, dbus.MakeVariant("AddMatch"))
+			c.Check(msg.Body, DeepEquals, []interface{}{
+				"type='signal',sender='org.freedesktop.DBus',interface='org.freedesktop.DBus',member='NameOwnerChanged',arg0='org.freedesktop.Avahi'",
+			})
+			return []*dbus.Message{
+				replyTo(msg),
+				// some other name appears
+				nameOwnerChanged("org.example.Other", "", ":1.2"),
+				// avahi went away and is back
+				nameOwnerChanged("org.freedesktop.Avahi", ":1.3", ""),
+				nameOwnerChanged("org.freedesktop.Avahi", "", ":1.4"),
+			}, nil
+		case 1:
+			c.Check(msg.Headers[dbus.FieldMember], DeepEquals, dbus.MakeVariant("RemoveMatch"))
+			return []*dbus.Message{replyTo(msg)}, nil
+		}
+		return nil, fmt.Errorf("unexpected message #%d: %s", n, msg)
+	})
+	defer restore()
+
+	started := make(chan struct{}, 10)
+	stop, err := avahi.WatchStarted(func() { started <- struct{}{} })
+	c.Assert(err, IsNil)
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		c.Fatal("avahi start was not noticed")
+	}
+	stop()
+	c.Check(started, HasLen, 0)
+	// stopping again is a no-op
+	stop()
+}
+
+func (s *avahiSuite) TestWatchStartedForgetsRegistered(c *C) {
+	restore := s.mockBus(c, func(msg *dbus.Message, n int) ([]*dbus.Message, error) {
+		switch n {
+		case 0:
+			checkCall(c, msg, "/", "EntryGroupNew")
+			return []*dbus.Message{replyTo(msg, dbus.ObjectPath("/Client1/EntryGroup1"))}, nil
+		case 1, 2:
+			return []*dbus.Message{replyTo(msg)}, nil
+		case 3:
+			c.Check(msg.Headers[dbus.FieldMember], DeepEquals, dbus.MakeVariant("AddMatch"))
+			return []*dbus.Message{replyTo(msg), nameOwnerChanged("org.freedesktop.Avahi", ":1.3", ":1.4")}, nil
+		case 4:
+			return []*dbus.Message{replyTo(msg)}, nil
+		}
+		return nil, fmt.Errorf("unexpected message #%d: %s", n, msg)
+	})
+	defer restore()
+
+	c.Assert(avahi.Register("snap.foo.svc", avahi.Service{Name: "foo", Type: "_http._tcp", Port: 8080}), IsNil)
+	c.Check(avahi.Registered(), HasLen, 1)
+
+	started := make(chan struct{}, 1)
+	stop, err := avahi.WatchStarted(func() { started <- struct{}{} })
+	c.Assert(err, IsNil)
+	defer stop()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		c.Fatal("avahi start was not noticed")
+	}
+	c.Check(avahi.Registered(), HasLen, 0)
+}
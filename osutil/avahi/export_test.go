@@ -0,0 +1,42 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package avahi
+
+import (
+	"github.com/godbus/dbus"
+)
+
+func Registered() map[string]string {
+	mu.Lock()
+	defer mu.Unlock()
+
+	registered := make(map[string]string, len(groups))
+	for tag, path := range groups {
+		registered[tag] = string(path)
+	}
+	return registered
+}
+
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	groups = make(map[string]dbus.ObjectPath)
+}
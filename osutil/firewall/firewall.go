@@ -0,0 +1,288 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package firewall opens ports in the firewall of the system, currently
+// through firewalld.
+package firewall
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/osutil"
+)
+
+// ErrNoFirewall is returned when no supported firewall is running.
+var ErrNoFirewall = errors.New("no supported firewall is running")
+
+// Port is a port of a transport protocol.
+type Port struct {
+	Number   uint16
+	Protocol string
+}
+
+func (p Port) String() string {
+	return fmt.Sprintf("%d/%s", p.Number, p.Protocol)
+}
+
+var validPort = regexp.MustCompile(`^([0-9]{1,5})/(tcp|udp)$`)
+
+// ParsePort parses a port in the <number>/<protocol> format, where protocol
+// is either tcp or udp.
+func ParsePort(s string) (Port, error) {
+	m := validPort.FindStringSubmatch(s)
+	if m == nil {
+		return Port{}, fmt.Errorf("invalid port %q, must be <number>/tcp or <number>/udp", s)
+	}
+	n, err := strconv.ParseUint(m[1], 10, 16)
+	if err != nil || n == 0 {
+		return Port{}, fmt.Errorf("invalid port number in %q", s)
+	}
+	return Port{Number: uint16(n), Protocol: m[2]}, nil
+}
+
+var firewallCmd = func(args ...string) error {
+	output, err := exec.Command("firewall-cmd", args...).CombinedOutput()
+	if err != nil {
+		return osutil.OutputErr(output, err)
+	}
+	return nil
+}
+
+// Available returns whether a supported firewall is running.
+func Available() bool {
+	return firewallCmd("--state") == nil
+}
+
+// The ports opened for each tag are recorded in a file of the tag, one
+// port per line. Ports which were already open in the firewall when first
+// requested, for instance by the administrator, are marked as external so
+// that they are never closed. A port shared by several tags is only closed
+// when the last of them no longer requests it.
+
+const externalMarker = "external"
+
+// record maps the ports of a tag to whether they were opened outside of
+// snapd.
+type record map[Port]bool
+
+// recordsLock serializes the changes to the records, as the ports of
+// different tags are set concurrently.
+var recordsLock sync.Mutex
+
+func recordFile(tag string) string {
+	return filepath.Join(dirs.SnapFirewallDir, tag+".ports")
+}
+
+func readRecordFile(path string) (record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	rec := make(record)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		port, err := ParsePort(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		rec[port] = len(fields) > 1 && fields[1] == externalMarker
+	}
+	return rec, scanner.Err()
+}
+
+func readRecord(tag string) (record, error) {
+	return readRecordFile(recordFile(tag))
+}
+
+// otherRecords returns the ports recorded for all the tags but the given
+// one, along with whether they were opened outside of snapd.
+func otherRecords(tag string) (record, error) {
+	paths, err := filepath.Glob(filepath.Join(dirs.SnapFirewallDir, "*.ports"))
+	if err != nil {
+		return nil, err
+	}
+	others := make(record)
+	for _, path := range paths {
+		if path == recordFile(tag) {
+			continue
+		}
+		rec, err := readRecordFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for port, external := range rec {
+			others[port] = others[port] || external
+		}
+	}
+	return others, nil
+}
+
+func writeRecord(tag string, rec record) error {
+	if len(rec) == 0 {
+		if err := os.Remove(recordFile(tag)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(dirs.SnapFirewallDir, 0755); err != nil {
+		return err
+	}
+	var b strings.Builder
+	for _, port := range sortedPorts(mapPorts(rec)) {
+		if rec[port] {
+			fmt.Fprintf(&b, "%s %s\n", port, externalMarker)
+		} else {
+			fmt.Fprintln(&b, port)
+		}
+	}
+	return osutil.AtomicWriteFile(recordFile(tag), []byte(b.String()), 0644, 0)
+}
+
+func changePort(action string, port Port) error {
+	// change both the runtime and the permanent configuration, so
+	// that the port stays open across reboots and firewall reloads
+	arg := fmt.Sprintf("--%s-port=%s", action, port)
+	if err := firewallCmd(arg); err != nil {
+		return fmt.Errorf("cannot %s port %s: %v", action, port, err)
+	}
+	if err := firewallCmd("--permanent", arg); err != nil {
+		return fmt.Errorf("cannot %s port %s permanently: %v", action, port, err)
+	}
+	return nil
+}
+
+// portOpen returns whether the port is open in the permanent configuration
+// of the firewall.
+func portOpen(port Port) bool {
+	// firewall-cmd exits with 1 if the port is not open
+	return firewallCmd("--permanent", fmt.Sprintf("--query-port=%s", port)) == nil
+}
+
+// SetPorts makes the given ports the open ports of the given tag, opening
+// those which were not open yet and closing those previously opened for
+// the tag which are no longer listed, unless they are used by other tags or
+// were opened outside of snapd. Ports are remembered across calls so that
+// they can be closed again with RemovePorts.
+func SetPorts(tag string, ports []Port) error {
+	recordsLock.Lock()
+	defer recordsLock.Unlock()
+
+	old, err := readRecord(tag)
+	if err != nil {
+		return fmt.Errorf("cannot read open ports of %q: %v", tag, err)
+	}
+	if len(old) == 0 && len(ports) == 0 {
+		return nil
+	}
+	if !Available() {
+		return ErrNoFirewall
+	}
+	others, err := otherRecords(tag)
+	if err != nil {
+		return fmt.Errorf("cannot read open ports: %v", err)
+	}
+
+	wanted := make(map[Port]bool, len(ports))
+	for _, port := range ports {
+		wanted[port] = true
+	}
+
+	current := make(record, len(old))
+	for port, external := range old {
+		current[port] = external
+	}
+	for _, port := range sortedPorts(mapPorts(old)) {
+		if wanted[port] {
+			continue
+		}
+		_, used := others[port]
+		if !used && !old[port] {
+			if err := changePort("remove", port); err != nil {
+				writeRecord(tag, current)
+				return err
+			}
+		}
+		delete(current, port)
+	}
+	for _, port := range ports {
+		if _, ok := current[port]; ok {
+			continue
+		}
+		if external, used := others[port]; used {
+			// already opened for another tag
+			current[port] = external
+			continue
+		}
+		if portOpen(port) {
+			current[port] = true
+			continue
+		}
+		if err := changePort("add", port); err != nil {
+			// remember what was opened so far, for cleanup
+			writeRecord(tag, current)
+			return err
+		}
+		current[port] = false
+	}
+	return writeRecord(tag, current)
+}
+
+// RemovePorts closes all the ports opened for the given tag, unless they
+// are used by other tags or were opened outside of snapd.
+func RemovePorts(tag string) error {
+	return SetPorts(tag, nil)
+}
+
+func mapPorts(rec record) []Port {
+	ports := make([]Port, 0, len(rec))
+	for port := range rec {
+		ports = append(ports, port)
+	}
+	return ports
+}
+
+func sortedPorts(ports []Port) []Port {
+	sort.Slice(ports, func(i, j int) bool {
+		if ports[i].Number != ports[j].Number {
+			return ports[i].Number < ports[j].Number
+		}
+		return ports[i].Protocol < ports[j].Protocol
+	})
+	return ports
+}
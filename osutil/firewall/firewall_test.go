@@ -0,0 +1,194 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package firewall_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/osutil/firewall"
+	"github.com/snapcore/snapd/testutil"
+)
+
+func TestFirewall(t *testing.T) { TestingT(t) }
+
+type firewallSuite struct {
+	testutil.BaseTest
+
+	calls   []string
+	failArg string
+	state   error
+	// open are the ports opened outside of snapd
+	open map[string]bool
+}
+
+var _ = Suite(&firewallSuite{})
+
+func (s *firewallSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+	dirs.SetRootDir(c.MkDir())
+	s.AddCleanup(func() { dirs.SetRootDir("") })
+
+	s.calls = nil
+	s.failArg = ""
+	s.state = nil
+	s.open = nil
+	s.AddCleanup(firewall.MockFirewallCmd(func(args ...string) error {
+		if args[0] == "--state" {
+			return s.state
+		}
+		if len(args) == 2 && strings.HasPrefix(args[1], "--query-port=") {
+			if s.open[strings.TrimPrefix(args[1], "--query-port=")] {
+				return nil
+			}
+			return errors.New("no")
+		}
+		call := strings.Join(args, " ")
+		s.calls = append(s.calls, call)
+		if s.failArg != "" && strings.HasSuffix(call, s.failArg) {
+			return errors.New("boom")
+		}
+		return nil
+	}))
+}
+
+func (s *firewallSuite) record(c *C, tag string) string {
+	data, err := os.ReadFile(filepath.Join(dirs.SnapFirewallDir, tag+".ports"))
+	if os.IsNotExist(err) {
+		return ""
+	}
+	c.Assert(err, IsNil)
+	return string(data)
+}
+
+func (s *firewallSuite) TestParsePort(c *C) {
+	p, err := firewall.ParsePort("8080/tcp")
+	c.Assert(err, IsNil)
+	c.Check(p, Equals, firewall.Port{Number: 8080, Protocol: "tcp"})
+	c.Check(p.String(), Equals, "8080/tcp")
+
+	for _, bad := range []string{"", "8080", "tcp", "8080/sctp", "0/udp", "65536/tcp", "-1/tcp", " 22/tcp"} {
+		_, err := firewall.ParsePort(bad)
+		c.Check(err, NotNil, Commentf("%q", bad))
+	}
+}
+
+func (s *firewallSuite) TestSetPortsAndRemove(c *C) {
+	ports := []firewall.Port{{Number: 8080, Protocol: "tcp"}, {Number: 53, Protocol: "udp"}}
+	c.Assert(firewall.SetPorts("snap.foo", ports), IsNil)
+	c.Check(s.calls, DeepEquals, []string{
+		"--add-port=8080/tcp", "--permanent --add-port=8080/tcp",
+		"--add-port=53/udp", "--permanent --add-port=53/udp",
+	})
+	c.Check(s.record(c, "snap.foo"), Equals, "53/udp\n8080/tcp\n")
+
+	// only the changes are applied
+	s.calls = nil
+	ports = []firewall.Port{{Number: 8080, Protocol: "tcp"}, {Number: 443, Protocol: "tcp"}}
+	c.Assert(firewall.SetPorts("snap.foo", ports), IsNil)
+	c.Check(s.calls, DeepEquals, []string{
+		"--remove-port=53/udp", "--permanent --remove-port=53/udp",
+		"--add-port=443/tcp", "--permanent --add-port=443/tcp",
+	})
+	c.Check(s.record(c, "snap.foo"), Equals, "443/tcp\n8080/tcp\n")
+
+	s.calls = nil
+	c.Assert(firewall.RemovePorts("snap.foo"), IsNil)
+	c.Check(s.calls, DeepEquals, []string{
+		"--remove-port=443/tcp", "--permanent --remove-port=443/tcp",
+		"--remove-port=8080/tcp", "--permanent --remove-port=8080/tcp",
+	})
+	c.Check(s.record(c, "snap.foo"), Equals, "")
+}
+
+func (s *firewallSuite) TestNothingToDo(c *C) {
+	s.state = errors.New("not running")
+	c.Assert(firewall.RemovePorts("snap.foo"), IsNil)
+	c.Assert(firewall.SetPorts("snap.foo", nil), IsNil)
+	c.Check(s.calls, HasLen, 0)
+}
+
+func (s *firewallSuite) TestNoFirewall(c *C) {
+	s.state = errors.New("not running")
+	err := firewall.SetPorts("snap.foo", []firewall.Port{{Number: 22, Protocol: "tcp"}})
+	c.Check(err, Equals, firewall.ErrNoFirewall)
+	c.Check(s.calls, HasLen, 0)
+}
+
+func (s *firewallSuite) TestSetPortsErrorRecordsOpened(c *C) {
+	s.failArg = "--add-port=443/tcp"
+	ports := []firewall.Port{{Number: 80, Protocol: "tcp"}, {Number: 443, Protocol: "tcp"}}
+	err := firewall.SetPorts("snap.foo", ports)
+	c.Check(err, ErrorMatches, "cannot add port 443/tcp: boom")
+	c.Check(s.record(c, "snap.foo"), Equals, "80/tcp\n")
+}
+
+func (s *firewallSuite) TestSharedPorts(c *C) {
+	c.Assert(firewall.SetPorts("snap.foo", []firewall.Port{{Number: 80, Protocol: "tcp"}}), IsNil)
+	c.Assert(firewall.SetPorts("snap.bar", []firewall.Port{{Number: 80, Protocol: "tcp"}, {Number: 443, Protocol: "tcp"}}), IsNil)
+	// 80/tcp was opened only once
+	c.Check(s.calls, DeepEquals, []string{
+		"--add-port=80/tcp", "--permanent --add-port=80/tcp",
+		"--add-port=443/tcp", "--permanent --add-port=443/tcp",
+	})
+	c.Check(s.record(c, "snap.bar"), Equals, "80/tcp\n443/tcp\n")
+
+	// the port is still used by snap.bar
+	s.calls = nil
+	c.Assert(firewall.RemovePorts("snap.foo"), IsNil)
+	c.Check(s.calls, HasLen, 0)
+	c.Check(s.record(c, "snap.foo"), Equals, "")
+
+	// and is closed with the last of its users
+	c.Assert(firewall.RemovePorts("snap.bar"), IsNil)
+	c.Check(s.calls, DeepEquals, []string{
+		"--remove-port=80/tcp", "--permanent --remove-port=80/tcp",
+		"--remove-port=443/tcp", "--permanent --remove-port=443/tcp",
+	})
+}
+
+func (s *firewallSuite) TestExternalPorts(c *C) {
+	// opened by the administrator
+	s.open = map[string]bool{"22/tcp": true}
+
+	c.Assert(firewall.SetPorts("snap.foo", []firewall.Port{{Number: 22, Protocol: "tcp"}, {Number: 80, Protocol: "tcp"}}), IsNil)
+	c.Check(s.calls, DeepEquals, []string{
+		"--add-port=80/tcp", "--permanent --add-port=80/tcp",
+	})
+	c.Check(s.record(c, "snap.foo"), Equals, "22/tcp external\n80/tcp\n")
+
+	// other users of the port know it is external
+	c.Assert(firewall.SetPorts("snap.bar", []firewall.Port{{Number: 22, Protocol: "tcp"}}), IsNil)
+	c.Check(s.record(c, "snap.bar"), Equals, "22/tcp external\n")
+
+	s.calls = nil
+	c.Assert(firewall.RemovePorts("snap.foo"), IsNil)
+	c.Assert(firewall.RemovePorts("snap.bar"), IsNil)
+	// the port opened by the administrator is left open
+	c.Check(s.calls, DeepEquals, []string{
+		"--remove-port=80/tcp", "--permanent --remove-port=80/tcp",
+	})
+}
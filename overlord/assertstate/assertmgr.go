@@ -158,6 +158,12 @@ func doValidateSnap(t *state.Task, _ *tomb.Tomb) error {
 		return err
 	}
 
+	// and that the build provenance document, if signed for, has not
+	// been tampered with
+	if err := snapasserts.CheckBuildProvenanceWithVerifiedRevision(snapsup.SnapPath, verifiedRev); err != nil {
+		return err
+	}
+
 	// TODO: set DeveloperID from assertions
 	return nil
 }
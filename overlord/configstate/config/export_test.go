@@ -21,6 +21,7 @@ package config
 
 import (
 	"encoding/json"
+	"time"
 )
 
 var PurgeNulls = purgeNulls
@@ -33,3 +34,21 @@ var (
 	SortPatchKeysByDepth       = sortPatchKeysByDepth
 	OverlapsWithExternalConfig = overlapsWithExternalConfig
 )
+
+func MockHistoryLimit(limit int) (restore func()) {
+	old := historyLimit
+	historyLimit = limit
+	return func() { historyLimit = old }
+}
+
+func MockTimeNow(f func() time.Time) (restore func()) {
+	old := timeNow
+	timeNow = f
+	return func() { timeNow = old }
+}
+
+func MockMaxUserConfigSize(size int) (restore func()) {
+	old := maxUserConfigSize
+	maxUserConfigSize = size
+	return func() { maxUserConfigSize = old }
+}
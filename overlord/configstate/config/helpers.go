@@ -24,6 +24,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
 	"regexp"
 	"sort"
 	"strings"
@@ -245,6 +246,167 @@ func RestoreRevisionConfig(st *state.State, snapName string, rev snap.Revision)
 	return nil
 }
 
+// Change describes how a configuration option differs between the
+// configuration saved for a revision of a snap and its current one. Old or
+// New are nil if the option is only set on one side.
+type Change struct {
+	Key string      `json:"key"`
+	Old interface{} `json:"old,omitempty"`
+	New interface{} `json:"new,omitempty"`
+}
+
+// DiffRevisionConfig compares the configuration saved for the given revision
+// of the snap with its current configuration and returns the options that
+// differ, sorted by key.
+// The caller is responsible for locking the state.
+func DiffRevisionConfig(st *state.State, snapName string, rev snap.Revision) ([]Change, error) {
+	var config map[string]*json.RawMessage                    // snap => configuration
+	var revisionConfig map[string]map[string]*json.RawMessage // snap => revision => configuration
+
+	err := st.Get("revision-config", &revisionConfig)
+	if err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, fmt.Errorf("internal error: cannot unmarshal revision-config: %v", err)
+	}
+	err = st.Get("config", &config)
+	if err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, fmt.Errorf("internal error: cannot unmarshal configuration: %v", err)
+	}
+
+	oldValues, err := flattenRawConfig(revisionConfig[snapName][rev.String()])
+	if err != nil {
+		return nil, err
+	}
+	newValues, err := flattenRawConfig(config[snapName])
+	if err != nil {
+		return nil, err
+	}
+
+	return diffFlatConfig(oldValues, newValues), nil
+}
+
+// diffFlatConfig returns the options which differ between the given
+// flattened configurations, sorted by key.
+func diffFlatConfig(oldValues, newValues map[string]interface{}) []Change {
+	var changes []Change
+	for key, old := range oldValues {
+		if new, ok := newValues[key]; !ok || !reflect.DeepEqual(old, new) {
+			changes = append(changes, Change{Key: key, Old: old, New: new})
+		}
+	}
+	for key, new := range newValues {
+		if _, ok := oldValues[key]; !ok {
+			changes = append(changes, Change{Key: key, New: new})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// flattenRawConfig decodes the configuration of a snap into a map of dotted
+// option paths to their values.
+func flattenRawConfig(raw *json.RawMessage) (map[string]interface{}, error) {
+	if raw == nil {
+		return make(map[string]interface{}), nil
+	}
+	var configm map[string]interface{}
+	if err := jsonutil.DecodeWithNumber(bytes.NewReader(*raw), &configm); err != nil {
+		return nil, fmt.Errorf("internal error: cannot unmarshal configuration: %v", err)
+	}
+	return flattenConfig(configm), nil
+}
+
+// flattenConfig returns a map of the dotted option paths of the given
+// configuration to their values. Keys of the configuration can themselves
+// be dotted paths, as in patches or gadget defaults.
+func flattenConfig(configm map[string]interface{}) map[string]interface{} {
+	values := make(map[string]interface{})
+	var flatten func(prefix string, m map[string]interface{})
+	flatten = func(prefix string, m map[string]interface{}) {
+		for k, v := range m {
+			// empty documents, left behind when unsetting all
+			// their options, are not options themselves
+			if sub, ok := v.(map[string]interface{}); ok {
+				flatten(prefix+k+".", sub)
+				continue
+			}
+			values[prefix+k] = v
+		}
+	}
+	flatten("", configm)
+	return values
+}
+
+// Origins of effective configuration values.
+const (
+	// OriginExplicit is the origin of options set to a value other than
+	// their default.
+	OriginExplicit = "explicit"
+	// OriginDefault is the origin of options set to their default value.
+	OriginDefault = "default"
+	// OriginUnset is the origin of options with a default which are not
+	// set, usually because they were unset after the default was applied.
+	OriginUnset = "unset"
+)
+
+// EffectiveValue describes the value of a configuration option of a snap and
+// where it comes from. Default is only set for options with a default.
+type EffectiveValue struct {
+	Key     string      `json:"key"`
+	Value   interface{} `json:"value,omitempty"`
+	Default interface{} `json:"default,omitempty"`
+	Origin  string      `json:"origin"`
+}
+
+// EffectiveConfig returns the options of the current configuration of the
+// snap together with the options of the given defaults, typically the gadget
+// defaults, telling for each of them whether its value is the default one.
+// The result is sorted by key.
+// The caller is responsible for locking the state.
+func EffectiveConfig(st *state.State, snapName string, defaults map[string]interface{}) ([]EffectiveValue, error) {
+	raw, err := GetSnapConfig(st, snapName)
+	if err != nil {
+		return nil, err
+	}
+	values, err := flattenRawConfig(raw)
+	if err != nil {
+		return nil, err
+	}
+	defaultValues := flattenConfig(defaults)
+
+	effective := make([]EffectiveValue, 0, len(values)+len(defaultValues))
+	for key, value := range values {
+		ev := EffectiveValue{Key: key, Value: value, Origin: OriginExplicit}
+		if def, ok := defaultValues[key]; ok {
+			ev.Default = def
+			if sameConfigValue(value, def) {
+				ev.Origin = OriginDefault
+			}
+		}
+		effective = append(effective, ev)
+	}
+	for key, def := range defaultValues {
+		if _, ok := values[key]; !ok {
+			effective = append(effective, EffectiveValue{Key: key, Default: def, Origin: OriginUnset})
+		}
+	}
+	sort.Slice(effective, func(i, j int) bool { return effective[i].Key < effective[j].Key })
+	return effective, nil
+}
+
+// sameConfigValue compares values through their JSON encoding, as values
+// read from the state use json.Number while defaults use native types.
+func sameConfigValue(a, b interface{}) bool {
+	ja, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	jb, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(ja, jb)
+}
+
 // DiscardRevisionConfig removes configuration snapshot of given snap/revision.
 // If no configuration exists for given revision it does nothing (no error).
 // The caller is responsible for locking the state.
@@ -269,10 +431,18 @@ func DiscardRevisionConfig(st *state.State, snapName string, rev snap.Revision)
 	return nil
 }
 
-// DeleteSnapConfig removed configuration of given snap from the state.
+// DeleteSnapConfig removed configuration of given snap from the state,
+// along with its configuration history and the configuration of its users.
 func DeleteSnapConfig(st *state.State, snapName string) error {
 	var config map[string]map[string]*json.RawMessage // snap => key => value
 
+	if err := deleteHistory(st, snapName); err != nil {
+		return err
+	}
+	if err := deleteUserConfig(st, snapName); err != nil {
+		return err
+	}
+
 	err := st.Get("config", &config)
 	if errors.Is(err, state.ErrNoState) {
 		return nil
@@ -124,6 +124,73 @@ func (s *configHelpersSuite) TestDiscardRevisionConfig(c *C) {
 	c.Assert(cfgsnapshot["snap3"], IsNil)
 }
 
+func (s *configHelpersSuite) TestDiffRevisionConfig(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	tr := config.NewTransaction(s.state)
+	c.Assert(tr.Set("snap1", "foo", "a"), IsNil)
+	c.Assert(tr.Set("snap1", "bar", map[string]interface{}{"baz": 1, "qux": true}), IsNil)
+	c.Assert(tr.Set("snap1", "gone", "x"), IsNil)
+	tr.Commit()
+	c.Assert(config.SaveRevisionConfig(s.state, "snap1", snap.R(1)), IsNil)
+
+	tr = config.NewTransaction(s.state)
+	c.Assert(tr.Set("snap1", "bar.baz", 2), IsNil)
+	c.Assert(tr.Set("snap1", "gone", nil), IsNil)
+	c.Assert(tr.Set("snap1", "new", "y"), IsNil)
+	tr.Commit()
+
+	changes, err := config.DiffRevisionConfig(s.state, "snap1", snap.R(1))
+	c.Assert(err, IsNil)
+	c.Check(changes, DeepEquals, []config.Change{
+		{Key: "bar.baz", Old: json.Number("1"), New: json.Number("2")},
+		{Key: "gone", Old: "x"},
+		{Key: "new", New: "y"},
+	})
+
+	// no configuration saved for the revision
+	changes, err = config.DiffRevisionConfig(s.state, "snap1", snap.R(7))
+	c.Assert(err, IsNil)
+	c.Check(changes, HasLen, 4)
+
+	// no configuration at all
+	changes, err = config.DiffRevisionConfig(s.state, "snap2", snap.R(1))
+	c.Assert(err, IsNil)
+	c.Check(changes, HasLen, 0)
+}
+
+func (s *configHelpersSuite) TestEffectiveConfig(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	tr := config.NewTransaction(s.state)
+	c.Assert(tr.Set("snap1", "foo", "a"), IsNil)
+	c.Assert(tr.Set("snap1", "bar", map[string]interface{}{"baz": 1, "qux": true}), IsNil)
+	tr.Commit()
+
+	defaults := map[string]interface{}{
+		"foo":       "b",
+		"bar.baz":   int64(1),
+		"bar":       map[string]interface{}{"quux": "x"},
+		"undefined": []interface{}{"a"},
+	}
+	values, err := config.EffectiveConfig(s.state, "snap1", defaults)
+	c.Assert(err, IsNil)
+	c.Check(values, DeepEquals, []config.EffectiveValue{
+		{Key: "bar.baz", Value: json.Number("1"), Default: int64(1), Origin: config.OriginDefault},
+		{Key: "bar.quux", Default: "x", Origin: config.OriginUnset},
+		{Key: "bar.qux", Value: true, Origin: config.OriginExplicit},
+		{Key: "foo", Value: "a", Default: "b", Origin: config.OriginExplicit},
+		{Key: "undefined", Default: []interface{}{"a"}, Origin: config.OriginUnset},
+	})
+
+	// no configuration and no defaults
+	values, err = config.EffectiveConfig(s.state, "snap2", nil)
+	c.Assert(err, IsNil)
+	c.Check(values, HasLen, 0)
+}
+
 func (s *configHelpersSuite) TestConfigSnapshotNoConfigs(c *C) {
 	s.state.Lock()
 	defer s.state.Unlock()
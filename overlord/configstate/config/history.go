@@ -0,0 +1,157 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+// historyLimit is the number of configuration changes kept for each snap.
+var historyLimit = 20
+
+// HistoryEntry records how the configuration of a snap was changed by a
+// change, or by snapctl outside of any change.
+type HistoryEntry struct {
+	// Change is the ID of the change which modified the configuration,
+	// empty when it was modified by snapctl outside of a change.
+	Change string `json:"change,omitempty"`
+	// Actor is the user on whose behalf the change modified the
+	// configuration, "snapd" for changes snapd made on its own, or
+	// "snapctl".
+	Actor   string    `json:"actor"`
+	Time    time.Time `json:"time"`
+	Changes []Change  `json:"changes"`
+}
+
+// RecordingCommit commits the transaction like Transaction.Commit and
+// records in the history of the given snap the options modified by it, if
+// any, attributing them to the given change and actor.
+//
+// The state associated with the transaction must be locked by the caller.
+func RecordingCommit(tr *Transaction, snapName, changeID, actor string) error {
+	st := tr.State()
+	before, err := GetSnapConfig(st, snapName)
+	if err != nil {
+		return err
+	}
+	tr.Commit()
+	after, err := GetSnapConfig(st, snapName)
+	if err != nil {
+		return err
+	}
+
+	oldValues, err := flattenRawConfig(before)
+	if err != nil {
+		return err
+	}
+	newValues, err := flattenRawConfig(after)
+	if err != nil {
+		return err
+	}
+	changes := diffFlatConfig(oldValues, newValues)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	history, err := allHistory(st)
+	if err != nil {
+		return err
+	}
+	entries := append(history[snapName], HistoryEntry{
+		Change:  changeID,
+		Actor:   actor,
+		Time:    timeNow(),
+		Changes: changes,
+	})
+	if len(entries) > historyLimit {
+		entries = entries[len(entries)-historyLimit:]
+	}
+	history[snapName] = entries
+	st.Set("config-history", history)
+	return nil
+}
+
+// History returns the recorded configuration changes of the snap, oldest
+// first.
+// The caller is responsible for locking the state.
+func History(st *state.State, snapName string) ([]HistoryEntry, error) {
+	history, err := allHistory(st)
+	if err != nil {
+		return nil, err
+	}
+	return history[snapName], nil
+}
+
+// HistoryEntryForChange returns the configuration changes of the snap made
+// by the given change. It returns an error if the history of the snap does
+// not include the change.
+// The caller is responsible for locking the state.
+func HistoryEntryForChange(st *state.State, snapName, changeID string) (*HistoryEntry, error) {
+	history, err := History(st, snapName)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Change == changeID {
+			return &history[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no configuration change of snap %q recorded for change %q", snapName, changeID)
+}
+
+func allHistory(st *state.State) (map[string][]HistoryEntry, error) {
+	var history map[string][]HistoryEntry
+	err := st.Get("config-history", &history)
+	if errors.Is(err, state.ErrNoState) {
+		return make(map[string][]HistoryEntry), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("internal error: cannot unmarshal configuration history: %v", err)
+	}
+	return history, nil
+}
+
+func deleteHistory(st *state.State, snapName string) error {
+	history, err := allHistory(st)
+	if err != nil {
+		return err
+	}
+	if _, ok := history[snapName]; ok {
+		delete(history, snapName)
+		st.Set("config-history", history)
+	}
+	return nil
+}
+
+// RestorePatch returns the configuration patch restoring the values the
+// options had before the change recorded by the entry.
+func (e *HistoryEntry) RestorePatch() map[string]interface{} {
+	patch := make(map[string]interface{}, len(e.Changes))
+	for _, ch := range e.Changes {
+		patch[ch.Key] = ch.Old
+	}
+	return patch
+}
+
+var timeNow = time.Now
@@ -0,0 +1,138 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package config_test
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+type historySuite struct {
+	state *state.State
+}
+
+var _ = Suite(&historySuite{})
+
+func (s *historySuite) SetUpTest(c *C) {
+	s.state = state.New(nil)
+}
+
+func (s *historySuite) TestRecordingCommit(c *C) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	defer config.MockTimeNow(func() time.Time { return now })()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	tr := config.NewTransaction(s.state)
+	c.Assert(tr.Set("snap1", "foo", "a"), IsNil)
+	c.Assert(tr.Set("snap1", "bar", map[string]interface{}{"baz": "b"}), IsNil)
+	c.Assert(config.RecordingCommit(tr, "snap1", "1", "configure-snap"), IsNil)
+
+	tr = config.NewTransaction(s.state)
+	c.Assert(tr.Set("snap1", "foo", "c"), IsNil)
+	c.Assert(tr.Set("snap1", "bar.baz", nil), IsNil)
+	c.Assert(config.RecordingCommit(tr, "snap1", "", "snapctl"), IsNil)
+
+	// the configuration was committed
+	var foo string
+	c.Assert(config.NewTransaction(s.state).Get("snap1", "foo", &foo), IsNil)
+	c.Check(foo, Equals, "c")
+
+	// no-op commits are not recorded
+	tr = config.NewTransaction(s.state)
+	c.Assert(tr.Set("snap1", "foo", "c"), IsNil)
+	c.Assert(config.RecordingCommit(tr, "snap1", "3", "configure-snap"), IsNil)
+
+	history, err := config.History(s.state, "snap1")
+	c.Assert(err, IsNil)
+	c.Check(history, DeepEquals, []config.HistoryEntry{{
+		Change: "1",
+		Actor:  "configure-snap",
+		Time:   now,
+		Changes: []config.Change{
+			{Key: "bar.baz", New: "b"},
+			{Key: "foo", New: "a"},
+		},
+	}, {
+		Actor: "snapctl",
+		Time:  now,
+		Changes: []config.Change{
+			{Key: "bar.baz", Old: "b"},
+			{Key: "foo", Old: "a", New: "c"},
+		},
+	}})
+
+	entry, err := config.HistoryEntryForChange(s.state, "snap1", "1")
+	c.Assert(err, IsNil)
+	c.Check(entry.RestorePatch(), DeepEquals, map[string]interface{}{
+		"bar.baz": nil,
+		"foo":     nil,
+	})
+	_, err = config.HistoryEntryForChange(s.state, "snap1", "3")
+	c.Check(err, ErrorMatches, `no configuration change of snap "snap1" recorded for change "3"`)
+
+	history, err = config.History(s.state, "snap2")
+	c.Assert(err, IsNil)
+	c.Check(history, HasLen, 0)
+}
+
+func (s *historySuite) TestHistoryLimit(c *C) {
+	defer config.MockHistoryLimit(2)()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	for _, v := range []string{"a", "b", "c"} {
+		tr := config.NewTransaction(s.state)
+		c.Assert(tr.Set("snap1", "foo", v), IsNil)
+		c.Assert(config.RecordingCommit(tr, "snap1", v, "configure-snap"), IsNil)
+	}
+
+	history, err := config.History(s.state, "snap1")
+	c.Assert(err, IsNil)
+	c.Assert(history, HasLen, 2)
+	c.Check(history[0].Change, Equals, "b")
+	c.Check(history[1].Change, Equals, "c")
+}
+
+func (s *historySuite) TestDeleteSnapConfigDeletesHistory(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	for _, snapName := range []string{"snap1", "snap2"} {
+		tr := config.NewTransaction(s.state)
+		c.Assert(tr.Set(snapName, "foo", "a"), IsNil)
+		c.Assert(config.RecordingCommit(tr, snapName, "1", "configure-snap"), IsNil)
+	}
+
+	c.Assert(config.DeleteSnapConfig(s.state, "snap1"), IsNil)
+
+	history, err := config.History(s.state, "snap1")
+	c.Assert(err, IsNil)
+	c.Check(history, HasLen, 0)
+	history, err = config.History(s.state, "snap2")
+	c.Assert(err, IsNil)
+	c.Check(history, HasLen, 1)
+}
@@ -0,0 +1,330 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/snapcore/snapd/strutil"
+)
+
+// Schema describes the acceptable configuration of a snap. It is loaded from
+// the optional meta/config-schema.json file shipped in the snap and supports
+// a small subset of JSON schema:
+//
+//	{
+//	  "properties": {
+//	    "port": {"type": "integer", "minimum": 1, "maximum": 65535},
+//	    "mode": {"type": "string", "enum": ["fast", "slow"]},
+//	    "db": {
+//	      "type": "object",
+//	      "properties": {"host": {"type": "string", "pattern": "^[a-z.]+$"}},
+//	      "additionalProperties": false
+//	    }
+//	  }
+//	}
+//
+// Options not described by the schema are accepted unless
+// additionalProperties is false.
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+var schemaTypes = []string{"string", "integer", "number", "boolean", "object", "array"}
+
+// ValidationError is returned when a configuration patch does not match the
+// configuration schema of a snap.
+type ValidationError struct {
+	Snap string
+	Key  string
+	Msg  string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %q option %q: %s", e.Snap, e.Key, e.Msg)
+}
+
+// ReadSchema reads the configuration schema at the given path. It returns
+// nil and no error if the file does not exist.
+func ReadSchema(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ParseSchema(data)
+}
+
+// ParseSchema parses and checks the given configuration schema.
+func ParseSchema(data []byte) (*Schema, error) {
+	var schema Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("cannot parse configuration schema: %v", err)
+	}
+	if schema.Type == "" {
+		schema.Type = "object"
+	}
+	if err := schema.check(""); err != nil {
+		return nil, fmt.Errorf("invalid configuration schema: %v", err)
+	}
+	if schema.Type != "object" {
+		return nil, fmt.Errorf(`invalid configuration schema: top-level type must be "object", not %q`, schema.Type)
+	}
+	return &schema, nil
+}
+
+func (s *Schema) check(key string) error {
+	where := "schema"
+	if key != "" {
+		where = fmt.Sprintf("schema of %q", key)
+	}
+	if s.Type != "" && !strutil.ListContains(schemaTypes, s.Type) {
+		return fmt.Errorf("%s has unsupported type %q", where, s.Type)
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("%s has invalid pattern: %v", where, err)
+		}
+		s.pattern = re
+	}
+	for name, prop := range s.Properties {
+		if prop == nil {
+			return fmt.Errorf("%s has empty property %q", where, name)
+		}
+		if _, err := ParseKey(name); err != nil {
+			return fmt.Errorf("%s has %v", where, err)
+		}
+		if err := prop.check(joinKey(key, name)); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		if err := s.Items.check(key + "[]"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// ValidatePatch checks the given configuration patch of the given snap
+// against the schema. The keys of the patch can be dotted paths, as accepted
+// by "snap set". Unsetting options, with nil values, is always allowed.
+func (s *Schema) ValidatePatch(snapName string, patch map[string]interface{}) error {
+	keys := make([]string, 0, len(patch))
+	for key := range patch {
+		keys = append(keys, key)
+	}
+	// report errors in a stable order
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := patch[key]
+		if value == nil {
+			continue
+		}
+		subkeys, err := ParseKey(key)
+		if err != nil {
+			return err
+		}
+		schema := s
+		for i, subkey := range subkeys {
+			prop := schema.lookup(subkey)
+			if prop == nil {
+				if schema.AdditionalProperties != nil && !*schema.AdditionalProperties {
+					return &ValidationError{Snap: snapName, Key: strings.Join(subkeys[:i+1], "."), Msg: "unknown option"}
+				}
+				schema = nil
+				break
+			}
+			if i < len(subkeys)-1 && prop.Type != "" && prop.Type != "object" {
+				return &ValidationError{Snap: snapName, Key: strings.Join(subkeys[:i+1], "."), Msg: fmt.Sprintf("must be %s", typeDescription(prop.Type))}
+			}
+			schema = prop
+		}
+		if schema == nil {
+			continue
+		}
+		if err := schema.validate(key, value); err != nil {
+			err.Snap = snapName
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Schema) lookup(name string) *Schema {
+	if s.Properties == nil {
+		return nil
+	}
+	return s.Properties[name]
+}
+
+func typeDescription(typ string) string {
+	switch typ {
+	case "integer", "object", "array":
+		return "an " + typ
+	}
+	return "a " + typ
+}
+
+func (s *Schema) validate(key string, value interface{}) *ValidationError {
+	invalid := func(format string, v ...interface{}) *ValidationError {
+		return &ValidationError{Key: key, Msg: fmt.Sprintf(format, v...)}
+	}
+
+	var num float64
+	isNum := false
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return invalid("cannot parse number %q", v)
+		}
+		num, isNum = f, true
+	case float64:
+		num, isNum = v, true
+	case int:
+		num, isNum = float64(v), true
+	case int64:
+		num, isNum = float64(v), true
+	}
+
+	switch s.Type {
+	case "":
+		// any type
+	case "string":
+		if _, ok := value.(string); !ok {
+			return invalid("must be a string")
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return invalid("must be a boolean")
+		}
+	case "number":
+		if !isNum {
+			return invalid("must be a number")
+		}
+	case "integer":
+		if !isNum || num != math.Trunc(num) {
+			return invalid("must be an integer")
+		}
+	case "object":
+		if _, ok := value.(map[string]interface{}); !ok {
+			return invalid("must be an object")
+		}
+	case "array":
+		if _, ok := value.([]interface{}); !ok {
+			return invalid("must be an array")
+		}
+	}
+
+	if len(s.Enum) > 0 && !s.inEnum(value, num, isNum) {
+		allowed := make([]string, 0, len(s.Enum))
+		for _, e := range s.Enum {
+			b, _ := json.Marshal(e)
+			allowed = append(allowed, string(b))
+		}
+		return invalid("must be one of %s", strings.Join(allowed, ", "))
+	}
+	if isNum {
+		if s.Minimum != nil && num < *s.Minimum {
+			return invalid("must be at least %v", *s.Minimum)
+		}
+		if s.Maximum != nil && num > *s.Maximum {
+			return invalid("must be at most %v", *s.Maximum)
+		}
+	}
+	if str, ok := value.(string); ok && s.pattern != nil && !s.pattern.MatchString(str) {
+		return invalid("must match %q", s.Pattern)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if v[name] == nil {
+				continue
+			}
+			prop := s.lookup(name)
+			if prop == nil {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					return &ValidationError{Key: joinKey(key, name), Msg: "unknown option"}
+				}
+				continue
+			}
+			if err := prop.validate(joinKey(key, name), v[name]); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				if err := s.Items.validate(fmt.Sprintf("%s[%d]", key, i), item); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) inEnum(value interface{}, num float64, isNum bool) bool {
+	for _, e := range s.Enum {
+		if f, ok := e.(float64); ok {
+			if isNum && f == num {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(e, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package config_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/overlord/configstate/config"
+)
+
+type schemaSuite struct{}
+
+var _ = Suite(&schemaSuite{})
+
+const testSchema = `{
+  "properties": {
+    "port": {"type": "integer", "minimum": 1, "maximum": 65535},
+    "ratio": {"type": "number"},
+    "debug": {"type": "boolean"},
+    "mode": {"type": "string", "enum": ["fast", "slow"]},
+    "hosts": {"type": "array", "items": {"type": "string", "pattern": "^[a-z.]+$"}},
+    "db": {
+      "type": "object",
+      "properties": {
+        "host": {"type": "string"},
+        "port": {"type": "integer"}
+      },
+      "additionalProperties": false
+    }
+  }
+}`
+
+func (s *schemaSuite) TestValidatePatchHappy(c *C) {
+	schema, err := config.ParseSchema([]byte(testSchema))
+	c.Assert(err, IsNil)
+
+	c.Check(schema.ValidatePatch("foo", map[string]interface{}{
+		"port":    json.Number("8080"),
+		"ratio":   json.Number("0.5"),
+		"debug":   true,
+		"mode":    "fast",
+		"hosts":   []interface{}{"example.com"},
+		"db":      map[string]interface{}{"host": "localhost", "port": json.Number("5432")},
+		"db.host": "remote",
+		// not described by the schema
+		"other": "value",
+		// unsetting is always fine
+		"db.port": nil,
+	}), IsNil)
+}
+
+func (s *schemaSuite) TestValidatePatchErrors(c *C) {
+	schema, err := config.ParseSchema([]byte(testSchema))
+	c.Assert(err, IsNil)
+
+	for _, t := range []struct {
+		patch map[string]interface{}
+		err   string
+	}{
+		{map[string]interface{}{"port": "http"}, `invalid "foo" option "port": must be an integer`},
+		{map[string]interface{}{"port": json.Number("1.5")}, `invalid "foo" option "port": must be an integer`},
+		{map[string]interface{}{"port": json.Number("0")}, `invalid "foo" option "port": must be at least 1`},
+		{map[string]interface{}{"port": json.Number("70000")}, `invalid "foo" option "port": must be at most 65535`},
+		{map[string]interface{}{"ratio": "half"}, `invalid "foo" option "ratio": must be a number`},
+		{map[string]interface{}{"debug": "true"}, `invalid "foo" option "debug": must be a boolean`},
+		{map[string]interface{}{"mode": "medium"}, `invalid "foo" option "mode": must be one of "fast", "slow"`},
+		{map[string]interface{}{"hosts": "example.com"}, `invalid "foo" option "hosts": must be an array`},
+		{map[string]interface{}{"hosts": []interface{}{"a.b", "A_B"}}, `invalid "foo" option "hosts\[1\]": must match .*`},
+		{map[string]interface{}{"db": "localhost"}, `invalid "foo" option "db": must be an object`},
+		{map[string]interface{}{"db.port": "x"}, `invalid "foo" option "db.port": must be an integer`},
+		{map[string]interface{}{"db.user": "x"}, `invalid "foo" option "db.user": unknown option`},
+		{map[string]interface{}{"db": map[string]interface{}{"user": "x"}}, `invalid "foo" option "db.user": unknown option`},
+		{map[string]interface{}{"port.number": json.Number("1")}, `invalid "foo" option "port": must be an integer`},
+	} {
+		c.Check(schema.ValidatePatch("foo", t.patch), ErrorMatches, t.err, Commentf("%v", t.patch))
+	}
+}
+
+func (s *schemaSuite) TestParseSchemaErrors(c *C) {
+	for _, t := range []struct {
+		schema string
+		err    string
+	}{
+		{`{`, `cannot parse configuration schema: .*`},
+		{`{"type": "string"}`, `invalid configuration schema: top-level type must be "object", not "string"`},
+		{`{"properties": {"a": {"type": "date"}}}`, `invalid configuration schema: schema of "a" has unsupported type "date"`},
+		{`{"properties": {"a": {"pattern": "("}}}`, `invalid configuration schema: schema of "a" has invalid pattern: .*`},
+		{`{"properties": {"A": {}}}`, `invalid configuration schema: schema has invalid option name: "A"`},
+		{`{"properties": {"a": {"properties": {"b": null}}}}`, `invalid configuration schema: schema of "a" has empty property "b"`},
+	} {
+		_, err := config.ParseSchema([]byte(t.schema))
+		c.Check(err, ErrorMatches, t.err, Commentf(t.schema))
+	}
+}
+
+func (s *schemaSuite) TestReadSchema(c *C) {
+	path := filepath.Join(c.MkDir(), "config-schema.json")
+
+	schema, err := config.ReadSchema(path)
+	c.Assert(err, IsNil)
+	c.Check(schema, IsNil)
+
+	c.Assert(os.WriteFile(path, []byte(testSchema), 0644), IsNil)
+	schema, err = config.ReadSchema(path)
+	c.Assert(err, IsNil)
+	c.Check(schema, NotNil)
+}
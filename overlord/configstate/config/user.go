@@ -0,0 +1,200 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/snapcore/snapd/osutil/sys"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+// The per-user configuration of snaps is kept apart from the system one. It
+// is not validated by the snaps as it is not seen by their hooks, and it is
+// only accessible to the user it belongs to.
+
+type userConfig map[string]map[string]map[string]*json.RawMessage // snap => uid => key => value
+
+// maxUserConfigSize is the maximum size of the configuration of a snap for
+// a single user, in its JSON form. Any user can change their own
+// configuration so it must not be allowed to grow the state without bound.
+var maxUserConfigSize = 64 * 1024
+
+func allUserConfig(st *state.State) (userConfig, error) {
+	var config userConfig
+	err := st.Get("user-config", &config)
+	if errors.Is(err, state.ErrNoState) {
+		return make(userConfig), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("internal error: cannot unmarshal user configuration: %v", err)
+	}
+	return config, nil
+}
+
+func uidKey(uid sys.UserID) string {
+	return strconv.FormatUint(uint64(uid), 10)
+}
+
+// GetUserConfig unmarshals into result the value of the provided key from
+// the configuration of the snap for the given user. The empty key returns
+// the whole configuration of the user. If the key does not exist, an error
+// of type *NoOptionError is returned.
+// The caller is responsible for locking the state.
+func GetUserConfig(st *state.State, snapName string, uid sys.UserID, key string, result interface{}) error {
+	subkeys, err := ParseKey(key)
+	if err != nil {
+		return err
+	}
+	config, err := allUserConfig(st)
+	if err != nil {
+		return err
+	}
+	return getFromConfig(snapName, subkeys, 0, config[snapName][uidKey(uid)], result)
+}
+
+// SetUserConfig sets the provided key to the given value in the
+// configuration of the snap for the given user. The key may be dotted as
+// for Transaction.Set, and a nil value unsets the key. Unlike Set, the
+// change is applied to the state right away.
+// The caller is responsible for locking the state.
+func SetUserConfig(st *state.State, snapName string, uid sys.UserID, key string, value interface{}) error {
+	return updateUserConfig(st, snapName, uid, func(s *userConfSetter) error {
+		return s.Set(snapName, key, value)
+	})
+}
+
+func setUserConfig(st *state.State, snapName string, uid sys.UserID, key string, value interface{}) error {
+	subkeys, err := ParseKey(key)
+	if err != nil {
+		return err
+	}
+	if len(subkeys) == 0 {
+		return fmt.Errorf("cannot set snap %q user configuration: no key provided", snapName)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("cannot marshal snap %q option %q: %s", snapName, key, err)
+	}
+	raw := json.RawMessage(data)
+
+	config, err := allUserConfig(st)
+	if err != nil {
+		return err
+	}
+	snapConfig := config[snapName]
+	if snapConfig == nil {
+		snapConfig = make(map[string]map[string]*json.RawMessage)
+	}
+	userCfg := snapConfig[uidKey(uid)]
+	if userCfg == nil {
+		userCfg = make(map[string]*json.RawMessage)
+	}
+
+	// check whether it's trying to traverse a non-map
+	if len(subkeys) > 1 {
+		var result interface{}
+		err = getFromConfig(snapName, subkeys, 0, userCfg, &result)
+		if err != nil && !IsNoOption(err) {
+			return err
+		}
+	}
+	changes := make(map[string]interface{})
+	if _, err := PatchConfig(snapName, subkeys, 0, changes, &raw); err != nil {
+		return err
+	}
+	applyChanges(userCfg, changes)
+	purgeNulls(userCfg)
+
+	if len(userCfg) == 0 {
+		delete(snapConfig, uidKey(uid))
+	} else {
+		snapConfig[uidKey(uid)] = userCfg
+	}
+	if len(snapConfig) == 0 {
+		delete(config, snapName)
+	} else {
+		config[snapName] = snapConfig
+	}
+	st.Set("user-config", config)
+	return nil
+}
+
+type userConfSetter struct {
+	st  *state.State
+	uid sys.UserID
+}
+
+func (s *userConfSetter) Set(snapName, key string, value interface{}) error {
+	return setUserConfig(s.st, snapName, s.uid, key, value)
+}
+
+// PatchUserConfig sets the values of the patch in the configuration of the
+// snap for the given user, as Patch does. The configuration is left
+// unchanged if any of the values cannot be set.
+// The caller is responsible for locking the state.
+func PatchUserConfig(st *state.State, snapName string, uid sys.UserID, patch map[string]interface{}) error {
+	return updateUserConfig(st, snapName, uid, func(s *userConfSetter) error {
+		return Patch(s, snapName, patch)
+	})
+}
+
+// updateUserConfig applies the given update to the configuration of the
+// snap for the given user, leaving it unchanged if the update fails or if
+// the configuration would be too large.
+func updateUserConfig(st *state.State, snapName string, uid sys.UserID, update func(s *userConfSetter) error) error {
+	orig, err := allUserConfig(st)
+	if err != nil {
+		return err
+	}
+	if err := update(&userConfSetter{st: st, uid: uid}); err != nil {
+		st.Set("user-config", orig)
+		return err
+	}
+
+	config, err := allUserConfig(st)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(config[snapName][uidKey(uid)])
+	if err != nil {
+		return err
+	}
+	if len(data) > maxUserConfigSize {
+		st.Set("user-config", orig)
+		return fmt.Errorf("cannot set snap %q user configuration: configuration would exceed the limit of %d bytes", snapName, maxUserConfigSize)
+	}
+	return nil
+}
+
+func deleteUserConfig(st *state.State, snapName string) error {
+	config, err := allUserConfig(st)
+	if err != nil {
+		return err
+	}
+	if _, ok := config[snapName]; ok {
+		delete(config, snapName)
+		st.Set("user-config", config)
+	}
+	return nil
+}
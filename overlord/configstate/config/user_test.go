@@ -0,0 +1,208 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package config_test
+
+import (
+	"encoding/json"
+	"strings"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+type userConfigSuite struct {
+	state *state.State
+}
+
+var _ = Suite(&userConfigSuite{})
+
+func (s *userConfigSuite) SetUpTest(c *C) {
+	s.state = state.New(nil)
+}
+
+func (s *userConfigSuite) TestSetGet(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	c.Assert(config.SetUserConfig(s.state, "snap1", 1000, "theme", "dark"), IsNil)
+	c.Assert(config.SetUserConfig(s.state, "snap1", 1000, "window.width", 800), IsNil)
+	c.Assert(config.SetUserConfig(s.state, "snap1", 1001, "theme", "light"), IsNil)
+
+	var theme string
+	c.Assert(config.GetUserConfig(s.state, "snap1", 1000, "theme", &theme), IsNil)
+	c.Check(theme, Equals, "dark")
+	c.Assert(config.GetUserConfig(s.state, "snap1", 1001, "theme", &theme), IsNil)
+	c.Check(theme, Equals, "light")
+
+	var width json.Number
+	c.Assert(config.GetUserConfig(s.state, "snap1", 1000, "window.width", &width), IsNil)
+	c.Check(width.String(), Equals, "800")
+
+	var all map[string]interface{}
+	c.Assert(config.GetUserConfig(s.state, "snap1", 1000, "", &all), IsNil)
+	c.Check(all, DeepEquals, map[string]interface{}{
+		"theme":  "dark",
+		"window": map[string]interface{}{"width": json.Number("800")},
+	})
+
+	// the user configuration is separate from the system one
+	var value interface{}
+	err := config.NewTransaction(s.state).Get("snap1", "theme", &value)
+	c.Check(config.IsNoOption(err), Equals, true)
+}
+
+func (s *userConfigSuite) TestGetNoOption(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	var value interface{}
+	err := config.GetUserConfig(s.state, "snap1", 1000, "theme", &value)
+	c.Check(config.IsNoOption(err), Equals, true)
+	c.Check(err, ErrorMatches, `snap "snap1" has no "theme" configuration option`)
+
+	c.Assert(config.SetUserConfig(s.state, "snap1", 1000, "theme", "dark"), IsNil)
+	// other users do not see it
+	err = config.GetUserConfig(s.state, "snap1", 1001, "theme", &value)
+	c.Check(config.IsNoOption(err), Equals, true)
+	err = config.GetUserConfig(s.state, "snap1", 1001, "", &value)
+	c.Check(config.IsNoOption(err), Equals, true)
+}
+
+func (s *userConfigSuite) TestUnset(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	c.Assert(config.SetUserConfig(s.state, "snap1", 1000, "a.b", 1), IsNil)
+	c.Assert(config.SetUserConfig(s.state, "snap1", 1000, "a.c", 2), IsNil)
+	c.Assert(config.SetUserConfig(s.state, "snap1", 1000, "a.b", nil), IsNil)
+
+	var a map[string]interface{}
+	c.Assert(config.GetUserConfig(s.state, "snap1", 1000, "a", &a), IsNil)
+	c.Check(a, DeepEquals, map[string]interface{}{"c": json.Number("2")})
+
+	// unsetting the last option removes the entries of the user and snap
+	c.Assert(config.SetUserConfig(s.state, "snap1", 1000, "a", nil), IsNil)
+	var raw map[string]interface{}
+	c.Assert(s.state.Get("user-config", &raw), IsNil)
+	c.Check(raw, HasLen, 0)
+}
+
+func (s *userConfigSuite) TestSetErrors(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	err := config.SetUserConfig(s.state, "snap1", 1000, "", "x")
+	c.Check(err, ErrorMatches, `cannot set snap "snap1" user configuration: no key provided`)
+	err = config.SetUserConfig(s.state, "snap1", 1000, "Bad", "x")
+	c.Check(err, ErrorMatches, `invalid option name: "Bad"`)
+
+	c.Assert(config.SetUserConfig(s.state, "snap1", 1000, "a", "x"), IsNil)
+	err = config.SetUserConfig(s.state, "snap1", 1000, "a.b", "y")
+	c.Check(err, ErrorMatches, `snap "snap1" option "a" is not a map`)
+}
+
+func (s *userConfigSuite) TestDeleteSnapConfigDeletesUserConfig(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	c.Assert(config.SetUserConfig(s.state, "snap1", 1000, "theme", "dark"), IsNil)
+	c.Assert(config.SetUserConfig(s.state, "snap2", 1000, "theme", "light"), IsNil)
+
+	c.Assert(config.DeleteSnapConfig(s.state, "snap1"), IsNil)
+
+	var value interface{}
+	err := config.GetUserConfig(s.state, "snap1", 1000, "theme", &value)
+	c.Check(config.IsNoOption(err), Equals, true)
+	c.Assert(config.GetUserConfig(s.state, "snap2", 1000, "theme", &value), IsNil)
+	c.Check(value, Equals, "light")
+}
+
+func (s *userConfigSuite) TestPatchUserConfig(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	c.Assert(config.SetUserConfig(s.state, "snap1", 1000, "a", "x"), IsNil)
+
+	err := config.PatchUserConfig(s.state, "snap1", 1000, map[string]interface{}{
+		"b":   "y",
+		"c.d": "z",
+		"a":   nil,
+	})
+	c.Assert(err, IsNil)
+
+	var all map[string]interface{}
+	c.Assert(config.GetUserConfig(s.state, "snap1", 1000, "", &all), IsNil)
+	c.Check(all, DeepEquals, map[string]interface{}{
+		"b": "y",
+		"c": map[string]interface{}{"d": "z"},
+	})
+}
+
+func (s *userConfigSuite) TestPatchUserConfigIsAtomic(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	c.Assert(config.SetUserConfig(s.state, "snap1", 1000, "a", "x"), IsNil)
+
+	err := config.PatchUserConfig(s.state, "snap1", 1000, map[string]interface{}{
+		"b":   "y",
+		"a.c": "z",
+	})
+	c.Assert(err, ErrorMatches, `snap "snap1" option "a" is not a map`)
+
+	var all map[string]interface{}
+	c.Assert(config.GetUserConfig(s.state, "snap1", 1000, "", &all), IsNil)
+	c.Check(all, DeepEquals, map[string]interface{}{"a": "x"})
+}
+
+func (s *userConfigSuite) TestUserConfigSizeLimit(c *C) {
+	restore := config.MockMaxUserConfigSize(64)
+	defer restore()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	c.Assert(config.SetUserConfig(s.state, "snap1", 1000, "a", strings.Repeat("x", 40)), IsNil)
+
+	err := config.SetUserConfig(s.state, "snap1", 1000, "b", strings.Repeat("y", 40))
+	c.Check(err, ErrorMatches, `cannot set snap "snap1" user configuration: configuration would exceed the limit of 64 bytes`)
+	err = config.PatchUserConfig(s.state, "snap1", 1000, map[string]interface{}{
+		"b": strings.Repeat("y", 20),
+		"c": strings.Repeat("z", 20),
+	})
+	c.Check(err, ErrorMatches, `cannot set snap "snap1" user configuration: configuration would exceed the limit of 64 bytes`)
+
+	var all map[string]interface{}
+	c.Assert(config.GetUserConfig(s.state, "snap1", 1000, "", &all), IsNil)
+	c.Check(all, DeepEquals, map[string]interface{}{"a": strings.Repeat("x", 40)})
+
+	// the limit applies to each user and snap separately
+	c.Assert(config.SetUserConfig(s.state, "snap1", 1001, "b", strings.Repeat("y", 40)), IsNil)
+	c.Assert(config.SetUserConfig(s.state, "snap2", 1000, "b", strings.Repeat("y", 40)), IsNil)
+
+	// the configuration can be replaced by one within the limit
+	err = config.PatchUserConfig(s.state, "snap1", 1000, map[string]interface{}{
+		"a": nil,
+		"b": strings.Repeat("y", 40),
+	})
+	c.Assert(err, IsNil)
+}
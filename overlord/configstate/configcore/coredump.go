@@ -31,10 +31,12 @@ import (
 )
 
 const (
-	optionCoredumpEnable     = "system.coredump.enable"
-	optionCoredumpMaxuse     = "system.coredump.maxuse"
-	coreOptionCoredumpEnable = "core." + optionCoredumpEnable
-	coreOptionCoredumpMaxuse = "core." + optionCoredumpMaxuse
+	optionCoredumpEnable         = "system.coredump.enable"
+	optionCoredumpMaxuse         = "system.coredump.maxuse"
+	optionCoredumpSnapMaxuse     = "system.coredump.snap-maxuse"
+	coreOptionCoredumpEnable     = "core." + optionCoredumpEnable
+	coreOptionCoredumpMaxuse     = "core." + optionCoredumpMaxuse
+	coreOptionCoredumpSnapMaxuse = "core." + optionCoredumpSnapMaxuse
 
 	coredumpCfgSubdir = "coredump.conf.d"
 	coredumpCfgFile   = "ubuntu-core.conf"
@@ -44,6 +46,7 @@ func init() {
 	// add supported configuration of this module
 	supportedConfigurations[coreOptionCoredumpEnable] = true
 	supportedConfigurations[coreOptionCoredumpMaxuse] = true
+	supportedConfigurations[coreOptionCoredumpSnapMaxuse] = true
 }
 
 func validMaxUseSize(sizeStr string) error {
@@ -68,8 +71,16 @@ func validateCoredumpSettings(tr ConfGetter) error {
 	if err != nil {
 		return err
 	}
+	if err := validMaxUseSize(maxUse); err != nil {
+		return err
+	}
 
-	return validMaxUseSize(maxUse)
+	// the core dumps of snap services are forwarded by the service manager
+	snapMaxUse, err := coreCfg(tr, optionCoredumpSnapMaxuse)
+	if err != nil {
+		return err
+	}
+	return validMaxUseSize(snapMaxUse)
 }
 
 func handleCoredumpConfiguration(dev sysconfig.Device, tr ConfGetter, opts *fsOnlyContext) error {
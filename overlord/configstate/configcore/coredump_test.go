@@ -118,6 +118,28 @@ func (s *coredumpSuite) TestConfigureCoredumpInvalidMaxUse(c *C) {
 	}
 }
 
+func (s *coredumpSuite) TestConfigureCoredumpSnapMaxUse(c *C) {
+	err := configcore.FilesystemOnlyRun(core20Dev, &mockConf{
+		state: s.state,
+		conf: map[string]interface{}{
+			"system.coredump.enable":      true,
+			"system.coredump.snap-maxuse": "16M",
+		},
+	})
+	c.Assert(err, IsNil)
+	// the core dumps are forwarded by snapd, systemd-coredump is unchanged
+	c.Check(s.coredumpCfgPath, testutil.FileEquals, "[Coredump]\nStorage=external\n")
+
+	err = configcore.FilesystemOnlyRun(core20Dev, &mockConf{
+		state: s.state,
+		conf: map[string]interface{}{
+			"system.coredump.enable":      true,
+			"system.coredump.snap-maxuse": "16m",
+		},
+	})
+	c.Assert(err, ErrorMatches, `invalid suffix .*`)
+}
+
 func (s *coredumpSuite) TestConfigureCoredumpNoModeEnv(c *C) {
 	err := configcore.FilesystemOnlyRun(coreDev, &mockConf{
 		state: s.state,
@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/snapcore/snapd/boot"
+	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/osutil/sys"
@@ -107,3 +108,7 @@ func MockEnvPath(newEnvPath string) func() {
 	envFilePath = newEnvPath
 	return func() { envFilePath = oldEnvPath }
 }
+
+func MockIfacestateDisconnectDenied(f func(st *state.State, conn *interfaces.Connection) (*state.TaskSet, error)) func() {
+	return testutil.Mock(&ifacestateDisconnectDenied, f)
+}
@@ -0,0 +1,48 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//go:build !nomanagers
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package configcore
+
+func init() {
+	// when set, the ports declared by the services of snaps are opened
+	// in the firewall of the system
+	supportedConfigurations["core.firewall-control-lite"] = true
+}
+
+func validateFirewallSettings(tr RunTransaction) error {
+	return validateBoolFlag(tr, "firewall-control-lite")
+}
+
+func handleFirewallSettings(tr RunTransaction, opts *fsOnlyContext) error {
+	output, err := coreCfg(tr, "firewall-control-lite")
+	if err != nil {
+		return err
+	}
+
+	// normalize the value so that it can be read as a boolean
+	switch output {
+	case "true":
+		tr.Set("core", "firewall-control-lite", true)
+	case "false":
+		tr.Set("core", "firewall-control-lite", false)
+	}
+
+	return nil
+}
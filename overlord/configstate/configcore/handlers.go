@@ -99,6 +99,9 @@ func init() {
 	// journal.persistent
 	addFSOnlyHandler(validateJournalSettings, handleJournalConfiguration, coreOnly)
 
+	// journal.system-max-use
+	addFSOnlyHandler(validateJournalSizeSettings, handleJournalSizeConfiguration, coreOnly)
+
 	// system.timezone
 	addFSOnlyHandler(validateTimezoneSettings, handleTimezoneConfiguration, coreOnly)
 
@@ -121,6 +124,9 @@ func init() {
 	// system.coredump
 	addFSOnlyHandler(validateCoredumpSettings, handleCoredumpConfiguration, coreOnly)
 
+	// snapd.reexec
+	addFSOnlyHandler(validateSnapdReexecSetting, handleSnapdReexecConfiguration, nil)
+
 	sysconfig.ApplyFilesystemOnlyDefaultsImpl = filesystemOnlyApply
 }
 
@@ -0,0 +1,48 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package configcore
+
+import (
+	"fmt"
+	"time"
+)
+
+func init() {
+	// add supported configuration of this module
+	supportedConfigurations["core.hooks.timeout"] = true
+}
+
+func validateHooksTimeout(tr RunTransaction) error {
+	timeoutStr, err := coreCfg(tr, "hooks.timeout")
+	if err != nil {
+		return err
+	}
+	if timeoutStr == "" {
+		return nil
+	}
+	timeout, err := time.ParseDuration(timeoutStr)
+	if err != nil {
+		return fmt.Errorf("hooks.timeout cannot be parsed: %v", err)
+	}
+	if timeout < time.Minute {
+		return fmt.Errorf("hooks.timeout must be at least one minute")
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package configcore_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/overlord/configstate/configcore"
+)
+
+type hooksSuite struct {
+	configcoreSuite
+}
+
+var _ = Suite(&hooksSuite{})
+
+func (s *hooksSuite) TestConfigureHooksTimeoutHappy(c *C) {
+	for _, timeout := range []string{"", "1m", "30m", "2h"} {
+		err := configcore.Run(classicDev, &mockConf{
+			state: s.state,
+			conf: map[string]interface{}{
+				"hooks.timeout": timeout,
+			},
+		})
+		c.Assert(err, IsNil)
+	}
+}
+
+func (s *hooksSuite) TestConfigureHooksTimeoutTooLow(c *C) {
+	err := configcore.Run(classicDev, &mockConf{
+		state: s.state,
+		conf: map[string]interface{}{
+			"hooks.timeout": "30s",
+		},
+	})
+	c.Assert(err, ErrorMatches, `hooks.timeout must be at least one minute`)
+}
+
+func (s *hooksSuite) TestConfigureHooksTimeoutInvalid(c *C) {
+	err := configcore.Run(classicDev, &mockConf{
+		state: s.state,
+		conf: map[string]interface{}{
+			"hooks.timeout": "invalid",
+		},
+	})
+	c.Assert(err, ErrorMatches, `hooks.timeout cannot be parsed:.*`)
+}
@@ -0,0 +1,134 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package configcore
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/ifacestate"
+	"github.com/snapcore/snapd/overlord/ifacestate/ifacerepo"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap/naming"
+	"github.com/snapcore/snapd/strutil"
+)
+
+const interfacesDenyOpt = "interfaces.deny"
+
+func init() {
+	// comma-separated list of interfaces that cannot be connected
+	supportedConfigurations["core."+interfacesDenyOpt] = true
+}
+
+var ifacestateDisconnectDenied = ifacestate.DisconnectDenied
+
+func validateInterfacesDeny(tr RunTransaction) error {
+	value, err := coreCfg(tr, interfacesDenyOpt)
+	if err != nil {
+		return err
+	}
+
+	for _, iface := range strutil.CommaSeparatedList(value) {
+		if err := naming.ValidateInterface(iface); err != nil {
+			return fmt.Errorf("cannot set %s: %v", interfacesDenyOpt, err)
+		}
+	}
+	return nil
+}
+
+// handleInterfacesDeny disconnects the existing connections of interfaces
+// that were just denied. Automatic connections are not marked as undesired
+// so that they come back once the interface is allowed again. Refusing new
+// connections of denied interfaces is done by ifacestate.
+func handleInterfacesDeny(tr RunTransaction, opts *fsOnlyContext) error {
+	var pristineDeny, newDeny string
+	if err := tr.GetPristine("core", interfacesDenyOpt, &pristineDeny); err != nil && !config.IsNoOption(err) {
+		return err
+	}
+	if err := tr.Get("core", interfacesDenyOpt, &newDeny); err != nil && !config.IsNoOption(err) {
+		return err
+	}
+	if pristineDeny == newDeny {
+		return nil
+	}
+
+	pristineDenied := strutil.CommaSeparatedList(pristineDeny)
+	var denied []string
+	for _, iface := range strutil.CommaSeparatedList(newDeny) {
+		if !strutil.ListContains(pristineDenied, iface) {
+			denied = append(denied, iface)
+		}
+	}
+	if len(denied) == 0 {
+		return nil
+	}
+
+	st := tr.State()
+	st.Lock()
+	defer st.Unlock()
+
+	conns, err := ifacestate.ConnectionStates(st)
+	if err != nil {
+		return fmt.Errorf("internal error: cannot get connections: %v", err)
+	}
+	var ids []string
+	for id, connState := range conns {
+		if connState.Active() && strutil.ListContains(denied, connState.Interface) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	sort.Strings(ids)
+
+	repo := ifacerepo.Get(st)
+	var tss []*state.TaskSet
+	for _, id := range ids {
+		connRef, err := interfaces.ParseConnRef(id)
+		if err != nil {
+			return err
+		}
+		conn, err := repo.Connection(connRef)
+		if err != nil {
+			// not in the repository, e.g. an unplugged hotplug device
+			continue
+		}
+		ts, err := ifacestateDisconnectDenied(st, conn)
+		if err != nil {
+			return fmt.Errorf("cannot disconnect %s: %v", id, err)
+		}
+		tss = append(tss, ts)
+	}
+	if len(tss) == 0 {
+		return nil
+	}
+
+	chg := st.NewChange("disconnect", i18n.G("Disconnect interfaces denied by the system configuration"))
+	for _, ts := range tss {
+		chg.AddAll(ts)
+	}
+	st.EnsureBefore(0)
+
+	return nil
+}
@@ -0,0 +1,169 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package configcore_test
+
+import (
+	"fmt"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/overlord/configstate/configcore"
+	"github.com/snapcore/snapd/overlord/ifacestate/ifacerepo"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap/snaptest"
+)
+
+type interfacesDenySuite struct {
+	configcoreSuite
+
+	repo         *interfaces.Repository
+	disconnected []string
+}
+
+var _ = Suite(&interfacesDenySuite{})
+
+const interfacesDenyConsumerYaml = `name: consumer
+version: 1
+plugs:
+  camera:
+  audio-record:
+  network:
+`
+
+const interfacesDenyCoreYaml = `name: core
+version: 1
+type: os
+slots:
+  camera:
+  audio-record:
+  network:
+`
+
+func (s *interfacesDenySuite) SetUpTest(c *C) {
+	s.configcoreSuite.SetUpTest(c)
+
+	s.repo = interfaces.NewRepository()
+	for _, iface := range builtin.Interfaces() {
+		c.Assert(s.repo.AddInterface(iface), IsNil)
+	}
+	for _, yaml := range []string{interfacesDenyConsumerYaml, interfacesDenyCoreYaml} {
+		info := snaptest.MockInfo(c, yaml, nil)
+		appSet, err := interfaces.NewSnapAppSet(info, nil)
+		c.Assert(err, IsNil)
+		c.Assert(s.repo.AddAppSet(appSet), IsNil)
+	}
+
+	conns := map[string]interface{}{}
+	for _, iface := range []string{"camera", "audio-record", "network"} {
+		connRef := &interfaces.ConnRef{
+			PlugRef: interfaces.PlugRef{Snap: "consumer", Name: iface},
+			SlotRef: interfaces.SlotRef{Snap: "core", Name: iface},
+		}
+		_, err := s.repo.Connect(connRef, nil, nil, nil, nil, nil)
+		c.Assert(err, IsNil)
+		conns[connRef.ID()] = map[string]interface{}{"interface": iface, "auto": true}
+	}
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	ifacerepo.Replace(s.state, s.repo)
+	s.state.Set("conns", conns)
+
+	s.disconnected = nil
+	s.AddCleanup(configcore.MockIfacestateDisconnectDenied(func(st *state.State, conn *interfaces.Connection) (*state.TaskSet, error) {
+		s.disconnected = append(s.disconnected, conn.Plug.Name())
+		return state.NewTaskSet(st.NewTask("disconnect", "mock disconnect")), nil
+	}))
+}
+
+func (s *interfacesDenySuite) TestInterfacesDenyValid(c *C) {
+	for _, value := range []string{"", "camera", "camera,audio-record", "camera, audio-record"} {
+		err := configcore.Run(classicDev, &mockConf{
+			state: s.state,
+			conf:  map[string]interface{}{"interfaces.deny": value},
+		})
+		c.Check(err, IsNil, Commentf("%q", value))
+	}
+	c.Check(s.disconnected, HasLen, 0)
+}
+
+func (s *interfacesDenySuite) TestInterfacesDenyInvalid(c *C) {
+	for _, tc := range []struct {
+		value  string
+		errStr string
+	}{
+		{"Camera", `cannot set interfaces.deny: invalid interface name: "Camera"`},
+		{"camera,-audio", `cannot set interfaces.deny: invalid interface name: "-audio"`},
+	} {
+		err := configcore.Run(classicDev, &mockConf{
+			state: s.state,
+			conf:  map[string]interface{}{"interfaces.deny": tc.value},
+		})
+		c.Check(err, ErrorMatches, tc.errStr)
+	}
+}
+
+func (s *interfacesDenySuite) TestInterfacesDenyDisconnects(c *C) {
+	err := configcore.Run(classicDev, &mockConf{
+		state:   s.state,
+		conf:    map[string]interface{}{"interfaces.deny": "camera"},
+		changes: map[string]interface{}{"interfaces.deny": "camera,audio-record,bluez"},
+	})
+	c.Assert(err, IsNil)
+	// only the newly denied interfaces are disconnected
+	c.Check(s.disconnected, DeepEquals, []string{"audio-record"})
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	chgs := s.state.Changes()
+	c.Assert(chgs, HasLen, 1)
+	c.Check(chgs[0].Kind(), Equals, "disconnect")
+	c.Check(chgs[0].Summary(), Equals, "Disconnect interfaces denied by the system configuration")
+	c.Check(chgs[0].Tasks(), HasLen, 1)
+}
+
+func (s *interfacesDenySuite) TestInterfacesDenyUnchanged(c *C) {
+	err := configcore.Run(classicDev, &mockConf{
+		state:   s.state,
+		conf:    map[string]interface{}{"interfaces.deny": "camera"},
+		changes: map[string]interface{}{"interfaces.deny": "camera"},
+	})
+	c.Assert(err, IsNil)
+	c.Check(s.disconnected, HasLen, 0)
+}
+
+func (s *interfacesDenySuite) TestInterfacesDenyDisconnectError(c *C) {
+	restore := configcore.MockIfacestateDisconnectDenied(func(st *state.State, conn *interfaces.Connection) (*state.TaskSet, error) {
+		return nil, fmt.Errorf("boom")
+	})
+	defer restore()
+
+	err := configcore.Run(classicDev, &mockConf{
+		state:   s.state,
+		changes: map[string]interface{}{"interfaces.deny": "network"},
+	})
+	c.Assert(err, ErrorMatches, `cannot disconnect consumer:network core:network: boom`)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Check(s.state.Changes(), HasLen, 0)
+}
@@ -25,6 +25,7 @@ import (
 	"path/filepath"
 
 	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/gadget/quantity"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/osutil/sys"
 	"github.com/snapcore/snapd/sysconfig"
@@ -36,12 +37,88 @@ var sysChownPath = sys.ChownPath
 
 func init() {
 	supportedConfigurations["core.journal.persistent"] = true
+	supportedConfigurations["core.journal.system-max-use"] = true
 }
 
 func validateJournalSettings(tr ConfGetter) error {
 	return validateBoolFlag(tr, "journal.persistent")
 }
 
+func validateJournalSizeSettings(tr ConfGetter) error {
+	output, err := coreCfg(tr, "journal.system-max-use")
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		return nil
+	}
+	_, err = parseJournalSize(output)
+	return err
+}
+
+func parseJournalSize(sizeStr string) (quantity.Size, error) {
+	sz, err := quantity.ParseSize(sizeStr)
+	if err != nil {
+		return 0, fmt.Errorf("cannot parse journal.system-max-use: %v", err)
+	}
+	// journald enforces a minimum size of its own
+	if sz < quantity.SizeMiB {
+		return 0, fmt.Errorf("journal.system-max-use must be at least one megabyte")
+	}
+	return sz, nil
+}
+
+// handleJournalSizeConfiguration limits the disk space used by the
+// persistent journal with a journald configuration snippet.
+func handleJournalSizeConfiguration(_ sysconfig.Device, tr ConfGetter, opts *fsOnlyContext) error {
+	output, err := coreCfg(tr, "journal.system-max-use")
+	if err != nil {
+		return err
+	}
+
+	rootDir := dirs.GlobalRootDir
+	if opts != nil {
+		rootDir = opts.RootDir
+	}
+	dir := filepath.Join(rootDir, "/etc/systemd/journald.conf.d")
+	name := "00-snap-core.conf"
+
+	dirContent := make(map[string]osutil.FileState, 1)
+	if output != "" {
+		sz, err := parseJournalSize(output)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		dirContent[name] = &osutil.MemoryFileState{
+			Content: []byte(fmt.Sprintf("[Journal]\nSystemMaxUse=%d\n", sz)),
+			Mode:    0644,
+		}
+	}
+	changed, removed, err := osutil.EnsureDirState(dir, name, dirContent)
+	if err != nil {
+		return err
+	}
+
+	if opts == nil && (len(changed) > 0 || len(removed) > 0) {
+		// journald reads its configuration only when started, restart
+		// it unless it is so old that this breaks the services logging
+		// to it, see handleJournalConfiguration
+		err := systemd.EnsureAtLeast(236)
+		if err == nil {
+			sysd := systemd.NewUnderRoot(dirs.GlobalRootDir, systemd.SystemMode, nil)
+			return sysd.Restart([]string{"systemd-journald.service"})
+		} else if !systemd.IsSystemdTooOld(err) {
+			// systemd not available
+			return err
+		}
+	}
+
+	return nil
+}
+
 func handleJournalConfiguration(_ sysconfig.Device, tr ConfGetter, opts *fsOnlyContext) error {
 	output, err := coreCfg(tr, "journal.persistent")
 	if err != nil {
@@ -30,6 +30,7 @@ import (
 	"github.com/snapcore/snapd/osutil/sys"
 	"github.com/snapcore/snapd/overlord/configstate/configcore"
 	"github.com/snapcore/snapd/systemd"
+	"github.com/snapcore/snapd/testutil"
 )
 
 type journalSuite struct {
@@ -179,3 +180,83 @@ func (s *journalSuite) TestFilesystemOnlyApply(c *C) {
 	c.Assert(err, IsNil)
 	c.Check(exists, Equals, true)
 }
+
+func (s *journalSuite) TestConfigureJournalSystemMaxUseInvalid(c *C) {
+	for _, t := range []struct {
+		value, err string
+	}{
+		{"foo", `cannot parse journal.system-max-use: no numerical prefix`},
+		{"100K", `cannot parse journal.system-max-use: invalid suffix "K"`},
+		{"0", `journal.system-max-use must be at least one megabyte`},
+		{"-1M", `cannot parse journal.system-max-use: size cannot be negative`},
+	} {
+		err := configcore.FilesystemOnlyRun(coreDev, &mockConf{
+			state: s.state,
+			conf:  map[string]interface{}{"journal.system-max-use": t.value},
+		})
+		c.Check(err, ErrorMatches, t.err, Commentf(t.value))
+	}
+	c.Check(filepath.Join(dirs.GlobalRootDir, "/etc/systemd/journald.conf.d/00-snap-core.conf"), testutil.FileAbsent)
+}
+
+func (s *journalSuite) TestConfigureJournalSystemMaxUse(c *C) {
+	err := configcore.FilesystemOnlyRun(coreDev, &mockConf{
+		state: s.state,
+		conf:  map[string]interface{}{"journal.system-max-use": "200M"},
+	})
+	c.Assert(err, IsNil)
+
+	confPath := filepath.Join(dirs.GlobalRootDir, "/etc/systemd/journald.conf.d/00-snap-core.conf")
+	c.Check(confPath, testutil.FileEquals, "[Journal]\nSystemMaxUse=209715200\n")
+	c.Check(s.systemctlArgs, DeepEquals, [][]string{
+		{"--version"},
+		{"stop", "systemd-journald.service"},
+		{"show", "--property=ActiveState", "systemd-journald.service"},
+		{"start", "systemd-journald.service"},
+	})
+
+	// unchanged
+	s.systemctlArgs = nil
+	err = configcore.FilesystemOnlyRun(coreDev, &mockConf{
+		state: s.state,
+		conf:  map[string]interface{}{"journal.system-max-use": "200M"},
+	})
+	c.Assert(err, IsNil)
+	c.Check(s.systemctlArgs, HasLen, 0)
+
+	// unset
+	err = configcore.FilesystemOnlyRun(coreDev, &mockConf{
+		state: s.state,
+		conf:  map[string]interface{}{"journal.system-max-use": ""},
+	})
+	c.Assert(err, IsNil)
+	c.Check(confPath, testutil.FileAbsent)
+	c.Check(s.systemctlArgs, HasLen, 4)
+}
+
+func (s *journalSuite) TestConfigureJournalSystemMaxUseOldSystemd(c *C) {
+	s.systemdVersion = "235"
+
+	err := configcore.FilesystemOnlyRun(coreDev, &mockConf{
+		state: s.state,
+		conf:  map[string]interface{}{"journal.system-max-use": "1G"},
+	})
+	c.Assert(err, IsNil)
+
+	c.Check(filepath.Join(dirs.GlobalRootDir, "/etc/systemd/journald.conf.d/00-snap-core.conf"), testutil.FileEquals, "[Journal]\nSystemMaxUse=1073741824\n")
+	// applied on the next start of journald only
+	c.Check(s.systemctlArgs, DeepEquals, [][]string{
+		{"--version"},
+	})
+}
+
+func (s *journalSuite) TestFilesystemOnlyApplySystemMaxUse(c *C) {
+	conf := configcore.PlainCoreConfig(map[string]interface{}{
+		"journal.system-max-use": "64M",
+	})
+	tmpDir := c.MkDir()
+	c.Assert(configcore.FilesystemOnlyApply(coreDev, tmpDir, conf), IsNil)
+	c.Check(s.systemctlArgs, HasLen, 0)
+
+	c.Check(filepath.Join(tmpDir, "/etc/systemd/journald.conf.d/00-snap-core.conf"), testutil.FileEquals, "[Journal]\nSystemMaxUse=67108864\n")
+}
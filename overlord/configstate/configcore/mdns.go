@@ -0,0 +1,51 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//go:build !nomanagers
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package configcore
+
+import (
+	"fmt"
+
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/strutil"
+)
+
+func init() {
+	// comma-separated list of the snaps whose services are advertised
+	// on the local network through mDNS
+	supportedConfigurations["core.mdns.advertise"] = true
+}
+
+// validateMDNSAdvertise validates the list of snaps allowed to advertise
+// their services, which is applied by snapstate the next time the services
+// of a snap are started.
+func validateMDNSAdvertise(tr RunTransaction) error {
+	value, err := coreCfg(tr, "mdns.advertise")
+	if err != nil {
+		return err
+	}
+
+	for _, name := range strutil.CommaSeparatedList(value) {
+		if err := snap.ValidateInstanceName(name); err != nil {
+			return fmt.Errorf("cannot set mdns.advertise: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package configcore_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/overlord/configstate/configcore"
+)
+
+type mdnsSuite struct {
+	configcoreSuite
+}
+
+var _ = Suite(&mdnsSuite{})
+
+func (s *mdnsSuite) TestMDNSAdvertiseValid(c *C) {
+	for _, value := range []string{"", "foo", "foo,bar_instance", "foo, bar"} {
+		err := configcore.Run(classicDev, &mockConf{
+			state: s.state,
+			conf:  map[string]interface{}{"mdns.advertise": value},
+		})
+		c.Check(err, IsNil, Commentf("%q", value))
+	}
+}
+
+func (s *mdnsSuite) TestMDNSAdvertiseInvalid(c *C) {
+	for _, tc := range []struct {
+		value  string
+		errStr string
+	}{
+		{"Foo", `cannot set mdns.advertise: invalid snap name: "Foo"`},
+		{"foo,-bar", `cannot set mdns.advertise: invalid snap name: "-bar"`},
+		{"foo_", `cannot set mdns.advertise: invalid instance key: ""`},
+	} {
+		err := configcore.Run(classicDev, &mockConf{
+			state: s.state,
+			conf:  map[string]interface{}{"mdns.advertise": tc.value},
+		})
+		c.Check(err, ErrorMatches, tc.errStr)
+	}
+}
@@ -0,0 +1,47 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//go:build !nomanagers
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+package configcore
+
+func init() {
+	// when set, installing bases and default content providers as
+	// prerequisites of a snap needs to be confirmed by the client
+	supportedConfigurations["core.prerequisites.confirm"] = true
+}
+
+func validatePrerequisitesSettings(tr RunTransaction) error {
+	return validateBoolFlag(tr, "prerequisites.confirm")
+}
+
+func handlePrerequisitesSettings(tr RunTransaction, opts *fsOnlyContext) error {
+	output, err := coreCfg(tr, "prerequisites.confirm")
+	if err != nil {
+		return err
+	}
+
+	// normalize the value so that it can be read as a boolean
+	switch output {
+	case "true":
+		tr.Set("core", "prerequisites.confirm", true)
+	case "false":
+		tr.Set("core", "prerequisites.confirm", false)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//go:build !nomanagers
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+package configcore_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/overlord/configstate/configcore"
+)
+
+type prerequisitesSuite struct {
+	configcoreSuite
+}
+
+var _ = Suite(&prerequisitesSuite{})
+
+func (s *prerequisitesSuite) TestPrerequisitesConfirmInvalid(c *C) {
+	err := configcore.Run(classicDev, &mockConf{
+		state: s.state,
+		conf:  map[string]interface{}{"prerequisites.confirm": "maybe"},
+	})
+	c.Assert(err, ErrorMatches, `prerequisites.confirm can only be set to 'true' or 'false'`)
+}
+
+func (s *prerequisitesSuite) TestPrerequisitesConfirmConfigure(c *C) {
+	tests := []struct {
+		value    interface{}
+		expected bool
+	}{
+		{"true", true},
+		{"false", false},
+		{true, true},
+		{false, false},
+	}
+
+	for _, t := range tests {
+		conf := &mockConf{
+			state: s.state,
+			conf:  map[string]interface{}{"prerequisites.confirm": t.value},
+		}
+
+		err := configcore.Run(classicDev, conf)
+		c.Assert(err, IsNil)
+
+		c.Check(conf.conf["prerequisites.confirm"], Equals, t.expected)
+	}
+}
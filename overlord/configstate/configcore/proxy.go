@@ -53,6 +53,7 @@ func init() {
 	supportedConfigurations["core.proxy.ftp"] = true
 	supportedConfigurations["core.proxy.no-proxy"] = true
 	supportedConfigurations["core.proxy.store"] = true
+	supportedConfigurations["core.proxy.store-discovery"] = true
 }
 
 func etcEnvironment() string {
@@ -108,6 +109,10 @@ func handleProxyConfiguration(tr RunTransaction, opts *fsOnlyContext) error {
 }
 
 func validateProxyStore(tr RunTransaction) error {
+	if err := validateBoolFlag(tr, "proxy.store-discovery"); err != nil {
+		return err
+	}
+
 	proxyStore, err := coreCfg(tr, "proxy.store")
 	if err != nil {
 		return err
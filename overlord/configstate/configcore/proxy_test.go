@@ -237,3 +237,23 @@ func (s *proxySuite) TestConfigureProxyStoreNoURL(c *C) {
 	err = configcore.Run(coreDev, conf)
 	c.Check(err, ErrorMatches, `cannot set proxy.store to "foo" with a matching store assertion with url unset`)
 }
+
+func (s *proxySuite) TestConfigureProxyStoreDiscovery(c *C) {
+	for _, v := range []interface{}{true, false, "true", ""} {
+		err := configcore.Run(coreDev, &mockConf{
+			state: s.state,
+			conf: map[string]interface{}{
+				"proxy.store-discovery": v,
+			},
+		})
+		c.Check(err, IsNil)
+	}
+
+	err := configcore.Run(coreDev, &mockConf{
+		state: s.state,
+		conf: map[string]interface{}{
+			"proxy.store-discovery": "dhcp",
+		},
+	})
+	c.Check(err, ErrorMatches, `proxy.store-discovery can only be set to 'true' or 'false'`)
+}
@@ -0,0 +1,74 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package configcore
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/sysconfig"
+)
+
+const (
+	optionSnapdReexec     = "snapd.reexec"
+	coreOptionSnapdReexec = "core." + optionSnapdReexec
+)
+
+func init() {
+	supportedConfigurations[coreOptionSnapdReexec] = true
+}
+
+func validateSnapdReexecSetting(tr ConfGetter) error {
+	return validateBoolFlag(tr, optionSnapdReexec)
+}
+
+// handleSnapdReexecConfiguration records whether snap and snapd should
+// re-execute into the snapd snap on classic systems. It takes effect the next
+// time they are started.
+func handleSnapdReexecConfiguration(dev sysconfig.Device, tr ConfGetter, opts *fsOnlyContext) error {
+	if !dev.Classic() {
+		// there is no re-exec on Ubuntu Core
+		return nil
+	}
+
+	reexec, err := coreCfg(tr, optionSnapdReexec)
+	if err != nil {
+		return err
+	}
+
+	disabledFile := dirs.SnapdReexecDisabledFile
+	if opts != nil {
+		disabledFile = filepath.Join(dirs.SnapdStateDir(opts.RootDir), filepath.Base(dirs.SnapdReexecDisabledFile))
+	}
+	if reexec == "false" {
+		if err := os.MkdirAll(filepath.Dir(disabledFile), 0755); err != nil {
+			return err
+		}
+		return osutil.EnsureFileState(disabledFile, &osutil.MemoryFileState{
+			Mode: 0644,
+		})
+	}
+	if err := os.Remove(disabledFile); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package configcore_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/overlord/configstate/configcore"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type reexecSuite struct {
+	configcoreSuite
+}
+
+var _ = Suite(&reexecSuite{})
+
+func (s *reexecSuite) SetUpTest(c *C) {
+	s.configcoreSuite.SetUpTest(c)
+
+	err := os.MkdirAll(filepath.Join(dirs.GlobalRootDir, "/etc/"), 0755)
+	c.Assert(err, IsNil)
+	err = os.WriteFile(filepath.Join(dirs.GlobalRootDir, "/etc/environment"), nil, 0644)
+	c.Assert(err, IsNil)
+}
+
+func (s *reexecSuite) TestConfigureSnapdReexec(c *C) {
+	err := configcore.Run(classicDev, &mockConf{
+		state: s.state,
+		conf: map[string]interface{}{
+			"snapd.reexec": false,
+		},
+	})
+	c.Assert(err, IsNil)
+	c.Check(dirs.SnapdReexecDisabledFile, testutil.FilePresent)
+
+	for _, reexec := range []interface{}{true, ""} {
+		err = configcore.Run(classicDev, &mockConf{
+			state: s.state,
+			conf: map[string]interface{}{
+				"snapd.reexec": reexec,
+			},
+		})
+		c.Assert(err, IsNil)
+		c.Check(dirs.SnapdReexecDisabledFile, testutil.FileAbsent)
+	}
+}
+
+func (s *reexecSuite) TestConfigureSnapdReexecIgnoredOnCore(c *C) {
+	err := configcore.Run(coreDev, &mockConf{
+		state: s.state,
+		conf: map[string]interface{}{
+			"snapd.reexec": false,
+		},
+	})
+	c.Assert(err, IsNil)
+	c.Check(dirs.SnapdReexecDisabledFile, testutil.FileAbsent)
+}
+
+func (s *reexecSuite) TestConfigureSnapdReexecInvalid(c *C) {
+	err := configcore.Run(classicDev, &mockConf{
+		state: s.state,
+		conf: map[string]interface{}{
+			"snapd.reexec": "maybe",
+		},
+	})
+	c.Assert(err, ErrorMatches, `snapd.reexec can only be set to 'true' or 'false'`)
+}
@@ -23,8 +23,10 @@ package configcore
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/devicestate"
 	"github.com/snapcore/snapd/strutil"
 	"github.com/snapcore/snapd/timeutil"
@@ -33,6 +35,8 @@ import (
 const (
 	minInhibitionDays = 1
 	maxInhibitionDays = 21
+
+	maxPreferIdleMinutes = 24 * 60
 )
 
 func init() {
@@ -41,8 +45,14 @@ func init() {
 	supportedConfigurations["core.refresh.timer"] = true
 	supportedConfigurations["core.refresh.metered"] = true
 	supportedConfigurations["core.refresh.retain"] = true
+	// refresh.snap-retain.<snap> is checked in applyHandlers
+	supportedConfigurations["core.refresh.snap-retain"] = true
 	supportedConfigurations["core.refresh.rate-limit"] = true
 	supportedConfigurations["core.refresh.max-inhibition-days"] = true
+	supportedConfigurations["core.refresh.closed-track"] = true
+	supportedConfigurations["core.refresh.snapd-canary-delay"] = true
+	supportedConfigurations["core.refresh.prefer-idle-minutes"] = true
+	supportedConfigurations["core.refresh.unhealthy-revert-window"] = true
 }
 
 func reportOrIgnoreInvalidManageRefreshes(tr RunTransaction, optName string) error {
@@ -68,6 +78,15 @@ func validateRefreshSchedule(tr RunTransaction) error {
 			return fmt.Errorf("max-inhibition-days must be a number between %d and %d, not %q", minInhibitionDays, maxInhibitionDays, maxInhibitionDaysStr)
 		}
 	}
+	preferIdleStr, err := coreCfg(tr, "refresh.prefer-idle-minutes")
+	if err != nil {
+		return err
+	}
+	if preferIdleStr != "" {
+		if n, err := strconv.ParseUint(preferIdleStr, 10, 16); err != nil || (n < 1 || n > maxPreferIdleMinutes) {
+			return fmt.Errorf("prefer-idle-minutes must be a number between 1 and %d, not %q", maxPreferIdleMinutes, preferIdleStr)
+		}
+	}
 	refreshRetainStr, err := coreCfg(tr, "refresh.retain")
 	if err != nil {
 		return err
@@ -99,6 +118,39 @@ func validateRefreshSchedule(tr RunTransaction) error {
 		return fmt.Errorf("refresh.metered value %q is invalid", refreshOnMeteredStr)
 	}
 
+	closedTrackStr, err := coreCfg(tr, "refresh.closed-track")
+	if err != nil {
+		return err
+	}
+	switch closedTrackStr {
+	case "", "warn", "switch":
+		// noop
+	default:
+		return fmt.Errorf("refresh.closed-track value %q is invalid", closedTrackStr)
+	}
+
+	canaryDelayStr, err := coreCfg(tr, "refresh.snapd-canary-delay")
+	if err != nil {
+		return err
+	}
+	if canaryDelayStr != "" {
+		canaryDelay, err := time.ParseDuration(canaryDelayStr)
+		if err != nil || canaryDelay < 0 {
+			return fmt.Errorf("refresh.snapd-canary-delay value %q is invalid", canaryDelayStr)
+		}
+	}
+
+	revertWindowStr, err := coreCfg(tr, "refresh.unhealthy-revert-window")
+	if err != nil {
+		return err
+	}
+	if revertWindowStr != "" {
+		revertWindow, err := time.ParseDuration(revertWindowStr)
+		if err != nil || revertWindow < 0 {
+			return fmt.Errorf("refresh.unhealthy-revert-window value %q is invalid", revertWindowStr)
+		}
+	}
+
 	// check (new) refresh.timer
 	refreshTimerStr, err := coreCfg(tr, "refresh.timer")
 	if err != nil {
@@ -149,6 +201,38 @@ func validateRefreshSchedule(tr RunTransaction) error {
 	return err
 }
 
+// validateRefreshSnapRetain checks the refresh.snap-retain.<snap> options,
+// which override refresh.retain for the given snaps.
+func validateRefreshSnapRetain(tr RunTransaction) error {
+	for _, name := range tr.Changes() {
+		if name == "core.refresh.snap-retain" {
+			var v interface{}
+			if err := tr.Get("core", "refresh.snap-retain", &v); err != nil && !config.IsNoOption(err) {
+				return err
+			}
+			if _, ok := v.(map[string]interface{}); v != nil && !ok {
+				return fmt.Errorf("refresh.snap-retain must be set per snap, e.g. refresh.snap-retain.<snap>=<number>")
+			}
+			continue
+		}
+		if !strings.HasPrefix(name, "core.refresh.snap-retain.") {
+			continue
+		}
+		key := strings.TrimPrefix(name, "core.")
+		retainStr, err := coreCfg(tr, key)
+		if err != nil {
+			return err
+		}
+		if retainStr == "" {
+			continue
+		}
+		if n, err := strconv.ParseUint(retainStr, 10, 8); err != nil || (n < 2 || n > 20) {
+			return fmt.Errorf("%s must be a number between 2 and 20, not %q", key, retainStr)
+		}
+	}
+	return nil
+}
+
 func validateRefreshRateLimit(tr RunTransaction) error {
 	refreshRateLimit, err := coreCfg(tr, "refresh.rate-limit")
 	if err != nil {
@@ -21,6 +21,7 @@
 package configcore_test
 
 import (
+	"fmt"
 	"time"
 
 	. "gopkg.in/check.v1"
@@ -181,6 +182,100 @@ func (s *refreshSuite) TestConfigureRefreshHoldOnMeteredHappy(c *C) {
 	c.Assert(err, IsNil)
 }
 
+func (s *refreshSuite) TestConfigureRefreshClosedTrackInvalid(c *C) {
+	err := configcore.Run(classicDev, &mockConf{
+		state: s.state,
+		conf: map[string]interface{}{
+			"refresh.closed-track": "ignore",
+		},
+	})
+	c.Assert(err, ErrorMatches, `refresh\.closed-track value "ignore" is invalid`)
+}
+
+func (s *refreshSuite) TestConfigureRefreshClosedTrackHappy(c *C) {
+	for _, policy := range []string{"warn", "switch", ""} {
+		err := configcore.Run(classicDev, &mockConf{
+			state: s.state,
+			conf: map[string]interface{}{
+				"refresh.closed-track": policy,
+			},
+		})
+		c.Assert(err, IsNil)
+	}
+}
+
+func (s *refreshSuite) TestConfigureRefreshSnapdCanaryDelayInvalid(c *C) {
+	for _, delay := range []string{"soon", "-1h", "10"} {
+		err := configcore.Run(classicDev, &mockConf{
+			state: s.state,
+			conf: map[string]interface{}{
+				"refresh.snapd-canary-delay": delay,
+			},
+		})
+		c.Check(err, ErrorMatches, fmt.Sprintf(`refresh\.snapd-canary-delay value %q is invalid`, delay))
+	}
+}
+
+func (s *refreshSuite) TestConfigureRefreshSnapdCanaryDelayHappy(c *C) {
+	for _, delay := range []string{"0s", "30m", "6h", ""} {
+		err := configcore.Run(classicDev, &mockConf{
+			state: s.state,
+			conf: map[string]interface{}{
+				"refresh.snapd-canary-delay": delay,
+			},
+		})
+		c.Assert(err, IsNil)
+	}
+}
+
+func (s *refreshSuite) TestConfigureRefreshUnhealthyRevertWindowInvalid(c *C) {
+	for _, window := range []string{"soon", "-1h", "10"} {
+		err := configcore.Run(classicDev, &mockConf{
+			state: s.state,
+			conf: map[string]interface{}{
+				"refresh.unhealthy-revert-window": window,
+			},
+		})
+		c.Check(err, ErrorMatches, fmt.Sprintf(`refresh\.unhealthy-revert-window value %q is invalid`, window))
+	}
+}
+
+func (s *refreshSuite) TestConfigureRefreshUnhealthyRevertWindowHappy(c *C) {
+	for _, window := range []string{"0s", "10m", "1h", ""} {
+		err := configcore.Run(classicDev, &mockConf{
+			state: s.state,
+			conf: map[string]interface{}{
+				"refresh.unhealthy-revert-window": window,
+			},
+		})
+		c.Assert(err, IsNil)
+	}
+}
+
+func (s *refreshSuite) TestConfigureRefreshPreferIdleMinutesHappy(c *C) {
+	for _, minutes := range []string{"1", "30", "1440", ""} {
+		err := configcore.Run(classicDev, &mockConf{
+			state: s.state,
+			conf: map[string]interface{}{
+				"refresh.prefer-idle-minutes": minutes,
+			},
+		})
+		c.Assert(err, IsNil)
+	}
+}
+
+func (s *refreshSuite) TestConfigureRefreshPreferIdleMinutesInvalid(c *C) {
+	for _, minutes := range []string{"0", "1441", "-1", "1h", "x"} {
+		err := configcore.Run(classicDev, &mockConf{
+			state: s.state,
+			conf: map[string]interface{}{
+				"refresh.prefer-idle-minutes": minutes,
+			},
+		})
+		c.Check(err, ErrorMatches, fmt.Sprintf(`prefer-idle-minutes must be a number between 1 and 1440, not %q`, minutes))
+	}
+}
+
 func (s *refreshSuite) TestConfigureRefreshRetainHappy(c *C) {
 	err := configcore.Run(classicDev, &mockConf{
 		state: s.state,
@@ -221,6 +316,31 @@ func (s *refreshSuite) TestConfigureRefreshRetainInvalid(c *C) {
 	c.Assert(err, ErrorMatches, `retain must be a number between 2 and 20, not "invalid"`)
 }
 
+func (s *refreshSuite) TestConfigureRefreshSnapRetain(c *C) {
+	for _, t := range []struct {
+		conf map[string]interface{}
+		err  string
+	}{
+		{map[string]interface{}{"refresh.snap-retain.some-snap": "4"}, ""},
+		{map[string]interface{}{"refresh.snap-retain.some-snap_foo": 20}, ""},
+		{map[string]interface{}{"refresh.snap-retain": map[string]interface{}{}}, ""},
+		{map[string]interface{}{"refresh.snap-retain.some-snap": "1"}, `refresh.snap-retain.some-snap must be a number between 2 and 20, not "1"`},
+		{map[string]interface{}{"refresh.snap-retain.some-snap": "invalid"}, `refresh.snap-retain.some-snap must be a number between 2 and 20, not "invalid"`},
+		{map[string]interface{}{"refresh.snap-retain": "4"}, `refresh.snap-retain must be set per snap, e.g. refresh.snap-retain.<snap>=<number>`},
+		{map[string]interface{}{"refresh.snap-retain.Some-Snap": "4"}, `cannot set "core.refresh.snap-retain.Some-Snap": invalid snap name: "Some-Snap"`},
+	} {
+		err := configcore.Run(classicDev, &mockConf{
+			state:   s.state,
+			changes: t.conf,
+		})
+		if t.err == "" {
+			c.Check(err, IsNil, Commentf("%v", t.conf))
+		} else {
+			c.Check(err, ErrorMatches, t.err, Commentf("%v", t.conf))
+		}
+	}
+}
+
 func (s *refreshSuite) TestConfigureRefreshMaxInhibitionDays(c *C) {
 	data := []struct {
 		val interface{}
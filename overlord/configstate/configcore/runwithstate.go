@@ -27,6 +27,7 @@ import (
 	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/release"
+	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/sysconfig"
 )
 
@@ -56,10 +57,23 @@ func init() {
 	// users.create.automatic
 	addWithStateHandler(validateUsersSettings, handleUserSettings, &flags{earlyConfigFilter: earlyUsersSettingsFilter})
 
+	// prerequisites.confirm
+	addWithStateHandler(validatePrerequisitesSettings, handlePrerequisitesSettings, nil)
+
+	// firewall-control-lite
+	addWithStateHandler(validateFirewallSettings, handleFirewallSettings, nil)
+
 	validateOnly := &flags{validatedOnlyStateConfig: true}
 	addWithStateHandler(validateRefreshSchedule, nil, validateOnly)
 	addWithStateHandler(validateRefreshRateLimit, nil, validateOnly)
+	addWithStateHandler(validateRefreshSnapRetain, nil, validateOnly)
+	addWithStateHandler(validateStoreDownloadParallel, nil, validateOnly)
+	addWithStateHandler(validateStoreMetadataCache, nil, validateOnly)
 	addWithStateHandler(validateAutomaticSnapshotsExpiration, nil, validateOnly)
+	addWithStateHandler(validateAutomaticPreRefreshSnapshots, nil, validateOnly)
+	addWithStateHandler(validateTmpSnapSize, nil, validateOnly)
+	addWithStateHandler(validateMDNSAdvertise, nil, validateOnly)
+	addWithStateHandler(validateHooksTimeout, nil, validateOnly)
 
 	// netplan.*
 	addWithStateHandler(validateNetplanSettings, handleNetplanConfiguration, coreOnly)
@@ -75,6 +89,9 @@ func init() {
 
 	// experimental.apparmor-prompting
 	addWithStateHandler(nil, doExperimentalApparmorPromptingDaemonRestart, nil)
+
+	// interfaces.deny
+	addWithStateHandler(validateInterfacesDeny, handleInterfacesDeny, nil)
 }
 
 // RunTransaction is an interface describing how to access
@@ -165,6 +182,10 @@ func applyHandlers(dev sysconfig.Device, cfg RunTransaction, handlers []configHa
 			if !validCertOption(k) {
 				return fmt.Errorf("cannot set store ssl certificate under name %q: name must only contain word characters or a dash", k)
 			}
+		case strings.HasPrefix(k, "core.refresh.snap-retain."):
+			if err := snap.ValidateInstanceName(strings.TrimPrefix(k, "core.refresh.snap-retain.")); err != nil {
+				return fmt.Errorf("cannot set %q: %v", k, err)
+			}
 		case isNetplanChange(k):
 			if release.OnClassic {
 				return fmt.Errorf("cannot set netplan configuration on classic")
@@ -28,6 +28,11 @@ import (
 func init() {
 	// add supported configuration of this module
 	supportedConfigurations["core.snapshots.automatic.retention"] = true
+	supportedConfigurations["core.snapshots.automatic.pre-refresh"] = true
+}
+
+func validateAutomaticPreRefreshSnapshots(tr RunTransaction) error {
+	return validateBoolFlag(tr, "snapshots.automatic.pre-refresh")
 }
 
 func validateAutomaticSnapshotsExpiration(tr RunTransaction) error {
@@ -71,3 +71,25 @@ func (s *refreshSuite) TestConfigureAutomaticSnapshotsExpirationInvalid(c *C) {
 	})
 	c.Assert(err, ErrorMatches, `snapshots.automatic.retention cannot be parsed:.*`)
 }
+
+func (s *snapshotsSuite) TestConfigureAutomaticPreRefreshSnapshotsHappy(c *C) {
+	for _, v := range []interface{}{"true", "false", true, false} {
+		err := configcore.Run(classicDev, &mockConf{
+			state: s.state,
+			conf: map[string]interface{}{
+				"snapshots.automatic.pre-refresh": v,
+			},
+		})
+		c.Assert(err, IsNil)
+	}
+}
+
+func (s *snapshotsSuite) TestConfigureAutomaticPreRefreshSnapshotsInvalid(c *C) {
+	err := configcore.Run(classicDev, &mockConf{
+		state: s.state,
+		conf: map[string]interface{}{
+			"snapshots.automatic.pre-refresh": "maybe",
+		},
+	})
+	c.Assert(err, ErrorMatches, `snapshots.automatic.pre-refresh can only be set to 'true' or 'false'`)
+}
@@ -22,16 +22,30 @@ package configcore
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"time"
 
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/sysconfig"
 )
 
+// maxStoreDownloadParallel is the largest number of concurrent requests
+// the download of a single snap can be split into.
+const maxStoreDownloadParallel = 8
+
+// maxStoreMetadataCacheSize is the largest number of store metadata
+// responses that can be cached.
+const maxStoreMetadataCacheSize = 1000
+
 func init() {
 	supportedConfigurations["core.store.access"] = true
+	supportedConfigurations["core.store.download-parallel"] = true
+	supportedConfigurations["core.store.metadata-cache-size"] = true
+	supportedConfigurations["core.store.metadata-cache-ttl"] = true
 }
 
 func validateStoreAccess(cfg ConfGetter) error {
@@ -48,6 +62,42 @@ func validateStoreAccess(cfg ConfGetter) error {
 	}
 }
 
+func validateStoreDownloadParallel(tr RunTransaction) error {
+	parallelStr, err := coreCfg(tr, "store.download-parallel")
+	if err != nil {
+		return err
+	}
+	if parallelStr == "" {
+		return nil
+	}
+	if n, err := strconv.ParseUint(parallelStr, 10, 8); err != nil || n < 1 || n > maxStoreDownloadParallel {
+		return fmt.Errorf("store.download-parallel must be a number between 1 and %d, not %q", maxStoreDownloadParallel, parallelStr)
+	}
+	return nil
+}
+
+func validateStoreMetadataCache(tr RunTransaction) error {
+	sizeStr, err := coreCfg(tr, "store.metadata-cache-size")
+	if err != nil {
+		return err
+	}
+	if sizeStr != "" {
+		if n, err := strconv.ParseUint(sizeStr, 10, 16); err != nil || n > maxStoreMetadataCacheSize {
+			return fmt.Errorf("store.metadata-cache-size must be a number between 0 and %d, not %q", maxStoreMetadataCacheSize, sizeStr)
+		}
+	}
+	ttlStr, err := coreCfg(tr, "store.metadata-cache-ttl")
+	if err != nil {
+		return err
+	}
+	if ttlStr != "" {
+		if ttl, err := time.ParseDuration(ttlStr); err != nil || ttl < 0 {
+			return fmt.Errorf("store.metadata-cache-ttl value %q is invalid", ttlStr)
+		}
+	}
+	return nil
+}
+
 // repairConfig is a set of configuration data that is consumed by the
 // snap-repair command. This struct is duplicated in cmd/snap-repair.
 type repairConfig struct {
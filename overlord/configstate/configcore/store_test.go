@@ -96,3 +96,61 @@ func (s *storeSuite) TestFilesystemOnlyApply(c *C) {
 
 	c.Check(repairConfig.StoreOffline, Equals, true)
 }
+
+func (s *storeSuite) TestStoreDownloadParallelHappy(c *C) {
+	for _, parallel := range []interface{}{"", 1, 4, 8} {
+		err := configcore.Run(classicDev, &mockConf{
+			state: s.state,
+			conf: map[string]interface{}{
+				"store.download-parallel": parallel,
+			},
+		})
+		c.Assert(err, IsNil)
+	}
+}
+
+func (s *storeSuite) TestStoreDownloadParallelUnhappy(c *C) {
+	for _, parallel := range []interface{}{0, 9, -1, "many"} {
+		err := configcore.Run(classicDev, &mockConf{
+			state: s.state,
+			conf: map[string]interface{}{
+				"store.download-parallel": parallel,
+			},
+		})
+		c.Assert(err, ErrorMatches, `store.download-parallel must be a number between 1 and 8, not ".*"`)
+	}
+}
+
+func (s *storeSuite) TestStoreMetadataCacheHappy(c *C) {
+	for _, conf := range []map[string]interface{}{
+		{"store.metadata-cache-size": 0},
+		{"store.metadata-cache-size": 1000},
+		{"store.metadata-cache-ttl": "0s"},
+		{"store.metadata-cache-ttl": "1h"},
+		{"store.metadata-cache-size": "", "store.metadata-cache-ttl": ""},
+	} {
+		err := configcore.Run(classicDev, &mockConf{
+			state: s.state,
+			conf:  conf,
+		})
+		c.Assert(err, IsNil)
+	}
+}
+
+func (s *storeSuite) TestStoreMetadataCacheUnhappy(c *C) {
+	for _, tc := range []struct {
+		conf   map[string]interface{}
+		errMsg string
+	}{
+		{map[string]interface{}{"store.metadata-cache-size": -1}, `store.metadata-cache-size must be a number between 0 and 1000, not "-1"`},
+		{map[string]interface{}{"store.metadata-cache-size": 1001}, `store.metadata-cache-size must be a number between 0 and 1000, not "1001"`},
+		{map[string]interface{}{"store.metadata-cache-ttl": "-1m"}, `store.metadata-cache-ttl value "-1m" is invalid`},
+		{map[string]interface{}{"store.metadata-cache-ttl": "soon"}, `store.metadata-cache-ttl value "soon" is invalid`},
+	} {
+		err := configcore.Run(classicDev, &mockConf{
+			state: s.state,
+			conf:  tc.conf,
+		})
+		c.Assert(err, ErrorMatches, tc.errMsg)
+	}
+}
@@ -42,6 +42,7 @@ const (
 func init() {
 	// add supported configuration of this module
 	supportedConfigurations["core.tmp.size"] = true
+	supportedConfigurations["core.tmp.snap-size"] = true
 }
 
 func validTmpfsSize(sizeStr string) error {
@@ -74,6 +75,17 @@ func validateTmpfsSettings(tr ConfGetter) error {
 	return validTmpfsSize(tmpfsSz)
 }
 
+// validateTmpSnapSize validates the size of the private /tmp of
+// snaps, which is applied by snapstate the next time a snap is linked.
+func validateTmpSnapSize(tr RunTransaction) error {
+	tmpfsSz, err := coreCfg(tr, "tmp.snap-size")
+	if err != nil {
+		return err
+	}
+
+	return validTmpfsSize(tmpfsSz)
+}
+
 func handleTmpfsConfiguration(_ sysconfig.Device, tr ConfGetter, opts *fsOnlyContext) error {
 	tmpfsSz, err := coreCfg(tr, "tmp.size")
 	if err != nil {
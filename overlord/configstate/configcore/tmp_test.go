@@ -220,3 +220,37 @@ func (s *tmpfsSuite) TestFilesystemOnlyApply(c *C) {
 	c.Check(tmpfsOverrCfg, testutil.FileEquals,
 		"[Mount]\nOptions=mode=1777,strictatime,nosuid,nodev,size=16777216\n")
 }
+
+func (s *tmpfsSuite) TestConfigureTmpSnapSize(c *C) {
+	for _, size := range []string{"", "0", "16M", "1G"} {
+		err := configcore.Run(classicDev, &mockConf{
+			state: s.state,
+			conf: map[string]interface{}{
+				"tmp.snap-size": size,
+			},
+		})
+		c.Assert(err, IsNil)
+	}
+
+	for _, tc := range []struct {
+		size   string
+		errStr string
+	}{
+		{"10k", `invalid suffix .*`},
+		{"20%", `invalid suffix .*`},
+		{"1M", `size is less than 16Mb`},
+	} {
+		err := configcore.Run(classicDev, &mockConf{
+			state: s.state,
+			conf: map[string]interface{}{
+				"tmp.snap-size": tc.size,
+			},
+		})
+		c.Check(err, ErrorMatches, tc.errStr)
+	}
+
+	// the size of the private /tmp of snaps does not touch the system tmpfs
+	_, err := os.Stat(s.servOverridePath)
+	c.Assert(os.IsNotExist(err), Equals, true)
+	c.Assert(s.systemctlArgs, IsNil)
+}
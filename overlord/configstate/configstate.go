@@ -26,6 +26,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/snapcore/snapd/gadget"
@@ -100,11 +101,38 @@ func ConfigureInstalled(st *state.State, snapName string, patch map[string]inter
 	if err := canConfigure(st, snapName); err != nil {
 		return nil, err
 	}
+	if err := validatePatch(st, snapName, patch); err != nil {
+		return nil, err
+	}
 
 	taskset := Configure(st, snapName, patch, flags)
 	return taskset, nil
 }
 
+// validatePatch checks the configuration patch against the configuration
+// schema shipped by the snap in meta/config-schema.json, if any, so that
+// invalid values are reported before running the configure hook.
+func validatePatch(st *state.State, snapName string, patch map[string]interface{}) error {
+	if snapName == "core" || len(patch) == 0 {
+		return nil
+	}
+	var snapst snapstate.SnapState
+	if err := snapstate.Get(st, snapName, &snapst); err != nil {
+		return err
+	}
+	// the schema is read from the mounted snap directly, as it is not
+	// part of the snap metadata
+	mountDir := snap.MinimalPlaceInfo(snapName, snapst.Current).MountDir()
+	schema, err := config.ReadSchema(filepath.Join(mountDir, "meta", "config-schema.json"))
+	if err != nil {
+		return fmt.Errorf("cannot validate configuration of snap %q: %v", snapName, err)
+	}
+	if schema == nil {
+		return nil
+	}
+	return schema.ValidatePatch(snapName, patch)
+}
+
 // Configure returns a taskset to apply the given configuration patch.
 func Configure(st *state.State, snapName string, patch map[string]interface{}, flags int) *state.TaskSet {
 	summary := fmt.Sprintf(i18n.G("Run configure hook of %q snap"), snapName)
@@ -164,6 +192,23 @@ func RemapSnapToResponse(snapName string) string {
 	return snapName
 }
 
+// EffectiveConfig returns the current configuration of the snap together
+// with the gadget defaults for it, telling for each option whether it is set
+// to its default. See config.EffectiveConfig.
+// The caller is responsible for locking the state.
+func EffectiveConfig(st *state.State, snapName string) ([]config.EffectiveValue, error) {
+	var defaults map[string]interface{}
+	deviceCtx, err := snapstate.DeviceCtx(st, nil, nil)
+	if err == nil {
+		defaults, err = snapstate.ConfigDefaults(st, deviceCtx, snapName)
+	}
+	// no model, gadget or defaults for the snap
+	if err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, err
+	}
+	return config.EffectiveConfig(st, snapName, defaults)
+}
+
 func delayedCrossMgrInit() {
 	devicestate.EarlyConfig = EarlyConfig
 }
@@ -21,7 +21,11 @@
 package configstate_test
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"time"
 
 	. "gopkg.in/check.v1"
@@ -226,6 +230,50 @@ func (s *tasksetsSuite) TestConfigureInstalledDenySnapd(c *C) {
 	c.Check(err, ErrorMatches, `cannot configure the "snapd" snap, please use "system" instead`)
 }
 
+func (s *tasksetsSuite) TestConfigureInstalledConfigSchema(c *C) {
+	dirs.SetRootDir(c.MkDir())
+	defer dirs.SetRootDir("")
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	snapstate.Set(s.state, "test-snap", &snapstate.SnapState{
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "test-snap", Revision: snap.R(1)},
+		}),
+		Current:  snap.R(1),
+		Active:   true,
+		SnapType: "app",
+	})
+
+	// no schema, anything goes
+	patch := map[string]interface{}{"port": "http"}
+	_, err := configstate.ConfigureInstalled(s.state, "test-snap", patch, 0)
+	c.Assert(err, IsNil)
+
+	schemaPath := filepath.Join(dirs.SnapMountDir, "test-snap/1/meta/config-schema.json")
+	c.Assert(os.MkdirAll(filepath.Dir(schemaPath), 0755), IsNil)
+	c.Assert(os.WriteFile(schemaPath, []byte(`{"properties": {"port": {"type": "integer", "minimum": 1}}}`), 0644), IsNil)
+
+	_, err = configstate.ConfigureInstalled(s.state, "test-snap", patch, 0)
+	c.Assert(err, ErrorMatches, `invalid "test-snap" option "port": must be an integer`)
+	var verr *config.ValidationError
+	c.Check(errors.As(err, &verr), Equals, true)
+
+	patch = map[string]interface{}{"port": json.Number("8080")}
+	ts, err := configstate.ConfigureInstalled(s.state, "test-snap", patch, 0)
+	c.Assert(err, IsNil)
+	c.Check(ts.Tasks(), HasLen, 1)
+
+	// unsetting is always allowed
+	patch = map[string]interface{}{"port": nil}
+	_, err = configstate.ConfigureInstalled(s.state, "test-snap", patch, 0)
+	c.Assert(err, IsNil)
+
+	c.Assert(os.WriteFile(schemaPath, []byte(`{"properties": `), 0644), IsNil)
+	_, err = configstate.ConfigureInstalled(s.state, "test-snap", patch, 0)
+	c.Assert(err, ErrorMatches, `cannot validate configuration of snap "test-snap": cannot parse configuration schema: .*`)
+}
+
 func (s *tasksetsSuite) TestDefaultConfigure(c *C) {
 	s.state.Lock()
 	snapstate.Set(s.state, "test-snap", &snapstate.SnapState{
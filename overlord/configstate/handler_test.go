@@ -21,6 +21,7 @@
 package configstate_test
 
 import (
+	"encoding/json"
 	"errors"
 	"os"
 	"path/filepath"
@@ -117,6 +118,103 @@ func (s *configureHandlerSuite) TestBeforeInitializesTransaction(c *C) {
 	c.Check(value, Equals, "bar")
 }
 
+func (s *configureHandlerSuite) TestErrorLeavesConfigurationUnchanged(c *C) {
+	s.context.Lock()
+	tr := config.NewTransaction(s.state)
+	c.Assert(tr.Set("test-snap", "port", 80), IsNil)
+	tr.Commit()
+	s.context.Set("patch", map[string]interface{}{
+		"port": 8080,
+		"host": "example.com",
+	})
+	s.context.Unlock()
+
+	c.Assert(s.handler.Before(), IsNil)
+
+	ignore, err := s.handler.Error(errors.New("invalid value for port: 8080"))
+	c.Check(ignore, Equals, false)
+	c.Check(err, ErrorMatches, `configure hook of snap "test-snap" failed, options "host", "port" left unchanged: invalid value for port: 8080`)
+
+	s.context.Lock()
+	s.context.Set("patch", map[string]interface{}{"port": 8080})
+	s.context.Unlock()
+	_, err = s.handler.Error(errors.New("something broke"))
+	c.Check(err, ErrorMatches, `configure hook of snap "test-snap" failed, option "port" left unchanged: something broke`)
+
+	// the transaction is not committed on failure
+	s.state.Lock()
+	defer s.state.Unlock()
+	var port int
+	var host string
+	tr = config.NewTransaction(s.state)
+	c.Check(tr.Get("test-snap", "port", &port), IsNil)
+	c.Check(port, Equals, 80)
+	c.Check(config.IsNoOption(tr.Get("test-snap", "host", &host)), Equals, true)
+}
+
+func (s *configureHandlerSuite) TestDoneRecordsHistory(c *C) {
+	s.state.Lock()
+	chg := s.state.NewChange("configure-snap", "...")
+	configstate.SetChangeActor(chg, "alice")
+	task := s.state.NewTask("run-hook", "...")
+	chg.AddTask(task)
+	autoChg := s.state.NewChange("auto-refresh", "...")
+	autoTask := s.state.NewTask("run-hook", "...")
+	autoChg.AddTask(autoTask)
+	s.state.Unlock()
+
+	setup := &hookstate.HookSetup{Snap: "test-snap", Revision: snap.R(1), Hook: "configure"}
+	context, err := hookstate.NewContext(task, s.state, setup, hooktest.NewMockHandler(), "")
+	c.Assert(err, IsNil)
+
+	context.Lock()
+	tr := configstate.ContextTransaction(context)
+	c.Assert(tr.Set("test-snap", "foo", "bar"), IsNil)
+	c.Assert(context.Done(), IsNil)
+	context.Unlock()
+
+	// changes without a recorded actor were made by snapd on its own
+	context, err = hookstate.NewContext(autoTask, s.state, setup, hooktest.NewMockHandler(), "")
+	c.Assert(err, IsNil)
+
+	context.Lock()
+	tr = configstate.ContextTransaction(context)
+	c.Assert(tr.Set("test-snap", "foo", "qux"), IsNil)
+	c.Assert(context.Done(), IsNil)
+	context.Unlock()
+
+	// snapctl set from outside of hooks uses an ephemeral context
+	context, err = hookstate.NewContext(nil, s.state, &hookstate.HookSetup{Snap: "test-snap"}, nil, "")
+	c.Assert(err, IsNil)
+
+	context.Lock()
+	tr = configstate.ContextTransaction(context)
+	c.Assert(tr.Set("test-snap", "foo", "baz"), IsNil)
+	c.Assert(context.Done(), IsNil)
+	context.Unlock()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	history, err := config.History(s.state, "test-snap")
+	c.Assert(err, IsNil)
+	c.Assert(history, HasLen, 3)
+	c.Check(history[0].Change, Equals, chg.ID())
+	c.Check(history[0].Actor, Equals, "alice")
+	c.Check(history[0].Changes, DeepEquals, []config.Change{{Key: "foo", New: "bar"}})
+	c.Check(history[1].Change, Equals, autoChg.ID())
+	c.Check(history[1].Actor, Equals, "snapd")
+	c.Check(history[1].Changes, DeepEquals, []config.Change{{Key: "foo", Old: "bar", New: "qux"}})
+	c.Check(history[2].Change, Equals, "")
+	c.Check(history[2].Actor, Equals, "snapctl")
+	c.Check(history[2].Changes, DeepEquals, []config.Change{{Key: "foo", Old: "qux", New: "baz"}})
+}
+
+func (s *configureHandlerSuite) TestErrorWithoutPatch(c *C) {
+	ignore, err := s.handler.Error(errors.New("boom"))
+	c.Check(ignore, Equals, false)
+	c.Check(err, IsNil)
+}
+
 func makeModel(override map[string]interface{}) *asserts.Model {
 	model := map[string]interface{}{
 		"type":         "model",
@@ -206,6 +304,82 @@ hooks:
 	c.Check(fl, Equals, 1.305)
 }
 
+func (s *configureHandlerSuite) TestEffectiveConfig(c *C) {
+	r := release.MockOnClassic(false)
+	defer r()
+
+	const mockGadgetSnapYaml = `
+name: canonical-pc
+type: gadget
+`
+	var mockGadgetYaml = []byte(`
+defaults:
+  testsnapidididididididididididid:
+      bar: baz
+      num: 1
+      nested:
+        key: value
+
+volumes:
+    volume-id:
+        bootloader: grub
+`)
+
+	info := snaptest.MockSnap(c, mockGadgetSnapYaml, &snap.SideInfo{Revision: snap.R(1)})
+	err := os.WriteFile(filepath.Join(info.MountDir(), "meta", "gadget.yaml"), mockGadgetYaml, 0644)
+	c.Assert(err, IsNil)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	snapstate.Set(s.state, "canonical-pc", &snapstate.SnapState{
+		Active: true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "canonical-pc", Revision: snap.R(1)},
+		}),
+		Current:  snap.R(1),
+		SnapType: "gadget",
+	})
+
+	r = snapstatetest.MockDeviceModel(makeModel(map[string]interface{}{
+		"gadget": "canonical-pc",
+	}))
+	defer r()
+
+	snapstate.Set(s.state, "test-snap", &snapstate.SnapState{
+		Active: true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "test-snap", Revision: snap.R(11), SnapID: "testsnapidididididididididididid"},
+		}),
+		Current:  snap.R(11),
+		SnapType: "app",
+	})
+
+	tr := config.NewTransaction(s.state)
+	c.Assert(tr.Set("test-snap", "bar", "baz"), IsNil)
+	c.Assert(tr.Set("test-snap", "num", 2), IsNil)
+	c.Assert(tr.Set("test-snap", "other", true), IsNil)
+	tr.Commit()
+
+	values, err := configstate.EffectiveConfig(s.state, "test-snap")
+	c.Assert(err, IsNil)
+	c.Check(values, DeepEquals, []config.EffectiveValue{
+		{Key: "bar", Value: "baz", Default: "baz", Origin: config.OriginDefault},
+		{Key: "nested.key", Default: "value", Origin: config.OriginUnset},
+		{Key: "num", Value: json.Number("2"), Default: int64(1), Origin: config.OriginExplicit},
+		{Key: "other", Value: true, Origin: config.OriginExplicit},
+	})
+
+	// no defaults for other snaps
+	tr = config.NewTransaction(s.state)
+	c.Assert(tr.Set("other-snap", "foo", "bar"), IsNil)
+	tr.Commit()
+	values, err = configstate.EffectiveConfig(s.state, "other-snap")
+	c.Assert(err, IsNil)
+	c.Check(values, DeepEquals, []config.EffectiveValue{
+		{Key: "foo", Value: "bar", Origin: config.OriginExplicit},
+	})
+}
+
 func (s *configureHandlerSuite) TestBeforeUseDefaultsMissingHook(c *C) {
 	r := release.MockOnClassic(false)
 	defer r()
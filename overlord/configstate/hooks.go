@@ -22,11 +22,13 @@ package configstate
 import (
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/hookstate"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/strutil"
 )
 
 // configureHandler is the handler for the configure hook.
@@ -105,8 +107,30 @@ func (h *configureHandler) Done() error {
 
 // Error is called by the HookManager after the configure hook has exited
 // non-zero, and includes the error.
-func (h *configureHandler) Error(err error) (bool, error) {
-	return false, nil
+//
+// The configuration transaction is only committed once the hook succeeds, so
+// none of the options of the patch are applied. The returned error says so,
+// naming the options that were being set.
+func (h *configureHandler) Error(hookErr error) (bool, error) {
+	h.context.Lock()
+	defer h.context.Unlock()
+
+	var patch map[string]interface{}
+	if err := h.context.Get("patch", &patch); err != nil || len(patch) == 0 {
+		return false, nil
+	}
+	keys := make([]string, 0, len(patch))
+	for key := range patch {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	noun := "option"
+	if len(keys) > 1 {
+		noun = "options"
+	}
+	return false, fmt.Errorf("configure hook of snap %q failed, %s %s left unchanged: %v",
+		h.context.InstanceName(), noun, strutil.Quoted(keys), hookErr)
 }
 
 // defaultConfigureHandler is the handler for the default-configure hook.
@@ -190,7 +214,10 @@ func ContextTransaction(context *hookstate.Context) *config.Transaction {
 	tr = config.NewTransaction(context.State())
 
 	context.OnDone(func() error {
-		tr.Commit()
+		changeID, actor := contextActor(context)
+		if err := config.RecordingCommit(tr, context.InstanceName(), changeID, actor); err != nil {
+			return err
+		}
 		if context.InstanceName() == "core" {
 			// make sure the Ensure logic can process
 			// system configuration changes as soon as possible
@@ -202,3 +229,25 @@ func ContextTransaction(context *hookstate.Context) *config.Transaction {
 	context.Cache(cachedTransaction{}, tr)
 	return tr
 }
+
+// SetChangeActor records on the change the user on whose behalf it modifies
+// the configuration of snaps, for the configuration history.
+func SetChangeActor(chg *state.Change, actor string) {
+	chg.Set("config-actor", actor)
+}
+
+// contextActor returns the ID of the change the context belongs to and the
+// user on whose behalf it was made, for the configuration history. Changes
+// made by snapd on its own are attributed to "snapd". Configuration set with
+// snapctl outside of hooks does not belong to any change.
+func contextActor(context *hookstate.Context) (changeID, actor string) {
+	task, ok := context.Task()
+	if !ok || task.Change() == nil {
+		return "", "snapctl"
+	}
+	chg := task.Change()
+	if err := chg.Get("config-actor", &actor); err != nil || actor == "" {
+		actor = "snapd"
+	}
+	return chg.ID(), actor
+}
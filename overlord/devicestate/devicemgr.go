@@ -124,9 +124,17 @@ type DeviceManager struct {
 	reg                          chan struct{}
 	noRegister                   bool
 
+	lastStoreProxyDiscoveryAttempt time.Time
+	storeProxyDiscoveryBackoff     time.Duration
+
 	preseed            bool
 	preseedSystemLabel string
 
+	// containerProfile is set when snapd runs as a package manager
+	// inside a container, where it manages neither boot nor disk
+	// encryption
+	containerProfile bool
+
 	ntpSyncedOrTimedOut bool
 }
 
@@ -140,6 +148,8 @@ func Manager(s *state.State, hookManager *hookstate.HookManager, runner *state.T
 		newStore: newStore,
 		reg:      make(chan struct{}),
 		preseed:  snapdenv.Preseeding(),
+
+		containerProfile: snapdenv.ContainerProfile(),
 	}
 	m.populateStateFromSeed = m.populateStateFromSeedImpl
 
@@ -181,6 +191,7 @@ func Manager(s *state.State, hookManager *hookstate.HookManager, runner *state.T
 	runner.AddHandler("request-serial", m.doRequestSerial, nil)
 	runner.AddHandler("mark-preseeded", m.doMarkPreseeded, nil)
 	runner.AddHandler("mark-seeded", m.doMarkSeeded, nil)
+	runner.AddHandler("discover-store-proxy", m.doDiscoverStoreProxy, nil)
 	runner.AddHandler("setup-ubuntu-save", m.doSetupUbuntuSave, nil)
 	runner.AddHandler("setup-run-system", m.doSetupRunSystem, nil)
 	runner.AddHandler("factory-reset-run-system", m.doFactoryResetRunSystem, nil)
@@ -1780,6 +1791,27 @@ func (e *ensureError) Error() string {
 var seedFailureFmt = `seeding failed with: %v. This indicates an error in your distribution, please see https://forum.snapcraft.io/t/16341 for more information.`
 
 // Ensure implements StateManager.Ensure.
+// ensureInstallAndReset runs the ensure steps that manage the installation,
+// the recovery systems and the factory reset of the system.
+func (m *DeviceManager) ensureInstallAndReset() (errs []error) {
+	if err := m.ensureInstalled(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := m.ensureTriedRecoverySystem(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := m.ensureFactoryReset(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := m.ensurePostFactoryReset(); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
 func (m *DeviceManager) Ensure() error {
 	var errs []error
 
@@ -1808,35 +1840,31 @@ func (m *DeviceManager) Ensure() error {
 			errs = append(errs, err)
 		}
 
-		if err := m.ensureBootOk(); err != nil {
-			errs = append(errs, err)
+		// in the container profile snapd manages neither boot nor
+		// installation and reset of the system
+		if !m.containerProfile {
+			if err := m.ensureBootOk(); err != nil {
+				errs = append(errs, err)
+			}
 		}
 
 		if err := m.ensureSeedInConfig(); err != nil {
 			errs = append(errs, err)
 		}
 
-		if err := m.ensureInstalled(); err != nil {
-			errs = append(errs, err)
-		}
-
-		if err := m.ensureTriedRecoverySystem(); err != nil {
-			errs = append(errs, err)
-		}
-
-		if err := m.ensureFactoryReset(); err != nil {
-			errs = append(errs, err)
+		if !m.containerProfile {
+			errs = append(errs, m.ensureInstallAndReset()...)
 		}
 
-		if err := m.ensurePostFactoryReset(); err != nil {
+		if err := m.ensureSerialBoundSystemUserAssertionsProcessed(); err != nil {
 			errs = append(errs, err)
 		}
 
-		if err := m.ensureSerialBoundSystemUserAssertionsProcessed(); err != nil {
+		if err := m.ensureExpiredUsersRemoved(); err != nil {
 			errs = append(errs, err)
 		}
 
-		if err := m.ensureExpiredUsersRemoved(); err != nil {
+		if err := m.ensureStoreProxyDiscovered(); err != nil {
 			errs = append(errs, err)
 		}
 	}
@@ -2705,6 +2733,8 @@ func (h fdeSetupHandler) Error(err error) (bool, error) {
 	return false, nil
 }
 
+var errContainerProfileNoEncryption = errors.New("cannot manage disk encryption with the container profile")
+
 var (
 	secbootEnsureRecoveryKey  = secboot.EnsureRecoveryKey
 	secbootRemoveRecoveryKeys = secboot.RemoveRecoveryKeys
@@ -2715,6 +2745,9 @@ var (
 // older systems might return both a recovery key for ubuntu-data and a
 // reinstall key for ubuntu-save.
 func (m *DeviceManager) EnsureRecoveryKeys() (*client.SystemRecoveryKeysResponse, error) {
+	if m.containerProfile {
+		return nil, errContainerProfileNoEncryption
+	}
 	deviceCtx, err := DeviceCtx(m.state, nil, nil)
 	if err != nil {
 		return nil, err
@@ -2782,6 +2815,9 @@ func (m *DeviceManager) EnsureRecoveryKeys() (*client.SystemRecoveryKeysResponse
 
 // RemoveRecoveryKeys removes and disables all recovery keys.
 func (m *DeviceManager) RemoveRecoveryKeys() error {
+	if m.containerProfile {
+		return errContainerProfileNoEncryption
+	}
 	mode := m.SystemMode(SysAny)
 	if mode != "run" {
 		return fmt.Errorf("cannot remove recovery keys from system mode %q", mode)
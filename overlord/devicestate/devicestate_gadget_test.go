@@ -477,6 +477,29 @@ func (s *deviceMgrGadgetSuite) TestUpdateGadgetOnCoreNoUpdateNeeded(c *C) {
 	c.Check(s.restartRequests, HasLen, 0)
 }
 
+func (s *deviceMgrGadgetSuite) TestUpdateGadgetOnCoreLogsVolumeProgress(c *C) {
+	restore := devicestate.MockGadgetUpdateWithProgress(func(model gadget.Model, current, update gadget.GadgetData, path string, policy gadget.UpdatePolicyFunc, _ gadget.ContentUpdateObserver, progress gadget.UpdateProgressFunc) error {
+		c.Assert(progress, NotNil)
+		progress("foo", 0, 1)
+		progress("pc", 2, 3)
+		return nil
+	})
+	defer restore()
+
+	isClassic := false
+	_, t := s.setupGadgetUpdate(c, "", gadgetYaml, "", isClassic)
+
+	s.se.Ensure()
+	s.se.Wait()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	// volumes which did not change are not logged
+	c.Assert(t.Log(), HasLen, 2)
+	c.Check(t.Log()[0], Matches, `.* INFO Updated 2 of 3 structures of gadget volume "pc"`)
+	c.Check(t.Log()[1], Matches, `.* INFO Task set to wait until a system restart allows to continue`)
+}
+
 func (s *deviceMgrGadgetSuite) TestUpdateGadgetOnCoreRollbackDirCreateFailed(c *C) {
 	if os.Geteuid() == 0 {
 		c.Skip("this test cannot run as root (permissions are not honored)")
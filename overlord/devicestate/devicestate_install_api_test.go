@@ -378,8 +378,10 @@ func (s *deviceMgrInstallAPISuite) testInstallFinishStep(c *C, opts finishStepOp
 
 	// Mock writing of contents
 	writeContentCalls := 0
-	restore = devicestate.MockInstallWriteContent(func(onVolumes map[string]*gadget.Volume, allLaidOutVols map[string]*gadget.LaidOutVolume, encSetupData *install.EncryptionSetupData, kSnapInfo *install.KernelSnapInfo, observer gadget.ContentObserver, perfTimings timings.Measurer) ([]*gadget.OnDiskVolume, error) {
+	restore = devicestate.MockInstallWriteContent(func(onVolumes map[string]*gadget.Volume, allLaidOutVols map[string]*gadget.LaidOutVolume, gadgetRoot string, encSetupData *install.EncryptionSetupData, kSnapInfo *install.KernelSnapInfo, observer gadget.ContentObserver, perfTimings timings.Measurer) ([]*gadget.OnDiskVolume, error) {
 		writeContentCalls++
+		// the gadget content is written from the mounted seed gadget
+		c.Check(gadgetRoot, Not(Equals), "")
 		vol := onVolumes["pc"]
 		for sIdx, vs := range vol.Structure {
 			c.Check(vs.Device, Equals, fmt.Sprintf("/dev/vda%d", sIdx+1))
@@ -36,6 +36,7 @@ import (
 	"github.com/snapcore/snapd/secboot"
 	"github.com/snapcore/snapd/secboot/keys"
 	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/snapdenv"
 )
 
 var _ = Suite(&deviceMgrRecoveryKeysSuite{})
@@ -220,6 +221,24 @@ func (s *deviceMgrRecoveryKeysSuite) TestEnsureRecoveryKeyRecoverMode(c *C) {
 	c.Check(err, ErrorMatches, `cannot ensure recovery keys from system mode "recover"`)
 }
 
+func (s *deviceMgrRecoveryKeysSuite) TestRecoveryKeysContainerProfile(c *C) {
+	defer snapdenv.MockContainerProfile(true)()
+	mgr, err := devicestate.Manager(s.state, s.hookMgr, s.o.TaskRunner(), nil)
+	c.Assert(err, IsNil)
+	devicestate.SetSystemMode(mgr, "run")
+
+	mockSnapFDEFile(c, "marker", nil)
+	mockSnapFDEFile(c, "ubuntu-save.key", make([]byte, 32))
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	_, err = mgr.EnsureRecoveryKeys()
+	c.Check(err, ErrorMatches, `cannot manage disk encryption with the container profile`)
+	err = mgr.RemoveRecoveryKeys()
+	c.Check(err, ErrorMatches, `cannot manage disk encryption with the container profile`)
+}
+
 func (s *deviceMgrRecoveryKeysSuite) testRemoveRecoveryKeys(c *C, classic bool) {
 	if classic {
 		s.setClassicWithModesModelInState(c)
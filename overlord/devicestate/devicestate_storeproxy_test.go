@@ -0,0 +1,322 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package devicestate_test
+
+import (
+	"encoding/hex"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	. "gopkg.in/check.v1"
+	"gopkg.in/tomb.v2"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/asserts/assertstest"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/httputil"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/assertstate"
+	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/devicestate"
+	"github.com/snapcore/snapd/overlord/devicestate/devicestatetest"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type deviceMgrStoreProxySuite struct {
+	deviceMgrBaseSuite
+
+	lookups []string
+}
+
+var _ = Suite(&deviceMgrStoreProxySuite{})
+
+func (s *deviceMgrStoreProxySuite) SetUpTest(c *C) {
+	classic := true
+	s.setupBaseTest(c, classic)
+
+	s.lookups = nil
+	s.AddCleanup(devicestate.MockNetLookupSRV(func(service, proto, name string) (string, []*net.SRV, error) {
+		s.lookups = append(s.lookups, name)
+		return "", nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}))
+	s.AddCleanup(devicestate.MockHttputilNewHTTPClient(func(opts *httputil.ClientOptions) *http.Client {
+		c.Check(opts.ProxyConnectHeader, NotNil)
+		return &http.Client{}
+	}))
+
+	// the configure hook of core is not available here, apply the patch
+	// directly
+	s.AddCleanup(testutil.Mock(&snapstate.Configure, func(st *state.State, snapName string, patch map[string]interface{}, flags int) *state.TaskSet {
+		c.Check(snapName, Equals, "core")
+		t := st.NewTask("fake-configure", "Configure core")
+		t.Set("patch", patch)
+		return state.NewTaskSet(t)
+	}))
+	s.o.TaskRunner().AddHandler("fake-configure", func(t *state.Task, _ *tomb.Tomb) error {
+		st := t.State()
+		st.Lock()
+		defer st.Unlock()
+		var patch map[string]interface{}
+		c.Assert(t.Get("patch", &patch), IsNil)
+		tr := config.NewTransaction(st)
+		for k, v := range patch {
+			c.Assert(tr.Set("core", k, v), IsNil)
+		}
+		tr.Commit()
+		return nil
+	}, nil)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	s.state.Set("seeded", true)
+	c.Assert(devicestatetest.SetDevice(s.state, &auth.DeviceState{
+		Brand: "canonical",
+		Model: "pc",
+	}), IsNil)
+}
+
+// discover runs a store proxy discovery, returning the change doing it if
+// one was started.
+func (s *deviceMgrStoreProxySuite) discover(c *C) *state.Change {
+	c.Assert(s.mgr.EnsureStoreProxyDiscovered(), IsNil)
+
+	s.state.Lock()
+	var chg *state.Change
+	for _, ch := range s.state.Changes() {
+		if ch.Kind() == "discover-store-proxy" && !ch.IsReady() {
+			chg = ch
+		}
+	}
+	s.state.Unlock()
+	if chg == nil {
+		return nil
+	}
+
+	// only run the tasks, the device is not fully set up for the
+	// ensure of the manager
+	runner := s.o.TaskRunner()
+	for i := 0; i < 100; i++ {
+		c.Assert(runner.Ensure(), IsNil)
+		runner.Wait()
+		s.state.Lock()
+		ready := chg.IsReady()
+		s.state.Unlock()
+		if ready {
+			break
+		}
+	}
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Check(chg.Status(), Equals, state.DoneStatus, Commentf("%v", chg.Err()))
+	return chg
+}
+
+// mockStoreProxy starts a snap store proxy serving the assertions to
+// register with it.
+func (s *deviceMgrStoreProxySuite) mockStoreProxy(c *C) *httptest.Server {
+	operatorAcct := assertstest.NewAccount(s.storeSigning, "foo-operator", nil, "")
+	var stoAs asserts.Assertion
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, Equals, "/v2/auth/store/assertions")
+		w.Header().Set("Content-Type", asserts.MediaType)
+		enc := asserts.NewEncoder(w)
+		c.Check(enc.Encode(operatorAcct), IsNil)
+		c.Check(enc.Encode(stoAs), IsNil)
+	}))
+	s.AddCleanup(server.Close)
+
+	var err error
+	stoAs, err = s.storeSigning.Sign(asserts.StoreType, map[string]interface{}{
+		"store":       "foo",
+		"operator-id": operatorAcct.AccountID(),
+		"url":         server.URL,
+		"timestamp":   time.Now().Format(time.RFC3339),
+	}, nil, "")
+	c.Assert(err, IsNil)
+	return server
+}
+
+func (s *deviceMgrStoreProxySuite) setDiscovery(c *C, enabled bool) {
+	s.state.Lock()
+	defer s.state.Unlock()
+	tr := config.NewTransaction(s.state)
+	c.Assert(tr.Set("core", "proxy.store-discovery", enabled), IsNil)
+	tr.Commit()
+}
+
+func (s *deviceMgrStoreProxySuite) checkRegistered(c *C, proxyStore string) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	var got string
+	tr := config.NewTransaction(s.state)
+	c.Assert(tr.GetMaybe("core", "proxy.store", &got), IsNil)
+	c.Check(got, Equals, proxyStore)
+
+	if proxyStore == "" {
+		return
+	}
+	_, err := assertstate.DB(s.state).Find(asserts.StoreType, map[string]string{
+		"store": proxyStore,
+	})
+	c.Check(err, IsNil)
+}
+
+func writeNetworkdLease(c *C, content string) {
+	leases := filepath.Join(dirs.GlobalRootDir, "/run/systemd/netif/leases")
+	c.Assert(os.MkdirAll(leases, 0755), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(leases, "2"), []byte(content), 0644), IsNil)
+}
+
+func writeResolvConf(c *C, content string) {
+	p := filepath.Join(dirs.GlobalRootDir, "/etc/resolv.conf")
+	c.Assert(os.MkdirAll(filepath.Dir(p), 0755), IsNil)
+	c.Assert(os.WriteFile(p, []byte(content), 0644), IsNil)
+}
+
+func (s *deviceMgrStoreProxySuite) TestStoreProxyDiscoveryDisabled(c *C) {
+	server := s.mockStoreProxy(c)
+	writeNetworkdLease(c, "OPTION_224="+hex.EncodeToString([]byte(server.URL))+"\n")
+
+	c.Check(s.discover(c), IsNil)
+	s.checkRegistered(c, "")
+
+	s.setDiscovery(c, false)
+	c.Check(s.discover(c), IsNil)
+	s.checkRegistered(c, "")
+}
+
+func (s *deviceMgrStoreProxySuite) TestStoreProxyDiscoveryDHCP(c *C) {
+	server := s.mockStoreProxy(c)
+	writeNetworkdLease(c, `# This is private data. Do not parse.
+ADDRESS=192.168.1.10
+DOMAINNAME=example.com
+OPTION_224=`+hex.EncodeToString([]byte(server.URL))+"\n")
+	s.setDiscovery(c, true)
+
+	chg := s.discover(c)
+	c.Assert(chg, NotNil)
+	s.checkRegistered(c, "foo")
+	// the lease domain was looked up too
+	c.Check(s.lookups, DeepEquals, []string{"example.com"})
+
+	// the setting was applied through the configuration of core
+	s.state.Lock()
+	defer s.state.Unlock()
+	tasks := chg.Tasks()
+	c.Assert(tasks, HasLen, 2)
+	c.Check(tasks[0].Kind(), Equals, "discover-store-proxy")
+	c.Check(tasks[1].Kind(), Equals, "fake-configure")
+	c.Check(tasks[1].WaitTasks(), DeepEquals, []*state.Task{tasks[0]})
+	var patch map[string]interface{}
+	c.Assert(tasks[1].Get("patch", &patch), IsNil)
+	c.Check(patch, DeepEquals, map[string]interface{}{"proxy.store": "foo"})
+}
+
+func (s *deviceMgrStoreProxySuite) TestStoreProxyDiscoveryDNSSD(c *C) {
+	server := s.mockStoreProxy(c)
+	u, err := url.Parse(server.URL)
+	c.Assert(err, IsNil)
+	host, portStr, err := net.SplitHostPort(u.Host)
+	c.Assert(err, IsNil)
+	port, err := strconv.Atoi(portStr)
+	c.Assert(err, IsNil)
+
+	writeResolvConf(c, "nameserver 127.0.0.53\nsearch corp.example.com\n")
+	s.AddCleanup(devicestate.MockNetLookupSRV(func(service, proto, name string) (string, []*net.SRV, error) {
+		c.Check(service, Equals, "snap-store-proxy")
+		c.Check(proto, Equals, "tcp")
+		s.lookups = append(s.lookups, name)
+		return "", []*net.SRV{{Target: host + ".", Port: uint16(port)}}, nil
+	}))
+	s.setDiscovery(c, true)
+
+	c.Check(s.discover(c), NotNil)
+	s.checkRegistered(c, "foo")
+	c.Check(s.lookups, DeepEquals, []string{"corp.example.com"})
+}
+
+func (s *deviceMgrStoreProxySuite) TestStoreProxyDiscoveryBackoff(c *C) {
+	writeResolvConf(c, "search corp.example.com\n")
+	s.setDiscovery(c, true)
+
+	c.Check(s.discover(c), NotNil)
+	s.checkRegistered(c, "")
+	c.Check(s.lookups, DeepEquals, []string{"corp.example.com"})
+
+	// no new attempt until the backoff interval expired
+	c.Check(s.discover(c), IsNil)
+	c.Check(s.lookups, HasLen, 1)
+
+	restore := devicestate.MockStoreProxyDiscoveryBackoff(s.mgr, time.Now().Add(-time.Hour), time.Minute)
+	defer restore()
+	c.Check(s.discover(c), NotNil)
+	c.Check(s.lookups, DeepEquals, []string{"corp.example.com", "corp.example.com"})
+}
+
+func (s *deviceMgrStoreProxySuite) TestStoreProxyDiscoveryBackoffInterval(c *C) {
+	now := time.Now()
+	c.Check(s.mgr.StoreProxyDiscoveryShouldBackoff(now), Equals, false)
+	c.Check(s.mgr.StoreProxyDiscoveryShouldBackoff(now.Add(4*time.Minute)), Equals, true)
+	now = now.Add(5 * time.Minute)
+	c.Check(s.mgr.StoreProxyDiscoveryShouldBackoff(now), Equals, false)
+	// the interval doubles
+	c.Check(s.mgr.StoreProxyDiscoveryShouldBackoff(now.Add(9*time.Minute)), Equals, true)
+	c.Check(s.mgr.StoreProxyDiscoveryShouldBackoff(now.Add(10*time.Minute)), Equals, false)
+}
+
+func (s *deviceMgrStoreProxySuite) TestStoreProxyDiscoveryProxyStoreSet(c *C) {
+	writeResolvConf(c, "search corp.example.com\n")
+	s.setDiscovery(c, true)
+
+	s.state.Lock()
+	tr := config.NewTransaction(s.state)
+	c.Assert(tr.Set("core", "proxy.store", "bar"), IsNil)
+	tr.Commit()
+	s.state.Unlock()
+
+	c.Check(s.discover(c), IsNil)
+	c.Check(s.lookups, HasLen, 0)
+}
+
+func (s *deviceMgrStoreProxySuite) TestStoreProxyDiscoveryNoStoreAssertion(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", asserts.MediaType)
+	}))
+	defer server.Close()
+	writeNetworkdLease(c, "OPTION_224="+hex.EncodeToString([]byte(server.URL))+"\n")
+	s.setDiscovery(c, true)
+	logbuf, restore := logger.MockLogger()
+	defer restore()
+
+	c.Check(s.discover(c), NotNil)
+	s.checkRegistered(c, "")
+	c.Check(logbuf.String(), Matches, `(?s).*cannot register with snap store proxy http://.*: no store assertion\n.*`)
+}
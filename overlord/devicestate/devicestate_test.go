@@ -756,6 +756,35 @@ func (s *deviceMgrSuite) TestDeviceManagerEnsureBootOkError(c *C) {
 	c.Assert(err, ErrorMatches, "devicemgr: cannot mark boot successful: bootloader err")
 }
 
+func (s *deviceMgrSuite) TestDeviceManagerEnsureContainerProfileSkipsBoot(c *C) {
+	s.setPCModelInState(c)
+
+	s.state.Lock()
+	// seeded
+	s.state.Set("seeded", true)
+	// has serial
+	devicestatetest.SetDevice(s.state, &auth.DeviceState{
+		Brand:  "canonical",
+		Model:  "pc",
+		Serial: "8989",
+	})
+	s.state.Unlock()
+
+	defer snapdenv.MockContainerProfile(true)()
+	// re-create manager so that the profile is picked up
+	var err error
+	s.mgr, err = devicestate.Manager(s.state, s.hookMgr, s.o.TaskRunner(), s.newStore)
+	c.Assert(err, IsNil)
+
+	s.bootloader.GetErr = fmt.Errorf("bootloader err")
+
+	devicestate.SetBootOkRan(s.mgr, false)
+
+	// the boot is not managed with the container profile
+	err = s.mgr.Ensure()
+	c.Assert(err, IsNil)
+}
+
 func fakeMyModel(extra map[string]interface{}) *asserts.Model {
 	model := map[string]interface{}{
 		"type":         "model",
@@ -22,6 +22,7 @@ package devicestate
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"time"
 
@@ -336,6 +337,12 @@ func MockSeedOpen(f func(seedDir, label string) (seed.Seed, error)) (restore fun
 }
 
 func MockGadgetUpdate(mock func(model gadget.Model, current, update gadget.GadgetData, path string, policy gadget.UpdatePolicyFunc, observer gadget.ContentUpdateObserver) error) (restore func()) {
+	return MockGadgetUpdateWithProgress(func(model gadget.Model, current, update gadget.GadgetData, path string, policy gadget.UpdatePolicyFunc, observer gadget.ContentUpdateObserver, _ gadget.UpdateProgressFunc) error {
+		return mock(model, current, update, path, policy, observer)
+	})
+}
+
+func MockGadgetUpdateWithProgress(mock func(model gadget.Model, current, update gadget.GadgetData, path string, policy gadget.UpdatePolicyFunc, observer gadget.ContentUpdateObserver, progress gadget.UpdateProgressFunc) error) (restore func()) {
 	old := gadgetUpdate
 	gadgetUpdate = mock
 	return func() {
@@ -405,7 +412,7 @@ func MockInstallFactoryReset(f func(model gadget.Model, gadgetRoot string, kerne
 	return restore
 }
 
-func MockInstallWriteContent(f func(onVolumes map[string]*gadget.Volume, allLaidOutVols map[string]*gadget.LaidOutVolume, encSetupData *install.EncryptionSetupData, kSnapInfo *install.KernelSnapInfo, observer gadget.ContentObserver, perfTimings timings.Measurer) ([]*gadget.OnDiskVolume, error)) (restore func()) {
+func MockInstallWriteContent(f func(onVolumes map[string]*gadget.Volume, allLaidOutVols map[string]*gadget.LaidOutVolume, gadgetRoot string, encSetupData *install.EncryptionSetupData, kSnapInfo *install.KernelSnapInfo, observer gadget.ContentObserver, perfTimings timings.Measurer) ([]*gadget.OnDiskVolume, error)) (restore func()) {
 	old := installWriteContent
 	installWriteContent = f
 	return func() {
@@ -600,3 +607,23 @@ func CleanUpEncryptionSetupDataInCache(st *state.State, label string) {
 }
 
 type UniqueSnapsInRecoverySystem = uniqueSnapsInRecoverySystem
+
+func MockNetLookupSRV(f func(service, proto, name string) (string, []*net.SRV, error)) (restore func()) {
+	return testutil.Mock(&netLookupSRV, f)
+}
+
+func (m *DeviceManager) EnsureStoreProxyDiscovered() error {
+	return m.ensureStoreProxyDiscovered()
+}
+
+func (m *DeviceManager) StoreProxyDiscoveryShouldBackoff(now time.Time) bool {
+	return m.storeProxyDiscoveryShouldBackoff(now)
+}
+
+func MockStoreProxyDiscoveryBackoff(m *DeviceManager, last time.Time, backoff time.Duration) (restore func()) {
+	oldLast, oldBackoff := m.lastStoreProxyDiscoveryAttempt, m.storeProxyDiscoveryBackoff
+	m.lastStoreProxyDiscoveryAttempt, m.storeProxyDiscoveryBackoff = last, backoff
+	return func() {
+		m.lastStoreProxyDiscoveryAttempt, m.storeProxyDiscoveryBackoff = oldLast, oldBackoff
+	}
+}
@@ -82,7 +82,7 @@ func pendingGadgetData(snapsup *snapstate.SnapSetup, pendingDeviceCtx snapstate.
 }
 
 var (
-	gadgetUpdate = gadget.Update
+	gadgetUpdate = gadget.UpdateWithProgress
 )
 
 func setGadgetRestartRequired(t *state.Task) {
@@ -205,7 +205,13 @@ func (m *DeviceManager) doUpdateGadgetAssets(t *state.Task, _ *tomb.Tomb) error
 		// attempt to modify modeenv inside, which implicitly is
 		// guarded by the state lock; on top of that we do not expect
 		// the update to be moving large amounts of data
-		if err := gadgetUpdate(model, *currentData, *updateData, snapRollbackDir, updatePolicy, updateObserver); err != nil {
+		progress := func(volName string, updated, total int) {
+			if updated == 0 {
+				return
+			}
+			t.Logf("Updated %d of %d structures of gadget volume %q", updated, total, volName)
+		}
+		if err := gadgetUpdate(model, *currentData, *updateData, snapRollbackDir, updatePolicy, updateObserver, progress); err != nil {
 			return err
 		}
 		if updateObserver == nil {
@@ -0,0 +1,355 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package devicestate
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/tomb.v2"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/httputil"
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/assertstate"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/configstate/proxyconf"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snapdenv"
+	"github.com/snapcore/snapd/strutil"
+)
+
+const (
+	// storeProxyDHCPOption is the site-specific DHCP option carrying the
+	// URL of a snap store proxy.
+	storeProxyDHCPOption = 224
+	// storeProxyService is the DNS-SD service name a snap store proxy is
+	// advertised under, i.e. _snap-store-proxy._tcp.<domain>.
+	storeProxyService = "snap-store-proxy"
+	// storeProxyAssertionsPath is where a snap store proxy serves the
+	// assertions needed to register against it.
+	storeProxyAssertionsPath = "v2/auth/store/assertions"
+)
+
+var (
+	netLookupSRV = net.LookupSRV
+
+	networkdLeasesDir = "/run/systemd/netif/leases"
+	resolvConfPath    = "/etc/resolv.conf"
+)
+
+// dhcpStoreProxy returns the store proxy URLs and the domains found in the
+// DHCP leases of systemd-networkd.
+func dhcpStoreProxy() (proxyURLs []string, domains []string) {
+	leases, err := filepath.Glob(filepath.Join(dirs.GlobalRootDir, networkdLeasesDir, "*"))
+	if err != nil {
+		return nil, nil
+	}
+	optKey := fmt.Sprintf("OPTION_%d", storeProxyDHCPOption)
+	for _, lease := range leases {
+		f, err := os.Open(lease)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			k, v, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+			if !ok {
+				continue
+			}
+			switch k {
+			case optKey:
+				raw, err := hex.DecodeString(v)
+				if err != nil {
+					logger.Debugf("cannot decode DHCP option %d in %s: %v", storeProxyDHCPOption, lease, err)
+					continue
+				}
+				proxyURLs = append(proxyURLs, strings.TrimRight(string(raw), "\x00"))
+			case "DOMAINNAME":
+				domains = append(domains, v)
+			}
+		}
+		f.Close()
+	}
+	return proxyURLs, domains
+}
+
+// resolvConfDomains returns the search domains configured for the resolver.
+func resolvConfDomains() []string {
+	f, err := os.Open(filepath.Join(dirs.GlobalRootDir, resolvConfPath))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var domains []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		if fields[0] == "domain" || fields[0] == "search" {
+			domains = append(domains, fields[1:]...)
+		}
+	}
+	return domains
+}
+
+// dnssdStoreProxy looks up the store proxies advertised via DNS-SD in the
+// given domains.
+func dnssdStoreProxy(domains []string) []string {
+	var proxyURLs []string
+	for _, domain := range domains {
+		if domain == "." {
+			continue
+		}
+		_, addrs, err := netLookupSRV(storeProxyService, "tcp", domain)
+		if err != nil {
+			logger.Debugf("cannot look up snap store proxy in %q: %v", domain, err)
+			continue
+		}
+		for _, addr := range addrs {
+			scheme := "http"
+			if addr.Port == 443 {
+				scheme = "https"
+			}
+			host := net.JoinHostPort(strings.TrimSuffix(addr.Target, "."), strconv.Itoa(int(addr.Port)))
+			proxyURLs = append(proxyURLs, fmt.Sprintf("%s://%s", scheme, host))
+		}
+	}
+	return proxyURLs
+}
+
+// discoverStoreProxies returns the URLs of the snap store proxies advertised
+// on the network, DHCP provided ones first.
+func discoverStoreProxies() []*url.URL {
+	candidates, domains := dhcpStoreProxy()
+	domains = strutil.Deduplicate(append(domains, resolvConfDomains()...))
+	candidates = append(candidates, dnssdStoreProxy(domains)...)
+
+	var proxies []*url.URL
+	for _, cand := range strutil.Deduplicate(candidates) {
+		u, err := url.Parse(cand)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			logger.Noticef("ignoring invalid snap store proxy URL %q", cand)
+			continue
+		}
+		proxies = append(proxies, u)
+	}
+	return proxies
+}
+
+// fetchStoreProxyAssertions fetches the assertions needed to register with
+// the snap store proxy at proxyURL, returning them together with its store
+// assertion.
+func fetchStoreProxyAssertions(client *http.Client, proxyURL *url.URL) (*asserts.Store, *asserts.Batch, error) {
+	u := *proxyURL
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + storeProxyAssertionsPath
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("User-Agent", snapdenv.UserAgent())
+	req.Header.Set("Accept", asserts.MediaType)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var store *asserts.Store
+	batch := asserts.NewBatch(nil)
+	dec := asserts.NewDecoder(resp.Body)
+	for {
+		a, err := dec.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if a.Type() == asserts.StoreType {
+			if store != nil {
+				return nil, nil, errors.New("cannot accept more than a single store assertion")
+			}
+			store = a.(*asserts.Store)
+		}
+		if err := batch.Add(a); err != nil {
+			return nil, nil, err
+		}
+	}
+	if store == nil {
+		return nil, nil, errors.New("no store assertion")
+	}
+	if store.URL() == nil {
+		return nil, nil, fmt.Errorf("store assertion for %q has no url", store.Store())
+	}
+	return store, batch, nil
+}
+
+// storeProxyDiscoveryShouldBackoff returns whether we should abstain from
+// further store proxy discovery attempts while its backoff interval is not
+// expired.
+func (m *DeviceManager) storeProxyDiscoveryShouldBackoff(now time.Time) bool {
+	if !m.lastStoreProxyDiscoveryAttempt.IsZero() && m.lastStoreProxyDiscoveryAttempt.Add(m.storeProxyDiscoveryBackoff).After(now) {
+		return true
+	}
+	if m.storeProxyDiscoveryBackoff == 0 {
+		m.storeProxyDiscoveryBackoff = 5 * time.Minute
+	} else {
+		newBackoff := m.storeProxyDiscoveryBackoff * 2
+		if newBackoff > (12 * time.Hour) {
+			newBackoff = 24 * time.Hour
+		}
+		m.storeProxyDiscoveryBackoff = newBackoff
+	}
+	m.lastStoreProxyDiscoveryAttempt = now
+	return false
+}
+
+// storeProxyDiscoveryWanted returns whether a snap store proxy should be
+// looked for on the network.
+func storeProxyDiscoveryWanted(st *state.State) (bool, error) {
+	tr := config.NewTransaction(st)
+	var discovery bool
+	if err := tr.GetMaybe("core", "proxy.store-discovery", &discovery); err != nil {
+		return false, err
+	}
+	var proxyStore, access string
+	if err := tr.GetMaybe("core", "proxy.store", &proxyStore); err != nil {
+		return false, err
+	}
+	if err := tr.GetMaybe("core", "store.access", &access); err != nil {
+		return false, err
+	}
+	return discovery && proxyStore == "" && access != "offline", nil
+}
+
+// ensureStoreProxyDiscovered starts a change looking for a snap store proxy
+// on the network when discovery is enabled and no proxy store is set yet.
+// Unsuccessful attempts are repeated with an increasing backoff.
+func (m *DeviceManager) ensureStoreProxyDiscovered() error {
+	m.state.Lock()
+	defer m.state.Unlock()
+
+	var seeded bool
+	if err := m.state.Get("seeded", &seeded); err != nil && !errors.Is(err, state.ErrNoState) {
+		return err
+	}
+	if !seeded {
+		return nil
+	}
+
+	wanted, err := storeProxyDiscoveryWanted(m.state)
+	if err != nil {
+		return err
+	}
+	if !wanted {
+		return nil
+	}
+
+	if m.changeInFlight("discover-store-proxy") {
+		return nil
+	}
+	if m.storeProxyDiscoveryShouldBackoff(time.Now()) {
+		return nil
+	}
+
+	chg := m.state.NewChange("discover-store-proxy", i18n.G("Discover snap store proxy"))
+	chg.AddTask(m.state.NewTask("discover-store-proxy", i18n.G("Look for a snap store proxy on the network")))
+	m.state.EnsureBefore(0)
+	return nil
+}
+
+func (m *DeviceManager) doDiscoverStoreProxy(t *state.Task, _ *tomb.Tomb) error {
+	st := t.State()
+	st.Lock()
+	defer st.Unlock()
+
+	// the configuration may have changed since the change was created
+	wanted, err := storeProxyDiscoveryWanted(st)
+	if err != nil {
+		return err
+	}
+	if !wanted {
+		return nil
+	}
+
+	proxyConf := proxyconf.New(st)
+	client := httputilNewHTTPClient(&httputil.ClientOptions{
+		Timeout:            30 * time.Second,
+		Proxy:              proxyConf.Conf,
+		ProxyConnectHeader: http.Header{"User-Agent": []string{snapdenv.UserAgent()}},
+		ExtraSSLCerts: &httputil.ExtraSSLCertsFromDir{
+			Dir: dirs.SnapdStoreSSLCertsDir,
+		},
+	})
+
+	st.Unlock()
+	var store *asserts.Store
+	var batch *asserts.Batch
+	for _, proxyURL := range discoverStoreProxies() {
+		var err error
+		store, batch, err = fetchStoreProxyAssertions(client, proxyURL)
+		if err == nil {
+			break
+		}
+		logger.Noticef("cannot register with snap store proxy %s: %v", proxyURL, err)
+	}
+	st.Lock()
+
+	if store == nil {
+		t.Logf("no snap store proxy found")
+		return nil
+	}
+	if err := assertstate.AddBatch(st, batch, nil); err != nil {
+		return fmt.Errorf("cannot add assertions of snap store proxy %q: %v", store.Store(), err)
+	}
+
+	// point the device at the proxy store through the configuration of
+	// the system, which also validates the setting and resets the device
+	// session that was obtained from the previous store
+	ts := snapstate.Configure(st, "core", map[string]interface{}{
+		"proxy.store": store.Store(),
+	}, 0)
+	ts.WaitFor(t)
+	t.Change().AddAll(ts)
+	t.Logf("using discovered snap store proxy %q at %s", store.Store(), store.URL())
+	return nil
+}
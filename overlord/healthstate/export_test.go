@@ -21,6 +21,10 @@ package healthstate
 
 import (
 	"time"
+
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/testutil"
 )
 
 func MockCheckTimeout(t time.Duration) (restore func()) {
@@ -31,4 +35,12 @@ func MockCheckTimeout(t time.Duration) (restore func()) {
 	}
 }
 
+func MockTimeNow(f func() time.Time) (restore func()) {
+	return testutil.Mock(&timeNow, f)
+}
+
+func MockSnapstateRevert(f func(st *state.State, name string, flags snapstate.Flags, fromChange string) (*state.TaskSet, error)) (restore func()) {
+	return testutil.Mock(&snapstateRevert, f)
+}
+
 var KnownStatuses = knownStatuses
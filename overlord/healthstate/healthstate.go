@@ -28,6 +28,7 @@ import (
 	"time"
 
 	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/hookstate"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
@@ -37,6 +38,11 @@ import (
 
 var checkTimeout = 30 * time.Second
 
+var (
+	timeNow         = time.Now
+	snapstateRevert = snapstate.Revert
+)
+
 func init() {
 	if s, ok := os.LookupEnv("SNAPD_CHECK_HEALTH_HOOK_TIMEOUT"); ok {
 		if to, err := time.ParseDuration(s); err == nil {
@@ -143,7 +149,14 @@ func (h *healthHandler) Done() error {
 		}
 	}
 
-	return h.appendHealth(&health)
+	st := h.context.State()
+	st.Lock()
+	defer st.Unlock()
+
+	if err := appendHealth(h.context, &health); err != nil {
+		return err
+	}
+	return maybeRevertUnhealthy(h.context, &health)
 }
 
 func (h *healthHandler) Error(err error) (bool, error) {
@@ -193,7 +206,103 @@ func SetFromHookContext(ctx *hookstate.Context) error {
 		}
 		return err
 	}
-	return appendHealth(ctx, &health)
+	if err := appendHealth(ctx, &health); err != nil {
+		return err
+	}
+	return maybeRevertUnhealthy(ctx, &health)
+}
+
+// unhealthyRevertWindow returns the time after a refresh during which a snap
+// reporting an error health status gets reverted, as set with the
+// refresh.unhealthy-revert-window option. It returns false if the option is
+// unset, in which case unhealthy snaps are never reverted.
+func unhealthyRevertWindow(st *state.State) (time.Duration, bool, error) {
+	var windowStr string
+	tr := config.NewTransaction(st)
+	if err := tr.GetMaybe("core", "refresh.unhealthy-revert-window", &windowStr); err != nil {
+		return 0, false, err
+	}
+	if windowStr == "" {
+		return 0, false, nil
+	}
+	window, err := time.ParseDuration(windowStr)
+	if err != nil {
+		return 0, false, fmt.Errorf("cannot parse refresh.unhealthy-revert-window: %v", err)
+	}
+	return window, true, nil
+}
+
+// maybeRevertUnhealthy reverts the refresh of a snap reporting an error
+// health status, if enabled with the refresh.unhealthy-revert-window option.
+// If the health is set from a hook running as part of the refresh, an error
+// is returned so that the refresh change is undone. If it is set outside of
+// hooks within the window after the refresh, a change reverting the snap is
+// created instead.
+func maybeRevertUnhealthy(ctx *hookstate.Context, health *HealthState) error {
+	if health.Status != ErrorStatus {
+		return nil
+	}
+	instanceName := ctx.InstanceName()
+
+	st := ctx.State()
+	window, enabled, err := unhealthyRevertWindow(st)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return nil
+	}
+
+	if task, ok := ctx.Task(); ok {
+		if isRefreshOf(task.Change(), instanceName) {
+			return fmt.Errorf("snap %q reported an error health status after refresh: %s", instanceName, health.Message)
+		}
+		return nil
+	}
+
+	var snapst snapstate.SnapState
+	if err := snapstate.Get(st, instanceName, &snapst); err != nil {
+		return err
+	}
+	// only the revision that was just refreshed to is reverted
+	if snapst.LastRefreshTime == nil || timeNow().Sub(*snapst.LastRefreshTime) > window {
+		return nil
+	}
+	if snapst.Current != health.Revision || snapst.LastIndex(snapst.Current) < 1 {
+		return nil
+	}
+
+	ts, err := snapstateRevert(st, instanceName, snapstate.Flags{}, "")
+	if err != nil {
+		logger.Noticef("cannot revert snap %q reporting an error health status: %v", instanceName, err)
+		return nil
+	}
+	chg := st.NewChange("revert-snap", fmt.Sprintf("Revert %q snap reporting an error health status after refresh", instanceName))
+	chg.AddAll(ts)
+	st.EnsureBefore(0)
+
+	return nil
+}
+
+// isRefreshOf returns whether the given change refreshes the given snap.
+func isRefreshOf(chg *state.Change, instanceName string) bool {
+	if chg == nil {
+		return false
+	}
+	for _, t := range chg.Tasks() {
+		if t.Kind() != "link-snap" {
+			continue
+		}
+		snapsup, err := snapstate.TaskSnapSetup(t)
+		if err != nil || snapsup.InstanceName() != instanceName || snapsup.Revert {
+			continue
+		}
+		var oldCurrent snap.Revision
+		if err := t.Get("old-current", &oldCurrent); err == nil && !oldCurrent.Unset() {
+			return true
+		}
+	}
+	return false
 }
 
 func All(st *state.State) (map[string]*HealthState, error) {
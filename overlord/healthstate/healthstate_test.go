@@ -29,6 +29,7 @@ import (
 
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/overlord"
+	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/healthstate"
 	"github.com/snapcore/snapd/overlord/hookstate"
 	"github.com/snapcore/snapd/overlord/snapstate"
@@ -251,3 +252,175 @@ func (s *healthSuite) TestSetFromHookContextEmpty(c *check.C) {
 	// no health in the context -> no health in state
 	c.Check(s.state.Get("health", &hs), testutil.ErrorIs, state.ErrNoState)
 }
+
+// setRevertWindow sets the refresh.unhealthy-revert-window option, it must be
+// called with the state lock held.
+func (s *healthSuite) setRevertWindow(c *check.C, window string) {
+	tr := config.NewTransaction(s.state)
+	c.Assert(tr.Set("core", "refresh.unhealthy-revert-window", window), check.IsNil)
+	tr.Commit()
+}
+
+func (s *healthSuite) TestSetFromHookContextErrorDuringRefresh(c *check.C) {
+	s.state.Lock()
+
+	chg := s.state.NewChange("refresh-snap", "...")
+	link := s.state.NewTask("link-snap", "...")
+	link.Set("snap-setup", &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{RealName: "test-snap", Revision: snap.R(42)},
+	})
+	link.Set("old-current", snap.R(41))
+	chg.AddTask(link)
+	task := s.state.NewTask("run-hook", "...")
+	chg.AddTask(task)
+
+	ctx, err := hookstate.NewContext(task, s.state, &hookstate.HookSetup{Snap: "test-snap", Revision: snap.R(42)}, nil, "")
+	c.Assert(err, check.IsNil)
+	s.state.Unlock()
+	ctx.Lock()
+	defer ctx.Unlock()
+
+	ctx.Set("health", &healthstate.HealthState{Revision: snap.R(42), Status: healthstate.ErrorStatus, Message: "cannot start"})
+	// the refresh is not affected unless enabled
+	c.Assert(healthstate.SetFromHookContext(ctx), check.IsNil)
+
+	s.setRevertWindow(c, "10m")
+	err = healthstate.SetFromHookContext(ctx)
+	c.Assert(err, check.ErrorMatches, `snap "test-snap" reported an error health status after refresh: cannot start`)
+
+	// the health is recorded nevertheless
+	health, err := healthstate.Get(s.state, "test-snap")
+	c.Assert(err, check.IsNil)
+	c.Check(health.Status, check.Equals, healthstate.ErrorStatus)
+
+	// other statuses do not affect the refresh
+	ctx.Set("health", &healthstate.HealthState{Revision: snap.R(42), Status: healthstate.WaitingStatus, Message: "starting up"})
+	c.Assert(healthstate.SetFromHookContext(ctx), check.IsNil)
+}
+
+func (s *healthSuite) TestSetFromHookContextErrorDuringInstall(c *check.C) {
+	s.state.Lock()
+
+	chg := s.state.NewChange("install-snap", "...")
+	link := s.state.NewTask("link-snap", "...")
+	link.Set("snap-setup", &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{RealName: "test-snap", Revision: snap.R(42)},
+	})
+	link.Set("old-current", snap.R(0))
+	chg.AddTask(link)
+	task := s.state.NewTask("run-hook", "...")
+	chg.AddTask(task)
+
+	s.setRevertWindow(c, "10m")
+
+	ctx, err := hookstate.NewContext(task, s.state, &hookstate.HookSetup{Snap: "test-snap", Revision: snap.R(42)}, nil, "")
+	c.Assert(err, check.IsNil)
+	s.state.Unlock()
+	ctx.Lock()
+	defer ctx.Unlock()
+
+	// there is nothing to revert to
+	ctx.Set("health", &healthstate.HealthState{Revision: snap.R(42), Status: healthstate.ErrorStatus, Message: "cannot start"})
+	c.Assert(healthstate.SetFromHookContext(ctx), check.IsNil)
+}
+
+func (s *healthSuite) TestSetFromEphemeralContextRevertsRecentRefresh(c *check.C) {
+	s.state.Lock()
+
+	refreshTime := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	snapstate.Set(s.state, "test-snap", &snapstate.SnapState{
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "test-snap", Revision: snap.R(41)},
+			{RealName: "test-snap", Revision: snap.R(42)},
+		}),
+		Current:         snap.R(42),
+		Active:          true,
+		SnapType:        "app",
+		LastRefreshTime: &refreshTime,
+	})
+
+	var reverted []string
+	defer healthstate.MockSnapstateRevert(func(st *state.State, name string, flags snapstate.Flags, fromChange string) (*state.TaskSet, error) {
+		reverted = append(reverted, name)
+		return state.NewTaskSet(st.NewTask("revert", "...")), nil
+	})()
+
+	ctx, err := hookstate.NewContext(nil, s.state, &hookstate.HookSetup{Snap: "test-snap", Revision: snap.R(42)}, nil, "")
+	c.Assert(err, check.IsNil)
+	s.state.Unlock()
+	ctx.Lock()
+	defer ctx.Unlock()
+	ctx.Set("health", &healthstate.HealthState{Revision: snap.R(42), Status: healthstate.ErrorStatus, Message: "cannot start"})
+
+	// not enabled
+	restore := healthstate.MockTimeNow(func() time.Time { return refreshTime.Add(time.Minute) })
+	c.Assert(healthstate.SetFromHookContext(ctx), check.IsNil)
+	restore()
+	c.Check(reverted, check.HasLen, 0)
+	c.Check(s.state.Changes(), check.HasLen, 0)
+
+	s.setRevertWindow(c, "10m")
+
+	// outside of the window
+	restore = healthstate.MockTimeNow(func() time.Time { return refreshTime.Add(time.Hour) })
+	c.Assert(healthstate.SetFromHookContext(ctx), check.IsNil)
+	restore()
+	c.Check(reverted, check.HasLen, 0)
+	c.Check(s.state.Changes(), check.HasLen, 0)
+
+	defer healthstate.MockTimeNow(func() time.Time { return refreshTime.Add(time.Minute) })()
+	c.Assert(healthstate.SetFromHookContext(ctx), check.IsNil)
+	c.Check(reverted, check.DeepEquals, []string{"test-snap"})
+	c.Assert(s.state.Changes(), check.HasLen, 1)
+	chg := s.state.Changes()[0]
+	c.Check(chg.Kind(), check.Equals, "revert-snap")
+	c.Check(chg.Summary(), check.Equals, `Revert "test-snap" snap reporting an error health status after refresh`)
+	c.Check(chg.Tasks(), check.HasLen, 1)
+}
+
+func (s *healthSuite) TestSetFromEphemeralContextNoRevert(c *check.C) {
+	s.state.Lock()
+
+	refreshTime := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	defer healthstate.MockTimeNow(func() time.Time { return refreshTime.Add(time.Minute) })()
+	defer healthstate.MockSnapstateRevert(func(st *state.State, name string, flags snapstate.Flags, fromChange string) (*state.TaskSet, error) {
+		c.Fatalf("unexpected revert of %q", name)
+		return nil, nil
+	})()
+	s.setRevertWindow(c, "10m")
+
+	// a single revision was installed recently
+	snapstate.Set(s.state, "test-snap", &snapstate.SnapState{
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "test-snap", Revision: snap.R(42)},
+		}),
+		Current:         snap.R(42),
+		Active:          true,
+		SnapType:        "app",
+		LastRefreshTime: &refreshTime,
+	})
+
+	ctx, err := hookstate.NewContext(nil, s.state, &hookstate.HookSetup{Snap: "test-snap", Revision: snap.R(42)}, nil, "")
+	c.Assert(err, check.IsNil)
+	s.state.Unlock()
+	ctx.Lock()
+	defer ctx.Unlock()
+	ctx.Set("health", &healthstate.HealthState{Revision: snap.R(42), Status: healthstate.ErrorStatus, Message: "cannot start"})
+	c.Assert(healthstate.SetFromHookContext(ctx), check.IsNil)
+
+	// the health is not about the current revision
+	snapstate.Set(s.state, "test-snap", &snapstate.SnapState{
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "test-snap", Revision: snap.R(41)},
+			{RealName: "test-snap", Revision: snap.R(42)},
+		}),
+		Current:         snap.R(42),
+		Active:          true,
+		SnapType:        "app",
+		LastRefreshTime: &refreshTime,
+	})
+	ctx.Set("health", &healthstate.HealthState{Revision: snap.R(41), Status: healthstate.ErrorStatus, Message: "cannot start"})
+	c.Assert(healthstate.SetFromHookContext(ctx), check.IsNil)
+
+	c.Check(s.state.Changes(), check.HasLen, 0)
+}
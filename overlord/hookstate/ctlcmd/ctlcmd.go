@@ -29,6 +29,7 @@ import (
 	"github.com/jessevdk/go-flags"
 
 	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/osutil/sys"
 	"github.com/snapcore/snapd/overlord/hookstate"
 	"github.com/snapcore/snapd/strutil"
 )
@@ -57,6 +58,15 @@ func (c *baseCommand) setUid(uid uint32) {
 	c.uid = strconv.FormatUint(uint64(uid), 10)
 }
 
+// userID returns the uid of the user running the command.
+func (c *baseCommand) userID() (sys.UserID, error) {
+	uid, err := strconv.ParseUint(c.uid, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("internal error: cannot parse uid %q: %v", c.uid, err)
+	}
+	return sys.UserID(uid), nil
+}
+
 func (c *baseCommand) setStdout(w io.Writer) {
 	c.stdout = w
 }
@@ -199,6 +209,11 @@ func isAllowedToRun(uid uint32, args []string) bool {
 			return true
 		}
 
+		// Users can change their own configuration of the snap.
+		if idx > 0 && args[0] == "set" && arg == "--user" {
+			return true
+		}
+
 		// Invoking help is always allowed.
 		if arg == "-h" || arg == "--help" {
 			return true
@@ -160,3 +160,12 @@ func (s *ctlcmdSuite) TestRunNonRootAllowedCommandWithAllowedCmdAsArg(c *C) {
 	c.Check(err, FitsTypeOf, &ctlcmd.ForbiddenCommandError{})
 	c.Check(err.Error(), Equals, `cannot use "set" with uid 1000, try with sudo`)
 }
+
+func (s *ctlcmdSuite) TestRunNonRootSetUser(c *C) {
+	// regular users may only change their own configuration with set
+	_, _, err := ctlcmd.Run(s.mockContext, []string{"set", "--", "--user"}, 1000)
+	c.Check(err, FitsTypeOf, &ctlcmd.ForbiddenCommandError{})
+
+	_, _, err = ctlcmd.Run(s.mockContext, []string{"set", "--user", "a=b"}, 1000)
+	c.Check(err, Not(FitsTypeOf), &ctlcmd.ForbiddenCommandError{})
+}
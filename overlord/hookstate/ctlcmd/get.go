@@ -51,7 +51,8 @@ type getCommand struct {
 	ForceSlotSide bool `long:"slot" description:"return attribute values from the slot side of the connection"`
 	ForcePlugSide bool `long:"plug" description:"return attribute values from the plug side of the connection"`
 	View          bool `long:"view" description:"return confdb values from the view declared in the plug"`
-	Pristine      bool `long:"pristine" description:"return confdb values disregarding changes from the current transaction"`
+	Pristine      bool `long:"pristine" description:"return configuration or confdb values disregarding changes from the current transaction"`
+	User          bool `long:"user" description:"return configuration values of the user running the command"`
 
 	Positional struct {
 		PlugOrSlotSpec string   `positional-args:"true" positional-arg-name:":<plug|slot>"`
@@ -96,6 +97,22 @@ with optional --plug and --slot command options:
 
 This requests the "usb-vendor" setting from the slot that is connected to
 "myplug".
+
+Values are printed with strict typing, with quoted strings and null for unset
+options, using the -t option:
+
+    $ snapctl get -t username
+    "frank"
+
+Configuration options may be printed as they were before the changes made by
+the current hook, for instance to migrate them in the configure hook, with:
+
+    $ snapctl get --pristine username
+
+Applications may print the options from the configuration of the snap for the
+user running them, as set with "snap set --user" or "snapctl set --user":
+
+    $ snapctl get --user theme
 `)
 
 func init() {
@@ -165,9 +182,6 @@ func (c *getCommand) Execute(args []string) error {
 	if c.Typed && c.Document {
 		return fmt.Errorf("cannot use -d and -t together")
 	}
-	if c.Pristine && !c.View {
-		return fmt.Errorf("cannot use --pristine without --view")
-	}
 
 	if strings.Contains(c.Positional.PlugOrSlotSpec, ":") {
 		parts := strings.SplitN(c.Positional.PlugOrSlotSpec, ":", 2)
@@ -179,6 +193,10 @@ func (c *getCommand) Execute(args []string) error {
 			return fmt.Errorf(`"snapctl get %s" not supported, use "snapctl get :%s" instead`, c.Positional.PlugOrSlotSpec, parts[1])
 		}
 
+		if c.User {
+			return fmt.Errorf("cannot use --user with interface attributes or confdb views")
+		}
+
 		if c.View {
 			if err := validateConfdbsFeatureFlag(context.State()); err != nil {
 				return err
@@ -188,6 +206,10 @@ func (c *getCommand) Execute(args []string) error {
 			return c.getConfdbValues(context, name, requests, c.Pristine)
 		}
 
+		if c.Pristine {
+			return fmt.Errorf("cannot use --pristine with interface attributes")
+		}
+
 		if len(c.Positional.Keys) == 0 {
 			return errors.New(i18n.G("get which attribute?"))
 		}
@@ -199,9 +221,46 @@ func (c *getCommand) Execute(args []string) error {
 	c.Positional.Keys = append([]string{c.Positional.PlugOrSlotSpec}, c.Positional.Keys[0:]...)
 	c.Positional.PlugOrSlotSpec = ""
 
+	if c.User {
+		return c.getUserConfigSetting(context)
+	}
 	return c.getConfigSetting(context)
 }
 
+func (c *getCommand) getUserConfigSetting(context *hookstate.Context) error {
+	if c.ForcePlugSide || c.ForceSlotSide {
+		return fmt.Errorf("cannot use --plug or --slot without <snap>:<plug|slot> argument")
+	}
+	if c.Pristine {
+		return fmt.Errorf("cannot use --pristine with --user")
+	}
+	if !context.IsEphemeral() {
+		return fmt.Errorf("cannot use --user from a hook")
+	}
+	uid, err := c.userID()
+	if err != nil {
+		return err
+	}
+
+	context.Lock()
+	defer context.Unlock()
+
+	return c.printValues(func(key string) (interface{}, bool, error) {
+		var value interface{}
+		err := config.GetUserConfig(context.State(), context.InstanceName(), uid, key, &value)
+		if err == nil {
+			return value, true, nil
+		}
+		if config.IsNoOption(err) {
+			if !c.Typed {
+				value = ""
+			}
+			return value, false, nil
+		}
+		return value, false, err
+	})
+}
+
 func (c *getCommand) getConfigSetting(context *hookstate.Context) error {
 	if c.ForcePlugSide || c.ForceSlotSide {
 		return fmt.Errorf("cannot use --plug or --slot without <snap>:<plug|slot> argument")
@@ -211,9 +270,14 @@ func (c *getCommand) getConfigSetting(context *hookstate.Context) error {
 	transaction := configstate.ContextTransaction(context)
 	context.Unlock()
 
+	get := transaction.Get
+	if c.Pristine {
+		get = transaction.GetPristine
+	}
+
 	return c.printValues(func(key string) (interface{}, bool, error) {
 		var value interface{}
-		err := transaction.Get(c.context().InstanceName(), key, &value)
+		err := get(c.context().InstanceName(), key, &value)
 		if err == nil {
 			return value, true, nil
 		}
@@ -99,6 +99,9 @@ var getTests = []struct {
 }, {
 	args:  "get :foo bar",
 	error: ".*interface attributes can only be read during the execution of interface hooks.*",
+}, {
+	args:  "get --pristine :foo bar",
+	error: "cannot use --pristine with interface attributes",
 }, {
 	args:   "get test-key1",
 	stdout: "test-value1\n",
@@ -235,6 +238,34 @@ func (s *getSuite) TestGetPartialNestedStruct(c *C) {
 	}
 }
 
+func (s *getSuite) TestGetPristine(c *C) {
+	s.mockContext.Lock()
+	tr := configstate.ContextTransaction(s.mockContext)
+	tr.Set("test-snap", "initial-key", "changed-value")
+	tr.Set("test-snap", "new-key", map[string]interface{}{"a": 1})
+	s.mockContext.Unlock()
+
+	stdout, stderr, err := ctlcmd.Run(s.mockContext, []string{"get", "initial-key"}, 0)
+	c.Assert(err, IsNil)
+	c.Check(string(stderr), Equals, "")
+	c.Check(string(stdout), Equals, "changed-value\n")
+
+	stdout, stderr, err = ctlcmd.Run(s.mockContext, []string{"get", "--pristine", "initial-key"}, 0)
+	c.Assert(err, IsNil)
+	c.Check(string(stderr), Equals, "")
+	c.Check(string(stdout), Equals, "initial-value\n")
+
+	stdout, stderr, err = ctlcmd.Run(s.mockContext, []string{"get", "--pristine", "-t", "new-key"}, 0)
+	c.Assert(err, IsNil)
+	c.Check(string(stderr), Equals, "")
+	c.Check(string(stdout), Equals, "null\n")
+
+	stdout, stderr, err = ctlcmd.Run(s.mockContext, []string{"get", "--pristine", "initial-key", "new-key"}, 0)
+	c.Assert(err, IsNil)
+	c.Check(string(stderr), Equals, "")
+	c.Check(string(stdout), Equals, "{\n\t\"initial-key\": \"initial-value\"\n}\n")
+}
+
 func (s *getSuite) TestGetRegularUser(c *C) {
 	state := state.New(nil)
 	state.Lock()
@@ -910,3 +941,38 @@ func (s *confdbSuite) TestConfdbExperimentalFlag(c *C) {
 		c.Check(stderr, IsNil)
 	}
 }
+
+func (s *getSuite) TestGetUser(c *C) {
+	st := state.New(nil)
+	st.Lock()
+	c.Assert(config.SetUserConfig(st, "test-snap", 1000, "theme", "dark"), IsNil)
+	c.Assert(config.SetUserConfig(st, "test-snap", 1001, "theme", "light"), IsNil)
+	st.Unlock()
+
+	setup := &hookstate.HookSetup{Snap: "test-snap", Revision: snap.R(1)}
+	mockContext, err := hookstate.NewContext(nil, st, setup, nil, "")
+	c.Assert(err, IsNil)
+
+	stdout, stderr, err := ctlcmd.Run(mockContext, []string{"get", "--user", "theme"}, 1000)
+	c.Assert(err, IsNil)
+	c.Check(string(stdout), Equals, "dark\n")
+	c.Check(string(stderr), Equals, "")
+
+	stdout, _, err = ctlcmd.Run(mockContext, []string{"get", "--user", "theme"}, 1001)
+	c.Assert(err, IsNil)
+	c.Check(string(stdout), Equals, "light\n")
+
+	// unset options of the user are empty
+	stdout, _, err = ctlcmd.Run(mockContext, []string{"get", "--user", "theme"}, 1002)
+	c.Assert(err, IsNil)
+	c.Check(string(stdout), Equals, "\n")
+}
+
+func (s *getSuite) TestGetUserErrors(c *C) {
+	_, _, err := ctlcmd.Run(s.mockContext, []string{"get", "--user", "theme"}, 1000)
+	c.Check(err, ErrorMatches, "cannot use --user from a hook")
+	_, _, err = ctlcmd.Run(s.mockContext, []string{"get", "--user", "--pristine", "theme"}, 0)
+	c.Check(err, ErrorMatches, "cannot use --pristine with --user")
+	_, _, err = ctlcmd.Run(s.mockContext, []string{"get", "--user", ":myplug", "foo"}, 0)
+	c.Check(err, ErrorMatches, "cannot use --user with interface attributes or confdb views")
+}
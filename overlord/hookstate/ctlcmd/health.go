@@ -57,7 +57,10 @@ status can be one of:
   configured); the message must be sufficient to point the user in the right
   direction.
 
-- error: something is broken; the message must explain what.
+- error: something is broken; the message must explain what. If the
+  refresh.unhealthy-revert-window system option is set, the snap is reverted to
+  its previous revision when this is reported during a refresh, or within that
+  window after it.
 `)
 )
 
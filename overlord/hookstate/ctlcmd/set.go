@@ -40,6 +40,7 @@ type setCommand struct {
 	baseCommand
 
 	View bool `long:"view" description:"return confdb values from the view declared in the plug"`
+	User bool `long:"user" description:"set configuration values of the user running the command"`
 
 	Positional struct {
 		PlugOrSlotSpec string   `positional-arg-name:":<plug|slot>"`
@@ -70,6 +71,11 @@ Plug and slot attributes may be set in the respective prepare and connect hooks
 by naming the respective plug or slot:
 
     $ snapctl set :myplug path=/dev/ttyS0
+
+Applications may change the configuration of the snap for the user running
+them, which is applied right away and does not run the configure hook:
+
+    $ snapctl set --user theme=dark
 `)
 
 func init() {
@@ -95,9 +101,16 @@ func (s *setCommand) Execute(args []string) error {
 	if strings.Contains(s.Positional.PlugOrSlotSpec, "=") || !strings.Contains(s.Positional.PlugOrSlotSpec, ":") {
 		s.Positional.ConfValues = append([]string{s.Positional.PlugOrSlotSpec}, s.Positional.ConfValues[0:]...)
 		s.Positional.PlugOrSlotSpec = ""
+		if s.User {
+			return s.setUserConfigSetting(context)
+		}
 		return s.setConfigSetting(context)
 	}
 
+	if s.User {
+		return fmt.Errorf("cannot use --user with interface attributes or confdb views")
+	}
+
 	parts := strings.SplitN(s.Positional.PlugOrSlotSpec, ":", 2)
 	snap, name := parts[0], parts[1]
 	if name == "" {
@@ -142,6 +155,26 @@ func (s *setCommand) setConfigSetting(context *hookstate.Context) error {
 	return nil
 }
 
+func (s *setCommand) setUserConfigSetting(context *hookstate.Context) error {
+	if !context.IsEphemeral() {
+		return fmt.Errorf("cannot use --user from a hook")
+	}
+	uid, err := s.userID()
+	if err != nil {
+		return err
+	}
+
+	opts := &clientutil.ParseConfigOptions{String: s.String, Typed: s.Typed}
+	confValues, _, err := clientutil.ParseConfigValues(s.Positional.ConfValues, opts)
+	if err != nil {
+		return err
+	}
+
+	context.Lock()
+	defer context.Unlock()
+	return config.PatchUserConfig(context.State(), context.InstanceName(), uid, confValues)
+}
+
 func setInterfaceAttribute(context *hookstate.Context, staticAttrs map[string]interface{}, dynamicAttrs map[string]interface{}, key string, value interface{}) error {
 	data, err := json.Marshal(value)
 	if err != nil {
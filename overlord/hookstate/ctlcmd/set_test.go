@@ -573,3 +573,56 @@ func (s *confdbSuite) TestConfdbOnlyChangeViewCanSet(c *C) {
 	c.Check(stdout, IsNil)
 	c.Check(stderr, IsNil)
 }
+
+func (s *setSuite) TestSetUser(c *C) {
+	st := s.mockContext.State()
+	setup := &hookstate.HookSetup{Snap: "test-snap", Revision: snap.R(1)}
+	mockContext, err := hookstate.NewContext(nil, st, setup, nil, "")
+	c.Assert(err, IsNil)
+
+	// regular users can change their own configuration
+	stdout, stderr, err := ctlcmd.Run(mockContext, []string{"set", "--user", "theme=dark", "window.width=800"}, 1000)
+	c.Assert(err, IsNil)
+	c.Check(string(stdout), Equals, "")
+	c.Check(string(stderr), Equals, "")
+
+	st.Lock()
+	defer st.Unlock()
+	var all map[string]interface{}
+	c.Assert(config.GetUserConfig(st, "test-snap", 1000, "", &all), IsNil)
+	c.Check(all, DeepEquals, map[string]interface{}{
+		"theme":  "dark",
+		"window": map[string]interface{}{"width": json.Number("800")},
+	})
+
+	// the system configuration is left untouched
+	var value interface{}
+	err = config.NewTransaction(st).Get("test-snap", "theme", &value)
+	c.Check(config.IsNoOption(err), Equals, true)
+}
+
+func (s *setSuite) TestSetUserTooLarge(c *C) {
+	st := s.mockContext.State()
+	setup := &hookstate.HookSetup{Snap: "test-snap", Revision: snap.R(1)}
+	mockContext, err := hookstate.NewContext(nil, st, setup, nil, "")
+	c.Assert(err, IsNil)
+
+	_, _, err = ctlcmd.Run(mockContext, []string{"set", "--user", "theme=" + strings.Repeat("x", 128*1024)}, 1000)
+	c.Check(err, ErrorMatches, `cannot set snap "test-snap" user configuration: configuration would exceed the limit of 65536 bytes`)
+
+	st.Lock()
+	defer st.Unlock()
+	var value interface{}
+	err = config.GetUserConfig(st, "test-snap", 1000, "theme", &value)
+	c.Check(config.IsNoOption(err), Equals, true)
+}
+
+func (s *setSuite) TestSetUserFromHook(c *C) {
+	_, _, err := ctlcmd.Run(s.mockContext, []string{"set", "--user", "theme=dark"}, 1000)
+	c.Check(err, ErrorMatches, "cannot use --user from a hook")
+}
+
+func (s *setSuite) TestSetUserWithAttributes(c *C) {
+	_, _, err := ctlcmd.Run(s.mockContext, []string{"set", "--user", ":myplug", "foo=bar"}, 0)
+	c.Check(err, ErrorMatches, "cannot use --user with interface attributes or confdb views")
+}
@@ -0,0 +1,188 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ctlcmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/snapcore/snapd/client/clientutil"
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/jsonutil"
+	"github.com/snapcore/snapd/overlord/hookstate"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+type stashCommand struct {
+	baseCommand
+
+	Positional struct {
+		Action string   `positional-arg-name:"<set|get>" required:"yes"`
+		Args   []string `positional-arg-name:"<key[=value]>"`
+	} `positional-args:"yes"`
+
+	String   bool `short:"s" description:"parse the value as a string"`
+	Typed    bool `short:"t" description:"parse the value strictly as JSON document, or strict typing with nulls and quoted strings when getting values"`
+	Document bool `short:"d" description:"always return document, even with single key"`
+}
+
+var shortStashHelp = i18n.G("Share data between the hooks of a change")
+var longStashHelp = i18n.G(`
+The stash command stores and retrieves values shared by the hooks of the
+snap which run as part of the same change. For instance, a pre-refresh hook
+can record the version of the data schema for the post-refresh hook:
+
+    $ snapctl stash set schema-version=3
+    $ snapctl stash get schema-version
+    3
+
+Values are stored with the change and are discarded with it. A value may be
+removed with an exclamation mark:
+
+    $ snapctl stash set schema-version!
+`)
+
+func init() {
+	addCommand("stash", shortStashHelp, longStashHelp, func() command { return &stashCommand{} })
+}
+
+// stashKey is the key of the change data holding the stashed values, indexed
+// by snap instance name.
+const stashKey = "snapctl-stash"
+
+var validStashKey = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+func (c *stashCommand) Execute(args []string) error {
+	context, err := c.ensureContext()
+	if err != nil {
+		return err
+	}
+	if context.IsEphemeral() {
+		return errors.New(i18n.G("cannot use stash outside of a hook"))
+	}
+
+	if len(c.Positional.Args) == 0 {
+		return fmt.Errorf(i18n.G("stash %s which key?"), c.Positional.Action)
+	}
+
+	switch c.Positional.Action {
+	case "set":
+		return c.set(context)
+	case "get":
+		return c.get(context)
+	default:
+		return fmt.Errorf(i18n.G("unknown stash action %q (want set or get)"), c.Positional.Action)
+	}
+}
+
+func (c *stashCommand) set(context *hookstate.Context) error {
+	if c.Typed && c.String {
+		return fmt.Errorf("cannot use -t and -s together")
+	}
+
+	opts := &clientutil.ParseConfigOptions{String: c.String, Typed: c.Typed}
+	values, keys, err := clientutil.ParseConfigValues(c.Positional.Args, opts)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if !validStashKey.MatchString(key) {
+			return fmt.Errorf(i18n.G("invalid stash key %q"), key)
+		}
+	}
+
+	context.Lock()
+	defer context.Unlock()
+
+	chg, stash, err := changeStash(context)
+	if err != nil {
+		return err
+	}
+	snapStash := stash[context.InstanceName()]
+	if snapStash == nil {
+		snapStash = make(map[string]*json.RawMessage)
+		stash[context.InstanceName()] = snapStash
+	}
+	for _, key := range keys {
+		if values[key] == nil {
+			delete(snapStash, key)
+			continue
+		}
+		data, err := json.Marshal(values[key])
+		if err != nil {
+			return err
+		}
+		raw := json.RawMessage(data)
+		snapStash[key] = &raw
+	}
+	if len(snapStash) == 0 {
+		delete(stash, context.InstanceName())
+	}
+	chg.Set(stashKey, stash)
+	return nil
+}
+
+func (c *stashCommand) get(context *hookstate.Context) error {
+	context.Lock()
+	_, stash, err := changeStash(context)
+	context.Unlock()
+	if err != nil {
+		return err
+	}
+
+	snapStash := stash[context.InstanceName()]
+	patch := make(map[string]interface{})
+	for _, key := range c.Positional.Args {
+		raw, ok := snapStash[key]
+		if !ok {
+			continue
+		}
+		var value interface{}
+		if err := jsonutil.DecodeWithNumber(bytes.NewReader(*raw), &value); err != nil {
+			return fmt.Errorf("internal error: cannot decode stashed value of %q: %v", key, err)
+		}
+		patch[key] = value
+	}
+
+	getCmd := &getCommand{baseCommand: c.baseCommand, Document: c.Document, Typed: c.Typed}
+	getCmd.Positional.Keys = c.Positional.Args
+	return getCmd.printPatch(patch)
+}
+
+// changeStash returns the change of the hook and the values stashed in it.
+func changeStash(context *hookstate.Context) (*state.Change, map[string]map[string]*json.RawMessage, error) {
+	task, _ := context.Task()
+	chg := task.Change()
+	if chg == nil {
+		return nil, nil, fmt.Errorf("internal error: hook task %s is not part of a change", task.ID())
+	}
+
+	var stash map[string]map[string]*json.RawMessage
+	if err := chg.Get(stashKey, &stash); err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, nil, err
+	}
+	if stash == nil {
+		stash = make(map[string]map[string]*json.RawMessage)
+	}
+	return chg, stash, nil
+}
@@ -0,0 +1,165 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ctlcmd_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/overlord/hookstate"
+	"github.com/snapcore/snapd/overlord/hookstate/ctlcmd"
+	"github.com/snapcore/snapd/overlord/hookstate/hooktest"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type stashSuite struct {
+	testutil.BaseTest
+	st          *state.State
+	mockHandler *hooktest.MockHandler
+	chg         *state.Change
+}
+
+var _ = Suite(&stashSuite{})
+
+func (s *stashSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+	s.st = state.New(nil)
+	s.mockHandler = hooktest.NewMockHandler()
+
+	s.st.Lock()
+	s.chg = s.st.NewChange("refresh", "refresh snaps")
+	s.st.Unlock()
+}
+
+func (s *stashSuite) hookContext(c *C, chg *state.Change, snapName, hook string) *hookstate.Context {
+	s.st.Lock()
+	defer s.st.Unlock()
+	task := s.st.NewTask("run-hook", "run hook")
+	if chg != nil {
+		chg.AddTask(task)
+	}
+	setup := &hookstate.HookSetup{Snap: snapName, Revision: snap.R(1), Hook: hook}
+	context, err := hookstate.NewContext(task, s.st, setup, s.mockHandler, "")
+	c.Assert(err, IsNil)
+	return context
+}
+
+func (s *stashSuite) run(c *C, context *hookstate.Context, args ...string) string {
+	stdout, stderr, err := ctlcmd.Run(context, append([]string{"stash"}, args...), 0)
+	c.Assert(err, IsNil)
+	c.Check(string(stderr), Equals, "")
+	return string(stdout)
+}
+
+func (s *stashSuite) TestStashSharedBetweenHooksOfChange(c *C) {
+	preRefresh := s.hookContext(c, s.chg, "test-snap", "pre-refresh")
+	c.Check(s.run(c, preRefresh, "set", "schema-version=3", "name=foo", `info={"a": 1}`), Equals, "")
+
+	postRefresh := s.hookContext(c, s.chg, "test-snap", "post-refresh")
+	c.Check(s.run(c, postRefresh, "get", "schema-version"), Equals, "3\n")
+	c.Check(s.run(c, postRefresh, "get", "name"), Equals, "foo\n")
+	c.Check(s.run(c, postRefresh, "get", "-t", "name"), Equals, "\"foo\"\n")
+	c.Check(s.run(c, postRefresh, "get", "schema-version", "info"), Equals, `{
+	"info": {
+		"a": 1
+	},
+	"schema-version": 3
+}
+`)
+	c.Check(s.run(c, postRefresh, "get", "-d", "name"), Equals, `{
+	"name": "foo"
+}
+`)
+	c.Check(s.run(c, postRefresh, "get", "missing"), Equals, "\n")
+
+	// values can be removed
+	c.Check(s.run(c, postRefresh, "set", "name!"), Equals, "")
+	c.Check(s.run(c, preRefresh, "get", "name"), Equals, "\n")
+	c.Check(s.run(c, preRefresh, "get", "schema-version"), Equals, "3\n")
+}
+
+func (s *stashSuite) TestStashIsolatedBetweenSnapsAndChanges(c *C) {
+	context := s.hookContext(c, s.chg, "test-snap", "pre-refresh")
+	c.Check(s.run(c, context, "set", "schema-version=3"), Equals, "")
+
+	// another snap in the same change
+	other := s.hookContext(c, s.chg, "other-snap", "post-refresh")
+	c.Check(s.run(c, other, "get", "schema-version"), Equals, "\n")
+
+	// the same snap in another change
+	s.st.Lock()
+	chg := s.st.NewChange("refresh", "refresh snaps")
+	s.st.Unlock()
+	later := s.hookContext(c, chg, "test-snap", "post-refresh")
+	c.Check(s.run(c, later, "get", "schema-version"), Equals, "\n")
+
+	s.st.Lock()
+	defer s.st.Unlock()
+	var stash map[string]map[string]interface{}
+	c.Assert(s.chg.Get("snapctl-stash", &stash), IsNil)
+	c.Check(stash, DeepEquals, map[string]map[string]interface{}{
+		"test-snap": {"schema-version": 3.0},
+	})
+}
+
+func (s *stashSuite) TestStashErrors(c *C) {
+	context := s.hookContext(c, s.chg, "test-snap", "pre-refresh")
+	for _, t := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"set"}, `stash set which key\?`},
+		{[]string{"get"}, `stash get which key\?`},
+		{[]string{"unset", "foo"}, `unknown stash action "unset" \(want set or get\)`},
+		{[]string{"set", "foo"}, `invalid configuration: "foo" \(want key=value\)`},
+		{[]string{"set", "Foo=1"}, `invalid stash key "Foo"`},
+		{[]string{"set", "foo.bar=1"}, `invalid stash key "foo.bar"`},
+		{[]string{"set", "-t", "-s", "foo=1"}, `cannot use -t and -s together`},
+		{[]string{"set", "-t", "foo=bar"}, `failed to parse JSON: .*`},
+	} {
+		_, _, err := ctlcmd.Run(context, append([]string{"stash"}, t.args...), 0)
+		c.Check(err, ErrorMatches, t.err, Commentf("%v", t.args))
+	}
+
+	s.st.Lock()
+	defer s.st.Unlock()
+	var stash map[string]interface{}
+	c.Check(s.chg.Get("snapctl-stash", &stash), testutil.ErrorIs, state.ErrNoState)
+}
+
+func (s *stashSuite) TestStashEphemeralContext(c *C) {
+	context, err := hookstate.NewContext(nil, s.st, nil, nil, "")
+	c.Assert(err, IsNil)
+	_, _, err = ctlcmd.Run(context, []string{"stash", "get", "foo"}, 0)
+	c.Check(err, ErrorMatches, "cannot use stash outside of a hook")
+}
+
+func (s *stashSuite) TestStashTaskWithoutChange(c *C) {
+	context := s.hookContext(c, nil, "test-snap", "pre-refresh")
+	_, _, err := ctlcmd.Run(context, []string{"stash", "get", "foo"}, 0)
+	c.Check(err, ErrorMatches, `internal error: hook task [0-9]+ is not part of a change`)
+}
+
+func (s *stashSuite) TestStashRequiresRoot(c *C) {
+	context := s.hookContext(c, s.chg, "test-snap", "pre-refresh")
+	_, _, err := ctlcmd.Run(context, []string{"stash", "get", "foo"}, 1000)
+	c.Check(err, FitsTypeOf, &ctlcmd.ForbiddenCommandError{})
+}
@@ -20,6 +20,8 @@
 package ctlcmd
 
 import (
+	"encoding/json"
+
 	"gopkg.in/yaml.v2"
 
 	"github.com/snapcore/snapd/i18n"
@@ -29,6 +31,7 @@ import (
 
 type systemModeCommand struct {
 	baseCommand
+	Json bool `long:"json"`
 }
 
 var shortSystemModeHelp = i18n.G("Get the current system mode and associated details")
@@ -36,7 +39,7 @@ var shortSystemModeHelp = i18n.G("Get the current system mode and associated det
 var longSystemModeHelp = i18n.G(`
 The system-mode command returns information about the device's current system mode.
 
-This information includes the mode itself and whether the model snaps have been installed from the seed (seed-loaded). The system mode is either run, recover, or install.
+This information includes the mode itself and whether the model snaps have been installed from the seed (seed-loaded). The system mode is either run, recover, install or factory-reset.
 
 Retrieved information can also include "factory mode" details: 'factory: true' declares whether the device booted an image flagged as for factory use. This flag can be set for convenience when building the image. No security sensitive decisions should be based on this bit alone.
 
@@ -45,6 +48,8 @@ The output is in YAML format. Example output:
     system-mode: install
     seed-loaded: true
     factory: true
+
+The output can be changed to JSON format by using the --json flag.
 `)
 
 func init() {
@@ -54,9 +59,9 @@ func init() {
 var devicestateSystemModeInfoFromState = devicestate.SystemModeInfoFromState
 
 type systemModeResult struct {
-	SystemMode string `yaml:"system-mode,omitempty"`
-	Seeded     bool   `yaml:"seed-loaded"`
-	Factory    bool   `yaml:"factory,omitempty"`
+	SystemMode string `yaml:"system-mode,omitempty" json:"system-mode,omitempty"`
+	Seeded     bool   `yaml:"seed-loaded" json:"seed-loaded"`
+	Factory    bool   `yaml:"factory,omitempty" json:"factory,omitempty"`
 }
 
 func (c *systemModeCommand) Execute(args []string) error {
@@ -82,6 +87,15 @@ func (c *systemModeCommand) Execute(args []string) error {
 		res.Factory = true
 	}
 
+	if c.Json {
+		b, err := json.MarshalIndent(res, "", "  ")
+		if err != nil {
+			return err
+		}
+		c.printf("%s\n", string(b))
+		return nil
+	}
+
 	b, err := yaml.Marshal(res)
 	if err != nil {
 		return err
@@ -124,3 +124,58 @@ func (s *systemModeSuite) TestSystemMode(c *C) {
 		}
 	}
 }
+
+func (s *systemModeSuite) TestSystemModeJSON(c *C) {
+	s.st.Lock()
+	task := s.st.NewTask("test-task", "my test task")
+	setup := &hookstate.HookSetup{Snap: "snap1", Revision: snap.R(1), Hook: "test-hook"}
+	mockContext, err := hookstate.NewContext(task, s.st, setup, s.mockHandler, "")
+	c.Check(err, IsNil)
+	s.st.Unlock()
+
+	var smi *devicestate.SystemModeInfo
+	r := ctlcmd.MockDevicestateSystemModeInfoFromState(func(s *state.State) (*devicestate.SystemModeInfo, error) {
+		return smi, nil
+	})
+	defer r()
+
+	tests := []struct {
+		smi    devicestate.SystemModeInfo
+		stdout string
+	}{
+		{
+			smi: devicestate.SystemModeInfo{
+				Mode:   "run",
+				Seeded: true,
+			},
+			stdout: `{
+  "system-mode": "run",
+  "seed-loaded": true
+}
+`,
+		}, {
+			smi: devicestate.SystemModeInfo{
+				Mode:       "factory-reset",
+				HasModeenv: true,
+				Seeded:     false,
+				BootFlags:  []string{"factory"},
+			},
+			stdout: `{
+  "system-mode": "factory-reset",
+  "seed-loaded": false,
+  "factory": true
+}
+`,
+		},
+	}
+
+	for _, test := range tests {
+		smi = &test.smi
+
+		stdout, stderr, err := ctlcmd.Run(mockContext, []string{"system-mode", "--json"}, 1000)
+		comment := Commentf("%v", test)
+		c.Check(err, IsNil, comment)
+		c.Check(string(stdout), Equals, test.stdout, comment)
+		c.Check(string(stderr), Equals, "", comment)
+	}
+}
@@ -36,6 +36,7 @@ import (
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/restart"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
@@ -349,6 +350,7 @@ func (m *HookManager) runHook(context *Context, snapst *snapstate.SnapState, hoo
 	// hijacking component hooks as well.
 	mustHijack := context.IsSnapHook() && m.hijacked(hooksup.Hook, hooksup.Snap) != nil
 	hookExists := false
+	var hookInfo *snap.HookInfo
 
 	if !mustHijack {
 		// not hijacked, snap must be installed
@@ -362,7 +364,8 @@ func (m *HookManager) runHook(context *Context, snapst *snapstate.SnapState, hoo
 		}
 
 		if context.IsSnapHook() {
-			hookExists = info.Hooks[hooksup.Hook] != nil
+			hookInfo = info.Hooks[hooksup.Hook]
+			hookExists = hookInfo != nil
 			if !hookExists && !hooksup.Optional {
 				return fmt.Errorf("snap %q has no %q hook", hooksup.Snap, hooksup.Hook)
 			}
@@ -375,7 +378,8 @@ func (m *HookManager) runHook(context *Context, snapst *snapstate.SnapState, hoo
 				return fmt.Errorf(`cannot read "%s+%s" component details: %v`, info.SnapName(), hooksup.Component, err)
 			}
 
-			hookExists = comp.Hooks[hooksup.Hook] != nil
+			hookInfo = comp.Hooks[hooksup.Hook]
+			hookExists = hookInfo != nil
 			if !hookExists && !hooksup.Optional {
 				return fmt.Errorf(`component "%s+%s" has no %q hook`, info.SnapName(), hooksup.Component, hooksup.Hook)
 			}
@@ -427,6 +431,16 @@ func (m *HookManager) runHook(context *Context, snapst *snapstate.SnapState, hoo
 		return err
 	}
 
+	if hookExists {
+		context.Lock()
+		timeout, err := hookTimeout(m.state, hookInfo, hooksup.Timeout)
+		context.Unlock()
+		if err != nil {
+			return err
+		}
+		hooksup.Timeout = timeout
+	}
+
 	// some hooks get hijacked, e.g. the core configuration
 	var err error
 	var output []byte
@@ -508,6 +522,46 @@ func snapCmd() string {
 
 var defaultHookTimeout = 10 * time.Minute
 
+// hookTimeout returns the maximum time the given hook can run. The timeout
+// declared by the hook takes precedence over the one requested when setting
+// up the hook, but it can be longer only up to the hooks.timeout system
+// option set by the administrator. The state must be locked.
+func hookTimeout(st *state.State, hook *snap.HookInfo, setupTimeout time.Duration) (time.Duration, error) {
+	declared := time.Duration(hook.Timeout)
+	if declared <= 0 {
+		return setupTimeout, nil
+	}
+	timeout := setupTimeout
+	if timeout == 0 {
+		timeout = defaultHookTimeout
+	}
+	if declared <= timeout {
+		return declared, nil
+	}
+
+	tr := config.NewTransaction(st)
+	var maxTimeoutStr string
+	if err := tr.GetMaybe("core", "hooks.timeout", &maxTimeoutStr); err != nil {
+		return 0, err
+	}
+	if maxTimeoutStr == "" {
+		return timeout, nil
+	}
+	maxTimeout, err := time.ParseDuration(maxTimeoutStr)
+	if err != nil {
+		// validated by configcore, it cannot happen in practice
+		logger.Noticef("cannot parse hooks.timeout: %v", err)
+		return timeout, nil
+	}
+	if declared > maxTimeout {
+		declared = maxTimeout
+	}
+	if declared < timeout {
+		return timeout, nil
+	}
+	return declared, nil
+}
+
 func runHookAndWait(hookSource string, revision snap.Revision, hookName, hookContext string, timeout time.Duration, tomb *tomb.Tomb) ([]byte, error) {
 	argv := []string{snapCmd(), "run", "--hook", hookName, "-r", revision.String(), hookSource}
 	if timeout == 0 {
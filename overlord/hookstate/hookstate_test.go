@@ -34,6 +34,7 @@ import (
 
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/overlord"
+	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/hookstate"
 	"github.com/snapcore/snapd/overlord/hookstate/hooktest"
 	"github.com/snapcore/snapd/overlord/restart"
@@ -528,6 +529,66 @@ func (s *hookManagerSuite) TestHookTaskEnforcesDefaultTimeout(c *C) {
 	checkTaskLogContains(c, s.task, `.*exceeded maximum runtime of 150ms`)
 }
 
+func (s *hookManagerSuite) runHookWithTimeouts(c *C, hookTimeout string, setupTimeout time.Duration) time.Duration {
+	s.state.Lock()
+	sideInfo := &snap.SideInfo{RealName: "timeout-snap", SnapID: "timeout-snap-id", Revision: snap.R(1)}
+	snaptest.MockSnap(c, fmt.Sprintf(`
+name: timeout-snap
+version: 1.0
+hooks:
+    configure:
+        timeout: %s
+`, hookTimeout), sideInfo)
+	snapstate.Set(s.state, "timeout-snap", &snapstate.SnapState{
+		Active:   true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{sideInfo}),
+		Current:  snap.R(1),
+	})
+	s.state.Unlock()
+
+	var timeout time.Duration
+	restore := hookstate.MockRunHook(func(ctx *hookstate.Context, tomb *tomb.Tomb) ([]byte, error) {
+		timeout = ctx.Timeout()
+		return nil, nil
+	})
+	defer restore()
+
+	hooksup := &hookstate.HookSetup{
+		Snap:     "timeout-snap",
+		Revision: snap.R(1),
+		Hook:     "configure",
+		Timeout:  setupTimeout,
+	}
+	_, err := s.manager.EphemeralRunHook(context.Background(), hooksup, nil)
+	c.Assert(err, IsNil)
+	return timeout
+}
+
+func (s *hookManagerSuite) TestHookTimeoutDeclaredBySnap(c *C) {
+	// without the system option hooks can only shorten their timeout
+	c.Check(s.runHookWithTimeouts(c, "30m", 5*time.Minute), Equals, 5*time.Minute)
+	c.Check(s.runHookWithTimeouts(c, "30m", 0), Equals, 10*time.Minute)
+	c.Check(s.runHookWithTimeouts(c, "30s", 5*time.Minute), Equals, 30*time.Second)
+	// no declared timeout
+	c.Check(s.runHookWithTimeouts(c, "0s", 5*time.Minute), Equals, 5*time.Minute)
+}
+
+func (s *hookManagerSuite) TestHookTimeoutDeclaredBySnapBoundedBySystemConfig(c *C) {
+	s.state.Lock()
+	tr := config.NewTransaction(s.state)
+	c.Assert(tr.Set("core", "hooks.timeout", "1h"), IsNil)
+	tr.Commit()
+	s.state.Unlock()
+
+	c.Check(s.runHookWithTimeouts(c, "30m", 5*time.Minute), Equals, 30*time.Minute)
+	c.Check(s.runHookWithTimeouts(c, "3h", 5*time.Minute), Equals, time.Hour)
+	c.Check(s.runHookWithTimeouts(c, "30s", 5*time.Minute), Equals, 30*time.Second)
+	// the system option does not lengthen the other hooks
+	c.Check(s.runHookWithTimeouts(c, "0s", 5*time.Minute), Equals, 5*time.Minute)
+	// nor shorten the timeout set by snapd
+	c.Check(s.runHookWithTimeouts(c, "3h", 2*time.Hour), Equals, 2*time.Hour)
+}
+
 func (s *hookManagerSuite) TestHookTaskEnforcedTimeoutWithIgnoreError(c *C) {
 	var hooksup hookstate.HookSetup
 
@@ -0,0 +1,411 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacestate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/snapcore/snapd/features"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/ifacestate/schema"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+	userclient "github.com/snapcore/snapd/usersession/client"
+)
+
+// ErrConnectionPromptNotFound is returned when a connection prompt with the
+// given ID does not exist.
+var ErrConnectionPromptNotFound = errors.New("cannot find connection prompt")
+
+var timeNow = time.Now
+
+// ConnectionPrompt describes a plug which was not auto-connected while a
+// candidate slot on the system snap exists, so that the user can be asked
+// whether the connection should be made. Prompts belong to the user who
+// requested the change installing or refreshing the snap.
+type ConnectionPrompt struct {
+	ID        string             `json:"id"`
+	Timestamp time.Time          `json:"timestamp"`
+	UserID    uint32             `json:"user-id"`
+	Interface string             `json:"interface"`
+	Plug      interfaces.PlugRef `json:"plug"`
+	Slot      interfaces.SlotRef `json:"slot"`
+}
+
+const (
+	// ConnectionPromptAllow requests the prompted connection to be made.
+	ConnectionPromptAllow = "allow"
+	// ConnectionPromptDeny rejects the prompted connection.
+	ConnectionPromptDeny = "deny"
+
+	// ConnectionPromptLifespanSingle applies the reply only to the
+	// prompt being replied to.
+	ConnectionPromptLifespanSingle = "single"
+	// ConnectionPromptLifespanForever additionally records the reply as
+	// a rule which is applied to future auto-connect attempts of the plug.
+	ConnectionPromptLifespanForever = "forever"
+)
+
+// ConnectionPromptReply holds the reply to a connection prompt.
+type ConnectionPromptReply struct {
+	Action   string `json:"action"`
+	Lifespan string `json:"lifespan,omitempty"`
+}
+
+func (r *ConnectionPromptReply) validate() error {
+	switch r.Action {
+	case ConnectionPromptAllow, ConnectionPromptDeny:
+	default:
+		return fmt.Errorf("invalid connection prompt action %q", r.Action)
+	}
+	switch r.Lifespan {
+	case "", ConnectionPromptLifespanSingle, ConnectionPromptLifespanForever:
+	default:
+		return fmt.Errorf("invalid connection prompt lifespan %q", r.Lifespan)
+	}
+	return nil
+}
+
+func getConnectionPrompts(st *state.State) (map[string]*ConnectionPrompt, error) {
+	var prompts map[string]*ConnectionPrompt
+	if err := st.Get("connection-prompts", &prompts); err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, fmt.Errorf("cannot obtain connection prompts: %v", err)
+	}
+	if prompts == nil {
+		prompts = make(map[string]*ConnectionPrompt)
+	}
+	return prompts, nil
+}
+
+// getConnectionPromptRules returns the remembered replies to connection
+// prompts, keyed by the plug reference.
+func getConnectionPromptRules(st *state.State) (map[string]string, error) {
+	var rules map[string]string
+	if err := st.Get("connection-prompt-rules", &rules); err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, fmt.Errorf("cannot obtain connection prompt rules: %v", err)
+	}
+	if rules == nil {
+		rules = make(map[string]string)
+	}
+	return rules, nil
+}
+
+// ConnectionPrompts returns the pending connection prompts of the given user
+// ordered by ID.
+func ConnectionPrompts(st *state.State, userID uint32) ([]*ConnectionPrompt, error) {
+	prompts, err := getConnectionPrompts(st)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*ConnectionPrompt, 0, len(prompts))
+	for _, prompt := range prompts {
+		if prompt.UserID != userID {
+			continue
+		}
+		result = append(result, prompt)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, _ := strconv.Atoi(result[i].ID)
+		b, _ := strconv.Atoi(result[j].ID)
+		return a < b
+	})
+	return result, nil
+}
+
+// ConnectionPromptByID returns the pending connection prompt of the given
+// user with the given ID.
+func ConnectionPromptByID(st *state.State, userID uint32, id string) (*ConnectionPrompt, error) {
+	prompts, err := getConnectionPrompts(st)
+	if err != nil {
+		return nil, err
+	}
+	prompt, ok := prompts[id]
+	// prompts of other users are not disclosed
+	if !ok || prompt.UserID != userID {
+		return nil, ErrConnectionPromptNotFound
+	}
+	return prompt, nil
+}
+
+// ReplyToConnectionPrompt resolves the connection prompt of the given user
+// with the given ID. If the reply allows the connection, the returned task
+// set connects the plug to the prompted slot; otherwise the returned task set
+// is nil. When the lifespan of the reply is "forever" the decision is
+// remembered and applied the next time the plug would be prompted for.
+func ReplyToConnectionPrompt(st *state.State, userID uint32, id string, reply *ConnectionPromptReply) (*state.TaskSet, error) {
+	if err := reply.validate(); err != nil {
+		return nil, err
+	}
+	prompts, err := getConnectionPrompts(st)
+	if err != nil {
+		return nil, err
+	}
+	prompt, ok := prompts[id]
+	if !ok || prompt.UserID != userID {
+		return nil, ErrConnectionPromptNotFound
+	}
+
+	var ts *state.TaskSet
+	if reply.Action == ConnectionPromptAllow {
+		ts, err = connect(st, prompt.Plug.Snap, prompt.Plug.Name, prompt.Slot.Snap, prompt.Slot.Name, connectOpts{})
+		if err != nil && !errors.As(err, new(*ErrAlreadyConnected)) {
+			return nil, err
+		}
+	}
+
+	if reply.Lifespan == ConnectionPromptLifespanForever {
+		rules, err := getConnectionPromptRules(st)
+		if err != nil {
+			return nil, err
+		}
+		rules[prompt.Plug.String()] = reply.Action
+		st.Set("connection-prompt-rules", rules)
+	}
+
+	delete(prompts, id)
+	st.Set("connection-prompts", prompts)
+	return ts, nil
+}
+
+func connectionPromptingEnabled(st *state.State) (bool, error) {
+	tr := config.NewTransaction(st)
+	return features.Flag(tr, features.ConnectionPrompting)
+}
+
+// systemSlotCandidate returns the single slot of the given interface offered
+// by the system snap, or nil if there is none or more than one.
+func systemSlotCandidate(repo *interfaces.Repository, iface string) *snap.SlotInfo {
+	var candidate *snap.SlotInfo
+	for _, slot := range repo.AllSlots(iface) {
+		if typ := slot.Snap.Type(); typ != snap.TypeOS && typ != snap.TypeSnapd {
+			continue
+		}
+		if candidate != nil {
+			return nil
+		}
+		candidate = slot
+	}
+	return candidate
+}
+
+// requesterUserID returns the user who requested the change of the given
+// task, as recorded by the API. Changes not requested by a user, like seeding
+// or auto-refreshes, are attributed to root.
+func requesterUserID(task *state.Task) (uint32, error) {
+	var userID uint32
+	chg := task.Change()
+	if chg == nil {
+		return 0, nil
+	}
+	if err := chg.Get("requester-uid", &userID); err != nil && !errors.Is(err, state.ErrNoState) {
+		return 0, err
+	}
+	return userID, nil
+}
+
+// addConnectionPrompts records prompts of the given user for the given plugs
+// which did not get any connection, either existing or newly auto-connected,
+// and returns the added prompts. Plugs for which an "allow" rule was
+// remembered are added to newconns instead, together with options marking
+// them as manual connections in connOpts.
+func addConnectionPrompts(st *state.State, repo *interfaces.Repository, userID uint32, plugs []*snap.PlugInfo, newconns map[string]*interfaces.ConnRef, connOpts map[string]*connectOpts, conns map[string]*schema.ConnState) ([]*ConnectionPrompt, error) {
+	prompts, err := getConnectionPrompts(st)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := getConnectionPromptRules(st)
+	if err != nil {
+		return nil, err
+	}
+
+	connected := make(map[interfaces.PlugRef]bool)
+	for id := range conns {
+		connRef, err := interfaces.ParseConnRef(id)
+		if err != nil {
+			return nil, err
+		}
+		// undesired connections were explicitly disconnected by
+		// the user, so they must not be prompted for again
+		connected[connRef.PlugRef] = true
+	}
+	for _, connRef := range newconns {
+		connected[connRef.PlugRef] = true
+	}
+	for _, prompt := range prompts {
+		connected[prompt.Plug] = true
+	}
+
+	var lastID int
+	if err := st.Get("last-connection-prompt-id", &lastID); err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, err
+	}
+	var added []*ConnectionPrompt
+	for _, plug := range plugs {
+		plugRef := interfaces.PlugRef{Snap: plug.Snap.InstanceName(), Name: plug.Name}
+		if connected[plugRef] {
+			continue
+		}
+		slot := systemSlotCandidate(repo, plug.Interface)
+		if slot == nil {
+			continue
+		}
+		switch rules[plugRef.String()] {
+		case ConnectionPromptDeny:
+			continue
+		case ConnectionPromptAllow:
+			connRef := interfaces.NewConnRef(plug, slot)
+			newconns[connRef.ID()] = connRef
+			connOpts[connRef.ID()] = &connectOpts{}
+			continue
+		}
+		lastID++
+		id := strconv.Itoa(lastID)
+		prompt := &ConnectionPrompt{
+			ID:        id,
+			Timestamp: timeNow(),
+			UserID:    userID,
+			Interface: plug.Interface,
+			Plug:      plugRef,
+			Slot:      interfaces.SlotRef{Snap: slot.Snap.InstanceName(), Name: slot.Name},
+		}
+		prompts[id] = prompt
+		added = append(added, prompt)
+	}
+	if len(added) > 0 {
+		st.Set("last-connection-prompt-id", lastID)
+		st.Set("connection-prompts", prompts)
+	}
+	return added, nil
+}
+
+var asyncConnectionPromptNotification = func(userID uint32, promptInfo *userclient.ConnectionPromptInfo) {
+	client := userclient.NewForUids(int(userID))
+	// run in a go-routine to avoid potentially slow operation
+	go func() {
+		if err := client.ConnectionPromptNotification(context.TODO(), promptInfo); err != nil {
+			logger.Noticef("cannot send connection prompt notification: %v", err)
+		}
+	}()
+}
+
+// notifyConnectionPrompts asks the session agent of the owner of each of the
+// given prompts to notify them about it.
+func notifyConnectionPrompts(prompts []*ConnectionPrompt) {
+	for _, prompt := range prompts {
+		asyncConnectionPromptNotification(prompt.UserID, &userclient.ConnectionPromptInfo{
+			ID:           prompt.ID,
+			InstanceName: prompt.Plug.Snap,
+			Interface:    prompt.Interface,
+		})
+	}
+}
+
+// removeConnectionPromptsForPlug removes the prompts for the given plug, which
+// got connected otherwise, returning them so that they can be restored on
+// undo.
+func removeConnectionPromptsForPlug(st *state.State, plugRef interfaces.PlugRef) (removed map[string]*ConnectionPrompt, err error) {
+	prompts, err := getConnectionPrompts(st)
+	if err != nil {
+		return nil, err
+	}
+	for id, prompt := range prompts {
+		if prompt.Plug != plugRef {
+			continue
+		}
+		if removed == nil {
+			removed = make(map[string]*ConnectionPrompt)
+		}
+		removed[id] = prompt
+		delete(prompts, id)
+	}
+	if len(removed) > 0 {
+		st.Set("connection-prompts", prompts)
+	}
+	return removed, nil
+}
+
+func restoreConnectionPrompts(st *state.State, removed map[string]*ConnectionPrompt) error {
+	if len(removed) == 0 {
+		return nil
+	}
+	prompts, err := getConnectionPrompts(st)
+	if err != nil {
+		return err
+	}
+	for id, prompt := range removed {
+		prompts[id] = prompt
+	}
+	st.Set("connection-prompts", prompts)
+	return nil
+}
+
+// discardConnectionPrompts removes the prompts and rules concerning the given
+// snap, returning the removed rules so that they can be restored on undo.
+func discardConnectionPrompts(st *state.State, instanceName string) (removedRules map[string]string, err error) {
+	prompts, err := getConnectionPrompts(st)
+	if err != nil {
+		return nil, err
+	}
+	for id, prompt := range prompts {
+		if prompt.Plug.Snap == instanceName || prompt.Slot.Snap == instanceName {
+			delete(prompts, id)
+		}
+	}
+	st.Set("connection-prompts", prompts)
+
+	rules, err := getConnectionPromptRules(st)
+	if err != nil {
+		return nil, err
+	}
+	for key, action := range rules {
+		if strings.HasPrefix(key, instanceName+":") {
+			if removedRules == nil {
+				removedRules = make(map[string]string)
+			}
+			removedRules[key] = action
+			delete(rules, key)
+		}
+	}
+	st.Set("connection-prompt-rules", rules)
+	return removedRules, nil
+}
+
+func restoreConnectionPromptRules(st *state.State, removedRules map[string]string) error {
+	if len(removedRules) == 0 {
+		return nil
+	}
+	rules, err := getConnectionPromptRules(st)
+	if err != nil {
+		return err
+	}
+	for key, action := range removedRules {
+		rules[key] = action
+	}
+	st.Set("connection-prompt-rules", rules)
+	return nil
+}
@@ -0,0 +1,392 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacestate_test
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts/assertstest"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/ifacestate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/testutil"
+	userclient "github.com/snapcore/snapd/usersession/client"
+)
+
+var coreSnapYamlWithTestSlot = `
+name: core
+version: 1
+type: os
+slots:
+ test:
+  interface: test
+`
+
+var promptingConsumerYaml = `
+name: consumer
+version: 1
+plugs:
+ plug:
+  interface: test
+`
+
+var connectionPromptTime = time.Date(2024, 8, 1, 10, 0, 0, 0, time.UTC)
+
+type connectionPromptNotification struct {
+	userID     uint32
+	promptInfo *userclient.ConnectionPromptInfo
+}
+
+func (s *interfaceManagerSuite) setupConnectionPrompt(c *C, enabled bool) (*snap.Info, *[]connectionPromptNotification) {
+	s.MockModel(c, nil)
+
+	s.AddCleanup(ifacestate.MockTimeNow(func() time.Time { return connectionPromptTime }))
+	var notifications []connectionPromptNotification
+	s.AddCleanup(ifacestate.MockAsyncConnectionPromptNotification(func(userID uint32, promptInfo *userclient.ConnectionPromptInfo) {
+		notifications = append(notifications, connectionPromptNotification{userID, promptInfo})
+	}))
+
+	restore := assertstest.MockBuiltinBaseDeclaration([]byte(`
+type: base-declaration
+authority-id: canonical
+series: 16
+slots:
+  test:
+    allow-auto-connection: false
+`))
+	s.AddCleanup(restore)
+
+	s.state.Lock()
+	tr := config.NewTransaction(s.state)
+	tr.Set("core", "experimental.connection-prompting", enabled)
+	tr.Commit()
+	s.state.Unlock()
+
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"})
+	s.mockSnap(c, coreSnapYamlWithTestSlot)
+	s.manager(c)
+
+	snapInfo := s.mockSnap(c, promptingConsumerYaml)
+	change := s.addSetupSnapSecurityChange(c, &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{
+			RealName: snapInfo.SnapName(),
+			Revision: snapInfo.Revision,
+		},
+	})
+	s.state.Lock()
+	// as recorded by the API for the user installing the snap
+	change.Set("requester-uid", 1000)
+	s.state.Unlock()
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Assert(change.Status(), Equals, state.DoneStatus)
+	return snapInfo, &notifications
+}
+
+func (s *interfaceManagerSuite) TestAutoConnectAddsConnectionPrompt(c *C) {
+	_, notifications := s.setupConnectionPrompt(c, true)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	var conns map[string]interface{}
+	_ = s.state.Get("conns", &conns)
+	c.Check(conns, HasLen, 0)
+
+	prompts, err := ifacestate.ConnectionPrompts(s.state, 1000)
+	c.Assert(err, IsNil)
+	c.Assert(prompts, HasLen, 1)
+	c.Check(prompts[0].ID, Equals, "1")
+	c.Check(prompts[0].Timestamp.Equal(connectionPromptTime), Equals, true)
+	c.Check(prompts[0].UserID, Equals, uint32(1000))
+	c.Check(prompts[0].Interface, Equals, "test")
+	c.Check(prompts[0].Plug, Equals, interfaces.PlugRef{Snap: "consumer", Name: "plug"})
+	c.Check(prompts[0].Slot, Equals, interfaces.SlotRef{Snap: "core", Name: "test"})
+
+	prompt, err := ifacestate.ConnectionPromptByID(s.state, 1000, "1")
+	c.Assert(err, IsNil)
+	c.Check(prompt, DeepEquals, prompts[0])
+
+	_, err = ifacestate.ConnectionPromptByID(s.state, 1000, "2")
+	c.Check(err, Equals, ifacestate.ErrConnectionPromptNotFound)
+
+	// the session agent of the user was asked to notify them
+	c.Check(*notifications, DeepEquals, []connectionPromptNotification{{
+		userID: 1000,
+		promptInfo: &userclient.ConnectionPromptInfo{
+			ID:           "1",
+			InstanceName: "consumer",
+			Interface:    "test",
+		},
+	}})
+}
+
+func (s *interfaceManagerSuite) TestConnectionPromptsOfOtherUsers(c *C) {
+	s.setupConnectionPrompt(c, true)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	for _, userID := range []uint32{0, 1001} {
+		prompts, err := ifacestate.ConnectionPrompts(s.state, userID)
+		c.Assert(err, IsNil)
+		c.Check(prompts, HasLen, 0)
+
+		_, err = ifacestate.ConnectionPromptByID(s.state, userID, "1")
+		c.Check(err, Equals, ifacestate.ErrConnectionPromptNotFound)
+
+		_, err = ifacestate.ReplyToConnectionPrompt(s.state, userID, "1", &ifacestate.ConnectionPromptReply{Action: "deny"})
+		c.Check(err, Equals, ifacestate.ErrConnectionPromptNotFound)
+	}
+
+	// the prompt is still pending for its owner
+	prompts, err := ifacestate.ConnectionPrompts(s.state, 1000)
+	c.Assert(err, IsNil)
+	c.Check(prompts, HasLen, 1)
+}
+
+func (s *interfaceManagerSuite) TestConnectRemovesConnectionPrompt(c *C) {
+	s.setupConnectionPrompt(c, true)
+
+	s.state.Lock()
+	ts, err := ifacestate.Connect(s.state, "consumer", "plug", "core", "test")
+	c.Assert(err, IsNil)
+	chg := s.state.NewChange("connect", "...")
+	chg.AddAll(ts)
+	s.state.Unlock()
+
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Assert(chg.Err(), IsNil)
+
+	// connecting the plug manually resolves the prompt
+	prompts, err := ifacestate.ConnectionPrompts(s.state, 1000)
+	c.Assert(err, IsNil)
+	c.Check(prompts, HasLen, 0)
+}
+
+func (s *interfaceManagerSuite) TestConnectUndoRestoresConnectionPrompt(c *C) {
+	s.setupConnectionPrompt(c, true)
+
+	s.state.Lock()
+	ts, err := ifacestate.Connect(s.state, "consumer", "plug", "core", "test")
+	c.Assert(err, IsNil)
+	chg := s.state.NewChange("connect", "...")
+	chg.AddAll(ts)
+	terr := s.state.NewTask("error-trigger", "provoking undo")
+	terr.WaitAll(ts)
+	chg.AddTask(terr)
+	s.state.Unlock()
+
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Assert(chg.Status(), Equals, state.ErrorStatus)
+
+	prompts, err := ifacestate.ConnectionPrompts(s.state, 1000)
+	c.Assert(err, IsNil)
+	c.Assert(prompts, HasLen, 1)
+	c.Check(prompts[0].ID, Equals, "1")
+}
+
+func (s *interfaceManagerSuite) TestAutoConnectNoConnectionPromptWhenDisabled(c *C) {
+	s.setupConnectionPrompt(c, false)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	prompts, err := ifacestate.ConnectionPrompts(s.state, 1000)
+	c.Assert(err, IsNil)
+	c.Check(prompts, HasLen, 0)
+}
+
+func (s *interfaceManagerSuite) TestReplyToConnectionPromptAllow(c *C) {
+	s.setupConnectionPrompt(c, true)
+
+	s.state.Lock()
+	ts, err := ifacestate.ReplyToConnectionPrompt(s.state, 1000, "1", &ifacestate.ConnectionPromptReply{Action: "allow"})
+	c.Assert(err, IsNil)
+	c.Assert(ts, NotNil)
+	chg := s.state.NewChange("connect", "...")
+	chg.AddAll(ts)
+	s.state.Unlock()
+
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Assert(chg.Err(), IsNil)
+
+	var conns map[string]interface{}
+	c.Assert(s.state.Get("conns", &conns), IsNil)
+	c.Check(conns, DeepEquals, map[string]interface{}{
+		"consumer:plug core:test": map[string]interface{}{"interface": "test"},
+	})
+
+	prompts, err := ifacestate.ConnectionPrompts(s.state, 1000)
+	c.Assert(err, IsNil)
+	c.Check(prompts, HasLen, 0)
+
+	// a single reply is not remembered
+	var rules map[string]string
+	c.Check(s.state.Get("connection-prompt-rules", &rules), testutil.ErrorIs, state.ErrNoState)
+}
+
+func (s *interfaceManagerSuite) TestReplyToConnectionPromptDenyForever(c *C) {
+	snapInfo, _ := s.setupConnectionPrompt(c, true)
+
+	s.state.Lock()
+	ts, err := ifacestate.ReplyToConnectionPrompt(s.state, 1000, "1", &ifacestate.ConnectionPromptReply{Action: "deny", Lifespan: "forever"})
+	c.Assert(err, IsNil)
+	c.Check(ts, IsNil)
+
+	prompts, err := ifacestate.ConnectionPrompts(s.state, 1000)
+	c.Assert(err, IsNil)
+	c.Check(prompts, HasLen, 0)
+
+	var rules map[string]string
+	c.Assert(s.state.Get("connection-prompt-rules", &rules), IsNil)
+	c.Check(rules, DeepEquals, map[string]string{"consumer:plug": "deny"})
+	s.state.Unlock()
+
+	// the remembered decision prevents prompting again
+	change := s.addSetupSnapSecurityChange(c, &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{
+			RealName: snapInfo.SnapName(),
+			Revision: snapInfo.Revision,
+		},
+	})
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Assert(change.Status(), Equals, state.DoneStatus)
+	prompts, err = ifacestate.ConnectionPrompts(s.state, 1000)
+	c.Assert(err, IsNil)
+	c.Check(prompts, HasLen, 0)
+}
+
+func (s *interfaceManagerSuite) TestReplyToConnectionPromptAllowForeverAutoConnects(c *C) {
+	snapInfo, _ := s.setupConnectionPrompt(c, true)
+
+	s.state.Lock()
+	_, err := ifacestate.ReplyToConnectionPrompt(s.state, 1000, "1", &ifacestate.ConnectionPromptReply{Action: "allow", Lifespan: "forever"})
+	c.Assert(err, IsNil)
+	s.state.Unlock()
+
+	// the remembered decision connects the plug on the next auto-connect
+	change := s.addSetupSnapSecurityChange(c, &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{
+			RealName: snapInfo.SnapName(),
+			Revision: snapInfo.Revision,
+		},
+	})
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Assert(change.Status(), Equals, state.DoneStatus)
+
+	var conns map[string]interface{}
+	c.Assert(s.state.Get("conns", &conns), IsNil)
+	c.Check(conns, DeepEquals, map[string]interface{}{
+		"consumer:plug core:test": map[string]interface{}{"interface": "test"},
+	})
+}
+
+func (s *interfaceManagerSuite) TestReplyToConnectionPromptErrors(c *C) {
+	s.setupConnectionPrompt(c, true)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	_, err := ifacestate.ReplyToConnectionPrompt(s.state, 1000, "1", &ifacestate.ConnectionPromptReply{Action: "maybe"})
+	c.Check(err, ErrorMatches, `invalid connection prompt action "maybe"`)
+	_, err = ifacestate.ReplyToConnectionPrompt(s.state, 1000, "1", &ifacestate.ConnectionPromptReply{Action: "allow", Lifespan: "timespan"})
+	c.Check(err, ErrorMatches, `invalid connection prompt lifespan "timespan"`)
+	_, err = ifacestate.ReplyToConnectionPrompt(s.state, 1000, "42", &ifacestate.ConnectionPromptReply{Action: "allow"})
+	c.Check(err, Equals, ifacestate.ErrConnectionPromptNotFound)
+}
+
+func (s *interfaceManagerSuite) TestDiscardConnsDiscardsConnectionPrompts(c *C) {
+	s.manager(c)
+
+	s.state.Lock()
+	s.state.Set("connection-prompts", map[string]interface{}{
+		"1": map[string]interface{}{
+			"id":        "1",
+			"user-id":   1000,
+			"interface": "test",
+			"plug":      map[string]interface{}{"snap": "consumer", "plug": "plug"},
+			"slot":      map[string]interface{}{"snap": "core", "slot": "test"},
+		},
+		"2": map[string]interface{}{
+			"id":        "2",
+			"user-id":   1000,
+			"interface": "test",
+			"plug":      map[string]interface{}{"snap": "other", "plug": "plug"},
+			"slot":      map[string]interface{}{"snap": "core", "slot": "test"},
+		},
+	})
+	s.state.Set("connection-prompt-rules", map[string]string{
+		"consumer:plug": "deny",
+		"other:plug":    "allow",
+	})
+	snapstate.Set(s.state, "consumer", &snapstate.SnapState{})
+	s.state.Unlock()
+
+	change, t := s.addDiscardConnsChange("consumer")
+	s.state.Lock()
+	terr := s.state.NewTask("error-trigger", "provoking undo")
+	terr.WaitFor(t)
+	change.AddTask(terr)
+	s.state.Unlock()
+
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Assert(t.Status(), Equals, state.UndoneStatus)
+
+	// prompts of the removed snap are gone
+	prompts, err := ifacestate.ConnectionPrompts(s.state, 1000)
+	c.Assert(err, IsNil)
+	c.Assert(prompts, HasLen, 1)
+	c.Check(prompts[0].ID, Equals, "2")
+
+	// while the rules were restored on undo
+	var rules map[string]string
+	c.Assert(s.state.Get("connection-prompt-rules", &rules), IsNil)
+	c.Check(rules, DeepEquals, map[string]string{
+		"consumer:plug": "deny",
+		"other:plug":    "allow",
+	})
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/testutil"
 	"github.com/snapcore/snapd/timings"
+	userclient "github.com/snapcore/snapd/usersession/client"
 )
 
 var (
@@ -108,6 +109,18 @@ func MockSnapdAppArmorServiceIsDisabled(f func() bool) (restore func()) {
 	return r
 }
 
+func MockSnapConfineMissingFeatures(f func() (string, []string, error)) (restore func()) {
+	return testutil.Mock(&snapConfineMissingFeatures, f)
+}
+
+func MockTimeNow(f func() time.Time) (restore func()) {
+	return testutil.Mock(&timeNow, f)
+}
+
+func MockAsyncConnectionPromptNotification(f func(userID uint32, promptInfo *userclient.ConnectionPromptInfo)) (restore func()) {
+	return testutil.Mock(&asyncConnectionPromptNotification, f)
+}
+
 func MockContentLinkRetryTimeout(d time.Duration) (restore func()) {
 	old := contentLinkRetryTimeout
 	contentLinkRetryTimeout = d
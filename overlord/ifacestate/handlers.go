@@ -518,6 +518,14 @@ func (m *InterfaceManager) doDiscardConns(task *state.Task, _ *tomb.Tomb) error
 	}
 	task.Set("removed", removed)
 	setConns(st, conns)
+
+	removedRules, err := discardConnectionPrompts(st, instanceName)
+	if err != nil {
+		return err
+	}
+	if len(removedRules) > 0 {
+		task.Set("removed-connection-prompt-rules", removedRules)
+	}
 	return nil
 }
 
@@ -542,6 +550,15 @@ func (m *InterfaceManager) undoDiscardConns(task *state.Task, _ *tomb.Tomb) erro
 	}
 	setConns(st, conns)
 	task.Set("removed", nil)
+
+	var removedRules map[string]string
+	if err := task.Get("removed-connection-prompt-rules", &removedRules); err != nil && !errors.Is(err, state.ErrNoState) {
+		return err
+	}
+	if err := restoreConnectionPromptRules(st, removedRules); err != nil {
+		return err
+	}
+	task.Set("removed-connection-prompt-rules", nil)
 	return nil
 }
 
@@ -735,6 +752,15 @@ func (m *InterfaceManager) doConnect(task *state.Task, _ *tomb.Tomb) (err error)
 	}
 	setConns(st, conns)
 
+	// the plug is connected now, prompting for it is moot
+	removedPrompts, err := removeConnectionPromptsForPlug(st, plugRef)
+	if err != nil {
+		return err
+	}
+	if len(removedPrompts) > 0 {
+		task.Set("removed-connection-prompts", removedPrompts)
+	}
+
 	// the dynamic attributes might have been updated by the interface's BeforeConnectPlug/Slot code,
 	// so we need to update the task for connect-plug- and connect-slot- hooks to see new values.
 	setDynamicHookAttributes(task, conn.Plug.DynamicAttrs(), conn.Slot.DynamicAttrs())
@@ -993,6 +1019,15 @@ func (m *InterfaceManager) undoConnect(task *state.Task, _ *tomb.Tomb) error {
 	}
 	setConns(st, conns)
 
+	var removedPrompts map[string]*ConnectionPrompt
+	if err := task.Get("removed-connection-prompts", &removedPrompts); err != nil && !errors.Is(err, state.ErrNoState) {
+		return err
+	}
+	if err := restoreConnectionPrompts(st, removedPrompts); err != nil {
+		return err
+	}
+	task.Set("removed-connection-prompts", nil)
+
 	if err := m.repo.Disconnect(connRef.PlugRef.Snap, connRef.PlugRef.Name, connRef.SlotRef.Snap, connRef.SlotRef.Name); err != nil {
 		return err
 	}
@@ -1498,6 +1533,26 @@ func (m *InterfaceManager) doAutoConnect(task *state.Task, _ *tomb.Tomb) error {
 		}
 	}
 
+	// Prompt for the plugs which were left unconnected
+	promptingEnabled, err := connectionPromptingEnabled(st)
+	if err != nil {
+		return err
+	}
+	if promptingEnabled {
+		if connOpts == nil {
+			connOpts = make(map[string]*connectOpts)
+		}
+		userID, err := requesterUserID(task)
+		if err != nil {
+			return err
+		}
+		prompts, err := addConnectionPrompts(st, m.repo, userID, plugs, newconns, connOpts, conns)
+		if err != nil {
+			return err
+		}
+		notifyConnectionPrompts(prompts)
+	}
+
 	autots, hasInterfaceHooks, err := batchConnectTasks(st, snapsup, newconns, connOpts)
 	if err != nil {
 		return err
@@ -1738,6 +1793,14 @@ func (m *InterfaceManager) doHotplugConnect(task *state.Task, _ *tomb.Tomb) erro
 		if !conn.HotplugGone || conn.Undesired {
 			continue
 		}
+		denied, err := isInterfaceDenied(st, conn.Interface)
+		if err != nil {
+			return err
+		}
+		if denied {
+			task.Logf("cannot recreate connection %s: interface %q is denied by the system configuration", id, conn.Interface)
+			continue
+		}
 
 		// the device was unplugged while connected, so it had disconnect hooks run; recreate the connection
 		connRef, err := interfaces.ParseConnRef(id)
@@ -43,6 +43,7 @@ import (
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/strutil"
 	"github.com/snapcore/snapd/systemd"
 	"github.com/snapcore/snapd/timings"
 )
@@ -53,6 +54,7 @@ func init() {
 
 var (
 	snapdAppArmorServiceIsDisabled = snapdAppArmorServiceIsDisabledImpl
+	snapConfineMissingFeatures     = interfaces.SnapConfineMissingFeatures
 
 	writeSystemKey = interfaces.WriteSystemKey
 )
@@ -109,10 +111,14 @@ func (m *InterfaceManager) addBackends(extra []interfaces.SecurityBackend) error
 		}
 	}
 
+	// if the flag cannot be read the apparmor_parser cache is used
+	hashCache, _ := features.Flag(config.NewTransaction(m.state), features.AppArmorHashCache)
+
 	opts := interfaces.SecurityBackendOptions{
-		Preseed:       m.preseed,
-		CoreSnapInfo:  coreSnapInfo,
-		SnapdSnapInfo: snapdSnapInfo,
+		Preseed:           m.preseed,
+		CoreSnapInfo:      coreSnapInfo,
+		SnapdSnapInfo:     snapdSnapInfo,
+		AppArmorHashCache: hashCache,
 	}
 	for _, backend := range allSecurityBackends() {
 		if err := backend.Initialize(&opts); err != nil {
@@ -162,6 +168,16 @@ var profilesNeedRegenerationImpl = func(m *InterfaceManager) bool {
 	return mismatch
 }
 
+// isInterfaceDenied returns whether connections of the given interface were
+// disabled system-wide with the interfaces.deny system option.
+func isInterfaceDenied(st *state.State, iface string) (bool, error) {
+	var deny string
+	if err := config.NewTransaction(st).GetMaybe("core", "interfaces.deny", &deny); err != nil {
+		return false, err
+	}
+	return strutil.ListContains(strutil.CommaSeparatedList(deny), iface), nil
+}
+
 // Checks whether AppArmor Prompting should be used. Caller must lock m.state.
 func (m *InterfaceManager) assesAppArmorPrompting() bool {
 	tr := config.NewTransaction(m.state)
@@ -720,6 +736,15 @@ func addNewConnection(st *state.State, task *state.Task, newconns map[string]*in
 		return nil
 	}
 
+	denied, err := isInterfaceDenied(st, plug.Interface)
+	if err != nil {
+		return err
+	}
+	if denied {
+		task.Logf("cannot connect plug %s to slot %s: interface %q is denied by the system configuration", plug, slot, plug.Interface)
+		return nil
+	}
+
 	if task.Kind() == "auto-connect" {
 		ignore, err := findSymmetricAutoconnectTask(st, plug.Snap.InstanceName(), slot.Snap.InstanceName(), task)
 		if err != nil {
@@ -21,6 +21,7 @@ package ifacestate
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -35,6 +36,7 @@ import (
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snapdenv"
+	"github.com/snapcore/snapd/snapdtool"
 	"github.com/snapcore/snapd/timings"
 )
 
@@ -93,6 +95,8 @@ func Manager(s *state.State, hookManager *hookstate.HookManager, runner *state.T
 		extraInterfaces: extraInterfaces,
 		extraBackends:   extraBackends,
 		preseed:         snapdenv.Preseeding(),
+		// there are no hotplug devices to track in containers
+		udevMonitorDisabled: snapdenv.ContainerProfile(),
 	}
 
 	taskKinds := map[string]bool{}
@@ -237,6 +241,7 @@ func (m *InterfaceManager) StartUp() error {
 		s.Warnf(`the snapd.apparmor service is disabled; snap applications will likely not start.
 Run "systemctl enable --now snapd.apparmor" to correct this.`)
 	}
+	m.checkSnapConfineCompatibility()
 
 	ifacerepo.Replace(s, m.repo)
 
@@ -248,6 +253,23 @@ Run "systemctl enable --now snapd.apparmor" to correct this.`)
 	return nil
 }
 
+// checkSnapConfineCompatibility warns if the snap-confine used to run snaps,
+// which may be packaged separately from snapd, lacks features snapd relies
+// on. Caller must lock m.state.
+func (m *InterfaceManager) checkSnapConfineCompatibility() {
+	version, missing, err := snapConfineMissingFeatures()
+	if err != nil {
+		logger.Debugf("cannot check snap-confine compatibility: %v", err)
+		return
+	}
+	if len(missing) == 0 {
+		return
+	}
+	logger.Noticef("snap-confine version %s lacks features required by snapd version %s: %s", version, snapdtool.Version, strings.Join(missing, ", "))
+	m.state.Warnf(`snap-confine version %s lacks features required by snapd version %s, the following are degraded: %s
+Update snap-confine to the version of snapd to correct this.`, version, snapdtool.Version, strings.Join(missing, ", "))
+}
+
 // Ensure implements StateManager.Ensure.
 func (m *InterfaceManager) Ensure() error {
 	// do not worry about udev monitor in preseeding mode
@@ -54,6 +54,16 @@ func (e ErrAlreadyConnected) Error() string {
 	return fmt.Sprintf("already connected: %q", e.Connection.ID())
 }
 
+// ErrInterfaceDenied describes the error that occurs when attempting to
+// connect an interface disabled with the interfaces.deny system option.
+type ErrInterfaceDenied struct {
+	Interface string
+}
+
+func (e *ErrInterfaceDenied) Error() string {
+	return fmt.Sprintf("cannot connect %q interface: denied by the system configuration", e.Interface)
+}
+
 // findSymmetricAutoconnectTask checks if there is another auto-connect task affecting same snap because of plug/slot.
 func findSymmetricAutoconnectTask(st *state.State, plugSnap, slotSnap string, installTask *state.Task) (bool, error) {
 	snapsup, err := snapstate.TaskSnapSetup(installTask)
@@ -140,6 +150,16 @@ func connect(st *state.State, plugSnap, plugName, slotSnap, slotName string, fla
 		return nil, err
 	}
 
+	if plug, ok := plugSnapInfo.Plugs[plugName]; ok {
+		denied, err := isInterfaceDenied(st, plug.Interface)
+		if err != nil {
+			return nil, err
+		}
+		if denied {
+			return nil, &ErrInterfaceDenied{Interface: plug.Interface}
+		}
+	}
+
 	plugStatic, slotStatic, err := initialConnectAttributes(st, plugSnapInfo, plugSnap, plugName, slotSnapInfo, slotSnap, slotName)
 	if err != nil {
 		return nil, err
@@ -321,6 +341,20 @@ func Disconnect(st *state.State, conn *interfaces.Connection) (*state.TaskSet, e
 	return disconnectTasks(st, conn, disconnectOpts{})
 }
 
+// DisconnectDenied returns a set of tasks for disconnecting a connection of an
+// interface denied with the interfaces.deny system option. Unlike with
+// Disconnect, automatic connections are not recorded as undesired, so they
+// are made again by auto-connection once the interface is allowed again.
+func DisconnectDenied(st *state.State, conn *interfaces.Connection) (*state.TaskSet, error) {
+	plugSnap := conn.Plug.Snap().InstanceName()
+	slotSnap := conn.Slot.Snap().InstanceName()
+	if err := snapstate.CheckChangeConflictMany(st, []string{plugSnap, slotSnap}, ""); err != nil {
+		return nil, err
+	}
+
+	return disconnectTasks(st, conn, disconnectOpts{AutoDisconnect: true})
+}
+
 // Forget returs a set of tasks for disconnecting and forgetting an interface.
 // If the interface is already disconnected, it will be removed from the state
 // (forgotten).
@@ -43,6 +43,7 @@ import (
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/overlord"
 	"github.com/snapcore/snapd/overlord/assertstate"
+	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/devicestate"
 	"github.com/snapcore/snapd/overlord/hookstate"
 	"github.com/snapcore/snapd/overlord/ifacestate"
@@ -59,6 +60,7 @@ import (
 	"github.com/snapcore/snapd/snap/naming"
 	"github.com/snapcore/snapd/snap/snaptest"
 	"github.com/snapcore/snapd/snapdenv"
+	"github.com/snapcore/snapd/snapdtool"
 	"github.com/snapcore/snapd/testutil"
 	"github.com/snapcore/snapd/timings"
 )
@@ -296,6 +298,10 @@ slots:
 		// pretend the snapd.apparmor.service is enabled
 		return false
 	}))
+	s.AddCleanup(ifacestate.MockSnapConfineMissingFeatures(func() (string, []string, error) {
+		// pretend snap-confine matches snapd
+		return "1.0", nil, nil
+	}))
 
 	s.BaseTest.AddCleanup(ifacestate.MockCreateInterfacesRequestsManager(fakeCreateInterfacesRequestsManager))
 	s.BaseTest.AddCleanup(ifacestate.MockInterfacesRequestsManagerStop(fakeInterfacesRequestsManagerStop))
@@ -961,6 +967,30 @@ func (s *interfaceManagerSuite) TestParallelInstallConnectTask(c *C) {
 	c.Assert(hs, Equals, hookstate.HookSetup{Snap: "consumer_foo", Hook: "connect-plug-plug", Optional: true})
 }
 
+func (s *interfaceManagerSuite) TestConnectDeniedInterface(c *C) {
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"}, &ifacetest.TestInterface{InterfaceName: "test2"})
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+	_ = s.manager(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	tr := config.NewTransaction(s.state)
+	c.Assert(tr.Set("core", "interfaces.deny", "test"), IsNil)
+	tr.Commit()
+
+	ts, err := ifacestate.Connect(s.state, "consumer", "plug", "producer", "slot")
+	c.Assert(ts, IsNil)
+	c.Assert(err, ErrorMatches, `cannot connect "test" interface: denied by the system configuration`)
+	c.Check(err, DeepEquals, &ifacestate.ErrInterfaceDenied{Interface: "test"})
+
+	// the interface of the plug is checked, other interfaces are not affected
+	ts, err = ifacestate.Connect(s.state, "consumer", "otherplug", "producer", "slot")
+	c.Assert(err, IsNil)
+	c.Assert(ts, NotNil)
+}
+
 func (s *interfaceManagerSuite) TestConnectAlreadyConnected(c *C) {
 	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"}, &ifacetest.TestInterface{InterfaceName: "test2"})
 	s.mockSnap(c, consumerYaml)
@@ -1738,6 +1768,40 @@ func (s *interfaceManagerSuite) TestDisconnectFull(c *C) {
 	s.testDisconnect(c, "consumer", "plug", "producer", "slot")
 }
 
+func (s *interfaceManagerSuite) TestDisconnectDeniedForgetsAutoConnection(c *C) {
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"}, &ifacetest.TestInterface{InterfaceName: "test2"})
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	s.state.Lock()
+	s.state.Set("conns", map[string]interface{}{
+		"consumer:plug producer:slot": map[string]interface{}{"interface": "test", "auto": true},
+	})
+	s.state.Unlock()
+
+	conn := s.getConnection(c, "consumer", "plug", "producer", "slot")
+
+	s.state.Lock()
+	change := s.state.NewChange("disconnect", "...")
+	ts, err := ifacestate.DisconnectDenied(s.state, conn)
+	c.Assert(err, IsNil)
+	change.AddAll(ts)
+	s.state.Unlock()
+
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Assert(change.Err(), IsNil)
+	c.Check(change.Status(), Equals, state.DoneStatus)
+
+	// the connection is not kept as undesired, so that it is made again
+	// by auto-connection once the interface is allowed
+	var conns map[string]interface{}
+	c.Assert(s.state.Get("conns", &conns), IsNil)
+	c.Check(conns, HasLen, 0)
+}
+
 func (s *interfaceManagerSuite) getConnection(c *C, plugSnap, plugName, slotSnap, slotName string) *interfaces.Connection {
 	conn, err := s.manager(c).Repository().Connection(&interfaces.ConnRef{
 		PlugRef: interfaces.PlugRef{Snap: plugSnap, Name: plugName},
@@ -3197,6 +3261,21 @@ func (s *interfaceManagerSuite) TestDoSetupSnapSecurityAutoConnectsDeclBased(c *
 	})
 }
 
+// The auto-connect task will *not* auto-connect plugs of interfaces denied by the system configuration.
+func (s *interfaceManagerSuite) TestDoSetupSnapSecurityAutoConnectsDeclBasedDeniedInterface(c *C) {
+	s.state.Lock()
+	tr := config.NewTransaction(s.state)
+	c.Assert(tr.Set("core", "interfaces.deny", "test2,test"), IsNil)
+	tr.Commit()
+	s.state.Unlock()
+
+	s.testDoSetupSnapSecurityAutoConnectsDeclBased(c, true, func(conns map[string]interface{}, repoConns []*interfaces.ConnRef) {
+		// Ensure nothing is connected.
+		c.Check(conns, HasLen, 0)
+		c.Check(repoConns, HasLen, 0)
+	})
+}
+
 // The auto-connect task will *not* auto-connect plugs with viable candidates when snap declarations are missing.
 func (s *interfaceManagerSuite) TestDoSetupSnapSecurityAutoConnectsDeclBasedWhenMissingDecl(c *C) {
 	s.testDoSetupSnapSecurityAutoConnectsDeclBased(c, false, func(conns map[string]interface{}, repoConns []*interfaces.ConnRef) {
@@ -7149,6 +7228,35 @@ func (s *interfaceManagerSuite) TestStartupWarningForDisabledAppArmor(c *C) {
 	c.Check(warns[0].String(), Matches, `the snapd\.apparmor service is disabled.*\nRun .* to correct this\.`)
 }
 
+func (s *interfaceManagerSuite) TestStartupWarningForIncompatibleSnapConfine(c *C) {
+	restore := snapdtool.MockVersion("2.63")
+	defer restore()
+	restore = ifacestate.MockSnapConfineMissingFeatures(func() (string, []string, error) {
+		return "2.48", []string{"cgroup-v2-device-filter", "per-user-mount-ns"}, nil
+	})
+	defer restore()
+	_ = s.manager(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	warns := s.state.AllWarnings()
+	c.Assert(warns, HasLen, 1)
+	c.Check(warns[0].String(), Equals, `snap-confine version 2.48 lacks features required by snapd version 2.63, the following are degraded: cgroup-v2-device-filter, per-user-mount-ns
+Update snap-confine to the version of snapd to correct this.`)
+}
+
+func (s *interfaceManagerSuite) TestStartupNoWarningUnknownSnapConfine(c *C) {
+	restore := ifacestate.MockSnapConfineMissingFeatures(func() (string, []string, error) {
+		return "", nil, fmt.Errorf("cannot open snapd info file")
+	})
+	defer restore()
+	_ = s.manager(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Check(s.state.AllWarnings(), HasLen, 0)
+}
+
 func (s *interfaceManagerSuite) TestAutoconnectSelf(c *C) {
 	s.MockModel(c, nil)
 
@@ -8260,6 +8368,45 @@ func (s *interfaceManagerSuite) TestUDevMonitorInit(c *C) {
 	c.Assert(u.StopCalls, Equals, 1)
 }
 
+func (s *interfaceManagerSuite) TestUDevMonitorContainerProfile(c *C) {
+	restore := snapdenv.MockContainerProfile(true)
+	defer restore()
+
+	st := s.state
+	st.Lock()
+	snapstate.Set(s.state, "core", &snapstate.SnapState{
+		Active: true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "core", Revision: snap.R(1)},
+		}),
+		Current:  snap.R(1),
+		SnapType: "os",
+	})
+	st.Unlock()
+	s.mockSnap(c, coreSnapYaml)
+
+	restoreTimeout := ifacestate.MockUDevInitRetryTimeout(0 * time.Second)
+	defer restoreTimeout()
+
+	var udevMonitorCreated bool
+	restoreCreate := ifacestate.MockCreateUDevMonitor(func(udevmonitor.DeviceAddedFunc, udevmonitor.DeviceRemovedFunc, udevmonitor.EnumerationDoneFunc) udevmonitor.Interface {
+		udevMonitorCreated = true
+		return &udevMonitorMock{}
+	})
+	defer restoreCreate()
+
+	mgr, err := ifacestate.Manager(s.state, nil, s.o.TaskRunner(), nil, nil)
+	c.Assert(err, IsNil)
+	s.o.AddManager(mgr)
+	c.Assert(s.o.StartUp(), IsNil)
+
+	// hotplug is disabled in containers
+	for i := 0; i < 5; i++ {
+		c.Assert(s.se.Ensure(), IsNil)
+	}
+	c.Check(udevMonitorCreated, Equals, false)
+}
+
 func (s *interfaceManagerSuite) TestUDevMonitorInitErrors(c *C) {
 	u := udevMonitorMock{
 		ConnectError: fmt.Errorf("Connect failed"),
@@ -9936,6 +10083,106 @@ func (s *interfaceManagerSuite) TestDoSetupSnapSecurityAutoConnectsDeclBasedAnyS
 	s.testDoSetupSnapSecurityAutoConnectsDeclBasedAnySlotsPerPlug(c, check)
 }
 
+func (s *interfaceManagerSuite) TestDoSetupSnapSecurityAutoConnectsNewProviderToAllConsumers(c *C) {
+	s.MockModel(c, nil)
+
+	// both producers allow their consumers to be connected to several of
+	// them
+	for _, name := range []string{"theme1", "theme2"} {
+		s.MockSnapDecl(c, name, "one-publisher", map[string]interface{}{
+			"format": "1",
+			"slots": map[string]interface{}{
+				"content": map[string]interface{}{
+					"allow-auto-connection": map[string]interface{}{
+						"slots-per-plug": "*",
+					},
+				},
+			},
+		})
+	}
+	s.MockSnapDecl(c, "theme-consumer1", "one-publisher", nil)
+	s.MockSnapDecl(c, "theme-consumer2", "one-publisher", nil)
+
+	for _, name := range []string{"theme-consumer1", "theme-consumer2"} {
+		s.mockSnap(c, fmt.Sprintf(`
+name: %s
+version: 1
+plugs:
+  plug:
+    interface: content
+    content: themes
+`, name))
+	}
+	s.mockSnap(c, `
+name: theme1
+version: 1
+slots:
+  slot:
+    interface: content
+    content: themes
+`)
+
+	// the consumers are connected to the first producer already
+	s.state.Lock()
+	s.state.Set("conns", map[string]interface{}{
+		"theme-consumer1:plug theme1:slot": map[string]interface{}{
+			"auto":        true,
+			"interface":   "content",
+			"plug-static": map[string]interface{}{"content": "themes"},
+			"slot-static": map[string]interface{}{"content": "themes"},
+		},
+		"theme-consumer2:plug theme1:slot": map[string]interface{}{
+			"auto":        true,
+			"interface":   "content",
+			"plug-static": map[string]interface{}{"content": "themes"},
+			"slot-static": map[string]interface{}{"content": "themes"},
+		},
+	})
+	s.state.Unlock()
+
+	s.manager(c)
+
+	snapInfo := s.mockSnap(c, `
+name: theme2
+version: 1
+slots:
+  slot:
+    interface: content
+    content: themes
+`)
+
+	// Run the setup-snap-security task and let it finish.
+	change := s.addSetupSnapSecurityChange(c, &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{
+			RealName: snapInfo.SnapName(),
+			SnapID:   snapInfo.SnapID,
+			Revision: snapInfo.Revision,
+		},
+	})
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	// Ensure that the task succeeded.
+	c.Assert(change.Status(), Equals, state.DoneStatus)
+
+	// the new producer got connected to all the consumers
+	var conns map[string]interface{}
+	c.Assert(s.state.Get("conns", &conns), IsNil)
+	for _, name := range []string{"theme-consumer1", "theme-consumer2"} {
+		for _, producer := range []string{"theme1", "theme2"} {
+			c.Check(conns[fmt.Sprintf("%s:plug %s:slot", name, producer)], DeepEquals, map[string]interface{}{
+				"auto":        true,
+				"interface":   "content",
+				"plug-static": map[string]interface{}{"content": "themes"},
+				"slot-static": map[string]interface{}{"content": "themes"},
+			})
+		}
+	}
+	c.Check(conns, HasLen, 4)
+}
+
 func (s *interfaceManagerSuite) TestDoSetupSnapSecurityAutoConnectsDeclBasedSlotNames(c *C) {
 	s.MockModel(c, nil)
 
@@ -41,6 +41,7 @@ import (
 	"github.com/snapcore/snapd/overlord/cmdstate"
 	"github.com/snapcore/snapd/overlord/confdbstate"
 	"github.com/snapcore/snapd/overlord/configstate"
+	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/configstate/proxyconf"
 	"github.com/snapcore/snapd/overlord/devicestate"
 	"github.com/snapcore/snapd/overlord/healthstate"
@@ -76,6 +77,9 @@ var (
 
 	defaultCachedDownloads = 5
 
+	defaultCachedMetadata   = 100
+	defaultMetadataCacheTTL = 10 * time.Minute
+
 	configstateInit = configstate.Init
 	systemdSdNotify = systemd.SdNotify
 )
@@ -337,11 +341,32 @@ func initRestart(s *state.State, curBootID string, restartHandler restart.Handle
 	return restart.Manager(s, curBootID, restartHandler)
 }
 
+// metadataCacheConf returns the size and TTL of the store metadata cache as
+// configured with store.metadata-cache-size and store.metadata-cache-ttl.
+// The state must be locked.
+func metadataCacheConf(st *state.State) (size int, ttl time.Duration) {
+	size, ttl = defaultCachedMetadata, defaultMetadataCacheTTL
+
+	tr := config.NewTransaction(st)
+	var confSize int
+	if err := tr.Get("core", "store.metadata-cache-size", &confSize); err == nil {
+		size = confSize
+	}
+	var confTTL string
+	if err := tr.Get("core", "store.metadata-cache-ttl", &confTTL); err == nil {
+		if d, err := time.ParseDuration(confTTL); err == nil {
+			ttl = d
+		}
+	}
+	return size, ttl
+}
+
 func (o *Overlord) newStoreWithContext(storeCtx store.DeviceAndAuthContext) snapstate.StoreService {
 	cfg := store.DefaultConfig()
 	cfg.Proxy = o.proxyConf
 	sto := storeNew(cfg, storeCtx)
 	sto.SetCacheDownloads(defaultCachedDownloads)
+	sto.SetMetadataCache(metadataCacheConf(o.State()))
 	return sto
 }
 
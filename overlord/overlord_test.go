@@ -224,6 +224,37 @@ func (ovs *overlordSuite) TestNewWithStateSnapmgrUpdate(c *C) {
 	c.Check(refreshPrivacyKey, HasLen, 16)
 }
 
+func (ovs *overlordSuite) testNewStoreMetadataCacheConf(c *C, conf string, size int, ttl time.Duration) {
+	fakeState := []byte(fmt.Sprintf(`{"data":{"patch-level":%d,"config":{"core":%s}},"changes":null,"tasks":null,"last-change-id":0,"last-task-id":0,"last-lane-id":0}`, patch.Level, conf))
+	c.Assert(os.WriteFile(dirs.SnapStateFile, fakeState, 0600), IsNil)
+
+	var storeCfg *store.Config
+	restore := overlord.MockStoreNew(func(cfg *store.Config, dac store.DeviceAndAuthContext) *store.Store {
+		storeCfg = cfg
+		return store.New(cfg, dac)
+	})
+	defer restore()
+
+	_, err := overlord.New(nil)
+	c.Assert(err, IsNil)
+
+	c.Assert(storeCfg, NotNil)
+	c.Check(storeCfg.MetadataCacheSize, Equals, size)
+	c.Check(storeCfg.MetadataCacheTTL, Equals, ttl)
+}
+
+func (ovs *overlordSuite) TestNewStoreMetadataCacheDefault(c *C) {
+	ovs.testNewStoreMetadataCacheConf(c, `{}`, 100, 10*time.Minute)
+}
+
+func (ovs *overlordSuite) TestNewStoreMetadataCacheConfigured(c *C) {
+	ovs.testNewStoreMetadataCacheConf(c, `{"store": {"metadata-cache-size": 5, "metadata-cache-ttl": "1h"}}`, 5, time.Hour)
+}
+
+func (ovs *overlordSuite) TestNewStoreMetadataCacheDisabled(c *C) {
+	ovs.testNewStoreMetadataCacheConf(c, `{"store": {"metadata-cache-size": 0}}`, 0, 10*time.Minute)
+}
+
 func (ovs *overlordSuite) TestNewWithInvalidState(c *C) {
 	fakeState := []byte(``)
 	err := os.WriteFile(dirs.SnapStateFile, fakeState, 0600)
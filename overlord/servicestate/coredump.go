@@ -0,0 +1,161 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package servicestate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/gadget/quantity"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/sandbox/cgroup"
+	"github.com/snapcore/snapd/systemd"
+)
+
+// coredumpForwardInterval is how often the new core dumps of snap services
+// are forwarded to the directories of the snaps.
+var coredumpForwardInterval = 5 * time.Minute
+
+// ensureCoredumpsForwarded copies the core dumps of snap services collected
+// by systemd-coredump to a directory of each snap under SnapCoredumpDir, if
+// enabled with the system.coredump.snap-maxuse option. The oldest core dumps
+// of a snap are removed to keep the disk space used by its directory within
+// the option.
+func (m *ServiceManager) ensureCoredumpsForwarded() error {
+	now := time.Now()
+	if now.Before(m.nextCoredumpsForward) {
+		return nil
+	}
+	m.nextCoredumpsForward = now.Add(coredumpForwardInterval)
+
+	m.state.Lock()
+	tr := config.NewTransaction(m.state)
+	var enabled bool
+	var maxUseStr, cursor string
+	err := tr.GetMaybe("core", "system.coredump.enable", &enabled)
+	if err == nil {
+		err = tr.GetMaybe("core", "system.coredump.snap-maxuse", &maxUseStr)
+	}
+	if err == nil {
+		err = m.state.Get("coredumps-forwarded-cursor", &cursor)
+		if errors.Is(err, state.ErrNoState) {
+			err = nil
+		}
+	}
+	m.state.Unlock()
+	if err != nil {
+		return err
+	}
+	if !enabled || maxUseStr == "" {
+		return nil
+	}
+	maxUse, err := quantity.ParseSize(maxUseStr)
+	if err != nil {
+		return err
+	}
+
+	coredumps, err := systemd.Coredumps(cursor)
+	if err != nil && cursor != "" {
+		// the last forwarded entry may have been rotated out of the
+		// journal, the core dumps already forwarded are skipped
+		logger.Noticef("cannot list core dumps after the last forwarded one: %v", err)
+		coredumps, err = systemd.Coredumps("")
+	}
+	if err != nil {
+		return err
+	}
+	if len(coredumps) == 0 {
+		return nil
+	}
+	for _, cd := range coredumps {
+		if err := forwardCoredump(cd, maxUse); err != nil {
+			logger.Noticef("cannot forward core dump of process %d: %v", cd.Pid, err)
+		}
+	}
+
+	m.state.Lock()
+	defer m.state.Unlock()
+	m.state.Set("coredumps-forwarded-cursor", coredumps[len(coredumps)-1].Cursor)
+	return nil
+}
+
+// forwardCoredump copies the given core dump to the directory of its snap,
+// if it is one of a snap service.
+func forwardCoredump(cd *systemd.Coredump, maxUse quantity.Size) error {
+	if filepath.Ext(cd.Cgroup) != ".service" || cd.Filename == "" {
+		return nil
+	}
+	snapName := cgroup.SnapNameFromCgroupPath(cd.Cgroup)
+	if snapName == "" {
+		return nil
+	}
+
+	dir := filepath.Join(dirs.SnapCoredumpDir, snapName)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	// the timestamps have the same number of digits, so the names sort
+	// in the order of the crashes
+	target := filepath.Join(dir, fmt.Sprintf("core.%s.%d", cd.Timestamp, cd.Pid))
+	if osutil.FileExists(target) {
+		return nil
+	}
+	if err := systemd.DumpCoredump(cd, target); err != nil {
+		return err
+	}
+	return pruneCoredumps(dir, maxUse)
+}
+
+// pruneCoredumps removes the oldest core dumps in the given directory so
+// that they use at most maxUse bytes.
+func pruneCoredumps(dir string, maxUse quantity.Size) error {
+	// the entries are sorted by name, the oldest first
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var coredumps []os.FileInfo
+	var size int64
+	for _, entry := range entries {
+		fi, err := entry.Info()
+		if err != nil || !fi.Mode().IsRegular() {
+			continue
+		}
+		coredumps = append(coredumps, fi)
+		size += fi.Size()
+	}
+	for _, fi := range coredumps {
+		if size <= int64(maxUse) {
+			break
+		}
+		if err := os.Remove(filepath.Join(dir, fi.Name())); err != nil {
+			return err
+		}
+		size -= fi.Size()
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package servicestate_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/servicestate"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type coredumpSuite struct {
+	baseServiceMgrTestSuite
+
+	coredumpctl *testutil.MockCmd
+}
+
+var _ = Suite(&coredumpSuite{})
+
+// the core dumps of two services of foo, of the app of foo, which is not a
+// service, and of a service of the foo_x parallel instance
+const mockCoredumpEntries = `
+{"__CURSOR":"s=1","COREDUMP_TIMESTAMP":"1700000000000000","COREDUMP_PID":"1","COREDUMP_CGROUP":"/system.slice/snap.foo.svc.service","COREDUMP_FILENAME":"/var/lib/systemd/coredump/core.1"}
+{"__CURSOR":"s=2","COREDUMP_TIMESTAMP":"1700000001000000","COREDUMP_PID":"2","COREDUMP_CGROUP":"/user.slice/user-1000.slice/user@1000.service/app.slice/snap.foo.app-54b38acc-3ba2-4c6d-b284-7ac07e1159e5.scope","COREDUMP_FILENAME":"/var/lib/systemd/coredump/core.2"}
+{"__CURSOR":"s=3","COREDUMP_TIMESTAMP":"1700000002000000","COREDUMP_PID":"3","COREDUMP_CGROUP":"/system.slice/snap.foo_x.svc.service","COREDUMP_FILENAME":"/var/lib/systemd/coredump/core.3"}
+{"__CURSOR":"s=4","COREDUMP_TIMESTAMP":"1700000003000000","COREDUMP_PID":"4","COREDUMP_CGROUP":"/system.slice/snap.foo.other.service","COREDUMP_FILENAME":"/var/lib/systemd/coredump/core.4"}
+`
+
+func (s *coredumpSuite) SetUpTest(c *C) {
+	s.baseServiceMgrTestSuite.SetUpTest(c)
+	s.AddCleanup(servicestate.MockCoredumpForwardInterval(0))
+
+	s.coredumpctl = testutil.MockCommand(c, "coredumpctl", `
+for arg in "$@"; do
+    case "$arg" in
+        --output=*)
+            printf "coredump" > "${arg#--output=}"
+            ;;
+    esac
+done
+`)
+	s.AddCleanup(s.coredumpctl.Restore)
+}
+
+func (s *coredumpSuite) mockJournalctl(c *C, entries string) *testutil.MockCmd {
+	cmd := testutil.MockCommand(c, "journalctl", "cat <<'EOF'"+entries+"EOF\n")
+	s.AddCleanup(cmd.Restore)
+	return cmd
+}
+
+func (s *coredumpSuite) configure(c *C, enabled bool, snapMaxUse string) {
+	s.state.Lock()
+	defer s.state.Unlock()
+	tr := config.NewTransaction(s.state)
+	c.Assert(tr.Set("core", "system.coredump.enable", enabled), IsNil)
+	c.Assert(tr.Set("core", "system.coredump.snap-maxuse", snapMaxUse), IsNil)
+	tr.Commit()
+}
+
+func (s *coredumpSuite) TestForwardDisabled(c *C) {
+	journalctl := s.mockJournalctl(c, mockCoredumpEntries)
+
+	c.Assert(s.mgr.Ensure(), IsNil)
+	s.configure(c, false, "1M")
+	c.Assert(s.mgr.Ensure(), IsNil)
+	s.configure(c, true, "")
+	c.Assert(s.mgr.Ensure(), IsNil)
+
+	c.Check(journalctl.Calls(), HasLen, 0)
+	c.Check(dirs.SnapCoredumpDir, testutil.FileAbsent)
+}
+
+func (s *coredumpSuite) TestForward(c *C) {
+	journalctl := s.mockJournalctl(c, mockCoredumpEntries)
+	s.configure(c, true, "1M")
+
+	c.Assert(s.mgr.Ensure(), IsNil)
+
+	fooDir := filepath.Join(dirs.SnapCoredumpDir, "foo")
+	c.Check(filepath.Join(fooDir, "core.1700000000000000.1"), testutil.FileEquals, "coredump")
+	c.Check(filepath.Join(fooDir, "core.1700000003000000.4"), testutil.FileEquals, "coredump")
+	c.Check(filepath.Join(dirs.SnapCoredumpDir, "foo_x", "core.1700000002000000.3"), testutil.FileEquals, "coredump")
+	entries, err := os.ReadDir(fooDir)
+	c.Assert(err, IsNil)
+	c.Check(entries, HasLen, 2)
+	fi, err := os.Stat(fooDir)
+	c.Assert(err, IsNil)
+	c.Check(fi.Mode().Perm(), Equals, os.FileMode(0700))
+
+	c.Check(s.coredumpctl.Calls(), DeepEquals, [][]string{
+		{"coredumpctl", "--no-pager", "--output=" + filepath.Join(fooDir, "core.1700000000000000.1"), "dump", "COREDUMP_PID=1", "COREDUMP_TIMESTAMP=1700000000000000"},
+		{"coredumpctl", "--no-pager", "--output=" + filepath.Join(dirs.SnapCoredumpDir, "foo_x", "core.1700000002000000.3"), "dump", "COREDUMP_PID=3", "COREDUMP_TIMESTAMP=1700000002000000"},
+		{"coredumpctl", "--no-pager", "--output=" + filepath.Join(fooDir, "core.1700000003000000.4"), "dump", "COREDUMP_PID=4", "COREDUMP_TIMESTAMP=1700000003000000"},
+	})
+
+	// the next run continues after the last forwarded core dump
+	c.Assert(s.mgr.Ensure(), IsNil)
+	c.Check(journalctl.Calls(), DeepEquals, [][]string{
+		{"journalctl", "--output=json", "--no-pager", "MESSAGE_ID=fc2e22bc6ee647b6b90729ab34a250b1"},
+		{"journalctl", "--output=json", "--no-pager", "MESSAGE_ID=fc2e22bc6ee647b6b90729ab34a250b1", "--after-cursor=s=4"},
+	})
+	// and skips the core dumps already forwarded
+	c.Check(s.coredumpctl.Calls(), HasLen, 3)
+}
+
+func (s *coredumpSuite) TestForwardPrunesOldest(c *C) {
+	s.mockJournalctl(c, mockCoredumpEntries)
+	// room for a single core dump of 8 bytes
+	s.configure(c, true, "10")
+
+	c.Assert(s.mgr.Ensure(), IsNil)
+
+	fooDir := filepath.Join(dirs.SnapCoredumpDir, "foo")
+	c.Check(filepath.Join(fooDir, "core.1700000000000000.1"), testutil.FileAbsent)
+	c.Check(filepath.Join(fooDir, "core.1700000003000000.4"), testutil.FileEquals, "coredump")
+	c.Check(filepath.Join(dirs.SnapCoredumpDir, "foo_x", "core.1700000002000000.3"), testutil.FileEquals, "coredump")
+}
+
+func (s *coredumpSuite) TestForwardOnInterval(c *C) {
+	restore := servicestate.MockCoredumpForwardInterval(time.Hour)
+	defer restore()
+	journalctl := s.mockJournalctl(c, mockCoredumpEntries)
+	s.configure(c, true, "1M")
+
+	c.Assert(s.mgr.Ensure(), IsNil)
+	c.Assert(s.mgr.Ensure(), IsNil)
+	c.Check(journalctl.Calls(), HasLen, 1)
+}
@@ -20,6 +20,8 @@
 package servicestate
 
 import (
+	"time"
+
 	tomb "gopkg.in/tomb.v2"
 
 	"github.com/snapcore/snapd/overlord/state"
@@ -76,3 +78,11 @@ func MockResourcesCheckFeatureRequirements(f func(*quota.Resources) error) (rest
 	resourcesCheckFeatureRequirements = f
 	return r
 }
+
+func MockCoredumpForwardInterval(interval time.Duration) (restore func()) {
+	return testutil.Mock(&coredumpForwardInterval, interval)
+}
+
+func MockAvahiWatchStarted(f func(started func()) (stop func(), err error)) (restore func()) {
+	return testutil.Mock(&avahiWatchStarted, f)
+}
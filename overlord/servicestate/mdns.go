@@ -0,0 +1,129 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package servicestate
+
+import (
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/osutil/avahi"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/progress"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/strutil"
+	"github.com/snapcore/snapd/wrappers"
+)
+
+var avahiWatchStarted = avahi.WatchStarted
+
+// ensureServicesAdvertised advertises again on the local network the enabled
+// services of the snaps allowed to by the mdns.advertise system option, once
+// the snap services are ensured at startup and then each time the Avahi
+// daemon appears on the system bus, as the advertisements outlive neither
+// snapd nor Avahi. Advertising is best-effort so errors are only logged.
+func (m *ServiceManager) ensureServicesAdvertised() error {
+	m.state.Lock()
+	if !m.ensuredSnapSvcs {
+		m.state.Unlock()
+		return nil
+	}
+	var snaps string
+	err := config.NewTransaction(m.state).GetMaybe("core", "mdns.advertise", &snaps)
+	m.state.Unlock()
+	if err != nil {
+		return err
+	}
+	if snaps == "" {
+		// nothing can be advertised
+		return nil
+	}
+
+	if m.stopAvahiWatch == nil {
+		stop, err := avahiWatchStarted(m.avahiStarted)
+		if err != nil {
+			logger.Noticef("cannot watch avahi daemon, services will not be advertised again when it restarts: %v", err)
+			stop = func() {}
+		}
+		m.stopAvahiWatch = stop
+	}
+
+	m.advertiseMu.Lock()
+	pending := m.advertisePending
+	m.advertisePending = false
+	m.advertiseMu.Unlock()
+	if !pending {
+		return nil
+	}
+
+	m.state.Lock()
+	candidates, err := servicesToAdvertise(m.state)
+	m.state.Unlock()
+	if err != nil {
+		return err
+	}
+	// query systemd and talk to avahi without holding the state lock
+	for info, svcs := range candidates {
+		disabledSvcs, err := wrappers.QueryDisabledServices(info, progress.Null)
+		if err != nil {
+			logger.Noticef("cannot query disabled services of snap %q: %v", info.InstanceName(), err)
+			continue
+		}
+		var enabled []*snap.AppInfo
+		for _, app := range svcs {
+			if !strutil.ListContains(disabledSvcs.SystemServices, app.Name) {
+				enabled = append(enabled, app)
+			}
+		}
+		snapstate.AdvertiseServices(enabled)
+	}
+	return nil
+}
+
+// avahiStarted is called by the watch of the Avahi daemon when it appears
+// on the system bus.
+func (m *ServiceManager) avahiStarted() {
+	m.advertiseMu.Lock()
+	m.advertisePending = true
+	m.advertiseMu.Unlock()
+	m.state.EnsureBefore(0)
+}
+
+// servicesToAdvertise returns the services of the active snaps that can be
+// advertised on the local network, if enabled. The state must be locked.
+func servicesToAdvertise(st *state.State) (map[*snap.Info][]*snap.AppInfo, error) {
+	snapStates, err := snapstate.All(st)
+	if err != nil {
+		return nil, err
+	}
+	toAdvertise := make(map[*snap.Info][]*snap.AppInfo)
+	for _, snapst := range snapStates {
+		if !snapst.Active {
+			continue
+		}
+		info, err := snapst.CurrentInfo()
+		if err != nil {
+			continue
+		}
+		if svcs := snapstate.ServicesToAdvertise(st, info.Services(), nil); len(svcs) != 0 {
+			toAdvertise[info] = svcs
+		}
+	}
+	return toAdvertise, nil
+}
@@ -0,0 +1,167 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package servicestate_test
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/godbus/dbus"
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dbusutil"
+	"github.com/snapcore/snapd/dbusutil/dbustest"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/servicestate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/snapstate/snapstatetest"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/systemd"
+	"github.com/snapcore/snapd/systemd/systemdtest"
+)
+
+type mdnsSuite struct {
+	baseServiceMgrTestSuite
+
+	mu      sync.Mutex
+	members []string
+
+	started  func()
+	watched  int
+	watchErr error
+}
+
+var _ = Suite(&mdnsSuite{})
+
+func (s *mdnsSuite) SetUpTest(c *C) {
+	s.baseServiceMgrTestSuite.SetUpTest(c)
+	s.members = nil
+	s.started = nil
+	s.watched = 0
+	s.watchErr = nil
+
+	conn, err := dbustest.Connection(func(msg *dbus.Message, n int) ([]*dbus.Message, error) {
+		member := msg.Headers[dbus.FieldMember].Value().(string)
+		s.mu.Lock()
+		s.members = append(s.members, member)
+		s.mu.Unlock()
+		reply := &dbus.Message{
+			Type: dbus.TypeMethodReply,
+			Headers: map[dbus.HeaderField]dbus.Variant{
+				dbus.FieldReplySerial: dbus.MakeVariant(msg.Serial()),
+				dbus.FieldSender:      dbus.MakeVariant(":1"),
+			},
+		}
+		if member == "EntryGroupNew" {
+			reply.Headers[dbus.FieldSignature] = dbus.MakeVariant(dbus.SignatureOf(dbus.ObjectPath("")))
+			reply.Body = []interface{}{dbus.ObjectPath("/Client1/EntryGroup1")}
+		}
+		return []*dbus.Message{reply}, nil
+	})
+	c.Assert(err, IsNil)
+	s.AddCleanup(func() { conn.Close() })
+	s.AddCleanup(dbusutil.MockOnlySystemBusAvailable(conn))
+
+	s.AddCleanup(servicestate.MockAvahiWatchStarted(func(started func()) (func(), error) {
+		s.watched++
+		if s.watchErr != nil {
+			return nil, s.watchErr
+		}
+		s.started = started
+		return func() {}, nil
+	}))
+
+	s.AddCleanup(systemd.MockSystemctl(func(cmd ...string) ([]byte, error) {
+		if out := systemdtest.HandleMockAllUnitsActiveOutput(cmd, nil); out != nil {
+			return out, nil
+		}
+		return nil, nil
+	}))
+	s.AddCleanup(snapstatetest.MockDeviceModel(s.uc16Model))
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	si := snap.SideInfo{RealName: "test-snap", Revision: snap.R(7)}
+	info := snaptest.MockSnap(c, mdnsSnapYaml, &si)
+	// withdraw what was advertised while the bus is still mocked
+	s.AddCleanup(func() { snapstate.WithdrawServices(info.Services()) })
+	snapstate.Set(s.state, "test-snap", &snapstate.SnapState{
+		Active:   true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{&si}),
+		Current:  snap.R(7),
+		SnapType: "app",
+	})
+}
+
+func (s *mdnsSuite) setAdvertise(c *C, value string) {
+	s.state.Lock()
+	defer s.state.Unlock()
+	tr := config.NewTransaction(s.state)
+	c.Assert(tr.Set("core", "mdns.advertise", value), IsNil)
+	tr.Commit()
+}
+
+func (s *mdnsSuite) calls() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.members...)
+}
+
+func (s *mdnsSuite) TestEnsureAdvertisesNotEnabled(c *C) {
+	c.Assert(s.mgr.Ensure(), IsNil)
+
+	c.Check(s.calls(), HasLen, 0)
+	c.Check(s.watched, Equals, 0)
+}
+
+func (s *mdnsSuite) TestEnsureAdvertisesAgainWhenAvahiStarts(c *C) {
+	s.setAdvertise(c, "test-snap")
+
+	c.Assert(s.mgr.Ensure(), IsNil)
+	c.Check(s.calls(), DeepEquals, []string{"EntryGroupNew", "AddService", "Commit"})
+	c.Check(s.watched, Equals, 1)
+	c.Assert(s.started, NotNil)
+
+	// nothing more is done until avahi appears again on the bus
+	c.Assert(s.mgr.Ensure(), IsNil)
+	c.Check(s.calls(), HasLen, 3)
+
+	s.started()
+	c.Assert(s.mgr.Ensure(), IsNil)
+	c.Check(s.calls(), DeepEquals, []string{
+		"EntryGroupNew", "AddService", "Commit",
+		// the group of the previous registration is freed first
+		"Free", "EntryGroupNew", "AddService", "Commit",
+	})
+	c.Check(s.watched, Equals, 1)
+}
+
+func (s *mdnsSuite) TestEnsureAdvertisesWatchError(c *C) {
+	s.setAdvertise(c, "test-snap")
+	s.watchErr = errors.New("boom")
+
+	c.Assert(s.mgr.Ensure(), IsNil)
+	c.Check(s.calls(), DeepEquals, []string{"EntryGroupNew", "AddService", "Commit"})
+
+	// the watch is not attempted again
+	c.Assert(s.mgr.Ensure(), IsNil)
+	c.Check(s.watched, Equals, 1)
+}
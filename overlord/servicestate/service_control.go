@@ -135,6 +135,7 @@ func (m *ServiceManager) doServiceControl(t *state.Task, _ *tomb.Tomb) error {
 			ScopeOptions: sc.ScopeOptions,
 		}
 		st.Unlock()
+		snapstate.WithdrawServices(services)
 		err := wrappers.StopServices(services, opts, snap.StopReasonOther, meter, perfTimings)
 		st.Lock()
 		if err != nil {
@@ -159,8 +160,12 @@ func (m *ServiceManager) doServiceControl(t *state.Task, _ *tomb.Tomb) error {
 			Enable:       enable,
 			ScopeOptions: sc.ScopeOptions,
 		}
+		toAdvertise := snapstate.ServicesToAdvertise(st, startupOrdered, nil)
 		st.Unlock()
 		err = wrappers.StartServices(startupOrdered, nil, opts, meter, perfTimings)
+		if err == nil {
+			snapstate.AdvertiseServices(toAdvertise)
+		}
 		st.Lock()
 		if err != nil {
 			return err
@@ -29,13 +29,17 @@ import (
 	"testing"
 	"time"
 
+	"github.com/godbus/dbus"
 	. "gopkg.in/check.v1"
 
 	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/dbusutil"
+	"github.com/snapcore/snapd/dbusutil/dbustest"
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/osutil/user"
 	"github.com/snapcore/snapd/overlord"
+	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/servicestate"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/snapstate/snapstatetest"
@@ -800,6 +804,77 @@ func (s *serviceControlSuite) TestStopServices(c *C) {
 	})
 }
 
+const mdnsSnapYaml = `name: test-snap
+version: 1.0
+apps:
+  foo:
+    daemon: simple
+    plugs: [network-bind]
+    mdns:
+      type: _http._tcp
+      port: 8080
+`
+
+func (s *serviceControlSuite) TestStartStopServicesAdvertise(c *C) {
+	st := s.state
+	st.Lock()
+	defer st.Unlock()
+
+	var members []string
+	conn, err := dbustest.Connection(func(msg *dbus.Message, n int) ([]*dbus.Message, error) {
+		member := msg.Headers[dbus.FieldMember].Value().(string)
+		members = append(members, member)
+		reply := &dbus.Message{
+			Type: dbus.TypeMethodReply,
+			Headers: map[dbus.HeaderField]dbus.Variant{
+				dbus.FieldReplySerial: dbus.MakeVariant(msg.Serial()),
+				dbus.FieldSender:      dbus.MakeVariant(":1"),
+			},
+		}
+		if member == "EntryGroupNew" {
+			reply.Headers[dbus.FieldSignature] = dbus.MakeVariant(dbus.SignatureOf(dbus.ObjectPath("")))
+			reply.Body = []interface{}{dbus.ObjectPath("/Client1/EntryGroup1")}
+		}
+		return []*dbus.Message{reply}, nil
+	})
+	c.Assert(err, IsNil)
+	defer conn.Close()
+	s.AddCleanup(dbusutil.MockOnlySystemBusAvailable(conn))
+
+	tr := config.NewTransaction(st)
+	tr.Set("core", "mdns.advertise", "test-snap")
+	tr.Commit()
+
+	si := snap.SideInfo{RealName: "test-snap", Revision: snap.R(7)}
+	snaptest.MockSnap(c, mdnsSnapYaml, &si)
+	snapstate.Set(st, "test-snap", &snapstate.SnapState{
+		Active:   true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{&si}),
+		Current:  snap.R(7),
+		SnapType: "app",
+	})
+
+	defer s.se.Stop()
+	for _, action := range []string{"start", "stop"} {
+		chg := st.NewChange("service-control", "...")
+		t := st.NewTask("service-control", "...")
+		t.Set("service-action", &servicestate.ServiceAction{
+			SnapName: "test-snap",
+			Action:   action,
+		})
+		chg.AddTask(t)
+
+		st.Unlock()
+		err = s.o.Settle(5 * time.Second)
+		st.Lock()
+		c.Assert(err, IsNil)
+		c.Assert(t.Status(), Equals, state.DoneStatus)
+	}
+
+	// the service was advertised when started and withdrawn when stopped
+	c.Check(members, DeepEquals, []string{"EntryGroupNew", "AddService", "Commit", "Free"})
+}
+
 func (s *serviceControlSuite) TestStopServicesWithScope(c *C) {
 	st := s.state
 	st.Lock()
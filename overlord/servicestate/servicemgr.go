@@ -26,6 +26,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/snapcore/snapd/dirs"
@@ -48,6 +49,14 @@ type ServiceManager struct {
 	state *state.State
 
 	ensuredSnapSvcs bool
+
+	// advertiseMu protects advertisePending, which is set by the watch of
+	// the Avahi daemon
+	advertiseMu      sync.Mutex
+	advertisePending bool
+	stopAvahiWatch   func()
+
+	nextCoredumpsForward time.Time
 }
 
 // Manager returns a new service manager.
@@ -55,6 +64,9 @@ func Manager(st *state.State, runner *state.TaskRunner) *ServiceManager {
 	delayedCrossMgrInit()
 	m := &ServiceManager{
 		state: st,
+		// the services are advertised again once ensured at startup, as
+		// the advertisements do not outlive snapd
+		advertisePending: true,
 	}
 	// TODO: undo handler
 	runner.AddHandler("service-control", m.doServiceControl, nil)
@@ -204,9 +216,22 @@ func (m *ServiceManager) Ensure() error {
 	if err := m.ensureSnapServicesUpdated(); err != nil {
 		return err
 	}
+	if err := m.ensureServicesAdvertised(); err != nil {
+		return err
+	}
+	if err := m.ensureCoredumpsForwarded(); err != nil {
+		return err
+	}
 	return nil
 }
 
+// Stop implements StateStopper.Stop.
+func (m *ServiceManager) Stop() {
+	if m.stopAvahiWatch != nil {
+		m.stopAvahiWatch()
+	}
+}
+
 func delayedCrossMgrInit() {
 	// hook into conflict checks mechanisms
 	snapstate.RegisterAffectedSnapsByAttr("service-action", serviceControlAffectedSnaps)
@@ -45,6 +45,7 @@ var (
 	CleanupRestore             = cleanupRestore
 	DoCheck                    = doCheck
 	DoForget                   = doForget
+	UndoPreRefreshSave         = undoPreRefreshSave
 	SaveExpiration             = saveExpiration
 	ExpiredSnapshotSets        = expiredSnapshotSets
 	RemoveSnapshotState        = removeSnapshotState
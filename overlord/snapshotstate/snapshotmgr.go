@@ -70,6 +70,7 @@ func Manager(st *state.State, runner *state.TaskRunner) *SnapshotManager {
 	delayedCrossMgrInit()
 
 	runner.AddHandler("save-snapshot", doSave, doForget)
+	runner.AddHandler("save-pre-refresh-snapshot", doSave, undoPreRefreshSave)
 	runner.AddHandler("forget-snapshot", doForget, nil)
 	runner.AddHandler("check-snapshot", doCheck, nil)
 	runner.AddHandler("restore-snapshot", doRestore, undoRestore)
@@ -461,9 +462,62 @@ func doForget(task *state.Task, _ *tomb.Tomb) error {
 	return osRemove(snapshot.Filename)
 }
 
+// undoPreRefreshSave restores the data saved before a refresh into the
+// (reverted) current revision of the snap and then forgets the snapshot. If
+// the data cannot be restored the snapshot is kept so that the user can
+// restore it by hand.
+func undoPreRefreshSave(task *state.Task, tomb *tomb.Tomb) error {
+	st := task.State()
+	st.Lock()
+	var snapshot snapshotSetup
+	if err := task.Get("snapshot-setup", &snapshot); err != nil {
+		st.Unlock()
+		return taskGetErrMsg(task, err, "snapshot")
+	}
+	cur, err := snapstateCurrentInfo(st, snapshot.Snap)
+	if err != nil {
+		st.Unlock()
+		return err
+	}
+	opts, err := getSnapDirOpts(st, snapshot.Snap)
+	st.Unlock()
+	if err != nil {
+		return err
+	}
+
+	logf := func(format string, args ...interface{}) {
+		st.Lock()
+		defer st.Unlock()
+		task.Logf(format, args...)
+	}
+
+	if err := restorePreRefreshSnapshot(tomb, &snapshot, cur.Revision, opts, logf); err != nil {
+		logf("cannot restore data of snap %q from snapshot set #%d, keeping it: %v", snapshot.Snap, snapshot.SetID, err)
+		return nil
+	}
+
+	return doForget(task, tomb)
+}
+
+func restorePreRefreshSnapshot(tomb *tomb.Tomb, snapshot *snapshotSetup, rev snap.Revision, opts *dirs.SnapDirOptions, logf backend.Logf) error {
+	reader, err := backendOpen(snapshot.Filename, backend.ExtractFnameSetID)
+	if err != nil {
+		return fmt.Errorf("cannot open snapshot: %v", err)
+	}
+	defer reader.Close()
+
+	restoreState, err := backendRestore(reader, tomb.Context(nil), rev, nil, logf, opts)
+	if err != nil {
+		return err
+	}
+	backendCleanup(restoreState)
+	return nil
+}
+
 func delayedCrossMgrInit() {
 	// hook automatic snapshots into snapstate logic
 	snapstate.AutomaticSnapshot = AutomaticSnapshot
+	snapstate.AutomaticPreRefreshSnapshot = AutomaticPreRefreshSnapshot
 	snapstate.AutomaticSnapshotExpiration = AutomaticSnapshotExpiration
 	snapstate.EstimateSnapshotSize = EstimateSnapshotSize
 }
@@ -26,6 +26,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"time"
 
 	"gopkg.in/check.v1"
@@ -56,6 +57,7 @@ func (snapshotSuite) TestManager(c *check.C) {
 		"cleanup-after-restore",
 		"forget-snapshot",
 		"restore-snapshot",
+		"save-pre-refresh-snapshot",
 		"save-snapshot",
 	})
 }
@@ -886,6 +888,72 @@ func (rs *readerSuite) TestDoForgetRemovesAutomaticSnapshotExpiry(c *check.C) {
 		}})
 }
 
+func (rs *readerSuite) TestUndoPreRefreshSave(c *check.C) {
+	defer snapshotstate.MockSnapstateCurrentInfo(func(_ *state.State, snapName string) (*snap.Info, error) {
+		c.Check(snapName, check.Equals, "a-snap")
+		return &snap.Info{SideInfo: snap.SideInfo{RealName: "a-snap", Revision: snap.R(7)}}, nil
+	})()
+	defer snapshotstate.MockBackendRestore(func(_ *backend.Reader, _ context.Context, rev snap.Revision, users []string, _ backend.Logf, _ *dirs.SnapDirOptions) (*backend.RestoreState, error) {
+		rs.calls = append(rs.calls, "restore")
+		c.Check(rev, check.Equals, snap.R(7))
+		c.Check(users, check.IsNil)
+		return &backend.RestoreState{}, nil
+	})()
+
+	st := rs.task.State()
+	st.Lock()
+	task := st.NewTask("save-pre-refresh-snapshot", "...")
+	task.Set("snapshot-setup", map[string]interface{}{
+		"set-id":   1,
+		"snap":     "a-snap",
+		"filename": "/some/1_file.zip",
+		"auto":     true,
+	})
+	st.Set("snapshots", map[uint64]interface{}{
+		1: map[string]interface{}{"expiry-time": "2037-02-12T12:50:00Z"},
+	})
+	st.Unlock()
+
+	err := snapshotstate.UndoPreRefreshSave(task, &tomb.Tomb{})
+	c.Assert(err, check.IsNil)
+	c.Check(rs.calls, check.DeepEquals, []string{"open", "restore", "cleanup", "remove"})
+
+	st.Lock()
+	defer st.Unlock()
+	var expirations map[uint64]interface{}
+	c.Assert(st.Get("snapshots", &expirations), check.IsNil)
+	c.Check(expirations, check.HasLen, 0)
+}
+
+func (rs *readerSuite) TestUndoPreRefreshSaveKeepsSnapshotOnRestoreError(c *check.C) {
+	defer snapshotstate.MockSnapstateCurrentInfo(func(*state.State, string) (*snap.Info, error) {
+		return &snap.Info{SideInfo: snap.SideInfo{RealName: "a-snap", Revision: snap.R(7)}}, nil
+	})()
+	defer snapshotstate.MockBackendRestore(func(*backend.Reader, context.Context, snap.Revision, []string, backend.Logf, *dirs.SnapDirOptions) (*backend.RestoreState, error) {
+		rs.calls = append(rs.calls, "restore")
+		return nil, errors.New("bzzt")
+	})()
+
+	st := rs.task.State()
+	st.Lock()
+	task := st.NewTask("save-pre-refresh-snapshot", "...")
+	task.Set("snapshot-setup", map[string]interface{}{
+		"set-id":   1,
+		"snap":     "a-snap",
+		"filename": "/some/1_file.zip",
+		"auto":     true,
+	})
+	st.Unlock()
+
+	err := snapshotstate.UndoPreRefreshSave(task, &tomb.Tomb{})
+	c.Assert(err, check.IsNil)
+	c.Check(rs.calls, check.DeepEquals, []string{"open", "restore"})
+
+	st.Lock()
+	defer st.Unlock()
+	c.Check(strings.Join(task.Log(), "\n"), check.Matches, `.* cannot restore data of snap "a-snap" from snapshot set #1, keeping it: bzzt`)
+}
+
 func (snapshotSuite) TestManagerRunCleanupAbandonedImportsAtStartup(c *check.C) {
 	n := 0
 	restore := snapshotstate.MockBackendCleanupAbandonedImports(func() (int, error) {
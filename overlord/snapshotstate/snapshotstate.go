@@ -467,6 +467,43 @@ func AutomaticSnapshot(st *state.State, snapName string) (ts *state.TaskSet, err
 	return ts, nil
 }
 
+// AutomaticPreRefreshSnapshot returns a taskset saving the data of the given
+// snap before it is refreshed, if enabled via the
+// snapshots.automatic.pre-refresh system option. When the refresh is undone
+// the saved data is restored into the reverted revision.
+func AutomaticPreRefreshSnapshot(st *state.State, snapName string) (ts *state.TaskSet, err error) {
+	var enabled interface{}
+	tr := config.NewTransaction(st)
+	if err := tr.GetMaybe("core", "snapshots.automatic.pre-refresh", &enabled); err != nil {
+		return nil, err
+	}
+	if enabled != true && enabled != "true" {
+		return nil, snapstate.ErrNothingToDo
+	}
+	expiration, err := AutomaticSnapshotExpiration(st)
+	if err != nil {
+		return nil, err
+	}
+	if expiration == 0 {
+		return nil, snapstate.ErrNothingToDo
+	}
+	setID, err := newSnapshotSetID(st)
+	if err != nil {
+		return nil, err
+	}
+
+	desc := fmt.Sprintf("Save data of snap %q before refresh in automatic snapshot set #%d", snapName, setID)
+	task := st.NewTask("save-pre-refresh-snapshot", desc)
+	snapshot := snapshotSetup{
+		SetID: setID,
+		Snap:  snapName,
+		Auto:  true,
+	}
+	task.Set("snapshot-setup", &snapshot)
+
+	return state.NewTaskSet(task), nil
+}
+
 // Restore creates a taskset for restoring a snapshot's data.
 // Note that the state must be locked by the caller.
 func Restore(st *state.State, setID uint64, snapNames []string, users []string) (snapsFound []string, ts *state.TaskSet, err error) {
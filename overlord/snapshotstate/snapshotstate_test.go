@@ -1724,6 +1724,49 @@ func (snapshotSuite) TestAutomaticSnapshot(c *check.C) {
 	})
 }
 
+func (snapshotSuite) TestAutomaticPreRefreshSnapshotDisabled(c *check.C) {
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	_, err := snapshotstate.AutomaticPreRefreshSnapshot(st, "foo")
+	c.Assert(err, check.Equals, snapstate.ErrNothingToDo)
+
+	tr := config.NewTransaction(st)
+	tr.Set("core", "snapshots.automatic.pre-refresh", true)
+	tr.Set("core", "snapshots.automatic.retention", "no")
+	tr.Commit()
+
+	_, err = snapshotstate.AutomaticPreRefreshSnapshot(st, "foo")
+	c.Assert(err, check.Equals, snapstate.ErrNothingToDo)
+}
+
+func (snapshotSuite) TestAutomaticPreRefreshSnapshot(c *check.C) {
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	tr := config.NewTransaction(st)
+	tr.Set("core", "snapshots.automatic.pre-refresh", "true")
+	tr.Commit()
+
+	ts, err := snapshotstate.AutomaticPreRefreshSnapshot(st, "foo")
+	c.Assert(err, check.IsNil)
+
+	tasks := ts.Tasks()
+	c.Assert(tasks, check.HasLen, 1)
+	c.Check(tasks[0].Kind(), check.Equals, "save-pre-refresh-snapshot")
+	c.Check(tasks[0].Summary(), check.Equals, `Save data of snap "foo" before refresh in automatic snapshot set #1`)
+	var snapshot map[string]interface{}
+	c.Check(tasks[0].Get("snapshot-setup", &snapshot), check.IsNil)
+	c.Check(snapshot, check.DeepEquals, map[string]interface{}{
+		"set-id":  1.,
+		"snap":    "foo",
+		"current": "unset",
+		"auto":    true,
+	})
+}
+
 func (snapshotSuite) TestAutomaticSnapshotDefaultClassic(c *check.C) {
 	release.MockOnClassic(true)
 
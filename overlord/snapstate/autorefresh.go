@@ -39,6 +39,8 @@ import (
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/release"
 	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/channel"
+	"github.com/snapcore/snapd/store"
 	"github.com/snapcore/snapd/strutil"
 	"github.com/snapcore/snapd/timeutil"
 	"github.com/snapcore/snapd/timings"
@@ -77,6 +79,19 @@ var (
 // refreshRetryDelay specified the minimum time to retry failed refreshes
 var refreshRetryDelay = 20 * time.Minute
 
+// cannot wait for user sessions to become idle for more than maxIdlePostponement
+const maxIdlePostponement = 24 * time.Hour
+
+// idleRetryDelay is how often an auto-refresh waiting for idle user sessions
+// checks them again
+var idleRetryDelay = 10 * time.Minute
+
+// sessionsIdle queries the idle state of all user sessions via their session
+// agents
+var sessionsIdle = func(ctx context.Context) (map[int]userclient.SessionIdleInfo, error) {
+	return userclient.New().SessionIdle(ctx)
+}
+
 // refreshCandidate carries information about a single snap to update as part
 // of auto-refresh.
 type refreshCandidate struct {
@@ -130,6 +145,9 @@ type autoRefresh struct {
 	lastRefreshSchedule string
 	nextRefresh         time.Time
 	lastRefreshAttempt  time.Time
+	// idleWaitStart is when the current auto-refresh started waiting for
+	// user sessions to become idle
+	idleWaitStart time.Time
 
 	restoredMonitoring bool
 }
@@ -253,6 +271,74 @@ func canRefreshOnMeteredConnection(st *state.State) (bool, error) {
 	return onMetered != "hold", nil
 }
 
+// handleClosedTracks deals with snaps tracking a channel of a track that was
+// closed in the store, which would otherwise silently stop getting updates.
+// Unless the refresh.closed-track policy is "switch", in which case snaps are
+// moved to the default track of the snap, a warning is emitted.
+func handleClosedTracks(st *state.State, closed []*store.TrackClosedError) error {
+	if len(closed) == 0 {
+		return nil
+	}
+	tr := config.NewTransaction(st)
+	var policy string
+	err := tr.GetMaybe("core", "refresh.closed-track", &policy)
+	if err != nil && !errors.Is(err, state.ErrNoState) {
+		return err
+	}
+
+	for _, tcErr := range closed {
+		if policy == "switch" && tcErr.DefaultTrack != "" {
+			switched, err := switchFromClosedTrack(st, tcErr)
+			if err != nil {
+				return err
+			}
+			if switched {
+				continue
+			}
+		}
+		st.Warnf("snap %q is tracking the closed track %q and will not be refreshed; use 'snap refresh --channel' to track another channel", tcErr.Snap, tcErr.Track)
+	}
+	return nil
+}
+
+// switchFromClosedTrack creates a change switching the snap to the same risk
+// in the default track of the snap. It returns false if the snap cannot be
+// switched now.
+func switchFromClosedTrack(st *state.State, tcErr *store.TrackClosedError) (bool, error) {
+	var snapst SnapState
+	if err := Get(st, tcErr.Snap, &snapst); err != nil {
+		return false, err
+	}
+	ch, err := channel.ParseVerbatim(snapst.TrackingChannel, "")
+	if err != nil {
+		return false, err
+	}
+	risk := ch.Risk
+	if risk == "" {
+		risk = "stable"
+	}
+	newChannel := tcErr.DefaultTrack + "/" + risk
+	ts, err := Switch(st, tcErr.Snap, &RevisionOptions{Channel: newChannel})
+	if err != nil {
+		var conflErr *ChangeConflictError
+		if errors.As(err, &conflErr) {
+			logger.Noticef("cannot switch snap %q from closed track %q: %v", tcErr.Snap, tcErr.Track, err)
+			return false, nil
+		}
+		return false, err
+	}
+	// the switch goes through a change like a user initiated one so that
+	// it is recorded, conflicts with other operations on the snap and
+	// runs the switch-snap handler
+	msg := fmt.Sprintf(i18n.G("Switch snap %q from closed track %q to %q"), tcErr.Snap, tcErr.Track, newChannel)
+	chg := st.NewChange("switch-snap-channel", msg)
+	chg.AddAll(ts)
+	chg.Set("snap-names", []string{tcErr.Snap})
+	st.EnsureBefore(0)
+	st.Warnf("snap %q is being switched from the closed track %q to %q", tcErr.Snap, tcErr.Track, newChannel)
+	return true, nil
+}
+
 func (m *autoRefresh) canRefreshRespectingMetered(now, lastRefresh time.Time) (can bool, err error) {
 	can, err = canRefreshOnMeteredConnection(m.state)
 	if err != nil {
@@ -280,6 +366,66 @@ func (m *autoRefresh) canRefreshRespectingMetered(now, lastRefresh time.Time) (c
 	return false, nil
 }
 
+// preferIdleDuration returns how long user sessions should have been idle
+// before an auto-refresh is attempted, or zero if refreshes should not wait
+// for idle sessions.
+func preferIdleDuration(st *state.State) time.Duration {
+	var preferIdleMinutes int
+	err := config.NewTransaction(st).GetMaybe("core", "refresh.prefer-idle-minutes", &preferIdleMinutes)
+	if err != nil {
+		logger.Noticef("internal error: refresh.prefer-idle-minutes system option is not valid: %v", err)
+		return 0
+	}
+	return time.Duration(preferIdleMinutes) * time.Minute
+}
+
+// canRefreshRespectingIdle returns whether all user sessions have been idle
+// for the time configured with refresh.prefer-idle-minutes, so that apps are
+// not refreshed under the feet of their users. The state must be locked, it
+// is temporarily unlocked while querying the session agents.
+func (m *autoRefresh) canRefreshRespectingIdle(now time.Time) bool {
+	// only desktop systems have user sessions worth waiting for
+	if !release.OnClassic {
+		return true
+	}
+	preferIdle := preferIdleDuration(m.state)
+	if preferIdle == 0 {
+		m.idleWaitStart = time.Time{}
+		return true
+	}
+
+	if m.idleWaitStart.IsZero() {
+		m.idleWaitStart = now
+	}
+	if now.Sub(m.idleWaitStart) >= maxIdlePostponement {
+		logger.Noticef("Auto refresh waited for idle user sessions for too long (%d hours). Trying to refresh now.", int(maxIdlePostponement.Hours()))
+		m.idleWaitStart = time.Time{}
+		return true
+	}
+
+	m.state.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	sessions, err := sessionsIdle(ctx)
+	cancel()
+	m.state.Lock()
+	if err != nil {
+		// do not hold refreshes because of misbehaving session agents
+		logger.Noticef("Cannot determine if user sessions are idle: %v", err)
+		m.idleWaitStart = time.Time{}
+		return true
+	}
+
+	for uid, session := range sessions {
+		if !session.Idle || session.IdleSince.IsZero() || now.Sub(session.IdleSince) < preferIdle {
+			logger.Debugf("Auto refresh postponed until the session of user %d is idle for %s", uid, preferIdle)
+			return false
+		}
+	}
+
+	m.idleWaitStart = time.Time{}
+	return true
+}
+
 func isStoreOnline(s *state.State) (bool, error) {
 	tr := config.NewTransaction(s)
 
@@ -395,6 +541,12 @@ func (m *autoRefresh) Ensure() (err error) {
 				return nil
 			}
 
+			if !m.canRefreshRespectingIdle(now) {
+				// check the user sessions again soon
+				m.nextRefresh = now.Add(idleRetryDelay)
+				return nil
+			}
+
 			err = m.launchAutoRefresh()
 			if _, ok := err.(*httputil.PersistentNetworkError); ok {
 				// refresh will be retried after refreshRetryDelay
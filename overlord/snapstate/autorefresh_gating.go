@@ -743,8 +743,11 @@ func conditionalAutoRefreshAffectedSnaps(t *state.Task) ([]string, error) {
 		return nil, fmt.Errorf("internal error: cannot get snaps to update for %s task %s", t.Kind(), t.ID())
 	}
 	names := make([]string, 0, len(snaps))
+	// once gate-auto-refresh hooks ran, doConditionalAutoRefresh empties
+	// the map if all snaps are held, or narrows it down to the snaps that
+	// are not held if refresh tasks get created. Otherwise held snaps
+	// remain in the map until the task is done.
 	for sn := range snaps {
-		// TODO: drop snaps once we know the outcome of gate-auto-refresh hooks.
 		names = append(names, sn)
 	}
 	return names, nil
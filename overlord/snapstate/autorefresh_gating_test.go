@@ -2232,7 +2232,7 @@ func (s *snapmgrTestSuite) TestAutoRefreshPhase2AllHeld(c *C) {
 		"run-hook [snap-b;gate-auto-refresh]",
 	}
 
-	s.testAutoRefreshPhase2(c, nil, func(snapName string) {
+	chg := s.testAutoRefreshPhase2(c, nil, func(snapName string) {
 		switch snapName {
 		case "snap-b":
 			// pretend that snap-b calls snapctl --hold to hold refresh of base-snap-b
@@ -2248,6 +2248,14 @@ func (s *snapmgrTestSuite) TestAutoRefreshPhase2AllHeld(c *C) {
 	}, expected)
 
 	c.Assert(logbuf.String(), testutil.Contains, `skipping refresh of held snaps: base-snap-b,snap-a`)
+
+	// held snaps are no longer considered affected by the gating task
+	s.state.Lock()
+	defer s.state.Unlock()
+	var snaps map[string]interface{}
+	c.Assert(chg.Tasks()[0].Kind(), Equals, "conditional-auto-refresh")
+	c.Assert(chg.Tasks()[0].Get("snaps", &snaps), IsNil)
+	c.Check(snaps, HasLen, 0)
 }
 
 func (s *snapmgrTestSuite) testAutoRefreshPhase2DiskSpaceCheck(c *C, fail bool) {
@@ -2473,6 +2481,7 @@ func (s *snapmgrTestSuite) TestAutoRefreshPhase2ConflictOtherSnapOp(c *C) {
 		ChangeKind: "fake-auto-refresh",
 		Snap:       "snap-a",
 		ChangeID:   chg.ID(),
+		RetryAfter: 5 * time.Second,
 	})
 
 	_, err = snapstate.Update(s.state, "snap-a", nil, 0, snapstate.Flags{})
@@ -2480,6 +2489,7 @@ func (s *snapmgrTestSuite) TestAutoRefreshPhase2ConflictOtherSnapOp(c *C) {
 		ChangeKind: "fake-auto-refresh",
 		Snap:       "snap-a",
 		ChangeID:   chg.ID(),
+		RetryAfter: 5 * time.Second,
 	})
 
 	// only 2 tasks because we don't run settle() so conditional-auto-refresh
@@ -953,6 +953,88 @@ func (s *autoRefreshTestSuite) TestRefreshOnMeteredConnNotMetered(c *C) {
 	c.Check(s.store.ops, DeepEquals, []string{"list-refresh"})
 }
 
+func (s *autoRefreshTestSuite) TestRefreshPreferIdleSessionsBusy(c *C) {
+	defer release.MockOnClassic(true)()
+	restore := snapstate.MockSessionsIdle(func(ctx context.Context) (map[int]userclient.SessionIdleInfo, error) {
+		return map[int]userclient.SessionIdleInfo{
+			1000: {Idle: true, IdleSince: time.Now().Add(-time.Hour)},
+			1001: {Idle: false},
+		}, nil
+	})
+	defer restore()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	tr := config.NewTransaction(s.state)
+	tr.Set("core", "refresh.prefer-idle-minutes", 30)
+	tr.Commit()
+
+	af := snapstate.NewAutoRefresh(s.state)
+
+	s.state.Set("last-refresh", time.Now().Add(-5*24*time.Hour))
+	s.state.Unlock()
+	err := af.Ensure()
+	s.state.Lock()
+	c.Check(err, IsNil)
+	// no refresh
+	c.Check(s.store.ops, HasLen, 0)
+	// but it is retried soon
+	c.Check(af.NextRefresh().After(time.Now()), Equals, true)
+	c.Check(af.NextRefresh().Before(time.Now().Add(11*time.Minute)), Equals, true)
+}
+
+func (s *autoRefreshTestSuite) TestRefreshPreferIdleSessionsIdle(c *C) {
+	defer release.MockOnClassic(true)()
+	restore := snapstate.MockSessionsIdle(func(ctx context.Context) (map[int]userclient.SessionIdleInfo, error) {
+		return map[int]userclient.SessionIdleInfo{
+			1000: {Idle: true, IdleSince: time.Now().Add(-time.Hour)},
+		}, nil
+	})
+	defer restore()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	tr := config.NewTransaction(s.state)
+	tr.Set("core", "refresh.prefer-idle-minutes", 30)
+	tr.Commit()
+
+	af := snapstate.NewAutoRefresh(s.state)
+
+	s.state.Set("last-refresh", time.Now().Add(-5*24*time.Hour))
+	s.state.Unlock()
+	err := af.Ensure()
+	s.state.Lock()
+	c.Check(err, IsNil)
+	c.Check(s.store.ops, DeepEquals, []string{"list-refresh"})
+}
+
+func (s *autoRefreshTestSuite) TestRefreshPreferIdleSessionsError(c *C) {
+	defer release.MockOnClassic(true)()
+	restore := snapstate.MockSessionsIdle(func(ctx context.Context) (map[int]userclient.SessionIdleInfo, error) {
+		return nil, fmt.Errorf("boom")
+	})
+	defer restore()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	tr := config.NewTransaction(s.state)
+	tr.Set("core", "refresh.prefer-idle-minutes", 30)
+	tr.Commit()
+
+	af := snapstate.NewAutoRefresh(s.state)
+
+	s.state.Set("last-refresh", time.Now().Add(-5*24*time.Hour))
+	s.state.Unlock()
+	err := af.Ensure()
+	s.state.Lock()
+	c.Check(err, IsNil)
+	// session agents errors do not hold the refresh
+	c.Check(s.store.ops, DeepEquals, []string{"list-refresh"})
+}
+
 func (s *autoRefreshTestSuite) TestInitialInhibitRefreshWithinInhibitWindow(c *C) {
 	s.state.Lock()
 	defer s.state.Unlock()
@@ -22,8 +22,10 @@ package backend
 import (
 	"context"
 	"os"
+	"syscall"
 
 	"github.com/snapcore/snapd/kernel"
+	"github.com/snapcore/snapd/osutil/firewall"
 	"github.com/snapcore/snapd/osutil/sys"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/testutil"
@@ -38,6 +40,22 @@ var (
 	SnapCommonDataDirs = snapCommonDataDirs
 )
 
+func MockOsutilIsMounted(f func(baseDir string) (bool, error)) (restore func()) {
+	return testutil.Mock(&osutilIsMounted, f)
+}
+
+func MockSyscallStatfs(f func(path string, buf *syscall.Statfs_t) error) (restore func()) {
+	return testutil.Mock(&syscallStatfs, f)
+}
+
+func MockFirewallSetPorts(f func(tag string, ports []firewall.Port) error) (restore func()) {
+	return testutil.Mock(&firewallSetPorts, f)
+}
+
+func MockFirewallRemovePorts(f func(tag string) error) (restore func()) {
+	return testutil.Mock(&firewallRemovePorts, f)
+}
+
 func MockWrappersAddSnapdSnapServices(f func(s *snap.Info, opts *wrappers.AddSnapdSnapServicesOptions, inter wrappers.Interacter) (wrappers.SnapdRestart, error)) (restore func()) {
 	old := wrappersAddSnapdSnapServices
 	wrappersAddSnapdSnapServices = f
@@ -0,0 +1,65 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package backend
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/snapcore/snapd/osutil/firewall"
+	"github.com/snapcore/snapd/snap"
+)
+
+var (
+	firewallSetPorts    = firewall.SetPorts
+	firewallRemovePorts = firewall.RemovePorts
+)
+
+func firewallTag(instanceName string) string {
+	return "snap." + instanceName
+}
+
+// SetupFirewallPorts opens the ports declared by the services of the snap in
+// the firewall of the system and closes those opened for a previous revision
+// which are no longer declared.
+func (b Backend) SetupFirewallPorts(info *snap.Info) error {
+	appNames := make([]string, 0, len(info.Apps))
+	for name := range info.Apps {
+		appNames = append(appNames, name)
+	}
+	sort.Strings(appNames)
+
+	var ports []firewall.Port
+	for _, name := range appNames {
+		for _, p := range info.Apps[name].Ports {
+			port, err := firewall.ParsePort(p)
+			if err != nil {
+				return fmt.Errorf("internal error: %v", err)
+			}
+			ports = append(ports, port)
+		}
+	}
+	return firewallSetPorts(firewallTag(info.InstanceName()), ports)
+}
+
+// RemoveFirewallPorts closes all the ports opened for the snap.
+func (b Backend) RemoveFirewallPorts(instanceName string) error {
+	return firewallRemovePorts(firewallTag(instanceName))
+}
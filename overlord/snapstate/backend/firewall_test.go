@@ -0,0 +1,85 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package backend_test
+
+import (
+	"errors"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/osutil/firewall"
+	"github.com/snapcore/snapd/overlord/snapstate/backend"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type firewallSuite struct {
+	testutil.BaseTest
+}
+
+var _ = Suite(&firewallSuite{})
+
+func (s *firewallSuite) TestSetupFirewallPorts(c *C) {
+	info := snaptest.MockInfo(c, `name: foo
+version: 1
+apps:
+ web:
+  command: bin/web
+  daemon: simple
+  plugs: [network-bind]
+  ports: [8080/tcp, 8443/tcp]
+ dns:
+  command: bin/dns
+  daemon: simple
+  plugs: [network-bind]
+  ports: [5353/udp]
+ cli:
+  command: bin/cli
+`, nil)
+	info.InstanceKey = "bar"
+
+	var calls int
+	s.AddCleanup(backend.MockFirewallSetPorts(func(tag string, ports []firewall.Port) error {
+		calls++
+		c.Check(tag, Equals, "snap.foo_bar")
+		c.Check(ports, DeepEquals, []firewall.Port{
+			{Number: 5353, Protocol: "udp"},
+			{Number: 8080, Protocol: "tcp"},
+			{Number: 8443, Protocol: "tcp"},
+		})
+		return errors.New("boom")
+	}))
+
+	b := backend.Backend{}
+	c.Check(b.SetupFirewallPorts(info), ErrorMatches, "boom")
+	c.Check(calls, Equals, 1)
+}
+
+func (s *firewallSuite) TestRemoveFirewallPorts(c *C) {
+	var tags []string
+	s.AddCleanup(backend.MockFirewallRemovePorts(func(tag string) error {
+		tags = append(tags, tag)
+		return nil
+	}))
+
+	b := backend.Backend{}
+	c.Check(b.RemoveFirewallPorts("foo_bar"), IsNil)
+	c.Check(tags, DeepEquals, []string{"snap.foo_bar"})
+}
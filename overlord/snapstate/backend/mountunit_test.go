@@ -49,6 +49,8 @@ type FakeSystemd struct {
 	EnsureMountUnitFileCalls  []ParamsForEnsureMountUnitFile
 	EnsureMountUnitFileResult ResultForEnsureMountUnitFile
 
+	EnsureMountUnitFileWithOptionsCalls []*systemd.MountUnitOptions
+
 	RemoveMountUnitFileCalls  []string
 	RemoveMountUnitFileResult error
 
@@ -62,6 +64,11 @@ func (s *FakeSystemd) EnsureMountUnitFile(description, what, where, fstype strin
 	return s.EnsureMountUnitFileResult.path, s.EnsureMountUnitFileResult.err
 }
 
+func (s *FakeSystemd) EnsureMountUnitFileWithOptions(unitOptions *systemd.MountUnitOptions) (string, error) {
+	s.EnsureMountUnitFileWithOptionsCalls = append(s.EnsureMountUnitFileWithOptionsCalls, unitOptions)
+	return s.EnsureMountUnitFileResult.path, s.EnsureMountUnitFileResult.err
+}
+
 func (s *FakeSystemd) RemoveMountUnitFile(mountDir string) error {
 	s.RemoveMountUnitFileCalls = append(s.RemoveMountUnitFileCalls, mountDir)
 	return s.RemoveMountUnitFileResult
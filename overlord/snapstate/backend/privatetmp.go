@@ -0,0 +1,107 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package backend
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/gadget/quantity"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/progress"
+	"github.com/snapcore/snapd/systemd"
+)
+
+var (
+	osutilIsMounted = osutil.IsMounted
+	syscallStatfs   = syscall.Statfs
+)
+
+// privateTmpDir returns the directory that snap-confine bind mounts, through
+// its tmp sub-directory, as /tmp of the given snap.
+func privateTmpDir(instanceName string) string {
+	return filepath.Join(dirs.SnapPrivateTmpDir, "snap."+instanceName)
+}
+
+// SetupPrivateTmp backs the private /tmp of the snap with a tmpfs of the
+// given size, zero meaning the default of half of the RAM. The size of an
+// already mounted tmpfs changes the next time it is mounted.
+func (b Backend) SetupPrivateTmp(instanceName string, size quantity.Size, meter progress.Meter) error {
+	// snap-confine expects both directories to be owned by root with
+	// mode 0700, the root one is normally created by systemd-tmpfiles
+	if err := os.MkdirAll(dirs.SnapPrivateTmpDir, 0700); err != nil {
+		return err
+	}
+	dir := privateTmpDir(instanceName)
+	if err := os.Mkdir(dir, 0700); err != nil && !os.IsExist(err) {
+		return err
+	}
+
+	options := []string{"mode=0700", "nosuid", "nodev"}
+	if size != 0 {
+		options = append(options, fmt.Sprintf("size=%d", size))
+	}
+	sysd := systemd.New(systemd.SystemMode, meter)
+	// transient as /tmp does not survive a reboot either
+	_, err := sysd.EnsureMountUnitFileWithOptions(&systemd.MountUnitOptions{
+		Lifetime:    systemd.Transient,
+		Description: fmt.Sprintf("Private /tmp of snap %s", instanceName),
+		What:        "tmpfs",
+		Where:       dirs.StripRootDir(dir),
+		Fstype:      "tmpfs",
+		Options:     options,
+		Origin:      "private-tmp",
+		// restarting would discard the content of the tmpfs
+		PreventRestartIfModified: true,
+	})
+	return err
+}
+
+// RemovePrivateTmp unmounts the tmpfs backing the private /tmp of the snap
+// and removes the directory.
+func (b Backend) RemovePrivateTmp(instanceName string, meter progress.Meter) error {
+	dir := privateTmpDir(instanceName)
+	sysd := systemd.New(systemd.SystemMode, meter)
+	if err := sysd.RemoveMountUnitFile(dir); err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// PrivateTmpUsage returns the space in use in and the size of the tmpfs
+// backing the private /tmp of the snap, both are zero if /tmp of the snap is
+// not backed by a tmpfs.
+func PrivateTmpUsage(instanceName string) (used, size quantity.Size, err error) {
+	dir := privateTmpDir(instanceName)
+	mounted, err := osutilIsMounted(dir)
+	if err != nil || !mounted {
+		return 0, 0, err
+	}
+	var st syscall.Statfs_t
+	if err := syscallStatfs(dir, &st); err != nil {
+		return 0, 0, err
+	}
+	size = quantity.Size(st.Blocks * uint64(st.Bsize))
+	used = quantity.Size((st.Blocks - st.Bfree) * uint64(st.Bsize))
+	return used, size, nil
+}
@@ -0,0 +1,136 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package backend_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/gadget/quantity"
+	"github.com/snapcore/snapd/overlord/snapstate/backend"
+	"github.com/snapcore/snapd/progress"
+	"github.com/snapcore/snapd/systemd"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type privateTmpSuite struct {
+	testutil.BaseTest
+
+	sysd *FakeSystemd
+}
+
+var _ = Suite(&privateTmpSuite{})
+
+func (s *privateTmpSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+	dirs.SetRootDir(c.MkDir())
+	s.AddCleanup(func() { dirs.SetRootDir("") })
+
+	s.sysd = &FakeSystemd{}
+	s.AddCleanup(systemd.MockNewSystemd(func(be systemd.Backend, roodDir string, mode systemd.InstanceMode, meter systemd.Reporter) systemd.Systemd {
+		return s.sysd
+	}))
+}
+
+func (s *privateTmpSuite) TestSetupPrivateTmp(c *C) {
+	b := backend.Backend{}
+	err := b.SetupPrivateTmp("foo_bar", 64*quantity.SizeMiB, progress.Null)
+	c.Assert(err, IsNil)
+
+	dir := filepath.Join(dirs.SnapPrivateTmpDir, "snap.foo_bar")
+	for _, d := range []string{dirs.SnapPrivateTmpDir, dir} {
+		fi, err := os.Stat(d)
+		c.Assert(err, IsNil)
+		c.Check(fi.Mode(), Equals, os.ModeDir|0700)
+	}
+	c.Check(s.sysd.EnsureMountUnitFileWithOptionsCalls, DeepEquals, []*systemd.MountUnitOptions{{
+		Lifetime:                 systemd.Transient,
+		Description:              "Private /tmp of snap foo_bar",
+		What:                     "tmpfs",
+		Where:                    "/tmp/snap-private-tmp/snap.foo_bar",
+		Fstype:                   "tmpfs",
+		Options:                  []string{"mode=0700", "nosuid", "nodev", "size=67108864"},
+		Origin:                   "private-tmp",
+		PreventRestartIfModified: true,
+	}})
+
+	// the directory may exist already, no size uses the tmpfs default
+	err = b.SetupPrivateTmp("foo_bar", 0, progress.Null)
+	c.Assert(err, IsNil)
+	c.Assert(s.sysd.EnsureMountUnitFileWithOptionsCalls, HasLen, 2)
+	c.Check(s.sysd.EnsureMountUnitFileWithOptionsCalls[1].Options, DeepEquals, []string{"mode=0700", "nosuid", "nodev"})
+}
+
+func (s *privateTmpSuite) TestSetupPrivateTmpError(c *C) {
+	s.sysd.EnsureMountUnitFileResult.err = errors.New("boom")
+
+	err := backend.Backend{}.SetupPrivateTmp("foo", 0, progress.Null)
+	c.Assert(err, ErrorMatches, "boom")
+}
+
+func (s *privateTmpSuite) TestRemovePrivateTmp(c *C) {
+	dir := filepath.Join(dirs.SnapPrivateTmpDir, "snap.foo")
+	c.Assert(os.MkdirAll(filepath.Join(dir, "tmp"), 0700), IsNil)
+
+	err := backend.Backend{}.RemovePrivateTmp("foo", progress.Null)
+	c.Assert(err, IsNil)
+	c.Check(s.sysd.RemoveMountUnitFileCalls, DeepEquals, []string{dir})
+	c.Check(dir, testutil.FileAbsent)
+
+	// the unit must be removed before the directory
+	c.Assert(os.MkdirAll(dir, 0700), IsNil)
+	s.sysd.RemoveMountUnitFileResult = errors.New("boom")
+	err = backend.Backend{}.RemovePrivateTmp("foo", progress.Null)
+	c.Assert(err, ErrorMatches, "boom")
+	c.Check(dir, testutil.FilePresent)
+}
+
+func (s *privateTmpSuite) TestPrivateTmpUsage(c *C) {
+	dir := filepath.Join(dirs.SnapPrivateTmpDir, "snap.foo")
+	mounted := false
+	defer backend.MockOsutilIsMounted(func(baseDir string) (bool, error) {
+		c.Check(baseDir, Equals, dir)
+		return mounted, nil
+	})()
+	defer backend.MockSyscallStatfs(func(path string, buf *syscall.Statfs_t) error {
+		c.Check(path, Equals, dir)
+		buf.Bsize = 4096
+		buf.Blocks = 1024
+		buf.Bfree = 768
+		return nil
+	})()
+
+	// not backed by a tmpfs
+	used, size, err := backend.PrivateTmpUsage("foo")
+	c.Assert(err, IsNil)
+	c.Check(used, Equals, quantity.Size(0))
+	c.Check(size, Equals, quantity.Size(0))
+
+	mounted = true
+	used, size, err = backend.PrivateTmpUsage("foo")
+	c.Assert(err, IsNil)
+	c.Check(used, Equals, quantity.Size(1024*1024))
+	c.Check(size, Equals, 4*quantity.SizeMiB)
+}
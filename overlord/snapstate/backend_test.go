@@ -36,6 +36,7 @@ import (
 	"github.com/snapcore/snapd/cmd/snaplock"
 	"github.com/snapcore/snapd/cmd/snaplock/runinhibit"
 	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/gadget/quantity"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/overlord/auth"
 	"github.com/snapcore/snapd/overlord/snapstate"
@@ -234,6 +235,12 @@ func (f *fakeStore) SnapInfo(ctx context.Context, spec store.SnapSpec, user *aut
 		Name: spec.Name,
 	}
 	info, err := f.snap(sspec)
+	if err == nil && spec.Name == "some-epoch-snap" {
+		info.Channels = map[string]*snap.ChannelSnapInfo{
+			"latest/stable":    {Revision: snap.R(11), Channel: "latest/stable", Epoch: snap.E("42")},
+			"latest/candidate": {Revision: snap.R(7), Channel: "latest/candidate", Epoch: snap.Epoch{Read: []uint32{13, 42}, Write: []uint32{42}}},
+		}
+	}
 
 	userID := 0
 	if user != nil {
@@ -468,6 +475,8 @@ func (f *fakeStore) lookupRefresh(cand refreshCand) (*snap.Info, error) {
 		return nil, store.ErrNoUpdateAvailable
 	case "fakestore-please-error-on-refresh":
 		return nil, fmt.Errorf("failing as requested")
+	case "closed-track-snap-id":
+		return nil, &store.TrackClosedError{Track: "1.0", DefaultTrack: "latest"}
 	case "services-snap-id":
 		name = "services-snap"
 	case "some-snap-id":
@@ -852,6 +861,12 @@ func (f *fakeStore) SnapAction(ctx context.Context, currentSnaps []*store.Curren
 			refreshErrors[cur.InstanceName] = err
 			continue
 		}
+		var tcErr *store.TrackClosedError
+		if errors.As(err, &tcErr) {
+			tcErr.Snap = cur.InstanceName
+			refreshErrors[cur.InstanceName] = tcErr
+			continue
+		}
 		if err != nil {
 			return nil, nil, err
 		}
@@ -974,6 +989,16 @@ type fakeSnappyBackend struct {
 
 	lockDir string
 
+	// privateTmps tracks the size of the private /tmp of snaps, it is
+	// kept out of ops to not affect the many tests checking those
+	privateTmps         map[string]quantity.Size
+	setupPrivateTmpErr  error
+	removePrivateTmpErr error
+
+	// firewallPorts tracks the ports of snaps opened in the firewall,
+	// it is kept out of ops for the same reason
+	firewallPorts map[string][]string
+
 	// TODO cleanup triggers above
 	maybeInjectErr func(*fakeOp) error
 
@@ -1337,6 +1362,51 @@ func (f *fakeSnappyBackend) MaybeSetNextBoot(
 	return boot.RebootInfo{RebootRequired: reboot}, nil
 }
 
+func (f *fakeSnappyBackend) SetupPrivateTmp(instanceName string, size quantity.Size, meter progress.Meter) error {
+	if f.setupPrivateTmpErr != nil {
+		return f.setupPrivateTmpErr
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.privateTmps == nil {
+		f.privateTmps = make(map[string]quantity.Size)
+	}
+	f.privateTmps[instanceName] = size
+	return nil
+}
+
+func (f *fakeSnappyBackend) RemovePrivateTmp(instanceName string, meter progress.Meter) error {
+	if f.removePrivateTmpErr != nil {
+		return f.removePrivateTmpErr
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.privateTmps, instanceName)
+	return nil
+}
+
+func (f *fakeSnappyBackend) SetupFirewallPorts(info *snap.Info) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.firewallPorts == nil {
+		f.firewallPorts = make(map[string][]string)
+	}
+	var ports []string
+	for _, app := range info.Apps {
+		ports = append(ports, app.Ports...)
+	}
+	sort.Strings(ports)
+	f.firewallPorts[info.InstanceName()] = ports
+	return nil
+}
+
+func (f *fakeSnappyBackend) RemoveFirewallPorts(instanceName string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.firewallPorts, instanceName)
+	return nil
+}
+
 func (f *fakeSnappyBackend) LinkSnap(info *snap.Info, dev snap.Device, linkCtx backend.LinkContext, tm timings.Measurer) (err error) {
 	if info.MountDir() == f.linkSnapWaitTrigger {
 		f.linkSnapWaitCh <- 1
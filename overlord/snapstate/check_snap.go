@@ -20,6 +20,7 @@
 package snapstate
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"regexp"
@@ -35,7 +36,9 @@ import (
 	"github.com/snapcore/snapd/release"
 	seccomp_compiler "github.com/snapcore/snapd/sandbox/seccomp"
 	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snapdenv"
 	"github.com/snapcore/snapd/snapdtool"
+	"github.com/snapcore/snapd/store"
 	"github.com/snapcore/snapd/strutil"
 )
 
@@ -359,6 +362,9 @@ func checkGadgetOrKernel(st *state.State, snapInfo, curInfo *snap.Info, snapf sn
 		// not a relevant check
 		return nil
 	}
+	if snapdenv.ContainerProfile() {
+		return fmt.Errorf("cannot install %s snap %q: snapd runs with the container profile", kind, snapInfo.InstanceName())
+	}
 	ok, err := HasSnapOfType(st, typ)
 	if err != nil {
 		return fmt.Errorf("cannot detect original %s snap: %v", kind, err)
@@ -435,6 +441,31 @@ func checkBases(st *state.State, snapInfo, curInfo *snap.Info, _ snap.Container,
 	return fmt.Errorf("cannot find required base %q", snapInfo.Base)
 }
 
+// EpochGapError is returned when a snap cannot be refreshed to a revision
+// because that revision cannot read the data of the current epoch.
+type EpochGapError struct {
+	Snap         string
+	Revision     snap.Revision
+	Epoch        snap.Epoch
+	CurrentEpoch snap.Epoch
+	// Hop is a revision from the channel map of the snap that can be
+	// refreshed to first to bridge the gap between the epochs, if any.
+	Hop *snap.ChannelSnapInfo
+}
+
+func (e *EpochGapError) Error() string {
+	desc := "local snap"
+	if e.Revision.Store() {
+		desc = fmt.Sprintf("new revision %s", e.Revision)
+	}
+
+	msg := fmt.Sprintf("cannot refresh %q to %s with epoch %s, because it can't read the current epoch of %s", e.Snap, desc, e.Epoch, e.CurrentEpoch)
+	if e.Hop != nil {
+		msg += fmt.Sprintf(", refresh to revision %s from channel %q first", e.Hop.Revision, e.Hop.Channel)
+	}
+	return msg
+}
+
 func checkEpochs(_ *state.State, snapInfo, curInfo *snap.Info, _ snap.Container, _ Flags, deviceCtx DeviceContext) error {
 	if curInfo == nil {
 		return nil
@@ -442,12 +473,40 @@ func checkEpochs(_ *state.State, snapInfo, curInfo *snap.Info, _ snap.Container,
 	if snapInfo.Epoch.CanRead(curInfo.Epoch) {
 		return nil
 	}
-	desc := "local snap"
-	if snapInfo.SideInfo.Revision.Store() {
-		desc = fmt.Sprintf("new revision %s", snapInfo.SideInfo.Revision)
+
+	return &EpochGapError{
+		Snap:         snapInfo.InstanceName(),
+		Revision:     snapInfo.SideInfo.Revision,
+		Epoch:        snapInfo.Epoch,
+		CurrentEpoch: curInfo.Epoch,
+	}
+}
+
+// findEpochHop looks for a revision in the channel map of the snap in the
+// store that can bridge the gap between the epochs of the given error.
+func findEpochHop(ctx context.Context, st *state.State, gapErr *EpochGapError, opts Options) *snap.ChannelSnapInfo {
+	if !gapErr.Revision.Store() {
+		return nil
 	}
 
-	return fmt.Errorf("cannot refresh %q to %s with epoch %s, because it can't read the current epoch of %s", snapInfo.InstanceName(), desc, snapInfo.Epoch, curInfo.Epoch)
+	user, err := userFromUserID(st, opts.UserID)
+	if err != nil {
+		return nil
+	}
+	deviceCtx, err := DeviceCtx(st, nil, opts.DeviceCtx)
+	if err != nil {
+		return nil
+	}
+	sto := Store(st, deviceCtx)
+
+	st.Unlock()
+	info, err := sto.SnapInfo(ctx, store.SnapSpec{Name: snap.InstanceSnap(gapErr.Snap)}, user)
+	st.Lock()
+	if err != nil {
+		logger.Debugf("cannot get the channel map of %q: %v", gapErr.Snap, err)
+		return nil
+	}
+	return snap.EpochHop(info.Channels, gapErr.CurrentEpoch, gapErr.Epoch)
 }
 
 // check that the snap installed in the system (via snapst) can be
@@ -469,8 +528,8 @@ func earlyEpochCheck(info *snap.Info, snapst *SnapState) error {
 	return checkEpochs(nil, info, cur, nil, Flags{}, nil)
 }
 
-func earlyChecks(st *state.State, snapst *SnapState, update *snap.Info, comps []snap.ComponentSideInfo, flags Flags) (Flags, error) {
-	flags, err := ensureInstallPreconditions(st, update, flags, snapst)
+func earlyChecks(st *state.State, snapst *SnapState, update *snap.Info, comps []snap.ComponentSideInfo, opts Options) (Flags, error) {
+	flags, err := ensureInstallPreconditions(st, update, opts.Flags, snapst)
 	if err != nil {
 		return flags, err
 	}
@@ -480,6 +539,10 @@ func earlyChecks(st *state.State, snapst *SnapState, update *snap.Info, comps []
 	}
 
 	if err := earlyEpochCheck(update, snapst); err != nil {
+		var gapErr *EpochGapError
+		if errors.As(err, &gapErr) {
+			gapErr.Hop = findEpochHop(context.TODO(), st, gapErr, opts)
+		}
 		return flags, err
 	}
 	return flags, nil
@@ -499,6 +562,21 @@ func ensureSnapAndComponentsAssertionStatus(si snap.SideInfo, comps []snap.Compo
 	return nil
 }
 
+// checkUnassertedSideloadAllowed checks that the model of the device allows
+// installing unasserted snaps or components, which is not the case if it
+// restricts who can sign the assertions of sideloaded ones. The kind is
+// either "snap" or "component".
+func checkUnassertedSideloadAllowed(kind, name string, deviceCtx DeviceContext) error {
+	if deviceCtx == nil {
+		return nil
+	}
+	model := deviceCtx.Model()
+	if model == nil || model.SideloadAuthority() == nil {
+		return nil
+	}
+	return fmt.Errorf("cannot install unasserted %s %q: the device model only allows sideloading snaps signed by: %s", kind, name, strings.Join(model.SideloadAuthority(), ", "))
+}
+
 // check that the listed system users are valid
 var osutilEnsureSnapUserGroup = osutil.EnsureSnapUserGroup
 
@@ -38,6 +38,7 @@ import (
 	seccomp_compiler "github.com/snapcore/snapd/sandbox/seccomp"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/snapdenv"
 	"github.com/snapcore/snapd/snapdtool"
 	"github.com/snapcore/snapd/testutil"
 )
@@ -346,6 +347,32 @@ version: 2
 	c.Check(err, IsNil)
 }
 
+func (s *checkSnapSuite) TestCheckSnapGadgetContainerProfile(c *C) {
+	restore := snapdenv.MockContainerProfile(true)
+	defer restore()
+
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	for _, typ := range []string{"gadget", "kernel"} {
+		yaml := fmt.Sprintf("name: foo\ntype: %s\nversion: 1\n", typ)
+		info, err := snap.InfoFromSnapYaml([]byte(yaml))
+		c.Assert(err, IsNil)
+
+		var openSnapFile = func(path string, si *snap.SideInfo) (*snap.Info, snap.Container, error) {
+			return info, emptyContainer(c), nil
+		}
+		restore := snapstate.MockOpenSnapFile(openSnapFile)
+		defer restore()
+
+		st.Unlock()
+		err = snapstate.CheckSnap(st, "snap-path", "foo", nil, nil, snapstate.Flags{}, s.deviceCtx)
+		st.Lock()
+		c.Check(err, ErrorMatches, fmt.Sprintf(`cannot install %s snap "foo": snapd runs with the container profile`, typ))
+	}
+}
+
 func (s *checkSnapSuite) TestCheckSnapGadgetUpdateLocal(c *C) {
 	reset := release.MockOnClassic(false)
 	defer reset()
@@ -256,6 +256,12 @@ func InstallComponentPath(st *state.State, csi *snap.ComponentSideInfo, info *sn
 		return nil, err
 	}
 
+	if csi.Revision.Unset() && !opts.Seed {
+		if err := checkUnassertedSideloadAllowed("component", csi.Component.String(), opts.DeviceCtx); err != nil {
+			return nil, err
+		}
+	}
+
 	// Read ComponentInfo and verify that the component is consistent with the
 	// data in the snap info
 	compInfo, _, err := backend.OpenComponentFile(path, info, csi)
@@ -397,6 +397,30 @@ func (s *snapmgrTestSuite) TestInstallUnassertedComponentFailsWithAssertedSnap(c
 	c.Assert(err, ErrorMatches, `cannot mix asserted snap and unasserted components`)
 }
 
+func (s *snapmgrTestSuite) TestInstallComponentPathUnassertedSideloadAuthority(c *C) {
+	const snapName = "mysnap"
+	const compName = "mycomp"
+	snapRev := snap.R(-1)
+	info := createTestSnapInfoForComponent(c, snapName, snapRev, compName)
+	_, compPath := createTestComponent(c, snapName, compName, info)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	defer snapstatetest.MockDeviceModel(MakeModel(map[string]interface{}{
+		"sideload-authority": []interface{}{"delegate"},
+	}))()
+
+	setStateWithOneSnap(s.state, snapName, snapRev)
+
+	csi := snap.NewComponentSideInfo(naming.ComponentRef{
+		SnapName: snapName, ComponentName: compName}, snap.Revision{})
+	ts, err := snapstate.InstallComponentPath(s.state, csi, info, compPath,
+		snapstate.Options{})
+	c.Assert(ts, IsNil)
+	c.Assert(err, ErrorMatches, `cannot install unasserted component "mysnap\+mycomp": the device model only allows sideloading snaps signed by: brand, delegate`)
+}
+
 func (s *snapmgrTestSuite) TestInstallAssertedComponentFailsWithUnassertedSnap(c *C) {
 	const snapName = "mysnap"
 	const compName = "mycomp"
@@ -23,6 +23,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/strutil"
@@ -41,6 +42,9 @@ type ChangeConflictError struct {
 	Message string
 	// ChangeID can optionally be set to the ID of the change with which the operation conflicts
 	ChangeID string
+	// RetryAfter can optionally be set to the expected time until the
+	// conflicting change is done
+	RetryAfter time.Duration
 }
 
 func (e *ChangeConflictError) Error() string {
@@ -156,12 +160,14 @@ func checkChangeConflictExclusiveKinds(st *state.State, newExclusiveChangeKind,
 				Message:    "ubuntu-core to core transition in progress, no other changes allowed until this is done",
 				ChangeKind: "transition-ubuntu-core",
 				ChangeID:   chg.ID(),
+				RetryAfter: conflictRetryAfter(chg),
 			}
 		case "transition-to-snapd-snap":
 			return &ChangeConflictError{
 				Message:    "transition to snapd snap in progress, no other changes allowed until this is done",
 				ChangeKind: "transition-to-snapd-snap",
 				ChangeID:   chg.ID(),
+				RetryAfter: conflictRetryAfter(chg),
 			}
 		case "remodel":
 			if ignoreChangeID != "" && chg.ID() == ignoreChangeID {
@@ -171,6 +177,7 @@ func checkChangeConflictExclusiveKinds(st *state.State, newExclusiveChangeKind,
 				Message:    "remodeling in progress, no other changes allowed until this is done",
 				ChangeKind: "remodel",
 				ChangeID:   chg.ID(),
+				RetryAfter: conflictRetryAfter(chg),
 			}
 		case "create-recovery-system":
 			if ignoreChangeID != "" && chg.ID() == ignoreChangeID {
@@ -180,6 +187,7 @@ func checkChangeConflictExclusiveKinds(st *state.State, newExclusiveChangeKind,
 				Message:    "creating recovery system in progress, no other changes allowed until this is done",
 				ChangeKind: "create-recovery-system",
 				ChangeID:   chg.ID(),
+				RetryAfter: conflictRetryAfter(chg),
 			}
 		case "remove-recovery-system":
 			// TODO: it is not totally necessary for this to be an exclusive
@@ -192,6 +200,7 @@ func checkChangeConflictExclusiveKinds(st *state.State, newExclusiveChangeKind,
 				Message:    "removing recovery system in progress, no other changes allowed until this is done",
 				ChangeKind: "remove-recovery-system",
 				ChangeID:   chg.ID(),
+				RetryAfter: conflictRetryAfter(chg),
 			}
 		case "revert-snap", "refresh-snap":
 			// Snapd downgrades are exclusive changes
@@ -207,6 +216,7 @@ func checkChangeConflictExclusiveKinds(st *state.State, newExclusiveChangeKind,
 				Message:    "snapd downgrade in progress, no other changes allowed until this is done",
 				ChangeKind: chg.Kind(),
 				ChangeID:   chg.ID(),
+				RetryAfter: conflictRetryAfter(chg),
 			}
 		default:
 			if newExclusiveChangeKind != "" {
@@ -218,6 +228,7 @@ func checkChangeConflictExclusiveKinds(st *state.State, newExclusiveChangeKind,
 					Message:    msg,
 					ChangeKind: chg.Kind(),
 					ChangeID:   chg.ID(),
+					RetryAfter: conflictRetryAfter(chg),
 				}
 			}
 		}
@@ -225,6 +236,38 @@ func checkChangeConflictExclusiveKinds(st *state.State, newExclusiveChangeKind,
 	return nil
 }
 
+// Bounds of the time after which operations that conflict with a change are
+// suggested to be retried.
+const (
+	minConflictRetryAfter = 5 * time.Second
+	maxConflictRetryAfter = 5 * time.Minute
+)
+
+// conflictRetryAfter estimates the time until the given change is done from
+// how long it has been running and the share of its tasks that are ready.
+func conflictRetryAfter(chg *state.Change) time.Duration {
+	tasks := chg.Tasks()
+	ready := 0
+	for _, t := range tasks {
+		if t.Status().Ready() {
+			ready++
+		}
+	}
+	if ready == 0 || ready == len(tasks) {
+		return minConflictRetryAfter
+	}
+
+	elapsed := timeNow().Sub(chg.SpawnTime())
+	remaining := elapsed * time.Duration(len(tasks)-ready) / time.Duration(ready)
+	switch {
+	case remaining < minConflictRetryAfter:
+		return minConflictRetryAfter
+	case remaining > maxConflictRetryAfter:
+		return maxConflictRetryAfter
+	}
+	return remaining
+}
+
 // CheckChangeConflictRunExclusively checks for conflicts with a new change which
 // must be run when no other changes are running.
 func CheckChangeConflictRunExclusively(st *state.State, newChangeKind string) error {
@@ -293,6 +336,7 @@ func CheckChangeConflictMany(st *state.State, instanceNames []string, ignoreChan
 					Snap:       snap,
 					ChangeKind: chg.Kind(),
 					ChangeID:   chg.ID(),
+					RetryAfter: conflictRetryAfter(chg),
 				}
 			}
 		}
@@ -328,7 +372,10 @@ func checkChangeConflictIgnoringOneChange(st *state.State, instanceName string,
 
 		// TODO: implement the rather-boring-but-more-performant SnapState.Equals
 		if !reflect.DeepEqual(snapst, &cursnapst) {
-			return &ChangeConflictError{Snap: instanceName}
+			return &ChangeConflictError{
+				Snap:    instanceName,
+				Message: fmt.Sprintf("snap %q was changed by another operation in the meantime", instanceName),
+			}
 		}
 	}
 
@@ -356,12 +403,14 @@ func CheckUpdateKernelCommandLineConflict(st *state.State, ignoreChangeID string
 				Message:    "kernel command line already being updated, no additional changes for it allowed meanwhile",
 				ChangeKind: task.Kind(),
 				ChangeID:   chg.ID(),
+				RetryAfter: conflictRetryAfter(chg),
 			}
 		case "update-managed-boot-config":
 			return &ChangeConflictError{
 				Message:    "boot config is being updated, no change in kernel command line is allowed meanwhile",
 				ChangeKind: task.Kind(),
 				ChangeID:   chg.ID(),
+				RetryAfter: conflictRetryAfter(chg),
 			}
 		}
 	}
@@ -20,6 +20,8 @@
 package snapstate_test
 
 import (
+	"time"
+
 	. "gopkg.in/check.v1"
 
 	"github.com/snapcore/snapd/overlord/snapstate"
@@ -48,6 +50,53 @@ func (s *conflictSuite) TestChangeConflictErrorIs(c *C) {
 	c.Check(this, testutil.ErrorIs, that)
 }
 
+func (s *conflictSuite) TestChangeConflictRetryAfter(c *C) {
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	restore := snapstate.MockAffectedSnapsByKind(map[string]snapstate.AffectedSnapsFunc{
+		"test-task": func(t *state.Task) ([]string, error) {
+			return []string{"some-snap"}, nil
+		},
+	})
+	defer restore()
+
+	chg := st.NewChange("test-change", "...")
+	var tasks []*state.Task
+	for i := 0; i < 4; i++ {
+		t := st.NewTask("test-task", "...")
+		chg.AddTask(t)
+		tasks = append(tasks, t)
+	}
+	restore = snapstate.MockTimeNow(func() time.Time {
+		return chg.SpawnTime().Add(40 * time.Second)
+	})
+	defer restore()
+
+	// nothing is done yet, the minimum is suggested
+	err := snapstate.CheckChangeConflictMany(st, []string{"some-snap"}, "")
+	c.Assert(err, FitsTypeOf, &snapstate.ChangeConflictError{})
+	conflErr := err.(*snapstate.ChangeConflictError)
+	c.Check(conflErr.ChangeID, Equals, chg.ID())
+	c.Check(conflErr.RetryAfter, Equals, 5*time.Second)
+
+	// a quarter of the change took 40s, so the rest should take 120s
+	tasks[0].SetStatus(state.DoneStatus)
+	err = snapstate.CheckChangeConflictMany(st, []string{"some-snap"}, "")
+	c.Assert(err, FitsTypeOf, &snapstate.ChangeConflictError{})
+	c.Check(err.(*snapstate.ChangeConflictError).RetryAfter, Equals, 120*time.Second)
+
+	// the estimate is capped
+	restore = snapstate.MockTimeNow(func() time.Time {
+		return chg.SpawnTime().Add(time.Hour)
+	})
+	defer restore()
+	err = snapstate.CheckChangeConflictMany(st, []string{"some-snap"}, "")
+	c.Assert(err, FitsTypeOf, &snapstate.ChangeConflictError{})
+	c.Check(err.(*snapstate.ChangeConflictError).RetryAfter, Equals, 5*time.Minute)
+}
+
 func (s *conflictSuite) TestSnapsAffectedByTaskKind(c *C) {
 	st := state.New(nil)
 	st.Lock()
@@ -25,6 +25,7 @@ import (
 
 	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/asserts/snapasserts"
+	"github.com/snapcore/snapd/osutil/avahi"
 	"github.com/snapcore/snapd/overlord/snapstate/backend"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/snap"
@@ -105,6 +106,16 @@ func MockPrerequisitesRetryTimeout(d time.Duration) (restore func()) {
 	return func() { prerequisitesRetryTimeout = old }
 }
 
+func MockMaxConcurrentDownloads(n int) (restore func()) {
+	old := maxConcurrentDownloads
+	maxConcurrentDownloads = n
+	return func() { maxConcurrentDownloads = old }
+}
+
+func (m *SnapManager) BlockedTask(cand *state.Task, running []*state.Task) bool {
+	return m.blockedTask(cand, running)
+}
+
 func MockOsutilEnsureSnapUserGroup(mock func(name string, id uint32, extraUsers bool) error) (restore func()) {
 	old := osutilEnsureSnapUserGroup
 	osutilEnsureSnapUserGroup = mock
@@ -265,6 +276,14 @@ func MockIsOnMeteredConnection(mock func() (bool, error)) func() {
 	}
 }
 
+func MockSessionsIdle(mock func(ctx context.Context) (map[int]userclient.SessionIdleInfo, error)) (restore func()) {
+	old := sessionsIdle
+	sessionsIdle = mock
+	return func() {
+		sessionsIdle = old
+	}
+}
+
 func MockLocalInstallCleanupWait(d time.Duration) (restore func()) {
 	old := localInstallCleanupWait
 	localInstallCleanupWait = d
@@ -460,6 +479,8 @@ var (
 	CreateGateAutoRefreshHooks = createGateAutoRefreshHooks
 	AutoRefreshPhase1          = autoRefreshPhase1
 	RefreshRetain              = refreshRetain
+	PrivateTmpSize             = privateTmpSize
+	SnapRefreshRetain          = snapRefreshRetain
 	RefreshCheck               = refreshAppsCheck
 
 	ExcludeFromRefreshAppAwareness = excludeFromRefreshAppAwareness
@@ -609,3 +630,36 @@ type CustomInstallGoal struct {
 func (c *CustomInstallGoal) toInstall(ctx context.Context, st *state.State, opts Options) ([]Target, error) {
 	return c.ToInstall(ctx, st, opts)
 }
+
+func MockSnapdSelfCheck(f func(info *snap.Info) error) (restore func()) {
+	return testutil.Mock(&snapdSelfCheck, f)
+}
+
+func SnapdSelfCheck(info *snap.Info) error {
+	return snapdSelfCheck(info)
+}
+
+func MockSnapdSelfCheckExe(exe string) (restore func()) {
+	return testutil.Mock(&snapdSelfCheckExe, exe)
+}
+
+func (m *SnapManager) DoCheckSnapd(t *state.Task) error {
+	return m.doCheckSnapd(t, nil)
+}
+
+func MockAutomaticPreRefreshSnapshot(f func(st *state.State, instanceName string) (*state.TaskSet, error)) (restore func()) {
+	return testutil.Mock(&AutomaticPreRefreshSnapshot, f)
+}
+
+func MockAvahi(register func(tag string, svc avahi.Service) error, unregister func(tag string) error) (restore func()) {
+	r1 := testutil.Mock(&avahiRegister, register)
+	r2 := testutil.Mock(&avahiUnregister, unregister)
+	return func() {
+		r1()
+		r2()
+	}
+}
+
+func MockQueuedSnapOpRetry(d time.Duration) (restore func()) {
+	return testutil.Mock(&queuedSnapOpRetry, d)
+}
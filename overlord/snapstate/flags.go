@@ -113,6 +113,11 @@ type Flags struct {
 
 	// Lane is the lane that tasks should join if Transaction is set to "all-snaps".
 	Lane int `json:"lane,omitempty"`
+
+	// Retain overrides the number of revisions of the snap to keep
+	// when refreshing it, taking precedence over the refresh.retain
+	// and refresh.snap-retain system options.
+	Retain int `json:"retain,omitempty"`
 }
 
 // DevModeAllowed returns whether a snap can be installed with devmode
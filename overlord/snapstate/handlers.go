@@ -42,6 +42,7 @@ import (
 	"github.com/snapcore/snapd/cmd/snaplock/runinhibit"
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/features"
+	"github.com/snapcore/snapd/gadget/quantity"
 	"github.com/snapcore/snapd/i18n"
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/logger"
@@ -356,9 +357,14 @@ func (m *SnapManager) installOneBaseOrRequired(t *state.Task, snapName string, c
 		}
 
 		// other kind of dependency, check if it's in progress
-		if ok, err := inProgress(snapName); err != nil {
+		if linkTask, err := findLinkSnapTaskForSnap(st, snapName); err != nil {
 			return nil, err
-		} else if ok {
+		} else if linkTask != nil {
+			// a refresh of the dependency in the same change is
+			// already ordered before the tasks that need it
+			if linkTask.Change().ID() == t.Change().ID() {
+				return nil, nil
+			}
 			return nil, onInFlight
 		}
 
@@ -526,9 +532,14 @@ func (m *SnapManager) installPrereqs(t *state.Task, base string, prereq map[stri
 	// because of change conflicts or similar we retry. Only if all snaps
 	// can be installed together we add the tasks to the change.
 	var tss []*state.TaskSet
+	// snaps that are not installed yet and would be installed
+	var newPrereqs []string
 	for prereqName, contentAttrs := range prereq {
+		installed, err := isInstalled(st, prereqName)
+		if err != nil {
+			return err
+		}
 		var onInFlightErr error = nil
-		var err error
 		var ts *state.TaskSet
 		timings.Run(tm, "install-prereq", fmt.Sprintf("install %q", prereqName), func(timings.Measurer) {
 			noTypeBaseCheck := false
@@ -541,6 +552,9 @@ func (m *SnapManager) installPrereqs(t *state.Task, base string, prereq map[stri
 			continue
 		}
 		tss = append(tss, ts)
+		if !installed {
+			newPrereqs = append(newPrereqs, prereqName)
+		}
 	}
 
 	// for base snaps we need to wait until the change is done
@@ -560,6 +574,24 @@ func (m *SnapManager) installPrereqs(t *state.Task, base string, prereq map[stri
 		if err != nil {
 			return prereqError("snap base", base, err)
 		}
+		if tsBase != nil {
+			installed, err := isInstalled(st, base)
+			if err != nil {
+				return err
+			}
+			if !installed {
+				newPrereqs = append(newPrereqs, base)
+			}
+		}
+	}
+
+	if err := checkPrerequisitesConfirmed(t.Change(), newPrereqs); err != nil {
+		for _, ts := range append(tss, tsBase) {
+			if ts != nil {
+				st.DiscardUnlinkedTasks(ts.Tasks())
+			}
+		}
+		return err
 	}
 
 	// On classic systems that are already seeded, automatically
@@ -619,6 +651,25 @@ func (m *SnapManager) installPrereqs(t *state.Task, base string, prereq map[stri
 	return nil
 }
 
+// checkPrerequisitesConfirmed returns an error if the change was requested
+// without confirming the installation of prerequisites, as required by the
+// prerequisites.confirm option, and the given snaps would be installed as
+// prerequisites of it.
+func checkPrerequisitesConfirmed(chg *state.Change, newPrereqs []string) error {
+	if len(newPrereqs) == 0 {
+		return nil
+	}
+	var confirm bool
+	if err := chg.Get("confirm-prerequisites", &confirm); err != nil && !errors.Is(err, state.ErrNoState) {
+		return err
+	}
+	if !confirm {
+		return nil
+	}
+	sort.Strings(newPrereqs)
+	return fmt.Errorf("cannot install prerequisites %s: installation of prerequisites requires confirmation", strutil.Quoted(newPrereqs))
+}
+
 func prereqError(what, snapName string, err error) error {
 	if _, ok := err.(*state.Retry); ok {
 		return err
@@ -683,6 +734,18 @@ func autoRefreshRateLimited(st *state.State) (rate int64) {
 	return val
 }
 
+// downloadParallel returns the number of concurrent requests a snap
+// download can be split into, or 0 if it should use a single one.
+func downloadParallel(st *state.State) int {
+	tr := config.NewTransaction(st)
+
+	var parallel int
+	if err := tr.Get("core", "store.download-parallel", &parallel); err != nil {
+		return 0
+	}
+	return parallel
+}
+
 func downloadSnapParams(st *state.State, t *state.Task) (*SnapSetup, StoreService, *auth.UserState, error) {
 	snapsup, err := TaskSnapSetup(t)
 	if err != nil {
@@ -715,6 +778,7 @@ func (m *SnapManager) doDownloadSnap(t *state.Task, tomb *tomb.Tomb) error {
 		// NOTE rate is never negative
 		rate = autoRefreshRateLimited(st)
 	}
+	parallel := downloadParallel(st)
 	st.Unlock()
 	if err != nil {
 		return err
@@ -730,6 +794,11 @@ func (m *SnapManager) doDownloadSnap(t *state.Task, tomb *tomb.Tomb) error {
 	dlOpts := &store.DownloadOptions{
 		Scheduled: snapsup.IsAutoRefresh,
 		RateLimit: rate,
+		Parallel:  parallel,
+	}
+	if snapsup.InstanceKey != "" {
+		// deltas apply to the installed revision of the instance
+		dlOpts.InstanceName = snapsup.InstanceName()
 	}
 	if snapsup.DownloadInfo == nil {
 		vsets, err := EnforcedValidationSets(st)
@@ -826,6 +895,10 @@ func (m *SnapManager) doPreDownloadSnap(t *state.Task, tomb *tomb.Tomb) error {
 		// pre-downloads are only triggered in auto-refreshes
 		Scheduled: true,
 		RateLimit: autoRefreshRateLimited(st),
+		Parallel:  downloadParallel(st),
+	}
+	if snapsup.InstanceKey != "" {
+		dlOpts.InstanceName = snapsup.InstanceName()
 	}
 
 	perfTimings := state.TimingsForTask(t)
@@ -2097,6 +2170,68 @@ func isSingleRebootBoundary(linkSnap *state.Task) bool {
 	return false
 }
 
+// hasPrivateTmp returns whether the snap gets a size limited private
+// /tmp, which is the case for all snaps that run applications.
+func hasPrivateTmp(info *snap.Info) bool {
+	switch info.Type() {
+	case snap.TypeApp, snap.TypeGadget, snap.TypeKernel:
+		return true
+	}
+	return false
+}
+
+// firewallControlEnabled returns whether the ports declared by the
+// services of snaps are opened in the firewall of the system, as
+// controlled by the firewall-control-lite system option.
+func firewallControlEnabled(st *state.State) bool {
+	var enabled bool
+	err := config.NewTransaction(st).Get("core", "firewall-control-lite", &enabled)
+	if err != nil && !config.IsNoOption(err) {
+		logger.Noticef("cannot get firewall-control-lite system option: %v", err)
+	}
+	return enabled
+}
+
+// setupFirewallPorts opens the ports of the snap in the firewall. This
+// goes through firewalld and can take a while, so the state is unlocked
+// meanwhile.
+func (m *SnapManager) setupFirewallPorts(st *state.State, info *snap.Info) error {
+	st.Unlock()
+	defer st.Lock()
+	return m.backend.SetupFirewallPorts(info)
+}
+
+// removeFirewallPorts closes the ports of the snap in the firewall, with
+// the state unlocked as for setupFirewallPorts.
+func (m *SnapManager) removeFirewallPorts(st *state.State, instanceName string) error {
+	st.Unlock()
+	defer st.Lock()
+	return m.backend.RemoveFirewallPorts(instanceName)
+}
+
+// privateTmpSize returns the size of the private /tmp of a snap, from the
+// tmp.snap-size system option or otherwise the memory limit of the quota
+// group of the snap, as a tmpfs is backed by memory. A size of 0 means
+// the tmpfs default.
+func privateTmpSize(st *state.State, opts *wrappers.SnapServiceOptions) quantity.Size {
+	var sizeStr string
+	err := config.NewTransaction(st).Get("core", "tmp.snap-size", &sizeStr)
+	if err != nil && !config.IsNoOption(err) {
+		logger.Noticef("cannot get tmp.snap-size system option: %v", err)
+	}
+	if sizeStr != "" {
+		size, err := quantity.ParseSize(sizeStr)
+		if err == nil {
+			return size
+		}
+		logger.Noticef("cannot use tmp.snap-size system option: %v", err)
+	}
+	if opts != nil && opts.QuotaGroup != nil {
+		return opts.QuotaGroup.MemoryLimit
+	}
+	return 0
+}
+
 func (m *SnapManager) doLinkSnap(t *state.Task, _ *tomb.Tomb) (err error) {
 	st := t.State()
 	st.Lock()
@@ -2292,6 +2427,21 @@ func (m *SnapManager) doLinkSnap(t *state.Task, _ *tomb.Tomb) (err error) {
 		return err
 	}
 
+	// the private /tmp is not essential for the snap to work, snap-confine
+	// falls back to a plain directory without it
+	if hasPrivateTmp(newInfo) {
+		size := privateTmpSize(st, opts)
+		if err := m.backend.SetupPrivateTmp(newInfo.InstanceName(), size, pb); err != nil {
+			logger.Noticef("cannot setup private /tmp of snap %q: %v", newInfo.InstanceName(), err)
+		}
+	}
+
+	if firewallControlEnabled(st) {
+		if err := m.setupFirewallPorts(st, newInfo); err != nil {
+			logger.Noticef("cannot open the ports of snap %q in the firewall: %v", newInfo.InstanceName(), err)
+		}
+	}
+
 	// Set next boot for snaps that need it. Note that if we have
 	// kernel-modules components this gets delayed as it happens in the
 	// "prepare-kernel-modules-components" task. The default is set to
@@ -2806,6 +2956,12 @@ func (m *SnapManager) undoLinkSnap(t *state.Task, _ *tomb.Tomb) error {
 		if err := discardAuxStoreInfo(snapsup.SideInfo.SnapID); err != nil {
 			return fmt.Errorf("cannot remove auxiliary store info: %v", err)
 		}
+		if err := m.backend.RemovePrivateTmp(snapsup.InstanceName(), progress.Null); err != nil {
+			logger.Noticef("cannot remove private /tmp of snap %q: %v", snapsup.InstanceName(), err)
+		}
+		if err := m.removeFirewallPorts(st, snapsup.InstanceName()); err != nil {
+			logger.Noticef("cannot close the ports of snap %q in the firewall: %v", snapsup.InstanceName(), err)
+		}
 	}
 
 	isRevert := snapsup.Revert
@@ -3202,14 +3358,17 @@ func (m *SnapManager) startSnapServices(t *state.Task, _ *tomb.Tomb) error {
 	}
 
 	pb := NewTaskProgressAdapterUnlocked(t)
+	toAdvertise := ServicesToAdvertise(st, startupOrdered, missingSvcsOverview.FoundSystemServices)
 
 	st.Unlock()
 	err = m.backend.StartServices(startupOrdered, &wrappers.DisabledServices{
 		SystemServices: missingSvcsOverview.FoundSystemServices,
 		UserServices:   missingSvcsOverview.FoundUserServices,
 	}, pb, perfTimings)
+	if err == nil {
+		AdvertiseServices(toAdvertise)
+	}
 	st.Lock()
-
 	return err
 }
 
@@ -3254,6 +3413,7 @@ func (m *SnapManager) undoStartSnapServices(t *state.Task, _ *tomb.Tomb) error {
 
 	// stop the services
 	st.Unlock()
+	WithdrawServices(svcs)
 	err = m.backend.StopServices(svcs, stopReason, progress.Null, perfTimings)
 	st.Lock()
 	if err != nil {
@@ -3294,6 +3454,8 @@ func (m *SnapManager) stopSnapServices(t *state.Task, _ *tomb.Tomb) error {
 	st.Unlock()
 	defer st.Lock()
 
+	WithdrawServices(svcs)
+
 	// stop the services
 	err = m.backend.StopServices(svcs, stopReason, pb, perfTimings)
 	if err != nil {
@@ -3392,14 +3554,15 @@ func (m *SnapManager) undoStopSnapServices(t *state.Task, _ *tomb.Tomb) error {
 		return err
 	}
 
+	toAdvertise := ServicesToAdvertise(st, startupOrdered, disabledServices.SystemServices)
+
 	st.Unlock()
 	err = m.backend.StartServices(startupOrdered, &disabledServices, progress.Null, perfTimings)
-	st.Lock()
-	if err != nil {
-		return err
+	if err == nil {
+		AdvertiseServices(toAdvertise)
 	}
-
-	return nil
+	st.Lock()
+	return err
 }
 
 func (m *SnapManager) doKillSnapApps(t *state.Task, _ *tomb.Tomb) (err error) {
@@ -3479,6 +3642,8 @@ func (m *SnapManager) doKillSnapApps(t *state.Task, _ *tomb.Tomb) (err error) {
 
 	pb := NewTaskProgressAdapterUnlocked(t)
 
+	WithdrawServices(svcs)
+
 	// Make sure snap services are stopped because they may have started through snapctl
 	err = m.backend.StopServices(svcs, snap.ServiceStopReason(reason), pb, perfTimings)
 	if err != nil {
@@ -3773,6 +3938,14 @@ func (m *SnapManager) doDiscardSnap(t *state.Task, _ *tomb.Tomb) error {
 		if err != nil {
 			return err
 		}
+		if err := m.backend.RemovePrivateTmp(snapsup.InstanceName(), progress.Null); err != nil {
+			return fmt.Errorf("cannot remove private /tmp: %v", err)
+		}
+		// close the ports regardless of firewall-control-lite, which
+		// may have been disabled after they were opened
+		if err := m.removeFirewallPorts(st, snapsup.InstanceName()); err != nil {
+			logger.Noticef("cannot close the ports of snap %q in the firewall: %v", snapsup.InstanceName(), err)
+		}
 		if err := m.removeSnapCookie(st, snapsup.InstanceName()); err != nil {
 			return fmt.Errorf("cannot remove snap cookie: %v", err)
 		}
@@ -4808,6 +4981,9 @@ func (m *SnapManager) doConditionalAutoRefresh(t *state.Task, tomb *tomb.Tomb) e
 
 	if len(snaps) == 0 {
 		logger.Debugf("refresh gating: no snaps to refresh")
+		// all snaps were held, none of them is affected by this task
+		// anymore - see conditionalAutoRefreshAffectedSnaps().
+		t.Set("snaps", map[string]*refreshCandidate{})
 		return nil
 	}
 
@@ -477,6 +477,43 @@ func (s *downloadSnapSuite) TestDoUndoDownloadSnap(c *C) {
 
 }
 
+func (s *downloadSnapSuite) TestDoDownloadParallel(c *C) {
+	s.state.Lock()
+
+	tr := config.NewTransaction(s.state)
+	tr.Set("core", "store.download-parallel", 4)
+	tr.Commit()
+
+	si := &snap.SideInfo{
+		RealName: "foo",
+		SnapID:   "foo-id",
+		Revision: snap.R(11),
+	}
+	t := s.state.NewTask("download-snap", "test")
+	t.Set("snap-setup", &snapstate.SnapSetup{
+		SideInfo: si,
+		DownloadInfo: &snap.DownloadInfo{
+			DownloadURL: "http://some-url.com/snap",
+		},
+	})
+	s.state.NewChange("sample", "...").AddTask(t)
+
+	s.state.Unlock()
+
+	s.se.Ensure()
+	s.se.Wait()
+
+	c.Assert(s.fakeStore.downloads, DeepEquals, []fakeDownload{
+		{
+			name:   "foo",
+			target: filepath.Join(dirs.SnapBlobDir, "foo_11.snap"),
+			opts: &store.DownloadOptions{
+				Parallel: 4,
+			},
+		},
+	})
+}
+
 func (s *downloadSnapSuite) TestDoDownloadRateLimitedIntegration(c *C) {
 	s.state.Lock()
 
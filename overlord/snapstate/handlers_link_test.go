@@ -22,6 +22,7 @@ package snapstate_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -37,6 +38,8 @@ import (
 	"github.com/snapcore/snapd/cmd/snaplock"
 	"github.com/snapcore/snapd/cmd/snaplock/runinhibit"
 	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/gadget/quantity"
+	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/overlord/auth"
 	"github.com/snapcore/snapd/overlord/configstate/config"
@@ -51,10 +54,12 @@ import (
 	"github.com/snapcore/snapd/sandbox/apparmor"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/naming"
+	"github.com/snapcore/snapd/snap/quota"
 	"github.com/snapcore/snapd/snap/snaptest"
 	"github.com/snapcore/snapd/snapdtool"
 	"github.com/snapcore/snapd/testutil"
 	userclient "github.com/snapcore/snapd/usersession/client"
+	"github.com/snapcore/snapd/wrappers"
 )
 
 type linkSnapSuite struct {
@@ -155,6 +160,112 @@ func (s *linkSnapSuite) TestDoLinkSnapSuccess(c *C) {
 	c.Check(lp.instanceNames, DeepEquals, []string{"foo"})
 }
 
+func (s *linkSnapSuite) TestDoLinkSnapSetsUpPrivateTmp(c *C) {
+	s.state.Lock()
+	tr := config.NewTransaction(s.state)
+	tr.Set("core", "tmp.snap-size", "64M")
+	tr.Commit()
+
+	t := s.state.NewTask("link-snap", "test")
+	t.Set("snap-setup", &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{
+			RealName: "foo",
+			Revision: snap.R(33),
+		},
+	})
+	chg := s.state.NewChange("sample", "...")
+	chg.AddTask(t)
+	s.state.Unlock()
+
+	s.se.Ensure()
+	s.se.Wait()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Assert(chg.Err(), IsNil)
+	c.Check(s.fakeBackend.privateTmps, DeepEquals, map[string]quantity.Size{
+		"foo": 64 * quantity.SizeMiB,
+	})
+}
+
+func (s *linkSnapSuite) TestDoLinkSnapPrivateTmpErrorIsNotFatal(c *C) {
+	logbuf, restore := logger.MockLogger()
+	defer restore()
+	s.fakeBackend.setupPrivateTmpErr = errors.New("boom")
+
+	s.state.Lock()
+	t := s.state.NewTask("link-snap", "test")
+	t.Set("snap-setup", &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{
+			RealName: "foo",
+			Revision: snap.R(33),
+		},
+	})
+	chg := s.state.NewChange("sample", "...")
+	chg.AddTask(t)
+	s.state.Unlock()
+
+	s.se.Ensure()
+	s.se.Wait()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Assert(chg.Err(), IsNil)
+	c.Check(t.Status(), Equals, state.DoneStatus)
+	c.Check(logbuf.String(), testutil.Contains, `cannot setup private /tmp of snap "foo": boom`)
+}
+
+func (s *linkSnapSuite) TestDoLinkSnapSetsUpFirewallPorts(c *C) {
+	for _, enabled := range []bool{false, true} {
+		s.fakeBackend.firewallPorts = nil
+
+		s.state.Lock()
+		tr := config.NewTransaction(s.state)
+		tr.Set("core", "firewall-control-lite", enabled)
+		tr.Commit()
+
+		t := s.state.NewTask("link-snap", "test")
+		t.Set("snap-setup", &snapstate.SnapSetup{
+			SideInfo: &snap.SideInfo{
+				RealName: "foo",
+				Revision: snap.R(33),
+			},
+		})
+		chg := s.state.NewChange("sample", "...")
+		chg.AddTask(t)
+		s.state.Unlock()
+
+		s.se.Ensure()
+		s.se.Wait()
+
+		s.state.Lock()
+		c.Assert(chg.Err(), IsNil)
+		_, ok := s.fakeBackend.firewallPorts["foo"]
+		c.Check(ok, Equals, enabled)
+		s.state.Unlock()
+	}
+}
+
+func (s *linkSnapSuite) TestPrivateTmpSize(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	grp, err := quota.NewGroup("grp", quota.NewResourcesBuilder().WithMemoryLimit(32*quantity.SizeMiB).Build())
+	c.Assert(err, IsNil)
+	opts := &wrappers.SnapServiceOptions{QuotaGroup: grp}
+
+	// tmpfs default
+	c.Check(snapstate.PrivateTmpSize(s.state, nil), Equals, quantity.Size(0))
+	// from the quota group
+	c.Check(snapstate.PrivateTmpSize(s.state, opts), Equals, 32*quantity.SizeMiB)
+
+	// the system option wins
+	tr := config.NewTransaction(s.state)
+	tr.Set("core", "tmp.snap-size", "1G")
+	tr.Commit()
+	c.Check(snapstate.PrivateTmpSize(s.state, opts), Equals, quantity.SizeGiB)
+}
+
 func (s *linkSnapSuite) TestDoLinkSnapSuccessWithCohort(c *C) {
 	// we start without the auxiliary store info
 	c.Check(snapstate.AuxStoreInfoFilename("foo-id"), testutil.FileAbsent)
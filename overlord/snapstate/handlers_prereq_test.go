@@ -363,6 +363,80 @@ func (s *prereqSuite) TestDoPrereqTalksToStoreAndQueues(c *C) {
 	c.Check(linkedSnaps, testutil.DeepUnsortedMatches, expectedLinkedSnaps)
 }
 
+func (s *prereqSuite) TestDoPrereqRequiresConfirmation(c *C) {
+	s.state.Lock()
+
+	snapstate.Set(s.state, "core", &snapstate.SnapState{
+		Active: true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "core", Revision: snap.R(1)},
+		}),
+		Current:  snap.R(1),
+		SnapType: "os",
+	})
+
+	t := s.state.NewTask("prerequisites", "test")
+	t.Set("snap-setup", &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{
+			RealName: "foo",
+			Revision: snap.R(33),
+		},
+		Base:               "some-base",
+		PrereqContentAttrs: map[string][]string{"prereq1": {"some-content"}},
+	})
+	chg := s.state.NewChange("sample", "...")
+	chg.AddTask(t)
+	chg.Set("confirm-prerequisites", true)
+	tasksBefore := len(s.state.Tasks())
+	s.state.Unlock()
+
+	s.se.Ensure()
+	s.se.Wait()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Check(t.Status(), Equals, state.ErrorStatus)
+	c.Check(chg.Err(), ErrorMatches, `(?s).*cannot install prerequisites "prereq1", "some-base": installation of prerequisites requires confirmation.*`)
+	// the tasks of the prerequisites were not kept around
+	c.Check(chg.Tasks(), HasLen, 1)
+	c.Check(s.state.Tasks(), HasLen, tasksBefore)
+}
+
+func (s *prereqSuite) TestDoPrereqConfirmationNotNeededWhenInstalled(c *C) {
+	s.state.Lock()
+
+	for _, name := range []string{"snapd", "core", "some-base", "prereq1"} {
+		snapstate.Set(s.state, name, &snapstate.SnapState{
+			Active: true,
+			Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+				{RealName: name, Revision: snap.R(1)},
+			}),
+			Current: snap.R(1),
+		})
+	}
+
+	t := s.state.NewTask("prerequisites", "test")
+	t.Set("snap-setup", &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{
+			RealName: "foo",
+			Revision: snap.R(33),
+		},
+		Base:               "some-base",
+		PrereqContentAttrs: map[string][]string{"prereq1": {"some-content"}},
+	})
+	chg := s.state.NewChange("sample", "...")
+	chg.AddTask(t)
+	chg.Set("confirm-prerequisites", true)
+	s.state.Unlock()
+
+	s.se.Ensure()
+	s.se.Wait()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Check(t.Status(), Equals, state.DoneStatus)
+}
+
 func (s *prereqSuite) TestDoPrereqRetryWhenBaseInFlight(c *C) {
 	restore := snapstate.MockPrerequisitesRetryTimeout(1 * time.Millisecond)
 	defer restore()
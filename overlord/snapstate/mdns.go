@@ -0,0 +1,104 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapstate
+
+import (
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/osutil/avahi"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/strutil"
+)
+
+var (
+	avahiRegister   = avahi.Register
+	avahiUnregister = avahi.Unregister
+)
+
+// mdnsAdvertiseAllowed returns whether the services of the snap can be
+// advertised on the local network, as controlled by the mdns.advertise
+// system option.
+func mdnsAdvertiseAllowed(st *state.State, instanceName string) bool {
+	var snaps string
+	err := config.NewTransaction(st).Get("core", "mdns.advertise", &snaps)
+	if err != nil && !config.IsNoOption(err) {
+		logger.Noticef("cannot get mdns.advertise system option: %v", err)
+	}
+	return strutil.ListContains(strutil.CommaSeparatedList(snaps), instanceName)
+}
+
+// ServicesToAdvertise returns the given services which declare mDNS details,
+// skipping those listed as disabled, if their snap is allowed to advertise
+// them by the mdns.advertise system option. The state must be locked.
+func ServicesToAdvertise(st *state.State, svcs []*snap.AppInfo, disabled []string) []*snap.AppInfo {
+	var toAdvertise []*snap.AppInfo
+	allowed := make(map[string]bool)
+	for _, app := range svcs {
+		if app.MDNS == nil || strutil.ListContains(disabled, app.Name) {
+			continue
+		}
+		instanceName := app.Snap.InstanceName()
+		isAllowed, ok := allowed[instanceName]
+		if !ok {
+			isAllowed = mdnsAdvertiseAllowed(st, instanceName)
+			allowed[instanceName] = isAllowed
+		}
+		if isAllowed {
+			toAdvertise = append(toAdvertise, app)
+		}
+	}
+	return toAdvertise
+}
+
+// AdvertiseServices advertises on the local network the given services, as
+// returned by ServicesToAdvertise. Advertising is best-effort so errors are
+// only logged. As it talks to Avahi over D-Bus, it should be called without
+// the state lock held.
+func AdvertiseServices(svcs []*snap.AppInfo) {
+	for _, app := range svcs {
+		name := app.MDNS.Name
+		if name == "" {
+			name = app.Snap.InstanceName()
+		}
+		svc := avahi.Service{
+			Name: name,
+			Type: app.MDNS.Type,
+			Port: uint16(app.MDNS.Port),
+			TXT:  app.MDNS.TXT,
+		}
+		if err := avahiRegister(app.SecurityTag(), svc); err != nil {
+			logger.Noticef("cannot advertise service %q: %v", app.ServiceName(), err)
+		}
+	}
+}
+
+// WithdrawServices withdraws from the local network the given services, if
+// they were advertised. Errors are only logged. Like AdvertiseServices, it
+// should be called without the state lock held.
+func WithdrawServices(svcs []*snap.AppInfo) {
+	for _, app := range svcs {
+		// the services are withdrawn regardless of mdns.advertise, which
+		// may have changed after they were advertised
+		if err := avahiUnregister(app.SecurityTag()); err != nil {
+			logger.Noticef("cannot withdraw advertised service %q: %v", app.ServiceName(), err)
+		}
+	}
+}
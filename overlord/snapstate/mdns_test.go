@@ -0,0 +1,166 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapstate_test
+
+import (
+	"errors"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/osutil/avahi"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type mdnsSuite struct {
+	testutil.BaseTest
+	state *state.State
+
+	registered   map[string]avahi.Service
+	unregistered []string
+}
+
+var _ = Suite(&mdnsSuite{})
+
+func (s *mdnsSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+	s.state = state.New(nil)
+
+	s.registered = make(map[string]avahi.Service)
+	s.unregistered = nil
+	s.AddCleanup(snapstate.MockAvahi(func(tag string, svc avahi.Service) error {
+		s.registered[tag] = svc
+		return nil
+	}, func(tag string) error {
+		s.unregistered = append(s.unregistered, tag)
+		return nil
+	}))
+}
+
+const mdnsSnapYaml = `name: appliance
+version: 1
+apps:
+  web:
+    daemon: simple
+    plugs: [network-bind]
+    mdns:
+      type: _http._tcp
+      port: 8080
+      txt: [path=/]
+  printer:
+    daemon: simple
+    plugs: [network-bind]
+    mdns:
+      name: Office Printer
+      type: _ipp._tcp
+      port: 631
+  worker:
+    daemon: simple
+`
+
+func (s *mdnsSuite) setAdvertise(c *C, value string) {
+	tr := config.NewTransaction(s.state)
+	c.Assert(tr.Set("core", "mdns.advertise", value), IsNil)
+	tr.Commit()
+}
+
+func (s *mdnsSuite) TestAdvertiseServicesNotAllowed(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	info := snaptest.MockInfo(c, mdnsSnapYaml, &snap.SideInfo{Revision: snap.R(1)})
+
+	// services are not advertised unless the snap is allowed to
+	snapstate.AdvertiseServices(snapstate.ServicesToAdvertise(s.state, info.Services(), nil))
+	c.Check(s.registered, HasLen, 0)
+
+	s.setAdvertise(c, "other-snap")
+	snapstate.AdvertiseServices(snapstate.ServicesToAdvertise(s.state, info.Services(), nil))
+	c.Check(s.registered, HasLen, 0)
+}
+
+func (s *mdnsSuite) TestAdvertiseServices(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.setAdvertise(c, "other-snap,appliance")
+	info := snaptest.MockInfo(c, mdnsSnapYaml, &snap.SideInfo{Revision: snap.R(1)})
+
+	snapstate.AdvertiseServices(snapstate.ServicesToAdvertise(s.state, info.Services(), nil))
+	c.Check(s.registered, DeepEquals, map[string]avahi.Service{
+		"snap.appliance.web": {
+			Name: "appliance",
+			Type: "_http._tcp",
+			Port: 8080,
+			TXT:  []string{"path=/"},
+		},
+		"snap.appliance.printer": {
+			Name: "Office Printer",
+			Type: "_ipp._tcp",
+			Port: 631,
+		},
+	})
+}
+
+func (s *mdnsSuite) TestAdvertiseServicesSkipsDisabled(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.setAdvertise(c, "appliance")
+	info := snaptest.MockInfo(c, mdnsSnapYaml, &snap.SideInfo{Revision: snap.R(1)})
+
+	snapstate.AdvertiseServices(snapstate.ServicesToAdvertise(s.state, info.Services(), []string{"printer"}))
+	c.Check(s.registered, HasLen, 1)
+	c.Check(s.registered["snap.appliance.web"].Type, Equals, "_http._tcp")
+}
+
+func (s *mdnsSuite) TestAdvertiseServicesErrorIsIgnored(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.setAdvertise(c, "appliance")
+	info := snaptest.MockInfo(c, mdnsSnapYaml, &snap.SideInfo{Revision: snap.R(1)})
+
+	var tags []string
+	restore := snapstate.MockAvahi(func(tag string, svc avahi.Service) error {
+		tags = append(tags, tag)
+		return errors.New("boom")
+	}, nil)
+	defer restore()
+
+	// all services are attempted
+	snapstate.AdvertiseServices(snapstate.ServicesToAdvertise(s.state, info.Services(), nil))
+	c.Check(tags, HasLen, 2)
+}
+
+func (s *mdnsSuite) TestWithdrawServices(c *C) {
+	info := snaptest.MockInfo(c, mdnsSnapYaml, &snap.SideInfo{Revision: snap.R(1)})
+
+	snapstate.WithdrawServices(info.Services())
+	c.Check(s.unregistered, testutil.DeepUnsortedMatches, []string{
+		"snap.appliance.web",
+		"snap.appliance.printer",
+		"snap.appliance.worker",
+	})
+}
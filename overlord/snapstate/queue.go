@@ -0,0 +1,145 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapstate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gopkg.in/tomb.v2"
+
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+// queuedSnapOpRetry is how often a queued snap operation checks whether
+// it can proceed.
+var queuedSnapOpRetry = 10 * time.Second
+
+// QueuedSnapOpTasks is called once a queued snap operation is first in line
+// for its snap and no other change affects the snap anymore. It must return
+// the task sets of the operation described by the given queued-snap-op task,
+// or a ChangeConflictError if the operation still conflicts with another
+// change. It is set by the daemon, which defines the operations.
+var QueuedSnapOpTasks func(ctx context.Context, t *state.Task) ([]*state.TaskSet, error)
+
+// NewQueuedSnapOpTask returns a task that holds a snap operation on the given
+// snap until the changes it conflicts with and the operations queued before
+// it for the same snap are done. The caller records the details of the
+// operation on the task for QueuedSnapOpTasks. Until it proceeds the queued
+// operation conflicts with other changes of the snap.
+func NewQueuedSnapOpTask(st *state.State, instanceName string) *state.Task {
+	t := st.NewTask("queued-snap-op", fmt.Sprintf(i18n.G("Wait for other changes of snap %q to finish"), instanceName))
+	t.Set("snap-name", instanceName)
+	return t
+}
+
+type queuedSnapOpProceedingKey struct {
+	instanceName string
+}
+
+func queuedSnapOpAffectedSnaps(t *state.Task) ([]string, error) {
+	var instanceName string
+	if err := t.Get("snap-name", &instanceName); err != nil {
+		return nil, err
+	}
+	// while the operation at the head of the queue creates its tasks the
+	// queued operations for the snap must not conflict with it
+	if proceeding, _ := t.State().Cached(queuedSnapOpProceedingKey{instanceName}).(bool); proceeding {
+		return nil, nil
+	}
+	return []string{instanceName}, nil
+}
+
+// queuedBefore returns whether task a was queued before task b. Tasks are
+// numbered in creation order.
+func queuedBefore(a, b *state.Task) bool {
+	idA, errA := strconv.Atoi(a.ID())
+	idB, errB := strconv.Atoi(b.ID())
+	if errA != nil || errB != nil {
+		return a.ID() < b.ID()
+	}
+	return idA < idB
+}
+
+// queuedSnapOpIsFirst returns whether no snap operation queued before the
+// given one for the same snap is still waiting.
+func queuedSnapOpIsFirst(t *state.Task, instanceName string) (bool, error) {
+	for _, other := range t.State().Tasks() {
+		if other == t || other.Kind() != "queued-snap-op" || other.Status().Ready() {
+			continue
+		}
+		var otherName string
+		if err := other.Get("snap-name", &otherName); err != nil {
+			return false, err
+		}
+		if otherName == instanceName && queuedBefore(other, t) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (m *SnapManager) doQueuedSnapOp(t *state.Task, tomb *tomb.Tomb) error {
+	st := t.State()
+	st.Lock()
+	defer st.Unlock()
+
+	var instanceName string
+	if err := t.Get("snap-name", &instanceName); err != nil {
+		return err
+	}
+
+	first, err := queuedSnapOpIsFirst(t, instanceName)
+	if err != nil {
+		return err
+	}
+	if !first {
+		return &state.Retry{After: queuedSnapOpRetry}
+	}
+	if QueuedSnapOpTasks == nil {
+		return fmt.Errorf("internal error: cannot run queued snap operations")
+	}
+
+	st.Cache(queuedSnapOpProceedingKey{instanceName}, true)
+	tss, err := QueuedSnapOpTasks(tomb.Context(context.Background()), t)
+	st.Cache(queuedSnapOpProceedingKey{instanceName}, nil)
+	if err != nil {
+		var conflErr *ChangeConflictError
+		if errors.As(err, &conflErr) {
+			t.Logf("Waiting for conflicting change in progress: %s", conflErr)
+			return &state.Retry{After: queuedSnapOpRetry}
+		}
+		return err
+	}
+
+	chg := t.Change()
+	for _, ts := range tss {
+		ts.WaitFor(t)
+		chg.AddAll(ts)
+	}
+	t.SetStatus(state.DoneStatus)
+	st.EnsureBefore(0)
+
+	return nil
+}
@@ -0,0 +1,153 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapstate_test
+
+import (
+	"context"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type queuedSnapOpSuite struct {
+	baseHandlerSuite
+}
+
+var _ = Suite(&queuedSnapOpSuite{})
+
+func (s *queuedSnapOpSuite) SetUpTest(c *C) {
+	s.baseHandlerSuite.SetUpTest(c)
+	s.AddCleanup(snapstate.MockQueuedSnapOpRetry(time.Millisecond))
+}
+
+func (s *queuedSnapOpSuite) mockQueuedSnapOpTasks(f func(ctx context.Context, t *state.Task) ([]*state.TaskSet, error)) {
+	s.AddCleanup(testutil.Mock(&snapstate.QueuedSnapOpTasks, f))
+}
+
+func (s *queuedSnapOpSuite) queue(c *C, name string) *state.Change {
+	chg := s.state.NewChange("remove-snap", "...")
+	chg.AddTask(snapstate.NewQueuedSnapOpTask(s.state, name))
+	return chg
+}
+
+func (s *queuedSnapOpSuite) run() {
+	for i := 0; i < 3; i++ {
+		time.Sleep(2 * time.Millisecond)
+		s.se.Ensure()
+		s.se.Wait()
+	}
+}
+
+func (s *queuedSnapOpSuite) TestQueuedSnapOpConflicts(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	chg := s.queue(c, "foo")
+
+	err := snapstate.CheckChangeConflict(s.state, "foo", nil)
+	c.Assert(err, FitsTypeOf, &snapstate.ChangeConflictError{})
+	c.Check(err.(*snapstate.ChangeConflictError).ChangeID, Equals, chg.ID())
+
+	c.Check(snapstate.CheckChangeConflict(s.state, "bar", nil), IsNil)
+}
+
+func (s *queuedSnapOpSuite) TestQueuedSnapOpWaitsForConflict(c *C) {
+	conflicting := true
+	s.mockQueuedSnapOpTasks(func(ctx context.Context, t *state.Task) ([]*state.TaskSet, error) {
+		// the queued operation itself does not get in the way
+		c.Assert(snapstate.CheckChangeConflict(t.State(), "foo", nil), IsNil)
+		if conflicting {
+			return nil, &snapstate.ChangeConflictError{Snap: "foo", ChangeKind: "refresh-snap", ChangeID: "42"}
+		}
+		return []*state.TaskSet{state.NewTaskSet(t.State().NewTask("fake-op", "..."))}, nil
+	})
+
+	s.state.Lock()
+	chg := s.queue(c, "foo")
+	queued := chg.Tasks()[0]
+	s.state.Unlock()
+
+	s.run()
+
+	s.state.Lock()
+	c.Check(queued.Status(), Equals, state.DoingStatus)
+	c.Check(chg.Tasks(), HasLen, 1)
+	c.Check(queued.Log(), Not(HasLen), 0)
+	c.Check(queued.Log()[0], Matches, `.* Waiting for conflicting change in progress: .*`)
+	conflicting = false
+	s.state.Unlock()
+
+	s.run()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Check(queued.Status(), Equals, state.DoneStatus)
+	tasks := chg.Tasks()
+	c.Assert(tasks, HasLen, 2)
+	c.Check(tasks[1].Kind(), Equals, "fake-op")
+	c.Check(tasks[1].WaitTasks(), DeepEquals, []*state.Task{queued})
+}
+
+func (s *queuedSnapOpSuite) TestQueuedSnapOpFIFO(c *C) {
+	var order []string
+	var blocking *state.Task
+	s.mockQueuedSnapOpTasks(func(ctx context.Context, t *state.Task) ([]*state.TaskSet, error) {
+		st := t.State()
+		if blocking != nil && !blocking.Status().Ready() {
+			return nil, &snapstate.ChangeConflictError{Snap: "foo", ChangeKind: "remove-snap", ChangeID: blocking.Change().ID()}
+		}
+		order = append(order, t.Change().ID())
+		// the operation keeps the snap busy until it is done
+		blocking = st.NewTask("fake-op", "...")
+		return []*state.TaskSet{state.NewTaskSet(blocking)}, nil
+	})
+
+	s.state.Lock()
+	chg1 := s.queue(c, "foo")
+	chg2 := s.queue(c, "foo")
+	chg3 := s.queue(c, "foo")
+	s.state.Unlock()
+
+	s.run()
+
+	s.state.Lock()
+	c.Check(order, DeepEquals, []string{chg1.ID()})
+	c.Check(chg2.Tasks()[0].Status(), Equals, state.DoingStatus)
+	c.Check(chg3.Tasks()[0].Status(), Equals, state.DoingStatus)
+	blocking.SetStatus(state.DoneStatus)
+	s.state.Unlock()
+
+	s.run()
+
+	s.state.Lock()
+	c.Check(order, DeepEquals, []string{chg1.ID(), chg2.ID()})
+	blocking.SetStatus(state.DoneStatus)
+	s.state.Unlock()
+
+	s.run()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Check(order, DeepEquals, []string{chg1.ID(), chg2.ID(), chg3.ID()})
+}
@@ -269,6 +269,39 @@ func arrangeSingleRebootForSplitTaskSets(beforeTss map[snap.Type]*state.TaskSet)
 	return nil
 }
 
+// waitForLastTaskAfterDownload makes the tasks of 'ts' following its
+// LastBeforeLocalModificationsEdge wait for the last task of the 'dep'
+// task-set, leaving the download and validation of the snap free to run in
+// parallel with 'dep'. Without such an edge the first task of 'ts' waits.
+func waitForLastTaskAfterDownload(ts, dep *state.TaskSet) error {
+	lastBefore := ts.MaybeEdge(LastBeforeLocalModificationsEdge)
+	if lastBefore == nil {
+		return waitForLastTask(ts, dep)
+	}
+	last, err := dep.Edge(EndEdge)
+	if err != nil {
+		return err
+	}
+
+	inTs := make(map[*state.Task]bool)
+	for _, t := range ts.Tasks() {
+		inTs[t] = true
+	}
+	var next []*state.Task
+	for _, t := range lastBefore.HaltTasks() {
+		if inTs[t] {
+			next = append(next, t)
+		}
+	}
+	if len(next) == 0 {
+		return waitForLastTask(ts, dep)
+	}
+	for _, t := range next {
+		t.WaitFor(last)
+	}
+	return nil
+}
+
 // waitForLastTask makes the first task of 'ts' wait for the last task of the 'dep' task-set.
 func waitForLastTask(ts, dep *state.TaskSet) error {
 	last, err := dep.Edge(EndEdge)
@@ -285,7 +318,8 @@ func waitForLastTask(ts, dep *state.TaskSet) error {
 
 // arrangeSnapToWaitForBaseIfPresent sets up dependency on the base of a snap, if the base is
 // also being updated. The boot-base is ignored here, as the boot-base is handled separately
-// as a part of the essential snaps.
+// as a part of the essential snaps. Only the tasks of the snap that modify the system wait
+// for the base, so the snap is downloaded while the base is being updated.
 func arrangeSnapToWaitForBaseIfPresent(snapTs *state.TaskSet, bases map[string]*state.TaskSet) error {
 	snapsup := maybeTaskSetSnapSetup(snapTs)
 	if snapsup == nil {
@@ -293,7 +327,7 @@ func arrangeSnapToWaitForBaseIfPresent(snapTs *state.TaskSet, bases map[string]*
 	}
 
 	if baseTs := bases[snapsup.Base]; baseTs != nil {
-		return waitForLastTask(snapTs, baseTs)
+		return waitForLastTaskAfterDownload(snapTs, baseTs)
 	}
 	return nil
 }
@@ -424,6 +424,30 @@ func (s *rebootSuite) TestArrangeSnapToWaitForBaseIfPresentNotPresent(c *C) {
 	c.Check(s.setDependsOn(c, tss[1], tss[0]), Equals, false)
 }
 
+func (s *rebootSuite) TestArrangeSnapToWaitForBaseIfPresentDownloadsInParallel(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	tss := []*state.TaskSet{
+		s.taskSetForSnapSetup("my-base", "", snap.TypeBase),
+		s.taskSetForSnapSetup("my-app", "my-base", snap.TypeApp),
+	}
+	// the first task stands for the download here
+	download := tss[1].Tasks()[0]
+	tss[1].MarkEdge(download, snapstate.LastBeforeLocalModificationsEdge)
+
+	err := snapstate.ArrangeSnapToWaitForBaseIfPresent(tss[1], map[string]*state.TaskSet{
+		"my-base": tss[0],
+	})
+	c.Check(err, IsNil)
+
+	// only the tasks modifying the system wait for the base
+	lastTaskOfBase, err := tss[0].Edge(snapstate.EndEdge)
+	c.Assert(err, IsNil)
+	c.Check(download.WaitTasks(), HasLen, 0)
+	c.Check(tss[1].Tasks()[1].WaitTasks(), DeepEquals, []*state.Task{download, lastTaskOfBase})
+}
+
 func (s *rebootSuite) TestArrangeSnapTaskSetsLinkageAndRestartUC16NoSplits(c *C) {
 	defer snapstatetest.MockDeviceModel(DefaultModel())()
 
@@ -31,6 +31,7 @@ import (
 	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/release"
+	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/store"
 	"github.com/snapcore/snapd/strutil"
 	"github.com/snapcore/snapd/timings"
@@ -324,3 +325,51 @@ func updateRefreshCandidates(st *state.State, hints map[string]*refreshCandidate
 	st.Set("refresh-candidates", oldHints)
 	return nil
 }
+
+// AvailableRefresh describes a pending refresh of an installed snap as
+// recorded by the last refresh hints check.
+type AvailableRefresh struct {
+	Revision     snap.Revision
+	Version      string
+	Channel      string
+	DownloadSize int64
+}
+
+// AvailableRefreshes returns the refreshes found by the last refresh hints
+// check, keyed by snap instance name. The information is read from the state
+// only, the store is not contacted. Candidates for snaps that are no longer
+// installed or that were already refreshed to the candidate revision are
+// not returned.
+func AvailableRefreshes(st *state.State) (map[string]*AvailableRefresh, error) {
+	var candidates map[string]*refreshCandidate
+	if err := st.Get("refresh-candidates", &candidates); err != nil {
+		if errors.Is(err, state.ErrNoState) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	refreshes := make(map[string]*AvailableRefresh, len(candidates))
+	for name, cand := range candidates {
+		var snapst SnapState
+		if err := Get(st, name, &snapst); err != nil {
+			if errors.Is(err, state.ErrNoState) {
+				continue
+			}
+			return nil, err
+		}
+		if snapst.Current == cand.Revision() {
+			continue
+		}
+		refresh := &AvailableRefresh{
+			Revision: cand.Revision(),
+			Version:  cand.SnapSetup.Version,
+			Channel:  cand.SnapSetup.Channel,
+		}
+		if cand.DownloadInfo != nil {
+			refresh.DownloadSize = cand.DownloadInfo.Size
+		}
+		refreshes[name] = refresh
+	}
+	return refreshes, nil
+}
@@ -97,6 +97,11 @@ func (r *recordingStore) SnapAction(ctx context.Context, currentSnaps []*store.C
 	return res, nil, nil
 }
 
+func (r *recordingStore) SnapInfo(ctx context.Context, spec store.SnapSpec, user *auth.UserState) (*snap.Info, error) {
+	r.ops = append(r.ops, "snap-info:"+spec.Name)
+	return nil, store.ErrSnapNotFound
+}
+
 type refreshHintsTestSuite struct {
 	testutil.BaseTest
 	state *state.State
@@ -685,6 +690,9 @@ func (s *refreshHintsTestSuite) TestRefreshHintsNotApplicableWrongEpoch(c *C) {
 	c.Assert(candidates, HasLen, 1)
 	// other-snap ignored due to epoch
 	c.Check(candidates["some-snap"], NotNil)
+	// the channel map of other-snap was checked for a revision bridging
+	// the epochs
+	c.Check(s.store.ops, DeepEquals, []string{"list-refresh", "snap-info:other-snap"})
 }
 
 func (s *refreshHintsTestSuite) TestSnapStoreOffline(c *C) {
@@ -798,3 +806,63 @@ func (s *refreshHintsTestSuite) TestUpdateCandidatesDiscriminating(c *C) {
 	c.Check(hints["bar"].SideInfo.Revision, Equals, snap.R(1))
 	c.Check(hints["bar"].Monitored, Equals, true)
 }
+
+func (s *refreshHintsTestSuite) TestAvailableRefreshes(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	refreshes, err := snapstate.AvailableRefreshes(s.state)
+	c.Assert(err, IsNil)
+	c.Check(refreshes, HasLen, 0)
+
+	for _, name := range []string{"foo", "bar"} {
+		snapstate.Set(s.state, name, &snapstate.SnapState{
+			Active: true,
+			Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+				{RealName: name, Revision: snap.R(1)},
+			}),
+			Current: snap.R(1),
+		})
+	}
+
+	err = snapstate.UpdateRefreshCandidates(s.state, map[string]*snapstate.RefreshCandidate{
+		"foo": {SnapSetup: snapstate.SnapSetup{
+			SideInfo:     &snap.SideInfo{RealName: "foo", Revision: snap.R(5)},
+			Version:      "2.0",
+			Channel:      "stable",
+			DownloadInfo: &snap.DownloadInfo{Size: 1024},
+		}},
+		"bar": {SnapSetup: snapstate.SnapSetup{
+			SideInfo: &snap.SideInfo{RealName: "bar", Revision: snap.R(2)},
+		}},
+	}, nil)
+	c.Assert(err, IsNil)
+
+	refreshes, err = snapstate.AvailableRefreshes(s.state)
+	c.Assert(err, IsNil)
+	c.Check(refreshes, DeepEquals, map[string]*snapstate.AvailableRefresh{
+		"foo": {Revision: snap.R(5), Version: "2.0", Channel: "stable", DownloadSize: 1024},
+		"bar": {Revision: snap.R(2)},
+	})
+}
+
+func (s *refreshHintsTestSuite) TestAvailableRefreshesSkipsStaleCandidates(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	err := snapstate.UpdateRefreshCandidates(s.state, map[string]*snapstate.RefreshCandidate{
+		// already refreshed to the candidate revision
+		"some-snap": {SnapSetup: snapstate.SnapSetup{
+			SideInfo: &snap.SideInfo{RealName: "some-snap", Revision: snap.R(5)},
+		}},
+		// no longer installed
+		"gone": {SnapSetup: snapstate.SnapSetup{
+			SideInfo: &snap.SideInfo{RealName: "gone", Revision: snap.R(2)},
+		}},
+	}, nil)
+	c.Assert(err, IsNil)
+
+	refreshes, err := snapstate.AvailableRefreshes(s.state)
+	c.Assert(err, IsNil)
+	c.Check(refreshes, HasLen, 0)
+}
@@ -0,0 +1,152 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapstate
+
+import (
+	"debug/elf"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/tomb.v2"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snapdtool"
+)
+
+// snapdCanaryDelay returns the refresh.snapd-canary-delay setting, and
+// whether the staging of snapd refreshes is enabled at all.
+func snapdCanaryDelay(st *state.State) (time.Duration, bool, error) {
+	var delayStr string
+	tr := config.NewTransaction(st)
+	if err := tr.GetMaybe("core", "refresh.snapd-canary-delay", &delayStr); err != nil {
+		return 0, false, err
+	}
+	if delayStr == "" {
+		return 0, false, nil
+	}
+	delay, err := time.ParseDuration(delayStr)
+	if err != nil {
+		// validated by configcore already
+		return 0, false, fmt.Errorf("internal error: invalid refresh.snapd-canary-delay %q: %v", delayStr, err)
+	}
+	return delay, true, nil
+}
+
+// snapdSelfCheckExe is the executable that the snapd of a new revision is
+// compared with, that is the currently running snapd.
+var snapdSelfCheckExe = "/proc/self/exe"
+
+// snapdSelfCheck checks that the snapd of the given, mounted, revision of
+// the snapd snap can execute on this system. Nothing from the new revision
+// is run: its info file must report a version and its snapd must be an
+// executable for the same machine as the current snapd.
+var snapdSelfCheck = func(info *snap.Info) error {
+	libExecDir := filepath.Join(info.MountDir(), dirs.CoreLibExecDir)
+	if _, _, err := snapdtool.SnapdVersionFromInfoFile(libExecDir); err != nil {
+		return err
+	}
+
+	snapd := filepath.Join(libExecDir, "snapd")
+	fi, err := os.Stat(snapd)
+	if err != nil {
+		return err
+	}
+	if !fi.Mode().IsRegular() || fi.Mode().Perm()&0111 == 0 {
+		return fmt.Errorf("%s is not an executable", snapd)
+	}
+	newMachine, newClass, err := elfMachine(snapd)
+	if err != nil {
+		return err
+	}
+	curMachine, curClass, err := elfMachine(snapdSelfCheckExe)
+	if err != nil {
+		return err
+	}
+	if newMachine != curMachine || newClass != curClass {
+		return fmt.Errorf("%s is built for %s %s, not %s %s", snapd, newClass, newMachine, curClass, curMachine)
+	}
+	return nil
+}
+
+func elfMachine(path string) (elf.Machine, elf.Class, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return 0, 0, fmt.Errorf("cannot read executable %s: %v", path, err)
+	}
+	defer f.Close()
+	return f.Machine, f.Class, nil
+}
+
+// doCheckSnapd verifies that a new revision of the snapd snap works before
+// the current snapd is replaced by it. On auto-refreshes the refresh is then
+// held for the refresh.snapd-canary-delay window before proceeding.
+func (m *SnapManager) doCheckSnapd(t *state.Task, _ *tomb.Tomb) error {
+	st := t.State()
+	st.Lock()
+	defer st.Unlock()
+
+	snapsup, err := TaskSnapSetup(t)
+	if err != nil {
+		return err
+	}
+
+	var verified time.Time
+	if err := t.Get("snapd-verified-time", &verified); err != nil && !errors.Is(err, state.ErrNoState) {
+		return err
+	}
+	if verified.IsZero() {
+		newInfo, err := readInfo(snapsup.InstanceName(), snapsup.SideInfo, 0)
+		if err != nil {
+			return err
+		}
+		st.Unlock()
+		err = snapdSelfCheck(newInfo)
+		st.Lock()
+		if err != nil {
+			return fmt.Errorf("cannot use snapd revision %s: %v", snapsup.Revision(), err)
+		}
+		verified = timeNow()
+		t.Set("snapd-verified-time", verified)
+		t.Logf("Verified snapd revision %s", snapsup.Revision())
+	}
+
+	if !snapsup.IsAutoRefresh {
+		return nil
+	}
+	delay, _, err := snapdCanaryDelay(st)
+	if err != nil {
+		return err
+	}
+	if remaining := verified.Add(delay).Sub(timeNow()); remaining > 0 {
+		logger.Debugf("holding refresh of snapd to revision %s for %s", snapsup.Revision(), remaining)
+		return &state.Retry{
+			After:  remaining,
+			Reason: fmt.Sprintf("snapd canary window ends in %s", remaining.Round(time.Second)),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapstate_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/snapstate/snapstatetest"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/testutil"
+)
+
+func (s *snapmgrTestSuite) setSnapdCanaryDelay(c *C, delay string) {
+	tr := config.NewTransaction(s.state)
+	c.Assert(tr.Set("core", "refresh.snapd-canary-delay", delay), IsNil)
+	tr.Commit()
+}
+
+func (s *snapmgrTestSuite) setupSnapdForCanary() {
+	snapstate.Set(s.state, "snapd", &snapstate.SnapState{
+		Active: true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "snapd", SnapID: "snapd-snap-id", Revision: snap.R(1)},
+		}),
+		Current:  snap.R(1),
+		SnapType: "snapd",
+	})
+}
+
+func (s *snapmgrTestSuite) TestUpdateSnapdCanaryTasks(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.setupSnapdForCanary()
+
+	// snapd refreshes are not staged by default
+	ts, err := snapstate.Update(s.state, "snapd", nil, s.user.ID, snapstate.Flags{})
+	c.Assert(err, IsNil)
+	c.Check(taskKinds(ts.Tasks()), Not(testutil.Contains), "check-snapd")
+
+	s.setSnapdCanaryDelay(c, "1h")
+	ts, err = snapstate.Update(s.state, "snapd", nil, s.user.ID, snapstate.Flags{})
+	c.Assert(err, IsNil)
+	kinds := taskKinds(ts.Tasks())
+	c.Assert(kinds, testutil.Contains, "check-snapd")
+	for i, kind := range kinds {
+		if kind == "check-snapd" {
+			// the new snapd is checked right after it was mounted
+			c.Check(kinds[i-1], Equals, "mount-snap")
+		}
+	}
+}
+
+func (s *snapmgrTestSuite) TestUpdateSnapdCanaryRunThrough(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.setupSnapdForCanary()
+	s.setSnapdCanaryDelay(c, "0s")
+
+	var checked []snap.Revision
+	restore := snapstate.MockSnapdSelfCheck(func(info *snap.Info) error {
+		checked = append(checked, info.Revision)
+		return nil
+	})
+	defer restore()
+
+	ts, err := snapstate.Update(s.state, "snapd", nil, s.user.ID, snapstate.Flags{})
+	c.Assert(err, IsNil)
+	chg := s.state.NewChange("refresh", "refresh snapd")
+	chg.AddAll(ts)
+
+	s.settle(c)
+
+	c.Assert(chg.Err(), IsNil)
+	c.Check(chg.Status(), Equals, state.DoneStatus)
+	c.Check(checked, DeepEquals, []snap.Revision{snap.R(11)})
+
+	var snapst snapstate.SnapState
+	c.Assert(snapstate.Get(s.state, "snapd", &snapst), IsNil)
+	c.Check(snapst.Current, Equals, snap.R(11))
+}
+
+func (s *snapmgrTestSuite) TestUpdateSnapdCanarySelfCheckFails(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.setupSnapdForCanary()
+	s.setSnapdCanaryDelay(c, "1h")
+
+	restore := snapstate.MockSnapdSelfCheck(func(info *snap.Info) error {
+		return errors.New("exec format error")
+	})
+	defer restore()
+
+	ts, err := snapstate.Update(s.state, "snapd", nil, s.user.ID, snapstate.Flags{})
+	c.Assert(err, IsNil)
+	chg := s.state.NewChange("refresh", "refresh snapd")
+	chg.AddAll(ts)
+
+	s.settle(c)
+
+	c.Check(chg.Err(), ErrorMatches, `(?s).*cannot use snapd revision 11: exec format error.*`)
+
+	// the current snapd was never touched
+	var snapst snapstate.SnapState
+	c.Assert(snapstate.Get(s.state, "snapd", &snapst), IsNil)
+	c.Check(snapst.Current, Equals, snap.R(1))
+	c.Check(snapst.Active, Equals, true)
+}
+
+func (s *snapmgrTestSuite) TestCheckSnapdCanaryWindow(c *C) {
+	s.state.Lock()
+	s.setSnapdCanaryDelay(c, "1h")
+	s.state.Unlock()
+
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	restore := snapstate.MockTimeNow(func() time.Time { return now })
+	defer restore()
+	checks := 0
+	restore = snapstate.MockSnapdSelfCheck(func(info *snap.Info) error {
+		checks++
+		return nil
+	})
+	defer restore()
+
+	for _, autoRefresh := range []bool{false, true} {
+		s.state.Lock()
+		t := s.state.NewTask("check-snapd", "...")
+		t.Set("snap-setup", &snapstate.SnapSetup{
+			SideInfo: &snap.SideInfo{
+				RealName: "snapd",
+				SnapID:   "snapd-snap-id",
+				Revision: snap.R(11),
+			},
+			Type:  snap.TypeSnapd,
+			Flags: snapstate.Flags{IsAutoRefresh: autoRefresh},
+		})
+		s.state.Unlock()
+
+		err := s.snapmgr.DoCheckSnapd(t)
+		if !autoRefresh {
+			// manual refreshes proceed once verified
+			c.Check(err, IsNil)
+			continue
+		}
+		// auto-refreshes are held for the canary window
+		c.Assert(err, FitsTypeOf, &state.Retry{})
+		c.Check(err.(*state.Retry).After, Equals, time.Hour)
+		c.Check(err.(*state.Retry).Reason, Equals, "snapd canary window ends in 1h0m0s")
+
+		now = now.Add(50 * time.Minute)
+		err = s.snapmgr.DoCheckSnapd(t)
+		c.Assert(err, FitsTypeOf, &state.Retry{})
+		c.Check(err.(*state.Retry).After, Equals, 10*time.Minute)
+
+		now = now.Add(10 * time.Minute)
+		c.Check(s.snapmgr.DoCheckSnapd(t), IsNil)
+	}
+	// snapd is verified only once per task
+	c.Check(checks, Equals, 2)
+}
+
+func (s *snapmgrTestSuite) mockSnapdRevision(c *C, info *snap.Info, infoFile string, snapd []byte) {
+	libExecDir := filepath.Join(info.MountDir(), dirs.CoreLibExecDir)
+	c.Assert(os.MkdirAll(libExecDir, 0755), IsNil)
+	if infoFile != "" {
+		c.Assert(os.WriteFile(filepath.Join(libExecDir, "info"), []byte(infoFile), 0644), IsNil)
+	}
+	if snapd != nil {
+		c.Assert(os.WriteFile(filepath.Join(libExecDir, "snapd"), snapd, 0755), IsNil)
+	}
+}
+
+func (s *snapmgrTestSuite) TestSnapdSelfCheck(c *C) {
+	// the test binary stands in for both the current and the new snapd
+	exe, err := os.Executable()
+	c.Assert(err, IsNil)
+	restore := snapstate.MockSnapdSelfCheckExe(exe)
+	defer restore()
+	exeData, err := os.ReadFile(exe)
+	c.Assert(err, IsNil)
+
+	info := &snap.Info{SideInfo: snap.SideInfo{RealName: "snapd", Revision: snap.R(11)}}
+	s.mockSnapdRevision(c, info, "VERSION=2.99\n", exeData)
+
+	c.Check(snapstate.SnapdSelfCheck(info), IsNil)
+}
+
+func (s *snapmgrTestSuite) TestSnapdSelfCheckErrors(c *C) {
+	exe, err := os.Executable()
+	c.Assert(err, IsNil)
+	restore := snapstate.MockSnapdSelfCheckExe(exe)
+	defer restore()
+	exeData, err := os.ReadFile(exe)
+	c.Assert(err, IsNil)
+
+	for i, tc := range []struct {
+		infoFile string
+		snapd    []byte
+		err      string
+	}{
+		{"", exeData, `cannot open snapd info file .*`},
+		{"VERSION=\n", exeData, `cannot find version in snapd info file .*`},
+		{"VERSION=2.99\n", nil, `stat .*/usr/lib/snapd/snapd: no such file or directory`},
+		{"VERSION=2.99\n", []byte("#!/bin/sh\nexec /usr/bin/snapd \"$@\"\n"), `cannot read executable .*/usr/lib/snapd/snapd: bad magic number .*`},
+	} {
+		info := &snap.Info{SideInfo: snap.SideInfo{RealName: "snapd", Revision: snap.R(20 + i)}}
+		s.mockSnapdRevision(c, info, tc.infoFile, tc.snapd)
+		c.Check(snapstate.SnapdSelfCheck(info), ErrorMatches, tc.err, Commentf("#%d", i))
+	}
+}
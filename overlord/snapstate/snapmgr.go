@@ -595,6 +595,12 @@ var snapReadInfo = snap.ReadInfo
 
 // AutomaticSnapshot allows to hook snapshot manager's AutomaticSnapshot.
 var AutomaticSnapshot func(st *state.State, instanceName string) (ts *state.TaskSet, err error)
+
+// AutomaticPreRefreshSnapshot allows to hook snapshot manager's
+// AutomaticPreRefreshSnapshot. By default no snapshot is taken.
+var AutomaticPreRefreshSnapshot = func(st *state.State, instanceName string) (ts *state.TaskSet, err error) {
+	return nil, ErrNothingToDo
+}
 var AutomaticSnapshotExpiration func(st *state.State) (time.Duration, error)
 var EstimateSnapshotSize func(st *state.State, instanceName string, users []string) (uint64, error)
 
@@ -824,6 +830,7 @@ func Manager(st *state.State, runner *state.TaskRunner) (*SnapManager, error) {
 	runner.AddHandler("prepare-snap", m.doPrepareSnap, m.undoPrepareSnap)
 	runner.AddHandler("download-snap", m.doDownloadSnap, m.undoPrepareSnap)
 	runner.AddHandler("mount-snap", m.doMountSnap, m.undoMountSnap)
+	runner.AddHandler("check-snapd", m.doCheckSnapd, nil)
 	runner.AddHandler("unlink-current-snap", m.doUnlinkCurrentSnap, m.undoUnlinkCurrentSnap)
 	runner.AddHandler("copy-snap-data", m.doCopySnapData, m.undoCopySnapData)
 	runner.AddCleanup("copy-snap-data", m.cleanupCopySnapData)
@@ -868,6 +875,7 @@ func Manager(st *state.State, runner *state.TaskRunner) (*SnapManager, error) {
 	// no undo for now since it's last task in valset auto-resolution change
 	runner.AddHandler("enforce-validation-sets", m.doEnforceValidationSets, nil)
 	runner.AddHandler("pre-download-snap", m.doPreDownloadSnap, nil)
+	runner.AddHandler("queued-snap-op", m.doQueuedSnapOp, nil)
 
 	// component tasks
 	runner.AddHandler("prepare-component", m.doPrepareComponent, nil)
@@ -885,6 +893,7 @@ func Manager(st *state.State, runner *state.TaskRunner) (*SnapManager, error) {
 	runner.AddBlocked(m.blockedTask)
 
 	RegisterAffectedSnapsByKind("conditional-auto-refresh", conditionalAutoRefreshAffectedSnaps)
+	RegisterAffectedSnapsByKind("queued-snap-op", queuedSnapOpAffectedSnaps)
 
 	return m, nil
 }
@@ -951,16 +960,30 @@ func genRefreshRequestSalt(st *state.State) error {
 	return nil
 }
 
+// maxConcurrentDownloads is the maximum number of "download-snap" tasks
+// running at the same time. Snaps that are refreshed together get their own
+// lanes and would otherwise all be downloaded at once.
+var maxConcurrentDownloads = 4
+
 func (m *SnapManager) blockedTask(cand *state.Task, running []*state.Task) bool {
-	// Serialize "prerequisites", the state lock is not enough as
-	// Install() inside doPrerequisites() will unlock to talk to
-	// the store.
-	if cand.Kind() == "prerequisites" {
+	switch cand.Kind() {
+	case "prerequisites":
+		// Serialize "prerequisites", the state lock is not enough as
+		// Install() inside doPrerequisites() will unlock to talk to
+		// the store.
 		for _, t := range running {
 			if t.Kind() == "prerequisites" {
 				return true
 			}
 		}
+	case "download-snap":
+		downloading := 0
+		for _, t := range running {
+			if t.Kind() == "download-snap" {
+				downloading++
+			}
+		}
+		return downloading >= maxConcurrentDownloads
 	}
 
 	return false
@@ -236,6 +236,41 @@ func refreshRetain(st *state.State) int {
 	return retain
 }
 
+// snapRefreshRetain returns the number of revisions to retain for the given
+// snap: the Retain flag if set, the refresh.snap-retain.<snap> system option
+// if set, or the system wide refresh.retain value otherwise. The snap itself
+// cannot change how many of its revisions are kept.
+func snapRefreshRetain(st *state.State, instanceName string, flags Flags) int {
+	if flags.Retain > 0 {
+		return flags.Retain
+	}
+
+	var val interface{}
+	err := config.NewTransaction(st).GetMaybe("core", "refresh.snap-retain."+instanceName, &val)
+	var retain int
+	if err == nil {
+		switch v := val.(type) {
+		case nil:
+			// not set
+		case json.Number:
+			retain, err = strconv.Atoi(string(v))
+		case string:
+			retain, err = strconv.Atoi(v)
+		default:
+			err = fmt.Errorf("unexpected type: %T", v)
+		}
+	}
+	if err != nil {
+		logger.Noticef("internal error: refresh.snap-retain system option of snap %q is not valid: %v", instanceName, err)
+		retain = 0
+	}
+
+	if retain == 0 {
+		return refreshRetain(st)
+	}
+	return retain
+}
+
 var excludeFromRefreshAppAwareness = func(t snap.Type) bool {
 	return t == snap.TypeSnapd || t == snap.TypeOS
 }
@@ -506,6 +541,19 @@ func doInstall(st *state.State, snapst *SnapState, snapsup SnapSetup, compsups [
 	if !revisionIsLocal {
 		mount := st.NewTask("mount-snap", fmt.Sprintf(i18n.G("Mount snap %q%s"), snapsup.InstanceName(), revisionStr))
 		addTask(mount)
+
+		// when staging snapd refreshes, check that the new snapd
+		// works before anything else is changed
+		if snapsup.Type == snap.TypeSnapd && snapst.IsInstalled() && !snapsup.Flags.Revert {
+			_, staged, err := snapdCanaryDelay(st)
+			if err != nil {
+				return nil, err
+			}
+			if staged {
+				checkSnapd := st.NewTask("check-snapd", fmt.Sprintf(i18n.G("Check snap %q%s"), snapsup.InstanceName(), revisionStr))
+				addTask(checkSnapd)
+			}
+		}
 	} else {
 		if snapsup.Flags.RemoveSnapPath {
 			// If the revision is local, we will not need the
@@ -554,6 +602,20 @@ func doInstall(st *state.State, snapst *SnapState, snapsup SnapSetup, compsups [
 		stop.Set("stop-reason", snap.StopReasonRefresh)
 		addTask(stop)
 
+		// the data is saved with the services stopped, and restored when
+		// undoing with the old revision linked again
+		if runRefreshHooks && snapsup.Type == snap.TypeApp {
+			ts, err := AutomaticPreRefreshSnapshot(st, snapsup.InstanceName())
+			switch {
+			case err == nil:
+				for _, t := range ts.Tasks() {
+					addTask(t)
+				}
+			case err != ErrNothingToDo:
+				return nil, err
+			}
+		}
+
 		removeAliases := st.NewTask("remove-aliases", fmt.Sprintf(i18n.G("Remove aliases for snap %q"), snapsup.InstanceName()))
 		removeAliases.Set("remove-reason", removeAliasesReasonRefresh)
 		addTask(removeAliases)
@@ -722,7 +784,7 @@ func doInstall(st *state.State, snapst *SnapState, snapsup SnapSetup, compsups [
 	// Do not do that if we are reverting to a local revision
 	var cleanupTask *state.Task
 	if snapst.IsInstalled() && !snapsup.Flags.Revert {
-		retain := refreshRetain(st)
+		retain := snapRefreshRetain(st, snapsup.InstanceName(), snapsup.Flags)
 
 		// if we're not using an already present revision, account for the one being added
 		if snapst.LastIndex(targetRevision) == -1 {
@@ -1303,6 +1365,19 @@ func defaultProviderContentAttrs(st *state.State, info *snap.Info, prqt PrereqTr
 	return prqt.MissingProviderContentTags(info, repo)
 }
 
+// checkParallelInstancesEnabled returns an error if the experimental
+// parallel-instances feature is not enabled.
+func checkParallelInstancesEnabled(tr *config.Transaction) error {
+	flag, err := features.Flag(tr, features.ParallelInstances)
+	if err != nil {
+		return err
+	}
+	if !flag {
+		return fmt.Errorf("experimental feature disabled - test it by setting 'experimental.parallel-instances' to true")
+	}
+	return nil
+}
+
 // validateFeatureFlags validates the given snap only uses experimental
 // features that are enabled by the user.
 func validateFeatureFlags(st *state.State, info *snap.Info) error {
@@ -1319,13 +1394,9 @@ func validateFeatureFlags(st *state.State, info *snap.Info) error {
 	}
 
 	if info.InstanceKey != "" {
-		flag, err := features.Flag(tr, features.ParallelInstances)
-		if err != nil {
+		if err := checkParallelInstancesEnabled(tr); err != nil {
 			return err
 		}
-		if !flag {
-			return fmt.Errorf("experimental feature disabled - test it by setting 'experimental.parallel-instances' to true")
-		}
 	}
 
 	var hasUserService, usesDbusActivation bool
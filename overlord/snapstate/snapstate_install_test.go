@@ -1125,7 +1125,7 @@ func (s *snapmgrTestSuite) TestInstallStateConflict(c *C) {
 
 	_, err := snapstate.Install(context.Background(), s.state, "some-snap", nil, 0, snapstate.Flags{})
 	c.Check(err, testutil.ErrorIs, &snapstate.ChangeConflictError{})
-	c.Assert(err, ErrorMatches, `snap "some-snap" has changes in progress`)
+	c.Assert(err, ErrorMatches, `snap "some-snap" was changed by another operation in the meantime`)
 }
 
 func (s *snapmgrTestSuite) TestInstallPathTooEarly(c *C) {
@@ -1176,6 +1176,35 @@ func (s *snapmgrTestSuite) TestInstallPathSnapIDRevisionUnset(c *C) {
 	c.Assert(err, ErrorMatches, fmt.Sprintf(`internal error: snap id set to install %q but revision is unset`, mockSnap))
 }
 
+func (s *snapmgrTestSuite) TestInstallPathUnassertedSideloadAuthority(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	defer snapstatetest.MockDeviceModel(MakeModel(map[string]interface{}{
+		"sideload-authority": []interface{}{"delegate"},
+	}))()
+
+	mockSnap := makeTestSnap(c, "name: some-snap\nversion: 1.0")
+	_, _, err := snapstate.InstallPath(s.state, &snap.SideInfo{RealName: "some-snap"}, mockSnap, "", "", snapstate.Flags{}, nil)
+	c.Assert(err, ErrorMatches, `cannot install unasserted snap "some-snap": the device model only allows sideloading snaps signed by: brand, delegate`)
+
+	_, err = snapstate.TryPath(s.state, "some-snap", c.MkDir(), snapstate.Flags{})
+	c.Assert(err, ErrorMatches, `cannot install unasserted snap "some-snap": .*`)
+
+	// nor unasserted components along with asserted snaps
+	si := &snap.SideInfo{RealName: "some-snap", SnapID: "some-snap-id", Revision: snap.R(7)}
+	csi := snap.NewComponentSideInfo(naming.NewComponentRef("some-snap", "comp"), snap.Revision{})
+	goal := snapstate.PathInstallGoal("some-snap", mockSnap, si, map[*snap.ComponentSideInfo]string{
+		csi: filepath.Join(c.MkDir(), "some-snap+comp.comp"),
+	}, snapstate.RevisionOptions{})
+	_, _, err = snapstate.InstallOne(context.Background(), s.state, goal, snapstate.Options{})
+	c.Assert(err, ErrorMatches, `cannot install unasserted component "some-snap\+comp": the device model only allows sideloading snaps signed by: brand, delegate`)
+
+	// asserted snaps are not affected
+	_, _, err = snapstate.InstallPath(s.state, si, mockSnap, "", "", snapstate.Flags{}, nil)
+	c.Assert(err, IsNil)
+}
+
 func (s *snapmgrTestSuite) TestInstallPathValidateFlags(c *C) {
 	s.state.Lock()
 	defer s.state.Unlock()
@@ -1526,6 +1555,7 @@ func (s *snapmgrTestSuite) testParallelInstanceInstallRunThrough(c *C, inputFlag
 		macaroon: s.user.StoreMacaroon,
 		name:     "some-snap",
 		target:   filepath.Join(dirs.SnapBlobDir, "some-snap_instance_11.snap"),
+		opts:     &store.DownloadOptions{InstanceName: "some-snap_instance"},
 	}})
 	c.Check(s.fakeStore.seenPrivacyKeys["privacy-key"], Equals, true, Commentf("salts seen: %v", s.fakeStore.seenPrivacyKeys))
 	expected := fakeOps{
@@ -3934,6 +3964,26 @@ version: 1.0
 	c.Assert(err, IsNil)
 }
 
+func (s *snapmgrTestSuite) TestParallelInstallStoreExperimentalSwitch(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	opts := &snapstate.RevisionOptions{Channel: "stable"}
+	_, err := snapstate.Install(context.Background(), s.state, "some-snap_foo", opts, s.user.ID, snapstate.Flags{})
+	c.Assert(err, ErrorMatches, "experimental feature disabled - test it by setting 'experimental.parallel-instances' to true")
+	// the store was not asked about the snap
+	c.Check(s.fakeBackend.ops, HasLen, 0)
+
+	// enable parallel instances
+	tr := config.NewTransaction(s.state)
+	tr.Set("core", "experimental.parallel-instances", true)
+	tr.Commit()
+
+	ts, err := snapstate.Install(context.Background(), s.state, "some-snap_foo", opts, s.user.ID, snapstate.Flags{})
+	c.Assert(err, IsNil)
+	c.Check(ts.Tasks(), Not(HasLen), 0)
+}
+
 func (s *snapmgrTestSuite) TestInstallMany(c *C) {
 	s.state.Lock()
 	defer s.state.Unlock()
@@ -6744,6 +6794,9 @@ func (s *snapmgrTestSuite) testInstallComponentsRunThrough(c *C, opts testInstal
 		name:     opts.snapName,
 		target:   filepath.Join(dirs.SnapBlobDir, fmt.Sprintf("%s_%v.snap", instanceName, snapRevision)),
 	}}
+	if opts.instanceKey != "" {
+		downloads[0].opts = &store.DownloadOptions{InstanceName: instanceName}
+	}
 	for i, compName := range opts.components {
 		downloads = append(downloads, fakeDownload{
 			macaroon: s.user.StoreMacaroon,
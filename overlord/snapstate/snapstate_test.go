@@ -40,11 +40,13 @@ import (
 	"github.com/snapcore/snapd/asserts/snapasserts"
 	"github.com/snapcore/snapd/bootloader"
 	"github.com/snapcore/snapd/bootloader/bootloadertest"
+	"github.com/snapcore/snapd/desktop/desktopentry"
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/gadget"
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/osutil/avahi"
 	"github.com/snapcore/snapd/osutil/squashfs"
 	"github.com/snapcore/snapd/overlord"
 	"github.com/snapcore/snapd/overlord/auth"
@@ -3528,6 +3530,24 @@ func (s *snapmgrTestSuite) TestDisableDoesNotEnableAgain(c *C) {
 	c.Assert(ts, IsNil)
 }
 
+func (s *snapmgrTestSuite) TestBlockedTaskBoundsConcurrentDownloads(c *C) {
+	restore := snapstate.MockMaxConcurrentDownloads(2)
+	defer restore()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	dl1 := s.state.NewTask("download-snap", "...")
+	dl2 := s.state.NewTask("download-snap", "...")
+	dl3 := s.state.NewTask("download-snap", "...")
+	link := s.state.NewTask("link-snap", "...")
+
+	c.Check(s.snapmgr.BlockedTask(dl2, []*state.Task{dl1, link}), Equals, false)
+	c.Check(s.snapmgr.BlockedTask(dl3, []*state.Task{dl1, dl2}), Equals, true)
+	// other tasks of independent snaps keep running
+	c.Check(s.snapmgr.BlockedTask(link, []*state.Task{dl1, dl2}), Equals, false)
+}
+
 func (s *snapmgrTestSuite) TestEnsureRemovesVulnerableCoreSnap(c *C) {
 	s.testEnsureRemovesVulnerableSnap(c, "core")
 }
@@ -5330,6 +5350,77 @@ func (s *snapmgrTestSuite) TestSeqRetainConf(c *C) {
 	}
 }
 
+func (s *snapmgrTestSuite) TestSeqRetainPerSnapConf(c *C) {
+	revseq := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	for i := 2; i <= 10; i++ {
+		s.TearDownTest(c)
+		s.SetUpTest(c)
+		s.state.Lock()
+		tr := config.NewTransaction(s.state)
+		// the per-snap system option takes precedence over the
+		// system wide one
+		tr.Set("core", "refresh.retain", 5)
+		tr.Set("core", "refresh.snap-retain.some-snap", i)
+		tr.Commit()
+		s.state.Unlock()
+
+		s.testUpdateSequence(c, &opSeqOpts{before: revseq[:9], current: 9, via: 10, after: revseq[10-i:]})
+	}
+}
+
+func (s *snapmgrTestSuite) TestSnapRefreshRetain(c *C) {
+	st := s.state
+	st.Lock()
+	defer st.Unlock()
+
+	restore := release.MockOnClassic(true)
+	defer restore()
+
+	// falls back to the system default
+	c.Check(snapstate.SnapRefreshRetain(st, "some-snap", snapstate.Flags{}), Equals, 2)
+
+	tr := config.NewTransaction(st)
+	tr.Set("core", "refresh.retain", 3)
+	tr.Commit()
+	// falls back to the system option
+	c.Check(snapstate.SnapRefreshRetain(st, "some-snap", snapstate.Flags{}), Equals, 3)
+
+	// the configuration of the snap itself is ignored
+	tr = config.NewTransaction(st)
+	tr.Set("some-snap", "refresh.retain", 10)
+	tr.Commit()
+	c.Check(snapstate.SnapRefreshRetain(st, "some-snap", snapstate.Flags{}), Equals, 3)
+
+	buf, restoreLogger := logger.MockLogger()
+	defer restoreLogger()
+
+	for i, val := range []struct {
+		input    interface{}
+		expected int
+		msg      string
+	}{
+		{json.Number("4"), 4, "^$"},
+		{"6", 6, "^$"},
+		// invalid => system option
+		{"many", 3, `.*internal error: refresh.snap-retain system option of snap "some-snap" is not valid: strconv.Atoi: parsing "many": invalid syntax\n`},
+		{map[string]interface{}{"foo": "bar"}, 3, `.*internal error: refresh.snap-retain system option of snap "some-snap" is not valid: unexpected type: map\[string\]interface {}\n`},
+	} {
+		tr := config.NewTransaction(st)
+		tr.Set("core", "refresh.snap-retain.some-snap", val.input)
+		tr.Commit()
+		c.Check(snapstate.SnapRefreshRetain(st, "some-snap", snapstate.Flags{}), Equals, val.expected, Commentf("#%d", i))
+		c.Check(buf.String(), Matches, val.msg, Commentf("#%d", i))
+		buf.Reset()
+	}
+
+	// the option of another snap does not apply
+	c.Check(snapstate.SnapRefreshRetain(st, "other-snap", snapstate.Flags{}), Equals, 3)
+
+	// the flag takes precedence over everything
+	c.Check(snapstate.SnapRefreshRetain(st, "some-snap", snapstate.Flags{Retain: 7}), Equals, 7)
+}
+
 func (s *snapmgrTestSuite) TestRefreshRetain(c *C) {
 	st := s.state
 	st.Lock()
@@ -7574,6 +7665,83 @@ func (s *snapmgrTestSuite) TestStopSnapServicesUndo(c *C) {
 	})
 }
 
+const mdnsServicesSnap = `name: hello-snap
+version: 1
+apps:
+ svc1:
+  command: bin/hello
+  daemon: simple
+  plugs: [network-bind]
+  mdns:
+   type: _http._tcp
+   port: 8080
+ svc2:
+  command: bin/hello
+  daemon: simple
+  plugs: [network-bind]
+  mdns:
+   type: _ipp._tcp
+   port: 631
+`
+
+func (s *snapmgrTestSuite) TestStopSnapServicesUndoAdvertisesServices(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	var registered, unregistered []string
+	restore := snapstate.MockAvahi(func(tag string, svc avahi.Service) error {
+		registered = append(registered, tag)
+		return nil
+	}, func(tag string) error {
+		unregistered = append(unregistered, tag)
+		return nil
+	})
+	defer restore()
+
+	tr := config.NewTransaction(s.state)
+	tr.Set("core", "mdns.advertise", "hello-snap")
+	tr.Commit()
+
+	prevCurrentlyDisabled := s.fakeBackend.servicesCurrentlyDisabled
+	s.fakeBackend.servicesCurrentlyDisabled = []string{"svc1"}
+	defer func() {
+		s.fakeBackend.servicesCurrentlyDisabled = prevCurrentlyDisabled
+	}()
+
+	si := &snap.SideInfo{RealName: "hello-snap", SnapID: "hello-snap-id", Revision: snap.R(1)}
+	snaptest.MockSnap(c, mdnsServicesSnap, si)
+
+	snapstate.Set(s.state, "hello-snap", &snapstate.SnapState{
+		Active:   true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{si}),
+		Current:  si.Revision,
+		SnapType: "app",
+	})
+
+	// using MockSnap, we want to read the bits on disk
+	snapstate.MockSnapReadInfo(snap.ReadInfo)
+
+	chg := s.state.NewChange("services..", "")
+	t := s.state.NewTask("stop-snap-services", "")
+	sup := &snapstate.SnapSetup{SideInfo: si}
+	t.Set("snap-setup", sup)
+	chg.AddTask(t)
+	terr := s.state.NewTask("error-trigger", "provoking total undo")
+	terr.WaitFor(t)
+	terr.JoinLane(t.Lanes()[0])
+	chg.AddTask(terr)
+
+	s.settle(c)
+
+	c.Check(chg.Status(), Equals, state.ErrorStatus)
+	c.Check(t.Status(), Equals, state.UndoneStatus)
+
+	// the services were withdrawn when stopped
+	c.Check(unregistered, testutil.DeepUnsortedMatches, []string{"snap.hello-snap.svc1", "snap.hello-snap.svc2"})
+	// and advertised again when started on undo, except for the disabled one
+	c.Check(registered, DeepEquals, []string{"snap.hello-snap.svc2"})
+}
+
 func (s *snapmgrTestSuite) TestStopSnapServicesErrInUndo(c *C) {
 	s.state.Lock()
 	defer s.state.Unlock()
@@ -9550,13 +9718,15 @@ X-SnapInstanceName=test-snap`)
 	err := s.snapmgr.Ensure()
 	c.Assert(err, IsNil)
 
+	key, err := os.ReadFile(dirs.SnapDesktopLaunchKey)
+	c.Assert(err, IsNil)
 	expectedContent := fmt.Sprintf(`
 [Desktop Entry]
 X-SnapInstanceName=test-snap
 Name=test
 X-SnapAppName=test-snap
-Exec=env BAMF_DESKTOP_FILE_HINT=%s/test-snap_test-snap.desktop %s/test-snap
-`[1:], dirs.SnapDesktopFilesDir, dirs.SnapBinariesDir)
+Exec=env BAMF_DESKTOP_FILE_HINT=%s SNAP_DESKTOP_LAUNCH_TOKEN=%s %s/test-snap
+`[1:], desktopFile, desktopentry.LaunchToken(key, desktopFile, "test-snap"), dirs.SnapBinariesDir)
 
 	c.Assert(desktopFile, testutil.FileEquals, expectedContent)
 	c.Assert(otherDesktopFile, testutil.FileAbsent)
@@ -9645,6 +9815,118 @@ func (s *snapmgrTestSuite) TestSaveRefreshCandidatesOnAutoRefresh(c *C) {
 	c.Check(cands["some-other-snap"], NotNil)
 }
 
+func (s *snapmgrTestSuite) setupClosedTrackSnaps() {
+	snapstate.Set(s.state, "some-snap", &snapstate.SnapState{
+		Active: true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "some-snap", SnapID: "some-snap-id", Revision: snap.R(1)},
+		}),
+		Current:  snap.R(1),
+		SnapType: "app",
+	})
+	snapstate.Set(s.state, "closed-track-snap", &snapstate.SnapState{
+		Active: true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "closed-track-snap", SnapID: "closed-track-snap-id", Revision: snap.R(1)},
+		}),
+		Current:         snap.R(1),
+		SnapType:        "app",
+		TrackingChannel: "1.0/candidate",
+	})
+}
+
+func (s *snapmgrTestSuite) TestAutoRefreshClosedTrackWarns(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.setupClosedTrackSnaps()
+
+	names, _, err := snapstate.AutoRefresh(context.Background(), s.state)
+	c.Assert(err, IsNil)
+	c.Check(names, DeepEquals, []string{"some-snap"})
+
+	warnings := s.state.AllWarnings()
+	c.Assert(warnings, HasLen, 1)
+	c.Check(warnings[0].String(), Equals, `snap "closed-track-snap" is tracking the closed track "1.0" and will not be refreshed; use 'snap refresh --channel' to track another channel`)
+
+	var snapst snapstate.SnapState
+	c.Assert(snapstate.Get(s.state, "closed-track-snap", &snapst), IsNil)
+	c.Check(snapst.TrackingChannel, Equals, "1.0/candidate")
+
+	// a manual refresh reports the closed track
+	_, err = snapstate.Update(s.state, "closed-track-snap", nil, 0, snapstate.Flags{})
+	c.Check(err, ErrorMatches, `track "1.0" of snap "closed-track-snap" is closed`)
+}
+
+func (s *snapmgrTestSuite) TestAutoRefreshClosedTrackSwitch(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	tr := config.NewTransaction(s.state)
+	tr.Set("core", "refresh.closed-track", "switch")
+	tr.Commit()
+
+	s.setupClosedTrackSnaps()
+
+	names, _, err := snapstate.AutoRefresh(context.Background(), s.state)
+	c.Assert(err, IsNil)
+	c.Check(names, DeepEquals, []string{"some-snap"})
+
+	// the snap state is only updated by the change
+	var snapst snapstate.SnapState
+	c.Assert(snapstate.Get(s.state, "closed-track-snap", &snapst), IsNil)
+	c.Check(snapst.TrackingChannel, Equals, "1.0/candidate")
+
+	chgs := s.state.Changes()
+	c.Assert(chgs, HasLen, 1)
+	chg := chgs[0]
+	c.Check(chg.Kind(), Equals, "switch-snap-channel")
+	c.Check(chg.Summary(), Equals, `Switch snap "closed-track-snap" from closed track "1.0" to "latest/candidate"`)
+	var snapNames []string
+	c.Assert(chg.Get("snap-names", &snapNames), IsNil)
+	c.Check(snapNames, DeepEquals, []string{"closed-track-snap"})
+	tasks := chg.Tasks()
+	c.Assert(tasks, HasLen, 1)
+	c.Check(tasks[0].Kind(), Equals, "switch-snap")
+
+	warnings := s.state.AllWarnings()
+	c.Assert(warnings, HasLen, 1)
+	c.Check(warnings[0].String(), Equals, `snap "closed-track-snap" is being switched from the closed track "1.0" to "latest/candidate"`)
+
+	s.settle(c)
+
+	c.Check(chg.Status(), Equals, state.DoneStatus)
+	c.Assert(snapstate.Get(s.state, "closed-track-snap", &snapst), IsNil)
+	c.Check(snapst.TrackingChannel, Equals, "latest/candidate")
+}
+
+func (s *snapmgrTestSuite) TestAutoRefreshClosedTrackSwitchConflict(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	tr := config.NewTransaction(s.state)
+	tr.Set("core", "refresh.closed-track", "switch")
+	tr.Commit()
+
+	s.setupClosedTrackSnaps()
+
+	chg := s.state.NewChange("other", "...")
+	t := s.state.NewTask("link-snap", "...")
+	t.Set("snap-setup", &snapstate.SnapSetup{SideInfo: &snap.SideInfo{RealName: "closed-track-snap"}})
+	chg.AddTask(t)
+
+	names, _, err := snapstate.AutoRefresh(context.Background(), s.state)
+	c.Assert(err, IsNil)
+	c.Check(names, DeepEquals, []string{"some-snap"})
+
+	c.Check(s.state.Changes(), HasLen, 1)
+
+	// the snap is not switched but the closed track is reported
+	warnings := s.state.AllWarnings()
+	c.Assert(warnings, HasLen, 1)
+	c.Check(warnings[0].String(), Equals, `snap "closed-track-snap" is tracking the closed track "1.0" and will not be refreshed; use 'snap refresh --channel' to track another channel`)
+}
+
 func (s *snapmgrTestSuite) testBackoffOnAutoRefresh(c *C, afterReboot bool) {
 	s.state.Lock()
 	defer s.state.Unlock()
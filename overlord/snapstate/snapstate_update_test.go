@@ -725,7 +725,32 @@ func (s *snapmgrTestSuite) TestUpdateFailsEarlyOnEpochMismatch(c *C) {
 	})
 
 	_, err := snapstate.Update(s.state, "some-epoch-snap", nil, 0, snapstate.Flags{})
-	c.Assert(err, ErrorMatches, `cannot refresh "some-epoch-snap" to new revision 11 with epoch 42, because it can't read the current epoch of 13`)
+	c.Assert(err, ErrorMatches, `cannot refresh "some-epoch-snap" to new revision 11 with epoch 42, because it can't read the current epoch of 13, refresh to revision 7 from channel "latest/candidate" first`)
+
+	var gapErr *snapstate.EpochGapError
+	c.Assert(errors.As(err, &gapErr), Equals, true)
+	c.Check(gapErr.Hop.Revision, Equals, snap.R(7))
+}
+
+func (s *snapmgrTestSuite) TestUpdateManyEpochMismatchSuggestsHop(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	snapstate.Set(s.state, "some-epoch-snap", &snapstate.SnapState{
+		Active: true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "some-epoch-snap", SnapID: "some-epoch-snap-id", Revision: snap.R(1)},
+		}),
+		Current:  snap.R(1),
+		SnapType: "app",
+	})
+
+	_, _, err := snapstate.UpdateMany(context.Background(), s.state, []string{"some-epoch-snap"}, nil, s.user.ID, nil)
+	c.Assert(err, ErrorMatches, `cannot refresh "some-epoch-snap" to new revision 11 with epoch 42, because it can't read the current epoch of 13, refresh to revision 7 from channel "latest/candidate" first`)
+
+	var gapErr *snapstate.EpochGapError
+	c.Assert(errors.As(err, &gapErr), Equals, true)
+	c.Check(gapErr.Hop.Revision, Equals, snap.R(7))
 }
 
 func (s *snapmgrTestSuite) TestUpdateTasksPropagatesErrors(c *C) {
@@ -777,6 +802,65 @@ func (s *snapmgrTestSuite) TestUpdateTasks(c *C) {
 	c.Check(snapsup.Channel, Equals, "some-channel")
 }
 
+func (s *snapmgrTestSuite) TestUpdateTasksPreRefreshSnapshot(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	snapstate.Set(s.state, "some-snap", &snapstate.SnapState{
+		Active:          true,
+		TrackingChannel: "latest/edge",
+		Sequence:        snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{{RealName: "some-snap", SnapID: "some-snap-id", Revision: snap.R(7)}}),
+		Current:         snap.R(7),
+		SnapType:        "app",
+	})
+
+	var snapshotTask *state.Task
+	restore := snapstate.MockAutomaticPreRefreshSnapshot(func(st *state.State, instanceName string) (*state.TaskSet, error) {
+		c.Check(instanceName, Equals, "some-snap")
+		snapshotTask = st.NewTask("save-pre-refresh-snapshot", "...")
+		return state.NewTaskSet(snapshotTask), nil
+	})
+	defer restore()
+
+	ts, err := snapstate.Update(s.state, "some-snap", &snapstate.RevisionOptions{Channel: "some-channel"}, s.user.ID, snapstate.Flags{})
+	c.Assert(err, IsNil)
+	c.Assert(snapshotTask, NotNil)
+
+	kinds := taskKinds(ts.Tasks())
+	var idx int
+	for i, k := range kinds {
+		if k == "save-pre-refresh-snapshot" {
+			idx = i
+		}
+	}
+	c.Assert(idx, Not(Equals), 0)
+	c.Check(kinds[idx-1], Equals, "stop-snap-services")
+	c.Check(kinds[idx+1], Equals, "remove-aliases")
+	c.Check(snapshotTask.WaitTasks(), HasLen, 1)
+	c.Check(snapshotTask.WaitTasks()[0].Kind(), Equals, "stop-snap-services")
+}
+
+func (s *snapmgrTestSuite) TestUpdateTasksPreRefreshSnapshotError(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	snapstate.Set(s.state, "some-snap", &snapstate.SnapState{
+		Active:          true,
+		TrackingChannel: "latest/edge",
+		Sequence:        snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{{RealName: "some-snap", SnapID: "some-snap-id", Revision: snap.R(7)}}),
+		Current:         snap.R(7),
+		SnapType:        "app",
+	})
+
+	restore := snapstate.MockAutomaticPreRefreshSnapshot(func(st *state.State, instanceName string) (*state.TaskSet, error) {
+		return nil, fmt.Errorf("boom")
+	})
+	defer restore()
+
+	_, err := snapstate.Update(s.state, "some-snap", &snapstate.RevisionOptions{Channel: "some-channel"}, s.user.ID, snapstate.Flags{})
+	c.Assert(err, ErrorMatches, "boom")
+}
+
 func (s *snapmgrTestSuite) TestUpdateAmendRunThrough(c *C) {
 	const tryMode = false
 	s.testUpdateAmendRunThrough(c, tryMode, nil)
@@ -1629,6 +1713,7 @@ func (s *snapmgrTestSuite) testParallelInstanceUpdateRunThrough(c *C, refreshApp
 		macaroon: s.user.StoreMacaroon,
 		name:     "services-snap",
 		target:   filepath.Join(dirs.SnapBlobDir, "services-snap_instance_11.snap"),
+		opts:     &store.DownloadOptions{InstanceName: "services-snap_instance"},
 	}})
 	c.Check(s.fakeStore.seenPrivacyKeys["privacy-key"], Equals, true, Commentf("salts seen: %v", s.fakeStore.seenPrivacyKeys))
 	// start with an easier-to-read error if this fails:
@@ -5744,6 +5829,22 @@ func (s *snapmgrTestSuite) TestUpdateAllDevMode(c *C) {
 	c.Check(updates, HasLen, 0)
 }
 
+// firstTaskAfterDownload returns the task of the task-set that follows the
+// download and validation of the snap.
+func firstTaskAfterDownload(c *C, ts *state.TaskSet) *state.Task {
+	lastBefore, err := ts.Edge(snapstate.LastBeforeLocalModificationsEdge)
+	c.Assert(err, IsNil)
+	for _, t := range lastBefore.HaltTasks() {
+		for _, tsk := range ts.Tasks() {
+			if t == tsk {
+				return t
+			}
+		}
+	}
+	c.Fatalf("no task follows %q", lastBefore.Kind())
+	return nil
+}
+
 func taskSetsShareLane(tss ...*state.TaskSet) bool {
 	lanes := make(map[int]int)
 	for _, ts := range tss {
@@ -5815,17 +5916,17 @@ func (s *snapmgrTestSuite) TestUpdateManyWaitForBasesUC16(c *C) {
 	}
 
 	// Some-snap is expected to wait for both the essential snap, but
-	// also the base of some-snap. These dependencies are set up between
-	// last tasks of core/some-base, on preprequisites
+	// also the base of some-snap. The dependency on the essential snap is
+	// set up on prerequisites, the one on some-base on the first task
+	// after the download, which can run while some-base is updated
 	lastTaskOfCore, err := tts[0].Edge(snapstate.EndEdge)
 	c.Assert(err, IsNil)
 	lastTaskOfBase, err := tts[1].Edge(snapstate.EndEdge)
 	c.Assert(err, IsNil)
 	firstTaskOfSnap, err := tts[2].Edge(snapstate.BeginEdge)
 	c.Assert(err, IsNil)
-	c.Check(firstTaskOfSnap.WaitTasks(), HasLen, 2)
-	c.Check(firstTaskOfSnap.WaitTasks(), testutil.Contains, lastTaskOfCore)
-	c.Check(firstTaskOfSnap.WaitTasks(), testutil.Contains, lastTaskOfBase)
+	c.Check(firstTaskOfSnap.WaitTasks(), DeepEquals, []*state.Task{lastTaskOfCore})
+	c.Check(firstTaskAfterDownload(c, tts[2]).WaitTasks(), testutil.Contains, lastTaskOfBase)
 
 	// core and the other snaps are not expected to share the same lane
 	c.Check(taskSetsShareLane(tts[0], tts[1]), Equals, false)
@@ -5895,17 +5996,16 @@ func (s *snapmgrTestSuite) TestUpdateManyWaitForBasesUC18(c *C) {
 	}
 
 	// Some-app will be waiting for the bases, which includes both some-base and
-	// core18. The first task of some-snap will be waiting for the last tasks of
-	// those two dependencies.
+	// core18. The first task of some-snap will be waiting for the last task of
+	// core18, while some-snap is only mounted after some-base is updated.
 	lastTaskOfCore, err := tts[1].Edge(snapstate.EndEdge)
 	c.Assert(err, IsNil)
 	lastTaskOfBase, err := tts[2].Edge(snapstate.EndEdge)
 	c.Assert(err, IsNil)
 	firstTaskOfSnap, err := tts[3].Edge(snapstate.BeginEdge)
 	c.Assert(err, IsNil)
-	c.Check(firstTaskOfSnap.WaitTasks(), HasLen, 2)
-	c.Check(firstTaskOfSnap.WaitTasks(), testutil.Contains, lastTaskOfCore)
-	c.Check(firstTaskOfSnap.WaitTasks(), testutil.Contains, lastTaskOfBase)
+	c.Check(firstTaskOfSnap.WaitTasks(), DeepEquals, []*state.Task{lastTaskOfCore})
+	c.Check(firstTaskAfterDownload(c, tts[3]).WaitTasks(), testutil.Contains, lastTaskOfBase)
 
 	// Core18 and snapd are not expected to share the same lane, we only
 	// check essential snaps as those are the ones that can end up in same lane.
@@ -10448,11 +10548,16 @@ NextSnap3:
 			continue
 		}
 
-		firstTaskOfCurrent, err := currentTs.Edge(snapstate.BeginEdge)
-		c.Assert(err, IsNil)
 		lastTaskOfPrev, err := prevTs.Edge(snapstate.EndEdge)
 		c.Assert(err, IsNil)
-		c.Check(firstTaskOfCurrent.WaitTasks(), testutil.Contains, lastTaskOfPrev)
+		if prevTs == tsByName["some-base"] {
+			// the app is downloaded while its base is being updated
+			c.Check(firstTaskAfterDownload(c, currentTs).WaitTasks(), testutil.Contains, lastTaskOfPrev)
+		} else {
+			firstTaskOfCurrent, err := currentTs.Edge(snapstate.BeginEdge)
+			c.Assert(err, IsNil)
+			c.Check(firstTaskOfCurrent.WaitTasks(), testutil.Contains, lastTaskOfPrev)
+		}
 		prevTs = currentTs
 	}
 
@@ -14380,7 +14485,7 @@ func (s *snapmgrTestSuite) TestUpdateStateConflict(c *C) {
 
 	_, err := snapstate.Update(s.state, "some-snap", nil, 0, snapstate.Flags{})
 	c.Check(err, testutil.ErrorIs, &snapstate.ChangeConflictError{})
-	c.Assert(err, ErrorMatches, `snap "some-snap" has changes in progress`)
+	c.Assert(err, ErrorMatches, `snap "some-snap" was changed by another operation in the meantime`)
 }
 
 func (s *snapmgrTestSuite) TestUpdateStateConflictRemoved(c *C) {
@@ -14401,7 +14506,7 @@ func (s *snapmgrTestSuite) TestUpdateStateConflictRemoved(c *C) {
 
 	_, err := snapstate.Update(s.state, "some-snap", nil, 0, snapstate.Flags{})
 	c.Check(err, testutil.ErrorIs, &snapstate.ChangeConflictError{})
-	c.Assert(err, ErrorMatches, `snap "some-snap" has changes in progress`)
+	c.Assert(err, ErrorMatches, `snap "some-snap" was changed by another operation in the meantime`)
 }
 
 func (s *snapmgrTestSuite) TestUpdateBackToPrevRevision(c *C) {
@@ -16316,6 +16421,9 @@ func (s *snapmgrTestSuite) testUpdateWithComponentsRunThrough(c *C, opts updateW
 			name:     snapName,
 			target:   filepath.Join(dirs.SnapBlobDir, fmt.Sprintf("%s_%v.snap", instanceName, newSnapRev)),
 		}}
+		if opts.instanceKey != "" {
+			downloads[0].opts = &store.DownloadOptions{InstanceName: instanceName}
+		}
 	}
 	for _, compName := range opts.postRefreshComponents {
 		downloads = append(downloads, fakeDownload{
@@ -887,6 +887,7 @@ func sendActionsByUserID(ctx context.Context, st *state.State, actionsByUserID m
 
 	sto := Store(st, opts.DeviceCtx)
 
+	var closedTracks []*store.TrackClosedError
 	for u, actions := range actionsForUser {
 		st.Unlock()
 		perUserSars, _, err := sto.SnapAction(ctx, current, actions, nil, u, refreshOpts)
@@ -909,6 +910,10 @@ func sendActionsByUserID(ctx context.Context, st *state.State, actionsByUserID m
 					_, _, err := saErr.SingleOpError()
 					return nil, nil, err
 				}
+				var tcErr *store.TrackClosedError
+				if errors.As(e, &tcErr) && refreshOpts != nil && refreshOpts.Scheduled {
+					closedTracks = append(closedTracks, tcErr)
+				}
 
 				noUpdatesAvailable = append(noUpdatesAvailable, name)
 			}
@@ -919,6 +924,10 @@ func sendActionsByUserID(ctx context.Context, st *state.State, actionsByUserID m
 		sars = append(sars, perUserSars...)
 	}
 
+	if err := handleClosedTracks(st, closedTracks); err != nil {
+		return nil, nil, err
+	}
+
 	return sars, noUpdatesAvailable, nil
 }
 
@@ -29,6 +29,7 @@ import (
 	"github.com/snapcore/snapd/asserts/snapasserts"
 	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/snapstate/backend"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/snap"
@@ -121,7 +122,7 @@ func (t *target) setups(st *state.State, opts Options) (SnapSetup, []ComponentSe
 		compSideInfos = append(compSideInfos, *comp.CompSideInfo)
 	}
 
-	flags, err := earlyChecks(st, &t.snapst, t.info, compSideInfos, opts.Flags)
+	flags, err := earlyChecks(st, &t.snapst, t.info, compSideInfos, opts)
 	if err != nil {
 		return SnapSetup{}, nil, err
 	}
@@ -631,6 +632,13 @@ func (s *storeInstallGoal) validateAndPrune(st *state.State, installedSnaps map[
 			continue
 		}
 
+		// fail early, before asking the store about the snap
+		if _, instanceKey := snap.SplitInstanceName(sn.InstanceName); instanceKey != "" {
+			if err := checkParallelInstancesEnabled(config.NewTransaction(st)); err != nil {
+				return err
+			}
+		}
+
 		// only provide a default the channel if the revision is not set, since
 		// we don't want to prevent the user from installing a specific revision
 		// that doesn't happen to exist in the "stable" risk
@@ -1405,6 +1413,21 @@ func targetForPathSnap(update PathSnap, snapst SnapState, opts Options) (target,
 		}
 	}
 
+	if !opts.Seed {
+		if si.SnapID == "" {
+			if err := checkUnassertedSideloadAllowed("snap", update.InstanceName, opts.DeviceCtx); err != nil {
+				return target{}, err
+			}
+		}
+		for csi := range update.Components {
+			if csi.Revision.Unset() {
+				if err := checkUnassertedSideloadAllowed("component", csi.Component.String(), opts.DeviceCtx); err != nil {
+					return target{}, err
+				}
+			}
+		}
+	}
+
 	if err := snap.ValidateInstanceName(update.InstanceName); err != nil {
 		return target{}, fmt.Errorf("invalid instance name: %v", err)
 	}
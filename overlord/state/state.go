@@ -422,6 +422,20 @@ func (s *State) TaskCount() int {
 	return len(s.tasks)
 }
 
+// DiscardUnlinkedTasks removes the given tasks from the state unless they
+// were linked to a change. It is used to drop tasks that were created to
+// describe an operation which is then not carried out, instead of leaving
+// them to be pruned eventually.
+func (s *State) DiscardUnlinkedTasks(tasks []*Task) {
+	s.writing()
+	for _, t := range tasks {
+		if t.Change() != nil {
+			continue
+		}
+		delete(s.tasks, t.ID())
+	}
+}
+
 func (s *State) tasksIn(tids []string) []*Task {
 	res := make([]*Task, len(tids))
 	for i, tid := range tids {
@@ -120,6 +120,29 @@ func (ss *stateSuite) TestStrayTaskWithNoChange(c *C) {
 	c.Assert(st.TaskCount(), Equals, 2)
 }
 
+func (ss *stateSuite) TestDiscardUnlinkedTasks(c *C) {
+	st := state.New(nil)
+	st.Lock()
+	defer st.Unlock()
+
+	chg := st.NewChange("change", "...")
+	t1 := st.NewTask("foo", "...")
+	chg.AddTask(t1)
+	t2 := st.NewTask("bar", "...")
+	t3 := st.NewTask("baz", "...")
+	c.Assert(st.TaskCount(), Equals, 3)
+
+	st.DiscardUnlinkedTasks([]*state.Task{t1, t2})
+
+	// only the unlinked task was removed
+	c.Check(st.TaskCount(), Equals, 2)
+	c.Check(st.Task(t1.ID()), Equals, t1)
+	c.Check(chg.Tasks(), DeepEquals, []*state.Task{t1})
+
+	st.DiscardUnlinkedTasks([]*state.Task{t3})
+	c.Check(st.TaskCount(), Equals, 1)
+}
+
 func (ss *stateSuite) TestSetPanic(c *C) {
 	st := state.New(nil)
 	st.Lock()
@@ -759,6 +782,7 @@ func (ss *stateSuite) TestMethodEntrance(c *C) {
 		func() { st.Warnf("hello") },
 		func() { st.OkayWarnings(time.Time{}) },
 		func() { st.UnshowAllWarnings() },
+		func() { st.DiscardUnlinkedTasks(nil) },
 	}
 
 	reads := []func(){
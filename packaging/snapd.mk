@@ -26,6 +26,9 @@ vars += bindir sbindir libexecdir mandir datadir localstatedir sharedstatedir un
 #   with_core_bits: set to 1 to build snapd with things needed for the core/snapd snap
 #   with_alt_snap_mount_dir: set to 1 to build snapd with alternate snap mount directory
 vars += with_testkeys with_apparmor with_core_bits with_alt_snap_mount_dir
+# 3) optional build options:
+#   with_container_profile: set to 1 to run snapd purely as a package manager
+#   inside containers, see snapdenv.ContainerProfile
 # Verify that none of the variables are empty. This may happen if snapd.mk and
 # distribution packaging generating snapd.defines.mk get out of sync.
 
@@ -197,6 +200,13 @@ install::
 	rm -f $(DESTDIR)$(libexecdir)/snapd/snapd-apparmor
 endif
 
+ifeq ($(with_container_profile),1)
+# Run snapd with the profile for containers.
+install::
+	install -m 755 -d $(DESTDIR)$(unitdir)/snapd.service.d
+	printf '[Service]\nEnvironment=SNAPD_PROFILE=container\n' > $(DESTDIR)$(unitdir)/snapd.service.d/container-profile.conf
+endif
+
 # Tests use C.UTF-8 because some some code depend on this for fancy Unicode
 # output that unit tests do not mock.
 .PHONY: check
@@ -43,7 +43,34 @@ func MockFileExists(mockFileExists func(string) bool) (restorer func()) {
 	}
 }
 
+func MockSystemdContainerPath(path string) (restore func()) {
+	old := systemdContainerPath
+	systemdContainerPath = path
+	return func() {
+		systemdContainerPath = old
+	}
+}
+
+func MockSelfUIDMapPath(path string) (restore func()) {
+	old := selfUIDMapPath
+	selfUIDMapPath = path
+	return func() {
+		selfUIDMapPath = old
+	}
+}
+
+func MockIsWritable(f func(path string) bool) (restore func()) {
+	old := isWritable
+	isWritable = f
+	return func() {
+		isWritable = old
+	}
+}
+
 var (
+	CgroupDelegated    = cgroupDelegated
+	GetContainerType   = getContainerType
+	InUserNamespace    = inUserNamespace
 	GetWSLVersion      = getWSLVersion
 	FilesystemRootType = filesystemRootType
 	ProcMountsPath     = &procMountsPath
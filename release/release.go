@@ -26,6 +26,8 @@ import (
 	"strings"
 	"unicode"
 
+	"golang.org/x/sys/unix"
+
 	"github.com/snapcore/snapd/strutil"
 )
 
@@ -171,6 +173,68 @@ func getWSLVersion() int {
 	return 2
 }
 
+var systemdContainerPath = "/run/systemd/container"
+
+// getContainerType returns the kind of container the process is running in,
+// or an empty string when not running inside a container.
+//
+// systemd records the container manager it was started by (e.g. "lxc") in
+// /run/systemd/container. Application containers usually do not run
+// systemd, so for those we look for the marker files left by Docker and
+// Podman.
+func getContainerType() string {
+	if data, err := os.ReadFile(systemdContainerPath); err == nil {
+		if containerType := strings.TrimSpace(string(data)); containerType != "" {
+			return containerType
+		}
+	}
+	if fileExists("/.dockerenv") {
+		return "docker"
+	}
+	if fileExists("/run/.containerenv") {
+		return "podman"
+	}
+	return ""
+}
+
+var selfUIDMapPath = "/proc/self/uid_map"
+
+// inUserNamespace returns whether the process runs in a user namespace other
+// than the initial one, as unprivileged containers do. The initial user
+// namespace maps the whole range of user IDs onto itself.
+func inUserNamespace() bool {
+	data, err := os.ReadFile(selfUIDMapPath)
+	if err != nil {
+		return false
+	}
+	fields := strings.Fields(string(data))
+	return !(len(fields) == 3 && fields[0] == "0" && fields[1] == "0" && fields[2] == "4294967295")
+}
+
+var cgroupProcsPaths = []string{
+	// the unified hierarchy
+	"/sys/fs/cgroup/cgroup.procs",
+	// the hierarchy systemd uses with cgroup v1
+	"/sys/fs/cgroup/systemd/cgroup.procs",
+}
+
+var isWritable = func(path string) bool {
+	return unix.Access(path, unix.W_OK) == nil
+}
+
+// cgroupDelegated returns whether the cgroup hierarchy seen by the process
+// was delegated to it, so that processes can be moved into new cgroups, e.g.
+// the transient scopes tracking snap applications. LXD delegates the
+// hierarchy to its containers, while Docker mounts it read-only by default.
+func cgroupDelegated() bool {
+	for _, path := range cgroupProcsPaths {
+		if fileExists(path) {
+			return isWritable(path)
+		}
+	}
+	return false
+}
+
 // SystemctlSupportsUserUnits returns true if the systemctl utility
 // supports user units.
 func SystemctlSupportsUserUnits() bool {
@@ -195,6 +259,24 @@ var OnWSL bool
 // Otherwise it is set to 0
 var WSLVersion int
 
+// OnContainer states whether the process is running inside a container
+// such as LXD or Docker.
+var OnContainer bool
+
+// If the previous is true, ContainerType names the container manager,
+// otherwise it is empty.
+var ContainerType string
+
+// OnUnprivilegedContainer states whether the process is running inside a
+// container that uses a user namespace, and so is not allowed to act on the
+// devices of the host.
+var OnUnprivilegedContainer bool
+
+// If OnContainer is true, ContainerCgroupDelegated states whether the
+// cgroup hierarchy of the container was delegated to it, which systemd needs
+// to move processes into new cgroups.
+var ContainerCgroupDelegated bool
+
 // ReleaseInfo contains data loaded from /etc/os-release on startup.
 var ReleaseInfo OS
 
@@ -207,6 +289,11 @@ func init() {
 
 	WSLVersion = getWSLVersion()
 	OnWSL = WSLVersion != 0
+
+	ContainerType = getContainerType()
+	OnContainer = ContainerType != ""
+	OnUnprivilegedContainer = OnContainer && inUserNamespace()
+	ContainerCgroupDelegated = OnContainer && cgroupDelegated()
 }
 
 // MockOnClassic forces the process to appear inside a classic
@@ -225,6 +312,36 @@ func MockOnCoreDesktop(onCoreDesktop bool) (restore func()) {
 	return func() { OnCoreDesktop = old }
 }
 
+// MockOnContainer forces the process to appear inside a container of the
+// given type, or outside of any container if the type is empty, for testing
+// purposes. The container is unprivileged if requested, and has its cgroup
+// hierarchy delegated.
+func MockOnContainer(containerType string, unprivileged bool) (restore func()) {
+	oldOnContainer := OnContainer
+	oldContainerType := ContainerType
+	oldOnUnprivilegedContainer := OnUnprivilegedContainer
+	oldContainerCgroupDelegated := ContainerCgroupDelegated
+	OnContainer = containerType != ""
+	ContainerType = containerType
+	OnUnprivilegedContainer = OnContainer && unprivileged
+	ContainerCgroupDelegated = OnContainer
+	return func() {
+		OnContainer = oldOnContainer
+		ContainerType = oldContainerType
+		OnUnprivilegedContainer = oldOnUnprivilegedContainer
+		ContainerCgroupDelegated = oldContainerCgroupDelegated
+	}
+}
+
+// MockContainerCgroupDelegated forces the cgroup hierarchy of the container
+// the process appears to run in to be delegated to it or not, for testing
+// purposes.
+func MockContainerCgroupDelegated(delegated bool) (restore func()) {
+	old := ContainerCgroupDelegated
+	ContainerCgroupDelegated = OnContainer && delegated
+	return func() { ContainerCgroupDelegated = old }
+}
+
 // MockReleaseInfo fakes a given information to appear in ReleaseInfo,
 // as if it was read /etc/os-release on startup.
 func MockReleaseInfo(osRelease *OS) (restore func()) {
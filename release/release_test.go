@@ -29,6 +29,7 @@ import (
 
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/release"
+	"github.com/snapcore/snapd/strutil"
 	"github.com/snapcore/snapd/testutil"
 )
 
@@ -259,6 +260,113 @@ func (s *ReleaseTestSuite) TestLXDInWSL2(c *C) {
 	c.Check(v, Equals, 2)
 }
 
+func (s *ReleaseTestSuite) TestContainerType(c *C) {
+	containerPath := filepath.Join(c.MkDir(), "container")
+	defer release.MockSystemdContainerPath(containerPath)()
+
+	var existing []string
+	defer release.MockFileExists(func(path string) bool {
+		return strutil.ListContains(existing, path)
+	})()
+
+	// not in a container
+	c.Check(release.GetContainerType(), Equals, "")
+
+	// marker files of application containers
+	existing = []string{"/run/.containerenv"}
+	c.Check(release.GetContainerType(), Equals, "podman")
+	existing = []string{"/.dockerenv"}
+	c.Check(release.GetContainerType(), Equals, "docker")
+
+	// the container manager recorded by systemd takes precedence
+	c.Assert(os.WriteFile(containerPath, []byte("lxc\n"), 0644), IsNil)
+	c.Check(release.GetContainerType(), Equals, "lxc")
+
+	// but is ignored when empty
+	c.Assert(os.WriteFile(containerPath, []byte("\n"), 0644), IsNil)
+	c.Check(release.GetContainerType(), Equals, "docker")
+}
+
+func (s *ReleaseTestSuite) TestInUserNamespace(c *C) {
+	uidMapPath := filepath.Join(c.MkDir(), "uid_map")
+	defer release.MockSelfUIDMapPath(uidMapPath)()
+
+	// cannot tell
+	c.Check(release.InUserNamespace(), Equals, false)
+
+	// the initial user namespace
+	c.Assert(os.WriteFile(uidMapPath, []byte("         0          0 4294967295\n"), 0644), IsNil)
+	c.Check(release.InUserNamespace(), Equals, false)
+
+	// an unprivileged container
+	c.Assert(os.WriteFile(uidMapPath, []byte("         0    1000000 1000000000\n"), 0644), IsNil)
+	c.Check(release.InUserNamespace(), Equals, true)
+}
+
+func (s *ReleaseTestSuite) TestCgroupDelegated(c *C) {
+	var existing, writable []string
+	defer release.MockFileExists(func(path string) bool {
+		return strutil.ListContains(existing, path)
+	})()
+	defer release.MockIsWritable(func(path string) bool {
+		return strutil.ListContains(writable, path)
+	})()
+
+	// no cgroup hierarchy at all
+	c.Check(release.CgroupDelegated(), Equals, false)
+
+	// a read-only unified hierarchy, as in Docker
+	existing = []string{"/sys/fs/cgroup/cgroup.procs"}
+	c.Check(release.CgroupDelegated(), Equals, false)
+
+	// a delegated unified hierarchy, as in LXD
+	writable = []string{"/sys/fs/cgroup/cgroup.procs"}
+	c.Check(release.CgroupDelegated(), Equals, true)
+
+	// the systemd hierarchy with cgroup v1
+	existing = []string{"/sys/fs/cgroup/systemd/cgroup.procs"}
+	c.Check(release.CgroupDelegated(), Equals, false)
+	writable = []string{"/sys/fs/cgroup/systemd/cgroup.procs"}
+	c.Check(release.CgroupDelegated(), Equals, true)
+}
+
+func (s *ReleaseTestSuite) TestMockOnContainer(c *C) {
+	restore := release.MockOnContainer("lxc", true)
+	c.Check(release.OnContainer, Equals, true)
+	c.Check(release.ContainerType, Equals, "lxc")
+	c.Check(release.OnUnprivilegedContainer, Equals, true)
+	c.Check(release.ContainerCgroupDelegated, Equals, true)
+	restore()
+
+	restore = release.MockOnContainer("docker", false)
+	c.Check(release.OnContainer, Equals, true)
+	c.Check(release.ContainerType, Equals, "docker")
+	c.Check(release.OnUnprivilegedContainer, Equals, false)
+	restore()
+
+	restore = release.MockOnContainer("", true)
+	defer restore()
+	c.Check(release.OnContainer, Equals, false)
+	c.Check(release.ContainerType, Equals, "")
+	c.Check(release.OnUnprivilegedContainer, Equals, false)
+	c.Check(release.ContainerCgroupDelegated, Equals, false)
+}
+
+func (s *ReleaseTestSuite) TestMockContainerCgroupDelegated(c *C) {
+	defer release.MockOnContainer("docker", false)()
+	c.Check(release.ContainerCgroupDelegated, Equals, true)
+
+	restore := release.MockContainerCgroupDelegated(false)
+	c.Check(release.ContainerCgroupDelegated, Equals, false)
+	restore()
+	c.Check(release.ContainerCgroupDelegated, Equals, true)
+
+	// never delegated outside of containers
+	defer release.MockOnContainer("", false)()
+	defer release.MockContainerCgroupDelegated(true)()
+	c.Check(release.ContainerCgroupDelegated, Equals, false)
+}
+
 func (s *ReleaseTestSuite) TestSystemctlSupportsUserUnits(c *C) {
 	for _, t := range []struct {
 		id, versionID string
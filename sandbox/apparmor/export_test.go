@@ -22,6 +22,7 @@ package apparmor
 import (
 	"io"
 	"os"
+	"time"
 
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/testutil"
@@ -102,3 +103,7 @@ var (
 func FreshAppArmorAssessment() {
 	appArmorAssessment = &appArmorAssess{appArmorProber: &appArmorProbe{}}
 }
+
+func MockHashCacheMaxAge(age time.Duration) (restore func()) {
+	return testutil.Mock(&hashCacheMaxAge, age)
+}
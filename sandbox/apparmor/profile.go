@@ -52,6 +52,12 @@ const (
 	// SkipKernelLoad tells apparmor_parser not to load profiles into the kernel. The use
 	// case of this is when in pre-seeding mode.
 	SkipKernelLoad AaParserFlags = 1 << iota
+
+	// HashCache makes snapd compile profiles itself, in parallel, into a cache
+	// of binary policies keyed by the hash of the profiles and of everything
+	// affecting their compilation, so that profiles which did not change are
+	// not recompiled. The apparmor_parser cache is not used then.
+	HashCache AaParserFlags = 1 << iota
 )
 
 var (
@@ -90,6 +96,10 @@ capability bpf,
 `
 
 func numberOfJobsParam() string {
+	return fmt.Sprintf("-j%d", numberOfJobs())
+}
+
+func numberOfJobs() int {
 	cpus := runtimeNumCPU()
 	// Do not use all CPUs as this may have negative impact when booting.
 	if cpus > 2 {
@@ -106,7 +116,7 @@ func numberOfJobsParam() string {
 		cpus = 1
 	}
 
-	return fmt.Sprintf("-j%d", cpus)
+	return cpus
 }
 
 // LoadProfiles loads apparmor profiles from the given files.
@@ -118,6 +128,10 @@ var LoadProfiles = func(fnames []string, cacheDir string, flags AaParserFlags) e
 		return nil
 	}
 
+	if flags&HashCache != 0 {
+		return loadProfilesWithHashCache(fnames, cacheDir, flags)
+	}
+
 	args := []string{"--replace", "--write-cache", fmt.Sprintf("--cache-loc=%s", cacheDir)}
 	if flags&ConserveCPU != 0 {
 		args = append(args, numberOfJobsParam())
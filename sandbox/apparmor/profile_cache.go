@@ -0,0 +1,383 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package apparmor
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/osutil"
+)
+
+// hashCacheDirName is the name of the directory, inside the apparmor cache
+// directory, holding the binary policies compiled by snapd.
+const hashCacheDirName = "snapd-hash"
+
+// hashCacheMaxAge is the age after which unused entries of the hash cache
+// are removed.
+var hashCacheMaxAge = 30 * 24 * time.Hour
+
+// parserBaseDir returns the directory where the given parser command looks
+// up the files included with the <path> form.
+func parserBaseDir(parserArgs []string, internal bool) string {
+	baseDir := ConfDir
+	if internal {
+		for i, arg := range parserArgs {
+			if arg == "--base" && i+1 < len(parserArgs) {
+				baseDir = parserArgs[i+1]
+			}
+		}
+	}
+	return baseDir
+}
+
+// compilationKey returns a hash of everything, besides the profile text and
+// the files it includes, that affects the binary policy produced by the
+// given parser command: its arguments and mtime, the kernel and parser
+// features and the tunables.
+func compilationKey(parserArgs []string, baseDir string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "args:%q\n", parserArgs)
+	fmt.Fprintf(h, "parser-mtime:%d\n", ParserMtime())
+	// errors are reflected by empty feature lists
+	kernelFeatures, _ := KernelFeatures()
+	fmt.Fprintf(h, "kernel-features:%q\n", kernelFeatures)
+	parserFeatures, _ := ParserFeatures()
+	fmt.Fprintf(h, "parser-features:%q\n", parserFeatures)
+	hashTree(h, filepath.Join(baseDir, "tunables"))
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// includeRe matches the include directives of profiles, in both the
+// "#include" and the "include" forms, possibly conditional.
+var includeRe = regexp.MustCompile(`^\s*#?include\s+(?:if\s+exists\s+)?(<[^>]+>|"[^"]+")`)
+
+// includeHasher hashes the files included by profiles, directly or through
+// other included files. The includes and digests of the files are cached as
+// profiles share most of them, e.g. the abstractions.
+type includeHasher struct {
+	baseDir  string
+	includes map[string][]string
+	digests  map[string]string
+}
+
+func newIncludeHasher(baseDir string) *includeHasher {
+	return &includeHasher{
+		baseDir:  baseDir,
+		includes: make(map[string][]string),
+		digests:  make(map[string]string),
+	}
+}
+
+// hashIncludes adds the names and contents of the files transitively
+// included by the given profile to h.
+func (ih *includeHasher) hashIncludes(h hash.Hash, fname string) {
+	seen := map[string]bool{fname: true}
+	queue := []string{fname}
+	var included []string
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, inc := range ih.directIncludes(cur) {
+			if seen[inc] {
+				continue
+			}
+			seen[inc] = true
+			included = append(included, inc)
+			queue = append(queue, inc)
+		}
+	}
+	sort.Strings(included)
+	for _, path := range included {
+		fmt.Fprintf(h, "include:%s:%s\n", path, ih.digest(path))
+	}
+}
+
+// directIncludes returns the files included by the given file, an included
+// directory standing for all the files it contains.
+func (ih *includeHasher) directIncludes(fname string) []string {
+	if incs, ok := ih.includes[fname]; ok {
+		return incs
+	}
+	var incs []string
+	if f, err := os.Open(fname); err == nil {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			m := includeRe.FindStringSubmatch(scanner.Text())
+			if m == nil {
+				continue
+			}
+			incs = append(incs, ih.resolve(m[1])...)
+		}
+		f.Close()
+	}
+	ih.includes[fname] = incs
+	return incs
+}
+
+// resolve returns the files referenced by the <path> or "path" argument of
+// an include directive.
+func (ih *includeHasher) resolve(ref string) []string {
+	path := ref[1 : len(ref)-1]
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(ih.baseDir, path)
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		// a file, or a missing one
+		return []string{path}
+	}
+	var paths []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			paths = append(paths, filepath.Join(path, entry.Name()))
+		}
+	}
+	return paths
+}
+
+// digest returns the digest of the content of the given file, or an empty
+// string if it cannot be read.
+func (ih *includeHasher) digest(fname string) string {
+	if d, ok := ih.digests[fname]; ok {
+		return d
+	}
+	var d string
+	if content, err := os.ReadFile(fname); err == nil {
+		sum := sha256.Sum256(content)
+		d = hex.EncodeToString(sum[:])
+	}
+	ih.digests[fname] = d
+	return d
+}
+
+// hashTree adds the names and contents of the regular files found in dir
+// to h. Unreadable files only contribute their names.
+func hashTree(h hash.Hash, dir string) {
+	var paths []string
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode().IsRegular() {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Fprintf(h, "file:%s\n", path)
+		if f, err := os.Open(path); err == nil {
+			io.Copy(h, f)
+			f.Close()
+		}
+	}
+}
+
+// profileCacheKey returns the name of the cache entry holding the binary
+// policy of the given profile.
+func profileCacheKey(compilationKey, fname string, ih *includeHasher) (string, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n", compilationKey)
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	ih.hashIncludes(h, fname)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// loadProfilesWithHashCache compiles the profiles which are not yet in the
+// hash cache in parallel and then loads the binary policies of all the
+// given profiles into the kernel.
+func loadProfilesWithHashCache(fnames []string, cacheDir string, flags AaParserFlags) error {
+	cmd, internal, err := AppArmorParser()
+	if err != nil {
+		return err
+	}
+	parserPath := cmd.Path
+	baseArgs := cmd.Args[1:]
+	quiet := !osutil.GetenvBool("SNAPD_DEBUG")
+
+	hashDir := filepath.Join(cacheDir, hashCacheDirName)
+	if err := os.MkdirAll(hashDir, 0755); err != nil {
+		return fmt.Errorf("cannot create apparmor hash cache directory: %v", err)
+	}
+
+	baseDir := parserBaseDir(baseArgs, internal)
+	key := compilationKey(baseArgs, baseDir)
+	ih := newIncludeHasher(baseDir)
+	now := time.Now()
+	binaries := make([]string, 0, len(fnames))
+	toCompile := make(map[string]string)
+	for _, fname := range fnames {
+		entry, err := profileCacheKey(key, fname, ih)
+		if err != nil {
+			return fmt.Errorf("cannot load apparmor profiles: %v", err)
+		}
+		binary := filepath.Join(hashDir, entry)
+		binaries = append(binaries, binary)
+		if osutil.FileExists(binary) {
+			// mark the entry as recently used
+			os.Chtimes(binary, now, now)
+			continue
+		}
+		toCompile[binary] = fname
+	}
+
+	if err := compileProfiles(parserPath, baseArgs, toCompile, flags, quiet); err != nil {
+		return err
+	}
+
+	if flags&SkipKernelLoad == 0 {
+		args := append([]string{}, baseArgs...)
+		args = append(args, "--replace", "--binary")
+		if quiet {
+			args = append(args, "--quiet")
+		}
+		args = append(args, binaries...)
+		if err := runParser(parserPath, args); err != nil {
+			return fmt.Errorf("cannot load apparmor profiles: %v", err)
+		}
+	}
+
+	pruneHashCache(hashDir, now)
+	return nil
+}
+
+// compileProfiles compiles the profiles in toCompile, a map of cache entry
+// paths to profile paths, using a pool of parser processes.
+func compileProfiles(parserPath string, baseArgs []string, toCompile map[string]string, flags AaParserFlags, quiet bool) error {
+	if len(toCompile) == 0 {
+		return nil
+	}
+
+	workers := runtimeNumCPU()
+	if flags&ConserveCPU != 0 {
+		workers = numberOfJobs()
+	}
+	if workers > len(toCompile) {
+		workers = len(toCompile)
+	}
+
+	binaries := make([]string, 0, len(toCompile))
+	for binary := range toCompile {
+		binaries = append(binaries, binary)
+	}
+	sort.Strings(binaries)
+
+	jobs := make(chan string)
+	errs := make(chan error, len(binaries))
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for binary := range jobs {
+				errs <- compileProfile(parserPath, baseArgs, toCompile[binary], binary, quiet)
+			}
+		}()
+	}
+	for _, binary := range binaries {
+		jobs <- binary
+	}
+	close(jobs)
+	wg.Wait()
+	close(errs)
+
+	var msgs []string
+	for err := range errs {
+		if err != nil {
+			msgs = append(msgs, err.Error())
+		}
+	}
+	if len(msgs) > 0 {
+		return fmt.Errorf("cannot compile apparmor profiles: %s", strings.Join(msgs, "\n"))
+	}
+	return nil
+}
+
+func compileProfile(parserPath string, baseArgs []string, fname, binary string, quiet bool) error {
+	tmp := binary + ".tmp"
+	args := append([]string{}, baseArgs...)
+	args = append(args, "--skip-kernel-load", "--ofile="+tmp)
+	if quiet {
+		args = append(args, "--quiet")
+	}
+	args = append(args, fname)
+	if err := runParser(parserPath, args); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("%s: %v", fname, err)
+	}
+	if err := os.Rename(tmp, binary); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("%s: %v", fname, err)
+	}
+	return nil
+}
+
+func runParser(parserPath string, args []string) error {
+	cmd := exec.Command(parserPath, args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil || strings.Contains(string(output), "parser error") {
+		if err == nil {
+			// ensure we have an error to report
+			err = fmt.Errorf("exit status 0 with parser error")
+		}
+		return fmt.Errorf("%s\napparmor_parser output:\n%s", err, string(output))
+	}
+	return nil
+}
+
+// pruneHashCache removes the entries of the hash cache which were not used
+// for longer than hashCacheMaxAge.
+func pruneHashCache(hashDir string, now time.Time) {
+	entries, err := os.ReadDir(hashDir)
+	if err != nil {
+		logger.Noticef("cannot prune apparmor hash cache: %v", err)
+		return
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || info.IsDir() {
+			continue
+		}
+		if now.Sub(info.ModTime()) > hashCacheMaxAge {
+			if err := os.Remove(filepath.Join(hashDir, entry.Name())); err != nil {
+				logger.Noticef("cannot remove stale apparmor hash cache entry: %v", err)
+			}
+		}
+	}
+}
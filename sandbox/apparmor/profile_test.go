@@ -26,6 +26,7 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	. "gopkg.in/check.v1"
 
@@ -125,6 +126,226 @@ parser error
 	})
 }
 
+const hashCacheParserScript = `
+for arg in "$@"; do
+	case "$arg" in
+		--ofile=*)
+			echo compiled > "${arg#--ofile=}"
+			;;
+	esac
+done
+`
+
+func (s *appArmorSuite) TestLoadProfilesHashCache(c *C) {
+	dirs.SetRootDir(c.MkDir())
+	defer dirs.SetRootDir("")
+	restore := apparmor.MockFeatures(nil, nil, nil, nil)
+	defer restore()
+	restore = apparmor.MockRuntimeNumCPU(func() int { return 1 })
+	defer restore()
+	cmd := testutil.MockCommand(c, "apparmor_parser", hashCacheParserScript)
+	defer cmd.Restore()
+	restore = apparmor.MockParserSearchPath(cmd.BinDir())
+	defer restore()
+
+	profilesDir := c.MkDir()
+	smbd := filepath.Join(profilesDir, "snap.samba.smbd")
+	nmbd := filepath.Join(profilesDir, "snap.samba.nmbd")
+	c.Assert(os.WriteFile(smbd, []byte("smbd profile"), 0644), IsNil)
+	c.Assert(os.WriteFile(nmbd, []byte("nmbd profile"), 0644), IsNil)
+
+	hashDir := filepath.Join(apparmor.CacheDir, "snapd-hash")
+	cacheEntries := func() []string {
+		entries, err := filepath.Glob(filepath.Join(hashDir, "*"))
+		c.Assert(err, IsNil)
+		return entries
+	}
+
+	// both profiles are compiled on first load
+	err := apparmor.LoadProfiles([]string{smbd, nmbd}, apparmor.CacheDir, apparmor.HashCache)
+	c.Assert(err, IsNil)
+	entries := cacheEntries()
+	c.Assert(entries, HasLen, 2)
+	calls := cmd.Calls()
+	c.Assert(calls, HasLen, 3)
+	for _, call := range calls[:2] {
+		c.Check(call[:2], DeepEquals, []string{"apparmor_parser", "--skip-kernel-load"})
+		c.Check(strings.HasPrefix(call[2], "--ofile="+hashDir+"/"), Equals, true)
+		c.Check(strings.HasSuffix(call[2], ".tmp"), Equals, true)
+		c.Check(call[3], Equals, "--quiet")
+	}
+	c.Check(calls[2][:4], DeepEquals, []string{"apparmor_parser", "--replace", "--binary", "--quiet"})
+	c.Check(calls[2][4:], HasLen, 2)
+	for _, binary := range calls[2][4:] {
+		c.Check(binary, testutil.FileEquals, "compiled\n")
+	}
+	smbdBinary := calls[2][4]
+	cmd.ForgetCalls()
+
+	// nothing is compiled when loading the same profiles again
+	err = apparmor.LoadProfiles([]string{smbd, nmbd}, apparmor.CacheDir, apparmor.HashCache)
+	c.Assert(err, IsNil)
+	c.Check(cacheEntries(), DeepEquals, entries)
+	c.Check(cmd.Calls(), DeepEquals, [][]string{
+		append([]string{"apparmor_parser", "--replace", "--binary", "--quiet"}, calls[2][4:]...),
+	})
+	cmd.ForgetCalls()
+
+	// only the changed profile is compiled
+	c.Assert(os.WriteFile(nmbd, []byte("new nmbd profile"), 0644), IsNil)
+	err = apparmor.LoadProfiles([]string{smbd, nmbd}, apparmor.CacheDir, apparmor.HashCache)
+	c.Assert(err, IsNil)
+	c.Check(cacheEntries(), HasLen, 3)
+	calls = cmd.Calls()
+	c.Assert(calls, HasLen, 2)
+	c.Check(calls[0][len(calls[0])-1], Equals, nmbd)
+	c.Check(calls[1][:4], DeepEquals, []string{"apparmor_parser", "--replace", "--binary", "--quiet"})
+	c.Check(calls[1][4], Equals, smbdBinary)
+}
+
+func (s *appArmorSuite) TestLoadProfilesHashCacheFeaturesChange(c *C) {
+	dirs.SetRootDir(c.MkDir())
+	defer dirs.SetRootDir("")
+	restore := apparmor.MockFeatures([]string{"network"}, nil, nil, nil)
+	defer restore()
+	cmd := testutil.MockCommand(c, "apparmor_parser", hashCacheParserScript)
+	defer cmd.Restore()
+	restore = apparmor.MockParserSearchPath(cmd.BinDir())
+	defer restore()
+
+	profile := filepath.Join(c.MkDir(), "snap.samba.smbd")
+	c.Assert(os.WriteFile(profile, []byte("smbd profile"), 0644), IsNil)
+
+	err := apparmor.LoadProfiles([]string{profile}, apparmor.CacheDir, apparmor.HashCache|apparmor.SkipKernelLoad)
+	c.Assert(err, IsNil)
+	c.Check(cmd.Calls(), HasLen, 1)
+	cmd.ForgetCalls()
+
+	// different kernel features invalidate the cached policy
+	restore = apparmor.MockFeatures([]string{"network", "policy"}, nil, nil, nil)
+	defer restore()
+	err = apparmor.LoadProfiles([]string{profile}, apparmor.CacheDir, apparmor.HashCache|apparmor.SkipKernelLoad)
+	c.Assert(err, IsNil)
+	calls := cmd.Calls()
+	c.Assert(calls, HasLen, 1)
+	c.Check(calls[0][1], Equals, "--skip-kernel-load")
+	entries, err := filepath.Glob(filepath.Join(apparmor.CacheDir, "snapd-hash", "*"))
+	c.Assert(err, IsNil)
+	c.Check(entries, HasLen, 2)
+}
+
+func (s *appArmorSuite) TestLoadProfilesHashCacheIncludesChange(c *C) {
+	dirs.SetRootDir(c.MkDir())
+	defer dirs.SetRootDir("")
+	restore := apparmor.MockFeatures(nil, nil, nil, nil)
+	defer restore()
+	cmd := testutil.MockCommand(c, "apparmor_parser", hashCacheParserScript)
+	defer cmd.Restore()
+	restore = apparmor.MockParserSearchPath(cmd.BinDir())
+	defer restore()
+
+	abstractions := filepath.Join(apparmor.ConfDir, "abstractions")
+	c.Assert(os.MkdirAll(filepath.Join(abstractions, "base.d"), 0755), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(abstractions, "base"), []byte(`
+  #include <abstractions/base.d>
+  include if exists <local/smbd>
+`), 0644), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(abstractions, "base.d", "extra"), []byte("extra rules"), 0644), IsNil)
+
+	profilesDir := c.MkDir()
+	snippet := filepath.Join(profilesDir, "snippet")
+	c.Assert(os.WriteFile(snippet, []byte("snippet rules"), 0644), IsNil)
+	profile := filepath.Join(profilesDir, "snap.samba.smbd")
+	c.Assert(os.WriteFile(profile, []byte(fmt.Sprintf(`
+profile "snap.samba.smbd" {
+  #include <abstractions/base>
+  include "%s"
+}
+`, snippet)), 0644), IsNil)
+
+	load := func() int {
+		cmd.ForgetCalls()
+		err := apparmor.LoadProfiles([]string{profile}, apparmor.CacheDir, apparmor.HashCache|apparmor.SkipKernelLoad)
+		c.Assert(err, IsNil)
+		return len(cmd.Calls())
+	}
+
+	c.Check(load(), Equals, 1)
+	c.Check(load(), Equals, 0)
+
+	// a change of a file included directly, through another included file,
+	// from an included directory or of a file whose inclusion is conditional
+	// invalidates the cached policy
+	for _, change := range []struct {
+		path    string
+		content string
+	}{
+		{snippet, "new snippet rules"},
+		{filepath.Join(abstractions, "base.d", "extra"), "new extra rules"},
+		{filepath.Join(abstractions, "base.d", "more"), "more rules"},
+		{filepath.Join(apparmor.ConfDir, "local", "smbd"), "local rules"},
+	} {
+		c.Assert(os.MkdirAll(filepath.Dir(change.path), 0755), IsNil)
+		c.Assert(os.WriteFile(change.path, []byte(change.content), 0644), IsNil)
+		c.Check(load(), Equals, 1, Commentf("%s", change.path))
+		c.Check(load(), Equals, 0, Commentf("%s", change.path))
+	}
+}
+
+func (s *appArmorSuite) TestLoadProfilesHashCachePrunesStaleEntries(c *C) {
+	dirs.SetRootDir(c.MkDir())
+	defer dirs.SetRootDir("")
+	restore := apparmor.MockFeatures(nil, nil, nil, nil)
+	defer restore()
+	restore = apparmor.MockHashCacheMaxAge(time.Hour)
+	defer restore()
+	cmd := testutil.MockCommand(c, "apparmor_parser", hashCacheParserScript)
+	defer cmd.Restore()
+	restore = apparmor.MockParserSearchPath(cmd.BinDir())
+	defer restore()
+
+	hashDir := filepath.Join(apparmor.CacheDir, "snapd-hash")
+	c.Assert(os.MkdirAll(hashDir, 0755), IsNil)
+	stale := filepath.Join(hashDir, "stale")
+	c.Assert(os.WriteFile(stale, nil, 0644), IsNil)
+	old := time.Now().Add(-2 * time.Hour)
+	c.Assert(os.Chtimes(stale, old, old), IsNil)
+
+	profile := filepath.Join(c.MkDir(), "snap.samba.smbd")
+	c.Assert(os.WriteFile(profile, []byte("smbd profile"), 0644), IsNil)
+	err := apparmor.LoadProfiles([]string{profile}, apparmor.CacheDir, apparmor.HashCache)
+	c.Assert(err, IsNil)
+
+	c.Check(stale, testutil.FileAbsent)
+	entries, err := filepath.Glob(filepath.Join(hashDir, "*"))
+	c.Assert(err, IsNil)
+	c.Check(entries, HasLen, 1)
+}
+
+func (s *appArmorSuite) TestLoadProfilesHashCacheReportsErrors(c *C) {
+	dirs.SetRootDir(c.MkDir())
+	defer dirs.SetRootDir("")
+	restore := apparmor.MockFeatures(nil, nil, nil, nil)
+	defer restore()
+	cmd := testutil.MockCommand(c, "apparmor_parser", "echo parser error; exit 0")
+	defer cmd.Restore()
+	restore = apparmor.MockParserSearchPath(cmd.BinDir())
+	defer restore()
+
+	profile := filepath.Join(c.MkDir(), "snap.samba.smbd")
+	c.Assert(os.WriteFile(profile, []byte("smbd profile"), 0644), IsNil)
+	err := apparmor.LoadProfiles([]string{profile}, apparmor.CacheDir, apparmor.HashCache)
+	c.Assert(err, ErrorMatches, `(?s)cannot compile apparmor profiles: .*/snap.samba.smbd: exit status 0 with parser error
+apparmor_parser output:
+parser error
+`)
+	// nothing is loaded nor cached
+	c.Check(cmd.Calls(), HasLen, 1)
+	entries, err := filepath.Glob(filepath.Join(apparmor.CacheDir, "snapd-hash", "*"))
+	c.Assert(err, IsNil)
+	c.Check(entries, HasLen, 0)
+}
+
 func (s *appArmorSuite) TestLoadProfilesRunsAppArmorParserReplaceWithSnapdDebug(c *C) {
 	os.Setenv("SNAPD_DEBUG", "1")
 	defer os.Unsetenv("SNAPD_DEBUG")
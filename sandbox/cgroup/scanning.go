@@ -96,6 +96,16 @@ func securityTagFromCgroupPath(path string) naming.SecurityTag {
 	return nil
 }
 
+// SnapNameFromCgroupPath returns the instance name of the snap whose
+// application, service or hook processes are in the cgroup with the given
+// path, or an empty string if the cgroup is not one of a snap.
+func SnapNameFromCgroupPath(path string) string {
+	if parsedTag := securityTagFromCgroupPath(path); parsedTag != nil {
+		return parsedTag.InstanceName()
+	}
+	return ""
+}
+
 type InstancePathsOptions struct {
 	ReturnCGroupPath bool
 }
@@ -79,6 +79,14 @@ func (s *scanningSuite) TestSecurityTagFromCgroupPath(c *C) {
 	c.Check(cgroup.SecurityTagFromCgroupPath("/a/b/snap.foo\\x2bcomp.hook.install.54b38acc-3ba2-4c6d-b284-7ac07e1159e5.scope"), DeepEquals, mustParseTag("snap.foo+comp.hook.install"))
 }
 
+func (s *scanningSuite) TestSnapNameFromCgroupPath(c *C) {
+	c.Check(cgroup.SnapNameFromCgroupPath("/system.slice/snap.foo.svc.service"), Equals, "foo")
+	c.Check(cgroup.SnapNameFromCgroupPath("/system.slice/snap.foo_x.svc.service"), Equals, "foo_x")
+	c.Check(cgroup.SnapNameFromCgroupPath("/user.slice/user-1000.slice/user@1000.service/app.slice/snap.foo.app-54b38acc-3ba2-4c6d-b284-7ac07e1159e5.scope"), Equals, "foo")
+	c.Check(cgroup.SnapNameFromCgroupPath("/user.slice/user-1000.slice/session-1.scope"), Equals, "")
+	c.Check(cgroup.SnapNameFromCgroupPath("/system.slice/snapd.service"), Equals, "")
+}
+
 // Returns the number of occurrences of 'needle' in the array 'arr'
 func matchesInArray(arr []string, needle string) int {
 	counter := 0
@@ -14,6 +14,7 @@ import (
 	"github.com/snapcore/snapd/dbusutil"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/randutil"
+	"github.com/snapcore/snapd/release"
 	"github.com/snapcore/snapd/systemd"
 )
 
@@ -47,6 +48,13 @@ func CreateTransientScopeForTracking(securityTag string, opts *TrackingOptions)
 	}
 	logger.Debugf("creating transient scope %s", securityTag)
 
+	// Inside a container systemd can only move the process into the new
+	// scope if the cgroup hierarchy was delegated to the container.
+	if release.OnContainer && !release.ContainerCgroupDelegated {
+		logger.Debugf("cannot track process in %s container without cgroup delegation", release.ContainerType)
+		return ErrCannotTrackProcess
+	}
+
 	// Session or system bus might be unavailable. To avoid being fragile
 	// ignore all errors when establishing session bus connection to avoid
 	// breaking user interactions. This is consistent with similar failure
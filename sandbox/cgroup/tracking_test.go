@@ -34,6 +34,7 @@ import (
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/features"
 	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/release"
 	"github.com/snapcore/snapd/sandbox/cgroup"
 	"github.com/snapcore/snapd/systemd"
 	"github.com/snapcore/snapd/testutil"
@@ -46,17 +47,22 @@ func enableFeatures(c *C, ff ...features.SnapdFeature) {
 	}
 }
 
-type trackingSuite struct{}
+type trackingSuite struct {
+	testutil.BaseTest
+}
 
 var _ = Suite(&trackingSuite{})
 
 func (s *trackingSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
 	dirs.SetRootDir(c.MkDir())
 	cgroup.MockVersion(cgroup.V2, nil)
+	s.AddCleanup(release.MockOnContainer("", false))
 }
 
 func (s *trackingSuite) TearDownTest(c *C) {
 	dirs.SetRootDir("")
+	s.BaseTest.TearDownTest(c)
 }
 
 // CreateTransientScopeForTracking always attempts to track, even when refresh app awareness flag is off.
@@ -75,6 +81,41 @@ func (s *trackingSuite) TestCreateTransientScopeForTrackingFeatureDisabled(c *C)
 	c.Assert(err, ErrorMatches, "cannot track application process")
 }
 
+func (s *trackingSuite) TestCreateTransientScopeForTrackingContainerWithoutDelegation(c *C) {
+	restore := release.MockOnContainer("docker", false)
+	defer restore()
+	restore = release.MockContainerCgroupDelegated(false)
+	defer restore()
+	// Neither bus is used as tracking is known not to work.
+	restore = dbusutil.MockConnections(func() (*dbus.Conn, error) {
+		c.Fatal("unexpected system bus connection")
+		return nil, nil
+	}, func() (*dbus.Conn, error) {
+		c.Fatal("unexpected session bus connection")
+		return nil, nil
+	})
+	defer restore()
+
+	err := cgroup.CreateTransientScopeForTracking("snap.pkg.app", nil)
+	c.Assert(err, Equals, cgroup.ErrCannotTrackProcess)
+}
+
+func (s *trackingSuite) TestCreateTransientScopeForTrackingContainerWithDelegation(c *C) {
+	restore := release.MockOnContainer("lxc", true)
+	defer restore()
+	// Hand out stub connections to both the system and session bus.
+	restore = dbusutil.MockConnections(dbustest.StubConnection, dbustest.StubConnection)
+	defer restore()
+	restore = cgroup.MockRandomUUID(func() (string, error) {
+		return "", errors.New("mocked uuid error")
+	})
+	defer restore()
+
+	// tracking is attempted
+	err := cgroup.CreateTransientScopeForTracking("snap.pkg.app", nil)
+	c.Assert(err, ErrorMatches, "mocked uuid error")
+}
+
 // TestCreateTransientScopeForTrackingUUIDFailure tests the UUID error path
 func (s *trackingSuite) TestCreateTransientScopeForTrackingUUIDFailure(c *C) {
 	// Hand out stub connections to both the system and session bus.
@@ -108,28 +149,12 @@ func (s *trackingSuite) TestCreateTransientScopeForTrackingFeatureEnabled(c *C)
 		return uuid, nil
 	})
 	defer restore()
-	signalChan := make(chan struct{})
 	// Replace interactions with DBus so that only session bus is available and responds with our logic.
-	conn, inject, err := dbustest.InjectableConnection(func(msg *dbus.Message, n int) ([]*dbus.Message, error) {
-		switch n {
-		case 0:
-			return []*dbus.Message{checkSystemdSignalSubscribe(c, msg)}, nil
-		case 1:
-			defer func() {
-				close(signalChan)
-			}()
-			return []*dbus.Message{checkAndRespondToStartTransientUnit(c, msg, "snap.pkg.app-"+uuid+".scope", 312123)}, nil
-		case 2:
-			return []*dbus.Message{checkSystemSignalUnsubscribe(c, msg)}, nil
-		}
-		return nil, fmt.Errorf("unexpected message #%d: %s", n, msg)
-	})
-	go func() {
-		select {
-		case <-signalChan:
-			inject(mockJobRemovedSignal("snap.pkg.app-"+uuid+".scope", "done"))
-		}
-	}()
+	script := dbustest.NewScript()
+	expectSystemdSignalSubscribe(script)
+	thenJobRemoved(expectStartTransientUnit(script, "snap.pkg.app-"+uuid+".scope", 312123), "snap.pkg.app-"+uuid+".scope", "done")
+	expectSystemdSignalUnsubscribe(script)
+	conn, err := script.Connection()
 	c.Assert(err, IsNil)
 	restore = dbusutil.MockOnlySessionBusAvailable(conn)
 	defer restore()
@@ -141,6 +166,7 @@ func (s *trackingSuite) TestCreateTransientScopeForTrackingFeatureEnabled(c *C)
 
 	err = cgroup.CreateTransientScopeForTracking("snap.pkg.app", nil)
 	c.Check(err, IsNil)
+	c.Check(script.Verify(), IsNil)
 }
 
 func (s *trackingSuite) TestCreateTransientScopeForTrackingUnhappyNotRootGeneric(c *C) {
@@ -547,109 +573,35 @@ func (s *trackingSuite) TestCreateTransientScopeConfirmInvalidTag(c *C) {
 
 const systemdSignalMatch = `type='signal',interface='org.freedesktop.systemd1.Manager',member='JobRemoved'`
 
-func checkSystemdSignalSubscribe(c *C, msg *dbus.Message) *dbus.Message {
-	var rule string
-	dbus.Store(msg.Body, &rule)
-	c.Check(rule, Equals, systemdSignalMatch)
-	return &dbus.Message{
-		Type: dbus.TypeMethodReply,
-		Headers: map[dbus.HeaderField]dbus.Variant{
-			dbus.FieldReplySerial: dbus.MakeVariant(msg.Serial()),
-			dbus.FieldSender:      dbus.MakeVariant(":1"), // This does not matter.
-		},
-	}
+func expectSystemdSignalSubscribe(script *dbustest.Script) {
+	script.ExpectCall("org.freedesktop.DBus", "AddMatch").WithArgs(systemdSignalMatch)
 }
 
-func checkSystemSignalUnsubscribe(c *C, msg *dbus.Message) *dbus.Message {
-	var rule string
-	dbus.Store(msg.Body, &rule)
-	c.Check(rule, Equals, systemdSignalMatch)
-	return &dbus.Message{
-		Type: dbus.TypeMethodReply,
-		Headers: map[dbus.HeaderField]dbus.Variant{
-			dbus.FieldReplySerial: dbus.MakeVariant(msg.Serial()),
-			dbus.FieldSender:      dbus.MakeVariant(":1"), // This does not matter.
-		},
-	}
+func expectSystemdSignalUnsubscribe(script *dbustest.Script) {
+	script.ExpectCall("org.freedesktop.DBus", "RemoveMatch").WithArgs(systemdSignalMatch)
 }
 
-func checkAndRespondToStartTransientUnit(c *C, msg *dbus.Message, scopeName string, pid int) *dbus.Message {
-	// XXX: Those types might live in a package somewhere
-	type Property struct {
-		Name  string
-		Value interface{}
-	}
-	type Unit struct {
-		Name  string
-		Props []Property
-	}
-	// Signature of StartTransientUnit, string, string, array of Property and array of Unit (see above).
-	requestSig := dbus.SignatureOf("", "", []Property{}, []Unit{})
-
-	c.Assert(msg.Type, Equals, dbus.TypeMethodCall)
-	c.Check(msg.Flags, Equals, dbus.Flags(0))
-	c.Check(msg.Headers, DeepEquals, map[dbus.HeaderField]dbus.Variant{
-		dbus.FieldDestination: dbus.MakeVariant("org.freedesktop.systemd1"),
-		dbus.FieldPath:        dbus.MakeVariant(dbus.ObjectPath("/org/freedesktop/systemd1")),
-		dbus.FieldInterface:   dbus.MakeVariant("org.freedesktop.systemd1.Manager"),
-		dbus.FieldMember:      dbus.MakeVariant("StartTransientUnit"),
-		dbus.FieldSignature:   dbus.MakeVariant(requestSig),
-	})
-	c.Check(msg.Body, DeepEquals, []interface{}{
-		scopeName,
-		"fail",
-		[][]interface{}{
-			{"PIDs", dbus.MakeVariant([]uint32{uint32(pid)})},
-		},
-		[][]interface{}{},
-	})
-
-	responseSig := dbus.SignatureOf(dbus.ObjectPath(""))
-	return &dbus.Message{
-		Type: dbus.TypeMethodReply,
-		Headers: map[dbus.HeaderField]dbus.Variant{
-			dbus.FieldReplySerial: dbus.MakeVariant(msg.Serial()),
-			dbus.FieldSender:      dbus.MakeVariant(":1"), // This does not matter.
-			// dbus.FieldDestination is provided automatically by DBus test helper.
-			dbus.FieldSignature: dbus.MakeVariant(responseSig),
-		},
+func expectStartTransientUnit(script *dbustest.Script, scopeName string, pid int) *dbustest.Call {
+	return script.ExpectCall("org.freedesktop.systemd1.Manager", "StartTransientUnit").
+		To("org.freedesktop.systemd1").
+		OnPath("/org/freedesktop/systemd1").
+		WithArgs(
+			scopeName,
+			"fail",
+			[][]interface{}{
+				{"PIDs", dbus.MakeVariant([]uint32{uint32(pid)})},
+			},
+			[][]interface{}{},
+		).
 		// The object path returned in the body is not used by snap run yet.
-		Body: []interface{}{dbus.ObjectPath("/org/freedesktop/systemd1/job/1462")},
-	}
+		Return(dbus.ObjectPath("/org/freedesktop/systemd1/job/1462"))
 }
 
-func checkAndFailToStartTransientUnit(c *C, msg *dbus.Message, errMsg string) *dbus.Message {
-	c.Assert(msg.Type, Equals, dbus.TypeMethodCall)
-	// ignore the message and just produce an error response
-	return &dbus.Message{
-		Type: dbus.TypeError,
-		Headers: map[dbus.HeaderField]dbus.Variant{
-			dbus.FieldReplySerial: dbus.MakeVariant(msg.Serial()),
-			dbus.FieldSender:      dbus.MakeVariant(":1"), // This does not matter.
-			// dbus.FieldDestination is provided automatically by DBus test helper.
-			dbus.FieldErrorName: dbus.MakeVariant(errMsg),
-		},
-	}
-}
-
-func mockJobRemovedSignal(unit, result string) *dbus.Message {
-	return &dbus.Message{
-		Type: dbus.TypeSignal,
-		Headers: map[dbus.HeaderField]dbus.Variant{
-			dbus.FieldSender:    dbus.MakeVariant(":1"), // This does not matter.
-			dbus.FieldPath:      dbus.MakeVariant(dbus.ObjectPath("/org/freedesktop/systemd1")),
-			dbus.FieldInterface: dbus.MakeVariant("org.freedesktop.systemd1.Manager"),
-			dbus.FieldMember:    dbus.MakeVariant("JobRemoved"),
-			dbus.FieldSignature: dbus.MakeVariant(
-				dbus.SignatureOf(uint32(0), dbus.ObjectPath(""), "", "")),
-		},
-		Body: []interface{}{
-			uint32(1),
-			dbus.ObjectPath("/org/freedesktop/systemd1/job/1462"),
-			unit,
-			result,
-		},
-	}
+// thenJobRemoved makes systemd signal the completion of the job started by
+// the call.
+func thenJobRemoved(call *dbustest.Call, unit, result string) *dbustest.Call {
+	return call.ThenEmit("/org/freedesktop/systemd1", "org.freedesktop.systemd1.Manager", "JobRemoved",
+		uint32(1), dbus.ObjectPath("/org/freedesktop/systemd1/job/1462"), unit, result)
 }
 
 func (s *trackingSuite) TestCreateTransientScopeHappyWithRetriedCheckCgroupV1(c *C) {
@@ -670,18 +622,10 @@ func (s *trackingSuite) TestCreateTransientScopeHappyWithRetriedCheckCgroupV1(c
 		return uuid, nil
 	})
 	defer restore()
-	sessionBus, err := dbustest.Connection(func(msg *dbus.Message, n int) ([]*dbus.Message, error) {
-		c.Logf("%v got msg: %v", n, msg)
-		switch n {
-		case 0:
-			return []*dbus.Message{checkAndRespondToStartTransientUnit(c, msg, "snap.pkg.app-"+uuid+".scope", 312123)}, nil
-			// CreateTransientScopeForTracking is called twice in the test
-		case 1:
-			return []*dbus.Message{checkAndRespondToStartTransientUnit(c, msg, "snap.pkg.app-"+uuid+".scope", 312123)}, nil
-		}
-		return nil, fmt.Errorf("unexpected message #%d: %s", n, msg)
-	})
-
+	script := dbustest.NewScript()
+	// CreateTransientScopeForTracking is called twice in the test
+	expectStartTransientUnit(script, "snap.pkg.app-"+uuid+".scope", 312123).Times(2)
+	sessionBus, err := script.Connection()
 	c.Assert(err, IsNil)
 	restore = dbusutil.MockOnlySessionBusAvailable(sessionBus)
 	defer restore()
@@ -711,6 +655,7 @@ func (s *trackingSuite) TestCreateTransientScopeHappyWithRetriedCheckCgroupV1(c
 	c.Assert(err, ErrorMatches, "cannot track application process")
 	c.Check(pathInTrackingCgroupCalls, Equals, 100)
 	c.Check(logBuf.String(), testutil.Contains, "systemd could not associate process 312123 with transient scope")
+	c.Check(script.Verify(), IsNil)
 }
 
 func (s *trackingSuite) TestCreateTransientScopeUnhappyJobFailed(c *C) {
@@ -725,29 +670,11 @@ func (s *trackingSuite) TestCreateTransientScopeUnhappyJobFailed(c *C) {
 		return uuid, nil
 	})
 	defer restore()
-	signalChan := make(chan struct{})
-	sessionBus, inject, err := dbustest.InjectableConnection(func(msg *dbus.Message, n int) ([]*dbus.Message, error) {
-		c.Logf("dbus message %v", n)
-		switch n {
-		case 0:
-			return []*dbus.Message{checkSystemdSignalSubscribe(c, msg)}, nil
-		case 1:
-			defer func() {
-				close(signalChan)
-			}()
-			return []*dbus.Message{checkAndRespondToStartTransientUnit(c, msg, "snap.pkg.app-"+uuid+".scope", 312123)}, nil
-		case 2:
-			return []*dbus.Message{checkSystemSignalUnsubscribe(c, msg)}, nil
-		}
-		return nil, fmt.Errorf("unexpected message #%d: %s", n, msg)
-	})
-	go func() {
-		select {
-		case <-signalChan:
-			inject(mockJobRemovedSignal("snap.pkg.app-"+uuid+".scope", "failed"))
-		}
-	}()
-
+	script := dbustest.NewScript()
+	expectSystemdSignalSubscribe(script)
+	thenJobRemoved(expectStartTransientUnit(script, "snap.pkg.app-"+uuid+".scope", 312123), "snap.pkg.app-"+uuid+".scope", "failed")
+	expectSystemdSignalUnsubscribe(script)
+	sessionBus, err := script.Connection()
 	c.Assert(err, IsNil)
 	restore = dbusutil.MockOnlySessionBusAvailable(sessionBus)
 	defer restore()
@@ -761,6 +688,7 @@ func (s *trackingSuite) TestCreateTransientScopeUnhappyJobFailed(c *C) {
 	// creating transient scope fails when systemd reports that the job failed
 	err = cgroup.CreateTransientScopeForTracking("snap.pkg.app", nil)
 	c.Assert(err, ErrorMatches, "transient scope could not be started, job /org/freedesktop/systemd1/job/1462 finished with result failed")
+	c.Check(script.Verify(), IsNil)
 }
 
 func (s *trackingSuite) TestCreateTransientScopeUnhappyJobTimeout(c *C) {
@@ -777,20 +705,12 @@ func (s *trackingSuite) TestCreateTransientScopeUnhappyJobTimeout(c *C) {
 	defer restore()
 	restore = cgroup.MockCreateScopeJobTimeout(100 * time.Millisecond)
 	defer restore()
-	// mock a connection, but without support for injecting signal messages
-	sessionBus, err := dbustest.Connection(func(msg *dbus.Message, n int) ([]*dbus.Message, error) {
-		c.Logf("dbus message %v", n)
-		switch n {
-		case 0:
-			return []*dbus.Message{checkSystemdSignalSubscribe(c, msg)}, nil
-		case 1:
-			return []*dbus.Message{checkAndRespondToStartTransientUnit(c, msg, "snap.pkg.app-"+uuid+".scope", 312123)}, nil
-		case 2:
-			return []*dbus.Message{checkSystemSignalUnsubscribe(c, msg)}, nil
-		}
-		return nil, fmt.Errorf("unexpected message #%d: %s", n, msg)
-	})
-
+	// systemd never signals the completion of the job
+	script := dbustest.NewScript()
+	expectSystemdSignalSubscribe(script)
+	expectStartTransientUnit(script, "snap.pkg.app-"+uuid+".scope", 312123)
+	expectSystemdSignalUnsubscribe(script)
+	sessionBus, err := script.Connection()
 	c.Assert(err, IsNil)
 	restore = dbusutil.MockOnlySessionBusAvailable(sessionBus)
 	defer restore()
@@ -804,54 +724,34 @@ func (s *trackingSuite) TestCreateTransientScopeUnhappyJobTimeout(c *C) {
 	// creating transient scope fails when systemd reports that the job failed
 	err = cgroup.CreateTransientScopeForTracking("snap.pkg.app", nil)
 	c.Assert(err, ErrorMatches, "transient scope not created in 100ms")
+	c.Check(script.Verify(), IsNil)
 }
 
 func (s *trackingSuite) TestDoCreateTransientScopeHappyCgroupV2(c *C) {
-	signalChan := make(chan struct{})
-	conn, inject, err := dbustest.InjectableConnection(func(msg *dbus.Message, n int) ([]*dbus.Message, error) {
-		c.Logf("message: %v", msg)
-		switch n {
-		case 0:
-			return []*dbus.Message{checkSystemdSignalSubscribe(c, msg)}, nil
-		case 1:
-			defer func() {
-				close(signalChan)
-			}()
-			return []*dbus.Message{checkAndRespondToStartTransientUnit(c, msg, "foo.scope", 312123)}, nil
-		case 2:
-			return []*dbus.Message{checkSystemSignalUnsubscribe(c, msg)}, nil
-		}
-		return nil, fmt.Errorf("unexpected message #%d: %s", n, msg)
-	})
-	go func() {
-		select {
-		case <-signalChan:
-			inject(mockJobRemovedSignal("foo.scope", "done"))
-		}
-	}()
-
+	script := dbustest.NewScript()
+	expectSystemdSignalSubscribe(script)
+	thenJobRemoved(expectStartTransientUnit(script, "foo.scope", 312123), "foo.scope", "done")
+	expectSystemdSignalUnsubscribe(script)
+	conn, err := script.Connection()
 	c.Assert(err, IsNil)
 	defer conn.Close()
 	err = cgroup.DoCreateTransientScope(conn, "foo.scope", 312123)
 	c.Assert(err, IsNil)
+	c.Check(script.Verify(), IsNil)
 }
 
 func (s *trackingSuite) TestDoCreateTransientScopeHappyCgroupV1(c *C) {
 	restore := cgroup.MockVersion(cgroup.V1, nil)
 	defer restore()
 
-	conn, err := dbustest.Connection(func(msg *dbus.Message, n int) ([]*dbus.Message, error) {
-		c.Logf("message: %v", msg)
-		switch n {
-		case 0:
-			return []*dbus.Message{checkAndRespondToStartTransientUnit(c, msg, "foo.scope", 312123)}, nil
-		}
-		return nil, fmt.Errorf("unexpected message #%d: %s", n, msg)
-	})
+	script := dbustest.NewScript()
+	expectStartTransientUnit(script, "foo.scope", 312123)
+	conn, err := script.Connection()
 	c.Assert(err, IsNil)
 	defer conn.Close()
 	err = cgroup.DoCreateTransientScope(conn, "foo.scope", 312123)
 	c.Assert(err, IsNil)
+	c.Check(script.Verify(), IsNil)
 }
 
 func (s *trackingSuite) TestDoCreateTransientScopeForwardedErrors(c *C) {
@@ -864,22 +764,17 @@ func (s *trackingSuite) TestDoCreateTransientScopeForwardedErrors(c *C) {
 		{"org.freedesktop.DBus.Error.UnknownMethod", "unknown dbus object method"},
 		{"org.freedesktop.DBus.Error.Spawn.ChildExited", "dbus spawned child process exited"},
 	} {
-		conn, err := dbustest.Connection(func(msg *dbus.Message, n int) ([]*dbus.Message, error) {
-			switch n {
-			case 0:
-				return []*dbus.Message{checkSystemdSignalSubscribe(c, msg)}, nil
-			case 1:
-				return []*dbus.Message{checkAndFailToStartTransientUnit(c, msg, t.dbusError)}, nil
-			case 2:
-				return []*dbus.Message{checkSystemSignalUnsubscribe(c, msg)}, nil
-			}
-			return nil, fmt.Errorf("unexpected message #%d: %s", n, msg)
-		})
+		script := dbustest.NewScript()
+		expectSystemdSignalSubscribe(script)
+		expectStartTransientUnit(script, "foo.scope", 312123).Fail(t.dbusError)
+		expectSystemdSignalUnsubscribe(script)
+		conn, err := script.Connection()
 		c.Assert(err, IsNil)
 		defer conn.Close()
 		err = cgroup.DoCreateTransientScope(conn, "foo.scope", 312123)
 		c.Assert(strings.HasSuffix(err.Error(), fmt.Sprintf(" [%s]", t.dbusError)), Equals, true, Commentf("%q ~ %s", err, t.dbusError))
 		c.Check(err, ErrorMatches, t.msg+" .*")
+		c.Check(script.Verify(), IsNil)
 	}
 }
 
@@ -887,41 +782,31 @@ func (s *trackingSuite) TestDoCreateTransientScopeClashingScopeName(c *C) {
 	// In case our UUID algorithm is bad and systemd reports that an unit with
 	// identical name already exists, we provide a special error handler for that.
 	errMsg := "org.freedesktop.systemd1.UnitExists"
-	conn, err := dbustest.Connection(func(msg *dbus.Message, n int) ([]*dbus.Message, error) {
-		switch n {
-		case 0:
-			return []*dbus.Message{checkSystemdSignalSubscribe(c, msg)}, nil
-		case 1:
-			return []*dbus.Message{checkAndFailToStartTransientUnit(c, msg, errMsg)}, nil
-		case 2:
-			return []*dbus.Message{checkSystemSignalUnsubscribe(c, msg)}, nil
-		}
-		return nil, fmt.Errorf("unexpected message #%d: %s", n, msg)
-	})
+	script := dbustest.NewScript()
+	expectSystemdSignalSubscribe(script)
+	expectStartTransientUnit(script, "foo.scope", 312123).Fail(errMsg)
+	expectSystemdSignalUnsubscribe(script)
+	conn, err := script.Connection()
 	c.Assert(err, IsNil)
 	defer conn.Close()
 	err = cgroup.DoCreateTransientScope(conn, "foo.scope", 312123)
 	c.Assert(err, ErrorMatches, "cannot create transient scope: scope .* clashed: .*")
+	c.Check(script.Verify(), IsNil)
 }
 
 func (s *trackingSuite) TestDoCreateTransientScopeOtherDBusErrors(c *C) {
 	// Other DBus errors are not special-cased and cause a generic failure handler.
 	errMsg := "org.example.BadHairDay"
-	conn, err := dbustest.Connection(func(msg *dbus.Message, n int) ([]*dbus.Message, error) {
-		switch n {
-		case 0:
-			return []*dbus.Message{checkSystemdSignalSubscribe(c, msg)}, nil
-		case 1:
-			return []*dbus.Message{checkAndFailToStartTransientUnit(c, msg, errMsg)}, nil
-		case 2:
-			return []*dbus.Message{checkSystemSignalUnsubscribe(c, msg)}, nil
-		}
-		return nil, fmt.Errorf("unexpected message #%d: %s", n, msg)
-	})
+	script := dbustest.NewScript()
+	expectSystemdSignalSubscribe(script)
+	expectStartTransientUnit(script, "foo.scope", 312123).Fail(errMsg)
+	expectSystemdSignalUnsubscribe(script)
+	conn, err := script.Connection()
 	c.Assert(err, IsNil)
 	defer conn.Close()
 	err = cgroup.DoCreateTransientScope(conn, "foo.scope", 312123)
 	c.Assert(err, ErrorMatches, `cannot create transient scope: DBus error "org.example.BadHairDay": \[\]`)
+	c.Check(script.Verify(), IsNil)
 }
 
 func (s *trackingSuite) TestSessionOrMaybeSystemBusTotalFailureForRoot(c *C) {
@@ -22,6 +22,7 @@ package snap
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
 
 	"github.com/snapcore/snapd/logger"
@@ -266,6 +267,31 @@ func (e *Epoch) CanRead(other Epoch) bool {
 	return intersect(rs, ws)
 }
 
+// EpochHop returns the latest revision among the given channels that can be
+// refreshed to from a revision with epoch from, and that a revision with epoch
+// to can be refreshed to from. Refreshing to it first bridges the gap between
+// two epochs that cannot be refreshed between directly. It returns nil if
+// there is no such revision.
+func EpochHop(channels map[string]*ChannelSnapInfo, from, to Epoch) *ChannelSnapInfo {
+	names := make([]string, 0, len(channels))
+	for name := range channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var hop *ChannelSnapInfo
+	for _, name := range names {
+		ch := channels[name]
+		if ch == nil || !ch.Epoch.CanRead(from) || !to.CanRead(ch.Epoch) {
+			continue
+		}
+		if hop == nil || ch.Revision.N > hop.Revision.N {
+			hop = ch
+		}
+	}
+	return hop
+}
+
 func intersect(rs, ws []uint32) bool {
 	// O(𝑚𝑛) instead of O(𝑚log𝑛) for the binary search we could do, but
 	// 𝑚 and 𝑛 < 10, so the simple solution is good enough (and if that
@@ -374,3 +374,21 @@ func (s *epochSuite) TestEqual(c *check.C) {
 		c.Check(test.b.Equal(test.a), check.Equals, test.eq, check.Commentf("ab/%d", i))
 	}
 }
+
+func (s *epochSuite) TestEpochHop(c *check.C) {
+	channels := map[string]*snap.ChannelSnapInfo{
+		"latest/stable":    {Revision: snap.R(10), Channel: "latest/stable", Epoch: snap.E("0")},
+		"latest/candidate": {Revision: snap.R(20), Channel: "latest/candidate", Epoch: snap.E("1*")},
+		"latest/beta":      {Revision: snap.R(25), Channel: "latest/beta", Epoch: snap.E("1*")},
+		"latest/edge":      {Revision: snap.R(30), Channel: "latest/edge", Epoch: snap.E("2*")},
+	}
+
+	hop := snap.EpochHop(channels, snap.E("0"), snap.E("2*"))
+	c.Assert(hop, check.NotNil)
+	c.Check(hop.Revision, check.Equals, snap.R(25))
+	c.Check(hop.Channel, check.Equals, "latest/beta")
+
+	// no revision can read epoch 0 and be read by epoch 3
+	c.Check(snap.EpochHop(channels, snap.E("0"), snap.E("3")), check.IsNil)
+	c.Check(snap.EpochHop(nil, snap.E("0"), snap.E("2*")), check.IsNil)
+}
@@ -422,6 +422,9 @@ type Info struct {
 	// The ordered list of tracks that contain channels
 	Tracks []string
 
+	// The lifecycle of the tracks as published in the store, by track name
+	TrackInfo map[string]*TrackInfo
+
 	Layout map[string]*Layout
 
 	// The list of common-ids from all apps of the snap
@@ -489,6 +492,27 @@ func (l *Layout) String() string {
 	return buf.String()
 }
 
+// Track statuses as published in the store.
+const (
+	TrackStatusDefault = "default"
+	TrackStatusActive  = "active"
+	TrackStatusClosed  = "closed"
+)
+
+// TrackInfo holds the store information about the lifecycle of a track.
+type TrackInfo struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	// EndOfLife is when the track stops receiving updates, it is zero if
+	// the publisher did not announce it.
+	EndOfLife time.Time `json:"end-of-life"`
+}
+
+// Closed returns whether the track was closed in the store.
+func (t *TrackInfo) Closed() bool {
+	return t.Status == TrackStatusClosed
+}
+
 // ChannelSnapInfo is the minimum information that can be used to clearly
 // distinguish different revisions of the same snap.
 type ChannelSnapInfo struct {
@@ -514,6 +538,11 @@ func (s *Info) Provenance() string {
 	return s.SnapProvenance
 }
 
+// BuildProvenancePath is the path inside a snap of the optional document
+// (e.g. SLSA provenance) describing how the snap was built. Its digest is
+// carried by the snap-revision assertion of the snap.
+const BuildProvenancePath = "meta/build-provenance.json"
+
 // InstanceName returns the blessed name of the snap decorated with instance
 // key, if any.
 func (s *Info) InstanceName() string {
@@ -1279,6 +1308,74 @@ func (st StopModeType) Validate() error {
 	return fmt.Errorf(`"stop-mode" field contains invalid value %q`, st)
 }
 
+// SchedulingPolicy is the CPU scheduling policy the processes of an app are
+// run with.
+type SchedulingPolicy string
+
+// Validate ensures that the SchedulingPolicy has a valid value. Real-time
+// policies are not supported as they would let any snap starve the rest of
+// the system.
+func (sp SchedulingPolicy) Validate() error {
+	switch sp {
+	case "", "idle", "batch":
+		// valid
+		return nil
+	}
+	return fmt.Errorf(`"scheduling-policy" field contains invalid value %q`, sp)
+}
+
+// IOPriorityClass is the IO scheduling class the processes of a service are
+// run with.
+type IOPriorityClass string
+
+// Validate ensures that the IOPriorityClass has a valid value.
+func (ioc IOPriorityClass) Validate() error {
+	switch ioc {
+	case "", "realtime", "best-effort", "idle":
+		// valid
+		return nil
+	}
+	return fmt.Errorf(`"io-priority-class" field contains invalid value %q`, ioc)
+}
+
+// NetworkMode is the network isolation mode the processes of a service are
+// run with.
+type NetworkMode string
+
+const (
+	// NetworkNone runs the service without any network access beyond
+	// a private loopback device.
+	NetworkNone NetworkMode = "none"
+	// NetworkPrivate runs the service in a private network namespace,
+	// the network is reachable only through the sockets declared by the
+	// service, which are created by systemd in the host namespace.
+	NetworkPrivate NetworkMode = "private"
+)
+
+// Validate ensures that the NetworkMode has a valid value.
+func (nm NetworkMode) Validate() error {
+	switch nm {
+	case "", NetworkNone, NetworkPrivate:
+		// valid
+		return nil
+	}
+	return fmt.Errorf(`"network" field contains invalid value %q`, nm)
+}
+
+// MDNSInfo holds how a service is advertised on the local network through
+// mDNS/DNS-SD.
+type MDNSInfo struct {
+	// Name is the name of the advertised service instance, it defaults
+	// to the name of the snap instance.
+	Name string `yaml:"name,omitempty"`
+	// Type is the DNS-SD service type, e.g. _http._tcp.
+	Type string `yaml:"type"`
+	// Port is the port the service listens on.
+	Port int `yaml:"port"`
+	// TXT holds the key=value entries of the TXT record of the service.
+	TXT []string `yaml:"txt,omitempty"`
+}
+
 // Runnable represents a runnable element of a snap. This could either be an
 // app, a hook, or a component hook.
 type Runnable struct {
@@ -1336,6 +1433,48 @@ type AppInfo struct {
 	Timer *TimerInfo
 
 	Autostart string
+
+	// SingleInstance is set for apps of which only one instance can run
+	// per user, further launches forward their arguments to the running
+	// instance.
+	SingleInstance bool
+
+	// CPUAffinity is the list of CPUs the processes of the app are
+	// restricted to, all CPUs can be used if empty.
+	CPUAffinity []int
+	// SchedulingPolicy is the CPU scheduling policy of the processes of
+	// the app, the default policy is used if empty.
+	SchedulingPolicy SchedulingPolicy
+
+	// IOPriorityClass and IOPriorityLevel are the IO scheduling class
+	// and priority within the class (0 is the highest, 7 the lowest) of
+	// the processes of a service.
+	IOPriorityClass IOPriorityClass
+	IOPriorityLevel *int
+
+	// Network is the network isolation mode of a service, the service
+	// shares the network of the host if empty.
+	Network NetworkMode
+
+	// Ports are the ports, in the <number>/<protocol> format, a service
+	// listens on and which can be opened in the firewall of the system.
+	Ports []string
+
+	// MDNS holds how a service is advertised on the local network.
+	MDNS *MDNSInfo
+}
+
+// SingleInstanceSocket returns the path of the socket through which the
+// running instance of a single-instance app receives the arguments of further
+// launches by the given user.
+func (app *AppInfo) SingleInstanceSocket(euid sys.UserID) string {
+	return filepath.Join(app.Snap.UserXdgRuntimeDir(euid), app.Name+".instance")
+}
+
+// SingleInstanceLockFile returns the path of the lock held by the running
+// instance of a single-instance app for the given user.
+func (app *AppInfo) SingleInstanceLockFile(euid sys.UserID) string {
+	return app.SingleInstanceSocket(euid) + ".lock"
 }
 
 // Runnable returns a Runnable for this app.
@@ -1386,6 +1525,10 @@ type HookInfo struct {
 	Environment  strutil.OrderedMap
 	CommandChain []string
 
+	// Timeout is the maximum time the hook is expected to run, if
+	// declared by the snap.
+	Timeout timeout.Timeout
+
 	Explicit bool
 }
 
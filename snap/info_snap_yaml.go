@@ -108,6 +108,18 @@ type appYaml struct {
 	Timer string `yaml:"timer,omitempty"`
 
 	Autostart string `yaml:"autostart,omitempty"`
+
+	SingleInstance bool `yaml:"single-instance,omitempty"`
+
+	CPUAffinity      []int            `yaml:"cpu-affinity,omitempty"`
+	SchedulingPolicy SchedulingPolicy `yaml:"scheduling-policy,omitempty"`
+
+	IOPriorityClass IOPriorityClass `yaml:"io-priority-class,omitempty"`
+	IOPriorityLevel *int            `yaml:"io-priority-level,omitempty"`
+
+	Network NetworkMode `yaml:"network,omitempty"`
+	Ports   []string    `yaml:"ports,omitempty"`
+	MDNS    *MDNSInfo   `yaml:"mdns,omitempty"`
 }
 
 type hookYaml struct {
@@ -115,6 +127,7 @@ type hookYaml struct {
 	SlotNames    []string           `yaml:"slots,omitempty"`
 	Environment  strutil.OrderedMap `yaml:"environment,omitempty"`
 	CommandChain []string           `yaml:"command-chain,omitempty"`
+	Timeout      timeout.Timeout    `yaml:"timeout,omitempty"`
 }
 
 type componentYaml struct {
@@ -359,6 +372,7 @@ func setComponentsFromSnapYaml(y snapYaml, snap *Info, strk *scopedTracker) erro
 				Name:         hookName,
 				Environment:  hookData.Environment,
 				CommandChain: hookData.CommandChain,
+				Timeout:      hookData.Timeout,
 				Component:    &component,
 				Explicit:     true,
 			}
@@ -429,31 +443,39 @@ func setAppsFromSnapYaml(y snapYaml, snap *Info, strk *scopedTracker) error {
 	for appName, yApp := range y.Apps {
 		// Collect all apps
 		app := &AppInfo{
-			Snap:            snap,
-			Name:            appName,
-			LegacyAliases:   yApp.Aliases,
-			Command:         yApp.Command,
-			CommandChain:    yApp.CommandChain,
-			StartTimeout:    yApp.StartTimeout,
-			Daemon:          yApp.Daemon,
-			DaemonScope:     yApp.DaemonScope,
-			StopTimeout:     yApp.StopTimeout,
-			StopCommand:     yApp.StopCommand,
-			ReloadCommand:   yApp.ReloadCommand,
-			PostStopCommand: yApp.PostStopCommand,
-			RestartCond:     yApp.RestartCond,
-			RestartDelay:    yApp.RestartDelay,
-			BusName:         yApp.BusName,
-			CommonID:        yApp.CommonID,
-			Environment:     yApp.Environment,
-			Completer:       yApp.Completer,
-			StopMode:        yApp.StopMode,
-			RefreshMode:     yApp.RefreshMode,
-			InstallMode:     yApp.InstallMode,
-			Before:          yApp.Before,
-			After:           yApp.After,
-			Autostart:       yApp.Autostart,
-			WatchdogTimeout: yApp.WatchdogTimeout,
+			Snap:             snap,
+			Name:             appName,
+			LegacyAliases:    yApp.Aliases,
+			Command:          yApp.Command,
+			CommandChain:     yApp.CommandChain,
+			StartTimeout:     yApp.StartTimeout,
+			Daemon:           yApp.Daemon,
+			DaemonScope:      yApp.DaemonScope,
+			StopTimeout:      yApp.StopTimeout,
+			StopCommand:      yApp.StopCommand,
+			ReloadCommand:    yApp.ReloadCommand,
+			PostStopCommand:  yApp.PostStopCommand,
+			RestartCond:      yApp.RestartCond,
+			RestartDelay:     yApp.RestartDelay,
+			BusName:          yApp.BusName,
+			CommonID:         yApp.CommonID,
+			Environment:      yApp.Environment,
+			Completer:        yApp.Completer,
+			StopMode:         yApp.StopMode,
+			RefreshMode:      yApp.RefreshMode,
+			InstallMode:      yApp.InstallMode,
+			Before:           yApp.Before,
+			After:            yApp.After,
+			Autostart:        yApp.Autostart,
+			WatchdogTimeout:  yApp.WatchdogTimeout,
+			SingleInstance:   yApp.SingleInstance,
+			CPUAffinity:      yApp.CPUAffinity,
+			SchedulingPolicy: yApp.SchedulingPolicy,
+			IOPriorityClass:  yApp.IOPriorityClass,
+			IOPriorityLevel:  yApp.IOPriorityLevel,
+			Network:          yApp.Network,
+			Ports:            yApp.Ports,
+			MDNS:             yApp.MDNS,
 		}
 		if len(y.Plugs) > 0 || len(yApp.PlugNames) > 0 {
 			app.Plugs = make(map[string]*PlugInfo)
@@ -558,6 +580,7 @@ func setHooksFromSnapYaml(y snapYaml, snap *Info, strk *scopedTracker) {
 			Name:         hookName,
 			Environment:  yHook.Environment,
 			CommandChain: yHook.CommandChain,
+			Timeout:      yHook.Timeout,
 			Explicit:     true,
 		}
 		if len(y.Plugs) > 0 || len(yHook.PlugNames) > 0 {
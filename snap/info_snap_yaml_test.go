@@ -1908,6 +1908,126 @@ apps:
 	c.Check(app.Autostart, Equals, "")
 }
 
+func (s *YamlSuite) TestSnapYamlAppSingleInstance(c *C) {
+	y := []byte(`name: wat
+version: 42
+apps:
+ foo:
+   command: bin/foo
+   single-instance: true
+ bar:
+   command: bin/bar
+`)
+	info, err := snap.InfoFromSnapYaml(y)
+	c.Assert(err, IsNil)
+	c.Check(info.Apps["foo"].SingleInstance, Equals, true)
+	c.Check(info.Apps["bar"].SingleInstance, Equals, false)
+}
+
+func (s *YamlSuite) TestSnapYamlAppCPUAffinityAndSchedulingPolicy(c *C) {
+	y := []byte(`name: wat
+version: 42
+apps:
+ foo:
+   command: bin/foo
+   cpu-affinity: [0, 2]
+   scheduling-policy: idle
+ bar:
+   command: bin/bar
+`)
+	info, err := snap.InfoFromSnapYaml(y)
+	c.Assert(err, IsNil)
+	c.Check(info.Apps["foo"].CPUAffinity, DeepEquals, []int{0, 2})
+	c.Check(info.Apps["foo"].SchedulingPolicy, Equals, snap.SchedulingPolicy("idle"))
+	c.Check(info.Apps["bar"].CPUAffinity, HasLen, 0)
+	c.Check(info.Apps["bar"].SchedulingPolicy, Equals, snap.SchedulingPolicy(""))
+}
+
+func (s *YamlSuite) TestSnapYamlAppIOPriority(c *C) {
+	y := []byte(`name: wat
+version: 42
+apps:
+ foo:
+   command: bin/foo
+   daemon: simple
+   io-priority-class: best-effort
+   io-priority-level: 0
+ bar:
+   command: bin/bar
+   daemon: simple
+`)
+	info, err := snap.InfoFromSnapYaml(y)
+	c.Assert(err, IsNil)
+	c.Check(info.Apps["foo"].IOPriorityClass, Equals, snap.IOPriorityClass("best-effort"))
+	c.Assert(info.Apps["foo"].IOPriorityLevel, NotNil)
+	c.Check(*info.Apps["foo"].IOPriorityLevel, Equals, 0)
+	c.Check(info.Apps["bar"].IOPriorityClass, Equals, snap.IOPriorityClass(""))
+	c.Check(info.Apps["bar"].IOPriorityLevel, IsNil)
+}
+
+func (s *YamlSuite) TestSnapYamlAppNetwork(c *C) {
+	y := []byte(`name: wat
+version: 42
+apps:
+ foo:
+   command: bin/foo
+   daemon: simple
+   network: none
+ bar:
+   command: bin/bar
+   daemon: simple
+`)
+	info, err := snap.InfoFromSnapYaml(y)
+	c.Assert(err, IsNil)
+	c.Check(info.Apps["foo"].Network, Equals, snap.NetworkNone)
+	c.Check(info.Apps["bar"].Network, Equals, snap.NetworkMode(""))
+}
+
+func (s *YamlSuite) TestSnapYamlAppPorts(c *C) {
+	y := []byte(`name: wat
+version: 42
+apps:
+ foo:
+   command: bin/foo
+   daemon: simple
+   ports: [8080/tcp, 5353/udp]
+ bar:
+   command: bin/bar
+   daemon: simple
+`)
+	info, err := snap.InfoFromSnapYaml(y)
+	c.Assert(err, IsNil)
+	c.Check(info.Apps["foo"].Ports, DeepEquals, []string{"8080/tcp", "5353/udp"})
+	c.Check(info.Apps["bar"].Ports, HasLen, 0)
+}
+
+func (s *YamlSuite) TestSnapYamlAppMDNS(c *C) {
+	y := []byte(`name: wat
+version: 42
+apps:
+ foo:
+   command: bin/foo
+   daemon: simple
+   mdns:
+     name: My Appliance
+     type: _http._tcp
+     port: 8080
+     txt: [path=/]
+ bar:
+   command: bin/bar
+   daemon: simple
+`)
+	info, err := snap.InfoFromSnapYaml(y)
+	c.Assert(err, IsNil)
+	c.Check(info.Apps["foo"].MDNS, DeepEquals, &snap.MDNSInfo{
+		Name: "My Appliance",
+		Type: "_http._tcp",
+		Port: 8080,
+		TXT:  []string{"path=/"},
+	})
+	c.Check(info.Apps["bar"].MDNS, IsNil)
+}
+
 func (s *YamlSuite) TestSnapYamlAppCommonID(c *C) {
 	yAutostart := []byte(`name: wat
 version: 42
@@ -1953,6 +2073,20 @@ hooks:
 	c.Check(hook.CommandChain, DeepEquals, []string{"hookchain1", "hookchain2"})
 }
 
+func (s *YamlSuite) TestSnapYamlHookTimeout(c *C) {
+	y := []byte(`name: wat
+version: 42
+hooks:
+ post-refresh:
+  timeout: 30m
+ configure:
+`)
+	info, err := snap.InfoFromSnapYaml(y)
+	c.Assert(err, IsNil)
+	c.Check(info.Hooks["post-refresh"].Timeout, Equals, timeout.Timeout(30*time.Minute))
+	c.Check(info.Hooks["configure"].Timeout, Equals, timeout.Timeout(0))
+}
+
 func (s *YamlSuite) TestSnapYamlRestartDelay(c *C) {
 	yAutostart := []byte(`name: wat
 version: 42
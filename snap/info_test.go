@@ -1352,6 +1352,20 @@ func (s *infoSuite) TestAppDesktopFile(c *C) {
 	c.Check(snapInfo.DesktopPrefix(), Equals, "sample+instance")
 }
 
+func (s *infoSuite) TestAppSingleInstancePaths(c *C) {
+	snaptest.MockSnap(c, sampleYaml, &snap.SideInfo{})
+	snapInfo, err := snap.ReadInfo("sample", &snap.SideInfo{})
+	c.Assert(err, IsNil)
+
+	app := snapInfo.Apps["app"]
+	c.Check(app.SingleInstanceSocket(1000), Equals, filepath.Join(dirs.XdgRuntimeDirBase, "1000/snap.sample/app.instance"))
+	c.Check(app.SingleInstanceLockFile(1000), Equals, filepath.Join(dirs.XdgRuntimeDirBase, "1000/snap.sample/app.instance.lock"))
+
+	// snap with instance key
+	snapInfo.InstanceKey = "instance"
+	c.Check(app.SingleInstanceSocket(1000), Equals, filepath.Join(dirs.XdgRuntimeDirBase, "1000/snap.sample_instance/app.instance"))
+}
+
 func (s *infoSuite) testAppDesktopFileWithDesktopFileIDs(c *C, isParallelInstance bool) {
 	const sampleDesktopFileIDsYaml = `
 name: sample
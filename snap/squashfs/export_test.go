@@ -88,3 +88,8 @@ func MockIsRootWritableOverlay(new func() (string, error)) (restore func()) {
 		isRootWritableOverlay = old
 	}
 }
+
+var (
+	ReadFileNative       = readFileNative
+	ErrNativeUnsupported = errNativeUnsupported
+)
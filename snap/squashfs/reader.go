@@ -0,0 +1,508 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package squashfs
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+)
+
+// The native reader understands enough of the squashfs 4.0 format, see
+// https://dr-emann.github.io/squashfs/squashfs.html, to read single files
+// of a snap without unsquashfs or a mount. Only uncompressed and gzip
+// compressed images are read natively; images using any other compression,
+// including xz, are read with unsquashfs instead.
+
+// errNativeUnsupported is returned by the native reader for images it
+// cannot read.
+var errNativeUnsupported = errors.New("squashfs image not supported by the native reader")
+
+const (
+	compressionGzip = 1
+
+	flagUncompressedInodes    = 0x0001
+	flagUncompressedData      = 0x0002
+	flagUncompressedFragments = 0x0008
+
+	metadataBlockSize       = 8192
+	metadataUncompressedBit = 0x8000
+	dataUncompressedBit     = 1 << 24
+	noFragment              = 0xffffffff
+
+	inodeBasicDir      = 1
+	inodeBasicFile     = 2
+	inodeBasicSymlink  = 3
+	inodeExtendedDir   = 8
+	inodeExtendedFile  = 9
+	inodeExtendedLink  = 10
+	fragmentEntrySize  = 16
+	fragmentsPerBlock  = metadataBlockSize / fragmentEntrySize
+	maxNativeFileSize  = 64 * 1024 * 1024
+	maxDirectoryLength = 16 * 1024 * 1024
+)
+
+type superblock struct {
+	Magic               uint32
+	InodeCount          uint32
+	ModificationTime    uint32
+	BlockSize           uint32
+	FragmentEntryCount  uint32
+	CompressionID       uint16
+	BlockLog            uint16
+	Flags               uint16
+	IDCount             uint16
+	VersionMajor        uint16
+	VersionMinor        uint16
+	RootInodeRef        uint64
+	BytesUsed           uint64
+	IDTableStart        uint64
+	XattrIDTableStart   uint64
+	InodeTableStart     uint64
+	DirectoryTableStart uint64
+	FragmentTableStart  uint64
+	ExportTableStart    uint64
+}
+
+type image struct {
+	r  io.ReaderAt
+	sb superblock
+}
+
+func openImage(r io.ReaderAt) (*image, error) {
+	im := &image{r: r}
+	buf := make([]byte, superblockSize)
+	if _, err := r.ReadAt(buf, 0); err != nil {
+		return nil, fmt.Errorf("cannot read superblock: %v", err)
+	}
+	if err := binary.Read(bytes.NewReader(buf), binary.LittleEndian, &im.sb); err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(buf, magic) {
+		return nil, fmt.Errorf("invalid squashfs magic")
+	}
+	if im.sb.VersionMajor != 4 || im.sb.VersionMinor != 0 {
+		return nil, errNativeUnsupported
+	}
+	allUncompressed := uint16(flagUncompressedInodes | flagUncompressedData | flagUncompressedFragments)
+	if im.sb.CompressionID != compressionGzip && im.sb.Flags&allUncompressed != allUncompressed {
+		return nil, errNativeUnsupported
+	}
+	if im.sb.BlockSize == 0 || im.sb.BlockSize > 1024*1024 {
+		return nil, fmt.Errorf("invalid block size %d", im.sb.BlockSize)
+	}
+	return im, nil
+}
+
+func (im *image) decompress(data []byte, limit int) ([]byte, error) {
+	if im.sb.CompressionID != compressionGzip {
+		return nil, errNativeUnsupported
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	out, err := io.ReadAll(io.LimitReader(zr, int64(limit)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(out) > limit {
+		return nil, fmt.Errorf("decompressed block too large")
+	}
+	return out, nil
+}
+
+// readMetadataBlock returns the content of the metadata block at the given
+// position and the position of the next block.
+func (im *image) readMetadataBlock(pos int64) ([]byte, int64, error) {
+	var hdr [2]byte
+	if _, err := im.r.ReadAt(hdr[:], pos); err != nil {
+		return nil, 0, fmt.Errorf("cannot read metadata block header: %v", err)
+	}
+	size := binary.LittleEndian.Uint16(hdr[:])
+	uncompressed := size&metadataUncompressedBit != 0
+	size &^= metadataUncompressedBit
+	if size == 0 || size > metadataBlockSize {
+		return nil, 0, fmt.Errorf("invalid metadata block size %d", size)
+	}
+	data := make([]byte, size)
+	if _, err := im.r.ReadAt(data, pos+2); err != nil {
+		return nil, 0, fmt.Errorf("cannot read metadata block: %v", err)
+	}
+	next := pos + 2 + int64(size)
+	if uncompressed {
+		return data, next, nil
+	}
+	data, err := im.decompress(data, metadataBlockSize)
+	return data, next, err
+}
+
+// metadataReader reads a stream of metadata, possibly crossing the
+// boundaries of the metadata blocks it is stored in.
+type metadataReader struct {
+	im   *image
+	buf  []byte
+	next int64
+}
+
+func (im *image) metadataReader(blockPos int64, offset int) (*metadataReader, error) {
+	buf, next, err := im.readMetadataBlock(blockPos)
+	if err != nil {
+		return nil, err
+	}
+	if offset > len(buf) {
+		return nil, fmt.Errorf("invalid metadata offset %d", offset)
+	}
+	return &metadataReader{im: im, buf: buf[offset:], next: next}, nil
+}
+
+func (mr *metadataReader) Read(p []byte) (int, error) {
+	if len(mr.buf) == 0 {
+		buf, next, err := mr.im.readMetadataBlock(mr.next)
+		if err != nil {
+			return 0, err
+		}
+		mr.buf, mr.next = buf, next
+	}
+	n := copy(p, mr.buf)
+	mr.buf = mr.buf[n:]
+	return n, nil
+}
+
+func (mr *metadataReader) read(v interface{}) error {
+	return binary.Read(mr, binary.LittleEndian, v)
+}
+
+type inode struct {
+	typ uint16
+	// directories
+	dirBlock  uint32
+	dirOffset uint16
+	dirSize   uint32
+	// regular files
+	blocksStart uint64
+	fileSize    uint64
+	fragment    uint32
+	fragOffset  uint32
+	blockSizes  []uint32
+	// symlinks
+	target string
+}
+
+func (ino *inode) isDir() bool {
+	return ino.typ == inodeBasicDir || ino.typ == inodeExtendedDir
+}
+
+func (im *image) inode(ref uint64) (*inode, error) {
+	mr, err := im.metadataReader(int64(im.sb.InodeTableStart)+int64(ref>>16), int(ref&0xffff))
+	if err != nil {
+		return nil, err
+	}
+	var hdr struct {
+		Type, Permissions, UID, GID uint16
+		Mtime, InodeNumber          uint32
+	}
+	if err := mr.read(&hdr); err != nil {
+		return nil, err
+	}
+	ino := &inode{typ: hdr.Type}
+	switch hdr.Type {
+	case inodeBasicDir:
+		var d struct {
+			BlockIdx, LinkCount   uint32
+			FileSize, BlockOffset uint16
+			ParentInode           uint32
+		}
+		if err := mr.read(&d); err != nil {
+			return nil, err
+		}
+		ino.dirBlock, ino.dirOffset, ino.dirSize = d.BlockIdx, d.BlockOffset, uint32(d.FileSize)
+	case inodeExtendedDir:
+		var d struct {
+			LinkCount, FileSize, BlockIdx, ParentInode uint32
+			IndexCount, BlockOffset                    uint16
+			XattrIdx                                   uint32
+		}
+		if err := mr.read(&d); err != nil {
+			return nil, err
+		}
+		ino.dirBlock, ino.dirOffset, ino.dirSize = d.BlockIdx, d.BlockOffset, d.FileSize
+	case inodeBasicFile:
+		var f struct {
+			BlocksStart, FragIdx, BlockOffset, FileSize uint32
+		}
+		if err := mr.read(&f); err != nil {
+			return nil, err
+		}
+		ino.blocksStart, ino.fileSize, ino.fragment, ino.fragOffset = uint64(f.BlocksStart), uint64(f.FileSize), f.FragIdx, f.BlockOffset
+	case inodeExtendedFile:
+		var f struct {
+			BlocksStart, FileSize, Sparse             uint64
+			LinkCount, FragIdx, BlockOffset, XattrIdx uint32
+		}
+		if err := mr.read(&f); err != nil {
+			return nil, err
+		}
+		ino.blocksStart, ino.fileSize, ino.fragment, ino.fragOffset = f.BlocksStart, f.FileSize, f.FragIdx, f.BlockOffset
+	case inodeBasicSymlink, inodeExtendedLink:
+		var l struct {
+			LinkCount, TargetSize uint32
+		}
+		if err := mr.read(&l); err != nil {
+			return nil, err
+		}
+		if l.TargetSize > 4096 {
+			return nil, fmt.Errorf("invalid symlink target size %d", l.TargetSize)
+		}
+		target := make([]byte, l.TargetSize)
+		if _, err := io.ReadFull(mr, target); err != nil {
+			return nil, err
+		}
+		ino.target = string(target)
+		return ino, nil
+	default:
+		// devices, fifos and sockets carry no data
+		return ino, nil
+	}
+
+	if ino.isDir() {
+		return ino, nil
+	}
+	if ino.fileSize > maxNativeFileSize {
+		return nil, errNativeUnsupported
+	}
+	blockCount := ino.fileSize / uint64(im.sb.BlockSize)
+	if ino.fragment == noFragment && ino.fileSize%uint64(im.sb.BlockSize) != 0 {
+		blockCount++
+	}
+	ino.blockSizes = make([]uint32, blockCount)
+	if err := mr.read(ino.blockSizes); err != nil {
+		return nil, err
+	}
+	return ino, nil
+}
+
+type dirEntry struct {
+	name string
+	ref  uint64
+}
+
+func (im *image) readDir(dir *inode) ([]dirEntry, error) {
+	// the size accounts for the implicit "." and ".." entries
+	if dir.dirSize <= 3 {
+		return nil, nil
+	}
+	if dir.dirSize > maxDirectoryLength {
+		return nil, errNativeUnsupported
+	}
+	mr, err := im.metadataReader(int64(im.sb.DirectoryTableStart)+int64(dir.dirBlock), int(dir.dirOffset))
+	if err != nil {
+		return nil, err
+	}
+	listing := make([]byte, dir.dirSize-3)
+	if _, err := io.ReadFull(mr, listing); err != nil {
+		return nil, fmt.Errorf("cannot read directory: %v", err)
+	}
+	r := bytes.NewReader(listing)
+
+	var entries []dirEntry
+	for r.Len() > 0 {
+		var hdr struct {
+			Count, Start, InodeNumber uint32
+		}
+		if err := binary.Read(r, binary.LittleEndian, &hdr); err != nil {
+			return nil, err
+		}
+		if hdr.Count >= 256 {
+			return nil, fmt.Errorf("invalid directory header count %d", hdr.Count)
+		}
+		for i := uint32(0); i <= hdr.Count; i++ {
+			var ent struct {
+				Offset      uint16
+				InodeOffset int16
+				Type        uint16
+				NameSize    uint16
+			}
+			if err := binary.Read(r, binary.LittleEndian, &ent); err != nil {
+				return nil, err
+			}
+			name := make([]byte, int(ent.NameSize)+1)
+			if _, err := io.ReadFull(r, name); err != nil {
+				return nil, err
+			}
+			entries = append(entries, dirEntry{
+				name: string(name),
+				ref:  uint64(hdr.Start)<<16 | uint64(ent.Offset),
+			})
+		}
+	}
+	return entries, nil
+}
+
+func notExist(filePath string) error {
+	return &os.PathError{Op: "open", Path: filePath, Err: syscall.ENOENT}
+}
+
+// lookup returns the inode of the given path, without following symlinks.
+func (im *image) lookup(filePath string) (*inode, error) {
+	ino, err := im.inode(im.sb.RootInodeRef)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range strings.Split(strings.Trim(filePath, "/"), "/") {
+		if name == "" || name == "." {
+			continue
+		}
+		if ino.typ == inodeBasicSymlink || ino.typ == inodeExtendedLink {
+			// leave following symlinks to unsquashfs
+			return nil, errNativeUnsupported
+		}
+		if !ino.isDir() {
+			return nil, notExist(filePath)
+		}
+		entries, err := im.readDir(ino)
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for _, ent := range entries {
+			if ent.name == name {
+				if ino, err = im.inode(ent.ref); err != nil {
+					return nil, err
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, notExist(filePath)
+		}
+	}
+	return ino, nil
+}
+
+func (im *image) readDataBlock(pos int64, size uint32, uncompressedFlag bool) ([]byte, error) {
+	uncompressed := size&dataUncompressedBit != 0 || uncompressedFlag
+	size &^= dataUncompressedBit
+	if size > im.sb.BlockSize+1024 {
+		return nil, fmt.Errorf("invalid data block size %d", size)
+	}
+	data := make([]byte, size)
+	if _, err := im.r.ReadAt(data, pos); err != nil {
+		return nil, fmt.Errorf("cannot read data block: %v", err)
+	}
+	if uncompressed {
+		return data, nil
+	}
+	return im.decompress(data, int(im.sb.BlockSize))
+}
+
+func (im *image) fragmentBlock(idx uint32) ([]byte, error) {
+	if idx >= im.sb.FragmentEntryCount {
+		return nil, fmt.Errorf("invalid fragment index %d", idx)
+	}
+	// the fragment table is indexed by the locations of the metadata
+	// blocks holding the fragment entries
+	var loc [8]byte
+	if _, err := im.r.ReadAt(loc[:], int64(im.sb.FragmentTableStart)+int64(idx/fragmentsPerBlock)*8); err != nil {
+		return nil, fmt.Errorf("cannot read fragment table: %v", err)
+	}
+	mr, err := im.metadataReader(int64(binary.LittleEndian.Uint64(loc[:])), int(idx%fragmentsPerBlock)*fragmentEntrySize)
+	if err != nil {
+		return nil, err
+	}
+	var entry struct {
+		Start  uint64
+		Size   uint32
+		Unused uint32
+	}
+	if err := mr.read(&entry); err != nil {
+		return nil, err
+	}
+	return im.readDataBlock(int64(entry.Start), entry.Size, im.sb.Flags&flagUncompressedFragments != 0)
+}
+
+func (im *image) readFile(filePath string) ([]byte, error) {
+	ino, err := im.lookup(filePath)
+	if err != nil {
+		return nil, err
+	}
+	switch ino.typ {
+	case inodeBasicFile, inodeExtendedFile:
+	case inodeBasicSymlink, inodeExtendedLink:
+		// unsquashfs knows how to deal with symlinks
+		return nil, errNativeUnsupported
+	default:
+		return nil, fmt.Errorf("cannot read %q: not a regular file", filePath)
+	}
+
+	content := make([]byte, 0, ino.fileSize)
+	pos := int64(ino.blocksStart)
+	for _, size := range ino.blockSizes {
+		if size == 0 {
+			// sparse block
+			content = append(content, make([]byte, im.sb.BlockSize)...)
+			continue
+		}
+		block, err := im.readDataBlock(pos, size, im.sb.Flags&flagUncompressedData != 0)
+		if err != nil {
+			return nil, err
+		}
+		content = append(content, block...)
+		pos += int64(size &^ dataUncompressedBit)
+	}
+	if ino.fragment != noFragment {
+		frag, err := im.fragmentBlock(ino.fragment)
+		if err != nil {
+			return nil, err
+		}
+		tail := ino.fileSize % uint64(im.sb.BlockSize)
+		if uint64(ino.fragOffset)+tail > uint64(len(frag)) {
+			return nil, fmt.Errorf("invalid fragment offset %d", ino.fragOffset)
+		}
+		content = append(content, frag[ino.fragOffset:uint64(ino.fragOffset)+tail]...)
+	}
+	if uint64(len(content)) < ino.fileSize {
+		return nil, fmt.Errorf("cannot read %q: short content", filePath)
+	}
+	return content[:ino.fileSize], nil
+}
+
+// readFileNative reads a single file from the squashfs image at the given
+// path without unsquashfs.
+func readFileNative(imagePath, filePath string) ([]byte, error) {
+	f, err := os.Open(imagePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	im, err := openImage(f)
+	if err != nil {
+		return nil, err
+	}
+	return im.readFile(filePath)
+}
@@ -0,0 +1,458 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package squashfs_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/snap/squashfs"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type readerSuite struct {
+	testutil.BaseTest
+}
+
+var _ = Suite(&readerSuite{})
+
+const testBlockSize = 4096
+
+// testNode is a file, a symlink or a directory of a test image.
+type testNode struct {
+	name     string
+	content  string
+	target   string
+	children []*testNode
+
+	isDir    bool
+	ino      uint32
+	inodeOff int
+	dirOff   int
+	dirSize  int
+	blocks   []uint32
+	start    uint64
+	frag     uint32
+	fragOff  uint32
+}
+
+func file(name, content string) *testNode   { return &testNode{name: name, content: content} }
+func symlink(name, target string) *testNode { return &testNode{name: name, target: target} }
+func dir(name string, children ...*testNode) *testNode {
+	return &testNode{name: name, isDir: true, children: children}
+}
+
+type imageOpts struct {
+	compressed bool
+	fragments  bool
+	// compression overrides the compression id in the superblock
+	compression uint16
+}
+
+func appendUint16(b []byte, v uint16) []byte {
+	var buf [2]byte
+	binary.LittleEndian.PutUint16(buf[:], v)
+	return append(b, buf[:]...)
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], v)
+	return append(b, buf[:]...)
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	return append(b, buf[:]...)
+}
+
+func zlibCompress(c *C, data []byte) []byte {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	_, err := w.Write(data)
+	c.Assert(err, IsNil)
+	c.Assert(w.Close(), IsNil)
+	return buf.Bytes()
+}
+
+func metadataBlock(c *C, data []byte, compressed bool) []byte {
+	c.Assert(len(data) <= 8192, Equals, true)
+	hdr := uint16(len(data)) | 0x8000
+	if compressed {
+		data = zlibCompress(c, data)
+		hdr = uint16(len(data))
+	}
+	out := appendUint16(nil, hdr)
+	return append(out, data...)
+}
+
+func postorder(n *testNode, visit func(n *testNode)) {
+	for _, child := range n.children {
+		postorder(child, visit)
+	}
+	visit(n)
+}
+
+// makeImage writes a squashfs 4.0 image of the given tree, the inode and
+// the directory tables are kept in a single metadata block each.
+func makeImage(c *C, root *testNode, opts imageOpts) string {
+	var nodes []*testNode
+	postorder(root, func(n *testNode) {
+		sort.Slice(n.children, func(i, j int) bool { return n.children[i].name < n.children[j].name })
+		n.ino = uint32(len(nodes) + 1)
+		nodes = append(nodes, n)
+	})
+
+	// data blocks and fragments follow the superblock
+	data := make([]byte, squashfs.SuperblockSize)
+	var fragBlock []byte
+	for _, n := range nodes {
+		if n.isDir || n.target != "" {
+			continue
+		}
+		content := []byte(n.content)
+		n.start = uint64(len(data))
+		n.frag = 0xffffffff
+		for len(content) > 0 {
+			block := content
+			if len(block) > testBlockSize {
+				block = block[:testBlockSize]
+			} else if opts.fragments && len(block) < testBlockSize {
+				n.frag = 0
+				n.fragOff = uint32(len(fragBlock))
+				fragBlock = append(fragBlock, block...)
+				break
+			}
+			content = content[len(block):]
+			size := uint32(len(block)) | 1<<24
+			if opts.compressed {
+				block = zlibCompress(c, block)
+				size = uint32(len(block))
+			}
+			n.blocks = append(n.blocks, size)
+			data = append(data, block...)
+		}
+	}
+	var fragStart uint64
+	var fragSize uint32
+	if len(fragBlock) > 0 {
+		fragStart = uint64(len(data))
+		fragSize = uint32(len(fragBlock)) | 1<<24
+		if opts.compressed {
+			fragBlock = zlibCompress(c, fragBlock)
+			fragSize = uint32(len(fragBlock))
+		}
+		data = append(data, fragBlock...)
+	}
+
+	// the directory table only depends on the inode numbers and offsets,
+	// which in turn only depend on the sizes of the inodes
+	inodeSize := func(n *testNode) int {
+		switch {
+		case n.isDir:
+			return 32
+		case n.target != "":
+			return 24 + len(n.target)
+		default:
+			return 32 + 4*len(n.blocks)
+		}
+	}
+	off := 0
+	for _, n := range nodes {
+		n.inodeOff = off
+		off += inodeSize(n)
+	}
+	var dirTable []byte
+	for _, n := range nodes {
+		if !n.isDir {
+			continue
+		}
+		n.dirOff = len(dirTable)
+		if len(n.children) > 0 {
+			dirTable = appendUint32(dirTable, uint32(len(n.children)-1))
+			dirTable = appendUint32(dirTable, 0)
+			dirTable = appendUint32(dirTable, n.children[0].ino)
+			for _, child := range n.children {
+				typ := uint16(2)
+				if child.isDir {
+					typ = 1
+				} else if child.target != "" {
+					typ = 3
+				}
+				dirTable = appendUint16(dirTable, uint16(child.inodeOff))
+				dirTable = appendUint16(dirTable, uint16(child.ino-n.children[0].ino))
+				dirTable = appendUint16(dirTable, typ)
+				dirTable = appendUint16(dirTable, uint16(len(child.name)-1))
+				dirTable = append(dirTable, child.name...)
+			}
+		}
+		n.dirSize = len(dirTable) - n.dirOff + 3
+	}
+
+	var inodeTable []byte
+	for _, n := range nodes {
+		typ := uint16(2)
+		if n.isDir {
+			typ = 1
+		} else if n.target != "" {
+			typ = 3
+		}
+		inodeTable = appendUint16(inodeTable, typ)
+		inodeTable = appendUint16(inodeTable, 0755)
+		inodeTable = appendUint16(inodeTable, 0)
+		inodeTable = appendUint16(inodeTable, 0)
+		inodeTable = appendUint32(inodeTable, 0)
+		inodeTable = appendUint32(inodeTable, n.ino)
+		switch {
+		case n.isDir:
+			inodeTable = appendUint32(inodeTable, 0)
+			inodeTable = appendUint32(inodeTable, 2)
+			inodeTable = appendUint16(inodeTable, uint16(n.dirSize))
+			inodeTable = appendUint16(inodeTable, uint16(n.dirOff))
+			inodeTable = appendUint32(inodeTable, 0)
+		case n.target != "":
+			inodeTable = appendUint32(inodeTable, 1)
+			inodeTable = appendUint32(inodeTable, uint32(len(n.target)))
+			inodeTable = append(inodeTable, n.target...)
+		default:
+			inodeTable = appendUint32(inodeTable, uint32(n.start))
+			inodeTable = appendUint32(inodeTable, n.frag)
+			inodeTable = appendUint32(inodeTable, n.fragOff)
+			inodeTable = appendUint32(inodeTable, uint32(len(n.content)))
+			for _, size := range n.blocks {
+				inodeTable = appendUint32(inodeTable, size)
+			}
+		}
+	}
+
+	inodeTableStart := uint64(len(data))
+	data = append(data, metadataBlock(c, inodeTable, opts.compressed)...)
+	dirTableStart := uint64(len(data))
+	data = append(data, metadataBlock(c, dirTable, opts.compressed)...)
+	fragEntryCount := uint32(0)
+	fragTableStart := uint64(len(data))
+	if fragSize != 0 {
+		fragEntryCount = 1
+		entry := appendUint64(nil, fragStart)
+		entry = appendUint32(entry, fragSize)
+		entry = appendUint32(entry, 0)
+		data = append(data, metadataBlock(c, entry, opts.compressed)...)
+		data = appendUint64(data, fragTableStart)
+		fragTableStart = uint64(len(data)) - 8
+	}
+
+	compression := opts.compression
+	if compression == 0 {
+		compression = 1
+	}
+	flags := uint16(0)
+	if !opts.compressed {
+		flags = 0x1 | 0x2 | 0x8
+	}
+	// the superblock is written in place at the start of the image
+	sb := data[:0]
+	sb = append(sb, "hsqs"...)
+	sb = appendUint32(sb, uint32(len(nodes)))
+	sb = appendUint32(sb, 0)
+	sb = appendUint32(sb, testBlockSize)
+	sb = appendUint32(sb, fragEntryCount)
+	sb = appendUint16(sb, compression)
+	sb = appendUint16(sb, 12)
+	sb = appendUint16(sb, flags)
+	sb = appendUint16(sb, 1)
+	sb = appendUint16(sb, 4)
+	sb = appendUint16(sb, 0)
+	sb = appendUint64(sb, uint64(root.inodeOff))
+	sb = appendUint64(sb, uint64(len(data)))
+	sb = appendUint64(sb, 0xffffffffffffffff)
+	sb = appendUint64(sb, 0xffffffffffffffff)
+	sb = appendUint64(sb, inodeTableStart)
+	sb = appendUint64(sb, dirTableStart)
+	sb = appendUint64(sb, fragTableStart)
+	sb = appendUint64(sb, 0xffffffffffffffff)
+	c.Assert(sb, HasLen, squashfs.SuperblockSize)
+
+	p := filepath.Join(c.MkDir(), "foo_1.0_all.snap")
+	c.Assert(os.WriteFile(p, data, 0644), IsNil)
+	return p
+}
+
+var (
+	snapYaml = "name: foo\nversion: 1.0\n"
+	bigFile  = strings.Repeat("0123456789abcdef", testBlockSize/16*2) + "tail"
+)
+
+func testTree() *testNode {
+	return dir("",
+		dir("meta",
+			file("snap.yaml", snapYaml),
+			dir("hooks", file("configure", "#!/bin/sh\n")),
+			symlink("icon.svg", "../icon.svg"),
+			dir("empty"),
+		),
+		file("big", bigFile),
+		file("empty-file", ""),
+	)
+}
+
+func (s *readerSuite) testReadFileNative(c *C, opts imageOpts) {
+	p := makeImage(c, testTree(), opts)
+
+	for _, t := range []struct {
+		path, content string
+	}{
+		{"meta/snap.yaml", snapYaml},
+		{"/meta/hooks/configure", "#!/bin/sh\n"},
+		{"./big", bigFile},
+		{"empty-file", ""},
+	} {
+		content, err := squashfs.ReadFileNative(p, t.path)
+		c.Assert(err, IsNil, Commentf(t.path))
+		c.Check(string(content), Equals, t.content, Commentf(t.path))
+	}
+}
+
+func (s *readerSuite) TestReadFileNativeUncompressed(c *C) {
+	s.testReadFileNative(c, imageOpts{})
+}
+
+func (s *readerSuite) TestReadFileNativeCompressed(c *C) {
+	s.testReadFileNative(c, imageOpts{compressed: true})
+}
+
+func (s *readerSuite) TestReadFileNativeFragments(c *C) {
+	s.testReadFileNative(c, imageOpts{fragments: true})
+}
+
+func (s *readerSuite) TestReadFileNativeCompressedFragments(c *C) {
+	s.testReadFileNative(c, imageOpts{compressed: true, fragments: true})
+}
+
+func (s *readerSuite) TestReadFileNativeNotFound(c *C) {
+	p := makeImage(c, testTree(), imageOpts{compressed: true})
+
+	for _, path := range []string{
+		"meta/missing",
+		"meta/empty/missing",
+		"meta/snap.yaml/missing",
+		"missing/snap.yaml",
+	} {
+		_, err := squashfs.ReadFileNative(p, path)
+		c.Check(os.IsNotExist(err), Equals, true, Commentf(path))
+	}
+}
+
+func (s *readerSuite) TestReadFileNativeDirectory(c *C) {
+	p := makeImage(c, testTree(), imageOpts{})
+
+	_, err := squashfs.ReadFileNative(p, "meta/hooks")
+	c.Check(err, ErrorMatches, `cannot read "meta/hooks": not a regular file`)
+}
+
+func (s *readerSuite) TestReadFileNativeSymlinkUnsupported(c *C) {
+	p := makeImage(c, testTree(), imageOpts{})
+
+	_, err := squashfs.ReadFileNative(p, "meta/icon.svg")
+	c.Check(err, Equals, squashfs.ErrNativeUnsupported)
+}
+
+func (s *readerSuite) TestReadFileNativeCompressionUnsupported(c *C) {
+	// xz
+	p := makeImage(c, testTree(), imageOpts{compressed: true, compression: 4})
+
+	_, err := squashfs.ReadFileNative(p, "meta/snap.yaml")
+	c.Check(err, Equals, squashfs.ErrNativeUnsupported)
+}
+
+func (s *readerSuite) TestReadFileNativeUncompressedAnyCompression(c *C) {
+	// images without any compressed content can be read whatever the
+	// compression they were built with
+	p := makeImage(c, testTree(), imageOpts{compression: 4})
+
+	content, err := squashfs.ReadFileNative(p, "meta/snap.yaml")
+	c.Assert(err, IsNil)
+	c.Check(string(content), Equals, snapYaml)
+}
+
+func (s *readerSuite) TestReadFileNativeNotSquashfs(c *C) {
+	p := filepath.Join(c.MkDir(), "foo.snap")
+	c.Assert(os.WriteFile(p, bytes.Repeat([]byte{'x'}, 4096), 0644), IsNil)
+
+	_, err := squashfs.ReadFileNative(p, "meta/snap.yaml")
+	c.Check(err, ErrorMatches, "invalid squashfs magic")
+}
+
+func (s *readerSuite) TestReadFileNativeTruncated(c *C) {
+	p := makeImage(c, testTree(), imageOpts{compressed: true})
+	st, err := os.Stat(p)
+	c.Assert(err, IsNil)
+	// the image shrinking under the reader is an error, not a crash
+	c.Assert(os.Truncate(p, st.Size()/2), IsNil)
+
+	_, err = squashfs.ReadFileNative(p, "meta/snap.yaml")
+	c.Check(err, NotNil)
+}
+
+func (s *readerSuite) TestSnapReadFileDoesNotRunUnsquashfs(c *C) {
+	unsquashfs := testutil.MockCommand(c, "unsquashfs", "exit 1")
+	defer unsquashfs.Restore()
+
+	p := makeImage(c, testTree(), imageOpts{compressed: true, fragments: true})
+	sn := squashfs.New(p)
+
+	content, err := sn.ReadFile("meta/snap.yaml")
+	c.Assert(err, IsNil)
+	c.Check(string(content), Equals, snapYaml)
+
+	_, err = sn.ReadFile("meta/missing")
+	c.Check(os.IsNotExist(err), Equals, true)
+
+	c.Check(unsquashfs.Calls(), HasLen, 0)
+}
+
+func (s *readerSuite) TestSnapReadFileFallsBackToUnsquashfs(c *C) {
+	unsquashfs := testutil.MockCommand(c, "unsquashfs", `
+while [ -n "$1" ]; do
+	case "$1" in
+		-d) dest="$2"; shift 2 ;;
+		*) shift ;;
+	esac
+done
+mkdir -p "$dest/meta"
+echo icon > "$dest/meta/icon.svg"
+`)
+	defer unsquashfs.Restore()
+
+	p := makeImage(c, testTree(), imageOpts{})
+	sn := squashfs.New(p)
+
+	content, err := sn.ReadFile("meta/icon.svg")
+	c.Assert(err, IsNil)
+	c.Check(string(content), Equals, "icon\n")
+	c.Check(unsquashfs.Calls(), HasLen, 1)
+}
@@ -262,6 +262,14 @@ func (s *Snap) RandomAccessFile(filePath string) (interface {
 
 // ReadFile returns the content of a single file inside a squashfs snap.
 func (s *Snap) ReadFile(filePath string) (content []byte, err error) {
+	// read the file directly from the image when possible, this is
+	// much cheaper than running unsquashfs
+	content, err = readFileNative(s.path, filePath)
+	if err == nil || os.IsNotExist(err) {
+		return content, err
+	}
+	logger.Debugf("cannot read %q from %q natively, using unsquashfs: %v", filePath, s.path, err)
+
 	err = s.withUnpackedFile(filePath, func(p string) (err error) {
 		content, err = os.ReadFile(p)
 		return
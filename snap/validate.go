@@ -32,6 +32,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/osutil/firewall"
 	"github.com/snapcore/snapd/sandbox/apparmor"
 	"github.com/snapcore/snapd/snap/naming"
 	"github.com/snapcore/snapd/spdx"
@@ -191,6 +192,10 @@ func ValidateHook(hook *HookInfo) error {
 		}
 	}
 
+	if hook.Timeout < 0 {
+		return fmt.Errorf("hook timeout cannot be negative")
+	}
+
 	return nil
 }
 
@@ -872,10 +877,172 @@ func ValidateApp(app *AppInfo) error {
 	if app.InstallMode != "" && app.Daemon == "" {
 		return fmt.Errorf(`"install-mode" cannot be used for %q, only for services`, app.Name)
 	}
+	if app.SingleInstance && app.Daemon != "" {
+		return fmt.Errorf(`"single-instance" cannot be used for services`)
+	}
+	if err := validateCPUAffinity(app.CPUAffinity); err != nil {
+		return err
+	}
+	if err := app.SchedulingPolicy.Validate(); err != nil {
+		return err
+	}
+	if err := validateAppIOPriority(app); err != nil {
+		return err
+	}
+	if err := validateAppNetwork(app); err != nil {
+		return err
+	}
+	if err := validateAppPorts(app); err != nil {
+		return err
+	}
+	if err := validateAppMDNS(app); err != nil {
+		return err
+	}
 
 	return validateAppTimer(app)
 }
 
+// maxCPUAffinity is the number of CPUs that can be part of an affinity mask
+// (CPU_SETSIZE).
+const maxCPUAffinity = 1024
+
+func validateCPUAffinity(cpus []int) error {
+	seen := make(map[int]bool, len(cpus))
+	for _, cpu := range cpus {
+		if cpu < 0 || cpu >= maxCPUAffinity {
+			return fmt.Errorf(`"cpu-affinity" field contains invalid CPU %d`, cpu)
+		}
+		if seen[cpu] {
+			return fmt.Errorf(`"cpu-affinity" field contains duplicated CPU %d`, cpu)
+		}
+		seen[cpu] = true
+	}
+	return nil
+}
+
+func validateAppIOPriority(app *AppInfo) error {
+	if app.IOPriorityClass == "" && app.IOPriorityLevel == nil {
+		return nil
+	}
+	if app.Daemon == "" {
+		return fmt.Errorf(`"io-priority-class" and "io-priority-level" cannot be used for %q, only for services`, app.Name)
+	}
+	if err := app.IOPriorityClass.Validate(); err != nil {
+		return err
+	}
+	if app.IOPriorityLevel != nil {
+		if level := *app.IOPriorityLevel; level < 0 || level > 7 {
+			return fmt.Errorf(`"io-priority-level" must be between 0 and 7, not %d`, level)
+		}
+		if app.IOPriorityClass == "idle" {
+			return fmt.Errorf(`"io-priority-level" cannot be used with the "idle" IO priority class`)
+		}
+	}
+	return nil
+}
+
+func validateAppNetwork(app *AppInfo) error {
+	if app.Network == "" {
+		return nil
+	}
+	if err := app.Network.Validate(); err != nil {
+		return err
+	}
+	if app.Daemon == "" {
+		return fmt.Errorf(`"network" cannot be used for %q, only for services`, app.Name)
+	}
+	switch app.Network {
+	case NetworkNone:
+		// abstract unix sockets are bound to the network namespace too
+		for _, socket := range app.Sockets {
+			if addr := socket.ListenStream; addr != "" && addr[0] != '/' && addr[0] != '$' {
+				return fmt.Errorf(`"network" cannot be set to "none" for %q, socket %q is not a unix socket path`, app.Name, socket.Name)
+			}
+		}
+	case NetworkPrivate:
+		if len(app.Sockets) == 0 {
+			return fmt.Errorf(`"network" cannot be set to "private" for %q, it declares no sockets`, app.Name)
+		}
+	}
+	return nil
+}
+
+func hasPlugOfInterface(app *AppInfo, iface string) bool {
+	for _, plug := range app.Plugs {
+		if plug.Interface == iface {
+			return true
+		}
+	}
+	return false
+}
+
+func validateAppPorts(app *AppInfo) error {
+	if len(app.Ports) == 0 {
+		return nil
+	}
+	if app.Daemon == "" {
+		return fmt.Errorf(`"ports" cannot be used for %q, only for services`, app.Name)
+	}
+	if app.Network == NetworkNone {
+		return fmt.Errorf(`"ports" cannot be used for %q with "network" set to "none"`, app.Name)
+	}
+	if !hasPlugOfInterface(app, "network-bind") {
+		return fmt.Errorf(`"network-bind" interface plug is required when ports are used`)
+	}
+	seen := make(map[firewall.Port]bool, len(app.Ports))
+	for _, p := range app.Ports {
+		port, err := firewall.ParsePort(p)
+		if err != nil {
+			return fmt.Errorf(`"ports" field contains %v`, err)
+		}
+		if seen[port] {
+			return fmt.Errorf(`"ports" field contains duplicated port %q`, p)
+		}
+		seen[port] = true
+	}
+	return nil
+}
+
+// DNS-SD service types are made of a service name of up to 15 characters, as
+// per RFC 6335, followed by the transport protocol
+var validMDNSType = regexp.MustCompile(`^_[a-z0-9](?:[a-z0-9-]{0,13}[a-z0-9])?\._(?:tcp|udp)$`)
+
+func validateAppMDNS(app *AppInfo) error {
+	if app.MDNS == nil {
+		return nil
+	}
+	if app.Daemon == "" {
+		return fmt.Errorf(`"mdns" cannot be used for %q, only for services`, app.Name)
+	}
+	if app.DaemonScope == UserDaemon {
+		return fmt.Errorf(`"mdns" cannot be used for %q, only for system services`, app.Name)
+	}
+	if app.Network == NetworkNone {
+		return fmt.Errorf(`"mdns" cannot be used for %q with "network" set to "none"`, app.Name)
+	}
+	if _, ok := app.Plugs["network-bind"]; !ok {
+		return fmt.Errorf(`"network-bind" interface plug is required when mdns is used`)
+	}
+	mdns := app.MDNS
+	// DNS labels are limited to 63 bytes
+	if len(mdns.Name) > 63 {
+		return fmt.Errorf(`"mdns" field contains invalid name %q: longer than 63 bytes`, mdns.Name)
+	}
+	if !validMDNSType.MatchString(mdns.Type) {
+		return fmt.Errorf(`"mdns" field contains invalid service type %q`, mdns.Type)
+	}
+	if mdns.Port < 1 || mdns.Port > 65535 {
+		return fmt.Errorf(`"mdns" field contains invalid port %d`, mdns.Port)
+	}
+	for _, entry := range mdns.TXT {
+		// TXT record entries are length-prefixed with a single byte
+		if !strings.Contains(entry, "=") || strings.HasPrefix(entry, "=") || len(entry) > 255 {
+			return fmt.Errorf(`"mdns" field contains invalid TXT entry %q`, entry)
+		}
+	}
+	return nil
+}
+
 // ValidatePathVariables ensures that given path contains only $SNAP, $SNAP_DATA or $SNAP_COMMON.
 func ValidatePathVariables(path string) error {
 	for path != "" {
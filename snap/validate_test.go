@@ -25,6 +25,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	. "gopkg.in/check.v1"
@@ -34,6 +35,7 @@ import (
 	"github.com/snapcore/snapd/overlord/snapstate"
 	. "github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/testutil"
+	"github.com/snapcore/snapd/timeout"
 )
 
 type ValidateSuite struct {
@@ -145,6 +147,7 @@ func (s *ValidateSuite) TestValidateHook(c *C) {
 		{Name: "a-aa"},
 		{Name: "a-b-c"},
 		{Name: "valid", CommandChain: []string{"valid"}},
+		{Name: "valid", Timeout: timeout.Timeout(time.Hour)},
 	}
 	for _, hook := range validHooks {
 		err := ValidateHook(hook)
@@ -173,6 +176,9 @@ func (s *ValidateSuite) TestValidateHook(c *C) {
 		err := ValidateHook(hook)
 		c.Assert(err, ErrorMatches, `hook command-chain contains illegal.*`)
 	}
+
+	err := ValidateHook(&HookInfo{Name: "valid", Timeout: timeout.Timeout(-time.Minute)})
+	c.Assert(err, ErrorMatches, `hook timeout cannot be negative`)
 }
 
 // ValidateApp
@@ -2139,6 +2145,213 @@ func (s *ValidateSuite) TestAppInstallMode(c *C) {
 	c.Check(err, ErrorMatches, `"install-mode" cannot be used for "foo", only for services`)
 }
 
+func (s *ValidateSuite) TestAppSingleInstance(c *C) {
+	err := ValidateApp(&AppInfo{Name: "foo", SingleInstance: true})
+	c.Check(err, IsNil)
+
+	err = ValidateApp(&AppInfo{Name: "foo", Daemon: "simple", DaemonScope: SystemDaemon, SingleInstance: true})
+	c.Check(err, ErrorMatches, `"single-instance" cannot be used for services`)
+}
+
+func (s *ValidateSuite) TestAppCPUAffinity(c *C) {
+	err := ValidateApp(&AppInfo{Name: "foo", CPUAffinity: []int{0, 1, 1023}})
+	c.Check(err, IsNil)
+
+	err = ValidateApp(&AppInfo{Name: "foo", CPUAffinity: []int{-1}})
+	c.Check(err, ErrorMatches, `"cpu-affinity" field contains invalid CPU -1`)
+	err = ValidateApp(&AppInfo{Name: "foo", CPUAffinity: []int{1024}})
+	c.Check(err, ErrorMatches, `"cpu-affinity" field contains invalid CPU 1024`)
+	err = ValidateApp(&AppInfo{Name: "foo", CPUAffinity: []int{1, 1}})
+	c.Check(err, ErrorMatches, `"cpu-affinity" field contains duplicated CPU 1`)
+}
+
+func (s *ValidateSuite) TestAppSchedulingPolicy(c *C) {
+	for _, t := range []struct {
+		policy SchedulingPolicy
+		ok     bool
+	}{
+		{"", true},
+		{"idle", true},
+		{"batch", true},
+		{"rr", false},
+		{"fifo", false},
+		{"other", false},
+	} {
+		err := ValidateApp(&AppInfo{Name: "foo", SchedulingPolicy: t.policy})
+		if t.ok {
+			c.Check(err, IsNil)
+		} else {
+			c.Check(err, ErrorMatches, fmt.Sprintf(`"scheduling-policy" field contains invalid value %q`, t.policy))
+		}
+	}
+}
+
+func (s *ValidateSuite) TestAppIOPriority(c *C) {
+	level := func(l int) *int { return &l }
+
+	for _, t := range []struct {
+		class IOPriorityClass
+		level *int
+		err   string
+	}{
+		{"", nil, ""},
+		{"realtime", nil, ""},
+		{"best-effort", level(0), ""},
+		{"", level(7), ""},
+		{"idle", nil, ""},
+		{"low", nil, `"io-priority-class" field contains invalid value "low"`},
+		{"best-effort", level(8), `"io-priority-level" must be between 0 and 7, not 8`},
+		{"realtime", level(-1), `"io-priority-level" must be between 0 and 7, not -1`},
+		{"idle", level(4), `"io-priority-level" cannot be used with the "idle" IO priority class`},
+	} {
+		err := ValidateApp(&AppInfo{Name: "foo", Daemon: "simple", DaemonScope: SystemDaemon, IOPriorityClass: t.class, IOPriorityLevel: t.level})
+		if t.err == "" {
+			c.Check(err, IsNil)
+		} else {
+			c.Check(err, ErrorMatches, t.err)
+		}
+	}
+
+	// non-services cannot have an IO priority
+	err := ValidateApp(&AppInfo{Name: "foo", IOPriorityClass: "idle"})
+	c.Check(err, ErrorMatches, `"io-priority-class" and "io-priority-level" cannot be used for "foo", only for services`)
+	err = ValidateApp(&AppInfo{Name: "foo", IOPriorityLevel: level(1)})
+	c.Check(err, ErrorMatches, `"io-priority-class" and "io-priority-level" cannot be used for "foo", only for services`)
+}
+
+func (s *ValidateSuite) TestAppNetwork(c *C) {
+	info := &Info{SuggestedName: "foo"}
+	mkApp := func(network NetworkMode, listen ...string) *AppInfo {
+		app := &AppInfo{Snap: info, Name: "svc", Daemon: "simple", DaemonScope: SystemDaemon, Network: network}
+		if len(listen) > 0 {
+			app.Plugs = map[string]*PlugInfo{"network-bind": {Snap: info, Name: "network-bind", Interface: "network-bind"}}
+		}
+		for i, addr := range listen {
+			if app.Sockets == nil {
+				app.Sockets = make(map[string]*SocketInfo)
+			}
+			name := fmt.Sprintf("sock%d", i)
+			app.Sockets[name] = &SocketInfo{App: app, Name: name, ListenStream: addr}
+		}
+		return app
+	}
+
+	for _, t := range []struct {
+		app *AppInfo
+		err string
+	}{
+		{mkApp(""), ""},
+		{mkApp("none"), ""},
+		{mkApp("none", "$SNAP_DATA/sock"), ""},
+		{mkApp("private", "8080"), ""},
+		{mkApp("private", "$SNAP_COMMON/sock", "@snap.foo.sock"), ""},
+		{mkApp("host"), `"network" field contains invalid value "host"`},
+		{mkApp("none", "127.0.0.1:8080"), `"network" cannot be set to "none" for "svc", socket "sock0" is not a unix socket path`},
+		{mkApp("none", "@snap.foo.sock"), `"network" cannot be set to "none" for "svc", socket "sock0" is not a unix socket path`},
+		{mkApp("private"), `"network" cannot be set to "private" for "svc", it declares no sockets`},
+	} {
+		err := ValidateApp(t.app)
+		if t.err == "" {
+			c.Check(err, IsNil)
+		} else {
+			c.Check(err, ErrorMatches, t.err)
+		}
+	}
+
+	// non-services cannot be isolated from the network
+	err := ValidateApp(&AppInfo{Name: "foo", Network: "none"})
+	c.Check(err, ErrorMatches, `"network" cannot be used for "foo", only for services`)
+}
+
+func (s *ValidateSuite) TestAppPorts(c *C) {
+	networkBind := map[string]*PlugInfo{"network-bind": {Name: "network-bind", Interface: "network-bind"}}
+	for _, t := range []struct {
+		ports   []string
+		network NetworkMode
+		err     string
+	}{
+		{nil, "", ""},
+		{[]string{"8080/tcp", "8080/udp", "53/udp"}, "", ""},
+		{[]string{"8080/tcp"}, "private", ""},
+		{[]string{"8080"}, "", `"ports" field contains invalid port "8080", must be <number>/tcp or <number>/udp`},
+		{[]string{"0/tcp"}, "", `"ports" field contains invalid port number in "0/tcp"`},
+		{[]string{"80/tcp", "80/tcp"}, "", `"ports" field contains duplicated port "80/tcp"`},
+		{[]string{"80/tcp"}, "none", `"ports" cannot be used for "foo" with "network" set to "none"`},
+	} {
+		app := &AppInfo{Name: "foo", Daemon: "simple", DaemonScope: SystemDaemon, Plugs: networkBind, Ports: t.ports, Network: t.network}
+		if t.network == NetworkPrivate {
+			app.Sockets = map[string]*SocketInfo{"sock": {App: app, Name: "sock", ListenStream: "8080"}}
+		}
+		err := ValidateApp(app)
+		if t.err == "" {
+			c.Check(err, IsNil)
+		} else {
+			c.Check(err, ErrorMatches, t.err)
+		}
+	}
+
+	err := ValidateApp(&AppInfo{Name: "foo", Daemon: "simple", DaemonScope: SystemDaemon, Ports: []string{"80/tcp"}})
+	c.Check(err, ErrorMatches, `"network-bind" interface plug is required when ports are used`)
+
+	// the plug is identified by its interface, not its name
+	otherBind := map[string]*PlugInfo{"listen": {Name: "listen", Interface: "network-bind"}}
+	err = ValidateApp(&AppInfo{Name: "foo", Daemon: "simple", DaemonScope: SystemDaemon, Plugs: otherBind, Ports: []string{"80/tcp"}})
+	c.Check(err, IsNil)
+	notBind := map[string]*PlugInfo{"network-bind": {Name: "network-bind", Interface: "network"}}
+	err = ValidateApp(&AppInfo{Name: "foo", Daemon: "simple", DaemonScope: SystemDaemon, Plugs: notBind, Ports: []string{"80/tcp"}})
+	c.Check(err, ErrorMatches, `"network-bind" interface plug is required when ports are used`)
+
+	// non-services do not listen on ports
+	err = ValidateApp(&AppInfo{Name: "foo", Plugs: networkBind, Ports: []string{"80/tcp"}})
+	c.Check(err, ErrorMatches, `"ports" cannot be used for "foo", only for services`)
+}
+
+func (s *ValidateSuite) TestAppMDNS(c *C) {
+	networkBind := map[string]*PlugInfo{"network-bind": {Name: "network-bind", Interface: "network-bind"}}
+	for _, t := range []struct {
+		mdns    *MDNSInfo
+		network NetworkMode
+		err     string
+	}{
+		{nil, "", ""},
+		{&MDNSInfo{Type: "_http._tcp", Port: 80}, "", ""},
+		{&MDNSInfo{Name: "My Appliance", Type: "_ipp-2._udp", Port: 631, TXT: []string{"rp=printers", "note="}}, "", ""},
+		{&MDNSInfo{Type: "_http._tcp", Port: 80}, "private", ""},
+		{&MDNSInfo{Type: "http", Port: 80}, "", `"mdns" field contains invalid service type "http"`},
+		{&MDNSInfo{Type: "_http._sctp", Port: 80}, "", `"mdns" field contains invalid service type "_http._sctp"`},
+		{&MDNSInfo{Type: "_-http._tcp", Port: 80}, "", `"mdns" field contains invalid service type "_-http._tcp"`},
+		{&MDNSInfo{Type: "_a-very-long-name._tcp", Port: 80}, "", `"mdns" field contains invalid service type "_a-very-long-name._tcp"`},
+		{&MDNSInfo{Type: "_http._tcp"}, "", `"mdns" field contains invalid port 0`},
+		{&MDNSInfo{Type: "_http._tcp", Port: 65536}, "", `"mdns" field contains invalid port 65536`},
+		{&MDNSInfo{Name: strings.Repeat("a", 64), Type: "_http._tcp", Port: 80}, "", `"mdns" field contains invalid name "a+": longer than 63 bytes`},
+		{&MDNSInfo{Type: "_http._tcp", Port: 80, TXT: []string{"novalue"}}, "", `"mdns" field contains invalid TXT entry "novalue"`},
+		{&MDNSInfo{Type: "_http._tcp", Port: 80, TXT: []string{"=value"}}, "", `"mdns" field contains invalid TXT entry "=value"`},
+		{&MDNSInfo{Type: "_http._tcp", Port: 80}, "none", `"mdns" cannot be used for "foo" with "network" set to "none"`},
+	} {
+		app := &AppInfo{Name: "foo", Daemon: "simple", DaemonScope: SystemDaemon, Plugs: networkBind, MDNS: t.mdns, Network: t.network}
+		if t.network == NetworkPrivate {
+			app.Sockets = map[string]*SocketInfo{"sock": {App: app, Name: "sock", ListenStream: "8080"}}
+		}
+		err := ValidateApp(app)
+		if t.err == "" {
+			c.Check(err, IsNil)
+		} else {
+			c.Check(err, ErrorMatches, t.err)
+		}
+	}
+
+	mdns := &MDNSInfo{Type: "_http._tcp", Port: 80}
+	err := ValidateApp(&AppInfo{Name: "foo", Daemon: "simple", DaemonScope: SystemDaemon, MDNS: mdns})
+	c.Check(err, ErrorMatches, `"network-bind" interface plug is required when mdns is used`)
+
+	// only services are advertised
+	err = ValidateApp(&AppInfo{Name: "foo", Plugs: networkBind, MDNS: mdns})
+	c.Check(err, ErrorMatches, `"mdns" cannot be used for "foo", only for services`)
+
+	err = ValidateApp(&AppInfo{Name: "foo", Daemon: "simple", DaemonScope: UserDaemon, Plugs: networkBind, MDNS: mdns})
+	c.Check(err, ErrorMatches, `"mdns" cannot be used for "foo", only for system services`)
+}
+
 func (s *ValidateSuite) TestValidateLinks(c *C) {
 	info, err := InfoFromSnapYaml([]byte(`name: foo
 version: 1.0
@@ -92,3 +92,27 @@ func MockPreseeding(preseeding bool) (restore func()) {
 		mockPreseeding = old
 	}
 }
+
+var mockContainerProfile *bool
+
+// ContainerProfile returns whether snapd runs with the profile for
+// containers, selected with SNAPD_PROFILE=container, where it acts purely as
+// a package manager. The profile disables at runtime the tracking of hotplug
+// devices, the installation of gadget and kernel snaps, and the management
+// of boot, installation, factory reset and disk encryption; it does not
+// compile anything out of snapd. Packages built with with_container_profile=1
+// set it through a drop-in for snapd.service.
+func ContainerProfile() bool {
+	if mockContainerProfile != nil {
+		return *mockContainerProfile
+	}
+	return os.Getenv("SNAPD_PROFILE") == "container"
+}
+
+func MockContainerProfile(container bool) (restore func()) {
+	old := mockContainerProfile
+	mockContainerProfile = &container
+	return func() {
+		mockContainerProfile = old
+	}
+}
@@ -144,3 +144,43 @@ func (s *snapdenvSuite) TestMockPreseeding(c *C) {
 	snapdenv.MockPreseeding(false)
 	c.Check(snapdenv.Preseeding(), Equals, false)
 }
+
+func (s *snapdenvSuite) TestContainerProfile(c *C) {
+	oldProfile := os.Getenv("SNAPD_PROFILE")
+	defer func() {
+		if oldProfile == "" {
+			os.Unsetenv("SNAPD_PROFILE")
+		} else {
+			os.Setenv("SNAPD_PROFILE", oldProfile)
+		}
+	}()
+
+	os.Setenv("SNAPD_PROFILE", "container")
+	c.Check(snapdenv.ContainerProfile(), Equals, true)
+
+	os.Setenv("SNAPD_PROFILE", "full")
+	c.Check(snapdenv.ContainerProfile(), Equals, false)
+
+	os.Unsetenv("SNAPD_PROFILE")
+	c.Check(snapdenv.ContainerProfile(), Equals, false)
+}
+
+func (s *snapdenvSuite) TestMockContainerProfile(c *C) {
+	oldProfile := os.Getenv("SNAPD_PROFILE")
+	defer func() {
+		if oldProfile == "" {
+			os.Unsetenv("SNAPD_PROFILE")
+		} else {
+			os.Setenv("SNAPD_PROFILE", oldProfile)
+		}
+	}()
+	os.Unsetenv("SNAPD_PROFILE")
+
+	r := snapdenv.MockContainerProfile(true)
+	defer r()
+
+	c.Check(snapdenv.ContainerProfile(), Equals, true)
+
+	snapdenv.MockContainerProfile(false)
+	c.Check(snapdenv.ContainerProfile(), Equals, false)
+}
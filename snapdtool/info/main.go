@@ -0,0 +1,32 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// info produces information about snap-confine to include in /usr/lib/snapd/info.
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/snapcore/snapd/snapdtool"
+)
+
+func main() {
+	fmt.Printf("SNAPD_SNAP_CONFINE_FEATURES='%s'\n", strings.Join(snapdtool.SnapConfineFeatures(), " "))
+}
@@ -0,0 +1,98 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapdtool
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/snapcore/snapd/strutil"
+)
+
+// snapConfineFeatures are the features of snap-confine that snapd relies
+// on, together with the first version of snapd that shipped a snap-confine
+// supporting them. The version is only used for snap-confine info files that
+// predate the recording of SNAPD_SNAP_CONFINE_FEATURES.
+var snapConfineFeatures = []struct {
+	name  string
+	since string
+}{
+	{"parallel-instances", "2.36"},
+	{"per-user-mount-ns", "2.37"},
+	{"cgroup-v2-device-filter", "2.49"},
+}
+
+// SnapConfineFeatures returns the features of snap-confine that this snapd
+// relies on. They are recorded in the info file installed along
+// snap-confine as SNAPD_SNAP_CONFINE_FEATURES.
+func SnapConfineFeatures() []string {
+	features := make([]string, 0, len(snapConfineFeatures))
+	for _, f := range snapConfineFeatures {
+		features = append(features, f.name)
+	}
+	sort.Strings(features)
+	return features
+}
+
+// SnapConfineInfo describes the snap-confine installed along an info file.
+type SnapConfineInfo struct {
+	Version  string
+	Features []string
+}
+
+// SnapConfineInfoFromInfoFile returns the version and the features of the
+// snap-confine installed in the given dir, as recorded by its info file.
+func SnapConfineInfoFromInfoFile(dir string) (*SnapConfineInfo, error) {
+	version, flags, err := SnapdVersionFromInfoFile(dir)
+	if err != nil {
+		return nil, err
+	}
+	sci := &SnapConfineInfo{Version: version}
+	if features, ok := flags["SNAPD_SNAP_CONFINE_FEATURES"]; ok {
+		sci.Features = strings.Fields(strings.Trim(features, "'"))
+		sort.Strings(sci.Features)
+		return sci, nil
+	}
+	// derive the features from the version for older info files
+	for _, f := range snapConfineFeatures {
+		cmp, err := strutil.VersionCompare(version, f.since)
+		if err != nil {
+			return nil, fmt.Errorf("cannot determine snap-confine features: %v", err)
+		}
+		if cmp >= 0 {
+			sci.Features = append(sci.Features, f.name)
+		}
+	}
+	sort.Strings(sci.Features)
+	return sci, nil
+}
+
+// MissingFeatures returns the features snapd relies on that are not
+// supported by the snap-confine.
+func (sci *SnapConfineInfo) MissingFeatures() []string {
+	var missing []string
+	for _, f := range SnapConfineFeatures() {
+		if !strutil.SortedListContains(sci.Features, f) {
+			missing = append(missing, f)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,97 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapdtool
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/snap/naming"
+)
+
+// maxConfineDebugInvocations limits for how many invocations of a snap the
+// verbose logging of snap-confine can be enabled at once.
+const maxConfineDebugInvocations = 1000
+
+func confineDebugFile(instanceName string) string {
+	return filepath.Join(dirs.SnapConfineDebugDir, instanceName)
+}
+
+// EnableConfineDebug enables verbose logging of snap-confine and
+// snap-update-ns for the next given number of invocations of the snap. The
+// control file is consumed by snap-confine, which decrements the count on
+// every invocation of the snap. Zero invocations disables verbose logging.
+func EnableConfineDebug(instanceName string, invocations int) error {
+	if err := naming.ValidateInstance(instanceName); err != nil {
+		return err
+	}
+	if invocations < 0 || invocations > maxConfineDebugInvocations {
+		return fmt.Errorf("invalid number of invocations %d, must be between 0 and %d", invocations, maxConfineDebugInvocations)
+	}
+	if invocations == 0 {
+		if err := os.Remove(confineDebugFile(instanceName)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(dirs.SnapConfineDebugDir, 0755); err != nil {
+		return err
+	}
+	content := []byte(strconv.Itoa(invocations) + "\n")
+	return osutil.AtomicWriteFile(confineDebugFile(instanceName), content, 0644, 0)
+}
+
+// ConfineDebugStatus returns the snaps for which verbose logging of
+// snap-confine is enabled, together with the number of remaining
+// invocations.
+func ConfineDebugStatus() (map[string]int, error) {
+	entries, err := os.ReadDir(dirs.SnapConfineDebugDir)
+	if os.IsNotExist(err) {
+		return map[string]int{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	status := make(map[string]int, len(entries))
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || naming.ValidateInstance(entry.Name()) != nil {
+			continue
+		}
+		content, err := os.ReadFile(confineDebugFile(entry.Name()))
+		if os.IsNotExist(err) {
+			// consumed in the meantime
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		invocations, err := strconv.Atoi(strings.TrimSpace(string(content)))
+		if err != nil || invocations <= 0 {
+			continue
+		}
+		status[entry.Name()] = invocations
+	}
+	return status, nil
+}
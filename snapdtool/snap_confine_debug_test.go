@@ -0,0 +1,104 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapdtool_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/snapdtool"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type confineDebugSuite struct{}
+
+var _ = Suite(&confineDebugSuite{})
+
+func (s *confineDebugSuite) SetUpTest(c *C) {
+	dirs.SetRootDir(c.MkDir())
+}
+
+func (s *confineDebugSuite) TearDownTest(c *C) {
+	dirs.SetRootDir("")
+}
+
+func (s *confineDebugSuite) TestEnableConfineDebug(c *C) {
+	err := snapdtool.EnableConfineDebug("foo_bar", 3)
+	c.Assert(err, IsNil)
+	c.Check(filepath.Join(dirs.SnapConfineDebugDir, "foo_bar"), testutil.FileEquals, "3\n")
+
+	err = snapdtool.EnableConfineDebug("foo", 1)
+	c.Assert(err, IsNil)
+
+	status, err := snapdtool.ConfineDebugStatus()
+	c.Assert(err, IsNil)
+	c.Check(status, DeepEquals, map[string]int{"foo": 1, "foo_bar": 3})
+
+	// zero invocations disables debugging
+	err = snapdtool.EnableConfineDebug("foo_bar", 0)
+	c.Assert(err, IsNil)
+	c.Check(filepath.Join(dirs.SnapConfineDebugDir, "foo_bar"), testutil.FileAbsent)
+
+	// which is fine even if it was not enabled
+	err = snapdtool.EnableConfineDebug("foo_bar", 0)
+	c.Assert(err, IsNil)
+
+	status, err = snapdtool.ConfineDebugStatus()
+	c.Assert(err, IsNil)
+	c.Check(status, DeepEquals, map[string]int{"foo": 1})
+}
+
+func (s *confineDebugSuite) TestEnableConfineDebugErrors(c *C) {
+	err := snapdtool.EnableConfineDebug("Foo", 1)
+	c.Check(err, ErrorMatches, `invalid snap name: "Foo"`)
+
+	err = snapdtool.EnableConfineDebug("foo", -1)
+	c.Check(err, ErrorMatches, `invalid number of invocations -1, must be between 0 and 1000`)
+	err = snapdtool.EnableConfineDebug("foo", 1001)
+	c.Check(err, ErrorMatches, `invalid number of invocations 1001, must be between 0 and 1000`)
+
+	c.Check(dirs.SnapConfineDebugDir, testutil.FileAbsent)
+}
+
+func (s *confineDebugSuite) TestConfineDebugStatusNoDir(c *C) {
+	status, err := snapdtool.ConfineDebugStatus()
+	c.Assert(err, IsNil)
+	c.Check(status, HasLen, 0)
+}
+
+func (s *confineDebugSuite) TestConfineDebugStatusIgnoresGarbage(c *C) {
+	c.Assert(os.MkdirAll(dirs.SnapConfineDebugDir, 0755), IsNil)
+	for name, content := range map[string]string{
+		"foo":   "2\n",
+		"bar":   "garbage",
+		"baz":   "0",
+		"..foo": "1",
+	} {
+		c.Assert(os.WriteFile(filepath.Join(dirs.SnapConfineDebugDir, name), []byte(content), 0644), IsNil)
+	}
+	c.Assert(os.Mkdir(filepath.Join(dirs.SnapConfineDebugDir, "dir"), 0755), IsNil)
+
+	status, err := snapdtool.ConfineDebugStatus()
+	c.Assert(err, IsNil)
+	c.Check(status, DeepEquals, map[string]int{"foo": 2})
+}
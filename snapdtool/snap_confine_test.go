@@ -0,0 +1,85 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapdtool_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/snapdtool"
+)
+
+type snapConfineSuite struct{}
+
+var _ = Suite(&snapConfineSuite{})
+
+func writeInfo(c *C, content string) string {
+	top := c.MkDir()
+	c.Assert(os.WriteFile(filepath.Join(top, "info"), []byte(content), 0644), IsNil)
+	return top
+}
+
+func (s *snapConfineSuite) TestSnapConfineFeatures(c *C) {
+	c.Check(snapdtool.SnapConfineFeatures(), DeepEquals, []string{
+		"cgroup-v2-device-filter",
+		"parallel-instances",
+		"per-user-mount-ns",
+	})
+}
+
+func (s *snapConfineSuite) TestSnapConfineInfoFeaturesRecorded(c *C) {
+	dir := writeInfo(c, "VERSION=2.63\nSNAPD_APPARMOR_REEXEC=1\nSNAPD_SNAP_CONFINE_FEATURES='per-user-mount-ns parallel-instances'\n")
+
+	sci, err := snapdtool.SnapConfineInfoFromInfoFile(dir)
+	c.Assert(err, IsNil)
+	c.Check(sci, DeepEquals, &snapdtool.SnapConfineInfo{
+		Version:  "2.63",
+		Features: []string{"parallel-instances", "per-user-mount-ns"},
+	})
+	c.Check(sci.MissingFeatures(), DeepEquals, []string{"cgroup-v2-device-filter"})
+}
+
+func (s *snapConfineSuite) TestSnapConfineInfoFeaturesFromVersion(c *C) {
+	for _, t := range []struct {
+		version string
+		missing []string
+	}{
+		{"2.61.3", nil},
+		{"2.49", nil},
+		{"2.48.3+20.04", []string{"cgroup-v2-device-filter"}},
+		{"2.36.1", []string{"cgroup-v2-device-filter", "per-user-mount-ns"}},
+		{"2.30", []string{"cgroup-v2-device-filter", "parallel-instances", "per-user-mount-ns"}},
+	} {
+		sci, err := snapdtool.SnapConfineInfoFromInfoFile(writeInfo(c, "VERSION="+t.version+"\n"))
+		c.Assert(err, IsNil)
+		c.Check(sci.Version, Equals, t.version)
+		c.Check(sci.MissingFeatures(), DeepEquals, t.missing, Commentf(t.version))
+	}
+}
+
+func (s *snapConfineSuite) TestSnapConfineInfoErrors(c *C) {
+	_, err := snapdtool.SnapConfineInfoFromInfoFile("/non-existing-dir")
+	c.Check(err, ErrorMatches, `cannot open snapd info file "/non-existing-dir/info":.*`)
+
+	_, err = snapdtool.SnapConfineInfoFromInfoFile(writeInfo(c, "VERSION=1:2.63\n"))
+	c.Check(err, ErrorMatches, `cannot determine snap-confine features: invalid version "1:2.63"`)
+}
@@ -77,24 +77,32 @@ func DistroSupportsReExec() bool {
 // Ensure we do not use older version of snapd, look for info file and ignore
 // version of core that do not yet have it.
 func systemSnapSupportsReExec(coreOrSnapdPath string) bool {
+	ok, reason := systemSnapReExecSupport(coreOrSnapdPath)
+	if !ok {
+		logger.Debugf("%s", reason)
+	}
+	return ok
+}
+
+// systemSnapReExecSupport is like systemSnapSupportsReExec but also returns
+// why the given core/snapd snap cannot be used as re-exec target.
+func systemSnapReExecSupport(coreOrSnapdPath string) (ok bool, reason string) {
 	infoDir := filepath.Join(coreOrSnapdPath, filepath.Join(dirs.CoreLibExecDir))
 	ver, _, err := SnapdVersionFromInfoFile(infoDir)
 	if err != nil {
 		logger.Noticef("%v", err)
-		return false
+		return false, err.Error()
 	}
 
 	// > 0 means our Version is bigger than the version of snapd in core
 	res, err := strutil.VersionCompare(Version, ver)
 	if err != nil {
-		logger.Debugf("cannot version compare %q and %q: %v", Version, ver, err)
-		return false
+		return false, fmt.Sprintf("cannot version compare %q and %q: %v", Version, ver, err)
 	}
 	if res > 0 {
-		logger.Debugf("snap (at %q) is older (%q) than distribution package (%q)", coreOrSnapdPath, ver, Version)
-		return false
+		return false, fmt.Sprintf("snap (at %q) is older (%q) than distribution package (%q)", coreOrSnapdPath, ver, Version)
 	}
-	return true
+	return true, ""
 }
 
 // InternalToolPath returns the path of an internal snapd tool. The tool
@@ -139,11 +147,28 @@ func InternalToolPath(tool string) (string, error) {
 // IsReexecEnabled checks the environment and configuration to assert whether
 // reexec has been explicitly enabled/disabled.
 func IsReexecEnabled() bool {
-	// XXX for now we are only checking environment variables
+	return reexecDisabledReason() == ""
+}
 
+// reexecDisabledReason returns why re-exec was disabled, or an empty string
+// if it was not. The environment takes precedence over re-exec being
+// disabled until reboot, which takes precedence over the configuration.
+func reexecDisabledReason() string {
 	// If we are asked not to re-execute use distribution packages. This is
 	// "spiritual" re-exec so use the same environment variable to decide.
-	return osutil.GetenvBool(reExecKey, true)
+	if os.Getenv(reExecKey) != "" {
+		if !osutil.GetenvBool(reExecKey, true) {
+			return fmt.Sprintf("disabled through %s in the environment", reExecKey)
+		}
+		return ""
+	}
+	if osutil.FileExists(dirs.SnapdReexecDisabledRunFile) {
+		return "disabled until reboot"
+	}
+	if osutil.FileExists(dirs.SnapdReexecDisabledFile) {
+		return "disabled through the snapd.reexec system option"
+	}
+	return ""
 }
 
 // IsReexecExplicitlyEnabled is a stronger check than IsReexecEnabled as it
@@ -152,6 +177,22 @@ func IsReexecExplicitlyEnabled() bool {
 	return os.Getenv(reExecKey) != "" && IsReexecEnabled()
 }
 
+// DisableReexecUntilReboot disables, or enables again, re-exec into the snapd
+// or core snap until the next boot of the system. This is useful to debug the
+// distribution package. The change applies to processes started afterwards.
+func DisableReexecUntilReboot(disable bool) error {
+	if !disable {
+		if err := os.Remove(dirs.SnapdReexecDisabledRunFile); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(dirs.SnapRunDir, 0755); err != nil {
+		return err
+	}
+	return osutil.AtomicWriteFile(dirs.SnapdReexecDisabledRunFile, nil, 0644, 0)
+}
+
 // mustUnsetenv will unset the given environment key or panic if it
 // cannot do that
 func mustUnsetenv(key string) {
@@ -180,22 +221,38 @@ func ExecInSnapdOrCoreSnap() {
 		return
 	}
 
-	if !IsReexecEnabled() {
-		logger.Debugf("re-exec disabled by user")
+	// Did we already re-exec?
+	if strings.HasPrefix(rootDir, dirs.SnapMountDir) {
 		return
 	}
 
-	// Did we already re-exec?
-	if strings.HasPrefix(rootDir, dirs.SnapMountDir) {
+	full, reason := reexecTarget(exe)
+	if full == "" {
+		logger.Debugf("not re-executing: %s", reason)
 		return
 	}
 
+	logger.Debugf("restarting into %q", full)
+	panic(syscallExec(full, os.Args, os.Environ()))
+}
+
+// reexecTarget returns the path of the given executable in the snapd or core
+// snap the current process should re-execute into. If it should not, the path
+// is empty and the reason explains why.
+func reexecTarget(exe string) (full, reason string) {
+	if reason := reexecDisabledReason(); reason != "" {
+		return "", reason
+	}
+
 	// If the distribution doesn't support re-exec or run-from-core then don't do it.
 	if !DistroSupportsReExec() {
 		if IsReexecExplicitlyEnabled() {
 			logger.Debugf("reexec explicitly enabled through environment")
 		} else {
-			return
+			if !release.OnClassic {
+				return "", "not supported on Ubuntu Core"
+			}
+			return "", fmt.Sprintf("not supported on distribution %q", release.ReleaseInfo.ID)
 		}
 	}
 
@@ -204,22 +261,55 @@ func ExecInSnapdOrCoreSnap() {
 
 	// Is this executable in the core snap too?
 	coreOrSnapdPath := snapdSnap
-	full := filepath.Join(snapdSnap, exe)
+	full = filepath.Join(snapdSnap, exe)
 	if !osutil.FileExists(full) {
 		coreOrSnapdPath = coreSnap
 		full = filepath.Join(coreSnap, exe)
 		if !osutil.FileExists(full) {
-			return
+			return "", fmt.Sprintf("%s is not provided by the snapd or core snap", exe)
 		}
 	}
 
 	// If the core snap doesn't support re-exec or run-from-core then don't do it.
-	if !systemSnapSupportsReExec(coreOrSnapdPath) {
-		return
+	if ok, reason := systemSnapReExecSupport(coreOrSnapdPath); !ok {
+		return "", reason
 	}
 
-	logger.Debugf("restarting into %q", full)
-	panic(syscallExec(full, os.Args, os.Environ()))
+	return full, ""
+}
+
+// ReexecInfo describes whether the running tool re-executed into the snapd or
+// core snap, and why.
+type ReexecInfo struct {
+	// Exe is the path of the running executable.
+	Exe string `json:"exe"`
+	// Reexecd is set if the tool runs from the snapd or core snap.
+	Reexecd bool `json:"reexecd"`
+	// Reason explains why the tool did not re-execute.
+	Reason string `json:"reason,omitempty"`
+}
+
+// ReexecStatus returns whether the running tool re-executed into the snapd or
+// core snap and, if it did not, why.
+func ReexecStatus() (*ReexecInfo, error) {
+	rootDir, exe, err := exeAndRoot()
+	if err != nil {
+		return nil, err
+	}
+	info := &ReexecInfo{
+		Exe: filepath.Join(rootDir, exe),
+	}
+	if strings.HasPrefix(rootDir, dirs.SnapMountDir) {
+		info.Reexecd = true
+		return info, nil
+	}
+	full, reason := reexecTarget(exe)
+	if full != "" {
+		// the snap was installed after the tool was started
+		reason = fmt.Sprintf("%s was not available when started", full)
+	}
+	info.Reason = reason
+	return info, nil
 }
 
 // IsReexecd returns true when the current process binary is running from a snap.
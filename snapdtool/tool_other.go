@@ -70,3 +70,27 @@ func IsReexecEnabled() bool {
 func IsReexecExplicitlyEnabled() bool {
 	return false
 }
+
+// DisableReexecUntilReboot disables, or enables again, re-exec into the snapd
+// or core snap until the next boot of the system.
+//
+// On this OS this is a stub and always returns an error.
+func DisableReexecUntilReboot(disable bool) error {
+	return errUnsupported
+}
+
+// ReexecInfo describes whether the running tool re-executed into the snapd or
+// core snap, and why.
+type ReexecInfo struct {
+	Exe     string `json:"exe"`
+	Reexecd bool   `json:"reexecd"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// ReexecStatus returns whether the running tool re-executed into the snapd or
+// core snap and, if it did not, why.
+//
+// On this OS this is a stub and always returns an error.
+func ReexecStatus() (*ReexecInfo, error) {
+	return nil, errUnsupported
+}
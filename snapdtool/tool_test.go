@@ -460,6 +460,101 @@ func (s *toolSuite) TestExecInSnapdOrCoreSnapDisabled(c *C) {
 	c.Check(s.execCalled, Equals, 0)
 }
 
+func (s *toolSuite) TestExecInSnapdOrCoreSnapDisabledUntilReboot(c *C) {
+	defer s.mockReExecFor(c, s.snapdPath, "potato")()
+	os.Unsetenv("SNAP_REEXEC")
+
+	c.Assert(snapdtool.DisableReexecUntilReboot(true), IsNil)
+	c.Check(dirs.SnapdReexecDisabledRunFile, testutil.FilePresent)
+	c.Check(snapdtool.IsReexecEnabled(), Equals, false)
+	snapdtool.ExecInSnapdOrCoreSnap()
+	c.Check(s.execCalled, Equals, 0)
+
+	// the environment takes precedence
+	os.Setenv("SNAP_REEXEC", "1")
+	c.Check(snapdtool.ExecInSnapdOrCoreSnap, PanicMatches, `>exec of "[^"]+/potato" in tests<`)
+	c.Check(s.execCalled, Equals, 1)
+	os.Unsetenv("SNAP_REEXEC")
+
+	c.Assert(snapdtool.DisableReexecUntilReboot(false), IsNil)
+	c.Check(dirs.SnapdReexecDisabledRunFile, testutil.FileAbsent)
+	c.Check(snapdtool.ExecInSnapdOrCoreSnap, PanicMatches, `>exec of "[^"]+/potato" in tests<`)
+	c.Check(s.execCalled, Equals, 2)
+	// enabling again is idempotent
+	c.Assert(snapdtool.DisableReexecUntilReboot(false), IsNil)
+}
+
+func (s *toolSuite) TestExecInSnapdOrCoreSnapDisabledByConfig(c *C) {
+	defer s.mockReExecFor(c, s.snapdPath, "potato")()
+	os.Unsetenv("SNAP_REEXEC")
+
+	c.Assert(os.MkdirAll(filepath.Dir(dirs.SnapdReexecDisabledFile), 0755), IsNil)
+	c.Assert(os.WriteFile(dirs.SnapdReexecDisabledFile, nil, 0644), IsNil)
+
+	c.Check(snapdtool.IsReexecEnabled(), Equals, false)
+	snapdtool.ExecInSnapdOrCoreSnap()
+	c.Check(s.execCalled, Equals, 0)
+}
+
+func (s *toolSuite) TestReexecStatus(c *C) {
+	defer s.mockReExecFor(c, s.snapdPath, "potato")()
+	os.Unsetenv("SNAP_REEXEC")
+	exe := filepath.Join(s.fakeroot, "/usr/lib/snapd/potato")
+
+	// the snapd snap was installed after the tool was started
+	info, err := snapdtool.ReexecStatus()
+	c.Assert(err, IsNil)
+	c.Check(info, DeepEquals, &snapdtool.ReexecInfo{
+		Exe:    exe,
+		Reason: filepath.Join(s.snapdPath, "usr/lib/snapd/potato") + " was not available when started",
+	})
+
+	for _, tc := range []struct {
+		setup  func()
+		reason string
+	}{{
+		setup:  func() { os.Setenv("SNAP_REEXEC", "0") },
+		reason: "disabled through SNAP_REEXEC in the environment",
+	}, {
+		setup:  func() { c.Assert(snapdtool.DisableReexecUntilReboot(true), IsNil) },
+		reason: "disabled until reboot",
+	}, {
+		setup:  func() { release.MockReleaseInfo(&release.OS{ID: "arch"}) },
+		reason: `not supported on distribution "arch"`,
+	}, {
+		setup:  func() { snapdtool.MockVersion("43") },
+		reason: `snap \(at ".*/snapd/42"\) is older \("42"\) than distribution package \("43"\)`,
+	}} {
+		restoreVersion := snapdtool.MockVersion("2")
+		restoreRelease := release.MockReleaseInfo(&release.OS{ID: "ubuntu"})
+		tc.setup()
+
+		info, err := snapdtool.ReexecStatus()
+		c.Assert(err, IsNil)
+		c.Check(info.Exe, Equals, exe)
+		c.Check(info.Reexecd, Equals, false)
+		c.Check(info.Reason, Matches, tc.reason)
+
+		os.Unsetenv("SNAP_REEXEC")
+		c.Assert(snapdtool.DisableReexecUntilReboot(false), IsNil)
+		restoreRelease()
+		restoreVersion()
+	}
+}
+
+func (s *toolSuite) TestReexecStatusReexecd(c *C) {
+	selfExe := filepath.Join(s.fakeroot, "proc/self/exe")
+	defer snapdtool.MockSelfExe(selfExe)()
+	c.Assert(os.Symlink(filepath.Join(s.snapdPath, "usr/lib/snapd/snapd"), selfExe), IsNil)
+
+	info, err := snapdtool.ReexecStatus()
+	c.Assert(err, IsNil)
+	c.Check(info, DeepEquals, &snapdtool.ReexecInfo{
+		Exe:     filepath.Join(s.snapdPath, "usr/lib/snapd/snapd"),
+		Reexecd: true,
+	})
+}
+
 func (s *toolSuite) TestExecInSnapdOrCoreSnapOnUnsupportedDistro(c *C) {
 	// TODO pay attention to libexecdir when enabling reexec on non-Ubuntu
 	// with /usr/libexec/
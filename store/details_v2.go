@@ -58,6 +58,7 @@ type storeSnap struct {
 	Website       string              `json:"website"`
 	StoreURL      string              `json:"store-url"`
 	Resources     []storeResource     `json:"resources"`
+	Tracks        []storeSnapTrack    `json:"tracks"`
 
 	// TODO: not yet defined: channel map
 
@@ -102,6 +103,12 @@ type storeSnapMedia struct {
 	Height int64  `json:"height"`
 }
 
+type storeSnapTrack struct {
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	EndOfLife time.Time `json:"end-of-life"`
+}
+
 type storeSnapCategory struct {
 	Featured bool   `json:"featured"`
 	Name     string `json:"name"`
@@ -280,6 +287,9 @@ func copyNonZeroFrom(src, dst *storeSnap) {
 	if len(src.Resources) > 0 {
 		dst.Resources = src.Resources
 	}
+	if len(src.Tracks) > 0 {
+		dst.Tracks = src.Tracks
+	}
 }
 
 func infoFromStoreSnap(d *storeSnap) (*snap.Info, error) {
@@ -351,6 +361,8 @@ func infoFromStoreSnap(d *storeSnap) (*snap.Info, error) {
 
 	addCategories(info, d.Categories)
 
+	addTracks(info, d.Tracks)
+
 	return info, nil
 }
 
@@ -442,3 +454,21 @@ func addCategories(info *snap.Info, categories []storeSnapCategory) {
 		info.Categories[i].Name = category.Name
 	}
 }
+
+func addTracks(info *snap.Info, tracks []storeSnapTrack) {
+	if len(tracks) == 0 {
+		return
+	}
+	info.TrackInfo = make(map[string]*snap.TrackInfo, len(tracks))
+	for _, t := range tracks {
+		var eol time.Time
+		if !t.EndOfLife.IsZero() {
+			eol = t.EndOfLife.UTC()
+		}
+		info.TrackInfo[t.Name] = &snap.TrackInfo{
+			Name:      t.Name,
+			Status:    t.Status,
+			EndOfLife: eol,
+		}
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"encoding/json"
 	"reflect"
 	"strings"
+	"time"
 
 	. "gopkg.in/check.v1"
 
@@ -175,6 +176,10 @@ const (
   "store-url": "https://snapcraft.io/thingy",
   "summary": "useful thingy",
   "title": "This Is The Most Fantastical Snap of Thingy",
+  "tracks": [
+     {"name": "latest", "status": "default"},
+     {"name": "1.0", "status": "closed", "end-of-life": "2024-04-30T00:00:00Z"}
+  ],
   "type": "app",
   "version": "9.50",
   "website": "http://example.com/thingy",
@@ -359,6 +364,10 @@ func (s *detailsV2Suite) TestInfoFromStoreSnap(c *C) {
 		},
 		StoreURL:       "https://snapcraft.io/thingy",
 		SnapProvenance: "prov",
+		TrackInfo: map[string]*snap.TrackInfo{
+			"latest": {Name: "latest", Status: "default"},
+			"1.0":    {Name: "1.0", Status: "closed", EndOfLife: time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC)},
+		},
 		// empty
 		BadInterfaces:   map[string]string{},
 		SystemUsernames: map[string]*snap.SystemUsernameInfo{},
@@ -529,6 +538,11 @@ func fillStruct(a interface{}, c *C) {
 				Name:     "some-component",
 				Revision: 1,
 			}}
+		case []storeSnapTrack:
+			x = []storeSnapTrack{{
+				Name:   "latest",
+				Status: "default",
+			}}
 		default:
 			c.Fatalf("unhandled field type %T", field.Interface())
 		}
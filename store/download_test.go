@@ -708,3 +708,31 @@ func (s *downloadSuite) TestActualDownloadRateLimited(c *C) {
 	c.Check(buf.String(), Equals, canary)
 	c.Check(ratelimitReaderUsed, Equals, true)
 }
+
+func (s *downloadSuite) TestActualDownloadRateLimitShared(c *C) {
+	var buckets []*ratelimit.Bucket
+	restore := store.MockRatelimitReader(func(r io.Reader, bucket *ratelimit.Bucket) io.Reader {
+		buckets = append(buckets, bucket)
+		return r
+	})
+	defer restore()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "downloaded data")
+	}))
+	defer ts.Close()
+
+	theStore := store.New(&store.Config{}, nil)
+	for _, rate := range []int64{1024, 1024, 2048} {
+		var buf SillyBuffer
+		err := store.Download(context.TODO(), "example-name", "", ts.URL, nil, theStore, &buf, 0, nil, &store.DownloadOptions{RateLimit: rate})
+		c.Assert(err, IsNil)
+	}
+	c.Assert(buckets, HasLen, 3)
+	// downloads at the same rate share the bucket limiting them
+	c.Check(buckets[0], Equals, buckets[1])
+	c.Check(int64(buckets[0].Rate()), Equals, int64(1024))
+	// which is replaced when the rate changes
+	c.Check(buckets[2], Not(Equals), buckets[0])
+	c.Check(int64(buckets[2].Rate()), Equals, int64(2048))
+}
@@ -25,6 +25,7 @@ import (
 	"net/url"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/snapcore/snapd/snap/channel"
 	"github.com/snapcore/snapd/strutil"
@@ -70,6 +71,17 @@ var (
 	ErrNoUpdateAvailable = errors.New("snap has no updates available")
 )
 
+// StoreUnavailableError is returned when requests to a store endpoint are
+// suspended after it failed repeatedly.
+type StoreUnavailableError struct {
+	Endpoint   string
+	RetryAfter time.Duration
+}
+
+func (e *StoreUnavailableError) Error() string {
+	return fmt.Sprintf("store endpoint %s is temporarily unavailable, retry after %s", e.Endpoint, e.RetryAfter.Round(time.Second))
+}
+
 // RevisionNotAvailableError is returned when an install is attempted for a snap but the/a revision is not available (given install constraints).
 type RevisionNotAvailableError struct {
 	Action   string
@@ -81,6 +93,20 @@ func (e *RevisionNotAvailableError) Error() string {
 	return "no snap revision available as specified"
 }
 
+// TrackClosedError is returned when a refresh is attempted for a snap that
+// tracks a channel of a track that was closed in the store.
+type TrackClosedError struct {
+	Snap  string
+	Track string
+	// DefaultTrack is the default track of the snap, if the store
+	// publishes one.
+	DefaultTrack string
+}
+
+func (e *TrackClosedError) Error() string {
+	return fmt.Sprintf("track %q of snap %q is closed", e.Track, e.Snap)
+}
+
 // DownloadError represents a download error
 type DownloadError struct {
 	Code int
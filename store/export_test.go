@@ -80,6 +80,38 @@ func MockDefaultRetryStrategy(t *testutil.BaseTest, strategy retry.Strategy) {
 	})
 }
 
+type RetryGate = retryGate
+
+var NewRetryGate = newRetryGate
+
+func MockTimeNow(f func() time.Time) (restore func()) {
+	return testutil.Mock(&timeNow, f)
+}
+
+// MockRetryBudget mocks the size and refill rates of the store retry budget.
+func MockRetryBudget(max, refillRate, successRatio float64) (restore func()) {
+	restoreMax := testutil.Mock(&retryBudgetMax, max)
+	restoreRate := testutil.Mock(&retryBudgetRefillRate, refillRate)
+	restoreRatio := testutil.Mock(&retryBudgetSuccessRatio, successRatio)
+	return func() {
+		restoreMax()
+		restoreRate()
+		restoreRatio()
+	}
+}
+
+// MockCircuitBreaker mocks when store endpoints are suspended and for how long.
+func MockCircuitBreaker(threshold int, cooldown, maxCooldown time.Duration) (restore func()) {
+	restoreThreshold := testutil.Mock(&circuitBreakerThreshold, threshold)
+	restoreCooldown := testutil.Mock(&circuitBreakerCooldown, cooldown)
+	restoreMaxCooldown := testutil.Mock(&circuitBreakerMaxCooldown, maxCooldown)
+	return func() {
+		restoreThreshold()
+		restoreCooldown()
+		restoreMaxCooldown()
+	}
+}
+
 func MockDownloadRetryStrategy(t *testutil.BaseTest, strategy retry.Strategy) {
 	originalDownloadRetryStrategy := downloadRetryStrategy
 	downloadRetryStrategy = strategy
@@ -149,6 +181,14 @@ func MockDownload(f func(ctx context.Context, name, sha3_384, downloadURL string
 	}
 }
 
+func MockParallelDownloadChunkSize(size int64) (restore func()) {
+	old := parallelDownloadChunkSize
+	parallelDownloadChunkSize = size
+	return func() {
+		parallelDownloadChunkSize = old
+	}
+}
+
 func MockDoDownloadReq(f func(ctx context.Context, storeURL *url.URL, cdnHeader string, resume int64, s *Store, user *auth.UserState) (*http.Response, error)) (restore func()) {
 	orig := doDownloadReq
 	doDownloadReq = f
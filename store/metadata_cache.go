@@ -0,0 +1,216 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/overlord/auth"
+)
+
+// metadataCache caches the responses to store metadata requests on disk,
+// together with the ETag the store sent for them. Responses younger than ttl
+// are used without contacting the store, older ones are revalidated with
+// If-None-Match so that unchanged metadata is not downloaded again. Only
+// snap details are cached, snap action responses are not as snaps are
+// downloaded from the URLs they carry.
+type metadataCache struct {
+	dir      string
+	ttl      time.Duration
+	maxItems int
+}
+
+type metadataCacheEntry struct {
+	ETag   string      `json:"etag,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body"`
+	Stored time.Time   `json:"stored"`
+}
+
+func newMetadataCache(dir string, ttl time.Duration, maxItems int) *metadataCache {
+	return &metadataCache{
+		dir:      dir,
+		ttl:      ttl,
+		maxItems: maxItems,
+	}
+}
+
+// SetMetadataCache sets up caching of store metadata responses, keeping at
+// most size of them and revalidating them with the store once older than
+// ttl. A size of 0 disables the cache.
+func (s *Store) SetMetadataCache(size int, ttl time.Duration) {
+	s.cfg.MetadataCacheSize = size
+	s.cfg.MetadataCacheTTL = ttl
+	if size > 0 {
+		s.metadataCache = newMetadataCache(dirs.SnapStoreMetadataCacheDir, ttl, size)
+	} else {
+		s.metadataCache = nil
+	}
+}
+
+// metadataCacheKey returns the key of the cached response to the given
+// request made on behalf of the given user.
+func metadataCacheKey(reqOptions *requestOptions, user *auth.UserState) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\n", reqOptions.Method, reqOptions.URL)
+	headers := make([]string, 0, len(reqOptions.ExtraHeaders))
+	for k := range reqOptions.ExtraHeaders {
+		headers = append(headers, k)
+	}
+	sort.Strings(headers)
+	for _, k := range headers {
+		fmt.Fprintf(h, "%s: %s\n", k, reqOptions.ExtraHeaders[k])
+	}
+	if user != nil {
+		fmt.Fprintf(h, "user: %d\n", user.ID)
+	}
+	h.Write(reqOptions.Data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (mc *metadataCache) path(key string) string {
+	return filepath.Join(mc.dir, key)
+}
+
+// get returns the cached response with the given key, or nil.
+func (mc *metadataCache) get(key string) *metadataCacheEntry {
+	data, err := os.ReadFile(mc.path(key))
+	if err != nil {
+		return nil
+	}
+	var entry metadataCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		logger.Debugf("cannot decode cached store response %s: %v", key, err)
+		return nil
+	}
+	return &entry
+}
+
+// fresh returns whether the cached response can be used without
+// revalidating it with the store.
+func (mc *metadataCache) fresh(entry *metadataCacheEntry) bool {
+	return time.Since(entry.Stored) < mc.ttl
+}
+
+// put stores the response with the given key, dropping the oldest responses
+// if the cache holds more than maxItems.
+func (mc *metadataCache) put(key string, entry *metadataCacheEntry) error {
+	// always try to create the cache dir first or the following
+	// osutil.IsWritable will always fail if the dir is missing
+	_ = os.MkdirAll(mc.dir, 0700)
+
+	if !osutil.IsWritable(mc.dir) {
+		return nil
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := osutil.AtomicWriteFile(mc.path(key), data, 0600, 0); err != nil {
+		return err
+	}
+	return mc.cleanup()
+}
+
+func (mc *metadataCache) cleanup() error {
+	entries, err := os.ReadDir(mc.dir)
+	if err != nil {
+		return err
+	}
+	if len(entries) <= mc.maxItems {
+		return nil
+	}
+
+	infos := make([]os.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		fi, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		infos = append(infos, fi)
+	}
+	sort.Sort(changesByMtime(infos))
+
+	var lastErr error
+	for _, fi := range infos[:len(infos)-mc.maxItems] {
+		if err := osRemove(mc.path(fi.Name())); err != nil && !os.IsNotExist(err) {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// response returns a response for the request answered from the cache.
+func (entry *metadataCacheEntry) response(reqOptions *requestOptions) *http.Response {
+	return &http.Response{
+		StatusCode: 200,
+		Header:     entry.Header,
+		Request:    &http.Request{Method: reqOptions.Method, URL: reqOptions.URL},
+	}
+}
+
+// decodeAndCacheJSONBody decodes the response like decodeJSONBody, caching
+// successful responses and answering not modified ones from the given cached
+// entry.
+func (s *Store) decodeAndCacheJSONBody(key string, cached *metadataCacheEntry, resp *http.Response, success interface{}, failure interface{}) error {
+	switch {
+	case resp.StatusCode == 304 && cached != nil:
+		logger.Debugf("Store response for %s was not modified, using the cached one.", resp.Request.URL)
+		resp.StatusCode = 200
+		resp.Header = cached.Header
+		cached.Stored = time.Now()
+		if err := s.metadataCache.put(key, cached); err != nil {
+			logger.Noticef("cannot update store metadata cache: %v", err)
+		}
+		return json.Unmarshal(cached.Body, success)
+	case resp.StatusCode == 200:
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(body, success); err != nil {
+			return err
+		}
+		entry := &metadataCacheEntry{
+			ETag:   resp.Header.Get("ETag"),
+			Header: resp.Header,
+			Body:   body,
+			Stored: time.Now(),
+		}
+		if err := s.metadataCache.put(key, entry); err != nil {
+			logger.Noticef("cannot update store metadata cache: %v", err)
+		}
+		return nil
+	}
+	return decodeJSONBody(resp, success, failure)
+}
@@ -0,0 +1,198 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package store
+
+import (
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/randutil"
+)
+
+var (
+	// retryBudgetMax is the number of retries that can be made in a
+	// burst, across all the store endpoints.
+	retryBudgetMax = 20.0
+	// retryBudgetRefillRate is the number of retries regained per second.
+	retryBudgetRefillRate = 0.1
+	// retryBudgetSuccessRatio is the number of retries regained per
+	// successful request.
+	retryBudgetSuccessRatio = 0.1
+
+	// circuitBreakerThreshold is the number of consecutive failed
+	// requests to an endpoint after which requests to it are suspended.
+	circuitBreakerThreshold = 10
+	// circuitBreakerCooldown is how long requests to an endpoint are
+	// first suspended for, doubling every time the endpoint still fails
+	// afterwards, up to circuitBreakerMaxCooldown.
+	circuitBreakerCooldown    = 30 * time.Second
+	circuitBreakerMaxCooldown = 10 * time.Minute
+
+	timeNow = time.Now
+)
+
+// circuitBreaker tracks the failures of a single store endpoint.
+type circuitBreaker struct {
+	failures int
+	// openUntil is the time until which no requests are made, once
+	// the breaker has tripped
+	openUntil time.Time
+	cooldown  time.Duration
+	// probing is set while a single request is let through to check
+	// whether the endpoint has recovered
+	probing bool
+}
+
+// retryGate implements httputil.RetryGate for the requests of a Store. It
+// holds a retry budget shared by all the endpoints, so that a degraded store
+// is not hammered with retries, and a circuit breaker per endpoint, so that
+// an endpoint that keeps failing is given time to recover. The cooldowns
+// are jittered so that devices do not come back to the store all at once.
+type retryGate struct {
+	mu sync.Mutex
+
+	tokens     float64
+	lastRefill time.Time
+
+	breakers map[string]*circuitBreaker
+}
+
+func newRetryGate() *retryGate {
+	return &retryGate{
+		tokens:     retryBudgetMax,
+		lastRefill: timeNow(),
+		breakers:   make(map[string]*circuitBreaker),
+	}
+}
+
+// endpointKey returns the endpoint of the given request URL, ignoring its
+// query.
+func endpointKey(endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return endpoint
+	}
+	return u.Scheme + "://" + u.Host + u.Path
+}
+
+func (g *retryGate) addTokens(tokens float64) {
+	g.tokens += tokens
+	if g.tokens > retryBudgetMax {
+		g.tokens = retryBudgetMax
+	}
+}
+
+func (g *retryGate) refill() {
+	now := timeNow()
+	if elapsed := now.Sub(g.lastRefill); elapsed > 0 {
+		g.addTokens(elapsed.Seconds() * retryBudgetRefillRate)
+	}
+	g.lastRefill = now
+}
+
+// Allow returns an error if requests to the endpoint are suspended.
+func (g *retryGate) Allow(endpoint string) error {
+	key := endpointKey(endpoint)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	b := g.breakers[key]
+	if b == nil || b.openUntil.IsZero() {
+		return nil
+	}
+	now := timeNow()
+	if now.Before(b.openUntil) || b.probing {
+		retryAfter := b.openUntil.Sub(now)
+		if retryAfter < 0 {
+			retryAfter = 0
+		}
+		return &StoreUnavailableError{Endpoint: key, RetryAfter: retryAfter}
+	}
+	// let a single request through to probe the endpoint
+	b.probing = true
+	return nil
+}
+
+// AllowRetry returns whether a failed request to the endpoint can be
+// retried, consuming the retry budget if so.
+func (g *retryGate) AllowRetry(endpoint string) bool {
+	key := endpointKey(endpoint)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if b := g.breakers[key]; b != nil && !b.openUntil.IsZero() {
+		return false
+	}
+	g.refill()
+	if g.tokens < 1 {
+		logger.Debugf("retry budget exhausted, not retrying %s", key)
+		return false
+	}
+	g.tokens--
+	return true
+}
+
+// Record records the outcome of a request to the endpoint, tripping its
+// circuit breaker after too many consecutive failures.
+func (g *retryGate) Record(endpoint string, failed bool) {
+	key := endpointKey(endpoint)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	b := g.breakers[key]
+	if !failed {
+		g.addTokens(retryBudgetSuccessRatio)
+		if b != nil {
+			if !b.openUntil.IsZero() {
+				logger.Noticef("store endpoint %s recovered", key)
+			}
+			delete(g.breakers, key)
+		}
+		return
+	}
+
+	if b == nil {
+		b = &circuitBreaker{}
+		g.breakers[key] = b
+	}
+	b.failures++
+	switch {
+	case b.probing:
+		// the endpoint is still failing
+		b.probing = false
+		b.cooldown *= 2
+		if b.cooldown > circuitBreakerMaxCooldown {
+			b.cooldown = circuitBreakerMaxCooldown
+		}
+	case b.openUntil.IsZero() && b.failures >= circuitBreakerThreshold:
+		b.cooldown = circuitBreakerCooldown
+	default:
+		return
+	}
+	// wait between the cooldown and half of it again
+	cooldown := b.cooldown + randutil.RandomDuration(b.cooldown/2)
+	b.openUntil = timeNow().Add(cooldown)
+	logger.Noticef("suspending requests to store endpoint %s for %s after %d consecutive failures", key, cooldown.Round(time.Second), b.failures)
+}
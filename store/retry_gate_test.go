@@ -0,0 +1,126 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package store_test
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/store"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type retryGateSuite struct {
+	testutil.BaseTest
+
+	now time.Time
+}
+
+var _ = Suite(&retryGateSuite{})
+
+const (
+	infoEndpoint = "https://api.snapcraft.io/v2/snaps/info/hello"
+	findEndpoint = "https://api.snapcraft.io/v2/snaps/find"
+)
+
+func (s *retryGateSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+
+	s.now = time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	s.AddCleanup(store.MockTimeNow(func() time.Time { return s.now }))
+	s.AddCleanup(store.MockRetryBudget(3, 0.5, 0.5))
+	s.AddCleanup(store.MockCircuitBreaker(2, time.Minute, 3*time.Minute))
+}
+
+func (s *retryGateSuite) TestRetryBudget(c *C) {
+	g := store.NewRetryGate()
+
+	// the budget is shared by all endpoints
+	c.Check(g.AllowRetry(infoEndpoint), Equals, true)
+	c.Check(g.AllowRetry(findEndpoint), Equals, true)
+	c.Check(g.AllowRetry(infoEndpoint+"?fields=revision"), Equals, true)
+	c.Check(g.AllowRetry(findEndpoint), Equals, false)
+
+	// and refills over time
+	s.now = s.now.Add(2 * time.Second)
+	c.Check(g.AllowRetry(findEndpoint), Equals, true)
+	c.Check(g.AllowRetry(findEndpoint), Equals, false)
+
+	// and with successful requests
+	g.Record(findEndpoint, false)
+	c.Check(g.AllowRetry(findEndpoint), Equals, false)
+	g.Record(findEndpoint, false)
+	c.Check(g.AllowRetry(findEndpoint), Equals, true)
+}
+
+func (s *retryGateSuite) TestCircuitBreaker(c *C) {
+	g := store.NewRetryGate()
+
+	g.Record(infoEndpoint, true)
+	c.Check(g.Allow(infoEndpoint), IsNil)
+	// a success resets the consecutive failures
+	g.Record(infoEndpoint, false)
+	g.Record(infoEndpoint, true)
+	c.Check(g.Allow(infoEndpoint), IsNil)
+
+	g.Record(infoEndpoint+"?fields=revision", true)
+	err := g.Allow(infoEndpoint)
+	c.Assert(err, FitsTypeOf, &store.StoreUnavailableError{})
+	unavailErr := err.(*store.StoreUnavailableError)
+	c.Check(unavailErr.Endpoint, Equals, infoEndpoint)
+	// the cooldown is jittered
+	c.Check(unavailErr.RetryAfter >= time.Minute, Equals, true)
+	c.Check(unavailErr.RetryAfter < 90*time.Second, Equals, true)
+	// failed requests are not retried
+	c.Check(g.AllowRetry(infoEndpoint), Equals, false)
+
+	// other endpoints are not affected
+	c.Check(g.Allow(findEndpoint), IsNil)
+	c.Check(g.AllowRetry(findEndpoint), Equals, true)
+
+	// after the cooldown a single request probes the endpoint
+	s.now = s.now.Add(90 * time.Second)
+	c.Check(g.Allow(infoEndpoint), IsNil)
+	c.Check(g.Allow(infoEndpoint), ErrorMatches, `store endpoint https://api.snapcraft.io/v2/snaps/info/hello is temporarily unavailable, retry after 0s`)
+
+	// it still fails so the cooldown doubles
+	g.Record(infoEndpoint, true)
+	err = g.Allow(infoEndpoint)
+	c.Assert(err, FitsTypeOf, &store.StoreUnavailableError{})
+	c.Check(err.(*store.StoreUnavailableError).RetryAfter >= 2*time.Minute, Equals, true)
+
+	// up to the maximum
+	s.now = s.now.Add(3 * time.Minute)
+	c.Check(g.Allow(infoEndpoint), IsNil)
+	g.Record(infoEndpoint, true)
+	err = g.Allow(infoEndpoint)
+	c.Assert(err, FitsTypeOf, &store.StoreUnavailableError{})
+	c.Check(err.(*store.StoreUnavailableError).RetryAfter >= 3*time.Minute, Equals, true)
+	c.Check(err.(*store.StoreUnavailableError).RetryAfter < 270*time.Second, Equals, true)
+
+	// the endpoint recovers
+	s.now = s.now.Add(270 * time.Second)
+	c.Check(g.Allow(infoEndpoint), IsNil)
+	g.Record(infoEndpoint, false)
+	c.Check(g.Allow(infoEndpoint), IsNil)
+	c.Check(g.Allow(infoEndpoint), IsNil)
+	c.Check(g.AllowRetry(infoEndpoint), Equals, true)
+}
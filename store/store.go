@@ -37,6 +37,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/juju/ratelimit"
 	"gopkg.in/retry.v1"
 
 	"github.com/snapcore/snapd/arch"
@@ -76,6 +77,8 @@ var defaultRetryStrategy = retry.LimitCount(6, retry.LimitTime(38*time.Second,
 	retry.Exponential{
 		Initial: 500 * time.Millisecond,
 		Factor:  2.5,
+		// spread the retries of many devices hitting a degraded store
+		Jitter: true,
 	},
 ))
 
@@ -112,6 +115,13 @@ type Config struct {
 	// CacheDownloads is the number of downloads that should be cached
 	CacheDownloads int
 
+	// MetadataCacheSize is the number of store metadata responses that
+	// should be cached, revalidating them with the store via their ETag
+	MetadataCacheSize int
+	// MetadataCacheTTL is how long cached store metadata responses are
+	// used without revalidating them with the store
+	MetadataCacheTTL time.Duration
+
 	// Proxy returns the HTTP proxy to use when talking to the store
 	Proxy func(*http.Request) (*url.URL, error)
 
@@ -163,9 +173,16 @@ type Store struct {
 
 	mu                sync.Mutex
 	suggestedCurrency string
+	// shared by the rate limited downloads
+	downloadBucket     *ratelimit.Bucket
+	downloadBucketRate int64
 
 	cacher downloadCache
 
+	metadataCache *metadataCache
+
+	retryGate *retryGate
+
 	proxy              func(*http.Request) (*url.URL, error)
 	proxyConnectHeader http.Header
 
@@ -323,7 +340,7 @@ func init() {
 	defaultConfig.DetailFields = jsonutil.StructFields((*snapDetails)(nil), "snap_yaml_raw")
 	defaultConfig.InfoFields = jsonutil.StructFields((*storeSnap)(nil), "snap-yaml")
 	defaultConfig.FindFields = append(jsonutil.StructFields((*storeSnap)(nil),
-		"architectures", "created-at", "epoch", "name", "snap-id", "snap-yaml", "resources"),
+		"architectures", "created-at", "epoch", "name", "snap-id", "snap-yaml", "resources", "tracks"),
 		"channel")
 }
 
@@ -411,6 +428,7 @@ func New(cfg *Config, dauthCtx DeviceAndAuthContext) *Store {
 		proxy:              cfg.Proxy,
 		proxyConnectHeader: proxyConnectHeader,
 		userAgent:          userAgent,
+		retryGate:          newRetryGate(),
 	}
 	store.client = store.newHTTPClient(&httputil.ClientOptions{
 		Timeout:    requestTimeout,
@@ -427,6 +445,7 @@ func New(cfg *Config, dauthCtx DeviceAndAuthContext) *Store {
 	store.auth = auth
 
 	store.SetCacheDownloads(cfg.CacheDownloads)
+	store.SetMetadataCache(cfg.MetadataCacheSize, cfg.MetadataCacheTTL)
 
 	return store
 }
@@ -605,6 +624,11 @@ type requestOptions struct {
 	//  - deviceAuthCustomStoreOnly: should be provided only in case
 	//    of a custom store
 	DeviceAuthNeed deviceAuthNeed
+
+	// Cacheable indicates that the response can be served from and
+	// stored in the store metadata cache, if enabled. Responses that
+	// carry download URLs, which expire, must not be cached.
+	Cacheable bool
 }
 
 func (r *requestOptions) addHeader(k, v string) {
@@ -711,11 +735,44 @@ func decodeJSONBody(resp *http.Response, success interface{}, failure interface{
 
 // retryRequestDecodeJSON calls retryRequest and decodes the response into either success or failure.
 func (s *Store) retryRequestDecodeJSON(ctx context.Context, reqOptions *requestOptions, user *auth.UserState, success interface{}, failure interface{}) (resp *http.Response, err error) {
-	return httputil.RetryRequest(reqOptions.URL.String(), func() (*http.Response, error) {
+	if reqOptions.Cacheable && s.metadataCache != nil {
+		return s.retryRequestDecodeCachedJSON(ctx, reqOptions, user, success, failure)
+	}
+	return httputil.RetryRequestWithGate(reqOptions.URL.String(), func() (*http.Response, error) {
 		return s.doRequest(ctx, s.client, reqOptions, user)
 	}, func(resp *http.Response) error {
 		return decodeJSONBody(resp, success, failure)
-	}, defaultRetryStrategy)
+	}, defaultRetryStrategy, s.retryGate)
+}
+
+// retryRequestDecodeCachedJSON is like retryRequestDecodeJSON but answers
+// the request from the store metadata cache if possible.
+func (s *Store) retryRequestDecodeCachedJSON(ctx context.Context, reqOptions *requestOptions, user *auth.UserState, success interface{}, failure interface{}) (resp *http.Response, err error) {
+	key := metadataCacheKey(reqOptions, user)
+	cached := s.metadataCache.get(key)
+	if cached != nil && s.metadataCache.fresh(cached) {
+		if err := json.Unmarshal(cached.Body, success); err == nil {
+			logger.Debugf("Using cached store response for %s.", reqOptions.URL)
+			return cached.response(reqOptions), nil
+		}
+	}
+	if cached != nil && cached.ETag != "" {
+		// don't modify the caller's options
+		opts := *reqOptions
+		opts.ExtraHeaders = make(map[string]string, len(reqOptions.ExtraHeaders)+1)
+		for k, v := range reqOptions.ExtraHeaders {
+			opts.ExtraHeaders[k] = v
+		}
+		opts.addHeader("If-None-Match", cached.ETag)
+		reqOptions = &opts
+	} else {
+		cached = nil
+	}
+	return httputil.RetryRequestWithGate(reqOptions.URL.String(), func() (*http.Response, error) {
+		return s.doRequest(ctx, s.client, reqOptions, user)
+	}, func(resp *http.Response) error {
+		return s.decodeAndCacheJSONBody(key, cached, resp, success, failure)
+	}, defaultRetryStrategy, s.retryGate)
 }
 
 // doRequest does an authenticated request to the store handling a potential macaroon refresh required if needed
@@ -1002,9 +1059,10 @@ func (s *Store) snapInfo(ctx context.Context, snapName string, fields string, us
 	}
 
 	reqOptions := &requestOptions{
-		Method:   "GET",
-		URL:      u,
-		APILevel: apiV2Endps,
+		Method:    "GET",
+		URL:       u,
+		APILevel:  apiV2Endps,
+		Cacheable: true,
 	}
 
 	var remote storeInfo
@@ -1050,8 +1108,40 @@ type Search struct {
 
 	// category is "section" in search v1
 	Category string
-	Private  bool
-	Scope    string
+	// Publisher restricts the results to snaps published by the
+	// account with the given username
+	Publisher string
+	// License restricts the results to snaps with the given license
+	License string
+	Private bool
+	Scope   string
+}
+
+// matchesFilters returns whether the snap matches the publisher and license
+// filters of the search. The store find APIs do not support these filters so
+// they are applied to the results.
+func (search *Search) matchesFilters(info *snap.Info) bool {
+	if search.Publisher != "" && !strings.EqualFold(info.Publisher.Username, search.Publisher) {
+		return false
+	}
+	if search.License != "" && info.License != search.License {
+		return false
+	}
+	return true
+}
+
+// inCategory returns whether the snap is in the category of the search. The
+// categories of the snaps are only known with search v2.
+func (search *Search) inCategory(info *snap.Info) bool {
+	if search.Category == "" {
+		return true
+	}
+	for _, category := range info.Categories {
+		if category.Name == search.Category {
+			return true
+		}
+	}
+	return false
 }
 
 // Find finds  (installable) snaps from the store, matching the
@@ -1138,7 +1228,7 @@ func (s *Store) Find(ctx context.Context, search *Search, user *auth.UserState)
 		}
 		return json.NewDecoder(resp.Body).Decode(&searchData)
 	}
-	resp, err := httputil.RetryRequest(u.String(), doRequest, readResponse, defaultRetryStrategy)
+	resp, err := httputil.RetryRequestWithGate(u.String(), doRequest, readResponse, defaultRetryStrategy, s.retryGate)
 	if err != nil {
 		return nil, err
 	}
@@ -1167,13 +1257,18 @@ func (s *Store) Find(ctx context.Context, search *Search, user *auth.UserState)
 		return nil, fmt.Errorf("received an unexpected content type (%q) when trying to search via %q", ct, resp.Request.URL)
 	}
 
-	snaps := make([]*snap.Info, len(searchData.Results))
-	for i, res := range searchData.Results {
+	// the results are checked against the category as well, in case it
+	// was ignored by the store
+	snaps := make([]*snap.Info, 0, len(searchData.Results))
+	for _, res := range searchData.Results {
 		info, err := infoFromStoreSearchResult(res)
 		if err != nil {
 			return nil, err
 		}
-		snaps[i] = info
+		if !search.inCategory(info) || !search.matchesFilters(info) {
+			continue
+		}
+		snaps = append(snaps, info)
 	}
 
 	err = s.decorateOrders(snaps, user)
@@ -1245,9 +1340,13 @@ func (s *Store) findV1(ctx context.Context, search *Search, user *auth.UserState
 		return nil, fmt.Errorf("received an unexpected content type (%q) when trying to search via %q", ct, resp.Request.URL)
 	}
 
-	snaps := make([]*snap.Info, len(searchData.Payload.Packages))
-	for i, pkg := range searchData.Payload.Packages {
-		snaps[i] = infoFromRemote(pkg)
+	snaps := make([]*snap.Info, 0, len(searchData.Payload.Packages))
+	for _, pkg := range searchData.Payload.Packages {
+		info := infoFromRemote(pkg)
+		if !search.matchesFilters(info) {
+			continue
+		}
+		snaps = append(snaps, info)
 	}
 
 	err = s.decorateOrders(snaps, user)
@@ -1362,7 +1461,7 @@ func (s *Store) WriteCatalogs(ctx context.Context, names io.Writer, adder SnapAd
 		return decodeCatalog(resp, names, adder)
 	}
 
-	resp, err := httputil.RetryRequest(u.String(), doRequest, readResponse, defaultRetryStrategy)
+	resp, err := httputil.RetryRequestWithGate(u.String(), doRequest, readResponse, defaultRetryStrategy, s.retryGate)
 	if err != nil {
 		return err
 	}
@@ -36,6 +36,7 @@ import (
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/overlord/auth"
 	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/channel"
 )
 
 // TODO: rename this type to something more general, since it is used for more
@@ -702,6 +703,20 @@ func (s *Store) snapAction(ctx context.Context, currentSnaps []*CurrentSnap, act
 			}
 			rrev := snap.R(res.Snap.Revision)
 
+			// explicit refreshes get whatever the store returns, only
+			// auto-refreshes report the closed track so that it can be
+			// acted upon
+			if a := refreshes[res.InstanceKey]; opts.Scheduled && a.Revision.Unset() {
+				ch := a.Channel
+				if ch == "" {
+					ch = cur.TrackingChannel
+				}
+				if err := closedTrackError(cur.InstanceName, ch, snapInfo); err != nil {
+					refreshErrors[cur.InstanceName] = err
+					continue
+				}
+			}
+
 			// here we check a few things to decide if the snap truly has no
 			// updates.
 			// * if the action is defined as a resource install, then the
@@ -876,3 +891,30 @@ func reportFetchAssertionsError(res *snapActionResult, assertq AssertionQuery) e
 
 	return assertq.AddGroupingError(fmt.Errorf("%s", rep.Message), asserts.Grouping(res.Key))
 }
+
+// closedTrackError returns a TrackClosedError if the given channel belongs to
+// a track that the store reports as closed for the snap. It is only used for
+// scheduled refreshes.
+func closedTrackError(instanceName, ch string, info *snap.Info) error {
+	if ch == "" || len(info.TrackInfo) == 0 {
+		return nil
+	}
+	parsed, err := channel.ParseVerbatim(ch, "")
+	if err != nil {
+		return nil
+	}
+	track := parsed.Track
+	if track == "" {
+		track = "latest"
+	}
+	if ti := info.TrackInfo[track]; ti == nil || !ti.Closed() {
+		return nil
+	}
+	tcErr := &TrackClosedError{Snap: instanceName, Track: track}
+	for _, ti := range info.TrackInfo {
+		if ti.Status == snap.TrackStatusDefault {
+			tcErr.DefaultTrack = ti.Name
+		}
+	}
+	return tcErr
+}
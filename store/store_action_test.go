@@ -34,6 +34,7 @@ import (
 
 	"github.com/snapcore/snapd/arch"
 	"github.com/snapcore/snapd/asserts/snapasserts"
+	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/overlord/auth"
 	"github.com/snapcore/snapd/release"
 	"github.com/snapcore/snapd/snap"
@@ -1069,6 +1070,174 @@ func (s *storeActionSuite) TestSnapActionAutoRefresh(c *C) {
 	c.Assert(results, HasLen, 1)
 }
 
+func (s *storeActionSuite) TestSnapActionNotInMetadataCache(c *C) {
+	restore := release.MockOnClassic(false)
+	defer restore()
+
+	n := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertRequest(c, r, "POST", snapActionPath)
+		n++
+
+		w.Header().Set("ETag", `"etag-1"`)
+		io.WriteString(w, `{
+  "results": [{
+     "result": "refresh",
+     "instance-key": "buPKUD3TKqCOgLEjjHx5kSiCpIs5cMuQ",
+     "snap-id": "buPKUD3TKqCOgLEjjHx5kSiCpIs5cMuQ",
+     "name": "hello-world",
+     "snap": {
+       "snap-id": "buPKUD3TKqCOgLEjjHx5kSiCpIs5cMuQ",
+       "name": "hello-world",
+       "revision": 26,
+       "version": "6.1",
+       "epoch": {"read": [0], "write": [0]},
+       "publisher": {
+          "id": "canonical",
+          "username": "canonical",
+          "display-name": "Canonical"
+       }
+     }
+  }]
+}`)
+	}))
+
+	c.Assert(mockServer, NotNil)
+	defer mockServer.Close()
+
+	mockServerURL, _ := url.Parse(mockServer.URL)
+	cfg := store.Config{
+		StoreBaseURL:      mockServerURL,
+		MetadataCacheSize: 10,
+		MetadataCacheTTL:  time.Hour,
+	}
+	dauthCtx := &testDauthContext{c: c, device: s.device}
+	sto := store.New(&cfg, dauthCtx)
+
+	refresh := func(opts *store.RefreshOptions) {
+		results, _, err := sto.SnapAction(s.ctx, []*store.CurrentSnap{
+			{
+				InstanceName:    "hello-world",
+				SnapID:          helloWorldSnapID,
+				TrackingChannel: "beta",
+				Revision:        snap.R(1),
+				RefreshedDate:   helloRefreshedDate,
+			},
+		}, []*store.SnapAction{
+			{
+				Action:       "refresh",
+				SnapID:       helloWorldSnapID,
+				InstanceName: "hello-world",
+			},
+		}, nil, nil, opts)
+		c.Assert(err, IsNil)
+		c.Assert(results, HasLen, 1)
+		c.Check(results[0].Revision, Equals, snap.R(26))
+	}
+
+	// action responses carry download URLs that expire, so they always
+	// come from the store, even for auto-refreshes
+	refresh(&store.RefreshOptions{Scheduled: true})
+	refresh(&store.RefreshOptions{Scheduled: true})
+	c.Check(n, Equals, 2)
+	refresh(&store.RefreshOptions{})
+	c.Check(n, Equals, 3)
+
+	c.Check(dirs.SnapStoreMetadataCacheDir, testutil.FileAbsent)
+}
+
+func (s *storeActionSuite) TestSnapActionRefreshClosedTrack(c *C) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertRequest(c, r, "POST", snapActionPath)
+
+		io.WriteString(w, `{
+  "results": [{
+     "result": "refresh",
+     "instance-key": "buPKUD3TKqCOgLEjjHx5kSiCpIs5cMuQ",
+     "snap-id": "buPKUD3TKqCOgLEjjHx5kSiCpIs5cMuQ",
+     "name": "hello-world",
+     "snap": {
+       "snap-id": "buPKUD3TKqCOgLEjjHx5kSiCpIs5cMuQ",
+       "name": "hello-world",
+       "revision": 26,
+       "version": "6.1",
+       "publisher": {
+          "id": "canonical",
+          "username": "canonical",
+          "display-name": "Canonical"
+       },
+       "tracks": [
+          {"name": "latest", "status": "default"},
+          {"name": "1.0", "status": "closed", "end-of-life": "2024-04-30T00:00:00Z"}
+       ]
+     }
+  }]
+}`)
+	}))
+
+	c.Assert(mockServer, NotNil)
+	defer mockServer.Close()
+
+	mockServerURL, _ := url.Parse(mockServer.URL)
+	cfg := store.Config{
+		StoreBaseURL: mockServerURL,
+	}
+	dauthCtx := &testDauthContext{c: c, device: s.device}
+	sto := store.New(&cfg, dauthCtx)
+
+	current := []*store.CurrentSnap{
+		{
+			InstanceName:    "hello-world",
+			SnapID:          helloWorldSnapID,
+			TrackingChannel: "1.0/stable",
+			Revision:        snap.R(1),
+			RefreshedDate:   helloRefreshedDate,
+		},
+	}
+	// explicit refreshes get what the store returns
+	results, _, err := sto.SnapAction(s.ctx, current, []*store.SnapAction{
+		{
+			Action:       "refresh",
+			SnapID:       helloWorldSnapID,
+			InstanceName: "hello-world",
+		},
+	}, nil, nil, nil)
+	c.Assert(err, IsNil)
+	c.Assert(results, HasLen, 1)
+	c.Check(results[0].Revision, Equals, snap.R(26))
+
+	// scheduled refreshes report the closed track
+	_, _, err = sto.SnapAction(s.ctx, current, []*store.SnapAction{
+		{
+			Action:       "refresh",
+			SnapID:       helloWorldSnapID,
+			InstanceName: "hello-world",
+		},
+	}, nil, nil, &store.RefreshOptions{Scheduled: true})
+	c.Assert(err, FitsTypeOf, &store.SnapActionError{})
+	c.Check(err.(*store.SnapActionError).Refresh, DeepEquals, map[string]error{
+		"hello-world": &store.TrackClosedError{
+			Snap:         "hello-world",
+			Track:        "1.0",
+			DefaultTrack: "latest",
+		},
+	})
+	c.Check(err.(*store.SnapActionError).Refresh["hello-world"], ErrorMatches, `track "1.0" of snap "hello-world" is closed`)
+
+	// switching away from the closed track works
+	results, _, err = sto.SnapAction(s.ctx, current, []*store.SnapAction{
+		{
+			Action:       "refresh",
+			SnapID:       helloWorldSnapID,
+			InstanceName: "hello-world",
+			Channel:      "latest/stable",
+		},
+	}, nil, nil, &store.RefreshOptions{Scheduled: true})
+	c.Assert(err, IsNil)
+	c.Assert(results, HasLen, 1)
+	c.Check(results[0].TrackInfo["1.0"].Closed(), Equals, true)
+}
+
 func (s *storeActionSuite) TestInstallFallbackChannelIsStable(c *C) {
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assertRequest(c, r, "POST", snapActionPath)
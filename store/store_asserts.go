@@ -199,7 +199,7 @@ func (s *Store) downloadAssertions(u *url.URL, decodeBody func(io.Reader) error,
 		Accept: asserts.MediaType,
 	}
 
-	resp, err := httputil.RetryRequest(reqOptions.URL.String(), func() (*http.Response, error) {
+	resp, err := httputil.RetryRequestWithGate(reqOptions.URL.String(), func() (*http.Response, error) {
 		return s.doRequest(context.TODO(), s.client, reqOptions, user)
 	}, func(resp *http.Response) error {
 		var e error
@@ -223,7 +223,7 @@ func (s *Store) downloadAssertions(u *url.URL, decodeBody func(io.Reader) error,
 			}
 		}
 		return e
-	}, defaultRetryStrategy)
+	}, defaultRetryStrategy, s.retryGate)
 
 	if err != nil {
 		return err
@@ -55,6 +55,7 @@ var downloadRetryStrategy = retry.LimitCount(7, retry.LimitTime(90*time.Second,
 	retry.Exponential{
 		Initial: 500 * time.Millisecond,
 		Factor:  2.5,
+		Jitter:  true,
 	},
 ))
 
@@ -185,6 +186,15 @@ type DownloadOptions struct {
 	RateLimit           int64
 	Scheduled           bool
 	LeavePartialOnError bool
+	// InstanceName is the instance name of the snap being downloaded, it
+	// locates the installed revision a delta is applied to. It defaults
+	// to the name of the snap.
+	InstanceName string
+	// Parallel is the maximum number of chunks of the snap that are
+	// fetched concurrently with range requests. Snaps smaller than two
+	// chunks, as well as resumed downloads, are always fetched with a
+	// single request.
+	Parallel int
 }
 
 // Download downloads the snap addressed by download info and returns its
@@ -229,6 +239,7 @@ func (s *Store) Download(ctx context.Context, name string, targetPath string, do
 	}
 
 	partialPath := targetPath + ".partial"
+	digestPath := partialDigestPath(partialPath)
 	w, err := os.OpenFile(partialPath, os.O_RDWR|os.O_CREATE, 0600)
 	if err != nil {
 		return err
@@ -247,8 +258,23 @@ func (s *Store) Download(ctx context.Context, name string, targetPath string, do
 		}
 		if dlOpts == nil || !dlOpts.LeavePartialOnError || fi == nil || fi.Size() == 0 {
 			os.Remove(w.Name())
+			os.Remove(digestPath)
 		}
 	}()
+	if resume > 0 && !partialMatches(digestPath, downloadInfo.Sha3_384) {
+		logger.Debugf("Partial download %q is of another revision, starting over.", partialPath)
+		if err := w.Truncate(0); err != nil {
+			return err
+		}
+		if resume, err = w.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+	}
+	if downloadInfo.Sha3_384 != "" {
+		if err := os.WriteFile(digestPath, []byte(downloadInfo.Sha3_384), 0600); err != nil {
+			return err
+		}
+	}
 	if resume > 0 {
 		logger.Debugf("Resuming download of %q at %d.", partialPath, resume)
 	} else {
@@ -257,7 +283,25 @@ func (s *Store) Download(ctx context.Context, name string, targetPath string, do
 
 	url := downloadInfo.DownloadURL
 	if downloadInfo.Size == 0 || resume < downloadInfo.Size {
-		err = download(ctx, name, downloadInfo.Sha3_384, url, user, s, w, resume, pbar, dlOpts)
+		if resume == 0 && useParallelDownload(downloadInfo.Size, dlOpts) {
+			err = downloadParallel(ctx, name, downloadInfo.Sha3_384, url, user, s, w, downloadInfo.Size, pbar, dlOpts)
+			switch {
+			case err == errRangeNotSupported:
+				logger.Debugf("Server does not support range requests, downloading %q in a single request.", url)
+				if err = w.Truncate(0); err != nil {
+					return err
+				}
+				err = download(ctx, name, downloadInfo.Sha3_384, url, user, s, w, 0, pbar, dlOpts)
+			case err != nil:
+				// chunks complete out of order, so what was
+				// written cannot be resumed
+				if terr := w.Truncate(0); terr != nil {
+					return terr
+				}
+			}
+		} else {
+			err = download(ctx, name, downloadInfo.Sha3_384, url, user, s, w, resume, pbar, dlOpts)
+		}
 		if err != nil {
 			logger.Debugf("download of %q failed: %#v", url, err)
 		}
@@ -300,6 +344,7 @@ func (s *Store) Download(ctx context.Context, name string, targetPath string, do
 	if err := os.Rename(w.Name(), targetPath); err != nil {
 		return err
 	}
+	os.Remove(digestPath)
 
 	if err := w.Sync(); err != nil {
 		return err
@@ -308,6 +353,25 @@ func (s *Store) Download(ctx context.Context, name string, targetPath string, do
 	return s.cacher.Put(downloadInfo.Sha3_384, targetPath)
 }
 
+// partialDigestPath returns the path of the file recording the sha3-384 of
+// the snap that a partial download is for.
+func partialDigestPath(partialPath string) string {
+	return partialPath + ".sha3-384"
+}
+
+// partialMatches returns whether the partial download with the given
+// digest file can be resumed to get the snap with the given sha3-384, that
+// is whether both are for the same revision. Partial downloads without a
+// digest file are resumed, the hash check at the end of the download
+// catches any mismatch.
+func partialMatches(digestPath, sha3_384 string) bool {
+	digest, err := os.ReadFile(digestPath)
+	if err != nil {
+		return true
+	}
+	return sha3_384 == "" || string(digest) == sha3_384
+}
+
 func downloadReqOpts(storeURL *url.URL, cdnHeader string, opts *DownloadOptions) *requestOptions {
 	reqOptions := requestOptions{
 		Method:       "GET",
@@ -436,8 +500,40 @@ func (w *TransferSpeedMonitoringWriter) Err() error {
 
 var ratelimitReader = ratelimit.Reader
 
+// downloadRateLimitBucket returns the token bucket throttling the rate limited
+// downloads of the store to the given rate in bytes per second. The bucket is
+// shared by all concurrent downloads so that they do not exceed the rate
+// together, it is replaced when the rate changes.
+func (s *Store) downloadRateLimitBucket(rate int64) *ratelimit.Bucket {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.downloadBucket == nil || s.downloadBucketRate != rate {
+		s.downloadBucket = ratelimit.NewBucketWithRate(float64(rate), 2*rate)
+		s.downloadBucketRate = rate
+	}
+	return s.downloadBucket
+}
+
 var download = downloadImpl
 
+// newDownloadHTTPClient returns the http.Client used to download snaps,
+// which does not forward authorization headers on redirects.
+func (s *Store) newDownloadHTTPClient(reqOptions *requestOptions) *http.Client {
+	cli := s.newHTTPClient(nil)
+	oldCheckRedirect := cli.CheckRedirect
+	if oldCheckRedirect == nil {
+		panic("internal error: the httputil.NewHTTPClient-produced http.Client must have CheckRedirect defined")
+	}
+	cli.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		// remove user/device auth headers from being sent in "CDN" redirects
+		// see also: https://bugs.launchpad.net/snapd/+bug/2027993
+		// TODO: do we need to remove other identifying headers?
+		dropAuthorization(req, &AuthorizeOptions{deviceAuth: true, apiLevel: reqOptions.APILevel})
+		return oldCheckRedirect(req, via)
+	}
+	return cli
+}
+
 // download writes an http.Request showing a progress.Meter
 func downloadImpl(ctx context.Context, name, sha3_384, downloadURL string, user *auth.UserState, s *Store, w io.ReadWriteSeeker, resume int64, pbar progress.Meter, dlOpts *DownloadOptions) error {
 	if dlOpts == nil {
@@ -485,18 +581,7 @@ func downloadImpl(ctx context.Context, name, sha3_384, downloadURL string, user
 			return fmt.Errorf("the download has been cancelled: %s", downloadCtx.Err())
 		}
 		var resp *http.Response
-		cli := s.newHTTPClient(nil)
-		oldCheckRedirect := cli.CheckRedirect
-		if oldCheckRedirect == nil {
-			panic("internal error: the httputil.NewHTTPClient-produced http.Client must have CheckRedirect defined")
-		}
-		cli.CheckRedirect = func(req *http.Request, via []*http.Request) error {
-			// remove user/device auth headers from being sent in "CDN" redirects
-			// see also: https://bugs.launchpad.net/snapd/+bug/2027993
-			// TODO: do we need to remove other identifying headers?
-			dropAuthorization(req, &AuthorizeOptions{deviceAuth: true, apiLevel: reqOptions.APILevel})
-			return oldCheckRedirect(req, via)
-		}
+		cli := s.newDownloadHTTPClient(reqOptions)
 		resp, finalErr = s.doRequest(downloadCtx, cli, reqOptions, user)
 		if cancelled(downloadCtx) {
 			return fmt.Errorf("the download has been cancelled: %s", downloadCtx.Err())
@@ -546,8 +631,7 @@ func downloadImpl(ctx context.Context, name, sha3_384, downloadURL string, user
 		var limiter io.Reader
 		limiter = resp.Body
 		if limit := dlOpts.RateLimit; limit > 0 {
-			bucket := ratelimit.NewBucketWithRate(float64(limit), 2*limit)
-			limiter = ratelimitReader(resp.Body, bucket)
+			limiter = ratelimitReader(resp.Body, s.downloadRateLimitBucket(limit))
 		}
 
 		stopMonitorCh := tc.Monitor()
@@ -600,6 +684,203 @@ func downloadImpl(ctx context.Context, name, sha3_384, downloadURL string, user
 	return finalErr
 }
 
+// parallelDownloadChunkSize is the size of the ranges requested by parallel
+// downloads.
+var parallelDownloadChunkSize int64 = 16 * 1024 * 1024
+
+// errRangeNotSupported is returned by parallel downloads when the server
+// ignores range requests.
+var errRangeNotSupported = errors.New("server does not support range requests")
+
+func useParallelDownload(size int64, dlOpts *DownloadOptions) bool {
+	return dlOpts != nil && dlOpts.Parallel > 1 && size > parallelDownloadChunkSize
+}
+
+var downloadParallel = downloadParallelImpl
+
+// downloadParallelImpl downloads the snap of the given size in chunks which
+// are fetched with range requests by up to dlOpts.Parallel workers and
+// written at their offset in w. As chunks complete out of order, the
+// sha3-384 is verified over the whole file once all of them are written.
+func downloadParallelImpl(ctx context.Context, name, sha3_384, downloadURL string, user *auth.UserState, s *Store, w *os.File, size int64, pbar progress.Meter, dlOpts *DownloadOptions) error {
+	storeURL, err := url.Parse(downloadURL)
+	if err != nil {
+		return err
+	}
+
+	cdnHeader, err := s.cdnHeader()
+	if err != nil {
+		return err
+	}
+
+	tc, downloadCtx := NewTransferSpeedMonitoringWriterAndContext(ctx, downloadSpeedMeasureWindow, downloadSpeedMin)
+	downloadCtx, cancel := context.WithCancel(downloadCtx)
+	defer cancel()
+
+	if pbar == nil {
+		pbar = progress.Null
+	}
+	pbar.Start(name, float64(size))
+	// the progress meter is not safe for concurrent use
+	var pbarMu sync.Mutex
+	progressW := writerFunc(func(p []byte) (int, error) {
+		pbarMu.Lock()
+		defer pbarMu.Unlock()
+		return pbar.Write(p)
+	})
+
+	var bucket *ratelimit.Bucket
+	if limit := dlOpts.RateLimit; limit > 0 {
+		// shared by all workers to limit the overall rate
+		bucket = s.downloadRateLimitBucket(limit)
+	}
+
+	var errMu sync.Mutex
+	var firstErr error
+	fail := func(err error) {
+		errMu.Lock()
+		defer errMu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+
+	startTime := time.Now()
+	stopMonitorCh := tc.Monitor()
+	chunks := make(chan int64)
+	var wg sync.WaitGroup
+	for i := 0; i < dlOpts.Parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for start := range chunks {
+				end := start + parallelDownloadChunkSize
+				if end > size {
+					end = size
+				}
+				cw := &chunkWriter{w: w, off: start}
+				if err := downloadChunk(downloadCtx, name, storeURL, cdnHeader, user, s, cw, end, io.MultiWriter(progressW, tc), bucket, dlOpts); err != nil {
+					fail(err)
+					return
+				}
+			}
+		}()
+	}
+queue:
+	for start := int64(0); start < size; start += parallelDownloadChunkSize {
+		select {
+		case chunks <- start:
+		case <-downloadCtx.Done():
+			break queue
+		}
+	}
+	close(chunks)
+	wg.Wait()
+	close(stopMonitorCh)
+	pbar.Finished()
+
+	if err := tc.Err(); err != nil {
+		return err
+	}
+	if firstErr != nil {
+		return firstErr
+	}
+	if cancelled(ctx) {
+		return fmt.Errorf("the download has been cancelled: %s", ctx.Err())
+	}
+
+	h := crypto.SHA3_384.New()
+	if _, err := io.Copy(h, io.NewSectionReader(w, 0, size)); err != nil {
+		return err
+	}
+	actualSha3 := fmt.Sprintf("%x", h.Sum(nil))
+	if sha3_384 != "" && sha3_384 != actualSha3 {
+		return HashError{name, actualSha3, sha3_384}
+	}
+	logger.Debugf("Download with %d parallel requests succeeded in %.03fs.", dlOpts.Parallel, time.Since(startTime).Seconds())
+	return nil
+}
+
+// downloadChunk downloads the range of the snap starting at the offset of
+// w and ending before end, resuming from what was written on retries.
+func downloadChunk(ctx context.Context, name string, storeURL *url.URL, cdnHeader string, user *auth.UserState, s *Store, w *chunkWriter, end int64, progressW io.Writer, bucket *ratelimit.Bucket, dlOpts *DownloadOptions) error {
+	var finalErr error
+	startTime := time.Now()
+	for attempt := retry.Start(downloadRetryStrategy, nil); attempt.Next(); {
+		reqOptions := downloadReqOpts(storeURL, cdnHeader, dlOpts)
+		reqOptions.ExtraHeaders["Range"] = fmt.Sprintf("bytes=%d-%d", w.off, end-1)
+
+		httputil.MaybeLogRetryAttempt(reqOptions.URL.String(), attempt, startTime)
+
+		var resp *http.Response
+		resp, finalErr = s.doRequest(ctx, s.newDownloadHTTPClient(reqOptions), reqOptions, user)
+		if cancelled(ctx) {
+			return fmt.Errorf("the download has been cancelled: %s", ctx.Err())
+		}
+		if finalErr != nil {
+			if httputil.ShouldRetryAttempt(attempt, finalErr) {
+				continue
+			}
+			break
+		}
+		if httputil.ShouldRetryHttpResponse(attempt, resp) {
+			resp.Body.Close()
+			continue
+		}
+
+		switch resp.StatusCode {
+		case 206: // Partial Content
+		case 200: // OK, the whole snap
+			resp.Body.Close()
+			return errRangeNotSupported
+		case 402: // Payment Required
+			resp.Body.Close()
+			return fmt.Errorf("please buy %s before installing it", name)
+		default:
+			resp.Body.Close()
+			return &DownloadError{Code: resp.StatusCode, URL: resp.Request.URL}
+		}
+
+		var body io.Reader = io.LimitReader(resp.Body, end-w.off)
+		if bucket != nil {
+			body = ratelimitReader(body, bucket)
+		}
+		_, finalErr = io.Copy(io.MultiWriter(w, progressW), body)
+		resp.Body.Close()
+		if cancelled(ctx) {
+			return fmt.Errorf("the download has been cancelled: %s", ctx.Err())
+		}
+		if finalErr == nil && w.off < end {
+			finalErr = io.ErrUnexpectedEOF
+		}
+		if finalErr != nil && httputil.ShouldRetryAttempt(attempt, finalErr) {
+			// resume the chunk from what was written
+			continue
+		}
+		break
+	}
+	return finalErr
+}
+
+// chunkWriter writes sequentially to a file starting at a given offset.
+type chunkWriter struct {
+	w   io.WriterAt
+	off int64
+}
+
+func (cw *chunkWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.WriteAt(p, cw.off)
+	cw.off += int64(n)
+	return n, err
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
+
 // DownloadStream will copy the snap from the request to the io.Reader
 func (s *Store) DownloadStream(ctx context.Context, name string, downloadInfo *snap.DownloadInfo, resume int64, user *auth.UserState) (io.ReadCloser, int, error) {
 	// most other store network operations use s.endpointURL, which returns an
@@ -755,7 +1036,11 @@ func (s *Store) downloadAndApplyDelta(name, targetPath string, downloadInfo *sna
 	}
 
 	logger.Debugf("Successfully downloaded delta for %q at %s", name, deltaPath)
-	if err := applyDelta(s, name, deltaPath, deltaInfo, targetPath, downloadInfo.Sha3_384); err != nil {
+	instanceName := name
+	if dlOpts != nil && dlOpts.InstanceName != "" {
+		instanceName = dlOpts.InstanceName
+	}
+	if err := applyDelta(s, instanceName, deltaPath, deltaInfo, targetPath, downloadInfo.Sha3_384); err != nil {
 		return err
 	}
 
@@ -30,6 +30,8 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
+	"sync"
 	"time"
 
 	"golang.org/x/crypto/sha3"
@@ -149,6 +151,87 @@ func (s *storeDownloadSuite) TestDownloadRangeRequest(c *C) {
 	c.Assert(targetFn, testutil.FileEquals, expectedContentStr)
 }
 
+func (s *storeDownloadSuite) TestDownloadRangeRequestSameRevision(c *C) {
+	partialContentStr := "partial content "
+	missingContentStr := "was downloaded"
+	expectedContentStr := partialContentStr + missingContentStr
+
+	restore := store.MockDownload(func(ctx context.Context, name, sha3, url string, user *auth.UserState, s *store.Store, w io.ReadWriteSeeker, resume int64, pbar progress.Meter, dlOpts *store.DownloadOptions) error {
+		c.Check(resume, Equals, int64(len(partialContentStr)))
+		w.Write([]byte(missingContentStr))
+		return nil
+	})
+	defer restore()
+
+	snap := &snap.Info{}
+	snap.RealName = "foo"
+	snap.DownloadURL = "URL"
+	snap.Sha3_384 = "abcdabcd"
+	snap.Size = int64(len(expectedContentStr))
+
+	targetFn := filepath.Join(c.MkDir(), "foo.snap")
+	c.Assert(os.WriteFile(targetFn+".partial", []byte(partialContentStr), 0644), IsNil)
+	c.Assert(os.WriteFile(targetFn+".partial.sha3-384", []byte("abcdabcd"), 0644), IsNil)
+
+	err := s.store.Download(s.ctx, "foo", targetFn, &snap.DownloadInfo, nil, nil, nil)
+	c.Assert(err, IsNil)
+
+	c.Check(targetFn, testutil.FileEquals, expectedContentStr)
+	c.Check(targetFn+".partial.sha3-384", testutil.FileAbsent)
+}
+
+func (s *storeDownloadSuite) TestDownloadRangeRequestOtherRevisionStartsOver(c *C) {
+	expectedContentStr := "new revision"
+
+	restore := store.MockDownload(func(ctx context.Context, name, sha3, url string, user *auth.UserState, s *store.Store, w io.ReadWriteSeeker, resume int64, pbar progress.Meter, dlOpts *store.DownloadOptions) error {
+		c.Check(resume, Equals, int64(0))
+		w.Write([]byte(expectedContentStr))
+		return nil
+	})
+	defer restore()
+
+	snap := &snap.Info{}
+	snap.RealName = "foo"
+	snap.DownloadURL = "URL"
+	snap.Sha3_384 = "abcdabcd"
+	snap.Size = int64(len(expectedContentStr))
+
+	targetFn := filepath.Join(c.MkDir(), "foo.snap")
+	c.Assert(os.WriteFile(targetFn+".partial", []byte("old revision partial content"), 0644), IsNil)
+	c.Assert(os.WriteFile(targetFn+".partial.sha3-384", []byte("0123456789"), 0644), IsNil)
+
+	err := s.store.Download(s.ctx, "foo", targetFn, &snap.DownloadInfo, nil, nil, nil)
+	c.Assert(err, IsNil)
+
+	c.Check(targetFn, testutil.FileEquals, expectedContentStr)
+	c.Check(targetFn+".partial.sha3-384", testutil.FileAbsent)
+}
+
+func (s *storeDownloadSuite) TestDownloadLeavesPartialDigestOnError(c *C) {
+	restore := store.MockDownload(func(ctx context.Context, name, sha3, url string, user *auth.UserState, s *store.Store, w io.ReadWriteSeeker, resume int64, pbar progress.Meter, dlOpts *store.DownloadOptions) error {
+		w.Write([]byte("some"))
+		return fmt.Errorf("uh, it failed")
+	})
+	defer restore()
+
+	snap := &snap.Info{}
+	snap.RealName = "foo"
+	snap.DownloadURL = "URL"
+	snap.Sha3_384 = "abcdabcd"
+	snap.Size = 10
+
+	targetFn := filepath.Join(c.MkDir(), "foo.snap")
+	err := s.store.Download(s.ctx, "foo", targetFn, &snap.DownloadInfo, nil, nil, &store.DownloadOptions{LeavePartialOnError: true})
+	c.Assert(err, ErrorMatches, "uh, it failed")
+	c.Check(targetFn+".partial", testutil.FileEquals, "some")
+	c.Check(targetFn+".partial.sha3-384", testutil.FileEquals, "abcdabcd")
+
+	err = s.store.Download(s.ctx, "foo", targetFn, &snap.DownloadInfo, nil, nil, nil)
+	c.Assert(err, ErrorMatches, "uh, it failed")
+	c.Check(targetFn+".partial", testutil.FileAbsent)
+	c.Check(targetFn+".partial.sha3-384", testutil.FileAbsent)
+}
+
 func (s *storeDownloadSuite) TestResumeOfCompleted(c *C) {
 	expectedContentStr := "nothing downloaded"
 
@@ -972,6 +1055,206 @@ func (s *storeDownloadSuite) TestDownloadDeltaRebuitlButCachePutFail(c *C) {
 	})
 }
 
+func (s *storeDownloadSuite) TestDownloadDeltaForInstance(c *C) {
+	restore := store.MockDownload(func(
+		ctx context.Context, name, sha3, url string, user *auth.UserState, s *store.Store,
+		w io.ReadWriteSeeker, resume int64, pbar progress.Meter, dlOpts *store.DownloadOptions,
+	) error {
+		c.Check(url, Equals, "http://delta.download.url/get")
+		_, err := w.Write([]byte("delta"))
+		return err
+	})
+	defer restore()
+
+	var appliedFor string
+	restore = store.MockApplyDelta(func(s *store.Store, name string, deltaPath string, deltaInfo *snap.DeltaInfo, targetPath string, targetSha3_384 string) error {
+		appliedFor = name
+		return os.WriteFile(targetPath, []byte("foo\n"), 0644)
+	})
+	defer restore()
+
+	// sha3-384256 of: foo\n
+	foo_sha3 := "a4d62fdfee48479a8951de809d9f3604309e8783d754d94c0842c89ddb544ee963bf64063644251e0521ca44aca97350"
+	dlInfo := snap.DownloadInfo{
+		DownloadURL: "http://download.url/get",
+		Deltas: []snap.DeltaInfo{
+			{
+				FromRevision: 1,
+				ToRevision:   2,
+				Format:       "xdelta3",
+				DownloadURL:  "http://delta.download.url/get",
+				Sha3_384:     foo_sha3,
+			},
+		},
+		Sha3_384: foo_sha3,
+	}
+
+	path := filepath.Join(c.MkDir(), "foo_instance_2.snap")
+	err := s.store.Download(s.ctx, "foo", path, &dlInfo, nil, nil, &store.DownloadOptions{InstanceName: "foo_instance"})
+	c.Assert(err, IsNil)
+	// the delta is applied to the installed revision of the instance
+	c.Check(appliedFor, Equals, "foo_instance")
+	c.Check(path, testutil.FileEquals, "foo\n")
+}
+
+func (s *storeDownloadSuite) parallelDownloadContent() []byte {
+	buf := make([]byte, 10000)
+	for i := range buf {
+		buf[i] = byte('a' + i%26)
+	}
+	return buf
+}
+
+func (s *storeDownloadSuite) TestDownloadParallel(c *C) {
+	restore := store.MockParallelDownloadChunkSize(3000)
+	defer restore()
+
+	content := s.parallelDownloadContent()
+	var mu sync.Mutex
+	var ranges []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ranges = append(ranges, r.Header.Get("Range"))
+		mu.Unlock()
+		http.ServeContent(w, r, "foo.snap", time.Time{}, bytes.NewReader(content))
+	}))
+	defer mockServer.Close()
+
+	snap := &snap.Info{}
+	snap.RealName = "foo"
+	snap.DownloadURL = mockServer.URL
+	snap.Sha3_384 = fmt.Sprintf("%x", sha3.Sum384(content))
+	snap.Size = int64(len(content))
+
+	targetFn := filepath.Join(c.MkDir(), "foo_1.0_all.snap")
+	err := s.store.Download(s.ctx, "foo", targetFn, &snap.DownloadInfo, nil, nil, &store.DownloadOptions{Parallel: 3})
+	c.Assert(err, IsNil)
+	c.Check(targetFn, testutil.FileEquals, content)
+	c.Check(targetFn+".partial", testutil.FileAbsent)
+
+	sort.Strings(ranges)
+	c.Check(ranges, DeepEquals, []string{
+		"bytes=0-2999",
+		"bytes=3000-5999",
+		"bytes=6000-8999",
+		"bytes=9000-9999",
+	})
+}
+
+func (s *storeDownloadSuite) TestDownloadParallelSmallSnap(c *C) {
+	content := s.parallelDownloadContent()
+	var ranges []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		w.Write(content)
+	}))
+	defer mockServer.Close()
+
+	snap := &snap.Info{}
+	snap.RealName = "foo"
+	snap.DownloadURL = mockServer.URL
+	snap.Sha3_384 = fmt.Sprintf("%x", sha3.Sum384(content))
+	snap.Size = int64(len(content))
+
+	// the snap fits in a single chunk of the default size
+	targetFn := filepath.Join(c.MkDir(), "foo_1.0_all.snap")
+	err := s.store.Download(s.ctx, "foo", targetFn, &snap.DownloadInfo, nil, nil, &store.DownloadOptions{Parallel: 3})
+	c.Assert(err, IsNil)
+	c.Check(targetFn, testutil.FileEquals, content)
+	c.Check(ranges, DeepEquals, []string{""})
+}
+
+func (s *storeDownloadSuite) TestDownloadParallelNoRangeSupport(c *C) {
+	restore := store.MockParallelDownloadChunkSize(3000)
+	defer restore()
+
+	content := s.parallelDownloadContent()
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// ranges are ignored
+		w.Write(content)
+	}))
+	defer mockServer.Close()
+
+	snap := &snap.Info{}
+	snap.RealName = "foo"
+	snap.DownloadURL = mockServer.URL
+	snap.Sha3_384 = fmt.Sprintf("%x", sha3.Sum384(content))
+	snap.Size = int64(len(content))
+
+	targetFn := filepath.Join(c.MkDir(), "foo_1.0_all.snap")
+	err := s.store.Download(s.ctx, "foo", targetFn, &snap.DownloadInfo, nil, nil, &store.DownloadOptions{Parallel: 3})
+	c.Assert(err, IsNil)
+	c.Check(targetFn, testutil.FileEquals, content)
+	c.Check(s.logbuf.String(), testutil.Contains, "Server does not support range requests")
+}
+
+func (s *storeDownloadSuite) TestDownloadParallelRetriesChunk(c *C) {
+	restore := store.MockParallelDownloadChunkSize(3000)
+	defer restore()
+
+	content := s.parallelDownloadContent()
+	var mu sync.Mutex
+	var ranges []string
+	var mockServer *httptest.Server
+	mockServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ranges = append(ranges, r.Header.Get("Range"))
+		mu.Unlock()
+		if r.Header.Get("Range") == "bytes=3000-5999" {
+			// send only the beginning of the chunk
+			w.Header().Set("Content-Length", "3000")
+			w.Header().Set("Content-Range", "bytes 3000-5999/10000")
+			w.WriteHeader(206)
+			w.Write(content[3000:4000])
+			return
+		}
+		http.ServeContent(w, r, "foo.snap", time.Time{}, bytes.NewReader(content))
+	}))
+	defer mockServer.Close()
+
+	snap := &snap.Info{}
+	snap.RealName = "foo"
+	snap.DownloadURL = mockServer.URL
+	snap.Sha3_384 = fmt.Sprintf("%x", sha3.Sum384(content))
+	snap.Size = int64(len(content))
+
+	targetFn := filepath.Join(c.MkDir(), "foo_1.0_all.snap")
+	err := s.store.Download(s.ctx, "foo", targetFn, &snap.DownloadInfo, nil, nil, &store.DownloadOptions{Parallel: 2})
+	c.Assert(err, IsNil)
+	c.Check(targetFn, testutil.FileEquals, content)
+	// the chunk is resumed from what was received
+	c.Check(ranges, testutil.Contains, "bytes=4000-5999")
+}
+
+func (s *storeDownloadSuite) TestDownloadParallelChunkFails(c *C) {
+	restore := store.MockParallelDownloadChunkSize(3000)
+	defer restore()
+
+	content := s.parallelDownloadContent()
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") == "bytes=6000-8999" {
+			w.WriteHeader(404)
+			return
+		}
+		http.ServeContent(w, r, "foo.snap", time.Time{}, bytes.NewReader(content))
+	}))
+	defer mockServer.Close()
+
+	snap := &snap.Info{}
+	snap.RealName = "foo"
+	snap.DownloadURL = mockServer.URL
+	snap.Sha3_384 = fmt.Sprintf("%x", sha3.Sum384(content))
+	snap.Size = int64(len(content))
+
+	targetFn := filepath.Join(c.MkDir(), "foo_1.0_all.snap")
+	err := s.store.Download(s.ctx, "foo", targetFn, &snap.DownloadInfo, nil, nil, &store.DownloadOptions{Parallel: 3, LeavePartialOnError: true})
+	c.Assert(err, FitsTypeOf, &store.DownloadError{})
+	c.Check(err.(*store.DownloadError).Code, Equals, 404)
+	// chunks completed out of order are not kept for resuming
+	c.Check(targetFn, testutil.FileAbsent)
+	c.Check(targetFn+".partial", testutil.FileAbsent)
+}
+
 func (s *storeDownloadSuite) TestDownloadStreamOK(c *C) {
 	expectedContent := []byte("I was downloaded")
 	restore := store.MockDoDownloadReq(func(ctx context.Context, url *url.URL, cdnHeader string, resume int64, s *store.Store, user *auth.UserState) (*http.Response, error) {
@@ -1531,6 +1531,42 @@ func (s *storeTestSuite) TestInfo500(c *C) {
 	c.Assert(n, Equals, 5)
 }
 
+func (s *storeTestSuite) TestInfo500CircuitBreaker(c *C) {
+	restore := store.MockCircuitBreaker(3, time.Minute, time.Hour)
+	defer restore()
+
+	var n = 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertRequest(c, r, "GET", infoPathPattern)
+		n++
+		w.WriteHeader(500)
+	}))
+
+	c.Assert(mockServer, NotNil)
+	defer mockServer.Close()
+
+	mockServerURL, _ := url.Parse(mockServer.URL)
+	cfg := store.Config{
+		StoreBaseURL: mockServerURL,
+		DetailFields: []string{},
+	}
+	dauthCtx := &testDauthContext{c: c, device: s.device}
+	sto := store.New(&cfg, dauthCtx)
+
+	spec := store.SnapSpec{
+		Name: "hello-world",
+	}
+	_, err := sto.SnapInfo(s.ctx, spec, nil)
+	c.Assert(err, ErrorMatches, `cannot get details for snap "hello-world": got unexpected HTTP status code 500 via GET to "http://.*?/info/hello-world.*"`)
+	// the endpoint was suspended after 3 failed requests
+	c.Check(n, Equals, 3)
+
+	_, err = sto.SnapInfo(s.ctx, spec, nil)
+	c.Assert(err, FitsTypeOf, &store.StoreUnavailableError{})
+	c.Check(err, ErrorMatches, `store endpoint http://.*/v2/snaps/info/hello-world is temporarily unavailable, retry after .*`)
+	c.Check(n, Equals, 3)
+}
+
 func (s *storeTestSuite) TestInfo500Once(c *C) {
 	var n = 0
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -1565,6 +1601,150 @@ func (s *storeTestSuite) TestInfo500Once(c *C) {
 	c.Assert(n, Equals, 2)
 }
 
+func (s *storeTestSuite) TestInfoMetadataCacheFresh(c *C) {
+	n := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertRequest(c, r, "GET", infoPathPattern)
+		n++
+		w.Header().Set("ETag", `"etag-1"`)
+		w.Header().Set("X-Suggested-Currency", "GBP")
+		w.WriteHeader(200)
+		io.WriteString(w, mockInfoJSON)
+	}))
+
+	c.Assert(mockServer, NotNil)
+	defer mockServer.Close()
+
+	mockServerURL, _ := url.Parse(mockServer.URL)
+	cfg := store.Config{
+		StoreBaseURL:      mockServerURL,
+		MetadataCacheSize: 10,
+		MetadataCacheTTL:  time.Hour,
+	}
+	dauthCtx := &testDauthContext{c: c, device: s.device}
+	sto := store.New(&cfg, dauthCtx)
+
+	spec := store.SnapSpec{
+		Name: "hello-world",
+	}
+	for i := 0; i < 2; i++ {
+		result, err := sto.SnapInfo(s.ctx, spec, nil)
+		c.Assert(err, IsNil)
+		c.Check(result.InstanceName(), Equals, "hello-world")
+		c.Check(result.Revision, Equals, snap.R(29))
+		c.Check(sto.SuggestedCurrency(), Equals, "GBP")
+	}
+	// the second request was answered from the cache
+	c.Check(n, Equals, 1)
+	entries, err := os.ReadDir(dirs.SnapStoreMetadataCacheDir)
+	c.Assert(err, IsNil)
+	c.Check(entries, HasLen, 1)
+}
+
+func (s *storeTestSuite) TestInfoMetadataCacheRevalidate(c *C) {
+	n := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertRequest(c, r, "GET", infoPathPattern)
+		n++
+		switch n {
+		case 1:
+			c.Check(r.Header.Get("If-None-Match"), Equals, "")
+			w.Header().Set("ETag", `"etag-1"`)
+			w.WriteHeader(200)
+			io.WriteString(w, mockInfoJSON)
+		case 2:
+			c.Check(r.Header.Get("If-None-Match"), Equals, `"etag-1"`)
+			w.WriteHeader(304)
+		default:
+			c.Fatalf("expected 2 requests, got %d", n)
+		}
+	}))
+
+	c.Assert(mockServer, NotNil)
+	defer mockServer.Close()
+
+	mockServerURL, _ := url.Parse(mockServer.URL)
+	cfg := store.Config{
+		StoreBaseURL: mockServerURL,
+	}
+	dauthCtx := &testDauthContext{c: c, device: s.device}
+	sto := store.New(&cfg, dauthCtx)
+	// a zero TTL always revalidates the cached responses
+	sto.SetMetadataCache(10, 0)
+
+	spec := store.SnapSpec{
+		Name: "hello-world",
+	}
+	for i := 0; i < 2; i++ {
+		result, err := sto.SnapInfo(s.ctx, spec, nil)
+		c.Assert(err, IsNil)
+		c.Check(result.InstanceName(), Equals, "hello-world")
+		c.Check(result.Revision, Equals, snap.R(29))
+	}
+	c.Check(n, Equals, 2)
+}
+
+func (s *storeTestSuite) TestInfoMetadataCacheSize(c *C) {
+	n := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertRequest(c, r, "GET", infoPathPattern)
+		n++
+		w.Header().Set("ETag", fmt.Sprintf(`"etag-%d"`, n))
+		w.WriteHeader(200)
+		io.WriteString(w, mockInfoJSON)
+	}))
+
+	c.Assert(mockServer, NotNil)
+	defer mockServer.Close()
+
+	mockServerURL, _ := url.Parse(mockServer.URL)
+	cfg := store.Config{
+		StoreBaseURL: mockServerURL,
+	}
+	dauthCtx := &testDauthContext{c: c, device: s.device}
+	sto := store.New(&cfg, dauthCtx)
+	sto.SetMetadataCache(2, time.Hour)
+
+	for _, name := range []string{"foo", "bar", "baz"} {
+		_, err := sto.SnapInfo(s.ctx, store.SnapSpec{Name: name}, nil)
+		c.Assert(err, IsNil)
+	}
+	c.Check(n, Equals, 3)
+
+	entries, err := os.ReadDir(dirs.SnapStoreMetadataCacheDir)
+	c.Assert(err, IsNil)
+	c.Check(entries, HasLen, 2)
+}
+
+func (s *storeTestSuite) TestInfoMetadataCacheDisabled(c *C) {
+	n := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertRequest(c, r, "GET", infoPathPattern)
+		n++
+		c.Check(r.Header.Get("If-None-Match"), Equals, "")
+		w.Header().Set("ETag", `"etag-1"`)
+		w.WriteHeader(200)
+		io.WriteString(w, mockInfoJSON)
+	}))
+
+	c.Assert(mockServer, NotNil)
+	defer mockServer.Close()
+
+	mockServerURL, _ := url.Parse(mockServer.URL)
+	cfg := store.Config{
+		StoreBaseURL: mockServerURL,
+	}
+	dauthCtx := &testDauthContext{c: c, device: s.device}
+	sto := store.New(&cfg, dauthCtx)
+
+	for i := 0; i < 2; i++ {
+		_, err := sto.SnapInfo(s.ctx, store.SnapSpec{Name: "hello-world"}, nil)
+		c.Assert(err, IsNil)
+	}
+	c.Check(n, Equals, 2)
+	c.Check(dirs.SnapStoreMetadataCacheDir, testutil.FileAbsent)
+}
+
 func (s *storeTestSuite) TestInfoAndChannels(c *C) {
 	n := 0
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -2901,6 +3081,124 @@ func (s *storeTestSuite) TestFindV2ErrorList(c *C) {
 	c.Check(err, ErrorMatches, `api error occurred`)
 }
 
+func (s *storeTestSuite) TestFindV2PublisherAndLicense(c *C) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertRequest(c, r, "GET", findPath)
+		query := r.URL.Query()
+		c.Check(query.Get("q"), Equals, "hello")
+		// search v2 does not support the publisher and license filters
+		c.Check(query.Get("publisher"), Equals, "")
+		c.Check(query.Get("license"), Equals, "")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(200)
+		io.WriteString(w, mockSearchJSONv2)
+	}))
+	c.Assert(mockServer, NotNil)
+	defer mockServer.Close()
+
+	mockServerURL, _ := url.Parse(mockServer.URL)
+	cfg := store.Config{
+		StoreBaseURL: mockServerURL,
+		FindFields:   []string{},
+	}
+	dauthCtx := &testDauthContext{c: c, device: s.device}
+	sto := store.New(&cfg, dauthCtx)
+
+	// so the results are filtered instead
+	for _, t := range []struct {
+		search store.Search
+		found  int
+	}{
+		{store.Search{Query: "hello", Publisher: "canonical"}, 1},
+		{store.Search{Query: "hello", Publisher: "Canonical", License: "MIT"}, 1},
+		{store.Search{Query: "hello", Publisher: "someone-else"}, 0},
+		{store.Search{Query: "hello", License: "GPL-3.0"}, 0},
+	} {
+		snaps, err := sto.Find(s.ctx, &t.search, nil)
+		c.Assert(err, IsNil)
+		c.Check(snaps, HasLen, t.found, Commentf("%+v", t.search))
+	}
+}
+
+func (s *storeTestSuite) TestFindV2CategoryChecked(c *C) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertRequest(c, r, "GET", findPath)
+		// the category is sent to the store, which returns the same
+		// results for any category here
+		c.Check(r.URL.Query().Get("category"), Not(Equals), "")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(200)
+		io.WriteString(w, mockSearchJSONv2)
+	}))
+	c.Assert(mockServer, NotNil)
+	defer mockServer.Close()
+
+	mockServerURL, _ := url.Parse(mockServer.URL)
+	cfg := store.Config{
+		StoreBaseURL: mockServerURL,
+		FindFields:   []string{},
+	}
+	dauthCtx := &testDauthContext{c: c, device: s.device}
+	sto := store.New(&cfg, dauthCtx)
+
+	for _, t := range []struct {
+		category string
+		found    int
+	}{
+		{"productivity", 1},
+		{"featured", 1},
+		{"games", 0},
+	} {
+		snaps, err := sto.Find(s.ctx, &store.Search{Query: "hello", Category: t.category}, nil)
+		c.Assert(err, IsNil)
+		c.Check(snaps, HasLen, t.found, Commentf("%s", t.category))
+	}
+}
+
+func (s *storeTestSuite) TestFindV1PublisherAndLicense(c *C) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, findPath) {
+			forceSearchV1(w)
+			return
+		}
+		assertRequest(c, r, "GET", searchPath)
+		// search v1 does not support the filters
+		c.Check(r.URL.Query().Get("publisher"), Equals, "")
+		c.Check(r.URL.Query().Get("license"), Equals, "")
+
+		w.Header().Set("Content-Type", "application/hal+json")
+		w.WriteHeader(200)
+		io.WriteString(w, mockSearchJSON)
+	}))
+	c.Assert(mockServer, NotNil)
+	defer mockServer.Close()
+
+	mockServerURL, _ := url.Parse(mockServer.URL)
+	cfg := store.Config{
+		StoreBaseURL: mockServerURL,
+		DetailFields: []string{},
+	}
+	dauthCtx := &testDauthContext{c: c, device: s.device}
+	sto := store.New(&cfg, dauthCtx)
+
+	// so the results are filtered instead
+	for _, t := range []struct {
+		search store.Search
+		found  int
+	}{
+		{store.Search{Query: "hello", Publisher: "canonical"}, 1},
+		{store.Search{Query: "hello", Publisher: "Canonical", License: "MIT"}, 1},
+		{store.Search{Query: "hello", Publisher: "someone-else"}, 0},
+		{store.Search{Query: "hello", License: "GPL-3.0"}, 0},
+	} {
+		snaps, err := sto.Find(s.ctx, &t.search, nil)
+		c.Assert(err, IsNil)
+		c.Check(snaps, HasLen, t.found, Commentf("%+v", t.search))
+	}
+}
+
 func (s *storeTestSuite) TestFindFailures(c *C) {
 	// bad query check is done early in Find(), so the test covers both search
 	// v1 & v2
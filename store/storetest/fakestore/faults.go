@@ -0,0 +1,113 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package fakestore
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Fault describes how the store misbehaves for the requests it applies to.
+type Fault struct {
+	// Latency delays the reply to the request.
+	Latency time.Duration
+	// Status, if set, is the HTTP status the store replies with instead
+	// of serving the request.
+	Status int
+	// Times is the number of requests the fault applies to, zero means
+	// all of them.
+	Times int
+}
+
+type scriptedFault struct {
+	pathPrefix string
+	fault      Fault
+	hits       int
+}
+
+// AddFault makes the requests for URL paths starting with pathPrefix
+// misbehave as described by fault. Faults are considered in the order in
+// which they were added, only the first applicable one is used.
+func (s *Store) AddFault(pathPrefix string, fault Fault) {
+	s.faultsMu.Lock()
+	defer s.faultsMu.Unlock()
+	s.faults = append(s.faults, &scriptedFault{pathPrefix: pathPrefix, fault: fault})
+}
+
+// ClearFaults makes the store behave normally again.
+func (s *Store) ClearFaults() {
+	s.faultsMu.Lock()
+	defer s.faultsMu.Unlock()
+	s.faults = nil
+}
+
+func (s *Store) nextFault(path string) *Fault {
+	s.faultsMu.Lock()
+	defer s.faultsMu.Unlock()
+	for _, f := range s.faults {
+		if !strings.HasPrefix(path, f.pathPrefix) {
+			continue
+		}
+		if f.fault.Times > 0 && f.hits >= f.fault.Times {
+			continue
+		}
+		f.hits++
+		fault := f.fault
+		return &fault
+	}
+	return nil
+}
+
+// withFaults wraps the store handler so that it applies the scripted
+// faults before serving the requests.
+func (s *Store) withFaults(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fault := s.nextFault(req.URL.Path)
+		if fault == nil {
+			h.ServeHTTP(w, req)
+			return
+		}
+		if fault.Latency > 0 {
+			select {
+			case <-time.After(fault.Latency):
+			case <-req.Context().Done():
+				return
+			}
+		}
+		if fault.Status == 0 {
+			h.ServeHTTP(w, req)
+			return
+		}
+		// reply like the store does with an error list
+		var errorList struct {
+			ErrorList []map[string]string `json:"error-list"`
+		}
+		errorList.ErrorList = []map[string]string{{
+			"code":    "fake-store-fault",
+			"message": http.StatusText(fault.Status),
+		}}
+		out, _ := json.Marshal(errorList)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(fault.Status)
+		w.Write(out)
+	})
+}
@@ -0,0 +1,86 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package fakestore
+
+import (
+	"encoding/json"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *storeTestSuite) TestFaultStatus(c *C) {
+	s.store.AddFault("/v2/assertions/", Fault{Status: 503})
+
+	resp, err := s.StoreGet("/v2/assertions/account/testrootorg")
+	c.Assert(err, IsNil)
+	defer resp.Body.Close()
+
+	c.Check(resp.StatusCode, Equals, 503)
+	c.Check(resp.Header.Get("Content-Type"), Equals, "application/json")
+	var body struct {
+		ErrorList []map[string]string `json:"error-list"`
+	}
+	c.Assert(json.NewDecoder(resp.Body).Decode(&body), IsNil)
+	c.Check(body.ErrorList, DeepEquals, []map[string]string{
+		{"code": "fake-store-fault", "message": "Service Unavailable"},
+	})
+
+	// other endpoints are not affected
+	resp, err = s.StoreGet("/")
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+	c.Check(resp.StatusCode, Equals, 418)
+}
+
+func (s *storeTestSuite) TestFaultTimes(c *C) {
+	s.store.AddFault("/", Fault{Status: 500, Times: 2})
+	s.store.AddFault("/", Fault{Status: 502, Times: 1})
+
+	var statuses []int
+	for i := 0; i < 4; i++ {
+		resp, err := s.StoreGet("/")
+		c.Assert(err, IsNil)
+		resp.Body.Close()
+		statuses = append(statuses, resp.StatusCode)
+	}
+	c.Check(statuses, DeepEquals, []int{500, 500, 502, 418})
+}
+
+func (s *storeTestSuite) TestFaultLatency(c *C) {
+	s.store.AddFault("/", Fault{Latency: 50 * time.Millisecond})
+
+	start := time.Now()
+	resp, err := s.StoreGet("/")
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+	c.Check(resp.StatusCode, Equals, 418)
+	c.Check(time.Since(start) >= 50*time.Millisecond, Equals, true)
+}
+
+func (s *storeTestSuite) TestClearFaults(c *C) {
+	s.store.AddFault("/", Fault{Status: 500})
+	s.store.ClearFaults()
+
+	resp, err := s.StoreGet("/")
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+	c.Check(resp.StatusCode, Equals, 418)
+}
@@ -17,7 +17,11 @@
  *
  */
 
-package store
+// Package fakestore implements an in-process store server serving snaps,
+// deltas, assertions and channel maps from a directory, for use by
+// integration tests and the spread test runner. The store can be scripted
+// to fail or delay requests with AddFault.
+package fakestore
 
 import (
 	"bufio"
@@ -34,12 +38,14 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/asserts/sysdb"
 	"github.com/snapcore/snapd/asserts/systestkeys"
 	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/snapfile"
 	"github.com/snapcore/snapd/snapdenv"
@@ -71,6 +77,9 @@ type Store struct {
 	srv *http.Server
 
 	channelRepository *ChannelRepository
+
+	faultsMu sync.Mutex
+	faults   []*scriptedFault
 }
 
 // NewStore creates a new store server serving snaps from the given top directory and assertions from topDir/asserts. If assertFallback is true missing assertions are looked up in the main online store.
@@ -90,8 +99,7 @@ func NewStore(topDir, addr string, assertFallback bool) *Store {
 
 		url: fmt.Sprintf("http://%s", addr),
 		srv: &http.Server{
-			Addr:    addr,
-			Handler: mux,
+			Addr: addr,
 		},
 		channelRepository: &ChannelRepository{
 			rootDir: filepath.Join(topDir, "channels"),
@@ -113,6 +121,8 @@ func NewStore(topDir, addr string, assertFallback bool) *Store {
 
 	mux.HandleFunc("/v2/repairs/", store.repairsEndpoint)
 
+	store.srv.Handler = store.withFaults(mux)
+
 	return store
 }
 
@@ -642,6 +652,7 @@ func (s *Store) collectAssertions() (asserts.Backstore, error) {
 type currentSnap struct {
 	SnapID          string `json:"snap-id"`
 	InstanceKey     string `json:"instance-key"`
+	Revision        int    `json:"revision"`
 	TrackingChannel string `json:"tracking-channel"`
 }
 
@@ -682,9 +693,10 @@ type detailsResultV2 struct {
 		Username string `json:"username"`
 	} `json:"publisher"`
 	Download struct {
-		URL      string `json:"url"`
-		Sha3_384 string `json:"sha3-384"`
-		Size     uint64 `json:"size"`
+		URL      string      `json:"url"`
+		Sha3_384 string      `json:"sha3-384"`
+		Size     uint64      `json:"size"`
+		Deltas   []deltaJSON `json:"deltas,omitempty"`
 	} `json:"download"`
 	Version     string `json:"version"`
 	Revision    int    `json:"revision"`
@@ -739,6 +751,8 @@ func (s *Store) snapActionEndpoint(w http.ResponseWriter, req *http.Request) {
 		}
 	}
 
+	deltaFormat := req.Header.Get("Snap-Accept-Delta-Format")
+
 	// check if we have downloadable snap of the given SnapID or name
 	for _, a := range actions {
 		name := a.Name
@@ -809,6 +823,21 @@ func (s *Store) snapActionEndpoint(w http.ResponseWriter, req *http.Request) {
 		res.Snap.Download.URL = fmt.Sprintf("%s/download/%s", s.RealURL(req), filepath.Base(fn))
 		res.Snap.Download.Sha3_384 = hexify(essInfo.Digest)
 		res.Snap.Download.Size = essInfo.Size
+		if deltaFormat != "" {
+			for _, cur := range reqData.Context {
+				if cur.InstanceKey != a.InstanceKey || cur.Revision == 0 || cur.Revision == essInfo.Revision {
+					continue
+				}
+				delta, err := s.findDelta(req, essInfo.Name, deltaFormat, cur.Revision, essInfo.Revision)
+				if err != nil {
+					http.Error(w, err.Error(), 500)
+					return
+				}
+				if delta != nil {
+					res.Snap.Download.Deltas = append(res.Snap.Download.Deltas, *delta)
+				}
+			}
+		}
 		replyData.Results = append(replyData.Results, res)
 	}
 
@@ -822,6 +851,66 @@ func (s *Store) snapActionEndpoint(w http.ResponseWriter, req *http.Request) {
 	w.Write(out)
 }
 
+type deltaJSON struct {
+	Format   string `json:"format"`
+	Sha3_384 string `json:"sha3-384"`
+	Size     uint64 `json:"size"`
+	Source   int    `json:"source"`
+	Target   int    `json:"target"`
+	URL      string `json:"url"`
+}
+
+func deltaFilename(snapName, format string, source, target int) string {
+	return fmt.Sprintf("%s_%d_%d.%s", snapName, source, target, format)
+}
+
+// findDelta looks for a delta between the given revisions of a snap in the
+// store directory, as added by AddDelta.
+func (s *Store) findDelta(req *http.Request, snapName, format string, source, target int) (*deltaJSON, error) {
+	fn := filepath.Join(s.blobDir, deltaFilename(snapName, format, source, target))
+	if !osutil.FileExists(fn) {
+		return nil, nil
+	}
+	digest, size, err := asserts.SnapFileSHA3_384(fn)
+	if err != nil {
+		return nil, fmt.Errorf("cannot compute digest of delta %q: %v", fn, err)
+	}
+	return &deltaJSON{
+		Format:   format,
+		Sha3_384: hexify(digest),
+		Size:     size,
+		Source:   source,
+		Target:   target,
+		URL:      fmt.Sprintf("%s/download/%s", s.RealURL(req), filepath.Base(fn)),
+	}, nil
+}
+
+// AddDelta makes the store offer the given delta file, in the given format,
+// to devices refreshing the snap from the source to the target revision.
+func (s *Store) AddDelta(deltaFn, snapName, format string, source, target int) error {
+	dst := filepath.Join(s.blobDir, deltaFilename(snapName, format, source, target))
+	return osutil.CopyFile(deltaFn, dst, osutil.CopyFlagOverwrite)
+}
+
+// AddToChannel makes the store serve the given snap file in the given
+// channel, in addition to the ones it was already added to.
+func (s *Store) AddToChannel(snapFn, channel string) error {
+	digest, _, err := asserts.SnapFileSHA3_384(snapFn)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.channelRepository.rootDir, 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(s.channelRepository.rootDir, digest), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = fmt.Fprintf(f, "%s\n", channel)
+	return err
+}
+
 func isAutoRefreshRequest(req *http.Request) bool {
 	return req.Header.Get("Snap-Refresh-Reason") == "scheduled"
 }
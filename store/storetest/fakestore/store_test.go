@@ -17,7 +17,7 @@
  *
  */
 
-package store
+package fakestore
 
 import (
 	"bytes"
@@ -426,14 +426,7 @@ func (s *storeTestSuite) makeAssertions(c *C, snapFn, name, snapID, develName, d
 }
 
 func (s *storeTestSuite) addToChannel(c *C, snapFn, channel string) {
-	dgst, _, err := asserts.SnapFileSHA3_384(snapFn)
-	c.Assert(err, IsNil)
-
-	f, err := os.OpenFile(filepath.Join(s.store.blobDir, "channels", dgst), os.O_CREATE|os.O_WRONLY, 0644)
-	c.Assert(err, IsNil)
-	defer f.Close()
-
-	fmt.Fprintf(f, "%s\n", channel)
+	c.Assert(s.store.AddToChannel(snapFn, channel), IsNil)
 }
 
 func (s *storeTestSuite) TestMakeTestSnap(c *C) {
@@ -1100,3 +1093,61 @@ func (s *storeTestSuite) TestSnapActionEndpointUnknownSnapAutoRefresh(c *C) {
 		},
 	})
 }
+
+func (s *storeTestSuite) TestSnapActionEndpointDeltas(c *C) {
+	snapFn := s.makeTestSnap(c, "name: foo\nversion: 10")
+	s.makeAssertions(c, snapFn, "foo", "xidididididididididididididididid", "foo-devel", "foo-devel-id", 99)
+
+	deltaFn := filepath.Join(c.MkDir(), "delta")
+	c.Assert(os.WriteFile(deltaFn, []byte("delta"), 0644), IsNil)
+	c.Assert(s.store.AddDelta(deltaFn, "foo", "xdelta3", 98, 99), IsNil)
+	deltaSha3_384, deltaSize := getSha(deltaFn)
+
+	refresh := func(deltaFormat string, revision int) map[string]interface{} {
+		req, err := http.NewRequest("POST", s.store.URL()+"/v2/snaps/refresh", bytes.NewReader([]byte(fmt.Sprintf(`{
+"context": [{"instance-key":"eFe8BTR5L5V9F7yHeMAPxkEr2NdUXMtw","snap-id":"xidididididididididididididididid","tracking-channel":"stable","revision":%d}],
+"actions": [{"action":"refresh","instance-key":"eFe8BTR5L5V9F7yHeMAPxkEr2NdUXMtw","snap-id":"xidididididididididididididididid"}]
+}`, revision))))
+		c.Assert(err, IsNil)
+		req.Header.Set("Content-Type", "application/json")
+		if deltaFormat != "" {
+			req.Header.Set("Snap-Accept-Delta-Format", deltaFormat)
+		}
+		resp, err := s.client.Do(req)
+		c.Assert(err, IsNil)
+		defer resp.Body.Close()
+		c.Assert(resp.StatusCode, Equals, 200)
+
+		var body struct {
+			Results []map[string]interface{}
+		}
+		c.Assert(json.NewDecoder(resp.Body).Decode(&body), IsNil)
+		c.Assert(body.Results, HasLen, 1)
+		return body.Results[0]["snap"].(map[string]interface{})["download"].(map[string]interface{})
+	}
+
+	download := refresh("xdelta3", 98)
+	c.Check(download["deltas"], DeepEquals, []interface{}{
+		map[string]interface{}{
+			"format":   "xdelta3",
+			"sha3-384": deltaSha3_384,
+			"size":     float64(deltaSize),
+			"source":   float64(98),
+			"target":   float64(99),
+			"url":      s.store.URL() + "/download/foo_98_99.xdelta3",
+		},
+	})
+
+	// no deltas when not asked for, for other formats or revisions
+	c.Check(refresh("", 98)["deltas"], IsNil)
+	c.Check(refresh("bsdiff", 98)["deltas"], IsNil)
+	c.Check(refresh("xdelta3", 97)["deltas"], IsNil)
+
+	resp, err := s.StoreGet("/download/foo_98_99.xdelta3")
+	c.Assert(err, IsNil)
+	defer resp.Body.Close()
+	c.Check(resp.StatusCode, Equals, 200)
+	content, err := io.ReadAll(resp.Body)
+	c.Assert(err, IsNil)
+	c.Check(string(content), Equals, "delta")
+}
@@ -0,0 +1,249 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package tooling
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/snap"
+)
+
+// A bundle is a tar archive carrying snaps together with all the assertions
+// needed to install them, so that they can be installed on a machine that
+// cannot reach the store. Besides the snap files it contains:
+//
+//	bundle.assert: the assertions, as a stream
+//	bundle.json: the manifest, listing the snaps in installation order
+const (
+	bundleFormat       = 1
+	bundleManifestName = "bundle.json"
+	bundleAssertsName  = "bundle.assert"
+)
+
+// BundleSnap describes a snap carried by a bundle.
+type BundleSnap struct {
+	Name     string        `json:"name"`
+	Revision snap.Revision `json:"revision"`
+	File     string        `json:"file"`
+
+	// Path is where the snap was extracted to by ExtractBundle.
+	Path string `json:"-"`
+}
+
+type bundleManifest struct {
+	Format int           `json:"format"`
+	Snaps  []*BundleSnap `json:"snaps"`
+}
+
+// Bundle describes an extracted bundle.
+type Bundle struct {
+	// Snaps are the snaps of the bundle, in the order they need to be
+	// installed in.
+	Snaps []*BundleSnap
+	// AssertsPath is the path of the extracted assertions stream.
+	AssertsPath string
+}
+
+// BundleWriter writes a bundle to an io.Writer.
+type BundleWriter struct {
+	tw       *tar.Writer
+	manifest bundleManifest
+	asserts  bytes.Buffer
+	enc      *asserts.Encoder
+	seen     map[string]bool
+}
+
+// NewBundleWriter returns a BundleWriter writing to w.
+func NewBundleWriter(w io.Writer) *BundleWriter {
+	bw := &BundleWriter{
+		tw:       tar.NewWriter(w),
+		manifest: bundleManifest{Format: bundleFormat},
+		seen:     make(map[string]bool),
+	}
+	bw.enc = asserts.NewEncoder(&bw.asserts)
+	return bw
+}
+
+// AddSnap adds the snap file at snapPath described by info to the bundle.
+// Snaps need to be added in the order they need to be installed in.
+func (bw *BundleWriter) AddSnap(snapPath string, info *snap.Info) error {
+	f, err := os.Open(snapPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Base(snapPath)
+	for _, s := range bw.manifest.Snaps {
+		if s.File == name {
+			return fmt.Errorf("cannot add %q to bundle twice", name)
+		}
+	}
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    fi.Size(),
+		ModTime: fi.ModTime(),
+	}
+	if err := bw.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if _, err := io.Copy(bw.tw, f); err != nil {
+		return err
+	}
+	bw.manifest.Snaps = append(bw.manifest.Snaps, &BundleSnap{
+		Name:     info.InstanceName(),
+		Revision: info.Revision,
+		File:     name,
+	})
+	return nil
+}
+
+// AddAssertion adds the given assertion to the bundle, it can be used as the
+// save function of an assertion fetcher. Assertions already added are
+// ignored.
+func (bw *BundleWriter) AddAssertion(a asserts.Assertion) error {
+	k := a.Ref().Unique()
+	if bw.seen[k] {
+		return nil
+	}
+	bw.seen[k] = true
+	return bw.enc.Encode(a)
+}
+
+func (bw *BundleWriter) writeFile(name string, data []byte) error {
+	hdr := &tar.Header{
+		Name: name,
+		Mode: 0644,
+		Size: int64(len(data)),
+	}
+	if err := bw.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := bw.tw.Write(data)
+	return err
+}
+
+// Close writes the assertions and the manifest of the bundle and finishes
+// it. It does not close the underlying io.Writer.
+func (bw *BundleWriter) Close() error {
+	if len(bw.manifest.Snaps) == 0 {
+		return errors.New("cannot write a bundle without snaps")
+	}
+	if err := bw.writeFile(bundleAssertsName, bw.asserts.Bytes()); err != nil {
+		return err
+	}
+	manifest, err := json.Marshal(&bw.manifest)
+	if err != nil {
+		return err
+	}
+	if err := bw.writeFile(bundleManifestName, manifest); err != nil {
+		return err
+	}
+	return bw.tw.Close()
+}
+
+func validBundleEntry(name string) bool {
+	return name != "" && name != "." && name != ".." && filepath.Base(name) == name
+}
+
+// ExtractBundle extracts the bundle read from r into targetDir, which must
+// exist.
+func ExtractBundle(r io.Reader, targetDir string) (*Bundle, error) {
+	tr := tar.NewReader(r)
+	var manifestData []byte
+	extracted := make(map[string]bool)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot read bundle: %v", err)
+		}
+		if hdr.Typeflag != tar.TypeReg || !validBundleEntry(hdr.Name) {
+			return nil, fmt.Errorf("cannot read bundle: unexpected entry %q", hdr.Name)
+		}
+		if extracted[hdr.Name] {
+			return nil, fmt.Errorf("cannot read bundle: duplicated entry %q", hdr.Name)
+		}
+		extracted[hdr.Name] = true
+
+		if hdr.Name == bundleManifestName {
+			manifestData, err = io.ReadAll(tr)
+			if err != nil {
+				return nil, fmt.Errorf("cannot read bundle: %v", err)
+			}
+			continue
+		}
+		if err := extractBundleFile(tr, filepath.Join(targetDir, hdr.Name)); err != nil {
+			return nil, fmt.Errorf("cannot extract bundle: %v", err)
+		}
+	}
+
+	if manifestData == nil {
+		return nil, errors.New("cannot read bundle: no manifest")
+	}
+	var manifest bundleManifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, fmt.Errorf("cannot read bundle manifest: %v", err)
+	}
+	if manifest.Format != bundleFormat {
+		return nil, fmt.Errorf("cannot read bundle: unsupported format %d", manifest.Format)
+	}
+	if !extracted[bundleAssertsName] {
+		return nil, errors.New("cannot read bundle: no assertions")
+	}
+	for _, s := range manifest.Snaps {
+		if !validBundleEntry(s.File) || !extracted[s.File] {
+			return nil, fmt.Errorf("cannot read bundle: snap %q is missing", s.Name)
+		}
+		s.Path = filepath.Join(targetDir, s.File)
+	}
+
+	return &Bundle{
+		Snaps:       manifest.Snaps,
+		AssertsPath: filepath.Join(targetDir, bundleAssertsName),
+	}, nil
+}
+
+func extractBundleFile(r io.Reader, path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,177 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package tooling_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/asserts/assertstest"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/store/tooling"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type bundleSuite struct {
+	testutil.BaseTest
+
+	storeSigning *assertstest.StoreStack
+}
+
+var _ = Suite(&bundleSuite{})
+
+func (s *bundleSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+	s.storeSigning = assertstest.NewStoreStack("canonical", nil)
+}
+
+func (s *bundleSuite) writeSnap(c *C, dir, name string, rev int) (string, *snap.Info) {
+	info := &snap.Info{
+		SideInfo: snap.SideInfo{
+			RealName: name,
+			Revision: snap.R(rev),
+		},
+	}
+	path := filepath.Join(dir, info.Filename())
+	c.Assert(os.WriteFile(path, []byte(name+" content"), 0644), IsNil)
+	return path, info
+}
+
+func (s *bundleSuite) TestWriteAndExtract(c *C) {
+	srcDir := c.MkDir()
+	basePath, baseInfo := s.writeSnap(c, srcDir, "core22", 10)
+	appPath, appInfo := s.writeSnap(c, srcDir, "foo", 2)
+
+	var buf bytes.Buffer
+	bw := tooling.NewBundleWriter(&buf)
+	c.Assert(bw.AddSnap(basePath, baseInfo), IsNil)
+	c.Assert(bw.AddSnap(appPath, appInfo), IsNil)
+	c.Assert(bw.AddAssertion(s.storeSigning.StoreAccountKey("")), IsNil)
+	c.Assert(bw.AddAssertion(s.storeSigning.TrustedAccount), IsNil)
+	// duplicated assertions are ignored
+	c.Assert(bw.AddAssertion(s.storeSigning.TrustedAccount), IsNil)
+	c.Assert(bw.Close(), IsNil)
+
+	targetDir := c.MkDir()
+	bundle, err := tooling.ExtractBundle(&buf, targetDir)
+	c.Assert(err, IsNil)
+	c.Assert(bundle.Snaps, HasLen, 2)
+	c.Check(bundle.Snaps[0], DeepEquals, &tooling.BundleSnap{
+		Name:     "core22",
+		Revision: snap.R(10),
+		File:     "core22_10.snap",
+		Path:     filepath.Join(targetDir, "core22_10.snap"),
+	})
+	c.Check(bundle.Snaps[1], DeepEquals, &tooling.BundleSnap{
+		Name:     "foo",
+		Revision: snap.R(2),
+		File:     "foo_2.snap",
+		Path:     filepath.Join(targetDir, "foo_2.snap"),
+	})
+	c.Check(bundle.Snaps[0].Path, testutil.FileEquals, "core22 content")
+	c.Check(bundle.Snaps[1].Path, testutil.FileEquals, "foo content")
+
+	f, err := os.Open(bundle.AssertsPath)
+	c.Assert(err, IsNil)
+	defer f.Close()
+	dec := asserts.NewDecoder(f)
+	var types []string
+	for {
+		a, err := dec.Decode()
+		if err != nil {
+			break
+		}
+		types = append(types, a.Type().Name)
+	}
+	c.Check(types, DeepEquals, []string{"account-key", "account"})
+}
+
+func (s *bundleSuite) TestWriteNoSnaps(c *C) {
+	var buf bytes.Buffer
+	bw := tooling.NewBundleWriter(&buf)
+	c.Check(bw.Close(), ErrorMatches, "cannot write a bundle without snaps")
+}
+
+func (s *bundleSuite) TestWriteSnapTwice(c *C) {
+	path, info := s.writeSnap(c, c.MkDir(), "foo", 2)
+
+	var buf bytes.Buffer
+	bw := tooling.NewBundleWriter(&buf)
+	c.Assert(bw.AddSnap(path, info), IsNil)
+	c.Check(bw.AddSnap(path, info), ErrorMatches, `cannot add "foo_2.snap" to bundle twice`)
+}
+
+func makeTar(c *C, entries map[string]string, order []string) *bytes.Buffer {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, name := range order {
+		data := entries[name]
+		c.Assert(tw.WriteHeader(&tar.Header{
+			Name: name,
+			Mode: 0644,
+			Size: int64(len(data)),
+		}), IsNil)
+		_, err := tw.Write([]byte(data))
+		c.Assert(err, IsNil)
+	}
+	c.Assert(tw.Close(), IsNil)
+	return &buf
+}
+
+func (s *bundleSuite) TestExtractErrors(c *C) {
+	manifest := `{"format":1,"snaps":[{"name":"foo","revision":"2","file":"foo_2.snap"}]}`
+	for _, t := range []struct {
+		entries map[string]string
+		order   []string
+		err     string
+	}{{
+		entries: map[string]string{"../foo_2.snap": "x"},
+		order:   []string{"../foo_2.snap"},
+		err:     `cannot read bundle: unexpected entry "../foo_2.snap"`,
+	}, {
+		entries: map[string]string{"foo_2.snap": "x"},
+		order:   []string{"foo_2.snap", "foo_2.snap"},
+		err:     `cannot read bundle: duplicated entry "foo_2.snap"`,
+	}, {
+		entries: map[string]string{"foo_2.snap": "x", "bundle.assert": ""},
+		order:   []string{"foo_2.snap", "bundle.assert"},
+		err:     `cannot read bundle: no manifest`,
+	}, {
+		entries: map[string]string{"bundle.json": `{"format":2}`},
+		order:   []string{"bundle.json"},
+		err:     `cannot read bundle: unsupported format 2`,
+	}, {
+		entries: map[string]string{"foo_2.snap": "x", "bundle.json": manifest},
+		order:   []string{"foo_2.snap", "bundle.json"},
+		err:     `cannot read bundle: no assertions`,
+	}, {
+		entries: map[string]string{"bundle.assert": "", "bundle.json": manifest},
+		order:   []string{"bundle.assert", "bundle.json"},
+		err:     `cannot read bundle: snap "foo" is missing`,
+	}} {
+		_, err := tooling.ExtractBundle(makeTar(c, t.entries, t.order), c.MkDir())
+		c.Check(err, ErrorMatches, t.err)
+	}
+}
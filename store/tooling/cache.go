@@ -0,0 +1,330 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package tooling
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/osutil/sys"
+)
+
+const (
+	sharedCacheLockFile = ".lock"
+	blobPrefix          = "blob-"
+	assertionPrefix     = "assertion-"
+
+	// maxSharedCacheBlobs is the number of snaps and components kept in
+	// the shared cache
+	maxSharedCacheBlobs = 50
+)
+
+// maxSharedCacheSize is the total size in bytes of the snaps and components
+// kept in the shared cache.
+var maxSharedCacheSize int64 = 4 * 1024 * 1024 * 1024
+
+// cacheableAssertionTypes are the assertion types whose primary key is a
+// content hash. Assertions of these types can still be re-issued with a
+// higher revision or revoked, so the cached ones are used only when the
+// store cannot be reached.
+var cacheableAssertionTypes = map[*asserts.AssertionType]bool{
+	asserts.AccountKeyType:           true,
+	asserts.SnapRevisionType:         true,
+	asserts.SnapResourceRevisionType: true,
+}
+
+var (
+	osGeteuid = sys.Geteuid
+
+	// sharedCacheOwner is the expected owner of the shared cache directory,
+	// overridden in the unit tests
+	sharedCacheOwner sys.UserID = 0
+)
+
+// sharedCache is a content-addressable cache of snaps, components and
+// assertions, shared by the tools run by the different users of the system
+// so that repeated downloads and prepare-image runs reuse the artifacts.
+//
+// The cache directory must be created by root with the sticky bit and be
+// writable by everyone, like /tmp, so that users can add entries but cannot
+// remove or replace the ones of others. Blobs are verified against their
+// digest after being copied out of the cache. Assertions added by other
+// users are used only if they can be checked right away against the
+// assertion database.
+type sharedCache struct {
+	dir string
+}
+
+// openSharedCache returns the shared cache in the given directory, or nil
+// if it cannot be used safely. The directory is created if running as its
+// owner, that is root.
+func openSharedCache(dir string) *sharedCache {
+	if osGeteuid() == sharedCacheOwner {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			logger.Debugf("cannot create shared cache: %v", err)
+			return nil
+		}
+		// MkdirAll is subject to the umask and does not set the sticky bit
+		if err := os.Chmod(dir, 0777|os.ModeSticky); err != nil {
+			logger.Debugf("cannot set up shared cache: %v", err)
+			return nil
+		}
+	}
+	fi, err := os.Lstat(dir)
+	if err != nil {
+		return nil
+	}
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok || !fi.IsDir() || sys.UserID(st.Uid) != sharedCacheOwner || fi.Mode()&os.ModeSticky == 0 || fi.Mode().Perm() != 0777 {
+		logger.Debugf("not using shared cache %s: unsafe ownership or permissions", dir)
+		return nil
+	}
+	return &sharedCache{dir: dir}
+}
+
+func (c *sharedCache) lock(exclusive bool) (unlock func(), err error) {
+	lockPath := filepath.Join(c.dir, sharedCacheLockFile)
+	// the lock file may be owned by another user, in which case it cannot
+	// be opened with O_CREAT in a sticky directory, but flock works on
+	// read-only files too
+	flock, err := osutil.OpenExistingLockForReading(lockPath)
+	if errors.Is(err, os.ErrNotExist) {
+		flock, err = osutil.NewFileLockWithMode(lockPath, 0644)
+		if errors.Is(err, os.ErrPermission) {
+			// lost a race with another user
+			flock, err = osutil.OpenExistingLockForReading(lockPath)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if exclusive {
+		err = flock.Lock()
+	} else {
+		err = flock.ReadLock()
+	}
+	if err != nil {
+		flock.Close()
+		return nil, err
+	}
+	return func() { flock.Close() }, nil
+}
+
+func (c *sharedCache) blobPath(sha3_384 string) string {
+	return filepath.Join(c.dir, blobPrefix+sha3_384)
+}
+
+func isHexDigest(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// getBlob puts the cached blob with the given hex encoded SHA3-384 digest
+// and size into targetFn. It returns whether this was possible.
+func (c *sharedCache) getBlob(sha3_384 string, size int64, targetFn string) bool {
+	if c == nil || !isHexDigest(sha3_384) {
+		return false
+	}
+	unlock, err := c.lock(false)
+	if err != nil {
+		logger.Debugf("cannot lock shared cache: %v", err)
+		return false
+	}
+	defer unlock()
+
+	cachePath := c.blobPath(sha3_384)
+	fi, err := os.Lstat(cachePath)
+	if err != nil || !fi.Mode().IsRegular() || fi.Size() != size {
+		return false
+	}
+	// hard link only the entries we own, the entries of other users are
+	// copied so that they cannot change the content afterwards
+	linked := false
+	if st, ok := fi.Sys().(*syscall.Stat_t); ok && sys.UserID(st.Uid) == osGeteuid() {
+		linked = os.Link(cachePath, targetFn) == nil
+	}
+	if !linked {
+		if err := osutil.CopyFile(cachePath, targetFn, osutil.CopyFlagOverwrite); err != nil {
+			logger.Debugf("cannot copy %s from shared cache: %v", targetFn, err)
+			return false
+		}
+	}
+	dgst, sz, err := osutil.FileDigest(targetFn, crypto.SHA3_384)
+	if err != nil || sz != uint64(size) || fmt.Sprintf("%x", dgst) != sha3_384 {
+		logger.Noticef("ignoring invalid entry %s of shared cache", cachePath)
+		os.Remove(targetFn)
+		return false
+	}
+	logger.Debugf("using shared cache for %s", targetFn)
+	return true
+}
+
+// putBlob adds the given file with the given hex encoded SHA3-384 digest to
+// the cache.
+func (c *sharedCache) putBlob(sha3_384, sourceFn string) error {
+	if c == nil || !isHexDigest(sha3_384) {
+		return nil
+	}
+	unlock, err := c.lock(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	cachePath := c.blobPath(sha3_384)
+	if osutil.FileExists(cachePath) {
+		return nil
+	}
+	f, err := os.Open(sourceFn)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := osutil.AtomicWrite(cachePath, f, 0644, 0); err != nil {
+		return err
+	}
+	c.cleanup()
+	return nil
+}
+
+// cleanup removes the least recently added blobs so that at most
+// maxSharedCacheBlobs, totalling at most maxSharedCacheSize bytes, are kept.
+// Only the entries of the current user can be removed, the ones of other
+// users are skipped.
+func (c *sharedCache) cleanup() {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+	var blobs []os.FileInfo
+	var size int64
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), blobPrefix) {
+			continue
+		}
+		if fi, err := entry.Info(); err == nil {
+			blobs = append(blobs, fi)
+			size += fi.Size()
+		}
+	}
+	count := len(blobs)
+	if count <= maxSharedCacheBlobs && size <= maxSharedCacheSize {
+		return
+	}
+	sort.Slice(blobs, func(i, j int) bool { return blobs[i].ModTime().Before(blobs[j].ModTime()) })
+	for _, fi := range blobs {
+		if count <= maxSharedCacheBlobs && size <= maxSharedCacheSize {
+			break
+		}
+		if err := os.Remove(filepath.Join(c.dir, fi.Name())); err == nil {
+			count--
+			size -= fi.Size()
+		}
+	}
+}
+
+// assertionKey returns a key for the given reference that does not depend on
+// whether optional primary key headers with default values are included.
+func assertionKey(ref *asserts.Ref) string {
+	key := asserts.ReducePrimaryKey(ref.Type, ref.PrimaryKey)
+	return ref.Type.Name + "/" + strings.Join(key, "/")
+}
+
+func (c *sharedCache) assertionPath(ref *asserts.Ref) string {
+	return filepath.Join(c.dir, fmt.Sprintf("%s%x", assertionPrefix, sha256.Sum256([]byte(assertionKey(ref)))))
+}
+
+// getAssertion returns the cached assertion for the given reference, or nil.
+// The assertion is not verified, it is trusted only if it was added by root
+// or by the current user, otherwise it must be checked before being used.
+func (c *sharedCache) getAssertion(ref *asserts.Ref) (a asserts.Assertion, trusted bool) {
+	if c == nil || !cacheableAssertionTypes[ref.Type] {
+		return nil, false
+	}
+	unlock, err := c.lock(false)
+	if err != nil {
+		logger.Debugf("cannot lock shared cache: %v", err)
+		return nil, false
+	}
+	defer unlock()
+
+	f, err := os.Open(c.assertionPath(ref))
+	if err != nil {
+		return nil, false
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, false
+	}
+	if st, ok := fi.Sys().(*syscall.Stat_t); ok {
+		owner := sys.UserID(st.Uid)
+		trusted = owner == sharedCacheOwner || owner == osGeteuid()
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, false
+	}
+	a, err = asserts.Decode(data)
+	if err != nil || a.Type() != ref.Type || assertionKey(a.Ref()) != assertionKey(ref) {
+		logger.Noticef("ignoring invalid entry for %v in shared cache", ref)
+		return nil, false
+	}
+	return a, trusted
+}
+
+// putAssertion adds the given assertion to the cache, if it is of a type
+// that can be cached, replacing a cached assertion with a lower revision.
+func (c *sharedCache) putAssertion(a asserts.Assertion) error {
+	if c == nil || !cacheableAssertionTypes[a.Type()] {
+		return nil
+	}
+	unlock, err := c.lock(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	cachePath := c.assertionPath(a.Ref())
+	if data, err := os.ReadFile(cachePath); err == nil {
+		if old, err := asserts.Decode(data); err == nil && old.Revision() >= a.Revision() {
+			return nil
+		}
+	}
+	return osutil.AtomicWriteFile(cachePath, asserts.Encode(a), 0644, 0)
+}
@@ -0,0 +1,456 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package tooling_test
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/crypto/sha3"
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/asserts/assertstest"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/osutil/sys"
+	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/progress"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/store"
+	"github.com/snapcore/snapd/store/tooling"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type sharedCacheSuite struct {
+	testutil.BaseTest
+
+	storeSigning *assertstest.StoreStack
+
+	downloads  []string
+	assertions []string
+	// storeErrs are the errors returned by the store for the given
+	// assertion types
+	storeErrs map[*asserts.AssertionType]error
+}
+
+var _ = Suite(&sharedCacheSuite{})
+
+func (s *sharedCacheSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+	dirs.SetRootDir(c.MkDir())
+	s.AddCleanup(func() { dirs.SetRootDir("") })
+	s.AddCleanup(tooling.MockSharedCacheOwner(sys.UserID(os.Geteuid())))
+	s.AddCleanup(tooling.MockGeteuid(sys.UserID(os.Geteuid())))
+
+	s.storeSigning = assertstest.NewStoreStack("canonical", nil)
+	s.downloads = nil
+	s.assertions = nil
+	s.storeErrs = nil
+}
+
+// interface for the store
+func (s *sharedCacheSuite) SnapAction(context.Context, []*store.CurrentSnap, []*store.SnapAction, store.AssertionQuery, *auth.UserState, *store.RefreshOptions) ([]store.SnapActionResult, []store.AssertionResult, error) {
+	panic("unexpected call to SnapAction")
+}
+
+func (s *sharedCacheSuite) Download(ctx context.Context, name, targetFn string, downloadInfo *snap.DownloadInfo, pbar progress.Meter, user *auth.UserState, dlOpts *store.DownloadOptions) error {
+	s.downloads = append(s.downloads, name)
+	return os.WriteFile(targetFn, []byte(name+" content"), 0644)
+}
+
+func (s *sharedCacheSuite) Assertion(assertType *asserts.AssertionType, primaryKey []string, user *auth.UserState) (asserts.Assertion, error) {
+	ref := &asserts.Ref{Type: assertType, PrimaryKey: primaryKey}
+	s.assertions = append(s.assertions, ref.Unique())
+	if err := s.storeErrs[assertType]; err != nil {
+		return nil, err
+	}
+	return ref.Resolve(s.storeSigning.Find)
+}
+
+func (s *sharedCacheSuite) SeqFormingAssertion(*asserts.AssertionType, []string, int, *auth.UserState) (asserts.Assertion, error) {
+	panic("unexpected call to SeqFormingAssertion")
+}
+
+func (s *sharedCacheSuite) SetAssertionMaxFormats(map[string]int) {}
+
+func (s *sharedCacheSuite) openCache(c *C) *tooling.SharedCache {
+	cache := tooling.OpenSharedCache(dirs.SnapToolingCacheDir)
+	c.Assert(cache, NotNil)
+	return cache
+}
+
+func (s *sharedCacheSuite) toolingStore(c *C) *tooling.ToolingStore {
+	tsto := tooling.MockToolingStore(s)
+	tsto.Stdout = io.Discard
+	tsto.SetSharedCache(s.openCache(c))
+	return tsto
+}
+
+func hexSha3_384(content string) string {
+	return fmt.Sprintf("%x", sha3.Sum384([]byte(content)))
+}
+
+func (s *sharedCacheSuite) TestOpenSharedCacheCreates(c *C) {
+	s.openCache(c)
+
+	fi, err := os.Stat(dirs.SnapToolingCacheDir)
+	c.Assert(err, IsNil)
+	c.Check(fi.Mode(), Equals, os.ModeDir|os.ModeSticky|0777)
+}
+
+func (s *sharedCacheSuite) TestOpenSharedCacheUnsafe(c *C) {
+	// not created by a regular user
+	restore := tooling.MockSharedCacheOwner(sys.UserID(os.Geteuid()) + 1)
+	defer restore()
+	c.Check(tooling.OpenSharedCache(dirs.SnapToolingCacheDir), IsNil)
+	c.Check(dirs.SnapToolingCacheDir, testutil.FileAbsent)
+
+	// not owned by the expected owner
+	c.Assert(os.MkdirAll(dirs.SnapToolingCacheDir, 0755), IsNil)
+	c.Assert(os.Chmod(dirs.SnapToolingCacheDir, 0777|os.ModeSticky), IsNil)
+	c.Check(tooling.OpenSharedCache(dirs.SnapToolingCacheDir), IsNil)
+
+	// without the sticky bit
+	restore()
+	c.Assert(os.Chmod(dirs.SnapToolingCacheDir, 0777), IsNil)
+	c.Check(tooling.OpenSharedCache(dirs.SnapToolingCacheDir), NotNil)
+	fi, err := os.Stat(dirs.SnapToolingCacheDir)
+	c.Assert(err, IsNil)
+	c.Check(fi.Mode()&os.ModeSticky, Equals, os.ModeSticky)
+
+	// a symlink
+	target := c.MkDir()
+	c.Assert(os.Chmod(target, 0777|os.ModeSticky), IsNil)
+	link := filepath.Join(c.MkDir(), "tooling")
+	c.Assert(os.Symlink(target, link), IsNil)
+	restore = tooling.MockSharedCacheOwner(sys.UserID(os.Geteuid()) + 1)
+	defer restore()
+	c.Check(tooling.OpenSharedCache(link), IsNil)
+}
+
+func (s *sharedCacheSuite) TestBlobs(c *C) {
+	cache := s.openCache(c)
+	dir := c.MkDir()
+	source := filepath.Join(dir, "source")
+	c.Assert(os.WriteFile(source, []byte("content"), 0644), IsNil)
+	digest := hexSha3_384("content")
+
+	target := filepath.Join(dir, "target")
+	c.Check(cache.GetBlob(digest, 7, target), Equals, false)
+
+	c.Assert(cache.PutBlob(digest, source), IsNil)
+	c.Check(filepath.Join(dirs.SnapToolingCacheDir, "blob-"+digest), testutil.FileEquals, "content")
+	// adding it again is fine
+	c.Assert(cache.PutBlob(digest, source), IsNil)
+
+	// wrong size
+	c.Check(cache.GetBlob(digest, 8, target), Equals, false)
+	c.Check(target, testutil.FileAbsent)
+
+	c.Check(cache.GetBlob(digest, 7, target), Equals, true)
+	c.Check(target, testutil.FileEquals, "content")
+}
+
+func (s *sharedCacheSuite) TestBlobsInvalidDigest(c *C) {
+	cache := s.openCache(c)
+	source := filepath.Join(c.MkDir(), "source")
+	c.Assert(os.WriteFile(source, []byte("content"), 0644), IsNil)
+
+	for _, digest := range []string{"", "../foo", "ABCD"} {
+		c.Check(cache.PutBlob(digest, source), IsNil)
+		c.Check(cache.GetBlob(digest, 7, filepath.Join(c.MkDir(), "target")), Equals, false)
+	}
+	entries, err := os.ReadDir(dirs.SnapToolingCacheDir)
+	c.Assert(err, IsNil)
+	for _, entry := range entries {
+		c.Check(entry.Name(), Equals, ".lock")
+	}
+}
+
+func (s *sharedCacheSuite) TestBlobsCorrupted(c *C) {
+	cache := s.openCache(c)
+	digest := hexSha3_384("content")
+	// another user put something else under the digest
+	c.Assert(os.WriteFile(filepath.Join(dirs.SnapToolingCacheDir, "blob-"+digest), []byte("CONTENT"), 0644), IsNil)
+
+	target := filepath.Join(c.MkDir(), "target")
+	c.Check(cache.GetBlob(digest, 7, target), Equals, false)
+	c.Check(target, testutil.FileAbsent)
+}
+
+func (s *sharedCacheSuite) TestBlobsCleanup(c *C) {
+	cache := s.openCache(c)
+	source := filepath.Join(c.MkDir(), "source")
+
+	var digests []string
+	for i := 0; i <= tooling.MaxSharedCacheBlobs; i++ {
+		content := fmt.Sprintf("content %d", i)
+		c.Assert(os.WriteFile(source, []byte(content), 0644), IsNil)
+		digest := hexSha3_384(content)
+		c.Assert(cache.PutBlob(digest, source), IsNil)
+		// make the order of the entries predictable
+		mtime := time.Now().Add(time.Duration(i-tooling.MaxSharedCacheBlobs) * time.Minute)
+		c.Assert(os.Chtimes(filepath.Join(dirs.SnapToolingCacheDir, "blob-"+digest), mtime, mtime), IsNil)
+		digests = append(digests, digest)
+	}
+
+	c.Assert(os.WriteFile(source, []byte("new content"), 0644), IsNil)
+	c.Assert(cache.PutBlob(hexSha3_384("new content"), source), IsNil)
+
+	// the two oldest entries were removed
+	c.Check(filepath.Join(dirs.SnapToolingCacheDir, "blob-"+digests[0]), testutil.FileAbsent)
+	c.Check(filepath.Join(dirs.SnapToolingCacheDir, "blob-"+digests[1]), testutil.FileAbsent)
+	c.Check(filepath.Join(dirs.SnapToolingCacheDir, "blob-"+digests[2]), testutil.FilePresent)
+	c.Check(filepath.Join(dirs.SnapToolingCacheDir, "blob-"+hexSha3_384("new content")), testutil.FilePresent)
+}
+
+func (s *sharedCacheSuite) TestBlobsCleanupSize(c *C) {
+	restore := tooling.MockMaxSharedCacheSize(20)
+	defer restore()
+	cache := s.openCache(c)
+	source := filepath.Join(c.MkDir(), "source")
+
+	var digests []string
+	for i := 0; i < 3; i++ {
+		content := fmt.Sprintf("content %d", i)
+		c.Assert(os.WriteFile(source, []byte(content), 0644), IsNil)
+		digest := hexSha3_384(content)
+		c.Assert(cache.PutBlob(digest, source), IsNil)
+		// make the order of the entries predictable
+		mtime := time.Now().Add(time.Duration(i-3) * time.Minute)
+		c.Assert(os.Chtimes(filepath.Join(dirs.SnapToolingCacheDir, "blob-"+digest), mtime, mtime), IsNil)
+		digests = append(digests, digest)
+	}
+
+	// only the two most recent entries fit in 20 bytes
+	c.Check(filepath.Join(dirs.SnapToolingCacheDir, "blob-"+digests[0]), testutil.FileAbsent)
+	c.Check(filepath.Join(dirs.SnapToolingCacheDir, "blob-"+digests[1]), testutil.FilePresent)
+	c.Check(filepath.Join(dirs.SnapToolingCacheDir, "blob-"+digests[2]), testutil.FilePresent)
+}
+
+func (s *sharedCacheSuite) TestDownloadUsesSharedCache(c *C) {
+	digest := hexSha3_384("foo content")
+	dlInfo := &snap.DownloadInfo{Size: int64(len("foo content")), Sha3_384: digest}
+
+	target1 := filepath.Join(c.MkDir(), "foo_1.snap")
+	c.Assert(s.toolingStore(c).DownloadBlob("foo", target1, dlInfo), IsNil)
+	c.Check(target1, testutil.FileEquals, "foo content")
+	c.Check(s.downloads, DeepEquals, []string{"foo"})
+
+	// another tooling store, for instance in another process
+	target2 := filepath.Join(c.MkDir(), "foo_1.snap")
+	c.Assert(s.toolingStore(c).DownloadBlob("foo", target2, dlInfo), IsNil)
+	c.Check(target2, testutil.FileEquals, "foo content")
+	c.Check(s.downloads, DeepEquals, []string{"foo"})
+}
+
+func (s *sharedCacheSuite) makeSnapRevision(c *C) *asserts.SnapRevision {
+	decl, err := s.storeSigning.Sign(asserts.SnapDeclarationType, map[string]interface{}{
+		"series":       "16",
+		"snap-id":      "snap-id-1",
+		"snap-name":    "foo",
+		"publisher-id": "canonical",
+		"timestamp":    time.Now().Format(time.RFC3339),
+	}, nil, "")
+	c.Assert(err, IsNil)
+	c.Assert(s.storeSigning.Add(decl), IsNil)
+
+	digest, err := asserts.EncodeDigest(crypto.SHA3_384, make([]byte, 48))
+	c.Assert(err, IsNil)
+	a, err := s.storeSigning.Sign(asserts.SnapRevisionType, map[string]interface{}{
+		"snap-id":       "snap-id-1",
+		"snap-sha3-384": digest,
+		"snap-size":     "123",
+		"snap-revision": "1",
+		"developer-id":  "canonical",
+		"timestamp":     time.Now().Format(time.RFC3339),
+	}, nil, "")
+	c.Assert(err, IsNil)
+	c.Assert(s.storeSigning.Add(a), IsNil)
+	return a.(*asserts.SnapRevision)
+}
+
+func (s *sharedCacheSuite) tryFetch(c *C, tsto *tooling.ToolingStore, ref *asserts.Ref, prereqs ...asserts.Assertion) (asserts.Assertion, error) {
+	db, err := asserts.OpenDatabase(&asserts.DatabaseConfig{
+		Backstore: asserts.NewMemoryBackstore(),
+		Trusted:   s.storeSigning.Trusted,
+	})
+	c.Assert(err, IsNil)
+	for _, a := range prereqs {
+		c.Assert(db.Add(a), IsNil)
+	}
+	f := tsto.AssertionFetcher(db, func(asserts.Assertion) error { return nil })
+	if err := f.Fetch(ref); err != nil {
+		return nil, err
+	}
+	return ref.Resolve(db.Find)
+}
+
+func (s *sharedCacheSuite) fetch(c *C, tsto *tooling.ToolingStore, ref *asserts.Ref, prereqs ...asserts.Assertion) asserts.Assertion {
+	a, err := s.tryFetch(c, tsto, ref, prereqs...)
+	c.Assert(err, IsNil)
+	c.Check(a.SignKeyID(), Equals, s.storeSigning.KeyID)
+	return a
+}
+
+func (s *sharedCacheSuite) TestAssertionFetcherUsesSharedCache(c *C) {
+	snapRev := s.makeSnapRevision(c)
+	ref := &asserts.Ref{Type: asserts.SnapRevisionType, PrimaryKey: []string{snapRev.SnapSHA3_384()}}
+
+	s.fetch(c, s.toolingStore(c), ref)
+	c.Check(s.assertions, HasLen, 3)
+
+	// the store is preferred when it can be reached
+	s.assertions = nil
+	s.fetch(c, s.toolingStore(c), ref)
+	c.Check(s.assertions, HasLen, 3)
+
+	// otherwise the snap-revision and the store account-key come from the
+	// cache, the snap-declaration is not content-addressed
+	s.storeErrs = map[*asserts.AssertionType]error{
+		asserts.SnapRevisionType: fmt.Errorf("store unavailable"),
+		asserts.AccountKeyType:   fmt.Errorf("store unavailable"),
+	}
+	s.fetch(c, s.toolingStore(c), ref)
+
+	s.storeErrs[asserts.SnapDeclarationType] = fmt.Errorf("store unavailable")
+	_, err := s.tryFetch(c, s.toolingStore(c), ref)
+	c.Check(err, ErrorMatches, `.*store unavailable`)
+}
+
+func (s *sharedCacheSuite) TestAssertionFetcherPrefersStore(c *C) {
+	snapRev := s.makeSnapRevision(c)
+	ref := &asserts.Ref{Type: asserts.SnapRevisionType, PrimaryKey: []string{snapRev.SnapSHA3_384()}}
+	c.Assert(s.openCache(c).PutAssertion(snapRev), IsNil)
+
+	headers := snapRev.Headers()
+	headers["revision"] = "1"
+	reissued, err := s.storeSigning.Sign(asserts.SnapRevisionType, headers, nil, "")
+	c.Assert(err, IsNil)
+	c.Assert(s.storeSigning.Add(reissued), IsNil)
+
+	a := s.fetch(c, s.toolingStore(c), ref)
+	c.Check(a.Revision(), Equals, 1)
+
+	// the cache entry was updated
+	a, _ = s.openCache(c).GetAssertion(ref)
+	c.Check(a.Revision(), Equals, 1)
+
+	// a revoked assertion is not served from the cache
+	s.storeErrs = map[*asserts.AssertionType]error{
+		asserts.SnapRevisionType: &asserts.NotFoundError{Type: asserts.SnapRevisionType},
+	}
+	_, err = s.tryFetch(c, s.toolingStore(c), ref)
+	c.Check(err, ErrorMatches, `snap-revision .*not found`)
+}
+
+// otherUser makes the entries of the shared cache appear to have been
+// added by another user, neither root nor the current one.
+func (s *sharedCacheSuite) otherUser() (restore func()) {
+	restore1 := tooling.MockSharedCacheOwner(sys.UserID(os.Geteuid()) + 1)
+	restore2 := tooling.MockGeteuid(sys.UserID(os.Geteuid()) + 2)
+	return func() {
+		restore2()
+		restore1()
+	}
+}
+
+func (s *sharedCacheSuite) TestAssertionFetcherOtherUserCheckedEntry(c *C) {
+	snapRev := s.makeSnapRevision(c)
+	ref := &asserts.Ref{Type: asserts.SnapRevisionType, PrimaryKey: []string{snapRev.SnapSHA3_384()}}
+	c.Assert(s.openCache(c).PutAssertion(snapRev), IsNil)
+
+	tsto := s.toolingStore(c)
+	restore := s.otherUser()
+	defer restore()
+	s.storeErrs = map[*asserts.AssertionType]error{
+		asserts.SnapRevisionType: fmt.Errorf("store unavailable"),
+	}
+
+	// the entry can be checked as the signing key is known already
+	s.fetch(c, tsto, ref, s.storeSigning.StoreAccountKey(""))
+
+	// the entry cannot be checked right away
+	_, err := s.tryFetch(c, tsto, ref)
+	c.Check(err, ErrorMatches, `.*store unavailable`)
+}
+
+func (s *sharedCacheSuite) TestAssertionFetcherOtherUserBogusEntry(c *C) {
+	snapRev := s.makeSnapRevision(c)
+	ref := &asserts.Ref{Type: asserts.SnapRevisionType, PrimaryKey: []string{snapRev.SnapSHA3_384()}}
+
+	// a well-formed assertion under the right key but signed by an
+	// unknown key
+	bogusKey, _ := assertstest.GenerateKey(752)
+	bogusSigning := assertstest.NewSigningDB("canonical", bogusKey)
+	bogus, err := bogusSigning.Sign(asserts.SnapRevisionType, snapRev.Headers(), nil, "")
+	c.Assert(err, IsNil)
+	c.Assert(s.openCache(c).PutAssertion(bogus), IsNil)
+
+	tsto := s.toolingStore(c)
+	restore := s.otherUser()
+	defer restore()
+
+	// the bogus entry is not used when the store cannot be reached
+	s.storeErrs = map[*asserts.AssertionType]error{
+		asserts.SnapRevisionType: fmt.Errorf("store unavailable"),
+	}
+	_, err = s.tryFetch(c, tsto, ref, s.storeSigning.StoreAccountKey(""))
+	c.Check(err, ErrorMatches, `.*store unavailable`)
+
+	s.storeErrs = nil
+	s.fetch(c, tsto, ref, s.storeSigning.StoreAccountKey(""))
+	c.Check(s.assertions, testutil.Contains, ref.Unique())
+
+	// the bogus entry is not replaced, as it belongs to another user
+	a, trusted := tooling.SharedCacheFromToolingStore(tsto).GetAssertion(ref)
+	c.Check(trusted, Equals, false)
+	c.Check(a.SignKeyID(), Equals, bogus.SignKeyID())
+}
+
+func (s *sharedCacheSuite) TestAssertionsNotCacheable(c *C) {
+	cache := s.openCache(c)
+
+	acct := s.storeSigning.TrustedAccount
+	c.Assert(cache.PutAssertion(acct), IsNil)
+	a, _ := cache.GetAssertion(acct.Ref())
+	c.Check(a, IsNil)
+}
+
+func (s *sharedCacheSuite) TestAssertionsOptionalPrimaryKey(c *C) {
+	cache := s.openCache(c)
+	snapRev := s.makeSnapRevision(c)
+	c.Assert(cache.PutAssertion(snapRev), IsNil)
+
+	for _, pk := range [][]string{
+		{snapRev.SnapSHA3_384()},
+		{snapRev.SnapSHA3_384(), "global-upload"},
+	} {
+		a, trusted := cache.GetAssertion(&asserts.Ref{Type: asserts.SnapRevisionType, PrimaryKey: pk})
+		c.Assert(a, NotNil)
+		c.Check(trusted, Equals, true)
+		c.Check(a.Ref().Unique(), Equals, snapRev.Ref().Unique())
+	}
+}
@@ -22,7 +22,11 @@ package tooling
 import (
 	"net/url"
 
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/osutil/sys"
+	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/store"
+	"github.com/snapcore/snapd/testutil"
 )
 
 var (
@@ -41,3 +45,56 @@ func (tsto *ToolingStore) StoreURL() *url.URL {
 func (opts *DownloadSnapOptions) Validate() error {
 	return opts.validate()
 }
+
+type SharedCache = sharedCache
+
+var (
+	OpenSharedCache     = openSharedCache
+	MaxSharedCacheBlobs = maxSharedCacheBlobs
+)
+
+func MockSharedCacheOwner(uid sys.UserID) (restore func()) {
+	restore = testutil.Backup(&sharedCacheOwner)
+	sharedCacheOwner = uid
+	return restore
+}
+
+func MockMaxSharedCacheSize(size int64) (restore func()) {
+	restore = testutil.Backup(&maxSharedCacheSize)
+	maxSharedCacheSize = size
+	return restore
+}
+
+func MockGeteuid(uid sys.UserID) (restore func()) {
+	restore = testutil.Backup(&osGeteuid)
+	osGeteuid = func() sys.UserID { return uid }
+	return restore
+}
+
+func (c *sharedCache) GetBlob(sha3_384 string, size int64, targetFn string) bool {
+	return c.getBlob(sha3_384, size, targetFn)
+}
+
+func (c *sharedCache) PutBlob(sha3_384, sourceFn string) error {
+	return c.putBlob(sha3_384, sourceFn)
+}
+
+func (c *sharedCache) GetAssertion(ref *asserts.Ref) (a asserts.Assertion, trusted bool) {
+	return c.getAssertion(ref)
+}
+
+func (c *sharedCache) PutAssertion(a asserts.Assertion) error {
+	return c.putAssertion(a)
+}
+
+func (tsto *ToolingStore) SetSharedCache(c *SharedCache) {
+	tsto.cache = c
+}
+
+func SharedCacheFromToolingStore(tsto *ToolingStore) *SharedCache {
+	return tsto.cache
+}
+
+func (tsto *ToolingStore) DownloadBlob(name, targetFn string, downloadInfo *snap.DownloadInfo) error {
+	return tsto.download(name, targetFn, downloadInfo, DownloadSnapOptions{})
+}
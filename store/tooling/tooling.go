@@ -31,9 +31,11 @@ import (
 	"path/filepath"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/asserts/snapasserts"
+	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/overlord/auth"
@@ -53,6 +55,9 @@ type ToolingStore struct {
 	sto StoreImpl
 	cfg *store.Config
 
+	// cache is the cache shared with the other users, nil if disabled
+	cache *sharedCache
+
 	assertMaxFormats map[string]int
 }
 
@@ -96,8 +101,9 @@ func newToolingStore(arch, storeID string) (*ToolingStore, error) {
 	}
 	sto := store.New(cfg, nil)
 	return &ToolingStore{
-		sto: sto,
-		cfg: cfg,
+		sto:   sto,
+		cfg:   cfg,
+		cache: openSharedCache(dirs.SnapToolingCacheDir),
 	}, nil
 }
 
@@ -306,6 +312,28 @@ func (tsto *ToolingStore) downloadWithProgressBar(download func(progress.Meter)
 	return download(pb)
 }
 
+// download downloads the given snap or component into targetFn, unless it
+// can be taken from the shared cache, to which it is then added.
+func (tsto *ToolingStore) download(name, targetFn string, downloadInfo *snap.DownloadInfo, opts DownloadSnapOptions) error {
+	if tsto.cache.getBlob(downloadInfo.Sha3_384, downloadInfo.Size, targetFn) {
+		return nil
+	}
+
+	download := func(pb progress.Meter) error {
+		dlOpts := &store.DownloadOptions{LeavePartialOnError: opts.LeavePartialOnError}
+		return tsto.sto.Download(context.TODO(), name, targetFn,
+			downloadInfo, pb, nil, dlOpts)
+	}
+	if err := tsto.downloadWithProgressBar(download); err != nil {
+		return err
+	}
+
+	if err := tsto.cache.putBlob(downloadInfo.Sha3_384, targetFn); err != nil {
+		logger.Debugf("cannot add %s to the shared cache: %v", name, err)
+	}
+	return nil
+}
+
 func (tsto *ToolingStore) snapDownload(targetFn string, sar *store.SnapActionResult, opts DownloadSnapOptions) (downloadedSnap *DownloadedSnap, err error) {
 	snap := sar.Info
 	redirectChannel := sar.RedirectChannel
@@ -324,12 +352,7 @@ func (tsto *ToolingStore) snapDownload(targetFn string, sar *store.SnapActionRes
 		logger.Debugf("File exists but has wrong hash, ignoring (here).")
 	}
 
-	download := func(pb progress.Meter) error {
-		dlOpts := &store.DownloadOptions{LeavePartialOnError: opts.LeavePartialOnError}
-		return tsto.sto.Download(context.TODO(), snap.SnapName(), targetFn,
-			&snap.DownloadInfo, pb, nil, dlOpts)
-	}
-	if err := tsto.downloadWithProgressBar(download); err != nil {
+	if err := tsto.download(snap.SnapName(), targetFn, &snap.DownloadInfo, opts); err != nil {
 		return nil, err
 	}
 
@@ -361,7 +384,12 @@ type CurrentSnap struct {
 
 type DownloadManyOptions struct {
 	BeforeDownloadFunc func(*snap.Info, map[string]*snap.ComponentInfo) (targetPath string, compPaths map[string]string, err error)
-	EnforceValidation  bool
+	// SkipDownloadFunc, if set, is called for each snap after
+	// BeforeDownloadFunc. If it returns true neither the snap nor its
+	// components are downloaded, the returned DownloadedSnap then
+	// carries only the information from the store and no paths.
+	SkipDownloadFunc  func(*snap.Info) bool
+	EnforceValidation bool
 }
 
 // DownloadMany downloads the specified snaps.
@@ -450,6 +478,26 @@ func (tsto *ToolingStore) DownloadMany(toDownload []SnapToDownload, curSnaps []*
 		if err != nil {
 			return nil, err
 		}
+		if opts.SkipDownloadFunc != nil && opts.SkipDownloadFunc(sar.Info) {
+			comps := make([]*DownloadedComponent, 0, len(cinfos))
+			for _, comp := range snapToDownload.CompsToDownload {
+				srr := sar.ResourceResult(comp)
+				if srr == nil {
+					return nil, fmt.Errorf("%s component for %s not found in store",
+						comp, sar.SnapName())
+				}
+				comps = append(comps, &DownloadedComponent{
+					Info: cinfos[comp],
+					Size: srr.DownloadInfo.Size,
+				})
+			}
+			downloadedSnaps[sar.SnapName()] = &DownloadedSnap{
+				Info:            sar.Info,
+				RedirectChannel: sar.RedirectChannel,
+				Components:      comps,
+			}
+			continue
+		}
 		dlSnap, err := tsto.snapDownload(targetPath, &sar, DownloadSnapOptions{})
 		if err != nil {
 			return nil, err
@@ -477,43 +525,91 @@ func (tsto *ToolingStore) DownloadMany(toDownload []SnapToDownload, curSnaps []*
 // AssertionFetcher creates an asserts.Fetcher for assertions, the fetcher will
 // add assertions in the given database and after that also call save for each of them.
 func (tsto *ToolingStore) AssertionFetcher(db *asserts.Database, save func(asserts.Assertion) error) asserts.Fetcher {
+	cached := make(map[string]bool)
 	retrieve := func(ref *asserts.Ref) (asserts.Assertion, error) {
-		return tsto.sto.Assertion(ref.Type, ref.PrimaryKey, nil)
+		return tsto.retrieveAssertion(db, ref, cached)
 	}
 	save2 := func(a asserts.Assertion) error {
-		// for checking
-		err := db.Add(a)
+		return tsto.addAssertion(db, a, cached, save)
+	}
+	return asserts.NewFetcher(db, retrieve, save2)
+}
+
+// retrieveAssertion retrieves the given assertion from the store, or from
+// the shared cache if the store cannot be reached. Assertions coming from the
+// shared cache are recorded in cached.
+func (tsto *ToolingStore) retrieveAssertion(db *asserts.Database, ref *asserts.Ref, cached map[string]bool) (asserts.Assertion, error) {
+	a, err := tsto.sto.Assertion(ref.Type, ref.PrimaryKey, nil)
+	var notFound *asserts.NotFoundError
+	if err == nil || errors.As(err, &notFound) {
+		// the store knows best about revoked or re-issued assertions
+		return a, err
+	}
+	if ca, trusted := tsto.cache.getAssertion(ref); ca != nil {
+		// the prerequisites of an assertion are fetched before it is
+		// added, so a bogus entry added by another user must not be used
+		if trusted || signedByKnownKey(db, ca) {
+			logger.Debugf("using shared cache for assertion %v: %v", ref, err)
+			cached[ca.Ref().Unique()] = true
+			return ca, nil
+		}
+	}
+	return nil, err
+}
+
+// signedByKnownKey returns whether the given assertion is properly signed by
+// a key already in db. Unlike db.Check, this does not require the other
+// assertions it must be consistent with to be in db already.
+func signedByKnownKey(db *asserts.Database, a asserts.Assertion) bool {
+	key, err := db.Find(asserts.AccountKeyType, map[string]string{
+		"public-key-sha3-384": a.SignKeyID(),
+	})
+	if err != nil {
+		return false
+	}
+	now := time.Now()
+	return asserts.CheckSignature(a, key.(*asserts.AccountKey), db, now, now) == nil
+}
+
+// addAssertion adds the given assertion to db, and then calls save for it.
+// Assertions coming from the shared cache that cannot be added are fetched
+// again from the store, the ones that were added are put in the cache.
+func (tsto *ToolingStore) addAssertion(db *asserts.Database, a asserts.Assertion, cached map[string]bool, save func(asserts.Assertion) error) error {
+	// for checking
+	err := db.Add(a)
+	if err != nil && cached[a.Ref().Unique()] {
+		logger.Noticef("cannot use assertion %v from shared cache: %v", a.Ref(), err)
+		a, err = tsto.sto.Assertion(a.Type(), a.Ref().PrimaryKey, nil)
 		if err != nil {
-			if _, ok := err.(*asserts.RevisionError); ok {
-				return nil
-			}
-			return fmt.Errorf("cannot add assertion %v: %v", a.Ref(), err)
+			return err
 		}
-		return save(a)
+		err = db.Add(a)
 	}
-	return asserts.NewFetcher(db, retrieve, save2)
+	if err != nil {
+		if _, ok := err.(*asserts.RevisionError); ok {
+			return nil
+		}
+		return fmt.Errorf("cannot add assertion %v: %v", a.Ref(), err)
+	}
+	if err := tsto.cache.putAssertion(a); err != nil {
+		logger.Debugf("cannot add assertion %v to the shared cache: %v", a.Ref(), err)
+	}
+	return save(a)
 }
 
 // AssertionSequenceFormingFetcher creates an asserts.SequenceFormingFetcher for
 // fetching assertions. The fetcher will then store the fetched assertions in the
 // given db and call save for each of them.
 func (tsto *ToolingStore) AssertionSequenceFormingFetcher(db *asserts.Database, save func(asserts.Assertion) error) asserts.SequenceFormingFetcher {
+	cached := make(map[string]bool)
 	retrieve := func(ref *asserts.Ref) (asserts.Assertion, error) {
-		return tsto.sto.Assertion(ref.Type, ref.PrimaryKey, nil)
+		return tsto.retrieveAssertion(db, ref, cached)
 	}
 	retrieveSeq := func(seq *asserts.AtSequence) (asserts.Assertion, error) {
 		return tsto.sto.SeqFormingAssertion(seq.Type, seq.SequenceKey, seq.Sequence, nil)
 	}
 	save2 := func(a asserts.Assertion) error {
-		// for checking
-		err := db.Add(a)
-		if err != nil {
-			if _, ok := err.(*asserts.RevisionError); ok {
-				return nil
-			}
-			return fmt.Errorf("cannot add assertion %v: %v", a.Ref(), err)
-		}
-		return save(a)
+		return tsto.addAssertion(db, a, cached, save)
 	}
 	return asserts.NewSequenceFormingFetcher(db, retrieve, retrieveSeq, save2)
 }
@@ -548,6 +644,8 @@ func MockToolingStore(sto StoreImpl) *ToolingStore {
 type DownloadedComponent struct {
 	Path string
 	Info *snap.ComponentInfo
+	// Size is the size of the component as reported by the store.
+	Size int64
 }
 
 func (tsto *ToolingStore) componentDownload(targetFn string, snapName string, srr *store.SnapResourceResult, opts DownloadSnapOptions) (downloadedComp *DownloadedComponent, err error) {
@@ -566,18 +664,14 @@ func (tsto *ToolingStore) componentDownload(targetFn string, snapName string, sr
 			logger.Debugf("not downloading, using existing file %s", targetFn)
 			return &DownloadedComponent{
 				Path: targetFn,
+				Size: srr.DownloadInfo.Size,
 			}, nil
 		}
 		logger.Debugf("File exists but has wrong hash, ignoring (here).")
 	}
 
 	cref := naming.NewComponentRef(snapName, srr.Name)
-	download := func(pb progress.Meter) error {
-		dlOpts := &store.DownloadOptions{LeavePartialOnError: opts.LeavePartialOnError}
-		return tsto.sto.Download(context.TODO(), cref.String(), targetFn,
-			&srr.DownloadInfo, pb, nil, dlOpts)
-	}
-	if err := tsto.downloadWithProgressBar(download); err != nil {
+	if err := tsto.download(cref.String(), targetFn, &srr.DownloadInfo, opts); err != nil {
 		return nil, err
 	}
 
@@ -585,5 +679,6 @@ func (tsto *ToolingStore) componentDownload(targetFn string, snapName string, sr
 		Path: targetFn,
 		Info: snap.NewComponentInfo(cref, ctyp, srr.Version,
 			"", "", "", snap.NewComponentSideInfo(cref, snap.R(srr.Revision))),
+		Size: srr.DownloadInfo.Size,
 	}, nil
 }
@@ -37,6 +37,7 @@ import (
 
 	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/asserts/assertstest"
+	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/overlord/auth"
@@ -85,6 +86,8 @@ func (s *toolingSuite) SetUpTest(c *C) {
 
 	s.BaseTest.SetUpTest(c)
 	s.BaseTest.AddCleanup(snap.MockSanitizePlugsSlots(func(snapInfo *snap.Info) {}))
+	dirs.SetRootDir(s.root)
+	s.AddCleanup(func() { dirs.SetRootDir("") })
 
 	s.tsto = tooling.MockToolingStore(s)
 	s.storeActionsBunchSizes = nil
@@ -567,6 +570,54 @@ func (s *toolingSuite) TestDownloadManySnapWithComps(c *C) {
 	c.Check(numReq, Equals, 1)
 }
 
+func (s *toolingSuite) TestDownloadManySkipDownload(c *C) {
+	comRevs := map[string]snap.Revision{
+		"comp1": snap.R(22),
+	}
+	s.SeedSnaps.MakeAssertedSnapWithComps(c, seedtest.SampleSnapYaml["required20"], nil,
+		snap.R(21), comRevs, "other", s.StoreSigning.Database)
+	s.setupSnaps(c, map[string]string{"core": "canonical"}, "")
+
+	// env shenanigans
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	snapsToDownld := []tooling.SnapToDownload{
+		{Snap: naming.Snap("core")},
+		{Snap: naming.Snap("required20"), CompsToDownload: []string{"comp1"}},
+	}
+	dlDir := c.MkDir()
+	bdf := func(si *snap.Info, cinfos map[string]*snap.ComponentInfo) (targetPath string, compPaths map[string]string, err error) {
+		compPaths = make(map[string]string, len(cinfos))
+		for name := range cinfos {
+			compPaths[name] = filepath.Join(dlDir, fmt.Sprintf("%s+%s.comp", si.SnapName(), name))
+		}
+		return filepath.Join(dlDir, si.SnapName()), compPaths, nil
+	}
+	topts := tooling.DownloadManyOptions{
+		BeforeDownloadFunc: bdf,
+		SkipDownloadFunc: func(si *snap.Info) bool {
+			return si.SnapName() == "required20"
+		},
+	}
+	dss, err := s.tsto.DownloadMany(snapsToDownld, nil, topts)
+	c.Assert(err, IsNil)
+	c.Check(len(dss), Equals, 2)
+	c.Check(dss["core"].Path, Equals, filepath.Join(dlDir, "core"))
+	c.Check(filepath.Join(dlDir, "core"), testutil.FilePresent)
+
+	// the store information is returned for the skipped snap
+	c.Check(dss["required20"].Path, Equals, "")
+	c.Check(dss["required20"].Info.SnapName(), Equals, "required20")
+	comps := dss["required20"].Components
+	c.Assert(comps, HasLen, 1)
+	c.Check(comps[0].Path, Equals, "")
+	c.Check(comps[0].Info.Component, Equals, naming.NewComponentRef("required20", "comp1"))
+	c.Check(comps[0].Size, Not(Equals), int64(0))
+	c.Check(filepath.Join(dlDir, "required20"), testutil.FileAbsent)
+	c.Check(filepath.Join(dlDir, "required20+comp1.comp"), testutil.FileAbsent)
+}
+
 func (s *toolingSuite) TestSetAssertionMaxFormats(c *C) {
 	c.Check(s.tsto.AssertionMaxFormats(), IsNil)
 
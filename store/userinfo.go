@@ -51,7 +51,7 @@ func (s *Store) UserInfo(email string) (userinfo *User, err error) {
 	var v keysReply
 	ssourl := fmt.Sprintf("%s/keys/%s", authURL(), url.QueryEscape(email))
 
-	resp, err := httputil.RetryRequest(ssourl, func() (*http.Response, error) {
+	resp, err := httputil.RetryRequestWithGate(ssourl, func() (*http.Response, error) {
 		return s.client.Get(ssourl)
 	}, func(resp *http.Response) error {
 		if resp.StatusCode != 200 {
@@ -63,7 +63,7 @@ func (s *Store) UserInfo(email string) (userinfo *User, err error) {
 			return fmt.Errorf("cannot unmarshal: %v", err)
 		}
 		return nil
-	}, defaultRetryStrategy)
+	}, defaultRetryStrategy, s.retryGate)
 
 	if err != nil {
 		return nil, err
@@ -21,7 +21,10 @@ package syscheck
 
 import (
 	"errors"
+	"path/filepath"
 
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/release"
 )
 
@@ -34,5 +37,11 @@ func checkWSL() error {
 		return errors.New("snapd does not work inside WSL1")
 	}
 
+	// WSL2 distributions only run systemd when explicitly enabled, without
+	// it services and mount units of snaps cannot be managed.
+	if release.WSLVersion == 2 && !osutil.IsDirectory(filepath.Join(dirs.GlobalRootDir, "/run/systemd/system")) {
+		return errors.New("snapd requires systemd inside WSL2, enable it by setting systemd=true in the [boot] section of /etc/wsl.conf")
+	}
+
 	return nil
 }
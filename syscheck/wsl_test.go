@@ -20,8 +20,12 @@
 package syscheck_test
 
 import (
+	"os"
+	"path/filepath"
+
 	. "gopkg.in/check.v1"
 
+	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/release"
 	"github.com/snapcore/snapd/syscheck"
 	"github.com/snapcore/snapd/testutil"
@@ -59,6 +63,18 @@ func (s *wslSuite) TestWSL1(c *C) {
 }
 
 func (s *wslSuite) TestWSL2(c *C) {
+	dirs.SetRootDir(c.MkDir())
+	defer dirs.SetRootDir("")
+	c.Assert(os.MkdirAll(filepath.Join(dirs.GlobalRootDir, "/run/systemd/system"), 0755), IsNil)
+
 	defer mockOnWSL(2)()
 	c.Check(syscheck.CheckWSL(), IsNil)
 }
+
+func (s *wslSuite) TestWSL2WithoutSystemd(c *C) {
+	dirs.SetRootDir(c.MkDir())
+	defer dirs.SetRootDir("")
+
+	defer mockOnWSL(2)()
+	c.Check(syscheck.CheckWSL(), ErrorMatches, `snapd requires systemd inside WSL2, enable it by setting systemd=true in the \[boot\] section of /etc/wsl.conf`)
+}
@@ -0,0 +1,129 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package systemd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/snapcore/snapd/osutil"
+)
+
+// coredumpMessageID is the MESSAGE_ID of the journal entries recorded by
+// systemd-coredump for each core dump.
+const coredumpMessageID = "fc2e22bc6ee647b6b90729ab34a250b1"
+
+// Coredump is a core dump collected by systemd-coredump, as recorded in the
+// journal.
+type Coredump struct {
+	// Cursor is the journal cursor of the entry
+	Cursor string
+	// Timestamp is the time of the crash in microseconds since the epoch,
+	// it identifies the core dump together with the pid
+	Timestamp string
+	Pid       int
+	UID       int
+	Signal    int
+	Exe       string
+	// Cgroup is the cgroup of the crashed process
+	Cgroup string
+	// Filename is the file storing the core dump, if stored externally
+	Filename string
+}
+
+// Time returns the time of the crash.
+func (cd *Coredump) Time() time.Time {
+	us, _ := strconv.ParseInt(cd.Timestamp, 10, 64)
+	return time.Unix(us/1000000, 1000*(us%1000000))
+}
+
+func (l Log) field(key string) string {
+	value, err := l.parseLogRawMessageString(key, func([]string) (string, error) {
+		return "", fmt.Errorf("multiple values not supported")
+	})
+	if err != nil {
+		return ""
+	}
+	return value
+}
+
+func (l Log) intField(key string) int {
+	value, _ := strconv.Atoi(l.field(key))
+	return value
+}
+
+// Coredumps returns the core dumps recorded in the journal that are visible
+// to the calling user, after the entry with the given cursor if not empty.
+func Coredumps(afterCursor string) ([]*Coredump, error) {
+	args := []string{"--output=json", "--no-pager", "MESSAGE_ID=" + coredumpMessageID}
+	if afterCursor != "" {
+		args = append(args, "--after-cursor="+afterCursor)
+	}
+	output, stderr, err := osutil.RunSplitOutput("journalctl", args...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list core dumps: %v", osutil.OutputErrCombine(output, stderr, err))
+	}
+
+	var coredumps []*Coredump
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	// the entries include the process environment, status and maps
+	scanner.Buffer(nil, 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		// journalctl may print "-- No entries --"
+		if !bytes.HasPrefix(line, []byte("{")) {
+			continue
+		}
+		var l Log
+		if err := json.Unmarshal(line, &l); err != nil {
+			return nil, fmt.Errorf("cannot decode core dump entry: %v", err)
+		}
+		coredumps = append(coredumps, &Coredump{
+			Cursor:    l.field("__CURSOR"),
+			Timestamp: l.field("COREDUMP_TIMESTAMP"),
+			Pid:       l.intField("COREDUMP_PID"),
+			UID:       l.intField("COREDUMP_UID"),
+			Signal:    l.intField("COREDUMP_SIGNAL"),
+			Exe:       l.field("COREDUMP_EXE"),
+			Cgroup:    l.field("COREDUMP_CGROUP"),
+			Filename:  l.field("COREDUMP_FILENAME"),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read core dumps list: %v", err)
+	}
+	return coredumps, nil
+}
+
+// DumpCoredump writes the given core dump to the output file. The core dump
+// is selected by both its pid and its timestamp, so that another process
+// that reused the pid is never picked.
+func DumpCoredump(cd *Coredump, output string) error {
+	out, stderr, err := osutil.RunSplitOutput("coredumpctl", "--no-pager", "--output="+output, "dump",
+		fmt.Sprintf("COREDUMP_PID=%d", cd.Pid), "COREDUMP_TIMESTAMP="+cd.Timestamp)
+	if err != nil {
+		return fmt.Errorf("cannot retrieve core dump of process %d: %v", cd.Pid, osutil.OutputErrCombine(out, stderr, err))
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package systemd_test
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/systemd"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type coredumpSuite struct {
+	testutil.BaseTest
+}
+
+var _ = Suite(&coredumpSuite{})
+
+func (s *coredumpSuite) TestCoredumps(c *C) {
+	journalctl := testutil.MockCommand(c, "journalctl", `
+cat <<'EOF'
+{"__CURSOR":"s=1","COREDUMP_TIMESTAMP":"1700000000123456","COREDUMP_PID":"1234","COREDUMP_UID":"0","COREDUMP_SIGNAL":"11","COREDUMP_EXE":"/snap/foo/x1/bin/svc","COREDUMP_CGROUP":"/system.slice/snap.foo.svc.service","COREDUMP_FILENAME":"/var/lib/systemd/coredump/core.svc.1234.zst"}
+{"__CURSOR":"s=2","COREDUMP_TIMESTAMP":"1700000001000000","COREDUMP_PID":"42","COREDUMP_UID":"1000","COREDUMP_SIGNAL":"6","COREDUMP_EXE":[47,98,105,110],"COREDUMP_CGROUP":"/user.slice/user-1000.slice/session-1.scope"}
+EOF
+`)
+	defer journalctl.Restore()
+
+	coredumps, err := systemd.Coredumps("")
+	c.Assert(err, IsNil)
+	c.Check(coredumps, DeepEquals, []*systemd.Coredump{{
+		Cursor:    "s=1",
+		Timestamp: "1700000000123456",
+		Pid:       1234,
+		UID:       0,
+		Signal:    11,
+		Exe:       "/snap/foo/x1/bin/svc",
+		Cgroup:    "/system.slice/snap.foo.svc.service",
+		Filename:  "/var/lib/systemd/coredump/core.svc.1234.zst",
+	}, {
+		Cursor:    "s=2",
+		Timestamp: "1700000001000000",
+		Pid:       42,
+		UID:       1000,
+		Signal:    6,
+		Exe:       "/bin",
+		Cgroup:    "/user.slice/user-1000.slice/session-1.scope",
+	}})
+	c.Check(coredumps[0].Time().Equal(time.Unix(1700000000, 123456000)), Equals, true)
+
+	_, err = systemd.Coredumps("s=1")
+	c.Assert(err, IsNil)
+	c.Check(journalctl.Calls(), DeepEquals, [][]string{
+		{"journalctl", "--output=json", "--no-pager", "MESSAGE_ID=fc2e22bc6ee647b6b90729ab34a250b1"},
+		{"journalctl", "--output=json", "--no-pager", "MESSAGE_ID=fc2e22bc6ee647b6b90729ab34a250b1", "--after-cursor=s=1"},
+	})
+}
+
+func (s *coredumpSuite) TestCoredumpsNone(c *C) {
+	journalctl := testutil.MockCommand(c, "journalctl", `echo "-- No entries --"`)
+	defer journalctl.Restore()
+
+	coredumps, err := systemd.Coredumps("")
+	c.Assert(err, IsNil)
+	c.Check(coredumps, HasLen, 0)
+}
+
+func (s *coredumpSuite) TestCoredumpsError(c *C) {
+	journalctl := testutil.MockCommand(c, "journalctl", `echo "boom" >&2; exit 1`)
+	defer journalctl.Restore()
+
+	_, err := systemd.Coredumps("")
+	c.Assert(err, ErrorMatches, `(?s)cannot list core dumps: .*boom.*`)
+}
+
+func (s *coredumpSuite) TestDumpCoredump(c *C) {
+	coredumpctl := testutil.MockCommand(c, "coredumpctl", "")
+	defer coredumpctl.Restore()
+
+	cd := &systemd.Coredump{Pid: 1234, Timestamp: "1700000000123456"}
+	c.Assert(systemd.DumpCoredump(cd, "/tmp/core"), IsNil)
+	c.Check(coredumpctl.Calls(), DeepEquals, [][]string{
+		{"coredumpctl", "--no-pager", "--output=/tmp/core", "dump", "COREDUMP_PID=1234", "COREDUMP_TIMESTAMP=1700000000123456"},
+	})
+}
+
+func (s *coredumpSuite) TestDumpCoredumpError(c *C) {
+	coredumpctl := testutil.MockCommand(c, "coredumpctl", `echo "no match" >&2; exit 1`)
+	defer coredumpctl.Restore()
+
+	cd := &systemd.Coredump{Pid: 1234, Timestamp: "1700000000123456"}
+	err := systemd.DumpCoredump(cd, "/tmp/core")
+	c.Assert(err, ErrorMatches, `(?s)cannot retrieve core dump of process 1234: .*no match.*`)
+}
@@ -0,0 +1,280 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package systemdtest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/systemd"
+)
+
+// UnitState is the state of a unit as modeled by FakeSystemd, together with
+// counters of the transitions it went through.
+type UnitState struct {
+	Enabled bool
+	Active  bool
+	Masked  bool
+
+	Starts   int
+	Stops    int
+	Restarts int
+	Reloads  int
+}
+
+// FakeSystemd implements systemd.Systemd by modeling the state of units
+// instead of calling systemctl, so that tests can check the resulting state
+// of the units rather than the exact systemctl invocations.
+//
+// Units are created the first time they are used. Only starting, stopping,
+// enabling and masking units and querying their status are modeled, calling
+// any other method panics.
+type FakeSystemd struct {
+	// Systemd is nil, the methods that are not modeled panic.
+	systemd.Systemd
+
+	mu            sync.Mutex
+	units         map[string]*UnitState
+	failures      map[string]error
+	daemonReloads int
+}
+
+// NewFakeSystemd returns a FakeSystemd without any units.
+func NewFakeSystemd() *FakeSystemd {
+	osutil.MustBeTestBinary("fake systemd can only be used from tests")
+	return &FakeSystemd{
+		units:    make(map[string]*UnitState),
+		failures: make(map[string]error),
+	}
+}
+
+// MockSystemd makes systemd.New and its variants return the given fake for
+// all instance modes.
+func MockSystemd(fake *FakeSystemd) (restore func()) {
+	return systemd.MockNewSystemd(func(be systemd.Backend, rootDir string, mode systemd.InstanceMode, rep systemd.Reporter) systemd.Systemd {
+		return fake
+	})
+}
+
+// SetUnit sets the state of the given unit.
+func (f *FakeSystemd) SetUnit(unit string, state UnitState) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.units[unit] = &state
+}
+
+// Unit returns the state of the given unit, it is the zero state for units
+// that were never used.
+func (f *FakeSystemd) Unit(unit string) UnitState {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if state, ok := f.units[unit]; ok {
+		return *state
+	}
+	return UnitState{}
+}
+
+// Units returns the names of the units that are known.
+func (f *FakeSystemd) Units() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	units := make([]string, 0, len(f.units))
+	for unit := range f.units {
+		units = append(units, unit)
+	}
+	return units
+}
+
+// DaemonReloads returns the number of times the configuration of systemd was
+// reloaded.
+func (f *FakeSystemd) DaemonReloads() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.daemonReloads
+}
+
+// FailOn makes the given operation fail with err for the given unit. The
+// operations are "start", "stop", "restart", "reload", "enable", "disable",
+// "mask" and "unmask".
+func (f *FakeSystemd) FailOn(op, unit string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures[op+" "+unit] = err
+}
+
+// transition applies the given state change to every unit, stopping at the
+// first failure like systemctl does.
+func (f *FakeSystemd) transition(op string, units []string, change func(state *UnitState) error) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, unit := range units {
+		if err := f.failures[op+" "+unit]; err != nil {
+			return err
+		}
+		state, ok := f.units[unit]
+		if !ok {
+			state = &UnitState{}
+			f.units[unit] = state
+		}
+		if err := change(state); err != nil {
+			return fmt.Errorf("cannot %s unit %s: %v", op, unit, err)
+		}
+	}
+	return nil
+}
+
+func start(state *UnitState) error {
+	if state.Masked {
+		return fmt.Errorf("unit is masked")
+	}
+	if !state.Active {
+		state.Active = true
+		state.Starts++
+	}
+	return nil
+}
+
+func stop(state *UnitState) error {
+	if state.Active {
+		state.Active = false
+		state.Stops++
+	}
+	return nil
+}
+
+func restart(state *UnitState) error {
+	if state.Masked {
+		return fmt.Errorf("unit is masked")
+	}
+	state.Active = true
+	state.Restarts++
+	return nil
+}
+
+func (f *FakeSystemd) Backend() systemd.Backend {
+	return systemd.RunningSystemdBackend
+}
+
+func (f *FakeSystemd) DaemonReload() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.daemonReloads++
+	return nil
+}
+
+func (f *FakeSystemd) EnableNoReload(units []string) error {
+	return f.transition("enable", units, func(state *UnitState) error {
+		if state.Masked {
+			return fmt.Errorf("unit is masked")
+		}
+		state.Enabled = true
+		return nil
+	})
+}
+
+func (f *FakeSystemd) DisableNoReload(units []string) error {
+	return f.transition("disable", units, func(state *UnitState) error {
+		state.Enabled = false
+		return nil
+	})
+}
+
+func (f *FakeSystemd) Start(units []string) error {
+	return f.transition("start", units, start)
+}
+
+func (f *FakeSystemd) StartNoBlock(units []string) error {
+	return f.transition("start", units, start)
+}
+
+func (f *FakeSystemd) Stop(units []string) error {
+	return f.transition("stop", units, stop)
+}
+
+func (f *FakeSystemd) Restart(units []string) error {
+	return f.transition("restart", units, restart)
+}
+
+func (f *FakeSystemd) RestartNoWaitForStop(units []string) error {
+	return f.transition("restart", units, restart)
+}
+
+func (f *FakeSystemd) ReloadOrRestart(units []string) error {
+	return f.transition("reload", units, func(state *UnitState) error {
+		if !state.Active {
+			// like systemctl reload-or-restart, start inactive units
+			return start(state)
+		}
+		state.Reloads++
+		return nil
+	})
+}
+
+func (f *FakeSystemd) Mask(unit string) error {
+	return f.transition("mask", []string{unit}, func(state *UnitState) error {
+		state.Masked = true
+		return nil
+	})
+}
+
+func (f *FakeSystemd) Unmask(unit string) error {
+	return f.transition("unmask", []string{unit}, func(state *UnitState) error {
+		state.Masked = false
+		return nil
+	})
+}
+
+func (f *FakeSystemd) IsEnabled(unit string) (bool, error) {
+	return f.Unit(unit).Enabled, nil
+}
+
+func (f *FakeSystemd) IsActive(unit string) (bool, error) {
+	return f.Unit(unit).Active, nil
+}
+
+func (f *FakeSystemd) InactiveEnterTimestamp(unit string) (time.Time, error) {
+	return time.Time{}, nil
+}
+
+func (f *FakeSystemd) Status(units []string) ([]*systemd.UnitStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sts := make([]*systemd.UnitStatus, len(units))
+	for i, unit := range units {
+		st := &systemd.UnitStatus{
+			Id:    unit,
+			Name:  unit,
+			Names: []string{unit},
+		}
+		if strings.HasSuffix(unit, ".service") {
+			st.Daemon = "simple"
+		}
+		if state, ok := f.units[unit]; ok {
+			st.Installed = true
+			st.Enabled = state.Enabled
+			st.Active = state.Active
+		}
+		sts[i] = st
+	}
+	return sts, nil
+}
@@ -0,0 +1,103 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2024 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package systemdtest_test
+
+import (
+	"errors"
+	"sort"
+	"testing"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/systemd"
+	"github.com/snapcore/snapd/systemd/systemdtest"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type fakeSystemdSuite struct{}
+
+var _ = Suite(&fakeSystemdSuite{})
+
+func (s *fakeSystemdSuite) TestTransitions(c *C) {
+	sysd := systemdtest.NewFakeSystemd()
+	restore := systemdtest.MockSystemd(sysd)
+	defer restore()
+	c.Check(systemd.New(systemd.SystemMode, nil), Equals, sysd)
+
+	c.Assert(sysd.EnableNoReload([]string{"foo.service", "bar.service"}), IsNil)
+	c.Assert(sysd.DaemonReload(), IsNil)
+	c.Assert(sysd.Start([]string{"foo.service"}), IsNil)
+	// starting an active unit does nothing
+	c.Assert(sysd.Start([]string{"foo.service"}), IsNil)
+	c.Assert(sysd.ReloadOrRestart([]string{"foo.service", "bar.service"}), IsNil)
+	c.Assert(sysd.Restart([]string{"bar.service"}), IsNil)
+	c.Assert(sysd.Stop([]string{"foo.service", "baz.service"}), IsNil)
+
+	c.Check(sysd.Unit("foo.service"), Equals, systemdtest.UnitState{Enabled: true, Starts: 1, Stops: 1, Reloads: 1})
+	c.Check(sysd.Unit("bar.service"), Equals, systemdtest.UnitState{Enabled: true, Active: true, Starts: 1, Restarts: 1})
+	c.Check(sysd.Unit("baz.service"), Equals, systemdtest.UnitState{})
+	c.Check(sysd.DaemonReloads(), Equals, 1)
+	units := sysd.Units()
+	sort.Strings(units)
+	c.Check(units, DeepEquals, []string{"bar.service", "baz.service", "foo.service"})
+
+	enabled, err := sysd.IsEnabled("foo.service")
+	c.Assert(err, IsNil)
+	c.Check(enabled, Equals, true)
+	active, err := sysd.IsActive("foo.service")
+	c.Assert(err, IsNil)
+	c.Check(active, Equals, false)
+
+	sts, err := sysd.Status([]string{"bar.service", "other.timer"})
+	c.Assert(err, IsNil)
+	c.Check(sts, DeepEquals, []*systemd.UnitStatus{
+		{Daemon: "simple", Id: "bar.service", Name: "bar.service", Names: []string{"bar.service"}, Enabled: true, Active: true, Installed: true},
+		{Id: "other.timer", Name: "other.timer", Names: []string{"other.timer"}},
+	})
+}
+
+func (s *fakeSystemdSuite) TestMasked(c *C) {
+	sysd := systemdtest.NewFakeSystemd()
+	sysd.SetUnit("foo.service", systemdtest.UnitState{Enabled: true})
+
+	c.Assert(sysd.Mask("foo.service"), IsNil)
+	c.Check(sysd.Start([]string{"foo.service"}), ErrorMatches, "cannot start unit foo.service: unit is masked")
+	c.Check(sysd.EnableNoReload([]string{"foo.service"}), ErrorMatches, "cannot enable unit foo.service: unit is masked")
+
+	c.Assert(sysd.Unmask("foo.service"), IsNil)
+	c.Assert(sysd.Start([]string{"foo.service"}), IsNil)
+	c.Check(sysd.Unit("foo.service"), Equals, systemdtest.UnitState{Enabled: true, Active: true, Starts: 1})
+}
+
+func (s *fakeSystemdSuite) TestFailOn(c *C) {
+	sysd := systemdtest.NewFakeSystemd()
+	sysd.FailOn("start", "bar.service", errors.New("boom"))
+
+	err := sysd.Start([]string{"foo.service", "bar.service", "baz.service"})
+	c.Check(err, ErrorMatches, "boom")
+	// units are handled in order until the failure
+	c.Check(sysd.Unit("foo.service").Active, Equals, true)
+	c.Check(sysd.Unit("bar.service").Active, Equals, false)
+	c.Check(sysd.Unit("baz.service").Active, Equals, false)
+
+	// other operations are not affected
+	c.Check(sysd.Stop([]string{"bar.service"}), IsNil)
+}
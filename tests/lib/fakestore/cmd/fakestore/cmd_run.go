@@ -24,7 +24,7 @@ import (
 	"os/signal"
 	"syscall"
 
-	"github.com/snapcore/snapd/tests/lib/fakestore/store"
+	"github.com/snapcore/snapd/store/storetest/fakestore"
 )
 
 type cmdRun struct {
@@ -56,7 +56,7 @@ func init() {
 }
 
 func runServer(topDir, addr string, assertFallback bool) error {
-	st := store.NewStore(topDir, addr, assertFallback)
+	st := fakestore.NewStore(topDir, addr, assertFallback)
 
 	if err := st.Start(); err != nil {
 		return err
@@ -21,16 +21,19 @@ package agent
 
 import (
 	"syscall"
+	"time"
 
 	"github.com/snapcore/snapd/i18n"
 )
 
 var (
 	SessionInfoCmd                     = sessionInfoCmd
+	SessionIdleCmd                     = sessionIdleCmd
 	ServiceControlCmd                  = serviceControlCmd
 	ServiceStatusCmd                   = serviceStatusCmd
 	PendingRefreshNotificationCmd      = pendingRefreshNotificationCmd
 	FinishRefreshNotificationCmd       = finishRefreshNotificationCmd
+	ConnectionPromptNotificationCmd    = connectionPromptNotificationCmd
 	GuessAppData                       = guessAppData
 	GetLocalizedAppNameFromDesktopFile = getLocalizedAppNameFromDesktopFile
 )
@@ -62,3 +65,11 @@ func MockCurrentLocale(newLocale string) (restore func()) {
 		currentLocale = i18n.CurrentLocale
 	}
 }
+
+func MockLogindUserIdle(f func() (bool, time.Time, error)) (restore func()) {
+	old := logindUserIdle
+	logindUserIdle = f
+	return func() {
+		logindUserIdle = old
+	}
+}
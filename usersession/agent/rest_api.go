@@ -31,6 +31,7 @@ import (
 
 	"github.com/mvo5/goconfigparser"
 
+	"github.com/snapcore/snapd/dbusutil"
 	"github.com/snapcore/snapd/desktop/notification"
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/i18n"
@@ -44,10 +45,12 @@ import (
 var restApi = []*Command{
 	rootCmd,
 	sessionInfoCmd,
+	sessionIdleCmd,
 	serviceControlCmd,
 	serviceStatusCmd,
 	pendingRefreshNotificationCmd,
 	finishRefreshNotificationCmd,
+	connectionPromptNotificationCmd,
 }
 
 var (
@@ -61,6 +64,11 @@ var (
 		GET:  sessionInfo,
 	}
 
+	sessionIdleCmd = &Command{
+		Path: "/v1/session-idle",
+		GET:  sessionIdle,
+	}
+
 	serviceControlCmd = &Command{
 		Path: "/v1/service-control",
 		POST: postServiceControl,
@@ -80,6 +88,11 @@ var (
 		Path: "/v1/notifications/finish-refresh",
 		POST: postRefreshFinishedNotification,
 	}
+
+	connectionPromptNotificationCmd = &Command{
+		Path: "/v1/notifications/connection-prompt",
+		POST: postConnectionPromptNotification,
+	}
 )
 
 func sessionInfo(c *Command, r *http.Request) Response {
@@ -89,6 +102,55 @@ func sessionInfo(c *Command, r *http.Request) Response {
 	return SyncResponse(m)
 }
 
+// logindUserIdle returns whether logind considers the sessions of the
+// user running the agent idle and, if so, since when.
+var logindUserIdle = func() (idle bool, since time.Time, err error) {
+	conn, err := dbusutil.SystemBus()
+	if err != nil {
+		return false, time.Time{}, fmt.Errorf("cannot connect to system bus: %v", err)
+	}
+	// https://www.freedesktop.org/software/systemd/man/latest/org.freedesktop.login1.html#User%20Objects
+	userObj := conn.Object("org.freedesktop.login1", "/org/freedesktop/login1/user/self")
+	idleV, err := userObj.GetProperty("org.freedesktop.login1.User.IdleHint")
+	if err != nil {
+		return false, time.Time{}, err
+	}
+	idle, ok := idleV.Value().(bool)
+	if !ok {
+		return false, time.Time{}, fmt.Errorf("logind returned invalid value for IdleHint: %s", idleV)
+	}
+	if !idle {
+		return false, time.Time{}, nil
+	}
+	sinceV, err := userObj.GetProperty("org.freedesktop.login1.User.IdleSinceHint")
+	if err != nil {
+		return false, time.Time{}, err
+	}
+	// microseconds since the epoch
+	usec, ok := sinceV.Value().(uint64)
+	if !ok {
+		return false, time.Time{}, fmt.Errorf("logind returned invalid value for IdleSinceHint: %s", sinceV)
+	}
+	if usec != 0 {
+		since = time.UnixMicro(int64(usec))
+	}
+	return true, since, nil
+}
+
+func sessionIdle(c *Command, r *http.Request) Response {
+	idle, since, err := logindUserIdle()
+	if err != nil {
+		return InternalError("cannot determine idle state: %v", err)
+	}
+	m := map[string]interface{}{
+		"idle": idle,
+	}
+	if !since.IsZero() {
+		m["idle-since"] = since
+	}
+	return SyncResponse(m)
+}
+
 func serviceStart(inst *client.ServiceInstruction, sysd systemd.Systemd) Response {
 	// Refuse to start non-snap services
 	for _, service := range inst.Services {
@@ -572,3 +634,62 @@ func postRefreshFinishedNotification(c *Command, r *http.Request) Response {
 	}
 	return SyncResponse(nil)
 }
+
+func postConnectionPromptNotification(c *Command, r *http.Request) Response {
+	if ok, resp := validateJSONRequest(r); !ok {
+		return resp
+	}
+
+	decoder := json.NewDecoder(r.Body)
+
+	var promptInfo client.ConnectionPromptInfo
+	if err := decoder.Decode(&promptInfo); err != nil {
+		return BadRequest("cannot decode request body into connection prompt info: %v", err)
+	}
+
+	var icon string
+	name, instanceKey := snap.SplitInstanceName(promptInfo.InstanceName)
+	if si, err := snap.ReadCurrentInfo(promptInfo.InstanceName); err == nil {
+		icon, name = guessAppData(si, name, instanceKey)
+	} else {
+		logger.Noticef("cannot load snap-info for %s: %v", combineNameAndKey(name, instanceKey), err)
+	}
+	if name == "" {
+		name = combineNameAndKey(name, instanceKey)
+	}
+
+	// Note that since the connection is shared, we are not closing it.
+	if c.s.bus == nil {
+		return SyncResponse(&resp{
+			Type:   ResponseTypeError,
+			Status: 500,
+			Result: &errorResult{
+				Message: "cannot connect to the session bus",
+			},
+		})
+	}
+
+	summary := fmt.Sprintf(i18n.G("%s requests access"), name)
+	body := fmt.Sprintf(i18n.G("Allow or deny its access to the %q interface."), promptInfo.Interface)
+	hints := []notification.Hint{
+		notification.WithDesktopEntry("io.snapcraft.SessionAgent"),
+		notification.WithUrgency(notification.NormalUrgency),
+	}
+
+	msg := &notification.Message{
+		Title: summary,
+		Body:  body,
+		Hints: hints,
+		Icon:  icon,
+	}
+	if err := c.s.notificationMgr.SendNotification(notification.ID("connection-prompt-"+promptInfo.ID), msg); err != nil {
+		return SyncResponse(&resp{
+			Type:   ResponseTypeError,
+			Status: 500,
+			Result: &errorResult{
+				Message: fmt.Sprintf("cannot send notification message: %v", err),
+			},
+		})
+	}
+	return SyncResponse(nil)
+}
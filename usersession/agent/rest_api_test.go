@@ -116,6 +116,69 @@ func (s *restSuite) TestSessionInfo(c *C) {
 	})
 }
 
+func (s *restSuite) TestSessionIdle(c *C) {
+	// the agent.SessionIdle end point only supports GET requests
+	c.Check(agent.SessionIdleCmd.PUT, IsNil)
+	c.Check(agent.SessionIdleCmd.POST, IsNil)
+	c.Check(agent.SessionIdleCmd.DELETE, IsNil)
+	c.Assert(agent.SessionIdleCmd.GET, NotNil)
+
+	c.Check(agent.SessionIdleCmd.Path, Equals, "/v1/session-idle")
+
+	since := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	restore := agent.MockLogindUserIdle(func() (bool, time.Time, error) {
+		return true, since, nil
+	})
+	defer restore()
+
+	rec := httptest.NewRecorder()
+	agent.SessionIdleCmd.GET(agent.SessionIdleCmd, nil).ServeHTTP(rec, nil)
+	c.Check(rec.Code, Equals, 200)
+
+	var rsp resp
+	c.Assert(json.Unmarshal(rec.Body.Bytes(), &rsp), IsNil)
+	c.Check(rsp.Type, Equals, agent.ResponseTypeSync)
+	c.Check(rsp.Result, DeepEquals, map[string]interface{}{
+		"idle":       true,
+		"idle-since": "2024-03-01T10:00:00Z",
+	})
+}
+
+func (s *restSuite) TestSessionIdleNotIdle(c *C) {
+	restore := agent.MockLogindUserIdle(func() (bool, time.Time, error) {
+		return false, time.Time{}, nil
+	})
+	defer restore()
+
+	rec := httptest.NewRecorder()
+	agent.SessionIdleCmd.GET(agent.SessionIdleCmd, nil).ServeHTTP(rec, nil)
+	c.Check(rec.Code, Equals, 200)
+
+	var rsp resp
+	c.Assert(json.Unmarshal(rec.Body.Bytes(), &rsp), IsNil)
+	c.Check(rsp.Result, DeepEquals, map[string]interface{}{
+		"idle": false,
+	})
+}
+
+func (s *restSuite) TestSessionIdleError(c *C) {
+	restore := agent.MockLogindUserIdle(func() (bool, time.Time, error) {
+		return false, time.Time{}, fmt.Errorf("boom")
+	})
+	defer restore()
+
+	rec := httptest.NewRecorder()
+	agent.SessionIdleCmd.GET(agent.SessionIdleCmd, nil).ServeHTTP(rec, nil)
+	c.Check(rec.Code, Equals, 500)
+
+	var rsp resp
+	c.Assert(json.Unmarshal(rec.Body.Bytes(), &rsp), IsNil)
+	c.Check(rsp.Type, Equals, agent.ResponseTypeError)
+	c.Check(rsp.Result, DeepEquals, map[string]interface{}{
+		"message": "cannot determine idle state: boom",
+	})
+}
+
 func (s *restSuite) TestServiceControl(c *C) {
 	// the agent.Services end point only supports POST requests
 	c.Assert(agent.ServiceControlCmd.GET, IsNil)
@@ -1064,6 +1127,47 @@ func (s *restSuite) TestPostCloseRefreshNotification(c *C) {
 	})
 }
 
+func (s *restSuite) TestPostConnectionPromptNotification(c *C) {
+	promptInfo := &client.ConnectionPromptInfo{ID: "42", InstanceName: "some-snap", Interface: "camera"}
+	reqBody, err := json.Marshal(promptInfo)
+	c.Assert(err, IsNil)
+	req := httptest.NewRequest("POST", "/v1/notifications/connection-prompt", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	agent.ConnectionPromptNotificationCmd.POST(agent.ConnectionPromptNotificationCmd, req).ServeHTTP(rec, req)
+	c.Check(rec.Code, Equals, 200)
+
+	var rsp resp
+	c.Assert(json.Unmarshal(rec.Body.Bytes(), &rsp), IsNil)
+	c.Check(rsp.Type, Equals, agent.ResponseTypeSync)
+	c.Check(rsp.Result, IsNil)
+
+	notifications := s.notify.GetAll()
+	c.Assert(notifications, HasLen, 1)
+	n := notifications[0]
+	c.Check(n.Summary, Equals, `some-snap requests access`)
+	c.Check(n.Body, Equals, `Allow or deny its access to the "camera" interface.`)
+	c.Check(n.Hints, DeepEquals, map[string]dbus.Variant{
+		"urgency":       dbus.MakeVariant(byte(notification.NormalUrgency)),
+		"desktop-entry": dbus.MakeVariant("io.snapcraft.SessionAgent"),
+	})
+}
+
+func (s *restSuite) TestPostConnectionPromptNotificationBadBody(c *C) {
+	req := httptest.NewRequest("POST", "/v1/notifications/connection-prompt", bytes.NewBufferString("garbage"))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	agent.ConnectionPromptNotificationCmd.POST(agent.ConnectionPromptNotificationCmd, req).ServeHTTP(rec, req)
+	c.Check(rec.Code, Equals, 400)
+
+	var rsp resp
+	c.Assert(json.Unmarshal(rec.Body.Bytes(), &rsp), IsNil)
+	c.Check(rsp.Type, Equals, agent.ResponseTypeError)
+	c.Check(rsp.Result, DeepEquals, map[string]interface{}{
+		"message": "cannot decode request body into connection prompt info: invalid character 'g' looking for beginning of value",
+	})
+}
+
 func createDesktopFile(c *C, desktopFilePath string, icon string, name string, localizedNames map[string]string) {
 	data := []byte("[Desktop Entry]\nName=" + name + "\n")
 	if icon != "" {
@@ -236,6 +236,39 @@ func (client *Client) SessionInfo(ctx context.Context) (info map[int]SessionInfo
 	return info, err
 }
 
+// SessionIdleInfo holds the idle state of a user as reported by logind.
+type SessionIdleInfo struct {
+	Idle      bool      `json:"idle"`
+	IdleSince time.Time `json:"idle-since,omitempty"`
+}
+
+// SessionIdle returns the idle state reported by each session agent.
+func (client *Client) SessionIdle(ctx context.Context) (info map[int]SessionIdleInfo, err error) {
+	responses, err := client.doMany(ctx, "GET", "/v1/session-idle", nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	info = make(map[int]SessionIdleInfo)
+	for _, resp := range responses {
+		if resp.err != nil {
+			if err == nil {
+				err = resp.err
+			}
+			continue
+		}
+		var si SessionIdleInfo
+		if decodeErr := json.Unmarshal(resp.Result, &si); decodeErr != nil {
+			if err == nil {
+				err = decodeErr
+			}
+			continue
+		}
+		info[resp.uid] = si
+	}
+	return info, err
+}
+
 type ServiceFailure struct {
 	Uid     int
 	Service string
@@ -526,3 +559,22 @@ func (client *Client) FinishRefreshNotification(ctx context.Context, closeInfo *
 	_, err = client.doMany(ctx, "POST", "/v1/notifications/finish-refresh", nil, headers, reqBody)
 	return err
 }
+
+// ConnectionPromptInfo holds information about a connection prompt provided
+// to userd.
+type ConnectionPromptInfo struct {
+	ID           string `json:"id"`
+	InstanceName string `json:"instance-name"`
+	Interface    string `json:"interface"`
+}
+
+// ConnectionPromptNotification notifies about a pending connection prompt.
+func (client *Client) ConnectionPromptNotification(ctx context.Context, promptInfo *ConnectionPromptInfo) error {
+	headers := map[string]string{"Content-Type": "application/json"}
+	reqBody, err := json.Marshal(promptInfo)
+	if err != nil {
+		return err
+	}
+	_, err = client.doMany(ctx, "POST", "/v1/notifications/connection-prompt", nil, headers, reqBody)
+	return err
+}
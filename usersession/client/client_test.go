@@ -193,6 +193,44 @@ func (s *clientSuite) TestSessionInfoWrongResultType(c *C) {
 	c.Check(err, ErrorMatches, `json: cannot unmarshal array into Go value of type client.SessionInfo`)
 }
 
+func (s *clientSuite) TestSessionIdle(c *C) {
+	s.handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, Equals, "/v1/session-idle")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(200)
+		w.Write([]byte(`{
+  "type": "sync",
+  "result": {
+    "idle": true,
+    "idle-since": "2024-03-01T10:00:00Z"
+  }
+}`))
+	})
+	si, err := s.cli.SessionIdle(context.Background())
+	c.Assert(err, IsNil)
+	since := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	c.Check(si, DeepEquals, map[int]client.SessionIdleInfo{
+		42:   {Idle: true, IdleSince: since},
+		1000: {Idle: true, IdleSince: since},
+	})
+}
+
+func (s *clientSuite) TestSessionIdleError(c *C) {
+	s.handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(500)
+		w.Write([]byte(`{
+  "type": "error",
+  "result": {
+    "message": "cannot determine idle state: boom"
+  }
+}`))
+	})
+	si, err := s.cli.SessionIdle(context.Background())
+	c.Check(si, DeepEquals, map[int]client.SessionIdleInfo{})
+	c.Check(err, ErrorMatches, "cannot determine idle state: boom")
+}
+
 func (s *clientSuite) TestServicesDaemonReload(c *C) {
 	s.handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -730,6 +768,22 @@ func (s *clientSuite) TestFinishRefreshNotification(c *C) {
 	c.Check(atomic.LoadInt32(&n), Equals, int32(2))
 }
 
+func (s *clientSuite) TestConnectionPromptNotification(c *C) {
+	cli := client.NewForUids(1000)
+	var n int32
+	s.handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&n, 1)
+		c.Assert(r.URL.Path, Equals, "/v1/notifications/connection-prompt")
+		body, err := io.ReadAll(r.Body)
+		c.Check(err, IsNil)
+		c.Check(string(body), DeepEquals, `{"id":"42","instance-name":"some-snap","interface":"camera"}`)
+	})
+	err := cli.ConnectionPromptNotification(context.Background(), &client.ConnectionPromptInfo{ID: "42", InstanceName: "some-snap", Interface: "camera"})
+	c.Assert(err, IsNil)
+	// only the session of the owner of the prompt is notified
+	c.Check(atomic.LoadInt32(&n), Equals, int32(1))
+}
+
 func (s *clientSuite) TestPendingRefreshNotificationOneClient(c *C) {
 	cli := client.NewForUids(1000)
 	var n int32
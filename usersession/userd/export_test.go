@@ -31,6 +31,14 @@ func MockSnapFromSender(f func(*dbus.Conn, dbus.Sender) (string, error)) func()
 	}
 }
 
+func MockSnapConnectedInterfaces(f func(snap string) ([]string, error)) func() {
+	old := snapConnectedInterfaces
+	snapConnectedInterfaces = f
+	return func() {
+		snapConnectedInterfaces = old
+	}
+}
+
 var (
 	DesktopFileSearchPath     = desktopFileSearchPath
 	DesktopFileIDToFilename   = desktopFileIDToFilename
@@ -24,10 +24,32 @@ import (
 
 	"github.com/godbus/dbus"
 
+	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/sandbox/cgroup"
+	"github.com/snapcore/snapd/strutil"
 )
 
-var snapFromSender = snapFromSenderImpl
+var (
+	snapFromSender          = snapFromSenderImpl
+	snapConnectedInterfaces = snapConnectedInterfacesImpl
+)
+
+// snapConnectedInterfacesImpl returns the interfaces of the connected plugs
+// of the given snap, as known to snapd.
+func snapConnectedInterfacesImpl(snap string) ([]string, error) {
+	cli := client.New(nil)
+	conns, err := cli.Connections(&client.ConnectionOptions{Snap: snap})
+	if err != nil {
+		return nil, err
+	}
+	var ifaces []string
+	for _, conn := range conns.Established {
+		if conn.Plug.Snap == snap && !strutil.ListContains(ifaces, conn.Interface) {
+			ifaces = append(ifaces, conn.Interface)
+		}
+	}
+	return ifaces, nil
+}
 
 func snapFromSenderImpl(conn *dbus.Conn, sender dbus.Sender) (string, error) {
 	pid, err := connectionPid(conn, sender)
@@ -26,6 +26,7 @@ import (
 	"os"
 	"os/exec"
 	"regexp"
+	"sync"
 	"syscall"
 	"time"
 
@@ -120,11 +121,31 @@ var (
 		//   - https://github.com/snapcore/snapd/pull/8910
 		"zoomus",
 	}
+
+	// interfaceURLSchemes are the url schemes that a snap may additionally
+	// pass to xdg-open when it has a connected plug of the given interface.
+	// The same criteria as for allowedURLSchemes apply, the recipients of
+	// these urls are only useful to snaps that were granted the matching
+	// access to the system.
+	interfaceURLSchemes = map[string][]string{
+		// tel/sms: the schemes allow specifying a phone number to dial or
+		//   to send a message to, handled by the dialer or messaging
+		//   application of the system
+		//   - scheme: tel:+123456789, sms:+123456789
+		"modem-manager": {"tel", "sms"},
+		"ofono":         {"tel", "sms"},
+	}
 )
 
 // Launcher implements the 'io.snapcraft.Launcher' DBus interface.
 type Launcher struct {
 	conn *dbus.Conn
+
+	mu sync.Mutex
+	// schemePrompts remembers the answers of the user to opening urls
+	// with schemes that are not otherwise allowed, keyed by snap and
+	// scheme, for the lifetime of the session
+	schemePrompts map[string]*schemePrompt
 }
 
 // Interface returns the name of the interface this object implements
@@ -171,9 +192,137 @@ func schemeHasHandler(scheme string) (bool, error) {
 	return validDesktopFileName.Match(out), nil
 }
 
+// auditURL records the decision taken on a request of a snap to open an url.
+// Only the scheme and the host of the url are recorded, as the rest of it may
+// carry private data.
+func auditURL(snap string, u *url.URL, allowed bool, reason string) {
+	decision := "denied"
+	if allowed {
+		decision = "allowed"
+	}
+	logger.Noticef("xdg-open: %s opening %s url (host %q) for snap %q: %s", decision, u.Scheme, u.Host, snap, reason)
+}
+
+// snapAllowsScheme returns whether one of the connected plugs of the snap
+// grants it opening urls with the given scheme.
+func snapAllowsScheme(snap, scheme string) (bool, error) {
+	ifaces, err := snapConnectedInterfaces(snap)
+	if err != nil {
+		return false, err
+	}
+	for _, iface := range ifaces {
+		if strutil.ListContains(interfaceURLSchemes[iface], scheme) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// schemePrompt is a question to the user about a scheme that is, or was,
+// being asked. Requests for the same snap and scheme that come in meanwhile
+// wait for its answer instead of asking again.
+type schemePrompt struct {
+	done    chan struct{}
+	allowed bool
+	err     error
+}
+
+// promptForScheme asks the user whether the snap may open urls with the given
+// scheme, remembering the answer for the rest of the session. The lock is not
+// held while the dialog is shown, as this can take minutes.
+func (s *Launcher) promptForScheme(snap, scheme string) (bool, error) {
+	key := snap + ":" + scheme
+	s.mu.Lock()
+	if s.schemePrompts == nil {
+		s.schemePrompts = make(map[string]*schemePrompt)
+	}
+	prompt, ok := s.schemePrompts[key]
+	if ok {
+		s.mu.Unlock()
+		<-prompt.done
+		return prompt.allowed, prompt.err
+	}
+	prompt = &schemePrompt{done: make(chan struct{})}
+	s.schemePrompts[key] = prompt
+	s.mu.Unlock()
+
+	prompt.allowed, prompt.err = askForScheme(snap, scheme)
+	if prompt.err != nil {
+		// try again next time
+		s.mu.Lock()
+		delete(s.schemePrompts, key)
+		s.mu.Unlock()
+	}
+	close(prompt.done)
+	return prompt.allowed, prompt.err
+}
+
+func askForScheme(snap, scheme string) (bool, error) {
+	dialog, err := ui.New()
+	if err != nil {
+		return false, err
+	}
+	allowed := dialog.YesNo(
+		i18n.G("Allow opening link?"),
+		fmt.Sprintf(i18n.G("Allow snap %q to open %q links?"), snap, scheme),
+		&ui.DialogOptions{
+			Timeout: 5 * 60 * time.Second,
+			Footer:  i18n.G("This dialog will close automatically after 5 minutes of inactivity."),
+		},
+	)
+	return allowed, nil
+}
+
+// checkURLScheme returns whether the snap may open the given url, together
+// with the reason for the decision. Schemes from allowedURLSchemes are always
+// allowed, then schemes granted by the connected interfaces of the snap.
+// Other schemes need a handler on the system, and the user is asked about
+// them when possible. As before the user could be asked, schemes with a
+// handler are allowed when the snap or the dialog is not available.
+func (s *Launcher) checkURLScheme(snap string, u *url.URL) (allowed bool, reason string) {
+	if strutil.ListContains(allowedURLSchemes, u.Scheme) {
+		return true, "allowed scheme"
+	}
+
+	if snap != "" {
+		allowed, err := snapAllowsScheme(snap, u.Scheme)
+		if err != nil {
+			logger.Noticef("cannot obtain connections of snap %q: %v", snap, err)
+		}
+		if allowed {
+			return true, "allowed by interface connection"
+		}
+	}
+
+	// scheme is not listed in our allowed schemes list, perform fallback
+	// and check whether the local system has a handler for it
+	hasHandler, err := schemeHasHandler(u.Scheme)
+	if err != nil {
+		logger.Noticef("cannot obtain scheme handler for %q: %v", u.Scheme, err)
+	}
+	if !hasHandler {
+		return false, "no handler for scheme"
+	}
+	if snap == "" {
+		return true, "system has a handler for scheme, unknown sender"
+	}
+	allowed, err = s.promptForScheme(snap, u.Scheme)
+	if err != nil {
+		logger.Noticef("cannot ask about opening %q urls: %v", u.Scheme, err)
+		return true, "system has a handler for scheme, cannot prompt user"
+	}
+	if !allowed {
+		return false, "denied by user"
+	}
+	return true, "allowed by user"
+}
+
 // OpenURL implements the 'OpenURL' method of the 'io.snapcraft.Launcher'
 // DBus interface. Before the provided url is passed to xdg-open the scheme is
-// validated against a list of allowed schemes. All other schemes are denied.
+// validated against a list of allowed schemes and the schemes granted by the
+// interface connections of the calling snap. Other schemes that the system has
+// a handler for are allowed unless the user declines opening them, all
+// remaining schemes are denied.
 func (s *Launcher) OpenURL(addr string, sender dbus.Sender) *dbus.Error {
 	logger.Debugf("open url: %q", addr)
 	if err := checkOnClassic(); err != nil {
@@ -188,16 +337,13 @@ func (s *Launcher) OpenURL(addr string, sender dbus.Sender) *dbus.Error {
 		return makeAccessDeniedError(fmt.Errorf("cannot open URL without a scheme"))
 	}
 
-	isAllowed := strutil.ListContains(allowedURLSchemes, u.Scheme)
-	if !isAllowed {
-		// scheme is not listed in our allowed schemes list, perform
-		// fallback and check whether the local system has a handler for
-		// it
-		isAllowed, err = schemeHasHandler(u.Scheme)
-		if err != nil {
-			logger.Noticef("cannot obtain scheme handler for %q: %v", u.Scheme, err)
-		}
+	snap, err := snapFromSender(s.conn, sender)
+	if err != nil {
+		logger.Debugf("cannot determine snap of %s: %v", sender, err)
+		snap = ""
 	}
+	isAllowed, reason := s.checkURLScheme(snap, u)
+	auditURL(snap, u, isAllowed, reason)
 	if !isAllowed {
 		return makeAccessDeniedError(fmt.Errorf("Supplied URL scheme %q is not allowed", u.Scheme))
 	}
@@ -25,14 +25,17 @@ import (
 	"path/filepath"
 	"syscall"
 	"testing"
+	"time"
 
 	"github.com/godbus/dbus"
 	. "gopkg.in/check.v1"
 
+	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/osutil/sys"
 	"github.com/snapcore/snapd/release"
 	"github.com/snapcore/snapd/testutil"
 	"github.com/snapcore/snapd/usersession/userd"
+	"github.com/snapcore/snapd/usersession/userd/ui"
 )
 
 func Test(t *testing.T) { TestingT(t) }
@@ -59,6 +62,10 @@ func (s *launcherSuite) SetUpTest(c *C) {
 	s.AddCleanup(userd.MockSnapFromSender(func(*dbus.Conn, dbus.Sender) (string, error) {
 		return "some-snap", nil
 	}))
+	s.AddCleanup(userd.MockSnapConnectedInterfaces(func(snap string) ([]string, error) {
+		c.Check(snap, Equals, "some-snap")
+		return []string{"network", "home"}, nil
+	}))
 }
 
 func (s *launcherSuite) TestOpenURLWithNotAllowedScheme(c *C) {
@@ -96,7 +103,44 @@ func (s *launcherSuite) TestOpenURLWithAllowedSchemeHappy(c *C) {
 	}
 }
 
+func (s *launcherSuite) TestOpenURLWithInterfaceScheme(c *C) {
+	restore := userd.MockSnapConnectedInterfaces(func(snap string) ([]string, error) {
+		c.Check(snap, Equals, "some-snap")
+		return []string{"network", "modem-manager"}, nil
+	})
+	defer restore()
+	logbuf, restore := logger.MockLogger()
+	defer restore()
+
+	for _, addr := range []string{"tel:+123456789", "sms:+123456789"} {
+		err := s.launcher.OpenURL(addr, ":some-dbus-sender")
+		c.Assert(err, IsNil)
+		c.Assert(s.mockXdgOpen.Calls(), DeepEquals, [][]string{
+			{"xdg-open", addr},
+		})
+		s.mockXdgOpen.ForgetCalls()
+	}
+	// the system handlers were not needed
+	c.Assert(s.mockXdgMime.Calls(), IsNil)
+	c.Check(logbuf.String(), testutil.Contains, `xdg-open: allowed opening tel url (host "") for snap "some-snap": allowed by interface connection`)
+}
+
+func (s *launcherSuite) TestOpenURLWithInterfaceSchemeConnectionsError(c *C) {
+	restore := userd.MockSnapConnectedInterfaces(func(snap string) ([]string, error) {
+		return nil, fmt.Errorf("boom")
+	})
+	defer restore()
+
+	err := s.launcher.OpenURL("tel:+123456789", ":some-dbus-sender")
+	c.Assert(err, ErrorMatches, `Supplied URL scheme "tel" is not allowed`)
+	c.Assert(s.mockXdgOpen.Calls(), IsNil)
+}
+
 func (s *launcherSuite) testOpenURLWithFallbackHappy(c *C, desktopFileName string) {
+	restore := mockUICommands(c, "true")
+	defer restore()
+	// forget what the user answered previously
+	s.launcher = &userd.Launcher{}
 	mockXdgMime := testutil.MockCommand(c, "xdg-mime", fmt.Sprintf(`echo "%s"`, desktopFileName))
 	defer mockXdgMime.Restore()
 	defer s.mockXdgOpen.ForgetCalls()
@@ -130,6 +174,129 @@ func (s *launcherSuite) TestOpenURLWithFallback(c *C) {
 	s.testOpenURLWithFallbackInvalidDesktopFile(c, "foo bar baz.desktop")
 }
 
+func (s *launcherSuite) TestOpenURLWithFallbackUserDeclines(c *C) {
+	mockXdgMime := testutil.MockCommand(c, "xdg-mime", `echo "handler.desktop"`)
+	defer mockXdgMime.Restore()
+	mockUI := testutil.MockCommand(c, "zenity", "false")
+	defer mockUI.Restore()
+
+	err := s.launcher.OpenURL("fallback-scheme://snapcraft.io", ":some-dbus-sender")
+	c.Assert(err, ErrorMatches, `Supplied URL scheme "fallback-scheme" is not allowed`)
+	c.Assert(s.mockXdgOpen.Calls(), IsNil)
+	c.Assert(mockUI.Calls(), HasLen, 1)
+
+	// the answer is remembered for the snap and the scheme
+	err = s.launcher.OpenURL("fallback-scheme://snapcraft.io/other", ":some-dbus-sender")
+	c.Assert(err, ErrorMatches, `Supplied URL scheme "fallback-scheme" is not allowed`)
+	c.Assert(mockUI.Calls(), HasLen, 1)
+}
+
+func (s *launcherSuite) TestOpenURLWithFallbackUserAcceptsRemembered(c *C) {
+	mockXdgMime := testutil.MockCommand(c, "xdg-mime", `echo "handler.desktop"`)
+	defer mockXdgMime.Restore()
+	mockUI := testutil.MockCommand(c, "zenity", "true")
+	defer mockUI.Restore()
+
+	for i := 0; i < 2; i++ {
+		err := s.launcher.OpenURL("fallback-scheme://snapcraft.io", ":some-dbus-sender")
+		c.Assert(err, IsNil)
+	}
+	c.Assert(s.mockXdgOpen.Calls(), HasLen, 2)
+	c.Assert(mockUI.Calls(), HasLen, 1)
+
+	// but not for other snaps
+	restore := userd.MockSnapFromSender(func(*dbus.Conn, dbus.Sender) (string, error) {
+		return "other-snap", nil
+	})
+	defer restore()
+	restore = userd.MockSnapConnectedInterfaces(func(snap string) ([]string, error) {
+		return nil, nil
+	})
+	defer restore()
+	err := s.launcher.OpenURL("fallback-scheme://snapcraft.io", ":some-dbus-sender")
+	c.Assert(err, IsNil)
+	c.Assert(mockUI.Calls(), HasLen, 2)
+}
+
+func (s *launcherSuite) TestOpenURLUnknownSender(c *C) {
+	restore := userd.MockSnapFromSender(func(*dbus.Conn, dbus.Sender) (string, error) {
+		return "", fmt.Errorf("not a snap")
+	})
+	defer restore()
+	mockXdgMime := testutil.MockCommand(c, "xdg-mime", `echo "handler.desktop"`)
+	defer mockXdgMime.Restore()
+
+	// allowed schemes can still be opened
+	err := s.launcher.OpenURL("https://snapcraft.io", ":some-dbus-sender")
+	c.Assert(err, IsNil)
+	c.Assert(s.mockXdgOpen.Calls(), HasLen, 1)
+
+	// the user is not asked about other ones, which are allowed when
+	// the system has a handler for them
+	mockUI := testutil.MockCommand(c, "zenity", "false")
+	defer mockUI.Restore()
+	err = s.launcher.OpenURL("fallback-scheme://snapcraft.io", ":some-dbus-sender")
+	c.Assert(err, IsNil)
+	c.Assert(s.mockXdgOpen.Calls(), HasLen, 2)
+	c.Assert(mockXdgMime.Calls(), HasLen, 1)
+	c.Assert(mockUI.Calls(), IsNil)
+}
+
+func (s *launcherSuite) TestOpenURLWithFallbackNoUI(c *C) {
+	mockXdgMime := testutil.MockCommand(c, "xdg-mime", `echo "handler.desktop"`)
+	defer mockXdgMime.Restore()
+	restore := ui.MockHasZenityExecutable(func() bool { return false })
+	defer restore()
+	restore = ui.MockHasKDialogExecutable(func() bool { return false })
+	defer restore()
+
+	err := s.launcher.OpenURL("fallback-scheme://snapcraft.io", ":some-dbus-sender")
+	c.Assert(err, IsNil)
+	c.Assert(s.mockXdgOpen.Calls(), DeepEquals, [][]string{
+		{"xdg-open", "fallback-scheme://snapcraft.io"},
+	})
+}
+
+func (s *launcherSuite) TestOpenURLPromptDoesNotBlockOthers(c *C) {
+	mockXdgMime := testutil.MockCommand(c, "xdg-mime", `echo "handler.desktop"`)
+	defer mockXdgMime.Restore()
+	flag := filepath.Join(c.MkDir(), "flag")
+	// the question about slow-scheme is only answered once the one about
+	// fast-scheme was asked
+	mockUI := testutil.MockCommand(c, "zenity", fmt.Sprintf(`
+case "$*" in
+*slow-scheme*)
+	while [ ! -e %[1]s ]; do sleep 0.01; done
+	;;
+*)
+	touch %[1]s
+	;;
+esac
+`, flag))
+	defer mockUI.Restore()
+
+	slowDone := make(chan *dbus.Error)
+	go func() {
+		slowDone <- s.launcher.OpenURL("slow-scheme://snapcraft.io", ":some-dbus-sender")
+	}()
+	// wait for the first question to be asked
+	for i := 0; i < 500 && len(mockUI.Calls()) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	c.Assert(mockUI.Calls(), HasLen, 1)
+
+	err := s.launcher.OpenURL("fast-scheme://snapcraft.io", ":some-dbus-sender")
+	c.Assert(err, IsNil)
+
+	select {
+	case err := <-slowDone:
+		c.Assert(err, IsNil)
+	case <-time.After(5 * time.Second):
+		c.Fatal("question about slow-scheme was not answered")
+	}
+	c.Assert(mockUI.Calls(), HasLen, 2)
+}
+
 func (s *launcherSuite) TestOpenURLWithFailingXdgOpen(c *C) {
 	cmd := testutil.MockCommand(c, "xdg-open", "false")
 	defer cmd.Restore()
@@ -59,7 +59,7 @@ func (ud *Userd) Init() error {
 	}
 
 	ud.dbusIfaces = []dbusInterface{
-		&Launcher{ud.conn},
+		&Launcher{conn: ud.conn},
 		&PrivilegedDesktopLauncher{ud.conn},
 		&Settings{ud.conn},
 	}
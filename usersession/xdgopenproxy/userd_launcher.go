@@ -29,6 +29,8 @@ const (
 	userdLauncherBusName    = "io.snapcraft.Launcher"
 	userdLauncherObjectPath = "/io/snapcraft/Launcher"
 	userdLauncherIface      = "io.snapcraft.Launcher"
+
+	userdAccessDeniedError = "org.freedesktop.DBus.Error.AccessDenied"
 )
 
 // userdLauncher is a launcher that forwards the requests to `snap userd` DBus API
@@ -47,5 +49,11 @@ func (s *userdLauncher) OpenFile(bus *dbus.Conn, filename string) error {
 
 func (s *userdLauncher) OpenURI(bus *dbus.Conn, path string) error {
 	launcher := bus.Object(userdLauncherBusName, userdLauncherObjectPath)
-	return launcher.Call("io.snapcraft.Launcher.OpenURL", 0, path).Store()
+	err := launcher.Call("io.snapcraft.Launcher.OpenURL", 0, path).Store()
+	// the URL was denied by the policy of userd or by the user, there is
+	// no point in trying elsewhere
+	if dbusErr, ok := err.(dbus.Error); ok && dbusErr.Name == userdAccessDeniedError {
+		return &responseError{msg: dbusErr.Error()}
+	}
+	return err
 }
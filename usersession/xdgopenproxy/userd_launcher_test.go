@@ -153,6 +153,21 @@ func (s *userdSuite) TestOpenURIError(c *C) {
 	})
 }
 
+func (s *userdSuite) TestOpenURIAccessDenied(c *C) {
+	s.openError = &dbus.Error{
+		Name: "org.freedesktop.DBus.Error.AccessDenied",
+		Body: []interface{}{`Supplied URL scheme "foo" is not allowed`},
+	}
+
+	launcher := &xdgopenproxy.UserdLauncher{}
+	err := launcher.OpenURI(s.SessionBus, "foo:bar")
+	c.Check(err, FitsTypeOf, xdgopenproxy.MakeResponseError(""))
+	c.Check(err, ErrorMatches, `Supplied URL scheme "foo" is not allowed`)
+	c.Check(s.calls, DeepEquals, []string{
+		"OpenURI foo:bar",
+	})
+}
+
 type fakeUserd struct {
 	*userdSuite
 }
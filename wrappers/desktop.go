@@ -22,6 +22,7 @@ package wrappers
 import (
 	"bufio"
 	"bytes"
+	"crypto/rand"
 	"fmt"
 	"os"
 	"os/exec"
@@ -104,11 +105,52 @@ var isValidDesktopFileLine = regexp.MustCompile(strings.Join([]string{
 	"^TargetEnvironment=",
 }, "|")).Match
 
+var desktopLaunchKey = desktopLaunchKeyImpl
+
+// desktopLaunchKeyImpl returns the key authenticating the launches from the
+// desktop files generated by snapd, generating it on first use.
+func desktopLaunchKeyImpl() ([]byte, error) {
+	key, err := os.ReadFile(dirs.SnapDesktopLaunchKey)
+	if err == nil {
+		// keys generated by earlier versions were readable by everyone
+		if err := os.Chmod(dirs.SnapDesktopLaunchKey, 0600); err != nil {
+			return nil, err
+		}
+		return key, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+	key = make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(dirs.SnapDesktopLaunchKey), 0755); err != nil {
+		return nil, err
+	}
+	// only snapd can read the key to forge or verify launch tokens
+	if err := osutil.AtomicWriteFile(dirs.SnapDesktopLaunchKey, key, 0600, 0); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// execEnv returns the environment prefix of the "Exec=" line launching the
+// given snap app from the desktop file.
+func execEnv(s *snap.Info, desktopFile string, app *snap.AppInfo) string {
+	env := fmt.Sprintf("env BAMF_DESKTOP_FILE_HINT=%s ", desktopFile)
+	key, err := desktopLaunchKey()
+	if err != nil {
+		logger.Noticef("cannot obtain desktop launch key, launches from %q will not be verified: %v", desktopFile, err)
+		return env
+	}
+	token := desktopentry.LaunchToken(key, desktopFile, snap.JoinSnapApp(s.InstanceName(), app.Name))
+	return env + fmt.Sprintf("%s=%s ", desktopentry.LaunchTokenEnv, token)
+}
+
 // detectAppAndRewriteExecLine parses snap app name from passed "Exec=" line and rewrites it
 // to use the wrapper path for snap application.
 func detectAppAndRewriteExecLine(s *snap.Info, desktopFile, line string) (appName string, execLine string, err error) {
-	env := fmt.Sprintf("env BAMF_DESKTOP_FILE_HINT=%s ", desktopFile)
-
 	cmd := strings.SplitN(line, "=", 2)[1]
 	for _, app := range s.Apps {
 		wrapper := app.WrapperPath()
@@ -123,9 +165,9 @@ func detectAppAndRewriteExecLine(s *snap.Info, desktopFile, line string) (appNam
 		// this is ok because desktop files are not run through sh
 		// so we don't have to worry about the arguments too much
 		if cmd == validCmd {
-			return app.Name, "Exec=" + env + wrapper, nil
+			return app.Name, "Exec=" + execEnv(s, desktopFile, app) + wrapper, nil
 		} else if strings.HasPrefix(cmd, validCmd+" ") {
-			return app.Name, fmt.Sprintf("Exec=%s%s%s", env, wrapper, line[len("Exec=")+len(validCmd):]), nil
+			return app.Name, fmt.Sprintf("Exec=%s%s%s", execEnv(s, desktopFile, app), wrapper, line[len("Exec=")+len(validCmd):]), nil
 		}
 	}
 
@@ -137,7 +179,7 @@ func detectAppAndRewriteExecLine(s *snap.Info, desktopFile, line string) (appNam
 	desktopFileApp := strings.TrimSuffix(df, filepath.Ext(df))
 	app, ok := s.Apps[desktopFileApp]
 	if ok {
-		newExec := fmt.Sprintf("Exec=%s%s", env, app.WrapperPath())
+		newExec := fmt.Sprintf("Exec=%s%s", execEnv(s, desktopFile, app), app.WrapperPath())
 		logger.Noticef("rewriting desktop file %q to %q", desktopFile, newExec)
 		return app.Name, newExec, nil
 	}
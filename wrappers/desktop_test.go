@@ -27,6 +27,7 @@ import (
 
 	. "gopkg.in/check.v1"
 
+	"github.com/snapcore/snapd/desktop/desktopentry"
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/snap"
@@ -104,6 +105,61 @@ func (s *desktopSuite) TestEnsurePackageDesktopFiles(c *C) {
 	c.Assert(osutil.FileExists(oldDesktopFilePath), Equals, false)
 }
 
+func (s *desktopSuite) TestEnsurePackageDesktopFilesLaunchToken(c *C) {
+	c.Assert(dirs.SnapDesktopLaunchKey, testutil.FileAbsent)
+
+	info := snaptest.MockSnap(c, desktopAppYaml, &snap.SideInfo{Revision: snap.R(11)})
+	baseDir := info.MountDir()
+	c.Assert(os.MkdirAll(filepath.Join(baseDir, "meta", "gui"), 0755), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(baseDir, "meta", "gui", "foobar.desktop"), []byte(`
+[Desktop Entry]
+Name=foo
+Exec=foo.foobar %U`), 0644), IsNil)
+
+	err := wrappers.EnsureSnapDesktopFiles([]*snap.Info{info})
+	c.Assert(err, IsNil)
+
+	// the launch key was generated
+	key, err := os.ReadFile(dirs.SnapDesktopLaunchKey)
+	c.Assert(err, IsNil)
+	c.Check(key, HasLen, 32)
+	stat, err := os.Stat(dirs.SnapDesktopLaunchKey)
+	c.Assert(err, IsNil)
+	c.Check(stat.Mode().Perm(), Equals, os.FileMode(0600))
+
+	desktopFile := filepath.Join(dirs.SnapDesktopFilesDir, "foo_foobar.desktop")
+	token := desktopentry.LaunchToken(key, desktopFile, "foo.foobar")
+	c.Check(desktopFile, testutil.FileContains, fmt.Sprintf("Exec=env BAMF_DESKTOP_FILE_HINT=%s SNAP_DESKTOP_LAUNCH_TOKEN=%s %s/foo.foobar %%U\n", desktopFile, token, dirs.SnapBinariesDir))
+
+	// and is reused afterwards
+	err = wrappers.EnsureSnapDesktopFiles([]*snap.Info{info})
+	c.Assert(err, IsNil)
+	c.Check(dirs.SnapDesktopLaunchKey, testutil.FileEquals, key)
+	c.Check(desktopFile, testutil.FileContains, "SNAP_DESKTOP_LAUNCH_TOKEN="+token)
+}
+
+func (s *desktopSuite) TestEnsurePackageDesktopFilesLaunchKeyPermissions(c *C) {
+	// keys generated by earlier versions were readable by everyone
+	c.Assert(os.MkdirAll(filepath.Dir(dirs.SnapDesktopLaunchKey), 0755), IsNil)
+	c.Assert(os.WriteFile(dirs.SnapDesktopLaunchKey, []byte("some-key"), 0644), IsNil)
+
+	info := snaptest.MockSnap(c, desktopAppYaml, &snap.SideInfo{Revision: snap.R(11)})
+	baseDir := info.MountDir()
+	c.Assert(os.MkdirAll(filepath.Join(baseDir, "meta", "gui"), 0755), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(baseDir, "meta", "gui", "foobar.desktop"), []byte(`
+[Desktop Entry]
+Name=foo
+Exec=foo.foobar %U`), 0644), IsNil)
+
+	err := wrappers.EnsureSnapDesktopFiles([]*snap.Info{info})
+	c.Assert(err, IsNil)
+
+	c.Check(dirs.SnapDesktopLaunchKey, testutil.FileEquals, "some-key")
+	stat, err := os.Stat(dirs.SnapDesktopLaunchKey)
+	c.Assert(err, IsNil)
+	c.Check(stat.Mode().Perm(), Equals, os.FileMode(0600))
+}
+
 func (s *desktopSuite) TestEnsurePackageDesktopFilesMangledDuplicate(c *C) {
 	expectedDesktopFilePath := filepath.Join(dirs.SnapDesktopFilesDir, "foo_foobar._.desktop")
 	c.Assert(osutil.FileExists(expectedDesktopFilePath), Equals, false)
@@ -455,6 +511,11 @@ var _ = Suite(&sanitizeDesktopFileSuite{})
 func (s *sanitizeDesktopFileSuite) SetUpTest(c *C) {
 	s.BaseTest.SetUpTest(c)
 	s.BaseTest.AddCleanup(snap.MockSanitizePlugsSlots(func(snapInfo *snap.Info) {}))
+	s.BaseTest.AddCleanup(wrappers.MockDesktopLaunchKey([]byte("some-key")))
+}
+
+func launchToken(desktopFile, snapApp string) string {
+	return desktopentry.LaunchToken([]byte("some-key"), desktopFile, snapApp)
 }
 
 func (s *sanitizeDesktopFileSuite) TearDownTest(c *C) {
@@ -544,8 +605,8 @@ Exec=snap.app.evil.evil
 X-SnapInstanceName=snap
 Name=foo
 X-SnapAppName=app
-Exec=env BAMF_DESKTOP_FILE_HINT=app.desktop %s/bin/snap.app
-`, dirs.SnapMountDir))
+Exec=env BAMF_DESKTOP_FILE_HINT=app.desktop SNAP_DESKTOP_LAUNCH_TOKEN=%s %s/bin/snap.app
+`, launchToken("app.desktop", "snap.app"), dirs.SnapMountDir))
 }
 
 func (s *sanitizeDesktopFileSuite) TestSanitizeFiltersExecOk(c *C) {
@@ -567,8 +628,8 @@ Exec=snap.app %U
 X-SnapInstanceName=snap
 Name=foo
 X-SnapAppName=app
-Exec=env BAMF_DESKTOP_FILE_HINT=foo.desktop %s/bin/snap.app %%U
-`, dirs.SnapMountDir))
+Exec=env BAMF_DESKTOP_FILE_HINT=foo.desktop SNAP_DESKTOP_LAUNCH_TOKEN=%s %s/bin/snap.app %%U
+`, launchToken("foo.desktop", "snap.app"), dirs.SnapMountDir))
 }
 
 // we do not support TryExec (even if its a valid line), this test ensures
@@ -667,8 +728,8 @@ Exec=snap.app
 X-SnapInstanceName=snap_bar
 Name=foo
 X-SnapAppName=app
-Exec=env BAMF_DESKTOP_FILE_HINT=snap+bar_app.desktop %s/bin/snap_bar.app
-`, dirs.SnapMountDir))
+Exec=env BAMF_DESKTOP_FILE_HINT=snap+bar_app.desktop SNAP_DESKTOP_LAUNCH_TOKEN=%s %s/bin/snap_bar.app
+`, launchToken("snap+bar_app.desktop", "snap_bar.app"), dirs.SnapMountDir))
 }
 
 func (s *sanitizeDesktopFileSuite) TestSanitizeParallelInstancesWithArgs(c *C) {
@@ -692,8 +753,8 @@ Exec=snap.app %U
 X-SnapInstanceName=snap_bar
 Name=foo
 X-SnapAppName=app
-Exec=env BAMF_DESKTOP_FILE_HINT=snap+bar_app.desktop %s/bin/snap_bar.app %%U
-`, dirs.SnapMountDir))
+Exec=env BAMF_DESKTOP_FILE_HINT=snap+bar_app.desktop SNAP_DESKTOP_LAUNCH_TOKEN=%s %s/bin/snap_bar.app %%U
+`, launchToken("snap+bar_app.desktop", "snap_bar.app"), dirs.SnapMountDir))
 }
 
 func (s *sanitizeDesktopFileSuite) TestDetectAppAndRewriteExecLineInvalid(c *C) {
@@ -715,7 +776,7 @@ apps:
 	appName, newl, err := wrappers.DetectAppAndRewriteExecLine(snap, "foo.desktop", "Exec=snap.app")
 	c.Assert(err, IsNil)
 	c.Assert(appName, Equals, "app")
-	c.Assert(newl, Equals, fmt.Sprintf("Exec=env BAMF_DESKTOP_FILE_HINT=foo.desktop %s/bin/snap.app", dirs.SnapMountDir))
+	c.Assert(newl, Equals, fmt.Sprintf("Exec=env BAMF_DESKTOP_FILE_HINT=foo.desktop SNAP_DESKTOP_LAUNCH_TOKEN=%s %s/bin/snap.app", launchToken("foo.desktop", "snap.app"), dirs.SnapMountDir))
 }
 
 func (s *sanitizeDesktopFileSuite) TestLangLang(c *C) {
@@ -805,8 +866,8 @@ X-SnapInstanceName=snap_bar
 Name=foo
 Icon=snap.snap_bar.icon
 X-SnapAppName=app
-Exec=env BAMF_DESKTOP_FILE_HINT=snap+bar_app.desktop %s/bin/snap_bar.app
-`, dirs.SnapMountDir))
+Exec=env BAMF_DESKTOP_FILE_HINT=snap+bar_app.desktop SNAP_DESKTOP_LAUNCH_TOKEN=%s %s/bin/snap_bar.app
+`, launchToken("snap+bar_app.desktop", "snap_bar.app"), dirs.SnapMountDir))
 }
 
 func (s *desktopSuite) TestAddRemoveDesktopFiles(c *C) {
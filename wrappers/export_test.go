@@ -45,6 +45,16 @@ var (
 	FindIconFiles = findIconFiles
 )
 
+func MockDesktopLaunchKey(key []byte) (restore func()) {
+	old := desktopLaunchKey
+	desktopLaunchKey = func() ([]byte, error) {
+		return key, nil
+	}
+	return func() {
+		desktopLaunchKey = old
+	}
+}
+
 func MockKillWait(wait time.Duration) (restore func()) {
 	oldKillWait := killWait
 	killWait = wait
@@ -23,6 +23,7 @@ import (
 	"bytes"
 	"fmt"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"text/template"
 	"time"
@@ -175,6 +176,21 @@ KillSignal={{.KillSignal}}
 {{- if .OOMAdjustScore }}
 OOMScoreAdjust={{.OOMAdjustScore}}
 {{- end}}
+{{- if .CPUAffinity}}
+CPUAffinity={{.CPUAffinity}}
+{{- end}}
+{{- if .App.SchedulingPolicy}}
+CPUSchedulingPolicy={{.App.SchedulingPolicy}}
+{{- end}}
+{{- if .App.IOPriorityClass}}
+IOSchedulingClass={{.App.IOPriorityClass}}
+{{- end}}
+{{- if .App.IOPriorityLevel}}
+IOSchedulingPriority={{.App.IOPriorityLevel}}
+{{- end}}
+{{- if .App.Network}}
+PrivateNetwork=yes
+{{- end}}
 {{- if .InterfaceServiceSnippets}}
 {{.InterfaceServiceSnippets}}
 {{- end}}
@@ -251,6 +267,7 @@ WantedBy={{.ServicesTarget}}
 		KillMode                 string
 		KillSignal               string
 		OOMAdjustScore           int
+		CPUAffinity              string
 		BusName                  string
 		Before                   []string
 		After                    []string
@@ -274,6 +291,7 @@ WantedBy={{.ServicesTarget}}
 		KillMode:       killMode,
 		KillSignal:     appInfo.StopMode.KillSignal(),
 		OOMAdjustScore: oomAdjustScore,
+		CPUAffinity:    cpuAffinity(appInfo.CPUAffinity),
 		BusName:        busName,
 
 		Before: generateServiceNames(appInfo.Snap, appInfo.Before),
@@ -324,3 +342,13 @@ WantedBy={{.ServicesTarget}}
 
 	return templateOut.Bytes(), nil
 }
+
+// cpuAffinity returns the list of CPUs in the format of the CPUAffinity=
+// systemd unit property.
+func cpuAffinity(cpus []int) string {
+	strs := make([]string, len(cpus))
+	for i, cpu := range cpus {
+		strs[i] = strconv.Itoa(cpu)
+	}
+	return strings.Join(strs, " ")
+}
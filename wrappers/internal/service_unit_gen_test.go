@@ -698,6 +698,144 @@ WantedBy=multi-user.target
 `, mountUnitPrefix, mountUnitPrefix))
 }
 
+func (s *serviceUnitGenSuite) TestCPUAffinityAndSchedulingPolicy(c *C) {
+	service := &snap.AppInfo{
+		Snap: &snap.Info{
+			SuggestedName: "snap",
+			Version:       "0.3.4",
+			SideInfo:      snap.SideInfo{Revision: snap.R(44)},
+		},
+		Name:             "app",
+		Command:          "bin/foo start",
+		Daemon:           "simple",
+		DaemonScope:      snap.SystemDaemon,
+		CPUAffinity:      []int{0, 3},
+		SchedulingPolicy: "batch",
+	}
+
+	generatedWrapper, err := internal.GenerateSnapServiceUnitFile(service, nil)
+	c.Assert(err, IsNil)
+
+	c.Check(string(generatedWrapper), Equals, fmt.Sprintf(`[Unit]
+# Auto-generated, DO NOT EDIT
+Description=Service for snap application snap.app
+Requires=%s-snap-44.mount
+Wants=network.target
+After=%s-snap-44.mount network.target snapd.apparmor.service
+X-Snappy=yes
+
+[Service]
+EnvironmentFile=-/etc/environment
+ExecStart=/usr/bin/snap run snap.app
+SyslogIdentifier=snap.app
+Restart=on-failure
+WorkingDirectory=/var/snap/snap/44
+TimeoutStopSec=30
+Type=simple
+CPUAffinity=0 3
+CPUSchedulingPolicy=batch
+
+[Install]
+WantedBy=multi-user.target
+`, mountUnitPrefix, mountUnitPrefix))
+}
+
+func (s *serviceUnitGenSuite) TestIOPriority(c *C) {
+	level := 6
+	service := &snap.AppInfo{
+		Snap: &snap.Info{
+			SuggestedName: "snap",
+			Version:       "0.3.4",
+			SideInfo:      snap.SideInfo{Revision: snap.R(44)},
+		},
+		Name:            "app",
+		Command:         "bin/foo start",
+		Daemon:          "simple",
+		DaemonScope:     snap.SystemDaemon,
+		IOPriorityClass: "best-effort",
+		IOPriorityLevel: &level,
+	}
+
+	generatedWrapper, err := internal.GenerateSnapServiceUnitFile(service, nil)
+	c.Assert(err, IsNil)
+
+	c.Check(string(generatedWrapper), Equals, fmt.Sprintf(`[Unit]
+# Auto-generated, DO NOT EDIT
+Description=Service for snap application snap.app
+Requires=%s-snap-44.mount
+Wants=network.target
+After=%s-snap-44.mount network.target snapd.apparmor.service
+X-Snappy=yes
+
+[Service]
+EnvironmentFile=-/etc/environment
+ExecStart=/usr/bin/snap run snap.app
+SyslogIdentifier=snap.app
+Restart=on-failure
+WorkingDirectory=/var/snap/snap/44
+TimeoutStopSec=30
+Type=simple
+IOSchedulingClass=best-effort
+IOSchedulingPriority=6
+
+[Install]
+WantedBy=multi-user.target
+`, mountUnitPrefix, mountUnitPrefix))
+
+	// the highest priority is rendered as well
+	level = 0
+	service.IOPriorityClass = ""
+	generatedWrapper, err = internal.GenerateSnapServiceUnitFile(service, nil)
+	c.Assert(err, IsNil)
+	c.Check(string(generatedWrapper), testutil.Contains, "\nIOSchedulingPriority=0\n")
+	c.Check(string(generatedWrapper), Not(testutil.Contains), "IOSchedulingClass=")
+}
+
+func (s *serviceUnitGenSuite) TestNetworkIsolation(c *C) {
+	service := &snap.AppInfo{
+		Snap: &snap.Info{
+			SuggestedName: "snap",
+			Version:       "0.3.4",
+			SideInfo:      snap.SideInfo{Revision: snap.R(44)},
+		},
+		Name:        "app",
+		Command:     "bin/foo start",
+		Daemon:      "simple",
+		DaemonScope: snap.SystemDaemon,
+		Network:     snap.NetworkNone,
+	}
+
+	generatedWrapper, err := internal.GenerateSnapServiceUnitFile(service, nil)
+	c.Assert(err, IsNil)
+
+	c.Check(string(generatedWrapper), Equals, fmt.Sprintf(`[Unit]
+# Auto-generated, DO NOT EDIT
+Description=Service for snap application snap.app
+Requires=%s-snap-44.mount
+Wants=network.target
+After=%s-snap-44.mount network.target snapd.apparmor.service
+X-Snappy=yes
+
+[Service]
+EnvironmentFile=-/etc/environment
+ExecStart=/usr/bin/snap run snap.app
+SyslogIdentifier=snap.app
+Restart=on-failure
+WorkingDirectory=/var/snap/snap/44
+TimeoutStopSec=30
+Type=simple
+PrivateNetwork=yes
+
+[Install]
+WantedBy=multi-user.target
+`, mountUnitPrefix, mountUnitPrefix))
+
+	service.Network = ""
+	generatedWrapper, err = internal.GenerateSnapServiceUnitFile(service, nil)
+	c.Assert(err, IsNil)
+	c.Check(string(generatedWrapper), Not(testutil.Contains), "PrivateNetwork=")
+}
+
 func (s *serviceUnitGenSuite) TestQuotaGroupSlice(c *C) {
 	service := &snap.AppInfo{
 		Snap: &snap.Info{
@@ -3589,6 +3589,48 @@ func (s *servicesTestSuite) TestStartServicesNoEnable(c *C) {
 	})
 }
 
+func (s *servicesTestSuite) TestStartServicesFakeSystemd(c *C) {
+	sysd := systemdtest.NewFakeSystemd()
+	defer systemdtest.MockSystemd(sysd)()
+
+	info := snaptest.MockSnap(c, packageHello+`
+ svc2:
+  command: bin/hello
+  daemon: simple
+`, &snap.SideInfo{Revision: snap.R(12)})
+
+	opts := &wrappers.StartServicesOptions{Enable: true}
+	err := wrappers.StartServices(info.Services(), nil, opts, &progress.Null, s.perfTimings)
+	c.Assert(err, IsNil)
+
+	for _, svc := range []string{"snap.hello-snap.svc1.service", "snap.hello-snap.svc2.service"} {
+		c.Check(sysd.Unit(svc), Equals, systemdtest.UnitState{Enabled: true, Active: true, Starts: 1}, Commentf(svc))
+	}
+	c.Check(sysd.DaemonReloads(), Equals, 1)
+	c.Check(s.sysdLog, HasLen, 0)
+}
+
+func (s *servicesTestSuite) TestStartServicesUndoFakeSystemd(c *C) {
+	sysd := systemdtest.NewFakeSystemd()
+	defer systemdtest.MockSystemd(sysd)()
+	sysd.FailOn("start", "snap.hello-snap.svc2.service", errors.New("boom"))
+
+	info := snaptest.MockSnap(c, packageHello+`
+ svc2:
+  command: bin/hello
+  daemon: simple
+`, &snap.SideInfo{Revision: snap.R(12)})
+
+	opts := &wrappers.StartServicesOptions{Enable: true}
+	err := wrappers.StartServices(info.Services(), nil, opts, &progress.Null, s.perfTimings)
+	c.Assert(err, ErrorMatches, "boom")
+
+	// the started service was stopped and all of them were disabled again
+	c.Check(sysd.Unit("snap.hello-snap.svc1.service"), Equals, systemdtest.UnitState{Starts: 1, Stops: 1})
+	c.Check(sysd.Unit("snap.hello-snap.svc2.service"), Equals, systemdtest.UnitState{})
+	c.Check(sysd.DaemonReloads(), Equals, 2)
+}
+
 func (s *servicesTestSuite) TestStartServicesUserDaemons(c *C) {
 	info := snaptest.MockSnap(c, packageHelloNoSrv+`
  svc1: